@@ -23,7 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -32,7 +32,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/covering"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
@@ -525,7 +524,7 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		}
 	}
 
-	b.maybeNotifyScheduledJobCompletion(ctx, jobs.StatusSucceeded, p.ExecCfg())
+	schedulebase.MaybeNotifyScheduledJobCompletion(ctx, b.job, jobs.StatusSucceeded, p.ExecCfg())
 	return nil
 }
 
@@ -590,53 +589,11 @@ func (b *backupResumer) readManifestOnResume(
 	return &desc, nil
 }
 
-func (b *backupResumer) maybeNotifyScheduledJobCompletion(
-	ctx context.Context, jobStatus jobs.Status, exec *sql.ExecutorConfig,
-) {
-	env := scheduledjobs.ProdJobSchedulerEnv
-	if knobs, ok := exec.DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
-		if knobs.JobSchedulerEnv != nil {
-			env = knobs.JobSchedulerEnv
-		}
-	}
-
-	if err := exec.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-		// Do not rely on b.job containing created_by_id.  Query it directly.
-		datums, err := exec.InternalExecutor.QueryRowEx(
-			ctx,
-			"lookup-schedule-info",
-			txn,
-			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
-			fmt.Sprintf(
-				"SELECT created_by_id FROM %s WHERE id=$1 AND created_by_type=$2",
-				env.SystemJobsTableName()),
-			*b.job.ID(), jobs.CreatedByScheduledJobs)
-
-		if err != nil {
-			return errors.Wrap(err, "schedule info lookup")
-		}
-		if datums == nil {
-			// Not a scheduled backup.
-			return nil
-		}
-
-		scheduleID := int64(tree.MustBeDInt(datums[0]))
-		if err := jobs.NotifyJobTermination(
-			ctx, env, *b.job.ID(), jobStatus, b.job.Details(), scheduleID, exec.InternalExecutor, txn); err != nil {
-			log.Warningf(ctx,
-				"failed to notify schedule %d of completion of job %d; err=%s",
-				scheduleID, *b.job.ID(), err)
-		}
-		return nil
-	}); err != nil {
-		log.Errorf(ctx, "maybeNotifySchedule error: %v", err)
-	}
-}
-
 // OnFailOrCancel is part of the jobs.Resumer interface.
 func (b *backupResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
-	defer b.maybeNotifyScheduledJobCompletion(
+	defer schedulebase.MaybeNotifyScheduledJobCompletion(
 		ctx,
+		b.job,
 		jobs.StatusFailed,
 		execCtx.(sql.JobExecContext).ExecCfg(),
 	)
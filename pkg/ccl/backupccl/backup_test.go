@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	gosql "database/sql"
+	"encoding/base64"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -4406,6 +4407,53 @@ func TestRegionalKMSEncryptedBackup(t *testing.T) {
 	})
 }
 
+// This test performs an encrypted BACKUP using a keyfile KMS, rotates its
+// master key, and checks that an incremental BACKUP and a RESTORE can still
+// use the data key encrypted under the older version of the master key.
+func TestKeyfileKMSEncryptedBackupRotation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx, _, sqlDB, rawDir, cleanupFn := BackupRestoreTestSetup(t, MultiNode, 3, InitManualReplication)
+	defer cleanupFn()
+
+	keyDir, cleanupKeyDir := testutils.TempDir(t)
+	defer cleanupKeyDir()
+	keyfile := filepath.Join(keyDir, "backup.keys")
+	writeKeys := func(keys ...string) {
+		var contents string
+		for _, k := range keys {
+			contents += base64.StdEncoding.EncodeToString([]byte(k)) + "\n"
+		}
+		require.NoError(t, ioutil.WriteFile(keyfile, []byte(contents), 0600))
+	}
+	encryptionOption := fmt.Sprintf("kms='keyfile://%s'", keyfile)
+
+	setupBackupEncryptedTest(ctx, t, sqlDB)
+
+	writeKeys("0123456789abcdef0123456789abcdef")
+	sqlDB.Exec(t, fmt.Sprintf(`BACKUP TO $1 WITH %s`, encryptionOption), LocalFoo+"/full")
+	checkBackupFilesEncrypted(t, rawDir)
+
+	// Rotate the master key.
+	writeKeys("0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210")
+	sqlDB.Exec(t, `UPDATE neverappears.neverappears SET other = 'neverappears'`)
+	sqlDB.Exec(t, fmt.Sprintf(`BACKUP TO $1 INCREMENTAL FROM $2 WITH %s`, encryptionOption),
+		LocalFoo+"/inc", LocalFoo+"/full")
+
+	before := sqlDB.QueryStr(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE neverappears.neverappears`)
+	sqlDB.Exec(t, `DROP DATABASE neverappears CASCADE`)
+	sqlDB.Exec(t, fmt.Sprintf(`RESTORE DATABASE neverappears FROM $1, $2 WITH %s`,
+		encryptionOption), LocalFoo+"/full", LocalFoo+"/inc")
+	sqlDB.CheckQueryResults(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE neverappears.neverappears`, before)
+
+	// Removing the version of the master key used by the BACKUP prevents the
+	// RESTORE.
+	writeKeys("fedcba9876543210fedcba9876543210")
+	sqlDB.ExpectErr(t, `failed to decrypt data key`,
+		fmt.Sprintf(`SHOW BACKUP $1 WITH %s`, encryptionOption), LocalFoo+"/full")
+}
+
 type testKMSEnv struct {
 	settings         *cluster.Settings
 	externalIOConfig *base.ExternalIODirConfig
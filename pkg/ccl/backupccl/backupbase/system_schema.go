@@ -234,6 +234,9 @@ var SystemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.MigrationsTable.GetName(): {
 		IncludeInClusterBackup: OptOutOfClusterBackup,
 	},
+	systemschema.NotificationsTable.GetName(): {
+		IncludeInClusterBackup: OptOutOfClusterBackup,
+	},
 }
 
 // GetSystemTablesToIncludeInClusterBackup returns a set of system table names that
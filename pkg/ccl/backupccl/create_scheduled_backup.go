@@ -12,13 +12,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -30,23 +30,19 @@ import (
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/jsonpb"
 	pbtypes "github.com/gogo/protobuf/types"
-	"github.com/gorhill/cronexpr"
 )
 
 const (
-	optFirstRun                = "first_run"
-	optOnExecFailure           = "on_execution_failure"
-	optOnPreviousRunning       = "on_previous_running"
 	optIgnoreExistingBackups   = "ignore_existing_backups"
 	optUpdatesLastBackupMetric = "updates_cluster_last_backup_time_metric"
 )
 
 var scheduledBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
-	optFirstRun:                sql.KVStringOptRequireValue,
-	optOnExecFailure:           sql.KVStringOptRequireValue,
-	optOnPreviousRunning:       sql.KVStringOptRequireValue,
-	optIgnoreExistingBackups:   sql.KVStringOptRequireNoValue,
-	optUpdatesLastBackupMetric: sql.KVStringOptRequireNoValue,
+	schedulebase.OptFirstRun:          sql.KVStringOptRequireValue,
+	schedulebase.OptOnExecFailure:     sql.KVStringOptRequireValue,
+	schedulebase.OptOnPreviousRunning: sql.KVStringOptRequireValue,
+	optIgnoreExistingBackups:          sql.KVStringOptRequireNoValue,
+	optUpdatesLastBackupMetric:        sql.KVStringOptRequireNoValue,
 }
 
 // scheduledBackupEval is a representation of tree.ScheduledBackup, prepared
@@ -71,112 +67,26 @@ type scheduledBackupEval struct {
 	kmsURIs              func() ([]string, error)
 }
 
-func parseOnError(onError string, details *jobspb.ScheduleDetails) error {
-	switch strings.ToLower(onError) {
-	case "retry":
-		details.OnError = jobspb.ScheduleDetails_RETRY_SOON
-	case "reschedule":
-		details.OnError = jobspb.ScheduleDetails_RETRY_SCHED
-	case "pause":
-		details.OnError = jobspb.ScheduleDetails_PAUSE_SCHED
-	default:
-		return errors.Newf(
-			"%q is not a valid on_execution_error; valid values are [retry|reschedule|pause]",
-			onError)
-	}
-	return nil
-}
+var forceFullBackup *schedulebase.ScheduleRecurrence
 
-func parseWaitBehavior(wait string, details *jobspb.ScheduleDetails) error {
-	switch strings.ToLower(wait) {
-	case "start":
-		details.Wait = jobspb.ScheduleDetails_NO_WAIT
-	case "skip":
-		details.Wait = jobspb.ScheduleDetails_SKIP
-	case "wait":
-		details.Wait = jobspb.ScheduleDetails_WAIT
-	default:
-		return errors.Newf(
-			"%q is not a valid on_previous_running; valid values are [start|skip|wait]",
-			wait)
-	}
-	return nil
-}
-
-func makeScheduleDetails(opts map[string]string) (jobspb.ScheduleDetails, error) {
-	var details jobspb.ScheduleDetails
-	if v, ok := opts[optOnExecFailure]; ok {
-		if err := parseOnError(v, &details); err != nil {
-			return details, err
-		}
-	}
-
-	if v, ok := opts[optOnPreviousRunning]; ok {
-		if err := parseWaitBehavior(v, &details); err != nil {
-			return details, err
-		}
-	}
-	return details, nil
-}
-
-func scheduleFirstRun(evalCtx *tree.EvalContext, opts map[string]string) (*time.Time, error) {
-	if v, ok := opts[optFirstRun]; ok {
-		firstRun, _, err := tree.ParseDTimestampTZ(evalCtx, v, time.Microsecond)
-		if err != nil {
-			return nil, err
-		}
-		return &firstRun.Time, nil
-	}
-	return nil, nil
-}
-
-type scheduleRecurrence struct {
-	cron      string
-	frequency time.Duration
-}
-
-// A sentinel value indicating the schedule never recurs.
-var neverRecurs *scheduleRecurrence
-
-func computeScheduleRecurrence(
-	now time.Time, evalFn func() (string, error),
-) (*scheduleRecurrence, error) {
-	if evalFn == nil {
-		return neverRecurs, nil
-	}
-	cron, err := evalFn()
-	if err != nil {
-		return nil, err
-	}
-	expr, err := cronexpr.Parse(cron)
-	if err != nil {
-		return nil, errors.Newf(
-			`error parsing schedule expression: %q; it must be a valid cron expression`,
-			cron)
-	}
-	nextRun := expr.Next(now)
-	frequency := expr.Next(nextRun).Sub(nextRun)
-	return &scheduleRecurrence{cron, frequency}, nil
-}
-
-var forceFullBackup *scheduleRecurrence
-
-func pickFullRecurrenceFromIncremental(inc *scheduleRecurrence) *scheduleRecurrence {
-	if inc.frequency <= time.Hour {
+func pickFullRecurrenceFromIncremental(
+	inc *schedulebase.ScheduleRecurrence,
+) *schedulebase.ScheduleRecurrence {
+	if inc.Frequency <= time.Hour {
 		// If incremental is faster than once an hour, take fulls every day,
 		// some time between midnight and 1 am.
-		return &scheduleRecurrence{
-			cron:      "@daily",
-			frequency: 24 * time.Hour,
+		return &schedulebase.ScheduleRecurrence{
+			Cron:      "@daily",
+			Frequency: 24 * time.Hour,
 		}
 	}
 
-	if inc.frequency <= 24*time.Hour {
+	if inc.Frequency <= 24*time.Hour {
 		// If incremental is less than a day, take full weekly;  some day
 		// between 0 and 1 am.
-		return &scheduleRecurrence{
-			cron:      "@weekly",
-			frequency: 7 * 24 * time.Hour,
+		return &schedulebase.ScheduleRecurrence{
+			Cron:      "@weekly",
+			Frequency: 7 * 24 * time.Hour,
 		}
 	}
 
@@ -194,19 +104,14 @@ func doCreateBackupSchedules(
 	if err := p.RequireAdminRole(ctx, scheduleBackupOp); err != nil {
 		return err
 	}
-	env := scheduledjobs.ProdJobSchedulerEnv
-	if knobs, ok := p.ExecCfg().DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
-		if knobs.JobSchedulerEnv != nil {
-			env = knobs.JobSchedulerEnv
-		}
-	}
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
 
 	// Evaluate incremental and full recurrence.
-	incRecurrence, err := computeScheduleRecurrence(env.Now(), eval.recurrence)
+	incRecurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.recurrence)
 	if err != nil {
 		return err
 	}
-	fullRecurrence, err := computeScheduleRecurrence(env.Now(), eval.fullBackupRecurrence)
+	fullRecurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.fullBackupRecurrence)
 	if err != nil {
 		return err
 	}
@@ -315,12 +220,12 @@ func doCreateBackupSchedules(
 	}
 
 	evalCtx := &p.ExtendedEvalContext().EvalContext
-	firstRun, err := scheduleFirstRun(evalCtx, scheduleOptions)
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
 	if err != nil {
 		return err
 	}
 
-	details, err := makeScheduleDetails(scheduleOptions)
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
 	if err != nil {
 		return err
 	}
@@ -428,7 +333,7 @@ func makeBackupSchedule(
 	env scheduledjobs.JobSchedulerEnv,
 	owner security.SQLUsername,
 	label string,
-	recurrence *scheduleRecurrence,
+	recurrence *schedulebase.ScheduleRecurrence,
 	details jobspb.ScheduleDetails,
 	unpauseOnSuccess int64,
 	updateLastMetricOnSuccess bool,
@@ -449,7 +354,7 @@ func makeBackupSchedule(
 		args.BackupType = ScheduledBackupExecutionArgs_FULL
 	}
 
-	if err := sj.SetSchedule(recurrence.cron); err != nil {
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
 		return nil, err
 	}
 
@@ -476,20 +381,9 @@ func emitSchedule(
 	to, incrementalFrom, kmsURIs []string,
 	resultsCh chan<- tree.Datums,
 ) error {
-	var nextRun tree.Datum
-	status := "ACTIVE"
-	if sj.IsPaused() {
-		nextRun = tree.DNull
-		status = "PAUSED"
-		if s := sj.ScheduleStatus(); s != "" {
-			status += ": " + s
-		}
-	} else {
-		next, err := tree.MakeDTimestampTZ(sj.NextRun(), time.Microsecond)
-		if err != nil {
-			return err
-		}
-		nextRun = next
+	status, nextRun, err := schedulebase.ScheduleStatusAndNextRun(sj)
+	if err != nil {
+		return err
 	}
 
 	redactedBackupNode, err := GetRedactedBackupNode(backupNode, to, incrementalFrom, kmsURIs, "",
@@ -622,7 +516,7 @@ var scheduledBackupHeader = colinfo.ResultColumns{
 }
 
 func collectScheduledBackupTelemetry(
-	incRecurrence *scheduleRecurrence,
+	incRecurrence *schedulebase.ScheduleRecurrence,
 	firstRun *time.Time,
 	fullRecurrencePicked bool,
 	details jobspb.ScheduleDetails,
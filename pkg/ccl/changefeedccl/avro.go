@@ -188,6 +188,22 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 			}
 			return tree.NewDBox2D(b), nil
 		}
+	case types.TSQueryFamily:
+		avroType = avroSchemaString
+		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DTSQuery).TSQuery.String(), nil
+		}
+		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+			return tree.ParseDTSQuery(x.(string))
+		}
+	case types.TSVectorFamily:
+		avroType = avroSchemaString
+		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DTSVector).TSVector.String(), nil
+		}
+		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+			return tree.ParseDTSVector(x.(string))
+		}
 	case types.GeographyFamily:
 		avroType = avroSchemaBytes
 		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
@@ -313,6 +313,8 @@ func TestAvroSchema(t *testing.T) {
 			`TIMETZ`:       `["null","string"]`,
 			`TIMESTAMP`:    `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
 			`TIMESTAMPTZ`:  `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
+			`TSQUERY`:      `["null","string"]`,
+			`TSVECTOR`:     `["null","string"]`,
 			`UUID`:         `["null","string"]`,
 			`DECIMAL(3,2)`: `["null",{"type":"bytes","logicalType":"decimal","precision":3,"scale":2}]`,
 		}
@@ -441,6 +443,15 @@ func TestAvroSchema(t *testing.T) {
 			{sqlType: `JSONB`,
 				sql:  `'{"b": 1}'`,
 				avro: `{"string":"{\"b\": 1}"}`},
+
+			{sqlType: `TSQUERY`, sql: `NULL`, avro: `null`},
+			{sqlType: `TSQUERY`,
+				sql:  `'fat & (rat | !cat)'`,
+				avro: `{"string":"'fat' & ( 'rat' | !'cat' )"}`},
+			{sqlType: `TSVECTOR`, sql: `NULL`, avro: `null`},
+			{sqlType: `TSVECTOR`,
+				sql:  `'fat:2,4 cat:3A'`,
+				avro: `{"string":"'cat':3A 'fat':2,4"}`},
 		}
 
 		for _, test := range goldens {
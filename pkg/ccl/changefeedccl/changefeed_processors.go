@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
@@ -248,16 +249,28 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	kvFeedMemMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(kvFeedMemMonCapacity))
 	ca.kvFeedMemMon = kvFeedMemMon
 
+	var selectClause *tree.SelectClause
+	if ca.spec.Feed.Select != `` {
+		selectClause, err = changefeedbase.ParseSelect(ca.spec.Feed.Select)
+		if err != nil {
+			// Early abort in the case that the changefeed's SELECT is invalid.
+			ca.MoveToDraining(err)
+			ca.cancel()
+			return ctx
+		}
+	}
+
 	buf := kvfeed.MakeChanBuffer()
 	leaseMgr := ca.flowCtx.Cfg.LeaseManager.(*lease.Manager)
 	_, withDiff := ca.spec.Feed.Opts[changefeedbase.OptDiff]
 	kvfeedCfg := makeKVFeedCfg(ca.flowCtx.Cfg, leaseMgr, ca.kvFeedMemMon, ca.spec,
-		spans, withDiff, buf, ca.metrics)
+		spans, withDiff, selectClause, buf, ca.metrics)
 	cfg := ca.flowCtx.Cfg
 
 	ca.eventProducer = &bufEventProducer{buf}
-	ca.eventConsumer = newKVEventToRowConsumer(ctx, cfg, ca.spanFrontier, kvfeedCfg.InitialHighWater,
-		ca.sink, ca.encoder, ca.spec.Feed, ca.knobs)
+	ca.eventConsumer = newKVEventToRowConsumer(ctx, cfg, ca.flowCtx.NewEvalCtx(),
+		ca.spanFrontier, kvfeedCfg.InitialHighWater, ca.sink, ca.encoder, ca.spec.Feed,
+		selectClause, ca.knobs)
 	ca.startKVFeed(ctx, kvfeedCfg)
 
 	return ctx
@@ -291,6 +304,7 @@ func makeKVFeedCfg(
 	spec execinfrapb.ChangeAggregatorSpec,
 	spans []roachpb.Span,
 	withDiff bool,
+	selectClause *tree.SelectClause,
 	buf kvfeed.EventBuffer,
 	metrics *Metrics,
 ) kvfeed.Config {
@@ -312,10 +326,12 @@ func makeKVFeedCfg(
 		Metrics:            &metrics.KVFeedMetrics,
 		MM:                 mm,
 		InitialHighWater:   initialHighWater,
+		EndTime:            spec.Feed.EndTime,
 		WithDiff:           withDiff,
 		NeedsInitialScan:   needsInitialScan,
 		SchemaChangeEvents: schemaChangeEvents,
 		SchemaChangePolicy: schemaChangePolicy,
+		Select:             selectClause,
 	}
 	return kvfeedCfg
 }
@@ -401,6 +417,14 @@ func (ca *changeAggregator) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMet
 		} else if !ca.resolvedSpanBuf.IsEmpty() {
 			return ca.ProcessRowHelper(ca.resolvedSpanBuf.Pop()), nil
 		}
+		if ca.endTimeReached() {
+			// All the spans have been resolved at the end time and forwarded to
+			// the changeFrontier, which stops once every changeAggregator is
+			// done.
+			ca.cancel()
+			ca.MoveToDraining(nil /* err */)
+			break
+		}
 		if err := ca.tick(); err != nil {
 			select {
 			// If the poller errored first, that's the
@@ -418,6 +442,15 @@ func (ca *changeAggregator) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMet
 	return nil, ca.DrainHelper()
 }
 
+// endTimeReached returns true if the changefeed has an end time and all the
+// spans watched by this changeAggregator have been resolved and flushed up to
+// it.
+func (ca *changeAggregator) endTimeReached() bool {
+	endTime := ca.spec.Feed.EndTime
+	return !endTime.IsEmpty() && len(ca.spansToFlush) == 0 &&
+		endTime.LessEq(ca.spanFrontier.Frontier())
+}
+
 // tick is the workhorse behind Next(). It retrieves the next event from
 // kvFeed, sends off this event to the event consumer, and flushes the sink
 // if necessary.
@@ -530,6 +563,14 @@ type kvEventToRowConsumer struct {
 	rfCache   *rowFetcherCache
 	details   jobspb.ChangefeedDetails
 	kvFetcher row.SpanKVFetcher
+
+	// selectClause is the parsed SELECT of a CREATE CHANGEFEED ... AS SELECT,
+	// or nil if the changefeed emits whole rows. The evaluators for it are
+	// built lazily for each version of the table descriptor.
+	selectClause *tree.SelectClause
+	evalCtx      *tree.EvalContext
+	evaluators   map[idVersion]*changefeedbase.SelectEvaluator
+	alloc        rowenc.DatumAlloc
 }
 
 var _ kvEventConsumer = &kvEventToRowConsumer{}
@@ -537,25 +578,33 @@ var _ kvEventConsumer = &kvEventToRowConsumer{}
 func newKVEventToRowConsumer(
 	ctx context.Context,
 	cfg *execinfra.ServerConfig,
+	evalCtx *tree.EvalContext,
 	frontier *span.Frontier,
 	cursor hlc.Timestamp,
 	sink Sink,
 	encoder Encoder,
 	details jobspb.ChangefeedDetails,
+	selectClause *tree.SelectClause,
 	knobs TestingKnobs,
 ) kvEventConsumer {
 	rfCache := newRowFetcherCache(ctx, cfg.Codec, cfg.Settings,
 		cfg.LeaseManager.(*lease.Manager), cfg.HydratedTables, cfg.DB)
 
-	return &kvEventToRowConsumer{
-		frontier: frontier,
-		encoder:  encoder,
-		sink:     sink,
-		cursor:   cursor,
-		rfCache:  rfCache,
-		details:  details,
-		knobs:    knobs,
-	}
+	c := &kvEventToRowConsumer{
+		frontier:     frontier,
+		encoder:      encoder,
+		sink:         sink,
+		cursor:       cursor,
+		rfCache:      rfCache,
+		details:      details,
+		knobs:        knobs,
+		evalCtx:      evalCtx,
+		selectClause: selectClause,
+	}
+	if selectClause != nil {
+		c.evaluators = make(map[idVersion]*changefeedbase.SelectEvaluator)
+	}
+	return c
 }
 
 // ConsumeEvent implements kvEventConsumer interface
@@ -568,6 +617,11 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, event kvfeed.Ev
 	if err != nil {
 		return err
 	}
+	if c.selectClause != nil && r.tableDesc != nil {
+		if matches, err := c.projectRow(ctx, &r); err != nil || !matches {
+			return err
+		}
+	}
 
 	// Ensure that r updates are strictly newer than the least resolved timestamp
 	// being tracked by the local span frontier. The poller should not be forwarding
@@ -609,6 +663,66 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, event kvfeed.Ev
 	return nil
 }
 
+// projectRow applies the filter and projection of the changefeed's SELECT to
+// the row, returning false if the row doesn't match the filter and must not be
+// emitted. Deletions are always emitted: only the primary key of a deleted row
+// is known, so there is nothing to evaluate the filter against.
+func (c *kvEventToRowConsumer) projectRow(ctx context.Context, r *encodeRow) (bool, error) {
+	if !r.deleted {
+		e, datums, err := c.evaluatorForRow(ctx, r.tableDesc, r.datums)
+		if err != nil {
+			return false, err
+		}
+		if matches, err := e.MatchesFilter(datums); err != nil || !matches {
+			return false, err
+		}
+		projected, err := e.Project(datums)
+		if err != nil {
+			return false, err
+		}
+		r.projection = &projectedRow{names: e.ColumnNames(), datums: projected}
+	}
+	if r.prevDatums != nil && !r.prevDeleted {
+		e, datums, err := c.evaluatorForRow(ctx, r.prevTableDesc, r.prevDatums)
+		if err != nil {
+			return false, err
+		}
+		projected, err := e.Project(datums)
+		if err != nil {
+			return false, err
+		}
+		r.prevProjection = &projectedRow{names: e.ColumnNames(), datums: projected}
+	}
+	return true, nil
+}
+
+// evaluatorForRow returns the SelectEvaluator for the given version of the
+// table descriptor along with the decoded datums of the row.
+func (c *kvEventToRowConsumer) evaluatorForRow(
+	ctx context.Context, desc catalog.TableDescriptor, row rowenc.EncDatumRow,
+) (*changefeedbase.SelectEvaluator, tree.Datums, error) {
+	key := idVersion{id: desc.GetID(), version: desc.GetVersion()}
+	e, ok := c.evaluators[key]
+	if !ok {
+		var err error
+		e, err = changefeedbase.NewSelectEvaluator(ctx, c.evalCtx, c.selectClause, desc)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, `evaluating CHANGEFEED AS SELECT on %s version %d`,
+				desc.GetName(), desc.GetVersion())
+		}
+		c.evaluators[key] = e
+	}
+	cols := desc.PublicColumns()
+	datums := make(tree.Datums, len(cols))
+	for i, col := range cols {
+		if err := row[i].EnsureDecoded(col.GetType(), &c.alloc); err != nil {
+			return nil, nil, err
+		}
+		datums[i] = row[i].Datum
+	}
+	return e, datums, nil
+}
+
 func (c *kvEventToRowConsumer) eventToRow(
 	ctx context.Context, event kvfeed.Event,
 ) (encodeRow, error) {
@@ -954,6 +1068,14 @@ func (cf *changeFrontier) schemaChangeBoundaryReached() (r bool) {
 	return !cf.schemaChangeBoundary.IsEmpty() && cf.schemaChangeBoundary.Equal(cf.sf.Frontier())
 }
 
+// endTimeReached returns true if the changefeed has an end time and the
+// spanFrontier has reached it. The boundary at which the changefeed stops is
+// not a schema change boundary.
+func (cf *changeFrontier) endTimeReached() bool {
+	endTime := cf.spec.Feed.EndTime
+	return !endTime.IsEmpty() && endTime.LessEq(cf.sf.Frontier())
+}
+
 // shouldFailOnSchemaChange checks the job's spec to determine whether it should
 // failed on schema change events after all spans have been resolved.
 func (cf *changeFrontier) shouldFailOnSchemaChange() bool {
@@ -977,7 +1099,7 @@ func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 			return cf.ProcessRowHelper(cf.resolvedBuf.Pop()), nil
 		}
 
-		if cf.schemaChangeBoundaryReached() && cf.shouldFailOnSchemaChange() {
+		if cf.schemaChangeBoundaryReached() && cf.shouldFailOnSchemaChange() && !cf.endTimeReached() {
 			// TODO(ajwerner): make this more useful by at least informing the client
 			// of which tables changed.
 			cf.MoveToDraining(pgerror.Newf(pgcode.SchemaChangeOccurred,
@@ -1167,7 +1289,8 @@ func (cf *changeFrontier) maybeProtectTimestamp(
 	txn *kv.Txn,
 	resolved hlc.Timestamp,
 ) error {
-	if cf.isSinkless() || !cf.schemaChangeBoundaryReached() || !cf.shouldProtectBoundaries() {
+	if cf.isSinkless() || !cf.schemaChangeBoundaryReached() || !cf.shouldProtectBoundaries() ||
+		cf.endTimeReached() {
 		return nil
 	}
 
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -75,8 +76,8 @@ func init() {
 func changefeedPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
 ) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
-	changefeedStmt, ok := stmt.(*tree.CreateChangefeed)
-	if !ok {
+	changefeedStmt := getChangefeedStatement(stmt)
+	if changefeedStmt == nil {
 		return nil, nil, nil, false, nil
 	}
 
@@ -150,7 +151,7 @@ func changefeedPlanHook(
 			return err
 		}
 
-		jobDescription, err := changefeedJobDescription(p, changefeedStmt, sinkURI, opts)
+		jobDescription, err := changefeedJobDescription(p, changefeedStmt.CreateChangefeed, sinkURI, opts)
 		if err != nil {
 			return err
 		}
@@ -168,14 +169,49 @@ func changefeedPlanHook(
 			statementTime = initialHighWater
 		}
 
+		// The end time may be in the future, in which case the changefeed runs
+		// until then, so it isn't evaluated like an AS OF SYSTEM TIME clause.
+		var endTime hlc.Timestamp
+		if e, ok := opts[changefeedbase.OptEndTime]; ok {
+			asOf := tree.AsOfClause{Expr: tree.NewStrVal(e)}
+			var err error
+			if endTime, err = tree.EvalAsOfTimestamp(
+				ctx, asOf, p.SemaCtx(), &p.ExtendedEvalContext().EvalContext,
+			); err != nil {
+				return err
+			}
+			if endTime.Less(statementTime) {
+				return errors.Errorf(`%s %s is before the changefeed start time %s`,
+					changefeedbase.OptEndTime, endTime.AsOfSystemTime(), statementTime.AsOfSystemTime())
+			}
+		}
+		if _, ok := opts[changefeedbase.OptInitialScanOnly]; ok {
+			endTime = statementTime
+		}
+
+		// A CREATE CHANGEFEED ... AS SELECT watches the single table it selects
+		// from.
+		targetList := changefeedStmt.Targets
+		var selectClause *tree.SelectClause
+		if changefeedStmt.Select != nil {
+			if selectClause, err = changefeedbase.ValidateSelect(changefeedStmt.Select); err != nil {
+				return err
+			}
+			tn, err := changefeedbase.SelectTableName(selectClause)
+			if err != nil {
+				return err
+			}
+			targetList = tree.TargetList{Tables: tree.TablePatterns{tn}}
+		}
+
 		// For now, disallow targeting a database or wildcard table selection.
 		// Getting it right as tables enter and leave the set over time is
 		// tricky.
-		if len(changefeedStmt.Targets.Databases) > 0 {
+		if len(targetList.Databases) > 0 {
 			return errors.Errorf(`CHANGEFEED cannot target %s`,
-				tree.AsString(&changefeedStmt.Targets))
+				tree.AsString(&targetList))
 		}
-		for _, t := range changefeedStmt.Targets.Tables {
+		for _, t := range targetList.Tables {
 			p, err := t.NormalizeTablePattern()
 			if err != nil {
 				return err
@@ -187,7 +223,7 @@ func changefeedPlanHook(
 
 		// This grabs table descriptors once to get their ids.
 		targetDescs, _, err := backupbase.ResolveTargetsToDescriptors(
-			ctx, p, statementTime, &changefeedStmt.Targets)
+			ctx, p, statementTime, &targetList)
 		if err != nil {
 			return errors.Wrap(err, "failed to resolve targets in the CHANGEFEED stmt")
 		}
@@ -210,6 +246,16 @@ func changefeedPlanHook(
 				if err := changefeedbase.ValidateTable(targets, table); err != nil {
 					return err
 				}
+				if selectClause != nil {
+					// Check that the expressions resolve and type check against
+					// the table so that mistakes are reported now rather than
+					// when the first row is emitted.
+					if _, err := changefeedbase.NewSelectEvaluator(
+						ctx, &p.ExtendedEvalContext().EvalContext, selectClause, table,
+					); err != nil {
+						return err
+					}
+				}
 			}
 		}
 
@@ -218,6 +264,10 @@ func changefeedPlanHook(
 			Opts:          opts,
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
+			EndTime:       endTime,
+		}
+		if changefeedStmt.Select != nil {
+			details.Select = tree.AsStringWithFlags(changefeedStmt.Select, tree.FmtParsable)
 		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{},
@@ -237,7 +287,8 @@ func changefeedPlanHook(
 		//   storage sink. Kafka etc have a key and value field in each message but
 		//   cloud storage sinks don't have anywhere to put the key. So if the key
 		//   is not in the value, then for DELETEs there is no way to recover which
-		//   key was deleted. We could make the user explicitly pass this option for
+		//   key was deleted. The same goes for webhook sinks, which only POST
+		//   values. We could make the user explicitly pass this option for
 		//   every cloud storage sink and error if they don't, but that seems
 		//   user-hostile for insufficient reason. We can't do this any earlier,
 		//   because we might return errors about `key_in_value` being incompatible
@@ -262,7 +313,7 @@ func changefeedPlanHook(
 		if _, err := getEncoder(details.Opts, details.Targets); err != nil {
 			return err
 		}
-		if isCloudStorageSink(parsedSink) {
+		if isCloudStorageSink(parsedSink) || isWebhookSink(parsedSink) {
 			details.Opts[changefeedbase.OptKeyInValue] = ``
 		}
 
@@ -345,9 +396,35 @@ func changefeedPlanHook(
 					}
 					return sqlDescIDs
 				}(),
-				Details:  details,
-				Progress: *progress.GetChangefeed(),
+				Details:   details,
+				Progress:  *progress.GetChangefeed(),
+				CreatedBy: changefeedStmt.CreatedByInfo,
 			}
+
+			if changefeedStmt.CreatedByInfo != nil {
+				// Changefeeds started by a schedule are created in the transaction
+				// executing the schedule, and are adopted by the job registry once
+				// it commits.
+				txn := p.ExtendedEvalContext().Txn
+				aj, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(ctx, jr, txn)
+				if err != nil {
+					return err
+				}
+				if protectedTimestampID != uuid.Nil {
+					ptr := jobsprotectedts.MakeRecord(protectedTimestampID, *aj.ID(),
+						statementTime, spansToProtect)
+					if err := p.ExecCfg().ProtectedTimestampProvider.Protect(ctx, txn, ptr); err != nil {
+						return err
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(*aj.ID()))}:
+					return nil
+				}
+			}
+
 			createJobAndProtectedTS := func(ctx context.Context, txn *kv.Txn) (err error) {
 				sj, err = p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, jr, txn)
 				if err != nil {
@@ -401,16 +478,64 @@ func changefeedPlanHook(
 	return fn, header, nil, avoidBuffering, nil
 }
 
+// annotatedChangefeedStatement is a tree.CreateChangefeed, optionally
+// annotated with the scheduling information.
+type annotatedChangefeedStatement struct {
+	*tree.CreateChangefeed
+	*jobs.CreatedByInfo
+}
+
+func getChangefeedStatement(stmt tree.Statement) *annotatedChangefeedStatement {
+	switch changefeed := stmt.(type) {
+	case *annotatedChangefeedStatement:
+		return changefeed
+	case *tree.CreateChangefeed:
+		return &annotatedChangefeedStatement{CreateChangefeed: changefeed}
+	default:
+		return nil
+	}
+}
+
 func changefeedJobDescription(
 	p sql.PlanHookState, changefeed *tree.CreateChangefeed, sinkURI string, opts map[string]string,
 ) (string, error) {
-	cleanedSinkURI, err := cloudimpl.SanitizeExternalStorageURI(sinkURI, []string{changefeedbase.SinkParamSASLPassword})
+	c, err := redactedChangefeedNode(changefeed.Targets, changefeed.Select, sinkURI, opts)
 	if err != nil {
 		return "", err
 	}
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(c, ann), nil
+}
+
+// redactedChangefeedNode returns a CREATE CHANGEFEED statement for the given
+// sink and options, with the secrets they contain redacted.
+func redactedChangefeedNode(
+	targets tree.TargetList, sel *tree.Select, sinkURI string, opts map[string]string,
+) (*tree.CreateChangefeed, error) {
+	cleanedSinkURI, err := cloudimpl.SanitizeExternalStorageURI(sinkURI, []string{changefeedbase.SinkParamSASLPassword})
+	if err != nil {
+		return nil, err
+	}
+	redactedOpts := make(map[string]string, len(opts))
+	for k, v := range opts {
+		if k == changefeedbase.OptWebhookAuthHeader && len(v) > 0 {
+			v = `redacted`
+		}
+		redactedOpts[k] = v
+	}
+	c := makeChangefeedNode(targets, cleanedSinkURI, redactedOpts)
+	c.Select = sel
+	return c, nil
+}
+
+// makeChangefeedNode returns a CREATE CHANGEFEED statement for the given
+// evaluated sink and options.
+func makeChangefeedNode(
+	targets tree.TargetList, sinkURI string, opts map[string]string,
+) *tree.CreateChangefeed {
 	c := &tree.CreateChangefeed{
-		Targets: changefeed.Targets,
-		SinkURI: tree.NewDString(cleanedSinkURI),
+		Targets: targets,
+		SinkURI: tree.NewDString(sinkURI),
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
@@ -420,8 +545,7 @@ func changefeedJobDescription(
 		c.Options = append(c.Options, opt)
 	}
 	sort.Slice(c.Options, func(i, j int) bool { return c.Options[i].Key < c.Options[j].Key })
-	ann := p.ExtendedEvalContext().Annotations
-	return tree.AsStringWithFQNames(c, ann), nil
+	return c
 }
 
 func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails, error) {
@@ -476,6 +600,16 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 				`cannot specify both %s and %s`, changefeedbase.OptInitialScan,
 				changefeedbase.OptNoInitialScan)
 		}
+		if _, initialScanOnly := details.Opts[changefeedbase.OptInitialScanOnly]; initialScanOnly {
+			for _, opt := range []string{
+				changefeedbase.OptNoInitialScan, changefeedbase.OptEndTime,
+			} {
+				if _, ok := details.Opts[opt]; ok {
+					return jobspb.ChangefeedDetails{}, errors.Errorf(
+						`cannot specify both %s and %s`, changefeedbase.OptInitialScanOnly, opt)
+				}
+			}
+		}
 	}
 	{
 		const opt = changefeedbase.OptEnvelope
@@ -503,6 +637,18 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 				`unknown %s: %s`, opt, v)
 		}
 	}
+	if details.Select != `` {
+		if _, err := changefeedbase.ParseSelect(details.Select); err != nil {
+			return jobspb.ChangefeedDetails{}, err
+		}
+		// The avro encoder derives its schemas from the table descriptor, so it
+		// can't describe projected columns.
+		const opt = changefeedbase.OptFormat
+		if v := changefeedbase.FormatType(details.Opts[opt]); v == changefeedbase.OptFormatAvro {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`%s=%s is not supported with CHANGEFEED AS SELECT`, opt, v)
+		}
+	}
 	return details, nil
 }
 
@@ -565,6 +711,7 @@ func (b *changefeedResumer) Resume(ctx context.Context, execCtx interface{}) err
 		startedCh := make(chan tree.Datums, 1)
 
 		if err = distChangefeedFlow(ctx, jobExec, jobID, details, progress, startedCh); err == nil {
+			schedulebase.MaybeNotifyScheduledJobCompletion(ctx, b.job, jobs.StatusSucceeded, execCfg)
 			return nil
 		}
 		if !IsRetryableError(err) {
@@ -625,6 +772,7 @@ func (b *changefeedResumer) OnFailOrCancel(ctx context.Context, jobExec interfac
 		telemetry.Count(`changefeed.enterprise.fail`)
 		exec.ExecCfg().JobRegistry.MetricsStruct().Changefeed.(*Metrics).Failures.Inc(1)
 	}
+	schedulebase.MaybeNotifyScheduledJobCompletion(ctx, b.job, jobs.StatusFailed, execCfg)
 	return nil
 }
 
@@ -687,21 +835,32 @@ func (b *changefeedResumer) OnPauseRequest(
 func getQualifiedTableName(
 	ctx context.Context, execCfg sql.ExecutorConfig, txn *kv.Txn, desc catalog.TableDescriptor,
 ) (string, error) {
-	dbDesc, err := catalogkv.MustGetDatabaseDescByID(ctx, txn, execCfg.Codec, desc.GetParentID())
+	tbName, err := getQualifiedTableNameObj(ctx, execCfg, txn, desc)
 	if err != nil {
 		return "", err
 	}
+	return tbName.String(), nil
+}
+
+// getQualifiedTableNameObj returns the database-qualified name of the table
+// or view represented by the provided descriptor as a tree.TableName.
+func getQualifiedTableNameObj(
+	ctx context.Context, execCfg sql.ExecutorConfig, txn *kv.Txn, desc catalog.TableDescriptor,
+) (tree.TableName, error) {
+	dbDesc, err := catalogkv.MustGetDatabaseDescByID(ctx, txn, execCfg.Codec, desc.GetParentID())
+	if err != nil {
+		return tree.TableName{}, err
+	}
 	schemaID := desc.GetParentSchemaID()
 	schemaName, err := resolver.ResolveSchemaNameByID(ctx, txn, execCfg.Codec, desc.GetParentID(), schemaID)
 	if err != nil {
-		return "", err
+		return tree.TableName{}, err
 	}
-	tbName := tree.MakeTableNameWithSchema(
+	return tree.MakeTableNameWithSchema(
 		tree.Name(dbDesc.GetName()),
 		tree.Name(schemaName),
 		tree.Name(desc.GetName()),
-	)
-	return tbName.String(), nil
+	), nil
 }
 
 // getChangefeedTargetName gets a table name with or without the dots
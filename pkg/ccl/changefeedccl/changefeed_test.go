@@ -155,6 +155,75 @@ func TestChangefeedDiff(t *testing.T) {
 	t.Run(`cloudstorage`, cloudStorageTest(testFn))
 }
 
+func TestChangefeedSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, secret STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'active', 's0'), (1, 'inactive', 's1')`)
+
+		t.Run(`filter and projection`, func(t *testing.T) {
+			foo := feed(t, f, `CREATE CHANGEFEED AS `+
+				`SELECT a, upper(b) AS status FROM foo WHERE b = 'active'`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{
+				`foo: [0]->{"after": {"a": 0, "status": "ACTIVE"}}`,
+			})
+
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'active', 's2'), (3, 'inactive', 's3')`)
+			assertPayloads(t, foo, []string{
+				`foo: [2]->{"after": {"a": 2, "status": "ACTIVE"}}`,
+			})
+
+			// Deletes are emitted whether or not the row matched the filter,
+			// because only the primary key of a deleted row is known.
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 3`)
+			assertPayloads(t, foo, []string{
+				`foo: [3]->{"after": null}`,
+			})
+		})
+
+		t.Run(`star and diff`, func(t *testing.T) {
+			foo := feed(t, f, `CREATE CHANGEFEED WITH diff AS `+
+				`SELECT * FROM foo AS f WHERE f.a = 2`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{
+				`foo: [2]->{"after": {"a": 2, "b": "active", "secret": "s2"}, "before": null}`,
+			})
+
+			sqlDB.Exec(t, `UPDATE foo SET b = 'inactive' WHERE a IN (1, 2)`)
+			assertPayloads(t, foo, []string{
+				`foo: [2]->{"after": {"a": 2, "b": "inactive", "secret": "s2"}, ` +
+					`"before": {"a": 2, "b": "active", "secret": "s2"}}`,
+			})
+		})
+
+		t.Run(`drop referenced column`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE drop_column (a INT PRIMARY KEY, b INT)`)
+			defer sqlDB.Exec(t, `DROP TABLE drop_column`)
+			sqlDB.Exec(t, `INSERT INTO drop_column VALUES (0, 1)`)
+			dropColumn := feed(t, f, `CREATE CHANGEFEED AS SELECT a FROM drop_column WHERE b > 0`)
+			defer closeFeed(t, dropColumn)
+			assertPayloads(t, dropColumn, []string{
+				`drop_column: [0]->{"after": {"a": 0}}`,
+			})
+			sqlDB.Exec(t, `ALTER TABLE drop_column DROP COLUMN b`)
+			sqlDB.Exec(t, `INSERT INTO drop_column VALUES (1)`)
+			for {
+				if _, err := dropColumn.Next(); err != nil {
+					require.Regexp(t, `CHANGEFEED AS SELECT is no longer valid for drop_column`, err)
+					break
+				}
+			}
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedEnvelope(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -440,6 +509,79 @@ func TestChangefeedInitialScan(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedEndTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '10ms'`)
+
+		// assertFeedDone checks that the changefeed stopped by itself once it
+		// emitted everything up to its end time.
+		assertFeedDone := func(t *testing.T, feed cdctest.TestFeed) {
+			t.Helper()
+			if e, ok := feed.(*cdctest.TableFeed); ok {
+				testutils.SucceedsSoon(t, func() error {
+					var status string
+					sqlDB.QueryRow(t, `SELECT status FROM system.jobs WHERE id = $1`, e.JobID).Scan(&status)
+					if status != `succeeded` {
+						return errors.Errorf(`expected succeeded got %s`, status)
+					}
+					return nil
+				})
+				return
+			}
+			m, err := feed.Next()
+			require.NoError(t, err)
+			require.Nil(t, m)
+		}
+
+		t.Run(`initial scan only`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE initial_scan_only (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO initial_scan_only VALUES (1), (2)`)
+
+			initialScanOnly := feed(t, f, `CREATE CHANGEFEED FOR initial_scan_only WITH initial_scan_only`)
+			defer closeFeed(t, initialScanOnly)
+			assertPayloads(t, initialScanOnly, []string{
+				`initial_scan_only: [1]->{"after": {"a": 1}}`,
+				`initial_scan_only: [2]->{"after": {"a": 2}}`,
+			})
+			sqlDB.Exec(t, `INSERT INTO initial_scan_only VALUES (3)`)
+			assertFeedDone(t, initialScanOnly)
+		})
+
+		t.Run(`end time`, func(t *testing.T) {
+			sqlDB.Exec(t, `CREATE TABLE end_time (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO end_time VALUES (1)`)
+			var cursor string
+			sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&cursor)
+			sqlDB.Exec(t, `INSERT INTO end_time VALUES (2)`)
+			var endTime string
+			sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&endTime)
+			sqlDB.Exec(t, `INSERT INTO end_time VALUES (3)`)
+
+			endTimeFeed := feed(t, f, `CREATE CHANGEFEED FOR end_time WITH cursor=$1, end_time=$2`,
+				cursor, endTime)
+			defer closeFeed(t, endTimeFeed)
+			assertPayloads(t, endTimeFeed, []string{
+				`end_time: [2]->{"after": {"a": 2}}`,
+			})
+			assertFeedDone(t, endTimeFeed)
+		})
+
+		t.Run(`invalid options`, func(t *testing.T) {
+			sqlDB.ExpectErr(t, `cannot specify both initial_scan_only and no_initial_scan`,
+				`CREATE CHANGEFEED FOR end_time WITH initial_scan_only, no_initial_scan`)
+			sqlDB.ExpectErr(t, `end_time .* is before the changefeed start time`,
+				`CREATE CHANGEFEED FOR end_time WITH end_time='1'`)
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedUserDefinedTypes(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
@@ -1910,6 +2052,31 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `unknown envelope: nope`,
 		`EXPERIMENTAL CHANGEFEED FOR foo WITH envelope=nope`,
 	)
+
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED AS SELECT must select from exactly one table`,
+		`CREATE CHANGEFEED AS SELECT foo.a FROM foo, d.bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED AS SELECT does not support WITH, ORDER BY, LIMIT or locking clauses`,
+		`CREATE CHANGEFEED AS SELECT a FROM foo ORDER BY a`,
+	)
+	sqlDB.ExpectErr(
+		t, `aggregate functions are not allowed in CHANGEFEED`,
+		`CREATE CHANGEFEED AS SELECT count(*) FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `column "nope" does not exist`,
+		`CREATE CHANGEFEED AS SELECT nope FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `argument of CHANGEFEED WHERE must be type bool, not type string`,
+		`CREATE CHANGEFEED AS SELECT a FROM foo WHERE b`,
+	)
+	sqlDB.ExpectErr(
+		t, `format=experimental_avro is not supported with CHANGEFEED AS SELECT`,
+		`CREATE CHANGEFEED WITH format=experimental_avro AS SELECT a FROM foo`,
+	)
 	sqlDB.ExpectErr(
 		t, `negative durations are not accepted: resolved='-1s'`,
 		`EXPERIMENTAL CHANGEFEED FOR foo WITH resolved='-1s'`,
@@ -2101,6 +2268,22 @@ func TestChangefeedErrors(t *testing.T) {
 		`experimental-nodelocal://0/bar`,
 	)
 
+	// So is the webhookSink.
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=experimental_avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', confluent_schema_registry=$2`,
+		`webhook-https://fake-host`, `schemareg-nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `param batch_size must be an integer`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `webhook-https://fake-host?batch_size=foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `option webhook_client_timeout must be a duration`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH webhook_client_timeout='soon'`,
+		`webhook-https://fake-host`,
+	)
+
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErr(
 		t, `key_in_value is only usable with envelope=wrapped`,
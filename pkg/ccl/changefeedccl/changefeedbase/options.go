@@ -29,6 +29,7 @@ const (
 	OptAvroSchemaPrefix         = `avro_schema_prefix`
 	OptConfluentSchemaRegistry  = `confluent_schema_registry`
 	OptCursor                   = `cursor`
+	OptEndTime                  = `end_time`
 	OptEnvelope                 = `envelope`
 	OptFormat                   = `format`
 	OptFullTableName            = `full_table_name`
@@ -40,6 +41,8 @@ const (
 	OptSchemaChangeEvents       = `schema_change_events`
 	OptSchemaChangePolicy       = `schema_change_policy`
 	OptProtectDataFromGCOnPause = `protect_data_from_gc_on_pause`
+	OptWebhookAuthHeader        = `webhook_auth_header`
+	OptWebhookClientTimeout     = `webhook_client_timeout`
 
 	// OptSchemaChangeEventClassColumnChange corresponds to all schema change
 	// events which add or remove any column.
@@ -69,6 +72,10 @@ const (
 	// cursor is specified. This option is useful to create a changefeed which
 	// subscribes only to new messages.
 	OptNoInitialScan = `no_initial_scan`
+	// OptInitialScanOnly enables an initial scan, after which the changefeed
+	// stops: the changefeed emits a snapshot of the watched tables at the
+	// statement time, or at the cursor timestamp if a cursor is specified.
+	OptInitialScanOnly = `initial_scan_only`
 
 	OptEnvelopeKeyOnly       EnvelopeType = `key_only`
 	OptEnvelopeRow           EnvelopeType = `row`
@@ -93,6 +100,12 @@ const (
 	SinkParamSASLHandshake    = `sasl_handshake`
 	SinkParamSASLUser         = `sasl_user`
 	SinkParamSASLPassword     = `sasl_password`
+
+	SinkSchemeWebhookHTTPS    = `webhook-https`
+	SinkParamWebhookBatchSize = `batch_size`
+	SinkParamWebhookFlushFreq = `flush_interval`
+	SinkParamWebhookRetryMax  = `max_retries`
+	SinkParamWebhookBackoff   = `retry_backoff`
 )
 
 // ChangefeedOptionExpectValues is used to parse changefeed options using
@@ -101,6 +114,7 @@ var ChangefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	OptAvroSchemaPrefix:         sql.KVStringOptRequireValue,
 	OptConfluentSchemaRegistry:  sql.KVStringOptRequireValue,
 	OptCursor:                   sql.KVStringOptRequireValue,
+	OptEndTime:                  sql.KVStringOptRequireValue,
 	OptEnvelope:                 sql.KVStringOptRequireValue,
 	OptFormat:                   sql.KVStringOptRequireValue,
 	OptFullTableName:            sql.KVStringOptRequireNoValue,
@@ -113,5 +127,8 @@ var ChangefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	OptSchemaChangePolicy:       sql.KVStringOptRequireValue,
 	OptInitialScan:              sql.KVStringOptRequireNoValue,
 	OptNoInitialScan:            sql.KVStringOptRequireNoValue,
+	OptInitialScanOnly:          sql.KVStringOptRequireNoValue,
 	OptProtectDataFromGCOnPause: sql.KVStringOptRequireNoValue,
+	OptWebhookAuthHeader:        sql.KVStringOptRequireValue,
+	OptWebhookClientTimeout:     sql.KVStringOptRequireValue,
 }
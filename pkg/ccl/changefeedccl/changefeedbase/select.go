@@ -0,0 +1,300 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedbase
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// selectRejectFlags are the classes of expressions which cannot be evaluated
+// one row at a time inside the changefeed processors.
+const selectRejectFlags = tree.RejectSpecial | tree.RejectSubqueries |
+	tree.RejectVolatileFunctions | tree.RejectNestedGenerators
+
+// ParseSelect parses the SELECT statement of a `CREATE CHANGEFEED ... AS
+// SELECT` as stored in the ChangefeedDetails of the job and checks that it has
+// a shape which changefeeds can evaluate.
+func ParseSelect(sql string) (*tree.SelectClause, error) {
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return nil, errors.Errorf(`expected a SELECT statement: %s`, sql)
+	}
+	return ValidateSelect(sel)
+}
+
+// ValidateSelect checks that the SELECT of a `CREATE CHANGEFEED ... AS SELECT`
+// is a projection and filter over exactly one table and returns its SELECT
+// clause. Anything which would need more than the current row of the table to
+// evaluate (joins, aggregations, ordering, etc) is rejected.
+func ValidateSelect(sel *tree.Select) (*tree.SelectClause, error) {
+	if sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return nil, errors.Errorf(
+			`CHANGEFEED AS SELECT does not support WITH, ORDER BY, LIMIT or locking clauses`)
+	}
+	stmt := sel.Select
+	for {
+		paren, ok := stmt.(*tree.ParenSelect)
+		if !ok {
+			break
+		}
+		if paren.Select.With != nil || paren.Select.OrderBy != nil ||
+			paren.Select.Limit != nil || paren.Select.Locking != nil {
+			return nil, errors.Errorf(
+				`CHANGEFEED AS SELECT does not support WITH, ORDER BY, LIMIT or locking clauses`)
+		}
+		stmt = paren.Select.Select
+	}
+	sc, ok := stmt.(*tree.SelectClause)
+	if !ok || sc.TableSelect {
+		return nil, errors.Errorf(`CHANGEFEED AS SELECT requires a simple SELECT statement`)
+	}
+	if sc.Distinct || sc.GroupBy != nil || sc.Having != nil || sc.Window != nil {
+		return nil, errors.Errorf(
+			`CHANGEFEED AS SELECT does not support DISTINCT, GROUP BY, HAVING or WINDOW clauses`)
+	}
+	if sc.From.AsOf.Expr != nil {
+		return nil, errors.Errorf(`CHANGEFEED AS SELECT does not support AS OF SYSTEM TIME, use the cursor option`)
+	}
+	if _, err := SelectTableName(sc); err != nil {
+		return nil, err
+	}
+	if len(sc.Exprs) == 0 {
+		return nil, errors.Errorf(`CHANGEFEED AS SELECT must select at least one column`)
+	}
+	return sc, nil
+}
+
+// SelectTableName returns the name of the single table selected from by the
+// given SELECT clause.
+func SelectTableName(sc *tree.SelectClause) (*tree.TableName, error) {
+	if len(sc.From.Tables) != 1 {
+		return nil, errors.Errorf(`CHANGEFEED AS SELECT must select from exactly one table`)
+	}
+	aliased, ok := sc.From.Tables[0].(*tree.AliasedTableExpr)
+	if !ok {
+		return nil, errors.Errorf(`CHANGEFEED AS SELECT cannot select from %s`,
+			tree.AsString(sc.From.Tables[0]))
+	}
+	tn, ok := aliased.Expr.(*tree.TableName)
+	if !ok || aliased.Ordinality || aliased.Lateral || aliased.IndexFlags != nil ||
+		len(aliased.As.Cols) > 0 {
+		return nil, errors.Errorf(`CHANGEFEED AS SELECT cannot select from %s`,
+			tree.AsString(aliased))
+	}
+	return tn, nil
+}
+
+// selectSourceName is the name that column references in the SELECT clause
+// are resolved against. It's the alias of the table, if there is one, and
+// otherwise the table name as written in the statement. Using the name from the
+// statement rather than the name of the descriptor keeps qualified column
+// references working after the table is renamed.
+func selectSourceName(sc *tree.SelectClause) tree.TableName {
+	aliased := sc.From.Tables[0].(*tree.AliasedTableExpr)
+	if aliased.As.Alias != "" {
+		return tree.MakeUnqualifiedTableName(aliased.As.Alias)
+	}
+	return *aliased.Expr.(*tree.TableName)
+}
+
+// ValidateSelectColumns checks that every column referenced by the SELECT
+// clause exists in the given version of the table. It's used to detect schema
+// changes which break the expressions of a running changefeed. Unlike
+// NewSelectEvaluator, it doesn't type check, so it can be used with table
+// descriptors which don't have their user defined types hydrated.
+func ValidateSelectColumns(sc *tree.SelectClause, desc catalog.TableDescriptor) error {
+	r := makeSelectResolver(sc, desc)
+	for _, target := range sc.Exprs {
+		if isStar(target.Expr) {
+			continue
+		}
+		if _, err := r.resolve(target.Expr); err != nil {
+			return err
+		}
+	}
+	if sc.Where != nil {
+		if _, err := r.resolve(sc.Where.Expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelectEvaluator evaluates the projection and filter of a `CREATE CHANGEFEED
+// ... AS SELECT` against rows of one version of a table descriptor. Rows are
+// expected to hold the datums of the public columns of the table in order.
+type SelectEvaluator struct {
+	evalCtx *tree.EvalContext
+	ivars   *selectIVarContainer
+	names   []string
+	exprs   []tree.TypedExpr
+	filter  tree.TypedExpr
+}
+
+// NewSelectEvaluator resolves and type checks the expressions of the SELECT
+// clause against the given version of the table descriptor.
+func NewSelectEvaluator(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	sc *tree.SelectClause,
+	desc catalog.TableDescriptor,
+) (*SelectEvaluator, error) {
+	r := makeSelectResolver(sc, desc)
+	if evalCtx.SessionData != nil {
+		r.searchPath = evalCtx.SessionData.SearchPath
+	}
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = r.ivars
+	semaCtx.Properties.Require(`CHANGEFEED`, selectRejectFlags)
+
+	e := &SelectEvaluator{evalCtx: evalCtx, ivars: r.ivars}
+	for _, target := range sc.Exprs {
+		if isStar(target.Expr) {
+			for i, col := range r.ivars.cols {
+				e.names = append(e.names, col.GetName())
+				e.exprs = append(e.exprs, r.ivarHelper.IndexedVar(i))
+			}
+			continue
+		}
+		name, err := tree.GetRenderColName(r.searchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := r.resolve(target.Expr)
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := tree.TypeCheck(ctx, expr, &semaCtx, types.Any)
+		if err != nil {
+			return nil, err
+		}
+		e.names = append(e.names, name)
+		e.exprs = append(e.exprs, typedExpr)
+	}
+	if sc.Where != nil {
+		expr, err := r.resolve(sc.Where.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if e.filter, err = tree.TypeCheckAndRequire(
+			ctx, expr, &semaCtx, types.Bool, `CHANGEFEED WHERE`,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// ColumnNames returns the names of the projected columns.
+func (e *SelectEvaluator) ColumnNames() []string {
+	return e.names
+}
+
+// MatchesFilter returns whether the row satisfies the WHERE clause. A row
+// always matches if there is no WHERE clause.
+func (e *SelectEvaluator) MatchesFilter(row tree.Datums) (bool, error) {
+	e.ivars.row = row
+	e.evalCtx.PushIVarContainer(e.ivars)
+	defer e.evalCtx.PopIVarContainer()
+	return schemaexpr.RunFilter(e.filter, e.evalCtx)
+}
+
+// Project evaluates the projected columns against the row. The returned datums
+// are in the same order as ColumnNames.
+func (e *SelectEvaluator) Project(row tree.Datums) (tree.Datums, error) {
+	e.ivars.row = row
+	e.evalCtx.PushIVarContainer(e.ivars)
+	defer e.evalCtx.PopIVarContainer()
+	projected := make(tree.Datums, len(e.exprs))
+	for i, expr := range e.exprs {
+		d, err := expr.Eval(e.evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		projected[i] = d
+	}
+	return projected, nil
+}
+
+func isStar(expr tree.Expr) bool {
+	switch t := expr.(type) {
+	case tree.UnqualifiedStar:
+		return true
+	case *tree.UnresolvedName:
+		return t.Star && t.NumParts == 1
+	}
+	return false
+}
+
+// selectResolver replaces the column references in expressions with
+// IndexedVars over the public columns of a table descriptor.
+type selectResolver struct {
+	source     *colinfo.DataSourceInfo
+	ivars      *selectIVarContainer
+	ivarHelper tree.IndexedVarHelper
+	searchPath sessiondata.SearchPath
+}
+
+func makeSelectResolver(sc *tree.SelectClause, desc catalog.TableDescriptor) selectResolver {
+	cols := desc.PublicColumns()
+	ivars := &selectIVarContainer{cols: cols}
+	return selectResolver{
+		source: colinfo.NewSourceInfoForSingleTable(
+			selectSourceName(sc), colinfo.ResultColumnsFromColumns(desc.GetID(), cols),
+		),
+		ivars:      ivars,
+		ivarHelper: tree.MakeIndexedVarHelper(ivars, len(cols)),
+		searchPath: sessiondata.DefaultSearchPath,
+	}
+}
+
+func (r *selectResolver) resolve(expr tree.Expr) (tree.Expr, error) {
+	var v schemaexpr.NameResolutionVisitor
+	return schemaexpr.ResolveNamesUsingVisitor(&v, expr, r.source, r.ivarHelper, r.searchPath)
+}
+
+// selectIVarContainer is a tree.IndexedVarContainer over the public columns of
+// a table descriptor and the row currently being evaluated.
+type selectIVarContainer struct {
+	cols []catalog.Column
+	row  tree.Datums
+}
+
+var _ tree.IndexedVarContainer = &selectIVarContainer{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *selectIVarContainer) IndexedVarEval(
+	idx int, ctx *tree.EvalContext,
+) (tree.Datum, error) {
+	return c.row[idx], nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *selectIVarContainer) IndexedVarResolvedType(idx int) *types.T {
+	return c.cols[idx].GetType()
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *selectIVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].GetName())
+	return &n
+}
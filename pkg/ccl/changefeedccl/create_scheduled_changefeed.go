@@ -0,0 +1,434 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/jsonpb"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+const scheduleChangefeedOp = "CREATE SCHEDULE FOR CHANGEFEED"
+
+// scheduleManagedChangefeedOptions are the changefeed options which are set
+// by the scheduled changefeed executor for each changefeed it starts, and so
+// cannot be specified in CREATE SCHEDULE FOR CHANGEFEED.
+var scheduleManagedChangefeedOptions = []string{
+	changefeedbase.OptCursor,
+	changefeedbase.OptEndTime,
+	changefeedbase.OptInitialScan,
+	changefeedbase.OptNoInitialScan,
+}
+
+// scheduledChangefeedEval is a representation of tree.ScheduledChangefeed,
+// prepared for evaluation.
+type scheduledChangefeedEval struct {
+	*tree.ScheduledChangefeed
+
+	// Schedule specific properties that get evaluated.
+	scheduleLabel func() (string, error)
+	recurrence    func() (string, error)
+	scheduleOpts  func() (map[string]string, error)
+
+	// Changefeed specific properties that get evaluated, so that the evaluated
+	// changefeed statement is stored in the schedule.
+	sinkURI        func() (string, error)
+	changefeedOpts func() (map[string]string, error)
+}
+
+// doCreateChangefeedSchedule creates the requested changefeed schedule. It is
+// a plan hook implementation responsible for the creation of scheduled
+// changefeeds.
+func doCreateChangefeedSchedule(
+	ctx context.Context,
+	p sql.PlanHookState,
+	eval *scheduledChangefeedEval,
+	resultsCh chan<- tree.Datums,
+) error {
+	if err := p.RequireAdminRole(ctx, scheduleChangefeedOp); err != nil {
+		return err
+	}
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
+
+	recurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.recurrence)
+	if err != nil {
+		return err
+	}
+	if recurrence == schedulebase.NeverRecurs {
+		return errors.AssertionFailedf("changefeed recurrence should be set")
+	}
+
+	sinkURI, err := eval.sinkURI()
+	if err != nil {
+		return errors.Wrapf(err, "failed to evaluate changefeed sink")
+	}
+	if sinkURI == `` {
+		return errors.Errorf("%s requires a sink", scheduleChangefeedOp)
+	}
+	changefeedOpts, err := eval.changefeedOpts()
+	if err != nil {
+		return err
+	}
+	for _, opt := range scheduleManagedChangefeedOptions {
+		if _, ok := changefeedOpts[opt]; ok {
+			return errors.Errorf("%s does not support the %s option", scheduleChangefeedOp, opt)
+		}
+	}
+
+	// The changefeeds of the schedule don't run in the session which created
+	// it, so the targets are stored fully qualified.
+	targets, err := qualifyChangefeedTargets(ctx, p, eval.CreateChangefeed.Targets)
+	if err != nil {
+		return err
+	}
+	changefeedNode := makeChangefeedNode(targets, sinkURI, changefeedOpts)
+
+	// Run the changefeed in dry-run mode. This does all of the sanity checks and
+	// validation we need to make in order to ensure the schedule is sane.
+	if err := dryRunChangefeed(ctx, p, changefeedNode); err != nil {
+		return errors.Wrapf(err, "failed to dry run changefeed")
+	}
+
+	var scheduleLabel string
+	if eval.scheduleLabel != nil {
+		label, err := eval.scheduleLabel()
+		if err != nil {
+			return err
+		}
+		scheduleLabel = label
+	} else {
+		scheduleLabel = fmt.Sprintf("CHANGEFEED %d", env.Now().Unix())
+	}
+
+	scheduleOptions, err := eval.scheduleOpts()
+	if err != nil {
+		return err
+	}
+
+	evalCtx := &p.ExtendedEvalContext().EvalContext
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	sj, err := makeChangefeedSchedule(env, p.User(), scheduleLabel, recurrence, details, changefeedNode)
+	if err != nil {
+		return err
+	}
+	if firstRun != nil {
+		sj.SetNextRun(*firstRun)
+	}
+
+	if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn); err != nil {
+		return err
+	}
+	collectScheduledChangefeedTelemetry(changefeedOpts, firstRun, details)
+	return emitChangefeedSchedule(sj, changefeedNode, sinkURI, changefeedOpts, resultsCh)
+}
+
+// qualifyChangefeedTargets returns the target list with each table replaced by
+// its fully qualified name.
+func qualifyChangefeedTargets(
+	ctx context.Context, p sql.PlanHookState, targets tree.TargetList,
+) (tree.TargetList, error) {
+	if len(targets.Databases) > 0 {
+		return tree.TargetList{}, errors.Errorf(`CHANGEFEED cannot target %s`,
+			tree.AsString(&targets))
+	}
+	statementTime := hlc.Timestamp{
+		WallTime: p.ExtendedEvalContext().GetStmtTimestamp().UnixNano(),
+	}
+	qualified := tree.TargetList{Tables: make(tree.TablePatterns, 0, len(targets.Tables))}
+	for _, t := range targets.Tables {
+		descs, _, err := backupbase.ResolveTargetsToDescriptors(
+			ctx, p, statementTime, &tree.TargetList{Tables: tree.TablePatterns{t}})
+		if err != nil {
+			return tree.TargetList{}, errors.Wrap(err, "failed to resolve targets in the CHANGEFEED stmt")
+		}
+		for _, desc := range descs {
+			if table, isTable := desc.(catalog.TableDescriptor); isTable {
+				tn, err := getQualifiedTableNameObj(ctx, *p.ExecCfg(), p.Txn(), table)
+				if err != nil {
+					return tree.TargetList{}, err
+				}
+				qualified.Tables = append(qualified.Tables, &tn)
+			}
+		}
+	}
+	return qualified, nil
+}
+
+func makeChangefeedSchedule(
+	env scheduledjobs.JobSchedulerEnv,
+	owner security.SQLUsername,
+	label string,
+	recurrence *schedulebase.ScheduleRecurrence,
+	details jobspb.ScheduleDetails,
+	changefeedNode *tree.CreateChangefeed,
+) (*jobs.ScheduledJob, error) {
+	sj := jobs.NewScheduledJob(env)
+	sj.SetScheduleLabel(label)
+	sj.SetOwner(owner)
+
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
+		return nil, err
+	}
+	sj.SetScheduleDetails(details)
+
+	// We do not set the cursor and end time of the changefeed: this is done
+	// when the scheduler kicks off the changefeed.
+	args := &ScheduledChangefeedExecutionArgs{
+		ChangefeedStatement: tree.AsString(changefeedNode),
+	}
+	any, err := pbtypes.MarshalAny(args)
+	if err != nil {
+		return nil, err
+	}
+	sj.SetExecutionDetails(
+		tree.ScheduledChangefeedExecutor.InternalName(),
+		jobspb.ExecutionArguments{Args: any},
+	)
+	return sj, nil
+}
+
+func emitChangefeedSchedule(
+	sj *jobs.ScheduledJob,
+	changefeedNode *tree.CreateChangefeed,
+	sinkURI string,
+	opts map[string]string,
+	resultsCh chan<- tree.Datums,
+) error {
+	status, nextRun, err := schedulebase.ScheduleStatusAndNextRun(sj)
+	if err != nil {
+		return err
+	}
+
+	redacted, err := redactedChangefeedNode(changefeedNode.Targets, changefeedNode.Select, sinkURI, opts)
+	if err != nil {
+		return err
+	}
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(sj.ScheduleID())),
+		tree.NewDString(sj.ScheduleLabel()),
+		tree.NewDString(status),
+		nextRun,
+		tree.NewDString(sj.ScheduleExpr()),
+		tree.NewDString(tree.AsString(redacted)),
+	}
+	return nil
+}
+
+// dryRunChangefeed creates the changefeed job under a transaction savepoint,
+// and then rolls back to that savepoint.
+func dryRunChangefeed(
+	ctx context.Context, p sql.PlanHookState, changefeedNode *tree.CreateChangefeed,
+) error {
+	sp, err := p.ExtendedEvalContext().Txn.CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	err = dryRunInvokeChangefeed(ctx, p, changefeedNode)
+	if rollbackErr := p.ExtendedEvalContext().Txn.RollbackToSavepoint(ctx, sp); rollbackErr != nil {
+		return rollbackErr
+	}
+	return err
+}
+
+func dryRunInvokeChangefeed(
+	ctx context.Context, p sql.PlanHookState, changefeedNode *tree.CreateChangefeed,
+) error {
+	// The changefeed is annotated as created by a schedule so that its job is
+	// created in the transaction, which is rolled back, instead of being
+	// started.
+	changefeedFn, err := planChangefeed(ctx, p, &annotatedChangefeedStatement{
+		CreateChangefeed: changefeedNode,
+		CreatedByInfo: &jobs.CreatedByInfo{
+			Name: jobs.CreatedByScheduledJobs,
+			ID:   jobs.InvalidScheduleID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return invokeChangefeed(ctx, changefeedFn)
+}
+
+// makeScheduledChangefeedEval prepares helper scheduledChangefeedEval struct
+// to assist in evaluation of various schedule and changefeed specific
+// components.
+func makeScheduledChangefeedEval(
+	ctx context.Context, p sql.PlanHookState, schedule *tree.ScheduledChangefeed,
+) (*scheduledChangefeedEval, error) {
+	eval := &scheduledChangefeedEval{ScheduledChangefeed: schedule}
+	var err error
+
+	if schedule.ScheduleLabel != nil {
+		eval.scheduleLabel, err = p.TypeAsString(ctx, schedule.ScheduleLabel, scheduleChangefeedOp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if schedule.Recurrence == nil {
+		// Sanity check: recurrence must be specified.
+		return nil, errors.New("RECURRING clause required")
+	}
+	eval.recurrence, err = p.TypeAsString(ctx, schedule.Recurrence, scheduleChangefeedOp)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.scheduleOpts, err = p.TypeAsStringOpts(
+		ctx, schedule.ScheduleOptions, schedulebase.ScheduleOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.CreateChangefeed.SinkURI == nil {
+		return nil, errors.Errorf("%s requires a sink", scheduleChangefeedOp)
+	}
+	eval.sinkURI, err = p.TypeAsString(ctx, schedule.CreateChangefeed.SinkURI, scheduleChangefeedOp)
+	if err != nil {
+		return nil, err
+	}
+	eval.changefeedOpts, err = p.TypeAsStringOpts(
+		ctx, schedule.CreateChangefeed.Options, changefeedbase.ChangefeedOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+	return eval, nil
+}
+
+// scheduledChangefeedHeader is the header for "CREATE SCHEDULE FOR CHANGEFEED"
+// statements results.
+var scheduledChangefeedHeader = colinfo.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "label", Typ: types.String},
+	{Name: "status", Typ: types.String},
+	{Name: "first_run", Typ: types.TimestampTZ},
+	{Name: "schedule", Typ: types.String},
+	{Name: "changefeed_stmt", Typ: types.String},
+}
+
+func collectScheduledChangefeedTelemetry(
+	opts map[string]string, firstRun *time.Time, details jobspb.ScheduleDetails,
+) {
+	telemetry.Count("scheduled-changefeed.create.success")
+	if _, ok := opts[changefeedbase.OptInitialScanOnly]; ok {
+		telemetry.Count("scheduled-changefeed.initial-scan-only")
+	}
+	if firstRun != nil {
+		telemetry.Count("scheduled-changefeed.first-run-picked")
+	}
+	switch details.Wait {
+	case jobspb.ScheduleDetails_WAIT:
+		telemetry.Count("scheduled-changefeed.wait-policy.wait")
+	case jobspb.ScheduleDetails_NO_WAIT:
+		telemetry.Count("scheduled-changefeed.wait-policy.no-wait")
+	case jobspb.ScheduleDetails_SKIP:
+		telemetry.Count("scheduled-changefeed.wait-policy.skip")
+	}
+	switch details.OnError {
+	case jobspb.ScheduleDetails_RETRY_SCHED:
+		telemetry.Count("scheduled-changefeed.error-policy.retry-schedule")
+	case jobspb.ScheduleDetails_RETRY_SOON:
+		telemetry.Count("scheduled-changefeed.error-policy.retry-soon")
+	case jobspb.ScheduleDetails_PAUSE_SCHED:
+		telemetry.Count("scheduled-changefeed.error-policy.pause-schedule")
+	}
+}
+
+func createChangefeedScheduleHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledChangefeed)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	eval, err := makeScheduledChangefeedEval(ctx, p, schedule)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		if err := doCreateChangefeedSchedule(ctx, p, eval, resultsCh); err != nil {
+			telemetry.Count("scheduled-changefeed.create.failed")
+			return err
+		}
+		return nil
+	}
+	return fn, scheduledChangefeedHeader, nil, false, nil
+}
+
+// MarshalJSONPB provides a custom Marshaller for jsonpb that redacts secrets in
+// the sink URI and options.
+func (m ScheduledChangefeedExecutionArgs) MarshalJSONPB(x *jsonpb.Marshaler) ([]byte, error) {
+	stmt, err := parser.ParseOne(m.ChangefeedStatement)
+	if err != nil {
+		return nil, err
+	}
+	changefeed, ok := stmt.AST.(*tree.CreateChangefeed)
+	if !ok {
+		return nil, errors.Errorf("unexpected %T statement in changefeed schedule: %v", stmt.AST, stmt.AST)
+	}
+
+	raw, ok := changefeed.SinkURI.(*tree.StrVal)
+	if !ok {
+		return nil, errors.Errorf("unexpected %T arg in changefeed schedule: %v", raw, raw)
+	}
+	opts := make(map[string]string, len(changefeed.Options))
+	for _, opt := range changefeed.Options {
+		var v string
+		if opt.Value != nil {
+			raw, ok := opt.Value.(*tree.StrVal)
+			if !ok {
+				return nil, errors.Errorf("unexpected %T arg in changefeed schedule: %v", raw, raw)
+			}
+			v = raw.RawString()
+		}
+		opts[string(opt.Key)] = v
+	}
+	redacted, err := redactedChangefeedNode(changefeed.Targets, changefeed.Select, raw.RawString(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ChangefeedStatement = redacted.String()
+	return json.Marshal(m)
+}
+
+func init() {
+	sql.AddPlanHook(createChangefeedScheduleHook)
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	gosql "database/sql"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobstest"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/jsonpb"
+	pbtypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/require"
+)
+
+// changefeedScheduleTestHelper starts a server, and arranges for job
+// scheduling daemon to use jobstest.JobSchedulerTestEnv. The schedules are
+// executed manually via executeSchedules.
+type changefeedScheduleTestHelper struct {
+	iodir            string
+	server           serverutils.TestServerInterface
+	env              *jobstest.JobSchedulerTestEnv
+	cfg              *scheduledjobs.JobExecutionConfig
+	sqlDB            *sqlutils.SQLRunner
+	executeSchedules func() error
+}
+
+func newChangefeedScheduleTestHelper(t *testing.T) (*changefeedScheduleTestHelper, func()) {
+	dir, dirCleanupFn := testutils.TempDir(t)
+
+	th := &changefeedScheduleTestHelper{
+		env:   jobstest.NewJobSchedulerTestEnv(jobstest.UseSystemTables, timeutil.Now()),
+		iodir: dir,
+	}
+
+	knobs := &jobs.TestingKnobs{
+		JobSchedulerEnv: th.env,
+		TakeOverJobsScheduling: func(
+			fn func(ctx context.Context, maxSchedules int64, txn *kv.Txn) error,
+		) {
+			th.executeSchedules = func() error {
+				defer th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+				return th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+					return fn(ctx, 0 /* allSchedules */, txn)
+				})
+			}
+		},
+		CaptureJobExecutionConfig: func(config *scheduledjobs.JobExecutionConfig) {
+			th.cfg = config
+		},
+	}
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		UseDatabase:   "d",
+		ExternalIODir: dir,
+		Knobs:         base.TestingKnobs{JobsTestingKnobs: knobs},
+	})
+	require.NotNil(t, th.cfg)
+	th.sqlDB = sqlutils.MakeSQLRunner(db)
+	th.server = s
+	th.sqlDB.Exec(t, `CREATE DATABASE d`)
+	th.sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	th.sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+
+	return th, func() {
+		dirCleanupFn()
+		s.Stopper().Stop(context.Background())
+	}
+}
+
+// createChangefeedSchedule executes the specified "CREATE SCHEDULE FOR
+// CHANGEFEED" query and loads the created schedule.
+func (h *changefeedScheduleTestHelper) createChangefeedSchedule(
+	t *testing.T, query string, args ...interface{},
+) *jobs.ScheduledJob {
+	var id int64
+	var unusedStr string
+	var unusedTS *time.Time
+	h.sqlDB.QueryRow(t, query, args...).Scan(
+		&id, &unusedStr, &unusedStr, &unusedTS, &unusedStr, &unusedStr)
+	return h.loadSchedule(t, id)
+}
+
+func (h *changefeedScheduleTestHelper) loadSchedule(t *testing.T, id int64) *jobs.ScheduledJob {
+	datums, cols, err := h.cfg.InternalExecutor.QueryRowExWithCols(
+		context.Background(), "sched-load", nil,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"SELECT * FROM system.scheduled_jobs WHERE schedule_id = $1",
+		id,
+	)
+	require.NoError(t, err)
+	require.NotNil(t, datums)
+
+	sj := jobs.NewScheduledJob(h.env)
+	require.NoError(t, sj.InitFromDatums(datums, cols))
+	return sj
+}
+
+// runSchedule executes the schedule and waits until it has started the
+// specified number of changefeeds which succeeded.
+func (h *changefeedScheduleTestHelper) runSchedule(
+	t *testing.T, sj *jobs.ScheduledJob, numJobs int,
+) {
+	h.env.SetTime(sj.NextRun().Add(time.Second))
+	require.NoError(t, h.executeSchedules())
+
+	testutils.SucceedsSoon(t, func() error {
+		h.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+		var succeeded int
+		h.sqlDB.QueryRow(t, "SELECT count(*) FROM "+h.env.SystemJobsTableName()+
+			" WHERE status=$1 AND created_by_type=$2 AND created_by_id=$3",
+			jobs.StatusSucceeded, jobs.CreatedByScheduledJobs, sj.ScheduleID()).Scan(&succeeded)
+		if succeeded != numJobs {
+			return errors.Newf("expected %d successful changefeeds, found %d", numJobs, succeeded)
+		}
+		return nil
+	})
+}
+
+var changefeedRowRe = regexp.MustCompile(`"after": \{"a": (\d+)`)
+
+// readChangefeedRows returns the primary keys of all the rows emitted to the
+// cloud storage sink in the specified directory, sorted.
+func readChangefeedRows(t *testing.T, dir string) []int {
+	var keys []int
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range changefeedRowRe.FindAllStringSubmatch(string(contents), -1) {
+			k, err := strconv.Atoi(m[1])
+			if err != nil {
+				return err
+			}
+			keys = append(keys, k)
+		}
+		return nil
+	}))
+	sort.Ints(keys)
+	return keys
+}
+
+func TestScheduledChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newChangefeedScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY)`)
+	th.sqlDB.Exec(t, `INSERT INTO t VALUES (1), (2)`)
+
+	sj := th.createChangefeedSchedule(t, `
+CREATE SCHEDULE 'my changefeed' FOR CHANGEFEED TABLE t INTO 'experimental-nodelocal://0/feed'
+RECURRING '@hourly'`)
+	require.Equal(t, "my changefeed", sj.ScheduleLabel())
+	require.Equal(t, tree.ScheduledChangefeedExecutor.InternalName(), sj.ExecutorType())
+
+	args := &ScheduledChangefeedExecutionArgs{}
+	require.NoError(t, pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args))
+	require.Equal(t,
+		`CREATE CHANGEFEED FOR TABLE d.public.t INTO 'experimental-nodelocal://0/feed'`,
+		args.ChangefeedStatement)
+	require.True(t, args.LastEndTime.IsEmpty())
+
+	th.sqlDB.CheckQueryResults(t,
+		`SELECT label, command FROM [SHOW SCHEDULES FOR CHANGEFEED]`,
+		[][]string{{"my changefeed",
+			`CREATE CHANGEFEED FOR TABLE d.public.t INTO 'experimental-nodelocal://0/feed'`}})
+
+	// The first changefeed emits a snapshot of the table.
+	th.runSchedule(t, sj, 1)
+	require.Equal(t, []int{1, 2}, readChangefeedRows(t, filepath.Join(th.iodir, "feed")))
+
+	sj = th.loadSchedule(t, sj.ScheduleID())
+	require.NoError(t, pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args))
+	require.False(t, args.LastEndTime.IsEmpty())
+
+	// The next changefeed emits the changes since the snapshot.
+	th.sqlDB.Exec(t, `INSERT INTO t VALUES (3)`)
+	th.runSchedule(t, sj, 2)
+	require.Equal(t, []int{1, 2, 3}, readChangefeedRows(t, filepath.Join(th.iodir, "feed")))
+}
+
+func TestScheduledChangefeedInitialScanOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newChangefeedScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY)`)
+	th.sqlDB.Exec(t, `INSERT INTO t VALUES (1), (2)`)
+
+	sj := th.createChangefeedSchedule(t, `
+CREATE SCHEDULE FOR CHANGEFEED TABLE t INTO 'experimental-nodelocal://0/feed' WITH initial_scan_only
+RECURRING '@hourly'`)
+
+	// Each changefeed emits a snapshot of the table.
+	th.runSchedule(t, sj, 1)
+	require.Equal(t, []int{1, 2}, readChangefeedRows(t, filepath.Join(th.iodir, "feed")))
+
+	th.sqlDB.Exec(t, `INSERT INTO t VALUES (3)`)
+	sj = th.loadSchedule(t, sj.ScheduleID())
+	th.runSchedule(t, sj, 2)
+	require.Equal(t, []int{1, 1, 2, 2, 3}, readChangefeedRows(t, filepath.Join(th.iodir, "feed")))
+}
+
+func TestCreateChangefeedScheduleErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newChangefeedScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY)`)
+
+	for _, opt := range []string{
+		`cursor = '1'`, `end_time = '1'`, `initial_scan`, `no_initial_scan`,
+	} {
+		th.sqlDB.ExpectErr(t, "does not support the .* option",
+			`CREATE SCHEDULE FOR CHANGEFEED TABLE t INTO 'experimental-nodelocal://0/feed' WITH `+opt+
+				` RECURRING '@hourly'`)
+	}
+	th.sqlDB.ExpectErr(t, `failed to resolve targets`,
+		`CREATE SCHEDULE FOR CHANGEFEED TABLE missing INTO 'experimental-nodelocal://0/feed' RECURRING '@hourly'`)
+
+	th.sqlDB.Exec(t, `CREATE USER testuser`)
+	th.sqlDB.Exec(t, `GRANT SELECT ON t TO testuser`)
+	pgURL, cleanupFunc := sqlutils.PGUrl(
+		t, th.server.ServingSQLAddr(), "TestCreateChangefeedScheduleErrors-testuser",
+		url.User("testuser"),
+	)
+	defer cleanupFunc()
+	pgURL.Path = "d"
+	testuser, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, testuser.Close()) }()
+
+	_, err = testuser.Exec(
+		`CREATE SCHEDULE FOR CHANGEFEED TABLE t INTO 'experimental-nodelocal://0/feed' RECURRING '@hourly'`)
+	require.Error(t, err)
+	require.Regexp(t, "only users with the admin role", err)
+}
+
+func TestScheduledChangefeedRedactsSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	args := &ScheduledChangefeedExecutionArgs{
+		ChangefeedStatement: `CREATE CHANGEFEED FOR TABLE d.public.t ` +
+			`INTO 's3://bucket/path?AWS_ACCESS_KEY_ID=key&AWS_SECRET_ACCESS_KEY=secret'`,
+	}
+	json, err := (&jsonpb.Marshaler{}).MarshalToString(args)
+	require.NoError(t, err)
+	require.False(t, strings.Contains(json, "secret"), json)
+	require.True(t, strings.Contains(json, "AWS_SECRET_ACCESS_KEY=redacted"), json)
+}
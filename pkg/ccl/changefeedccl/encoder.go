@@ -62,6 +62,19 @@ type encodeRow struct {
 	// prevTableDesc is a TableDescriptor for the table containing `prevDatums`.
 	// It's valid for interpreting the row at `updated.Prev()`.
 	prevTableDesc catalog.TableDescriptor
+	// projection is the result of evaluating the SELECT of a CREATE CHANGEFEED
+	// ... AS SELECT against `datums`. When set, encoders emit it in place of
+	// the table's columns.
+	projection *projectedRow
+	// prevProjection is the result of evaluating the SELECT of a CREATE
+	// CHANGEFEED ... AS SELECT against `prevDatums`.
+	prevProjection *projectedRow
+}
+
+// projectedRow holds the columns selected by a CREATE CHANGEFEED ... AS SELECT.
+type projectedRow struct {
+	names  []string
+	datums tree.Datums
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -172,7 +185,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var after map[string]interface{}
-	if !row.deleted {
+	if !row.deleted && row.projection != nil {
+		var err error
+		if after, err = encodeProjectionJSON(row.projection); err != nil {
+			return nil, err
+		}
+	} else if !row.deleted {
 		columns := row.tableDesc.PublicColumns()
 		after = make(map[string]interface{}, len(columns))
 		for i, col := range columns {
@@ -189,7 +207,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var before map[string]interface{}
-	if row.prevDatums != nil && !row.prevDeleted {
+	if row.prevProjection != nil && !row.prevDeleted {
+		var err error
+		if before, err = encodeProjectionJSON(row.prevProjection); err != nil {
+			return nil, err
+		}
+	} else if row.prevDatums != nil && !row.prevDeleted {
 		columns := row.prevTableDesc.PublicColumns()
 		before = make(map[string]interface{}, len(columns))
 		for i, col := range columns {
@@ -250,6 +273,19 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	return e.buf.Bytes(), nil
 }
 
+// encodeProjectionJSON maps the names of the projected columns to their values.
+func encodeProjectionJSON(p *projectedRow) (map[string]interface{}, error) {
+	entries := make(map[string]interface{}, len(p.names))
+	for i, name := range p.names {
+		var err error
+		entries[name], err = tree.AsJSON(p.datums[i], time.UTC)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(
 	_ context.Context, _ string, resolved hlc.Timestamp,
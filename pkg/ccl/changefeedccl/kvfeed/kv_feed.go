@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -51,6 +52,10 @@ type Config struct {
 	SchemaChangeEvents changefeedbase.SchemaChangeEventClass
 	SchemaChangePolicy changefeedbase.SchemaChangePolicy
 
+	// Select is the SELECT of a CREATE CHANGEFEED ... AS SELECT, if any. It's
+	// handed to the schemafeed to validate new versions of the watched table.
+	Select *tree.SelectClause
+
 	// If true, the feed will begin with a dump of data at exactly the
 	// InitialHighWater. This is a peculiar behavior. In general the
 	// InitialHighWater is a point in time at which all data is known to have
@@ -60,6 +65,11 @@ type Config struct {
 	// InitialHighWater is the timestamp from which new events are guaranteed to
 	// be produced.
 	InitialHighWater hlc.Timestamp
+
+	// EndTime, if set, is the timestamp at which the feed stops. The events up
+	// to and including EndTime are produced, after which all the spans are
+	// resolved at EndTime as a boundary.
+	EndTime hlc.Timestamp
 }
 
 // Run will run the kvfeed. The feed runs synchronously and returns an
@@ -95,7 +105,7 @@ func Run(ctx context.Context, cfg Config) error {
 		cfg.Sink, cfg.Spans,
 		cfg.SchemaChangeEvents, cfg.SchemaChangePolicy,
 		cfg.NeedsInitialScan, cfg.WithDiff,
-		cfg.InitialHighWater,
+		cfg.InitialHighWater, cfg.EndTime,
 		cfg.Codec,
 		sf, sc, pff, bf)
 	g.GoCtx(f.run)
@@ -109,6 +119,12 @@ func Run(ctx context.Context, cfg Config) error {
 		<-ctx.Done()
 		err = nil
 	}
+	var etErr endTimeReachedError
+	if errors.As(err, &etErr) {
+		log.Infof(ctx, "stopping changefeed at end time %v", etErr.ts)
+		<-ctx.Done()
+		err = nil
+	}
 	return err
 }
 
@@ -123,6 +139,17 @@ func (e schemaChangeDetectedError) Error() string {
 	return fmt.Sprintf("schema change deteceted at %v", e.ts)
 }
 
+// endTimeReachedError is a sentinel error to indicate to Run() that the feed
+// has resolved all the spans at its end time. Like schemaChangeDetectedError,
+// it is handled entirely in this package.
+type endTimeReachedError struct {
+	ts hlc.Timestamp
+}
+
+func (e endTimeReachedError) Error() string {
+	return fmt.Sprintf("end time reached at %v", e.ts)
+}
+
 type schemaFeed interface {
 	Peek(ctx context.Context, atOrBefore hlc.Timestamp) (events []schemafeed.TableEvent, err error)
 	Pop(ctx context.Context, atOrBefore hlc.Timestamp) (events []schemafeed.TableEvent, err error)
@@ -133,6 +160,7 @@ type kvFeed struct {
 	withDiff            bool
 	withInitialBackfill bool
 	initialHighWater    hlc.Timestamp
+	endTime             hlc.Timestamp
 	sink                EventBufferWriter
 	codec               keys.SQLCodec
 
@@ -152,7 +180,7 @@ func newKVFeed(
 	schemaChangeEvents changefeedbase.SchemaChangeEventClass,
 	schemaChangePolicy changefeedbase.SchemaChangePolicy,
 	withInitialBackfill, withDiff bool,
-	initialHighWater hlc.Timestamp,
+	initialHighWater, endTime hlc.Timestamp,
 	codec keys.SQLCodec,
 	tf schemaFeed,
 	sc kvScanner,
@@ -165,6 +193,7 @@ func newKVFeed(
 		withInitialBackfill: withInitialBackfill,
 		withDiff:            withDiff,
 		initialHighWater:    initialHighWater,
+		endTime:             endTime,
 		schemaChangeEvents:  schemaChangeEvents,
 		schemaChangePolicy:  schemaChangePolicy,
 		codec:               codec,
@@ -184,10 +213,16 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 		if err = f.scanIfShould(ctx, initialScan, highWater); err != nil {
 			return err
 		}
+		if f.endTimeReached(highWater) {
+			return f.resolveAtEndTime(ctx, highWater)
+		}
 		highWater, err = f.runUntilTableEvent(ctx, highWater)
 		if err != nil {
 			return err
 		}
+		if f.endTimeReached(highWater) {
+			return f.resolveAtEndTime(ctx, highWater)
+		}
 
 		// Resolve all of the spans as a boundary if the policy indicates that
 		// we should do so.
@@ -205,6 +240,24 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 	}
 }
 
+// endTimeReached returns true if the feed has an end time and all the events
+// up to it have been produced.
+func (f *kvFeed) endTimeReached(highWater hlc.Timestamp) bool {
+	return !f.endTime.IsEmpty() && f.endTime.LessEq(highWater)
+}
+
+// resolveAtEndTime resolves all of the spans at highWater as a boundary, which
+// lets the higher layers of the changefeed flush and stop, and returns the
+// endTimeReachedError sentinel.
+func (f *kvFeed) resolveAtEndTime(ctx context.Context, highWater hlc.Timestamp) error {
+	for _, span := range f.spans {
+		if err := f.sink.AddResolved(ctx, span, highWater, true); err != nil {
+			return err
+		}
+	}
+	return endTimeReachedError{highWater}
+}
+
 func (f *kvFeed) scanIfShould(
 	ctx context.Context, initialScan bool, highWater hlc.Timestamp,
 ) error {
@@ -280,7 +333,7 @@ func (f *kvFeed) runUntilTableEvent(
 	g := ctxgroup.WithContext(ctx)
 	physicalCfg := physicalConfig{Spans: f.spans, Timestamp: startFrom, WithDiff: f.withDiff}
 	g.GoCtx(func(ctx context.Context) error {
-		return copyFromSourceToSinkUntilTableEvent(ctx, f.sink, memBuf, physicalCfg, f.tableFeed)
+		return copyFromSourceToSinkUntilTableEvent(ctx, f.sink, memBuf, physicalCfg, f.tableFeed, f.endTime)
 	})
 	g.GoCtx(func(ctx context.Context) error {
 		return f.physicalFeed.Run(ctx, memBuf, physicalCfg)
@@ -299,6 +352,8 @@ func (f *kvFeed) runUntilTableEvent(
 		// We'll need to do this to ensure that a resolved timestamp propagates
 		// when we're trying to exit.
 		return tErr.Timestamp().Prev(), nil
+	} else if etErr := (*errEndTimeBoundaryReached)(nil); errors.As(err, &etErr) {
+		return etErr.endTime, nil
 	} else {
 		return hlc.Timestamp{}, err
 	}
@@ -312,17 +367,30 @@ func (e *errBoundaryReached) Error() string {
 	return "scan boundary reached: " + e.String()
 }
 
+// errEndTimeBoundaryReached is returned by copyFromSourceToSinkUntilTableEvent
+// when all the spans have been resolved up to the end time of the feed.
+type errEndTimeBoundaryReached struct {
+	endTime hlc.Timestamp
+}
+
+func (e *errEndTimeBoundaryReached) Error() string {
+	return fmt.Sprintf("end time boundary reached: %v", e.endTime)
+}
+
 // copyFromSourceToSinkUntilTableEvents will pull read entries from source and
 // publish them to sink if there is no table event from the schemaFeed. If a
 // tableEvent occurs then the function will return once all of the spans have
 // been resolved up to the event. The first such event will be returned as
-// *errBoundaryReached. A nil error will never be returned.
+// *errBoundaryReached. If endTime is set and no table event occurs at or
+// before it, the function returns *errEndTimeBoundaryReached once all of the
+// spans have been resolved up to endTime. A nil error will never be returned.
 func copyFromSourceToSinkUntilTableEvent(
 	ctx context.Context,
 	sink EventBufferWriter,
 	source EventBufferReader,
 	cfg physicalConfig,
 	tables schemaFeed,
+	endTime hlc.Timestamp,
 ) error {
 	// Maintain a local spanfrontier to tell when all the component rangefeeds
 	// being watched have reached the Scan boundary.
@@ -331,9 +399,14 @@ func copyFromSourceToSinkUntilTableEvent(
 		frontier.Forward(span, cfg.Timestamp)
 	}
 	var (
-		scanBoundary         *errBoundaryReached
+		// scanBoundary is returned once all of the spans have been resolved up
+		// to boundaryTimestamp, the first timestamp at which events are no
+		// longer published. It's either the first table event or the end time.
+		scanBoundary         error
+		boundaryTimestamp    hlc.Timestamp
+		sawTableEvent        bool
 		checkForScanBoundary = func(ts hlc.Timestamp) error {
-			if scanBoundary != nil {
+			if sawTableEvent {
 				return nil
 			}
 			nextEvents, err := tables.Peek(ctx, ts)
@@ -341,7 +414,11 @@ func copyFromSourceToSinkUntilTableEvent(
 				return err
 			}
 			if len(nextEvents) > 0 {
-				scanBoundary = &errBoundaryReached{nextEvents[0]}
+				sawTableEvent = true
+				if scanBoundary == nil || nextEvents[0].Timestamp().Less(boundaryTimestamp) {
+					scanBoundary = &errBoundaryReached{nextEvents[0]}
+					boundaryTimestamp = nextEvents[0].Timestamp()
+				}
 			}
 			return nil
 		}
@@ -349,14 +426,14 @@ func copyFromSourceToSinkUntilTableEvent(
 			if scanBoundary == nil {
 				return false, false
 			}
-			if e.Timestamp().Less(scanBoundary.Timestamp()) {
+			if e.Timestamp().Less(boundaryTimestamp) {
 				return false, false
 			}
 			switch e.Type() {
 			case KVEvent:
 				return true, false
 			case ResolvedEvent:
-				boundaryResolvedTimestamp := scanBoundary.Timestamp().Prev()
+				boundaryResolvedTimestamp := boundaryTimestamp.Prev()
 				resolved := e.Resolved()
 				if resolved.Timestamp.LessEq(boundaryResolvedTimestamp) {
 					return false, false
@@ -386,6 +463,10 @@ func copyFromSourceToSinkUntilTableEvent(
 			}
 		}
 	)
+	if !endTime.IsEmpty() {
+		scanBoundary = &errEndTimeBoundaryReached{endTime}
+		boundaryTimestamp = endTime.Next()
+	}
 	for {
 		e, err := source.Get(ctx)
 		if err != nil {
@@ -418,5 +499,6 @@ func makeTablefeedConfig(cfg Config) schemafeed.Config {
 		LeaseManager:       cfg.LeaseMgr,
 		SchemaChangeEvents: cfg.SchemaChangeEvents,
 		InitialHighWater:   cfg.InitialHighWater,
+		Select:             cfg.Select,
 	}
 }
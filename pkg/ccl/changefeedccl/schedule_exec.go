@@ -0,0 +1,261 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+// scheduledChangefeedExecutor starts a changefeed for each execution of a
+// changefeed schedule. Each changefeed stops at the time the schedule was
+// supposed to run, and starts from the end time of the previous successful
+// changefeed, so that the changefeeds together emit all the changes to the
+// targets. The first changefeed emits a snapshot of the targets instead. If
+// the schedule was created with the initial_scan_only option, each changefeed
+// emits a snapshot of the targets.
+type scheduledChangefeedExecutor struct {
+	metrics changefeedScheduleMetrics
+}
+
+type changefeedScheduleMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &changefeedScheduleMetrics{}
+
+// MetricStruct implements metric.Struct interface
+func (m *changefeedScheduleMetrics) MetricStruct() {}
+
+var _ jobs.ScheduledJobExecutor = &scheduledChangefeedExecutor{}
+
+// ExecuteJob implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledChangefeedExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := e.executeChangefeed(ctx, cfg, sj, txn); err != nil {
+		e.metrics.NumFailed.Inc(1)
+		return err
+	}
+	e.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (e *scheduledChangefeedExecutor) executeChangefeed(
+	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+) error {
+	args, changefeedStmt, err := extractChangefeedStatement(sj)
+	if err != nil {
+		return err
+	}
+
+	// Sanity check: make sure the schedule is not paused so that we don't set
+	// the end time to 0 (this shouldn't happen since job scheduler ignores
+	// paused schedules).
+	if sj.IsPaused() {
+		return errors.New("scheduled unexpectedly paused")
+	}
+
+	if !hasOption(changefeedStmt.Options, changefeedbase.OptInitialScanOnly) {
+		if args.LastEndTime.IsEmpty() {
+			// None of the changefeeds of the schedule succeeded yet: emit a
+			// snapshot of the targets, which the next changefeeds emit the changes
+			// to.
+			changefeedStmt.Options = append(changefeedStmt.Options,
+				tree.KVOption{Key: changefeedbase.OptInitialScanOnly})
+		} else {
+			// Emit the changes since the end of the last successful changefeed, up
+			// to the time this schedule was supposed to have run, but not later
+			// than now, so that the changefeed doesn't wait for future changes.
+			endTime := hlc.Timestamp{WallTime: sj.ScheduledRunTime().UnixNano()}
+			endTime.Backward(txn.ReadTimestamp())
+			endTime.Forward(args.LastEndTime)
+			changefeedStmt.Options = append(changefeedStmt.Options,
+				tree.KVOption{
+					Key:   changefeedbase.OptCursor,
+					Value: tree.NewDString(args.LastEndTime.AsOfSystemTime()),
+				},
+				tree.KVOption{
+					Key:   changefeedbase.OptEndTime,
+					Value: tree.NewDString(endTime.AsOfSystemTime()),
+				})
+		}
+	}
+
+	log.Infof(ctx, "Starting scheduled changefeed %d: %s",
+		sj.ScheduleID(), tree.AsString(changefeedStmt))
+
+	// Invoke changefeed plan hook.
+	hook, cleanup := cfg.PlanHookMaker("exec-changefeed", txn, sj.Owner())
+	defer cleanup()
+	changefeedFn, err := planChangefeed(ctx, hook.(sql.PlanHookState), changefeedStmt)
+	if err != nil {
+		return err
+	}
+	return invokeChangefeed(ctx, changefeedFn)
+}
+
+func hasOption(opts tree.KVOptions, opt string) bool {
+	for _, o := range opts {
+		if string(o.Key) == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func invokeChangefeed(ctx context.Context, changefeedFn sql.PlanHookRowFn) error {
+	resultCh := make(chan tree.Datums) // No need to close
+	g := ctxgroup.WithContext(ctx)
+
+	g.GoCtx(func(ctx context.Context) error {
+		select {
+		case <-resultCh:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	g.GoCtx(func(ctx context.Context) error {
+		return changefeedFn(ctx, nil, resultCh)
+	})
+
+	return g.Wait()
+}
+
+func planChangefeed(
+	ctx context.Context, p sql.PlanHookState, changefeedStmt *annotatedChangefeedStatement,
+) (sql.PlanHookRowFn, error) {
+	fn, cols, _, _, err := changefeedPlanHook(ctx, changefeedStmt, p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "changefeed eval: %q", tree.AsString(changefeedStmt))
+	}
+	if fn == nil {
+		return nil, errors.Newf("changefeed eval: %q", tree.AsString(changefeedStmt))
+	}
+	if len(cols) != 1 {
+		return nil, errors.Newf("unexpected result columns")
+	}
+	return fn, nil
+}
+
+// NotifyJobTermination implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledChangefeedExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID int64,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	schedule *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		e.metrics.NumSucceeded.Inc(1)
+		log.Infof(ctx, "changefeed job %d scheduled by %d succeeded", jobID, schedule.ScheduleID())
+		return e.changefeedSucceeded(schedule, details)
+	}
+
+	e.metrics.NumFailed.Inc(1)
+	err := errors.Errorf(
+		"changefeed job %d scheduled by %d failed with status %s",
+		jobID, schedule.ScheduleID(), jobStatus)
+	log.Errorf(ctx, "changefeed error: %v", err)
+	jobs.DefaultHandleFailedRun(schedule, "changefeed job %d failed with err=%v", jobID, err)
+	return nil
+}
+
+// changefeedSucceeded records the end time of the changefeed, which the next
+// changefeed of the schedule starts from.
+func (e *scheduledChangefeedExecutor) changefeedSucceeded(
+	schedule *jobs.ScheduledJob, details jobspb.Details,
+) error {
+	args := &ScheduledChangefeedExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(schedule.ExecutionArgs().Args, args); err != nil {
+		return errors.Wrap(err, "un-marshaling args")
+	}
+	if !args.LastEndTime.Forward(details.(jobspb.ChangefeedDetails).EndTime) {
+		return nil
+	}
+
+	// Caller updates schedule.
+	any, err := pbtypes.MarshalAny(args)
+	if err != nil {
+		return errors.Wrap(err, "marshaling args")
+	}
+	schedule.SetExecutionDetails(
+		schedule.ExecutorType(),
+		jobspb.ExecutionArguments{Args: any},
+	)
+	return nil
+}
+
+// Metrics implements ScheduledJobExecutor interface
+func (e *scheduledChangefeedExecutor) Metrics() metric.Struct {
+	return &e.metrics
+}
+
+// extractChangefeedStatement returns the arguments of the scheduled job and
+// the tree.CreateChangefeed node they encode.
+func extractChangefeedStatement(
+	sj *jobs.ScheduledJob,
+) (*ScheduledChangefeedExecutionArgs, *annotatedChangefeedStatement, error) {
+	args := &ScheduledChangefeedExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return nil, nil, errors.Wrap(err, "un-marshaling args")
+	}
+
+	node, err := parser.ParseOne(args.ChangefeedStatement)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing changefeed statement")
+	}
+
+	if changefeedStmt, ok := node.AST.(*tree.CreateChangefeed); ok {
+		return args, &annotatedChangefeedStatement{
+			CreateChangefeed: changefeedStmt,
+			CreatedByInfo: &jobs.CreatedByInfo{
+				Name: jobs.CreatedByScheduledJobs,
+				ID:   sj.ScheduleID(),
+			},
+		}, nil
+	}
+
+	return nil, nil, errors.Newf("unexpect node type %T", node)
+}
+
+func init() {
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledChangefeedExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledChangefeedExecutor.UserName())
+			return &scheduledChangefeedExecutor{
+				metrics: changefeedScheduleMetrics{ExecutorMetrics: &m},
+			}, nil
+		})
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -77,6 +78,10 @@ type Config struct {
 	// Should there be another function to decide whether to update the
 	// lease manager?
 	LeaseManager *lease.Manager
+
+	// Select is the SELECT of a CREATE CHANGEFEED ... AS SELECT, if any. Every
+	// version of the watched table must still have the columns it references.
+	Select *tree.SelectClause
 }
 
 // SchemaFeed tracks changes to a set of tables and exports them as a queue of
@@ -96,6 +101,7 @@ type SchemaFeed struct {
 	settings *cluster.Settings
 	targets  jobspb.ChangefeedTargets
 	leaseMgr *lease.Manager
+	sel      *tree.SelectClause
 	mu       struct {
 		syncutil.Mutex
 
@@ -198,6 +204,7 @@ func New(cfg Config) *SchemaFeed {
 		settings: cfg.Settings,
 		targets:  cfg.Targets,
 		leaseMgr: cfg.LeaseManager,
+		sel:      cfg.Select,
 	}
 	m.mu.previousTableVersion = make(map[descpb.ID]catalog.TableDescriptor)
 	m.mu.highWater = cfg.InitialHighWater
@@ -491,6 +498,12 @@ func (tf *SchemaFeed) validateDescriptor(
 		if err := changefeedbase.ValidateTable(tf.targets, desc); err != nil {
 			return err
 		}
+		if tf.sel != nil {
+			if err := changefeedbase.ValidateSelectColumns(tf.sel, desc); err != nil {
+				return errors.Wrapf(err, `CHANGEFEED AS SELECT is no longer valid for %s`,
+					desc.GetName())
+			}
+		}
 		log.Infof(ctx, "validate %v", formatDesc(desc))
 		if lastVersion, ok := tf.mu.previousTableVersion[desc.GetID()]; ok {
 			// NB: Writes can occur to a table
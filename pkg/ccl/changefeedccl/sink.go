@@ -200,6 +200,17 @@ func getSink(
 				opts, timestampOracle, makeExternalStorageFromURI, user,
 			)
 		}
+	case isWebhookSink(u):
+		cfg, err := getWebhookSinkConfig(q)
+		if err != nil {
+			return nil, err
+		}
+		// Every query parameter configures the sink itself (unknown ones are
+		// rejected below), so none of them are forwarded to the endpoint.
+		u.RawQuery = ``
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(u, cfg, opts)
+		}
 	case u.Scheme == changefeedbase.SinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
 		// expects.
@@ -0,0 +1,452 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	applicationTypeJSON = `application/json`
+	authorizationHeader = `Authorization`
+
+	defaultWebhookBatchSize     = 1
+	defaultWebhookFlushInterval = time.Second
+	defaultWebhookRetryMax      = 3
+	defaultWebhookRetryBackoff  = 500 * time.Millisecond
+	defaultWebhookClientTimeout = 3 * time.Second
+)
+
+func isWebhookSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeWebhookHTTPS
+}
+
+// webhookSinkConfig holds the batching and retry configuration of a
+// webhookSink, as parsed from the sink URI.
+type webhookSinkConfig struct {
+	// batchSize is the number of rows buffered before a POST is issued.
+	batchSize int
+	// flushInterval bounds how long a row may sit in the buffer before it is
+	// POSTed, even if the batch is not full.
+	flushInterval time.Duration
+	// retryMax is the number of times a failed POST is retried.
+	retryMax int
+	// retryBackoff is the initial backoff between retries. It doubles on every
+	// subsequent attempt.
+	retryBackoff time.Duration
+
+	caCert        []byte
+	clientCert    []byte
+	clientKey     []byte
+	tlsSkipVerify bool
+}
+
+// webhookSinkPayload is the JSON body of a POST containing row updates. Each
+// element of Payload is the value produced by the changefeed's encoder,
+// embedded verbatim.
+type webhookSinkPayload struct {
+	Payload []json.RawMessage `json:"payload"`
+	Length  int               `json:"length"`
+}
+
+// webhookSink emits to an HTTPS endpoint. Row updates are buffered and POSTed
+// as a JSON array once either the configured batch size is reached or the
+// flush interval elapses; resolved timestamps are POSTed immediately, one per
+// request. It is not concurrency-safe; all calls to Emit and Flush should be
+// from the same goroutine.
+type webhookSink struct {
+	url        url.URL
+	authHeader string
+	cfg        webhookSinkConfig
+	client     *httputil.Client
+
+	stopWorkerCh chan struct{}
+	worker       sync.WaitGroup
+
+	// sendMu serializes the POSTs issued by the client goroutine and the
+	// worker goroutine so that batches are delivered in the order they were
+	// buffered. It is always acquired before mu.
+	sendMu syncutil.Mutex
+
+	// Only synchronized between the client goroutine and the worker goroutine.
+	mu struct {
+		syncutil.Mutex
+		buf      []json.RawMessage
+		flushErr error
+	}
+}
+
+func getWebhookSinkConfig(q url.Values) (webhookSinkConfig, error) {
+	cfg := webhookSinkConfig{
+		batchSize:     defaultWebhookBatchSize,
+		flushInterval: defaultWebhookFlushInterval,
+		retryMax:      defaultWebhookRetryMax,
+		retryBackoff:  defaultWebhookRetryBackoff,
+	}
+
+	parsePositiveInt := func(param string, dest *int) error {
+		if v := q.Get(param); v != `` {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return errors.Errorf(`param %s must be an integer: %s`, param, err)
+			}
+			if i < 0 {
+				return errors.Errorf(`param %s must not be negative: %d`, param, i)
+			}
+			*dest = i
+		}
+		q.Del(param)
+		return nil
+	}
+	parseDuration := func(param string, dest *time.Duration) error {
+		if v := q.Get(param); v != `` {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return pgerror.Wrapf(err, pgcode.InvalidParameterValue, `param %s must be a duration`, param)
+			}
+			if d <= 0 {
+				return errors.Errorf(`param %s must be a positive duration: %s`, param, v)
+			}
+			*dest = d
+		}
+		q.Del(param)
+		return nil
+	}
+	parseBase64 := func(param string, dest *[]byte) error {
+		if v := q.Get(param); v != `` {
+			var err error
+			if *dest, err = base64.StdEncoding.DecodeString(v); err != nil {
+				return errors.Errorf(`param %s must be base 64 encoded: %s`, param, err)
+			}
+		}
+		q.Del(param)
+		return nil
+	}
+
+	if err := parsePositiveInt(changefeedbase.SinkParamWebhookBatchSize, &cfg.batchSize); err != nil {
+		return cfg, err
+	}
+	if cfg.batchSize == 0 {
+		return cfg, errors.Errorf(`param %s must be at least 1`, changefeedbase.SinkParamWebhookBatchSize)
+	}
+	if err := parseDuration(changefeedbase.SinkParamWebhookFlushFreq, &cfg.flushInterval); err != nil {
+		return cfg, err
+	}
+	if err := parsePositiveInt(changefeedbase.SinkParamWebhookRetryMax, &cfg.retryMax); err != nil {
+		return cfg, err
+	}
+	if err := parseDuration(changefeedbase.SinkParamWebhookBackoff, &cfg.retryBackoff); err != nil {
+		return cfg, err
+	}
+	if err := parseBase64(changefeedbase.SinkParamCACert, &cfg.caCert); err != nil {
+		return cfg, err
+	}
+	if err := parseBase64(changefeedbase.SinkParamClientCert, &cfg.clientCert); err != nil {
+		return cfg, err
+	}
+	if err := parseBase64(changefeedbase.SinkParamClientKey, &cfg.clientKey); err != nil {
+		return cfg, err
+	}
+	if v := q.Get(changefeedbase.SinkParamSkipTLSVerify); v != `` {
+		var err error
+		if cfg.tlsSkipVerify, err = strconv.ParseBool(v); err != nil {
+			return cfg, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamSkipTLSVerify, err)
+		}
+	}
+	q.Del(changefeedbase.SinkParamSkipTLSVerify)
+	return cfg, nil
+}
+
+func makeWebhookSink(
+	u *url.URL, cfg webhookSinkConfig, opts map[string]string,
+) (*webhookSink, error) {
+	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
+	case changefeedbase.OptFormatJSON:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
+	}
+
+	switch changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) {
+	case changefeedbase.OptEnvelopeWrapped:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope])
+	}
+
+	if _, ok := opts[changefeedbase.OptKeyInValue]; !ok {
+		return nil, errors.Errorf(`this sink requires the WITH %s option`, changefeedbase.OptKeyInValue)
+	}
+
+	timeout := defaultWebhookClientTimeout
+	if v, ok := opts[changefeedbase.OptWebhookClientTimeout]; ok {
+		var err error
+		if timeout, err = time.ParseDuration(v); err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				`option %s must be a duration`, changefeedbase.OptWebhookClientTimeout)
+		}
+		if timeout <= 0 {
+			return nil, errors.Errorf(`option %s must be a positive duration: %s`,
+				changefeedbase.OptWebhookClientTimeout, v)
+		}
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.tlsSkipVerify}
+	if cfg.caCert != nil {
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`param %s does not contain a valid PEM certificate`,
+				changefeedbase.SinkParamCACert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if cfg.clientCert != nil {
+		if cfg.clientKey == nil {
+			return nil, errors.Errorf(`%s requires %s to be set`,
+				changefeedbase.SinkParamClientCert, changefeedbase.SinkParamClientKey)
+		}
+		cert, err := tls.X509KeyPair(cfg.clientCert, cfg.clientKey)
+		if err != nil {
+			return nil, errors.Errorf(`invalid client certificate data provided: %s`, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if cfg.clientKey != nil {
+		return nil, errors.Errorf(`%s requires %s to be set`,
+			changefeedbase.SinkParamClientKey, changefeedbase.SinkParamClientCert)
+	}
+
+	// Swap the changefeed prefix for the scheme the endpoint actually speaks.
+	sinkURL := *u
+	sinkURL.Scheme = strings.TrimPrefix(sinkURL.Scheme, `webhook-`)
+
+	s := &webhookSink{
+		url:        sinkURL,
+		authHeader: opts[changefeedbase.OptWebhookAuthHeader],
+		cfg:        cfg,
+		client: &httputil.Client{Client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:     (&net.Dialer{Timeout: timeout}).DialContext,
+				TLSClientConfig: tlsConfig,
+			},
+		}},
+	}
+	s.start()
+	return s, nil
+}
+
+func (s *webhookSink) start() {
+	s.stopWorkerCh = make(chan struct{})
+	s.worker.Add(1)
+	go s.workerLoop()
+}
+
+// workerLoop periodically POSTs any buffered rows so that a partially filled
+// batch is not held back indefinitely. Errors are stashed and surfaced on the
+// next call to EmitRow or Flush.
+func (s *webhookSink) workerLoop() {
+	defer s.worker.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stopWorkerCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.cfg.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopWorkerCh:
+			return
+		case <-ticker.C:
+			if err := s.sendBuffered(ctx); err != nil {
+				s.mu.Lock()
+				if s.mu.flushErr == nil {
+					s.mu.flushErr = err
+				}
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, _ catalog.TableDescriptor, _, value []byte, _ hlc.Timestamp,
+) error {
+	if !json.Valid(value) {
+		return errors.AssertionFailedf(`webhook sink received non-JSON value: %q`, value)
+	}
+	// The encoder reuses its buffers, so the value must be copied before it is
+	// retained.
+	msg := make(json.RawMessage, len(value))
+	copy(msg, value)
+
+	s.mu.Lock()
+	if err := s.mu.flushErr; err != nil {
+		s.mu.flushErr = nil
+		s.mu.Unlock()
+		return err
+	}
+	s.mu.buf = append(s.mu.buf, msg)
+	full := len(s.mu.buf) >= s.cfg.batchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.sendBuffered(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(ctx, noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.sendWithRetries(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	flushErr := s.mu.flushErr
+	s.mu.flushErr = nil
+	s.mu.Unlock()
+	if flushErr != nil {
+		return flushErr
+	}
+	return s.sendBuffered(ctx)
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	close(s.stopWorkerCh)
+	s.worker.Wait()
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// sendBuffered POSTs every buffered row as a single batch. Because sendMu is
+// held for the duration, any batch taken by a concurrent caller has been
+// acknowledged by the time this returns.
+func (s *webhookSink) sendBuffered(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.mu.Lock()
+	batch := s.mu.buf
+	s.mu.buf = nil
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookSinkPayload{Payload: batch, Length: len(batch)})
+	if err != nil {
+		return err
+	}
+	if log.V(2) {
+		log.Infof(ctx, "sending batch of %d rows to webhook", len(batch))
+	}
+	return s.sendWithRetries(ctx, body)
+}
+
+func (s *webhookSink) sendWithRetries(ctx context.Context, body []byte) error {
+	opts := retry.Options{
+		InitialBackoff: s.cfg.retryBackoff,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		// The first attempt is not a retry.
+		MaxRetries: s.cfg.retryMax,
+	}
+	var err error
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		if err = s.sendMessage(ctx, body); err == nil || errors.Is(err, errWebhookNonRetryable) {
+			return err
+		}
+		log.VEventf(ctx, 1, "retrying webhook request: %v", err)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return errors.Wrapf(err, `sending to webhook sink failed after %d retries`, s.cfg.retryMax)
+}
+
+// errWebhookNonRetryable marks a response from the endpoint which will not
+// change if the request is repeated, such as a 4xx status.
+var errWebhookNonRetryable = errors.New(`non-retryable webhook response`)
+
+func (s *webhookSink) sendMessage(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return errors.Mark(err, errWebhookNonRetryable)
+	}
+	req.Header.Set("Content-Type", applicationTypeJSON)
+	if s.authHeader != `` {
+		req.Header.Set(authorizationHeader, s.authHeader)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		// Drain the body so the connection can be reused.
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		return nil
+	}
+	resBody, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<10))
+	if err != nil {
+		return errors.Wrapf(err, `failed to read body for HTTP response with status: %d`, res.StatusCode)
+	}
+	err = fmt.Errorf(`%s: %s`, res.Status, string(resBody))
+	if res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+	return errors.Mark(err, errWebhookNonRetryable)
+}
@@ -0,0 +1,335 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// mockWebhookServer records the bodies of the requests it receives. The
+// status it responds with can be scripted per request.
+type mockWebhookServer struct {
+	srv *httptest.Server
+	mu  struct {
+		syncutil.Mutex
+		statusCodes []int
+		bodies      []string
+		authHeaders []string
+	}
+}
+
+func makeMockWebhookServer() *mockWebhookServer {
+	s := &mockWebhookServer{}
+	s.srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if len(s.mu.statusCodes) > 0 {
+			code := s.mu.statusCodes[0]
+			s.mu.statusCodes = s.mu.statusCodes[1:]
+			if code != http.StatusOK {
+				http.Error(w, "scripted failure", code)
+				return
+			}
+		}
+		s.mu.bodies = append(s.mu.bodies, string(body))
+		s.mu.authHeaders = append(s.mu.authHeaders, r.Header.Get(authorizationHeader))
+	}))
+	return s
+}
+
+func (s *mockWebhookServer) sinkURI(t *testing.T, params url.Values) string {
+	u, err := url.Parse(s.srv.URL)
+	require.NoError(t, err)
+	u.Scheme = changefeedbase.SinkSchemeWebhookHTTPS
+	u.Path = `/changefeed`
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.srv.Certificate().Raw})
+	params.Set(changefeedbase.SinkParamCACert, base64.StdEncoding.EncodeToString(caCert))
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+func (s *mockWebhookServer) failNext(codes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.statusCodes = append(s.mu.statusCodes, codes...)
+}
+
+func (s *mockWebhookServer) pop() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bodies := s.mu.bodies
+	s.mu.bodies = nil
+	return bodies
+}
+
+func (s *mockWebhookServer) Close() {
+	s.srv.Close()
+}
+
+func makeTestWebhookSink(sinkURI string, extraOpts map[string]string) (Sink, error) {
+	opts := map[string]string{
+		changefeedbase.OptFormat:     string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope:   string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue: ``,
+	}
+	for k, v := range extraOpts {
+		opts[k] = v
+	}
+	targets := jobspb.ChangefeedTargets{0: jobspb.ChangefeedTarget{StatementTimeName: `t`}}
+	var nilOracle timestampLowerBoundOracle
+	return getSink(
+		context.Background(), sinkURI, 0 /* srcID */, opts, targets,
+		cluster.MakeTestingClusterSettings(), nilOracle, nil, /* makeExternalStorageFromURI */
+		security.RootUserName(),
+	)
+}
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	table := func(name string) catalog.TableDescriptor {
+		return tabledesc.NewImmutable(descpb.TableDescriptor{Name: name})
+	}
+	ctx := context.Background()
+
+	srv := makeMockWebhookServer()
+	defer srv.Close()
+
+	sinkURI := srv.sinkURI(t, url.Values{
+		changefeedbase.SinkParamWebhookBatchSize: {`2`},
+		changefeedbase.SinkParamWebhookFlushFreq: {`1h`},
+		changefeedbase.SinkParamWebhookBackoff:   {`1ms`},
+	})
+	sink, err := makeTestWebhookSink(sinkURI, map[string]string{
+		changefeedbase.OptWebhookAuthHeader: `Basic dXNlcjpwYXNz`,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// Empty flush.
+	require.NoError(t, sink.Flush(ctx))
+	require.Empty(t, srv.pop())
+
+	// Nothing is sent until the batch is full.
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":1}`), zeroTS))
+	require.Empty(t, srv.pop())
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":2}`), zeroTS))
+	require.Equal(t, []string{`{"payload":[{"after":1},{"after":2}],"length":2}`}, srv.pop())
+
+	// A partial batch is sent on Flush.
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":3}`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[{"after":3}],"length":1}`}, srv.pop())
+
+	// Resolved timestamps are sent immediately and on their own.
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, testEncoder{}, hlc.Timestamp{WallTime: 1}))
+	require.Equal(t, []string{`0.000000001,0`}, srv.pop())
+
+	// Every request carries the configured auth header.
+	srv.mu.Lock()
+	for _, h := range srv.mu.authHeaders {
+		require.Equal(t, `Basic dXNlcjpwYXNz`, h)
+	}
+	srv.mu.Unlock()
+
+	// Server errors are retried.
+	srv.failNext(http.StatusInternalServerError, http.StatusServiceUnavailable)
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":4}`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[{"after":4}],"length":1}`}, srv.pop())
+
+	// Client errors are not.
+	srv.failNext(http.StatusBadRequest)
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":5}`), zeroTS))
+	if err := sink.Flush(ctx); !testutils.IsError(err, `400 Bad Request`) {
+		t.Fatalf(`expected "400 Bad Request" error got: %+v`, err)
+	}
+	require.Empty(t, srv.pop())
+
+	// Retries are bounded.
+	srv.failNext(
+		http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError,
+	)
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":6}`), zeroTS))
+	if err := sink.Flush(ctx); !testutils.IsError(err, `failed after 3 retries`) {
+		t.Fatalf(`expected "failed after 3 retries" error got: %+v`, err)
+	}
+	require.Empty(t, srv.pop())
+
+	// Check simple success again after error.
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), nil, []byte(`{"after":7}`), zeroTS))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`{"payload":[{"after":7}],"length":1}`}, srv.pop())
+}
+
+func TestWebhookSinkFlushInterval(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv := makeMockWebhookServer()
+	defer srv.Close()
+
+	sinkURI := srv.sinkURI(t, url.Values{
+		changefeedbase.SinkParamWebhookBatchSize: {`100`},
+		changefeedbase.SinkParamWebhookFlushFreq: {`10ms`},
+	})
+	sink, err := makeTestWebhookSink(sinkURI, nil)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	table := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `t`})
+	require.NoError(t, sink.EmitRow(ctx, table, nil, []byte(`{"after":1}`), zeroTS))
+
+	// The row is delivered by the background worker without an explicit Flush.
+	var bodies []string
+	testutils.SucceedsSoon(t, func() error {
+		bodies = append(bodies, srv.pop()...)
+		if len(bodies) == 0 {
+			return errors.New(`no rows delivered yet`)
+		}
+		return nil
+	})
+	require.Len(t, bodies, 1)
+	var payload webhookSinkPayload
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &payload))
+	require.Equal(t, 1, payload.Length)
+	require.JSONEq(t, `{"after":1}`, string(payload.Payload[0]))
+}
+
+func TestWebhookSinkConfigErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	srv := makeMockWebhookServer()
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		params url.Values
+		opts   map[string]string
+		err    string
+	}{
+		{
+			params: url.Values{changefeedbase.SinkParamWebhookBatchSize: {`0`}},
+			err:    `param batch_size must be at least 1`,
+		},
+		{
+			params: url.Values{changefeedbase.SinkParamWebhookBatchSize: {`x`}},
+			err:    `param batch_size must be an integer`,
+		},
+		{
+			params: url.Values{changefeedbase.SinkParamWebhookFlushFreq: {`-1s`}},
+			err:    `param flush_interval must be a positive duration`,
+		},
+		{
+			params: url.Values{changefeedbase.SinkParamWebhookRetryMax: {`-1`}},
+			err:    `param max_retries must not be negative`,
+		},
+		{
+			params: url.Values{`nope`: {`1`}},
+			err:    `unknown sink query parameter: nope`,
+		},
+		{
+			opts: map[string]string{changefeedbase.OptWebhookClientTimeout: `soon`},
+			err:  `option webhook_client_timeout must be a duration`,
+		},
+		{
+			opts: map[string]string{changefeedbase.OptFormat: string(changefeedbase.OptFormatAvro)},
+			err:  `this sink is incompatible with format=experimental_avro`,
+		},
+	} {
+		params := tc.params
+		if params == nil {
+			params = url.Values{}
+		}
+		_, err := makeTestWebhookSink(srv.sinkURI(t, params), tc.opts)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestWebhookSinkEndToEnd(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{UseDatabase: "d"})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.experimental_poll_interval = '10ms'`)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a')`)
+
+	srv := makeMockWebhookServer()
+	defer srv.Close()
+
+	sinkURI := srv.sinkURI(t, url.Values{changefeedbase.SinkParamWebhookFlushFreq: {`10ms`}})
+	var jobID int64
+	sqlDB.QueryRow(t,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH webhook_auth_header='Bearer secret'`, sinkURI,
+	).Scan(&jobID)
+	defer sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+
+	// The auth header does not leak into the job description.
+	var description string
+	sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+	require.NotContains(t, description, `secret`)
+
+	var bodies []string
+	testutils.SucceedsSoon(t, func() error {
+		bodies = append(bodies, srv.pop()...)
+		if len(bodies) == 0 {
+			return errors.New(`no rows delivered yet`)
+		}
+		return nil
+	})
+	var payload webhookSinkPayload
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &payload))
+	require.Equal(t, 1, payload.Length)
+	require.JSONEq(t, `{"after": {"a": 1, "b": "a"}, "key": [1]}`, string(payload.Payload[0]))
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	require.Equal(t, `Bearer secret`, srv.mu.authHeaders[0])
+}
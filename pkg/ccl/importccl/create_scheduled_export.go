@@ -0,0 +1,354 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/jsonpb"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+const scheduleExportOp = "CREATE SCHEDULE FOR EXPORT"
+
+// scheduledExportEval is a representation of tree.ScheduledExport, prepared
+// for evaluation.
+type scheduledExportEval struct {
+	*tree.ScheduledExport
+
+	// Schedule specific properties that get evaluated.
+	scheduleLabel func() (string, error)
+	recurrence    func() (string, error)
+	scheduleOpts  func() (map[string]string, error)
+
+	// Export specific properties that get evaluated, so that the evaluated
+	// export statement is stored in the schedule.
+	file       func() (string, error)
+	exportOpts func() (map[string]string, error)
+}
+
+// doCreateExportSchedule creates the requested export schedule. It is a plan
+// hook implementation responsible for the creation of scheduled exports.
+func doCreateExportSchedule(
+	ctx context.Context, p sql.PlanHookState, eval *scheduledExportEval, resultsCh chan<- tree.Datums,
+) error {
+	if err := p.RequireAdminRole(ctx, scheduleExportOp); err != nil {
+		return err
+	}
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
+
+	recurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.recurrence)
+	if err != nil {
+		return err
+	}
+	if recurrence == schedulebase.NeverRecurs {
+		return errors.AssertionFailedf("export recurrence should be set")
+	}
+
+	file, err := eval.file()
+	if err != nil {
+		return errors.Wrapf(err, "failed to evaluate export destination")
+	}
+	// Sanity check the destination, since the export itself only runs when the
+	// schedule executes.
+	if _, err := cloudimpl.ExternalStorageConfFromURI(file, p.User()); err != nil {
+		return errors.Wrapf(err, "invalid export destination")
+	}
+	exportOpts, err := eval.exportOpts()
+	if err != nil {
+		return err
+	}
+	exportNode := makeExportNode(eval.Export, file, exportOpts)
+
+	var scheduleLabel string
+	if eval.scheduleLabel != nil {
+		label, err := eval.scheduleLabel()
+		if err != nil {
+			return err
+		}
+		scheduleLabel = label
+	} else {
+		scheduleLabel = fmt.Sprintf("EXPORT %d", env.Now().Unix())
+	}
+
+	scheduleOptions, err := eval.scheduleOpts()
+	if err != nil {
+		return err
+	}
+
+	evalCtx := &p.ExtendedEvalContext().EvalContext
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	// The exports of the schedule don't run in the session which created it,
+	// so the schedule records the database the query is resolved in.
+	sj, err := makeExportSchedule(
+		env, p.User(), scheduleLabel, recurrence, details, exportNode, p.CurrentDatabase())
+	if err != nil {
+		return err
+	}
+	if firstRun != nil {
+		sj.SetNextRun(*firstRun)
+	}
+
+	if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn); err != nil {
+		return err
+	}
+	collectScheduledExportTelemetry(exportNode, firstRun, details)
+	return emitExportSchedule(sj, exportNode, file, resultsCh)
+}
+
+// makeExportNode returns the EXPORT statement with the evaluated destination
+// and options.
+func makeExportNode(export *tree.Export, file string, opts map[string]string) *tree.Export {
+	e := &tree.Export{
+		Query:      export.Query,
+		FileFormat: export.FileFormat,
+		File:       tree.NewDString(file),
+	}
+	for k, v := range opts {
+		opt := tree.KVOption{Key: tree.Name(k)}
+		if len(v) > 0 {
+			opt.Value = tree.NewDString(v)
+		}
+		e.Options = append(e.Options, opt)
+	}
+	sort.Slice(e.Options, func(i, j int) bool { return e.Options[i].Key < e.Options[j].Key })
+	return e
+}
+
+// redactedExportNode returns the EXPORT statement with the secrets in its
+// destination redacted.
+func redactedExportNode(export *tree.Export, file string) (*tree.Export, error) {
+	clean, err := cloudimpl.SanitizeExternalStorageURI(file, nil /* extraParams */)
+	if err != nil {
+		return nil, err
+	}
+	redacted := *export
+	redacted.File = tree.NewDString(clean)
+	return &redacted, nil
+}
+
+func makeExportSchedule(
+	env scheduledjobs.JobSchedulerEnv,
+	owner security.SQLUsername,
+	label string,
+	recurrence *schedulebase.ScheduleRecurrence,
+	details jobspb.ScheduleDetails,
+	exportNode *tree.Export,
+	database string,
+) (*jobs.ScheduledJob, error) {
+	sj := jobs.NewScheduledJob(env)
+	sj.SetScheduleLabel(label)
+	sj.SetOwner(owner)
+
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
+		return nil, err
+	}
+	sj.SetScheduleDetails(details)
+
+	args := &ScheduledExportExecutionArgs{
+		ExportStatement: tree.AsString(exportNode),
+		Database:        database,
+	}
+	any, err := pbtypes.MarshalAny(args)
+	if err != nil {
+		return nil, err
+	}
+	sj.SetExecutionDetails(
+		tree.ScheduledExportExecutor.InternalName(),
+		jobspb.ExecutionArguments{Args: any},
+	)
+	return sj, nil
+}
+
+func emitExportSchedule(
+	sj *jobs.ScheduledJob, exportNode *tree.Export, file string, resultsCh chan<- tree.Datums,
+) error {
+	status, nextRun, err := schedulebase.ScheduleStatusAndNextRun(sj)
+	if err != nil {
+		return err
+	}
+
+	redacted, err := redactedExportNode(exportNode, file)
+	if err != nil {
+		return err
+	}
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(sj.ScheduleID())),
+		tree.NewDString(sj.ScheduleLabel()),
+		tree.NewDString(status),
+		nextRun,
+		tree.NewDString(sj.ScheduleExpr()),
+		tree.NewDString(tree.AsString(redacted)),
+	}
+	return nil
+}
+
+// makeScheduledExportEval prepares helper scheduledExportEval struct to assist
+// in evaluation of various schedule and export specific components.
+func makeScheduledExportEval(
+	ctx context.Context, p sql.PlanHookState, schedule *tree.ScheduledExport,
+) (*scheduledExportEval, error) {
+	eval := &scheduledExportEval{ScheduledExport: schedule}
+	var err error
+
+	if schedule.ScheduleLabel != nil {
+		eval.scheduleLabel, err = p.TypeAsString(ctx, schedule.ScheduleLabel, scheduleExportOp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if schedule.Recurrence == nil {
+		// Sanity check: recurrence must be specified.
+		return nil, errors.New("RECURRING clause required")
+	}
+	eval.recurrence, err = p.TypeAsString(ctx, schedule.Recurrence, scheduleExportOp)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.scheduleOpts, err = p.TypeAsStringOpts(
+		ctx, schedule.ScheduleOptions, schedulebase.ScheduleOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.file, err = p.TypeAsString(ctx, schedule.Export.File, scheduleExportOp)
+	if err != nil {
+		return nil, err
+	}
+	eval.exportOpts, err = p.TypeAsStringOpts(ctx, schedule.Export.Options, sql.ExportOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+	return eval, nil
+}
+
+// scheduledExportHeader is the header for "CREATE SCHEDULE FOR EXPORT"
+// statements results.
+var scheduledExportHeader = colinfo.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "label", Typ: types.String},
+	{Name: "status", Typ: types.String},
+	{Name: "first_run", Typ: types.TimestampTZ},
+	{Name: "schedule", Typ: types.String},
+	{Name: "export_stmt", Typ: types.String},
+}
+
+func collectScheduledExportTelemetry(
+	exportNode *tree.Export, firstRun *time.Time, details jobspb.ScheduleDetails,
+) {
+	telemetry.Count("scheduled-export.create.success")
+	telemetry.Count("scheduled-export.format." + exportNode.FileFormat)
+	if firstRun != nil {
+		telemetry.Count("scheduled-export.first-run-picked")
+	}
+	switch details.Wait {
+	case jobspb.ScheduleDetails_WAIT:
+		telemetry.Count("scheduled-export.wait-policy.wait")
+	case jobspb.ScheduleDetails_NO_WAIT:
+		telemetry.Count("scheduled-export.wait-policy.no-wait")
+	case jobspb.ScheduleDetails_SKIP:
+		telemetry.Count("scheduled-export.wait-policy.skip")
+	}
+	switch details.OnError {
+	case jobspb.ScheduleDetails_RETRY_SCHED:
+		telemetry.Count("scheduled-export.error-policy.retry-schedule")
+	case jobspb.ScheduleDetails_RETRY_SOON:
+		telemetry.Count("scheduled-export.error-policy.retry-soon")
+	case jobspb.ScheduleDetails_PAUSE_SCHED:
+		telemetry.Count("scheduled-export.error-policy.pause-schedule")
+	}
+}
+
+func createExportScheduleHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledExport)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	eval, err := makeScheduledExportEval(ctx, p, schedule)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		if err := doCreateExportSchedule(ctx, p, eval, resultsCh); err != nil {
+			telemetry.Count("scheduled-export.create.failed")
+			return err
+		}
+		return nil
+	}
+	return fn, scheduledExportHeader, nil, false, nil
+}
+
+// parseExportStatement parses the EXPORT statement stored in a schedule, and
+// returns it along with its destination.
+func parseExportStatement(stmt string) (*tree.Export, string, error) {
+	node, err := parser.ParseOne(stmt)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "parsing export statement")
+	}
+	export, ok := node.AST.(*tree.Export)
+	if !ok {
+		return nil, "", errors.Errorf("unexpected %T statement in export schedule: %v", node.AST, node.AST)
+	}
+	file, ok := export.File.(*tree.StrVal)
+	if !ok {
+		return nil, "", errors.Errorf("unexpected %T arg in export schedule: %v", export.File, export.File)
+	}
+	return export, file.RawString(), nil
+}
+
+// MarshalJSONPB provides a custom Marshaller for jsonpb that redacts secrets in
+// the destination URI.
+func (m ScheduledExportExecutionArgs) MarshalJSONPB(x *jsonpb.Marshaler) ([]byte, error) {
+	export, file, err := parseExportStatement(m.ExportStatement)
+	if err != nil {
+		return nil, err
+	}
+	redacted, err := redactedExportNode(export, file)
+	if err != nil {
+		return nil, err
+	}
+	m.ExportStatement = redacted.String()
+	return json.Marshal(m)
+}
+
+func init() {
+	sql.AddPlanHook(createExportScheduleHook)
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	gosql "database/sql"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobstest"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/jsonpb"
+	pbtypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/require"
+)
+
+// exportScheduleTestHelper starts a server, and arranges for job scheduling
+// daemon to use jobstest.JobSchedulerTestEnv. The schedules are executed
+// manually via executeSchedules.
+type exportScheduleTestHelper struct {
+	iodir            string
+	server           serverutils.TestServerInterface
+	env              *jobstest.JobSchedulerTestEnv
+	cfg              *scheduledjobs.JobExecutionConfig
+	sqlDB            *sqlutils.SQLRunner
+	executeSchedules func() error
+}
+
+func newExportScheduleTestHelper(t *testing.T) (*exportScheduleTestHelper, func()) {
+	dir, dirCleanupFn := testutils.TempDir(t)
+
+	th := &exportScheduleTestHelper{
+		env:   jobstest.NewJobSchedulerTestEnv(jobstest.UseSystemTables, timeutil.Now()),
+		iodir: dir,
+	}
+
+	knobs := &jobs.TestingKnobs{
+		JobSchedulerEnv: th.env,
+		TakeOverJobsScheduling: func(
+			fn func(ctx context.Context, maxSchedules int64, txn *kv.Txn) error,
+		) {
+			th.executeSchedules = func() error {
+				defer th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+				return th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+					return fn(ctx, 0 /* allSchedules */, txn)
+				})
+			}
+		},
+		CaptureJobExecutionConfig: func(config *scheduledjobs.JobExecutionConfig) {
+			th.cfg = config
+		},
+	}
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs:         base.TestingKnobs{JobsTestingKnobs: knobs},
+	})
+	require.NotNil(t, th.cfg)
+	th.sqlDB = sqlutils.MakeSQLRunner(db)
+	th.server = s
+
+	return th, func() {
+		dirCleanupFn()
+		s.Stopper().Stop(context.Background())
+	}
+}
+
+// createExportSchedule executes the specified "CREATE SCHEDULE FOR EXPORT"
+// query and loads the created schedule.
+func (h *exportScheduleTestHelper) createExportSchedule(
+	t *testing.T, query string, args ...interface{},
+) *jobs.ScheduledJob {
+	var id int64
+	var unusedStr string
+	var unusedTS *time.Time
+	h.sqlDB.QueryRow(t, query, args...).Scan(
+		&id, &unusedStr, &unusedStr, &unusedTS, &unusedStr, &unusedStr)
+	return h.loadSchedule(t, id)
+}
+
+func (h *exportScheduleTestHelper) loadSchedule(t *testing.T, id int64) *jobs.ScheduledJob {
+	datums, cols, err := h.cfg.InternalExecutor.QueryRowExWithCols(
+		context.Background(), "sched-load", nil,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"SELECT * FROM system.scheduled_jobs WHERE schedule_id = $1",
+		id,
+	)
+	require.NoError(t, err)
+	require.NotNil(t, datums)
+
+	sj := jobs.NewScheduledJob(h.env)
+	require.NoError(t, sj.InitFromDatums(datums, cols))
+	return sj
+}
+
+// waitForSuccessfulScheduledJobs waits until the schedule has started the
+// specified number of jobs which succeeded, and returns their IDs.
+func (h *exportScheduleTestHelper) waitForSuccessfulScheduledJobs(
+	t *testing.T, scheduleID int64, numJobs int,
+) []int64 {
+	var ids []int64
+	testutils.SucceedsSoon(t, func() error {
+		h.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+		ids = ids[:0]
+		rows := h.sqlDB.Query(t, "SELECT id FROM "+h.env.SystemJobsTableName()+
+			" WHERE status=$1 AND created_by_type=$2 AND created_by_id=$3 ORDER BY created",
+			jobs.StatusSucceeded, jobs.CreatedByScheduledJobs, scheduleID)
+		defer rows.Close()
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) != numJobs {
+			return errors.Newf("expected %d successful jobs, found %d", numJobs, len(ids))
+		}
+		return nil
+	})
+	return ids
+}
+
+func TestScheduledExport(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newExportScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY, b STRING)`)
+	th.sqlDB.Exec(t, `INSERT INTO t VALUES (1, 'one'), (2, 'two')`)
+
+	sj := th.createExportSchedule(t, `
+CREATE SCHEDULE 'my export' FOR EXPORT INTO CSV 'nodelocal://0/export' WITH delimiter = '|'
+FROM (SELECT * FROM t) RECURRING '@hourly'`)
+	require.Equal(t, "my export", sj.ScheduleLabel())
+	require.Equal(t, tree.ScheduledExportExecutor.InternalName(), sj.ExecutorType())
+
+	args := &ScheduledExportExecutionArgs{}
+	require.NoError(t, pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args))
+	require.Equal(t,
+		`EXPORT INTO CSV 'nodelocal://0/export' WITH delimiter = '|' FROM (SELECT * FROM t)`,
+		args.ExportStatement)
+
+	th.sqlDB.CheckQueryResults(t,
+		`SELECT label, command FROM [SHOW SCHEDULES FOR EXPORT]`,
+		[][]string{{"my export",
+			`EXPORT INTO CSV 'nodelocal://0/export' WITH delimiter = '|' FROM (SELECT * FROM t)`}})
+
+	// Run the schedule twice: each run writes all the rows to a new file.
+	for run := 1; run <= 2; run++ {
+		th.env.SetTime(sj.NextRun().Add(time.Second))
+		require.NoError(t, th.executeSchedules())
+		ids := th.waitForSuccessfulScheduledJobs(t, sj.ScheduleID(), run)
+
+		var progressBytes []byte
+		th.sqlDB.QueryRow(t, `SELECT progress FROM system.jobs WHERE id = $1`,
+			ids[run-1]).Scan(&progressBytes)
+		var progress jobspb.Progress
+		require.NoError(t, protoutil.Unmarshal(progressBytes, &progress))
+		require.Equal(t, int64(2), progress.GetExport().Rows)
+
+		files, err := ioutil.ReadDir(filepath.Join(th.iodir, "export"))
+		require.NoError(t, err)
+		require.Len(t, files, run)
+		for _, f := range files {
+			contents, err := ioutil.ReadFile(filepath.Join(th.iodir, "export", f.Name()))
+			require.NoError(t, err)
+			require.Equal(t, "1|one\n2|two\n", string(contents))
+		}
+
+		sj = th.loadSchedule(t, sj.ScheduleID())
+	}
+}
+
+func TestCreateExportScheduleRequiresAdminRole(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newExportScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY)`)
+	th.sqlDB.Exec(t, `CREATE USER testuser`)
+	th.sqlDB.Exec(t, `GRANT SELECT ON t TO testuser`)
+	pgURL, cleanupFunc := sqlutils.PGUrl(
+		t, th.server.ServingSQLAddr(), "TestCreateExportScheduleRequiresAdminRole-testuser",
+		url.User("testuser"),
+	)
+	defer cleanupFunc()
+	testuser, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, testuser.Close()) }()
+
+	_, err = testuser.Exec(
+		`CREATE SCHEDULE FOR EXPORT INTO CSV 'nodelocal://0/export' FROM (TABLE t) RECURRING '@daily'`)
+	require.Error(t, err)
+	require.Regexp(t, "only users with the admin role", err)
+}
+
+func TestScheduledExportRedactsDestination(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	args := &ScheduledExportExecutionArgs{
+		ExportStatement: `EXPORT INTO CSV 's3://bucket/path?AWS_ACCESS_KEY_ID=key&AWS_SECRET_ACCESS_KEY=secret' FROM TABLE t`,
+	}
+	json, err := (&jsonpb.Marshaler{}).MarshalToString(args)
+	require.NoError(t, err)
+	require.False(t, strings.Contains(json, "secret"), json)
+	require.True(t, strings.Contains(json, "AWS_SECRET_ACCESS_KEY=redacted"), json)
+}
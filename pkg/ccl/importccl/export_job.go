@@ -0,0 +1,89 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+// exportResumer runs the EXPORT statement of an export job, which is created
+// by a scheduled export. The statement is run as the user who created the
+// schedule, in the database which was current when it was created. Each
+// execution of an EXPORT writes files with distinct names, so a job which is
+// resumed after a failure writes all of its files again, next to the ones
+// written before the failure.
+type exportResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &exportResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *exportResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	details := r.job.Details().(jobspb.ExportDetails)
+
+	rows, err := p.ExecCfg().InternalExecutor.QueryEx(
+		ctx, "export-job", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: p.User(), Database: details.Database},
+		details.Statement,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Each row of the result of EXPORT describes a file it wrote.
+	var exported jobspb.ExportProgress
+	for _, row := range rows {
+		exported.Rows += int64(tree.MustBeDInt(row[1]))
+		exported.Bytes += int64(tree.MustBeDInt(row[2]))
+	}
+	if err := r.job.FractionProgressed(
+		ctx,
+		func(ctx context.Context, details jobspb.ProgressDetails) float32 {
+			prog := details.(*jobspb.Progress_Export).Export
+			prog.Rows = exported.Rows
+			prog.Bytes = exported.Bytes
+			return 1
+		},
+	); err != nil {
+		return err
+	}
+
+	telemetry.Count("export.scheduled.succeeded")
+	schedulebase.MaybeNotifyScheduledJobCompletion(ctx, r.job, jobs.StatusSucceeded, p.ExecCfg())
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *exportResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	// The files written so far are left in place.
+	telemetry.Count("export.scheduled.failed")
+	p := execCtx.(sql.JobExecContext)
+	schedulebase.MaybeNotifyScheduledJobCompletion(ctx, r.job, jobs.StatusFailed, p.ExecCfg())
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeExport,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &exportResumer{job: job}
+		},
+	)
+}
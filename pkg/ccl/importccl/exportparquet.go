@@ -0,0 +1,421 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+const exportParquetFilePatternDefault = exportFilePatternPart + ".parquet"
+
+var parquetCompressionCodecs = map[execinfrapb.ParquetWriterSpec_Compression]parquet.CompressionCodec{
+	execinfrapb.ParquetWriterSpec_NONE:   parquet.CompressionCodec_UNCOMPRESSED,
+	execinfrapb.ParquetWriterSpec_GZIP:   parquet.CompressionCodec_GZIP,
+	execinfrapb.ParquetWriterSpec_SNAPPY: parquet.CompressionCodec_SNAPPY,
+}
+
+// parquetEncodeFn encodes a non-NULL datum as a value of a Parquet column.
+type parquetEncodeFn func(d tree.Datum, f *tree.FmtCtx) (interface{}, error)
+
+// newParquetSchema returns the schema of the Parquet files holding rows with
+// the given column names and types, along with the functions used to encode
+// the datums of each column.
+//
+// Types which have a natural Parquet representation are exported as such, so
+// that other tools can interpret them. All other types, including DECIMAL
+// (whose precision isn't known in general) and arrays, are exported as
+// strings in the same format as CSV exports.
+func newParquetSchema(
+	names []string, typs []*types.T,
+) (*parquetschema.SchemaDefinition, []parquetEncodeFn, error) {
+	if len(names) != len(typs) {
+		return nil, nil, errors.AssertionFailedf(
+			"expected %d column names, found %d", len(typs), len(names))
+	}
+	root := &parquetschema.ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{Name: "root"},
+	}
+	encoders := make([]parquetEncodeFn, len(typs))
+	for i, typ := range typs {
+		elem, encode := parquetColumnForType(typ)
+		elem.Name = names[i]
+		elem.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+		root.Children = append(root.Children, &parquetschema.ColumnDefinition{SchemaElement: elem})
+		encoders[i] = encode
+	}
+	return parquetschema.SchemaDefinitionFromColumnDefinition(root), encoders, nil
+}
+
+func parquetColumnForType(typ *types.T) (*parquet.SchemaElement, parquetEncodeFn) {
+	elem := &parquet.SchemaElement{}
+	micros := &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}
+	switch typ.Family() {
+	case types.BoolFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BOOLEAN)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return bool(*d.(*tree.DBool)), nil
+		}
+
+	case types.IntFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		elem.LogicalType = &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 64, IsSigned: true}}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return int64(*d.(*tree.DInt)), nil
+		}
+
+	case types.FloatFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return float64(*d.(*tree.DFloat)), nil
+		}
+
+	case types.StringFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		elem.LogicalType = &parquet.LogicalType{STRING: parquet.NewStringType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return []byte(*d.(*tree.DString)), nil
+		}
+
+	case types.BytesFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return []byte(*d.(*tree.DBytes)), nil
+		}
+
+	case types.DateFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		elem.LogicalType = &parquet.LogicalType{DATE: parquet.NewDateType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			date := d.(*tree.DDate).Date
+			if !date.IsFinite() {
+				return nil, errors.Errorf("cannot export infinite date %s to parquet", d)
+			}
+			return int32(date.UnixEpochDays()), nil
+		}
+
+	case types.TimestampFamily, types.TimestampTZFamily:
+		adjusted := typ.Family() == types.TimestampTZFamily
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		elem.LogicalType = &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{
+			IsAdjustedToUTC: adjusted, Unit: micros,
+		}}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			var t time.Time
+			switch d := d.(type) {
+			case *tree.DTimestamp:
+				t = d.Time
+			case *tree.DTimestampTZ:
+				t = d.Time
+			}
+			return t.Unix()*1e6 + int64(t.Nanosecond()/1e3), nil
+		}
+
+	case types.TimeFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		elem.LogicalType = &parquet.LogicalType{TIME: &parquet.TimeType{Unit: micros}}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return int64(*d.(*tree.DTime)), nil
+		}
+
+	case types.UuidFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+		elem.TypeLength = func(l int32) *int32 { return &l }(16)
+		elem.LogicalType = &parquet.LogicalType{UUID: parquet.NewUUIDType()}
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return d.(*tree.DUuid).GetBytes(), nil
+		}
+
+	case types.JsonFamily:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		elem.LogicalType = &parquet.LogicalType{JSON: parquet.NewJsonType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+		return elem, func(d tree.Datum, _ *tree.FmtCtx) (interface{}, error) {
+			return []byte(d.(*tree.DJSON).JSON.String()), nil
+		}
+	}
+
+	elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+	elem.LogicalType = &parquet.LogicalType{STRING: parquet.NewStringType()}
+	elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	return elem, func(d tree.Datum, f *tree.FmtCtx) (interface{}, error) {
+		defer f.Reset()
+		d.Format(f)
+		return []byte(f.String()), nil
+	}
+}
+
+// parquetExporter buffers the rows of an exported file and encodes them in
+// the Parquet format.
+type parquetExporter struct {
+	buf      *bytes.Buffer
+	schema   *parquetschema.SchemaDefinition
+	encoders []parquetEncodeFn
+	codec    parquet.CompressionCodec
+	// rowGroupSize is the size after which row groups are flushed, 0 if a
+	// file only has a single row group.
+	rowGroupSize int64
+	writer       *goparquet.FileWriter
+	record       map[string]interface{}
+	names        []string
+	fmtCtx       *tree.FmtCtx
+}
+
+func newParquetExporter(sp execinfrapb.ParquetWriterSpec, typs []*types.T) (*parquetExporter, error) {
+	schema, encoders, err := newParquetSchema(sp.ColumnNames, typs)
+	if err != nil {
+		return nil, err
+	}
+	codec, ok := parquetCompressionCodecs[sp.CompressionCodec]
+	if !ok {
+		return nil, errors.Errorf("unsupported parquet compression codec %s", sp.CompressionCodec)
+	}
+	e := &parquetExporter{
+		buf:          bytes.NewBuffer([]byte{}),
+		schema:       schema,
+		encoders:     encoders,
+		codec:        codec,
+		rowGroupSize: sp.RowGroupSize,
+		record:       make(map[string]interface{}, len(typs)),
+		names:        sp.ColumnNames,
+		fmtCtx:       tree.NewFmtCtx(tree.FmtExport),
+	}
+	e.ResetBuffer()
+	return e, nil
+}
+
+// ResetBuffer discards the buffered file and starts a new one.
+func (e *parquetExporter) ResetBuffer() {
+	e.buf.Reset()
+	opts := []goparquet.FileWriterOption{
+		goparquet.WithSchemaDefinition(e.schema),
+		goparquet.WithCompressionCodec(e.codec),
+		goparquet.WithCreator(fmt.Sprintf("CockroachDB %s", build.GetInfo().Tag)),
+	}
+	if e.rowGroupSize > 0 {
+		opts = append(opts, goparquet.WithMaxRowGroupSize(e.rowGroupSize))
+	}
+	e.writer = goparquet.NewFileWriter(e.buf, opts...)
+}
+
+// Write adds a row to the file. The datums must be decoded.
+func (e *parquetExporter) Write(row rowenc.EncDatumRow) error {
+	for i, ed := range row {
+		if ed.IsNull() {
+			delete(e.record, e.names[i])
+			continue
+		}
+		v, err := e.encoders[i](tree.UnwrapDatum(nil, ed.Datum), e.fmtCtx)
+		if err != nil {
+			return err
+		}
+		e.record[e.names[i]] = v
+	}
+	return e.writer.AddData(e.record)
+}
+
+// Close flushes the last row group and writes the footer of the file.
+func (e *parquetExporter) Close() error {
+	return e.writer.Close()
+}
+
+// Bytes returns the content of the file.
+func (e *parquetExporter) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Len returns the size of the file.
+func (e *parquetExporter) Len() int {
+	return e.buf.Len()
+}
+
+func (e *parquetExporter) FileName(spec execinfrapb.ParquetWriterSpec, part string) string {
+	pattern := exportParquetFilePatternDefault
+	if spec.NamePattern != "" {
+		pattern = spec.NamePattern
+	}
+	return strings.Replace(pattern, exportFilePatternPart, part, -1)
+}
+
+func newParquetWriterProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.ParquetWriterSpec,
+	input execinfra.RowSource,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	c := &parquetWriterProcessor{
+		flowCtx:     flowCtx,
+		processorID: processorID,
+		spec:        spec,
+		input:       input,
+		output:      output,
+	}
+	semaCtx := tree.MakeSemaContext()
+	if err := c.out.Init(&execinfrapb.PostProcessSpec{}, c.OutputTypes(), &semaCtx, flowCtx.NewEvalCtx(), output); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type parquetWriterProcessor struct {
+	flowCtx     *execinfra.FlowCtx
+	processorID int32
+	spec        execinfrapb.ParquetWriterSpec
+	input       execinfra.RowSource
+	out         execinfra.ProcOutputHelper
+	output      execinfra.RowReceiver
+}
+
+var _ execinfra.Processor = &parquetWriterProcessor{}
+
+func (sp *parquetWriterProcessor) OutputTypes() []*types.T {
+	res := make([]*types.T, len(colinfo.ExportColumns))
+	for i := range res {
+		res[i] = colinfo.ExportColumns[i].Typ
+	}
+	return res
+}
+
+func (sp *parquetWriterProcessor) Run(ctx context.Context) {
+	ctx, span := tracing.ChildSpan(ctx, "parquetWriter")
+	defer span.Finish()
+
+	err := func() error {
+		typs := sp.input.OutputTypes()
+		sp.input.Start(ctx)
+		input := execinfra.MakeNoMetadataRowSource(sp.input, sp.output)
+
+		alloc := &rowenc.DatumAlloc{}
+
+		writer, err := newParquetExporter(sp.spec, typs)
+		if err != nil {
+			return err
+		}
+		defer writer.fmtCtx.Close()
+
+		chunk := 0
+		done := false
+		for {
+			var rows int64
+			writer.ResetBuffer()
+			for {
+				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
+					break
+				}
+				row, err := input.NextRow()
+				if err != nil {
+					return err
+				}
+				if row == nil {
+					done = true
+					break
+				}
+				rows++
+
+				for i := range row {
+					if err := row[i].EnsureDecoded(typs[i], alloc); err != nil {
+						return err
+					}
+				}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+			if rows < 1 {
+				break
+			}
+			// Close writer to ensure the last row group and the footer are written.
+			if err := writer.Close(); err != nil {
+				return errors.Wrap(err, "failed to close parquet writer")
+			}
+
+			conf, err := cloudimpl.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User())
+			if err != nil {
+				return err
+			}
+			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf)
+			if err != nil {
+				return err
+			}
+			defer es.Close()
+
+			nodeID, err := sp.flowCtx.EvalCtx.NodeID.OptionalNodeIDErr(47970)
+			if err != nil {
+				return err
+			}
+
+			part := fmt.Sprintf("n%d.%d", nodeID, chunk)
+			chunk++
+			filename := writer.FileName(sp.spec, part)
+			size := writer.Len()
+
+			if err := es.WriteFile(ctx, filename, bytes.NewReader(writer.Bytes())); err != nil {
+				return err
+			}
+			res := rowenc.EncDatumRow{
+				rowenc.DatumToEncDatum(
+					types.String,
+					tree.NewDString(filename),
+				),
+				rowenc.DatumToEncDatum(
+					types.Int,
+					tree.NewDInt(tree.DInt(rows)),
+				),
+				rowenc.DatumToEncDatum(
+					types.Int,
+					tree.NewDInt(tree.DInt(size)),
+				),
+			}
+
+			cs, err := sp.out.EmitRow(ctx, res)
+			if err != nil {
+				return err
+			}
+			if cs != execinfra.NeedMoreRows {
+				return errors.New("unexpected closure of consumer")
+			}
+			if done {
+				break
+			}
+		}
+
+		return nil
+	}()
+
+	execinfra.DrainAndClose(
+		ctx, sp.output, err, func(context.Context) {} /* pushTrailingMeta */, sp.input)
+}
+
+func init() {
+	rowexec.NewParquetWriterProcessor = newParquetWriterProcessor
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+const parquetRoundTripSchema = `(
+	id INT PRIMARY KEY,
+	b BOOL,
+	i2 INT2,
+	f FLOAT,
+	dec DECIMAL(10, 3),
+	s STRING,
+	by BYTES,
+	d DATE,
+	ts TIMESTAMP,
+	tstz TIMESTAMPTZ,
+	t TIME,
+	u UUID,
+	j JSONB,
+	iv INTERVAL,
+	ip INET,
+	arr INT[]
+)`
+
+func TestExportImportParquetRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	srv, conn, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	db := sqlutils.MakeSQLRunner(conn)
+
+	db.Exec(t, `CREATE TABLE src `+parquetRoundTripSchema)
+	db.Exec(t, `INSERT INTO src
+		SELECT
+			i,
+			i % 2 = 0,
+			(i * 7)::INT2,
+			i::FLOAT / 3,
+			(i::DECIMAL / 7)::DECIMAL(10, 3),
+			'row ✅ ' || i::STRING,
+			i::STRING::BYTES || '\x00'::BYTES,
+			'2021-01-01'::DATE + i,
+			'1969-12-31 23:59:59.5'::TIMESTAMP + i * '1 hour 1 microsecond'::INTERVAL,
+			'2021-01-01 00:00:00+00'::TIMESTAMPTZ - i * '1 day'::INTERVAL,
+			'00:00:00'::TIME + i * '1 minute 3 microseconds'::INTERVAL,
+			md5(i::STRING)::UUID,
+			json_build_object('i', i, 'a', ARRAY[i, i + 1]),
+			i * '1 day 1 second'::INTERVAL,
+			('10.0.0.' || (i % 255)::STRING)::INET,
+			ARRAY[i, NULL, -i]
+		FROM generate_series(1, 500) AS g(i)`)
+	db.Exec(t, `INSERT INTO src (id) VALUES (0)`)
+
+	for _, tc := range []struct {
+		name    string
+		options string
+	}{
+		{name: "default"},
+		{name: "uncompressed", options: `WITH compression = 'none'`},
+		{name: "gzip-chunks", options: `WITH compression = 'gzip', chunk_rows = '123'`},
+		{name: "row-groups", options: `WITH row_group_size = '4KiB'`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := "nodelocal://0/" + tc.name
+			var files []string
+			var totalRows int
+			for _, row := range db.QueryStr(t,
+				fmt.Sprintf(`EXPORT INTO PARQUET '%s' %s FROM SELECT * FROM src`, dest, tc.options),
+			) {
+				require.True(t, strings.HasSuffix(row[0], ".parquet"), row[0])
+				files = append(files, dest+"/"+row[0])
+				var n int
+				_, err := fmt.Sscan(row[1], &n)
+				require.NoError(t, err)
+				totalRows += n
+			}
+			require.Equal(t, 501, totalRows)
+
+			if tc.name == "row-groups" {
+				data := readFileByGlob(t, filepath.Join(dir, tc.name, "export*-n1.0.parquet"))
+				r, err := goparquet.NewFileReader(bytes.NewReader(data))
+				require.NoError(t, err)
+				require.Greater(t, r.RowGroupCount(), 1)
+			}
+
+			db.Exec(t, `CREATE TABLE dst `+parquetRoundTripSchema)
+			defer db.Exec(t, `DROP TABLE dst`)
+			db.Exec(t, fmt.Sprintf(`IMPORT INTO dst PARQUET DATA ('%s')`, strings.Join(files, `', '`)))
+			db.CheckQueryResults(t,
+				`SELECT * FROM dst ORDER BY id`, db.QueryStr(t, `SELECT * FROM src ORDER BY id`),
+			)
+		})
+	}
+
+	t.Run("into-columns", func(t *testing.T) {
+		db.Exec(t, `EXPORT INTO PARQUET 'nodelocal://0/cols' FROM SELECT id, s AS name, f FROM src`)
+		db.Exec(t, `CREATE TABLE narrow (id INT PRIMARY KEY, name STRING, extra INT DEFAULT 7)`)
+		db.Exec(t, `IMPORT INTO narrow (id, name) PARQUET DATA ('nodelocal://0/cols/*.parquet')`)
+		db.CheckQueryResults(t,
+			`SELECT id, name, extra FROM narrow ORDER BY id LIMIT 2`,
+			[][]string{{"0", "NULL", "7"}, {"1", "row ✅ 1", "7"}},
+		)
+		db.ExpectErr(t, `could not find column for parquet column f`,
+			`IMPORT INTO narrow (id, name) PARQUET DATA ('nodelocal://0/cols/*.parquet') WITH strict_validation`)
+		db.ExpectErr(t, `parquet column "f" of type DOUBLE cannot be imported into column "f" of type STRING`,
+			`IMPORT TABLE other (id INT PRIMARY KEY, f STRING) PARQUET DATA ('nodelocal://0/cols/*.parquet')`)
+	})
+}
+
+func TestExportParquetErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	srv, conn, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	db := sqlutils.MakeSQLRunner(conn)
+	db.Exec(t, `CREATE TABLE foo (a INT, b INT)`)
+
+	db.ExpectErr(t, `invalid option "delimiter" specified for PARQUET export format`,
+		`EXPORT INTO PARQUET 'nodelocal://0/t' WITH delimiter = '|' FROM SELECT * FROM foo`)
+	db.ExpectErr(t, `invalid option "row_group_size" specified for CSV export format`,
+		`EXPORT INTO CSV 'nodelocal://0/t' WITH row_group_size = '1MiB' FROM SELECT * FROM foo`)
+	db.ExpectErr(t, `unsupported compression codec lz4`,
+		`EXPORT INTO PARQUET 'nodelocal://0/t' WITH compression = 'lz4' FROM SELECT * FROM foo`)
+	db.ExpectErr(t, `duplicate column name "a" in PARQUET export`,
+		`EXPORT INTO PARQUET 'nodelocal://0/t' FROM SELECT a, a FROM foo`)
+}
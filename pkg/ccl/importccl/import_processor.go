@@ -243,6 +243,10 @@ func makeInputConverter(
 		return newAvroInputReader(
 			kvCh, singleTable, spec.Format.Avro, spec.WalltimeNanos,
 			int(spec.ReaderParallelism), evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		return newParquetInputReader(
+			kvCh, singleTable, singleTableTargetCols, spec.Format.Parquet, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), evalCtx)
 	default:
 		return nil, errors.Errorf(
 			"Requested IMPORT format (%d) not supported by this node", spec.Format.Format)
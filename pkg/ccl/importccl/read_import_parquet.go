@@ -0,0 +1,946 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// parquetKind classifies the columns of a parquet file by the logical type
+// (or the legacy converted type) annotating their physical type. It
+// determines how the values of the column are interpreted.
+type parquetKind int
+
+const (
+	// parquetPlain is a column without an annotation, or with an annotation
+	// which doesn't change how its values are interpreted (e.g. INT_16).
+	parquetPlain parquetKind = iota
+	parquetString
+	parquetUnsigned
+	parquetDecimal
+	parquetDate
+	parquetTime
+	parquetTimestamp
+	parquetUUID
+)
+
+// parquetColumn describes a flat column of a parquet file and the visible
+// column of the table it is imported into.
+type parquetColumn struct {
+	name string
+	idx  int
+	typ  parquet.Type
+	kind parquetKind
+	// unit is the duration of a unit of TIME and TIMESTAMP values.
+	unit time.Duration
+	// scale is the scale of DECIMAL values.
+	scale int32
+}
+
+// makeParquetColumn classifies a column of a parquet file, which is imported
+// into the visible column idx of the table. Nested and repeated columns, as
+// well as annotations we don't know how to interpret, are rejected.
+func makeParquetColumn(col *goparquet.Column, idx int) (parquetColumn, error) {
+	if strings.Contains(col.FlatName(), ".") || col.MaxRepetitionLevel() > 0 ||
+		*col.RepetitionType() == parquet.FieldRepetitionType_REPEATED {
+		return parquetColumn{}, errors.Errorf(
+			"parquet column %q: nested and repeated columns are not supported", col.FlatName())
+	}
+	elem := col.Element()
+	c := parquetColumn{name: col.FlatName(), idx: idx, typ: *col.Type()}
+
+	if lt := elem.LogicalType; lt != nil {
+		switch {
+		case lt.STRING != nil, lt.ENUM != nil, lt.JSON != nil:
+			c.kind = parquetString
+		case lt.INTEGER != nil:
+			if !lt.INTEGER.IsSigned && lt.INTEGER.BitWidth == 64 {
+				c.kind = parquetUnsigned
+			}
+		case lt.DECIMAL != nil:
+			c.kind, c.scale = parquetDecimal, lt.DECIMAL.Scale
+		case lt.DATE != nil:
+			c.kind = parquetDate
+		case lt.TIME != nil:
+			c.kind, c.unit = parquetTime, parquetTimeUnit(lt.TIME.Unit)
+		case lt.TIMESTAMP != nil:
+			c.kind, c.unit = parquetTimestamp, parquetTimeUnit(lt.TIMESTAMP.Unit)
+		case lt.UUID != nil:
+			c.kind = parquetUUID
+		case lt.UNKNOWN != nil:
+		default:
+			return parquetColumn{}, errors.Errorf(
+				"parquet column %q: unsupported logical type %s", c.name, lt)
+		}
+	} else if ct := elem.ConvertedType; ct != nil {
+		switch *ct {
+		case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON:
+			c.kind = parquetString
+		case parquet.ConvertedType_UINT_64:
+			c.kind = parquetUnsigned
+		case parquet.ConvertedType_INT_8, parquet.ConvertedType_INT_16,
+			parquet.ConvertedType_INT_32, parquet.ConvertedType_INT_64,
+			parquet.ConvertedType_UINT_8, parquet.ConvertedType_UINT_16,
+			parquet.ConvertedType_UINT_32:
+		case parquet.ConvertedType_DECIMAL:
+			c.kind, c.scale = parquetDecimal, elem.GetScale()
+		case parquet.ConvertedType_DATE:
+			c.kind = parquetDate
+		case parquet.ConvertedType_TIME_MILLIS:
+			c.kind, c.unit = parquetTime, time.Millisecond
+		case parquet.ConvertedType_TIME_MICROS:
+			c.kind, c.unit = parquetTime, time.Microsecond
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			c.kind, c.unit = parquetTimestamp, time.Millisecond
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			c.kind, c.unit = parquetTimestamp, time.Microsecond
+		default:
+			return parquetColumn{}, errors.Errorf(
+				"parquet column %q: unsupported converted type %s", c.name, ct)
+		}
+	}
+	if c.typ == parquet.Type_INT96 {
+		// INT96 is the deprecated encoding of timestamps used by Impala and
+		// older versions of Spark and Hive.
+		c.kind = parquetTimestamp
+	}
+	return c, nil
+}
+
+func parquetTimeUnit(u *parquet.TimeUnit) time.Duration {
+	switch {
+	case u == nil:
+		return time.Microsecond
+	case u.MILLIS != nil:
+		return time.Millisecond
+	case u.NANOS != nil:
+		return time.Nanosecond
+	}
+	return time.Microsecond
+}
+
+// String returns a description of the type of the column used in errors.
+func (c *parquetColumn) String() string {
+	switch c.kind {
+	case parquetString:
+		return fmt.Sprintf("%s (STRING)", c.typ)
+	case parquetUnsigned:
+		return fmt.Sprintf("%s (UNSIGNED)", c.typ)
+	case parquetDecimal:
+		return fmt.Sprintf("%s (DECIMAL)", c.typ)
+	case parquetDate:
+		return fmt.Sprintf("%s (DATE)", c.typ)
+	case parquetTime:
+		return fmt.Sprintf("%s (TIME)", c.typ)
+	case parquetTimestamp:
+		return fmt.Sprintf("%s (TIMESTAMP)", c.typ)
+	case parquetUUID:
+		return fmt.Sprintf("%s (UUID)", c.typ)
+	}
+	return c.typ.String()
+}
+
+// convertibleTo returns whether the values of the column can be imported into
+// a column of type t. Byte arrays which aren't otherwise annotated can be
+// imported into columns of any type, they are parsed like CSV values.
+func (c *parquetColumn) convertibleTo(t *types.T) bool {
+	f := t.Family()
+	switch c.kind {
+	case parquetString:
+		return true
+	case parquetUnsigned:
+		return f == types.IntFamily || f == types.DecimalFamily || f == types.FloatFamily
+	case parquetDecimal:
+		return f == types.DecimalFamily || f == types.FloatFamily
+	case parquetDate:
+		return f == types.DateFamily || f == types.TimestampFamily || f == types.TimestampTZFamily
+	case parquetTime:
+		return f == types.TimeFamily
+	case parquetTimestamp:
+		return f == types.TimestampFamily || f == types.TimestampTZFamily
+	case parquetUUID:
+		return f == types.UuidFamily || f == types.StringFamily || f == types.BytesFamily
+	}
+	switch c.typ {
+	case parquet.Type_BOOLEAN:
+		return f == types.BoolFamily
+	case parquet.Type_INT32, parquet.Type_INT64:
+		return f == types.IntFamily || f == types.DecimalFamily || f == types.FloatFamily
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return f == types.FloatFamily || f == types.DecimalFamily
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return true
+	}
+	return false
+}
+
+// toDatum converts a value of the column, as returned by the parquet library,
+// to a datum of type t.
+func (c *parquetColumn) toDatum(
+	v interface{}, t *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if v == nil {
+		// Let the target table schema verify whether nulls are allowed.
+		return tree.DNull, nil
+	}
+
+	switch c.kind {
+	case parquetString:
+		b, ok := v.([]byte)
+		if !ok {
+			break
+		}
+		if t.Family() == types.BytesFamily {
+			return tree.NewDBytes(tree.DBytes(b)), nil
+		}
+		return rowenc.ParseDatumStringAs(t, string(b), evalCtx)
+
+	case parquetUnsigned:
+		var u uint64
+		switch x := v.(type) {
+		case uint32:
+			u = uint64(x)
+		case uint64:
+			u = x
+		default:
+			return nil, errors.Errorf("unexpected value of type %T for %s", v, c)
+		}
+		if u > math.MaxInt64 {
+			return nil, errors.Errorf("value %d out of range for %s", u, t.SQLString())
+		}
+		return parquetIntToDatum(int64(u), t)
+
+	case parquetDecimal:
+		var coeff big.Int
+		switch x := v.(type) {
+		case int32:
+			coeff.SetInt64(int64(x))
+		case int64:
+			coeff.SetInt64(x)
+		case []byte:
+			// Decimals stored in byte arrays are big-endian two's complement.
+			coeff.SetBytes(x)
+			if len(x) > 0 && x[0]&0x80 != 0 {
+				coeff.Sub(&coeff, new(big.Int).Lsh(big.NewInt(1), uint(len(x))*8))
+			}
+		default:
+			return nil, errors.Errorf("unexpected value of type %T for %s", v, c)
+		}
+		d := &tree.DDecimal{Decimal: *apd.NewWithBigInt(&coeff, -c.scale)}
+		if t.Family() == types.FloatFamily {
+			f, err := d.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDFloat(tree.DFloat(f)), nil
+		}
+		return d, nil
+
+	case parquetDate:
+		days, ok := parquetInt(v)
+		if !ok {
+			break
+		}
+		if t.Family() == types.DateFamily {
+			d, err := pgdate.MakeDateFromUnixEpoch(days)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDDate(d), nil
+		}
+		return parquetTimestampToDatum(timeutil.Unix(days*secondsPerDay, 0), t)
+
+	case parquetTime:
+		i, ok := parquetInt(v)
+		if !ok {
+			break
+		}
+		return tree.MakeDTime(timeofday.FromInt(i * int64(c.unit/time.Microsecond))), nil
+
+	case parquetTimestamp:
+		if b, ok := v.([12]byte); ok {
+			return parquetTimestampToDatum(goparquet.Int96ToTime(b), t)
+		}
+		i, ok := parquetInt(v)
+		if !ok {
+			break
+		}
+		return parquetTimestampToDatum(timeutil.Unix(0, i*int64(c.unit)), t)
+
+	case parquetUUID:
+		b, ok := v.([]byte)
+		if !ok {
+			break
+		}
+		u, err := uuid.FromBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		switch t.Family() {
+		case types.StringFamily:
+			return tree.NewDString(u.String()), nil
+		case types.BytesFamily:
+			return tree.NewDBytes(tree.DBytes(u.GetBytes())), nil
+		}
+		return tree.NewDUuid(tree.DUuid{UUID: u}), nil
+
+	default:
+		switch x := v.(type) {
+		case bool:
+			return tree.MakeDBool(tree.DBool(x)), nil
+		case int32:
+			return parquetIntToDatum(int64(x), t)
+		case int64:
+			return parquetIntToDatum(x, t)
+		case float32:
+			return parquetFloatToDatum(float64(x), t)
+		case float64:
+			return parquetFloatToDatum(x, t)
+		case []byte:
+			if t.Family() == types.BytesFamily {
+				return tree.NewDBytes(tree.DBytes(x)), nil
+			}
+			return rowenc.ParseDatumStringAs(t, string(x), evalCtx)
+		}
+	}
+	return nil, errors.Errorf("unexpected value of type %T for %s", v, c)
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+func parquetInt(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	}
+	return 0, false
+}
+
+func parquetIntToDatum(i int64, t *types.T) (tree.Datum, error) {
+	switch t.Family() {
+	case types.FloatFamily:
+		return tree.NewDFloat(tree.DFloat(i)), nil
+	case types.DecimalFamily:
+		d := &tree.DDecimal{}
+		d.SetInt64(i)
+		return d, nil
+	}
+	return tree.NewDInt(tree.DInt(i)), nil
+}
+
+func parquetFloatToDatum(f float64, t *types.T) (tree.Datum, error) {
+	if t.Family() == types.DecimalFamily {
+		d := &tree.DDecimal{}
+		if _, err := d.SetFloat64(f); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+	return tree.NewDFloat(tree.DFloat(f)), nil
+}
+
+func parquetTimestampToDatum(ts time.Time, t *types.T) (tree.Datum, error) {
+	if t.Family() == types.TimestampTZFamily {
+		return tree.MakeDTimestampTZ(ts, time.Microsecond)
+	}
+	return tree.MakeDTimestamp(ts, time.Microsecond)
+}
+
+// parquetConsumer implements importRowConsumer interface.
+type parquetConsumer struct {
+	columns []parquetColumn
+}
+
+var _ importRowConsumer = &parquetConsumer{}
+
+// FillDatums implements importRowConsumer interface.
+func (p *parquetConsumer) FillDatums(
+	native interface{}, rowIndex int64, conv *row.DatumRowConverter,
+) error {
+	record, ok := native.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("unexpected native type; expected map[string]interface{} found %T instead", native)
+	}
+	for i := range p.columns {
+		col := &p.columns[i]
+		datum, err := col.toDatum(record[col.name], conv.VisibleColTypes[col.idx], conv.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "parquet column %q", col.name)
+		}
+		conv.Datums[col.idx] = datum
+	}
+
+	// Set any nil datums to DNull (in case the file didn't have the
+	// column at all).
+	for i := range conv.Datums {
+		if conv.TargetColOrds.Contains(i) && conv.Datums[i] == nil {
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// parquetRowGroup holds the decoded rows of a row group of a parquet file.
+type parquetRowGroup struct {
+	// rows are the decoded rows. It is nil if the row group was skipped, in
+	// which case numRows placeholder rows are produced for it.
+	rows    []map[string]interface{}
+	numRows int64
+	err     error
+}
+
+// parquetRowGroupStream produces the rows of a parquet file. Up to numWorkers
+// row groups are decoded concurrently ahead of the rows being consumed, but
+// rows are always produced in the order in which they appear in the file.
+type parquetRowGroupStream struct {
+	file       *parquetFile
+	meta       *parquet.FileMetaData
+	columns    []string
+	numWorkers int
+	// skip is the number of rows at the start of the file which are going to
+	// be skipped. Row groups which only contain such rows aren't decoded.
+	skip int64
+
+	ctx     context.Context
+	group   ctxgroup.Group
+	pending chan chan parquetRowGroup
+
+	cur      parquetRowGroup
+	curIdx   int64
+	produced int64
+	row      map[string]interface{}
+	err      error
+}
+
+var _ importRowProducer = &parquetRowGroupStream{}
+
+// start starts decoding the row groups of the file. The decoding goroutines
+// exit once ctx is canceled, which callers need to do once they are done
+// consuming rows.
+func (p *parquetRowGroupStream) start(ctx context.Context) {
+	p.ctx = ctx
+	p.group = ctxgroup.WithContext(ctx)
+	// The size of the pending queue bounds the number of row groups which are
+	// buffered in memory ahead of the consumer.
+	p.pending = make(chan chan parquetRowGroup, p.numWorkers)
+	p.group.GoCtx(func(ctx context.Context) error {
+		defer close(p.pending)
+		var firstRow int64
+		for i, rg := range p.meta.RowGroups {
+			i, numRows := i, rg.NumRows
+			res := make(chan parquetRowGroup, 1)
+			select {
+			case p.pending <- res:
+			case <-ctx.Done():
+				return nil
+			}
+			if firstRow+numRows <= p.skip {
+				res <- parquetRowGroup{numRows: numRows}
+			} else {
+				p.group.GoCtx(func(ctx context.Context) error {
+					rows, err := p.decodeRowGroup(ctx, i)
+					res <- parquetRowGroup{rows: rows, numRows: numRows, err: err}
+					return nil
+				})
+			}
+			firstRow += numRows
+		}
+		return nil
+	})
+}
+
+// wait waits for the decoding goroutines to exit.
+func (p *parquetRowGroupStream) wait() {
+	_ = p.group.Wait()
+}
+
+// decodeRowGroup decodes the rows of the i-th row group of the file. Every
+// row group is decoded with its own reader so that they can be decoded
+// concurrently.
+func (p *parquetRowGroupStream) decodeRowGroup(
+	ctx context.Context, i int,
+) ([]map[string]interface{}, error) {
+	f := p.file.newReader(ctx)
+	defer f.Close()
+	r, err := goparquet.NewFileReaderWithMetaData(f, p.meta, p.columns...)
+	if err != nil {
+		return nil, err
+	}
+	// NB: row groups are numbered from 1 by SeekToRowGroup.
+	if err := r.SeekToRowGroup(i + 1); err != nil {
+		return nil, errors.Wrapf(err, "reading parquet row group %d", i)
+	}
+	rows := make([]map[string]interface{}, p.meta.RowGroups[i].NumRows)
+	for j := range rows {
+		if rows[j], err = r.NextRow(); err != nil {
+			return nil, errors.Wrapf(err, "reading parquet row group %d", i)
+		}
+	}
+	return rows, nil
+}
+
+// Scan implements importRowProducer interface.
+func (p *parquetRowGroupStream) Scan() bool {
+	for p.err == nil && p.curIdx >= p.cur.numRows {
+		var res chan parquetRowGroup
+		var ok bool
+		select {
+		case res, ok = <-p.pending:
+			if !ok {
+				return false
+			}
+		case <-p.ctx.Done():
+			p.err = p.ctx.Err()
+			return false
+		}
+		select {
+		case p.cur = <-res:
+			p.curIdx = 0
+			p.err = p.cur.err
+		case <-p.ctx.Done():
+			p.err = p.ctx.Err()
+		}
+	}
+	if p.err != nil {
+		return false
+	}
+	if p.cur.rows != nil {
+		p.row = p.cur.rows[p.curIdx]
+		// Release the row as soon as it is handed to the consumers.
+		p.cur.rows[p.curIdx] = nil
+	}
+	p.curIdx++
+	p.produced++
+	return true
+}
+
+// Err implements importRowProducer interface.
+func (p *parquetRowGroupStream) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *parquetRowGroupStream) Skip() error {
+	p.row = nil
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *parquetRowGroupStream) Row() (interface{}, error) {
+	if p.row == nil {
+		return nil, errors.AssertionFailedf("row %d of parquet file was not decoded", p.produced)
+	}
+	res := p.row
+	p.row = nil
+	return res, nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *parquetRowGroupStream) Progress() float32 {
+	if p.meta.NumRows == 0 {
+		return 0
+	}
+	return float32(p.produced) / float32(p.meta.NumRows)
+}
+
+type parquetInputReader struct {
+	importContext *parallelImportContext
+	opts          roachpb.ParquetOptions
+}
+
+var _ inputConverter = &parquetInputReader{}
+
+func newParquetInputReader(
+	kvCh chan row.KVBatch,
+	tableDesc catalog.TableDescriptor,
+	targetCols tree.NameList,
+	opts roachpb.ParquetOptions,
+	walltime int64,
+	parallelism int,
+	evalCtx *tree.EvalContext,
+) (*parquetInputReader, error) {
+	return &parquetInputReader{
+		importContext: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts: opts,
+	}, nil
+}
+
+func (p *parquetInputReader) start(group ctxgroup.Group) {}
+
+// readFiles implements the inputConverter interface. Unlike the other
+// formats, parquet files aren't read as a stream: their metadata is at the end
+// of the file and row groups are located by their offsets, so they are read
+// through a parquetFile instead of readInputFiles.
+func (p *parquetInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	for dataFileIndex, dataFile := range dataFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.readFile(
+			ctx, dataFile, dataFileIndex, resumePos[dataFileIndex], format, makeExternalStorage, user,
+		); err != nil {
+			return errors.Wrapf(err, "%s", dataFile)
+		}
+	}
+	return nil
+}
+
+// parquetReadBufferSize is the size of the buffer of the readers of parquet
+// files stored in external storage.
+const parquetReadBufferSize = 64 << 10
+
+// parquetFile gives random access to the contents of a parquet file. Files
+// which can be read at arbitrary offsets from external storage are read from
+// it directly. Other files, e.g. compressed ones, are buffered in memory.
+type parquetFile struct {
+	es   cloud.ExternalStorage
+	size int64
+
+	// data holds the contents of the file if it is buffered in memory. The
+	// memory is accounted for by acc.
+	data []byte
+	acc  mon.BoundAccount
+}
+
+// openParquetFile prepares the parquet file stored in es for reading. If the
+// file has to be buffered in memory, it is accounted for against monitor.
+func openParquetFile(
+	ctx context.Context,
+	es cloud.ExternalStorage,
+	compression roachpb.IOFileFormat_Compression,
+	monitor *mon.BytesMonitor,
+) (*parquetFile, error) {
+	f := &parquetFile{es: es}
+	if compression == roachpb.IOFileFormat_None {
+		if sz, err := es.Size(ctx, ""); err == nil && sz > 0 {
+			f.size = sz
+			return f, nil
+		}
+	}
+
+	raw, err := es.ReadFile(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	defer raw.Close()
+	decompressed, err := decompressingReader(raw, "", compression)
+	if err != nil {
+		return nil, err
+	}
+	defer decompressed.Close()
+	f.acc = monitor.MakeBoundAccount()
+	if err := f.buffer(ctx, decompressed); err != nil {
+		f.close(ctx)
+		return nil, err
+	}
+	return f, nil
+}
+
+// buffer reads the contents of the file from r into memory, growing the memory
+// account before reading every chunk of it.
+func (f *parquetFile) buffer(ctx context.Context, r io.Reader) error {
+	const chunkSize = 1 << 20
+	var buf bytes.Buffer
+	for {
+		if err := f.acc.Grow(ctx, chunkSize); err != nil {
+			return errors.Wrap(err, "buffering parquet file")
+		}
+		n, err := buf.ReadFrom(io.LimitReader(r, chunkSize))
+		if err != nil {
+			return err
+		}
+		if n < chunkSize {
+			break
+		}
+	}
+	f.data, f.size = buf.Bytes(), int64(buf.Len())
+	return f.acc.ResizeTo(ctx, int64(cap(f.data)))
+}
+
+// newReader returns a reader over the contents of the file. Every reader has
+// its own position, so that multiple readers can be used concurrently.
+func (f *parquetFile) newReader(ctx context.Context) parquetReader {
+	if f.data != nil {
+		return nopReadSeekCloser{bytes.NewReader(f.data)}
+	}
+	return &storageReadSeeker{ctx: ctx, es: f.es, size: f.size}
+}
+
+// close releases the memory used by the file.
+func (f *parquetFile) close(ctx context.Context) {
+	f.data = nil
+	f.acc.Close(ctx)
+}
+
+// parquetReader is a reader over the contents of a parquet file.
+type parquetReader interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+// Close implements the io.Closer interface.
+func (nopReadSeekCloser) Close() error {
+	return nil
+}
+
+// storageReadSeeker reads a file of an external storage at arbitrary offsets.
+// The file is read as a stream from the current position. The stream is only
+// opened by the first read following a seek, and seeking elsewhere than the
+// current position closes it.
+type storageReadSeeker struct {
+	ctx  context.Context
+	es   cloud.ExternalStorage
+	size int64
+	pos  int64
+
+	stream io.ReadCloser
+	buf    *bufio.Reader
+}
+
+var _ parquetReader = &storageReadSeeker{}
+
+// Read implements the io.Reader interface.
+func (s *storageReadSeeker) Read(p []byte) (int, error) {
+	if s.pos >= s.size {
+		return 0, io.EOF
+	}
+	if s.stream == nil {
+		stream, _, err := s.es.ReadFileAt(s.ctx, "", s.pos)
+		if err != nil {
+			return 0, err
+		}
+		s.stream = stream
+		if s.buf == nil {
+			s.buf = bufio.NewReaderSize(stream, parquetReadBufferSize)
+		} else {
+			s.buf.Reset(stream)
+		}
+	}
+	n, err := s.buf.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+// Seek implements the io.Seeker interface.
+func (s *storageReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		pos += s.pos
+	case io.SeekEnd:
+		pos += s.size
+	default:
+		return 0, errors.AssertionFailedf("invalid whence %d", whence)
+	}
+	if pos < 0 {
+		return 0, errors.Errorf("seeking to negative position %d", pos)
+	}
+	if pos == s.pos {
+		return pos, nil
+	}
+	// Skip ahead within the buffered data rather than reopening the stream.
+	if s.stream != nil && pos > s.pos && pos-s.pos <= int64(s.buf.Buffered()) {
+		_, err := s.buf.Discard(int(pos - s.pos))
+		s.pos = pos
+		return pos, err
+	}
+	err := s.closeStream()
+	s.pos = pos
+	return pos, err
+}
+
+func (s *storageReadSeeker) closeStream() error {
+	if s.stream == nil {
+		return nil
+	}
+	err := s.stream.Close()
+	s.stream = nil
+	return err
+}
+
+// Close implements the io.Closer interface.
+func (s *storageReadSeeker) Close() error {
+	return s.closeStream()
+}
+
+// newImportParquetPipeline matches the columns of the parquet file to the
+// columns of the table being imported into.
+func newImportParquetPipeline(
+	ctx context.Context, p *parquetInputReader, file *parquetFile,
+) (*parquetRowGroupStream, importRowConsumer, error) {
+	f := file.newReader(ctx)
+	defer f.Close()
+	meta, err := goparquet.ReadFileMetaData(f, true /* extraValidation */)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reading parquet file metadata")
+	}
+	r, err := goparquet.NewFileReaderWithMetaData(f, meta)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reading parquet file schema")
+	}
+
+	colIdxByName := make(map[string]int)
+	for idx, col := range p.importContext.tableDesc.VisibleColumns() {
+		colIdxByName[col.GetName()] = idx
+	}
+	targetCols := make(map[string]struct{})
+	for _, name := range p.importContext.targetCols {
+		targetCols[string(name)] = struct{}{}
+	}
+
+	consumer := &parquetConsumer{}
+	producer := &parquetRowGroupStream{
+		file:       file,
+		meta:       meta,
+		numWorkers: p.importContext.numWorkers,
+	}
+	if producer.numWorkers <= 0 {
+		producer.numWorkers = 1
+	}
+	seen := make(map[int]struct{})
+	for _, col := range r.Columns() {
+		// Nested columns are named after their top level group.
+		name := lexbase.NormalizeName(strings.SplitN(col.FlatName(), ".", 2)[0])
+		idx, ok := colIdxByName[name]
+		if _, isTarget := targetCols[name]; ok && len(targetCols) > 0 && !isTarget {
+			ok = false
+		}
+		if !ok {
+			if p.opts.StrictMode {
+				return nil, nil, errors.Errorf("could not find column for parquet column %s", col.FlatName())
+			}
+			continue
+		}
+		pc, err := makeParquetColumn(col, idx)
+		if err != nil {
+			return nil, nil, err
+		}
+		visibleCol := p.importContext.tableDesc.VisibleColumns()[idx]
+		if !pc.convertibleTo(visibleCol.GetType()) {
+			return nil, nil, errors.Errorf(
+				"parquet column %q of type %s cannot be imported into column %q of type %s",
+				pc.name, &pc, visibleCol.GetName(), visibleCol.GetType().SQLString())
+		}
+		seen[idx] = struct{}{}
+		consumer.columns = append(consumer.columns, pc)
+		producer.columns = append(producer.columns, pc.name)
+	}
+	if len(consumer.columns) == 0 {
+		return nil, nil, errors.Errorf(
+			"none of the columns of the parquet file match the columns of table %s",
+			p.importContext.tableDesc.GetName())
+	}
+	if p.opts.StrictMode {
+		for idx, col := range p.importContext.tableDesc.VisibleColumns() {
+			if _, isTarget := targetCols[col.GetName()]; len(targetCols) > 0 && !isTarget {
+				continue
+			}
+			if _, ok := seen[idx]; !ok {
+				return nil, nil, errors.Errorf("column %s was not set in the parquet import", col.GetName())
+			}
+		}
+	}
+	return producer, consumer, nil
+}
+
+func (p *parquetInputReader) readFile(
+	ctx context.Context,
+	dataFile string,
+	inputIdx int32,
+	resumePos int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	conf, err := cloudimpl.ExternalStorageConfFromURI(dataFile, user)
+	if err != nil {
+		return err
+	}
+	es, err := makeExternalStorage(ctx, conf)
+	if err != nil {
+		return err
+	}
+	defer es.Close()
+	file, err := openParquetFile(
+		ctx, es, guessCompressionFromName(dataFile, format.Compression), p.importContext.evalCtx.Mon,
+	)
+	if err != nil {
+		return err
+	}
+	defer file.close(ctx)
+
+	producer, consumer, err := newImportParquetPipeline(ctx, p, file)
+	if err != nil {
+		return err
+	}
+	producer.skip = resumePos
+
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rowLimit: p.opts.RowLimit,
+	}
+	// Stop decoding row groups once the import of the file is done, e.g.
+	// because it reached the row limit.
+	ctx, cancel := context.WithCancel(ctx)
+	producer.start(ctx)
+	defer producer.wait()
+	defer cancel()
+	return runParallelImport(ctx, p.importContext, fileCtx, producer, consumer)
+}
@@ -0,0 +1,319 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/blobs"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// makeTestParquetFile writes the rows to a parquet file with the given
+// schema, flushing a row group every rowsPerGroup rows.
+func makeTestParquetFile(
+	t *testing.T, schema string, rowsPerGroup int, rows []map[string]interface{},
+) []byte {
+	sd, err := parquetschema.ParseSchemaDefinition(schema)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd))
+	for i, r := range rows {
+		require.NoError(t, w.AddData(r))
+		if (i+1)%rowsPerGroup == 0 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParquetRowGroupStream(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numRows, rowsPerGroup = 70, 7
+	rows := make([]map[string]interface{}, numRows)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": int64(i)}
+	}
+	data := makeTestParquetFile(t, `message test { required int64 id; }`, rowsPerGroup, rows)
+
+	newStream := func(t *testing.T, skip int64) *parquetRowGroupStream {
+		meta, err := goparquet.ReadFileMetaData(bytes.NewReader(data), true)
+		require.NoError(t, err)
+		require.Len(t, meta.RowGroups, numRows/rowsPerGroup)
+		return &parquetRowGroupStream{
+			file: &parquetFile{data: data}, meta: meta, columns: []string{"id"}, numWorkers: 3, skip: skip,
+		}
+	}
+
+	for _, skip := range []int64{0, 5, 7, 31, 69, 70} {
+		t.Run(fmt.Sprintf("skip=%d", skip), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			p := newStream(t, skip)
+			p.start(ctx)
+			defer p.wait()
+
+			var count int64
+			for p.Scan() {
+				count++
+				if count <= skip {
+					require.NoError(t, p.Skip())
+					continue
+				}
+				row, err := p.Row()
+				require.NoError(t, err)
+				require.Equal(t, count-1, row.(map[string]interface{})["id"])
+			}
+			require.NoError(t, p.Err())
+			require.Equal(t, int64(numRows), count)
+			require.Equal(t, float32(1), p.Progress())
+		})
+	}
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := newStream(t, 0)
+		p.start(ctx)
+		require.True(t, p.Scan())
+		cancel()
+		p.wait()
+		for p.Scan() {
+		}
+		require.Equal(t, context.Canceled, p.Err())
+	})
+}
+
+// TestParquetFile checks that uncompressed parquet files are read from
+// external storage at the offsets of their row groups, while compressed ones
+// are buffered in memory against the memory monitor.
+func TestParquetFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	const numRows, rowsPerGroup = 1000, 100
+	rows := make([]map[string]interface{}, numRows)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": int64(i), "s": []byte(fmt.Sprintf("row %d", i))}
+	}
+	data := makeTestParquetFile(t,
+		`message test { required int64 id; required binary s (STRING); }`, rowsPerGroup, rows)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data.parquet"), data, 0644))
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	_, err := gzw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data.parquet.gz"), gz.Bytes(), 0644))
+
+	settings := cluster.MakeTestingClusterSettings()
+	openStorage := func(t *testing.T, name string) cloud.ExternalStorage {
+		es, err := cloudimpl.ExternalStorageFromURI(ctx, "nodelocal://0/"+name,
+			base.ExternalIODirConfig{}, settings, blobs.TestBlobServiceClient(dir),
+			security.RootUserName(), nil, nil)
+		require.NoError(t, err)
+		return es
+	}
+	newMonitor := func(limit int64) *mon.BytesMonitor {
+		m := mon.NewMonitorWithLimit("test", mon.MemoryResource, limit,
+			nil /* curCount */, nil /* maxHist */, -1 /* increment */, math.MaxInt64, settings)
+		m.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+		return m
+	}
+	readRows := func(t *testing.T, f *parquetFile) {
+		r := f.newReader(ctx)
+		meta, err := goparquet.ReadFileMetaData(r, true /* extraValidation */)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Len(t, meta.RowGroups, numRows/rowsPerGroup)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		p := &parquetRowGroupStream{file: f, meta: meta, columns: []string{"id", "s"}, numWorkers: 3}
+		p.start(ctx)
+		defer p.wait()
+		var count int64
+		for p.Scan() {
+			row, err := p.Row()
+			require.NoError(t, err)
+			require.Equal(t, count, row.(map[string]interface{})["id"])
+			require.Equal(t, []byte(fmt.Sprintf("row %d", count)), row.(map[string]interface{})["s"])
+			count++
+		}
+		require.NoError(t, p.Err())
+		require.Equal(t, int64(numRows), count)
+	}
+
+	t.Run("uncompressed", func(t *testing.T) {
+		es := openStorage(t, "data.parquet")
+		defer es.Close()
+		m := newMonitor(0 /* limit */)
+		defer m.Stop(ctx)
+
+		f, err := openParquetFile(ctx, es, roachpb.IOFileFormat_None, m)
+		require.NoError(t, err)
+		defer f.close(ctx)
+		require.Nil(t, f.data)
+		require.Equal(t, int64(len(data)), f.size)
+		readRows(t, f)
+		require.Zero(t, m.AllocBytes())
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		es := openStorage(t, "data.parquet.gz")
+		defer es.Close()
+		m := newMonitor(0 /* limit */)
+		defer m.Stop(ctx)
+
+		f, err := openParquetFile(ctx, es, roachpb.IOFileFormat_Gzip, m)
+		require.NoError(t, err)
+		require.Equal(t, data, f.data)
+		require.GreaterOrEqual(t, m.AllocBytes(), int64(len(data)))
+		readRows(t, f)
+		f.close(ctx)
+		require.Zero(t, m.AllocBytes())
+	})
+
+	t.Run("gzip-over-budget", func(t *testing.T) {
+		es := openStorage(t, "data.parquet.gz")
+		defer es.Close()
+		m := newMonitor(int64(len(data)) / 2)
+		defer m.Stop(ctx)
+
+		_, err := openParquetFile(ctx, es, roachpb.IOFileFormat_Gzip, m)
+		require.Error(t, err)
+		require.Regexp(t, "memory budget exceeded", err)
+		require.Zero(t, m.AllocBytes())
+	})
+}
+
+func TestParquetColumnConversion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const schema = `message test {
+		optional binary s (STRING);
+		optional binary raw;
+		optional int32 d (DATE);
+		optional int64 ts (TIMESTAMP(MILLIS, true));
+		optional int96 legacy;
+		optional int64 tod (TIME_MICROS);
+		optional fixed_len_byte_array(4) dec (DECIMAL(9, 2));
+		optional int64 unsigned (INT(64, false));
+		optional fixed_len_byte_array(16) u (UUID);
+		optional group nested { optional int32 x; }
+		repeated int32 list;
+	}`
+	u := uuid.MakeV4()
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 8e6, time.UTC)
+	data := makeTestParquetFile(t, schema, 1, []map[string]interface{}{{
+		"s":      []byte("ARRAY[1,2]"),
+		"raw":    []byte("192.168.0.1"),
+		"d":      int32(18000),
+		"ts":     ts.UnixNano() / int64(time.Millisecond),
+		"legacy": goparquet.TimeToInt96(ts),
+		"tod":    int64(3723000004),
+		"dec":    []byte{0xff, 0xff, 0xfe, 0x0c},
+		"u":      u.GetBytes(),
+		"nested": map[string]interface{}{"x": int32(1)},
+		"list":   []int32{1, 2},
+	}})
+	r, err := goparquet.NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	// The writer of the parquet library cannot encode unsigned 64-bit integers,
+	// so inject the value the reader would return for them.
+	row["unsigned"] = uint64(math.MaxUint64)
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	for _, tc := range []struct {
+		col      string
+		typ      *types.T
+		expected string
+		err      string
+	}{
+		{col: "s", typ: types.MakeArray(types.Int), expected: `ARRAY[1,2]`},
+		{col: "s", typ: types.Bytes, expected: `'\x41525241595b312c325d'`},
+		{col: "raw", typ: types.INet, expected: `'192.168.0.1'`},
+		{col: "d", typ: types.Date, expected: `'2019-04-14'`},
+		{col: "d", typ: types.Timestamp, expected: `'2019-04-14 00:00:00'`},
+		{col: "ts", typ: types.TimestampTZ, expected: `'2021-03-04 05:06:07.008+00:00'`},
+		{col: "legacy", typ: types.Timestamp, expected: `'2021-03-04 05:06:07.008'`},
+		{col: "tod", typ: types.Time, expected: `'01:02:03.000004'`},
+		{col: "dec", typ: types.Decimal, expected: `-5.00`},
+		{col: "unsigned", typ: types.Int, err: `value 18446744073709551615 out of range for INT8`},
+		{col: "u", typ: types.Uuid, expected: fmt.Sprintf(`'%s'`, u)},
+		{col: "u", typ: types.String, expected: fmt.Sprintf(`'%s'`, u)},
+		{col: "nested.x", typ: types.Int, err: `parquet column "nested.x": nested and repeated columns are not supported`},
+		{col: "list", typ: types.Int, err: `parquet column "list": nested and repeated columns are not supported`},
+	} {
+		t.Run(fmt.Sprintf("%s/%s", tc.col, tc.typ), func(t *testing.T) {
+			col := r.GetColumnByName(tc.col)
+			require.NotNil(t, col)
+			pc, err := makeParquetColumn(col, 0)
+			if err == nil {
+				require.True(t, pc.convertibleTo(tc.typ))
+				var d tree.Datum
+				if d, err = pc.toDatum(row[tc.col], tc.typ, &evalCtx); err == nil {
+					require.Equal(t, tc.expected, tree.AsString(d))
+				}
+			}
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	// Columns which can't be imported into the type of the table column are
+	// rejected before reading any rows.
+	for _, tc := range []struct {
+		col string
+		typ *types.T
+	}{
+		{"d", types.Int},
+		{"ts", types.Date},
+		{"dec", types.String},
+	} {
+		pc, err := makeParquetColumn(r.GetColumnByName(tc.col), 0)
+		require.NoError(t, err)
+		require.False(t, pc.convertibleTo(tc.typ), "%s to %s", tc.col, tc.typ)
+	}
+}
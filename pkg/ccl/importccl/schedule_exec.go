@@ -0,0 +1,137 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+// scheduledExportExecutor creates an export job for each execution of an
+// export schedule.
+type scheduledExportExecutor struct {
+	metrics exportScheduleMetrics
+}
+
+type exportScheduleMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &exportScheduleMetrics{}
+
+// MetricStruct implements metric.Struct interface
+func (m *exportScheduleMetrics) MetricStruct() {}
+
+var _ jobs.ScheduledJobExecutor = &scheduledExportExecutor{}
+
+// ExecuteJob implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledExportExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := e.createExportJob(ctx, cfg, sj, txn); err != nil {
+		e.metrics.NumFailed.Inc(1)
+		return err
+	}
+	e.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (e *scheduledExportExecutor) createExportJob(
+	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+) error {
+	args := &ScheduledExportExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return errors.Wrap(err, "un-marshaling args")
+	}
+	export, file, err := parseExportStatement(args.ExportStatement)
+	if err != nil {
+		return err
+	}
+	redacted, err := redactedExportNode(export, file)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(ctx, "Starting scheduled export %d: %s", sj.ScheduleID(), tree.AsString(redacted))
+
+	p, cleanup := cfg.PlanHookMaker("exec-export", txn, sj.Owner())
+	defer cleanup()
+	record := jobs.Record{
+		Description: tree.AsString(redacted),
+		Username:    sj.Owner(),
+		Details: jobspb.ExportDetails{
+			Statement: args.ExportStatement,
+			Database:  args.Database,
+		},
+		Progress: jobspb.ExportProgress{},
+		CreatedBy: &jobs.CreatedByInfo{
+			Name: jobs.CreatedByScheduledJobs,
+			ID:   sj.ScheduleID(),
+		},
+	}
+	_, err = p.(sql.PlanHookState).ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(ctx, record, txn)
+	return err
+}
+
+// NotifyJobTermination implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledExportExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID int64,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	schedule *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		e.metrics.NumSucceeded.Inc(1)
+		log.Infof(ctx, "export job %d scheduled by %d succeeded", jobID, schedule.ScheduleID())
+		return nil
+	}
+
+	e.metrics.NumFailed.Inc(1)
+	err := errors.Errorf(
+		"export job %d scheduled by %d failed with status %s",
+		jobID, schedule.ScheduleID(), jobStatus)
+	log.Errorf(ctx, "export error: %v", err)
+	jobs.DefaultHandleFailedRun(schedule, "export job %d failed with err=%v", jobID, err)
+	return nil
+}
+
+// Metrics implements ScheduledJobExecutor interface
+func (e *scheduledExportExecutor) Metrics() metric.Struct {
+	return &e.metrics
+}
+
+func init() {
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledExportExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledExportExecutor.UserName())
+			return &scheduledExportExecutor{
+				metrics: exportScheduleMetrics{ExecutorMetrics: &m},
+			}, nil
+		})
+}
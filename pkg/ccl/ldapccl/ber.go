@@ -0,0 +1,227 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains the subset of the Basic Encoding Rules (X.690) needed
+// to encode and decode LDAP messages (RFC 4511, section 5.1). Only the
+// definite length form is supported, which is the only one allowed by LDAP.
+
+// berClass is the class of a BER element.
+type berClass byte
+
+const (
+	classUniversal   berClass = 0x00
+	classApplication berClass = 0x40
+	classContext     berClass = 0x80
+)
+
+const berConstructed = 0x20
+
+// Tags of the universal types used by LDAP.
+const (
+	tagBoolean     = 1
+	tagInteger     = 2
+	tagOctetString = 4
+	tagEnumerated  = 10
+	tagSequence    = 16
+	tagSet         = 17
+)
+
+// maxBERElementSize bounds the size of the elements read from the network,
+// so that a misbehaving peer cannot make us allocate arbitrary amounts of
+// memory.
+const maxBERElementSize = 16 << 20
+
+// berElement is a decoded BER element. The value of primitive elements is
+// stored in value; constructed elements have children instead.
+type berElement struct {
+	class       berClass
+	constructed bool
+	tag         int
+	value       []byte
+	children    []*berElement
+}
+
+func newBERSequence(children ...*berElement) *berElement {
+	return &berElement{class: classUniversal, constructed: true, tag: tagSequence, children: children}
+}
+
+func newBERSet(children ...*berElement) *berElement {
+	return &berElement{class: classUniversal, constructed: true, tag: tagSet, children: children}
+}
+
+func newBERConstructed(class berClass, tag int, children ...*berElement) *berElement {
+	return &berElement{class: class, constructed: true, tag: tag, children: children}
+}
+
+func newBERPrimitive(class berClass, tag int, value []byte) *berElement {
+	return &berElement{class: class, tag: tag, value: value}
+}
+
+func newBEROctetString(s string) *berElement {
+	return newBERPrimitive(classUniversal, tagOctetString, []byte(s))
+}
+
+func newBERBoolean(b bool) *berElement {
+	v := byte(0)
+	if b {
+		v = 0xff
+	}
+	return newBERPrimitive(classUniversal, tagBoolean, []byte{v})
+}
+
+func newBERInteger(tag int, i int64) *berElement {
+	// Use the minimal two's complement representation.
+	n := 1
+	for n < 8 && (i >= 1<<(8*n-1) || i < -(1<<(8*n-1))) {
+		n++
+	}
+	v := make([]byte, n)
+	for j := n - 1; j >= 0; j-- {
+		v[j] = byte(i)
+		i >>= 8
+	}
+	return newBERPrimitive(classUniversal, tag, v)
+}
+
+// is returns whether the element has the given class and tag.
+func (e *berElement) is(class berClass, tag int) bool {
+	return e.class == class && e.tag == tag
+}
+
+// str returns the value of a primitive element as a string.
+func (e *berElement) str() string {
+	return string(e.value)
+}
+
+// integer decodes the value of an INTEGER or ENUMERATED element.
+func (e *berElement) integer() (int64, error) {
+	if e.constructed || len(e.value) == 0 || len(e.value) > 8 {
+		return 0, errors.New("invalid BER integer")
+	}
+	// Sign-extend the first byte.
+	i := int64(int8(e.value[0]))
+	for _, b := range e.value[1:] {
+		i = i<<8 | int64(b)
+	}
+	return i, nil
+}
+
+// encode appends the encoding of the element to buf.
+func (e *berElement) encode(buf []byte) []byte {
+	id := byte(e.class) | byte(e.tag)
+	content := e.value
+	if e.constructed {
+		id |= berConstructed
+		content = nil
+		for _, c := range e.children {
+			content = c.encode(content)
+		}
+	}
+	buf = append(buf, id)
+	if l := len(content); l < 0x80 {
+		buf = append(buf, byte(l))
+	} else {
+		var lenBytes []byte
+		for ; l > 0; l >>= 8 {
+			lenBytes = append([]byte{byte(l)}, lenBytes...)
+		}
+		buf = append(buf, 0x80|byte(len(lenBytes)))
+		buf = append(buf, lenBytes...)
+	}
+	return append(buf, content...)
+}
+
+// readBERElement reads and decodes an element from r.
+func readBERElement(r *bufio.Reader) (*berElement, error) {
+	id, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if id&0x1f == 0x1f {
+		return nil, errors.New("BER tags above 30 are not supported")
+	}
+	l, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length := int(l)
+	if l&0x80 != 0 {
+		n := int(l & 0x7f)
+		if n == 0 {
+			return nil, errors.New("indefinite BER lengths are not supported")
+		}
+		if n > 4 {
+			return nil, errors.New("BER element too large")
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxBERElementSize {
+		return nil, errors.New("BER element too large")
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return decodeBERContent(id, content)
+}
+
+func decodeBERContent(id byte, content []byte) (*berElement, error) {
+	e := &berElement{
+		class:       berClass(id & 0xc0),
+		constructed: id&berConstructed != 0,
+		tag:         int(id & 0x1f),
+	}
+	if !e.constructed {
+		e.value = content
+		return e, nil
+	}
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, errors.New("truncated BER element")
+		}
+		childID, l := content[0], int(content[1])
+		content = content[2:]
+		if l&0x80 != 0 {
+			n := l & 0x7f
+			if n == 0 || n > 4 || len(content) < n {
+				return nil, errors.New("invalid BER length")
+			}
+			l = 0
+			for _, b := range content[:n] {
+				l = l<<8 | int(b)
+			}
+			content = content[n:]
+		}
+		if l > len(content) {
+			return nil, errors.New("truncated BER element")
+		}
+		child, err := decodeBERContent(childID, content[:l])
+		if err != nil {
+			return nil, err
+		}
+		e.children = append(e.children, child)
+		content = content[l:]
+	}
+	return e, nil
+}
@@ -0,0 +1,285 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains a minimal synchronous LDAPv3 client (RFC 4511),
+// supporting the operations needed for authentication: simple binds,
+// searches and the StartTLS extended operation.
+
+// Application tags of the LDAP protocol operations.
+const (
+	appBindRequest       = 0
+	appBindResponse      = 1
+	appUnbindRequest     = 2
+	appSearchRequest     = 3
+	appSearchResultEntry = 4
+	appSearchResultDone  = 5
+	appSearchResultRef   = 19
+	appExtendedRequest   = 23
+	appExtendedResponse  = 24
+)
+
+// startTLSOID is the name of the StartTLS extended operation (RFC 4511,
+// section 4.14).
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Result codes of interest (RFC 4511, appendix A).
+const (
+	resultSuccess            = 0
+	resultInvalidCredentials = 49
+)
+
+// Search scopes.
+const (
+	scopeBaseObject   = 0
+	scopeWholeSubtree = 2
+)
+
+// ldapTimeout bounds the duration of each LDAP operation.
+const ldapTimeout = 10 * time.Second
+
+// ldapError is an error returned by the LDAP server.
+type ldapError struct {
+	op         string
+	resultCode int64
+	message    string
+}
+
+func (e *ldapError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("LDAP %s failed with result code %d", e.op, e.resultCode)
+	}
+	return fmt.Sprintf("LDAP %s failed with result code %d: %s", e.op, e.resultCode, e.message)
+}
+
+// isInvalidCredentials returns whether err indicates that a bind was
+// rejected because of invalid credentials.
+func isInvalidCredentials(err error) bool {
+	var lerr *ldapError
+	return errors.As(err, &lerr) && lerr.resultCode == resultInvalidCredentials
+}
+
+// ldapEntry is an entry returned by a search.
+type ldapEntry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+// ldapConn is a connection to an LDAP server. It is not safe for concurrent
+// use.
+type ldapConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	nextID int64
+}
+
+// dialLDAP connects to the LDAP server at addr. If tlsConfig is not nil, the
+// connection uses TLS from the start (LDAPS).
+func dialLDAP(ctx context.Context, addr string, tlsConfig *tls.Config) (*ldapConn, error) {
+	d := net.Dialer{Timeout: ldapTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to LDAP server %s", addr)
+	}
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		_ = tlsConn.SetDeadline(time.Now().Add(ldapTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, errors.Wrapf(err, "TLS handshake with LDAP server %s failed", addr)
+		}
+		conn = tlsConn
+	}
+	return &ldapConn{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// close sends an unbind request and closes the connection.
+func (c *ldapConn) close() {
+	_ = c.send(newBERPrimitive(classApplication, appUnbindRequest, nil))
+	_ = c.conn.Close()
+}
+
+// send sends a request with a new message ID.
+func (c *ldapConn) send(op *berElement) error {
+	c.nextID++
+	msg := newBERSequence(newBERInteger(tagInteger, c.nextID), op)
+	_ = c.conn.SetDeadline(time.Now().Add(ldapTimeout))
+	_, err := c.conn.Write(msg.encode(nil))
+	return err
+}
+
+// receive reads the next response to the last request, and returns its
+// protocol operation.
+func (c *ldapConn) receive() (*berElement, error) {
+	for {
+		msg, err := readBERElement(c.r)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading LDAP response")
+		}
+		if !msg.is(classUniversal, tagSequence) || len(msg.children) < 2 {
+			return nil, errors.New("malformed LDAP response")
+		}
+		id, err := msg.children[0].integer()
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			// Unsolicited notification, e.g. Notice of Disconnection.
+			return nil, errors.New("LDAP server closed the connection")
+		}
+		if id != c.nextID {
+			// Not a response to the current request; ignore it.
+			continue
+		}
+		return msg.children[1], nil
+	}
+}
+
+// checkResult decodes the LDAPResult contained in a response, and returns an
+// error if it does not indicate success.
+func checkResult(opName string, resp *berElement, expectedTag int) error {
+	if !resp.is(classApplication, expectedTag) || len(resp.children) < 3 {
+		return errors.Newf("unexpected response to LDAP %s", opName)
+	}
+	code, err := resp.children[0].integer()
+	if err != nil {
+		return err
+	}
+	if code != resultSuccess {
+		return &ldapError{op: opName, resultCode: code, message: resp.children[2].str()}
+	}
+	return nil
+}
+
+// startTLS upgrades the connection to TLS with the StartTLS extended
+// operation.
+func (c *ldapConn) startTLS(tlsConfig *tls.Config) error {
+	if err := c.send(newBERConstructed(classApplication, appExtendedRequest,
+		newBERPrimitive(classContext, 0, []byte(startTLSOID)),
+	)); err != nil {
+		return err
+	}
+	resp, err := c.receive()
+	if err != nil {
+		return err
+	}
+	if err := checkResult("StartTLS", resp, appExtendedResponse); err != nil {
+		return err
+	}
+	tlsConn := tls.Client(c.conn, tlsConfig)
+	_ = tlsConn.SetDeadline(time.Now().Add(ldapTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Wrap(err, "StartTLS handshake with LDAP server failed")
+	}
+	c.conn = tlsConn
+	c.r = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// bind performs a simple bind with the given DN and password.
+func (c *ldapConn) bind(dn, password string) error {
+	if err := c.send(newBERConstructed(classApplication, appBindRequest,
+		newBERInteger(tagInteger, 3 /* version */),
+		newBEROctetString(dn),
+		newBERPrimitive(classContext, 0 /* simple */, []byte(password)),
+	)); err != nil {
+		return err
+	}
+	resp, err := c.receive()
+	if err != nil {
+		return err
+	}
+	return checkResult("bind", resp, appBindResponse)
+}
+
+// search performs a search, and returns the matching entries with the
+// requested attributes. At most sizeLimit entries are returned if it is
+// positive.
+func (c *ldapConn) search(
+	baseDN string, scope int64, filter *berElement, attributes []string, sizeLimit int64,
+) ([]ldapEntry, error) {
+	attrs := newBERSequence()
+	for _, a := range attributes {
+		attrs.children = append(attrs.children, newBEROctetString(a))
+	}
+	if err := c.send(newBERConstructed(classApplication, appSearchRequest,
+		newBEROctetString(baseDN),
+		newBERInteger(tagEnumerated, scope),
+		newBERInteger(tagEnumerated, 0 /* neverDerefAliases */),
+		newBERInteger(tagInteger, sizeLimit),
+		newBERInteger(tagInteger, int64(ldapTimeout/time.Second)),
+		newBERBoolean(false /* typesOnly */),
+		filter,
+		attrs,
+	)); err != nil {
+		return nil, err
+	}
+	var entries []ldapEntry
+	for {
+		resp, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case resp.is(classApplication, appSearchResultEntry):
+			entry, err := decodeSearchResultEntry(resp)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case resp.is(classApplication, appSearchResultRef):
+			// Referrals are not followed.
+		default:
+			if err := checkResult("search", resp, appSearchResultDone); err != nil {
+				return nil, err
+			}
+			return entries, nil
+		}
+	}
+}
+
+func decodeSearchResultEntry(resp *berElement) (ldapEntry, error) {
+	if len(resp.children) != 2 {
+		return ldapEntry{}, errors.New("malformed LDAP search result")
+	}
+	entry := ldapEntry{dn: resp.children[0].str(), attributes: make(map[string][]string)}
+	for _, attr := range resp.children[1].children {
+		if len(attr.children) != 2 {
+			return ldapEntry{}, errors.New("malformed LDAP search result")
+		}
+		name := attr.children[0].str()
+		for _, v := range attr.children[1].children {
+			entry.attributes[name] = append(entry.attributes[name], v.str())
+		}
+	}
+	return entry, nil
+}
+
+// getAttribute returns the values of an attribute of the entry. Attribute
+// names are case-insensitive.
+func (e ldapEntry) getAttribute(name string) []string {
+	for n, v := range e.attributes {
+		if strings.EqualFold(n, name) {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Context tags of the Filter choice (RFC 4511, section 4.5.1).
+const (
+	filterAnd            = 0
+	filterOr             = 1
+	filterNot            = 2
+	filterEqualityMatch  = 3
+	filterSubstrings     = 4
+	filterGreaterOrEqual = 5
+	filterLessOrEqual    = 6
+	filterPresent        = 7
+	filterApproxMatch    = 8
+)
+
+// Context tags of the components of a substrings filter.
+const (
+	substringInitial = 0
+	substringAny     = 1
+	substringFinal   = 2
+)
+
+// parseFilter parses the string representation of a search filter (RFC
+// 4515) into its BER encoding. Extensible matches are not supported.
+func parseFilter(s string) (*berElement, error) {
+	p := filterParser{s: s}
+	f, err := p.parse()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid LDAP filter %q", s)
+	}
+	if p.pos != len(s) {
+		return nil, errors.Newf("invalid LDAP filter %q: unexpected trailing characters", s)
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) parse() (*berElement, error) {
+	if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+		return nil, errors.New("expected '('")
+	}
+	p.pos++
+	if p.pos >= len(p.s) {
+		return nil, errors.New("unexpected end of filter")
+	}
+	var f *berElement
+	var err error
+	switch p.s[p.pos] {
+	case '&', '|':
+		tag := filterAnd
+		if p.s[p.pos] == '|' {
+			tag = filterOr
+		}
+		p.pos++
+		f = newBERConstructed(classContext, tag)
+		for p.pos < len(p.s) && p.s[p.pos] == '(' {
+			sub, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			f.children = append(f.children, sub)
+		}
+		if len(f.children) == 0 {
+			return nil, errors.New("empty filter list")
+		}
+	case '!':
+		p.pos++
+		sub, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		f = newBERConstructed(classContext, filterNot, sub)
+	default:
+		if f, err = p.parseItem(); err != nil {
+			return nil, err
+		}
+	}
+	if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+		return nil, errors.New("expected ')'")
+	}
+	p.pos++
+	return f, nil
+}
+
+// parseItem parses a simple, presence or substrings filter.
+func (p *filterParser) parseItem() (*berElement, error) {
+	end := strings.IndexByte(p.s[p.pos:], ')')
+	if end < 0 {
+		return nil, errors.New("expected ')'")
+	}
+	item := p.s[p.pos : p.pos+end]
+	p.pos += end
+
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, errors.Newf("invalid filter item %q", item)
+	}
+	attr, value := item[:eq], item[eq+1:]
+	tag := filterEqualityMatch
+	switch attr[len(attr)-1] {
+	case '>':
+		tag = filterGreaterOrEqual
+	case '<':
+		tag = filterLessOrEqual
+	case '~':
+		tag = filterApproxMatch
+	case ':':
+		return nil, errors.New("extensible match filters are not supported")
+	}
+	if tag != filterEqualityMatch {
+		attr = attr[:len(attr)-1]
+	}
+	if attr == "" {
+		return nil, errors.Newf("invalid filter item %q", item)
+	}
+
+	if tag == filterEqualityMatch && value == "*" {
+		return newBERPrimitive(classContext, filterPresent, []byte(attr)), nil
+	}
+	if tag == filterEqualityMatch && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		substrings := newBERSequence()
+		for i, part := range parts {
+			if part == "" {
+				continue
+			}
+			unescaped, err := unescapeFilterValue(part)
+			if err != nil {
+				return nil, err
+			}
+			subTag := substringAny
+			if i == 0 {
+				subTag = substringInitial
+			} else if i == len(parts)-1 {
+				subTag = substringFinal
+			}
+			substrings.children = append(substrings.children,
+				newBERPrimitive(classContext, subTag, []byte(unescaped)))
+		}
+		return newBERConstructed(classContext, filterSubstrings, newBEROctetString(attr), substrings), nil
+	}
+	unescaped, err := unescapeFilterValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return newBERConstructed(classContext, tag, newBEROctetString(attr), newBEROctetString(unescaped)), nil
+}
+
+// unescapeFilterValue decodes the \XX escape sequences of a filter value.
+func unescapeFilterValue(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", errors.New("truncated escape sequence in filter value")
+		}
+		dec, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", errors.New("invalid escape sequence in filter value")
+		}
+		b.Write(dec)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// escapeFilterValue escapes the characters of s that have a special meaning
+// in a filter value (RFC 4515, section 3).
+func escapeFilterValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			b.WriteString(`\` + hex.EncodeToString([]byte{c}))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// escapeDNValue escapes the characters of s that have a special meaning in
+// an attribute value of a distinguished name (RFC 4514, section 2.4).
+func escapeDNValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '+' || c == ',' || c == ';' || c == '<' || c == '>' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == 0:
+			b.WriteString(`\00`)
+		case (c == ' ' || c == '#') && i == 0, c == ' ' && i == len(s)-1:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,494 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/errors"
+)
+
+// The "ldap" authentication method verifies the password sent by the client
+// by binding to an LDAP server, like the method of the same name in
+// PostgreSQL. The SQL user must exist beforehand.
+//
+// It supports two modes, configured with the options of the HBA entry:
+//
+// - simple bind: the DN used to bind is made of ldapprefix, the user name
+//   and ldapsuffix.
+//
+// - search+bind: the server first binds with ldapbinddn and ldapbindpasswd
+//   (or anonymously), and looks up the user entry under ldapbasedn, with the
+//   user name in the ldapsearchattribute attribute or matching
+//   ldapsearchfilter, where $username is replaced by the user name. The
+//   server then binds as that entry with the password of the client.
+//
+// The connection to the LDAP server can be secured with LDAPS
+// (ldapscheme=ldaps) or with StartTLS (ldaptls=1). The certificate of the
+// LDAP server is verified with the CA certificates of the
+// server.ldap_authentication.tls_ca_certificates setting, or with the
+// system's if it is empty.
+//
+// Additionally, LDAP groups can be mapped to SQL roles with one or more
+// "ldapgrouprole=<group DN>:<role>" options; as for the other options whose
+// value contains commas or equal signs, the whole option must be quoted.
+// Upon each successful login, the user is granted the roles mapped to the
+// groups listed in the ldapgroupattribute attribute of its entry (memberOf by
+// default), and the other mapped roles are revoked.
+
+// authTypeCleartextPassword is the pgwire auth response code to request a
+// plaintext password during the connection handshake.
+const authTypeCleartextPassword int32 = 3
+
+const (
+	defaultLDAPPort         = 389
+	defaultLDAPSPort        = 636
+	defaultSearchAttribute  = "uid"
+	defaultGroupAttribute   = "memberOf"
+	usernamePlaceholder     = "$username"
+	ldapCACertsSettingName  = "server.ldap_authentication.tls_ca_certificates"
+	enterpriseFeatureName   = "LDAP authentication"
+	ldapGroupRoleOptionName = "ldapgrouprole"
+)
+
+// ldapCACertificates are the CA certificates used to verify the certificate
+// of the LDAP servers.
+var ldapCACertificates = func() *settings.StringSetting {
+	s := settings.RegisterValidatedStringSetting(
+		ldapCACertsSettingName,
+		"the PEM-encoded CA certificates used to verify the certificates of the LDAP servers "+
+			"of the ldap authentication method; if empty, the system's CA certificates are used",
+		"",
+		func(_ *settings.Values, s string) error {
+			if s != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(s)) {
+				return errors.New("no valid PEM-encoded certificate found")
+			}
+			return nil
+		},
+	).WithPublic()
+	s.SetReportable(false)
+	return s
+}()
+
+// groupRole maps an LDAP group to a SQL role.
+type groupRole struct {
+	groupDN string
+	role    security.SQLUsername
+}
+
+// ldapConfig is the configuration of an HBA entry using the ldap method.
+type ldapConfig struct {
+	server   string
+	port     int
+	ldaps    bool
+	startTLS bool
+
+	// Simple bind mode.
+	prefix, suffix string
+
+	// Search+bind mode.
+	baseDN          string
+	bindDN          string
+	bindPassword    string
+	searchAttribute string
+	searchFilter    string
+
+	groupAttribute string
+	groupRoles     []groupRole
+}
+
+func (cfg *ldapConfig) searchMode() bool {
+	return cfg.baseDN != ""
+}
+
+// parseLDAPConfig extracts the configuration of the method from the options
+// of an HBA entry.
+func parseLDAPConfig(entry hba.Entry) (*ldapConfig, error) {
+	cfg := &ldapConfig{}
+	seen := make(map[string]bool)
+	scheme := "ldap"
+	var port, tlsOpt string
+	for _, op := range entry.Options {
+		name, val := op[0], op[1]
+		if name != ldapGroupRoleOptionName {
+			if seen[name] {
+				return nil, errors.Errorf("option %s specified more than once", name)
+			}
+			seen[name] = true
+		}
+		switch name {
+		case "ldapserver":
+			cfg.server = val
+		case "ldapport":
+			port = val
+		case "ldapscheme":
+			scheme = val
+		case "ldaptls":
+			tlsOpt = val
+		case "ldapprefix":
+			cfg.prefix = val
+		case "ldapsuffix":
+			cfg.suffix = val
+		case "ldapbasedn":
+			cfg.baseDN = val
+		case "ldapbinddn":
+			cfg.bindDN = val
+		case "ldapbindpasswd":
+			cfg.bindPassword = val
+		case "ldapsearchattribute":
+			cfg.searchAttribute = val
+		case "ldapsearchfilter":
+			cfg.searchFilter = val
+		case "ldapgroupattribute":
+			cfg.groupAttribute = val
+		case ldapGroupRoleOptionName:
+			idx := strings.LastIndexByte(val, ':')
+			if idx <= 0 || idx == len(val)-1 {
+				return nil, errors.Errorf(
+					"invalid %s option %q: expected <group DN>:<role>", name, val)
+			}
+			role, err := security.MakeSQLUsernameFromUserInput(val[idx+1:], security.UsernameValidation)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid %s option %q", name, val)
+			}
+			cfg.groupRoles = append(cfg.groupRoles, groupRole{groupDN: val[:idx], role: role})
+		default:
+			return nil, errors.Errorf("unsupported option %s", name)
+		}
+	}
+
+	if cfg.server == "" {
+		return nil, errors.New(`missing "ldapserver" option in LDAP entry`)
+	}
+	switch scheme {
+	case "ldap":
+		cfg.port = defaultLDAPPort
+	case "ldaps":
+		cfg.ldaps = true
+		cfg.port = defaultLDAPSPort
+	default:
+		return nil, errors.Errorf("invalid ldapscheme %q: expected ldap or ldaps", scheme)
+	}
+	if port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil || p <= 0 || p > 65535 {
+			return nil, errors.Errorf("invalid ldapport %q", port)
+		}
+		cfg.port = p
+	}
+	switch tlsOpt {
+	case "", "0":
+	case "1":
+		cfg.startTLS = true
+	default:
+		return nil, errors.Errorf("invalid ldaptls %q: expected 0 or 1", tlsOpt)
+	}
+	if cfg.startTLS && cfg.ldaps {
+		return nil, errors.New("ldaptls cannot be used with ldapscheme=ldaps")
+	}
+
+	hasSimpleBindOptions := seen["ldapprefix"] || seen["ldapsuffix"]
+	hasSearchOptions := seen["ldapbasedn"] || seen["ldapbinddn"] || seen["ldapbindpasswd"] ||
+		seen["ldapsearchattribute"] || seen["ldapsearchfilter"]
+	if hasSimpleBindOptions && hasSearchOptions {
+		return nil, errors.New(
+			"cannot mix options for simple bind and search+bind modes in LDAP entry")
+	}
+	if hasSearchOptions {
+		if cfg.baseDN == "" {
+			return nil, errors.New(`missing "ldapbasedn" option for search+bind mode in LDAP entry`)
+		}
+		if cfg.searchAttribute != "" && cfg.searchFilter != "" {
+			return nil, errors.New("cannot use ldapsearchattribute together with ldapsearchfilter")
+		}
+		if cfg.searchFilter != "" {
+			if _, err := parseFilter(cfg.makeSearchFilter("user")); err != nil {
+				return nil, err
+			}
+		} else if cfg.searchAttribute == "" {
+			cfg.searchAttribute = defaultSearchAttribute
+		}
+	} else if !hasSimpleBindOptions {
+		return nil, errors.New(
+			`LDAP entry requires either "ldapprefix"/"ldapsuffix" or "ldapbasedn" options`)
+	}
+	if cfg.groupAttribute == "" {
+		cfg.groupAttribute = defaultGroupAttribute
+	}
+	return cfg, nil
+}
+
+// makeSearchFilter returns the filter used to look up the entry of a user.
+func (cfg *ldapConfig) makeSearchFilter(username string) string {
+	username = escapeFilterValue(username)
+	if cfg.searchFilter != "" {
+		return strings.Replace(cfg.searchFilter, usernamePlaceholder, username, -1)
+	}
+	return fmt.Sprintf("(%s=%s)", cfg.searchAttribute, username)
+}
+
+// authenticate verifies the password of the user against the LDAP server.
+// It returns the DNs of the groups of the user if the configuration maps
+// groups to roles.
+func (cfg *ldapConfig) authenticate(
+	ctx context.Context, tlsConfig *tls.Config, username, password string,
+) (groups []string, _ error) {
+	if password == "" {
+		// An empty password would result in an unauthenticated bind, which
+		// many servers accept (RFC 4513, section 5.1.2).
+		return nil, errors.New("empty password")
+	}
+
+	addr := net.JoinHostPort(cfg.server, strconv.Itoa(cfg.port))
+	var dialTLSConfig *tls.Config
+	if cfg.ldaps {
+		dialTLSConfig = tlsConfig
+	}
+	conn, err := dialLDAP(ctx, addr, dialTLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+	if cfg.startTLS {
+		if err := conn.startTLS(tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	var attributes []string
+	if len(cfg.groupRoles) > 0 {
+		attributes = []string{cfg.groupAttribute}
+	}
+
+	var userDN string
+	var entry ldapEntry
+	if cfg.searchMode() {
+		if err := conn.bind(cfg.bindDN, cfg.bindPassword); err != nil {
+			return nil, errors.Wrap(err, "unable to bind with the search DN")
+		}
+		filter, err := parseFilter(cfg.makeSearchFilter(username))
+		if err != nil {
+			return nil, err
+		}
+		if attributes == nil {
+			// Request no attributes.
+			attributes = []string{"1.1"}
+		}
+		entries, err := conn.search(cfg.baseDN, scopeWholeSubtree, filter, attributes, 0 /* sizeLimit */)
+		if err != nil {
+			return nil, err
+		}
+		switch len(entries) {
+		case 0:
+			return nil, errors.Newf("user %q not found in the LDAP directory", username)
+		case 1:
+		default:
+			return nil, errors.Newf("user %q is not unique in the LDAP directory", username)
+		}
+		entry = entries[0]
+		userDN = entry.dn
+	} else {
+		userDN = cfg.prefix + escapeDNValue(username) + cfg.suffix
+	}
+
+	if err := conn.bind(userDN, password); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.groupRoles) == 0 {
+		return nil, nil
+	}
+	if !cfg.searchMode() {
+		// Read the groups of the user with its own credentials.
+		filter, err := parseFilter("(objectClass=*)")
+		if err != nil {
+			return nil, err
+		}
+		entries, err := conn.search(userDN, scopeBaseObject, filter, attributes, 1 /* sizeLimit */)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the groups of the user")
+		}
+		if len(entries) != 1 {
+			return nil, errors.New("unable to read the groups of the user")
+		}
+		entry = entries[0]
+	}
+	return entry.getAttribute(cfg.groupAttribute), nil
+}
+
+// normalizeDN returns a canonical form of a DN for comparisons: the spaces
+// around the RDNs are removed and the DN is lowercased.
+func normalizeDN(dn string) string {
+	var rdns []string
+	var cur bytes.Buffer
+	for i := 0; i < len(dn); i++ {
+		switch c := dn[i]; {
+		case c == '\\' && i+1 < len(dn):
+			cur.WriteByte(c)
+			cur.WriteByte(dn[i+1])
+			i++
+		case c == ',':
+			rdns = append(rdns, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	rdns = append(rdns, strings.TrimSpace(cur.String()))
+	return strings.ToLower(strings.Join(rdns, ","))
+}
+
+// syncGroupRoles grants the user the roles mapped to its LDAP groups, and
+// revokes the other mapped roles.
+func syncGroupRoles(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	mapping []groupRole,
+	groups []string,
+) error {
+	userGroups := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		userGroups[normalizeDN(g)] = true
+	}
+	// A role can be mapped to several groups: it is granted if the user is a
+	// member of any of them.
+	wanted := make(map[security.SQLUsername]bool)
+	var roles []security.SQLUsername
+	for _, m := range mapping {
+		if _, ok := wanted[m.role]; !ok {
+			roles = append(roles, m.role)
+		}
+		wanted[m.role] = wanted[m.role] || userGroups[normalizeDN(m.groupDN)]
+	}
+
+	ie := execCfg.InternalExecutor
+	override := sessiondata.InternalExecutorOverride{User: security.RootUserName()}
+	rows, err := ie.QueryEx(ctx, "ldap-get-roles", nil /* txn */, override,
+		`SELECT "role" FROM system.public.role_members WHERE "member" = $1`, user)
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the roles of the user")
+	}
+	current := make(map[security.SQLUsername]bool, len(rows))
+	for _, row := range rows {
+		// system.role_members stores pre-normalized usernames.
+		current[security.MakeSQLUsernameFromPreNormalizedString(string(tree.MustBeDString(row[0])))] = true
+	}
+
+	for _, role := range roles {
+		var stmt string
+		switch {
+		case wanted[role] && !current[role]:
+			stmt = fmt.Sprintf("GRANT %s TO %s", role.SQLIdentifier(), user.SQLIdentifier())
+		case !wanted[role] && current[role]:
+			stmt = fmt.Sprintf("REVOKE %s FROM %s", role.SQLIdentifier(), user.SQLIdentifier())
+		default:
+			continue
+		}
+		if _, err := ie.ExecEx(ctx, "ldap-sync-roles", nil /* txn */, override, stmt); err != nil {
+			return errors.Wrapf(err, "unable to synchronize role %s with the LDAP groups", role)
+		}
+	}
+	return nil
+}
+
+// makeTLSConfig returns the TLS configuration used to connect to an LDAP
+// server.
+func makeTLSConfig(sv *settings.Values, server string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: server, MinVersion: tls.VersionTLS12}
+	if pem := ldapCACertificates.Get(sv); pem != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(pem)) {
+			return nil, errors.Newf("invalid %s setting", ldapCACertsSettingName)
+		}
+	}
+	return cfg, nil
+}
+
+func authLDAP(
+	ctx context.Context,
+	c pgwire.AuthConn,
+	_ tls.ConnectionState,
+	_ pgwire.PasswordRetrievalFn,
+	_ pgwire.PasswordValidUntilFn,
+	execCfg *sql.ExecutorConfig,
+	entry *hba.Entry,
+) (security.UserAuthHook, error) {
+	cfg, err := parseLDAPConfig(*entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SendAuthRequest(authTypeCleartextPassword, nil /* data */); err != nil {
+		return nil, err
+	}
+	pwdData, err := c.GetPwdData()
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(pwdData, 0) != len(pwdData)-1 {
+		return nil, errors.New("expected 0-terminated byte array")
+	}
+	password := string(pwdData[:len(pwdData)-1])
+
+	return func(requestedUser security.SQLUsername, clientConnection bool) (func(), error) {
+		if !clientConnection {
+			return nil, errors.New("LDAP authentication is only available for client connections")
+		}
+		tlsConfig, err := makeTLSConfig(&execCfg.Settings.SV, cfg.server)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := cfg.authenticate(ctx, tlsConfig, requestedUser.Normalized(), password)
+		if err != nil {
+			// The details are only logged, to not disclose the directory
+			// structure to the client.
+			c.LogAuthInfof(ctx, "LDAP authentication failed: %v", err)
+			return nil, errors.Errorf(security.ErrPasswordUserAuthFailed, requestedUser)
+		}
+
+		// Like for GSS, do the license check after the authentication so
+		// that administrators can verify their LDAP configuration.
+		if err := utilccl.CheckEnterpriseEnabled(
+			execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(), enterpriseFeatureName,
+		); err != nil {
+			return nil, err
+		}
+
+		if len(cfg.groupRoles) > 0 {
+			if err := syncGroupRoles(ctx, execCfg, requestedUser, cfg.groupRoles, groups); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}, nil
+}
+
+func checkEntry(entry hba.Entry) error {
+	_, err := parseLDAPConfig(entry)
+	return err
+}
+
+func init() {
+	pgwire.RegisterAuthMethod("ldap", authLDAP, hba.ConnAny, checkEntry)
+}
@@ -0,0 +1,290 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// fakeEntry is an entry of the directory of a fakeLDAPServer.
+type fakeEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// fakeLDAPServer is an in-process stand-in for an LDAP server. It supports
+// simple binds, searches and StartTLS, which is enough to exercise the
+// authentication method.
+type fakeLDAPServer struct {
+	t         *testing.T
+	ln        net.Listener
+	ldaps     bool
+	tlsConfig *tls.Config
+	entries   []fakeEntry
+
+	mu struct {
+		syncutil.Mutex
+		// binds lists the DNs of the successful binds.
+		binds []string
+		// tlsUpgrades counts the connections upgraded with StartTLS.
+		tlsUpgrades int
+	}
+}
+
+// loadTestTLSConfigs returns a server TLS configuration using the embedded
+// node certificate, and a client configuration trusting it.
+func loadTestTLSConfigs(t *testing.T) (server *tls.Config, caPEM string) {
+	load := func(name string) []byte {
+		b, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	cert, err := tls.X509KeyPair(load(security.EmbeddedNodeCert), load(security.EmbeddedNodeKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, string(load(security.EmbeddedCACert))
+}
+
+// testClientTLSConfig returns the client TLS configuration trusting the
+// given CA certificates.
+func testClientTLSConfig(t *testing.T, caPEM string) *tls.Config {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+		t.Fatal("invalid CA certificate")
+	}
+	return &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+}
+
+func startFakeLDAPServer(
+	t *testing.T, ldaps bool, tlsConfig *tls.Config, entries []fakeEntry,
+) *fakeLDAPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ldaps {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	s := &fakeLDAPServer{t: t, ln: ln, ldaps: ldaps, tlsConfig: tlsConfig, entries: entries}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeLDAPServer) port() string {
+	_, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return port
+}
+
+func (s *fakeLDAPServer) close() {
+	_ = s.ln.Close()
+}
+
+func (s *fakeLDAPServer) binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.mu.binds...)
+}
+
+func (s *fakeLDAPServer) tlsUpgrades() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.tlsUpgrades
+}
+
+func (s *fakeLDAPServer) findEntry(dn string) *fakeEntry {
+	for i := range s.entries {
+		if normalizeDN(s.entries[i].dn) == normalizeDN(dn) {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+func ldapResult(tag int, code int64, msg string) *berElement {
+	return newBERConstructed(classApplication, tag,
+		newBERInteger(tagEnumerated, code), newBEROctetString(""), newBEROctetString(msg))
+}
+
+func (s *fakeLDAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readBERElement(r)
+		if err != nil {
+			return
+		}
+		if len(msg.children) < 2 {
+			return
+		}
+		id := msg.children[0]
+		op := msg.children[1]
+		reply := func(resp *berElement) bool {
+			_, err := conn.Write(newBERSequence(id, resp).encode(nil))
+			return err == nil
+		}
+		switch {
+		case op.is(classApplication, appBindRequest):
+			dn, password := op.children[1].str(), op.children[2].str()
+			code := int64(resultInvalidCredentials)
+			if e := s.findEntry(dn); (dn == "" && password == "") ||
+				(e != nil && password != "" && e.password == password) {
+				code = resultSuccess
+				s.mu.Lock()
+				s.mu.binds = append(s.mu.binds, dn)
+				s.mu.Unlock()
+			}
+			if !reply(ldapResult(appBindResponse, code, "")) {
+				return
+			}
+
+		case op.is(classApplication, appSearchRequest):
+			base := normalizeDN(op.children[0].str())
+			scope, _ := op.children[1].integer()
+			filter := op.children[6]
+			var attrs []string
+			for _, a := range op.children[7].children {
+				attrs = append(attrs, a.str())
+			}
+			for _, e := range s.entries {
+				dn := normalizeDN(e.dn)
+				inScope := dn == base
+				if scope == scopeWholeSubtree {
+					inScope = inScope || strings.HasSuffix(dn, ","+base)
+				}
+				if !inScope || !matchFilter(filter, e.attributes) {
+					continue
+				}
+				attrList := newBERSequence()
+				for _, name := range attrs {
+					vals := ldapEntry{attributes: e.attributes}.getAttribute(name)
+					if vals == nil {
+						continue
+					}
+					set := newBERSet()
+					for _, v := range vals {
+						set.children = append(set.children, newBEROctetString(v))
+					}
+					attrList.children = append(attrList.children, newBERSequence(newBEROctetString(name), set))
+				}
+				if !reply(newBERConstructed(classApplication, appSearchResultEntry,
+					newBEROctetString(e.dn), attrList)) {
+					return
+				}
+			}
+			if !reply(ldapResult(appSearchResultDone, resultSuccess, "")) {
+				return
+			}
+
+		case op.is(classApplication, appExtendedRequest):
+			if len(op.children) == 0 || op.children[0].str() != startTLSOID || s.ldaps {
+				if !reply(ldapResult(appExtendedResponse, 2 /* protocolError */, "unsupported")) {
+					return
+				}
+				continue
+			}
+			if !reply(ldapResult(appExtendedResponse, resultSuccess, "")) {
+				return
+			}
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.mu.tlsUpgrades++
+			s.mu.Unlock()
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+
+		case op.is(classApplication, appUnbindRequest):
+			return
+
+		default:
+			s.t.Errorf("unexpected LDAP operation %d", op.tag)
+			return
+		}
+	}
+}
+
+// matchFilter evaluates a search filter against the attributes of an entry.
+func matchFilter(f *berElement, attributes map[string][]string) bool {
+	values := func(attr string) []string {
+		return ldapEntry{attributes: attributes}.getAttribute(attr)
+	}
+	switch f.tag {
+	case filterAnd:
+		for _, c := range f.children {
+			if !matchFilter(c, attributes) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, c := range f.children {
+			if matchFilter(c, attributes) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !matchFilter(f.children[0], attributes)
+	case filterEqualityMatch:
+		for _, v := range values(f.children[0].str()) {
+			if strings.EqualFold(v, f.children[1].str()) {
+				return true
+			}
+		}
+		return false
+	case filterPresent:
+		return values(f.str()) != nil
+	case filterSubstrings:
+		for _, v := range values(f.children[0].str()) {
+			v = strings.ToLower(v)
+			ok := true
+			for _, sub := range f.children[1].children {
+				s := strings.ToLower(sub.str())
+				switch sub.tag {
+				case substringInitial:
+					ok = ok && strings.HasPrefix(v, s)
+				case substringAny:
+					ok = ok && strings.Contains(v, s)
+				case substringFinal:
+					ok = ok && strings.HasSuffix(v, s)
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"context"
+	"crypto/tls"
+	gosql "database/sql"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntries = []fakeEntry{
+	{
+		dn:       "cn=admin,dc=example,dc=com",
+		password: "adminpw",
+	},
+	{
+		dn:       "uid=alice,ou=people,dc=example,dc=com",
+		password: "alicepw",
+		attributes: map[string][]string{
+			"uid":         {"alice"},
+			"objectClass": {"person"},
+			"mail":        {"alice@example.com"},
+			"memberOf": {
+				"cn=dba,ou=groups,dc=example,dc=com",
+				"cn=dev,ou=groups,dc=example,dc=com",
+			},
+		},
+	},
+	{
+		dn:       "uid=bob,ou=people,dc=example,dc=com",
+		password: "bobpw",
+		attributes: map[string][]string{
+			"uid":         {"bob"},
+			"objectClass": {"person"},
+			"memberOf":    {"cn=dev,ou=groups,dc=example,dc=com"},
+		},
+	},
+	{
+		dn:       "uid=bob,ou=contractors,dc=example,dc=com",
+		password: "bobpw2",
+		attributes: map[string][]string{
+			"uid":         {"bob"},
+			"objectClass": {"person"},
+		},
+	},
+}
+
+func parseTestEntry(t *testing.T, line string) hba.Entry {
+	conf, err := hba.ParseAndNormalize(line)
+	require.NoError(t, err)
+	require.Len(t, conf.Entries, 1)
+	return conf.Entries[0]
+}
+
+func TestParseLDAPConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		options string
+		err     string
+	}{
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" "ldapsuffix=,dc=example,dc=com"`, ``},
+		{`ldapserver=ldap.example.com "ldapbasedn=dc=example,dc=com"`, ``},
+		{`ldapserver=ldap.example.com "ldapbasedn=dc=example,dc=com" "ldapsearchfilter=(&(objectClass=person)(uid=$username))"`, ``},
+		{`ldapserver=ldap.example.com ldapscheme=ldaps "ldapbasedn=dc=example,dc=com" "ldapgrouprole=cn=dba,ou=groups,dc=example,dc=com:dba" "ldapgrouprole=cn=dev,ou=groups,dc=example,dc=com:dev"`, ``},
+		{`"ldapprefix=uid="`, `missing "ldapserver" option`},
+		{`ldapserver=ldap.example.com`, `requires either`},
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" "ldapbasedn=dc=example,dc=com"`, `cannot mix options`},
+		{`ldapserver=ldap.example.com "ldapbinddn=cn=admin"`, `missing "ldapbasedn"`},
+		{`ldapserver=ldap.example.com "ldapbasedn=dc=example,dc=com" ldapsearchattribute=uid "ldapsearchfilter=(uid=$username)"`, `cannot use ldapsearchattribute together with ldapsearchfilter`},
+		{`ldapserver=ldap.example.com "ldapbasedn=dc=example,dc=com" "ldapsearchfilter=(uid=$username"`, `invalid LDAP filter`},
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" ldapport=abc`, `invalid ldapport`},
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" ldapscheme=http`, `invalid ldapscheme`},
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" ldapscheme=ldaps ldaptls=1`, `ldaptls cannot be used with ldapscheme=ldaps`},
+		{`ldapserver=ldap.example.com ldapserver=other "ldapprefix=uid="`, `option ldapserver specified more than once`},
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" ldapgrouprole=dba`, `invalid ldapgrouprole option`},
+		{`ldapserver=ldap.example.com "ldapprefix=uid=" krb_realm=x`, `unsupported option krb_realm`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.options, func(t *testing.T) {
+			err := checkEntry(parseTestEntry(t, "host all all all ldap "+tc.options))
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+
+	cfg, err := parseLDAPConfig(parseTestEntry(t,
+		`host all all all ldap ldapserver=ldap.example.com "ldapbasedn=dc=example,dc=com"`))
+	require.NoError(t, err)
+	require.Equal(t, defaultLDAPPort, cfg.port)
+	require.Equal(t, "(uid=a\\2ab)", cfg.makeSearchFilter("a*b"))
+}
+
+func TestParseFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	attrs := map[string][]string{
+		"uid":         {"alice"},
+		"objectClass": {"person", "top"},
+		"mail":        {"alice@example.com"},
+		"cn":          {"a*b"},
+	}
+	testCases := []struct {
+		filter string
+		match  bool
+		err    string
+	}{
+		{`(uid=alice)`, true, ``},
+		{`(UID=Alice)`, true, ``},
+		{`(uid=bob)`, false, ``},
+		{`(mail=*)`, true, ``},
+		{`(phone=*)`, false, ``},
+		{`(mail=ali*@*.com)`, true, ``},
+		{`(mail=*bob*)`, false, ``},
+		{`(cn=a\2ab)`, true, ``},
+		{`(&(objectClass=person)(uid=alice))`, true, ``},
+		{`(&(objectClass=person)(uid=bob))`, false, ``},
+		{`(|(uid=bob)(uid=alice))`, true, ``},
+		{`(!(uid=alice))`, false, ``},
+		{`uid=alice`, false, `expected '\('`},
+		{`(uid=alice`, false, `expected '\)'`},
+		{`(uid=alice))`, false, `trailing characters`},
+		{`(&)`, false, `empty filter list`},
+		{`(uid:dn:=alice)`, false, `extensible match filters are not supported`},
+		{`(cn=a\2)`, false, `escape sequence`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			f, err := parseFilter(tc.filter)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			require.Equal(t, tc.match, matchFilter(f, attrs))
+		})
+	}
+
+	require.Equal(t, `a\2a\28\29\5cb`, escapeFilterValue(`a*()\b`))
+	require.Equal(t, `a\,b\+c\\d`, escapeDNValue(`a,b+c\d`))
+	require.Equal(t, `\ a#b \ `, escapeDNValue(` a#b  `))
+	require.Equal(t, "cn=a\\,b,dc=example", normalizeDN(" CN=a\\,b , DC=Example "))
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	serverTLS, caPEM := loadTestTLSConfigs(t)
+	clientTLS := testClientTLSConfig(t, caPEM)
+
+	ldapServer := startFakeLDAPServer(t, false /* ldaps */, serverTLS, testEntries)
+	defer ldapServer.close()
+	ldapsServer := startFakeLDAPServer(t, true /* ldaps */, serverTLS, testEntries)
+	defer ldapsServer.close()
+
+	const groupRoles = ` "ldapgrouprole=cn=dba,ou=groups,dc=example,dc=com:dba"` +
+		` "ldapgrouprole=CN=dev, OU=groups, DC=example, DC=com:dev"`
+
+	testCases := []struct {
+		name     string
+		server   *fakeLDAPServer
+		options  string
+		user     string
+		password string
+		bindDN   string
+		groups   []string
+		err      string
+	}{
+		{
+			name:     "simple bind",
+			options:  `"ldapprefix=uid=" "ldapsuffix=,ou=people,dc=example,dc=com"`,
+			user:     "alice",
+			password: "alicepw",
+			bindDN:   "uid=alice,ou=people,dc=example,dc=com",
+		},
+		{
+			name:     "simple bind wrong password",
+			options:  `"ldapprefix=uid=" "ldapsuffix=,ou=people,dc=example,dc=com"`,
+			user:     "alice",
+			password: "bobpw",
+			err:      "LDAP bind failed with result code 49",
+		},
+		{
+			name:     "empty password",
+			options:  `"ldapprefix=" "ldapsuffix="`,
+			user:     "",
+			password: "",
+			err:      "empty password",
+		},
+		{
+			name:     "simple bind with groups",
+			options:  `"ldapprefix=uid=" "ldapsuffix=,ou=people,dc=example,dc=com"` + groupRoles,
+			user:     "alice",
+			password: "alicepw",
+			bindDN:   "uid=alice,ou=people,dc=example,dc=com",
+			groups:   []string{"cn=dba,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"},
+		},
+		{
+			name:     "search and bind",
+			options:  `"ldapbasedn=ou=people,dc=example,dc=com" "ldapbinddn=cn=admin,dc=example,dc=com" ldapbindpasswd=adminpw`,
+			user:     "bob",
+			password: "bobpw",
+			bindDN:   "uid=bob,ou=people,dc=example,dc=com",
+		},
+		{
+			name:     "anonymous search with filter and groups",
+			options:  `"ldapbasedn=dc=example,dc=com" "ldapsearchfilter=(&(objectClass=person)(mail=$username@*))"` + groupRoles,
+			user:     "alice",
+			password: "alicepw",
+			bindDN:   "uid=alice,ou=people,dc=example,dc=com",
+			groups:   []string{"cn=dba,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"},
+		},
+		{
+			name:     "search ambiguous user",
+			options:  `"ldapbasedn=dc=example,dc=com"`,
+			user:     "bob",
+			password: "bobpw",
+			err:      `user "bob" is not unique`,
+		},
+		{
+			name:     "search unknown user",
+			options:  `"ldapbasedn=dc=example,dc=com"`,
+			user:     "carol",
+			password: "carolpw",
+			err:      `user "carol" not found`,
+		},
+		{
+			name:     "search filter injection",
+			options:  `"ldapbasedn=ou=people,dc=example,dc=com"`,
+			user:     "*",
+			password: "alicepw",
+			err:      `user "\*" not found`,
+		},
+		{
+			name:     "search with wrong bind password",
+			options:  `"ldapbasedn=ou=people,dc=example,dc=com" "ldapbinddn=cn=admin,dc=example,dc=com" ldapbindpasswd=wrong`,
+			user:     "bob",
+			password: "bobpw",
+			err:      "unable to bind with the search DN",
+		},
+		{
+			name:     "StartTLS",
+			options:  `ldaptls=1 "ldapprefix=uid=" "ldapsuffix=,ou=people,dc=example,dc=com"`,
+			user:     "bob",
+			password: "bobpw",
+			bindDN:   "uid=bob,ou=people,dc=example,dc=com",
+		},
+		{
+			name:     "LDAPS",
+			server:   ldapsServer,
+			options:  `ldapscheme=ldaps "ldapprefix=uid=" "ldapsuffix=,ou=people,dc=example,dc=com"`,
+			user:     "bob",
+			password: "bobpw",
+			bindDN:   "uid=bob,ou=people,dc=example,dc=com",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := tc.server
+			if s == nil {
+				s = ldapServer
+			}
+			cfg, err := parseLDAPConfig(parseTestEntry(t, fmt.Sprintf(
+				"host all all all ldap ldapserver=127.0.0.1 ldapport=%s %s", s.port(), tc.options)))
+			require.NoError(t, err)
+			bindsBefore := len(s.binds())
+			upgradesBefore := s.tlsUpgrades()
+
+			groups, err := cfg.authenticate(ctx, clientTLS, tc.user, tc.password)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			binds := s.binds()[bindsBefore:]
+			require.Equal(t, tc.bindDN, binds[len(binds)-1])
+			require.ElementsMatch(t, tc.groups, groups)
+			if cfg.startTLS {
+				require.Equal(t, upgradesBefore+1, s.tlsUpgrades())
+			}
+		})
+	}
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		cfg, err := parseLDAPConfig(parseTestEntry(t, fmt.Sprintf(
+			`host all all all ldap ldapserver=127.0.0.1 ldapport=%s ldaptls=1 "ldapprefix=uid=" "ldapsuffix=,ou=people,dc=example,dc=com"`,
+			ldapServer.port())))
+		require.NoError(t, err)
+		_, err = cfg.authenticate(ctx, &tls.Config{ServerName: "127.0.0.1"}, "bob", "bobpw")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "StartTLS handshake with LDAP server failed")
+	})
+}
+
+// TestLDAPAuthenticationMethod exercises the ldap method through SQL
+// connections, including the mapping of groups to roles.
+func TestLDAPAuthenticationMethod(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	serverTLS, caPEM := loadTestTLSConfigs(t)
+	ldapServer := startFakeLDAPServer(t, false /* ldaps */, serverTLS, testEntries)
+	defer ldapServer.close()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	pgServer := s.(*server.TestServer).PGServer()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING server.ldap_authentication.tls_ca_certificates = $1`, caPEM)
+	sqlDB.Exec(t, `CREATE USER alice`)
+	sqlDB.Exec(t, `CREATE USER bob`)
+	sqlDB.Exec(t, `CREATE ROLE dba`)
+	sqlDB.Exec(t, `CREATE ROLE dev`)
+	// The role is revoked upon login, since bob is not in the dba group.
+	sqlDB.Exec(t, `GRANT dba TO bob`)
+
+	hbaConf := fmt.Sprintf(`host all all all ldap ldapserver=127.0.0.1 ldapport=%s ldaptls=1 `+
+		`"ldapbasedn=ou=people,dc=example,dc=com" `+
+		`"ldapgrouprole=cn=dba,ou=groups,dc=example,dc=com:dba" `+
+		`"ldapgrouprole=cn=dev,ou=groups,dc=example,dc=com:dev"`, ldapServer.port())
+	sqlDB.Exec(t, `SET CLUSTER SETTING server.host_based_authentication.configuration = $1`, hbaConf)
+	expConf, err := pgwire.ParseAndNormalize(hbaConf)
+	require.NoError(t, err)
+	testutils.SucceedsSoon(t, func() error {
+		if curConf := pgServer.GetAuthenticationConfiguration(); expConf.String() != curConf.String() {
+			return errors.Newf("HBA config not yet loaded\ngot:\n%s\nexpected:\n%s", curConf, expConf)
+		}
+		return nil
+	})
+
+	connect := func(user, password string) error {
+		pgURL, cleanupFn := sqlutils.PGUrlWithOptionalClientCerts(
+			t, s.ServingSQLAddr(), t.Name(), url.UserPassword(user, password), false, /* withClientCerts */
+		)
+		defer cleanupFn()
+		conn, err := gosql.Open("postgres", pgURL.String())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Ping()
+	}
+	roles := func(user string) [][]string {
+		return sqlDB.QueryStr(t,
+			`SELECT role FROM system.role_members WHERE member = $1 ORDER BY role`, user)
+	}
+
+	require.NoError(t, connect("alice", "alicepw"))
+	require.Equal(t, [][]string{{"dba"}, {"dev"}}, roles("alice"))
+
+	require.NoError(t, connect("bob", "bobpw"))
+	require.Equal(t, [][]string{{"dev"}}, roles("bob"))
+
+	err = connect("bob", "wrong")
+	require.True(t, testutils.IsError(err, "password authentication failed for user bob"), "%v", err)
+	// A user that does not exist in SQL cannot log in.
+	err = connect("carol", "carolpw")
+	require.Error(t, err)
+}
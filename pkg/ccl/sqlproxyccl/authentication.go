@@ -14,9 +14,26 @@ import (
 	"github.com/jackc/pgproto3/v2"
 )
 
-func authenticate(clientConn, crdbConn net.Conn) error {
+// clientCredentials are the credentials provided by the client while
+// authenticating to the backend. They are kept so that the proxy can
+// authenticate on behalf of the client to another SQL pod of the tenant when
+// the connection is migrated.
+type clientCredentials struct {
+	// password is the cleartext password sent by the client, if the backend
+	// asked for one.
+	password *pgproto3.PasswordMessage
+	// replayable is false if the client authenticated with a challenge-based
+	// method, such as MD5 or SCRAM, whose responses can't be sent again.
+	replayable bool
+}
+
+// authenticate relays the authentication messages between the client and the
+// backend until the backend is ready to serve queries, and returns the
+// credentials provided by the client.
+func authenticate(clientConn, crdbConn net.Conn) (clientCredentials, error) {
 	fe := pgproto3.NewBackend(pgproto3.NewChunkReader(clientConn), clientConn)
 	be := pgproto3.NewFrontend(pgproto3.NewChunkReader(crdbConn), crdbConn)
+	creds := clientCredentials{replayable: true}
 
 	// The auth step should require only a few back and forths so 20 iterations
 	// should be enough.
@@ -26,12 +43,14 @@ func authenticate(clientConn, crdbConn net.Conn) error {
 		// TODO(spaskob): in verbose mode, log these messages.
 		backendMsg, err := be.Receive()
 		if err != nil {
-			return NewErrorf(CodeBackendReadFailed, "unable to receive message from backend: %v", err)
+			return clientCredentials{}, NewErrorf(
+				CodeBackendReadFailed, "unable to receive message from backend: %v", err,
+			)
 		}
 
 		err = fe.Send(backendMsg)
 		if err != nil {
-			return NewErrorf(
+			return clientCredentials{}, NewErrorf(
 				CodeClientWriteFailed, "unable to send message %v to client: %v", backendMsg, err,
 			)
 		}
@@ -41,7 +60,7 @@ func authenticate(clientConn, crdbConn net.Conn) error {
 		case *pgproto3.ReadyForQuery:
 			// Server has authenticated the connection successfully and is ready to
 			// serve queries.
-			return nil
+			return creds, nil
 		case *pgproto3.AuthenticationOk:
 			// Server has authenticated the connection; keep reading messages until
 			// `pgproto3.ReadyForQuery` is encountered which signifies that server
@@ -55,7 +74,7 @@ func authenticate(clientConn, crdbConn net.Conn) error {
 		case *pgproto3.ErrorResponse:
 			// Server has rejected the authentication response from the client and
 			// has closed the connection.
-			return NewErrorf(CodeAuthFailed, "authentication failed: %v", backendMsg)
+			return clientCredentials{}, NewErrorf(CodeAuthFailed, "authentication failed: %v", backendMsg)
 		case
 			*pgproto3.AuthenticationCleartextPassword,
 			*pgproto3.AuthenticationMD5Password,
@@ -64,17 +83,31 @@ func authenticate(clientConn, crdbConn net.Conn) error {
 			// Read the client response and forward it to server.
 			fntMsg, err := fe.Receive()
 			if err != nil {
-				return NewErrorf(CodeClientReadFailed, "unable to receive message from client: %v", err)
+				return clientCredentials{}, NewErrorf(
+					CodeClientReadFailed, "unable to receive message from client: %v", err,
+				)
 			}
 			err = be.Send(fntMsg)
 			if err != nil {
-				return NewErrorf(
+				return clientCredentials{}, NewErrorf(
 					CodeBackendWriteFailed, "unable to send message %v to backend: %v", fntMsg, err,
 				)
 			}
+			// Only a cleartext password can be sent again to another backend. The
+			// message is copied since the decoded messages are reused.
+			_, cleartext := tp.(*pgproto3.AuthenticationCleartextPassword)
+			if pwd, ok := fntMsg.(*pgproto3.PasswordMessage); ok && cleartext {
+				creds.password = &pgproto3.PasswordMessage{Password: pwd.Password}
+			} else {
+				creds.replayable = false
+			}
 		default:
-			return NewErrorf(CodeBackendDisconnected, "received unexpected backend message type: %v", tp)
+			return clientCredentials{}, NewErrorf(
+				CodeBackendDisconnected, "received unexpected backend message type: %v", tp,
+			)
 		}
 	}
-	return NewErrorf(CodeBackendDisconnected, "authentication took more than %d iterations", i)
+	return clientCredentials{}, NewErrorf(
+		CodeBackendDisconnected, "authentication took more than %d iterations", i,
+	)
 }
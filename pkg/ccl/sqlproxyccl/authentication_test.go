@@ -33,7 +33,9 @@ func TestAuthenticateOK(t *testing.T) {
 		require.Equal(t, beMsg, &pgproto3.ReadyForQuery{})
 	}()
 
-	require.NoError(t, authenticate(srv, cli))
+	creds, err := authenticate(srv, cli)
+	require.NoError(t, err)
+	require.Equal(t, clientCredentials{replayable: true}, creds)
 }
 
 func TestAuthenticateClearText(t *testing.T) {
@@ -75,7 +77,12 @@ func TestAuthenticateClearText(t *testing.T) {
 		require.Equal(t, beMsg, &pgproto3.ReadyForQuery{})
 	}()
 
-	require.NoError(t, authenticate(srv, cli))
+	creds, err := authenticate(srv, cli)
+	require.NoError(t, err)
+	require.Equal(t, clientCredentials{
+		password:   &pgproto3.PasswordMessage{Password: "password"},
+		replayable: true,
+	}, creds)
 }
 
 func TestAuthenticateError(t *testing.T) {
@@ -93,7 +100,7 @@ func TestAuthenticateError(t *testing.T) {
 		require.Equal(t, beMsg, &pgproto3.ErrorResponse{Severity: "FATAL", Code: "foo"})
 	}()
 
-	err := authenticate(srv, cli)
+	_, err := authenticate(srv, cli)
 	require.Error(t, err)
 	codeErr := (*CodeError)(nil)
 	require.True(t, errors.As(err, &codeErr))
@@ -115,7 +122,7 @@ func TestAuthenticateUnexpectedMessage(t *testing.T) {
 		require.Equal(t, beMsg, &pgproto3.BindComplete{})
 	}()
 
-	err := authenticate(srv, cli)
+	_, err := authenticate(srv, cli)
 	require.Error(t, err)
 	codeErr := (*CodeError)(nil)
 	require.True(t, errors.As(err, &codeErr))
@@ -9,14 +9,25 @@
 package sqlproxyccl
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/binary"
 	"io"
 	"net"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/sqlproxyccl/tenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgproto3/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// maxTenantDialAttempts is the number of SQL pods of a tenant that TenantDial
+// tries before giving up.
+const maxTenantDialAttempts = 3
+
 // BackendDial is an example backend dialer that does a TCP/IP connection
 // to a backend, SSL and forwards the start message.
 func BackendDial(
@@ -41,6 +52,66 @@ func BackendDial(
 	return conn, nil
 }
 
+// TenantDial is a backend dialer that resolves the address of one of the SQL
+// pods of a tenant through the given directory, and then connects to it like
+// BackendDial. If the pod can't be reached, the failure is reported to the
+// directory and another pod is tried.
+func TenantDial(
+	ctx context.Context,
+	directory tenant.Resolver,
+	msg *pgproto3.StartupMessage,
+	tenantID roachpb.TenantID,
+	clusterName string,
+	tlsConfig *tls.Config,
+) (net.Conn, error) {
+	conn, _, err := dialTenantPod(ctx, directory, msg, tenantID, clusterName, tlsConfig)
+	return conn, err
+}
+
+// dialTenantPod implements TenantDial, and also returns the address of the
+// SQL pod to which the connection was established.
+func dialTenantPod(
+	ctx context.Context,
+	directory tenant.Resolver,
+	msg *pgproto3.StartupMessage,
+	tenantID roachpb.TenantID,
+	clusterName string,
+	tlsConfig *tls.Config,
+) (net.Conn, string, error) {
+	var err error
+	for i := 0; i < maxTenantDialAttempts; i++ {
+		var addr string
+		addr, err = directory.EnsureTenantAddr(ctx, tenantID, clusterName)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil, "", NewErrorf(
+					CodeParamsRoutingFailed, "cluster %s-%s not found", clusterName, tenantID,
+				)
+			}
+			return nil, "", NewErrorf(
+				CodeBackendDown, "unable to resolve SQL pod of tenant %s: %v", tenantID, err,
+			)
+		}
+
+		var conn net.Conn
+		conn, err = BackendDial(msg, addr, tlsConfig)
+		if err == nil {
+			return conn, addr, nil
+		}
+		if codeErr := (*CodeError)(nil); !errors.As(err, &codeErr) || codeErr.code != CodeBackendDown {
+			return nil, "", err
+		}
+
+		// The pod may have gone away: let the directory refresh its view of
+		// the tenant before trying again.
+		log.Infof(ctx, "unable to reach SQL pod %s of tenant %s: %v", addr, tenantID, err)
+		if reportErr := directory.ReportFailure(ctx, tenantID, addr); reportErr != nil {
+			log.Warningf(ctx, "reporting failure of SQL pod %s of tenant %s: %v", addr, tenantID, reportErr)
+		}
+	}
+	return nil, "", err
+}
+
 // SSLOverlay attempts to upgrade the PG connection to use SSL
 // if a tls.Config is specified..
 func SSLOverlay(conn net.Conn, tlsConfig *tls.Config) (net.Conn, error) {
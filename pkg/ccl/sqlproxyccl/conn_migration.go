@@ -0,0 +1,626 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlproxyccl
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/sqlproxyccl/tenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgproto3/v2"
+)
+
+// defaultMigrationCheckInterval is the default interval at which the proxy
+// checks whether the SQL pods of the migratable connections are still part of
+// their tenant.
+const defaultMigrationCheckInterval = time.Second
+
+// migrationTimeout bounds the time spent migrating a connection to another
+// SQL pod.
+const migrationTimeout = 10 * time.Second
+
+// maxMessageSize is the size of the largest pgwire message accepted by the
+// forwarder. It matches the largest message accepted by CockroachDB.
+const maxMessageSize = 1 << 30
+
+const (
+	// serializeSessionQuery serializes the state of the session on the SQL
+	// pod from which the connection is migrated.
+	serializeSessionQuery = `SELECT encode(crdb_internal.serialize_session(), 'hex')`
+	// deserializeSessionQuery restores the state of the session on the SQL
+	// pod to which the connection is migrated.
+	deserializeSessionQuery = `SELECT crdb_internal.deserialize_session(decode('%s', 'hex'))`
+)
+
+// errNotMigratable is returned when the connection can't be migrated to
+// another SQL pod, no matter how many times it's tried.
+var errNotMigratable = errors.New("the connection can't be migrated")
+
+// errAuthNotReplayable is returned when the authentication method of the
+// client can't be replayed on another SQL pod.
+var errAuthNotReplayable = errors.Mark(
+	errors.New("the authentication method of the client can't be replayed"), errNotMigratable,
+)
+
+// forwarder relays the pgwire messages between a client and a SQL pod of a
+// tenant. Unlike a plain copy of the bytes, it tracks the message boundaries
+// and the transaction status reported by the SQL pod, so that it can move the
+// connection to another SQL pod of the tenant while the connection is idle,
+// i.e. while it has no open transaction and no pending results. This happens
+// when the SQL pod is removed from the directory, e.g. because it is being
+// drained. The session state is serialized on the old SQL pod, and restored
+// on the new one after the proxy authenticated with the credentials of the
+// client.
+type forwarder struct {
+	directory   tenant.Resolver
+	tenantID    roachpb.TenantID
+	clusterName string
+	startupMsg  *pgproto3.StartupMessage
+	tlsConfig   *tls.Config
+	creds       clientCredentials
+	metrics     *Metrics
+
+	// checkInterval is the interval at which the forwarder checks whether its
+	// SQL pod is still part of the tenant.
+	checkInterval time.Duration
+
+	clientConn net.Conn
+
+	// closed is closed by Close.
+	closed chan struct{}
+
+	// The following fields are only accessed by the goroutine forwarding the
+	// messages from the SQL pod to the client, which also performs the
+	// migrations.
+
+	// crdbAddr is the address of the SQL pod serving the connection.
+	crdbAddr string
+	// reader reads the messages sent by the SQL pod.
+	reader *backendReader
+	// migrationRequested is set once the SQL pod has been removed from the
+	// tenant, until the connection is migrated or the migration fails. After a
+	// failure, the migration is requested again by the next check of the SQL
+	// pod, so that it is retried at most once per checkInterval.
+	migrationRequested bool
+	// migrationDisabled is set when the connection can't be migrated.
+	migrationDisabled bool
+
+	mu struct {
+		syncutil.Mutex
+		// cond is signaled when a migration completes.
+		cond *sync.Cond
+		// crdbConn is the connection to the SQL pod.
+		crdbConn net.Conn
+		// pendingSyncs is the number of ReadyForQuery messages which the SQL
+		// pod is expected to send in response to the messages forwarded so far.
+		pendingSyncs int
+		// inBatch is set when the client sent extended protocol messages which
+		// weren't followed by a Sync yet.
+		inBatch bool
+		// txnStatus is the transaction status reported by the last
+		// ReadyForQuery message.
+		txnStatus byte
+		// writing is set while a client message is written to crdbConn.
+		writing bool
+		// migrating is set while the connection is migrated. The client
+		// messages are held back until the migration completes.
+		migrating bool
+		// closed is set by Close.
+		closed bool
+	}
+}
+
+func newForwarder(
+	directory tenant.Resolver,
+	tenantID roachpb.TenantID,
+	clusterName string,
+	startupMsg *pgproto3.StartupMessage,
+	tlsConfig *tls.Config,
+	creds clientCredentials,
+	metrics *Metrics,
+	checkInterval time.Duration,
+	clientConn net.Conn,
+	crdbConn net.Conn,
+	crdbAddr string,
+) *forwarder {
+	f := &forwarder{
+		directory:     directory,
+		tenantID:      tenantID,
+		clusterName:   clusterName,
+		startupMsg:    startupMsg,
+		tlsConfig:     tlsConfig,
+		creds:         creds,
+		metrics:       metrics,
+		checkInterval: checkInterval,
+		clientConn:    clientConn,
+		closed:        make(chan struct{}),
+		crdbAddr:      crdbAddr,
+	}
+	f.reader = startBackendReader(bufio.NewReader(crdbConn), f.closed)
+	f.mu.cond = sync.NewCond(&f.mu.Mutex)
+	f.mu.crdbConn = crdbConn
+	// The authentication ended with a ReadyForQuery message, and the
+	// session has no transaction yet.
+	f.mu.txnStatus = 'I'
+	return f
+}
+
+// Close closes the connection to the SQL pod, which may differ from the one
+// the forwarder was created with.
+func (f *forwarder) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.closed {
+		return
+	}
+	f.mu.closed = true
+	close(f.closed)
+	_ = f.mu.crdbConn.Close()
+}
+
+// forwardClientMsgs relays the messages of the client to the SQL pod until
+// reading or writing fails. Like io.Copy, it returns nil once the client
+// closes its connection.
+func (f *forwarder) forwardClientMsgs() error {
+	r := bufio.NewReader(f.clientConn)
+	for {
+		msg, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		crdbConn := f.beginWrite(msg[0])
+		_, err = crdbConn.Write(msg)
+		f.endWrite()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// beginWrite records the client message of the given type which is about to
+// be sent, and returns the connection to which it must be written. It waits
+// for any migration in progress to complete.
+func (f *forwarder) beginWrite(typ byte) net.Conn {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.mu.migrating {
+		f.mu.cond.Wait()
+	}
+	switch typ {
+	case 'Q', 'F':
+		// Simple queries and function calls are answered with a ReadyForQuery.
+		f.mu.pendingSyncs++
+	case 'S':
+		// Sync ends a batch of extended protocol messages.
+		f.mu.pendingSyncs++
+		f.mu.inBatch = false
+	case 'P', 'B', 'E', 'D', 'C', 'H':
+		f.mu.inBatch = true
+	}
+	f.mu.writing = true
+	return f.mu.crdbConn
+}
+
+func (f *forwarder) endWrite() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.writing = false
+}
+
+// forwardServerMsgs relays the messages of the SQL pod to the client until
+// reading or writing fails, or the context is canceled. Like io.Copy, it
+// returns nil once the SQL pod closes its connection. It also migrates the
+// connection to another SQL pod when the current one is removed from the
+// tenant.
+func (f *forwarder) forwardServerMsgs(ctx context.Context) error {
+	var tick <-chan time.Time
+	if f.checkInterval > 0 {
+		ticker := time.NewTicker(f.checkInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case msg := <-f.reader.msgs:
+			if _, err := f.clientConn.Write(msg); err != nil {
+				return err
+			}
+			if msg[0] != 'Z' {
+				continue
+			}
+			f.onReadyForQuery(msg)
+		case <-f.reader.failed:
+			if errors.Is(f.reader.err, io.EOF) {
+				return nil
+			}
+			return f.reader.err
+		case <-tick:
+			if !f.migrationRequested && !f.migrationDisabled {
+				f.migrationRequested = f.podRemoved(ctx)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+		if f.migrationRequested {
+			if err := f.tryMigrate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// onReadyForQuery records the transaction status reported by a ReadyForQuery
+// message of the SQL pod.
+func (f *forwarder) onReadyForQuery(msg []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.pendingSyncs > 0 {
+		f.mu.pendingSyncs--
+	}
+	if len(msg) > 5 {
+		f.mu.txnStatus = msg[5]
+	}
+}
+
+// podRemoved returns true if the SQL pod of the connection is no longer one of
+// the pods of the tenant.
+func (f *forwarder) podRemoved(ctx context.Context) bool {
+	addrs, err := f.directory.LookupTenantAddrs(ctx, f.tenantID)
+	if err != nil {
+		log.Warningf(ctx, "looking up SQL pods of tenant %s: %v", f.tenantID, err)
+		return false
+	}
+	for _, addr := range addrs {
+		if addr == f.crdbAddr {
+			return false
+		}
+	}
+	return true
+}
+
+// tryMigrate migrates the connection to another SQL pod if it is idle. The
+// migration is attempted again after the next forwarded message if the
+// connection isn't idle, or after the next check of the SQL pod if the
+// migration fails. An error is returned if the connection to the current SQL
+// pod can no longer be used.
+func (f *forwarder) tryMigrate(ctx context.Context) error {
+	if !f.beginMigration() {
+		return nil
+	}
+	err := f.migrate(ctx)
+	f.endMigration()
+
+	f.migrationRequested = false
+	var fatalErr *migrationFatalError
+	switch {
+	case err == nil:
+		f.metrics.ConnMigrationSuccessCount.Inc(1)
+		return nil
+	case errors.As(err, &fatalErr):
+		f.metrics.ConnMigrationErrorCount.Inc(1)
+		return fatalErr.err
+	case errors.Is(err, errNotMigratable):
+		f.migrationDisabled = true
+	}
+	f.metrics.ConnMigrationErrorCount.Inc(1)
+	log.Infof(ctx, "unable to migrate connection from SQL pod %s of tenant %s: %v",
+		f.crdbAddr, f.tenantID, err)
+	return nil
+}
+
+// beginMigration returns true if the connection is idle, in which case the
+// client messages are held back until endMigration is called.
+func (f *forwarder) beginMigration() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.pendingSyncs > 0 || f.mu.inBatch || f.mu.txnStatus != 'I' || f.mu.writing {
+		return false
+	}
+	f.mu.migrating = true
+	return true
+}
+
+func (f *forwarder) endMigration() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.migrating = false
+	f.mu.cond.Broadcast()
+}
+
+// migrationFatalError is returned by migrate when the connection to the
+// current SQL pod is left in an unknown state.
+type migrationFatalError struct {
+	err error
+}
+
+func (e *migrationFatalError) Error() string { return e.err.Error() }
+
+// migrate moves the idle connection to another SQL pod of the tenant.
+func (f *forwarder) migrate(ctx context.Context) error {
+	if !f.creds.replayable {
+		return errAuthNotReplayable
+	}
+	ctx, cancel := context.WithTimeout(ctx, migrationTimeout)
+	defer cancel()
+
+	// Serialize the session on the current SQL pod. Its responses are read
+	// through the reader, and are not forwarded to the client.
+	f.mu.Lock()
+	oldConn := f.mu.crdbConn
+	f.mu.Unlock()
+	if err := sendQuery(oldConn, serializeSessionQuery); err != nil {
+		return &migrationFatalError{err: errors.Wrap(err, "serializing session")}
+	}
+	state, err := readSingleValue(func() ([]byte, error) {
+		var err error
+		select {
+		case msg := <-f.reader.msgs:
+			return msg, nil
+		case <-f.reader.failed:
+			err = f.reader.err
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		// The responses to the query may not have been read completely, so
+		// they can't be told apart from the next ones anymore.
+		return nil, &migrationFatalError{err: errors.Wrap(err, "serializing session")}
+	})
+	if err != nil {
+		var pgErr *backendError
+		if errors.As(err, &pgErr) && pgErr.Code == pgcode.ObjectNotInPrerequisiteState.String() {
+			// The session has state which can't be restored on another SQL pod,
+			// such as LISTEN registrations or temporary objects.
+			return errors.Mark(errors.Wrap(err, "serializing session"), errNotMigratable)
+		}
+		return err
+	}
+	// Check that the state is hex-encoded before embedding it in a query.
+	if _, err := hex.DecodeString(state); err != nil {
+		return errors.Wrap(err, "decoding session state")
+	}
+
+	newConn, newAddr, err := dialTenantPod(
+		ctx, f.directory, f.startupMsg, f.tenantID, f.clusterName, f.tlsConfig,
+	)
+	if err != nil {
+		return err
+	}
+	if newAddr == f.crdbAddr {
+		_ = newConn.Close()
+		return errors.Newf("no other SQL pod is available")
+	}
+	newReader, err := f.restoreSession(ctx, newConn, state)
+	if err != nil {
+		_ = newConn.Close()
+		return errors.Wrapf(err, "restoring session on SQL pod %s", newAddr)
+	}
+
+	log.Infof(ctx, "migrated connection of tenant %s from SQL pod %s to %s",
+		f.tenantID, f.crdbAddr, newAddr)
+	f.reader.stop()
+	f.reader = startBackendReader(newReader, f.closed)
+	f.crdbAddr = newAddr
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.crdbConn = newConn
+	_ = oldConn.Close()
+	if f.mu.closed {
+		_ = newConn.Close()
+	}
+	return nil
+}
+
+// restoreSession authenticates to the new SQL pod on behalf of the client,
+// and restores the serialized session state. It returns the reader of the new
+// connection, which may hold buffered messages.
+func (f *forwarder) restoreSession(
+	ctx context.Context, conn net.Conn, state string,
+) (*bufio.Reader, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	r := bufio.NewReader(conn)
+	if err := f.replayAuthentication(conn, r); err != nil {
+		return nil, err
+	}
+	if err := sendQuery(conn, fmt.Sprintf(deserializeSessionQuery, state)); err != nil {
+		return nil, err
+	}
+	if _, err := readSingleValue(func() ([]byte, error) { return readMessage(r) }); err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// replayAuthentication authenticates to a SQL pod with the credentials of the
+// client, and reads the messages until the pod is ready for queries. The
+// messages sent by the SQL pod aren't forwarded to the client, which already
+// received the equivalent ones from the first SQL pod.
+func (f *forwarder) replayAuthentication(conn net.Conn, r *bufio.Reader) error {
+	// The auth step should require only a few back and forths so 20 iterations
+	// should be enough.
+	for i := 0; i < 20; i++ {
+		msg, err := readMessage(r)
+		if err != nil {
+			return err
+		}
+		switch msg[0] {
+		case 'R':
+			if len(msg) < 9 {
+				return errors.Newf("invalid authentication message")
+			}
+			switch binary.BigEndian.Uint32(msg[5:9]) {
+			case 0:
+				// AuthenticationOk.
+			case 3:
+				// AuthenticationCleartextPassword.
+				if f.creds.password == nil {
+					return errAuthNotReplayable
+				}
+				if _, err := conn.Write(f.creds.password.Encode(nil)); err != nil {
+					return err
+				}
+			default:
+				return errAuthNotReplayable
+			}
+		case 'E':
+			return decodeErrorResponse(msg)
+		case 'Z':
+			return nil
+		default:
+			// ParameterStatus, BackendKeyData and NoticeResponse messages are
+			// dropped.
+		}
+	}
+	return errors.Newf("authentication took more than 20 iterations")
+}
+
+// sendQuery sends a simple query to a SQL pod.
+func sendQuery(conn net.Conn, query string) error {
+	_, err := conn.Write((&pgproto3.Query{String: query}).Encode(nil))
+	return err
+}
+
+// readSingleValue reads the responses to a simple query with next until the
+// SQL pod is ready for another query, and returns the first value of the
+// results. An error sent by the SQL pod is returned as a *backendError.
+func readSingleValue(next func() ([]byte, error)) (string, error) {
+	var value string
+	var found bool
+	var queryErr error
+	for {
+		msg, err := next()
+		if err != nil {
+			return "", err
+		}
+		switch msg[0] {
+		case 'D':
+			var row pgproto3.DataRow
+			if err := row.Decode(msg[5:]); err != nil {
+				return "", err
+			}
+			if !found && len(row.Values) > 0 {
+				value, found = string(row.Values[0]), true
+			}
+		case 'E':
+			queryErr = decodeErrorResponse(msg)
+		case 'Z':
+			if queryErr != nil {
+				return "", queryErr
+			}
+			if !found {
+				return "", errors.New("query returned no result")
+			}
+			return value, nil
+		default:
+			// RowDescription, CommandComplete, NoticeResponse and
+			// ParameterStatus messages are dropped.
+		}
+	}
+}
+
+// backendError is an error sent by a SQL pod.
+type backendError struct {
+	pgproto3.ErrorResponse
+}
+
+func (e *backendError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Severity, e.Message)
+}
+
+func decodeErrorResponse(msg []byte) error {
+	pgErr := &backendError{}
+	if err := pgErr.Decode(msg[5:]); err != nil {
+		return err
+	}
+	return pgErr
+}
+
+// backendReader reads the messages of a SQL pod in its own goroutine, so that
+// the forwarder can wait for them along with other events.
+type backendReader struct {
+	msgs chan []byte
+	// failed is closed once reading fails, after setting err.
+	failed chan struct{}
+	err    error
+	// stopped is closed by stop to make the goroutine exit.
+	stopped chan struct{}
+}
+
+// startBackendReader starts reading the messages from r, until reading fails,
+// or stop is called, or closed is closed.
+func startBackendReader(r *bufio.Reader, closed <-chan struct{}) *backendReader {
+	br := &backendReader{
+		msgs:    make(chan []byte),
+		failed:  make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		for {
+			msg, err := readMessage(r)
+			if err != nil {
+				br.err = err
+				close(br.failed)
+				return
+			}
+			select {
+			case br.msgs <- msg:
+			case <-br.stopped:
+				return
+			case <-closed:
+				return
+			}
+		}
+	}()
+	return br
+}
+
+// stop makes the goroutine exit, once it's unblocked from reading by closing
+// its connection.
+func (br *backendReader) stop() {
+	close(br.stopped)
+}
+
+// readMessage reads a typed pgwire message, and returns it whole, including
+// its type and length.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := r.Peek(5)
+	if err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint32(header[1:5]))
+	if size < 4 || size > maxMessageSize {
+		return nil, errors.Newf("invalid message size %d", size)
+	}
+	msg := make([]byte, size+1)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,450 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlproxyccl
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/sqlproxyccl/tenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePod is a SQL pod which speaks just enough pgwire for the tests of the
+// connection migrations. Its sessions have a single value, which is set with
+// "SET <value>", shown with "SHOW" along with the name of the pod, and
+// serialized and deserialized with the queries used by the forwarder. The
+// sessions which ran "LISTEN" can't be serialized.
+type fakePod struct {
+	t        *testing.T
+	name     string
+	password string
+	ln       net.Listener
+	wg       sync.WaitGroup
+
+	// serializeCount is the number of serialization queries received by the
+	// pod.
+	serializeCount int64
+
+	mu struct {
+		syncutil.Mutex
+		conns []net.Conn
+	}
+}
+
+func newFakePod(t *testing.T, name, password string) *fakePod {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &fakePod{t: t, name: name, password: password, ln: ln}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			p.mu.Lock()
+			p.mu.conns = append(p.mu.conns, conn)
+			p.mu.Unlock()
+			p.wg.Add(1)
+			go func() {
+				defer p.wg.Done()
+				p.serve(conn)
+			}()
+		}
+	}()
+	return p
+}
+
+func (p *fakePod) addr() string {
+	return p.ln.Addr().String()
+}
+
+func (p *fakePod) close() {
+	_ = p.ln.Close()
+	p.mu.Lock()
+	for _, conn := range p.mu.conns {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (p *fakePod) serve(conn net.Conn) {
+	be := pgproto3.NewBackend(pgproto3.NewChunkReader(conn), conn)
+	if _, err := be.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	if err := be.Send(&pgproto3.AuthenticationCleartextPassword{}); err != nil {
+		return
+	}
+	msg, err := be.Receive()
+	if err != nil {
+		return
+	}
+	if pwd, ok := msg.(*pgproto3.PasswordMessage); !ok || pwd.Password != p.password {
+		_ = be.Send(&pgproto3.ErrorResponse{Severity: "FATAL", Message: "authentication failed"})
+		return
+	}
+	for _, msg := range []pgproto3.BackendMessage{
+		&pgproto3.AuthenticationOk{},
+		&pgproto3.ParameterStatus{Name: "pod", Value: p.name},
+		&pgproto3.ReadyForQuery{TxStatus: 'I'},
+	} {
+		if err := be.Send(msg); err != nil {
+			return
+		}
+	}
+
+	var value string
+	var listening bool
+	txnStatus := byte('I')
+	for {
+		msg, err := be.Receive()
+		if err != nil {
+			return
+		}
+		query, ok := msg.(*pgproto3.Query)
+		if !ok {
+			return
+		}
+		var row []byte
+		var errResp *pgproto3.ErrorResponse
+		q := query.String
+		switch {
+		case q == serializeSessionQuery:
+			atomic.AddInt64(&p.serializeCount, 1)
+			if listening {
+				errResp = &pgproto3.ErrorResponse{
+					Severity: "ERROR",
+					Code:     pgcode.ObjectNotInPrerequisiteState.String(),
+					Message:  "cannot serialize a session which is listening on channels",
+				}
+				break
+			}
+			row = []byte(hex.EncodeToString([]byte(value)))
+		case strings.HasPrefix(q, "SELECT crdb_internal.deserialize_session(decode('"):
+			state := strings.TrimPrefix(q, "SELECT crdb_internal.deserialize_session(decode('")
+			state = strings.TrimSuffix(state, "', 'hex'))")
+			b, err := hex.DecodeString(state)
+			if err != nil {
+				p.t.Errorf("unexpected state %q", state)
+				return
+			}
+			value = string(b)
+			row = []byte("t")
+		case q == "BEGIN":
+			txnStatus = 'T'
+		case q == "COMMIT":
+			txnStatus = 'I'
+		case q == "LISTEN":
+			listening = true
+		case strings.HasPrefix(q, "SET "):
+			value = strings.TrimPrefix(q, "SET ")
+		case q == "SHOW":
+			row = []byte(p.name + ":" + value)
+		default:
+			p.t.Errorf("unexpected query %q", q)
+			return
+		}
+		var msgs []pgproto3.BackendMessage
+		if errResp != nil {
+			msgs = append(msgs, errResp)
+		} else {
+			if row != nil {
+				msgs = append(msgs, &pgproto3.DataRow{Values: [][]byte{row}})
+			}
+			msgs = append(msgs, &pgproto3.CommandComplete{CommandTag: []byte("OK")})
+		}
+		msgs = append(msgs, &pgproto3.ReadyForQuery{TxStatus: txnStatus})
+		for _, msg := range msgs {
+			if err := be.Send(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// fakeResolver is a tenant.Resolver whose tenant has a mutable set of pods.
+type fakeResolver struct {
+	mu struct {
+		syncutil.Mutex
+		addrs []string
+	}
+}
+
+var _ tenant.Resolver = (*fakeResolver)(nil)
+
+func (r *fakeResolver) setPods(addrs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.addrs = addrs
+}
+
+func (r *fakeResolver) EnsureTenantAddr(
+	ctx context.Context, tenantID roachpb.TenantID, clusterName string,
+) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.mu.addrs) == 0 {
+		return "", errors.New("no pods")
+	}
+	return r.mu.addrs[0], nil
+}
+
+func (r *fakeResolver) LookupTenantAddrs(
+	ctx context.Context, tenantID roachpb.TenantID,
+) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mu.addrs, nil
+}
+
+func (r *fakeResolver) ReportFailure(
+	ctx context.Context, tenantID roachpb.TenantID, addr string,
+) error {
+	return nil
+}
+
+// fakeClient sends simple queries through a forwarder.
+type fakeClient struct {
+	t  *testing.T
+	fe *pgproto3.Frontend
+}
+
+// query runs a simple query and returns its result, if any, along with the
+// transaction status.
+func (c *fakeClient) query(q string) (string, byte) {
+	require.NoError(c.t, c.fe.Send(&pgproto3.Query{String: q}))
+	var result string
+	for {
+		msg, err := c.fe.Receive()
+		require.NoError(c.t, err)
+		switch msg := msg.(type) {
+		case *pgproto3.DataRow:
+			result = string(msg.Values[0])
+		case *pgproto3.ReadyForQuery:
+			return result, msg.TxStatus
+		}
+	}
+}
+
+func TestForwarderMigration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	podA := newFakePod(t, "a", "secret")
+	defer podA.close()
+	podB := newFakePod(t, "b", "secret")
+	defer podB.close()
+	resolver := &fakeResolver{}
+	resolver.setPods(podA.addr())
+
+	// startForwarder connects to the first pod of the tenant, and forwards the
+	// messages of a client to it.
+	startForwarder := func(
+		creds clientCredentials, checkInterval time.Duration,
+	) (*fakeClient, *Metrics, func()) {
+		startupMsg := &pgproto3.StartupMessage{
+			ProtocolVersion: pgproto3.ProtocolVersionNumber,
+			Parameters:      map[string]string{"user": "bob"},
+		}
+		crdbConn, crdbAddr, err := dialTenantPod(
+			ctx, resolver, startupMsg, roachpb.MakeTenantID(10), "", nil, /* tlsConfig */
+		)
+		require.NoError(t, err)
+		clientConn, proxyConn := net.Pipe()
+		errs := make(chan error, 1)
+		go func() {
+			_, err := authenticate(proxyConn, crdbConn)
+			errs <- err
+		}()
+		fe := pgproto3.NewFrontend(pgproto3.NewChunkReader(clientConn), clientConn)
+		for {
+			msg, err := fe.Receive()
+			require.NoError(t, err)
+			if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+				break
+			} else if _, ok := msg.(*pgproto3.AuthenticationCleartextPassword); ok {
+				require.NoError(t, fe.Send(&pgproto3.PasswordMessage{Password: "secret"}))
+			}
+		}
+		require.NoError(t, <-errs)
+
+		metrics := MakeProxyMetrics()
+		f := newForwarder(
+			resolver, roachpb.MakeTenantID(10), "", startupMsg, nil /* tlsConfig */, creds,
+			&metrics, checkInterval, proxyConn, crdbConn, crdbAddr,
+		)
+		fwdCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := f.forwardClientMsgs(); err != nil {
+				t.Errorf("forwarding client messages: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = f.forwardServerMsgs(fwdCtx)
+		}()
+		return &fakeClient{t: t, fe: fe}, &metrics, func() {
+			_ = clientConn.Close()
+			cancel()
+			f.Close()
+			_ = proxyConn.Close()
+			wg.Wait()
+		}
+	}
+
+	t.Run("migrate", func(t *testing.T) {
+		resolver.setPods(podA.addr())
+		client, metrics, stop := startForwarder(clientCredentials{
+			password:   &pgproto3.PasswordMessage{Password: "secret"},
+			replayable: true,
+		}, 10*time.Millisecond)
+		defer stop()
+
+		client.query("SET foo")
+		res, _ := client.query("SHOW")
+		require.Equal(t, "a:foo", res)
+
+		// The connection isn't migrated while it has an open transaction.
+		_, status := client.query("BEGIN")
+		require.Equal(t, byte('T'), status)
+		resolver.setPods(podB.addr())
+		time.Sleep(50 * time.Millisecond)
+		res, _ = client.query("SHOW")
+		require.Equal(t, "a:foo", res)
+		require.Equal(t, int64(0), metrics.ConnMigrationSuccessCount.Count())
+
+		// Once the transaction ends, the session moves to the other pod.
+		_, status = client.query("COMMIT")
+		require.Equal(t, byte('I'), status)
+		testutils.SucceedsSoon(t, func() error {
+			if n := metrics.ConnMigrationSuccessCount.Count(); n != 1 {
+				return errors.Errorf("expected 1 migration, found %d", n)
+			}
+			return nil
+		})
+		res, _ = client.query("SHOW")
+		require.Equal(t, "b:foo", res)
+		require.Equal(t, int64(0), metrics.ConnMigrationErrorCount.Count())
+	})
+
+	t.Run("not replayable", func(t *testing.T) {
+		resolver.setPods(podA.addr())
+		client, metrics, stop := startForwarder(clientCredentials{replayable: false}, 10*time.Millisecond)
+		defer stop()
+
+		client.query("SET bar")
+		resolver.setPods(podB.addr())
+		testutils.SucceedsSoon(t, func() error {
+			if n := metrics.ConnMigrationErrorCount.Count(); n != 1 {
+				return errors.Errorf("expected 1 failed migration, found %d", n)
+			}
+			return nil
+		})
+
+		// The connection stays on its pod, and the migration isn't retried.
+		time.Sleep(50 * time.Millisecond)
+		res, _ := client.query("SHOW")
+		require.Equal(t, "a:bar", res)
+		require.Equal(t, int64(1), metrics.ConnMigrationErrorCount.Count())
+		require.Equal(t, int64(0), metrics.ConnMigrationSuccessCount.Count())
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		resolver.setPods(podA.addr())
+		client, metrics, stop := startForwarder(clientCredentials{
+			password:   &pgproto3.PasswordMessage{Password: "wrong"},
+			replayable: true,
+		}, 10*time.Millisecond)
+		defer stop()
+
+		// The migration is retried, and the connection stays on its pod.
+		resolver.setPods(podB.addr())
+		testutils.SucceedsSoon(t, func() error {
+			if n := metrics.ConnMigrationErrorCount.Count(); n < 2 {
+				return errors.Errorf("expected failed migrations, found %d", n)
+			}
+			return nil
+		})
+		res, _ := client.query("SHOW")
+		require.Equal(t, "a:", res)
+	})
+
+	t.Run("listening", func(t *testing.T) {
+		resolver.setPods(podA.addr())
+		client, metrics, stop := startForwarder(clientCredentials{
+			password:   &pgproto3.PasswordMessage{Password: "secret"},
+			replayable: true,
+		}, 10*time.Millisecond)
+		defer stop()
+
+		// The session can't be serialized, so the connection isn't migrated, and
+		// the migration isn't retried.
+		client.query("LISTEN")
+		serializeCount := atomic.LoadInt64(&podA.serializeCount)
+		resolver.setPods(podB.addr())
+		testutils.SucceedsSoon(t, func() error {
+			if n := metrics.ConnMigrationErrorCount.Count(); n != 1 {
+				return errors.Errorf("expected 1 failed migration, found %d", n)
+			}
+			return nil
+		})
+		for i := 0; i < 10; i++ {
+			res, _ := client.query("SHOW")
+			require.Equal(t, "a:", res)
+			time.Sleep(5 * time.Millisecond)
+		}
+		require.Equal(t, int64(1), metrics.ConnMigrationErrorCount.Count())
+		require.Equal(t, int64(0), metrics.ConnMigrationSuccessCount.Count())
+		require.Equal(t, serializeCount+1, atomic.LoadInt64(&podA.serializeCount))
+	})
+
+	t.Run("retried once per check", func(t *testing.T) {
+		resolver.setPods(podA.addr())
+		client, metrics, stop := startForwarder(clientCredentials{
+			password:   &pgproto3.PasswordMessage{Password: "wrong"},
+			replayable: true,
+		}, time.Second)
+		defer stop()
+
+		// A failed migration isn't retried after every forwarded message, but
+		// only after the next check of the SQL pod.
+		resolver.setPods(podB.addr())
+		testutils.SucceedsSoon(t, func() error {
+			if n := metrics.ConnMigrationErrorCount.Count(); n != 1 {
+				return errors.Errorf("expected 1 failed migration, found %d", n)
+			}
+			return nil
+		})
+		for i := 0; i < 10; i++ {
+			res, _ := client.query("SHOW")
+			require.Equal(t, "a:", res)
+		}
+		require.LessOrEqual(t, metrics.ConnMigrationErrorCount.Count(), int64(2))
+	})
+}
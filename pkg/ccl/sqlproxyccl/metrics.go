@@ -23,6 +23,9 @@ type Metrics struct {
 	SuccessfulConnCount    *metric.Counter
 	AuthFailedCount        *metric.Counter
 	ExpiredClientConnCount *metric.Counter
+
+	ConnMigrationSuccessCount *metric.Counter
+	ConnMigrationErrorCount   *metric.Counter
 }
 
 // MetricStruct implements the metrics.Struct interface.
@@ -91,6 +94,18 @@ var (
 		Measurement: "Expired Client Connections",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaConnMigrationSuccessCount = metric.Metadata{
+		Name:        "proxy.conn_migration.success",
+		Help:        "Number of connections successfully migrated to another SQL pod",
+		Measurement: "Connection Migrations",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaConnMigrationErrorCount = metric.Metadata{
+		Name:        "proxy.conn_migration.error",
+		Help:        "Number of failed attempts to migrate connections to another SQL pod",
+		Measurement: "Connection Migrations",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 // MakeProxyMetrics instantiates the metrics holder for proxy monitoring.
@@ -106,5 +121,8 @@ func MakeProxyMetrics() Metrics {
 		SuccessfulConnCount:    metric.NewCounter(metaSuccessfulConnCount),
 		AuthFailedCount:        metric.NewCounter(metaAuthFailedCount),
 		ExpiredClientConnCount: metric.NewCounter(metaExpiredClientConnCount),
+
+		ConnMigrationSuccessCount: metric.NewCounter(metaConnMigrationSuccessCount),
+		ConnMigrationErrorCount:   metric.NewCounter(metaConnMigrationErrorCount),
 	}
 }
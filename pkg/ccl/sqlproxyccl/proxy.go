@@ -14,7 +14,10 @@ import (
 	"io"
 	"net"
 	"os"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/sqlproxyccl/tenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgproto3/v2"
 )
@@ -24,6 +27,10 @@ const pgAcceptSSLRequest = 'S'
 // See https://www.postgresql.org/docs/9.1/protocol-message-formats.html.
 var pgSSLRequest = []int32{8, 80877103}
 
+// resolveTenantTimeout bounds the time spent waiting for the directory to
+// provide a SQL pod of a tenant, which can involve resuming the tenant.
+const resolveTenantTimeout = 30 * time.Second
+
 // BackendConfig contains the configuration of a backend connection that is
 // being proxied.
 // To be removed once all clients are migrated to use backend dialer.
@@ -32,6 +39,13 @@ type BackendConfig struct {
 	OutgoingAddress string
 	// TLS settings to use when connecting to OutgoingAddress.
 	TLSConf *tls.Config
+	// TenantID identifies the tenant whose SQL pods are resolved through
+	// Options.Directory. When it is set and the proxy has a directory,
+	// OutgoingAddress is ignored.
+	TenantID roachpb.TenantID
+	// ClusterName is the name of the cluster requested by the client. If it
+	// is set, it must match the cluster name of the tenant in the directory.
+	ClusterName string
 	// Called after successfully connecting to OutgoingAddr.
 	OnConnectionSuccess func()
 	// KeepAliveLoop if provided controls the lifetime of the proxy connection.
@@ -82,6 +96,24 @@ type Options struct {
 	// The argument is the startup message received from the frontend. It
 	// contains the protocol version and params sent by the client.
 	BackendDialer func(msg *pgproto3.StartupMessage) (net.Conn, error)
+
+	// If set, used to find the SQL pods of the tenant named by the BackendConfig
+	// returned from BackendConfigFromParams, when no BackendDialer is set. New
+	// connections are balanced across the pods of the tenant, and another pod
+	// is tried when one can't be reached.
+	Directory tenant.Resolver
+
+	// MigrationCheckInterval is the interval at which the proxy checks whether
+	// the SQL pods of the connections resolved through Directory are still
+	// part of their tenants. The connections to SQL pods which were removed,
+	// e.g. because they are being drained, are migrated to other SQL pods of
+	// the tenant once they are idle. Defaults to one second. A negative value
+	// disables the migration of connections.
+	//
+	// A connection can only be migrated if the client authenticated with a
+	// cleartext password or without a password, since the proxy needs to
+	// authenticate on behalf of the client to the new SQL pod.
+	MigrationCheckInterval time.Duration
 }
 
 // Proxy takes an incoming client connection and relays it to a backend SQL
@@ -141,6 +173,9 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 
 	backendDialer := s.opts.BackendDialer
 	var backendConfig *BackendConfig
+	// crdbAddr is the address of the SQL pod resolved through the directory,
+	// if any. Only such connections can be migrated to other SQL pods.
+	var crdbAddr string
 	if s.opts.BackendConfigFromParams != nil {
 		var clientErr error
 		backendConfig, clientErr = s.opts.BackendConfigFromParams(msg.Parameters, proxyConn)
@@ -167,7 +202,18 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 				s.opts.ModifyRequestParams(msg.Parameters)
 			}
 
-			crdbConn, err := BackendDial(msg, backendConfig.OutgoingAddress, backendConfig.TLSConf)
+			var crdbConn net.Conn
+			var err error
+			if s.opts.Directory != nil && backendConfig.TenantID != (roachpb.TenantID{}) {
+				ctx, cancel := context.WithTimeout(context.Background(), resolveTenantTimeout)
+				defer cancel()
+				crdbConn, crdbAddr, err = dialTenantPod(
+					ctx, s.opts.Directory, msg, backendConfig.TenantID, backendConfig.ClusterName,
+					backendConfig.TLSConf,
+				)
+			} else {
+				crdbConn, err = BackendDial(msg, backendConfig.OutgoingAddress, backendConfig.TLSConf)
+			}
 			if err != nil {
 				if codeErr := (*CodeError)(nil); errors.As(err, &codeErr) {
 					sendErrToClient(conn, codeErr.code, codeErr.Error())
@@ -201,7 +247,8 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 	}
 	defer func() { _ = crdbConn.Close() }()
 
-	if err := authenticate(conn, crdbConn); err != nil {
+	creds, err := authenticate(conn, crdbConn)
+	if err != nil {
 		s.metrics.AuthFailedCount.Inc(1)
 		var codeErr *CodeError
 		if !errors.As(err, &codeErr) {
@@ -234,14 +281,28 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 		}
 	}
 
-	go func() {
-		_, err := io.Copy(crdbConn, conn)
-		errOutgoing <- err
-	}()
-	go func() {
-		_, err := io.Copy(conn, crdbConn)
-		errIncoming <- err
-	}()
+	if checkInterval := s.migrationCheckInterval(); crdbAddr != "" && checkInterval > 0 {
+		f := newForwarder(
+			s.opts.Directory, backendConfig.TenantID, backendConfig.ClusterName, msg,
+			backendConfig.TLSConf, creds, s.metrics, checkInterval, conn, crdbConn, crdbAddr,
+		)
+		defer f.Close()
+		go func() {
+			errOutgoing <- f.forwardClientMsgs()
+		}()
+		go func() {
+			errIncoming <- f.forwardServerMsgs(ctx)
+		}()
+	} else {
+		go func() {
+			_, err := io.Copy(crdbConn, conn)
+			errOutgoing <- err
+		}()
+		go func() {
+			_, err := io.Copy(conn, crdbConn)
+			errIncoming <- err
+		}()
+	}
 
 	select {
 	// NB: when using pgx, we see a nil errIncoming first on clean connection
@@ -285,3 +346,13 @@ func (s *Server) Proxy(proxyConn *Conn) error {
 		return nil
 	}
 }
+
+// migrationCheckInterval returns the interval at which the SQL pods of the
+// migratable connections are checked, or a non-positive value if connections
+// aren't migrated.
+func (s *Server) migrationCheckInterval() time.Duration {
+	if s.opts.MigrationCheckInterval == 0 {
+		return defaultMigrationCheckInterval
+	}
+	return s.opts.MigrationCheckInterval
+}
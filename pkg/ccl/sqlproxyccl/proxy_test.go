@@ -20,15 +20,19 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/sqlproxyccl/tenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgproto3/v2"
 	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 const FrontendError = "Frontend error!"
@@ -669,3 +673,71 @@ func TestProxyAgainstSecureCRDBWithIdleTimeout(t *testing.T) {
 	err = conn.QueryRow(context.Background(), "SELECT $1::int", 1).Scan(&n)
 	require.EqualError(t, err, "FATAL: terminating connection due to idle timeout (SQLSTATE 57P01)")
 }
+
+func TestProxyDirectory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	tc := serverutils.StartNewTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	sqlDB.Exec(t, `CREATE USER bob WITH PASSWORD 'builder'`)
+
+	// The tenant has a pod which is down, in addition to the real server.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	dirSrv, err := tenant.NewTestDirectoryServer(stopper)
+	require.NoError(t, err)
+	tenantID := roachpb.MakeTenantID(10)
+	dirSrv.CreateTenant(tenantID, "tenant-cluster")
+	require.True(t, dirSrv.AddPod(tenantID, deadAddr))
+	require.True(t, dirSrv.AddPod(tenantID, tc.Server(0).ServingSQLAddr()))
+
+	dirConn, err := grpc.Dial(dirSrv.Addr(), grpc.WithInsecure())
+	require.NoError(t, err)
+	stopper.AddCloser(stop.CloserFn(func() { _ = dirConn.Close() }))
+	dir, err := tenant.NewDirectory(ctx, stopper, tenant.NewDirectoryClient(dirConn))
+	require.NoError(t, err)
+
+	ac := makeAssertCtx()
+	opts := Options{
+		BackendConfigFromParams: func(params map[string]string, _ *Conn) (*BackendConfig, error) {
+			return &BackendConfig{
+				TenantID:    tenantID,
+				ClusterName: params["cluster"],
+				TLSConf:     &tls.Config{InsecureSkipVerify: true},
+			}, nil
+		},
+		ModifyRequestParams: func(params map[string]string) {
+			delete(params, "cluster")
+		},
+		OnSendErrToClient: ac.onSendErrToClient,
+		Directory:         dir,
+	}
+	s, addr, done := setupTestProxyWithCerts(t, &opts)
+	defer done()
+
+	// Every connection reaches the live pod, even when the dead one is tried
+	// first.
+	const numConns = 4
+	for i := 0; i < numConns; i++ {
+		url := fmt.Sprintf("postgres://bob:builder@%s?sslmode=require&cluster=tenant-cluster", addr)
+		conn, err := pgx.Connect(ctx, url)
+		require.NoError(t, err)
+		require.NoError(t, runTestQuery(conn))
+		require.NoError(t, conn.Close(ctx))
+	}
+	require.Equal(t, int64(numConns), s.metrics.SuccessfulConnCount.Count())
+
+	// Clusters unknown to the directory are rejected.
+	ac.assertConnectErr(
+		t, fmt.Sprintf("postgres://bob:builder@%s/", addr), "?sslmode=require&cluster=other-cluster",
+		CodeParamsRoutingFailed, "cluster other-cluster-10 not found",
+	)
+}
@@ -0,0 +1,423 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package tenant
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Resolver is the interface used by the proxy to map tenants to the
+// addresses of their SQL pods. Implementations must be safe for concurrent
+// use.
+type Resolver interface {
+	// EnsureTenantAddr returns the address of one of the given tenant's SQL
+	// pods. If the tenant has several pods, successive calls balance the
+	// returned addresses across them. If the tenant has no pods, then
+	// EnsureTenantAddr brings one up and waits for it to be ready. A NotFound
+	// GRPC status error is returned if the tenant does not exist, or if
+	// clusterName is not empty and does not match the tenant's cluster name.
+	EnsureTenantAddr(ctx context.Context, tenantID roachpb.TenantID, clusterName string) (string, error)
+
+	// LookupTenantAddrs returns the addresses of all the SQL pods of the
+	// given tenant which are known to be running. Unlike EnsureTenantAddr,
+	// it does not bring up a pod if there is none.
+	LookupTenantAddrs(ctx context.Context, tenantID roachpb.TenantID) ([]string, error)
+
+	// ReportFailure informs the resolver that the proxy could not connect to
+	// the SQL pod at the given address. This gives it a chance to refresh its
+	// knowledge of the tenant's pods, so that the next call to
+	// EnsureTenantAddr returns an address that works.
+	ReportFailure(ctx context.Context, tenantID roachpb.TenantID, addr string) error
+}
+
+// dirOptions control the behavior of tenant.Directory.
+type dirOptions struct {
+	refreshDelay time.Duration
+	podWatcher   chan<- string
+}
+
+// DirOption defines an option that can be passed to tenant.Directory in order
+// to control its behavior.
+type DirOption func(opts *dirOptions)
+
+// RefreshDelay specifies the minimum amount of time that must elapse between
+// attempts to refresh pods for a given tenant after ReportFailure is
+// called. This delay has the effect of throttling calls to directory server,
+// in order to avoid overloading it.
+//
+// RefreshDelay defaults to 100ms. Use -1 to never throttle.
+func RefreshDelay(delay time.Duration) DirOption {
+	return func(opts *dirOptions) {
+		opts.refreshDelay = delay
+	}
+}
+
+// PodWatcher provides a callback channel to which tenant pod change
+// notifications will be sent. Notifications will be sent when a tenant pod
+// is created, modified, or destroyed. The address of the pod is sent to the
+// channel, which is typically used by tests to wait for a change to
+// propagate.
+//
+// NOTE: Callers should ensure that the channel is buffered or that it is
+// read, or else the Directory will block.
+func PodWatcher(podWatcher chan<- string) DirOption {
+	return func(opts *dirOptions) {
+		opts.podWatcher = podWatcher
+	}
+}
+
+// Directory tracks the network locations of SQL tenant processes. It is used
+// by the sqlproxy to route incoming traffic to the correct backend process.
+// Process information is populated and kept current by making GRPC calls to
+// the directory server and by watching its stream of notifications. In
+// addition, Directory tries to bring up a SQL process for a tenant which has
+// none, and throttles the refreshes triggered by ReportFailure.
+//
+// All methods in the directory are thread-safe. Methods are intended to be
+// called concurrently by many threads at once, and so they take pains to
+// minimize contention. Furthermore, if changes in the tenants do not
+// propagate in time (e.g. a pod is removed), then ReportFailure allows the
+// caller to trigger a refresh.
+type Directory struct {
+	// client is the directory api client.
+	client DirectoryClient
+
+	// stopper use used for graceful shutdown of the endpoint watcher.
+	stopper *stop.Stopper
+
+	// options control how the environment operates.
+	options dirOptions
+
+	// mut synchronizes access to the in-memory tenant entry caches. Take care
+	// to never hold this lock during GRPC calls - it should only be held
+	// while adding or removing tenant entries to/from the caches.
+	mut struct {
+		syncutil.Mutex
+
+		// tenants is a cache of tenant entries. Each entry tracks available
+		// addresses for SQL processes for that tenant. The cache is
+		// populated on demand, as new tenants are connected to, and entries
+		// are updated by the endpoint watcher.
+		tenants map[roachpb.TenantID]*tenantEntry
+	}
+}
+
+var _ Resolver = (*Directory)(nil)
+
+// NewDirectory constructs a new Directory instance that tracks SQL tenant
+// processes managed by a given directory server. The given context is used
+// for tracing endpoint watching activity.
+//
+// NOTE: stopper.Stop must be called on the directory when it is no longer
+// needed.
+func NewDirectory(
+	ctx context.Context, stopper *stop.Stopper, client DirectoryClient, opts ...DirOption,
+) (*Directory, error) {
+	dir := &Directory{client: client, stopper: stopper}
+
+	dir.mut.tenants = make(map[roachpb.TenantID]*tenantEntry)
+	for _, opt := range opts {
+		opt(&dir.options)
+	}
+	if dir.options.refreshDelay == 0 {
+		// Default to a delay of 100ms between refresh attempts for a given
+		// tenant.
+		dir.options.refreshDelay = 100 * time.Millisecond
+	}
+
+	// Start the pod watcher on a background goroutine.
+	if err := dir.watchEndpoints(ctx, stopper); err != nil {
+		return nil, err
+	}
+
+	return dir, nil
+}
+
+// EnsureTenantAddr implements the Resolver interface.
+//
+// If the tenant has multiple pods, EnsureTenantAddr returns their addresses
+// in round-robin order. If no pods are available, it asks the directory
+// server to resume the tenant, and waits until a pod has been created and is
+// ready to accept connections. This can take seconds, so callers should
+// bound the wait with a context timeout.
+func (d *Directory) EnsureTenantAddr(
+	ctx context.Context, tenantID roachpb.TenantID, clusterName string,
+) (string, error) {
+	// Ensure that a directory entry has been created for this tenant.
+	entry, err := d.getEntry(ctx, tenantID, true /* allowCreate */)
+	if err != nil {
+		return "", err
+	}
+
+	// Check the cluster name matches the one in the tenant's metadata.
+	if clusterName != "" && clusterName != entry.ClusterName {
+		// Return a GRPC NotFound error.
+		log.Errorf(ctx, "cluster name %s doesn't match expected %s", clusterName, entry.ClusterName)
+		return "", status.Errorf(codes.NotFound,
+			"cluster name %s doesn't match expected %s", clusterName, entry.ClusterName)
+	}
+
+	ctx, cancel := d.stopper.WithCancelOnQuiesce(ctx)
+	defer cancel()
+	addr, err := entry.ChoosePodAddr(ctx, d.client, false /* errorIfNoPods */)
+	if err != nil {
+		return "", err
+	}
+	return addr, nil
+}
+
+// LookupTenantAddrs implements the Resolver interface.
+//
+// If no entry exists for the tenant yet, one is created and its pods are
+// fetched from the directory server.
+func (d *Directory) LookupTenantAddrs(
+	ctx context.Context, tenantID roachpb.TenantID,
+) ([]string, error) {
+	// Ensure that a directory entry has been created for this tenant.
+	entry, err := d.getEntry(ctx, tenantID, true /* allowCreate */)
+	if err != nil {
+		return nil, err
+	}
+	return entry.getPodAddrs(), nil
+}
+
+// ReportFailure implements the Resolver interface.
+//
+// The pods of the tenant are refreshed from the directory server, unless
+// they were refreshed less than RefreshDelay ago.
+func (d *Directory) ReportFailure(
+	ctx context.Context, tenantID roachpb.TenantID, addr string,
+) error {
+	entry, err := d.getEntry(ctx, tenantID, false /* allowCreate */)
+	if err != nil {
+		return err
+	} else if entry == nil {
+		// If no tenant is found, this is a no-op.
+		return nil
+	}
+
+	// Refresh the entry in case there is a new pod address.
+	return entry.RefreshPods(ctx, d.client)
+}
+
+// getEntry returns a directory entry for the given tenant. If the directory
+// does not contain such an entry, then getEntry will create one if allowCreate
+// is true. Otherwise, it returns nil. If an entry is returned, then
+// getEntry ensures that it is fully initialized with tenant metadata and
+// pods. Obtaining this information requires making a round-trip to the
+// directory server, but once it is cached, it is updated by the endpoint
+// watcher and by ReportFailure.
+func (d *Directory) getEntry(
+	ctx context.Context, tenantID roachpb.TenantID, allowCreate bool,
+) (*tenantEntry, error) {
+	entry := func() *tenantEntry {
+		// Acquire the directory lock just long enough to check the tenants
+		// map for the given tenant ID. Don't complete initialization while
+		// holding this lock, since that requires GRPC calls.
+		d.mut.Lock()
+		defer d.mut.Unlock()
+
+		entry, ok := d.mut.tenants[tenantID]
+		if ok {
+			// Entry exists, so return it.
+			return entry
+		}
+
+		if !allowCreate {
+			// No entry, but not allowed to create one, so done.
+			return nil
+		}
+
+		// Create the tenant entry and enter it into the tenants map.
+		log.Infof(ctx, "creating directory entry for tenant %s", tenantID)
+		entry = &tenantEntry{TenantID: tenantID, RefreshDelay: d.options.refreshDelay}
+		d.mut.tenants[tenantID] = entry
+		return entry
+	}()
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	// Initialize the entry now if not yet done.
+	err := entry.Initialize(ctx, d.client)
+	if err != nil {
+		// Remove the entry from the tenants map, since initialization
+		// failed, so that the next lookup tries again.
+		if d.deleteEntry(entry) {
+			log.Infof(ctx, "error initializing tenant %s: %v", tenantID, err)
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// deleteEntry removes the given directory entry for the given tenant, if it
+// exists. It returns true if an entry was actually deleted.
+func (d *Directory) deleteEntry(entry *tenantEntry) bool {
+	// Remove the entry from the tenants map, since initialization failed.
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	// Threads can race to add/remove entries, so ensure that right entry is
+	// removed.
+	existing, ok := d.mut.tenants[entry.TenantID]
+	if ok && entry == existing {
+		delete(d.mut.tenants, entry.TenantID)
+		return true
+	}
+
+	return false
+}
+
+// watchEndpoints establishes a watcher that looks for changes to tenant pods.
+// Whenever tenant pods start or terminate, the watcher will get a
+// notification and update the directory to reflect that change. If the
+// stream to the directory server breaks, the watcher reconnects with a
+// backoff, and drops the cached entries, since changes may have been missed
+// in the meantime.
+func (d *Directory) watchEndpoints(ctx context.Context, stopper *stop.Stopper) error {
+	req := WatchEndpointsRequest{}
+
+	// The loop that processes the event stream is running in a separate go
+	// routine. It is desirable however, that before we return, we have some
+	// guarantees regarding the started watcher. We use this channel to notify
+	// the outer function that the watcher is established.
+	waitChan := make(chan error)
+
+	err := stopper.RunAsyncTask(ctx, "watch-endpoints-client", func(ctx context.Context) {
+		var client Directory_WatchEndpointsClient
+		var err error
+		firstRun := true
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+
+		watchRetryOpts := retry.Options{
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     time.Second,
+			Multiplier:     2,
+			Closer:         stopper.ShouldQuiesce(),
+		}
+
+		for r := retry.StartWithCtx(ctx, watchRetryOpts); r.Next(); {
+			if client == nil {
+				client, err = d.client.WatchEndpoints(ctx, &req)
+				if firstRun {
+					waitChan <- err
+					firstRun = false
+					if err != nil {
+						return
+					}
+				}
+
+				if err != nil {
+					if ctx.Err() != nil || grpcutil.IsClosedConnection(err) {
+						break
+					}
+					log.Errorf(ctx, "err creating new watch endpoint client: %s", err)
+					continue
+				}
+				d.clearEntries()
+				r.Reset()
+			}
+
+			// Read the next watcher event.
+			resp, err := client.Recv()
+			if err != nil {
+				if ctx.Err() != nil || grpcutil.IsClosedConnection(err) {
+					break
+				}
+				if !errors.Is(err, io.EOF) {
+					log.Errorf(ctx, "err receiving stream events: %s", err)
+				}
+				// Loop around and allocate a new client.
+				client = nil
+				continue
+			}
+
+			// Update the directory entry for the tenant with the latest
+			// information about this pod.
+			d.updateTenantEntry(ctx, resp)
+
+			// If caller is watching pods, send to its channel now. Only do
+			// this after updating the tenant entry in the directory.
+			if d.options.podWatcher != nil {
+				select {
+				case d.options.podWatcher <- resp.IP:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Block until the initial endpoint watcher client stream is constructed.
+	return <-waitChan
+}
+
+// updateTenantEntry keeps tenant directory entries up-to-date by handling pod
+// watcher events. When a pod is created, destroyed, or modified, it updates
+// the tenant's entry to reflect that change. Events for tenants which are not
+// in the cache are ignored, since their entries are populated on demand.
+func (d *Directory) updateTenantEntry(ctx context.Context, resp *WatchEndpointsResponse) {
+	if resp.IP == "" || resp.TenantID == 0 {
+		// Nothing needs to be done if there is no IP address or tenant ID
+		// specified.
+		return
+	}
+
+	// Ensure that a directory entry exists for this tenant.
+	entry, err := d.getEntry(ctx, roachpb.MakeTenantID(resp.TenantID), false /* allowCreate */)
+	if err != nil {
+		if status.Code(err) != codes.NotFound {
+			// This should only happen in case of a deleted tenant or a
+			// transient error during fetch of tenant metadata (i.e. very
+			// rarely).
+			log.Errorf(ctx, "ignoring error getting entry for tenant %d: %v", resp.TenantID, err)
+		}
+		return
+	} else if entry == nil {
+		return
+	}
+
+	switch resp.Typ {
+	case ADDED, MODIFIED:
+		if entry.AddPodAddr(resp.IP) {
+			log.Infof(ctx, "added IP address %s for tenant %d", resp.IP, resp.TenantID)
+		}
+	case DELETED:
+		if entry.RemovePodAddr(resp.IP) {
+			log.Infof(ctx, "deleted IP address %s for tenant %d", resp.IP, resp.TenantID)
+		}
+	}
+}
+
+// clearEntries drops all the tenant entries of the directory, so that they
+// are recreated with fresh information on their next use.
+func (d *Directory) clearEntries() {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	d.mut.tenants = make(map[roachpb.TenantID]*tenantEntry)
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestDirectory starts a test directory server, in which tenant 10 has a
+// pod for each of the given addresses, and returns it along with a directory
+// connected to it.
+func newTestDirectory(
+	t *testing.T, stopper *stop.Stopper, podAddrs []string, opts ...DirOption,
+) (*TestDirectoryServer, *Directory) {
+	ctx := context.Background()
+	srv, err := NewTestDirectoryServer(stopper)
+	require.NoError(t, err)
+	srv.CreateTenant(roachpb.MakeTenantID(10), "tenant-cluster")
+	for _, addr := range podAddrs {
+		require.True(t, srv.AddPod(roachpb.MakeTenantID(10), addr))
+	}
+
+	conn, err := grpc.Dial(srv.Addr(), grpc.WithInsecure())
+	require.NoError(t, err)
+	stopper.AddCloser(stop.CloserFn(func() { _ = conn.Close() }))
+
+	dir, err := NewDirectory(ctx, stopper, NewDirectoryClient(conn), opts...)
+	require.NoError(t, err)
+	return srv, dir
+}
+
+// requirePods waits until the directory knows of the given pods of a tenant.
+func requirePods(t *testing.T, dir *Directory, tenantID roachpb.TenantID, expected ...string) {
+	testutils.SucceedsSoon(t, func() error {
+		addrs, err := dir.LookupTenantAddrs(context.Background(), tenantID)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(addrs, expected) {
+			return errors.Errorf("expected pods %v, found %v", expected, addrs)
+		}
+		return nil
+	})
+}
+
+func TestDirectoryErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	srv, dir := newTestDirectory(t, stopper, []string{"127.0.0.1:26257"})
+	tenantID := roachpb.MakeTenantID(10)
+
+	_, err := dir.EnsureTenantAddr(ctx, roachpb.MakeTenantID(11), "")
+	require.Equal(t, codes.NotFound, status.Code(err), "%v", err)
+
+	_, err = dir.LookupTenantAddrs(ctx, roachpb.MakeTenantID(11))
+	require.Equal(t, codes.NotFound, status.Code(err), "%v", err)
+
+	_, err = dir.EnsureTenantAddr(ctx, tenantID, "other-cluster")
+	require.Equal(t, codes.NotFound, status.Code(err), "%v", err)
+	require.Regexp(t, "cluster name other-cluster doesn't match expected tenant-cluster", err)
+
+	// Reporting a failure for a tenant which isn't cached is a no-op.
+	require.NoError(t, dir.ReportFailure(ctx, roachpb.MakeTenantID(11), "127.0.0.1:26257"))
+
+	// The failed lookups didn't prevent the creation of the tenant from being
+	// picked up.
+	srv.CreateTenant(roachpb.MakeTenantID(11), "tenant-cluster")
+	require.True(t, srv.AddPod(roachpb.MakeTenantID(11), "127.0.0.2:26257"))
+	addr, err := dir.EnsureTenantAddr(ctx, roachpb.MakeTenantID(11), "tenant-cluster")
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.2:26257", addr)
+}
+
+func TestWatchPods(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	events := make(chan string, 10)
+	srv, dir := newTestDirectory(t, stopper, []string{"127.0.0.1:1"}, PodWatcher(events))
+	tenantID := roachpb.MakeTenantID(10)
+
+	addrs, err := dir.LookupTenantAddrs(ctx, tenantID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:1"}, addrs)
+
+	// Once the tenant is cached, the changes to its pods are picked up from
+	// the watcher, which notifies the pod watcher after updating the entry.
+	require.True(t, srv.AddPod(tenantID, "127.0.0.1:2"))
+	requirePods(t, dir, tenantID, "127.0.0.1:1", "127.0.0.1:2")
+	require.Equal(t, "127.0.0.1:2", <-events)
+
+	require.True(t, srv.RemovePod(tenantID, "127.0.0.1:1"))
+	requirePods(t, dir, tenantID, "127.0.0.1:2")
+	require.Equal(t, "127.0.0.1:1", <-events)
+
+	addr, err := dir.EnsureTenantAddr(ctx, tenantID, "tenant-cluster")
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:2", addr)
+}
+
+func TestBalancePods(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	const numPods = 3
+	var podAddrs []string
+	for i := 0; i < numPods; i++ {
+		podAddrs = append(podAddrs, fmt.Sprintf("127.0.0.1:%d", i+1))
+	}
+	_, dir := newTestDirectory(t, stopper, podAddrs)
+	tenantID := roachpb.MakeTenantID(10)
+
+	counts := make(map[string]int)
+	for i := 0; i < 3*numPods; i++ {
+		addr, err := dir.EnsureTenantAddr(ctx, tenantID, "")
+		require.NoError(t, err)
+		counts[addr]++
+	}
+	require.Len(t, counts, numPods)
+	for addr, n := range counts {
+		require.Equal(t, 3, n, "pod %s", addr)
+	}
+}
+
+func TestResumeTenant(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	srv, dir := newTestDirectory(t, stopper, nil /* podAddrs */)
+	tenantID := roachpb.MakeTenantID(10)
+
+	// Without a pod, EnsureTenantAddr waits for one to be started.
+	var mu sync.Mutex
+	var started int
+	srv.SetPodStarter(func(ctx context.Context, id roachpb.TenantID) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if id != tenantID {
+			t.Errorf("unexpected tenant %s", id)
+		}
+		started++
+		return "127.0.0.1:10", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addr, err := dir.EnsureTenantAddr(ctx, tenantID, "tenant-cluster")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if addr != "127.0.0.1:10" {
+				t.Errorf("unexpected address %s", addr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 1, started)
+
+	// A canceled context stops the wait for a pod which never starts.
+	srv.CreateTenant(roachpb.MakeTenantID(11), "tenant-cluster")
+	srv.SetPodStarter(nil)
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err := dir.EnsureTenantAddr(ctx, roachpb.MakeTenantID(11), "tenant-cluster")
+	require.Error(t, err)
+}
+
+func TestReportFailure(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	_, dir := newTestDirectory(t, stopper, []string{"127.0.0.1:1"}, RefreshDelay(-1))
+	tenantID := roachpb.MakeTenantID(10)
+
+	addr, err := dir.EnsureTenantAddr(ctx, tenantID, "")
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:1", addr)
+
+	// Drop the pod from the cached entry, as though its addition was missed
+	// by the watcher. Reporting the failure brings the entry up-to-date.
+	entry, err := dir.getEntry(ctx, tenantID, false /* allowCreate */)
+	require.NoError(t, err)
+	require.True(t, entry.RemovePodAddr("127.0.0.1:1"))
+	require.NoError(t, dir.ReportFailure(ctx, tenantID, "127.0.0.1:2"))
+	addrs, err := dir.LookupTenantAddrs(ctx, tenantID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:1"}, addrs)
+}
@@ -0,0 +1,278 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tenantEntry is an entry in the tenant directory that records information
+// about a single tenant, including its ID, cluster name, and the addresses of
+// its SQL pods.
+type tenantEntry struct {
+	// These fields can be read by callers without synchronization, since
+	// they're written once during initialization, and are immutable
+	// thereafter.
+
+	// TenantID is the identifier for this tenant which is unique within a
+	// CRDB cluster.
+	TenantID roachpb.TenantID
+
+	// ClusterName is the name of the tenant's cluster. It is used, along with
+	// the tenant ID, to route connections to the tenant.
+	ClusterName string
+
+	// RefreshDelay is the minimum amount of time that must elapse between
+	// attempts to refresh the pods for this tenant after ReportFailure is
+	// called.
+	RefreshDelay time.Duration
+
+	// initialized is set to true once Initialize has been called.
+	initialized bool
+
+	// initError is set to any error that occurs in Initialize (or nil if no
+	// error occurred).
+	initError error
+
+	// pods synchronizes access to information about the tenant's SQL pods.
+	// These fields can be updated over time, so a lock must be obtained
+	// before accessing them.
+	pods struct {
+		syncutil.Mutex
+		addrs []string
+		// next is the index into addrs of the pod to return from the next
+		// call to ChoosePodAddr. It implements a round-robin policy.
+		next int
+	}
+
+	// calls synchronizes calls to the Directory service for this tenant (e.g.
+	// calls to GetTenant or ListEndpoints). Synchronization is needed to
+	// ensure that only one thread at a time is calling on behalf of a
+	// tenant, and that calls are rate limited to prevent storms.
+	calls struct {
+		syncutil.Mutex
+		lastRefresh time.Time
+	}
+}
+
+// Initialize fetches metadata about a tenant, such as its cluster name, as
+// well as the addresses of its pods, and stores that in the entry. After
+// successful initialization, the entry can be used to resolve pod addresses.
+// Initialize is idempotent and can be called concurrently.
+func (e *tenantEntry) Initialize(ctx context.Context, client DirectoryClient) error {
+	// If Initialize has already been called, return any error that occurred.
+	e.calls.Lock()
+	defer e.calls.Unlock()
+	if e.initialized {
+		return e.initError
+	}
+
+	tenantResp, err := client.GetTenant(ctx, &GetTenantRequest{TenantID: e.TenantID.ToUint64()})
+	if err != nil {
+		e.initialized = true
+		e.initError = err
+		return err
+	}
+
+	e.ClusterName = tenantResp.ClusterName
+
+	_, err = e.fetchPodsLocked(ctx, client)
+	e.initialized = true
+	e.initError = err
+	return err
+}
+
+// RefreshPods makes a synchronous directory server call to fetch the latest
+// information about the tenant's available pods, such as their addresses.
+func (e *tenantEntry) RefreshPods(ctx context.Context, client DirectoryClient) error {
+	// Lock so that only one thread at a time will refresh, since there's no
+	// point in multiple threads doing it within a short span of time - it's
+	// likely nothing has changed.
+	e.calls.Lock()
+	defer e.calls.Unlock()
+
+	// If refreshed recently, no-op.
+	if !e.canRefreshLocked() {
+		return nil
+	}
+
+	log.Infof(ctx, "refreshing tenant %s pods", e.TenantID)
+
+	_, err := e.fetchPodsLocked(ctx, client)
+	return err
+}
+
+// ChoosePodAddr returns the address of one of this tenant's available pods.
+// If a tenant has multiple pods, ChoosePodAddr returns their addresses in
+// round-robin order, so that new connections are balanced across the pods.
+// If a tenant has no available pods, then ChoosePodAddr asks the directory
+// server to bring one up, and blocks until the pod is ready or the context
+// is canceled. If errorIfNoPods is true, then ChoosePodAddr returns a
+// NotFound error instead of waiting when the tenant has no available pods.
+func (e *tenantEntry) ChoosePodAddr(
+	ctx context.Context, client DirectoryClient, errorIfNoPods bool,
+) (string, error) {
+	if addr := e.nextPodAddr(); addr != "" {
+		return addr, nil
+	}
+	if errorIfNoPods {
+		return "", status.Errorf(codes.NotFound, "no pods available for tenant %s", e.TenantID)
+	}
+
+	// There are no known pods, so ask the directory server to bring one up
+	// and wait until it's ready.
+	opts := retry.Options{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		if addr, err := e.ensureTenantPod(ctx, client); err != nil || addr != "" {
+			return addr, err
+		}
+	}
+	return "", ctx.Err()
+}
+
+// AddPodAddr inserts the given address into the tenant's list of pod
+// addresses. If it is already present, then AddPodAddr returns false.
+func (e *tenantEntry) AddPodAddr(addr string) bool {
+	e.pods.Lock()
+	defer e.pods.Unlock()
+
+	for _, existing := range e.pods.addrs {
+		if existing == addr {
+			return false
+		}
+	}
+
+	// Copy on write, since the slice may have been returned to callers of
+	// getPodAddrs.
+	addrs := make([]string, len(e.pods.addrs), len(e.pods.addrs)+1)
+	copy(addrs, e.pods.addrs)
+	e.pods.addrs = append(addrs, addr)
+	return true
+}
+
+// RemovePodAddr removes the given address from the tenant's list of pod
+// addresses. If it was not present, RemovePodAddr returns false.
+func (e *tenantEntry) RemovePodAddr(addr string) bool {
+	e.pods.Lock()
+	defer e.pods.Unlock()
+
+	for i, existing := range e.pods.addrs {
+		if existing == addr {
+			addrs := make([]string, 0, len(e.pods.addrs)-1)
+			addrs = append(addrs, e.pods.addrs[:i]...)
+			e.pods.addrs = append(addrs, e.pods.addrs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// getPodAddrs gets the current list of pod addresses within scope of lock
+// and returns them. The returned slice must not be modified.
+func (e *tenantEntry) getPodAddrs() []string {
+	e.pods.Lock()
+	defer e.pods.Unlock()
+	return e.pods.addrs
+}
+
+// nextPodAddr returns the address of the next pod in round-robin order, or
+// the empty string if the tenant has no known pods.
+func (e *tenantEntry) nextPodAddr() string {
+	e.pods.Lock()
+	defer e.pods.Unlock()
+	if len(e.pods.addrs) == 0 {
+		return ""
+	}
+	addr := e.pods.addrs[e.pods.next%len(e.pods.addrs)]
+	e.pods.next++
+	return addr
+}
+
+// ensureTenantPod ensures that at least one SQL pod exists for this tenant.
+// If none exists, it asks the directory server to bring one up and returns
+// the empty string, so that the caller can retry after the pod had time to
+// start.
+func (e *tenantEntry) ensureTenantPod(ctx context.Context, client DirectoryClient) (string, error) {
+	e.calls.Lock()
+	defer e.calls.Unlock()
+
+	// If an address was added while waiting for the lock, use it.
+	if addr := e.nextPodAddr(); addr != "" {
+		return addr, nil
+	}
+
+	// Try to resume the tenant if not yet resumed.
+	_, err := client.EnsureEndpoint(ctx, &EnsureEndpointRequest{TenantID: e.TenantID.ToUint64()})
+	if err != nil {
+		return "", err
+	}
+
+	// Get pod information for the newly resumed tenant. Except in rare race
+	// conditions, this is expected to be a wait-free operation.
+	addrs, err := e.fetchPodsLocked(ctx, client)
+	if err != nil || len(addrs) == 0 {
+		return "", err
+	}
+	log.Infof(ctx, "resumed tenant %s", e.TenantID)
+	return e.nextPodAddr(), nil
+}
+
+// fetchPodsLocked makes a synchronous directory server call to get the
+// latest information about the tenant's available pods, such as their
+// addresses.
+//
+// NOTE: Caller must lock the "calls" mutex before calling fetchPodsLocked.
+func (e *tenantEntry) fetchPodsLocked(
+	ctx context.Context, client DirectoryClient,
+) (addrs []string, err error) {
+	// List the endpoints for the given tenant.
+	list, err := client.ListEndpoints(ctx, &ListEndpointsRequest{TenantID: e.TenantID.ToUint64()})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get updated list of running pod addresses.
+	addrs = make([]string, 0, len(list.Endpoints))
+	for _, endpoint := range list.Endpoints {
+		addrs = append(addrs, endpoint.IP)
+	}
+
+	e.pods.Lock()
+	defer e.pods.Unlock()
+	e.pods.addrs = addrs
+
+	if len(addrs) != 0 {
+		log.Infof(ctx, "fetched addresses of tenant %s: %v", e.TenantID, addrs)
+	}
+	e.calls.lastRefresh = timeutil.Now()
+
+	return addrs, nil
+}
+
+// canRefreshLocked returns true if it's been at least X milliseconds since
+// the last time the tenant pod information was refreshed. This has the
+// effect of rate limiting RefreshPods calls.
+//
+// NOTE: Caller must lock the "calls" mutex before calling canRefreshLocked.
+func (e *tenantEntry) canRefreshLocked() bool {
+	return timeutil.Since(e.calls.lastRefresh) >= e.RefreshDelay
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package tenant
+
+import (
+	"context"
+	"net"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestDirectoryServer is an in-memory directory server, meant to be used in
+// tests of the directory and of the proxy. Tenants and their pods are
+// registered with CreateTenant and AddPod, and the changes to the pods are
+// streamed to the watchers.
+type TestDirectoryServer struct {
+	stopper  *stop.Stopper
+	grpcSrv  *grpc.Server
+	listener net.Listener
+
+	mu struct {
+		syncutil.Mutex
+		// tenants maps the IDs of the tenants to their metadata.
+		tenants map[roachpb.TenantID]*testTenant
+		// watchers are the active WatchEndpoints streams.
+		watchers map[*testWatcher]struct{}
+		// podStarter, if set, is called by EnsureEndpoint for the tenants
+		// which have no pod, and returns the address of the started pod.
+		podStarter func(ctx context.Context, tenantID roachpb.TenantID) (string, error)
+	}
+}
+
+var _ DirectoryServer = (*TestDirectoryServer)(nil)
+
+type testTenant struct {
+	clusterName string
+	pods        []string
+}
+
+type testWatcher struct {
+	events chan *WatchEndpointsResponse
+	done   <-chan struct{}
+}
+
+// NewTestDirectoryServer starts a directory server listening on a loopback
+// port. The server is stopped when the stopper is quiesced.
+func NewTestDirectoryServer(stopper *stop.Stopper) (*TestDirectoryServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &TestDirectoryServer{stopper: stopper, grpcSrv: grpc.NewServer(), listener: ln}
+	s.mu.tenants = make(map[roachpb.TenantID]*testTenant)
+	s.mu.watchers = make(map[*testWatcher]struct{})
+	RegisterDirectoryServer(s.grpcSrv, s)
+
+	ctx := context.Background()
+	if err := stopper.RunAsyncTask(ctx, "test-directory-server", func(ctx context.Context) {
+		_ = s.grpcSrv.Serve(ln)
+	}); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	if err := stopper.RunAsyncTask(ctx, "test-directory-server-stop", func(ctx context.Context) {
+		<-stopper.ShouldQuiesce()
+		s.grpcSrv.Stop()
+	}); err != nil {
+		s.grpcSrv.Stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Addr returns the address on which the server listens.
+func (s *TestDirectoryServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// SetPodStarter sets the function used by EnsureEndpoint to start a pod for
+// a tenant which has none. The returned address is added to the pods of the
+// tenant. Without a pod starter, EnsureEndpoint does nothing.
+func (s *TestDirectoryServer) SetPodStarter(
+	fn func(ctx context.Context, tenantID roachpb.TenantID) (string, error),
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.podStarter = fn
+}
+
+// CreateTenant registers a tenant with the given cluster name. The tenant has
+// no pod until one is added.
+func (s *TestDirectoryServer) CreateTenant(tenantID roachpb.TenantID, clusterName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.tenants[tenantID] = &testTenant{clusterName: clusterName}
+}
+
+// AddPod adds a pod at the given address to a tenant and notifies the
+// watchers. It returns false if the tenant does not exist or if it already
+// has a pod at that address.
+func (s *TestDirectoryServer) AddPod(tenantID roachpb.TenantID, addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.mu.tenants[tenantID]
+	if !ok {
+		return false
+	}
+	for _, pod := range t.pods {
+		if pod == addr {
+			return false
+		}
+	}
+	t.pods = append(t.pods, addr)
+	s.notifyLocked(&WatchEndpointsResponse{Typ: ADDED, IP: addr, TenantID: tenantID.ToUint64()})
+	return true
+}
+
+// RemovePod removes the pod at the given address from a tenant and notifies
+// the watchers. It returns false if the tenant has no pod at that address.
+func (s *TestDirectoryServer) RemovePod(tenantID roachpb.TenantID, addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.mu.tenants[tenantID]
+	if !ok {
+		return false
+	}
+	for i, pod := range t.pods {
+		if pod == addr {
+			t.pods = append(t.pods[:i:i], t.pods[i+1:]...)
+			s.notifyLocked(&WatchEndpointsResponse{Typ: DELETED, IP: addr, TenantID: tenantID.ToUint64()})
+			return true
+		}
+	}
+	return false
+}
+
+// notifyLocked queues the event for all the watchers. The events are buffered
+// by the watchers, so that notifyLocked doesn't block while holding the lock
+// unless a watcher falls far behind.
+func (s *TestDirectoryServer) notifyLocked(event *WatchEndpointsResponse) {
+	for w := range s.mu.watchers {
+		select {
+		case w.events <- event:
+		case <-w.done:
+		}
+	}
+}
+
+// ListEndpoints implements the DirectoryServer interface.
+func (s *TestDirectoryServer) ListEndpoints(
+	ctx context.Context, req *ListEndpointsRequest,
+) (*ListEndpointsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.getTenantLocked(req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListEndpointsResponse{}
+	for _, pod := range t.pods {
+		resp.Endpoints = append(resp.Endpoints, &Endpoint{IP: pod})
+	}
+	return resp, nil
+}
+
+// WatchEndpoints implements the DirectoryServer interface.
+func (s *TestDirectoryServer) WatchEndpoints(
+	_ *WatchEndpointsRequest, server Directory_WatchEndpointsServer,
+) error {
+	ctx := server.Context()
+	w := &testWatcher{events: make(chan *WatchEndpointsResponse, 100), done: ctx.Done()}
+	func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.mu.watchers[w] = struct{}{}
+	}()
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.mu.watchers, w)
+	}()
+
+	for {
+		select {
+		case event := <-w.events:
+			if err := server.Send(event); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		case <-s.stopper.ShouldQuiesce():
+			return nil
+		}
+	}
+}
+
+// EnsureEndpoint implements the DirectoryServer interface.
+func (s *TestDirectoryServer) EnsureEndpoint(
+	ctx context.Context, req *EnsureEndpointRequest,
+) (*EnsureEndpointResponse, error) {
+	starter, err := func() (func(context.Context, roachpb.TenantID) (string, error), error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		t, err := s.getTenantLocked(req.TenantID)
+		if err != nil || len(t.pods) != 0 {
+			return nil, err
+		}
+		return s.mu.podStarter, nil
+	}()
+	if err != nil {
+		return nil, err
+	} else if starter == nil {
+		return &EnsureEndpointResponse{}, nil
+	}
+
+	// Start the pod without holding the lock, since that can take a while.
+	tenantID := roachpb.MakeTenantID(req.TenantID)
+	addr, err := starter(ctx, tenantID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "starting pod of tenant %s: %v", tenantID, err)
+	}
+	s.AddPod(tenantID, addr)
+	return &EnsureEndpointResponse{}, nil
+}
+
+// GetTenant implements the DirectoryServer interface.
+func (s *TestDirectoryServer) GetTenant(
+	ctx context.Context, req *GetTenantRequest,
+) (*GetTenantResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.getTenantLocked(req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &GetTenantResponse{ClusterName: t.clusterName}, nil
+}
+
+func (s *TestDirectoryServer) getTenantLocked(tenantID uint64) (*testTenant, error) {
+	if tenantID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid tenant ID 0")
+	}
+	t, ok := s.mu.tenants[roachpb.MakeTenantID(tenantID)]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tenant %d not found", tenantID)
+	}
+	return t, nil
+}
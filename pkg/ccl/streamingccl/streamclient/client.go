@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 )
 
 // Client provides a way for the stream ingestion job to consume a
@@ -20,17 +21,22 @@ import (
 // TODO(57427): The stream client does not yet support the concept of
 //  generations in a stream.
 type Client interface {
-	// GetTopology returns the Topology of a stream.
-	GetTopology(address streamingccl.StreamAddress) (streamingccl.Topology, error)
+	// GetTopology returns the Topology of a stream of the given tenant's
+	// keyspace.
+	GetTopology(address streamingccl.StreamAddress, tenantID roachpb.TenantID) (streamingccl.Topology, error)
 
 	// ConsumePartition returns a channel on which we can start listening for
-	// events from a given partition that occur after a startTime.
+	// events from a given partition that occur after a startTime. A zero
+	// startTime requests the current contents of the partition to be emitted
+	// first.
 	//
 	// Canceling the context will stop reading the partition and close the event
+	// channel. If the client encounters an error while reading the partition, it
+	// sends the error on the returned error channel before closing the event
 	// channel.
-	// TODO: Add an error channel so that the client can report any errors
-	// encountered while reading the stream.
-	ConsumePartition(ctx context.Context, address streamingccl.PartitionAddress, startTime time.Time) (chan streamingccl.Event, error)
+	ConsumePartition(
+		ctx context.Context, address streamingccl.PartitionAddress, startTime time.Time,
+	) (chan streamingccl.Event, chan error, error)
 }
 
 // NewStreamClient creates a new stream client based on the stream
@@ -43,6 +49,8 @@ func NewStreamClient(streamAddress streamingccl.StreamAddress) (Client, error) {
 	}
 
 	switch streamURL.Scheme {
+	case "postgres", "postgresql":
+		streamClient = &sinklessReplicationClient{}
 	case TestScheme:
 		streamClient, err = newRandomStreamClient(streamURL)
 		if err != nil {
@@ -28,7 +28,7 @@ var _ Client = testStreamClient{}
 
 // GetTopology implements the Client interface.
 func (sc testStreamClient) GetTopology(
-	_ streamingccl.StreamAddress,
+	_ streamingccl.StreamAddress, _ roachpb.TenantID,
 ) (streamingccl.Topology, error) {
 	return streamingccl.Topology{Partitions: []streamingccl.PartitionAddress{
 		"s3://my_bucket/my_stream/partition_1",
@@ -39,7 +39,7 @@ func (sc testStreamClient) GetTopology(
 // ConsumePartition implements the Client interface.
 func (sc testStreamClient) ConsumePartition(
 	_ context.Context, pa streamingccl.PartitionAddress, _ time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	sampleKV := roachpb.KeyValue{
 		Key: []byte("key_1"),
 		Value: roachpb.Value{
@@ -53,14 +53,14 @@ func (sc testStreamClient) ConsumePartition(
 	events <- streamingccl.MakeCheckpointEvent(hlc.Timestamp{WallTime: 100})
 	close(events)
 
-	return events, nil
+	return events, nil, nil
 }
 
 // ExampleClientUsage serves as documentation to indicate how a stream
 // client could be used.
 func ExampleClient() {
 	client := testStreamClient{}
-	topology, err := client.GetTopology("s3://my_bucket/my_stream")
+	topology, err := client.GetTopology("s3://my_bucket/my_stream", roachpb.MakeTenantID(10))
 	if err != nil {
 		panic(err)
 	}
@@ -68,7 +68,7 @@ func ExampleClient() {
 	startTimestamp := timeutil.Now()
 
 	for _, partition := range topology.Partitions {
-		eventCh, err := client.ConsumePartition(context.Background(), partition, startTimestamp)
+		eventCh, errCh, err := client.ConsumePartition(context.Background(), partition, startTimestamp)
 		if err != nil {
 			panic(err)
 		}
@@ -87,6 +87,11 @@ func ExampleClient() {
 				panic(fmt.Sprintf("unexpected event type %v", event.Type()))
 			}
 		}
+		select {
+		case err := <-errCh:
+			panic(err)
+		default:
+		}
 	}
 
 	// Output:
@@ -112,7 +117,7 @@ func TestImplementationsCloseChannel(t *testing.T) {
 
 	for _, impl := range impls {
 		ctx, cancel := context.WithCancel(context.Background())
-		eventCh, err := impl.ConsumePartition(ctx, "test://53/", timeutil.Now())
+		eventCh, _, err := impl.ConsumePartition(ctx, "test://53/", timeutil.Now())
 		require.NoError(t, err)
 
 		// Ensure that the eventCh closes when the context is canceled.
@@ -0,0 +1,220 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamclient
+
+import (
+	"context"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx"
+)
+
+const (
+	// TenantIDParam is the query parameter of a partition address produced by
+	// the sinkless replication client which holds the ID of the tenant whose
+	// keyspace is streamed.
+	TenantIDParam = "TENANT_ID"
+	// PartitionStartKeyParam is the query parameter of a partition address
+	// produced by the sinkless replication client which holds the hex encoded
+	// start key of the partition.
+	PartitionStartKeyParam = "PARTITION_START_KEY"
+	// PartitionEndKeyParam is the query parameter of a partition address
+	// produced by the sinkless replication client which holds the hex encoded
+	// end key of the partition.
+	PartitionEndKeyParam = "PARTITION_END_KEY"
+)
+
+// sinklessReplicationClient reads a stream from a source cluster over a SQL
+// connection. The topology of the stream is resolved with the
+// crdb_internal.stream_topology builtin of the source cluster, and each
+// partition is read with a long-running crdb_internal.stream_partition query.
+type sinklessReplicationClient struct{}
+
+var _ Client = &sinklessReplicationClient{}
+
+// connect opens a connection to the source cluster at the address, after
+// stripping the partition parameters added by this client.
+func (m *sinklessReplicationClient) connect(addr *url.URL) (*pgx.Conn, error) {
+	connURL := *addr
+	q := connURL.Query()
+	q.Del(TenantIDParam)
+	q.Del(PartitionStartKeyParam)
+	q.Del(PartitionEndKeyParam)
+	// Disable result buffering so that every event is flushed to the client as
+	// soon as it is produced. The vectorized engine is disabled for the same
+	// reason, since it batches up the rows of the stream before returning them.
+	q.Set("results_buffer_size", "0")
+	q.Set("vectorize", "off")
+	connURL.RawQuery = q.Encode()
+
+	// Use pgx directly instead of database/sql so that each partition is read
+	// over its own connection, which is closed once the partition is no longer
+	// consumed.
+	connCfg, err := pgx.ParseConnectionString(connURL.String())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.Connect(connCfg)
+}
+
+// GetTopology implements the Client interface.
+func (m *sinklessReplicationClient) GetTopology(
+	address streamingccl.StreamAddress, tenantID roachpb.TenantID,
+) (streamingccl.Topology, error) {
+	streamURL, err := address.URL()
+	if err != nil {
+		return streamingccl.Topology{}, err
+	}
+	conn, err := m.connect(streamURL)
+	if err != nil {
+		return streamingccl.Topology{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	rows, err := conn.Query(
+		`SELECT start_key, end_key FROM crdb_internal.stream_topology($1)`, tenantID.ToUint64(),
+	)
+	if err != nil {
+		return streamingccl.Topology{}, err
+	}
+	defer rows.Close()
+
+	var topology streamingccl.Topology
+	for rows.Next() {
+		var startKey, endKey []byte
+		if err := rows.Scan(&startKey, &endKey); err != nil {
+			return streamingccl.Topology{}, err
+		}
+		partitionURL := *streamURL
+		q := partitionURL.Query()
+		q.Set(TenantIDParam, strconv.FormatUint(tenantID.ToUint64(), 10))
+		q.Set(PartitionStartKeyParam, hex.EncodeToString(startKey))
+		q.Set(PartitionEndKeyParam, hex.EncodeToString(endKey))
+		partitionURL.RawQuery = q.Encode()
+		topology.Partitions = append(topology.Partitions,
+			streamingccl.PartitionAddress(partitionURL.String()))
+	}
+	return topology, rows.Err()
+}
+
+// parsePartitionAddress returns the tenant ID and the span of the partition
+// identified by the address.
+func parsePartitionAddress(addr *url.URL) (roachpb.TenantID, roachpb.Span, error) {
+	q := addr.Query()
+	tenantID, err := strconv.ParseUint(q.Get(TenantIDParam), 10, 64)
+	if err != nil {
+		return roachpb.TenantID{}, roachpb.Span{}, errors.Wrapf(err, "parsing %s", TenantIDParam)
+	}
+	startKey, err := hex.DecodeString(q.Get(PartitionStartKeyParam))
+	if err != nil {
+		return roachpb.TenantID{}, roachpb.Span{}, errors.Wrapf(err, "parsing %s", PartitionStartKeyParam)
+	}
+	endKey, err := hex.DecodeString(q.Get(PartitionEndKeyParam))
+	if err != nil {
+		return roachpb.TenantID{}, roachpb.Span{}, errors.Wrapf(err, "parsing %s", PartitionEndKeyParam)
+	}
+	if tenantID == 0 || len(startKey) == 0 || len(endKey) == 0 {
+		return roachpb.TenantID{}, roachpb.Span{}, errors.Newf("partition address %s is missing "+
+			"the tenant ID or the partition span", addr.Redacted())
+	}
+	return roachpb.MakeTenantID(tenantID), roachpb.Span{Key: startKey, EndKey: endKey}, nil
+}
+
+// ConsumePartition implements the Client interface.
+func (m *sinklessReplicationClient) ConsumePartition(
+	ctx context.Context, address streamingccl.PartitionAddress, startTime time.Time,
+) (chan streamingccl.Event, chan error, error) {
+	partitionURL, err := address.URL()
+	if err != nil {
+		return nil, nil, err
+	}
+	tenantID, span, err := parsePartitionAddress(partitionURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var startTS hlc.Timestamp
+	if !startTime.IsZero() {
+		startTS = hlc.Timestamp{WallTime: startTime.UnixNano()}
+	}
+
+	conn, err := m.connect(partitionURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := conn.QueryEx(ctx,
+		`SELECT key, value, timestamp::STRING FROM crdb_internal.stream_partition($1, $2, $3, $4::DECIMAL)`,
+		nil, /* options */
+		tenantID.ToUint64(), []byte(span.Key), []byte(span.EndKey), startTS.AsOfSystemTime(),
+	)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	eventCh := make(chan streamingccl.Event)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(eventCh)
+		// Closing the connection also stops the query on the source cluster,
+		// which would otherwise stream events forever.
+		defer func() { _ = conn.Close() }()
+
+		for rows.Next() {
+			var key, value []byte
+			var tsStr string
+			if err := rows.Scan(&key, &value, &tsStr); err != nil {
+				errCh <- err
+				return
+			}
+			ts, err := parseHLC(tsStr)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			var event streamingccl.Event
+			if key == nil {
+				event = streamingccl.MakeCheckpointEvent(ts)
+			} else {
+				event = streamingccl.MakeKVEvent(roachpb.KeyValue{
+					Key:   key,
+					Value: roachpb.Value{RawBytes: value, Timestamp: ts},
+				})
+			}
+
+			select {
+			case eventCh <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := rows.Err(); err != nil && ctx.Err() == nil {
+			errCh <- err
+		}
+	}()
+
+	return eventCh, errCh, nil
+}
+
+// parseHLC parses the decimal representation of an HLC timestamp.
+func parseHLC(s string) (hlc.Timestamp, error) {
+	d, err := tree.ParseDDecimal(s)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	return tree.DecimalToHLC(&d.Decimal)
+}
@@ -176,7 +176,7 @@ func getNextTableID() int {
 
 // GetTopology implements the Client interface.
 func (m *randomStreamClient) GetTopology(
-	_ streamingccl.StreamAddress,
+	_ streamingccl.StreamAddress, _ roachpb.TenantID,
 ) (streamingccl.Topology, error) {
 	topology := streamingccl.Topology{Partitions: make([]streamingccl.PartitionAddress,
 		0, m.config.numPartitions)}
@@ -239,7 +239,7 @@ func (m *randomStreamClient) getDescriptorAndNamespaceKVForTableID(
 // ConsumePartition implements the Client interface.
 func (m *randomStreamClient) ConsumePartition(
 	ctx context.Context, partitionAddress streamingccl.PartitionAddress, startTime time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	eventCh := make(chan streamingccl.Event)
 	now := timeutil.Now()
 	if startTime.After(now) {
@@ -248,17 +248,17 @@ func (m *randomStreamClient) ConsumePartition(
 
 	partitionURL, err := partitionAddress.URL()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var partitionTableID int
 	partitionTableID, err = strconv.Atoi(partitionURL.Host)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tableDesc, systemKVs, err := m.getDescriptorAndNamespaceKVForTableID(descpb.ID(partitionTableID))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	go func() {
 		defer close(eventCh)
@@ -328,7 +328,7 @@ func (m *randomStreamClient) ConsumePartition(
 		}
 	}()
 
-	return eventCh, nil
+	return eventCh, nil, nil
 }
 
 func (m *randomStreamClient) makeRandomKey(
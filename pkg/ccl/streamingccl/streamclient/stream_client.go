@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 )
 
 // mockClient is a mock stream client.
@@ -21,7 +22,9 @@ type mockClient struct{}
 var _ Client = &mockClient{}
 
 // GetTopology implements the Client interface.
-func (m *mockClient) GetTopology(_ streamingccl.StreamAddress) (streamingccl.Topology, error) {
+func (m *mockClient) GetTopology(
+	_ streamingccl.StreamAddress, _ roachpb.TenantID,
+) (streamingccl.Topology, error) {
 	return streamingccl.Topology{
 		Partitions: []streamingccl.PartitionAddress{"some://address"},
 	}, nil
@@ -30,11 +33,11 @@ func (m *mockClient) GetTopology(_ streamingccl.StreamAddress) (streamingccl.Top
 // ConsumePartition implements the Client interface.
 func (m *mockClient) ConsumePartition(
 	ctx context.Context, _ streamingccl.PartitionAddress, _ time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	eventCh := make(chan streamingccl.Event)
 	go func() {
 		<-ctx.Done()
 		close(eventCh)
 	}()
-	return eventCh, nil
+	return eventCh, nil, nil
 }
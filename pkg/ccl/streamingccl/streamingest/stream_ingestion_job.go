@@ -10,15 +10,22 @@ package streamingest
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -26,10 +33,20 @@ type streamIngestionResumer struct {
 	job *jobs.Job
 }
 
+// cutoverSignalPollInterval is the interval at which the stream ingestion job
+// checks whether it has been signaled to cut over.
+var cutoverSignalPollInterval = settings.RegisterDurationSetting(
+	"bulkio.stream_ingestion.cutover_signal_poll_interval",
+	"the interval at which the stream ingestion job checks if it has been signaled to cutover",
+	30*time.Second,
+	settings.NonNegativeDuration,
+)
+
 func ingest(
 	ctx context.Context,
 	execCtx sql.JobExecContext,
 	streamAddress streamingccl.StreamAddress,
+	tenantID roachpb.TenantID,
 	progress jobspb.Progress,
 	jobID int64,
 ) error {
@@ -38,16 +55,19 @@ func ingest(
 	if err != nil {
 		return err
 	}
-	topology, err := client.GetTopology(streamAddress)
+	topology, err := client.GetTopology(streamAddress, tenantID)
 	if err != nil {
 		return err
 	}
 
-	// TODO(adityamaru): If the job is being resumed it is possible that it has
-	// check-pointed a resolved ts up to which all of its processors had ingested
-	// KVs. We can skip to ingesting after this resolved ts. Plumb the
-	// initialHighwatermark to the ingestion processor spec based on what we read
-	// from the job progress.
+	// If the job is being resumed it has check-pointed a resolved ts up to which
+	// all of its processors had ingested KVs, so we can skip to ingesting after
+	// it. Stream clients only accept a wall time to start from, so the logical
+	// component of the high-water is dropped, which may replay a few events.
+	var initialHighWater hlc.Timestamp
+	if h := progress.GetHighWater(); h != nil && !h.IsEmpty() {
+		initialHighWater = hlc.Timestamp{WallTime: h.WallTime}
+	}
 
 	evalCtx := execCtx.ExtendedEvalContext()
 	dsp := execCtx.DistSQLPlanner()
@@ -59,14 +79,12 @@ func ingest(
 
 	// Construct stream ingestion processor specs.
 	streamIngestionSpecs, streamIngestionFrontierSpec, err := distStreamIngestionPlanSpecs(
-		streamAddress, topology, nodes, jobID)
+		streamAddress, topology, nodes, initialHighWater, jobID)
 	if err != nil {
 		return err
 	}
 
 	// Plan and run the DistSQL flow.
-	// TODO: Updates from this flow need to feed back into the job to update the
-	// progress.
 	err = distStreamIngest(ctx, execCtx, nodes, jobID, planCtx, dsp, streamIngestionSpecs,
 		streamIngestionFrontierSpec)
 	if err != nil {
@@ -76,28 +94,162 @@ func ingest(
 	return nil
 }
 
+// checkForCutoverSignal periodically loads the job progress to see if it has
+// been marked for completion. Once it has, cancelIngestion is called to stop
+// the ingestion flow.
+func (s *streamIngestionResumer) checkForCutoverSignal(
+	ctx context.Context, sv *settings.Values, registry *jobs.Registry, cancelIngestion func(),
+) (cutover bool, _ error) {
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for {
+		timer.Reset(cutoverSignalPollInterval.Get(sv))
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			timer.Read = true
+			j, err := registry.LoadJob(ctx, *s.job.ID())
+			if err != nil {
+				return false, err
+			}
+			progress := j.Progress()
+			sp, ok := progress.GetDetails().(*jobspb.Progress_StreamIngest)
+			if !ok {
+				return false, errors.AssertionFailedf("job %d: unknown progress type %T",
+					*s.job.ID(), progress.GetDetails())
+			}
+			if sp.StreamIngest.MarkedForCompletion {
+				cancelIngestion()
+				return true, nil
+			}
+		}
+	}
+}
+
 // Resume is part of the jobs.Resumer interface.
 func (s *streamIngestionResumer) Resume(ctx context.Context, execCtx interface{}) error {
 	details := s.job.Details().(jobspb.StreamIngestionDetails)
 	p := execCtx.(sql.JobExecContext)
 
-	err := ingest(ctx, p, details.StreamAddress, s.job.Progress(),
-		*s.job.ID())
+	_, tenantID, err := keys.DecodeTenantPrefix(details.Span.Key)
 	if err != nil {
 		return err
 	}
 
+	// Ingest the stream until either the ingestion fails, or the job is signaled
+	// to cut over, at which point the ingestion is canceled.
+	var cutover bool
+	ingestCtx, cancelIngestion := context.WithCancel(ctx)
+	defer cancelIngestion()
+	g := ctxgroup.WithContext(ingestCtx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer cancelIngestion()
+		return ingest(ctx, p, details.StreamAddress, tenantID, s.job.Progress(), *s.job.ID())
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		var err error
+		cutover, err = s.checkForCutoverSignal(ctx, &p.ExecCfg().Settings.SV,
+			p.ExecCfg().JobRegistry, cancelIngestion)
+		return err
+	})
+	if err := g.Wait(); err != nil && !cutover {
+		return err
+	}
+	if !cutover {
+		return errors.New("stream ingestion ended before the job was signaled to cutover")
+	}
+
 	// TODO(adityamaru): We probably want to use the resultsCh to indicate that
 	// the processors have completed setup. We can then return the job ID in the
 	// plan hook similar to how changefeeds do it.
 
-	return nil
+	return s.cutover(ctx, p.ExecCfg(), details, tenantID)
+}
+
+// cutover reverts the ingested data to the job high-water, which is the latest
+// timestamp at which the ingested data is consistent, and activates the tenant
+// whose keyspace was ingested.
+func (s *streamIngestionResumer) cutover(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	details jobspb.StreamIngestionDetails,
+	tenantID roachpb.TenantID,
+) error {
+	j, err := execCfg.JobRegistry.LoadJob(ctx, *s.job.ID())
+	if err != nil {
+		return err
+	}
+	progress := j.Progress()
+	highWater := progress.GetHighWater()
+	if highWater == nil || highWater.IsEmpty() {
+		return errors.Newf("cannot cutover job %d before it has ingested a consistent snapshot "+
+			"of the stream", *s.job.ID())
+	}
+	if knobs := testingKnobs(execCfg); knobs != nil && knobs.BeforeCutover != nil {
+		if err := knobs.BeforeCutover(ctx, *highWater); err != nil {
+			return err
+		}
+	}
+	log.Infof(ctx, "cutting over stream ingestion job %d at %s", *s.job.ID(), highWater)
+	if err := revertSpan(ctx, execCfg, details.Span, *highWater); err != nil {
+		return err
+	}
+	if tenantID == roachpb.SystemTenantID {
+		return nil
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return sql.ActivateTenant(ctx, execCfg, txn, tenantID.ToUint64())
+	})
+}
+
+// testingKnobs returns the stream ingestion testing knobs of the server, if
+// any.
+func testingKnobs(execCfg *sql.ExecutorConfig) *sql.StreamIngestionTestingKnobs {
+	knobs, _ := execCfg.DistSQLSrv.TestingKnobs.StreamIngestionTestingKnobs.(*sql.StreamIngestionTestingKnobs)
+	return knobs
+}
+
+// revertSpan reverts all the data in the span to its state as of the target
+// time. Like sql.RevertTables, it reverts the span in batches of at most
+// sql.RevertTableDefaultBatchSize keys, and follows the resume span of each
+// batch until the whole span has been reverted.
+func revertSpan(
+	ctx context.Context, execCfg *sql.ExecutorConfig, span roachpb.Span, targetTime hlc.Timestamp,
+) error {
+	batchSize := int64(sql.RevertTableDefaultBatchSize)
+	if knobs := testingKnobs(execCfg); knobs != nil && knobs.RevertBatchSize > 0 {
+		batchSize = knobs.RevertBatchSize
+	}
+	for {
+		var b kv.Batch
+		b.AddRawRequest(&roachpb.RevertRangeRequest{
+			RequestHeader: roachpb.RequestHeader{
+				Key:    span.Key,
+				EndKey: span.EndKey,
+			},
+			TargetTime:                          targetTime,
+			EnableTimeBoundIteratorOptimization: true,
+		})
+		b.Header.MaxSpanRequestKeys = batchSize
+		if err := execCfg.DB.Run(ctx, &b); err != nil {
+			return err
+		}
+
+		r := b.RawResponse().Responses[0].GetRevertRange()
+		if r.ResumeSpan == nil {
+			return nil
+		}
+		if !r.ResumeSpan.Valid() {
+			return errors.Errorf("invalid resume span: %s", r.ResumeSpan)
+		}
+		span = *r.ResumeSpan
+	}
 }
 
 // OnFailOrCancel is part of the jobs.Resumer interface.
 func (s *streamIngestionResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
 	p := execCtx.(sql.JobExecContext)
-	db := p.ExecCfg().DB
 	details := s.job.Details().(jobspb.StreamIngestionDetails)
 
 	resolvedTime := details.StartTime
@@ -109,17 +261,7 @@ func (s *streamIngestionResumer) OnFailOrCancel(ctx context.Context, execCtx int
 		}
 		resolvedTime = *highWatermark
 	}
-	var b kv.Batch
-	b.AddRawRequest(&roachpb.RevertRangeRequest{
-		RequestHeader: roachpb.RequestHeader{
-			Key:    details.Span.Key,
-			EndKey: details.Span.EndKey,
-		},
-		TargetTime:                          resolvedTime,
-		EnableTimeBoundIteratorOptimization: true,
-	})
-	b.Header.MaxSpanRequestKeys = sql.RevertTableDefaultBatchSize
-	return db.Run(ctx, &b)
+	return revertSpan(ctx, p.ExecCfg(), details.Span, resolvedTime)
 }
 
 var _ jobs.Resumer = &streamIngestionResumer{}
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -80,7 +81,7 @@ func ingestionPlanHook(
 		prefix := keys.MakeTenantPrefix(ingestionStmt.Targets.Tenant)
 		streamIngestionDetails := jobspb.StreamIngestionDetails{
 			StreamAddress: streamingccl.StreamAddress(from[0]),
-			Span:          roachpb.Span{Key: prefix, EndKey: prefix.Next()},
+			Span:          roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
 			// TODO: Figure out what the initial ts should be.
 			StartTime: hlc.Timestamp{},
 		}
@@ -99,6 +100,14 @@ func ingestionPlanHook(
 
 		var sj *jobs.StartableJob
 		if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+			// Create the record of the ingested tenant, which remains inactive until
+			// the job cuts over.
+			if err := sql.CreateTenantRecord(ctx, p.ExecCfg(), txn, &descpb.TenantInfo{
+				ID:    ingestionStmt.Targets.Tenant.ToUint64(),
+				State: descpb.TenantInfo_ADD,
+			}); err != nil {
+				return err
+			}
 			sj, err = p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, jr, txn)
 			return err
 		}); err != nil {
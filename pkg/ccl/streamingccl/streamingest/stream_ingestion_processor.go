@@ -12,6 +12,7 @@ import (
 	"context"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
@@ -68,6 +69,8 @@ type streamIngestionProcessor struct {
 
 	// eventCh is the merged event channel of all of the partition event streams.
 	eventCh chan partitionEvent
+	// errCh receives the errors reported by any of the partition event streams.
+	errCh chan error
 }
 
 // partitionEvent augments a normal event with the partition it came from.
@@ -142,16 +145,23 @@ func newStreamIngestionDataProcessor(
 func (sip *streamIngestionProcessor) Start(ctx context.Context) context.Context {
 	ctx = sip.StartInternal(ctx, streamIngestionProcessorName)
 
-	startTime := timeutil.Unix(0 /* sec */, sip.spec.StartTime.WallTime)
+	// An empty start time is passed on to the client as the zero time, which
+	// requests the current contents of the partitions to be emitted first.
+	var startTime time.Time
+	if !sip.spec.StartTime.IsEmpty() {
+		startTime = timeutil.Unix(0 /* sec */, sip.spec.StartTime.WallTime)
+	}
 	eventChs := make(map[streamingccl.PartitionAddress]chan streamingccl.Event)
+	errChs := make(map[streamingccl.PartitionAddress]chan error)
 	for _, partitionAddress := range sip.spec.PartitionAddresses {
-		eventCh, err := sip.client.ConsumePartition(ctx, partitionAddress, startTime)
+		eventCh, errCh, err := sip.client.ConsumePartition(ctx, partitionAddress, startTime)
 		if err != nil {
 			sip.ingestionErr = errors.Wrapf(err, "consuming partition %v", partitionAddress)
 		}
 		eventChs[partitionAddress] = eventCh
+		errChs[partitionAddress] = errCh
 	}
-	sip.eventCh = merge(ctx, eventChs)
+	sip.eventCh, sip.errCh = merge(ctx, eventChs, errChs)
 
 	return ctx
 }
@@ -222,17 +232,23 @@ func (sip *streamIngestionProcessor) flush() error {
 }
 
 // merge takes events from all the streams and merges them into a single
-// channel.
+// channel. Errors reported by any of the streams are forwarded to the returned
+// error channel before the merged event channel is closed.
 func merge(
-	ctx context.Context, partitionStreams map[streamingccl.PartitionAddress]chan streamingccl.Event,
-) chan partitionEvent {
+	ctx context.Context,
+	partitionStreams map[streamingccl.PartitionAddress]chan streamingccl.Event,
+	partitionErrs map[streamingccl.PartitionAddress]chan error,
+) (chan partitionEvent, chan error) {
 	merged := make(chan partitionEvent)
+	mergedErrs := make(chan error, len(partitionStreams))
 
 	var wg sync.WaitGroup
 	wg.Add(len(partitionStreams))
 
 	for partition, eventCh := range partitionStreams {
-		go func(partition streamingccl.PartitionAddress, eventCh <-chan streamingccl.Event) {
+		go func(
+			partition streamingccl.PartitionAddress, eventCh <-chan streamingccl.Event, errCh <-chan error,
+		) {
 			defer wg.Done()
 			for event := range eventCh {
 				pe := partitionEvent{
@@ -243,19 +259,25 @@ func merge(
 				select {
 				case merged <- pe:
 				case <-ctx.Done():
-					// TODO: Add ctx.Err() to an error channel once ConsumePartition
-					// supports an error ch.
+					// The processor is shutting down, so there is no one left to
+					// report the error to.
 					return
 				}
 			}
-		}(partition, eventCh)
+			// Clients report an error, if any, before closing the event channel.
+			select {
+			case err := <-errCh:
+				mergedErrs <- errors.Wrapf(err, "consuming partition %v", partition)
+			default:
+			}
+		}(partition, eventCh, partitionErrs[partition])
 	}
 	go func() {
 		wg.Wait()
 		close(merged)
 	}()
 
-	return merged
+	return merged, mergedErrs
 }
 
 // consumeEvents handles processing events on the merged event queue and returns
@@ -307,6 +329,11 @@ func (sip *streamIngestionProcessor) consumeEvents() (*jobspb.ResolvedSpan, erro
 		}
 	}
 
+	select {
+	case err := <-sip.errCh:
+		return nil, err
+	default:
+	}
 	return nil, nil
 }
 
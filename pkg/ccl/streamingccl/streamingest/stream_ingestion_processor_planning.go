@@ -30,6 +30,7 @@ func distStreamIngestionPlanSpecs(
 	streamAddress streamingccl.StreamAddress,
 	topology streamingccl.Topology,
 	nodes []roachpb.NodeID,
+	initialHighWater hlc.Timestamp,
 	jobID int64,
 ) ([]*execinfrapb.StreamIngestionDataSpec, *execinfrapb.StreamIngestionFrontierSpec, error) {
 
@@ -45,6 +46,7 @@ func distStreamIngestionPlanSpecs(
 			spec := &execinfrapb.StreamIngestionDataSpec{
 				StreamAddress:      streamAddress,
 				PartitionAddresses: make([]streamingccl.PartitionAddress, 0),
+				StartTime:          initialHighWater,
 			}
 			streamIngestionSpecs = append(streamIngestionSpecs, spec)
 		}
@@ -63,8 +65,10 @@ func distStreamIngestionPlanSpecs(
 
 	// Create a spec for the StreamIngestionFrontier processor on the coordinator
 	// node.
-	// TODO: set HighWaterAtStart once the job progress logic has been hooked up.
-	streamIngestionFrontierSpec := &execinfrapb.StreamIngestionFrontierSpec{TrackedSpans: trackedSpans}
+	streamIngestionFrontierSpec := &execinfrapb.StreamIngestionFrontierSpec{
+		HighWaterAtStart: initialHighWater,
+		TrackedSpans:     trackedSpans,
+	}
 
 	return streamIngestionSpecs, streamIngestionFrontierSpec, nil
 }
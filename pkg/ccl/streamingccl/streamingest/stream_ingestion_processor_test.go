@@ -47,13 +47,16 @@ import (
 // partition addresses.
 type mockStreamClient struct {
 	partitionEvents map[streamingccl.PartitionAddress][]streamingccl.Event
+	// partitionErrors holds the error reported by a partition once all of its
+	// events have been emitted.
+	partitionErrors map[streamingccl.PartitionAddress]error
 }
 
 var _ streamclient.Client = &mockStreamClient{}
 
 // GetTopology implements the StreamClient interface.
 func (m *mockStreamClient) GetTopology(
-	_ streamingccl.StreamAddress,
+	_ streamingccl.StreamAddress, _ roachpb.TenantID,
 ) (streamingccl.Topology, error) {
 	panic("unimplemented mock method")
 }
@@ -61,11 +64,11 @@ func (m *mockStreamClient) GetTopology(
 // ConsumePartition implements the StreamClient interface.
 func (m *mockStreamClient) ConsumePartition(
 	_ context.Context, address streamingccl.PartitionAddress, _ time.Time,
-) (chan streamingccl.Event, error) {
+) (chan streamingccl.Event, chan error, error) {
 	var events []streamingccl.Event
 	var ok bool
 	if events, ok = m.partitionEvents[address]; !ok {
-		return nil, errors.Newf("no events found for paritition %s", address)
+		return nil, nil, errors.Newf("no events found for paritition %s", address)
 	}
 
 	eventCh := make(chan streamingccl.Event, len(events))
@@ -73,9 +76,13 @@ func (m *mockStreamClient) ConsumePartition(
 	for _, event := range events {
 		eventCh <- event
 	}
+	errCh := make(chan error, 1)
+	if err, ok := m.partitionErrors[address]; ok {
+		errCh <- err
+	}
 	close(eventCh)
 
-	return eventCh, nil
+	return eventCh, errCh, nil
 }
 
 // Close implements the StreamClient interface.
@@ -140,6 +147,48 @@ func TestStreamIngestionProcessor(t *testing.T) {
 	require.Equal(t, expectedRows, actualRows)
 }
 
+// TestStreamIngestionProcessorPartitionError tests that an error reported by
+// the stream client while consuming a partition fails the processor.
+func TestStreamIngestionProcessorPartitionError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+
+	tc := testcluster.StartTestCluster(t, 1 /* nodes */, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+	kvDB := tc.Server(0).DB()
+
+	events := []streamingccl.Event{
+		streamingccl.MakeCheckpointEvent(hlc.Timestamp{WallTime: 1}),
+	}
+	pa1 := streamingccl.PartitionAddress("partition1")
+	pa2 := streamingccl.PartitionAddress("partition2")
+	mockClient := &mockStreamClient{
+		partitionEvents: map[streamingccl.PartitionAddress][]streamingccl.Event{pa1: events, pa2: events},
+		partitionErrors: map[streamingccl.PartitionAddress]error{pa2: errors.New("stream failed")},
+	}
+
+	startTime := hlc.Timestamp{WallTime: timeutil.Now().UnixNano()}
+	partitionAddresses := []streamingccl.PartitionAddress{pa1, pa2}
+	out, err := runStreamIngestionProcessor(ctx, t, kvDB, "some://stream", partitionAddresses,
+		startTime, nil /* interceptEvents */, mockClient)
+	require.NoError(t, err)
+
+	var ingestionErr error
+	for {
+		row, meta := out.Next()
+		if meta != nil && meta.Err != nil {
+			ingestionErr = meta.Err
+		}
+		if row == nil && meta == nil {
+			break
+		}
+	}
+	require.Error(t, ingestionErr)
+	require.Regexp(t, "consuming partition partition2: stream failed", ingestionErr.Error())
+}
+
 func getPartitionSpanToTableID(
 	t *testing.T, partitionAddresses []streamingccl.PartitionAddress,
 ) map[string]int {
@@ -261,7 +310,8 @@ func TestRandomClientGeneration(t *testing.T) {
 	// The random client returns system and table data partitions.
 	streamClient, err := streamclient.NewStreamClient(streamingccl.StreamAddress(streamAddr))
 	require.NoError(t, err)
-	topo, err := streamClient.GetTopology(streamingccl.StreamAddress(streamAddr))
+	topo, err := streamClient.GetTopology(streamingccl.StreamAddress(streamAddr),
+		roachpb.SystemTenantID)
 	require.NoError(t, err)
 	// One system and two table data partitions.
 	require.Equal(t, numPartitions, len(topo.Partitions))
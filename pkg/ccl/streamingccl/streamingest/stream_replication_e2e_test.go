@@ -0,0 +1,154 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/kvccl"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamingutils"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamproducer"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantStreamingEndToEnd replicates a tenant from a source cluster into a
+// destination cluster using the replication stream produced by the source
+// cluster, cuts over, and checks that the tenant can be started on the
+// destination cluster with the data written on the source cluster.
+func TestTenantStreamingEndToEnd(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	defer jobs.TestingSetAdoptAndCancelIntervals(100*time.Millisecond, 100*time.Millisecond)()
+
+	// Start the source cluster and write some data in tenant 10.
+	source, sourceDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer source.Stopper().Stop(ctx)
+	sourceSQL := sqlutils.MakeSQLRunner(sourceDB)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sourceSQL.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+
+	tenantID := roachpb.MakeTenantID(10)
+	_, tenantConn := serverutils.StartTenant(t, source, base.TestTenantArgs{TenantID: tenantID})
+	defer tenantConn.Close()
+	tenantSQL := sqlutils.MakeSQLRunner(tenantConn)
+	tenantSQL.Exec(t, `CREATE DATABASE d`)
+	tenantSQL.Exec(t, `CREATE TABLE d.t1 (i INT PRIMARY KEY, s STRING)`)
+	tenantSQL.Exec(t, `INSERT INTO d.t1 SELECT i, i::STRING FROM generate_series(1, 100) AS g(i)`)
+
+	// Start the destination cluster and begin ingesting the stream of tenant 10.
+	// Some keys are written in the keyspace of the tenant before the ingestion
+	// starts, and are overwritten right before the cutover, after the cutover
+	// time. Each of them has a version to revert to, so the cutover has to
+	// revert them one by one, in more than a single revert batch.
+	const revertBatchSize = 10
+	revertedKeys := make([]roachpb.Key, 5*revertBatchSize)
+	for i := range revertedKeys {
+		revertedKeys[i] = append(keys.MakeTenantPrefix(tenantID), fmt.Sprintf("reverted-%03d", i)...)
+	}
+	writeRevertedKeys := func(ctx context.Context, db *kv.DB, value string) error {
+		var b kv.Batch
+		for _, k := range revertedKeys {
+			b.Put(k, value)
+		}
+		return db.Run(ctx, &b)
+	}
+	var wroteAfterCutover int32
+	ingestionKnobs := &sql.StreamIngestionTestingKnobs{RevertBatchSize: revertBatchSize}
+	dest, destDB, destKV := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			DistSQL: &execinfra.TestingKnobs{StreamIngestionTestingKnobs: ingestionKnobs},
+		},
+	})
+	defer dest.Stopper().Stop(ctx)
+	require.NoError(t, writeRevertedKeys(ctx, destKV, "before-cutover"))
+	ingestionKnobs.BeforeCutover = func(ctx context.Context, _ hlc.Timestamp) error {
+		if err := writeRevertedKeys(ctx, destKV, "after-cutover"); err != nil {
+			return err
+		}
+		atomic.StoreInt32(&wroteAfterCutover, 1)
+		return nil
+	}
+	destSQL := sqlutils.MakeSQLRunner(destDB)
+	destSQL.Exec(t, `SET CLUSTER SETTING bulkio.stream_ingestion.cutover_signal_poll_interval = '100ms'`)
+
+	pgURL, cleanupSinkCert := sqlutils.PGUrl(t, source.ServingSQLAddr(), t.Name(),
+		url.User(security.RootUser))
+	defer cleanupSinkCert()
+	destSQL.Exec(t, `RESTORE TENANT 10 FROM REPLICATION STREAM FROM $1`, pgURL.String())
+	var jobID int64
+	destSQL.QueryRow(t,
+		`SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'STREAM INGESTION'`).Scan(&jobID)
+
+	// Write more data once the ingestion has started, which should be streamed
+	// by the rangefeeds on the source cluster.
+	tenantSQL.Exec(t, `INSERT INTO d.t1 SELECT i, i::STRING FROM generate_series(101, 200) AS g(i)`)
+	tenantSQL.Exec(t, `UPDATE d.t1 SET s = 'updated' WHERE i % 10 = 0`)
+	tenantSQL.Exec(t, `DELETE FROM d.t1 WHERE i % 7 = 0`)
+	var lastWrite string
+	tenantSQL.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&lastWrite)
+
+	// Wait for the ingestion to catch up with the last write on the source
+	// cluster, and then signal the job to cut over.
+	testutils.SucceedsSoon(t, func() error {
+		var caughtUp bool
+		destSQL.QueryRow(t, `SELECT coalesce(high_water_timestamp >= $1::DECIMAL, false)
+FROM crdb_internal.jobs WHERE job_id = $2`, lastWrite, jobID).Scan(&caughtUp)
+		if !caughtUp {
+			return errors.Newf("job %d has not ingested the writes up to %s", jobID, lastWrite)
+		}
+		return nil
+	})
+	destSQL.Exec(t, `SELECT crdb_internal.complete_stream_ingestion_job($1)`, jobID)
+	destSQL.CheckQueryResultsRetry(t,
+		fmt.Sprintf(`SELECT status FROM [SHOW JOBS] WHERE job_id = %d`, jobID),
+		[][]string{{"succeeded"}})
+	destSQL.CheckQueryResults(t,
+		`SELECT id, active, crdb_internal.pb_to_json('cockroach.sql.sqlbase.TenantInfo', info) FROM system.tenants`,
+		[][]string{{`10`, `true`, `{"id": "10", "state": "ACTIVE"}`}},
+	)
+	require.Equal(t, int32(1), atomic.LoadInt32(&wroteAfterCutover))
+	for _, k := range revertedKeys {
+		v, err := destKV.Get(ctx, k)
+		require.NoError(t, err)
+		require.Equal(t, "before-cutover", string(v.ValueBytes()))
+	}
+
+	// Start the tenant on the destination cluster and compare its data with the
+	// source cluster.
+	log.TestingClearServerIdentifiers()
+	_, destTenantConn := serverutils.StartTenant(t, dest, base.TestTenantArgs{
+		TenantID: tenantID, Existing: true,
+	})
+	defer destTenantConn.Close()
+	destTenantSQL := sqlutils.MakeSQLRunner(destTenantConn)
+	destTenantSQL.CheckQueryResults(t, `SELECT * FROM d.t1 ORDER BY i`,
+		tenantSQL.QueryStr(t, `SELECT * FROM d.t1 AS OF SYSTEM TIME `+lastWrite+` ORDER BY i`))
+}
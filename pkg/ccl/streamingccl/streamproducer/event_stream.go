@@ -0,0 +1,223 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamproducer
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/streaming"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/errors"
+)
+
+// eventStream is a tree.ValueGenerator which emits the KV and checkpoint events
+// of a partition of a tenant's keyspace. If no start time is specified, the
+// stream begins with a scan of the partition at the current time. It then
+// follows the changes to the partition using a rangefeed.
+//
+// The stream only terminates when its context is canceled or when the
+// underlying rangefeed fails.
+type eventStream struct {
+	db        *kv.DB
+	ds        *kvcoord.DistSender
+	span      roachpb.Span
+	startTime hlc.Timestamp
+
+	// eventCh receives the events produced by the initial scan and the
+	// rangefeed.
+	eventCh chan *roachpb.RangeFeedEvent
+	// doneCh is closed once the goroutine producing events has exited, after
+	// which err holds the error it returned.
+	doneCh chan struct{}
+	err    error
+	cancel context.CancelFunc
+
+	// frontier tracks the resolved timestamp of every span in the partition.
+	// A checkpoint event is only emitted when the frontier advances.
+	frontier *span.Frontier
+	// data is the row returned by Values.
+	data tree.Datums
+}
+
+var _ tree.ValueGenerator = &eventStream{}
+
+// eventChannelSize is the size of the buffer between the rangefeed and the
+// consumer of the stream. The rangefeed needs to be drained quickly to avoid
+// blocking raft, so we allow some slack here.
+const eventChannelSize = 128
+
+// scanTargetBytes is the number of bytes requested per scan during the initial
+// scan of a partition.
+const scanTargetBytes = 16 << 20 // 16 MiB
+
+func newEventStream(
+	evalCtx *tree.EvalContext, tenantID roachpb.TenantID, sp roachpb.Span, startTime hlc.Timestamp,
+) (tree.ValueGenerator, error) {
+	if err := checkReplicationStreamAllowed(evalCtx, tenantID); err != nil {
+		return nil, err
+	}
+	if !sp.Valid() || !tenantSpan(tenantID).Contains(sp) {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"partition %s is not within the keyspace of tenant %d", sp, tenantID.ToUint64())
+	}
+	ds, err := distSender(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &eventStream{
+		db:        evalCtx.DB,
+		ds:        ds,
+		span:      sp,
+		startTime: startTime,
+	}, nil
+}
+
+// ResolvedType implements the tree.ValueGenerator interface.
+func (s *eventStream) ResolvedType() *types.T {
+	return streaming.StreamPartitionGeneratorType
+}
+
+// Start implements the tree.ValueGenerator interface.
+func (s *eventStream) Start(ctx context.Context, _ *kv.Txn) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.eventCh = make(chan *roachpb.RangeFeedEvent, eventChannelSize)
+	s.doneCh = make(chan struct{})
+	s.frontier = span.MakeFrontier(s.span)
+	s.frontier.Forward(s.span, s.startTime)
+
+	go func() {
+		defer close(s.doneCh)
+		s.err = s.produceEvents(ctx)
+	}()
+	return nil
+}
+
+// produceEvents scans the partition if needed, and then runs a rangefeed over
+// the partition until the context is canceled.
+func (s *eventStream) produceEvents(ctx context.Context) error {
+	startTime := s.startTime
+	if startTime.IsEmpty() {
+		startTime = s.db.Clock().Now()
+		if err := s.scanPartition(ctx, startTime); err != nil {
+			return err
+		}
+	}
+	return s.ds.RangeFeed(ctx, s.span, startTime, false /* withDiff */, s.eventCh)
+}
+
+// scanPartition emits the latest version of every key in the partition as of
+// the provided timestamp, followed by a checkpoint at that timestamp.
+func (s *eventStream) scanPartition(ctx context.Context, ts hlc.Timestamp) error {
+	log.VEventf(ctx, 2, "scanning partition %s at %s", s.span, ts)
+	txn := s.db.NewTxn(ctx, "replication stream initial scan")
+	txn.SetFixedTimestamp(ctx, ts)
+	for remaining := s.span; ; {
+		b := txn.NewBatch()
+		r := roachpb.NewScan(remaining.Key, remaining.EndKey, false /* forUpdate */).(*roachpb.ScanRequest)
+		r.ScanFormat = roachpb.BATCH_RESPONSE
+		b.Header.TargetBytes = scanTargetBytes
+		// NB: We use a raw request rather than the Scan() method because we want
+		// the MVCC timestamps which are encoded in the response but are filtered
+		// during result parsing.
+		b.AddRawRequest(r)
+		if err := txn.Run(ctx, b); err != nil {
+			return errors.Wrapf(err, "scanning %s", remaining)
+		}
+		res := b.RawResponse().Responses[0].GetScan()
+		for _, br := range res.BatchResponses {
+			for len(br) > 0 {
+				var kv roachpb.KeyValue
+				var err error
+				kv.Key, kv.Value.Timestamp, kv.Value.RawBytes, br, err = enginepb.ScanDecodeKeyValue(br)
+				if err != nil {
+					return errors.Wrapf(err, "decoding scan of %s", remaining)
+				}
+				var ev roachpb.RangeFeedEvent
+				ev.MustSetValue(&roachpb.RangeFeedValue{Key: kv.Key, Value: kv.Value})
+				if err := s.sendEvent(ctx, &ev); err != nil {
+					return err
+				}
+			}
+		}
+		if res.ResumeSpan == nil {
+			break
+		}
+		remaining = *res.ResumeSpan
+	}
+	var ev roachpb.RangeFeedEvent
+	ev.MustSetValue(&roachpb.RangeFeedCheckpoint{Span: s.span, ResolvedTS: ts})
+	return s.sendEvent(ctx, &ev)
+}
+
+func (s *eventStream) sendEvent(ctx context.Context, ev *roachpb.RangeFeedEvent) error {
+	select {
+	case s.eventCh <- ev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Next implements the tree.ValueGenerator interface.
+func (s *eventStream) Next(ctx context.Context) (bool, error) {
+	for {
+		select {
+		case ev := <-s.eventCh:
+			switch t := ev.GetValue().(type) {
+			case *roachpb.RangeFeedValue:
+				s.data = tree.Datums{
+					tree.NewDBytes(tree.DBytes(t.Key)),
+					tree.NewDBytes(tree.DBytes(t.Value.RawBytes)),
+					tree.TimestampToDecimalDatum(t.Value.Timestamp),
+				}
+				return true, nil
+			case *roachpb.RangeFeedCheckpoint:
+				if !s.frontier.Forward(t.Span, t.ResolvedTS) {
+					continue
+				}
+				s.data = tree.Datums{
+					tree.DNull,
+					tree.DNull,
+					tree.TimestampToDecimalDatum(s.frontier.Frontier()),
+				}
+				return true, nil
+			default:
+				return false, errors.AssertionFailedf("unexpected rangefeed event %v", t)
+			}
+		case <-s.doneCh:
+			return false, s.err
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// Values implements the tree.ValueGenerator interface.
+func (s *eventStream) Values() (tree.Datums, error) {
+	return s.data, nil
+}
+
+// Close implements the tree.ValueGenerator interface.
+func (s *eventStream) Close() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.doneCh
+}
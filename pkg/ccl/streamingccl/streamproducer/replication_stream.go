@@ -0,0 +1,152 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamproducer
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/streaming"
+	"github.com/cockroachdb/errors"
+)
+
+func init() {
+	streaming.StreamTopologyHook = newStreamTopologyGenerator
+	streaming.StreamPartitionHook = newEventStream
+}
+
+// checkReplicationStreamAllowed returns an error if the current session may
+// not produce a replication stream of the given tenant's keyspace.
+func checkReplicationStreamAllowed(evalCtx *tree.EvalContext, tenantID roachpb.TenantID) error {
+	if !evalCtx.Codec.ForSystemTenant() {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only the system tenant can stream the keyspace of other tenants")
+	}
+	if tenantID == roachpb.SystemTenantID {
+		return pgerror.New(pgcode.InvalidParameterValue,
+			"cannot stream the keyspace of the system tenant")
+	}
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Context)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"user needs the admin role to produce a replication stream")
+	}
+	if !kvserver.RangefeedEnabled.Get(&evalCtx.Settings.SV) {
+		return errors.New("replication streams require the kv.rangefeed.enabled setting")
+	}
+	return utilccl.CheckEnterpriseEnabled(
+		evalCtx.Settings, evalCtx.ClusterID, sql.ClusterOrganization.Get(&evalCtx.Settings.SV),
+		"REPLICATION STREAM",
+	)
+}
+
+// tenantSpan returns the span of the tenant's keyspace.
+func tenantSpan(tenantID roachpb.TenantID) roachpb.Span {
+	prefix := keys.MakeTenantPrefix(tenantID)
+	return roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}
+}
+
+// distSender returns the DistSender of the node evaluating the stream.
+func distSender(evalCtx *tree.EvalContext) (*kvcoord.DistSender, error) {
+	p, ok := evalCtx.Planner.(sql.PlanHookState)
+	if !ok {
+		return nil, errors.AssertionFailedf(
+			"replication streams cannot be evaluated with planner %T", evalCtx.Planner)
+	}
+	return p.ExecCfg().DistSender, nil
+}
+
+// streamTopologyGenerator is a tree.ValueGenerator over the partitions of a
+// replication stream. A partition is created for every range overlapping the
+// tenant's keyspace at the time the topology is resolved.
+type streamTopologyGenerator struct {
+	ds   *kvcoord.DistSender
+	span roachpb.Span
+
+	partitions []roachpb.Span
+	idx        int
+}
+
+var _ tree.ValueGenerator = &streamTopologyGenerator{}
+
+func newStreamTopologyGenerator(
+	evalCtx *tree.EvalContext, tenantID roachpb.TenantID,
+) (tree.ValueGenerator, error) {
+	if err := checkReplicationStreamAllowed(evalCtx, tenantID); err != nil {
+		return nil, err
+	}
+	ds, err := distSender(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &streamTopologyGenerator{
+		ds:   ds,
+		span: tenantSpan(tenantID),
+	}, nil
+}
+
+// ResolvedType implements the tree.ValueGenerator interface.
+func (s *streamTopologyGenerator) ResolvedType() *types.T {
+	return streaming.StreamTopologyGeneratorType
+}
+
+// Start implements the tree.ValueGenerator interface.
+func (s *streamTopologyGenerator) Start(ctx context.Context, _ *kv.Txn) error {
+	rs, err := keys.SpanAddr(s.span)
+	if err != nil {
+		return err
+	}
+	s.partitions = s.partitions[:0]
+	s.idx = -1
+	it := kvcoord.NewRangeIterator(s.ds)
+	for it.Seek(ctx, rs.Key, kvcoord.Ascending); ; it.Next(ctx) {
+		if !it.Valid() {
+			return it.Error()
+		}
+		partition, err := rs.Intersect(it.Desc())
+		if err != nil {
+			return err
+		}
+		s.partitions = append(s.partitions, partition.AsRawSpanWithNoLocals())
+		if !it.NeedAnother(rs) {
+			return nil
+		}
+	}
+}
+
+// Next implements the tree.ValueGenerator interface.
+func (s *streamTopologyGenerator) Next(_ context.Context) (bool, error) {
+	s.idx++
+	return s.idx < len(s.partitions), nil
+}
+
+// Values implements the tree.ValueGenerator interface.
+func (s *streamTopologyGenerator) Values() (tree.Datums, error) {
+	partition := s.partitions[s.idx]
+	return tree.Datums{
+		tree.NewDBytes(tree.DBytes(partition.Key)),
+		tree.NewDBytes(tree.DBytes(partition.EndKey)),
+	}, nil
+}
+
+// Close implements the tree.ValueGenerator interface.
+func (s *streamTopologyGenerator) Close() {}
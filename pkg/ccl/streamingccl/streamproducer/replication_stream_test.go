@@ -0,0 +1,159 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamproducer
+
+import (
+	"context"
+	gosql "database/sql"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/kvccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationStreamValidation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	ten10 := keys.MakeTenantPrefix(roachpb.MakeTenantID(10))
+	ten11 := keys.MakeTenantPrefix(roachpb.MakeTenantID(11))
+
+	sqlDB.ExpectErr(t, "replication streams require the kv.rangefeed.enabled setting",
+		`SELECT * FROM crdb_internal.stream_topology(10)`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+
+	sqlDB.ExpectErr(t, "cannot stream the keyspace of the system tenant",
+		`SELECT * FROM crdb_internal.stream_topology(1)`)
+	sqlDB.ExpectErr(t, "tenant ID must be positive",
+		`SELECT * FROM crdb_internal.stream_topology(0)`)
+	sqlDB.ExpectErr(t, "is not within the keyspace of tenant 10",
+		`SELECT * FROM crdb_internal.stream_partition(10, $1, $2, 0)`,
+		[]byte(ten10), []byte(ten11.PrefixEnd()))
+	sqlDB.ExpectErr(t, "is not within the keyspace of tenant 10",
+		`SELECT * FROM crdb_internal.stream_partition(10, $1, $2, 0)`,
+		[]byte(ten10.PrefixEnd()), []byte(ten10))
+
+	sqlDB.Exec(t, `CREATE USER testuser`)
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.TestUser))
+	defer cleanup()
+	testUserDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer testUserDB.Close()
+	sqlutils.MakeSQLRunner(testUserDB).ExpectErr(t,
+		"user needs the admin role to produce a replication stream",
+		`SELECT * FROM crdb_internal.stream_topology(10)`)
+}
+
+func TestReplicationStreamSinklessClient(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+
+	tenantID := roachpb.MakeTenantID(10)
+	_, tenantConn := serverutils.StartTenant(t, s, base.TestTenantArgs{TenantID: tenantID})
+	defer tenantConn.Close()
+	tenantSQL := sqlutils.MakeSQLRunner(tenantConn)
+	tenantSQL.Exec(t, `CREATE TABLE t (k STRING PRIMARY KEY)`)
+	tenantSQL.Exec(t, `INSERT INTO t VALUES ('scanned')`)
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanup()
+	streamAddr := streamingccl.StreamAddress(pgURL.String())
+	client, err := streamclient.NewStreamClient(streamAddr)
+	require.NoError(t, err)
+
+	// The partitions of the stream cover the keyspace of the tenant.
+	topology, err := client.GetTopology(streamAddr, tenantID)
+	require.NoError(t, err)
+	require.NotEmpty(t, topology.Partitions)
+	var partitionSpans roachpb.Spans
+	for _, partition := range topology.Partitions {
+		partitionURL, err := partition.URL()
+		require.NoError(t, err)
+		startKey, err := hex.DecodeString(partitionURL.Query().Get(streamclient.PartitionStartKeyParam))
+		require.NoError(t, err)
+		endKey, err := hex.DecodeString(partitionURL.Query().Get(streamclient.PartitionEndKeyParam))
+		require.NoError(t, err)
+		partitionSpans = append(partitionSpans, roachpb.Span{Key: startKey, EndKey: endKey})
+	}
+	require.Equal(t, tenantSpan(tenantID), roachpb.Span{
+		Key:    partitionSpans[0].Key,
+		EndKey: partitionSpans[len(partitionSpans)-1].EndKey,
+	})
+
+	// Consume all the partitions until both the row written before the stream
+	// started and the row written after it are observed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan streamingccl.Event)
+	for _, partition := range topology.Partitions {
+		eventCh, errCh, err := client.ConsumePartition(ctx, partition, time.Time{})
+		require.NoError(t, err)
+		go func() {
+			for event := range eventCh {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case err := <-errCh:
+				t.Error(err)
+			default:
+			}
+		}()
+	}
+	tenantSQL.Exec(t, `INSERT INTO t VALUES ('streamed')`)
+
+	var sawCheckpoint bool
+	seen := make(map[string]bool)
+	for !(sawCheckpoint && seen["scanned"] && seen["streamed"]) {
+		event := <-events
+		switch event.Type() {
+		case streamingccl.KVEvent:
+			// The rows of the table are encoded in the key of its primary index, so
+			// we check whether the key of the event contains one of the rows.
+			key := string(event.GetKV().Key)
+			for _, row := range []string{"scanned", "streamed"} {
+				if strings.Contains(key, row) {
+					seen[row] = true
+				}
+			}
+		case streamingccl.CheckpointEvent:
+			require.False(t, event.GetResolved().IsEmpty())
+			sawCheckpoint = true
+		}
+	}
+}
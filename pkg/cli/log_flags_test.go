@@ -40,6 +40,19 @@ func TestSetupLogging(t *testing.T) {
 		`redactable: true, ` +
 		`exit-on-error: false` +
 		`}`
+	const defaultHTTPConfig = `http-defaults: {` +
+		`method: POST, ` +
+		`timeout: 2s, ` +
+		`unsafe-tls: false, ` +
+		`disable-keep-alives: false, ` +
+		`flush-trigger-size: 256KiB, ` +
+		`max-staleness: 1s, ` +
+		`max-buffer-size: 10MiB, ` +
+		`filter: INFO, ` +
+		`format: json-compact, ` +
+		`redactable: true, ` +
+		`exit-on-error: false` +
+		`}`
 	stdFileDefaultsRe := regexp.MustCompile(
 		`file-defaults: \{dir: (?P<path>[^,]+), max-file-size: 10MiB, buffered-writes: true, filter: INFO, format: crdb-v2, redactable: true\}`)
 	fileDefaultsNoMaxSizeRe := regexp.MustCompile(
@@ -106,6 +119,7 @@ func TestSetupLogging(t *testing.T) {
 
 		// Shorten the configuration for legibility during reviews of test changes.
 		actual = strings.ReplaceAll(actual, defaultFluentConfig, "<fluentDefaults>")
+		actual = strings.ReplaceAll(actual, defaultHTTPConfig, "<httpDefaults>")
 		actual = stdFileDefaultsRe.ReplaceAllString(actual, "<stdFileDefaults($path)>")
 		actual = fileDefaultsNoMaxSizeRe.ReplaceAllString(actual, "<fileDefaultsNoMaxSize($path)>")
 		actual = strings.ReplaceAll(actual, fileDefaultsNoDir, "<fileDefaultsNoDir>")
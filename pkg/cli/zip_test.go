@@ -79,6 +79,7 @@ table_name NOT IN (
 	'predefined_comments',
 	'session_trace',
 	'session_variables',
+	'statement_diagnostics',
 	'tables'
 )
 ORDER BY name ASC`)
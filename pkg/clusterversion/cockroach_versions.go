@@ -237,6 +237,29 @@ const (
 	PostTruncatedAndRangeAppliedStateMigration
 	// SeparatedIntents allows the writing of separated intents/locks.
 	SeparatedIntents
+	// UserDefinedFunctions allows the creation of function descriptors for
+	// SQL-language user-defined functions.
+	UserDefinedFunctions
+	// ListenNotify adds the system.notifications table, which is used to
+	// deliver the notifications generated by NOTIFY to listening sessions.
+	ListenNotify
+	// SCRAMAuthentication enables the storage of SCRAM-SHA-256 password
+	// verifiers in system.users, as configured by the
+	// server.user_login.password_encryption setting.
+	SCRAMAuthentication
+	// ConditionalStatementDiagnostics adds the columns to
+	// system.statement_diagnostics_requests and system.statement_diagnostics
+	// which allow statement diagnostics to be sampled, conditioned on the
+	// execution latency, and collected continuously until an expiration time.
+	ConditionalStatementDiagnostics
+	// TSearchTypes enables the use of the TSVECTOR and TSQUERY types as column
+	// types.
+	TSearchTypes
+	// ReadCommittedIsolation allows transactions to run under the READ
+	// COMMITTED isolation level, once sql.txn.read_committed_isolation.enabled
+	// is also set. Nodes running older binaries don't know about the isolation
+	// level of a transaction and would treat it as SERIALIZABLE.
+	ReadCommittedIsolation
 
 	// Step (1): Add new versions here.
 )
@@ -395,6 +418,30 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     SeparatedIntents,
 		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 26},
 	},
+	{
+		Key:     UserDefinedFunctions,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 28},
+	},
+	{
+		Key:     ListenNotify,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 30},
+	},
+	{
+		Key:     SCRAMAuthentication,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 32},
+	},
+	{
+		Key:     ConditionalStatementDiagnostics,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 34},
+	},
+	{
+		Key:     TSearchTypes,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 36},
+	},
+	{
+		Key:     ReadCommittedIsolation,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 38},
+	},
 	// Step (2): Add new versions here.
 })
 
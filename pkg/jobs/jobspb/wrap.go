@@ -31,6 +31,8 @@ var _ Details = SchemaChangeGCDetails{}
 var _ Details = StreamIngestionDetails{}
 var _ Details = NewSchemaChangeDetails{}
 var _ Details = MigrationDetails{}
+var _ Details = RowLevelTTLDetails{}
+var _ Details = ExportDetails{}
 
 // ProgressDetails is a marker interface for job progress details proto structs.
 type ProgressDetails interface{}
@@ -44,6 +46,8 @@ var _ ProgressDetails = SchemaChangeGCProgress{}
 var _ ProgressDetails = StreamIngestionProgress{}
 var _ ProgressDetails = NewSchemaChangeProgress{}
 var _ ProgressDetails = MigrationProgress{}
+var _ ProgressDetails = RowLevelTTLProgress{}
+var _ ProgressDetails = ExportProgress{}
 
 // Type returns the payload's job type.
 func (p *Payload) Type() Type {
@@ -79,6 +83,10 @@ func DetailsType(d isPayload_Details) Type {
 		return TypeNewSchemaChange
 	case *Payload_Migration:
 		return TypeMigration
+	case *Payload_RowLevelTTL:
+		return TypeRowLevelTTL
+	case *Payload_Export:
+		return TypeExport
 	default:
 		panic(errors.AssertionFailedf("Payload.Type called on a payload with an unknown details type: %T", d))
 	}
@@ -115,6 +123,10 @@ func WrapProgressDetails(details ProgressDetails) interface {
 		return &Progress_NewSchemaChange{NewSchemaChange: &d}
 	case MigrationProgress:
 		return &Progress_Migration{Migration: &d}
+	case RowLevelTTLProgress:
+		return &Progress_RowLevelTTL{RowLevelTTL: &d}
+	case ExportProgress:
+		return &Progress_Export{Export: &d}
 	default:
 		panic(errors.AssertionFailedf("WrapProgressDetails: unknown details type %T", d))
 	}
@@ -146,6 +158,10 @@ func (p *Payload) UnwrapDetails() Details {
 		return *d.NewSchemaChange
 	case *Payload_Migration:
 		return *d.Migration
+	case *Payload_RowLevelTTL:
+		return *d.RowLevelTTL
+	case *Payload_Export:
+		return *d.Export
 	default:
 		return nil
 	}
@@ -177,6 +193,10 @@ func (p *Progress) UnwrapDetails() ProgressDetails {
 		return *d.NewSchemaChange
 	case *Progress_Migration:
 		return *d.Migration
+	case *Progress_RowLevelTTL:
+		return *d.RowLevelTTL
+	case *Progress_Export:
+		return *d.Export
 	default:
 		return nil
 	}
@@ -221,6 +241,10 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_NewSchemaChange{NewSchemaChange: &d}
 	case MigrationDetails:
 		return &Payload_Migration{Migration: &d}
+	case RowLevelTTLDetails:
+		return &Payload_RowLevelTTL{RowLevelTTL: &d}
+	case ExportDetails:
+		return &Payload_Export{Export: &d}
 	default:
 		panic(errors.AssertionFailedf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -256,7 +280,7 @@ const (
 func (Type) SafeValue() {}
 
 // NumJobTypes is the number of jobs types.
-const NumJobTypes = 13
+const NumJobTypes = 15
 
 func init() {
 	if len(Type_name) != NumJobTypes {
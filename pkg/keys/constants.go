@@ -400,6 +400,7 @@ const (
 	TenantsRangesID                     = 38 // pseudo
 	SqllivenessID                       = 39
 	MigrationsID                        = 40
+	NotificationsTableID                = 41
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
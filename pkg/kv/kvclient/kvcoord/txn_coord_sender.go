@@ -637,9 +637,37 @@ func (tc *TxnCoordSender) UpdateStateOnRemoteRetryableErr(
 ) *roachpb.Error {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
+	if stmtErr := tc.maybeHandleStatementRetryableErrLocked(ctx, pErr); stmtErr != nil {
+		return stmtErr
+	}
 	return roachpb.NewError(tc.handleRetryableErrLocked(ctx, pErr))
 }
 
+// maybeHandleStatementRetryableErrLocked handles a retryable error encountered
+// by a transaction whose isolation level tolerates write skew. Rather than
+// restarting the whole transaction, the transaction's read timestamp is moved
+// forward to one at which the failed request can succeed and the error is
+// turned into a statement retry error (see kv.NewStatementRetryError). The
+// client is expected to roll back the current statement to a savepoint and
+// to evaluate it again at the new read timestamp.
+//
+// Returns nil if the error can't be handled this way, in which case the
+// caller needs to fall back to restarting the transaction.
+func (tc *TxnCoordSender) maybeHandleStatementRetryableErrLocked(
+	ctx context.Context, pErr *roachpb.Error,
+) *roachpb.Error {
+	if !tc.mu.txn.IsolationLevel.ToleratesWriteSkew() {
+		return nil
+	}
+	ok, newTxn := roachpb.CanTransactionRefresh(ctx, pErr)
+	if !ok {
+		return nil
+	}
+	log.VEventf(ctx, 2, "retrying statement at %s after: %s", newTxn.ReadTimestamp, pErr)
+	tc.mu.txn.Update(newTxn)
+	return roachpb.NewError(kv.NewStatementRetryError(pErr.GoError()))
+}
+
 // handleRetryableErrLocked takes a retriable error and creates a
 // TransactionRetryWithProtoRefreshError containing the transaction that needs
 // to be used by the next attempt. It also handles various aspects of updating
@@ -773,6 +801,13 @@ func (tc *TxnCoordSender) updateStateLocked(
 			log.Fatalf(ctx, "retryable error for the wrong txn. ba.Txn: %s. pErr: %s",
 				ba.Txn, pErr)
 		}
+		// A batch that commits the transaction has no statement left to retry,
+		// so only other batches are eligible for statement retries.
+		if _, hasET := ba.GetArg(roachpb.EndTxn); !hasET {
+			if stmtErr := tc.maybeHandleStatementRetryableErrLocked(ctx, pErr); stmtErr != nil {
+				return stmtErr
+			}
+		}
 		return roachpb.NewError(tc.handleRetryableErrLocked(ctx, pErr))
 	}
 
@@ -865,6 +900,24 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsolationLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsolationLevel(level enginepb.IsolationLevel) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.active && level != tc.mu.txn.IsolationLevel {
+		return errors.New("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.IsolationLevel = level
+	return nil
+}
+
+// IsolationLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) IsolationLevel() enginepb.IsolationLevel {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.mu.txn.IsolationLevel
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -961,6 +1014,10 @@ func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	if tc.mu.txn.IsolationLevel.ToleratesWriteSkew() {
+		// The transaction can commit at its pushed timestamp.
+		return false
+	}
 	isTxnPushed := tc.mu.txn.WriteTimestamp != tc.mu.txn.ReadTimestamp
 	refreshAttemptNotPossible := tc.interceptorAlloc.txnSpanRefresher.refreshInvalid ||
 		tc.mu.txn.CommitTimestampFixed
@@ -1126,6 +1183,41 @@ func (tc *TxnCoordSender) Step(ctx context.Context) error {
 	return tc.interceptorAlloc.txnSeqNumAllocator.stepLocked(ctx)
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (tc *TxnCoordSender) StepReadTimestamp(ctx context.Context) error {
+	if tc.typ != kv.RootTxn {
+		return errors.AssertionFailedf("cannot step the read timestamp of a non-root txn")
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if err := tc.assertNotFinalized(); err != nil {
+		return err
+	}
+	txn := &tc.mu.txn
+	if !txn.IsolationLevel.ToleratesWriteSkew() || txn.CommitTimestampFixed ||
+		tc.mu.txnState != txnPending {
+		return nil
+	}
+
+	now := tc.clock.Now()
+	txn.Refresh(now)
+	// The new snapshot comes with a new uncertainty interval. The observed
+	// timestamps collected so far predate the snapshot, so they can no longer
+	// be used to shrink it.
+	txn.GlobalUncertaintyLimit.Forward(now.Add(tc.clock.MaxOffset().Nanoseconds(), 0))
+	txn.ResetObservedTimestamps()
+	return nil
+}
+
+// SetReadSeqNum is part of the TxnSender interface.
+func (tc *TxnCoordSender) SetReadSeqNum(seq enginepb.TxnSeq) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.interceptorAlloc.txnSeqNumAllocator.setReadSeqLocked(seq)
+}
+
 // ConfigureStepping is part of the TxnSender interface.
 func (tc *TxnCoordSender) ConfigureStepping(
 	ctx context.Context, mode kv.SteppingMode,
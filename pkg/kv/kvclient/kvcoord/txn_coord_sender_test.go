@@ -96,6 +96,58 @@ func TestTxnCoordSenderBeginTransaction(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderReadCommitted verifies that READ COMMITTED transactions
+// read at a new timestamp after each call to StepReadTimestamp, and that
+// write-write conflicts are turned into statement retry errors instead of
+// requiring the transaction to restart.
+func TestTxnCoordSenderReadCommitted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	s := createTestDB(t)
+	defer s.Stop()
+	ctx := context.Background()
+
+	txn := kv.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */)
+	require.NoError(t, txn.SetIsolationLevel(enginepb.READ_COMMITTED))
+	require.Equal(t, enginepb.READ_COMMITTED, txn.IsolationLevel())
+
+	// Anchor the transaction.
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	require.NoError(t, txn.Put(ctx, keyA, "a"))
+	require.Error(t, txn.SetIsolationLevel(enginepb.SERIALIZABLE))
+
+	// A write committed after the transaction's read timestamp is invisible
+	// until the read timestamp is stepped.
+	s.Manual.Increment(100)
+	require.NoError(t, s.DB.Put(ctx, keyB, "b1"))
+	res, err := txn.Get(ctx, keyB)
+	require.NoError(t, err)
+	require.False(t, res.Exists())
+
+	origReadTS := txn.ReadTimestamp()
+	s.Manual.Increment(100)
+	require.NoError(t, txn.StepReadTimestamp(ctx))
+	require.True(t, origReadTS.Less(txn.ReadTimestamp()))
+	res, err = txn.Get(ctx, keyB)
+	require.NoError(t, err)
+	require.Equal(t, []byte("b1"), res.ValueBytes())
+
+	// Writing below a committed value results in a statement retry error. The
+	// transaction moves its read timestamp above the conflicting value and
+	// remains usable.
+	s.Manual.Increment(100)
+	require.NoError(t, s.DB.Put(ctx, keyB, "b2"))
+	err = txn.Put(ctx, keyB, "b3")
+	require.True(t, kv.IsStatementRetryError(err), "unexpected error: %v", err)
+	require.Equal(t, roachpb.PENDING, txn.TestingCloneTxn().Status)
+	require.NoError(t, txn.Put(ctx, keyB, "b3"))
+	require.NoError(t, txn.Commit(ctx))
+
+	res, err = s.DB.Get(ctx, keyB)
+	require.NoError(t, err)
+	require.Equal(t, []byte("b3"), res.ValueBytes())
+}
+
 // TestTxnCoordSenderKeyRanges verifies that multiple requests to same or
 // overlapping key ranges causes the coordinator to keep track only of
 // the minimum number of ranges.
@@ -334,6 +334,16 @@ func (tc *txnCommitter) canCommitInParallel(
 		return false
 	}
 
+	// If the transaction tolerates write skew, we don't allow it to commit in
+	// parallel with writes. Conflicting readers push its intents without
+	// waiting, which would routinely violate the implicit commit condition and
+	// force the commit to be retried after a refresh that the transaction
+	// doesn't perform. An explicit commit can never be rejected because of a
+	// timestamp push.
+	if ba.Txn.IsolationLevel.ToleratesWriteSkew() {
+		return false
+	}
+
 	// If the transaction has a commit trigger, we don't allow it to commit in
 	// parallel with writes. There's no fundamental reason for this restriction,
 	// but for now it's not worth the complication.
@@ -144,6 +144,20 @@ func (s *txnSeqNumAllocator) stepLocked(ctx context.Context) error {
 	return nil
 }
 
+// setReadSeqLocked rewinds the read seqnum to a sequencing point
+// established by an earlier call to stepLocked.
+func (s *txnSeqNumAllocator) setReadSeqLocked(seq enginepb.TxnSeq) error {
+	if !s.steppingModeEnabled {
+		return errors.AssertionFailedf("stepping mode is not enabled")
+	}
+	if seq < 0 || seq > s.writeSeq {
+		return errors.AssertionFailedf(
+			"cannot set read seqnum to %d (write seqnum %d)", seq, s.writeSeq)
+	}
+	s.readSeq = seq
+	return nil
+}
+
 // configureSteppingLocked configures the stepping mode.
 //
 // When enabling stepping from the non-enabled state, the read seqnum
@@ -139,6 +139,15 @@ type txnSpanRefresher struct {
 func (sr *txnSpanRefresher) SendLocked(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (*roachpb.BatchResponse, *roachpb.Error) {
+	// Transactions that tolerate write skew never refresh their reads. They
+	// can commit above their read timestamp, and conflicts that would require
+	// a refresh are instead handled by retrying the statement that ran into
+	// them at a newer read timestamp (see maybeHandleStatementRetryableErrLocked).
+	// There is consequently no need to track refresh spans either.
+	if ba.Txn.IsolationLevel.ToleratesWriteSkew() {
+		return sr.wrapped.SendLocked(ctx, ba)
+	}
+
 	batchReadTimestamp := ba.Txn.ReadTimestamp
 	if sr.refreshedTimestamp.IsEmpty() {
 		// This must be the first batch we're sending for this epoch. Future
@@ -392,7 +392,10 @@ func IsEndTxnTriggeringRetryError(
 	// update anomalies.
 	if txn.WriteTooOld {
 		retry, reason = true, roachpb.RETRY_WRITE_TOO_OLD
-	} else {
+	} else if !txn.IsolationLevel.ToleratesWriteSkew() {
+		// Transactions that tolerate write skew are free to commit above
+		// their read timestamp. All others must have refreshed their reads up
+		// to their commit timestamp before getting here.
 		readTimestamp := txn.ReadTimestamp
 		isTxnPushed := txn.WriteTimestamp != readTimestamp
 
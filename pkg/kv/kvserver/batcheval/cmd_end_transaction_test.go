@@ -1063,3 +1063,45 @@ func TestPartialRollbackOnEndTransaction(t *testing.T) {
 		}
 	})
 }
+
+// TestIsEndTxnTriggeringRetryError verifies which transactions need to be
+// retried instead of committing at their current write timestamp.
+func TestIsEndTxnTriggeringRetryError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts, ts2 := hlc.Timestamp{WallTime: 1}, hlc.Timestamp{WallTime: 2}
+	for _, tc := range []struct {
+		name        string
+		isoLevel    enginepb.IsolationLevel
+		pushed      bool
+		writeTooOld bool
+		expRetry    bool
+		expReason   roachpb.TransactionRetryReason
+	}{
+		{name: "serializable", isoLevel: enginepb.SERIALIZABLE},
+		{name: "serializable pushed", isoLevel: enginepb.SERIALIZABLE, pushed: true,
+			expRetry: true, expReason: roachpb.RETRY_SERIALIZABLE},
+		{name: "serializable write too old", isoLevel: enginepb.SERIALIZABLE, writeTooOld: true,
+			expRetry: true, expReason: roachpb.RETRY_WRITE_TOO_OLD},
+		{name: "read committed", isoLevel: enginepb.READ_COMMITTED},
+		{name: "read committed pushed", isoLevel: enginepb.READ_COMMITTED, pushed: true},
+		{name: "read committed write too old", isoLevel: enginepb.READ_COMMITTED, writeTooOld: true,
+			expRetry: true, expReason: roachpb.RETRY_WRITE_TOO_OLD},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			txn := roachpb.MakeTransaction("test", roachpb.Key("a"), 0, ts, 0)
+			txn.IsolationLevel = tc.isoLevel
+			if tc.pushed {
+				txn.WriteTimestamp.Forward(ts2)
+			}
+			txn.WriteTooOld = tc.writeTooOld
+
+			retry, reason, _ := IsEndTxnTriggeringRetryError(&txn, &roachpb.EndTxnRequest{Commit: true})
+			require.Equal(t, tc.expRetry, retry)
+			if tc.expRetry {
+				require.Equal(t, tc.expReason, reason)
+			}
+		})
+	}
+}
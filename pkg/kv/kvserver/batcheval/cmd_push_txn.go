@@ -82,6 +82,9 @@ func declareKeysPushTransaction(
 //
 // Txn record not expired: If the pushee txn is not expired, its
 // priority is compared against the pusher's (see CanPushWithPriority).
+// The exception is a PUSH_TIMESTAMP of a pushee whose isolation level
+// tolerates write skew, which always succeeds because the pushee can
+// commit at the pushed timestamp without refreshing its reads.
 //
 // Push cannot proceed: a TransactionPushError is returned.
 //
@@ -228,6 +231,9 @@ func PushTxn(
 		// If just attempting to cleanup old or already-committed txns,
 		// pusher always fails.
 		pusherWins = false
+	case pushType == roachpb.PUSH_TIMESTAMP && reply.PusheeTxn.IsolationLevel.ToleratesWriteSkew():
+		reason = "pushee tolerates write skew"
+		pusherWins = true
 	case CanPushWithPriority(&args.PusherTxn, &reply.PusheeTxn):
 		reason = "pusher has priority"
 		pusherWins = true
@@ -239,6 +239,15 @@ func (w *lockTableWaiterImpl) WaitOn(
 					delay = 0
 				}
 
+				// Similarly, if a reader is blocked on a lock held by a transaction
+				// that tolerates write skew, push immediately. The push is
+				// guaranteed to succeed without waiting on the lock holder, which
+				// can commit at the pushed timestamp.
+				if state.held && state.guardAccess == spanset.SpanReadOnly &&
+					state.txn.IsolationLevel.ToleratesWriteSkew() {
+					delay = 0
+				}
+
 				if delay > 0 {
 					if timer == nil {
 						timer = timeutil.NewTimer()
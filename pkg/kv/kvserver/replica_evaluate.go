@@ -356,6 +356,14 @@ func evaluateBatch(
 					writeTooOldState.cantDeferWTOE = true
 				}
 
+				// Transactions that tolerate write skew handle write-write conflicts
+				// by re-evaluating the statement that ran into them at a newer read
+				// timestamp. Deferring the error would let them commit over the
+				// newer value without that re-evaluation, losing the update.
+				if baHeader.Txn != nil && baHeader.Txn.IsolationLevel.ToleratesWriteSkew() {
+					writeTooOldState.cantDeferWTOE = true
+				}
+
 				if baHeader.Txn != nil {
 					log.VEventf(ctx, 2, "setting WriteTooOld because of key: %s. wts: %s -> %s",
 						args.Header().Key, baHeader.Txn.WriteTimestamp, wtoErr.ActualTimestamp)
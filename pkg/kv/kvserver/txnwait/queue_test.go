@@ -115,6 +115,29 @@ func TestShouldPushImmediately(t *testing.T) {
 			}
 		})
 	}
+
+	// The timestamp of a transaction that tolerates write skew can always be
+	// pushed immediately, but aborting it still depends on priorities.
+	for _, typ := range []roachpb.PushTxnType{roachpb.PUSH_ABORT, roachpb.PUSH_TIMESTAMP} {
+		t.Run(typ.String(), func(t *testing.T) {
+			req := roachpb.PushTxnRequest{
+				PushType: typ,
+				PusherTxn: roachpb.Transaction{
+					TxnMeta: enginepb.TxnMeta{
+						Priority: mid,
+					},
+				},
+				PusheeTxn: enginepb.TxnMeta{
+					Priority:       mid,
+					IsolationLevel: enginepb.READ_COMMITTED,
+				},
+			}
+			expected := typ == roachpb.PUSH_TIMESTAMP
+			if shouldPush := ShouldPushImmediately(&req); shouldPush != expected {
+				t.Errorf("expected %t; got %t", expected, shouldPush)
+			}
+		})
+	}
 }
 
 func makeTS(w int64, l int32) hlc.Timestamp {
@@ -82,6 +82,17 @@ func (m *MockTransactionalSender) SetUserPriority(pri roachpb.UserPriority) erro
 	return nil
 }
 
+// SetIsolationLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetIsolationLevel(level enginepb.IsolationLevel) error {
+	m.txn.IsolationLevel = level
+	return nil
+}
+
+// IsolationLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) IsolationLevel() enginepb.IsolationLevel {
+	return m.txn.IsolationLevel
+}
+
 // SetDebugName is part of the TxnSender interface.
 func (m *MockTransactionalSender) SetDebugName(name string) {
 	m.txn.Name = name
@@ -187,6 +198,16 @@ func (m *MockTransactionalSender) Step(_ context.Context) error {
 	return nil
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (m *MockTransactionalSender) StepReadTimestamp(context.Context) error {
+	return nil
+}
+
+// SetReadSeqNum is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetReadSeqNum(enginepb.TxnSeq) error {
+	return nil
+}
+
 // ConfigureStepping is part of the TxnSender interface.
 func (m *MockTransactionalSender) ConfigureStepping(context.Context, SteppingMode) SteppingMode {
 	// See Step() above.
@@ -126,6 +126,13 @@ type TxnSender interface {
 	// SetUserPriority sets the txn's priority.
 	SetUserPriority(roachpb.UserPriority) error
 
+	// SetIsolationLevel sets the txn's isolation level. The isolation level
+	// cannot be changed once the transaction has sent a request.
+	SetIsolationLevel(enginepb.IsolationLevel) error
+
+	// IsolationLevel returns the txn's isolation level.
+	IsolationLevel() enginepb.IsolationLevel
+
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
@@ -276,6 +283,27 @@ type TxnSender interface {
 	// The method is idempotent.
 	Step(context.Context) error
 
+	// StepReadTimestamp establishes a new read snapshot for a transaction
+	// whose isolation level tolerates write skew: the read timestamp is moved
+	// forward to the current time, so that subsequent reads observe all the
+	// writes committed before the call. Used by the SQL layer to give each
+	// statement of a READ COMMITTED transaction its own snapshot.
+	//
+	// The method is a no-op for transactions at other isolation levels and
+	// for transactions with a fixed commit timestamp.
+	StepReadTimestamp(context.Context) error
+
+	// SetReadSeqNum sets the read sequence number of the transaction,
+	// rewinding the snapshot baseline for subsequent read-only
+	// operations to a sequencing point established earlier. This is
+	// used by SQL cursors, which must observe the data as of the
+	// statement that declared them.
+	//
+	// The sequence number must not exceed the current write sequence
+	// number. SetReadSeqNum() can only be called after stepping mode
+	// has been enabled.
+	SetReadSeqNum(seq enginepb.TxnSeq) error
+
 	// ConfigureStepping sets the sequencing point behavior.
 	//
 	// Note that a Sender is initially in the non-stepping mode,
@@ -224,6 +224,25 @@ func (txn *Txn) SetUserPriority(userPriority roachpb.UserPriority) error {
 	return txn.mu.sender.SetUserPriority(userPriority)
 }
 
+// SetIsolationLevel sets the transaction's isolation level. It needs to be
+// called before the transaction sends its first request.
+func (txn *Txn) SetIsolationLevel(level enginepb.IsolationLevel) error {
+	if txn.typ != RootTxn {
+		return errors.AssertionFailedf("SetIsolationLevel() called on leaf txn")
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SetIsolationLevel(level)
+}
+
+// IsolationLevel returns the transaction's isolation level.
+func (txn *Txn) IsolationLevel() enginepb.IsolationLevel {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.IsolationLevel()
+}
+
 // TestingSetPriority sets the transaction priority. It is intended for
 // internal (testing) use only.
 func (txn *Txn) TestingSetPriority(priority enginepb.TxnPriority) {
@@ -1066,7 +1085,11 @@ func (txn *Txn) UpdateStateOnRemoteRetryableErr(ctx context.Context, pErr *roach
 	}
 
 	pErr = txn.mu.sender.UpdateStateOnRemoteRetryableErr(ctx, pErr)
-	txn.replaceRootSenderIfTxnAbortedLocked(ctx, pErr.GetDetail().(*roachpb.TransactionRetryWithProtoRefreshError), origTxnID)
+	// Transactions that tolerate write skew may be asked to retry only the
+	// current statement, in which case there's no new transaction to switch to.
+	if retryErr, ok := pErr.GetDetail().(*roachpb.TransactionRetryWithProtoRefreshError); ok {
+		txn.replaceRootSenderIfTxnAbortedLocked(ctx, retryErr, origTxnID)
+	}
 
 	return pErr.GoError()
 }
@@ -1258,6 +1281,51 @@ func (txn *Txn) Step(ctx context.Context) error {
 	return txn.mu.sender.Step(ctx)
 }
 
+// StepReadTimestamp moves the read snapshot of a transaction whose isolation
+// level tolerates write skew to the current time. It is a no-op for other
+// transactions. See TxnSender.StepReadTimestamp.
+func (txn *Txn) StepReadTimestamp(ctx context.Context) error {
+	if txn.typ != RootTxn {
+		return errors.WithContextTags(
+			errors.AssertionFailedf("StepReadTimestamp() called on leaf txn"), ctx)
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.StepReadTimestamp(ctx)
+}
+
+// errStatementRetry marks the errors returned to transactions whose isolation
+// level tolerates write skew when a statement ran into a conflict that
+// requires it to be evaluated again, at a newer read timestamp.
+var errStatementRetry = errors.New("statement retry")
+
+// NewStatementRetryError wraps the retryable error encountered by a
+// transaction whose isolation level tolerates write skew into an error
+// instructing the client to roll back the effects of its current statement
+// and to retry that statement. The transaction itself remains usable and its
+// read timestamp has already been moved forward. The cause is retained for
+// its message only: the returned error is not a retryable KV error.
+func NewStatementRetryError(cause error) error {
+	return errors.Mark(
+		errors.Wrap(errors.Handled(cause), "statement needs to be retried"), errStatementRetry)
+}
+
+// IsStatementRetryError returns whether err was created by
+// NewStatementRetryError.
+func IsStatementRetryError(err error) bool {
+	return errors.Is(err, errStatementRetry)
+}
+
+// SetReadSeqNum rewinds the snapshot observed by subsequent reads to
+// the given sequencing point, which must have been established by an
+// earlier Step. Step-wise execution must be already enabled.
+func (txn *Txn) SetReadSeqNum(seq enginepb.TxnSeq) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SetReadSeqNum(seq)
+}
+
 // ConfigureStepping configures step-wise execution in the
 // transaction.
 func (txn *Txn) ConfigureStepping(ctx context.Context, mode SteppingMode) (prevMode SteppingMode) {
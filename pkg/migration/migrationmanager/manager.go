@@ -250,9 +250,10 @@ func (m *Manager) runMigration(
 	// TODO(ajwerner): Remove in 21.2.
 	if version.Version == clusterversion.ByKey(clusterversion.LongRunningMigrations) {
 		return mig.(*migration.SQLMigration).Run(ctx, version, migration.SQLDeps{
-			DB:       m.c.DB(),
-			Codec:    m.codec,
-			Settings: m.settings,
+			DB:               m.c.DB(),
+			Codec:            m.codec,
+			Settings:         m.settings,
+			InternalExecutor: m.ie,
 		})
 	}
 	alreadyCompleted, id, err := m.getOrCreateMigrationJob(ctx, user, version)
@@ -47,6 +47,16 @@ var migrations = []migration.Migration{
 		toCV(clusterversion.PostTruncatedAndRangeAppliedStateMigration),
 		postTruncatedStateMigration,
 	),
+	migration.NewSQLMigration(
+		"add the system.notifications table",
+		toCV(clusterversion.ListenNotify),
+		notificationsTableMigration,
+	),
+	migration.NewSQLMigration(
+		"add the columns for conditional statement diagnostics requests",
+		toCV(clusterversion.ConditionalStatementDiagnostics),
+		conditionalStatementDiagnosticsMigration,
+	),
 }
 
 func init() {
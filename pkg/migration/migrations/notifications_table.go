@@ -0,0 +1,28 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
+)
+
+func notificationsTableMigration(
+	ctx context.Context, _ clusterversion.ClusterVersion, d migration.SQLDeps,
+) error {
+	return sqlmigrations.CreateSystemTable(
+		ctx, d.DB, d.Codec, d.Settings, systemschema.NotificationsTable,
+	)
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+const addStatementDiagnosticsRequestsColumns = `
+ALTER TABLE system.statement_diagnostics_requests
+ADD COLUMN IF NOT EXISTS min_execution_latency INTERVAL NULL FAMILY "primary",
+ADD COLUMN IF NOT EXISTS sampling_probability FLOAT8 NULL FAMILY "primary",
+ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ NULL FAMILY "primary",
+ADD COLUMN IF NOT EXISTS continuous BOOL NULL FAMILY "primary"
+`
+
+const addStatementDiagnosticsColumns = `
+ALTER TABLE system.statement_diagnostics
+ADD COLUMN IF NOT EXISTS request_id INT8 NULL FAMILY "primary",
+ADD COLUMN IF NOT EXISTS execution_latency INTERVAL NULL FAMILY "primary"
+`
+
+// conditionalStatementDiagnosticsMigration adds the columns which describe
+// the conditions of the statement diagnostics requests, and the columns which
+// link the collected diagnostics to their requests.
+func conditionalStatementDiagnosticsMigration(
+	ctx context.Context, _ clusterversion.ClusterVersion, d migration.SQLDeps,
+) error {
+	asNode := sessiondata.InternalExecutorOverride{User: security.NodeUserName()}
+	for _, op := range []struct {
+		name, stmt string
+	}{
+		{"alter-stmt-diag-requests", addStatementDiagnosticsRequestsColumns},
+		{"alter-stmt-diag", addStatementDiagnosticsColumns},
+	} {
+		if _, err := d.InternalExecutor.ExecEx(ctx, op.name, nil /* txn */, asNode, op.stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
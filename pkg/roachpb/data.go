@@ -1076,6 +1076,9 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(t.Key) == 0 {
 		t.Key = o.Key
 	}
+	// The isolation level is fixed before the transaction sends its first
+	// request, so every copy of the transaction agrees on it.
+	t.IsolationLevel = o.IsolationLevel
 
 	// Update epoch-scoped state, depending on the two transactions' epochs.
 	if t.Epoch < o.Epoch {
@@ -1402,6 +1405,8 @@ func PrepareTransactionForRetry(
 		)
 		// Use the priority communicated back by the server.
 		txn.Priority = errTxnPri
+		// The new transaction runs at the same isolation level as the old one.
+		txn.IsolationLevel = pErr.GetTxn().IsolationLevel
 	case *ReadWithinUncertaintyIntervalError:
 		txn.WriteTimestamp.Forward(
 			readWithinUncertaintyIntervalRetryTimestamp(ctx, &txn, tErr, pErr.OriginNode))
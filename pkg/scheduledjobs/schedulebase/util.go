@@ -0,0 +1,212 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package schedulebase contains the helpers shared by the CREATE SCHEDULE
+// statements, which parse the schedule options and recurrence and create the
+// scheduled jobs, and by the jobs started by the schedules.
+package schedulebase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/gorhill/cronexpr"
+)
+
+// The schedule options supported by all the CREATE SCHEDULE statements.
+const (
+	OptFirstRun          = "first_run"
+	OptOnExecFailure     = "on_execution_failure"
+	OptOnPreviousRunning = "on_previous_running"
+)
+
+// ScheduleOptionExpectValues is used to parse the schedule options supported by
+// all the CREATE SCHEDULE statements using PlanHookState.TypeAsStringOpts().
+var ScheduleOptionExpectValues = map[string]sql.KVStringOptValidate{
+	OptFirstRun:          sql.KVStringOptRequireValue,
+	OptOnExecFailure:     sql.KVStringOptRequireValue,
+	OptOnPreviousRunning: sql.KVStringOptRequireValue,
+}
+
+func parseOnError(onError string, details *jobspb.ScheduleDetails) error {
+	switch strings.ToLower(onError) {
+	case "retry":
+		details.OnError = jobspb.ScheduleDetails_RETRY_SOON
+	case "reschedule":
+		details.OnError = jobspb.ScheduleDetails_RETRY_SCHED
+	case "pause":
+		details.OnError = jobspb.ScheduleDetails_PAUSE_SCHED
+	default:
+		return errors.Newf(
+			"%q is not a valid on_execution_error; valid values are [retry|reschedule|pause]",
+			onError)
+	}
+	return nil
+}
+
+func parseWaitBehavior(wait string, details *jobspb.ScheduleDetails) error {
+	switch strings.ToLower(wait) {
+	case "start":
+		details.Wait = jobspb.ScheduleDetails_NO_WAIT
+	case "skip":
+		details.Wait = jobspb.ScheduleDetails_SKIP
+	case "wait":
+		details.Wait = jobspb.ScheduleDetails_WAIT
+	default:
+		return errors.Newf(
+			"%q is not a valid on_previous_running; valid values are [start|skip|wait]",
+			wait)
+	}
+	return nil
+}
+
+// MakeScheduleDetails returns the schedule details corresponding to the
+// on_execution_failure and on_previous_running schedule options.
+func MakeScheduleDetails(opts map[string]string) (jobspb.ScheduleDetails, error) {
+	var details jobspb.ScheduleDetails
+	if v, ok := opts[OptOnExecFailure]; ok {
+		if err := parseOnError(v, &details); err != nil {
+			return details, err
+		}
+	}
+
+	if v, ok := opts[OptOnPreviousRunning]; ok {
+		if err := parseWaitBehavior(v, &details); err != nil {
+			return details, err
+		}
+	}
+	return details, nil
+}
+
+// ScheduleFirstRun returns the time of the first run requested with the
+// first_run schedule option, or nil if the option is not set.
+func ScheduleFirstRun(evalCtx *tree.EvalContext, opts map[string]string) (*time.Time, error) {
+	if v, ok := opts[OptFirstRun]; ok {
+		firstRun, _, err := tree.ParseDTimestampTZ(evalCtx, v, time.Microsecond)
+		if err != nil {
+			return nil, err
+		}
+		return &firstRun.Time, nil
+	}
+	return nil, nil
+}
+
+// ScheduleRecurrence is the evaluated RECURRING clause of a schedule.
+type ScheduleRecurrence struct {
+	Cron      string
+	Frequency time.Duration
+}
+
+// NeverRecurs is a sentinel value indicating the schedule never recurs.
+var NeverRecurs *ScheduleRecurrence
+
+// ComputeScheduleRecurrence evaluates the cron expression returned by evalFn.
+// It returns NeverRecurs if evalFn is nil.
+func ComputeScheduleRecurrence(
+	now time.Time, evalFn func() (string, error),
+) (*ScheduleRecurrence, error) {
+	if evalFn == nil {
+		return NeverRecurs, nil
+	}
+	cron, err := evalFn()
+	if err != nil {
+		return nil, err
+	}
+	expr, err := cronexpr.Parse(cron)
+	if err != nil {
+		return nil, errors.Newf(
+			`error parsing schedule expression: %q; it must be a valid cron expression`,
+			cron)
+	}
+	nextRun := expr.Next(now)
+	frequency := expr.Next(nextRun).Sub(nextRun)
+	return &ScheduleRecurrence{cron, frequency}, nil
+}
+
+// JobSchedulerEnv returns the JobSchedulerEnv to use when creating schedules,
+// which may be overridden by the testing knobs.
+func JobSchedulerEnv(execCfg *sql.ExecutorConfig) scheduledjobs.JobSchedulerEnv {
+	if knobs, ok := execCfg.DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
+		if knobs.JobSchedulerEnv != nil {
+			return knobs.JobSchedulerEnv
+		}
+	}
+	return scheduledjobs.ProdJobSchedulerEnv
+}
+
+// ScheduleStatusAndNextRun returns the status of a newly created schedule and
+// the time of its next run, as returned by the CREATE SCHEDULE statements. The
+// next run is NULL if the schedule is paused.
+func ScheduleStatusAndNextRun(sj *jobs.ScheduledJob) (string, tree.Datum, error) {
+	if sj.IsPaused() {
+		status := "PAUSED"
+		if s := sj.ScheduleStatus(); s != "" {
+			status += ": " + s
+		}
+		return status, tree.DNull, nil
+	}
+	nextRun, err := tree.MakeDTimestampTZ(sj.NextRun(), time.Microsecond)
+	if err != nil {
+		return "", nil, err
+	}
+	return "ACTIVE", nextRun, nil
+}
+
+// MaybeNotifyScheduledJobCompletion notifies the schedule which created the
+// job, if any, that the job terminated with the given status.
+func MaybeNotifyScheduledJobCompletion(
+	ctx context.Context, job *jobs.Job, jobStatus jobs.Status, exec *sql.ExecutorConfig,
+) {
+	env := JobSchedulerEnv(exec)
+
+	if err := exec.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		// Do not rely on job containing created_by_id. Query it directly.
+		datums, err := exec.InternalExecutor.QueryRowEx(
+			ctx,
+			"lookup-schedule-info",
+			txn,
+			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+			fmt.Sprintf(
+				"SELECT created_by_id FROM %s WHERE id=$1 AND created_by_type=$2",
+				env.SystemJobsTableName()),
+			*job.ID(), jobs.CreatedByScheduledJobs)
+		if err != nil {
+			return errors.Wrap(err, "schedule info lookup")
+		}
+		if datums == nil {
+			// Not a scheduled job.
+			return nil
+		}
+
+		scheduleID := int64(tree.MustBeDInt(datums[0]))
+		if err := jobs.NotifyJobTermination(
+			ctx, env, *job.ID(), jobStatus, job.Details(), scheduleID, exec.InternalExecutor, txn,
+		); err != nil {
+			log.Warningf(ctx,
+				"failed to notify schedule %d of completion of job %d; err=%s",
+				scheduleID, *job.ID(), err)
+		}
+		return nil
+	}); err != nil {
+		log.Errorf(ctx, "maybeNotifyScheduledJobCompletion error: %v", err)
+	}
+}
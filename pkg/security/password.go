@@ -11,6 +11,7 @@
 package security
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"os"
@@ -53,16 +54,106 @@ func appendEmptySha256(password string) []byte {
 	return append([]byte(password), sha256NewSum...)
 }
 
+// PasswordHashMethod is the algorithm used to store a password in
+// system.users.
+type PasswordHashMethod int8
+
+const (
+	// HashInvalidMethod is used for stored hashes that are not recognized.
+	HashInvalidMethod PasswordHashMethod = iota
+	// HashMissingPassword is used for users without a password.
+	HashMissingPassword
+	// HashBCrypt is the bcrypt hash of the password, computed after
+	// appendEmptySha256.
+	HashBCrypt
+	// HashSCRAMSHA256 is a SCRAM-SHA-256 verifier; see ScramVerifier.
+	HashSCRAMSHA256
+)
+
+func (m PasswordHashMethod) String() string {
+	switch m {
+	case HashMissingPassword:
+		return "<missing password>"
+	case HashBCrypt:
+		return "crdb-bcrypt"
+	case HashSCRAMSHA256:
+		return "scram-sha-256"
+	default:
+		return "<invalid method>"
+	}
+}
+
+// GetPasswordHashMethod returns the method that was used to produce the
+// given stored password hash.
+func GetPasswordHashMethod(hashedPassword []byte) PasswordHashMethod {
+	switch {
+	case len(hashedPassword) == 0:
+		return HashMissingPassword
+	case bytes.HasPrefix(hashedPassword, []byte(scramPrefix)):
+		return HashSCRAMSHA256
+	case bytes.HasPrefix(hashedPassword, []byte("$2")):
+		// All the bcrypt variants start with $2.
+		return HashBCrypt
+	default:
+		return HashInvalidMethod
+	}
+}
+
+// PasswordHashMethodSetting is the cluster setting that configures the
+// method used to store the passwords set via SQL.
+var PasswordHashMethodSetting = settings.RegisterEnumSetting(
+	"server.user_login.password_encryption",
+	"which hash method to use to store the passwords set via SQL. "+
+		"Passwords stored with a different method are converted upon the next successful "+
+		"cleartext password authentication",
+	HashBCrypt.String(),
+	map[int64]string{
+		int64(HashBCrypt):      HashBCrypt.String(),
+		int64(HashSCRAMSHA256): HashSCRAMSHA256.String(),
+	}).WithPublic()
+
+// GetConfiguredPasswordHashMethod returns the method configured by
+// server.user_login.password_encryption.
+func GetConfiguredPasswordHashMethod(sv *settings.Values) PasswordHashMethod {
+	return PasswordHashMethod(PasswordHashMethodSetting.Get(sv))
+}
+
 // CompareHashAndPassword tests that the provided bytes are equivalent to the
 // hash of the supplied password. If they are not equivalent, returns an
-// error.
+// error. The hash can use any of the supported methods.
 func CompareHashAndPassword(hashedPassword []byte, password string) error {
-	return bcrypt.CompareHashAndPassword(hashedPassword, appendEmptySha256(password))
+	switch GetPasswordHashMethod(hashedPassword) {
+	case HashBCrypt:
+		return bcrypt.CompareHashAndPassword(hashedPassword, appendEmptySha256(password))
+	case HashSCRAMSHA256:
+		v, err := ParseScramVerifier(hashedPassword)
+		if err != nil {
+			return err
+		}
+		if !v.matchesPassword(password) {
+			return errMismatchedPassword
+		}
+		return nil
+	case HashMissingPassword:
+		return errMismatchedPassword
+	default:
+		return errors.New("unknown password hash method")
+	}
 }
 
-// HashPassword takes a raw password and returns a bcrypt hashed password.
-func HashPassword(password string) ([]byte, error) {
-	return bcrypt.GenerateFromPassword(appendEmptySha256(password), BcryptCost)
+var errMismatchedPassword = errors.New("hashedPassword is not the hash of the given password")
+
+// HashPassword takes a raw password and returns its hash using the given
+// method.
+func HashPassword(method PasswordHashMethod, password string) ([]byte, error) {
+	switch method {
+	case HashBCrypt:
+		return bcrypt.GenerateFromPassword(appendEmptySha256(password), BcryptCost)
+	case HashSCRAMSHA256:
+		return hashPasswordScram(password)
+	default:
+		return nil, errors.AssertionFailedf("unsupported password hash method: %s", method)
+	}
 }
 
 // PromptForPassword prompts for a password.
@@ -0,0 +1,174 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ScramCost is the number of PBKDF2 iterations used to derive the SCRAM
+// keys of new passwords. It is exposed for testing.
+//
+// 4096 is the minimum recommended by RFC 7677, and the default used by
+// PostgreSQL.
+var ScramCost = 4096
+
+// scramSaltLength is the length of the random salt of SCRAM verifiers,
+// like in PostgreSQL.
+const scramSaltLength = 16
+
+// scramPrefix starts the textual representation of SCRAM-SHA-256 verifiers.
+const scramPrefix = "SCRAM-SHA-256$"
+
+// ScramVerifier is the information stored by the server to authenticate a
+// password using SCRAM-SHA-256 (RFC 5802, RFC 7677). The password cannot be
+// recovered from it.
+//
+// It is stored in system.users using the same textual representation as
+// PostgreSQL:
+//
+//   SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+//
+// where the salt and keys are base64-encoded.
+type ScramVerifier struct {
+	Iterations int
+	Salt       []byte
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// MakeScramVerifier derives the SCRAM-SHA-256 verifier of a password, using
+// the given salt and number of iterations.
+//
+// The password is used as-is: unlike PostgreSQL, no SASLprep normalization
+// is applied to it.
+func MakeScramVerifier(password string, salt []byte, iterations int) ScramVerifier {
+	saltedPassword := pbkdf2.Key([]byte(password), salt, iterations, sha256.Size, sha256.New)
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	return ScramVerifier{
+		Iterations: iterations,
+		Salt:       salt,
+		StoredKey:  storedKey[:],
+		ServerKey:  scramHMAC(saltedPassword, []byte("Server Key")),
+	}
+}
+
+// MakeMockScramVerifier returns a verifier that does not match any password.
+// It is used to go through the SCRAM exchange when a user has no SCRAM
+// verifier, so that the client gets a regular authentication failure at the
+// end of the exchange, like in PostgreSQL.
+func MakeMockScramVerifier() (ScramVerifier, error) {
+	salt := make([]byte, scramSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return ScramVerifier{}, err
+	}
+	return ScramVerifier{Iterations: ScramCost, Salt: salt}, nil
+}
+
+// ParseScramVerifier parses the textual representation of a SCRAM-SHA-256
+// verifier, as stored in system.users.
+func ParseScramVerifier(hashedPassword []byte) (ScramVerifier, error) {
+	var v ScramVerifier
+	if !bytes.HasPrefix(hashedPassword, []byte(scramPrefix)) {
+		return v, errors.New("not a SCRAM-SHA-256 verifier")
+	}
+	parts := bytes.Split(hashedPassword[len(scramPrefix):], []byte("$"))
+	if len(parts) != 2 {
+		return v, errors.New("invalid SCRAM-SHA-256 verifier")
+	}
+	iterSalt := bytes.Split(parts[0], []byte(":"))
+	keys := bytes.Split(parts[1], []byte(":"))
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return v, errors.New("invalid SCRAM-SHA-256 verifier")
+	}
+	var err error
+	if v.Iterations, err = strconv.Atoi(string(iterSalt[0])); err != nil || v.Iterations <= 0 {
+		return v, errors.New("invalid iteration count in SCRAM-SHA-256 verifier")
+	}
+	for _, f := range []struct {
+		dst *[]byte
+		src []byte
+	}{
+		{&v.Salt, iterSalt[1]},
+		{&v.StoredKey, keys[0]},
+		{&v.ServerKey, keys[1]},
+	} {
+		if *f.dst, err = base64.StdEncoding.DecodeString(string(f.src)); err != nil {
+			return v, errors.Wrap(err, "invalid SCRAM-SHA-256 verifier")
+		}
+	}
+	if len(v.StoredKey) != sha256.Size || len(v.ServerKey) != sha256.Size {
+		return v, errors.New("invalid key length in SCRAM-SHA-256 verifier")
+	}
+	return v, nil
+}
+
+// Encode returns the textual representation of the verifier.
+func (v ScramVerifier) Encode() []byte {
+	enc := base64.StdEncoding.EncodeToString
+	return []byte(scramPrefix + strconv.Itoa(v.Iterations) + ":" + enc(v.Salt) +
+		"$" + enc(v.StoredKey) + ":" + enc(v.ServerKey))
+}
+
+// VerifyClientProof checks the proof sent by the client in its final
+// message, for the given SCRAM authentication message.
+func (v ScramVerifier) VerifyClientProof(authMessage, clientProof []byte) bool {
+	if len(v.StoredKey) != sha256.Size || len(clientProof) != sha256.Size {
+		return false
+	}
+	clientSignature := scramHMAC(v.StoredKey, authMessage)
+	clientKey := make([]byte, sha256.Size)
+	for i := range clientKey {
+		clientKey[i] = clientProof[i] ^ clientSignature[i]
+	}
+	storedKey := sha256.Sum256(clientKey)
+	return subtle.ConstantTimeCompare(storedKey[:], v.StoredKey) == 1
+}
+
+// ServerSignature computes the signature sent to the client in the final
+// server message, which proves that the server knows the verifier.
+func (v ScramVerifier) ServerSignature(authMessage []byte) []byte {
+	return scramHMAC(v.ServerKey, authMessage)
+}
+
+// matchesPassword returns whether the verifier was derived from the given
+// password.
+func (v ScramVerifier) matchesPassword(password string) bool {
+	expected := MakeScramVerifier(password, v.Salt, v.Iterations)
+	return subtle.ConstantTimeCompare(expected.StoredKey, v.StoredKey) == 1 &&
+		subtle.ConstantTimeCompare(expected.ServerKey, v.ServerKey) == 1
+}
+
+// hashPasswordScram returns the textual representation of the SCRAM-SHA-256
+// verifier of a password, using a random salt.
+func hashPasswordScram(password string) ([]byte, error) {
+	salt := make([]byte, scramSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return MakeScramVerifier(password, salt, ScramCost).Encode(), nil
+}
+
+func scramHMAC(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(msg)
+	return h.Sum(nil)
+}
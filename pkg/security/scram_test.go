@@ -0,0 +1,85 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package security_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScramVerifier checks the SCRAM computations against the example
+// exchange of RFC 7677, section 3.
+func TestScramVerifier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	require.NoError(t, err)
+	v := security.MakeScramVerifier("pencil", salt, 4096)
+
+	const nonce = "rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+	authMessage := []byte("n=user,r=rOprNGfwEbeRWgbNEkqO," +
+		"r=" + nonce + ",s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096," +
+		"c=biws,r=" + nonce)
+	proof, err := base64.StdEncoding.DecodeString("dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=")
+	require.NoError(t, err)
+	require.True(t, v.VerifyClientProof(authMessage, proof))
+	require.Equal(t, "6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=",
+		base64.StdEncoding.EncodeToString(v.ServerSignature(authMessage)))
+
+	proof[0] ^= 1
+	require.False(t, v.VerifyClientProof(authMessage, proof))
+
+	// The textual representation round-trips.
+	parsed, err := security.ParseScramVerifier(v.Encode())
+	require.NoError(t, err)
+	require.Equal(t, v, parsed)
+
+	for _, invalid := range []string{
+		"",
+		"$2a$10$abc",
+		"SCRAM-SHA-256$4096:c2FsdA==",
+		"SCRAM-SHA-256$x:c2FsdA==$a2V5:a2V5",
+		"SCRAM-SHA-256$4096:c2FsdA==$a2V5:a2V5",
+	} {
+		_, err := security.ParseScramVerifier([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPasswordHashMethods(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(bcryptCost, scramCost int) {
+		security.BcryptCost, security.ScramCost = bcryptCost, scramCost
+	}(security.BcryptCost, security.ScramCost)
+	security.BcryptCost = 4
+	security.ScramCost = 16
+
+	for _, method := range []security.PasswordHashMethod{
+		security.HashBCrypt, security.HashSCRAMSHA256,
+	} {
+		t.Run(method.String(), func(t *testing.T) {
+			hash, err := security.HashPassword(method, "hunter2")
+			require.NoError(t, err)
+			require.Equal(t, method, security.GetPasswordHashMethod(hash))
+			require.NoError(t, security.CompareHashAndPassword(hash, "hunter2"))
+			require.Error(t, security.CompareHashAndPassword(hash, "hunter3"))
+		})
+	}
+
+	require.Equal(t, security.HashMissingPassword, security.GetPasswordHashMethod(nil))
+	require.Error(t, security.CompareHashAndPassword(nil, ""))
+	require.Equal(t, security.HashInvalidMethod, security.GetPasswordHashMethod([]byte("md5abc")))
+}
@@ -931,13 +931,26 @@ func (s *adminServer) Events(
 	if err != nil {
 		return nil, err
 	}
-	redactEvents := !req.UnredactedEvents
 
 	limit := req.Limit
 	if limit == 0 {
 		limit = defaultAPIEventLimit
 	}
 
+	return s.eventsHelper(ctx, req, userName, int(limit), 0 /* offset */)
+}
+
+// eventsHelper returns the event log entries matching req, skipping the
+// first offset entries and returning at most limit entries if limit is
+// positive.
+func (s *adminServer) eventsHelper(
+	ctx context.Context,
+	req *serverpb.EventsRequest,
+	userName security.SQLUsername,
+	limit, offset int,
+) (*serverpb.EventsResponse, error) {
+	redactEvents := !req.UnredactedEvents
+
 	// Execute the query.
 	q := makeSQLQuery()
 	q.Append(`SELECT timestamp, "eventType", "targetID", "reportingID", info, "uniqueID" `)
@@ -951,7 +964,10 @@ func (s *adminServer) Events(
 	}
 	q.Append("ORDER BY timestamp DESC ")
 	if limit > 0 {
-		q.Append("LIMIT $", limit)
+		q.Append("LIMIT $ ", limit)
+	}
+	if offset > 0 {
+		q.Append("OFFSET $", offset)
 	}
 	if len(q.Errors()) > 0 {
 		return nil, s.serverErrors(q.Errors())
@@ -1478,6 +1494,15 @@ func (s *adminServer) Jobs(
 		return nil, err
 	}
 
+	return s.jobsHelper(ctx, req, userName, int(req.Limit), 0 /* offset */)
+}
+
+// jobsHelper returns the jobs matching req, skipping the first offset jobs
+// and returning at most limit jobs if limit is positive.
+func (s *adminServer) jobsHelper(
+	ctx context.Context, req *serverpb.JobsRequest, userName security.SQLUsername, limit, offset int,
+) (*serverpb.JobsResponse, error) {
+
 	q := makeSQLQuery()
 	q.Append(`
       SELECT job_id, job_type, description, statement, user_name, descriptor_ids, status,
@@ -1496,8 +1521,11 @@ func (s *adminServer) Jobs(
 		q.Append(" AND (job_type != $ OR job_type IS NULL)", jobspb.TypeAutoCreateStats.String())
 	}
 	q.Append("ORDER BY created DESC")
-	if req.Limit > 0 {
-		q.Append(" LIMIT $", tree.DInt(req.Limit))
+	if limit > 0 {
+		q.Append(" LIMIT $", tree.DInt(limit))
+	}
+	if offset > 0 {
+		q.Append(" OFFSET $", tree.DInt(offset))
 	}
 	rows, cols, err := s.server.sqlServer.internalExecutor.QueryWithCols(
 		ctx, "admin-jobs", nil, /* txn */
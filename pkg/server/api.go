@@ -44,12 +44,16 @@ func writeJSONResponse(ctx context.Context, w http.ResponseWriter, code int, pay
 // others are implemented directly by apiV2Server.
 //
 // To register a new API endpoint, add it to the route definitions in
-// registerRoutes().
+// routes().
 type apiV2Server struct {
 	admin      *adminServer
 	authServer *authenticationV2Server
 	status     *statusServer
 	mux        *mux.Router
+
+	// openAPISpec is the OpenAPI specification generated from the route
+	// definitions, served under `openapi.json`.
+	openAPISpec *openAPIDocument
 }
 
 // newAPIV2Server returns a new apiV2Server.
@@ -69,40 +73,188 @@ func newAPIV2Server(ctx context.Context, s *Server) *apiV2Server {
 	return a
 }
 
-// registerRoutes registers endpoints under the current API server.
-func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler) {
-	var noOption roleoption.Option
+// apiRoute describes an API endpoint. Route definitions are used both to
+// register the endpoint and to generate the OpenAPI specification served
+// under `openapi.json`.
+type apiRoute struct {
+	// url is the path string that, if matched by the user request, is routed
+	// to this endpoint. Pattern-matching handled by gorilla.Mux; see
+	// https://github.com/gorilla/mux#matching-routes for supported patterns.
+	url string
+	// method is the HTTP method documented for this endpoint.
+	method string
+	// summary is a one-line description of the endpoint.
+	summary string
+	// params lists the query parameters accepted by the endpoint. Path
+	// parameters are derived from url.
+	params []apiParam
+	// response is a zero value of the type serialized in the body of
+	// successful responses, or nil if the body is empty.
+	response interface{}
+	// handler is the http.HandlerFunc to be called if this endpoint url
+	// matches.
+	handler http.HandlerFunc
+	// requiresAuth denotes whether this endpoint requires authentication. If
+	// the user isn't authenticated, an HTTP 401 error is returned. If the
+	// user is authenticated, the http.Request's context contains the current
+	// user's username.
+	requiresAuth bool
+	// role and option are used to determine if the current user is
+	// authorized to access this endpoint. If the user is not at least of
+	// type role, or does not have the roleoption option, an HTTP 403
+	// forbidden error is returned.
+	role   apiRole
+	option roleoption.Option
+}
 
-	// Add any new API endpoint definitions here, even if a sub-server handles
-	// them. Arguments:
-	//
-	// - `url` is the path string that, if matched by the user request, is
-	//    routed to this endpoint. Pattern-matching handled by gorilla.Mux; see
-	//    https://github.com/gorilla/mux#matching-routes for supported patterns.
-	// - `handler` is the http.HandlerFunc to be called if this endpoint url
-	//    matches.
-	// - `requiresAuth` is a bool that denotes whether this endpoint requires
-	//    authentication. If the user isn't authenticated, an HTTP 401 error is
-	//    returned. If the user is authenticated, the http.Request's context
-	//    contains the current user's username.
-	// - `role` and `option` are used to determine if the current user is
-	//    authorized to access this endpoint. If the user is not at least of type
-	//    `role`, or does not have the roleoption `option`, an HTTP 403 forbidden
-	//    error is returned.
-	routeDefinitions := []struct {
-		url          string
-		handler      http.HandlerFunc
-		requiresAuth bool
-		role         apiRole
-		option       roleoption.Option
-	}{
+// apiParam describes a query parameter of an API endpoint.
+type apiParam struct {
+	name        string
+	description string
+	// typ is the OpenAPI type of the parameter: "string", "integer" or
+	// "boolean".
+	typ string
+}
+
+var (
+	limitParam = apiParam{
+		name: "limit", typ: "integer",
+		description: "Maximum number of results to return in this call.",
+	}
+	offsetParam = apiParam{
+		name: "offset", typ: "integer",
+		description: "Continuation offset for results after a past limited run.",
+	}
+	startParam = apiParam{
+		name: "start", typ: "string",
+		description: "Continuation token for results after a past limited run.",
+	}
+)
+
+// routes returns the definitions of all endpoints of the API server. Add any
+// new API endpoint definitions here, even if a sub-server handles them.
+func (a *apiV2Server) routes() []apiRoute {
+	var noOption roleoption.Option
+	return []apiRoute{
 		// Pass through auth-related endpoints to the auth server.
-		{"login/", a.authServer.ServeHTTP, false /* requiresAuth */, regularRole, noOption},
-		{"logout/", a.authServer.ServeHTTP, false /* requiresAuth */, regularRole, noOption},
+		{
+			url: "login/", method: "POST", response: loginResponse{},
+			summary: "Create an API session for the user specified in the form values.",
+			handler: a.authServer.ServeHTTP, requiresAuth: false, role: regularRole, option: noOption,
+		},
+		{
+			url: "logout/", method: "POST", response: logoutResponse{},
+			summary: "Log out the API session specified in the request header.",
+			handler: a.authServer.ServeHTTP, requiresAuth: false, role: regularRole, option: noOption,
+		},
 
 		// Directly register other endpoints in the api server.
-		{"sessions/", a.listSessions, true /* requiresAuth */, adminRole, noOption},
+		{
+			url: "openapi.json", method: "GET", response: openAPIDocument{},
+			summary: "Get the OpenAPI specification of this API.",
+			handler: a.getOpenAPISpec, requiresAuth: false, role: regularRole, option: noOption,
+		},
+		{
+			url: "health/", method: "GET", response: healthResponse{},
+			summary: "Check the health of the node serving the request.",
+			params: []apiParam{{
+				name: "ready", typ: "boolean",
+				description: "If true, check whether the node is ready to accept SQL connections.",
+			}},
+			handler: a.health, requiresAuth: false, role: regularRole, option: noOption,
+		},
+		{
+			url: "sessions/", method: "GET", response: listSessionsResponse{},
+			summary: "List the SQL sessions on all nodes.",
+			params: []apiParam{limitParam, startParam, {
+				name: "username", typ: "string",
+				description: "Only list the sessions of this user.",
+			}},
+			handler: a.listSessions, requiresAuth: true, role: adminRole, option: noOption,
+		},
+		{
+			url: "nodes/", method: "GET", response: nodesResponse{},
+			summary: "List the nodes in the cluster.",
+			params:  []apiParam{limitParam, offsetParam},
+			handler: a.listNodes, requiresAuth: true, role: adminRole, option: noOption,
+		},
+		{
+			url: "nodes/{node_id}/ranges/", method: "GET", response: nodeRangesResponse{},
+			summary: "List the ranges with a replica on the given node.",
+			params: []apiParam{limitParam, offsetParam, {
+				name: "ranges", typ: "string",
+				description: "Comma-separated list of range IDs to restrict the results to.",
+			}},
+			handler: a.listNodeRanges, requiresAuth: true, role: adminRole, option: noOption,
+		},
+		{
+			url: "ranges/hot/", method: "GET", response: hotRangesResponse{},
+			summary: "List the hot ranges on all nodes, or on the given node.",
+			params: []apiParam{limitParam, startParam, {
+				name: "node_id", typ: "string",
+				description: "Only list the hot ranges of this node.",
+			}},
+			handler: a.listHotRanges, requiresAuth: true, role: adminRole, option: noOption,
+		},
+		{
+			url: "databases/", method: "GET", response: databasesResponse{},
+			summary: "List the databases visible to the user.",
+			params:  []apiParam{limitParam, offsetParam},
+			handler: a.listDatabases, requiresAuth: true, role: regularRole, option: noOption,
+		},
+		{
+			url: "databases/{database}/", method: "GET", response: databaseDetailsResponse{},
+			summary: "Get the details of a database.",
+			handler: a.databaseDetails, requiresAuth: true, role: regularRole, option: noOption,
+		},
+		{
+			url: "databases/{database}/tables/", method: "GET", response: databaseTablesResponse{},
+			summary: "List the tables of a database.",
+			params:  []apiParam{limitParam, offsetParam},
+			handler: a.databaseTables, requiresAuth: true, role: regularRole, option: noOption,
+		},
+		{
+			url: "databases/{database}/tables/{table}/", method: "GET", response: tableDetailsResponse{},
+			summary: "Get the details of a table.",
+			handler: a.tableDetails, requiresAuth: true, role: regularRole, option: noOption,
+		},
+		{
+			url: "databases/{database}/tables/{table}/indexes/", method: "GET", response: tableIndexesResponse{},
+			summary: "List the indexes of a table.",
+			params:  []apiParam{limitParam, offsetParam},
+			handler: a.tableIndexes, requiresAuth: true, role: regularRole, option: noOption,
+		},
+		{
+			url: "jobs/", method: "GET", response: jobsResponse{},
+			summary: "List the jobs visible to the user, most recently created first.",
+			params: []apiParam{limitParam, offsetParam, {
+				name: "status", typ: "string",
+				description: "Only list the jobs with this status.",
+			}, {
+				name: "type", typ: "string",
+				description: "Only list the jobs of this type, e.g. BACKUP.",
+			}},
+			handler: a.listJobs, requiresAuth: true, role: regularRole, option: noOption,
+		},
+		{
+			url: "events/", method: "GET", response: eventsResponse{},
+			summary: "List the entries of the event log, most recent first.",
+			params: []apiParam{limitParam, offsetParam, {
+				name: "type", typ: "string",
+				description: "Only list the events of this type, e.g. create_table.",
+			}, {
+				name: "target_id", typ: "integer",
+				description: "Only list the events with this target ID.",
+			}},
+			handler: a.listEvents, requiresAuth: true, role: adminRole, option: noOption,
+		},
 	}
+}
+
+// registerRoutes registers endpoints under the current API server.
+func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler) {
+	routeDefinitions := a.routes()
+	a.openAPISpec = makeOpenAPIDocument(routeDefinitions)
 
 	// For all routes requiring authentication, have the outer mux (a.mux)
 	// send requests through to the authMux, and also register the relevant route
@@ -115,7 +267,7 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 		}
 		if route.requiresAuth {
 			a.mux.Handle(apiV2Path+route.url, authMux)
-			if route.role != regularRole {
+			if route.role != regularRole || route.option != 0 {
 				handler = &roleAuthorizationMux{
 					ie:     a.admin.ie,
 					role:   route.role,
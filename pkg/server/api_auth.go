@@ -376,3 +376,10 @@ func (r *roleAuthorizationMux) ServeHTTP(w http.ResponseWriter, req *http.Reques
 func apiToOutgoingGatewayCtx(ctx context.Context, r *http.Request) context.Context {
 	return metadata.NewOutgoingContext(ctx, forwardAuthenticationMetadata(ctx, r))
 }
+
+// apiToIncomingGatewayCtx converts an HTTP API (v1 or v2) context, to one that
+// can be passed directly to the admin and status server implementations, as
+// if the request had been received over gRPC under the same logged-in user.
+func apiToIncomingGatewayCtx(ctx context.Context, r *http.Request) context.Context {
+	return metadata.NewIncomingContext(ctx, forwardAuthenticationMetadata(ctx, r))
+}
@@ -0,0 +1,326 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+	"github.com/gorilla/mux"
+)
+
+type healthResponse struct {
+	NodeID roachpb.NodeID `json:"node_id"`
+	// Ready is true if readiness was requested and the node is ready to accept
+	// SQL connections.
+	Ready bool `json:"ready"`
+}
+
+// health reports whether the node serving the request is alive, and, if the
+// `ready` query parameter is true, whether it is ready to accept SQL
+// connections. A node that is not ready responds with an HTTP 503 error.
+//
+// Like the v1 health endpoint, this endpoint does not require authentication
+// and thus must not report privileged information.
+func (a *apiV2Server) health(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ready := false
+	if readyStr := r.URL.Query().Get("ready"); readyStr != "" {
+		var err error
+		if ready, err = strconv.ParseBool(readyStr); err != nil {
+			http.Error(w, "invalid ready value", http.StatusBadRequest)
+			return
+		}
+	}
+	if _, err := a.admin.Health(ctx, &serverpb.HealthRequest{Ready: ready}); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, healthResponse{
+		NodeID: a.status.gossip.NodeID.Get(),
+		Ready:  ready,
+	})
+}
+
+// nodeStatus is the subset of the status of a node reported by the nodes
+// endpoint.
+type nodeStatus struct {
+	NodeID            roachpb.NodeID                         `json:"node_id"`
+	Address           util.UnresolvedAddr                    `json:"address"`
+	SQLAddress        util.UnresolvedAddr                    `json:"sql_address"`
+	Locality          roachpb.Locality                       `json:"locality"`
+	ClusterName       string                                 `json:"cluster_name"`
+	ServerVersion     roachpb.Version                        `json:"server_version"`
+	BuildTag          string                                 `json:"build_tag"`
+	StartedAt         int64                                  `json:"started_at"`
+	UpdatedAt         int64                                  `json:"updated_at"`
+	TotalSystemMemory int64                                  `json:"total_system_memory"`
+	NumStores         int                                    `json:"num_stores"`
+	LivenessStatus    livenesspb.NodeLivenessStatus          `json:"liveness_status"`
+	Metrics           map[string]float64                     `json:"metrics,omitempty"`
+	StoreMetrics      map[roachpb.StoreID]map[string]float64 `json:"store_metrics,omitempty"`
+}
+
+func makeNodeStatus(
+	status *statuspb.NodeStatus, liveness livenesspb.NodeLivenessStatus,
+) nodeStatus {
+	ns := nodeStatus{
+		NodeID:            status.Desc.NodeID,
+		Address:           status.Desc.Address,
+		SQLAddress:        status.Desc.SQLAddress,
+		Locality:          status.Desc.Locality,
+		ClusterName:       status.Desc.ClusterName,
+		ServerVersion:     status.Desc.ServerVersion,
+		BuildTag:          status.Desc.BuildTag,
+		StartedAt:         status.StartedAt,
+		UpdatedAt:         status.UpdatedAt,
+		TotalSystemMemory: status.TotalSystemMemory,
+		NumStores:         len(status.StoreStatuses),
+		LivenessStatus:    liveness,
+		Metrics:           status.Metrics,
+		StoreMetrics:      make(map[roachpb.StoreID]map[string]float64, len(status.StoreStatuses)),
+	}
+	for _, ss := range status.StoreStatuses {
+		ns.StoreMetrics[ss.Desc.StoreID] = ss.Metrics
+	}
+	return ns
+}
+
+type nodesResponse struct {
+	Nodes []nodeStatus `json:"nodes"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// listNodes lists the nodes in the cluster, ordered by node ID.
+func (a *apiV2Server) listNodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	nodes, err := a.status.Nodes(ctx, &serverpb.NodesRequest{})
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	sort.Slice(nodes.Nodes, func(i, j int) bool {
+		return nodes.Nodes[i].Desc.NodeID < nodes.Nodes[j].Desc.NodeID
+	})
+	result, next := simplePaginate(nodes.Nodes, limit, offset)
+
+	var response nodesResponse
+	for i, nodeStatuses := 0, result.([]statuspb.NodeStatus); i < len(nodeStatuses); i++ {
+		status := &nodeStatuses[i]
+		response.Nodes = append(response.Nodes,
+			makeNodeStatus(status, nodes.LivenessByNodeID[status.Desc.NodeID]))
+	}
+	if limit > 0 && next < len(nodes.Nodes) {
+		response.Next = next
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
+
+// rangeInfo is the subset of the state of a replica reported by the node
+// ranges endpoint.
+type rangeInfo struct {
+	RangeID           roachpb.RangeID             `json:"range_id"`
+	StoreID           roachpb.StoreID             `json:"store_id"`
+	Span              serverpb.PrettySpan         `json:"span"`
+	Replicas          []roachpb.ReplicaDescriptor `json:"replicas"`
+	LeaseholderNodeID roachpb.NodeID              `json:"leaseholder_node_id,omitempty"`
+	RaftState         string                      `json:"raft_state"`
+	QueriesPerSecond  float64                     `json:"queries_per_second"`
+	WritesPerSecond   float64                     `json:"writes_per_second"`
+	Quiescent         bool                        `json:"quiescent"`
+	Ticking           bool                        `json:"ticking"`
+	Problems          serverpb.RangeProblems      `json:"problems"`
+	ErrorMessage      string                      `json:"error_message,omitempty"`
+}
+
+func makeRangeInfo(info *serverpb.RangeInfo) rangeInfo {
+	ri := rangeInfo{
+		StoreID:          info.SourceStoreID,
+		Span:             info.Span,
+		RaftState:        info.RaftState.State,
+		QueriesPerSecond: info.Stats.QueriesPerSecond,
+		WritesPerSecond:  info.Stats.WritesPerSecond,
+		Quiescent:        info.Quiescent,
+		Ticking:          info.Ticking,
+		Problems:         info.Problems,
+		ErrorMessage:     info.ErrorMessage,
+	}
+	if desc := info.State.Desc; desc != nil {
+		ri.RangeID = desc.RangeID
+		ri.Replicas = desc.Replicas().Descriptors()
+	}
+	if lease := info.State.Lease; lease != nil {
+		ri.LeaseholderNodeID = lease.Replica.NodeID
+	}
+	return ri
+}
+
+type nodeRangesResponse struct {
+	Ranges []rangeInfo `json:"ranges"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// listNodeRanges lists the ranges with a replica on the node specified in
+// the URL, ordered by range ID and store ID.
+func (a *apiV2Server) listNodeRanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	req := &serverpb.RangesRequest{NodeId: mux.Vars(r)["node_id"]}
+	if rangesStr := r.URL.Query().Get("ranges"); rangesStr != "" {
+		for _, s := range strings.Split(rangesStr, ",") {
+			rangeID, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil || rangeID <= 0 {
+				http.Error(w, "invalid range ID: "+s, http.StatusBadRequest)
+				return
+			}
+			req.RangeIDs = append(req.RangeIDs, roachpb.RangeID(rangeID))
+		}
+	}
+	ranges, err := a.status.Ranges(ctx, req)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+
+	response := nodeRangesResponse{Ranges: make([]rangeInfo, 0, len(ranges.Ranges))}
+	for i := range ranges.Ranges {
+		response.Ranges = append(response.Ranges, makeRangeInfo(&ranges.Ranges[i]))
+	}
+	sort.Slice(response.Ranges, func(i, j int) bool {
+		ri, rj := &response.Ranges[i], &response.Ranges[j]
+		if ri.RangeID != rj.RangeID {
+			return ri.RangeID < rj.RangeID
+		}
+		return ri.StoreID < rj.StoreID
+	})
+	total := len(response.Ranges)
+	result, next := simplePaginate(response.Ranges, limit, offset)
+	response.Ranges = result.([]rangeInfo)
+	if limit > 0 && next < total {
+		response.Next = next
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
+
+type hotRangeInfo struct {
+	RangeID          roachpb.RangeID `json:"range_id"`
+	NodeID           roachpb.NodeID  `json:"node_id"`
+	StoreID          roachpb.StoreID `json:"store_id"`
+	QueriesPerSecond float64         `json:"queries_per_second"`
+}
+
+type hotRangesError struct {
+	NodeID  roachpb.NodeID `json:"node_id,omitempty"`
+	Message string         `json:"message"`
+}
+
+type hotRangesResponse struct {
+	Ranges []hotRangeInfo   `json:"ranges"`
+	Errors []hotRangesError `json:"errors,omitempty"`
+	// Next is the continuation token to pass to get the next page of results,
+	// if any.
+	Next string `json:"next,omitempty"`
+}
+
+// listHotRanges lists the hot ranges on all nodes, or on the node specified
+// by the `node_id` query parameter. The results are ordered by node ID, then
+// by store ID and range ID, so that they can be paginated across nodes;
+// clients interested in the hottest ranges sort them by queries per second.
+func (a *apiV2Server) listHotRanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, start := getRPCPaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	var requestedNodeID roachpb.NodeID
+	if nodeIDStr := r.URL.Query().Get("node_id"); nodeIDStr != "" {
+		var err error
+		if requestedNodeID, _, err = a.status.parseNodeID(nodeIDStr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	response := &hotRangesResponse{Ranges: make([]hotRangeInfo, 0)}
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		return a.status.dialNode(ctx, nodeID)
+	}
+	remoteRequest := serverpb.HotRangesRequest{NodeID: "local"}
+	nodeFn := func(ctx context.Context, client interface{}, nodeID roachpb.NodeID) (interface{}, error) {
+		if requestedNodeID != 0 && nodeID != requestedNodeID {
+			return nil, nil
+		}
+		resp, err := client.(serverpb.StatusClient).HotRanges(ctx, &remoteRequest)
+		if err != nil {
+			return nil, err
+		}
+		nodeResp := resp.HotRangesByNodeID[nodeID]
+		if nodeResp.ErrorMessage != "" {
+			return nil, errors.Newf("%s", nodeResp.ErrorMessage)
+		}
+		var hotRanges []hotRangeInfo
+		for _, store := range nodeResp.Stores {
+			for _, hr := range store.HotRanges {
+				hotRanges = append(hotRanges, hotRangeInfo{
+					RangeID:          hr.Desc.RangeID,
+					NodeID:           nodeID,
+					StoreID:          store.StoreID,
+					QueriesPerSecond: hr.QueriesPerSecond,
+				})
+			}
+		}
+		sort.Slice(hotRanges, func(i, j int) bool {
+			if hotRanges[i].StoreID != hotRanges[j].StoreID {
+				return hotRanges[i].StoreID < hotRanges[j].StoreID
+			}
+			return hotRanges[i].RangeID < hotRanges[j].RangeID
+		})
+		return hotRanges, nil
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		if nodeResp == nil {
+			return
+		}
+		response.Ranges = append(response.Ranges, nodeResp.([]hotRangeInfo)...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		response.Errors = append(response.Errors, hotRangesError{NodeID: nodeID, Message: err.Error()})
+	}
+
+	next, err := a.status.paginatedIterateNodes(
+		ctx, "hot ranges", limit, start, dialFn, nodeFn, responseFn, errorFn)
+	if err != nil {
+		response.Errors = append(response.Errors, hotRangesError{Message: err.Error()})
+	}
+	if limit > 0 {
+		if nextBytes, err := next.MarshalText(); err != nil {
+			response.Errors = append(response.Errors, hotRangesError{Message: err.Error()})
+		} else {
+			response.Next = string(nextBytes)
+		}
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
@@ -43,3 +43,25 @@ func apiV2InternalError(ctx context.Context, err error, w http.ResponseWriter) {
 	log.ErrorfDepth(ctx, 1, "%s", err)
 	http.Error(w, errAPIInternalErrorString, http.StatusInternalServerError)
 }
+
+// apiV2Error should be used to report errors returned by the admin and status
+// server implementations during requests for V2 (non-GRPC) endpoints. Errors
+// denoting a bad request, a missing object or insufficient privileges are
+// sent with the corresponding HTTP status code; all other errors are handled
+// like in apiV2InternalError.
+func apiV2Error(ctx context.Context, err error, w http.ResponseWriter) {
+	var code int
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	default:
+		log.ErrorfDepth(ctx, 1, "%s", err)
+		http.Error(w, errAPIInternalErrorString, http.StatusInternalServerError)
+		return
+	}
+	http.Error(w, status.Convert(err).Message(), code)
+}
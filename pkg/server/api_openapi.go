@@ -0,0 +1,280 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// openAPIDocument is an OpenAPI 3.0 specification of the v2 API. It is
+// generated from the route definitions by makeOpenAPIDocument; only the
+// subset of the specification used by the generator is modeled.
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Servers    []openAPIServer                         `json:"servers"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOperation struct {
+	Summary    string                     `json:"summary"`
+	Parameters []openAPIParameter         `json:"parameters,omitempty"`
+	Responses  map[string]openAPIResponse `json:"responses"`
+	Security   []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// openAPISchema describes a JSON value. An empty schema matches any value.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// apiSessionSecurityScheme is the name of the security scheme of the
+// endpoints requiring authentication.
+const apiSessionSecurityScheme = "api_session"
+
+// pathParamRe matches the gorilla.Mux path variables in a route URL, with
+// their optional pattern.
+var pathParamRe = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// makeOpenAPIDocument generates the OpenAPI specification of the given
+// routes. The schemas of the responses are derived from the Go types of the
+// route definitions, following the rules of encoding/json.
+func makeOpenAPIDocument(routes []apiRoute) *openAPIDocument {
+	g := openAPIGenerator{schemas: make(map[string]*openAPISchema)}
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title: "CockroachDB v2 API",
+			Description: "Endpoints requiring authentication expect the session token returned " +
+				"by `login/` in the `" + apiV2AuthHeader + "` header.",
+			Version: "2.0",
+		},
+		Servers: []openAPIServer{{URL: strings.TrimSuffix(apiV2Path, "/")}},
+		Paths:   make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				apiSessionSecurityScheme: {Type: "apiKey", In: "header", Name: apiV2AuthHeader},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		op := &openAPIOperation{
+			Summary:   route.summary,
+			Responses: make(map[string]openAPIResponse),
+		}
+		for _, match := range pathParamRe.FindAllStringSubmatch(route.url, -1) {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &openAPISchema{Type: "string"},
+			})
+		}
+		for _, param := range route.params {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name:        param.name,
+				In:          "query",
+				Description: param.description,
+				Schema:      &openAPISchema{Type: param.typ},
+			})
+		}
+
+		ok := openAPIResponse{Description: http.StatusText(http.StatusOK)}
+		if route.response != nil {
+			ok.Content = map[string]openAPIMediaType{
+				"application/json": {Schema: g.schemaFor(reflect.TypeOf(route.response))},
+			}
+		}
+		op.Responses["200"] = ok
+		if route.requiresAuth {
+			op.Security = []map[string][]string{{apiSessionSecurityScheme: {}}}
+			op.Responses["401"] = openAPIResponse{Description: http.StatusText(http.StatusUnauthorized)}
+			if route.role != regularRole || route.option != 0 {
+				op.Responses["403"] = openAPIResponse{Description: http.StatusText(http.StatusForbidden)}
+			}
+		}
+
+		url := "/" + pathParamRe.ReplaceAllString(route.url, "{$1}")
+		if doc.Paths[url] == nil {
+			doc.Paths[url] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[url][strings.ToLower(route.method)] = op
+	}
+	return doc
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// openAPIGenerator derives schemas from Go types. Named struct types are
+// added to the schemas map and referenced by name.
+type openAPIGenerator struct {
+	schemas map[string]*openAPISchema
+}
+
+// implements returns whether t or *t implements the given interface.
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func (g *openAPIGenerator) schemaFor(t reflect.Type) *openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case implements(t, jsonMarshalerType):
+		// The encoding is type-specific.
+		return &openAPISchema{}
+	case implements(t, textMarshalerType):
+		return &openAPISchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings.
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			s := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+			g.addProperties(s, t)
+			return s
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// Register the schema before adding the properties, so that
+			// recursive types refer to it.
+			s := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+			g.schemas[name] = s
+			g.addProperties(s, t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &openAPISchema{}
+	}
+}
+
+// addProperties adds the fields of the struct type t to the properties of s.
+// Like in encoding/json, the fields of embedded structs without a JSON name
+// are promoted.
+func (g *openAPIGenerator) addProperties(s *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// Unexported field.
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name = tag[:idx]
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct &&
+			!implements(ft, jsonMarshalerType) {
+			g.addProperties(s, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schemaFor(f.Type)
+	}
+}
+
+// getOpenAPISpec serves the OpenAPI specification of the API.
+func (a *apiV2Server) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(r.Context(), w, http.StatusOK, a.openAPISpec)
+}
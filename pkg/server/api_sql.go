@@ -0,0 +1,296 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/gorilla/mux"
+)
+
+type databasesResponse struct {
+	Databases []string `json:"databases"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// listDatabases lists the databases visible to the logged-in user.
+func (a *apiV2Server) listDatabases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	dbs, err := a.admin.Databases(ctx, &serverpb.DatabasesRequest{})
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	result, next := simplePaginate(dbs.Databases, limit, offset)
+	response := databasesResponse{Databases: result.([]string)}
+	if limit > 0 && next < len(dbs.Databases) {
+		response.Next = next
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
+
+type databaseDetailsResponse struct {
+	// DescriptorID identifies the database in the events of the event log.
+	DescriptorID int64                                    `json:"descriptor_id"`
+	Grants       []serverpb.DatabaseDetailsResponse_Grant `json:"grants"`
+	NumTables    int                                      `json:"num_tables"`
+}
+
+// databaseDetails returns the details of the database specified in the URL.
+func (a *apiV2Server) databaseDetails(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	details, err := a.admin.DatabaseDetails(ctx, &serverpb.DatabaseDetailsRequest{
+		Database: mux.Vars(r)["database"],
+	})
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, databaseDetailsResponse{
+		DescriptorID: details.DescriptorID,
+		Grants:       details.Grants,
+		NumTables:    len(details.TableNames),
+	})
+}
+
+type databaseTablesResponse struct {
+	// TableNames contains the schema-qualified names of the tables.
+	TableNames []string `json:"table_names"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// databaseTables lists the tables of the database specified in the URL.
+func (a *apiV2Server) databaseTables(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	details, err := a.admin.DatabaseDetails(ctx, &serverpb.DatabaseDetailsRequest{
+		Database: mux.Vars(r)["database"],
+	})
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	result, next := simplePaginate(details.TableNames, limit, offset)
+	response := databaseTablesResponse{TableNames: result.([]string)}
+	if limit > 0 && next < len(details.TableNames) {
+		response.Next = next
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
+
+type tableDetailsResponse struct {
+	// DescriptorID identifies the table in the events of the event log.
+	DescriptorID         int64                                  `json:"descriptor_id"`
+	Columns              []serverpb.TableDetailsResponse_Column `json:"columns"`
+	Grants               []serverpb.TableDetailsResponse_Grant  `json:"grants"`
+	NumIndexes           int                                    `json:"num_indexes"`
+	RangeCount           int64                                  `json:"range_count"`
+	CreateTableStatement string                                 `json:"create_table_statement"`
+	// ZoneConfigStatement is the CONFIGURE ZONE statement for the zone
+	// configuration in effect for the table.
+	ZoneConfigStatement string                          `json:"zone_config_statement"`
+	ZoneConfigLevel     serverpb.ZoneConfigurationLevel `json:"zone_config_level"`
+}
+
+// tableDetails returns the details of the table specified in the URL. The
+// table name can be qualified with a schema name.
+func (a *apiV2Server) tableDetails(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	vars := mux.Vars(r)
+	details, err := a.admin.TableDetails(ctx, &serverpb.TableDetailsRequest{
+		Database: vars["database"],
+		Table:    vars["table"],
+	})
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, tableDetailsResponse{
+		DescriptorID:         details.DescriptorID,
+		Columns:              details.Columns,
+		Grants:               details.Grants,
+		NumIndexes:           len(groupTableIndexes(details.Indexes)),
+		RangeCount:           details.RangeCount,
+		CreateTableStatement: details.CreateTableStatement,
+		ZoneConfigStatement:  details.ConfigureZoneStatement,
+		ZoneConfigLevel:      details.ZoneConfigLevel,
+	})
+}
+
+type indexColumn struct {
+	Column string `json:"column"`
+	// Direction is either "ASC" or "DESC"; it is unset for stored columns.
+	Direction string `json:"direction,omitempty"`
+	Storing   bool   `json:"storing"`
+	Implicit  bool   `json:"implicit"`
+}
+
+type tableIndex struct {
+	Name    string        `json:"name"`
+	Unique  bool          `json:"unique"`
+	Columns []indexColumn `json:"columns"`
+}
+
+// groupTableIndexes groups the rows of SHOW INDEXES, which contain one entry
+// per indexed column, by index. The order of the indexes and of their
+// columns is preserved.
+func groupTableIndexes(rows []serverpb.TableDetailsResponse_Index) []tableIndex {
+	var indexes []tableIndex
+	for _, row := range rows {
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != row.Name {
+			indexes = append(indexes, tableIndex{Name: row.Name, Unique: row.Unique})
+		}
+		idx := &indexes[len(indexes)-1]
+		col := indexColumn{Column: row.Column, Storing: row.Storing, Implicit: row.Implicit}
+		if !row.Storing {
+			col.Direction = row.Direction
+		}
+		idx.Columns = append(idx.Columns, col)
+	}
+	return indexes
+}
+
+type tableIndexesResponse struct {
+	Indexes []tableIndex `json:"indexes"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// tableIndexes lists the indexes of the table specified in the URL.
+func (a *apiV2Server) tableIndexes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	vars := mux.Vars(r)
+	details, err := a.admin.TableDetails(ctx, &serverpb.TableDetailsRequest{
+		Database: vars["database"],
+		Table:    vars["table"],
+	})
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	indexes := groupTableIndexes(details.Indexes)
+	result, next := simplePaginate(indexes, limit, offset)
+	response := tableIndexesResponse{Indexes: result.([]tableIndex)}
+	if limit > 0 && next < len(indexes) {
+		response.Next = next
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
+
+type jobsResponse struct {
+	Jobs []serverpb.JobsResponse_Job `json:"jobs"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// listJobs lists the jobs visible to the logged-in user, most recently
+// created first. Like in the v1 endpoint, automatic statistics jobs are only
+// listed if requested by type.
+func (a *apiV2Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	req := &serverpb.JobsRequest{Status: r.URL.Query().Get("status")}
+	if typStr := r.URL.Query().Get("type"); typStr != "" {
+		typ, ok := jobspb.Type_value[typStr]
+		if !ok {
+			http.Error(w, "invalid job type: "+typStr, http.StatusBadRequest)
+			return
+		}
+		req.Type = jobspb.Type(typ)
+	}
+	userName, err := userFromContext(ctx)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	// Request one more job than needed to find out whether there is a next
+	// page.
+	fetchLimit := limit
+	if limit > 0 {
+		fetchLimit++
+	}
+	jobs, err := a.admin.jobsHelper(ctx, req, userName, fetchLimit, offset)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	response := jobsResponse{Jobs: jobs.Jobs}
+	if limit > 0 && len(response.Jobs) > limit {
+		response.Jobs = response.Jobs[:limit]
+		response.Next = offset + limit
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
+
+type eventsResponse struct {
+	Events []serverpb.EventsResponse_Event `json:"events"`
+	// Next is the offset to pass to get the next page of results, if any.
+	Next int `json:"next,omitempty"`
+}
+
+// listEvents lists the entries of the event log, most recent first. As in the
+// v1 endpoint, the contents of the events are redacted, and at most
+// defaultAPIEventLimit events are returned if no limit is specified.
+func (a *apiV2Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	limit, offset := getSimplePaginationValues(r)
+	if limit == 0 {
+		limit = defaultAPIEventLimit
+	}
+	ctx = apiToIncomingGatewayCtx(ctx, r)
+
+	req := &serverpb.EventsRequest{Type: r.URL.Query().Get("type")}
+	if targetIDStr := r.URL.Query().Get("target_id"); targetIDStr != "" {
+		targetID, err := strconv.ParseInt(targetIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid target_id value", http.StatusBadRequest)
+			return
+		}
+		req.TargetId = targetID
+	}
+	userName, err := userFromContext(ctx)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	// Request one more event than needed to find out whether there is a next
+	// page.
+	events, err := a.admin.eventsHelper(ctx, req, userName, limit+1, offset)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	response := eventsResponse{Events: events.Events}
+	if len(response.Events) > limit {
+		response.Events = response.Events[:limit]
+		response.Next = offset + limit
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, response)
+}
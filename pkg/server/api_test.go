@@ -0,0 +1,286 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	routes := (&apiV2Server{}).routes()
+	doc := makeOpenAPIDocument(routes)
+
+	// Every route is documented, with its path parameters.
+	for _, route := range routes {
+		url := "/" + pathParamRe.ReplaceAllString(route.url, "{$1}")
+		op, ok := doc.Paths[url][strings.ToLower(route.method)]
+		require.True(t, ok, "route %s not documented", route.url)
+		require.Equal(t, route.summary, op.Summary)
+		require.Equal(t, route.requiresAuth, len(op.Security) > 0, route.url)
+		var pathParams []string
+		for _, param := range op.Parameters {
+			if param.In == "path" {
+				pathParams = append(pathParams, param.Name)
+			}
+		}
+		require.Equal(t, strings.Count(route.url, "{"), len(pathParams), route.url)
+	}
+	op := doc.Paths["/databases/{database}/tables/{table}/"]["get"]
+	require.Equal(t, "database", op.Parameters[0].Name)
+	require.Equal(t, "table", op.Parameters[1].Name)
+
+	// All the references to schemas can be resolved.
+	var checkRefs func(s *openAPISchema)
+	checkRefs = func(s *openAPISchema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			_, ok := doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+			require.True(t, ok, "unresolved reference %s", s.Ref)
+		}
+		checkRefs(s.Items)
+		checkRefs(s.AdditionalProperties)
+		for _, p := range s.Properties {
+			checkRefs(p)
+		}
+	}
+	for _, s := range doc.Components.Schemas {
+		checkRefs(s)
+	}
+	for _, ops := range doc.Paths {
+		for _, op := range ops {
+			for _, resp := range op.Responses {
+				for _, content := range resp.Content {
+					checkRefs(content.Schema)
+				}
+			}
+		}
+	}
+
+	// The properties follow the JSON encoding of the response types,
+	// including the promoted fields of embedded structs.
+	sessions := doc.Components.Schemas["server.listSessionsResponse"]
+	require.NotNil(t, sessions)
+	for _, prop := range []string{"sessions", "errors", "next"} {
+		require.Contains(t, sessions.Properties, prop)
+	}
+	nodes := doc.Components.Schemas["server.nodeStatus"]
+	require.NotNil(t, nodes)
+	require.Equal(t, &openAPISchema{Type: "integer", Format: "int32"}, nodes.Properties["node_id"])
+}
+
+func TestAPIV2Endpoints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCluster := serverutils.StartNewTestCluster(t, 3, base.TestClusterArgs{})
+	ctx := context.Background()
+	defer testCluster.Stopper().Stop(ctx)
+
+	ts1 := testCluster.Server(0)
+	db := sqlutils.MakeSQLRunner(testCluster.ServerConn(0))
+	db.Exec(t, `CREATE DATABASE testdb`)
+	db.Exec(t, `CREATE TABLE testdb.t (a INT PRIMARY KEY, b INT, c INT, INDEX b_idx (b) STORING (c))`)
+	for i := 0; i < 5; i++ {
+		db.Exec(t, `CREATE TABLE testdb.t`+strconv.Itoa(i)+` (a INT PRIMARY KEY, b INT)`)
+	}
+	// Create a couple of schema change jobs.
+	db.Exec(t, `CREATE INDEX ON testdb.t0 (b)`)
+	db.Exec(t, `CREATE INDEX ON testdb.t1 (b)`)
+
+	adminClient, err := ts1.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+
+	doRequest := func(client http.Client, path string, query url.Values, expectedCode int, response interface{}) {
+		req, err := http.NewRequest("GET", ts1.AdminURL()+apiV2Path+path, nil)
+		require.NoError(t, err)
+		req.URL.RawQuery = query.Encode()
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		bytesResponse, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, expectedCode, resp.StatusCode, string(bytesResponse))
+		if response != nil {
+			require.NoError(t, json.Unmarshal(bytesResponse, response))
+		}
+	}
+
+	t.Run("nodes", func(t *testing.T) {
+		var all nodesResponse
+		doRequest(adminClient, "nodes/", nil, http.StatusOK, &all)
+		require.Len(t, all.Nodes, 3)
+		require.Zero(t, all.Next)
+
+		// Paginate one node at a time.
+		var paginated []nodeStatus
+		offset := 0
+		for {
+			var page nodesResponse
+			doRequest(adminClient, "nodes/", url.Values{
+				"limit": {"1"}, "offset": {strconv.Itoa(offset)},
+			}, http.StatusOK, &page)
+			paginated = append(paginated, page.Nodes...)
+			if page.Next == 0 {
+				break
+			}
+			offset = page.Next
+		}
+		require.Len(t, paginated, 3)
+		for i := range paginated {
+			require.Equal(t, all.Nodes[i].NodeID, paginated[i].NodeID)
+		}
+	})
+
+	t.Run("ranges", func(t *testing.T) {
+		var ranges nodeRangesResponse
+		doRequest(adminClient, "nodes/1/ranges/", url.Values{"limit": {"2"}}, http.StatusOK, &ranges)
+		require.Len(t, ranges.Ranges, 2)
+		require.Equal(t, 2, ranges.Next)
+
+		doRequest(adminClient, "nodes/abc/ranges/", nil, http.StatusBadRequest, nil)
+
+		var hotRanges hotRangesResponse
+		doRequest(adminClient, "ranges/hot/", url.Values{"node_id": {"1"}}, http.StatusOK, &hotRanges)
+		require.Empty(t, hotRanges.Errors)
+		for _, r := range hotRanges.Ranges {
+			require.EqualValues(t, 1, r.NodeID)
+		}
+	})
+
+	t.Run("databases", func(t *testing.T) {
+		var dbs databasesResponse
+		doRequest(adminClient, "databases/", nil, http.StatusOK, &dbs)
+		require.Contains(t, dbs.Databases, "testdb")
+
+		var details databaseDetailsResponse
+		doRequest(adminClient, "databases/testdb/", nil, http.StatusOK, &details)
+		require.Equal(t, 6, details.NumTables)
+		doRequest(adminClient, "databases/missing/", nil, http.StatusNotFound, nil)
+
+		var tables []string
+		offset := 0
+		for {
+			var page databaseTablesResponse
+			doRequest(adminClient, "databases/testdb/tables/", url.Values{
+				"limit": {"4"}, "offset": {strconv.Itoa(offset)},
+			}, http.StatusOK, &page)
+			require.LessOrEqual(t, len(page.TableNames), 4)
+			tables = append(tables, page.TableNames...)
+			if page.Next == 0 {
+				break
+			}
+			offset = page.Next
+		}
+		require.Len(t, tables, 6)
+
+		var table tableDetailsResponse
+		doRequest(adminClient, "databases/testdb/tables/public.t/", nil, http.StatusOK, &table)
+		require.Len(t, table.Columns, 3)
+		require.Equal(t, 2, table.NumIndexes)
+
+		var indexes tableIndexesResponse
+		doRequest(adminClient, "databases/testdb/tables/t/indexes/", nil, http.StatusOK, &indexes)
+		require.Len(t, indexes.Indexes, 2)
+		// SHOW INDEXES doesn't guarantee an order of the indexes.
+		var bIdx tableIndex
+		for _, idx := range indexes.Indexes {
+			if idx.Name == "b_idx" {
+				bIdx = idx
+			}
+		}
+		require.Equal(t, "b_idx", bIdx.Name)
+		var storing []string
+		for _, col := range bIdx.Columns {
+			if col.Storing {
+				storing = append(storing, col.Column)
+			}
+		}
+		require.Equal(t, []string{"c"}, storing)
+
+		// An offset without a limit skips the first indexes.
+		var lastIndex tableIndexesResponse
+		doRequest(adminClient, "databases/testdb/tables/t/indexes/", url.Values{
+			"offset": {"1"},
+		}, http.StatusOK, &lastIndex)
+		require.Equal(t, indexes.Indexes[1:], lastIndex.Indexes)
+		require.Zero(t, lastIndex.Next)
+	})
+
+	t.Run("jobs and events", func(t *testing.T) {
+		var jobs jobsResponse
+		doRequest(adminClient, "jobs/", url.Values{"limit": {"1"}}, http.StatusOK, &jobs)
+		require.Len(t, jobs.Jobs, 1)
+		require.Equal(t, 1, jobs.Next)
+		doRequest(adminClient, "jobs/", url.Values{"type": {"invalid"}}, http.StatusBadRequest, nil)
+
+		var events eventsResponse
+		doRequest(adminClient, "events/", url.Values{"type": {"create_table"}}, http.StatusOK, &events)
+		numEvents := len(events.Events)
+		require.LessOrEqual(t, 6, numEvents)
+		require.Zero(t, events.Next)
+
+		var paginated []serverpb.EventsResponse_Event
+		offset := 0
+		for {
+			var page eventsResponse
+			doRequest(adminClient, "events/", url.Values{
+				"type": {"create_table"}, "limit": {"5"}, "offset": {strconv.Itoa(offset)},
+			}, http.StatusOK, &page)
+			require.LessOrEqual(t, len(page.Events), 5)
+			paginated = append(paginated, page.Events...)
+			if page.Next == 0 {
+				break
+			}
+			require.Equal(t, offset+5, page.Next)
+			offset = page.Next
+		}
+		require.Len(t, paginated, numEvents)
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		nonAdminClient, err := ts1.GetAuthenticatedHTTPClient(false)
+		require.NoError(t, err)
+		doRequest(nonAdminClient, "nodes/", nil, http.StatusForbidden, nil)
+		doRequest(nonAdminClient, "events/", nil, http.StatusForbidden, nil)
+		doRequest(nonAdminClient, "databases/", nil, http.StatusOK, nil)
+
+		// Health and the specification don't require authentication.
+		anonymousClient, err := ts1.GetHTTPClient()
+		require.NoError(t, err)
+		var health healthResponse
+		doRequest(anonymousClient, "health/", url.Values{"ready": {"true"}}, http.StatusOK, &health)
+		require.True(t, health.Ready)
+		doRequest(anonymousClient, "nodes/", nil, http.StatusUnauthorized, nil)
+		var spec openAPIDocument
+		doRequest(anonymousClient, "openapi.json", nil, http.StatusOK, &spec)
+		require.Contains(t, spec.Paths, "/nodes/{node_id}/ranges/")
+	})
+}
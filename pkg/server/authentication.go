@@ -437,7 +437,11 @@ func (s *authenticationServer) verifyPassword(
 		}
 	}
 
-	return security.CompareHashAndPassword(hashedPassword, password) == nil, false, nil
+	if security.CompareHashAndPassword(hashedPassword, password) != nil {
+		return false, false, nil
+	}
+	sql.MaybeUpgradeStoredPasswordHash(ctx, s.server.sqlServer.execCfg, username, password, hashedPassword)
+	return true, false, nil
 }
 
 // CreateAuthSecret creates a secret, hash pair to populate a session auth token.
@@ -34,14 +34,18 @@ import (
 // simplePaginate takes in an input slice, and returns a sub-slice of the next
 // `limit` elements starting at `offset`. The second returned value is the
 // next offset that can be used to return the next "limit" results, or
-// len(result) if there are no more results.
+// len(result) if there are no more results. If limit is not positive, all the
+// elements starting at `offset` are returned.
 func simplePaginate(input interface{}, limit, offset int) (result interface{}, next int) {
 	val := reflect.ValueOf(input)
-	if limit <= 0 || val.Kind() != reflect.Slice {
-		return input, 0
-	} else if offset < 0 {
+	if offset < 0 {
 		offset = 0
 	}
+	if val.Kind() != reflect.Slice || (limit <= 0 && offset == 0) {
+		return input, 0
+	} else if limit <= 0 {
+		limit = val.Len()
+	}
 	startIdx := offset
 	endIdx := offset + limit
 	if startIdx > val.Len() {
@@ -441,3 +445,17 @@ func getRPCPaginationValues(r *http.Request) (limit int, start paginationState)
 	}
 	return limit, start
 }
+
+// getSimplePaginationValues parses offset-based pagination related values out
+// of the query string of a Request. Meant for use with simplePaginate. Missing
+// or invalid values are returned as 0; in particular, an offset is honored
+// even if no limit is specified.
+func getSimplePaginationValues(r *http.Request) (limit, offset int) {
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		offset = o
+	}
+	return limit, offset
+}
@@ -13,6 +13,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http/httptest"
 	"sort"
 	"strconv"
 	"strings"
@@ -83,6 +84,35 @@ func TestSimplePaginate(t *testing.T) {
 	})
 }
 
+func TestGetSimplePaginationValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		query                          string
+		expectedLimit, expectedOffset int
+	}{
+		{"", 0, 0},
+		{"limit=5", 5, 0},
+		{"limit=5&offset=10", 5, 10},
+		// The offset applies even without a limit.
+		{"offset=10", 0, 10},
+		{"limit=0&offset=10", 0, 10},
+		{"limit=-1&offset=10", 0, 10},
+		{"limit=abc&offset=10", 0, 10},
+		{"limit=5&offset=-1", 5, 0},
+		{"limit=5&offset=abc", 5, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?"+tc.query, nil)
+			limit, offset := getSimplePaginationValues(r)
+			require.Equal(t, tc.expectedLimit, limit)
+			require.Equal(t, tc.expectedOffset, offset)
+		})
+	}
+}
+
 // TestPaginationState is a datadriven-based test for paginationState and
 // related methods.
 //
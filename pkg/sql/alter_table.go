@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
@@ -409,6 +410,14 @@ func (n *alterTableNode) startExec(params runParams) error {
 				continue
 			}
 
+			if n.tableDesc.HasRowLevelTTL() && colToDrop.GetName() == colinfo.TTLDefaultExpirationColumnName {
+				return pgerror.Newf(
+					pgcode.InvalidTableDefinition,
+					"cannot drop column %s while row-level TTL is active",
+					colToDrop.GetName(),
+				)
+			}
+
 			// If the dropped column uses a sequence, remove references to it from that sequence.
 			if colToDrop.NumUsesSequences() > 0 {
 				if err := params.p.removeSequenceDependencies(params.ctx, n.tableDesc, colToDrop.ColumnDesc()); err != nil {
@@ -100,7 +100,8 @@ func ValidateColumnDefType(t *types.T) error {
 	case types.BitFamily, types.IntFamily, types.FloatFamily, types.BoolFamily, types.BytesFamily, types.DateFamily,
 		types.INetFamily, types.IntervalFamily, types.JsonFamily, types.OidFamily, types.TimeFamily,
 		types.TimestampFamily, types.TimestampTZFamily, types.UuidFamily, types.TimeTZFamily,
-		types.GeographyFamily, types.GeometryFamily, types.EnumFamily, types.Box2DFamily:
+		types.GeographyFamily, types.GeometryFamily, types.EnumFamily, types.Box2DFamily,
+		types.TSQueryFamily, types.TSVectorFamily:
 		// These types are OK.
 
 	default:
@@ -123,7 +124,8 @@ func ColumnTypeIsIndexable(t *types.T) bool {
 func ColumnTypeIsInvertedIndexable(t *types.T) bool {
 	family := t.Family()
 	return family == types.JsonFamily || family == types.ArrayFamily ||
-		family == types.GeographyFamily || family == types.GeometryFamily
+		family == types.GeographyFamily || family == types.GeometryFamily ||
+		family == types.TSVectorFamily
 }
 
 // MustBeValueEncoded returns true if columns of the given kind can only be value
@@ -137,7 +139,8 @@ func MustBeValueEncoded(semanticType *types.T) bool {
 		default:
 			return MustBeValueEncoded(semanticType.ArrayContents())
 		}
-	case types.JsonFamily, types.TupleFamily, types.GeographyFamily, types.GeometryFamily:
+	case types.JsonFamily, types.TupleFamily, types.GeographyFamily, types.GeometryFamily,
+		types.TSQueryFamily, types.TSVectorFamily:
 		return true
 	}
 	return false
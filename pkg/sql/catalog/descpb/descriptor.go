@@ -40,6 +40,8 @@ func GetDescriptorID(desc *Descriptor) ID {
 		return t.Type.ID
 	case *Descriptor_Schema:
 		return t.Schema.ID
+	case *Descriptor_Function:
+		return t.Function.ID
 	default:
 		panic(errors.AssertionFailedf("GetID: unknown Descriptor type %T", t))
 	}
@@ -56,6 +58,8 @@ func GetDescriptorName(desc *Descriptor) string {
 		return t.Type.Name
 	case *Descriptor_Schema:
 		return t.Schema.Name
+	case *Descriptor_Function:
+		return t.Function.Name
 	default:
 		panic(errors.AssertionFailedf("GetDescriptorName: unknown Descriptor type %T", t))
 	}
@@ -72,6 +76,8 @@ func GetDescriptorVersion(desc *Descriptor) DescriptorVersion {
 		return t.Type.Version
 	case *Descriptor_Schema:
 		return t.Schema.Version
+	case *Descriptor_Function:
+		return t.Function.Version
 	default:
 		panic(errors.AssertionFailedf("GetVersion: unknown Descriptor type %T", t))
 	}
@@ -88,6 +94,8 @@ func GetDescriptorModificationTime(desc *Descriptor) hlc.Timestamp {
 		return t.Type.ModificationTime
 	case *Descriptor_Schema:
 		return t.Schema.ModificationTime
+	case *Descriptor_Function:
+		return t.Function.ModificationTime
 	default:
 		debug.PrintStack()
 		panic(errors.AssertionFailedf("GetDescriptorModificationTime: unknown Descriptor type %T", t))
@@ -105,6 +113,8 @@ func GetDescriptorState(desc *Descriptor) DescriptorState {
 		return t.Type.State
 	case *Descriptor_Schema:
 		return t.Schema.State
+	case *Descriptor_Function:
+		return t.Function.State
 	default:
 		debug.PrintStack()
 		panic(errors.AssertionFailedf("GetDescriptorState: unknown Descriptor type %T", t))
@@ -122,6 +132,8 @@ func setDescriptorModificationTime(desc *Descriptor, ts hlc.Timestamp) {
 		t.Type.ModificationTime = ts
 	case *Descriptor_Schema:
 		t.Schema.ModificationTime = ts
+	case *Descriptor_Function:
+		t.Function.ModificationTime = ts
 	default:
 		panic(errors.AssertionFailedf("setModificationTime: unknown Descriptor type %T", t))
 	}
@@ -436,6 +436,7 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.ScheduledJobsTableID:                 privilege.ReadWriteData,
 	keys.SqllivenessID:                        privilege.ReadWriteData,
 	keys.MigrationsID:                         privilege.ReadWriteData,
+	keys.NotificationsTableID:                 privilege.ReadWriteData,
 }
 
 // SetOwner sets the owner of the privilege descriptor to the provided string.
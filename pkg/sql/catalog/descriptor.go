@@ -101,6 +101,19 @@ type SchemaDescriptor interface {
 	SchemaDesc() *descpb.SchemaDescriptor
 }
 
+// FunctionDescriptor will eventually be called funcdesc.Descriptor.
+// It is implemented by Immutable.
+type FunctionDescriptor interface {
+	Descriptor
+	FuncDesc() *descpb.FunctionDescriptor
+	// GetVolatility returns the volatility of the function as understood by
+	// the optimizer.
+	GetVolatility() tree.Volatility
+	// ArgTypes returns the types of the arguments of the function.
+	ArgTypes() []*types.T
+	Validate() error
+}
+
 // TableDescriptor is an interface around the table descriptor types.
 type TableDescriptor interface {
 	Descriptor
@@ -267,6 +280,9 @@ type TableDescriptor interface {
 	IsLocalityRegionalByTable() bool
 	IsLocalityGlobal() bool
 	GetRegionalByTableRegion() (descpb.RegionName, error)
+
+	GetRowLevelTTL() *descpb.TableDescriptor_RowLevelTTL
+	HasRowLevelTTL() bool
 }
 
 // Index is an interface around the index descriptor types.
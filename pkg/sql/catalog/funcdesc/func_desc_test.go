@@ -0,0 +1,101 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package funcdesc_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSafeMessage(t *testing.T) {
+	for _, tc := range []struct {
+		desc catalog.FunctionDescriptor
+		exp  string
+	}{
+		{
+			desc: funcdesc.NewImmutable(descpb.FunctionDescriptor{
+				ID:             12,
+				Version:        1,
+				ParentID:       2,
+				ParentSchemaID: 29,
+				Args:           []descpb.FunctionDescriptor_Argument{{Name: "a", Type: types.Int}},
+				Volatility:     descpb.FunctionDescriptor_IMMUTABLE,
+				State:          descpb.DescriptorState_OFFLINE,
+				OfflineReason:  "foo",
+			}),
+			exp: "funcdesc.Immutable: {ID: 12, Version: 1, ModificationTime: \"0,0\", ParentID: 2, ParentSchemaID: 29, State: OFFLINE, OfflineReason: \"foo\", NumArgs: 1, Volatility: IMMUTABLE}",
+		},
+		{
+			desc: funcdesc.NewCreatedMutable(descpb.FunctionDescriptor{
+				ID:             42,
+				Version:        1,
+				ParentID:       2,
+				ParentSchemaID: 29,
+				State:          descpb.DescriptorState_PUBLIC,
+			}),
+			exp: "funcdesc.Mutable: {ID: 42, Version: 1, IsUncommitted: true, ModificationTime: \"0,0\", ParentID: 2, ParentSchemaID: 29, State: PUBLIC, NumArgs: 0, Volatility: VOLATILE}",
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			redacted := string(redact.Sprint(tc.desc).Redact())
+			require.Equal(t, tc.exp, redacted)
+			{
+				var m map[string]interface{}
+				require.NoError(t, yaml.UnmarshalStrict([]byte(redacted), &m))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() descpb.FunctionDescriptor {
+		return descpb.FunctionDescriptor{
+			Name:           "f",
+			ID:             52,
+			Version:        1,
+			ParentID:       50,
+			ParentSchemaID: 29,
+			Args:           []descpb.FunctionDescriptor_Argument{{Name: "a", Type: types.Int}},
+			ReturnType:     types.Int,
+			Volatility:     descpb.FunctionDescriptor_IMMUTABLE,
+			LeakProof:      true,
+			Privileges:     descpb.NewDefaultPrivilegeDescriptor(security.RootUserName()),
+		}
+	}
+	require.NoError(t, funcdesc.NewImmutable(valid()).Validate())
+
+	for _, tc := range []struct {
+		err    string
+		mutate func(*descpb.FunctionDescriptor)
+	}{
+		{`empty function name`, func(d *descpb.FunctionDescriptor) { d.Name = "" }},
+		{`invalid parentSchemaID 0`, func(d *descpb.FunctionDescriptor) { d.ParentSchemaID = 0 }},
+		{`function "f" has no return type`, func(d *descpb.FunctionDescriptor) { d.ReturnType = nil }},
+		{`argument 1 of function "f" has no type`, func(d *descpb.FunctionDescriptor) { d.Args[0].Type = nil }},
+		{`function "f" is leakproof but not immutable`, func(d *descpb.FunctionDescriptor) {
+			d.Volatility = descpb.FunctionDescriptor_STABLE
+		}},
+	} {
+		t.Run(tc.err, func(t *testing.T) {
+			desc := valid()
+			tc.mutate(&desc)
+			require.EqualError(t, funcdesc.NewImmutable(desc).Validate(), tc.err)
+		})
+	}
+}
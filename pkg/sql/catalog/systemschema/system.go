@@ -44,6 +44,8 @@ func ShouldSplitAtDesc(rawDesc *roachpb.Value) bool {
 		return false
 	case *descpb.Descriptor_Schema:
 		return false
+	case *descpb.Descriptor_Function:
+		return false
 	default:
 		panic(errors.AssertionFailedf("unexpected descriptor type %#v", &desc))
 	}
@@ -299,9 +301,14 @@ CREATE TABLE system.statement_diagnostics_requests(
 	statement_fingerprint STRING NOT NULL,
 	statement_diagnostics_id INT8,
 	requested_at TIMESTAMPTZ NOT NULL,
+	min_execution_latency INTERVAL NULL,
+	sampling_probability FLOAT8 NULL,
+	expires_at TIMESTAMPTZ NULL,
+	continuous BOOL NULL,
 	INDEX completed_idx (completed, id) STORING (statement_fingerprint),
 
-	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at)
+	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at,
+	                  min_execution_latency, sampling_probability, expires_at, continuous)
 );`
 
 	StatementDiagnosticsTableSchema = `
@@ -313,8 +320,11 @@ create table system.statement_diagnostics(
   trace JSONB,
   bundle_chunks INT ARRAY,
 	error STRING,
+  request_id INT8 NULL,
+  execution_latency INTERVAL NULL,
 
-	FAMILY "primary" (id, statement_fingerprint, statement, collected_at, trace, bundle_chunks, error)
+	FAMILY "primary" (id, statement_fingerprint, statement, collected_at, trace, bundle_chunks, error,
+	                  request_id, execution_latency)
 );`
 
 	ScheduledJobsTableSchema = `
@@ -356,6 +366,17 @@ CREATE TABLE system.migrations (
     FAMILY "primary" (major, minor, patch, internal, completed_at),
     PRIMARY KEY (major, minor, patch, internal)
 )`
+
+	NotificationsTableSchema = `
+CREATE TABLE system.notifications (
+    created   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    id        UUID NOT NULL DEFAULT gen_random_uuid(),
+    channel   STRING NOT NULL,
+    payload   STRING NOT NULL,
+    sender_id INT8 NOT NULL,
+    FAMILY "primary" (created, id, channel, payload, sender_id),
+    PRIMARY KEY (created, id)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1549,13 +1570,18 @@ var (
 			{Name: "statement_fingerprint", ID: 3, Type: types.String, Nullable: false},
 			{Name: "statement_diagnostics_id", ID: 4, Type: types.Int, Nullable: true},
 			{Name: "requested_at", ID: 5, Type: types.TimestampTZ, Nullable: false},
+			{Name: "min_execution_latency", ID: 6, Type: types.Interval, Nullable: true},
+			{Name: "sampling_probability", ID: 7, Type: types.Float, Nullable: true},
+			{Name: "expires_at", ID: 8, Type: types.TimestampTZ, Nullable: true},
+			{Name: "continuous", ID: 9, Type: types.Bool, Nullable: true},
 		},
-		NextColumnID: 6,
+		NextColumnID: 10,
 		Families: []descpb.ColumnFamilyDescriptor{
 			{
-				Name:        "primary",
-				ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at"},
-				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+				Name: "primary",
+				ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at",
+					"min_execution_latency", "sampling_probability", "expires_at", "continuous"},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
 			},
 		},
 		NextFamilyID: 1,
@@ -1595,14 +1621,16 @@ var (
 			{Name: "trace", ID: 5, Type: types.Jsonb, Nullable: true},
 			{Name: "bundle_chunks", ID: 6, Type: types.IntArray, Nullable: true},
 			{Name: "error", ID: 7, Type: types.String, Nullable: true},
+			{Name: "request_id", ID: 8, Type: types.Int, Nullable: true},
+			{Name: "execution_latency", ID: 9, Type: types.Interval, Nullable: true},
 		},
-		NextColumnID: 8,
+		NextColumnID: 10,
 		Families: []descpb.ColumnFamilyDescriptor{
 			{
 				Name: "primary",
 				ColumnNames: []string{"id", "statement_fingerprint", "statement",
-					"collected_at", "trace", "bundle_chunks", "error"},
-				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7},
+					"collected_at", "trace", "bundle_chunks", "error", "request_id", "execution_latency"},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
 			},
 		},
 		NextFamilyID: 1,
@@ -1749,6 +1777,53 @@ var (
 		FormatVersion:  descpb.InterleavedFormatVersion,
 		NextMutationID: 1,
 	})
+
+	genRandomUUIDString = "gen_random_uuid()"
+
+	// NotificationsTable is the descriptor for the notifications table. It
+	// stores the notifications generated by NOTIFY statements until they have
+	// been delivered to the listening sessions on every node.
+	NotificationsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "notifications",
+		ID:                      keys.NotificationsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "created", ID: 1, Type: types.TimestampTZ, DefaultExpr: &nowTZString, Nullable: false},
+			{Name: "id", ID: 2, Type: types.Uuid, DefaultExpr: &genRandomUUIDString, Nullable: false},
+			{Name: "channel", ID: 3, Type: types.String, Nullable: false},
+			{Name: "payload", ID: 4, Type: types.String, Nullable: false},
+			{Name: "sender_id", ID: 5, Type: types.Int, Nullable: false},
+		},
+		NextColumnID: 6,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"created", "id", "channel", "payload", "sender_id"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:        tabledesc.PrimaryKeyIndexName,
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"created", "id"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+			},
+			ColumnIDs: []descpb.ColumnID{1, 2},
+			Version:   descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.NotificationsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
 )
 
 // newCommentPrivilegeDescriptor returns a privilege descriptor for comment table
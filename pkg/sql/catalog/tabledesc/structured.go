@@ -197,6 +197,11 @@ func (desc *wrapper) IsPartitionAllBy() bool {
 	return desc.PartitionAllBy
 }
 
+// HasRowLevelTTL returns whether the table has row-level TTL configured.
+func (desc *wrapper) HasRowLevelTTL() bool {
+	return desc.RowLevelTTL != nil
+}
+
 // GetParentSchemaID returns the ParentSchemaID if the descriptor has
 // one. If the descriptor was created before the field was added, then the
 // descriptor belongs to a table under the `public` physical schema. The static
@@ -1953,6 +1958,10 @@ func (desc *wrapper) ValidateTable(ctx context.Context) error {
 		if err := desc.validatePartitioning(); err != nil {
 			return err
 		}
+
+		if err := desc.validateRowLevelTTL(columnNames); err != nil {
+			return err
+		}
 	}
 
 	// Fill in any incorrect privileges that may have been missed due to mixed-versions.
@@ -2586,6 +2595,23 @@ func (ps partitionInterval) Range() interval.Range {
 	return interval.Range{Start: []byte(ps.start), End: []byte(ps.end)}
 }
 
+// validateRowLevelTTL validates that a table with row-level TTL has an
+// expiration interval and an expiration column.
+func (desc *wrapper) validateRowLevelTTL(columnNames map[string]descpb.ColumnID) error {
+	ttl := desc.RowLevelTTL
+	if ttl == nil {
+		return nil
+	}
+	if ttl.ExpireAfter == "" {
+		return errors.AssertionFailedf("row-level TTL on table %q has no expiration interval", desc.Name)
+	}
+	if _, ok := columnNames[colinfo.TTLDefaultExpirationColumnName]; !ok {
+		return errors.AssertionFailedf(
+			"row-level TTL on table %q requires column %q", desc.Name, colinfo.TTLDefaultExpirationColumnName)
+	}
+	return nil
+}
+
 // validatePartitioning validates that any PartitioningDescriptors contained in
 // table indexes are well-formed. See validatePartitioningDesc for details.
 func (desc *wrapper) validatePartitioning() error {
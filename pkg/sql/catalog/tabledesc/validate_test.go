@@ -112,6 +112,7 @@ var validationMap = []struct {
 			"Temporary":                     {status: thisFieldReferencesNoObjects},
 			"LocalityConfig":                {status: iSolemnlySwearThisFieldIsValidated},
 			"PartitionAllBy":                {status: iSolemnlySwearThisFieldIsValidated},
+			"RowLevelTTL":                   {status: iSolemnlySwearThisFieldIsValidated},
 		},
 	},
 	{
@@ -194,16 +195,18 @@ var validationMap = []struct {
 			"OnDelete":          {status: thisFieldReferencesNoObjects},
 			"OnUpdate":          {status: thisFieldReferencesNoObjects},
 			"Match":             {status: thisFieldReferencesNoObjects},
+			"Deferrability":     {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
 		obj: descpb.UniqueWithoutIndexConstraint{},
 		fieldMap: map[string]validationStatusInfo{
-			"TableID":   {status: iSolemnlySwearThisFieldIsValidated},
-			"ColumnIDs": {status: iSolemnlySwearThisFieldIsValidated},
-			"Name":      {status: thisFieldReferencesNoObjects},
-			"Validity":  {status: thisFieldReferencesNoObjects},
-			"Predicate": {status: iSolemnlySwearThisFieldIsValidated},
+			"TableID":       {status: iSolemnlySwearThisFieldIsValidated},
+			"ColumnIDs":     {status: iSolemnlySwearThisFieldIsValidated},
+			"Name":          {status: thisFieldReferencesNoObjects},
+			"Validity":      {status: thisFieldReferencesNoObjects},
+			"Predicate":     {status: iSolemnlySwearThisFieldIsValidated},
+			"Deferrability": {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -8,6 +8,7 @@ targets = [
     ('default_cmp_proj_ops.eg.go', 'default_cmp_proj_ops_tmpl.go'),
     ('default_cmp_sel_ops.eg.go', 'default_cmp_sel_ops_tmpl.go'),
     ('distinct.eg.go', 'distinct_tmpl.go'),
+    ('first_last_nth_value.eg.go', 'first_last_nth_value_tmpl.go'),
     ('hash_aggregator.eg.go', 'hash_aggregator_tmpl.go'),
     ('hash_utils.eg.go', 'hash_utils_tmpl.go'),
     ('hashjoiner.eg.go', 'hashjoiner_tmpl.go'),
@@ -15,6 +16,7 @@ targets = [
     ('hashtable_full_default.eg.go', 'hashtable_tmpl.go'),
     ('hashtable_full_deleting.eg.go', 'hashtable_tmpl.go'),
     ('is_null_ops.eg.go', 'is_null_ops_tmpl.go'),
+    ('lead_lag.eg.go', 'lead_lag_tmpl.go'),
     # ('like_ops.eg.go', ...); See `gen_like_ops_rule` below.
     ('mergejoinbase.eg.go', 'mergejoinbase_tmpl.go'),
     ('mergejoiner_exceptall.eg.go', 'mergejoiner_tmpl.go'),
@@ -32,6 +34,7 @@ targets = [
     ('proj_const_right_ops.eg.go', 'proj_const_ops_tmpl.go'),
     ('proj_non_const_ops.eg.go', 'proj_non_const_ops_tmpl.go'),
     ('quicksort.eg.go', 'quicksort_tmpl.go'),
+    ('range_offset_handler.eg.go', 'range_offset_handler_tmpl.go'),
     ('rank.eg.go', 'rank_tmpl.go'),
     ('relative_rank.eg.go', 'relative_rank_tmpl.go'),
     ('row_number.eg.go', 'row_number_tmpl.go'),
@@ -53,6 +53,8 @@ type aggregatorTestCase struct {
 	groupCols      []uint32
 	aggCols        [][]uint32
 	constArguments [][]execinfrapb.Expression
+	// groupingSets, if set, contains the columns of each grouping set.
+	groupingSets [][]uint32
 	// spec will be populated during init().
 	spec           *execinfrapb.AggregatorSpec
 	aggDistinct    []bool
@@ -148,6 +150,11 @@ func (tc *aggregatorTestCase) init() error {
 		GroupCols:    tc.groupCols,
 		Aggregations: aggregations,
 	}
+	for _, cols := range tc.groupingSets {
+		tc.spec.GroupingSets = append(
+			tc.spec.GroupingSets, execinfrapb.AggregatorSpec_GroupingSet{Cols: cols},
+		)
+	}
 	return nil
 }
 
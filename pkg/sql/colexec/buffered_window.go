@@ -0,0 +1,306 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/marusama/semaphore"
+)
+
+// WindowArgs contains the arguments that are shared among the window
+// functions which need to buffer their input partitions.
+type WindowArgs struct {
+	EvalCtx *tree.EvalContext
+	// MainAllocator must be an unlimited allocator. It is used to check
+	// whether the memory usage exceeds MemoryLimit, in which case the buffered
+	// tuples are spilled to disk.
+	MainAllocator *colmem.Allocator
+	MemoryLimit   int64
+	QueueCfg      colcontainer.DiskQueueCfg
+	FdSemaphore   semaphore.Semaphore
+	DiskAcc       *mon.BoundAccount
+	Input         colexecbase.Operator
+	InputTypes    []*types.T
+	// OutputColIdx specifies in which coldata.Vec the operator should put its
+	// output (it must be equal to the number of input columns).
+	OutputColIdx int
+	// PartitionColIdx is the index of the boolean column that indicates the
+	// start of each partition, or tree.NoColumnIdx if there is a single
+	// partition.
+	PartitionColIdx int
+	// PeersColIdx is the index of the boolean column that indicates the start
+	// of each peer group, or tree.NoColumnIdx if the window function doesn't
+	// need the information about the peer groups.
+	PeersColIdx int
+}
+
+// bufferedWindower is the interface of the window functions which need to
+// buffer the whole partition in order to compute their output.
+type bufferedWindower interface {
+	// init is called once the buffer which stores the tuples of the current
+	// partition has been created. The columns of the buffer are the same as
+	// the input columns.
+	init(buffer *spillingBuffer)
+
+	// startNewPartition is called once all tuples of a new partition have
+	// been appended to the buffer and before processBatch is called on the
+	// partition.
+	startNewPartition(ctx context.Context, partitionSize int)
+
+	// processBatch computes the output of the window function for the tuples
+	// of the current partition in the range [startIdx, endIdx) and writes the
+	// results into the output vector starting at outputIdx. processBatch is
+	// called with non-overlapping increasing ranges that cover the whole
+	// partition. It is called within a PerformOperation call on the output
+	// vector.
+	processBatch(ctx context.Context, output coldata.Vec, outputIdx, startIdx, endIdx int)
+}
+
+// newBufferedWindowOperator creates a new Operator that computes the given
+// window function which buffers each partition of its input.
+func newBufferedWindowOperator(
+	args *WindowArgs, windower bufferedWindower, outputColType *types.T,
+) colexecbase.Operator {
+	outputTypes := make([]*types.T, len(args.InputTypes), len(args.InputTypes)+1)
+	copy(outputTypes, args.InputTypes)
+	outputTypes = append(outputTypes, outputColType)
+	// The cache mode is chosen to reuse the cache since all tuples of a
+	// partition are appended to the buffer before they are read.
+	diskQueueCfg := args.QueueCfg
+	diskQueueCfg.CacheMode = colcontainer.DiskQueueCacheModeReuseCache
+	diskQueueCfg.SetDefaultBufferSizeBytesForCacheMode()
+	return &bufferedWindowOp{
+		OneInputNode:    NewOneInputNode(args.Input),
+		allocator:       args.MainAllocator,
+		memoryLimit:     args.MemoryLimit,
+		diskQueueCfg:    diskQueueCfg,
+		fdSemaphore:     args.FdSemaphore,
+		diskAcc:         args.DiskAcc,
+		inputTypes:      args.InputTypes,
+		outputTypes:     outputTypes,
+		outputColIdx:    args.OutputColIdx,
+		partitionColIdx: args.PartitionColIdx,
+		windower:        windower,
+	}
+}
+
+type bufferedWindowState int
+
+const (
+	// windowLoading is the state in which the bufferedWindowOp appends the
+	// tuples of the current partition to the buffer. Once the end of the
+	// partition is reached, the operator transitions to windowProcessing
+	// state.
+	windowLoading bufferedWindowState = iota
+	// windowProcessing is the state in which the bufferedWindowOp computes
+	// the output of the window function for the buffered partition. The output
+	// batch is populated by copying the buffered tuples and computing the
+	// output column. Once the whole partition has been processed, the operator
+	// transitions to windowLoading state (or windowFinished state if the input
+	// has been exhausted).
+	windowProcessing
+	// windowFinished is the state in which the bufferedWindowOp emits the last
+	// output batch (if any), closes the disk resources and emits the
+	// zero-length batch.
+	windowFinished
+)
+
+// bufferedWindowOp is an Operator that buffers each partition of its input in
+// a spillingBuffer and computes the output of a window function on the
+// buffered partition. The output batches can contain tuples from several
+// partitions.
+type bufferedWindowOp struct {
+	OneInputNode
+	closerHelper
+
+	allocator       *colmem.Allocator
+	memoryLimit     int64
+	diskQueueCfg    colcontainer.DiskQueueCfg
+	fdSemaphore     semaphore.Semaphore
+	diskAcc         *mon.BoundAccount
+	inputTypes      []*types.T
+	outputTypes     []*types.T
+	outputColIdx    int
+	partitionColIdx int
+
+	state    bufferedWindowState
+	windower bufferedWindower
+	buffer   *spillingBuffer
+
+	// currentBatch is the last batch read from the input, and nextIdx is the
+	// index of the first tuple in currentBatch which hasn't been appended to
+	// the buffer yet.
+	currentBatch coldata.Batch
+	nextIdx      int
+	// inputDone indicates whether the input has been fully consumed.
+	inputDone bool
+
+	// partitionStarted indicates whether the windower has been notified about
+	// the partition stored in the buffer.
+	partitionStarted bool
+	// nextOutputIdx is the index of the next tuple of the current partition
+	// for which the output should be computed.
+	nextOutputIdx int
+
+	output coldata.Batch
+}
+
+var _ closableOperator = &bufferedWindowOp{}
+
+func (b *bufferedWindowOp) Init() {
+	b.Input().Init()
+	b.state = windowLoading
+	colIdxs := make([]int, len(b.inputTypes))
+	for i := range colIdxs {
+		colIdxs[i] = i
+	}
+	b.buffer = newSpillingBuffer(
+		&NewSpillingQueueArgs{
+			UnlimitedAllocator: b.allocator,
+			Types:              b.inputTypes,
+			MemoryLimit:        b.memoryLimit,
+			DiskQueueCfg:       b.diskQueueCfg,
+			FDSemaphore:        b.fdSemaphore,
+			DiskAcc:            b.diskAcc,
+		}, colIdxs,
+	)
+	b.windower.init(b.buffer)
+	b.output = b.allocator.NewMemBatchWithFixedCapacity(b.outputTypes, coldata.BatchSize())
+}
+
+func (b *bufferedWindowOp) Next(ctx context.Context) coldata.Batch {
+	b.output.ResetInternalBatch()
+	outputLen := 0
+	for {
+		switch b.state {
+		case windowLoading:
+			if b.currentBatch == nil || b.nextIdx >= b.currentBatch.Length() {
+				b.currentBatch = b.Input().Next(ctx)
+				b.nextIdx = 0
+				if b.currentBatch.Length() == 0 {
+					b.inputDone = true
+					if b.buffer.length > 0 {
+						b.state = windowProcessing
+					} else {
+						b.state = windowFinished
+					}
+					continue
+				}
+			}
+			// Find the end of the current partition within the batch.
+			n := b.currentBatch.Length()
+			endIdx := n
+			if b.partitionColIdx != tree.NoColumnIdx {
+				partitionCol := b.currentBatch.ColVec(b.partitionColIdx).Bool()
+				sel := b.currentBatch.Selection()
+				for i := b.nextIdx; i < n; i++ {
+					idx := i
+					if sel != nil {
+						idx = sel[i]
+					}
+					// The first tuple of the batch only starts a new partition if
+					// the buffer already contains some tuples.
+					if partitionCol[idx] && (i > b.nextIdx || b.buffer.length > 0) {
+						endIdx = i
+						b.state = windowProcessing
+						break
+					}
+				}
+			}
+			b.buffer.appendTuples(ctx, b.currentBatch, b.nextIdx, endIdx)
+			b.nextIdx = endIdx
+
+		case windowProcessing:
+			if !b.partitionStarted {
+				b.buffer.finishAppending(ctx)
+				b.windower.startNewPartition(ctx, b.buffer.length)
+				b.partitionStarted = true
+				b.nextOutputIdx = 0
+			}
+			// First, we copy over the buffered tuples.
+			startIdx, startOutputLen := b.nextOutputIdx, outputLen
+			b.allocator.PerformOperation(b.output.ColVecs()[:len(b.inputTypes)], func() {
+				for outputLen < coldata.BatchSize() && b.nextOutputIdx < b.buffer.length {
+					batch, batchIdx := b.buffer.getBatchWithTuple(ctx, b.nextOutputIdx)
+					toCopy := batch.Length() - batchIdx
+					if remaining := coldata.BatchSize() - outputLen; toCopy > remaining {
+						toCopy = remaining
+					}
+					for colIdx, vec := range b.output.ColVecs()[:len(b.inputTypes)] {
+						vec.Copy(
+							coldata.CopySliceArgs{
+								SliceArgs: coldata.SliceArgs{
+									Src:         batch.ColVec(colIdx),
+									DestIdx:     outputLen,
+									SrcStartIdx: batchIdx,
+									SrcEndIdx:   batchIdx + toCopy,
+								},
+							},
+						)
+					}
+					outputLen += toCopy
+					b.nextOutputIdx += toCopy
+				}
+			})
+			// Now we can compute the output column.
+			outputVec := b.output.ColVec(b.outputColIdx)
+			b.allocator.PerformOperation([]coldata.Vec{outputVec}, func() {
+				b.windower.processBatch(ctx, outputVec, startOutputLen, startIdx, b.nextOutputIdx)
+			})
+			if b.nextOutputIdx == b.buffer.length {
+				// The whole partition has been processed.
+				b.buffer.reset(ctx)
+				b.partitionStarted = false
+				if b.inputDone {
+					b.state = windowFinished
+				} else {
+					b.state = windowLoading
+				}
+			}
+			if outputLen == coldata.BatchSize() {
+				b.output.SetLength(outputLen)
+				return b.output
+			}
+
+		case windowFinished:
+			if outputLen > 0 {
+				b.output.SetLength(outputLen)
+				return b.output
+			}
+			if err := b.Close(ctx); err != nil {
+				colexecerror.InternalError(err)
+			}
+			return coldata.ZeroBatch
+
+		default:
+			colexecerror.InternalError(errors.AssertionFailedf("window operator in unhandled state"))
+			// This code is unreachable, but the compiler cannot infer that.
+			return nil
+		}
+	}
+}
+
+func (b *bufferedWindowOp) Close(ctx context.Context) error {
+	if !b.close() || b.buffer == nil {
+		return nil
+	}
+	return b.buffer.close(ctx)
+}
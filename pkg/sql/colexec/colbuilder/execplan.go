@@ -145,6 +145,10 @@ func (r *opResult) resetToState(ctx context.Context, arg colexec.NewColOperatorR
 }
 
 func needHashAggregator(aggSpec *execinfrapb.AggregatorSpec) (bool, error) {
+	if len(aggSpec.GroupingSets) > 0 {
+		// Only the hash aggregator supports multiple grouping sets.
+		return true, nil
+	}
 	var groupCols, orderedCols util.FastIntSet
 	for _, col := range aggSpec.OrderedGroupCols {
 		orderedCols.Add(int(col))
@@ -196,6 +200,16 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 		}
 		return nil
 
+	case spec.Core.JoinReader != nil:
+		jr := spec.Core.JoinReader
+		if len(jr.LookupColumns) == 0 {
+			if jr.Type != descpb.InnerJoin || !jr.OnExpr.Empty() {
+				return errors.Newf("index join with ON expression or non-inner join type is not supported")
+			}
+			return nil
+		}
+		return colfetcher.IsLookupJoinSupported(jr)
+
 	case spec.Core.Filterer != nil:
 		return nil
 
@@ -205,6 +219,9 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 				return errors.Newf("filtering aggregation not supported")
 			}
 		}
+		if len(spec.Core.Aggregator.GroupingSets) > 0 && len(spec.Core.Aggregator.Aggregations) == 0 {
+			return errors.Newf("grouping sets without aggregations not supported")
+		}
 		return nil
 
 	case spec.Core.Distinct != nil:
@@ -235,26 +252,21 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 		return nil
 
 	case spec.Core.Windower != nil:
-		for _, wf := range spec.Core.Windower.WindowFns {
-			if wf.Frame != nil {
-				frame, err := wf.Frame.ConvertToAST()
-				if err != nil {
-					return err
-				}
-				if !frame.IsDefaultFrame() {
-					return errors.Newf("window functions with non-default window frames are not supported")
-				}
-			}
+		for i := range spec.Core.Windower.WindowFns {
+			wf := &spec.Core.Windower.WindowFns[i]
 			if wf.FilterColIdx != tree.NoColumnIdx {
 				return errors.Newf("window functions with FILTER clause are not supported")
 			}
 			if wf.Func.AggregateFunc != nil {
-				return errors.Newf("aggregate functions used as window functions are not supported")
-			}
-
-			if _, supported := SupportedWindowFns[*wf.Func.WindowFunc]; !supported {
+				if !colexecagg.IsAggOptimized(*wf.Func.AggregateFunc) {
+					return errors.Newf("aggregate function %s used as window function is not supported", wf.Func.AggregateFunc)
+				}
+			} else if _, supported := SupportedWindowFns[*wf.Func.WindowFunc]; !supported {
 				return errors.Newf("window function %s is not supported", wf.String())
 			}
+			if !colexec.IsWindowFrameSupported(wf.Frame, &wf.Ordering, spec.Input[0].ColumnTypes) {
+				return errors.Newf("window frame %s is not supported", wf.Frame)
+			}
 		}
 		return nil
 
@@ -272,10 +284,12 @@ var (
 	errBackfillerWrap                 = errors.New("core.Backfiller is not supported (not an execinfra.RowSource)")
 	errReadImportWrap                 = errors.New("core.ReadImport is not supported (not an execinfra.RowSource)")
 	errCSVWriterWrap                  = errors.New("core.CSVWriter is not supported (not an execinfra.RowSource)")
+	errParquetWriterWrap              = errors.New("core.ParquetWriter is not supported (not an execinfra.RowSource)")
 	errSamplerWrap                    = errors.New("core.Sampler is not supported (not an execinfra.RowSource)")
 	errSampleAggregatorWrap           = errors.New("core.SampleAggregator is not supported (not an execinfra.RowSource)")
 	errBackupDataWrap                 = errors.New("core.BackupData is not supported (not an execinfra.RowSource)")
 	errSplitAndScatterWrap            = errors.New("core.SplitAndScatter is not supported (not an execinfra.RowSource)")
+	errTTLWrap                        = errors.New("core.TTL is not supported (not an execinfra.RowSource)")
 	errExperimentalWrappingProhibited = errors.New("wrapping for non-JoinReader and non-LocalPlanNode cores is prohibited in vectorize=experimental_always")
 )
 
@@ -300,6 +314,8 @@ func canWrap(mode sessiondatapb.VectorizeExecMode, spec *execinfrapb.ProcessorSp
 		return errReadImportWrap
 	case spec.Core.CSVWriter != nil:
 		return errCSVWriterWrap
+	case spec.Core.ParquetWriter != nil:
+		return errParquetWriterWrap
 	case spec.Core.Sampler != nil:
 		return errSamplerWrap
 	case spec.Core.SampleAggregator != nil:
@@ -335,6 +351,8 @@ func canWrap(mode sessiondatapb.VectorizeExecMode, spec *execinfrapb.ProcessorSp
 	case spec.Core.SplitAndScatter != nil:
 		return errSplitAndScatterWrap
 	case spec.Core.RestoreData != nil:
+	case spec.Core.TTL != nil:
+		return errTTLWrap
 	default:
 		return errors.AssertionFailedf("unexpected processor core %q", spec.Core)
 	}
@@ -733,6 +751,61 @@ func NewColOperator(
 			result.ColumnTypes = scanOp.ResultTypes
 			result.ToClose = append(result.ToClose, scanOp)
 
+		case core.JoinReader != nil:
+			if err := checkNumIn(inputs, 1); err != nil {
+				return r, err
+			}
+			inputTypes := make([]*types.T, len(spec.Input[0].ColumnTypes))
+			copy(inputTypes, spec.Input[0].ColumnTypes)
+			var joinOp interface {
+				colexecbase.Operator
+				execinfra.KVReader
+				execinfrapb.MetadataSource
+				execinfra.Releasable
+				colexecbase.Closer
+			}
+			if len(core.JoinReader.LookupColumns) == 0 {
+				indexJoinAllocator := colmem.NewAllocator(
+					ctx, result.createBufferingUnlimitedMemAccount(
+						ctx, flowCtx, fmt.Sprintf("index-join-%d", spec.ProcessorID),
+					), factory,
+				)
+				indexJoinOp, err := colfetcher.NewColIndexJoin(
+					ctx, indexJoinAllocator, flowCtx, evalCtx, inputs[0], core.JoinReader, post, inputTypes,
+				)
+				if err != nil {
+					return r, err
+				}
+				joinOp = indexJoinOp
+				result.ColumnTypes = indexJoinOp.ResultTypes
+			} else {
+				lookupJoinAllocator := colmem.NewAllocator(
+					ctx, result.createBufferingUnlimitedMemAccount(
+						ctx, flowCtx, fmt.Sprintf("lookup-join-%d", spec.ProcessorID),
+					), factory,
+				)
+				lookupJoinOp, err := colfetcher.NewColLookupJoin(
+					ctx, lookupJoinAllocator, flowCtx, evalCtx, inputs[0], core.JoinReader, post, inputTypes,
+				)
+				if err != nil {
+					return r, err
+				}
+				joinOp = lookupJoinOp
+				result.ColumnTypes = lookupJoinOp.ResultTypes
+			}
+			result.Op = joinOp
+			result.KVReader = joinOp
+			result.MetadataSources = append(result.MetadataSources, joinOp)
+			result.Releasables = append(result.Releasables, joinOp)
+			result.ToClose = append(result.ToClose, joinOp)
+			if !core.JoinReader.OnExpr.Empty() {
+				if err := result.planAndMaybeWrapFilter(
+					ctx, flowCtx, evalCtx, args, core.JoinReader.OnExpr, factory,
+				); err != nil {
+					return r, err
+				}
+			}
+
 		case core.Filterer != nil:
 			if err := checkNumIn(inputs, 1); err != nil {
 				return r, err
@@ -793,6 +866,11 @@ func NewColOperator(
 			if err != nil {
 				return r, err
 			}
+			if len(aggSpec.GroupingSets) > 0 {
+				// The last output column contains the index of the grouping
+				// set of each row.
+				newAggArgs.OutputTypes = append(newAggArgs.OutputTypes, types.Int)
+			}
 			result.ColumnTypes = newAggArgs.OutputTypes
 
 			if needHash {
@@ -802,10 +880,13 @@ func NewColOperator(
 				// a disk-backed one here.
 				hashAggregatorMemMonitorName := fmt.Sprintf("hash-aggregator-%d", spec.ProcessorID)
 				diskSpillingDisabled := !colexec.HashAggregationDiskSpillingEnabled.Get(&flowCtx.Cfg.Settings.SV)
-				if diskSpillingDisabled {
-					// The disk spilling is disabled by the cluster setting, so
-					// we give an unlimited memory account to the in-memory
-					// hash aggregator and don't set up the disk spiller.
+				if diskSpillingDisabled || len(aggSpec.GroupingSets) > 0 {
+					// The disk spilling is disabled by the cluster setting (or
+					// the external hash aggregator cannot be used because it
+					// partitions the input on all grouping columns, which is
+					// incorrect for multiple grouping sets), so we give an
+					// unlimited memory account to the in-memory hash
+					// aggregator and don't set up the disk spiller.
 					hashAggregatorUnlimitedMemAccount := result.createBufferingUnlimitedMemAccount(
 						ctx, flowCtx, hashAggregatorMemMonitorName,
 					)
@@ -1136,7 +1217,6 @@ func NewColOperator(
 				copy(typs, result.ColumnTypes)
 				tempColOffset, partitionColIdx := uint32(0), tree.NoColumnIdx
 				peersColIdx := tree.NoColumnIdx
-				windowFn := *wf.Func.WindowFunc
 				if len(core.Windower.PartitionBy) > 0 {
 					// TODO(yuzefovich): add support for hashing partitioner
 					// (probably by leveraging hash routers once we can
@@ -1170,7 +1250,7 @@ func NewColOperator(
 				if err != nil {
 					return r, err
 				}
-				if windowFnNeedsPeersInfo(*wf.Func.WindowFunc) {
+				if windowFnNeedsPeersInfo(&wf) {
 					peersColIdx = int(wf.OutputColIdx + tempColOffset)
 					input, err = colexec.NewWindowPeerGrouper(
 						streamingAllocator, input, typs, wf.Ordering.Columns,
@@ -1182,38 +1262,101 @@ func NewColOperator(
 					typs[len(typs)-1] = types.Bool
 				}
 
+				argTypes := make([]*types.T, len(wf.ArgsIdxs))
+				for i, idx := range wf.ArgsIdxs {
+					argTypes[i] = typs[idx]
+				}
+				_, returnType, err := execinfrapb.GetWindowFunctionInfo(wf.Func, argTypes...)
+				if err != nil {
+					return r, err
+				}
 				outputIdx := int(wf.OutputColIdx + tempColOffset)
-				switch windowFn {
-				case execinfrapb.WindowerSpec_ROW_NUMBER:
-					result.Op = colexec.NewRowNumberOperator(streamingAllocator, input, outputIdx, partitionColIdx)
-				case execinfrapb.WindowerSpec_RANK, execinfrapb.WindowerSpec_DENSE_RANK:
-					result.Op, err = colexec.NewRankOperator(
-						streamingAllocator, input, windowFn, wf.Ordering.Columns,
-						outputIdx, partitionColIdx, peersColIdx,
-					)
-				case execinfrapb.WindowerSpec_PERCENT_RANK, execinfrapb.WindowerSpec_CUME_DIST:
-					// We are using an unlimited memory monitor here because
-					// relative rank operators themselves are responsible for
-					// making sure that we stay within the memory limit, and
-					// they will fall back to disk if necessary.
-					memAccName := memMonitorsPrefix + "relative-rank"
-					unlimitedAllocator := colmem.NewAllocator(
-						ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, memAccName), factory,
-					)
-					diskAcc := result.createDiskAccount(ctx, flowCtx, memAccName)
-					result.Op, err = colexec.NewRelativeRankOperator(
-						unlimitedAllocator, execinfra.GetWorkMemLimit(flowCtx.Cfg), args.DiskQueueCfg,
-						args.FDSemaphore, input, typs, windowFn, wf.Ordering.Columns,
-						outputIdx, partitionColIdx, peersColIdx, diskAcc,
+				// newWindowArgs returns the arguments for the window functions
+				// that buffer their partitions. We are using an unlimited
+				// memory monitor because these operators themselves are
+				// responsible for making sure that we stay within the memory
+				// limit, and they will fall back to disk if necessary.
+				newWindowArgs := func(memAccName string) *colexec.WindowArgs {
+					memAccName = memMonitorsPrefix + memAccName
+					memoryLimit := execinfra.GetWorkMemLimit(flowCtx.Cfg)
+					if flowCtx.Cfg.TestingKnobs.ForceDiskSpill {
+						memoryLimit = 1
+					}
+					return &colexec.WindowArgs{
+						EvalCtx: evalCtx,
+						MainAllocator: colmem.NewAllocator(
+							ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, memAccName), factory,
+						),
+						MemoryLimit:     memoryLimit,
+						QueueCfg:        args.DiskQueueCfg,
+						FdSemaphore:     args.FDSemaphore,
+						DiskAcc:         result.createDiskAccount(ctx, flowCtx, memAccName),
+						Input:           input,
+						InputTypes:      typs,
+						OutputColIdx:    outputIdx,
+						PartitionColIdx: partitionColIdx,
+						PeersColIdx:     peersColIdx,
+					}
+				}
+				if wf.Func.AggregateFunc != nil {
+					result.Op, err = colexec.NewWindowAggregatorOperator(
+						newWindowArgs("window-aggregator"), *wf.Func.AggregateFunc,
+						wf.Frame, &wf.Ordering, wf.ArgsIdxs, returnType,
 					)
-					// NewRelativeRankOperator sometimes returns a constOp when
-					// there are no ordering columns, so we check that the
-					// returned operator is a Closer.
-					if c, ok := result.Op.(colexecbase.Closer); ok {
-						result.ToClose = append(result.ToClose, c)
+				} else {
+					windowFn := *wf.Func.WindowFunc
+					switch windowFn {
+					case execinfrapb.WindowerSpec_ROW_NUMBER:
+						result.Op = colexec.NewRowNumberOperator(streamingAllocator, input, outputIdx, partitionColIdx)
+					case execinfrapb.WindowerSpec_RANK, execinfrapb.WindowerSpec_DENSE_RANK:
+						result.Op, err = colexec.NewRankOperator(
+							streamingAllocator, input, windowFn, wf.Ordering.Columns,
+							outputIdx, partitionColIdx, peersColIdx,
+						)
+					case execinfrapb.WindowerSpec_PERCENT_RANK, execinfrapb.WindowerSpec_CUME_DIST:
+						// We are using an unlimited memory monitor here because
+						// relative rank operators themselves are responsible for
+						// making sure that we stay within the memory limit, and
+						// they will fall back to disk if necessary.
+						memAccName := memMonitorsPrefix + "relative-rank"
+						unlimitedAllocator := colmem.NewAllocator(
+							ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, memAccName), factory,
+						)
+						diskAcc := result.createDiskAccount(ctx, flowCtx, memAccName)
+						result.Op, err = colexec.NewRelativeRankOperator(
+							unlimitedAllocator, execinfra.GetWorkMemLimit(flowCtx.Cfg), args.DiskQueueCfg,
+							args.FDSemaphore, input, typs, windowFn, wf.Ordering.Columns,
+							outputIdx, partitionColIdx, peersColIdx, diskAcc,
+						)
+					case execinfrapb.WindowerSpec_LAG:
+						result.Op, err = colexec.NewLagOperator(newWindowArgs("lag"), wf.ArgsIdxs)
+					case execinfrapb.WindowerSpec_LEAD:
+						result.Op, err = colexec.NewLeadOperator(newWindowArgs("lead"), wf.ArgsIdxs)
+					case execinfrapb.WindowerSpec_FIRST_VALUE:
+						result.Op, err = colexec.NewFirstValueOperator(
+							newWindowArgs("first-value"), wf.Frame, &wf.Ordering, wf.ArgsIdxs,
+						)
+					case execinfrapb.WindowerSpec_LAST_VALUE:
+						result.Op, err = colexec.NewLastValueOperator(
+							newWindowArgs("last-value"), wf.Frame, &wf.Ordering, wf.ArgsIdxs,
+						)
+					case execinfrapb.WindowerSpec_NTH_VALUE:
+						result.Op, err = colexec.NewNthValueOperator(
+							newWindowArgs("nth-value"), wf.Frame, &wf.Ordering, wf.ArgsIdxs,
+						)
+					default:
+						return r, errors.AssertionFailedf("window function %s is not supported", wf.String())
 					}
-				default:
-					return r, errors.AssertionFailedf("window function %s is not supported", wf.String())
+				}
+				if err != nil {
+					return r, err
+				}
+				// Buffering window operators need to be closed. Note that
+				// NewRelativeRankOperator sometimes returns a constOp when
+				// there are no ordering columns, so we check that the
+				// returned operator is a Closer.
+				if c, ok := result.Op.(colexecbase.Closer); ok {
+					result.ToClose = append(result.ToClose, c)
 				}
 
 				if tempColOffset > 0 {
@@ -1228,10 +1371,6 @@ func NewColOperator(
 					result.Op = colexec.NewSimpleProjectOp(result.Op, int(wf.OutputColIdx+tempColOffset), projection)
 				}
 
-				_, returnType, err := execinfrapb.GetWindowFunctionInfo(wf.Func, []*types.T{}...)
-				if err != nil {
-					return r, err
-				}
 				result.ColumnTypes = appendOneType(result.ColumnTypes, returnType)
 				input = result.Op
 			}
@@ -11,6 +11,7 @@
 package colbuilder
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/errors"
@@ -24,6 +25,11 @@ var SupportedWindowFns = map[execinfrapb.WindowerSpec_WindowFunc]struct{}{
 	execinfrapb.WindowerSpec_DENSE_RANK:   {},
 	execinfrapb.WindowerSpec_PERCENT_RANK: {},
 	execinfrapb.WindowerSpec_CUME_DIST:    {},
+	execinfrapb.WindowerSpec_LAG:          {},
+	execinfrapb.WindowerSpec_LEAD:         {},
+	execinfrapb.WindowerSpec_FIRST_VALUE:  {},
+	execinfrapb.WindowerSpec_LAST_VALUE:   {},
+	execinfrapb.WindowerSpec_NTH_VALUE:    {},
 }
 
 // windowFnNeedsPeersInfo returns whether a window function pays attention to
@@ -31,11 +37,19 @@ var SupportedWindowFns = map[execinfrapb.WindowerSpec_WindowFunc]struct{}{
 // same partition - from PARTITION BY clause - that are not distinct on the
 // columns in ORDER BY clause). For most window functions, the result of
 // computation should be the same for "peers", so most window functions do need
-// this information.
-func windowFnNeedsPeersInfo(windowFn execinfrapb.WindowerSpec_WindowFunc) bool {
-	switch windowFn {
-	case execinfrapb.WindowerSpec_ROW_NUMBER:
-		// row_number doesn't pay attention to the concept of "peers."
+// this information. The functions that are computed over the window frame (as
+// well as the aggregate functions) need it only if the frame does.
+func windowFnNeedsPeersInfo(wf *execinfrapb.WindowerSpec_WindowFn) bool {
+	if wf.Func.AggregateFunc != nil {
+		return colexec.WindowFrameNeedsPeersInfo(wf.Frame)
+	}
+	switch windowFn := *wf.Func.WindowFunc; windowFn {
+	case
+		execinfrapb.WindowerSpec_ROW_NUMBER,
+		execinfrapb.WindowerSpec_LAG,
+		execinfrapb.WindowerSpec_LEAD:
+		// row_number, lag and lead don't pay attention to the concept of
+		// "peers."
 		return false
 	case
 		execinfrapb.WindowerSpec_RANK,
@@ -43,6 +57,11 @@ func windowFnNeedsPeersInfo(windowFn execinfrapb.WindowerSpec_WindowFunc) bool {
 		execinfrapb.WindowerSpec_PERCENT_RANK,
 		execinfrapb.WindowerSpec_CUME_DIST:
 		return true
+	case
+		execinfrapb.WindowerSpec_FIRST_VALUE,
+		execinfrapb.WindowerSpec_LAST_VALUE,
+		execinfrapb.WindowerSpec_NTH_VALUE:
+		return colexec.WindowFrameNeedsPeersInfo(wf.Frame)
 	default:
 		colexecerror.InternalError(errors.AssertionFailedf("window function %s is not supported", windowFn.String()))
 		// This code is unreachable, but the compiler cannot infer that.
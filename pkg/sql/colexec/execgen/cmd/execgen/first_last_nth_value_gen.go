@@ -0,0 +1,44 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package main
+
+import (
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+const firstLastNthValueTmpl = "pkg/sql/colexec/first_last_nth_value_tmpl.go"
+
+func genFirstLastNthValue(inputFileContents string, wr io.Writer) error {
+	r := strings.NewReplacer(
+		"_CANONICAL_TYPE_FAMILY", "{{.CanonicalTypeFamilyStr}}",
+		"_TYPE_WIDTH", typeWidthReplacement,
+		"_TYPE", "{{.VecMethod}}",
+		"TemplateType", "{{.VecMethod}}",
+	)
+	s := r.Replace(inputFileContents)
+
+	s = replaceManipulationFuncs(s)
+
+	tmpl, err := template.New("first_last_nth_value").Parse(s)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(wr, sameTypeComparisonOpToOverloads[tree.EQ])
+}
+
+func init() {
+	registerGenerator(genFirstLastNthValue, "first_last_nth_value.eg.go", firstLastNthValueTmpl)
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package main
+
+import (
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+type rangeOffsetHandlerTmplInfo struct {
+	CanonicalTypeFamilyStr string
+	Widths                 []*rangeOffsetHandlerWidthTmplInfo
+}
+
+type rangeOffsetHandlerWidthTmplInfo struct {
+	Width        int32
+	VecMethod    string
+	GoType       string
+	OffsetGoType string
+	BoundGoType  string
+
+	plusOverload  *twoArgsResolvedOverload
+	minusOverload *twoArgsResolvedOverload
+	cmpOverload   *twoArgsResolvedOverload
+}
+
+func (i rangeOffsetHandlerWidthTmplInfo) AssignAdd(targetElem, leftElem, rightElem string) string {
+	return i.plusOverload.Right.Assign(targetElem, leftElem, rightElem, "", "", "")
+}
+
+func (i rangeOffsetHandlerWidthTmplInfo) AssignSub(targetElem, leftElem, rightElem string) string {
+	return i.minusOverload.Right.Assign(targetElem, leftElem, rightElem, "", "", "")
+}
+
+func (i rangeOffsetHandlerWidthTmplInfo) Compare(targetElem, leftElem, rightElem string) string {
+	return i.cmpOverload.Right.Compare(targetElem, leftElem, rightElem, "", "")
+}
+
+var (
+	_ = rangeOffsetHandlerWidthTmplInfo{}.AssignAdd
+	_ = rangeOffsetHandlerWidthTmplInfo{}.AssignSub
+	_ = rangeOffsetHandlerWidthTmplInfo{}.Compare
+)
+
+// canonicalWidth returns the width of t as it is used by the overloads (only
+// integers have different widths).
+func canonicalWidth(t *types.T) int32 {
+	if t.Family() == types.IntFamily && t.Width() != 64 {
+		return t.Width()
+	}
+	return anyWidth
+}
+
+// findTwoArgsResolvedOverload returns the resolved overload that matches the
+// given predicate and takes in the arguments of the given types.
+func findTwoArgsResolvedOverload(
+	matches func(*overloadBase) bool, left, right *types.T,
+) *twoArgsResolvedOverload {
+	leftFamily := typeconv.TypeFamilyToCanonicalTypeFamily(left.Family())
+	rightFamily := typeconv.TypeFamilyToCanonicalTypeFamily(right.Family())
+	for _, o := range resolvedBinCmpOpsOverloads {
+		if matches(o.overloadBase) &&
+			o.Left.argTypeOverload.CanonicalTypeFamily == leftFamily && o.Left.Width == canonicalWidth(left) &&
+			o.Right.lastArgTypeOverload.CanonicalTypeFamily == rightFamily && o.Right.Width == canonicalWidth(right) {
+			return o
+		}
+	}
+	colexecerror.InternalError(errors.AssertionFailedf(
+		"unexpectedly didn't find an overload for %s and %s", left, right,
+	))
+	// This code is unreachable, but the compiler cannot infer that.
+	return nil
+}
+
+func findBinOpOverload(binOp tree.BinaryOperator, left, right *types.T) *twoArgsResolvedOverload {
+	return findTwoArgsResolvedOverload(func(o *overloadBase) bool {
+		return o.kind == binaryOverload && o.BinOp == binOp
+	}, left, right)
+}
+
+func findCmpOpOverload(
+	cmpOp tree.ComparisonOperator, left, right *types.T,
+) *twoArgsResolvedOverload {
+	return findTwoArgsResolvedOverload(func(o *overloadBase) bool {
+		return o.kind == comparisonOverload && o.CmpOp == cmpOp
+	}, left, right)
+}
+
+// rangeOffsetHandlerOrderingTypes contains the types of the ordering column
+// for which RANGE mode with offsets is supported by the vectorized engine
+// along with the types of the corresponding offsets. Note that the integer
+// offsets are always of types.Int since that's the type of the DInt datum.
+var rangeOffsetHandlerOrderingTypes = [][2]*types.T{
+	{types.Int2, types.Int},
+	{types.Int4, types.Int},
+	{types.Int, types.Int},
+	{types.Float, types.Float},
+	{types.Decimal, types.Decimal},
+	{types.Interval, types.Interval},
+	{types.TimestampTZ, types.Interval},
+}
+
+const rangeOffsetHandlerTmpl = "pkg/sql/colexec/range_offset_handler_tmpl.go"
+
+func genRangeOffsetHandler(inputFileContents string, wr io.Writer) error {
+	r := strings.NewReplacer(
+		"_CANONICAL_TYPE_FAMILY", "{{.CanonicalTypeFamilyStr}}",
+		"_TYPE_WIDTH", typeWidthReplacement,
+		"_OFFSET_GOTYPE", "{{.OffsetGoType}}",
+		"_BOUND_GOTYPE", "{{.BoundGoType}}",
+		"_GOTYPE", "{{.GoType}}",
+		"_TYPE", "{{.VecMethod}}",
+		"TemplateType", "{{.VecMethod}}",
+	)
+	s := r.Replace(inputFileContents)
+
+	assignAddRe := makeFunctionRegex("_ASSIGN_ADD", 3)
+	s = assignAddRe.ReplaceAllString(s, makeTemplateFunctionCall("AssignAdd", 3))
+	assignSubRe := makeFunctionRegex("_ASSIGN_SUB", 3)
+	s = assignSubRe.ReplaceAllString(s, makeTemplateFunctionCall("AssignSub", 3))
+	compareRe := makeFunctionRegex("_COMPARE", 3)
+	s = compareRe.ReplaceAllString(s, makeTemplateFunctionCall("Compare", 3))
+
+	tmpl, err := template.New("range_offset_handler").Parse(s)
+	if err != nil {
+		return err
+	}
+
+	var tmplInfos []*rangeOffsetHandlerTmplInfo
+	for _, typs := range rangeOffsetHandlerOrderingTypes {
+		ordType, offsetType := typs[0], typs[1]
+		canonicalTypeFamily := typeconv.TypeFamilyToCanonicalTypeFamily(ordType.Family())
+		canonicalTypeFamilyStr := toString(canonicalTypeFamily)
+		var info *rangeOffsetHandlerTmplInfo
+		for _, i := range tmplInfos {
+			if i.CanonicalTypeFamilyStr == canonicalTypeFamilyStr {
+				info = i
+				break
+			}
+		}
+		if info == nil {
+			info = &rangeOffsetHandlerTmplInfo{CanonicalTypeFamilyStr: canonicalTypeFamilyStr}
+			tmplInfos = append(tmplInfos, info)
+		}
+		width := canonicalWidth(ordType)
+		plusOverload := findBinOpOverload(tree.Plus, ordType, offsetType)
+		info.Widths = append(info.Widths, &rangeOffsetHandlerWidthTmplInfo{
+			Width:         width,
+			VecMethod:     toVecMethod(canonicalTypeFamily, width),
+			GoType:        toPhysicalRepresentation(canonicalTypeFamily, width),
+			OffsetGoType:  plusOverload.Right.GoType,
+			BoundGoType:   plusOverload.Right.RetGoType,
+			plusOverload:  plusOverload,
+			minusOverload: findBinOpOverload(tree.Minus, ordType, offsetType),
+			cmpOverload:   findCmpOpOverload(tree.LT, ordType, plusOverload.Right.RetType),
+		})
+	}
+	return tmpl.Execute(wr, tmplInfos)
+}
+
+func init() {
+	registerGenerator(genRangeOffsetHandler, "range_offset_handler.eg.go", rangeOffsetHandlerTmpl)
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for first_last_nth_value.eg.go. It's
+// formatted in a special way, so it's both valid Go and a valid text/template
+// input. This permits editing this file with editor support.
+//
+// */}}
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/execgen"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// Workaround for bazel auto-generated code. goimports does not automatically
+// pick up the right packages when run within the bazel sandbox.
+var (
+	_ tree.AggType
+)
+
+// {{/*
+
+// Declarations to make the template compile properly.
+
+// _CANONICAL_TYPE_FAMILY is the template variable.
+const _CANONICAL_TYPE_FAMILY = types.UnknownFamily
+
+// _TYPE_WIDTH is the template variable.
+const _TYPE_WIDTH = 0
+
+// */}}
+
+// NewFirstValueOperator creates a new Operator that computes the FIRST_VALUE
+// window function. argIdxs must contain the index of the single argument.
+func NewFirstValueOperator(
+	args *WindowArgs,
+	frame *execinfrapb.WindowerSpec_Frame,
+	ordering *execinfrapb.Ordering,
+	argIdxs []uint32,
+) (colexecbase.Operator, error) {
+	return newFirstLastNthValueOperator(args, frame, ordering, argIdxs, firstValueFn)
+}
+
+// NewLastValueOperator creates a new Operator that computes the LAST_VALUE
+// window function. argIdxs must contain the index of the single argument.
+func NewLastValueOperator(
+	args *WindowArgs,
+	frame *execinfrapb.WindowerSpec_Frame,
+	ordering *execinfrapb.Ordering,
+	argIdxs []uint32,
+) (colexecbase.Operator, error) {
+	return newFirstLastNthValueOperator(args, frame, ordering, argIdxs, lastValueFn)
+}
+
+// NewNthValueOperator creates a new Operator that computes the NTH_VALUE
+// window function. argIdxs must contain the indices of the value and of the
+// (one-based) position of the row within the window frame.
+func NewNthValueOperator(
+	args *WindowArgs,
+	frame *execinfrapb.WindowerSpec_Frame,
+	ordering *execinfrapb.Ordering,
+	argIdxs []uint32,
+) (colexecbase.Operator, error) {
+	return newFirstLastNthValueOperator(args, frame, ordering, argIdxs, nthValueFn)
+}
+
+type firstLastNthValueFn int
+
+const (
+	firstValueFn firstLastNthValueFn = iota
+	lastValueFn
+	nthValueFn
+)
+
+var errInvalidArgumentForNthValue = pgerror.Newf(
+	pgcode.InvalidParameterValue, "argument of nth_value() must be greater than zero")
+
+func newFirstLastNthValueOperator(
+	args *WindowArgs,
+	frame *execinfrapb.WindowerSpec_Frame,
+	ordering *execinfrapb.Ordering,
+	argIdxs []uint32,
+	fn firstLastNthValueFn,
+) (colexecbase.Operator, error) {
+	expectedNumArgs := 1
+	if fn == nthValueFn {
+		expectedNumArgs = 2
+	}
+	if len(argIdxs) != expectedNumArgs {
+		return nil, errors.AssertionFailedf(
+			"unexpected number of arguments for window function: %d", len(argIdxs))
+	}
+	base := firstLastNthValueBase{
+		framer: newWindowFramer(frame, ordering, args.InputTypes, args.PeersColIdx),
+		fn:     fn,
+		argIdx: int(argIdxs[0]),
+		nthIdx: tree.NoColumnIdx,
+	}
+	if fn == nthValueFn {
+		base.nthIdx = int(argIdxs[1])
+	}
+	argType := args.InputTypes[argIdxs[0]]
+	switch typeconv.TypeFamilyToCanonicalTypeFamily(argType.Family()) {
+	// {{range .}}
+	case _CANONICAL_TYPE_FAMILY:
+		switch argType.Width() {
+		// {{range .WidthOverloads}}
+		case _TYPE_WIDTH:
+			return newBufferedWindowOperator(
+				args, &firstLastNthValue_TYPEWindow{firstLastNthValueBase: base}, argType,
+			), nil
+			// {{end}}
+		}
+		// {{end}}
+	}
+	return nil, errors.Errorf("unsupported window function type %s", argType)
+}
+
+// firstLastNthValueBase extracts common fields and methods of the
+// first_value, last_value and nth_value window functions.
+type firstLastNthValueBase struct {
+	buffer *spillingBuffer
+	framer *windowFramer
+	fn     firstLastNthValueFn
+	// argIdx is the index of the value argument, and nthIdx is the index of
+	// the position argument of nth_value (or tree.NoColumnIdx).
+	argIdx int
+	nthIdx int
+}
+
+func (b *firstLastNthValueBase) init(buffer *spillingBuffer) {
+	b.buffer = buffer
+	b.framer.init(buffer)
+}
+
+func (b *firstLastNthValueBase) startNewPartition(_ context.Context, partitionSize int) {
+	b.framer.startPartition(partitionSize)
+}
+
+// getRequestedIdx advances the framer to the i'th row of the partition and
+// returns the index of the row of the partition which value is the result for
+// the i'th row, or -1 if the result is NULL.
+func (b *firstLastNthValueBase) getRequestedIdx(ctx context.Context, i int) int {
+	b.framer.next(ctx)
+	switch b.fn {
+	case firstValueFn:
+		return b.framer.frameFirstIdx()
+	case lastValueFn:
+		return b.framer.frameLastIdx()
+	default:
+		nthVec, nthIdx := b.buffer.getVecWithTuple(ctx, b.nthIdx, i)
+		if nthVec.Nulls().MaybeHasNulls() && nthVec.Nulls().NullAt(nthIdx) {
+			return -1
+		}
+		nth := nthVec.Int64()[nthIdx]
+		if nth <= 0 {
+			colexecerror.ExpectedError(errInvalidArgumentForNthValue)
+		}
+		if nth > int64(b.buffer.length) {
+			// The requested row is definitely outside of the frame.
+			return -1
+		}
+		return b.framer.frameNthIdx(int(nth))
+	}
+}
+
+// {{range .}}
+// {{range .WidthOverloads}}
+
+// firstLastNthValue_TYPEWindow computes the first_value, last_value and
+// nth_value window functions for the _TYPE type.
+type firstLastNthValue_TYPEWindow struct {
+	firstLastNthValueBase
+}
+
+var _ bufferedWindower = &firstLastNthValue_TYPEWindow{}
+
+func (w *firstLastNthValue_TYPEWindow) processBatch(
+	ctx context.Context, output coldata.Vec, outputIdx, startIdx, endIdx int,
+) {
+	outputCol := output.TemplateType()
+	outputNulls := output.Nulls()
+	for i := startIdx; i < endIdx; i, outputIdx = i+1, outputIdx+1 {
+		requestedIdx := w.getRequestedIdx(ctx, i)
+		if requestedIdx == -1 {
+			// The frame doesn't contain the requested row.
+			outputNulls.SetNull(outputIdx)
+			continue
+		}
+		vec, vecIdx := w.buffer.getVecWithTuple(ctx, w.argIdx, requestedIdx)
+		if vec.Nulls().MaybeHasNulls() && vec.Nulls().NullAt(vecIdx) {
+			outputNulls.SetNull(outputIdx)
+			continue
+		}
+		col := vec.TemplateType()
+		val := col.Get(vecIdx)
+		execgen.SET(outputCol, outputIdx, val)
+	}
+}
+
+// {{end}}
+// {{end}}
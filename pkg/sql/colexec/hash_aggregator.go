@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
 )
 
@@ -60,6 +61,10 @@ const (
 // The output row ordering of this operator is arbitrary.
 // Note that throughout this file "buckets" and "groups" mean the same thing
 // and are used interchangeably.
+//
+// If the spec has multiple grouping sets, every buffered batch is aggregated
+// once for each grouping set, each of which has its own hash table and
+// buckets, so all grouping sets are computed in a single pass over the input.
 type hashAggregator struct {
 	OneInputNode
 
@@ -94,6 +99,15 @@ type hashAggregator struct {
 	// groups ("head" here means the tuple that was first seen from the group).
 	ht *hashTable
 
+	// groupingSets contains the state of each grouping set if the aggregation
+	// is computed for multiple grouping sets (see AggregatorSpec.GroupingSets).
+	// In that case, buckets and ht refer to the grouping set that is currently
+	// being aggregated or output.
+	groupingSets []hashAggregatorGroupingSet
+	// curGroupingSet is the index of the grouping set that is currently being
+	// output.
+	curGroupingSet int
+
 	// state stores the current state of hashAggregator.
 	state hashAggregatorState
 
@@ -126,6 +140,20 @@ type hashAggregator struct {
 	toClose     colexecbase.Closers
 }
 
+// hashAggregatorGroupingSet is the state of a single grouping set of the
+// hashAggregator.
+type hashAggregatorGroupingSet struct {
+	// buckets and ht are the buckets and the hash table of the grouping set
+	// (see hashAggregator). ht is nil if the grouping set is empty, in which
+	// case there is always exactly one bucket.
+	buckets []*aggBucket
+	ht      *hashTable
+	// nullAggs contains the ANY_NOT_NULL aggregations of grouping columns that
+	// are not part of the grouping set. These aggregations produce NULL for the
+	// groups of the grouping set.
+	nullAggs util.FastIntSet
+}
+
 var _ ResettableOperator = &hashAggregator{}
 var _ colexecbase.BufferingInMemoryOperator = &hashAggregator{}
 var _ closableOperator = &hashAggregator{}
@@ -179,6 +207,21 @@ func NewHashAggregator(
 	if newSpillingQueueArgs != nil {
 		hashAgg.inputTrackingState.tuples = newSpillingQueue(newSpillingQueueArgs)
 	}
+	if len(args.Spec.GroupingSets) > 0 {
+		hashAgg.groupingSets = make([]hashAggregatorGroupingSet, len(args.Spec.GroupingSets))
+		for i := range args.Spec.GroupingSets {
+			var set util.FastIntSet
+			for _, c := range args.Spec.GroupingSets[i].Cols {
+				set.Add(int(c))
+			}
+			for j, agg := range args.Spec.Aggregations {
+				if agg.Func == execinfrapb.AggregatorSpec_ANY_NOT_NULL && len(agg.ColIdx) == 1 &&
+					!set.Contains(int(agg.ColIdx[0])) {
+					hashAgg.groupingSets[i].nullAggs.Add(j)
+				}
+			}
+		}
+	}
 	return hashAgg, err
 }
 
@@ -188,16 +231,42 @@ func (op *hashAggregator) Init() {
 	// TPCH queries using tpchvec/bench.
 	const hashTableLoadFactor = 0.1
 	const hashTableNumBuckets = 256
-	op.ht = newHashTable(
-		op.allocator,
-		hashTableLoadFactor,
-		hashTableNumBuckets,
-		op.inputTypes,
-		op.spec.GroupCols,
-		true, /* allowNullEquality */
-		hashTableDistinctBuildMode,
-		hashTableDefaultProbeMode,
-	)
+	newAggHashTable := func(groupCols []uint32) *hashTable {
+		return newHashTable(
+			op.allocator,
+			hashTableLoadFactor,
+			hashTableNumBuckets,
+			op.inputTypes,
+			groupCols,
+			true, /* allowNullEquality */
+			hashTableDistinctBuildMode,
+			hashTableDefaultProbeMode,
+		)
+	}
+	if op.groupingSets == nil {
+		op.ht = newAggHashTable(op.spec.GroupCols)
+		return
+	}
+	for i := range op.groupingSets {
+		if cols := op.spec.GroupingSets[i].Cols; len(cols) > 0 {
+			op.groupingSets[i].ht = newAggHashTable(cols)
+		}
+	}
+	op.initEmptyGroupingSets()
+}
+
+// initEmptyGroupingSets creates the only bucket of each empty grouping set,
+// which produces a row even if there are no input tuples.
+func (op *hashAggregator) initEmptyGroupingSets() {
+	for i := range op.groupingSets {
+		if gs := &op.groupingSets[i]; gs.ht == nil {
+			bucket := op.hashAlloc.newAggBucket()
+			bucket.init(
+				op.aggFnsAlloc.MakeAggregateFuncs(), op.aggHelper.makeSeenMaps(), nil, /* groups */
+			)
+			gs.buckets = append(gs.buckets[:0], bucket)
+		}
+	}
 }
 
 func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
@@ -223,17 +292,10 @@ func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
 				// This is the last input batch.
 				if op.bufferingState.tuples.Length() == 0 {
 					// There are currently no buffered tuples to perform the
-					// aggregation on.
-					if len(op.buckets) == 0 {
-						// We don't have any buckets which means that there were
-						// no input tuples whatsoever, so we can transition to
-						// finished state right away.
-						op.state = hashAggregatorDone
-					} else {
-						// There are some buckets, so we proceed to the
-						// outputting state.
-						op.state = hashAggregatorOutputting
-					}
+					// aggregation on, so we proceed to the outputting state
+					// (or transition to finished state right away if we don't
+					// have any buckets).
+					op.finishAggregation()
 				} else {
 					// There are some buffered tuples on which we need to run
 					// the aggregation.
@@ -258,13 +320,13 @@ func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
 
 		case hashAggregatorAggregating:
 			op.inputArgsConverter.ConvertBatch(op.bufferingState.tuples)
-			op.onlineAgg(ctx, op.bufferingState.tuples)
+			if op.groupingSets == nil {
+				op.onlineAgg(ctx, op.bufferingState.tuples)
+			} else {
+				op.groupingSetsAgg(ctx, op.bufferingState.tuples)
+			}
 			if op.bufferingState.pendingBatch.Length() == 0 {
-				if len(op.buckets) == 0 {
-					op.state = hashAggregatorDone
-				} else {
-					op.state = hashAggregatorOutputting
-				}
+				op.finishAggregation()
 				continue
 			}
 			op.bufferingState.tuples.ResetInternalBatch()
@@ -292,9 +354,12 @@ func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
 					}
 					curOutputIdx++
 				}
+				if op.groupingSets != nil {
+					op.setGroupingSetOutput(curOutputIdx)
+				}
 			})
 			op.buckets = op.buckets[curOutputIdx:]
-			if len(op.buckets) == 0 {
+			if len(op.buckets) == 0 && !op.nextGroupingSetToOutput() {
 				op.state = hashAggregatorDone
 			}
 			op.output.SetLength(curOutputIdx)
@@ -311,6 +376,87 @@ func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
 	}
 }
 
+// finishAggregation transitions to the outputting state once all input tuples
+// have been aggregated, or to the finished state if there are no buckets.
+func (op *hashAggregator) finishAggregation() {
+	if op.groupingSets != nil {
+		op.curGroupingSet = -1
+		op.buckets = nil
+		if !op.nextGroupingSetToOutput() {
+			op.state = hashAggregatorDone
+			return
+		}
+	}
+	if len(op.buckets) == 0 {
+		// We don't have any buckets which means that there were no input
+		// tuples whatsoever, so we can transition to finished state right
+		// away.
+		op.state = hashAggregatorDone
+	} else {
+		op.state = hashAggregatorOutputting
+	}
+}
+
+// groupingSetsAgg aggregates all tuples in b into the buckets of every
+// grouping set. The length and the selection vector of b are restored before
+// each grouping set is processed since onlineAgg modifies them.
+func (op *hashAggregator) groupingSetsAgg(ctx context.Context, b coldata.Batch) {
+	n := b.Length()
+	for i := range op.groupingSets {
+		gs := &op.groupingSets[i]
+		b.SetSelection(false)
+		b.SetLength(n)
+		if gs.ht == nil {
+			// All tuples belong to the only group of the empty grouping set.
+			b.SetSelection(true)
+			sel := b.Selection()[:n]
+			for j := range sel {
+				sel[j] = j
+			}
+			op.aggHelper.performAggregation(
+				ctx, b.ColVecs(), n, sel, gs.buckets[0], nil, /* groups */
+			)
+			continue
+		}
+		op.ht, op.buckets = gs.ht, gs.buckets
+		op.onlineAgg(ctx, b)
+		gs.buckets = op.buckets
+	}
+}
+
+// nextGroupingSetToOutput advances to the next grouping set that has buckets
+// to output and returns false if there are no such grouping sets left.
+func (op *hashAggregator) nextGroupingSetToOutput() bool {
+	if op.groupingSets == nil {
+		return false
+	}
+	for op.curGroupingSet++; op.curGroupingSet < len(op.groupingSets); op.curGroupingSet++ {
+		if buckets := op.groupingSets[op.curGroupingSet].buckets; len(buckets) > 0 {
+			op.buckets = buckets
+			return true
+		}
+	}
+	return false
+}
+
+// setGroupingSetOutput populates the column with the index of the grouping
+// set for the first n output tuples, which all belong to the grouping set that
+// is currently being output, and sets the output of the aggregations that
+// produce NULL for the grouping set.
+func (op *hashAggregator) setGroupingSetOutput(n int) {
+	gs := &op.groupingSets[op.curGroupingSet]
+	setIdxs := op.output.ColVec(len(op.spec.Aggregations)).Int64()
+	for i := 0; i < n; i++ {
+		setIdxs[i] = int64(op.curGroupingSet)
+	}
+	for fnIdx, ok := gs.nullAggs.Next(0); ok; fnIdx, ok = gs.nullAggs.Next(fnIdx + 1) {
+		nulls := op.output.ColVec(fnIdx).Nulls()
+		for i := 0; i < n; i++ {
+			nulls.SetNull(i)
+		}
+	}
+}
+
 func (op *hashAggregator) setupScratchSlices(numBuffered int) {
 	if len(op.scratch.eqChains) < numBuffered {
 		op.scratch.eqChains = make([][]int, numBuffered)
@@ -499,8 +645,20 @@ func (op *hashAggregator) reset(ctx context.Context) {
 	op.bufferingState.tuples.ResetInternalBatch()
 	op.bufferingState.pendingBatch = nil
 	op.bufferingState.unprocessedIdx = 0
-	op.buckets = op.buckets[:0]
-	op.ht.reset(ctx)
+	if op.groupingSets == nil {
+		op.buckets = op.buckets[:0]
+		op.ht.reset(ctx)
+	} else {
+		op.buckets = nil
+		for i := range op.groupingSets {
+			gs := &op.groupingSets[i]
+			gs.buckets = gs.buckets[:0]
+			if gs.ht != nil {
+				gs.ht.reset(ctx)
+			}
+		}
+		op.initEmptyGroupingSets()
+	}
 	if op.inputTrackingState.tuples != nil {
 		if err := op.inputTrackingState.tuples.close(ctx); err != nil {
 			colexecerror.InternalError(err)
@@ -135,6 +135,52 @@ var hashAggregatorTestCases = []aggregatorTestCase{
 
 		name: "unusedInputCol",
 	},
+	{
+		input: tuples{
+			{1, 1, 1},
+			{1, 2, 2},
+			{2, 1, 3},
+			{1, 1, 4},
+		},
+		typs: []*types.T{types.Int, types.Int, types.Int},
+		aggFns: []execinfrapb.AggregatorSpec_Func{
+			execinfrapb.AggregatorSpec_ANY_NOT_NULL,
+			execinfrapb.AggregatorSpec_ANY_NOT_NULL,
+			execinfrapb.AggregatorSpec_SUM_INT,
+			execinfrapb.AggregatorSpec_COUNT,
+		},
+		groupCols:    []uint32{0, 1},
+		aggCols:      [][]uint32{{0}, {1}, {2}, {1}},
+		aggDistinct:  []bool{false, false, false, true},
+		groupingSets: [][]uint32{{0, 1}, {0}, {}},
+
+		expected: tuples{
+			{1, 1, 5, 1, 0},
+			{1, 2, 2, 1, 0},
+			{2, 1, 3, 1, 0},
+			{1, nil, 7, 2, 1},
+			{2, nil, 3, 1, 1},
+			{nil, nil, 10, 2, 2},
+		},
+
+		name: "groupingSets",
+	},
+	{
+		input: tuples{},
+		typs:  []*types.T{types.Int, types.Int},
+		aggFns: []execinfrapb.AggregatorSpec_Func{
+			execinfrapb.AggregatorSpec_ANY_NOT_NULL,
+			execinfrapb.AggregatorSpec_COUNT_ROWS,
+		},
+		aggCols:      [][]uint32{{0}, {}},
+		groupingSets: [][]uint32{{0}, {}},
+
+		expected: tuples{
+			{nil, 0, 1},
+		},
+
+		name: "groupingSetsNoInput",
+	},
 }
 
 func init() {
@@ -156,7 +202,10 @@ func TestHashAggregator(t *testing.T) {
 			&evalCtx, nil /* semaCtx */, tc.spec.Aggregations, tc.typs,
 		)
 		require.NoError(t, err)
-		runTests(t, []tuples{tc.input}, tc.expected, unorderedVerifier, func(sources []colexecbase.Operator) (colexecbase.Operator, error) {
+		if len(tc.spec.GroupingSets) > 0 {
+			outputTypes = append(outputTypes, types.Int)
+		}
+		runTestsWithTyps(t, []tuples{tc.input}, [][]*types.T{tc.typs}, tc.expected, unorderedVerifier, func(sources []colexecbase.Operator) (colexecbase.Operator, error) {
 			return NewHashAggregator(&colexecagg.NewAggregatorArgs{
 				Allocator:      testAllocator,
 				MemAccount:     testMemAcc,
@@ -0,0 +1,191 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for lead_lag.eg.go. It's formatted in a
+// special way, so it's both valid Go and a valid text/template input. This
+// permits editing this file with editor support.
+//
+// */}}
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/execgen"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// Workaround for bazel auto-generated code. goimports does not automatically
+// pick up the right packages when run within the bazel sandbox.
+var (
+	_ tree.AggType
+)
+
+// {{/*
+
+// Declarations to make the template compile properly.
+
+// _CANONICAL_TYPE_FAMILY is the template variable.
+const _CANONICAL_TYPE_FAMILY = types.UnknownFamily
+
+// _TYPE_WIDTH is the template variable.
+const _TYPE_WIDTH = 0
+
+// */}}
+
+// NewLagOperator creates a new Operator that computes the LAG window function.
+// argIdxs are the indices of the arguments of the function: the value, and
+// optionally the offset and the default value.
+func NewLagOperator(args *WindowArgs, argIdxs []uint32) (colexecbase.Operator, error) {
+	return newLeadLagOperator(args, argIdxs, true /* isLag */)
+}
+
+// NewLeadOperator creates a new Operator that computes the LEAD window
+// function. argIdxs are the indices of the arguments of the function: the
+// value, and optionally the offset and the default value.
+func NewLeadOperator(args *WindowArgs, argIdxs []uint32) (colexecbase.Operator, error) {
+	return newLeadLagOperator(args, argIdxs, false /* isLag */)
+}
+
+func newLeadLagOperator(
+	args *WindowArgs, argIdxs []uint32, isLag bool,
+) (colexecbase.Operator, error) {
+	if len(argIdxs) == 0 || len(argIdxs) > 3 {
+		return nil, errors.AssertionFailedf("unexpected number of arguments for lead or lag: %d", len(argIdxs))
+	}
+	base := leadLagBase{
+		isLag:      isLag,
+		argIdx:     int(argIdxs[0]),
+		offsetIdx:  tree.NoColumnIdx,
+		defaultIdx: tree.NoColumnIdx,
+	}
+	if len(argIdxs) > 1 {
+		base.offsetIdx = int(argIdxs[1])
+	}
+	if len(argIdxs) > 2 {
+		base.defaultIdx = int(argIdxs[2])
+	}
+	argType := args.InputTypes[argIdxs[0]]
+	switch typeconv.TypeFamilyToCanonicalTypeFamily(argType.Family()) {
+	// {{range .}}
+	case _CANONICAL_TYPE_FAMILY:
+		switch argType.Width() {
+		// {{range .WidthOverloads}}
+		case _TYPE_WIDTH:
+			return newBufferedWindowOperator(args, &leadLag_TYPEWindow{leadLagBase: base}, argType), nil
+			// {{end}}
+		}
+		// {{end}}
+	}
+	return nil, errors.Errorf("unsupported lead or lag type %s", argType)
+}
+
+// leadLagBase extracts common fields and methods of the lead and lag window
+// functions.
+type leadLagBase struct {
+	buffer *spillingBuffer
+	isLag  bool
+	// argIdx, offsetIdx and defaultIdx are the indices of the arguments. The
+	// latter two are tree.NoColumnIdx if the corresponding arguments are
+	// omitted.
+	argIdx     int
+	offsetIdx  int
+	defaultIdx int
+
+	partitionSize int
+}
+
+func (b *leadLagBase) init(buffer *spillingBuffer) {
+	b.buffer = buffer
+}
+
+func (b *leadLagBase) startNewPartition(_ context.Context, partitionSize int) {
+	b.partitionSize = partitionSize
+}
+
+// getRequestedIdx returns the index of the row of the current partition which
+// is requested by the i'th row (the index might be outside of the partition).
+// offsetIsNull is true if the offset of the i'th row is NULL.
+func (b *leadLagBase) getRequestedIdx(ctx context.Context, i int) (_ int, offsetIsNull bool) {
+	offset := int64(1)
+	if b.offsetIdx != tree.NoColumnIdx {
+		offsetVec, offsetIdx := b.buffer.getVecWithTuple(ctx, b.offsetIdx, i)
+		if offsetVec.Nulls().MaybeHasNulls() && offsetVec.Nulls().NullAt(offsetIdx) {
+			return 0, true
+		}
+		offset = offsetVec.Int64()[offsetIdx]
+	}
+	if b.isLag {
+		offset = -offset
+	}
+	// Note that the offset can be arbitrarily large, so we need to be careful
+	// to not overflow.
+	if offset >= int64(b.partitionSize) || offset <= -int64(b.partitionSize) {
+		return b.partitionSize, false
+	}
+	return i + int(offset), false
+}
+
+// {{range .}}
+// {{range .WidthOverloads}}
+
+// leadLag_TYPEWindow computes the lead and lag window functions for the _TYPE
+// type.
+type leadLag_TYPEWindow struct {
+	leadLagBase
+}
+
+var _ bufferedWindower = &leadLag_TYPEWindow{}
+
+func (w *leadLag_TYPEWindow) processBatch(
+	ctx context.Context, output coldata.Vec, outputIdx, startIdx, endIdx int,
+) {
+	outputCol := output.TemplateType()
+	outputNulls := output.Nulls()
+	for i := startIdx; i < endIdx; i, outputIdx = i+1, outputIdx+1 {
+		requestedIdx, offsetIsNull := w.getRequestedIdx(ctx, i)
+		if offsetIsNull {
+			// The offset is NULL, so the result is NULL.
+			outputNulls.SetNull(outputIdx)
+			continue
+		}
+		var vec coldata.Vec
+		var vecIdx int
+		if requestedIdx >= 0 && requestedIdx < w.partitionSize {
+			vec, vecIdx = w.buffer.getVecWithTuple(ctx, w.argIdx, requestedIdx)
+		} else if w.defaultIdx != tree.NoColumnIdx {
+			// The requested row is outside of the partition, so the default
+			// value of the current row is used.
+			vec, vecIdx = w.buffer.getVecWithTuple(ctx, w.defaultIdx, i)
+		} else {
+			outputNulls.SetNull(outputIdx)
+			continue
+		}
+		if vec.Nulls().MaybeHasNulls() && vec.Nulls().NullAt(vecIdx) {
+			outputNulls.SetNull(outputIdx)
+			continue
+		}
+		col := vec.TemplateType()
+		val := col.Get(vecIdx)
+		execgen.SET(outputCol, outputIdx, val)
+	}
+}
+
+// {{end}}
+// {{end}}
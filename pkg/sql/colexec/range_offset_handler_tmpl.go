@@ -0,0 +1,272 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for range_offset_handler.eg.go. It's
+// formatted in a special way, so it's both valid Go and a valid text/template
+// input. This permits editing this file with editor support.
+//
+// */}}
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/errors"
+)
+
+// Workaround for bazel auto-generated code. goimports does not automatically
+// pick up the right packages when run within the bazel sandbox.
+var (
+	_ apd.Context
+	_ = coldata.BatchSize
+	_ duration.Duration
+	_ tree.AggType
+)
+
+// {{/*
+
+// Declarations to make the template compile properly.
+
+// _GOTYPE is the template variable.
+type _GOTYPE interface{}
+
+// _OFFSET_GOTYPE is the template variable.
+type _OFFSET_GOTYPE interface{}
+
+// _BOUND_GOTYPE is the template variable.
+type _BOUND_GOTYPE interface{}
+
+// _CANONICAL_TYPE_FAMILY is the template variable.
+const _CANONICAL_TYPE_FAMILY = types.UnknownFamily
+
+// _TYPE_WIDTH is the template variable.
+const _TYPE_WIDTH = 0
+
+// _ASSIGN_ADD is the template addition function for assigning the first input
+// to the result of the second input + the third input.
+func _ASSIGN_ADD(_, _, _ interface{}) {
+	colexecerror.InternalError(errors.AssertionFailedf(""))
+}
+
+// _ASSIGN_SUB is the template subtraction function for assigning the first
+// input to the result of the second input - the third input.
+func _ASSIGN_SUB(_, _, _ interface{}) {
+	colexecerror.InternalError(errors.AssertionFailedf(""))
+}
+
+// _COMPARE is the template comparison function for assigning the first input
+// to the result of comparing the second input to the third input, returning
+// an int less than, equal to, or greater than zero if the second input is less
+// than, equal to, or greater than the third input respectively.
+func _COMPARE(_, _, _ interface{}) {
+	colexecerror.InternalError(errors.AssertionFailedf(""))
+}
+
+// */}}
+
+// rangeOffsetHandler is a utility operator used to retrieve the location of
+// the start or end bound for each row when in RANGE mode with an OFFSET
+// PRECEDING or OFFSET FOLLOWING bound type.
+type rangeOffsetHandler interface {
+	// startPartition resets the state of the handler to be used on a new
+	// partition.
+	startPartition()
+
+	// getIdx returns the index of the start or end bound (depending on the
+	// handler) of the frame for the given row of the current partition.
+	// Note that the index of the end bound is exclusive. getIdx must be called
+	// with non-decreasing values of currRow within a partition since the
+	// handler relies on the bounds being non-decreasing as well.
+	getIdx(ctx context.Context, currRow int) int
+}
+
+// isRangeOffsetHandlerSupported returns whether the vectorized engine supports
+// RANGE mode with an offset bound for an ordering column of the given type.
+func isRangeOffsetHandlerSupported(ordColType *types.T) bool {
+	switch ordColType.Family() {
+	case types.IntFamily, types.FloatFamily, types.DecimalFamily,
+		types.IntervalFamily, types.TimestampFamily, types.TimestampTZFamily:
+	default:
+		// Some other types (like DATE) share the physical representation with
+		// the supported ones, but the offsets are applied to them differently.
+		return false
+	}
+	switch typeconv.TypeFamilyToCanonicalTypeFamily(ordColType.Family()) {
+	// {{range .}}
+	case _CANONICAL_TYPE_FAMILY:
+		switch ordColType.Width() {
+		// {{range .Widths}}
+		case _TYPE_WIDTH:
+			return true
+			// {{end}}
+		}
+		// {{end}}
+	}
+	return false
+}
+
+// newRangeOffsetHandler returns a rangeOffsetHandler for the given bound of
+// the frame. ordColIdx is the index of the ordering column among the columns
+// stored in the buffer.
+func newRangeOffsetHandler(
+	bound *execinfrapb.WindowerSpec_Frame_Bound,
+	ordColType *types.T,
+	ordColAsc, isStart bool,
+	buffer *spillingBuffer,
+	ordColIdx int,
+) rangeOffsetHandler {
+	var datumAlloc rowenc.DatumAlloc
+	datum, rem, err := rowenc.DecodeTableValue(&datumAlloc, bound.OffsetType.Type, bound.TypedOffset)
+	if err != nil {
+		colexecerror.InternalError(errors.NewAssertionErrorWithWrappedErrf(err,
+			"error decoding %d bytes", errors.Safe(len(bound.TypedOffset))))
+	}
+	if len(rem) != 0 {
+		colexecerror.InternalError(errors.AssertionFailedf(
+			"%d trailing bytes in encoded value", errors.Safe(len(rem))))
+	}
+	// Note that we use the type of the datum (rather than the type in the
+	// spec) because all integer offsets are represented by DInt.
+	offset := colconv.GetDatumToPhysicalFn(datum.ResolvedType())(datum)
+	preceding := bound.BoundType == execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING
+	base := rangeOffsetHandlerBase{
+		buffer:    buffer,
+		ordColIdx: ordColIdx,
+		ordColAsc: ordColAsc,
+		isStart:   isStart,
+		// When the ordering is ascending, the PRECEDING rows have smaller
+		// values, and when it is descending, the FOLLOWING ones do.
+		subtract: preceding == ordColAsc,
+	}
+	switch typeconv.TypeFamilyToCanonicalTypeFamily(ordColType.Family()) {
+	// {{range .}}
+	case _CANONICAL_TYPE_FAMILY:
+		switch ordColType.Width() {
+		// {{range .Widths}}
+		case _TYPE_WIDTH:
+			return &rangeOffsetHandler_TYPE{
+				rangeOffsetHandlerBase: base,
+				offset:                 offset.(_OFFSET_GOTYPE),
+			}
+			// {{end}}
+		}
+		// {{end}}
+	}
+	colexecerror.InternalError(
+		errors.AssertionFailedf("unsupported ordering column type for RANGE mode with offset: %s", ordColType))
+	// This code is unreachable, but the compiler cannot infer that.
+	return nil
+}
+
+// rangeOffsetHandlerBase extracts common fields and methods of the
+// rangeOffsetHandler utility operators.
+type rangeOffsetHandlerBase struct {
+	buffer    *spillingBuffer
+	ordColIdx int
+	ordColAsc bool
+	isStart   bool
+	// subtract indicates whether the offset should be subtracted from the
+	// value of the current row (rather than added to it) to get the value of
+	// the bound.
+	subtract bool
+	// lastIdx is the index of the bound returned by the last call to getIdx.
+	// Since the bounds are non-decreasing within a partition, the search for
+	// the next bound can start from lastIdx.
+	lastIdx int
+}
+
+func (b *rangeOffsetHandlerBase) startPartition() {
+	b.lastIdx = 0
+}
+
+// {{range .}}
+// {{range .Widths}}
+
+// rangeOffsetHandler_TYPE is a utility operator that calculates the bound of
+// the frame for the ordering column of the _TYPE type.
+type rangeOffsetHandler_TYPE struct {
+	rangeOffsetHandlerBase
+	offset _OFFSET_GOTYPE
+}
+
+var _ rangeOffsetHandler = &rangeOffsetHandler_TYPE{}
+
+// getIdx returns the index of the first row (starting from lastIdx) that is
+// within the frame for the start bound or the first row that is past the
+// frame for the end bound. If there is no such row, the length of the
+// partition is returned.
+func (h *rangeOffsetHandler_TYPE) getIdx(ctx context.Context, currRow int) (idx int) {
+	if h.lastIdx >= h.buffer.length {
+		return h.buffer.length
+	}
+	// NULL values are not offset, so the bound for a row with a NULL value
+	// is determined by the NULL peers of that row.
+	vec, vecIdx := h.buffer.getVecWithTuple(ctx, h.ordColIdx, currRow)
+	boundIsNull := vec.Nulls().MaybeHasNulls() && vec.Nulls().NullAt(vecIdx)
+	var bound _BOUND_GOTYPE
+	if !boundIsNull {
+		currVal := vec.TemplateType().Get(vecIdx)
+		if h.subtract {
+			_ASSIGN_SUB(bound, currVal, h.offset)
+		} else {
+			_ASSIGN_ADD(bound, currVal, h.offset)
+		}
+	}
+	for idx = h.lastIdx; idx < h.buffer.length; {
+		batch, batchIdx := h.buffer.getBatchWithTuple(ctx, idx)
+		vec = batch.ColVec(h.ordColIdx)
+		col := vec.TemplateType()
+		nulls := vec.Nulls()
+		n := batch.Length()
+		for ; batchIdx < n; batchIdx, idx = batchIdx+1, idx+1 {
+			// NULL values are ordered before all non-NULL values.
+			var cmp int
+			isNull := nulls.MaybeHasNulls() && nulls.NullAt(batchIdx)
+			if isNull || boundIsNull {
+				if !isNull {
+					cmp = 1
+				} else if !boundIsNull {
+					cmp = -1
+				}
+			} else {
+				val := col.Get(batchIdx)
+				_COMPARE(cmp, val, bound)
+			}
+			if !h.ordColAsc {
+				cmp = -cmp
+			}
+			// The start bound is the first row that is not before the bound
+			// value, and the end bound is the first row after it.
+			if cmp > 0 || (cmp == 0 && h.isStart) {
+				h.lastIdx = idx
+				return idx
+			}
+		}
+	}
+	h.lastIdx = h.buffer.length
+	return h.buffer.length
+}
+
+// {{end}}
+// {{end}}
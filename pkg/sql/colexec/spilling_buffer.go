@@ -0,0 +1,332 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// spillingBufferNumCachedBatches is the number of batches read from the disk
+// queue of the spillingBuffer that are kept in memory, so that the callers
+// which access the spilled tuples at a few different (but increasing)
+// positions don't need to rewind the disk queue on every access.
+const spillingBufferNumCachedBatches = 4
+
+// spillingBuffer is an append-only buffer of tuples which supports access to
+// the tuples by their position. The tuples are kept in memory until the memory
+// limit is reached, and the remaining ones are appended to a rewindable
+// spillingQueue which keeps them on disk.
+//
+// All of the tuples need to be appended before any of them are accessed
+// (which is a limitation of the rewindable spillingQueue), and reset() needs
+// to be called before the buffer can be reused. The access to the spilled
+// tuples is the most efficient when the accessed positions are increasing:
+// the disk queue can only be read sequentially, so if a batch that precedes
+// the last read one is requested and it is not among the cached batches, the
+// disk queue is rewound.
+type spillingBuffer struct {
+	unlimitedAllocator *colmem.Allocator
+	memoryLimit        int64
+	// storedTypes are the types of the stored columns.
+	storedTypes []*types.T
+	// colIdxs are the indices of the stored columns in the appended batches.
+	colIdxs []int
+
+	// length is the number of tuples in the buffer.
+	length int
+	// inMemBatches contain the tuples at the start of the buffer. All batches
+	// have coldata.BatchSize() capacity, and all of them but the last one are
+	// full, so the tuple at position i is in the (i / coldata.BatchSize())'th
+	// batch. The batches are reused after reset(), so only the first
+	// numInMemBatches batches are used.
+	inMemBatches    []coldata.Batch
+	numInMemBatches int
+
+	// spilled indicates whether the memory limit has been reached, and the
+	// tuples are being appended to the disk queue.
+	spilled   bool
+	diskQueue *spillingQueue
+	// diskScratch accumulates the tuples which are appended to the disk queue
+	// until a full batch is collected, so that all of the batches in the disk
+	// queue but the last one are full as well.
+	diskScratch coldata.Batch
+	// nextDiskBatchIdx is the index of the batch that the next dequeue from the
+	// disk queue returns.
+	nextDiskBatchIdx int
+	cache            []spillingBufferCachedBatch
+	// numAccesses is used to find the least recently used cached batch.
+	numAccesses int
+	// finishedAppending indicates whether finishAppending has been called.
+	finishedAppending bool
+}
+
+type spillingBufferCachedBatch struct {
+	batch coldata.Batch
+	// diskBatchIdx is the index of the cached batch in the disk queue, or -1 if
+	// the slot is unused.
+	diskBatchIdx int
+	lastAccess   int
+}
+
+// newSpillingBuffer creates a new spillingBuffer that stores the columns
+// colIdxs of the batches with args.Types schema. An unlimited allocator must be
+// passed in. The buffer will use this allocator to check whether memory usage
+// exceeds the given memory limit and spill to disk if so.
+func newSpillingBuffer(args *NewSpillingQueueArgs, colIdxs []int) *spillingBuffer {
+	storedTypes := make([]*types.T, len(colIdxs))
+	for i, colIdx := range colIdxs {
+		storedTypes[i] = args.Types[colIdx]
+	}
+	// The disk queue always keeps the tuples on disk (the memory limit is
+	// enforced by the buffer itself).
+	diskQueueArgs := *args
+	diskQueueArgs.Types = storedTypes
+	diskQueueArgs.MemoryLimit = 0
+	return &spillingBuffer{
+		unlimitedAllocator: args.UnlimitedAllocator,
+		memoryLimit:        args.MemoryLimit,
+		storedTypes:        storedTypes,
+		colIdxs:            colIdxs,
+		diskQueue:          newRewindableSpillingQueue(&diskQueueArgs),
+	}
+}
+
+// appendTuples appends the tuples of batch in the range [startIdx, endIdx)
+// (the indices take the selection vector of batch into account) to the buffer.
+func (b *spillingBuffer) appendTuples(
+	ctx context.Context, batch coldata.Batch, startIdx, endIdx int,
+) {
+	if b.finishedAppending {
+		colexecerror.InternalError(errors.AssertionFailedf("appendTuples called after finishAppending"))
+	}
+	if len(b.storedTypes) == 0 {
+		// There are no columns to store, so we only need to count the tuples.
+		b.length += endIdx - startIdx
+		return
+	}
+	sel := batch.Selection()
+	for srcStartIdx := startIdx; srcStartIdx < endIdx; {
+		dest := b.getTailBatch(ctx)
+		destIdx := dest.Length()
+		toCopy := dest.Capacity() - destIdx
+		if toCopy > endIdx-srcStartIdx {
+			toCopy = endIdx - srcStartIdx
+		}
+		b.unlimitedAllocator.PerformOperation(dest.ColVecs(), func() {
+			for i, colIdx := range b.colIdxs {
+				dest.ColVec(i).Copy(
+					coldata.CopySliceArgs{
+						SliceArgs: coldata.SliceArgs{
+							Src:         batch.ColVec(colIdx),
+							Sel:         sel,
+							DestIdx:     destIdx,
+							SrcStartIdx: srcStartIdx,
+							SrcEndIdx:   srcStartIdx + toCopy,
+						},
+					},
+				)
+			}
+			dest.SetLength(destIdx + toCopy)
+		})
+		srcStartIdx += toCopy
+		b.length += toCopy
+	}
+}
+
+// getTailBatch returns the batch into which the next appended tuples should be
+// copied. The returned batch always has some capacity left.
+func (b *spillingBuffer) getTailBatch(ctx context.Context) coldata.Batch {
+	if !b.spilled {
+		if b.numInMemBatches > 0 {
+			if tail := b.inMemBatches[b.numInMemBatches-1]; tail.Length() < tail.Capacity() {
+				return tail
+			}
+		}
+		batchSize := int64(colmem.EstimateBatchSizeBytes(b.storedTypes, coldata.BatchSize()))
+		if b.numInMemBatches < len(b.inMemBatches) {
+			// We can reuse an already allocated batch.
+			tail := b.inMemBatches[b.numInMemBatches]
+			tail.ResetInternalBatch()
+			tail.SetLength(0)
+			b.numInMemBatches++
+			return tail
+		} else if b.unlimitedAllocator.Used()+batchSize <= b.memoryLimit {
+			tail := b.unlimitedAllocator.NewMemBatchWithFixedCapacity(b.storedTypes, coldata.BatchSize())
+			b.inMemBatches = append(b.inMemBatches, tail)
+			b.numInMemBatches++
+			return tail
+		}
+		b.spilled = true
+	}
+	if b.diskScratch == nil {
+		b.diskScratch = b.unlimitedAllocator.NewMemBatchWithFixedCapacity(b.storedTypes, coldata.BatchSize())
+	} else if b.diskScratch.Length() == b.diskScratch.Capacity() {
+		b.flushDiskScratch(ctx)
+	}
+	return b.diskScratch
+}
+
+// flushDiskScratch enqueues the tuples accumulated in diskScratch to the disk
+// queue.
+func (b *spillingBuffer) flushDiskScratch(ctx context.Context) {
+	if err := b.diskQueue.enqueue(ctx, b.diskScratch); err != nil {
+		colexecerror.InternalError(err)
+	}
+	b.diskScratch.ResetInternalBatch()
+	b.diskScratch.SetLength(0)
+}
+
+// finishAppending must be called once all of the tuples have been appended,
+// before any of them are accessed.
+func (b *spillingBuffer) finishAppending(ctx context.Context) {
+	if b.finishedAppending {
+		return
+	}
+	b.finishedAppending = true
+	if !b.spilled {
+		return
+	}
+	if b.diskScratch.Length() > 0 {
+		b.flushDiskScratch(ctx)
+	}
+	if err := b.diskQueue.enqueue(ctx, coldata.ZeroBatch); err != nil {
+		colexecerror.InternalError(err)
+	}
+}
+
+// getBatchWithTuple returns the batch which contains the tuple at position idx
+// along with the index of the tuple within that batch. The columns of the
+// returned batch are the stored columns, in the order of colIdxs, and all of
+// the batch's tuples (not only the requested one) are consecutive tuples of
+// the buffer. The batch must not be modified, and it is only valid until the
+// next call to getBatchWithTuple or getVecWithTuple.
+func (b *spillingBuffer) getBatchWithTuple(ctx context.Context, idx int) (coldata.Batch, int) {
+	if !b.finishedAppending {
+		colexecerror.InternalError(errors.AssertionFailedf("spillingBuffer accessed before finishAppending"))
+	}
+	if idx < 0 || idx >= b.length {
+		colexecerror.InternalError(errors.AssertionFailedf(
+			"index %d is out of bounds of spillingBuffer of length %d", idx, b.length,
+		))
+	}
+	batchIdx, tupleIdx := idx/coldata.BatchSize(), idx%coldata.BatchSize()
+	if batchIdx < b.numInMemBatches {
+		return b.inMemBatches[batchIdx], tupleIdx
+	}
+	return b.getDiskBatch(ctx, batchIdx-b.numInMemBatches), tupleIdx
+}
+
+// getVecWithTuple is like getBatchWithTuple, but it returns only the vector of
+// the colIdx'th stored column.
+func (b *spillingBuffer) getVecWithTuple(
+	ctx context.Context, colIdx int, idx int,
+) (coldata.Vec, int) {
+	batch, tupleIdx := b.getBatchWithTuple(ctx, idx)
+	return batch.ColVec(colIdx), tupleIdx
+}
+
+// getDiskBatch returns the diskBatchIdx'th batch of the disk queue, either
+// from the cache or by reading it from the disk queue.
+func (b *spillingBuffer) getDiskBatch(ctx context.Context, diskBatchIdx int) coldata.Batch {
+	b.numAccesses++
+	if b.cache == nil {
+		b.cache = make([]spillingBufferCachedBatch, spillingBufferNumCachedBatches)
+		for i := range b.cache {
+			b.cache[i].diskBatchIdx = -1
+		}
+	}
+	lruIdx := 0
+	for i := range b.cache {
+		if b.cache[i].diskBatchIdx == diskBatchIdx {
+			b.cache[i].lastAccess = b.numAccesses
+			return b.cache[i].batch
+		}
+		if b.cache[i].lastAccess < b.cache[lruIdx].lastAccess {
+			lruIdx = i
+		}
+	}
+	if diskBatchIdx < b.nextDiskBatchIdx {
+		// The disk queue can only be read sequentially, so we have to start
+		// over.
+		if err := b.diskQueue.rewind(); err != nil {
+			colexecerror.InternalError(err)
+		}
+		b.nextDiskBatchIdx = 0
+	}
+	var dequeued coldata.Batch
+	for b.nextDiskBatchIdx <= diskBatchIdx {
+		var err error
+		if dequeued, err = b.diskQueue.dequeue(ctx); err != nil {
+			colexecerror.InternalError(err)
+		}
+		if dequeued.Length() == 0 {
+			colexecerror.InternalError(errors.AssertionFailedf(
+				"unexpectedly reached the end of the disk queue of spillingBuffer",
+			))
+		}
+		b.nextDiskBatchIdx++
+	}
+	// The dequeued batch is only valid until the next call to dequeue(), so we
+	// need to copy it.
+	cached := &b.cache[lruIdx]
+	if cached.batch == nil {
+		cached.batch = b.unlimitedAllocator.NewMemBatchWithFixedCapacity(b.storedTypes, coldata.BatchSize())
+	}
+	cached.batch.ResetInternalBatch()
+	n := dequeued.Length()
+	b.unlimitedAllocator.PerformOperation(cached.batch.ColVecs(), func() {
+		for i := range b.storedTypes {
+			cached.batch.ColVec(i).Copy(
+				coldata.CopySliceArgs{
+					SliceArgs: coldata.SliceArgs{
+						Src:       dequeued.ColVec(i),
+						SrcEndIdx: n,
+					},
+				},
+			)
+		}
+		cached.batch.SetLength(n)
+	})
+	cached.diskBatchIdx = diskBatchIdx
+	cached.lastAccess = b.numAccesses
+	return cached.batch
+}
+
+// reset empties the buffer so that it can be reused. The in-memory batches are
+// kept for reuse.
+func (b *spillingBuffer) reset(ctx context.Context) {
+	b.length = 0
+	b.numInMemBatches = 0
+	if b.spilled {
+		b.diskQueue.reset(ctx)
+		b.spilled = false
+	}
+	if b.diskScratch != nil {
+		b.diskScratch.ResetInternalBatch()
+		b.diskScratch.SetLength(0)
+	}
+	b.nextDiskBatchIdx = 0
+	for i := range b.cache {
+		b.cache[i].diskBatchIdx = -1
+	}
+	b.finishedAppending = false
+}
+
+// close releases the disk resources of the buffer.
+func (b *spillingBuffer) close(ctx context.Context) error {
+	return b.diskQueue.close(ctx)
+}
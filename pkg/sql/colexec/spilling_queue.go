@@ -534,6 +534,9 @@ func (q *spillingQueue) rewind() error {
 		}
 	}
 	q.curHeadIdx = 0
+	// The last dequeued batch (if it came from the disk queue) is no longer
+	// valid, so we release the memory it used.
+	q.unlimitedAllocator.ReleaseMemory(q.lastDequeuedBatchMemUsage)
 	q.lastDequeuedBatchMemUsage = 0
 	q.rewindableState.numItemsDequeued = 0
 	return nil
@@ -550,6 +553,7 @@ func (q *spillingQueue) reset(ctx context.Context) {
 	q.curHeadIdx = 0
 	q.curTailIdx = 0
 	q.nextInMemBatchCapacity = 0
+	q.unlimitedAllocator.ReleaseMemory(q.lastDequeuedBatchMemUsage)
 	q.lastDequeuedBatchMemUsage = 0
 	q.rewindableState.numItemsDequeued = 0
 	q.testingKnobs.numEnqueues = 0
@@ -183,10 +183,11 @@ func (vsc *vectorizedStatsCollectorImpl) finish() *execinfrapb.ComponentStats {
 
 	if kvTime {
 		s.KV.KVTime.Set(time)
-		// Note that kvTime is true only for ColBatchScans, and this is the
-		// only case when we want to add the number of rows read (because the
-		// wrapped joinReaders and tableReaders will add that statistic
-		// themselves).
+		// Note that kvTime is true only for the vectorized operators that
+		// perform KV reads (ColBatchScan, ColIndexJoin, and ColLookupJoin), and
+		// this is the only case when we want to add the number of rows read
+		// (because the wrapped joinReaders and tableReaders will add that
+		// statistic themselves).
 		s.KV.TuplesRead.Set(uint64(vsc.kvReader.GetRowsRead()))
 		s.KV.BytesRead.Set(uint64(vsc.kvReader.GetBytesRead()))
 		s.KV.ContentionTime.Set(vsc.kvReader.GetCumulativeContentionTime())
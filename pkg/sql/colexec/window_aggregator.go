@@ -0,0 +1,161 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecagg"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// NewWindowAggregatorOperator creates a new Operator that computes the given
+// aggregate function over the window frame of each row. argIdxs are the
+// indices of the arguments of the aggregate function, and outputType is its
+// return type. Only the aggregate functions that have optimized vectorized
+// implementations are supported.
+func NewWindowAggregatorOperator(
+	args *WindowArgs,
+	aggFn execinfrapb.AggregatorSpec_Func,
+	frame *execinfrapb.WindowerSpec_Frame,
+	ordering *execinfrapb.Ordering,
+	argIdxs []uint32,
+	outputType *types.T,
+) (colexecbase.Operator, error) {
+	if !colexecagg.IsAggOptimized(aggFn) {
+		return nil, errors.AssertionFailedf("unsupported window aggregate function %s", aggFn)
+	}
+	aggArgs := &colexecagg.NewAggregatorArgs{
+		Allocator:  args.MainAllocator,
+		InputTypes: args.InputTypes,
+		Spec: &execinfrapb.AggregatorSpec{
+			Aggregations: []execinfrapb.AggregatorSpec_Aggregation{{Func: aggFn, ColIdx: argIdxs}},
+		},
+		EvalCtx:     args.EvalCtx,
+		OutputTypes: []*types.T{outputType},
+	}
+	aggAlloc, _, _, err := colexecagg.NewAggregateFuncsAlloc(aggArgs, 1 /* allocSize */, true /* isHashAgg */)
+	if err != nil {
+		return nil, err
+	}
+	framer := newWindowFramer(frame, ordering, args.InputTypes, args.PeersColIdx)
+	frame = framer.frame
+	windower := &windowAggregator{
+		framer:    framer,
+		agg:       aggAlloc.MakeAggregateFuncs()[0],
+		inputIdxs: argIdxs,
+		// If the frame always starts at the beginning of the partition and
+		// no rows are excluded, the frame can only grow as we move through
+		// the partition, so the aggregation can be computed incrementally.
+		// This requires that flushing the result doesn't modify the state of
+		// the aggregate function, which is not the case for the bytes-like
+		// and datum-backed types.
+		cumulative: frame.Bounds.Start.BoundType == execinfrapb.WindowerSpec_Frame_UNBOUNDED_PRECEDING &&
+			frame.Exclusion == execinfrapb.WindowerSpec_Frame_NO_EXCLUSION &&
+			isFlushNonDestructive(outputType),
+		sel: make([]int, coldata.BatchSize()),
+	}
+	windower.copyDecimals = windower.cumulative && outputType.Family() == types.DecimalFamily
+	for i := range windower.sel {
+		windower.sel[i] = i
+	}
+	return newBufferedWindowOperator(args, windower, outputType), nil
+}
+
+// isFlushNonDestructive returns whether the result of an aggregate function of
+// the given output type can be flushed while the aggregation continues.
+func isFlushNonDestructive(outputType *types.T) bool {
+	switch typeconv.TypeFamilyToCanonicalTypeFamily(outputType.Family()) {
+	case types.BytesFamily, typeconv.DatumVecCanonicalTypeFamily:
+		return false
+	}
+	return true
+}
+
+// windowAggregator computes an aggregate function over the window frame of
+// each row of the partition.
+type windowAggregator struct {
+	buffer    *spillingBuffer
+	framer    *windowFramer
+	agg       colexecagg.AggregateFunc
+	inputIdxs []uint32
+	// cumulative indicates whether the aggregation is computed incrementally,
+	// in which case aggregatedEnd is the index of the first row of the
+	// partition which hasn't been aggregated yet.
+	cumulative    bool
+	aggregatedEnd int
+	// copyDecimals indicates whether the flushed decimals need to be copied
+	// because they share the memory with the state of the cumulative
+	// aggregation.
+	copyDecimals bool
+	// sel is a selection vector such that sel[i] = i.
+	sel []int
+}
+
+var _ bufferedWindower = &windowAggregator{}
+
+func (w *windowAggregator) init(buffer *spillingBuffer) {
+	w.buffer = buffer
+	w.framer.init(buffer)
+}
+
+func (w *windowAggregator) startNewPartition(_ context.Context, partitionSize int) {
+	w.framer.startPartition(partitionSize)
+	w.agg.Reset()
+	w.aggregatedEnd = 0
+}
+
+func (w *windowAggregator) processBatch(
+	ctx context.Context, output coldata.Vec, outputIdx, startIdx, endIdx int,
+) {
+	w.agg.SetOutput(output)
+	for i := startIdx; i < endIdx; i, outputIdx = i+1, outputIdx+1 {
+		w.framer.next(ctx)
+		if w.cumulative {
+			if frameEnd := w.framer.frameLastIdx() + 1; frameEnd > w.aggregatedEnd {
+				w.aggregate(ctx, w.aggregatedEnd, frameEnd)
+				w.aggregatedEnd = frameEnd
+			}
+		} else {
+			w.agg.Reset()
+			for _, interval := range w.framer.frameIntervals() {
+				w.aggregate(ctx, interval.start, interval.end)
+			}
+		}
+		w.agg.Flush(outputIdx)
+		if w.copyDecimals && !output.Nulls().NullAt(outputIdx) {
+			col := output.Decimal()
+			var d apd.Decimal
+			d.Set(&col[outputIdx])
+			col[outputIdx] = d
+		}
+	}
+}
+
+// aggregate adds the rows of the partition in the range [startIdx, endIdx) to
+// the aggregation.
+func (w *windowAggregator) aggregate(ctx context.Context, startIdx, endIdx int) {
+	for startIdx < endIdx {
+		batch, batchIdx := w.buffer.getBatchWithTuple(ctx, startIdx)
+		n := batch.Length() - batchIdx
+		if n > endIdx-startIdx {
+			n = endIdx - startIdx
+		}
+		w.agg.Compute(batch.ColVecs(), w.inputIdxs, n, w.sel[batchIdx:batchIdx+n])
+		startIdx += n
+	}
+}
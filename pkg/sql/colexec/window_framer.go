@@ -0,0 +1,430 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// defaultWindowFrame is the frame that is used when the window function
+// doesn't specify one: RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW.
+var defaultWindowFrame = execinfrapb.WindowerSpec_Frame{
+	Mode: execinfrapb.WindowerSpec_Frame_RANGE,
+	Bounds: execinfrapb.WindowerSpec_Frame_Bounds{
+		Start: execinfrapb.WindowerSpec_Frame_Bound{
+			BoundType: execinfrapb.WindowerSpec_Frame_UNBOUNDED_PRECEDING,
+		},
+		End: &execinfrapb.WindowerSpec_Frame_Bound{
+			BoundType: execinfrapb.WindowerSpec_Frame_CURRENT_ROW,
+		},
+	},
+}
+
+// normalizeWindowFrame returns the frame that is equivalent to the given one
+// but has all of the optional parts specified.
+func normalizeWindowFrame(frame *execinfrapb.WindowerSpec_Frame) *execinfrapb.WindowerSpec_Frame {
+	if frame == nil {
+		return &defaultWindowFrame
+	}
+	if frame.Bounds.End == nil {
+		// If the end bound is omitted, it is CURRENT ROW.
+		frameCopy := *frame
+		frameCopy.Bounds.End = &execinfrapb.WindowerSpec_Frame_Bound{
+			BoundType: execinfrapb.WindowerSpec_Frame_CURRENT_ROW,
+		}
+		return &frameCopy
+	}
+	return frame
+}
+
+// isOffsetBound returns whether the bound is OFFSET PRECEDING or OFFSET
+// FOLLOWING.
+func isOffsetBound(bound *execinfrapb.WindowerSpec_Frame_Bound) bool {
+	return bound.BoundType == execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING ||
+		bound.BoundType == execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING
+}
+
+// WindowFrameNeedsPeersInfo returns whether the information about peer groups
+// is needed in order to compute the given frame.
+func WindowFrameNeedsPeersInfo(frame *execinfrapb.WindowerSpec_Frame) bool {
+	frame = normalizeWindowFrame(frame)
+	if frame.Mode == execinfrapb.WindowerSpec_Frame_ROWS {
+		// ROWS mode only needs the peer groups when the peers of the current
+		// row are excluded.
+		return frame.Exclusion == execinfrapb.WindowerSpec_Frame_EXCLUDE_GROUP ||
+			frame.Exclusion == execinfrapb.WindowerSpec_Frame_EXCLUDE_TIES
+	}
+	return true
+}
+
+// IsWindowFrameSupported returns whether the vectorized engine supports the
+// given frame for the window function with the given ordering. inputTypes are
+// the types of the input to the window function.
+func IsWindowFrameSupported(
+	frame *execinfrapb.WindowerSpec_Frame, ordering *execinfrapb.Ordering, inputTypes []*types.T,
+) bool {
+	frame = normalizeWindowFrame(frame)
+	if frame.Mode != execinfrapb.WindowerSpec_Frame_RANGE {
+		return true
+	}
+	if !isOffsetBound(&frame.Bounds.Start) && !isOffsetBound(frame.Bounds.End) {
+		return true
+	}
+	// RANGE mode with offsets requires exactly one ordering column.
+	if ordering == nil || len(ordering.Columns) != 1 {
+		return false
+	}
+	return isRangeOffsetHandlerSupported(inputTypes[ordering.Columns[0].ColIdx])
+}
+
+// windowInterval represents the range of rows [start, end) of the partition.
+type windowInterval struct {
+	start int
+	end   int
+}
+
+// windowFramer computes the window frame of each row of the current partition
+// stored in a spillingBuffer. The rows are processed one at a time (in order)
+// by calling next(), after which the frame of the current row can be accessed.
+type windowFramer struct {
+	frame      *execinfrapb.WindowerSpec_Frame
+	ordering   *execinfrapb.Ordering
+	inputTypes []*types.T
+	// peersColIdx is the index of the boolean column that indicates the start
+	// of each peer group. It is tree.NoColumnIdx if the peer groups are not
+	// needed for the frame.
+	peersColIdx int
+
+	buffer        *spillingBuffer
+	partitionSize int
+
+	// startOffset and endOffset are the offsets of the bounds in ROWS and
+	// GROUPS modes.
+	startOffset int
+	endOffset   int
+	// startHandler and endHandler are used to compute the bounds in RANGE mode
+	// with offsets.
+	startHandler rangeOffsetHandler
+	endHandler   rangeOffsetHandler
+
+	// currentRow is the index of the current row in the partition.
+	currentRow int
+	// currentGroup is the index of the peer group of the current row in the
+	// partition.
+	currentGroup int
+	// peersStart and peersEnd describe the peer group of the current row.
+	peersStart int
+	peersEnd   int
+	// startGroup and endGroup are used to find the peer groups at which the
+	// frame starts and ends in GROUPS mode with offsets.
+	startGroup windowGroupCursor
+	endGroup   windowGroupCursor
+
+	// frameStart and frameEnd describe the frame of the current row before the
+	// exclusion clause is applied.
+	frameStart int
+	frameEnd   int
+	// intervals contains the frame of the current row after the exclusion
+	// clause is applied.
+	intervals []windowInterval
+}
+
+// windowGroupCursor tracks the start of a peer group of the partition. Since
+// the peer groups are found by scanning the peers column, the cursor can only
+// move forward.
+type windowGroupCursor struct {
+	group int
+	idx   int
+}
+
+// newWindowFramer returns a new windowFramer for the given frame. The
+// ordering and the inputTypes are only used in RANGE mode with offsets.
+func newWindowFramer(
+	frame *execinfrapb.WindowerSpec_Frame,
+	ordering *execinfrapb.Ordering,
+	inputTypes []*types.T,
+	peersColIdx int,
+) *windowFramer {
+	frame = normalizeWindowFrame(frame)
+	if !WindowFrameNeedsPeersInfo(frame) {
+		peersColIdx = tree.NoColumnIdx
+	} else if peersColIdx == tree.NoColumnIdx {
+		colexecerror.InternalError(errors.AssertionFailedf("peers column is required for the window frame"))
+	}
+	return &windowFramer{
+		frame:       frame,
+		ordering:    ordering,
+		inputTypes:  inputTypes,
+		peersColIdx: peersColIdx,
+	}
+}
+
+// init sets the buffer that stores the tuples of the current partition.
+func (f *windowFramer) init(buffer *spillingBuffer) {
+	f.buffer = buffer
+	if f.frame.Mode != execinfrapb.WindowerSpec_Frame_RANGE {
+		return
+	}
+	if isOffsetBound(&f.frame.Bounds.Start) || isOffsetBound(f.frame.Bounds.End) {
+		ordCol := f.ordering.Columns[0]
+		ordColType := f.inputTypes[ordCol.ColIdx]
+		ordColAsc := ordCol.Direction == execinfrapb.Ordering_Column_ASC
+		if isOffsetBound(&f.frame.Bounds.Start) {
+			f.startHandler = newRangeOffsetHandler(
+				&f.frame.Bounds.Start, ordColType, ordColAsc, true /* isStart */, buffer, int(ordCol.ColIdx),
+			)
+		}
+		if isOffsetBound(f.frame.Bounds.End) {
+			f.endHandler = newRangeOffsetHandler(
+				f.frame.Bounds.End, ordColType, ordColAsc, false /* isStart */, buffer, int(ordCol.ColIdx),
+			)
+		}
+	}
+}
+
+// startPartition prepares the framer to process a new partition. All tuples of
+// the partition must have been appended to the buffer.
+func (f *windowFramer) startPartition(partitionSize int) {
+	f.partitionSize = partitionSize
+	f.currentRow = -1
+	f.currentGroup = -1
+	f.peersStart, f.peersEnd = 0, 0
+	f.startGroup = windowGroupCursor{}
+	f.endGroup = windowGroupCursor{}
+	// The offsets can be arbitrarily large, so we clamp them to the size of
+	// the partition.
+	f.startOffset = clampWindowOffset(f.frame.Bounds.Start.IntOffset, partitionSize)
+	f.endOffset = clampWindowOffset(f.frame.Bounds.End.IntOffset, partitionSize)
+	if f.startHandler != nil {
+		f.startHandler.startPartition()
+	}
+	if f.endHandler != nil {
+		f.endHandler.startPartition()
+	}
+}
+
+func clampWindowOffset(offset uint64, partitionSize int) int {
+	if offset > uint64(partitionSize) {
+		return partitionSize
+	}
+	return int(offset)
+}
+
+// next advances the framer to the next row of the partition and computes its
+// frame.
+func (f *windowFramer) next(ctx context.Context) {
+	f.currentRow++
+	if f.peersColIdx != tree.NoColumnIdx && f.currentRow >= f.peersEnd {
+		// The current row is the first one in a new peer group.
+		f.currentGroup++
+		f.peersStart = f.currentRow
+		f.peersEnd = f.nextPeerGroupStart(ctx, f.currentRow+1)
+	}
+	f.frameStart = f.computeStart(ctx)
+	f.frameEnd = f.computeEnd(ctx)
+	f.computeIntervals()
+}
+
+// nextPeerGroupStart returns the index of the first row at or after idx that
+// starts a new peer group, or the size of the partition if there is no such
+// row.
+func (f *windowFramer) nextPeerGroupStart(ctx context.Context, idx int) int {
+	for idx < f.partitionSize {
+		batch, batchIdx := f.buffer.getBatchWithTuple(ctx, idx)
+		peersCol := batch.ColVec(f.peersColIdx).Bool()
+		n := batch.Length()
+		for ; batchIdx < n; batchIdx, idx = batchIdx+1, idx+1 {
+			if peersCol[batchIdx] {
+				return idx
+			}
+		}
+	}
+	return f.partitionSize
+}
+
+// groupStart returns the index of the first row of the given peer group, or
+// the size of the partition if there is no such group. The requested groups
+// must be non-decreasing for each cursor.
+func (f *windowFramer) groupStart(ctx context.Context, cursor *windowGroupCursor, group int) int {
+	for cursor.group < group && cursor.idx < f.partitionSize {
+		cursor.idx = f.nextPeerGroupStart(ctx, cursor.idx+1)
+		cursor.group++
+	}
+	return cursor.idx
+}
+
+// computeStart returns the index of the first row in the frame of the current
+// row (before the exclusion clause is applied).
+func (f *windowFramer) computeStart(ctx context.Context) int {
+	switch f.frame.Bounds.Start.BoundType {
+	case execinfrapb.WindowerSpec_Frame_UNBOUNDED_PRECEDING:
+		return 0
+	case execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING:
+		switch f.frame.Mode {
+		case execinfrapb.WindowerSpec_Frame_ROWS:
+			return maxInt(0, f.currentRow-f.startOffset)
+		case execinfrapb.WindowerSpec_Frame_GROUPS:
+			return f.groupStart(ctx, &f.startGroup, maxInt(0, f.currentGroup-f.startOffset))
+		case execinfrapb.WindowerSpec_Frame_RANGE:
+			return f.startHandler.getIdx(ctx, f.currentRow)
+		}
+	case execinfrapb.WindowerSpec_Frame_CURRENT_ROW:
+		if f.frame.Mode == execinfrapb.WindowerSpec_Frame_ROWS {
+			return f.currentRow
+		}
+		return f.peersStart
+	case execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING:
+		switch f.frame.Mode {
+		case execinfrapb.WindowerSpec_Frame_ROWS:
+			return minInt(f.currentRow+f.startOffset, f.partitionSize)
+		case execinfrapb.WindowerSpec_Frame_GROUPS:
+			return f.groupStart(ctx, &f.startGroup, f.currentGroup+f.startOffset)
+		case execinfrapb.WindowerSpec_Frame_RANGE:
+			return f.startHandler.getIdx(ctx, f.currentRow)
+		}
+	}
+	colexecerror.InternalError(errors.AssertionFailedf(
+		"unexpected window frame start bound %s in %s mode",
+		f.frame.Bounds.Start.BoundType, f.frame.Mode,
+	))
+	// This code is unreachable, but the compiler cannot infer that.
+	return 0
+}
+
+// computeEnd returns the index after the last row in the frame of the current
+// row (before the exclusion clause is applied).
+func (f *windowFramer) computeEnd(ctx context.Context) int {
+	switch f.frame.Bounds.End.BoundType {
+	case execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING:
+		switch f.frame.Mode {
+		case execinfrapb.WindowerSpec_Frame_ROWS:
+			return maxInt(0, f.currentRow-f.endOffset+1)
+		case execinfrapb.WindowerSpec_Frame_GROUPS:
+			if f.currentGroup-f.endOffset < 0 {
+				return 0
+			}
+			return f.groupStart(ctx, &f.endGroup, f.currentGroup-f.endOffset+1)
+		case execinfrapb.WindowerSpec_Frame_RANGE:
+			return f.endHandler.getIdx(ctx, f.currentRow)
+		}
+	case execinfrapb.WindowerSpec_Frame_CURRENT_ROW:
+		if f.frame.Mode == execinfrapb.WindowerSpec_Frame_ROWS {
+			return f.currentRow + 1
+		}
+		return f.peersEnd
+	case execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING:
+		switch f.frame.Mode {
+		case execinfrapb.WindowerSpec_Frame_ROWS:
+			return minInt(f.currentRow+f.endOffset+1, f.partitionSize)
+		case execinfrapb.WindowerSpec_Frame_GROUPS:
+			return f.groupStart(ctx, &f.endGroup, f.currentGroup+f.endOffset+1)
+		case execinfrapb.WindowerSpec_Frame_RANGE:
+			return f.endHandler.getIdx(ctx, f.currentRow)
+		}
+	case execinfrapb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING:
+		return f.partitionSize
+	}
+	colexecerror.InternalError(errors.AssertionFailedf(
+		"unexpected window frame end bound %s in %s mode",
+		f.frame.Bounds.End.BoundType, f.frame.Mode,
+	))
+	// This code is unreachable, but the compiler cannot infer that.
+	return 0
+}
+
+// computeIntervals applies the exclusion clause to the frame of the current
+// row.
+func (f *windowFramer) computeIntervals() {
+	f.intervals = f.intervals[:0]
+	if f.frameStart >= f.frameEnd {
+		// The frame is empty.
+		return
+	}
+	var excludeStart, excludeEnd int
+	switch f.frame.Exclusion {
+	case execinfrapb.WindowerSpec_Frame_NO_EXCLUSION:
+		f.intervals = append(f.intervals, windowInterval{start: f.frameStart, end: f.frameEnd})
+		return
+	case execinfrapb.WindowerSpec_Frame_EXCLUDE_CURRENT_ROW:
+		excludeStart, excludeEnd = f.currentRow, f.currentRow+1
+	case execinfrapb.WindowerSpec_Frame_EXCLUDE_GROUP, execinfrapb.WindowerSpec_Frame_EXCLUDE_TIES:
+		excludeStart, excludeEnd = f.peersStart, f.peersEnd
+	}
+	f.appendInterval(f.frameStart, minInt(f.frameEnd, excludeStart))
+	if f.frame.Exclusion == execinfrapb.WindowerSpec_Frame_EXCLUDE_TIES {
+		// The current row itself is not excluded.
+		f.appendInterval(maxInt(f.frameStart, f.currentRow), minInt(f.frameEnd, f.currentRow+1))
+	}
+	f.appendInterval(maxInt(f.frameStart, excludeEnd), f.frameEnd)
+}
+
+func (f *windowFramer) appendInterval(start, end int) {
+	if start < end {
+		f.intervals = append(f.intervals, windowInterval{start: start, end: end})
+	}
+}
+
+// frameIntervals returns the frame of the current row as a list of
+// non-overlapping intervals in increasing order. The returned slice is only
+// valid until the next call to next().
+func (f *windowFramer) frameIntervals() []windowInterval {
+	return f.intervals
+}
+
+// frameFirstIdx returns the index of the first row in the frame of the current
+// row, or -1 if the frame is empty.
+func (f *windowFramer) frameFirstIdx() int {
+	if len(f.intervals) == 0 {
+		return -1
+	}
+	return f.intervals[0].start
+}
+
+// frameLastIdx returns the index of the last row in the frame of the current
+// row, or -1 if the frame is empty.
+func (f *windowFramer) frameLastIdx() int {
+	if len(f.intervals) == 0 {
+		return -1
+	}
+	return f.intervals[len(f.intervals)-1].end - 1
+}
+
+// frameNthIdx returns the index of the nth row (starting from one) in the
+// frame of the current row, or -1 if there is no such row.
+func (f *windowFramer) frameNthIdx(n int) int {
+	for _, interval := range f.intervals {
+		if n <= interval.end-interval.start {
+			return interval.start + n - 1
+		}
+		n -= interval.end - interval.start
+	}
+	return -1
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -14,10 +14,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/cockroachdb/apd/v2"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/testutils/colcontainerutils"
@@ -68,6 +71,30 @@ func TestWindowFunctions(t *testing.T) {
 	denseRankFn := execinfrapb.WindowerSpec_DENSE_RANK
 	percentRankFn := execinfrapb.WindowerSpec_PERCENT_RANK
 	cumeDistFn := execinfrapb.WindowerSpec_CUME_DIST
+	lagFn := execinfrapb.WindowerSpec_LAG
+	leadFn := execinfrapb.WindowerSpec_LEAD
+	firstValueWindowFn := execinfrapb.WindowerSpec_FIRST_VALUE
+	lastValueWindowFn := execinfrapb.WindowerSpec_LAST_VALUE
+	nthValueWindowFn := execinfrapb.WindowerSpec_NTH_VALUE
+	countFn := execinfrapb.AggregatorSpec_COUNT
+	countRowsFn := execinfrapb.AggregatorSpec_COUNT_ROWS
+	minFn := execinfrapb.AggregatorSpec_MIN
+	sumFn := execinfrapb.AggregatorSpec_SUM
+	maxFn := execinfrapb.AggregatorSpec_MAX
+	oneFollowingFrame := &execinfrapb.WindowerSpec_Frame{
+		Mode: execinfrapb.WindowerSpec_Frame_ROWS,
+		Bounds: execinfrapb.WindowerSpec_Frame_Bounds{
+			Start: execinfrapb.WindowerSpec_Frame_Bound{
+				BoundType: execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING,
+				IntOffset: 1,
+			},
+			End: &execinfrapb.WindowerSpec_Frame_Bound{
+				BoundType: execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING,
+				IntOffset: 1,
+			},
+		},
+	}
+	encodedOne := encodeWindowFrameOffset(t, tree.NewDInt(1))
 	accounts := make([]*mon.BoundAccount, 0)
 	monitors := make([]*mon.BytesMonitor, 0)
 	for _, spillForced := range []bool{false, true} {
@@ -278,6 +305,186 @@ func TestWindowFunctions(t *testing.T) {
 					},
 				},
 			},
+			{
+				tuples:   tuples{{1, 1}, {1, 2}, {1, 3}, {2, 4}, {2, 5}},
+				expected: tuples{{1, 1, nil}, {1, 2, 1}, {1, 3, 2}, {2, 4, nil}, {2, 5, 4}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					PartitionBy: []uint32{0},
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{WindowFunc: &lagFn},
+							ArgsIdxs:     []uint32{1},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 1}}},
+							OutputColIdx: 2,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{4, 1, -4}, {2, 1, -2}, {1, 2, -1}, {3, nil, -3}},
+				expected: tuples{{1, 2, -1, 3}, {2, 1, -2, 3}, {3, nil, -3, nil}, {4, 1, -4, -4}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{WindowFunc: &leadFn},
+							ArgsIdxs:     []uint32{0, 1, 2},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							OutputColIdx: 3,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{3}, {1}, {4}, {2}},
+				expected: tuples{{1, 1}, {2, 1}, {3, 2}, {4, 3}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{WindowFunc: &firstValueWindowFn},
+							ArgsIdxs:     []uint32{0},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							Frame:        oneFollowingFrame,
+							OutputColIdx: 1,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{3}, {1}, {4}, {2}},
+				expected: tuples{{1, 2}, {2, 3}, {3, 4}, {4, 4}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{WindowFunc: &lastValueWindowFn},
+							ArgsIdxs:     []uint32{0},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							Frame:        oneFollowingFrame,
+							OutputColIdx: 1,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{3, 3}, {1, 2}, {3, 1}, {2, 2}},
+				expected: tuples{{1, 2, nil}, {2, 2, 2}, {3, 1, 1}, {3, 3, 3}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{WindowFunc: &nthValueWindowFn},
+							ArgsIdxs:     []uint32{0, 1},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							OutputColIdx: 2,
+						},
+					},
+				},
+			},
+			// Aggregate functions used as window functions.
+			{
+				tuples:   tuples{{3}, {1}, {3}, {2}},
+				expected: tuples{{1, 1}, {2, 2}, {3, 3}, {3, 3}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{AggregateFunc: &maxFn},
+							ArgsIdxs:     []uint32{0},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							OutputColIdx: 1,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{3}, {1}, {4}, {2}},
+				expected: tuples{{1, *apd.New(1, 0)}, {2, *apd.New(3, 0)}, {3, *apd.New(6, 0)}, {4, *apd.New(10, 0)}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:         execinfrapb.WindowerSpec_Func{AggregateFunc: &sumFn},
+							ArgsIdxs:     []uint32{0},
+							Ordering:     execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							OutputColIdx: 1,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{1, 3}, {1, 1}, {2, 5}, {1, 2}},
+				expected: tuples{{1, 1, 2}, {1, 2, 3}, {1, 3, nil}, {2, 5, nil}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					PartitionBy: []uint32{0},
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:     execinfrapb.WindowerSpec_Func{AggregateFunc: &minFn},
+							ArgsIdxs: []uint32{1},
+							Ordering: execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 1}}},
+							Frame: &execinfrapb.WindowerSpec_Frame{
+								Mode: execinfrapb.WindowerSpec_Frame_ROWS,
+								Bounds: execinfrapb.WindowerSpec_Frame_Bounds{
+									Start: execinfrapb.WindowerSpec_Frame_Bound{
+										BoundType: execinfrapb.WindowerSpec_Frame_CURRENT_ROW,
+									},
+									End: &execinfrapb.WindowerSpec_Frame_Bound{
+										BoundType: execinfrapb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING,
+									},
+								},
+								Exclusion: execinfrapb.WindowerSpec_Frame_EXCLUDE_CURRENT_ROW,
+							},
+							OutputColIdx: 2,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{3}, {1}, {2}, {1}, {3}},
+				expected: tuples{{1, 2}, {1, 2}, {2, 3}, {3, 3}, {3, 3}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:     execinfrapb.WindowerSpec_Func{AggregateFunc: &countRowsFn},
+							Ordering: execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							Frame: &execinfrapb.WindowerSpec_Frame{
+								Mode: execinfrapb.WindowerSpec_Frame_GROUPS,
+								Bounds: execinfrapb.WindowerSpec_Frame_Bounds{
+									Start: execinfrapb.WindowerSpec_Frame_Bound{
+										BoundType: execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING,
+										IntOffset: 1,
+									},
+								},
+							},
+							OutputColIdx: 1,
+						},
+					},
+				},
+			},
+			{
+				tuples:   tuples{{5}, {2}, {nil}, {1}, {2}},
+				expected: tuples{{nil, 0}, {1, 3}, {2, 3}, {2, 3}, {5, 1}},
+				windowerSpec: execinfrapb.WindowerSpec{
+					WindowFns: []execinfrapb.WindowerSpec_WindowFn{
+						{
+							Func:     execinfrapb.WindowerSpec_Func{AggregateFunc: &countFn},
+							ArgsIdxs: []uint32{0},
+							Ordering: execinfrapb.Ordering{Columns: []execinfrapb.Ordering_Column{{ColIdx: 0}}},
+							Frame: &execinfrapb.WindowerSpec_Frame{
+								Mode: execinfrapb.WindowerSpec_Frame_RANGE,
+								Bounds: execinfrapb.WindowerSpec_Frame_Bounds{
+									Start: execinfrapb.WindowerSpec_Frame_Bound{
+										BoundType:   execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING,
+										TypedOffset: encodedOne,
+										OffsetType:  execinfrapb.DatumInfo{Encoding: descpb.DatumEncoding_VALUE, Type: types.Int},
+									},
+									End: &execinfrapb.WindowerSpec_Frame_Bound{
+										BoundType:   execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING,
+										TypedOffset: encodedOne,
+										OffsetType:  execinfrapb.DatumInfo{Encoding: descpb.DatumEncoding_VALUE, Type: types.Int},
+									},
+								},
+							},
+							OutputColIdx: 1,
+						},
+					},
+				},
+			},
 		} {
 			log.Infof(ctx, "spillForced=%t/%s", spillForced, tc.windowerSpec.WindowFns[0].Func.String())
 			var semsToCheck []semaphore.Semaphore
@@ -287,10 +494,14 @@ func TestWindowFunctions(t *testing.T) {
 				for i := range ct {
 					ct[i] = types.Int
 				}
-				resultType := types.Int
-				wf := tc.windowerSpec.WindowFns[0].Func.WindowFunc
-				if wf == &percentRankFn || wf == &cumeDistFn {
-					resultType = types.Float
+				wf := tc.windowerSpec.WindowFns[0]
+				argTypes := make([]*types.T, len(wf.ArgsIdxs))
+				for i, idx := range wf.ArgsIdxs {
+					argTypes[i] = ct[idx]
+				}
+				_, resultType, err := execinfrapb.GetWindowFunctionInfo(wf.Func, argTypes...)
+				if err != nil {
+					return nil, err
 				}
 				spec := &execinfrapb.ProcessorSpec{
 					Input: []execinfrapb.InputSyncSpec{{ColumnTypes: ct}},
@@ -329,3 +540,14 @@ func TestWindowFunctions(t *testing.T) {
 		m.Stop(ctx)
 	}
 }
+
+// encodeWindowFrameOffset returns the encoding of the given RANGE mode offset
+// as it is stored in the window frame bound.
+func encodeWindowFrameOffset(t *testing.T, offset tree.Datum) []byte {
+	var a rowenc.DatumAlloc
+	typ := offset.ResolvedType()
+	datum := rowenc.DatumToEncDatum(typ, offset)
+	encoded, err := datum.Encode(typ, &a, descpb.DatumEncoding_VALUE, nil /* appendTo */)
+	require.NoError(t, err)
+	return encoded
+}
@@ -234,7 +234,9 @@ func NewColBatchScan(
 	fetcher := cFetcherPool.Get().(*cFetcher)
 	if _, _, err := initCRowFetcher(
 		flowCtx.Codec(), allocator, execinfra.GetWorkMemLimit(flowCtx.Cfg),
-		fetcher, table, columnIdxMap, neededColumns, spec, spec.HasSystemColumns,
+		fetcher, table, columnIdxMap, neededColumns, int(spec.IndexIdx), spec.Reverse,
+		spec.Visibility, spec.LockingStrength, spec.LockingWaitPolicy,
+		spec.HasSystemColumns, virtualColumn,
 	); err != nil {
 		return nil, err
 	}
@@ -268,10 +270,14 @@ func initCRowFetcher(
 	desc catalog.TableDescriptor,
 	colIdxMap catalog.TableColMap,
 	valNeededForCol util.FastIntSet,
-	spec *execinfrapb.TableReaderSpec,
+	indexIdx int,
+	reverse bool,
+	visibility execinfrapb.ScanVisibility,
+	lockStrength descpb.ScanLockingStrength,
+	lockWaitPolicy descpb.ScanLockingWaitPolicy,
 	withSystemColumns bool,
+	virtualColumn catalog.Column,
 ) (index *descpb.IndexDescriptor, isSecondaryIndex bool, err error) {
-	indexIdx := int(spec.IndexIdx)
 	if indexIdx >= len(desc.ActiveIndexes()) {
 		return nil, false, errors.Errorf("invalid indexIdx %d", indexIdx)
 	}
@@ -287,11 +293,10 @@ func initCRowFetcher(
 		ValNeededForCol:  valNeededForCol,
 	}
 
-	virtualColumn := tabledesc.FindVirtualColumn(desc, spec.VirtualColumn)
-	tableArgs.InitCols(desc, spec.Visibility, withSystemColumns, virtualColumn)
+	tableArgs.InitCols(desc, visibility, withSystemColumns, virtualColumn)
 
 	if err := fetcher.Init(
-		codec, allocator, memoryLimit, spec.Reverse, spec.LockingStrength, spec.LockingWaitPolicy, tableArgs,
+		codec, allocator, memoryLimit, reverse, lockStrength, lockWaitPolicy, tableArgs,
 	); err != nil {
 		return nil, false, err
 	}
@@ -0,0 +1,219 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// indexJoinSpansMemLimit is the limit on the memory used by the lookup spans
+// of a single batch of lookups performed by the ColIndexJoin. It matches the
+// batch size used by the row-by-row index join.
+const indexJoinSpansMemLimit = 4 << 20 /* 4 MiB */
+
+type indexJoinState int
+
+const (
+	// indexJoinConstructingSpans is the state in which the ColIndexJoin reads
+	// the tuples from its input and constructs the lookup spans for them until
+	// either the memory limit is reached or the input is exhausted.
+	indexJoinConstructingSpans indexJoinState = iota
+	// indexJoinFetching is the state in which the ColIndexJoin outputs the
+	// rows looked up for the current batch of spans.
+	indexJoinFetching
+	// indexJoinDone is the state in which the ColIndexJoin has looked up the
+	// rows for all of its input tuples.
+	indexJoinDone
+)
+
+// ColIndexJoin is the vectorized implementation of the index join. Its input
+// tuples contain the primary key columns of the table in the first positions
+// (usually, the input comes from a scan of a secondary index), and for each of
+// them the corresponding row of the primary index is looked up. The looked up
+// rows are output as is, meaning that the input columns are not part of the
+// output.
+type ColIndexJoin struct {
+	lookupBase
+
+	state indexJoinState
+	// maintainOrdering indicates whether the looked up rows must be output in
+	// the same order as the input tuples.
+	maintainOrdering bool
+
+	// inputBatch is the last batch read from the input, and nextInputIdx is
+	// the position of the first tuple in it for which the lookup span hasn't
+	// been constructed yet.
+	inputBatch   coldata.Batch
+	nextInputIdx int
+	inputDone    bool
+
+	// ResultTypes is the slice of resulting column types from this operator.
+	ResultTypes []*types.T
+}
+
+var _ execinfra.KVReader = &ColIndexJoin{}
+var _ execinfra.Releasable = &ColIndexJoin{}
+var _ colexecbase.Closer = &ColIndexJoin{}
+var _ colexecbase.Operator = &ColIndexJoin{}
+
+// Init initializes a ColIndexJoin.
+func (s *ColIndexJoin) Init() {
+	s.input.Init()
+	s.init = true
+}
+
+// Next is part of the Operator interface.
+func (s *ColIndexJoin) Next(ctx context.Context) coldata.Batch {
+	s.maybeStartTracing(ctx, "colindexjoin")
+	for {
+		switch s.state {
+		case indexJoinConstructingSpans:
+			for !s.inputDone && s.spansMemUsage < indexJoinSpansMemLimit {
+				if s.inputBatch == nil || s.nextInputIdx == s.inputBatch.Length() {
+					s.inputBatch = s.input.Next(ctx)
+					s.nextInputIdx = 0
+					if s.inputBatch.Length() == 0 {
+						s.inputDone = true
+						break
+					}
+					s.spanGen.convertBatch(s.inputBatch)
+				}
+				for n := s.inputBatch.Length(); s.nextInputIdx < n && s.spansMemUsage < indexJoinSpansMemLimit; s.nextInputIdx++ {
+					lookupSpan, hasNullLookupCol, containsNull, err := s.spanGen.generateSpan(s.nextInputIdx)
+					if err != nil {
+						colexecerror.InternalError(err)
+					}
+					if hasNullLookupCol {
+						colexecerror.InternalError(errors.AssertionFailedf("unexpected NULL primary key value in index join"))
+					}
+					s.addSpan(lookupSpan, containsNull)
+				}
+			}
+			if len(s.spans) == 0 {
+				s.state = indexJoinDone
+				continue
+			}
+			// The looked up rows are output in the order of the spans, so we can
+			// only sort the spans if we don't have to maintain the ordering.
+			if err := s.startScan(!s.maintainOrdering /* sortSpans */); err != nil {
+				colexecerror.InternalError(err)
+			}
+			s.state = indexJoinFetching
+
+		case indexJoinFetching:
+			batch, err := s.rf.NextBatch(s.ctx)
+			if err != nil {
+				colexecerror.InternalError(err)
+			}
+			if batch.Length() == 0 {
+				s.resetSpans()
+				s.state = indexJoinConstructingSpans
+				continue
+			}
+			s.rowsRead += int64(batch.Length())
+			return batch
+
+		case indexJoinDone:
+			return coldata.ZeroBatch
+
+		default:
+			colexecerror.InternalError(errors.AssertionFailedf("unexpected indexJoinState %d", s.state))
+		}
+	}
+}
+
+// NewColIndexJoin creates a new ColIndexJoin operator. The allocator is used
+// both for the output batches and for the accounting of the memory used by the
+// lookup spans.
+func NewColIndexJoin(
+	ctx context.Context,
+	allocator *colmem.Allocator,
+	flowCtx *execinfra.FlowCtx,
+	evalCtx *tree.EvalContext,
+	input colexecbase.Operator,
+	spec *execinfrapb.JoinReaderSpec,
+	post *execinfrapb.PostProcessSpec,
+	inputTypes []*types.T,
+) (*ColIndexJoin, error) {
+	// NB: we hit this with a zero NodeID (but !ok) with multi-tenancy.
+	if nodeID, ok := flowCtx.NodeID.OptionalNodeID(); nodeID == 0 && ok {
+		return nil, errors.Errorf("attempting to create a ColIndexJoin with uninitialized NodeID")
+	}
+	if len(spec.LookupColumns) != 0 {
+		return nil, errors.AssertionFailedf("index join must not have lookup columns")
+	}
+	if spec.IndexIdx != 0 {
+		return nil, errors.AssertionFailedf("index join must be against primary index")
+	}
+	if spec.Type != descpb.InnerJoin || !spec.OnExpr.Empty() {
+		return nil, errors.AssertionFailedf("only inner index joins without ON expression are supported")
+	}
+
+	table := tabledesc.NewImmutable(spec.Table)
+	tableCols, err := getTableColumns(ctx, flowCtx, evalCtx, table, spec.Visibility, spec.HasSystemColumns)
+	if err != nil {
+		return nil, err
+	}
+	// The output of the index join consists only of the table columns.
+	neededCols, err := getNeededColumns(flowCtx, evalCtx, post, execinfrapb.Expression{}, tableCols.typs)
+	if err != nil {
+		return nil, err
+	}
+	numLookupCols := len(table.GetPrimaryIndex().IndexDesc().ColumnIDs)
+	if numLookupCols > len(inputTypes) {
+		return nil, errors.AssertionFailedf(
+			"index join input has %d columns, expected at least %d", len(inputTypes), numLookupCols)
+	}
+	fetcher := cFetcherPool.Get().(*cFetcher)
+	index, err := initLookupFetcher(flowCtx, allocator, fetcher, table, tableCols, spec, neededCols, numLookupCols)
+	if err != nil {
+		return nil, err
+	}
+
+	spanBuilder := span.MakeBuilder(evalCtx, flowCtx.Codec(), table, index)
+	spanBuilder.SetNeededColumns(neededCols)
+	keyTypes, _ := getIndexKeyTypes(index, tableCols, numLookupCols)
+	lookupCols := make([]int, numLookupCols)
+	for i := range lookupCols {
+		lookupCols[i] = i
+	}
+
+	s := &ColIndexJoin{
+		lookupBase: lookupBase{
+			input:     input,
+			flowCtx:   flowCtx,
+			rf:        fetcher,
+			allocator: allocator,
+		},
+		maintainOrdering: spec.MaintainOrdering,
+		ResultTypes:      tableCols.typs,
+	}
+	s.spanGen.init(spanBuilder, len(inputTypes), lookupCols, keyTypes)
+	return s, nil
+}
+
+// Release implements the execinfra.Releasable interface.
+func (s *ColIndexJoin) Release() {
+	s.release()
+}
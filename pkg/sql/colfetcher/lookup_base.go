@@ -0,0 +1,414 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/colconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// lookupBase contains the state and the methods shared by ColIndexJoin and
+// ColLookupJoin, both of which perform lookups into an index of a table for
+// the tuples read from their input.
+type lookupBase struct {
+	input   colexecbase.Operator
+	flowCtx *execinfra.FlowCtx
+	rf      *cFetcher
+	// allocator is used to account for the memory used by the lookup spans
+	// (and, in case of the lookup join, by the buffered input tuples).
+	allocator *colmem.Allocator
+
+	spanGen lookupSpanGenerator
+	// spans are the lookup spans for the current batch of input tuples, and
+	// spansMemUsage is the amount of memory registered with the allocator for
+	// them.
+	spans         roachpb.Spans
+	spansMemUsage int64
+	// limitBatches indicates whether the KV batches of the lookups should be
+	// limited.
+	limitBatches bool
+
+	ctx context.Context
+	// tracingSpan is created when the stats should be collected for the query
+	// execution, and it will be finished when closing the operator.
+	tracingSpan *tracing.Span
+	// rowsRead contains the number of total rows this operator has looked up
+	// so far.
+	rowsRead int64
+	// init is true after Init() has been called.
+	init bool
+}
+
+var _ execinfra.KVReader = &lookupBase{}
+var _ execinfra.OpNode = &lookupBase{}
+var _ colexecbase.Closer = &lookupBase{}
+
+// spanOverhead is the memory footprint of a single roachpb.Span (not including
+// the keys themselves).
+const spanOverhead = int64(unsafe.Sizeof(roachpb.Span{}))
+
+// ChildCount implements the execinfra.OpNode interface.
+func (b *lookupBase) ChildCount(verbose bool) int {
+	return 1
+}
+
+// Child implements the execinfra.OpNode interface.
+func (b *lookupBase) Child(nth int, verbose bool) execinfra.OpNode {
+	if nth == 0 {
+		return b.input
+	}
+	colexecerror.InternalError(errors.AssertionFailedf("invalid index %d", nth))
+	// This code is unreachable, but the compiler cannot infer that.
+	return nil
+}
+
+// maybeStartTracing captures the context passed into the first Next() call
+// and possibly replaces it with a child one when the stats are collected.
+func (b *lookupBase) maybeStartTracing(ctx context.Context, opName string) {
+	if b.ctx == nil {
+		b.ctx = ctx
+		if execinfra.ShouldCollectStats(b.ctx, b.flowCtx) {
+			// We need to start a child span so that the only contention events
+			// present in the recording would be because of this cFetcher.
+			b.ctx, b.tracingSpan = execinfra.ProcessorSpan(b.ctx, opName)
+		}
+	}
+}
+
+// addSpan appends the given span (possibly split into several column family
+// specific spans) to the lookup spans of the current input batch.
+func (b *lookupBase) addSpan(s roachpb.Span, containsNull bool) {
+	oldLen := len(b.spans)
+	b.spans = b.spanGen.spanBuilder.MaybeSplitSpanIntoSeparateFamilies(
+		b.spans, s, b.spanGen.numLookupCols, containsNull,
+	)
+	var memUsage int64
+	for _, newSpan := range b.spans[oldLen:] {
+		memUsage += spanOverhead + int64(len(newSpan.Key)+len(newSpan.EndKey))
+	}
+	b.allocator.AdjustMemoryUsage(memUsage)
+	b.spansMemUsage += memUsage
+}
+
+// startScan starts the lookup of the accumulated spans. If sortSpans is true,
+// the spans are sorted which allows the lower layers to optimize the
+// iteration over the data; note that in this case the looked up rows are not
+// returned in the order of the input tuples.
+func (b *lookupBase) startScan(sortSpans bool) error {
+	if sortSpans {
+		sort.Sort(b.spans)
+	}
+	log.VEventf(b.ctx, 1, "scanning %d spans", len(b.spans))
+	return b.rf.StartScan(
+		b.flowCtx.Txn, b.spans, b.limitBatches, 0, /* limitHint */
+		b.flowCtx.TraceKV, b.flowCtx.EvalCtx.TestingKnobs.ForceProductionBatchSizes,
+	)
+}
+
+// resetSpans discards the lookup spans of the current input batch and
+// releases the memory used by them. It must only be called once the scan of
+// the spans has been exhausted.
+func (b *lookupBase) resetSpans() {
+	b.allocator.ReleaseMemory(b.spansMemUsage)
+	b.spansMemUsage = 0
+	b.spans = b.spans[:0]
+}
+
+// DrainMeta is part of the MetadataSource interface.
+func (b *lookupBase) DrainMeta(ctx context.Context) []execinfrapb.ProducerMetadata {
+	if !b.init {
+		// Init() and Next() might never get called, in which case the fetcher
+		// is uninitialized.
+		return nil
+	}
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(ctx, b.flowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead = b.GetBytesRead()
+	meta.Metrics.RowsRead = b.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	if b.tracingSpan != nil {
+		// See the comment in ColBatchScan.DrainMeta for why we collect the
+		// trace data only when we have derived a new context.
+		if trace := execinfra.GetTraceData(b.ctx); trace != nil {
+			trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{TraceData: trace})
+		}
+	}
+	return trailingMeta
+}
+
+// GetBytesRead is part of the execinfra.KVReader interface.
+func (b *lookupBase) GetBytesRead() int64 {
+	if b.rf.fetcher == nil {
+		// No lookups have been performed.
+		return 0
+	}
+	return b.rf.fetcher.GetBytesRead()
+}
+
+// GetRowsRead is part of the execinfra.KVReader interface.
+func (b *lookupBase) GetRowsRead() int64 {
+	return b.rowsRead
+}
+
+// GetCumulativeContentionTime is part of the execinfra.KVReader interface.
+func (b *lookupBase) GetCumulativeContentionTime() time.Duration {
+	if b.ctx == nil {
+		// Next was never called, so there was no contention events.
+		return 0
+	}
+	return execinfra.GetCumulativeContentionTime(b.ctx)
+}
+
+// Close implements the colexecbase.Closer interface.
+func (b *lookupBase) Close(context.Context) error {
+	if b.tracingSpan != nil {
+		b.tracingSpan.Finish()
+		b.tracingSpan = nil
+	}
+	return nil
+}
+
+// release releases the fetcher and the converter used by the lookupBase.
+func (b *lookupBase) release() {
+	b.rf.Release()
+	b.spanGen.converter.Release()
+}
+
+// lookupSpanGenerator generates the lookup spans for the tuples of batches.
+// The values of the lookup columns are converted to datums and are encoded
+// into the keys of the index.
+type lookupSpanGenerator struct {
+	spanBuilder *span.Builder
+	// lookupCols are the indices of the columns in the converted batches that
+	// correspond to the first numLookupCols columns of the index.
+	lookupCols    []int
+	numLookupCols int
+	keyTypes      []*types.T
+	converter     *colconv.VecToDatumConverter
+	scratch       rowenc.EncDatumRow
+}
+
+func (g *lookupSpanGenerator) init(
+	spanBuilder *span.Builder, batchWidth int, lookupCols []int, keyTypes []*types.T,
+) {
+	*g = lookupSpanGenerator{
+		spanBuilder:   spanBuilder,
+		lookupCols:    lookupCols,
+		numLookupCols: len(lookupCols),
+		keyTypes:      keyTypes,
+		converter:     colconv.NewVecToDatumConverter(batchWidth, lookupCols),
+		scratch:       make(rowenc.EncDatumRow, len(lookupCols)),
+	}
+}
+
+// convertBatch converts the lookup columns of the batch to datums. The tuples
+// of the batch can then be referred to by their position in the batch with the
+// selection vector applied.
+func (g *lookupSpanGenerator) convertBatch(batch coldata.Batch) {
+	g.converter.ConvertBatchAndDeselect(batch)
+}
+
+// generateSpan returns the lookup span for the tuple at position tupleIdx of
+// the last converted batch. hasNullLookupCol is true if any of the lookup
+// columns of the tuple is NULL (such a tuple cannot match any row when
+// performing a join, and the returned span is invalid).
+func (g *lookupSpanGenerator) generateSpan(
+	tupleIdx int,
+) (_ roachpb.Span, hasNullLookupCol bool, containsNull bool, _ error) {
+	for i, colIdx := range g.lookupCols {
+		d := g.converter.GetDatumColumn(colIdx)[tupleIdx]
+		if d == tree.DNull {
+			return roachpb.Span{}, true, false, nil
+		}
+		g.scratch[i] = rowenc.DatumToEncDatum(g.keyTypes[i], d)
+	}
+	s, containsNull, err := g.spanBuilder.SpanFromEncDatums(g.scratch, g.numLookupCols)
+	return s, false, containsNull, err
+}
+
+// tableColumns contains the information about the columns of a table looked
+// up by an index or a lookup join.
+type tableColumns struct {
+	colIdxMap catalog.TableColMap
+	typs      []*types.T
+}
+
+// getTableColumns returns the columns of the table that are produced by the
+// fetcher given the visibility and whether the system columns are requested.
+func getTableColumns(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	evalCtx *tree.EvalContext,
+	table catalog.TableDescriptor,
+	visibility execinfrapb.ScanVisibility,
+	hasSystemColumns bool,
+) (tableColumns, error) {
+	cols := table.PublicColumns()
+	if visibility == execinfra.ScanVisibilityPublicAndNotPublic {
+		cols = table.DeletableColumns()
+	}
+	res := tableColumns{
+		colIdxMap: catalog.ColumnIDToOrdinalMap(cols),
+		typs:      catalog.ColumnTypes(cols),
+	}
+	if hasSystemColumns {
+		for _, sysCol := range table.SystemColumns() {
+			res.typs = append(res.typs, sysCol.GetType())
+			res.colIdxMap.Set(sysCol.GetID(), res.colIdxMap.Len())
+		}
+	}
+	// Neither the lookup operators nor the cFetcher are processors, so we
+	// need to hydrate the types ourselves (see the comment in
+	// NewColBatchScan).
+	resolver := flowCtx.TypeResolverFactory.NewTypeResolver(evalCtx.Txn)
+	if err := resolver.HydrateTypeSlice(ctx, res.typs); err != nil {
+		return tableColumns{}, err
+	}
+	return res, nil
+}
+
+// getNeededColumns returns the set of the columns produced by an operator
+// (with the given types) that are used either by the post-processing spec or
+// by the ON expression.
+func getNeededColumns(
+	flowCtx *execinfra.FlowCtx,
+	evalCtx *tree.EvalContext,
+	post *execinfrapb.PostProcessSpec,
+	onExpr execinfrapb.Expression,
+	typs []*types.T,
+) (util.FastIntSet, error) {
+	var neededCols util.FastIntSet
+	if !post.Projection && len(post.RenderExprs) == 0 {
+		// No projection or rendering; all columns are needed.
+		neededCols.AddRange(0, len(typs)-1)
+		return neededCols, nil
+	}
+	for _, c := range post.OutputColumns {
+		neededCols.Add(int(c))
+	}
+	semaCtx := flowCtx.TypeResolverFactory.NewSemaContext(evalCtx.Txn)
+	addUsedVars := func(expr execinfrapb.Expression) error {
+		if expr.Empty() {
+			return nil
+		}
+		var helper execinfrapb.ExprHelper
+		if err := helper.Init(expr, typs, semaCtx, evalCtx); err != nil {
+			return err
+		}
+		for i := range typs {
+			if helper.Vars.IndexedVarUsed(i) {
+				neededCols.Add(i)
+			}
+		}
+		return nil
+	}
+	for _, renderExpr := range post.RenderExprs {
+		if err := addUsedVars(renderExpr); err != nil {
+			return util.FastIntSet{}, err
+		}
+	}
+	if err := addUsedVars(onExpr); err != nil {
+		return util.FastIntSet{}, err
+	}
+	return neededCols, nil
+}
+
+// initLookupFetcher initializes the cFetcher that performs the lookups into
+// the index specified by spec. neededTableCols is the set of the table
+// columns that need to be decoded; the index key columns used for the lookups
+// are decoded as well. It returns the index being looked up.
+func initLookupFetcher(
+	flowCtx *execinfra.FlowCtx,
+	allocator *colmem.Allocator,
+	fetcher *cFetcher,
+	table catalog.TableDescriptor,
+	tableCols tableColumns,
+	spec *execinfrapb.JoinReaderSpec,
+	neededTableCols util.FastIntSet,
+	numLookupCols int,
+) (*descpb.IndexDescriptor, error) {
+	indexIdx := int(spec.IndexIdx)
+	if indexIdx >= len(table.ActiveIndexes()) {
+		return nil, errors.Errorf("invalid indexIdx %d", indexIdx)
+	}
+	index := table.ActiveIndexes()[indexIdx].IndexDesc()
+	columnIDs, _ := index.FullColumnIDs()
+	if numLookupCols > len(columnIDs) {
+		return nil, errors.Errorf(
+			"%d lookup columns specified, expecting at most %d", numLookupCols, len(columnIDs))
+	}
+	valNeededForCol := neededTableCols.Copy()
+	for _, id := range columnIDs[:numLookupCols] {
+		valNeededForCol.Add(tableCols.colIdxMap.GetDefault(id))
+	}
+	_, isSecondaryIndex, err := initCRowFetcher(
+		flowCtx.Codec(), allocator, execinfra.GetWorkMemLimit(flowCtx.Cfg),
+		fetcher, table, tableCols.colIdxMap, valNeededForCol, indexIdx, false, /* reverse */
+		spec.Visibility, spec.LockingStrength, spec.LockingWaitPolicy,
+		spec.HasSystemColumns, nil, /* virtualColumn */
+	)
+	if err != nil {
+		return nil, err
+	}
+	if isSecondaryIndex {
+		var indexCols util.FastIntSet
+		if err := index.RunOverAllColumns(func(id descpb.ColumnID) error {
+			indexCols.Add(tableCols.colIdxMap.GetDefault(id))
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if !neededTableCols.SubsetOf(indexCols) {
+			return nil, errors.Errorf("joinreader index does not cover all columns")
+		}
+	}
+	return index, nil
+}
+
+// getIndexKeyTypes returns the types of the first numCols columns of the
+// index.
+func getIndexKeyTypes(
+	index *descpb.IndexDescriptor, tableCols tableColumns, numCols int,
+) (keyTypes []*types.T, tableColIdxs []int) {
+	columnIDs, _ := index.FullColumnIDs()
+	keyTypes = make([]*types.T, numCols)
+	tableColIdxs = make([]int, numCols)
+	for i, id := range columnIDs[:numCols] {
+		tableColIdxs[i] = tableCols.colIdxMap.GetDefault(id)
+		keyTypes[i] = tableCols.typs[tableColIdxs[i]]
+	}
+	return keyTypes, tableColIdxs
+}
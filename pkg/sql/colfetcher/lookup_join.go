@@ -0,0 +1,672 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+// lookupJoinInputMemLimit is the limit on the memory used by the input tuples
+// buffered by the ColLookupJoin before performing the lookups for them. It
+// matches the batch size used by the row-by-row lookup join that doesn't
+// maintain the ordering.
+const lookupJoinInputMemLimit = 2 << 20 /* 2 MiB */
+
+const (
+	sizeOfInt = int64(unsafe.Sizeof(int(0)))
+	// keyToInputIdxsEntryOverhead is the approximate memory footprint of a
+	// single entry in keyToInputIdxs map (not including the key bytes and the
+	// elements of the slice).
+	keyToInputIdxsEntryOverhead = int64(unsafe.Sizeof("")) + int64(unsafe.Sizeof([]int{}))
+)
+
+type lookupJoinState int
+
+const (
+	// lookupJoinReadingInput is the state in which the ColLookupJoin buffers
+	// the tuples from its input and constructs the lookup spans for them until
+	// either the memory limit is reached or the input is exhausted.
+	lookupJoinReadingInput lookupJoinState = iota
+	// lookupJoinFetching is the state in which the ColLookupJoin looks up the
+	// rows for the buffered input tuples. If the ordering doesn't need to be
+	// maintained, the looked up rows are joined with the input tuples right
+	// away; otherwise, they are buffered.
+	lookupJoinFetching
+	// lookupJoinEmittingUnmatched is the state in which the ColLookupJoin that
+	// doesn't maintain the ordering emits the input tuples that didn't match
+	// any looked up rows (for left outer and left anti joins).
+	lookupJoinEmittingUnmatched
+	// lookupJoinEmittingInOrder is the state in which the ColLookupJoin that
+	// maintains the ordering emits the joined tuples in the order of the input
+	// tuples.
+	lookupJoinEmittingInOrder
+	// lookupJoinDone is the state in which the ColLookupJoin has processed all
+	// of its input tuples.
+	lookupJoinDone
+)
+
+// ColLookupJoin is the vectorized implementation of the lookup join. For the
+// tuples read from its input, it looks up the rows of an index of a table
+// which have the values of the index key columns equal to the values of the
+// lookup columns of the input tuples.
+//
+// The input tuples are buffered in batches of limited size, and all lookups
+// for such a batch are performed at once (with the duplicate spans removed).
+// The looked up rows are matched with the input tuples by the encoded values
+// of the index key columns. If the ordering of the input must be maintained,
+// the looked up rows are buffered and the output is produced by iterating over
+// the input tuples in order; this is only supported when the lookup columns
+// form a key, so that at most one row is looked up for each input tuple.
+//
+// Only inner, left outer, left semi, and left anti joins are supported. The
+// ON expression is not evaluated by the ColLookupJoin, so it must be planned
+// as a filter on top of the operator (which is only possible for inner joins).
+type ColLookupJoin struct {
+	lookupBase
+
+	state            lookupJoinState
+	joinType         descpb.JoinType
+	maintainOrdering bool
+	inputTypes       []*types.T
+	// inputColsToCopy and lookedUpColsToCopy are the indices of the input and
+	// of the table columns that are needed in the output.
+	inputColsToCopy    []int
+	lookedUpColsToCopy []int
+	inputDone          bool
+
+	// inputBuffer contains the input tuples for which the rows are being
+	// looked up.
+	inputBuffer    coldata.Batch
+	inputBufferLen int
+	// keyToInputIdxs maps the encoded lookup key to the positions of the
+	// buffered input tuples that have it, and keysMemUsage is the amount of
+	// memory registered with the allocator for the map.
+	keyToInputIdxs map[string][]int
+	keysMemUsage   int64
+	// matched indicates, for each buffered input tuple, whether any row has
+	// been looked up for it.
+	matched []bool
+
+	// lookedUpKeyGen is used to get the encoded lookup keys of the looked up
+	// rows.
+	lookedUpKeyGen lookupSpanGenerator
+	// lookedUpBatch is the last batch returned by the fetcher, nextLookedUpIdx
+	// is the position of the first looked up row in it that hasn't been fully
+	// processed, and nextMatchIdx is the position of the first input tuple
+	// among the ones matching that row that hasn't been processed yet.
+	lookedUpBatch   coldata.Batch
+	nextLookedUpIdx int
+	nextMatchIdx    int
+
+	// lookedUpBuffer contains the looked up rows when the ordering needs to be
+	// maintained, and inputToLookedUp contains the position in lookedUpBuffer
+	// of the row matching each buffered input tuple.
+	lookedUpBuffer    coldata.Batch
+	lookedUpBufferLen int
+	inputToLookedUp   []int
+
+	// nextEmitIdx is the position of the first buffered input tuple that
+	// hasn't been considered in lookupJoinEmittingUnmatched or
+	// lookupJoinEmittingInOrder states.
+	nextEmitIdx int
+
+	// inputIdxs and lookedUpIdxs are the positions of the tuples from the
+	// input and from the looked up rows that form the output tuples that
+	// haven't been copied into the output batch yet. An index of -1 in
+	// lookedUpIdxs means that the table columns are NULL.
+	inputIdxs    []int
+	lookedUpIdxs []int
+	scratchSel   []int
+	output       coldata.Batch
+	outputLen    int
+
+	// ResultTypes is the slice of resulting column types from this operator.
+	ResultTypes []*types.T
+}
+
+var _ execinfra.KVReader = &ColLookupJoin{}
+var _ execinfra.Releasable = &ColLookupJoin{}
+var _ colexecbase.Closer = &ColLookupJoin{}
+var _ colexecbase.Operator = &ColLookupJoin{}
+
+// Init initializes a ColLookupJoin.
+func (s *ColLookupJoin) Init() {
+	s.input.Init()
+	s.init = true
+	s.inputBuffer = s.allocator.NewMemBatchWithFixedCapacity(s.inputTypes, 0 /* capacity */)
+	if s.maintainOrdering && s.joinType.ShouldIncludeRightColsInOutput() {
+		s.lookedUpBuffer = s.allocator.NewMemBatchWithFixedCapacity(s.rf.typs, 0 /* capacity */)
+	}
+	s.output = s.allocator.NewMemBatchWithFixedCapacity(s.ResultTypes, coldata.BatchSize())
+	s.inputIdxs = make([]int, 0, coldata.BatchSize())
+	s.lookedUpIdxs = make([]int, 0, coldata.BatchSize())
+	s.scratchSel = make([]int, 0, coldata.BatchSize())
+}
+
+// Next is part of the Operator interface.
+func (s *ColLookupJoin) Next(ctx context.Context) coldata.Batch {
+	s.maybeStartTracing(ctx, "collookupjoin")
+	s.output.ResetInternalBatch()
+	s.outputLen = 0
+	for {
+		switch s.state {
+		case lookupJoinReadingInput:
+			s.readInput(ctx)
+			if s.inputBufferLen == 0 {
+				s.state = lookupJoinDone
+				continue
+			}
+			if len(s.spans) == 0 {
+				// None of the input tuples can match any rows.
+				s.state = s.stateAfterFetching()
+				continue
+			}
+			// The looked up rows are matched with the input tuples by their
+			// keys, so we can always sort the spans.
+			if err := s.startScan(true /* sortSpans */); err != nil {
+				colexecerror.InternalError(err)
+			}
+			s.state = lookupJoinFetching
+
+		case lookupJoinFetching:
+			if s.lookedUpBatch == nil || s.nextLookedUpIdx == s.lookedUpBatch.Length() {
+				// The output tuples referencing the current looked up batch must
+				// be copied before the fetcher overwrites it.
+				s.flush(s.lookedUpBatch)
+				batch, err := s.rf.NextBatch(s.ctx)
+				if err != nil {
+					colexecerror.InternalError(err)
+				}
+				if batch.Length() == 0 {
+					s.lookedUpBatch = nil
+					s.resetSpans()
+					s.state = s.stateAfterFetching()
+					continue
+				}
+				s.rowsRead += int64(batch.Length())
+				s.lookedUpBatch = batch
+				s.nextLookedUpIdx, s.nextMatchIdx = 0, 0
+				s.lookedUpKeyGen.convertBatch(batch)
+			}
+			if s.maintainOrdering {
+				s.bufferLookedUpBatch()
+				continue
+			}
+			if s.joinLookedUpBatch() {
+				s.flush(s.lookedUpBatch)
+				s.output.SetLength(s.outputLen)
+				return s.output
+			}
+
+		case lookupJoinEmittingUnmatched:
+			outputFull := s.emitUnmatched()
+			s.flush(nil /* source */)
+			if outputFull {
+				s.output.SetLength(s.outputLen)
+				return s.output
+			}
+			s.finishInputBatch()
+
+		case lookupJoinEmittingInOrder:
+			outputFull := s.emitInOrder()
+			if s.lookedUpBufferLen > 0 {
+				s.flush(s.lookedUpBuffer)
+			} else {
+				s.flush(nil /* source */)
+			}
+			if outputFull {
+				s.output.SetLength(s.outputLen)
+				return s.output
+			}
+			s.finishInputBatch()
+
+		case lookupJoinDone:
+			if s.outputLen > 0 {
+				s.output.SetLength(s.outputLen)
+				return s.output
+			}
+			return coldata.ZeroBatch
+
+		default:
+			colexecerror.InternalError(errors.AssertionFailedf("unexpected lookupJoinState %d", s.state))
+		}
+	}
+}
+
+// readInput buffers the tuples from the input and constructs the lookup spans
+// for them.
+func (s *ColLookupJoin) readInput(ctx context.Context) {
+	for !s.inputDone &&
+		colmem.GetProportionalBatchMemSize(s.inputBuffer, int64(s.inputBufferLen)) < lookupJoinInputMemLimit {
+		batch := s.input.Next(ctx)
+		n := batch.Length()
+		if n == 0 {
+			s.inputDone = true
+			break
+		}
+		s.spanGen.convertBatch(batch)
+		var keysMemUsage int64
+		for i := 0; i < n; i++ {
+			inputIdx := s.inputBufferLen + i
+			s.matched = append(s.matched, false)
+			if s.maintainOrdering {
+				s.inputToLookedUp = append(s.inputToLookedUp, -1)
+			}
+			lookupSpan, hasNullLookupCol, containsNull, err := s.spanGen.generateSpan(i)
+			if err != nil {
+				colexecerror.InternalError(err)
+			}
+			if hasNullLookupCol {
+				// NULL values never match.
+				continue
+			}
+			key := string(lookupSpan.Key)
+			inputIdxs, ok := s.keyToInputIdxs[key]
+			if !ok {
+				s.addSpan(lookupSpan, containsNull)
+				keysMemUsage += keyToInputIdxsEntryOverhead + int64(len(key))
+			}
+			s.keyToInputIdxs[key] = append(inputIdxs, inputIdx)
+			keysMemUsage += sizeOfInt
+		}
+		s.allocator.AdjustMemoryUsage(keysMemUsage)
+		s.keysMemUsage += keysMemUsage
+		s.allocator.PerformOperation(s.inputBuffer.ColVecs(), func() {
+			for colIdx, vec := range s.inputBuffer.ColVecs() {
+				vec.Append(coldata.SliceArgs{
+					Src:         batch.ColVec(colIdx),
+					Sel:         batch.Selection(),
+					DestIdx:     s.inputBufferLen,
+					SrcStartIdx: 0,
+					SrcEndIdx:   n,
+				})
+			}
+		})
+		s.inputBufferLen += n
+	}
+}
+
+// stateAfterFetching returns the state to transition to once all rows have
+// been looked up for the buffered input tuples.
+func (s *ColLookupJoin) stateAfterFetching() lookupJoinState {
+	if s.maintainOrdering {
+		return lookupJoinEmittingInOrder
+	}
+	return lookupJoinEmittingUnmatched
+}
+
+// finishInputBatch resets the state for the buffered input tuples which have
+// been fully processed.
+func (s *ColLookupJoin) finishInputBatch() {
+	s.inputBufferLen = 0
+	s.matched = s.matched[:0]
+	s.inputToLookedUp = s.inputToLookedUp[:0]
+	s.lookedUpBufferLen = 0
+	s.nextEmitIdx = 0
+	// This loop gets optimized to a runtime.mapclear call.
+	for k := range s.keyToInputIdxs {
+		delete(s.keyToInputIdxs, k)
+	}
+	s.allocator.ReleaseMemory(s.keysMemUsage)
+	s.keysMemUsage = 0
+	s.state = lookupJoinReadingInput
+}
+
+// getMatches returns the positions of the buffered input tuples that match
+// the looked up row at position lookedUpIdx of the last looked up batch.
+func (s *ColLookupJoin) getMatches(lookedUpIdx int) []int {
+	lookupSpan, hasNullLookupCol, _, err := s.lookedUpKeyGen.generateSpan(lookedUpIdx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	if hasNullLookupCol {
+		return nil
+	}
+	return s.keyToInputIdxs[string(lookupSpan.Key)]
+}
+
+// outputFull returns whether the output batch will be full once the pending
+// output tuples are copied into it.
+func (s *ColLookupJoin) outputFull() bool {
+	return s.outputLen+len(s.inputIdxs) == coldata.BatchSize()
+}
+
+func (s *ColLookupJoin) addOutputTuple(inputIdx, lookedUpIdx int) {
+	s.inputIdxs = append(s.inputIdxs, inputIdx)
+	s.lookedUpIdxs = append(s.lookedUpIdxs, lookedUpIdx)
+}
+
+// joinLookedUpBatch joins the rows of the last looked up batch with the
+// matching input tuples. It returns true if the output batch became full, in
+// which case joinLookedUpBatch must be called again to continue processing of
+// the looked up batch.
+func (s *ColLookupJoin) joinLookedUpBatch() (outputFull bool) {
+	for n := s.lookedUpBatch.Length(); s.nextLookedUpIdx < n; s.nextLookedUpIdx, s.nextMatchIdx = s.nextLookedUpIdx+1, 0 {
+		matches := s.getMatches(s.nextLookedUpIdx)
+		for ; s.nextMatchIdx < len(matches); s.nextMatchIdx++ {
+			if s.outputFull() {
+				return true
+			}
+			inputIdx := matches[s.nextMatchIdx]
+			switch s.joinType {
+			case descpb.InnerJoin, descpb.LeftOuterJoin:
+				s.matched[inputIdx] = true
+				s.addOutputTuple(inputIdx, s.nextLookedUpIdx)
+			case descpb.LeftSemiJoin:
+				if !s.matched[inputIdx] {
+					s.matched[inputIdx] = true
+					s.addOutputTuple(inputIdx, -1 /* lookedUpIdx */)
+				}
+			case descpb.LeftAntiJoin:
+				s.matched[inputIdx] = true
+			}
+		}
+	}
+	return false
+}
+
+// emitUnmatched emits the buffered input tuples that didn't match any looked
+// up rows, if required by the join type. It returns true if the output batch
+// became full.
+func (s *ColLookupJoin) emitUnmatched() (outputFull bool) {
+	if s.joinType != descpb.LeftOuterJoin && s.joinType != descpb.LeftAntiJoin {
+		return false
+	}
+	for ; s.nextEmitIdx < s.inputBufferLen; s.nextEmitIdx++ {
+		if s.outputFull() {
+			return true
+		}
+		if !s.matched[s.nextEmitIdx] {
+			s.addOutputTuple(s.nextEmitIdx, -1 /* lookedUpIdx */)
+		}
+	}
+	return false
+}
+
+// bufferLookedUpBatch processes all rows of the last looked up batch when the
+// ordering needs to be maintained: the input tuples matching the rows are
+// marked as matched and, if the table columns are part of the output, the
+// rows are appended to the lookedUpBuffer.
+func (s *ColLookupJoin) bufferLookedUpBatch() {
+	n := s.lookedUpBatch.Length()
+	includeRight := s.joinType.ShouldIncludeRightColsInOutput()
+	for i := 0; i < n; i++ {
+		for _, inputIdx := range s.getMatches(i) {
+			if s.matched[inputIdx] {
+				colexecerror.InternalError(errors.AssertionFailedf(
+					"multiple rows looked up for a single input tuple when lookup columns are a key",
+				))
+			}
+			s.matched[inputIdx] = true
+			if includeRight {
+				s.inputToLookedUp[inputIdx] = s.lookedUpBufferLen + i
+			}
+		}
+	}
+	if includeRight {
+		s.allocator.PerformOperation(s.lookedUpBuffer.ColVecs(), func() {
+			for _, colIdx := range s.lookedUpColsToCopy {
+				s.lookedUpBuffer.ColVec(colIdx).Append(coldata.SliceArgs{
+					Src:         s.lookedUpBatch.ColVec(colIdx),
+					DestIdx:     s.lookedUpBufferLen,
+					SrcStartIdx: 0,
+					SrcEndIdx:   n,
+				})
+			}
+		})
+		s.lookedUpBufferLen += n
+	}
+	s.nextLookedUpIdx = n
+}
+
+// emitInOrder emits the output tuples in the order of the buffered input
+// tuples when the ordering needs to be maintained. It returns true if the
+// output batch became full.
+func (s *ColLookupJoin) emitInOrder() (outputFull bool) {
+	for ; s.nextEmitIdx < s.inputBufferLen; s.nextEmitIdx++ {
+		if s.outputFull() {
+			return true
+		}
+		matched := s.matched[s.nextEmitIdx]
+		switch s.joinType {
+		case descpb.InnerJoin:
+			if matched {
+				s.addOutputTuple(s.nextEmitIdx, s.inputToLookedUp[s.nextEmitIdx])
+			}
+		case descpb.LeftOuterJoin:
+			s.addOutputTuple(s.nextEmitIdx, s.inputToLookedUp[s.nextEmitIdx])
+		case descpb.LeftSemiJoin:
+			if matched {
+				s.addOutputTuple(s.nextEmitIdx, -1 /* lookedUpIdx */)
+			}
+		case descpb.LeftAntiJoin:
+			if !matched {
+				s.addOutputTuple(s.nextEmitIdx, -1 /* lookedUpIdx */)
+			}
+		}
+	}
+	return false
+}
+
+// flush copies the pending output tuples into the output batch. source is the
+// batch that contains the looked up rows referenced by the pending output
+// tuples; it can be nil if all of them have NULL table columns.
+func (s *ColLookupJoin) flush(source coldata.Batch) {
+	n := len(s.inputIdxs)
+	if n == 0 {
+		return
+	}
+	s.allocator.PerformOperation(s.output.ColVecs(), func() {
+		for _, colIdx := range s.inputColsToCopy {
+			s.output.ColVec(colIdx).Copy(coldata.CopySliceArgs{
+				SliceArgs: coldata.SliceArgs{
+					Src:         s.inputBuffer.ColVec(colIdx),
+					Sel:         s.inputIdxs,
+					DestIdx:     s.outputLen,
+					SrcStartIdx: 0,
+					SrcEndIdx:   n,
+				},
+			})
+		}
+		if !s.joinType.ShouldIncludeRightColsInOutput() {
+			return
+		}
+		numInputCols := len(s.inputTypes)
+		if source == nil {
+			for _, colIdx := range s.lookedUpColsToCopy {
+				s.output.ColVec(numInputCols+colIdx).Nulls().SetNullRange(s.outputLen, s.outputLen+n)
+			}
+			return
+		}
+		// The positions of -1 in lookedUpIdxs need to be replaced with valid
+		// ones before copying, and the corresponding output values are then
+		// set to NULL.
+		sel := s.lookedUpIdxs
+		hasUnmatched := false
+		for _, idx := range s.lookedUpIdxs {
+			if idx < 0 {
+				hasUnmatched = true
+				break
+			}
+		}
+		if hasUnmatched {
+			sel = s.scratchSel[:n]
+			for i, idx := range s.lookedUpIdxs {
+				if idx < 0 {
+					idx = 0
+				}
+				sel[i] = idx
+			}
+		}
+		for _, colIdx := range s.lookedUpColsToCopy {
+			outputVec := s.output.ColVec(numInputCols + colIdx)
+			outputVec.Copy(coldata.CopySliceArgs{
+				SliceArgs: coldata.SliceArgs{
+					Src:         source.ColVec(colIdx),
+					Sel:         sel,
+					DestIdx:     s.outputLen,
+					SrcStartIdx: 0,
+					SrcEndIdx:   n,
+				},
+			})
+			if hasUnmatched {
+				outputNulls := outputVec.Nulls()
+				for i, idx := range s.lookedUpIdxs {
+					if idx < 0 {
+						outputNulls.SetNull(s.outputLen + i)
+					}
+				}
+			}
+		}
+	})
+	s.outputLen += n
+	s.inputIdxs = s.inputIdxs[:0]
+	s.lookedUpIdxs = s.lookedUpIdxs[:0]
+}
+
+// NewColLookupJoin creates a new ColLookupJoin operator. The allocator is used
+// for the output batches as well as for the buffered input tuples and looked
+// up rows.
+func NewColLookupJoin(
+	ctx context.Context,
+	allocator *colmem.Allocator,
+	flowCtx *execinfra.FlowCtx,
+	evalCtx *tree.EvalContext,
+	input colexecbase.Operator,
+	spec *execinfrapb.JoinReaderSpec,
+	post *execinfrapb.PostProcessSpec,
+	inputTypes []*types.T,
+) (*ColLookupJoin, error) {
+	// NB: we hit this with a zero NodeID (but !ok) with multi-tenancy.
+	if nodeID, ok := flowCtx.NodeID.OptionalNodeID(); nodeID == 0 && ok {
+		return nil, errors.Errorf("attempting to create a ColLookupJoin with uninitialized NodeID")
+	}
+	if err := IsLookupJoinSupported(spec); err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "unsupported lookup join")
+	}
+	for _, colIdx := range spec.LookupColumns {
+		if int(colIdx) >= len(inputTypes) {
+			return nil, errors.AssertionFailedf("invalid lookup column %d", colIdx)
+		}
+	}
+
+	table := tabledesc.NewImmutable(spec.Table)
+	tableCols, err := getTableColumns(ctx, flowCtx, evalCtx, table, spec.Visibility, spec.HasSystemColumns)
+	if err != nil {
+		return nil, err
+	}
+	var internalTypes []*types.T
+	if spec.Type.ShouldIncludeRightColsInOutput() {
+		internalTypes = make([]*types.T, 0, len(inputTypes)+len(tableCols.typs))
+		internalTypes = append(internalTypes, inputTypes...)
+		internalTypes = append(internalTypes, tableCols.typs...)
+	} else {
+		internalTypes = inputTypes
+	}
+	neededCols, err := getNeededColumns(flowCtx, evalCtx, post, spec.OnExpr, internalTypes)
+	if err != nil {
+		return nil, err
+	}
+	var inputColsToCopy, lookedUpColsToCopy []int
+	var neededTableCols util.FastIntSet
+	for colIdx, ok := neededCols.Next(0); ok; colIdx, ok = neededCols.Next(colIdx + 1) {
+		if colIdx < len(inputTypes) {
+			inputColsToCopy = append(inputColsToCopy, colIdx)
+		} else {
+			lookedUpColsToCopy = append(lookedUpColsToCopy, colIdx-len(inputTypes))
+			neededTableCols.Add(colIdx - len(inputTypes))
+		}
+	}
+
+	numLookupCols := len(spec.LookupColumns)
+	fetcher := cFetcherPool.Get().(*cFetcher)
+	index, err := initLookupFetcher(
+		flowCtx, allocator, fetcher, table, tableCols, spec, neededTableCols, numLookupCols,
+	)
+	if err != nil {
+		return nil, err
+	}
+	spanBuilder := span.MakeBuilder(evalCtx, flowCtx.Codec(), table, index)
+	spanBuilder.SetNeededColumns(neededTableCols)
+	keyTypes, keyTableColIdxs := getIndexKeyTypes(index, tableCols, numLookupCols)
+	lookupCols := make([]int, numLookupCols)
+	for i := range lookupCols {
+		lookupCols[i] = int(spec.LookupColumns[i])
+	}
+
+	s := &ColLookupJoin{
+		lookupBase: lookupBase{
+			input:     input,
+			flowCtx:   flowCtx,
+			rf:        fetcher,
+			allocator: allocator,
+			// If the lookup columns form a key, there is only one result per
+			// lookup, so the fetcher should parallelize the key lookups it
+			// performs.
+			limitBatches: !spec.LookupColumnsAreKey,
+		},
+		joinType:           spec.Type,
+		maintainOrdering:   spec.MaintainOrdering,
+		inputTypes:         inputTypes,
+		inputColsToCopy:    inputColsToCopy,
+		lookedUpColsToCopy: lookedUpColsToCopy,
+		keyToInputIdxs:     make(map[string][]int),
+		ResultTypes:        spec.Type.MakeOutputTypes(inputTypes, tableCols.typs),
+	}
+	s.spanGen.init(spanBuilder, len(inputTypes), lookupCols, keyTypes)
+	s.lookedUpKeyGen.init(spanBuilder, len(tableCols.typs), keyTableColIdxs, keyTypes)
+	return s, nil
+}
+
+// IsLookupJoinSupported returns an error if the lookup join described by spec
+// cannot be executed by the ColLookupJoin.
+func IsLookupJoinSupported(spec *execinfrapb.JoinReaderSpec) error {
+	if len(spec.LookupColumns) == 0 {
+		return errors.Newf("lookup join must have lookup columns")
+	}
+	switch spec.Type {
+	case descpb.InnerJoin:
+	case descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin:
+		if !spec.OnExpr.Empty() {
+			return errors.Newf("ON expression is only supported with inner lookup joins")
+		}
+	default:
+		return errors.Newf("%s lookup join is not supported", spec.Type)
+	}
+	if spec.MaintainOrdering && !spec.LookupColumnsAreKey {
+		return errors.Newf("maintaining ordering is only supported when lookup columns are a key")
+	}
+	if spec.LeftJoinWithPairedJoiner || spec.OutputGroupContinuationForLeftRow {
+		return errors.Newf("paired joins are not supported")
+	}
+	return nil
+}
+
+// Release implements the execinfra.Releasable interface.
+func (s *ColLookupJoin) Release() {
+	s.release()
+	s.lookedUpKeyGen.converter.Release()
+}
@@ -0,0 +1,416 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colflow_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colfetcher"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// makeJoinReaderFlowCtx returns a FlowCtx that can be used to run both the
+// joinReader and the vectorized lookup and index joins.
+func makeJoinReaderFlowCtx(
+	ctx context.Context, t testing.TB, s serverutils.TestServerInterface,
+) (_ *execinfra.FlowCtx, cleanup func()) {
+	st := s.ClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	tempEngine, _, err := storage.NewTempEngine(ctx, base.DefaultTestTempStorageConfig(st), base.DefaultTestStoreSpec)
+	require.NoError(t, err)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings:    st,
+			TempStorage: tempEngine,
+			DiskMonitor: testDiskMonitor,
+		},
+		Txn:    kv.NewTxn(ctx, s.DB(), s.NodeID()),
+		Local:  true,
+		NodeID: evalCtx.NodeID,
+	}
+	return flowCtx, func() {
+		tempEngine.Close()
+		evalCtx.Stop(ctx)
+	}
+}
+
+// runJoinReader runs the JoinReader core specified by spec on the provided
+// input rows either with the joinReader processor or with the vectorized
+// operator and calls onRow on every output row. In the latter case, it also
+// verifies that the join was planned natively.
+func runJoinReader(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.ProcessorSpec,
+	input rowenc.EncDatumRows,
+	vectorized bool,
+	onRow func(rowenc.EncDatumRow),
+) error {
+	inputTypes := spec.Input[0].ColumnTypes
+	var output execinfra.RowSource
+	if vectorized {
+		columnarizer, err := colexec.NewBufferingColumnarizer(
+			ctx, testAllocator, flowCtx, 1 /* processorID */, execinfra.NewRepeatableRowSource(inputTypes, input),
+		)
+		if err != nil {
+			return err
+		}
+		result, err := colbuilder.NewColOperator(ctx, flowCtx, &colexec.NewColOperatorArgs{
+			Spec:                spec,
+			Inputs:              []colexecbase.Operator{columnarizer},
+			StreamingMemAccount: testMemAcc,
+		})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			for _, memAccount := range result.OpAccounts {
+				memAccount.Close(ctx)
+			}
+			for _, memMonitor := range result.OpMonitors {
+				memMonitor.Stop(ctx)
+			}
+		}()
+		switch result.KVReader.(type) {
+		case *colfetcher.ColIndexJoin, *colfetcher.ColLookupJoin:
+		default:
+			return errors.Errorf("unexpectedly planned %T", result.KVReader)
+		}
+		m, err := colexec.NewMaterializer(
+			flowCtx, 2 /* processorID */, result.Op, spec.ResultTypes, nil, /* output */
+			result.MetadataSources, result.ToClose, nil /* execStatsForTrace */, nil, /* cancelFlow */
+		)
+		if err != nil {
+			return err
+		}
+		output = m
+	} else {
+		proc, err := rowexec.NewProcessor(
+			ctx, flowCtx, 0 /* processorID */, &spec.Core, &spec.Post,
+			[]execinfra.RowSource{execinfra.NewRepeatableRowSource(inputTypes, input)},
+			[]execinfra.RowReceiver{nil}, nil, /* localProcessors */
+		)
+		if err != nil {
+			return err
+		}
+		output = proc.(execinfra.RowSource)
+	}
+
+	output.Start(ctx)
+	defer output.ConsumerClosed()
+	for {
+		row, meta := output.Next()
+		if meta != nil {
+			if meta.Err != nil {
+				return meta.Err
+			}
+			continue
+		}
+		if row == nil {
+			return nil
+		}
+		onRow(row)
+	}
+}
+
+// TestLookupJoinAgainstProcessor verifies that the vectorized lookup and index
+// joins produce the same results as the joinReader.
+func TestLookupJoinAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	const numRows = 100
+	// The table has a non-unique secondary index on b which contains NULL
+	// values for some rows.
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT PRIMARY KEY, b INT, c INT, INDEX b_idx (b)",
+		numRows,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, func(row int) tree.Datum {
+			if row%11 == 0 {
+				return tree.DNull
+			}
+			return tree.NewDInt(tree.DInt(row % 7))
+		}, sqlutils.RowModuloFn(5)),
+	)
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	var secondaryIdx uint32
+	for i, idx := range td.ActiveIndexes() {
+		if idx.GetName() == "b_idx" {
+			secondaryIdx = uint32(i)
+		}
+	}
+	require.NotZero(t, secondaryIdx)
+
+	rng, _ := randutil.NewPseudoRand()
+	randomInput := func(numInputRows int, maxVal int) rowenc.EncDatumRows {
+		input := make(rowenc.EncDatumRows, numInputRows)
+		for i := range input {
+			input[i] = make(rowenc.EncDatumRow, 2)
+			for j := range input[i] {
+				d := tree.DNull
+				if rng.Intn(10) != 0 {
+					d = tree.NewDInt(tree.DInt(rng.Intn(maxVal+2) - 1))
+				}
+				input[i][j] = rowenc.DatumToEncDatum(types.Int, d)
+			}
+		}
+		return input
+	}
+
+	// Use small batch sizes in some of the runs in order to exercise the code
+	// paths in which the output batch gets full.
+	defer func(batchSize int) {
+		require.NoError(t, coldata.SetBatchSizeForTests(batchSize))
+	}(coldata.BatchSize())
+	batchSizes := []int{coldata.BatchSize(), 1, 3}
+
+	joinTypes := []descpb.JoinType{
+		descpb.InnerJoin, descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin,
+	}
+	for run := 0; run < 10; run++ {
+		require.NoError(t, coldata.SetBatchSizeForTests(batchSizes[rng.Intn(len(batchSizes))]))
+		for _, joinType := range joinTypes {
+			for _, lookupIntoPrimary := range []bool{true, false} {
+				spec := execinfrapb.JoinReaderSpec{
+					Table:         *td.TableDesc(),
+					LookupColumns: []uint32{uint32(rng.Intn(2))},
+					Type:          joinType,
+				}
+				var post execinfrapb.PostProcessSpec
+				outputTypes := rowenc.TwoIntCols
+				if lookupIntoPrimary {
+					spec.LookupColumnsAreKey = true
+					spec.MaintainOrdering = rng.Intn(2) == 0
+					if joinType.ShouldIncludeRightColsInOutput() {
+						outputTypes = rowenc.MakeIntCols(5)
+					}
+				} else {
+					// Only the columns present in the secondary index can be
+					// output.
+					spec.IndexIdx = secondaryIdx
+					if joinType.ShouldIncludeRightColsInOutput() {
+						post = execinfrapb.PostProcessSpec{
+							Projection:    true,
+							OutputColumns: []uint32{0, 1, 2, 3},
+						}
+						outputTypes = rowenc.FourIntCols
+					}
+				}
+				if joinType == descpb.InnerJoin && rng.Intn(2) == 0 {
+					// Add an ON expression that refers to both input and table
+					// columns.
+					spec.OnExpr = execinfrapb.Expression{Expr: "@1 + @3 > 20"}
+				}
+				pspec := &execinfrapb.ProcessorSpec{
+					Input:       []execinfrapb.InputSyncSpec{{ColumnTypes: rowenc.TwoIntCols}},
+					Core:        execinfrapb.ProcessorCoreUnion{JoinReader: &spec},
+					Post:        post,
+					ResultTypes: outputTypes,
+				}
+				input := randomInput(rng.Intn(2*numRows), numRows)
+				checkLookupJoin(ctx, t, s, pspec, input, spec.MaintainOrdering)
+			}
+		}
+
+		// Index join: the input contains the primary key values in the first
+		// column.
+		input := randomInput(rng.Intn(2*numRows), numRows)
+		for _, row := range input {
+			if row[0].IsNull() {
+				row[0] = rowenc.DatumToEncDatum(types.Int, tree.NewDInt(0))
+			}
+		}
+		spec := execinfrapb.JoinReaderSpec{
+			Table:            *td.TableDesc(),
+			MaintainOrdering: rng.Intn(2) == 0,
+		}
+		pspec := &execinfrapb.ProcessorSpec{
+			Input:       []execinfrapb.InputSyncSpec{{ColumnTypes: rowenc.TwoIntCols}},
+			Core:        execinfrapb.ProcessorCoreUnion{JoinReader: &spec},
+			Post:        execinfrapb.PostProcessSpec{Projection: true, OutputColumns: []uint32{2, 0}},
+			ResultTypes: rowenc.TwoIntCols,
+		}
+		checkLookupJoin(ctx, t, s, pspec, input, spec.MaintainOrdering)
+	}
+}
+
+func checkLookupJoin(
+	ctx context.Context,
+	t *testing.T,
+	s serverutils.TestServerInterface,
+	spec *execinfrapb.ProcessorSpec,
+	input rowenc.EncDatumRows,
+	maintainOrdering bool,
+) {
+	run := func(vectorized bool) []string {
+		flowCtx, cleanup := makeJoinReaderFlowCtx(ctx, t, s)
+		defer cleanup()
+		var rows []string
+		require.NoError(t, runJoinReader(ctx, flowCtx, spec, input, vectorized, func(row rowenc.EncDatumRow) {
+			rows = append(rows, row.String(spec.ResultTypes))
+		}))
+		if !maintainOrdering {
+			sort.Strings(rows)
+		}
+		return rows
+	}
+	expected, actual := run(false /* vectorized */), run(true /* vectorized */)
+	if strings.Join(expected, "\n") != strings.Join(actual, "\n") {
+		t.Fatalf(
+			"different results for %s\ninput: %s\nexpected:\n%s\nactual:\n%s",
+			spec.Core.JoinReader, input.String(spec.Input[0].ColumnTypes),
+			strings.Join(expected, "\n"), strings.Join(actual, "\n"),
+		)
+	}
+}
+
+// BenchmarkLookupJoin compares the performance of the vectorized lookup and
+// index joins against the joinReader.
+func BenchmarkLookupJoin(b *testing.B) {
+	defer leaktest.AfterTest(b)()
+	defer log.Scope(b).Close(b)
+	ctx := context.Background()
+
+	s, sqlDB, kvDB := serverutils.StartServer(b, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	const numTableRows = 1 << 14
+	// Every value of b is present in 16 rows of the table.
+	sqlutils.CreateTable(b, sqlDB, "t",
+		"a INT PRIMARY KEY, b INT, c INT, INDEX b_idx (b)",
+		numTableRows,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowModuloFn(numTableRows/16), sqlutils.RowModuloFn(42)),
+	)
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	var secondaryIdx uint32
+	for i, idx := range td.ActiveIndexes() {
+		if idx.GetName() == "b_idx" {
+			secondaryIdx = uint32(i)
+		}
+	}
+
+	type benchCase struct {
+		name               string
+		spec               execinfrapb.JoinReaderSpec
+		post               execinfrapb.PostProcessSpec
+		resultTypes        []*types.T
+		matchesPerInputRow int
+	}
+	cases := []benchCase{
+		{
+			name:               "index-join",
+			spec:               execinfrapb.JoinReaderSpec{Table: *td.TableDesc()},
+			post:               execinfrapb.PostProcessSpec{Projection: true, OutputColumns: []uint32{2}},
+			resultTypes:        rowenc.OneIntCol,
+			matchesPerInputRow: 1,
+		},
+		{
+			name: "lookup-join-key",
+			spec: execinfrapb.JoinReaderSpec{
+				Table:               *td.TableDesc(),
+				LookupColumns:       []uint32{0},
+				LookupColumnsAreKey: true,
+			},
+			post:               execinfrapb.PostProcessSpec{Projection: true, OutputColumns: []uint32{3}},
+			resultTypes:        rowenc.OneIntCol,
+			matchesPerInputRow: 1,
+		},
+		{
+			name: "lookup-join-non-key",
+			spec: execinfrapb.JoinReaderSpec{
+				Table:         *td.TableDesc(),
+				LookupColumns: []uint32{0},
+				IndexIdx:      secondaryIdx,
+			},
+			post:               execinfrapb.PostProcessSpec{Projection: true, OutputColumns: []uint32{1}},
+			resultTypes:        rowenc.OneIntCol,
+			matchesPerInputRow: 16,
+		},
+	}
+	for _, c := range cases {
+		for _, maintainOrdering := range []bool{false, true} {
+			if maintainOrdering && len(c.spec.LookupColumns) > 0 && !c.spec.LookupColumnsAreKey {
+				// This case isn't supported by the ColLookupJoin.
+				continue
+			}
+			for _, numInputRows := range []int{1 << 4, 1 << 8, 1 << 12} {
+				input := make(rowenc.EncDatumRows, numInputRows)
+				for i := range input {
+					// Note that the primary key values start from 1.
+					val := i%numTableRows + 1
+					if c.matchesPerInputRow > 1 {
+						val = i % (numTableRows / c.matchesPerInputRow)
+					}
+					input[i] = rowenc.EncDatumRow{rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(val)))}
+				}
+				spec := c.spec
+				spec.MaintainOrdering = maintainOrdering
+				pspec := &execinfrapb.ProcessorSpec{
+					Input:       []execinfrapb.InputSyncSpec{{ColumnTypes: rowenc.OneIntCol}},
+					Core:        execinfrapb.ProcessorCoreUnion{JoinReader: &spec},
+					Post:        c.post,
+					ResultTypes: c.resultTypes,
+				}
+				expectedNumOutputRows := numInputRows * c.matchesPerInputRow
+				for _, vectorized := range []bool{false, true} {
+					b.Run(fmt.Sprintf("%s/ordering=%t/rows=%d/vectorized=%t", c.name, maintainOrdering, numInputRows, vectorized), func(b *testing.B) {
+						flowCtx, cleanup := makeJoinReaderFlowCtx(ctx, b, s)
+						defer cleanup()
+						b.SetBytes(int64(numInputRows*8 + expectedNumOutputRows*8))
+						b.ResetTimer()
+						for i := 0; i < b.N; i++ {
+							numOutputRows := 0
+							if err := runJoinReader(ctx, flowCtx, pspec, input, vectorized, func(rowenc.EncDatumRow) {
+								numOutputRows++
+							}); err != nil {
+								b.Fatal(err)
+							}
+							if numOutputRows != expectedNumOutputRows {
+								b.Fatalf("got %d output rows, expected %d", numOutputRows, expectedNumOutputRows)
+							}
+						}
+					})
+				}
+			}
+		}
+	}
+}
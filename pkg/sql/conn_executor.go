@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/cancelchecker"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -637,6 +638,7 @@ func (s *Server) newConnExecutor(
 		portals:   make(map[string]PreparedPortal),
 	}
 	ex.extraTxnState.prepStmtsNamespaceMemAcc = ex.sessionMon.MakeBoundAccount()
+	ex.extraTxnState.sqlCursors.mon = ex.sessionMon
 	ex.extraTxnState.descCollection = descs.MakeCollection(
 		s.cfg.LeaseManager, s.cfg.Settings, sd, s.cfg.HydratedTables)
 	ex.extraTxnState.txnRewindPos = -1
@@ -646,6 +648,7 @@ func (s *Server) newConnExecutor(
 
 	ex.sessionTracing.ex = ex
 	ex.transitionCtx.sessionTracing = &ex.sessionTracing
+	ex.transitionCtx.traceParent = ex.sessionTraceParent
 	ex.statsCollector = ex.newStatsCollector()
 
 	ex.initPlanner(ctx, &ex.planner)
@@ -686,6 +689,7 @@ func (s *Server) newConnExecutorWithTxn(
 	// The new transaction stuff below requires active monitors and traces, so
 	// we need to activate the executor now.
 	ex.activate(ctx, parentMon, mon.BoundAccount{})
+	ex.hasExternalTxn = true
 
 	// Perform some surgery on the executor - replace its state machine and
 	// initialize the state.
@@ -701,6 +705,7 @@ func (s *Server) newConnExecutorWithTxn(
 		nil, /* historicalTimestamp */
 		roachpb.UnspecifiedUserPriority,
 		tree.ReadWrite,
+		txn.IsolationLevel(),
 		txn,
 		ex.transitionCtx)
 
@@ -834,6 +839,10 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		log.Warningf(ctx, "error while cleaning up connExecutor: %s", err)
 	}
 
+	if ex.notifications.listener != nil {
+		ex.notifications.listener.UnlistenAll()
+	}
+
 	if ex.hasCreatedTemporarySchema && !ex.server.cfg.TestingKnobs.DisableTempObjectsCleanupOnSessionExit {
 		ie := MakeInternalExecutor(ctx, ex.server, MemoryMetrics{}, ex.server.cfg.Settings)
 		err := cleanupSessionTempObjects(
@@ -956,6 +965,18 @@ type connExecutor struct {
 		// that staged them commits.
 		jobs jobsCollection
 
+		// listenActions accumulates the LISTEN and UNLISTEN statements run by the
+		// transaction. They take effect once the transaction commits.
+		listenActions listenActionsCollection
+
+		// sqlCursors contains the cursors declared by the transaction. They are
+		// closed when the transaction finishes.
+		sqlCursors sqlCursors
+
+		// deferredConstraints contains the modes set by SET CONSTRAINTS and the
+		// constraint checks deferred until the transaction commits.
+		deferredConstraints deferredConstraints
+
 		// schemaChangeJobsCache is a map of descriptor IDs to Jobs.
 		// Used in createOrUpdateSchemaChangeJob so we can check if a job has been
 		// queued up for the given ID.
@@ -966,6 +987,12 @@ type connExecutor struct {
 		// stateOpen.
 		autoRetryCounter int
 
+		// stmtRetryPos and stmtRetryCounter keep track of the statement-level
+		// retries performed for the statement at position stmtRetryPos in a
+		// READ COMMITTED transaction. See retryStatementMaybe.
+		stmtRetryPos     CmdPos
+		stmtRetryCounter int
+
 		// numDDL keeps track of how many DDL statements have been
 		// executed so far.
 		numDDL int
@@ -1136,10 +1163,19 @@ type connExecutor struct {
 	// going to find a suitable time to close the connection.
 	draining bool
 
+	// notifications holds the state of the session related to LISTEN and
+	// NOTIFY.
+	notifications sessionNotifications
+
 	// executorType is set to whether this executor is an ordinary executor which
 	// responds to user queries or an internal one.
 	executorType executorType
 
+	// hasExternalTxn is set if the executor runs its statements within a
+	// transaction owned by its caller (see newConnExecutorWithTxn), for example
+	// the statements in the body of a UDF.
+	hasExternalTxn bool
+
 	// hasCreatedTemporarySchema is set if the executor has created a
 	// temporary schema, which requires special cleanup on close.
 	hasCreatedTemporarySchema bool
@@ -1243,6 +1279,9 @@ func (ns *prepStmtNamespace) resetTo(
 // commits, rolls back or restarts.
 func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) error {
 	ex.extraTxnState.jobs = nil
+	ex.extraTxnState.listenActions = nil
+	ex.extraTxnState.sqlCursors.closeAll(ctx)
+	ex.extraTxnState.deferredConstraints.reset()
 	if ex.server.cfg.Settings.Version.IsActive(ctx, clusterversion.NewSchemaChanger) {
 		ex.extraTxnState.schemaChangerState = SchemaChangerState{
 			mode: ex.sessionData.NewSchemaChangerMode,
@@ -1575,7 +1614,9 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		payload = eventNonRetriableErrPayload{err: tcmd.Err}
 	case Sync:
 		// Note that the Sync result will flush results to the network connection.
-		res = ex.clientComm.CreateSyncResult(pos)
+		syncRes := ex.clientComm.CreateSyncResult(pos)
+		res = syncRes
+		ex.bufferPendingNotifications(syncRes)
 		if ex.draining {
 			// If we're draining, check whether this is a good time to finish the
 			// connection. If we're not inside a transaction, we stop processing
@@ -1608,6 +1649,11 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 	case Flush:
 		// Closing the res will flush the connection's buffer.
 		res = ex.clientComm.CreateFlushResult(pos)
+	case DeliverNotifications:
+		// Closing the res will send the notifications buffered on it, if any.
+		notificationsRes := ex.clientComm.CreateDeliverNotificationsResult(pos)
+		res = notificationsRes
+		ex.bufferPendingNotifications(notificationsRes)
 	default:
 		panic(errors.AssertionFailedf("unsupported command type: %T", cmd))
 	}
@@ -1756,6 +1802,11 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 		// if the rewind point is not current set to the command's position
 		// (i.e. we don't do anything if txnRewindPos != pos).
 
+		if advInfo.code == stayInPlace {
+			// A statement of a READ COMMITTED transaction is being retried; the
+			// rewind position is unaffected.
+			return nil
+		}
 		if advInfo.code != advanceOne {
 			panic(errors.AssertionFailedf("unexpected advanceCode: %s", advInfo.code))
 		}
@@ -1787,6 +1838,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case Flush:
 				canAdvance = true
+			case DeliverNotifications:
+				canAdvance = true
 			default:
 				panic(errors.AssertionFailedf("unsupported cmd: %T", cmd))
 			}
@@ -2044,9 +2097,14 @@ func (ex *connExecutor) setTransactionModes(
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.AssertionFailedf(
-			"unknown isolation level: %s", errors.Safe(modes.Isolation))
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		if modes.Isolation != tree.SerializableIsolation && modes.Isolation != tree.ReadCommittedIsolation {
+			return errors.AssertionFailedf(
+				"unknown isolation level: %s", errors.Safe(modes.Isolation))
+		}
+		if err := ex.state.setIsolationLevel(ex.txnIsolationLevelToProto(modes.Isolation)); err != nil {
+			return err
+		}
 	}
 	rwMode := modes.ReadWriteMode
 	if modes.AsOf.Expr != nil && asOfTs.IsEmpty() {
@@ -2086,6 +2144,30 @@ func (ex *connExecutor) txnPriorityWithSessionDefault(mode tree.UserPriority) ro
 	return txnPriorityToProto(mode)
 }
 
+// txnIsolationLevelToProto maps a SQL isolation level to the one the KV
+// transaction will run under. READ COMMITTED is upgraded to SERIALIZABLE unless
+// the sql.txn.read_committed_isolation.enabled cluster setting is set and the
+// whole cluster has been upgraded to a version that understands the isolation
+// level of a transaction.
+func (ex *connExecutor) txnIsolationLevelToProto(level tree.IsolationLevel) enginepb.IsolationLevel {
+	st := ex.server.cfg.Settings
+	if level == tree.ReadCommittedIsolation &&
+		readCommittedIsolationEnabled.Get(&st.SV) &&
+		st.Version.IsActive(ex.Ctx(), clusterversion.ReadCommittedIsolation) {
+		return enginepb.READ_COMMITTED
+	}
+	return enginepb.SERIALIZABLE
+}
+
+func (ex *connExecutor) txnIsolationLevelWithSessionDefault(
+	level tree.IsolationLevel,
+) enginepb.IsolationLevel {
+	if level == tree.UnspecifiedIsolation {
+		level = tree.IsolationLevel(ex.sessionData.DefaultTxnIsolationLevel)
+	}
+	return ex.txnIsolationLevelToProto(level)
+}
+
 func (ex *connExecutor) readWriteModeWithSessionDefault(
 	mode tree.ReadWriteMode,
 ) tree.ReadWriteMode {
@@ -2163,10 +2245,18 @@ func (ex *connExecutor) initEvalCtx(ctx context.Context, evalCtx *extendedEvalCo
 		DistSQLPlanner:       ex.server.cfg.DistSQLPlanner,
 		TxnModesSetter:       ex,
 		Jobs:                 &ex.extraTxnState.jobs,
+		ListenActions:        &ex.extraTxnState.listenActions,
+		Notifications:        &ex.notifications,
+		SQLCursors:           &ex.extraTxnState.sqlCursors,
 		SchemaChangeJobCache: ex.extraTxnState.schemaChangeJobsCache,
 		schemaAccessors:      scInterface,
 		sqlStatsCollector:    ex.statsCollector,
 	}
+	// Internal executors can run statements in transactions that they don't
+	// commit, so they cannot defer constraint checks until the commit.
+	if ex.executorType != executorTypeInternal {
+		evalCtx.DeferredConstraints = &ex.extraTxnState.deferredConstraints
+	}
 }
 
 // resetEvalCtx initializes the fields of evalCtx that can change
@@ -2273,6 +2363,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 
 	if advInfo.code == rewind {
 		ex.extraTxnState.autoRetryCounter++
+		ex.extraTxnState.stmtRetryCounter = 0
 	}
 
 	// Handle transaction events which cause updates to txnState.
@@ -2280,6 +2371,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 	case noEvent:
 	case txnStart:
 		ex.extraTxnState.autoRetryCounter = 0
+		ex.extraTxnState.stmtRetryCounter = 0
 		ex.extraTxnState.onTxnFinish, ex.extraTxnState.onTxnRestart = ex.recordTransactionStart()
 	case txnCommit:
 		if res.Err() != nil {
@@ -2330,6 +2422,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 			}
 		}
 		ex.notifyStatsRefresherOfNewTables(ex.Ctx())
+		ex.applyListenActions(ex.extraTxnState.listenActions)
 
 		if err := ex.server.cfg.JobRegistry.Run(
 			ex.ctxHolder.connCtx,
@@ -2503,6 +2596,20 @@ func (ex *connExecutor) getPrepStmtsAccessor() preparedStatementsAccessor {
 }
 
 // sessionEventf logs a message to the session event log (if any).
+// sessionTraceParent returns the remote span identified by the traceparent
+// session variable, if set.
+func (ex *connExecutor) sessionTraceParent() *tracing.SpanMeta {
+	if ex.sessionData.TraceParent == "" {
+		return nil
+	}
+	// The value was validated when the variable was set.
+	sm, err := tracing.ParseTraceParent(ex.sessionData.TraceParent)
+	if err != nil {
+		return nil
+	}
+	return sm
+}
+
 func (ex *connExecutor) sessionEventf(ctx context.Context, format string, args ...interface{}) {
 	if log.ExpensiveLogEnabled(ctx, 2) {
 		log.VEventfDepth(ctx, 1 /* depth */, 2 /* level */, format, args...)
@@ -2789,6 +2896,22 @@ func (ps connExPrepStmtsAccessor) DeleteAll(ctx context.Context) {
 	)
 }
 
+// Add is part of the preparedStatementsAccessor interface.
+func (ps connExPrepStmtsAccessor) Add(
+	ctx context.Context, name string, stmt parser.Statement, placeholderHints tree.PlaceholderTypes,
+) error {
+	if _, ok := ps.Get(name); ok {
+		return pgerror.Newf(
+			pgcode.DuplicatePreparedStatement, "prepared statement %q already exists", name,
+		)
+	}
+	_, err := ps.ex.addPreparedStmt(
+		ctx, name, makeStatement(stmt, ps.ex.generateID()), placeholderHints,
+		PreparedStatementOriginSessionMigration,
+	)
+	return err
+}
+
 // contextStatementKey is an empty type for the handle associated with the
 // statement value (see context.Value).
 type contextStatementKey struct{}
@@ -633,6 +633,26 @@ func (ex *connExecutor) execStmtInOpenState(
 		return makeErrEvent(err)
 	}
 
+	// Under READ COMMITTED isolation, every statement reads from its own
+	// snapshot, so we move the transaction's read timestamp forward. We also
+	// place a savepoint so that the statement can be rolled back and retried
+	// on its own if it runs into a conflict (see retryStatementMaybe). This is
+	// only done for top-level statements: statements run by an executor within
+	// its caller's transaction (e.g. the body of a UDF) are part of the
+	// caller's statement, so they read from its snapshot, and a rollback to a
+	// savepoint of theirs would also undo the caller's writes.
+	var stmtSavepoint kv.SavepointToken
+	if ex.state.mu.txn.IsolationLevel().ToleratesWriteSkew() && !tree.CanModifySchema(ast) &&
+		!ex.hasExternalTxn {
+		if err := ex.state.mu.txn.StepReadTimestamp(ctx); err != nil {
+			return makeErrEvent(err)
+		}
+		var err error
+		if stmtSavepoint, err = ex.state.mu.txn.CreateSavepoint(ctx); err != nil {
+			return makeErrEvent(err)
+		}
+	}
+
 	if err := p.semaCtx.Placeholders.Assign(pinfo, stmt.NumPlaceholders); err != nil {
 		return makeErrEvent(err)
 	}
@@ -667,6 +687,15 @@ func (ex *connExecutor) execStmtInOpenState(
 	}
 
 	if err := res.Err(); err != nil {
+		if kv.IsStatementRetryError(err) {
+			if stmtSavepoint != nil && ex.retryStatementMaybe(ctx, stmtSavepoint) {
+				return eventStmtRetry{}, nil, nil
+			}
+			// The statement can't be retried; surface the conflict to the client
+			// like the transaction retry errors it stands in for.
+			err = pgerror.WithCandidateCode(err, pgcode.SerializationFailure)
+			res.SetError(err)
+		}
 		return makeErrEvent(err)
 	}
 
@@ -699,6 +728,48 @@ func (ex *connExecutor) execStmtInOpenState(
 	return nil, nil, nil
 }
 
+// maxStmtRetries is the number of times a statement of a READ COMMITTED
+// transaction is retried before its error is returned to the client.
+const maxStmtRetries = 10
+
+// retryStatementMaybe is called when the statement at the current position
+// failed with a statement retry error (see kv.IsStatementRetryError). If
+// possible, it rolls the transaction back to the savepoint created before the
+// statement ran and discards the statement's results, in which case it returns
+// true and the statement is expected to be executed again. Retrying is not
+// possible once some of the statement's results have been delivered to the
+// client or when the statement has been retried too many times.
+func (ex *connExecutor) retryStatementMaybe(
+	ctx context.Context, savepoint kv.SavepointToken,
+) bool {
+	_, pos, err := ex.stmtBuf.CurCmd()
+	if err != nil {
+		return false
+	}
+	if ex.extraTxnState.stmtRetryPos != pos {
+		ex.extraTxnState.stmtRetryPos = pos
+		ex.extraTxnState.stmtRetryCounter = 0
+	}
+	if ex.extraTxnState.stmtRetryCounter >= maxStmtRetries {
+		log.VEventf(ctx, 2, "statement retries exhausted")
+		return false
+	}
+
+	cl := ex.clientComm.LockCommunication()
+	defer cl.Close()
+	if cl.ClientPos() >= pos {
+		log.VEventf(ctx, 2, "results already delivered; cannot retry statement")
+		return false
+	}
+	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, savepoint); err != nil {
+		log.VEventf(ctx, 2, "cannot retry statement: %v", err)
+		return false
+	}
+	cl.RTrim(ctx, pos)
+	ex.extraTxnState.stmtRetryCounter++
+	return true
+}
+
 func (ex *connExecutor) checkDescriptorTwoVersionInvariant(ctx context.Context) error {
 	var inRetryBackoff func()
 	if knobs := ex.server.cfg.SchemaChangerTestingKnobs; knobs != nil {
@@ -716,10 +787,14 @@ func (ex *connExecutor) checkDescriptorTwoVersionInvariant(ctx context.Context)
 		// Create a new transaction to retry with a higher timestamp than the
 		// timestamps used in the retry loop above.
 		userPriority := ex.state.mu.txn.UserPriority()
+		isoLevel := ex.state.mu.txn.IsolationLevel()
 		ex.state.mu.txn = kv.NewTxnWithSteppingEnabled(ctx, ex.transitionCtx.db, ex.transitionCtx.nodeIDOrZero)
 		if err := ex.state.mu.txn.SetUserPriority(userPriority); err != nil {
 			return err
 		}
+		if err := ex.state.mu.txn.SetIsolationLevel(isoLevel); err != nil {
+			return err
+		}
 	}
 	return err
 }
@@ -749,6 +824,17 @@ func (ex *connExecutor) commitSQLTransactionInternal(
 		}
 	}
 
+	if pending := ex.extraTxnState.deferredConstraints.takePending(); len(pending) > 0 {
+		// The planner's eval context might have been used to run the statement
+		// that is being auto-committed, so we reset it before running the checks.
+		ex.resetEvalCtx(
+			&ex.planner.extendedEvalCtx, ex.state.mu.txn, ex.planner.ExtendedEvalContext().StmtTimestamp,
+		)
+		if err := checkDeferredConstraints(ctx, &ex.planner, pending); err != nil {
+			return err
+		}
+	}
+
 	if err := validatePrimaryKeys(&ex.extraTxnState.descCollection); err != nil {
 		return err
 	}
@@ -757,6 +843,9 @@ func (ex *connExecutor) commitSQLTransactionInternal(
 		return err
 	}
 
+	// Cursors must not use the transaction once it is committed.
+	ex.extraTxnState.sqlCursors.closeAll(ctx)
+
 	if err := ex.state.mu.txn.Commit(ctx); err != nil {
 		return err
 	}
@@ -789,6 +878,8 @@ func validatePrimaryKeys(tc *descs.Collection) error {
 // rollbackSQLTransaction executes a ROLLBACK statement: the KV transaction is
 // rolled-back and an event is produced.
 func (ex *connExecutor) rollbackSQLTransaction(ctx context.Context) (fsm.Event, fsm.EventPayload) {
+	ex.extraTxnState.sqlCursors.closeAll(ctx)
+	ex.extraTxnState.deferredConstraints.reset()
 	if err := ex.state.mu.txn.Rollback(ctx); err != nil {
 		log.Warningf(ctx, "txn rollback failed: %s", err)
 	}
@@ -1126,6 +1217,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(s.Modes.UserPriority),
 				mode,
+				ex.txnIsolationLevelWithSessionDefault(s.Modes.Isolation),
 				sqlTs,
 				historicalTs,
 				ex.transitionCtx)
@@ -1146,6 +1238,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
 				mode,
+				ex.txnIsolationLevelWithSessionDefault(tree.UnspecifiedIsolation),
 				sqlTs,
 				historicalTs,
 				ex.transitionCtx)
@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
@@ -199,8 +200,16 @@ func (ex *connExecutor) prepare(
 
 	var flags planFlags
 	prepare := func(ctx context.Context, txn *kv.Txn) (err error) {
-		ex.statsCollector.reset(&ex.server.sqlStats, ex.appStats, &ex.phaseTimes)
 		p := &ex.planner
+		if origin == PreparedStatementOriginSessionMigration {
+			// The statements of a migrated session are prepared while ex.planner
+			// executes the statement restoring the session, so they need their
+			// own planner and must leave the stats of that statement alone.
+			p = &planner{execCfg: ex.server.cfg, alloc: &rowenc.DatumAlloc{}}
+			ex.initPlanner(ctx, p)
+		} else {
+			ex.statsCollector.reset(&ex.server.sqlStats, ex.appStats, &ex.phaseTimes)
+		}
 		ex.resetPlanner(ctx, p, txn, ex.server.cfg.Clock.PhysicalTime() /* stmtTS */)
 		p.stmt = stmt
 		p.semaCtx.Annotations = tree.MakeAnnotations(stmt.NumAnnotations)
@@ -235,7 +244,7 @@ func (ex *connExecutor) populatePrepared(
 	ctx context.Context, txn *kv.Txn, placeholderHints tree.PlaceholderTypes, p *planner,
 ) (planFlags, error) {
 	if before := ex.server.cfg.TestingKnobs.BeforePrepare; before != nil {
-		if err := before(ctx, ex.planner.stmt.String(), txn); err != nil {
+		if err := before(ctx, p.stmt.String(), txn); err != nil {
 			return 0, err
 		}
 	}
@@ -23,12 +23,15 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/mutations"
@@ -38,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -451,6 +455,187 @@ SELECT IF(x::INT::FLOAT = x,
 	}
 }
 
+// TestReadCommittedStmtRetry verifies that a statement of a READ COMMITTED
+// transaction that runs into a write-write conflict is retried on its own at a
+// newer read timestamp, instead of failing the whole transaction.
+func TestReadCommittedStmtRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const updateStmt = `UPDATE t.kv SET v = v + 1 WHERE k = 1`
+	var armed int32
+	sent, proceed := make(chan struct{}), make(chan struct{})
+	var updateExecs int32
+	params, _ := tests.CreateTestServerParams()
+	params.Knobs.Store.(*kvserver.StoreTestingKnobs).TestingRequestFilter =
+		func(_ context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+			if ba.Txn != nil && ba.Txn.IsolationLevel == enginepb.READ_COMMITTED &&
+				atomic.CompareAndSwapInt32(&armed, 1, 0) {
+				close(sent)
+				<-proceed
+			}
+			return nil
+		}
+	params.Knobs.SQLExecutor = &sql.ExecutorTestingKnobs{
+		StatementFilter: func(_ context.Context, stmt string, _ error) {
+			if stmt == updateStmt {
+				atomic.AddInt32(&updateExecs, 1)
+			}
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.Background())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY, v INT);
+INSERT INTO t.kv VALUES (1, 1);
+`)
+
+	rcTxn, err := db.Begin()
+	require.NoError(t, err)
+	_, err = rcTxn.Exec(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+	require.NoError(t, err)
+	// Deliver some results to the client so that the transaction can't be
+	// retried as a whole.
+	var v int
+	require.NoError(t, rcTxn.QueryRow(`SELECT v FROM t.kv WHERE k = 1`).Scan(&v))
+	require.Equal(t, 1, v)
+
+	// Once the update has picked its read timestamp, write and commit a new
+	// value above it from another transaction. The update runs into the other
+	// transaction's lock and then into its committed value, which it can't
+	// read at its original timestamp.
+	atomic.StoreInt32(&armed, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := rcTxn.Exec(updateStmt)
+		errCh <- err
+	}()
+	<-sent
+	otherTxn, err := db.Begin()
+	require.NoError(t, err)
+	_, err = otherTxn.Exec(`UPDATE t.kv SET v = 10 WHERE k = 1`)
+	require.NoError(t, err)
+	close(proceed)
+	require.NoError(t, otherTxn.Commit())
+	require.NoError(t, <-errCh)
+	require.NoError(t, rcTxn.Commit())
+
+	sqlDB.CheckQueryResults(t, `SELECT v FROM t.kv WHERE k = 1`, [][]string{{"11"}})
+	require.Equal(t, int32(2), atomic.LoadInt32(&updateExecs))
+}
+
+// TestReadCommittedUDFSnapshot verifies that the statements in the body of a
+// UDF read from the snapshot of the READ COMMITTED statement calling it, even
+// when rows are written concurrently while the calling statement runs.
+func TestReadCommittedUDFSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const udfBody = `SELECT count(*) FROM t.kv`
+	var armed int32
+	var s serverutils.TestServerInterface
+	params, _ := tests.CreateTestServerParams()
+	params.Knobs.SQLExecutor = &sql.ExecutorTestingKnobs{
+		StatementFilter: func(ctx context.Context, stmt string, err error) {
+			// After the first call of the UDF, write and commit a new row from
+			// another transaction.
+			if err == nil && stmt == udfBody && atomic.CompareAndSwapInt32(&armed, 1, 0) {
+				if _, err := s.InternalExecutor().(*sql.InternalExecutor).Exec(
+					ctx, "concurrent-insert", nil /* txn */, `INSERT INTO t.kv VALUES (100)`,
+				); err != nil {
+					t.Error(err)
+				}
+			}
+		},
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.Background())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY);
+INSERT INTO t.kv VALUES (1), (2);
+CREATE FUNCTION t.count_kv(x INT) RETURNS INT LANGUAGE SQL VOLATILE AS '`+udfBody+`';
+`)
+
+	rcTxn, err := db.Begin()
+	require.NoError(t, err)
+	_, err = rcTxn.Exec(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+	require.NoError(t, err)
+	atomic.StoreInt32(&armed, 1)
+	rows, err := rcTxn.Query(`SELECT k, t.count_kv(k) FROM t.kv ORDER BY k`)
+	require.NoError(t, err)
+	var counts []int
+	for rows.Next() {
+		var k, count int
+		require.NoError(t, rows.Scan(&k, &count))
+		counts = append(counts, count)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rcTxn.Commit())
+
+	require.Equal(t, int32(0), atomic.LoadInt32(&armed))
+	require.Equal(t, []int{2, 2}, counts)
+	// The next statement reads from a new snapshot.
+	sqlDB.CheckQueryResults(t, `SELECT t.count_kv(0)`, [][]string{{"3"}})
+}
+
+// TestReadCommittedMixedVersion verifies that transactions keep running under
+// SERIALIZABLE isolation, even when READ COMMITTED is requested and enabled,
+// until the cluster version allowing it is active.
+func TestReadCommittedMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	prevVersion := clusterversion.ByKey(clusterversion.ReadCommittedIsolation - 1)
+	params, _ := tests.CreateTestServerParams()
+	params.Settings = cluster.MakeTestingClusterSettingsWithVersions(
+		clusterversion.ByKey(clusterversion.ReadCommittedIsolation),
+		prevVersion,
+		false, /* initializeVersion */
+	)
+	params.Knobs.Server = &server.TestingKnobs{
+		BinaryVersionOverride:          prevVersion,
+		DisableAutomaticVersionUpgrade: 1,
+	}
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	// Use a single connection so that the session default below applies to
+	// all the statements.
+	db.SetMaxOpenConns(1)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `SET default_transaction_isolation = 'read committed'`)
+
+	checkIsolation := func(expected string) {
+		t.Helper()
+		sqlDB.CheckQueryResults(t, `SHOW transaction_isolation`, [][]string{{expected}})
+
+		txn, err := db.Begin()
+		require.NoError(t, err)
+		_, err = txn.Exec(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+		require.NoError(t, err)
+		var level string
+		require.NoError(t, txn.QueryRow(`SHOW transaction_isolation`).Scan(&level))
+		require.NoError(t, txn.Commit())
+		require.Equal(t, expected, level)
+	}
+
+	checkIsolation("serializable")
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.ReadCommittedIsolation).String())
+	checkIsolation("read committed")
+}
+
 func TestAppNameStatisticsInitialization(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
@@ -106,6 +107,7 @@ type eventTxnStartPayload struct {
 	// current_timestamp(), transaction_timestamp().
 	txnSQLTimestamp     time.Time
 	readOnly            tree.ReadWriteMode
+	isoLevel            enginepb.IsolationLevel
 	historicalTimestamp *hlc.Timestamp
 }
 
@@ -113,6 +115,7 @@ type eventTxnStartPayload struct {
 func makeEventTxnStartPayload(
 	pri roachpb.UserPriority,
 	readOnly tree.ReadWriteMode,
+	isoLevel enginepb.IsolationLevel,
 	txnSQLTimestamp time.Time,
 	historicalTimestamp *hlc.Timestamp,
 	tranCtx transitionCtx,
@@ -120,6 +123,7 @@ func makeEventTxnStartPayload(
 	return eventTxnStartPayload{
 		pri:                 pri,
 		readOnly:            readOnly,
+		isoLevel:            isoLevel,
 		txnSQLTimestamp:     txnSQLTimestamp,
 		historicalTimestamp: historicalTimestamp,
 		tranCtx:             tranCtx,
@@ -184,6 +188,11 @@ type eventTxnRestart struct{}
 // generated by releasing regular savepoints.
 type eventTxnReleased struct{}
 
+// eventStmtRetry is generated when a statement of a READ COMMITTED transaction
+// encountered a conflict and was rolled back so that it can be executed again
+// at a newer read timestamp. The transaction itself stays open.
+type eventStmtRetry struct{}
+
 // payloadWithError is a common interface for the payloads that wrap an error.
 type payloadWithError interface {
 	errorCause() error
@@ -197,6 +206,7 @@ func (eventNonRetriableErr) Event()    {}
 func (eventRetriableErr) Event()       {}
 func (eventTxnRestart) Event()         {}
 func (eventTxnReleased) Event()        {}
+func (eventStmtRetry) Event()          {}
 
 // TxnStateTransitions describe the transitions used by a connExecutor's
 // fsm.Machine. Args.Extended is a txnState, which is muted by the Actions.
@@ -287,6 +297,16 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 				return nil
 			},
 		},
+		eventStmtRetry{}: {
+			Description: "Retriable err in a READ COMMITTED statement; will retry the statement",
+			Next:        stateOpen{ImplicitTxn: fsm.Var("implicitTxn")},
+			Action: func(args fsm.Args) error {
+				// The statement has already been rolled back to its savepoint by this
+				// point; it will be executed again at the current position.
+				args.Extended.(*txnState).setAdvanceInfo(stayInPlace, noRewind, noEvent)
+				return nil
+			},
+		},
 	},
 	// Handle the errors in implicit txns. They move us to NoTxn.
 	stateOpen{ImplicitTxn: fsm.True}: {
@@ -451,6 +471,7 @@ func noTxnToOpen(args fsm.Args) error {
 		payload.historicalTimestamp,
 		payload.pri,
 		payload.readOnly,
+		payload.isoLevel,
 		nil, /* txn */
 		payload.tranCtx,
 	)
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
@@ -346,6 +347,25 @@ func (DrainRequest) String() string {
 
 var _ Command = DrainRequest{}
 
+// DeliverNotifications is a command pushed by the session's notification
+// listener when notifications generated by NOTIFY statements are waiting to be
+// delivered to the client. It wakes up an idle connExecutor so that the
+// notifications are delivered without waiting for the client to send a
+// command.
+//
+// If the session is in a transaction, the notifications are held until the
+// transaction finishes.
+type DeliverNotifications struct{}
+
+// command implements the Command interface.
+func (DeliverNotifications) command() string { return "deliver notifications" }
+
+func (DeliverNotifications) String() string {
+	return "DeliverNotifications"
+}
+
+var _ Command = DeliverNotifications{}
+
 // SendError is a command that, upon execution, send a specific error to the
 // client. This is used by pgwire to schedule errors to be sent at an
 // appropriate time.
@@ -618,6 +638,9 @@ type ClientComm interface {
 	CreateCopyInResult(pos CmdPos) CopyInResult
 	// CreateDrainResult creates a result for a Drain command.
 	CreateDrainResult(pos CmdPos) DrainResult
+	// CreateDeliverNotificationsResult creates a result for a
+	// DeliverNotifications command.
+	CreateDeliverNotificationsResult(pos CmdPos) DeliverNotificationsResult
 
 	// lockCommunication ensures that no further results are delivered to the
 	// client. The returned ClientLock can be queried to see what results have
@@ -773,6 +796,7 @@ type DeleteResult interface {
 // flushed.
 type SyncResult interface {
 	ResultBase
+	NotificationBuffer
 }
 
 // FlushResult represents the result of a Flush command. When this result is
@@ -787,6 +811,23 @@ type DrainResult interface {
 	ResultBase
 }
 
+// DeliverNotificationsResult represents the result of a DeliverNotifications
+// command. When closed, the notifications buffered on the result, if any, are
+// sent and all buffered data is flushed. If no notifications were buffered,
+// closing this result produces no output for the client.
+type DeliverNotificationsResult interface {
+	ResultBase
+	NotificationBuffer
+}
+
+// NotificationBuffer is implemented by the results of the commands after which
+// notifications generated by NOTIFY statements can be sent to the client.
+type NotificationBuffer interface {
+	// BufferNotification buffers a notification to be sent to the client before
+	// the result's completion message, if any.
+	BufferNotification(notify.Notification)
+}
+
 // EmptyQueryResult represents the result of an empty query (a query
 // representing a blank string).
 type EmptyQueryResult interface {
@@ -865,6 +906,10 @@ type streamingCommandResult struct {
 	err          error
 	rowsAffected int
 
+	// resume, if set, is used to wait for the consumer to ask for the next row
+	// after the columns and each row have been sent on ch.
+	resume chan struct{}
+
 	// closeCallback, if set, is called when Close()/Discard() is called.
 	closeCallback func(*streamingCommandResult, resCloseType)
 }
@@ -875,6 +920,27 @@ var _ CommandResultClose = &streamingCommandResult{}
 // SetColumns is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) SetColumns(ctx context.Context, cols colinfo.ResultColumns) {
 	r.ch <- ieIteratorResult{cols: cols}
+	// If the consumer is gone, the error will be returned when adding the
+	// first row.
+	_ = r.waitForResume(ctx)
+}
+
+// waitForResume blocks until the consumer asks for the next row, if the result
+// is used in lockstep mode. It returns an error if the consumer has been
+// closed.
+func (r *streamingCommandResult) waitForResume(ctx context.Context) error {
+	if r.resume == nil {
+		return nil
+	}
+	select {
+	case _, ok := <-r.resume:
+		if !ok {
+			return errIteratorClosed
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // BufferParamStatusUpdate is part of the RestrictedCommandResult interface.
@@ -887,6 +953,11 @@ func (r *streamingCommandResult) BufferNotice(notice pgnotice.Notice) {
 	panic("unimplemented")
 }
 
+// BufferNotification is part of the NotificationBuffer interface.
+func (r *streamingCommandResult) BufferNotification(notify.Notification) {
+	panic("unimplemented")
+}
+
 // ResetStmtType is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) ResetStmtType(stmt tree.Statement) {
 	panic("unimplemented")
@@ -901,7 +972,7 @@ func (r *streamingCommandResult) AddRow(ctx context.Context, row tree.Datums) er
 	rowCopy := make(tree.Datums, len(row))
 	copy(rowCopy, row)
 	r.ch <- ieIteratorResult{row: rowCopy}
-	return nil
+	return r.waitForResume(ctx)
 }
 
 func (r *streamingCommandResult) DisableBuffering() {
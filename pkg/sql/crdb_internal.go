@@ -129,6 +129,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalZonesTableID:                     crdbInternalZonesTable,
 		catconstants.CrdbInternalInvalidDescriptorsTableID:        crdbInternalInvalidDescriptorsTable,
 		catconstants.CrdbInternalClusterDatabasePrivilegesTableID: crdbInternalClusterDatabasePrivilegesTable,
+		catconstants.CrdbInternalStmtDiagnosticsTableID:           crdbInternalStmtDiagnosticsTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -3878,3 +3879,53 @@ CREATE TABLE crdb_internal.cluster_database_privileges (
 			})
 	},
 }
+
+var crdbInternalStmtDiagnosticsTable = virtualSchemaTable{
+	comment: `collected statement diagnostics, including the traces captured by statement diagnostics requests (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.statement_diagnostics (
+	id                    INT NOT NULL,
+	request_id            INT,
+	statement_fingerprint STRING NOT NULL,
+	statement             STRING NOT NULL,
+	collected_at          TIMESTAMPTZ NOT NULL,
+	execution_latency     INTERVAL,
+	bundle_size           INT NOT NULL,
+	error                 STRING
+)`,
+	populate: func(
+		ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error,
+	) (retErr error) {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.statement_diagnostics"); err != nil {
+			return err
+		}
+		// The columns which link the diagnostics to their requests only exist
+		// once the cluster is upgraded.
+		requestID, execLatency := "NULL::INT8", "NULL::INTERVAL"
+		if p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ConditionalStatementDiagnostics) {
+			requestID, execLatency = "d.request_id", "d.execution_latency"
+		}
+		query := `
+SELECT d.id, ` + requestID + `, d.statement_fingerprint, d.statement, d.collected_at, ` + execLatency + `,
+       COALESCE((SELECT sum(length(c.data)) FROM system.statement_bundle_chunks AS c
+                  WHERE c.id = ANY (d.bundle_chunks)), 0)::INT8,
+       d.error
+  FROM system.statement_diagnostics AS d
+ ORDER BY d.collected_at, d.id`
+		it, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryIteratorEx(
+			ctx, "crdb-internal-statement-diagnostics", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			query)
+		if err != nil {
+			return err
+		}
+		defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+		var ok bool
+		for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+			if err := addRow(it.Cur()...); err != nil {
+				return err
+			}
+		}
+		return err
+	},
+}
@@ -0,0 +1,456 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type createFunctionNode struct {
+	n        *tree.CreateFunction
+	funcName *tree.FunctionName
+	dbDesc   catalog.DatabaseDescriptor
+}
+
+// Use to satisfy the linter.
+var _ planNode = &createFunctionNode{n: nil}
+
+func (p *planner) CreateFunction(ctx context.Context, n *tree.CreateFunction) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	// Make sure that all nodes in the cluster are able to resolve user-defined
+	// functions.
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.UserDefinedFunctions) {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for user-defined function creation")
+	}
+
+	// Resolve the desired new function name.
+	funcName, db, err := resolveNewFunctionName(p.RunParams(ctx), n.FuncName)
+	if err != nil {
+		return nil, err
+	}
+	n.FuncName.SetAnnotation(&p.semaCtx.Annotations, funcName)
+	return &createFunctionNode{
+		n:        n,
+		funcName: funcName,
+		dbDesc:   db,
+	}, nil
+}
+
+// resolveNewFunctionName resolves the name of a function which is about to be
+// created, and checks that the user can create objects in its database.
+func resolveNewFunctionName(
+	params runParams, name *tree.UnresolvedObjectName,
+) (*tree.FunctionName, catalog.DatabaseDescriptor, error) {
+	// Resolve the target schema and database.
+	db, _, prefix, err := params.p.ResolveTargetObject(params.ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := params.p.CheckPrivilege(params.ctx, db, privilege.CREATE); err != nil {
+		return nil, nil, err
+	}
+
+	// Disallow function creation in the system database.
+	if db.GetID() == keys.SystemDatabaseID {
+		return nil, nil, errors.New("cannot create a function in the system database")
+	}
+
+	funcName := tree.MakeFunctionNameWithPrefix(prefix, name.Object())
+	return &funcName, db, nil
+}
+
+// functionOptions holds the validated options of a CREATE FUNCTION statement.
+type functionOptions struct {
+	volatility descpb.FunctionDescriptor_Volatility
+	leakProof  bool
+	nullInput  descpb.FunctionDescriptor_NullInputBehavior
+	body       string
+}
+
+// makeFunctionOptions validates the options of a CREATE FUNCTION statement.
+// Each option can be specified at most once; LANGUAGE and AS are mandatory.
+func makeFunctionOptions(opts tree.FunctionOptions) (functionOptions, error) {
+	res := functionOptions{
+		volatility: descpb.FunctionDescriptor_VOLATILE,
+		nullInput:  descpb.FunctionDescriptor_CALLED_ON_NULL_INPUT,
+	}
+	var seenLang, seenVolatility, seenLeakProof, seenNullInput, seenBody bool
+	checkSeen := func(seen *bool) error {
+		if *seen {
+			return pgerror.New(pgcode.Syntax, "conflicting or redundant options")
+		}
+		*seen = true
+		return nil
+	}
+	for _, opt := range opts {
+		switch t := opt.(type) {
+		case tree.FunctionLanguage:
+			if err := checkSeen(&seenLang); err != nil {
+				return res, err
+			}
+			switch t {
+			case "SQL":
+			case "PLPGSQL":
+				return res, unimplemented.NewWithIssue(17511, "PL/pgSQL functions")
+			default:
+				return res, pgerror.Newf(pgcode.UndefinedObject,
+					"language %q does not exist", strings.ToLower(string(t)))
+			}
+		case tree.FunctionVolatility:
+			if err := checkSeen(&seenVolatility); err != nil {
+				return res, err
+			}
+			switch tree.Volatility(t) {
+			case tree.VolatilityImmutable:
+				res.volatility = descpb.FunctionDescriptor_IMMUTABLE
+			case tree.VolatilityStable:
+				res.volatility = descpb.FunctionDescriptor_STABLE
+			default:
+				res.volatility = descpb.FunctionDescriptor_VOLATILE
+			}
+		case tree.FunctionLeakProof:
+			if err := checkSeen(&seenLeakProof); err != nil {
+				return res, err
+			}
+			res.leakProof = bool(t)
+		case tree.FunctionNullInputBehavior:
+			if err := checkSeen(&seenNullInput); err != nil {
+				return res, err
+			}
+			if t == tree.FunctionCalledOnNullInput {
+				res.nullInput = descpb.FunctionDescriptor_CALLED_ON_NULL_INPUT
+			} else {
+				res.nullInput = descpb.FunctionDescriptor_RETURNS_NULL_ON_NULL_INPUT
+			}
+		case tree.FunctionBody:
+			if err := checkSeen(&seenBody); err != nil {
+				return res, err
+			}
+			res.body = string(t)
+		default:
+			return res, errors.AssertionFailedf("unknown function option %T", t)
+		}
+	}
+	if !seenLang {
+		return res, pgerror.New(pgcode.InvalidFunctionDefinition, "no language specified")
+	}
+	if !seenBody {
+		return res, pgerror.New(pgcode.InvalidFunctionDefinition, "no function body specified")
+	}
+	// LEAKPROOF is only meaningful for immutable functions, because
+	// tree.Volatility orders it above IMMUTABLE.
+	if res.leakProof && res.volatility != descpb.FunctionDescriptor_IMMUTABLE {
+		return res, pgerror.New(pgcode.InvalidFunctionDefinition,
+			"cannot create leakproof function which is not immutable")
+	}
+	return res, nil
+}
+
+// resolveFunctionSignature resolves the argument and return types of a
+// CREATE FUNCTION statement.
+func resolveFunctionSignature(
+	ctx context.Context, semaCtx *tree.SemaContext, n *tree.CreateFunction,
+) ([]descpb.FunctionDescriptor_Argument, *types.T, error) {
+	resolve := func(ref tree.ResolvableTypeReference, what string) (*types.T, error) {
+		typ, err := tree.ResolveType(ctx, ref, semaCtx.GetTypeResolver())
+		if err != nil {
+			return nil, err
+		}
+		if typ.UserDefined() {
+			return nil, unimplemented.NewWithIssue(17511,
+				"user-defined types in the signature of user-defined functions")
+		}
+		switch typ.Family() {
+		case types.AnyFamily, types.UnknownFamily:
+			return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"SQL functions cannot %s type %s", what, typ.SQLString())
+		}
+		return typ, nil
+	}
+	args := make([]descpb.FunctionDescriptor_Argument, len(n.Args))
+	seen := make(map[tree.Name]struct{}, len(n.Args))
+	for i := range n.Args {
+		if name := n.Args[i].Name; name != "" {
+			if _, ok := seen[name]; ok {
+				return nil, nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"parameter name %q used more than once", name)
+			}
+			seen[name] = struct{}{}
+		}
+		typ, err := resolve(n.Args[i].Type, "have arguments of")
+		if err != nil {
+			return nil, nil, err
+		}
+		args[i] = descpb.FunctionDescriptor_Argument{Name: string(n.Args[i].Name), Type: typ}
+	}
+	retType, err := resolve(n.ReturnType, "return")
+	if err != nil {
+		return nil, nil, err
+	}
+	return args, retType, nil
+}
+
+// validateFunctionBody checks that the body of a SQL function only contains
+// statements which can be run by a function of the given volatility, and
+// that its last statement returns rows.
+func validateFunctionBody(
+	body string,
+	args []descpb.FunctionDescriptor_Argument,
+	retType *types.T,
+	volatility descpb.FunctionDescriptor_Volatility,
+) error {
+	stmts, err := parser.Parse(body)
+	if err != nil {
+		return err
+	}
+	for i := range stmts {
+		stmt := stmts[i].AST
+		switch stmt.(type) {
+		case *tree.Select:
+		case *tree.Insert, *tree.Update, *tree.Delete:
+			if volatility != descpb.FunctionDescriptor_VOLATILE {
+				return pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"%s is not allowed in a non-volatile function", stmt.StatementTag())
+			}
+		default:
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"%s statements are not supported in SQL functions", stmt.StatementTag())
+		}
+		if _, _, err := replaceUDFArgs(stmt, args, true /* renumber */); err != nil {
+			return err
+		}
+	}
+	if len(stmts) == 0 || stmts[len(stmts)-1].AST.StatementType() != tree.Rows {
+		return errors.WithDetail(
+			pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"return type mismatch in function declared to return %s", retType.SQLString()),
+			"Function's final statement must be SELECT or INSERT/UPDATE/DELETE RETURNING.",
+		)
+	}
+	return nil
+}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	opts, err := makeFunctionOptions(n.n.Options)
+	if err != nil {
+		return err
+	}
+	if opts.leakProof {
+		if err := params.p.RequireAdminRole(params.ctx, "define a leakproof function"); err != nil {
+			return err
+		}
+	}
+	args, retType, err := resolveFunctionSignature(params.ctx, params.p.SemaCtx(), n.n)
+	if err != nil {
+		return err
+	}
+	if err := validateFunctionBody(opts.body, args, retType, opts.volatility); err != nil {
+		return err
+	}
+
+	// Check if a function with the same name exists already.
+	flags := tree.ObjectLookupFlags{CommonLookupFlags: tree.CommonLookupFlags{
+		Required:    false,
+		AvoidCached: true,
+	}}
+	found, existing, err := params.p.Descriptors().GetMutableFunctionByName(
+		params.ctx, params.p.Txn(), n.funcName, flags,
+	)
+	if err != nil {
+		return err
+	}
+	if found {
+		if !n.n.Replace {
+			return sqlerrors.NewFunctionAlreadyExistsError(n.funcName.String())
+		}
+		return n.replaceFunction(params, existing, args, retType, opts)
+	}
+
+	// Built-in functions take precedence during name resolution, so a
+	// user-defined function with the same name could only be called with a
+	// qualified name.
+	if _, ok := tree.FunDefs[n.funcName.Object()]; ok {
+		return pgerror.Newf(pgcode.DuplicateFunction,
+			"function %q conflicts with a built-in function", n.funcName.Object())
+	}
+
+	funcKey, schemaID, err := getCreateFunctionParams(params, n.funcName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+
+	// As in Postgres, everyone can execute a newly created function.
+	privs := descpb.NewDefaultPrivilegeDescriptor(params.p.User())
+	privs.Grant(params.p.User(), privilege.List{privilege.ALL})
+	privs.Grant(security.PublicRoleName(), privilege.List{privilege.EXECUTE})
+
+	funcDesc := funcdesc.NewCreatedMutable(descpb.FunctionDescriptor{
+		Name:              n.funcName.Object(),
+		ID:                id,
+		ParentID:          n.dbDesc.GetID(),
+		ParentSchemaID:    schemaID,
+		Version:           1,
+		Privileges:        privs,
+		Args:              args,
+		ReturnType:        retType,
+		Volatility:        opts.volatility,
+		LeakProof:         opts.leakProof,
+		NullInputBehavior: opts.nullInput,
+		Lang:              descpb.FunctionDescriptor_SQL,
+		Body:              opts.body,
+	})
+	if err := params.p.createDescriptorWithID(
+		params.ctx,
+		funcKey.Key(params.ExecCfg().Codec),
+		id,
+		funcDesc,
+		params.EvalContext().Settings,
+		tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Log the event.
+	return params.p.logEvent(params.ctx,
+		funcDesc.GetID(),
+		&eventpb.CreateFunction{
+			FunctionName: n.funcName.FQString(),
+		})
+}
+
+// replaceFunction implements CREATE OR REPLACE FUNCTION for an existing
+// function. Overloading is not supported, so the signature of the function
+// cannot change.
+func (n *createFunctionNode) replaceFunction(
+	params runParams,
+	desc *funcdesc.Mutable,
+	args []descpb.FunctionDescriptor_Argument,
+	retType *types.T,
+	opts functionOptions,
+) error {
+	if err := params.p.canModifyFunction(params.ctx, desc); err != nil {
+		return err
+	}
+	dropHint := func(err error) error {
+		return errors.WithHintf(err, "Use DROP FUNCTION %s first.", n.funcName)
+	}
+	if len(args) != len(desc.Args) {
+		return dropHint(pgerror.Newf(pgcode.DuplicateFunction,
+			"function %q already exists with different argument types", n.funcName.Object()))
+	}
+	for i := range args {
+		if !args[i].Type.Identical(desc.Args[i].Type) {
+			return dropHint(pgerror.Newf(pgcode.DuplicateFunction,
+				"function %q already exists with different argument types", n.funcName.Object()))
+		}
+		if desc.Args[i].Name != "" && args[i].Name != desc.Args[i].Name {
+			return dropHint(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"cannot change name of input parameter %q", desc.Args[i].Name))
+		}
+	}
+	if !retType.Identical(desc.ReturnType) {
+		return dropHint(pgerror.New(pgcode.InvalidFunctionDefinition,
+			"cannot change return type of existing function"))
+	}
+
+	desc.Args = args
+	desc.Volatility = opts.volatility
+	desc.LeakProof = opts.leakProof
+	desc.NullInputBehavior = opts.nullInput
+	desc.Body = opts.body
+	if err := params.p.writeFunctionDescChange(
+		params.ctx, desc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Log the event.
+	return params.p.logEvent(params.ctx,
+		desc.GetID(),
+		&eventpb.CreateFunction{
+			FunctionName: n.funcName.FQString(),
+			IsReplace:    true,
+		})
+}
+
+// getCreateFunctionParams performs some initial validation on the input new
+// FunctionName and returns the key for the new function descriptor, and the
+// ID of the parent schema.
+func getCreateFunctionParams(
+	params runParams, name *tree.FunctionName, db catalog.DatabaseDescriptor,
+) (funcKey catalogkeys.DescriptorKey, schemaID descpb.ID, err error) {
+	// Get the ID of the schema the function is being created in.
+	dbID := db.GetID()
+	schemaID, err = params.p.getSchemaIDForCreate(params.ctx, params.ExecCfg().Codec, dbID, name.Schema())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Check permissions on the schema.
+	if err := params.p.canCreateOnSchema(
+		params.ctx, schemaID, dbID, params.p.User(), skipCheckPublicSchema); err != nil {
+		return nil, 0, err
+	}
+
+	funcKey = catalogkv.MakeObjectNameKey(params.ctx, params.ExecCfg().Settings, dbID, schemaID, name.Object())
+	exists, collided, err := catalogkv.LookupObjectID(
+		params.ctx, params.p.txn, params.ExecCfg().Codec, dbID, schemaID, name.Object())
+	if err == nil && exists {
+		// Try and see what kind of object we collided with.
+		desc, err := catalogkv.GetAnyDescriptorByID(params.ctx, params.p.txn, params.ExecCfg().Codec, collided, catalogkv.Immutable)
+		if err != nil {
+			return nil, 0, sqlerrors.WrapErrorWhileConstructingObjectAlreadyExistsErr(err)
+		}
+		return nil, 0, sqlerrors.MakeObjectAlreadyExistsError(desc.DescriptorProto(), name.String())
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return funcKey, schemaID, nil
+}
+
+func (n *createFunctionNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *createFunctionNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *createFunctionNode) Close(ctx context.Context)           {}
+func (n *createFunctionNode) ReadingOwnWrites()                   {}
@@ -322,7 +322,13 @@ func (p *planner) checkPasswordAndGetHash(
 		}
 	}
 
-	hashedPassword, err = security.HashPassword(password)
+	// Mixed-version clusters must keep using bcrypt, which older nodes
+	// know how to verify.
+	method := security.HashBCrypt
+	if st.Version.IsActive(ctx, clusterversion.SCRAMAuthentication) {
+		method = security.GetConfiguredPasswordHashMethod(&st.SV)
+	}
+	hashedPassword, err = security.HashPassword(method, password)
 	if err != nil {
 		return hashedPassword, err
 	}
@@ -88,6 +88,8 @@ var minimumTypeUsageVersions = map[types.Family]clusterversion.Key{
 	types.GeographyFamily: clusterversion.GeospatialType,
 	types.GeometryFamily:  clusterversion.GeospatialType,
 	types.Box2DFamily:     clusterversion.Box2DType,
+	types.TSQueryFamily:   clusterversion.TSearchTypes,
+	types.TSVectorFamily:  clusterversion.TSearchTypes,
 }
 
 // isTypeSupportedInVersion returns whether a given type is supported in the given version.
@@ -332,6 +334,12 @@ func (n *createTableNode) startExec(params runParams) error {
 		}
 	}
 
+	if desc.HasRowLevelTTL() {
+		if err := params.p.createRowLevelTTLScheduledJob(params.ctx, desc); err != nil {
+			return err
+		}
+	}
+
 	// Descriptor written to store here.
 	if err := params.p.createDescriptorWithID(
 		params.ctx, tKey.Key(params.ExecCfg().Codec), id, desc, params.EvalContext().Settings,
@@ -645,6 +653,7 @@ func addUniqueWithoutIndexColumnTableDef(
 		string(d.Unique.ConstraintName),
 		[]string{string(d.Name)},
 		"", /* predicate */
+		tree.ConstraintNotDeferrable,
 		ts,
 		validationBehavior,
 	); err != nil {
@@ -711,7 +720,7 @@ func addUniqueWithoutIndexTableDef(
 		colNames[i] = string(d.Columns[i].Column)
 	}
 	if err := ResolveUniqueWithoutIndexConstraint(
-		ctx, desc, string(d.Name), colNames, predicate, ts, validationBehavior,
+		ctx, desc, string(d.Name), colNames, predicate, d.Deferrability, ts, validationBehavior,
 	); err != nil {
 		return err
 	}
@@ -731,6 +740,7 @@ func ResolveUniqueWithoutIndexConstraint(
 	constraintName string,
 	colNames []string,
 	predicate string,
+	deferrability tree.ConstraintDeferrability,
 	ts TableState,
 	validationBehavior tree.ValidationBehavior,
 ) error {
@@ -784,11 +794,12 @@ func ResolveUniqueWithoutIndexConstraint(
 	}
 
 	uc := descpb.UniqueWithoutIndexConstraint{
-		Name:      constraintName,
-		TableID:   tbl.ID,
-		ColumnIDs: columnIDs,
-		Predicate: predicate,
-		Validity:  validity,
+		Name:          constraintName,
+		TableID:       tbl.ID,
+		ColumnIDs:     columnIDs,
+		Predicate:     predicate,
+		Validity:      validity,
+		Deferrability: descpb.ConstraintDeferrabilityValue[deferrability],
 	}
 
 	if ts == NewTable {
@@ -1067,6 +1078,7 @@ func ResolveFK(
 		OnDelete:            descpb.ForeignKeyReferenceActionValue[d.Actions.Delete],
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
+		Deferrability:       descpb.ConstraintDeferrabilityValue[d.Deferrability],
 	}
 
 	if ts == NewTable {
@@ -1426,6 +1438,12 @@ func newTableDescIfAs(
 	if err != nil {
 		return nil, err
 	}
+	if desc.HasRowLevelTTL() {
+		return nil, pgerror.New(
+			pgcode.FeatureNotSupported,
+			"row-level TTL is not supported with CREATE TABLE ... AS",
+		)
+	}
 	desc.CreateQuery = getFinalSourceQuery(p.AsSource, evalContext)
 	return desc, nil
 }
@@ -1483,7 +1501,7 @@ func NewTableDesc(
 		semaCtx,
 		evalCtx,
 		n.StorageParams,
-		&paramparse.TableStorageParamObserver{},
+		&paramparse.TableStorageParamObserver{TableDesc: desc.TableDesc()},
 	); err != nil {
 		return nil, err
 	}
@@ -1615,6 +1633,36 @@ func NewTableDesc(
 		primaryIndexColumnSet[string(regionalByRowCol)] = struct{}{}
 	}
 
+	// Add the implied expiration column of tables with row-level TTL, unless
+	// it was specified explicitly, as SHOW CREATE TABLE does.
+	if ttl := desc.RowLevelTTL; ttl != nil {
+		ttlColExists := false
+		for _, def := range n.Defs {
+			if d, ok := def.(*tree.ColumnTableDef); ok && d.Name == colinfo.TTLDefaultExpirationColumnName {
+				t, err := tree.ResolveType(ctx, d.Type, vt)
+				if err != nil {
+					return nil, err
+				}
+				if t.Family() != types.TimestampTZFamily {
+					return nil, pgerror.Newf(
+						pgcode.InvalidTableDefinition,
+						"column %s of a table with row-level TTL must be of type %s",
+						d.Name, types.TimestampTZ.SQLString(),
+					)
+				}
+				ttlColExists = true
+			}
+		}
+		if !ttlColExists {
+			colDef, err := rowLevelTTLExpirationColDef(ttl.ExpireAfter)
+			if err != nil {
+				return nil, err
+			}
+			n.Defs = append(n.Defs, colDef)
+			columnDefaultExprs = append(columnDefaultExprs, nil)
+		}
+	}
+
 	if n.PartitionByTable.ContainsPartitioningClause() {
 		// Table PARTITION BY columns are always part of the primary index
 		// column set.
@@ -0,0 +1,271 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/execbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+// deferredConstraints holds the state of the deferrable constraints in a
+// transaction: the modes set with SET CONSTRAINTS, and the FK and uniqueness
+// checks that were deferred until the transaction commits.
+type deferredConstraints struct {
+	// allSet is true if SET CONSTRAINTS ALL was run by the transaction, in
+	// which case allDeferred is the mode it set.
+	allSet      bool
+	allDeferred bool
+	// named contains the modes set by SET CONSTRAINTS for specific constraints
+	// after the last SET CONSTRAINTS ALL.
+	named map[string]bool
+
+	// pending contains the violations found by the deferred checks. Each of
+	// them is checked again before the transaction commits.
+	pending []*exec.DeferrableCheckViolation
+}
+
+// isDeferred returns whether the check that found the given violation is
+// deferred in the current transaction.
+func (d *deferredConstraints) isDeferred(v *exec.DeferrableCheckViolation) bool {
+	if v.Deferrability == tree.ConstraintNotDeferrable {
+		return false
+	}
+	if deferred, ok := d.named[v.ConstraintName]; ok {
+		return deferred
+	}
+	if d.allSet {
+		return d.allDeferred
+	}
+	return v.Deferrability == tree.ConstraintInitiallyDeferred
+}
+
+// add queues a violation found by a deferred check.
+func (d *deferredConstraints) add(v *exec.DeferrableCheckViolation) {
+	d.pending = append(d.pending, v)
+}
+
+// setMode implements SET CONSTRAINTS. It returns the pending violations whose
+// checks are no longer deferred, which have to be checked again right away.
+func (d *deferredConstraints) setMode(
+	n *tree.SetConstraints,
+) (immediate []*exec.DeferrableCheckViolation) {
+	if n.All {
+		d.allSet = true
+		d.allDeferred = n.Deferred
+		d.named = nil
+	} else {
+		if d.named == nil {
+			d.named = make(map[string]bool, len(n.Names))
+		}
+		for _, name := range n.Names {
+			d.named[string(name)] = n.Deferred
+		}
+	}
+	if n.Deferred {
+		return nil
+	}
+	stillDeferred := d.pending[:0]
+	for _, v := range d.pending {
+		if d.isDeferred(v) {
+			stillDeferred = append(stillDeferred, v)
+		} else {
+			immediate = append(immediate, v)
+		}
+	}
+	d.pending = stillDeferred
+	return immediate
+}
+
+// takePending returns all the pending violations and clears them.
+func (d *deferredConstraints) takePending() []*exec.DeferrableCheckViolation {
+	pending := d.pending
+	d.pending = nil
+	return pending
+}
+
+// reset clears the state when the transaction finishes or restarts.
+func (d *deferredConstraints) reset() {
+	*d = deferredConstraints{}
+}
+
+// checkDeferredConstraints runs again the checks that found the given
+// violations. If a violation still exists, the error of the original check is
+// returned.
+//
+// The violations of each constraint are checked by a single query, which is
+// the check the optimizer builds for an insert of the rows of the table that
+// still have the violating key values (see optbuilder.BuildDeferredFKCheck).
+func checkDeferredConstraints(
+	ctx context.Context, p *planner, violations []*exec.DeferrableCheckViolation,
+) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	type constraintKey struct {
+		table      cat.StableID
+		name       string
+		foreignKey bool
+	}
+	var keys []constraintKey
+	byConstraint := make(map[constraintKey][]*exec.DeferrableCheckViolation)
+	for _, v := range violations {
+		key := constraintKey{table: v.Table, name: v.ConstraintName, foreignKey: v.ForeignKey}
+		if _, ok := byConstraint[key]; !ok {
+			keys = append(keys, key)
+		}
+		byConstraint[key] = append(byConstraint[key], v)
+	}
+
+	// We place a sequence point before the checks, so that they observe all
+	// the writes of the transaction.
+	if err := p.Txn().Step(ctx); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := p.runDeferredCheck(ctx, byConstraint[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runDeferredCheck plans and runs the check of the violations of a single
+// deferred constraint.
+func (p *planner) runDeferredCheck(
+	ctx context.Context, violations []*exec.DeferrableCheckViolation,
+) error {
+	v := violations[0]
+	keyVals := make([]tree.Datums, len(violations))
+	for i := range violations {
+		keyVals[i] = violations[i].KeyVals
+	}
+
+	catalog := &p.optPlanningCtx.catalog
+	var o xform.Optimizer
+	o.Init(p.EvalContext(), catalog)
+	var check memo.RelExpr
+	var keyCols opt.ColList
+	var ok bool
+	var err error
+	if v.ForeignKey {
+		var c memo.FKChecksItem
+		c, ok, err = optbuilder.BuildDeferredFKCheck(
+			ctx, &p.semaCtx, p.EvalContext(), catalog, o.Factory(), v.Table, v.ConstraintName, keyVals,
+		)
+		check, keyCols = c.Check, c.KeyCols
+	} else {
+		var c memo.UniqueChecksItem
+		c, ok, err = optbuilder.BuildDeferredUniqueCheck(
+			ctx, &p.semaCtx, p.EvalContext(), catalog, o.Factory(), v.Table, v.ConstraintName, keyVals,
+		)
+		check, keyCols = c.Check, c.KeyCols
+	}
+	if err != nil || !ok {
+		return err
+	}
+	o.Memo().SetRoot(check, &physical.Required{})
+	optimized, err := o.Optimize()
+	if err != nil {
+		return err
+	}
+
+	// Report the error of the original check which found the violation.
+	mkErr := func(keyVals tree.Datums) error {
+		for _, v := range violations {
+			if keyValsEqual(p.EvalContext(), keyVals, v.KeyVals) {
+				return v.Err
+			}
+		}
+		return v.Err
+	}
+	bld := execbuilder.New(
+		newExecFactory(p), o.Memo(), catalog, optimized, p.EvalContext(), false, /* allowAutoCommit */
+	)
+	checkPlan, err := bld.BuildDeferredCheck(keyCols, mkErr)
+	if err != nil {
+		return err
+	}
+
+	rows := rowcontainer.NewRowContainer(
+		p.EvalContext().Mon.MakeBoundAccount(), colinfo.ColTypeInfoFromResCols(nil),
+	)
+	defer rows.Close(ctx)
+	params := runParams{ctx: ctx, extendedEvalCtx: &p.extendedEvalCtx, p: p}
+	return runPlanInsidePlan(params, checkPlan.(*planComponents), rows)
+}
+
+// keyValsEqual returns whether the given key values are equal.
+func keyValsEqual(evalCtx *tree.EvalContext, a, b tree.Datums) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Compare(evalCtx, b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type setConstraintsNode struct {
+	n *tree.SetConstraints
+}
+
+// SetConstraints sets the mode of deferrable constraints in the current
+// transaction.
+// See https://www.postgresql.org/docs/current/sql-set-constraints.html for
+// details.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	for _, name := range n.Names {
+		row, err := p.ExecCfg().InternalExecutor.QueryRowEx(
+			ctx, "set-constraints", p.txn,
+			sessiondata.InternalExecutorOverride{User: p.User(), Database: p.CurrentDatabase()},
+			"SELECT bool_and(condeferrable) FROM pg_catalog.pg_constraint WHERE conname = $1",
+			string(name),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil || row[0] == tree.DNull {
+			return nil, pgerror.Newf(pgcode.UndefinedObject, "constraint %q does not exist", name)
+		}
+		if !tree.MustBeDBool(row[0]) {
+			return nil, pgerror.Newf(pgcode.WrongObjectType, "constraint %q is not deferrable", name)
+		}
+	}
+	return &setConstraintsNode{n: n}, nil
+}
+
+func (n *setConstraintsNode) startExec(params runParams) error {
+	d := params.extendedEvalCtx.DeferredConstraints
+	if d == nil {
+		return nil
+	}
+	return checkDeferredConstraints(params.ctx, params.p, d.setMode(n.n))
+}
+
+func (*setConstraintsNode) Next(runParams) (bool, error) { return false, nil }
+func (*setConstraintsNode) Values() tree.Datums          { return tree.Datums{} }
+func (*setConstraintsNode) Close(context.Context)        {}
@@ -55,6 +55,16 @@ WHERE status='%s' AND created_by_type='%s' AND created_by_id=schedule_id
 			"executor_type = '%s'", tree.ScheduledBackupExecutor.InternalName()))
 		columnExprs = append(columnExprs, fmt.Sprintf(
 			"%s->>'backup_statement' AS command", commandColumn))
+	case tree.ScheduledExportExecutor:
+		whereExprs = append(whereExprs, fmt.Sprintf(
+			"executor_type = '%s'", tree.ScheduledExportExecutor.InternalName()))
+		columnExprs = append(columnExprs, fmt.Sprintf(
+			"%s->>'export_statement' AS command", commandColumn))
+	case tree.ScheduledChangefeedExecutor:
+		whereExprs = append(whereExprs, fmt.Sprintf(
+			"executor_type = '%s'", tree.ScheduledChangefeedExecutor.InternalName()))
+		columnExprs = append(columnExprs, fmt.Sprintf(
+			"%s->>'changefeed_statement' AS command", commandColumn))
 	default:
 		// Strip out '@type' tag from the ExecutionArgs.args, and display what's left.
 		columnExprs = append(columnExprs, fmt.Sprintf("%s #-'{@type}' AS command", commandColumn))
@@ -36,6 +36,10 @@ func (p *planner) Discard(ctx context.Context, s *tree.Discard) (planNode, error
 
 		// DEALLOCATE ALL
 		p.preparedStatements.DeleteAll(ctx)
+
+		// UNLISTEN *
+		*p.extendedEvalCtx.ListenActions = append(*p.extendedEvalCtx.ListenActions,
+			listenAction{unlisten: true, all: true})
 	default:
 		return nil, errors.AssertionFailedf("unknown mode for DISCARD: %d", s.Mode)
 	}
@@ -1066,11 +1066,25 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 	maxNum := 10
 	typs := make([]*types.T, maxCols)
 	for i := range typs {
-		// TODO(yuzefovich): randomize the types of the columns once we support
-		// window functions that take in arguments.
+		// TODO(yuzefovich): randomize the types of the columns.
 		typs[i] = types.Int
 	}
+	var windowFuncs []execinfrapb.WindowerSpec_Func
 	for windowFn := range colbuilder.SupportedWindowFns {
+		windowFn := windowFn
+		windowFuncs = append(windowFuncs, execinfrapb.WindowerSpec_Func{WindowFunc: &windowFn})
+	}
+	for _, aggFn := range []execinfrapb.AggregatorSpec_Func{
+		execinfrapb.AggregatorSpec_COUNT_ROWS,
+		execinfrapb.AggregatorSpec_COUNT,
+		execinfrapb.AggregatorSpec_SUM,
+		execinfrapb.AggregatorSpec_MIN,
+		execinfrapb.AggregatorSpec_MAX,
+	} {
+		aggFn := aggFn
+		windowFuncs = append(windowFuncs, execinfrapb.WindowerSpec_Func{AggregateFunc: &aggFn})
+	}
+	for _, windowFunc := range windowFuncs {
 		for _, partitionBy := range [][]uint32{
 			{},     // No PARTITION BY clause.
 			{0},    // Partitioning on the first input column.
@@ -1088,28 +1102,69 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 					inputTypes := typs[:nCols:nCols]
 					rows := rowenc.MakeRandIntRowsInRange(rng, nRows, nCols, maxNum, nullProbability)
 
+					// Functions that are computed over the window frame get a
+					// random frame.
+					var argsIdxs []uint32
+					usesFrame := windowFunc.AggregateFunc != nil
+					if windowFunc.AggregateFunc != nil {
+						if *windowFunc.AggregateFunc != execinfrapb.AggregatorSpec_COUNT_ROWS {
+							argsIdxs = []uint32{0}
+						}
+					} else {
+						switch *windowFunc.WindowFunc {
+						case execinfrapb.WindowerSpec_LAG, execinfrapb.WindowerSpec_LEAD:
+							argsIdxs = []uint32{0}
+						case execinfrapb.WindowerSpec_FIRST_VALUE, execinfrapb.WindowerSpec_LAST_VALUE:
+							argsIdxs = []uint32{0}
+							usesFrame = true
+						case execinfrapb.WindowerSpec_NTH_VALUE:
+							if nCols < 2 {
+								continue
+							}
+							argsIdxs = []uint32{0, 1}
+							usesFrame = true
+						}
+					}
+					ordering := generateOrderingGivenPartitionBy(rng, nCols, nOrderingCols, partitionBy)
+					var frame *execinfrapb.WindowerSpec_Frame
+					if usesFrame {
+						frame = generateWindowFrame(rng, len(ordering.Columns))
+					}
 					windowerSpec := &execinfrapb.WindowerSpec{
 						PartitionBy: partitionBy,
 						WindowFns: []execinfrapb.WindowerSpec_WindowFn{
 							{
-								Func:         execinfrapb.WindowerSpec_Func{WindowFunc: &windowFn},
-								Ordering:     generateOrderingGivenPartitionBy(rng, nCols, nOrderingCols, partitionBy),
+								Func:         windowFunc,
+								ArgsIdxs:     argsIdxs,
+								Ordering:     ordering,
+								Frame:        frame,
 								OutputColIdx: uint32(nCols),
 								FilterColIdx: tree.NoColumnIdx,
 							},
 						},
 					}
-					if windowFn == execinfrapb.WindowerSpec_ROW_NUMBER &&
-						len(partitionBy)+len(windowerSpec.WindowFns[0].Ordering.Columns) < nCols {
-						// The output of row_number is not deterministic if there are
-						// columns that are not present in either PARTITION BY or ORDER BY
-						// clauses, so we skip such a configuration.
+					isRankFn := false
+					if windowFunc.WindowFunc != nil {
+						switch *windowFunc.WindowFunc {
+						case execinfrapb.WindowerSpec_RANK, execinfrapb.WindowerSpec_DENSE_RANK,
+							execinfrapb.WindowerSpec_PERCENT_RANK, execinfrapb.WindowerSpec_CUME_DIST:
+							isRankFn = true
+						}
+					}
+					if !isRankFn && len(partitionBy)+len(ordering.Columns) < nCols {
+						// The output of the window functions that depend on the
+						// order of rows within the peer groups is not deterministic
+						// if there are columns that are not present in either
+						// PARTITION BY or ORDER BY clauses, so we skip such a
+						// configuration.
 						continue
 					}
 
-					// Currently, we only support window functions that take no
-					// arguments, so we leave the second argument empty.
-					_, outputType, err := execinfrapb.GetWindowFunctionInfo(execinfrapb.WindowerSpec_Func{WindowFunc: &windowFn})
+					argTypes := make([]*types.T, len(argsIdxs))
+					for i, idx := range argsIdxs {
+						argTypes[i] = inputTypes[idx]
+					}
+					_, outputType, err := execinfrapb.GetWindowFunctionInfo(windowFunc, argTypes...)
 					require.NoError(t, err)
 					pspec := &execinfrapb.ProcessorSpec{
 						Input:       []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
@@ -1117,13 +1172,19 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 						ResultTypes: append(inputTypes, outputType),
 					}
 					args := verifyColOperatorArgs{
-						anyOrder:   true,
-						inputTypes: [][]*types.T{inputTypes},
-						inputs:     []rowenc.EncDatumRows{rows},
-						pspec:      pspec,
+						anyOrder:       true,
+						inputTypes:     [][]*types.T{inputTypes},
+						inputs:         []rowenc.EncDatumRows{rows},
+						pspec:          pspec,
+						forceDiskSpill: rng.Float64() < 0.5,
+						// Some window functions don't buffer any tuples, and the ones
+						// that do fall back to disk without notifying the testing
+						// callback.
+						forcedDiskSpillMightNotOccur: true,
 					}
 					if err := verifyColOperator(t, args); err != nil {
-						fmt.Printf("seed = %d\n", seed)
+						fmt.Printf("seed = %d forceDiskSpill = %t\n", seed, args.forceDiskSpill)
+						fmt.Printf("window function = %s\n", windowerSpec.WindowFns[0].String())
 						prettyPrintTypes(inputTypes, "t" /* tableName */)
 						prettyPrintInput(rows, inputTypes, "t" /* tableName */)
 						t.Fatal(err)
@@ -1134,6 +1195,82 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 	}
 }
 
+// generateWindowFrame returns a random window frame for a window function
+// with nOrderingCols ordering columns on integer columns.
+func generateWindowFrame(rng *rand.Rand, nOrderingCols int) *execinfrapb.WindowerSpec_Frame {
+	modes := []execinfrapb.WindowerSpec_Frame_Mode{
+		execinfrapb.WindowerSpec_Frame_RANGE,
+		execinfrapb.WindowerSpec_Frame_ROWS,
+		execinfrapb.WindowerSpec_Frame_GROUPS,
+	}
+	exclusions := []execinfrapb.WindowerSpec_Frame_Exclusion{
+		execinfrapb.WindowerSpec_Frame_NO_EXCLUSION,
+		execinfrapb.WindowerSpec_Frame_EXCLUDE_CURRENT_ROW,
+		execinfrapb.WindowerSpec_Frame_EXCLUDE_GROUP,
+		execinfrapb.WindowerSpec_Frame_EXCLUDE_TIES,
+	}
+	frame := &execinfrapb.WindowerSpec_Frame{
+		Mode:      modes[rng.Intn(len(modes))],
+		Exclusion: exclusions[rng.Intn(len(exclusions))],
+	}
+	// RANGE mode with offsets requires exactly one ordering column.
+	allowOffsets := frame.Mode != execinfrapb.WindowerSpec_Frame_RANGE || nOrderingCols == 1
+	// The bound types are listed in the order of the positions of the bounds,
+	// and the end bound cannot be positioned before the start bound.
+	boundTypes := []execinfrapb.WindowerSpec_Frame_BoundType{
+		execinfrapb.WindowerSpec_Frame_UNBOUNDED_PRECEDING,
+		execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING,
+		execinfrapb.WindowerSpec_Frame_CURRENT_ROW,
+		execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING,
+		execinfrapb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING,
+	}
+	genBound := func(boundTypeIdx int, offset int) execinfrapb.WindowerSpec_Frame_Bound {
+		bound := execinfrapb.WindowerSpec_Frame_Bound{BoundType: boundTypes[boundTypeIdx]}
+		if bound.BoundType == execinfrapb.WindowerSpec_Frame_OFFSET_PRECEDING ||
+			bound.BoundType == execinfrapb.WindowerSpec_Frame_OFFSET_FOLLOWING {
+			if frame.Mode == execinfrapb.WindowerSpec_Frame_RANGE {
+				var a rowenc.DatumAlloc
+				bound.OffsetType = execinfrapb.DatumInfo{Encoding: descpb.DatumEncoding_VALUE, Type: types.Int}
+				datum := rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(offset)))
+				var err error
+				bound.TypedOffset, err = datum.Encode(types.Int, &a, descpb.DatumEncoding_VALUE, nil /* appendTo */)
+				if err != nil {
+					panic(err)
+				}
+			} else {
+				bound.IntOffset = uint64(offset)
+			}
+		}
+		return bound
+	}
+	var startIdx, endIdx int
+	for {
+		// The start bound cannot be UNBOUNDED FOLLOWING, and the end bound
+		// cannot be UNBOUNDED PRECEDING.
+		startIdx = rng.Intn(len(boundTypes) - 1)
+		endIdx = startIdx + rng.Intn(len(boundTypes)-startIdx)
+		if endIdx == 0 {
+			endIdx = 1
+		}
+		if !allowOffsets && (startIdx == 1 || startIdx == 3 || endIdx == 1 || endIdx == 3) {
+			continue
+		}
+		break
+	}
+	startOffset, endOffset := rng.Intn(3), rng.Intn(3)
+	// Make sure that the end bound is not positioned before the start bound
+	// when both are offsets of the same type since the row engine doesn't
+	// support such frames.
+	if (startIdx == 1 && endIdx == 1 && startOffset < endOffset) ||
+		(startIdx == 3 && endIdx == 3 && startOffset > endOffset) {
+		startOffset, endOffset = endOffset, startOffset
+	}
+	frame.Bounds.Start = genBound(startIdx, startOffset)
+	end := genBound(endIdx, endOffset)
+	frame.Bounds.End = &end
+	return frame
+}
+
 // generateRandomSupportedTypes generates nCols random types that are supported
 // by the vectorized engine.
 func generateRandomSupportedTypes(rng *rand.Rand, nCols int) []*types.T {
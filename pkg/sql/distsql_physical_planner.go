@@ -1383,8 +1383,11 @@ type aggregatorPlanningInfo struct {
 	isScalar             bool
 	groupCols            []int
 	groupColOrdering     colinfo.ColumnOrdering
-	inputMergeOrdering   execinfrapb.Ordering
-	reqOrdering          ReqOrdering
+	// groupingSets, if set, contains the grouping sets (subsets of groupCols)
+	// for which the aggregations are computed separately.
+	groupingSets       []util.FastIntSet
+	inputMergeOrdering execinfrapb.Ordering
+	reqOrdering        ReqOrdering
 }
 
 // addAggregators adds aggregators corresponding to a groupNode and updates the plan to
@@ -1426,6 +1429,7 @@ func (dsp *DistSQLPlanner) addAggregators(
 		isScalar:             n.isScalar,
 		groupCols:            n.groupCols,
 		groupColOrdering:     n.groupColOrdering,
+		groupingSets:         n.groupingSets,
 		inputMergeOrdering:   dsp.convertOrdering(planReqOrdering(n.plan), p.PlanToStreamColMap),
 		reqOrdering:          n.reqOrdering,
 	})
@@ -1468,6 +1472,24 @@ func (dsp *DistSQLPlanner) planAggregators(
 		orderedGroupCols[i] = uint32(p.PlanToStreamColMap[c.ColIdx])
 		orderedGroupColSet.Add(c.ColIdx)
 	}
+	var groupingSets []execinfrapb.AggregatorSpec_GroupingSet
+	// commonGroupingSetCols are the columns that are common to all grouping
+	// sets. Only these can be used to partition the input of the final stage.
+	var commonGroupingSetCols []uint32
+	if info.groupingSets != nil {
+		groupingSets = make([]execinfrapb.AggregatorSpec_GroupingSet, len(info.groupingSets))
+		common := info.groupingSets[0].Copy()
+		for i, set := range info.groupingSets {
+			set.ForEach(func(idx int) {
+				groupingSets[i].Cols = append(groupingSets[i].Cols, uint32(p.PlanToStreamColMap[idx]))
+			})
+			common.IntersectionWith(set)
+		}
+		commonGroupingSetCols = make([]uint32, 0, common.Len())
+		common.ForEach(func(idx int) {
+			commonGroupingSetCols = append(commonGroupingSetCols, uint32(p.PlanToStreamColMap[idx]))
+		})
+	}
 
 	// We can have a local stage of distinct processors if all aggregation
 	// functions are distinct.
@@ -1531,10 +1553,11 @@ func (dsp *DistSQLPlanner) planAggregators(
 	// just a final stage. We only use a local stage if:
 	//  - the previous stage is distributed on multiple nodes, and
 	//  - all aggregation functions support it, and
-	//  - no function is performing distinct aggregation.
+	//  - no function is performing distinct aggregation, and
+	//  - there are no grouping sets.
 	//  TODO(radu): we could relax this by splitting the aggregation into two
 	//  different paths and joining on the results.
-	multiStage := prevStageNode == 0
+	multiStage := prevStageNode == 0 && info.groupingSets == nil
 	if multiStage {
 		for _, e := range info.aggregations {
 			if e.Distinct {
@@ -1558,6 +1581,7 @@ func (dsp *DistSQLPlanner) planAggregators(
 			Aggregations:     info.aggregations,
 			GroupCols:        groupCols,
 			OrderedGroupCols: orderedGroupCols,
+			GroupingSets:     groupingSets,
 		}
 	} else {
 		// Some aggregations might need multiple aggregation as part of
@@ -1867,13 +1891,22 @@ func (dsp *DistSQLPlanner) planAggregators(
 		}
 		finalOutTypes[i] = returnTyp
 	}
+	if info.groupingSets != nil {
+		// The aggregator outputs the index of the grouping set as an additional
+		// column.
+		finalOutTypes = append(finalOutTypes, types.Int)
+	}
 
 	// Update p.PlanToStreamColMap; we will have a simple 1-to-1 mapping of
 	// planNode columns to stream columns because the aggregator
 	// has been programmed to produce the same columns as the groupNode.
-	p.PlanToStreamColMap = identityMap(p.PlanToStreamColMap, len(info.aggregations))
+	p.PlanToStreamColMap = identityMap(p.PlanToStreamColMap, len(finalOutTypes))
 
-	if len(finalAggsSpec.GroupCols) == 0 || len(p.ResultRouters) == 1 {
+	hashCols := finalAggsSpec.GroupCols
+	if info.groupingSets != nil {
+		hashCols = commonGroupingSetCols
+	}
+	if len(hashCols) == 0 || len(p.ResultRouters) == 1 {
 		// No GROUP BY, or we have a single stream. Use a single final aggregator.
 		// If the previous stage was all on a single node, put the final
 		// aggregator there. Otherwise, bring the results back on this node.
@@ -1894,7 +1927,7 @@ func (dsp *DistSQLPlanner) planAggregators(
 		for _, resultProc := range p.ResultRouters {
 			p.Processors[resultProc].Spec.Output[0] = execinfrapb.OutputRouterSpec{
 				Type:        execinfrapb.OutputRouterSpec_BY_HASH,
-				HashColumns: finalAggsSpec.GroupCols,
+				HashColumns: hashCols,
 			}
 		}
 
@@ -3530,7 +3563,8 @@ func (dsp *DistSQLPlanner) createPlanForWindow(
 }
 
 // createPlanForExport creates a physical plan for EXPORT.
-// We add a new stage of CSVWriter processors to the input plan.
+// We add a new stage of CSVWriter or ParquetWriter processors to the input
+// plan.
 func (dsp *DistSQLPlanner) createPlanForExport(
 	planCtx *PlanningCtx, n *exportNode,
 ) (*PhysicalPlan, error) {
@@ -3538,14 +3572,27 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 	if err != nil {
 		return nil, err
 	}
-	core := execinfrapb.ProcessorCoreUnion{CSVWriter: &execinfrapb.CSVWriterSpec{
-		Destination:      n.destination,
-		NamePattern:      n.fileNamePattern,
-		Options:          n.csvOpts,
-		ChunkRows:        int64(n.chunkRows),
-		CompressionCodec: n.fileCompression,
-		UserProto:        planCtx.planner.User().EncodeProto(),
-	}}
+	var core execinfrapb.ProcessorCoreUnion
+	if n.fileFormat == exportFormatParquet {
+		core.ParquetWriter = &execinfrapb.ParquetWriterSpec{
+			Destination:      n.destination,
+			NamePattern:      n.fileNamePattern,
+			ColumnNames:      n.columnNames,
+			ChunkRows:        int64(n.chunkRows),
+			RowGroupSize:     n.rowGroupSize,
+			CompressionCodec: n.parquetCompression,
+			UserProto:        planCtx.planner.User().EncodeProto(),
+		}
+	} else {
+		core.CSVWriter = &execinfrapb.CSVWriterSpec{
+			Destination:      n.destination,
+			NamePattern:      n.fileNamePattern,
+			Options:          n.csvOpts,
+			ChunkRows:        int64(n.chunkRows),
+			CompressionCodec: n.fileCompression,
+			UserProto:        planCtx.planner.User().EncodeProto(),
+		}
+	}
 
 	resTypes := make([]*types.T, len(colinfo.ExportColumns))
 	for i := range colinfo.ExportColumns {
@@ -3555,7 +3602,7 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		core, execinfrapb.PostProcessSpec{}, resTypes, execinfrapb.Ordering{},
 	)
 
-	// The CSVWriter produces the same columns as the EXPORT statement.
+	// The writers produce the same columns as the EXPORT statement.
 	plan.PlanToStreamColMap = identityMap(plan.PlanToStreamColMap, len(colinfo.ExportColumns))
 	return plan, nil
 }
@@ -481,6 +481,7 @@ func (e *distSQLSpecExecFactory) constructAggregators(
 	input exec.Node,
 	groupCols []exec.NodeColumnOrdinal,
 	groupColOrdering colinfo.ColumnOrdering,
+	groupingSets []exec.NodeColumnOrdinalSet,
 	aggregations []exec.AggInfo,
 	reqOrdering exec.OutputOrdering,
 	isScalar bool,
@@ -527,13 +528,18 @@ func (e *distSQLSpecExecFactory) constructAggregators(
 			isScalar:             isScalar,
 			groupCols:            convertOrdinalsToInts(groupCols),
 			groupColOrdering:     groupColOrdering,
+			groupingSets:         groupingSets,
 			inputMergeOrdering:   physPlan.MergeOrdering,
 			reqOrdering:          ReqOrdering(reqOrdering),
 		},
 	); err != nil {
 		return nil, err
 	}
-	physPlan.ResultColumns = getResultColumnsForGroupBy(physPlan.ResultColumns, groupCols, aggregations)
+	if groupingSets != nil {
+		physPlan.ResultColumns = getResultColumnsForGroupingSets(physPlan.ResultColumns, groupCols, aggregations)
+	} else {
+		physPlan.ResultColumns = getResultColumnsForGroupBy(physPlan.ResultColumns, groupCols, aggregations)
+	}
 	return plan, nil
 }
 
@@ -548,6 +554,7 @@ func (e *distSQLSpecExecFactory) ConstructGroupBy(
 		input,
 		groupCols,
 		groupColOrdering,
+		nil, /* groupingSets */
 		aggregations,
 		reqOrdering,
 		false, /* isScalar */
@@ -561,12 +568,30 @@ func (e *distSQLSpecExecFactory) ConstructScalarGroupBy(
 		input,
 		nil, /* groupCols */
 		nil, /* groupColOrdering */
+		nil, /* groupingSets */
 		aggregations,
 		exec.OutputOrdering{}, /* reqOrdering */
 		true,                  /* isScalar */
 	)
 }
 
+func (e *distSQLSpecExecFactory) ConstructGroupingSets(
+	input exec.Node,
+	groupCols []exec.NodeColumnOrdinal,
+	groupingSets []exec.NodeColumnOrdinalSet,
+	aggregations []exec.AggInfo,
+) (exec.Node, error) {
+	return e.constructAggregators(
+		input,
+		groupCols,
+		nil, /* groupColOrdering */
+		groupingSets,
+		aggregations,
+		exec.OutputOrdering{}, /* reqOrdering */
+		false,                 /* isScalar */
+	)
+}
+
 func (e *distSQLSpecExecFactory) ConstructDistinct(
 	input exec.Node,
 	distinctCols, orderedCols exec.NodeColumnOrdinalSet,
@@ -161,6 +161,11 @@ func ExamineDescriptors(
 		case catalog.SchemaDescriptor:
 			// parent schema id is always 0.
 			skipParentSchemaCheck = true
+		case catalog.FunctionDescriptor:
+			if err := d.Validate(); err != nil {
+				problemsFound = true
+				fmt.Fprint(stdout, reportMsg(desc, "%s", err))
+			}
 		}
 
 		// TODO(postamar): The following descriptor checks on parent id, parent
@@ -349,6 +354,8 @@ func reportMsg(desc catalog.Descriptor, format string, args ...interface{}) stri
 		header = "  Schema"
 	case catalog.DatabaseDescriptor:
 		header = "Database"
+	case catalog.FunctionDescriptor:
+		header = "Function"
 	}
 	return fmt.Sprintf("%s %3d: ParentID %3d, ParentSchemaID %2d, Name '%s': ",
 		header, desc.GetID(), desc.GetParentID(), desc.GetParentSchemaID(), desc.GetName()) +
@@ -36,6 +36,7 @@ type dropCascadeState struct {
 	toDeleteByID            map[descpb.ID]*toDelete
 	allTableObjectsToDelete []*tabledesc.Mutable
 	typesToDelete           []*typedesc.Mutable
+	functionsToDelete       []functionToDrop
 
 	droppedNames []string
 }
@@ -154,8 +155,26 @@ func (d *dropCascadeState) resolveCollectedObjects(
 			if err != nil {
 				return err
 			}
-			// If we couldn't find the object at all, then continue.
 			if !found {
+				// If we couldn't resolve objName as a type either, try a function.
+				fn := tree.MakeQualifiedFunctionName(objName.Catalog(), objName.Schema(), objName.Object())
+				found, funcDesc, err := p.Descriptors().GetMutableFunctionByName(
+					ctx, p.txn, &fn, tree.ObjectLookupFlags{
+						CommonLookupFlags: tree.CommonLookupFlags{
+							RequireMutable: true,
+							IncludeOffline: true,
+						},
+					})
+				if err != nil {
+					return err
+				}
+				if found {
+					d.functionsToDelete = append(d.functionsToDelete, functionToDrop{
+						desc:   funcDesc,
+						fqName: fn.FQString(),
+					})
+				}
+				// Otherwise, we couldn't find the object at all.
 				continue
 			}
 			typDesc, ok := desc.(*typedesc.Mutable)
@@ -219,6 +238,15 @@ func (d *dropCascadeState) dropAllCollectedObjects(ctx context.Context, p *plann
 		}
 	}
 
+	// Finally, delete all of the functions. Nothing can depend on a function,
+	// so there is nothing to check. Each function is deleted by its own job.
+	for _, fn := range d.functionsToDelete {
+		if err := p.dropFunctionImpl(ctx, fn.desc, "dropping function "+fn.fqName); err != nil {
+			return err
+		}
+		d.droppedNames = append(d.droppedNames, fn.fqName)
+	}
+
 	return nil
 }
 
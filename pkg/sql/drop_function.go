@@ -0,0 +1,112 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type functionToDrop struct {
+	desc   *funcdesc.Mutable
+	fqName string
+}
+
+type dropFunctionNode struct {
+	n  *tree.DropFunction
+	fd []functionToDrop
+}
+
+// Use to satisfy the linter.
+var _ planNode = &dropFunctionNode{n: nil}
+
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	node := &dropFunctionNode{n: n}
+	seen := make(map[descpb.ID]struct{})
+	for i := range n.Functions {
+		// Resolve the desired function descriptor.
+		name, funcDesc, err := p.ResolveMutableFunctionDescriptor(ctx, &n.Functions[i], !n.IfExists)
+		if err != nil {
+			return nil, err
+		}
+		if funcDesc == nil {
+			continue
+		}
+		// If we've already seen this function, then skip it.
+		if _, ok := seen[funcDesc.ID]; ok {
+			continue
+		}
+		seen[funcDesc.ID] = struct{}{}
+
+		// Nothing can depend on a function, so CASCADE and RESTRICT are
+		// equivalent; only check that the function can be dropped.
+		if err := p.canModifyFunction(ctx, funcDesc); err != nil {
+			return nil, err
+		}
+		node.fd = append(node.fd, functionToDrop{desc: funcDesc, fqName: name.FQString()})
+	}
+	return node, nil
+}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	for _, toDrop := range n.fd {
+		if err := params.p.dropFunctionImpl(
+			params.ctx, toDrop.desc, tree.AsStringWithFQNames(n.n, params.Ann()),
+		); err != nil {
+			return err
+		}
+		// Log a Drop Function event.
+		if err := params.p.logEvent(params.ctx, toDrop.desc.ID,
+			&eventpb.DropFunction{FunctionName: toDrop.fqName}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropFunctionImpl marks the function as dropped and queues a job which
+// removes its name and deletes its descriptor.
+func (p *planner) dropFunctionImpl(
+	ctx context.Context, funcDesc *funcdesc.Mutable, jobDesc string,
+) error {
+	if funcDesc.Dropped() {
+		return errors.Errorf("function %q is already being dropped", funcDesc.Name)
+	}
+
+	// Add a draining name.
+	funcDesc.DrainingNames = append(funcDesc.DrainingNames, descpb.NameInfo{
+		ParentID:       funcDesc.ParentID,
+		ParentSchemaID: funcDesc.ParentSchemaID,
+		Name:           funcDesc.Name,
+	})
+
+	// Actually mark the function as dropped.
+	funcDesc.SetDropped()
+	return p.writeFunctionDescChange(ctx, funcDesc, jobDesc)
+}
+
+func (n *dropFunctionNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *dropFunctionNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *dropFunctionNode) Close(ctx context.Context)           {}
+func (n *dropFunctionNode) ReadingOwnWrites()                   {}
@@ -377,6 +377,11 @@ func (p *planner) dropTableImpl(
 		return droppedViews, err
 	}
 
+	// Remove the schedule deleting the expired rows of the table.
+	if err := p.deleteRowLevelTTLScheduledJob(ctx, tableDesc); err != nil {
+		return droppedViews, err
+	}
+
 	err = p.initiateDropTable(ctx, tableDesc, !droppingParent, jobDesc, true /* drain name */)
 	return droppedViews, err
 }
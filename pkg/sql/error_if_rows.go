@@ -39,14 +39,25 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	}
 	n.nexted = true
 
-	ok, err := n.plan.Next(params)
-	if err != nil {
-		return false, err
+	for {
+		ok, err := n.plan.Next(params)
+		if err != nil || !ok {
+			return false, err
+		}
+		err = n.mkErr(n.plan.Values())
+		v, ok := err.(*exec.DeferrableCheckViolation)
+		if !ok {
+			return false, err
+		}
+		// The check is for a deferrable constraint. If it is deferred, queue
+		// the violation so that it is checked again before the transaction
+		// commits, and look for more violations.
+		d := params.extendedEvalCtx.DeferredConstraints
+		if d == nil || !d.isDeferred(v) {
+			return false, v.Err
+		}
+		d.add(v)
 	}
-	if ok {
-		return false, n.mkErr(n.plan.Values())
-	}
-	return false, nil
 }
 
 func (n *errorIfRowsNode) Values() tree.Datums {
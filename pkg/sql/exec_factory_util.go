@@ -196,6 +196,16 @@ func getResultColumnsForGroupBy(
 	return columns
 }
 
+// getResultColumnsForGroupingSets returns the result columns of a grouping
+// sets aggregation: the columns of a GROUP BY on all the grouping columns,
+// followed by the index of the grouping set.
+func getResultColumnsForGroupingSets(
+	inputCols colinfo.ResultColumns, groupCols []exec.NodeColumnOrdinal, aggregations []exec.AggInfo,
+) colinfo.ResultColumns {
+	columns := getResultColumnsForGroupBy(inputCols, groupCols, aggregations)
+	return append(columns, colinfo.ResultColumn{Name: "grouping_set", Typ: types.Int})
+}
+
 // convertOrdinalsToInts converts a slice of exec.NodeColumnOrdinals to a slice
 // of ints.
 func convertOrdinalsToInts(ordinals []exec.NodeColumnOrdinal) []int {
@@ -56,6 +56,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -151,6 +152,16 @@ var allowCrossDatabaseSeqOwner = settings.RegisterBoolSetting(
 	false,
 ).WithPublic()
 
+// readCommittedIsolationEnabled controls whether transactions can run under
+// the READ COMMITTED isolation level. When disabled, transactions asking for
+// it are silently upgraded to SERIALIZABLE.
+var readCommittedIsolationEnabled = settings.RegisterBoolSetting(
+	"sql.txn.read_committed_isolation.enabled",
+	"set to true to allow transactions to use the READ COMMITTED isolation level; "+
+		"when false, such transactions run under SERIALIZABLE isolation",
+	false,
+)
+
 // traceTxnThreshold can be used to log SQL transactions that take
 // longer than duration to complete. For example, traceTxnThreshold=1s
 // will log the trace for any transaction that takes 1s or longer. To
@@ -787,6 +798,10 @@ type ExecutorConfig struct {
 	// StmtDiagnosticsRecorder deals with recording statement diagnostics.
 	StmtDiagnosticsRecorder *stmtdiagnostics.Registry
 
+	// NotificationRegistry delivers the notifications generated by NOTIFY
+	// statements to the sessions listening on this node.
+	NotificationRegistry *notify.Registry
+
 	ExternalIODirConfig base.ExternalIODirConfig
 
 	// HydratedTables is a node-level cache of table descriptors which utilize
@@ -995,6 +1010,12 @@ func (*BackupRestoreTestingKnobs) ModuleTestingKnobs() {}
 // StreamIngestionTestingKnobs contains knobs for stream ingestion behavior.
 type StreamIngestionTestingKnobs struct {
 	Interceptors []func(event streamingccl.Event, pa streamingccl.PartitionAddress)
+	// BeforeCutover is called once the ingestion has stopped, before the
+	// ingested data is reverted to the cutover time.
+	BeforeCutover func(ctx context.Context, cutoverTime hlc.Timestamp) error
+	// RevertBatchSize, if positive, overrides the maximum number of keys that
+	// are reverted in a single batch when the ingested data is reverted.
+	RevertBatchSize int64
 }
 
 var _ base.ModuleTestingKnobs = &StreamIngestionTestingKnobs{}
@@ -1175,6 +1196,8 @@ func checkResultType(typ *types.T) error {
 	case types.OidFamily:
 	case types.TupleFamily:
 	case types.EnumFamily:
+	case types.TSQueryFamily:
+	case types.TSVectorFamily:
 	case types.ArrayFamily:
 		if typ.ArrayContents().Family() == types.ArrayFamily {
 			// Technically we could probably return arrays of arrays to a
@@ -2125,6 +2148,10 @@ func (m *sessionDataMutator) SetDefaultTransactionReadOnly(val bool) {
 	m.data.DefaultTxnReadOnly = val
 }
 
+func (m *sessionDataMutator) SetDefaultTransactionIsolationLevel(val tree.IsolationLevel) {
+	m.data.DefaultTxnIsolationLevel = int(val)
+}
+
 func (m *sessionDataMutator) SetDefaultTransactionUseFollowerReads(val bool) {
 	m.data.DefaultTxnUseFollowerReads = val
 }
@@ -2252,6 +2279,10 @@ func (m *sessionDataMutator) SetSaveTablesPrefix(prefix string) {
 	m.data.SaveTablesPrefix = prefix
 }
 
+func (m *sessionDataMutator) SetTraceParent(val string) {
+	m.data.TraceParent = val
+}
+
 func (m *sessionDataMutator) SetTempTablesEnabled(val bool) {
 	m.data.TempTablesEnabled = val
 }
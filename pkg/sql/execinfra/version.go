@@ -39,7 +39,7 @@ import "github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 //
 // ATTENTION: When updating these fields, add a brief description of what
 // changed to the version history below.
-const Version execinfrapb.DistSQLVersion = 45
+const Version execinfrapb.DistSQLVersion = 46
 
 // MinAcceptedVersion is the oldest version that the server is compatible with.
 // A server will not accept flows with older versions.
@@ -51,6 +51,12 @@ const MinAcceptedVersion execinfrapb.DistSQLVersion = 44
 
 Please add new entries at the top.
 
+- Version: 46 (MinAcceptedVersion: 44)
+  - A new field GroupingSets was added to AggregatorSpec for computing the
+    aggregations of multiple grouping sets in a single pass over the input.
+    The change is backwards compatible (mixed versions will prevent
+    parallelization).
+
 - Version: 45 (MinAcceptedVersion: 44)
   - A new field PrefixEqualityColumns was added to InvertedJoinerSpec for
     performing inverted joins on multi-column inverted indexes.
@@ -56,6 +56,11 @@ func (m *CSVWriterSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
 }
 
+// User accesses the user field.
+func (m *ParquetWriterSpec) User() security.SQLUsername {
+	return m.UserProto.Decode()
+}
+
 // User accesses the user field.
 func (m *ReadImportDataSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
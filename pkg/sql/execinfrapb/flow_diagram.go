@@ -113,6 +113,17 @@ func (a *AggregatorSpec) summary() (string, []string) {
 	if len(a.OrderedGroupCols) > 0 {
 		details = append(details, fmt.Sprintf("Ordered: %s", colListStr(a.OrderedGroupCols)))
 	}
+	if len(a.GroupingSets) > 0 {
+		var buf bytes.Buffer
+		buf.WriteString("Grouping sets: ")
+		for i := range a.GroupingSets {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "(%s)", colListStr(a.GroupingSets[i].Cols))
+		}
+		details = append(details, buf.String())
+	}
 	for _, agg := range a.Aggregations {
 		var buf bytes.Buffer
 		buf.WriteString(agg.Func.String())
@@ -488,11 +499,21 @@ func (s *CSVWriterSpec) summary() (string, []string) {
 	return "CSVWriter", []string{s.Destination}
 }
 
+// summary implements the diagramCellType interface.
+func (s *ParquetWriterSpec) summary() (string, []string) {
+	return "ParquetWriter", []string{s.Destination}
+}
+
 // summary implements the diagramCellType interface.
 func (s *BulkRowWriterSpec) summary() (string, []string) {
 	return "BulkRowWriterSpec", []string{}
 }
 
+// summary implements the diagramCellType interface.
+func (s *TTLSpec) summary() (string, []string) {
+	return "TTL", []string{s.TableDesc.Name}
+}
+
 // summary implements the diagramCellType interface.
 func (w *WindowerSpec) summary() (string, []string) {
 	details := make([]string, 0, len(w.WindowFns))
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -178,12 +179,15 @@ func buildStatementBundle(
 //
 // diagRequestID should be the ID returned by ShouldCollectDiagnostics, or zero
 // if diagnostics were triggered by EXPLAIN ANALYZE (DEBUG).
+//
+// execLatency is the execution latency of the statement.
 func (bundle *diagnosticsBundle) insert(
 	ctx context.Context,
 	fingerprint string,
 	ast tree.Statement,
 	stmtDiagRecorder *stmtdiagnostics.Registry,
 	diagRequestID stmtdiagnostics.RequestID,
+	execLatency time.Duration,
 ) {
 	var err error
 	bundle.diagID, err = stmtDiagRecorder.InsertStatementDiagnostics(
@@ -194,6 +198,7 @@ func (bundle *diagnosticsBundle) insert(
 		bundle.traceJSON,
 		bundle.zip,
 		bundle.collectionErr,
+		execLatency,
 	)
 	if err != nil {
 		log.Warningf(ctx, "failed to report statement diagnostics: %s", err)
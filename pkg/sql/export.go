@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -40,9 +41,17 @@ type exportNode struct {
 	// fileNamePattern represents the file naming pattern for the
 	// export, typically to be appended to the destination URI
 	fileNamePattern string
+	// fileFormat is the format of the exported files, either CSV or PARQUET.
+	fileFormat      string
 	csvOpts         roachpb.CSVOptions
 	chunkRows       int
 	fileCompression execinfrapb.FileCompression
+
+	// columnNames, rowGroupSize and parquetCompression are only used when
+	// exporting to Parquet.
+	columnNames        []string
+	rowGroupSize       int64
+	parquetCompression execinfrapb.ParquetWriterSpec_Compression
 }
 
 func (e *exportNode) startExec(params runParams) error {
@@ -62,26 +71,63 @@ func (e *exportNode) Close(ctx context.Context) {
 }
 
 const (
-	exportOptionDelimiter   = "delimiter"
-	exportOptionNullAs      = "nullas"
-	exportOptionChunkRows   = "chunk_rows"
-	exportOptionFileName    = "filename"
-	exportOptionCompression = "compression"
+	exportOptionDelimiter    = "delimiter"
+	exportOptionNullAs       = "nullas"
+	exportOptionChunkRows    = "chunk_rows"
+	exportOptionFileName     = "filename"
+	exportOptionCompression  = "compression"
+	exportOptionRowGroupSize = "row_group_size"
+)
+
+// ExportOptionExpectValues is used to validate the options of EXPORT, including
+// those of the exports run by the scheduled exports.
+var ExportOptionExpectValues = map[string]KVStringOptValidate{
+	exportOptionChunkRows:    KVStringOptRequireValue,
+	exportOptionDelimiter:    KVStringOptRequireValue,
+	exportOptionFileName:     KVStringOptRequireValue,
+	exportOptionNullAs:       KVStringOptRequireValue,
+	exportOptionCompression:  KVStringOptRequireValue,
+	exportOptionRowGroupSize: KVStringOptRequireValue,
+}
+
+const (
+	exportFormatCSV     = "CSV"
+	exportFormatParquet = "PARQUET"
 )
 
-var exportOptionExpectValues = map[string]KVStringOptValidate{
-	exportOptionChunkRows:   KVStringOptRequireValue,
-	exportOptionDelimiter:   KVStringOptRequireValue,
-	exportOptionFileName:    KVStringOptRequireValue,
-	exportOptionNullAs:      KVStringOptRequireValue,
-	exportOptionCompression: KVStringOptRequireValue,
+// exportFormatAllowedOptions are the format specific options allowed by each
+// of the export formats. The remaining options are common to all formats.
+var exportFormatAllowedOptions = map[string]map[string]struct{}{
+	exportFormatCSV: {
+		exportOptionDelimiter: {},
+		exportOptionNullAs:    {},
+	},
+	exportFormatParquet: {
+		exportOptionRowGroupSize: {},
+	},
+}
+
+var exportCommonOptions = map[string]struct{}{
+	exportOptionChunkRows:   {},
+	exportOptionFileName:    {},
+	exportOptionCompression: {},
 }
 
 const exportChunkRowsDefault = 100000
 const exportFilePatternPart = "%part%"
 const exportFilePatternDefault = exportFilePatternPart + ".csv"
+const exportParquetFilePatternDefault = exportFilePatternPart + ".parquet"
 const exportCompressionCodec = "gzip"
 
+// exportParquetCompressionCodecs maps the names accepted by the compression
+// option of a Parquet export to the codec they select. Parquet compresses
+// column chunks inside of the file, so the files keep their extension.
+var exportParquetCompressionCodecs = map[string]execinfrapb.ParquetWriterSpec_Compression{
+	"none":   execinfrapb.ParquetWriterSpec_NONE,
+	"gzip":   execinfrapb.ParquetWriterSpec_GZIP,
+	"snappy": execinfrapb.ParquetWriterSpec_SNAPPY,
+}
+
 // featureExportEnabled is used to enable and disable the EXPORT feature.
 var featureExportEnabled = settings.RegisterBoolSetting(
 	"feature.export.enabled",
@@ -113,7 +159,7 @@ func (ef *execFactory) ConstructExport(
 		return nil, errors.Errorf("EXPORT cannot be used inside a transaction")
 	}
 
-	if fileFormat != "CSV" {
+	if fileFormat != exportFormatCSV && fileFormat != exportFormatParquet {
 		return nil, errors.Errorf("unsupported export format: %q", fileFormat)
 	}
 
@@ -142,10 +188,19 @@ func (ef *execFactory) ConstructExport(
 		}
 	}
 
-	optVals, err := evalStringOptions(ef.planner.EvalContext(), options, exportOptionExpectValues)
+	optVals, err := evalStringOptions(ef.planner.EvalContext(), options, ExportOptionExpectValues)
 	if err != nil {
 		return nil, err
 	}
+	for opt := range optVals {
+		if _, ok := exportCommonOptions[opt]; ok {
+			continue
+		}
+		if _, ok := exportFormatAllowedOptions[fileFormat][opt]; !ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"invalid option %q specified for %s export format", opt, fileFormat)
+		}
+	}
 
 	csvOpts := roachpb.CSVOptions{}
 
@@ -171,6 +226,13 @@ func (ef *execFactory) ConstructExport(
 		}
 	}
 
+	exportID := ef.planner.stmt.QueryID.String()
+	source := input.(planNode)
+
+	if fileFormat == exportFormatParquet {
+		return makeParquetExportNode(source, string(*destination), exportID, chunkRows, optVals)
+	}
+
 	// Check whenever compression is expected and extract compression codec name in case
 	// of positive result
 	var codec execinfrapb.FileCompression
@@ -183,15 +245,70 @@ func (ef *execFactory) ConstructExport(
 		}
 	}
 
-	exportID := ef.planner.stmt.QueryID.String()
 	namePattern := fmt.Sprintf("export%s-%s", exportID, exportFilePatternDefault)
 
 	return &exportNode{
-		source:          input.(planNode),
+		source:          source,
 		destination:     string(*destination),
 		fileNamePattern: namePattern,
+		fileFormat:      exportFormatCSV,
 		csvOpts:         csvOpts,
 		chunkRows:       chunkRows,
 		fileCompression: codec,
 	}, nil
 }
+
+// makeParquetExportNode returns an exportNode which writes the rows of source
+// to Parquet files. The columns of the files are named after the result
+// columns of source, which must therefore be unique.
+func makeParquetExportNode(
+	source planNode, destination, exportID string, chunkRows int, optVals map[string]string,
+) (*exportNode, error) {
+	cols := planColumns(source)
+	columnNames := make([]string, len(cols))
+	seen := make(map[string]struct{}, len(cols))
+	for i := range cols {
+		name := cols[i].Name
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.Newf(pgcode.InvalidColumnReference,
+				"duplicate column name %q in PARQUET export, use an alias to rename the column", name)
+		}
+		if strings.Contains(name, ".") {
+			return nil, pgerror.Newf(pgcode.InvalidColumnReference,
+				"column name %q in PARQUET export cannot contain \".\", use an alias to rename the column", name)
+		}
+		seen[name] = struct{}{}
+		columnNames[i] = name
+	}
+
+	codec := execinfrapb.ParquetWriterSpec_SNAPPY
+	if name, ok := optVals[exportOptionCompression]; ok && len(name) != 0 {
+		if codec, ok = exportParquetCompressionCodecs[strings.ToLower(name)]; !ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unsupported compression codec %s", name)
+		}
+	}
+
+	var rowGroupSize int64
+	if override, ok := optVals[exportOptionRowGroupSize]; ok {
+		sz, err := humanizeutil.ParseBytes(override)
+		if err != nil {
+			return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+		}
+		if sz < 1 {
+			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid parquet row group size")
+		}
+		rowGroupSize = sz
+	}
+
+	return &exportNode{
+		source:             source,
+		destination:        destination,
+		fileNamePattern:    fmt.Sprintf("export%s-%s", exportID, exportParquetFilePatternDefault),
+		fileFormat:         exportFormatParquet,
+		chunkRows:          chunkRows,
+		columnNames:        columnNames,
+		rowGroupSize:       rowGroupSize,
+		parquetCompression: codec,
+	}, nil
+}
@@ -13,6 +13,7 @@ package faketreeeval
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -153,6 +154,18 @@ func (ep *DummyEvalPlanner) CompactEngineSpan(
 	return errors.WithStack(errEvalPlanner)
 }
 
+// RequestStatementBundle is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) RequestStatementBundle(
+	ctx context.Context,
+	fingerprint string,
+	samplingProbability float64,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+	continuous bool,
+) error {
+	return errors.WithStack(errEvalPlanner)
+}
+
 // MemberOfWithAdminOption is part of the EvalPlanner interface.
 func (ep *DummyEvalPlanner) MemberOfWithAdminOption(
 	ctx context.Context, member security.SQLUsername,
@@ -160,6 +173,16 @@ func (ep *DummyEvalPlanner) MemberOfWithAdminOption(
 	return nil, errors.WithStack(errEvalPlanner)
 }
 
+// SerializeSessionState is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) SerializeSessionState() (*tree.DBytes, error) {
+	return nil, errors.WithStack(errEvalPlanner)
+}
+
+// DeserializeSessionState is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) DeserializeSessionState(state *tree.DBytes) (*tree.DBool, error) {
+	return nil, errors.WithStack(errEvalPlanner)
+}
+
 var _ tree.EvalPlanner = &DummyEvalPlanner{}
 
 var errEvalPlanner = pgerror.New(pgcode.ScalarOperationCannotRunWithoutFullSessionContext,
@@ -0,0 +1,148 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// ResolveMutableFunctionDescriptor resolves the function referenced by the
+// given FuncObj. If the argument types of the function are specified, they
+// must match those of the function. It returns nil if the function does not
+// exist and required is false.
+func (p *planner) ResolveMutableFunctionDescriptor(
+	ctx context.Context, fn *tree.FuncObj, required bool,
+) (*tree.FunctionName, *funcdesc.Mutable, error) {
+	found, prefix, desc, err := tree.ResolveExisting(
+		ctx, fn.FuncName, functionLookup{p: p, mutable: true}, tree.ObjectLookupFlags{},
+		p.CurrentDatabase(), p.CurrentSearchPath(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if found && fn.HasArgs {
+		found, err = p.functionArgsMatch(ctx, desc.(*funcdesc.Mutable), fn.Args)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if !found {
+		if required {
+			return nil, nil, sqlerrors.NewUndefinedFunctionError(fn)
+		}
+		return nil, nil, nil
+	}
+	name := tree.MakeFunctionNameWithPrefix(prefix, fn.FuncName.Object())
+	return &name, desc.(*funcdesc.Mutable), nil
+}
+
+// functionArgsMatch returns whether the given arguments have the same types as
+// the arguments of the function.
+func (p *planner) functionArgsMatch(
+	ctx context.Context, desc *funcdesc.Mutable, args tree.FuncArgs,
+) (bool, error) {
+	if len(args) != len(desc.Args) {
+		return false, nil
+	}
+	for i := range args {
+		typ, err := tree.ResolveType(ctx, args[i].Type, p.semaCtx.GetTypeResolver())
+		if err != nil {
+			return false, err
+		}
+		if !typ.Identical(desc.Args[i].Type) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// canModifyFunction checks whether the current user can modify or drop the
+// given function.
+func (p *planner) canModifyFunction(ctx context.Context, desc *funcdesc.Mutable) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin {
+		return nil
+	}
+
+	hasOwnership, err := p.HasOwnership(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if !hasOwnership {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of function %s", tree.Name(desc.GetName()))
+	}
+	return nil
+}
+
+func (p *planner) writeFunctionDesc(ctx context.Context, desc *funcdesc.Mutable) error {
+	b := p.txn.NewBatch()
+	if err := p.Descriptors().WriteDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), desc, b,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// writeFunctionDescChange writes the given function descriptor and queues a
+// schema change job which waits for the previous version of the descriptor
+// to be released and, if the function is being dropped, deletes it.
+func (p *planner) writeFunctionDescChange(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	job, jobExists := p.extendedEvalCtx.SchemaChangeJobCache[desc.ID]
+	if jobExists {
+		// Update it.
+		if err := job.WithTxn(p.txn).SetDescription(ctx,
+			func(ctx context.Context, desc string) (string, error) {
+				return desc + "; " + jobDesc, nil
+			},
+		); err != nil {
+			return err
+		}
+		log.Infof(ctx, "job %d: updated with for change on function %d", *job.ID(), desc.ID)
+	} else {
+		// Or, create a new job.
+		jobRecord := jobs.Record{
+			Description:   jobDesc,
+			Username:      p.User(),
+			DescriptorIDs: descpb.IDs{desc.ID},
+			Details: jobspb.SchemaChangeDetails{
+				DescID: desc.ID,
+				// The version distinction for database jobs doesn't matter for
+				// function jobs.
+				FormatVersion: jobspb.DatabaseJobFormatVersion,
+			},
+			Progress: jobspb.SchemaChangeProgress{},
+		}
+		newJob, err := p.extendedEvalCtx.QueueJob(ctx, jobRecord)
+		if err != nil {
+			return err
+		}
+		log.Infof(ctx, "queued new schema change job %d for function %d", *newJob.ID(), desc.ID)
+	}
+
+	return p.writeFunctionDesc(ctx, desc)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -49,6 +50,9 @@ func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
 	case n.Targets.Types != nil:
 		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnType)
 		grantOn = privilege.Type
+	case n.Targets.Functions != nil:
+		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnFunction)
+		grantOn = privilege.Function
 	default:
 		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnTable)
 		grantOn = privilege.Table
@@ -100,6 +104,9 @@ func (p *planner) Revoke(ctx context.Context, n *tree.Revoke) (planNode, error)
 	case n.Targets.Types != nil:
 		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnType)
 		grantOn = privilege.Type
+	case n.Targets.Functions != nil:
+		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnFunction)
+		grantOn = privilege.Function
 	default:
 		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnTable)
 		grantOn = privilege.Table
@@ -273,6 +280,23 @@ func (n *changePrivilegesNode) startExec(params runParams) error {
 						TypeName:                       d.Name, // FIXME
 					}})
 			}
+		case *funcdesc.Mutable:
+			if err := p.writeFunctionDescChange(
+				ctx,
+				d,
+				fmt.Sprintf("updating privileges for function %d", d.ID),
+			); err != nil {
+				return err
+			}
+			for _, grantee := range n.grantees {
+				privs := eventDetails // copy the granted/revoked privilege list.
+				privs.Grantee = grantee.Normalized()
+				events = append(events, eventEntry{d.ID,
+					&eventpb.ChangeFunctionPrivilege{
+						CommonSQLPrivilegeEventDetails: privs,
+						FunctionName:                   d.Name, // FIXME
+					}})
+			}
 		case *schemadesc.Mutable:
 			if err := p.writeSchemaDescChange(
 				ctx,
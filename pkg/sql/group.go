@@ -15,6 +15,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
 // A groupNode implements the planNode interface and handles the grouping logic.
@@ -37,6 +38,12 @@ type groupNode struct {
 	// even if there are no input rows, e.g. SELECT MIN(x) FROM t.
 	isScalar bool
 
+	// groupingSets is set if the aggregation is computed separately for each of
+	// several grouping sets. Each set contains indices in the source plan, and is
+	// a subset of groupCols. In this case, the groupNode has an additional
+	// trailing column with the index of each row's grouping set.
+	groupingSets []util.FastIntSet
+
 	// funcs contains the information about all aggregate functions.
 	funcs []*aggregateFuncHolder
 
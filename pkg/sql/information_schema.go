@@ -1435,6 +1435,8 @@ CREATE TABLE information_schema.table_constraints (
 				tbNameStr := tree.NewDString(table.GetName())
 
 				for conName, c := range conInfo {
+					deferrable := c.Deferrability() != descpb.ConstraintDeferrability_NotDeferrable
+					initiallyDeferred := c.Deferrability() == descpb.ConstraintDeferrability_InitiallyDeferred
 					if err := addRow(
 						dbNameStr,                       // constraint_catalog
 						scNameStr,                       // constraint_schema
@@ -1443,8 +1445,8 @@ CREATE TABLE information_schema.table_constraints (
 						scNameStr,                       // table_schema
 						tbNameStr,                       // table_name
 						tree.NewDString(string(c.Kind)), // constraint_type
-						yesOrNoDatum(false),             // is_deferrable
-						yesOrNoDatum(false),             // initially_deferred
+						yesOrNoDatum(deferrable),        // is_deferrable
+						yesOrNoDatum(initiallyDeferred), // initially_deferred
 					); err != nil {
 						return err
 					}
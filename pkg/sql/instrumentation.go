@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -29,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/errors"
@@ -88,12 +90,16 @@ type instrumentationHelper struct {
 	discardRows bool
 
 	diagRequestID               stmtdiagnostics.RequestID
+	diagRequest                 stmtdiagnostics.Request
 	finishCollectionDiagnostics func()
 	withStatementTrace          func(trace tracing.Recording, stmt string)
 
-	sp      *tracing.Span
-	origCtx context.Context
-	evalCtx *tree.EvalContext
+	sp *tracing.Span
+	// startTime is the time at which the verbose trace of the statement
+	// started, used to measure its execution latency.
+	startTime time.Time
+	origCtx   context.Context
+	evalCtx   *tree.EvalContext
 
 	// If savePlanForStats is true, the explainPlan will be collected and returned
 	// via PlanForStats().
@@ -155,7 +161,7 @@ func (ih *instrumentationHelper) Setup(
 		ih.discardRows = true
 
 	default:
-		ih.collectBundle, ih.diagRequestID, ih.finishCollectionDiagnostics =
+		ih.collectBundle, ih.diagRequestID, ih.diagRequest, ih.finishCollectionDiagnostics =
 			stmtDiagnosticsRecorder.ShouldCollectDiagnostics(ctx, fingerprint)
 	}
 
@@ -188,6 +194,7 @@ func (ih *instrumentationHelper) Setup(
 	ih.traceMetadata = make(execNodeTraceMetadata)
 	ih.origCtx = ctx
 	ih.evalCtx = p.EvalContext()
+	ih.startTime = timeutil.Now()
 	newCtx, ih.sp = tracing.StartVerboseTrace(ctx, cfg.AmbientCtx.Tracer, "traced statement")
 	return newCtx, true
 }
@@ -252,16 +259,24 @@ func (ih *instrumentationHelper) Finish(
 
 	var bundle diagnosticsBundle
 	if ih.collectBundle {
-		ie := p.extendedEvalCtx.InternalExecutor.(*InternalExecutor)
-		placeholders := p.extendedEvalCtx.Placeholders
-		planStr := ih.planStringForBundle(&statsCollector.phaseTimes)
-		bundle = buildStatementBundle(
-			ih.origCtx, cfg.DB, ie, &p.curPlan, planStr, trace, placeholders,
-		)
-		bundle.insert(ctx, ih.fingerprint, ast, cfg.StmtDiagnosticsRecorder, ih.diagRequestID)
+		execLatency := timeutil.Since(ih.startTime)
+		// A diagnostics request may only be interested in the slow executions of
+		// the statement, in which case the trace of a fast execution is
+		// discarded.
+		if ih.diagRequestID == 0 || execLatency >= ih.diagRequest.MinExecutionLatency() {
+			ie := p.extendedEvalCtx.InternalExecutor.(*InternalExecutor)
+			placeholders := p.extendedEvalCtx.Placeholders
+			planStr := ih.planStringForBundle(&statsCollector.phaseTimes)
+			bundle = buildStatementBundle(
+				ih.origCtx, cfg.DB, ie, &p.curPlan, planStr, trace, placeholders,
+			)
+			bundle.insert(ctx, ih.fingerprint, ast, cfg.StmtDiagnosticsRecorder, ih.diagRequestID, execLatency)
+			if ih.finishCollectionDiagnostics != nil {
+				telemetry.Inc(sqltelemetry.StatementDiagnosticsCollectedCounter)
+			}
+		}
 		if ih.finishCollectionDiagnostics != nil {
 			ih.finishCollectionDiagnostics()
-			telemetry.Inc(sqltelemetry.StatementDiagnosticsCollectedCounter)
 		}
 	}
 
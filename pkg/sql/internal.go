@@ -72,6 +72,17 @@ type InternalExecutor struct {
 	//
 	// Warning: Not safe for concurrent use from multiple goroutines.
 	syntheticDescriptors []catalog.Descriptor
+
+	// lockstep, if set, makes the queries executed through the
+	// InternalExecutor run in lockstep with the iterator consuming their
+	// results: the connExecutor goroutine blocks after delivering the result
+	// columns and each row until the iterator asks for the next row. This
+	// guarantees that the query does not use its transaction while the
+	// iterator is not being advanced, which allows the transaction to be used
+	// by other statements in between (see sql_cursor.go).
+	//
+	// Only queries returning rows can be executed in this mode.
+	lockstep bool
 }
 
 // WithSyntheticDescriptors sets the synthetic descriptors before running the
@@ -138,6 +149,7 @@ func (ie *InternalExecutor) initConnEx(
 	ctx context.Context,
 	txn *kv.Txn,
 	ch chan ieIteratorResult,
+	resume chan struct{},
 	sd *sessiondata.SessionData,
 	stmtBuf *StmtBuf,
 	wg *sync.WaitGroup,
@@ -145,7 +157,8 @@ func (ie *InternalExecutor) initConnEx(
 	errCallback func(error),
 ) {
 	clientComm := &internalClientComm{
-		ch: ch,
+		ch:     ch,
+		resume: resume,
 		// init lastDelivered below the position of the first result (0).
 		lastDelivered: -1,
 		sync:          syncCallback,
@@ -231,6 +244,14 @@ type rowsIterator struct {
 	rowsAffected int
 	resultCols   colinfo.ResultColumns
 
+	// resume is only set in lockstep mode (see InternalExecutor.lockstep). It
+	// is used to unblock the connExecutor goroutine once the iterator is ready
+	// to receive the next row, and is closed on Close().
+	resume chan struct{}
+	// blocked indicates whether the connExecutor goroutine is blocked waiting
+	// on resume.
+	blocked bool
+
 	lastRow tree.Datums
 	lastErr error
 	done    bool
@@ -276,6 +297,14 @@ func (r *rowsIterator) Next(ctx context.Context) (_ bool, retErr error) {
 		retErr = r.lastErr
 	}()
 
+	if r.blocked {
+		// The channel has a buffer of one element and the connExecutor
+		// goroutine consumes the element before sending anything else, so this
+		// never blocks.
+		r.resume <- struct{}{}
+		r.blocked = false
+	}
+
 	select {
 	case next, ok := <-r.ch:
 		if !ok {
@@ -287,6 +316,7 @@ func (r *rowsIterator) Next(ctx context.Context) (_ bool, retErr error) {
 			// No need to make a copy because streamingCommandResult does that
 			// for us.
 			r.lastRow = next.row
+			r.blocked = r.resume != nil
 			return true, nil
 		}
 		if next.rowsAffectedIncrement != nil {
@@ -303,6 +333,7 @@ func (r *rowsIterator) Next(ctx context.Context) (_ bool, retErr error) {
 			if r.resultCols == nil {
 				r.resultCols = next.cols
 			}
+			r.blocked = r.resume != nil
 			return r.Next(ctx)
 		}
 		if next.err == nil {
@@ -327,6 +358,13 @@ func (r *rowsIterator) Close() error {
 	// Closing the stmtBuf will tell the connExecutor to stop executing commands
 	// (if it hasn't exited yet).
 	r.stmtBuf.Close()
+	if r.resume != nil {
+		// Unblock the connExecutor goroutine and tell it to stop producing
+		// rows.
+		close(r.resume)
+		r.resume = nil
+		r.blocked = false
+	}
 	// We need to finish the span but only after the connExecutor goroutine is
 	// done.
 	defer func() {
@@ -345,8 +383,9 @@ func (r *rowsIterator) Close() error {
 	// be pushed into the channel). Improve this.
 	for res := range r.ch {
 		// We are only interested in possible errors if we haven't already seen
-		// one. All other things are simply ignored.
-		if res.err != nil && r.lastErr == nil {
+		// one. All other things are simply ignored, as is the error that stops
+		// a query executed in lockstep mode.
+		if res.err != nil && r.lastErr == nil && !errors.Is(res.err, errIteratorClosed) {
 			r.lastErr = res.err
 			if r.errCallback != nil {
 				r.lastErr = r.errCallback(r.lastErr)
@@ -361,6 +400,34 @@ func (r *rowsIterator) Types() colinfo.ResultColumns {
 	return r.resultCols
 }
 
+// waitForColumns blocks until the result columns of a query executed in
+// lockstep mode have been received, returning any error encountered while
+// planning the query.
+func (r *rowsIterator) waitForColumns(ctx context.Context) error {
+	select {
+	case next, ok := <-r.ch:
+		if !ok {
+			return errors.AssertionFailedf("query finished without returning columns")
+		}
+		if next.err != nil {
+			r.lastErr = next.err
+			return next.err
+		}
+		if next.cols == nil {
+			return errors.AssertionFailedf("unexpected result before columns: %v", next)
+		}
+		r.resultCols = next.cols
+		r.blocked = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// errIteratorClosed is returned to the connExecutor goroutine running a query
+// in lockstep mode when the iterator is closed before the query has finished.
+var errIteratorClosed = errors.New("iterator closed")
+
 // Query executes the supplied SQL statement and returns the resulting rows.
 // If no user has been previously set through SetSessionData, the statement is
 // executed as the root user.
@@ -692,7 +759,16 @@ func (ie *InternalExecutor) execInternal(
 	// statement we care about before that command is sent for execution.
 	var resPos CmdPos
 
+	if ie.lockstep && parsed.AST.StatementType() != tree.Rows {
+		return nil, errors.AssertionFailedf(
+			"cannot execute %s in lockstep mode", parsed.AST.StatementTag())
+	}
+
 	ch := make(chan ieIteratorResult, ieIteratorChannelBufferSize)
+	var resume chan struct{}
+	if ie.lockstep {
+		resume = make(chan struct{}, 1)
+	}
 	syncCallback := func(results []resWithPos) {
 		// Close the stmtBuf so that the connExecutor exits its run() loop.
 		stmtBuf.Close()
@@ -718,7 +794,7 @@ func (ie *InternalExecutor) execInternal(
 		stmtBuf.Close()
 		ch <- ieIteratorResult{err: err}
 	}
-	ie.initConnEx(ctx, txn, ch, sd, stmtBuf, &wg, syncCallback, errCallback)
+	ie.initConnEx(ctx, txn, ch, resume, sd, stmtBuf, &wg, syncCallback, errCallback)
 
 	typeHints := make(tree.PlaceholderTypes, len(datums))
 	for i, d := range datums {
@@ -767,12 +843,22 @@ func (ie *InternalExecutor) execInternal(
 	if parsed.AST.StatementType() != tree.Rows {
 		resultColumns = rowsAffectedResultColumns
 	}
-	return &rowsIterator{
+	r = &rowsIterator{
 		ch:         ch,
 		resultCols: resultColumns,
+		resume:     resume,
 		stmtBuf:    stmtBuf,
 		wg:         &wg,
-	}, nil
+	}
+	if ie.lockstep {
+		// Wait until the query has been planned so that planning errors are
+		// returned right away.
+		if err := r.waitForColumns(ctx); err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
 }
 
 // internalClientComm is an implementation of ClientComm used by the
@@ -786,6 +872,10 @@ type internalClientComm struct {
 	// or ExecPortal commands) are propagated to the consumer (the iterator).
 	ch chan ieIteratorResult
 
+	// resume, if set, is used to run the query in lockstep with the consumer
+	// (see InternalExecutor.lockstep).
+	resume chan struct{}
+
 	lastDelivered CmdPos
 
 	// sync, if set, is called whenever a Sync is executed.
@@ -818,7 +908,8 @@ func (icc *internalClientComm) CreateStatementResult(
 // closed.
 func (icc *internalClientComm) createRes(pos CmdPos, onClose func()) *streamingCommandResult {
 	res := &streamingCommandResult{
-		ch: icc.ch,
+		ch:     icc.ch,
+		resume: icc.resume,
 		closeCallback: func(res *streamingCommandResult, typ resCloseType) {
 			if typ == discarded {
 				return
@@ -900,6 +991,13 @@ func (icc *internalClientComm) CreateDrainResult(pos CmdPos) DrainResult {
 	panic("unimplemented")
 }
 
+// CreateDeliverNotificationsResult is part of the ClientComm interface.
+func (icc *internalClientComm) CreateDeliverNotificationsResult(
+	pos CmdPos,
+) DeliverNotificationsResult {
+	panic("unimplemented")
+}
+
 // noopClientLock is an implementation of ClientLock that says that no results
 // have been communicated to the client.
 type noopClientLock internalClientComm
@@ -4,6 +4,7 @@ package lex
 
 var KeywordsCategories = map[string]string{
 	"abort":                        "U",
+	"absolute":                     "U",
 	"access":                       "U",
 	"action":                       "U",
 	"add":                          "U",
@@ -22,6 +23,7 @@ var KeywordsCategories = map[string]string{
 	"array":                        "R",
 	"as":                           "R",
 	"asc":                          "R",
+	"asensitive":                   "U",
 	"asymmetric":                   "R",
 	"at":                           "U",
 	"attribute":                    "U",
@@ -30,6 +32,7 @@ var KeywordsCategories = map[string]string{
 	"availability":                 "U",
 	"backup":                       "U",
 	"backups":                      "U",
+	"backward":                     "U",
 	"before":                       "U",
 	"begin":                        "U",
 	"between":                      "C",
@@ -43,6 +46,7 @@ var KeywordsCategories = map[string]string{
 	"bundle":                       "U",
 	"by":                           "U",
 	"cache":                        "U",
+	"called":                       "U",
 	"cancel":                       "U",
 	"cancelquery":                  "U",
 	"cascade":                      "U",
@@ -95,6 +99,7 @@ var KeywordsCategories = map[string]string{
 	"current_time":                 "R",
 	"current_timestamp":            "R",
 	"current_user":                 "R",
+	"cursor":                       "U",
 	"cycle":                        "U",
 	"data":                         "U",
 	"database":                     "U",
@@ -155,6 +160,7 @@ var KeywordsCategories = map[string]string{
 	"for":                          "R",
 	"force_index":                  "U",
 	"foreign":                      "R",
+	"forward":                      "U",
 	"from":                         "R",
 	"full":                         "T",
 	"function":                     "U",
@@ -180,6 +186,7 @@ var KeywordsCategories = map[string]string{
 	"having":                       "R",
 	"high":                         "U",
 	"histogram":                    "U",
+	"hold":                         "U",
 	"hour":                         "U",
 	"identity":                     "U",
 	"if":                           "C",
@@ -188,6 +195,7 @@ var KeywordsCategories = map[string]string{
 	"ignore_foreign_keys":          "U",
 	"ilike":                        "T",
 	"immediate":                    "U",
+	"immutable":                    "U",
 	"import":                       "U",
 	"in":                           "R",
 	"include":                      "U",
@@ -200,6 +208,8 @@ var KeywordsCategories = map[string]string{
 	"initially":                    "R",
 	"inject":                       "U",
 	"inner":                        "T",
+	"input":                        "U",
+	"insensitive":                  "U",
 	"insert":                       "U",
 	"int":                          "C",
 	"integer":                      "C",
@@ -228,6 +238,7 @@ var KeywordsCategories = map[string]string{
 	"lc_collate":                   "U",
 	"lc_ctype":                     "U",
 	"leading":                      "R",
+	"leakproof":                    "U",
 	"lease":                        "U",
 	"least":                        "C",
 	"left":                         "T",
@@ -237,6 +248,7 @@ var KeywordsCategories = map[string]string{
 	"limit":                        "R",
 	"linestring":                   "U",
 	"list":                         "U",
+	"listen":                       "U",
 	"local":                        "U",
 	"locality":                     "U",
 	"localtime":                    "R",
@@ -254,6 +266,7 @@ var KeywordsCategories = map[string]string{
 	"minvalue":                     "U",
 	"modifyclustersetting":         "U",
 	"month":                        "U",
+	"move":                         "U",
 	"multilinestring":              "U",
 	"multilinestringm":             "U",
 	"multilinestringz":             "U",
@@ -284,6 +297,7 @@ var KeywordsCategories = map[string]string{
 	"normal":                       "U",
 	"not":                          "R",
 	"nothing":                      "R",
+	"notify":                       "U",
 	"notnull":                      "T",
 	"noviewactivity":               "U",
 	"nowait":                       "U",
@@ -338,6 +352,7 @@ var KeywordsCategories = map[string]string{
 	"prepare":                      "U",
 	"preserve":                     "U",
 	"primary":                      "R",
+	"prior":                        "U",
 	"priority":                     "U",
 	"privileges":                   "U",
 	"public":                       "U",
@@ -358,6 +373,7 @@ var KeywordsCategories = map[string]string{
 	"regional":                     "U",
 	"regions":                      "U",
 	"reindex":                      "U",
+	"relative":                     "U",
 	"release":                      "U",
 	"rename":                       "U",
 	"repeatable":                   "U",
@@ -369,6 +385,7 @@ var KeywordsCategories = map[string]string{
 	"resume":                       "U",
 	"retry":                        "U",
 	"returning":                    "R",
+	"returns":                      "U",
 	"revision_history":             "U",
 	"revoke":                       "U",
 	"right":                        "T",
@@ -386,6 +403,7 @@ var KeywordsCategories = map[string]string{
 	"schedules":                    "U",
 	"schema":                       "U",
 	"schemas":                      "U",
+	"scroll":                       "U",
 	"scrub":                        "U",
 	"search":                       "U",
 	"second":                       "U",
@@ -415,6 +433,7 @@ var KeywordsCategories = map[string]string{
 	"some":                         "R",
 	"split":                        "U",
 	"sql":                          "U",
+	"stable":                       "U",
 	"start":                        "U",
 	"statements":                   "U",
 	"statistics":                   "U",
@@ -468,6 +487,7 @@ var KeywordsCategories = map[string]string{
 	"union":                        "R",
 	"unique":                       "R",
 	"unknown":                      "U",
+	"unlisten":                     "U",
 	"unlogged":                     "U",
 	"unsplit":                      "U",
 	"until":                        "U",
@@ -489,6 +509,7 @@ var KeywordsCategories = map[string]string{
 	"viewactivity":                 "U",
 	"virtual":                      "C",
 	"visible":                      "R",
+	"volatile":                     "U",
 	"when":                         "R",
 	"where":                        "R",
 	"window":                       "R",
@@ -505,6 +526,7 @@ var KeywordsCategories = map[string]string{
 // deterministic results.
 var KeywordNames = []string{
 	"abort",
+	"absolute",
 	"access",
 	"action",
 	"add",
@@ -523,6 +545,7 @@ var KeywordNames = []string{
 	"array",
 	"as",
 	"asc",
+	"asensitive",
 	"asymmetric",
 	"at",
 	"attribute",
@@ -531,6 +554,7 @@ var KeywordNames = []string{
 	"availability",
 	"backup",
 	"backups",
+	"backward",
 	"before",
 	"begin",
 	"between",
@@ -544,6 +568,7 @@ var KeywordNames = []string{
 	"bundle",
 	"by",
 	"cache",
+	"called",
 	"cancel",
 	"cancelquery",
 	"cascade",
@@ -596,6 +621,7 @@ var KeywordNames = []string{
 	"current_time",
 	"current_timestamp",
 	"current_user",
+	"cursor",
 	"cycle",
 	"data",
 	"database",
@@ -656,6 +682,7 @@ var KeywordNames = []string{
 	"for",
 	"force_index",
 	"foreign",
+	"forward",
 	"from",
 	"full",
 	"function",
@@ -681,6 +708,7 @@ var KeywordNames = []string{
 	"having",
 	"high",
 	"histogram",
+	"hold",
 	"hour",
 	"identity",
 	"if",
@@ -689,6 +717,7 @@ var KeywordNames = []string{
 	"ignore_foreign_keys",
 	"ilike",
 	"immediate",
+	"immutable",
 	"import",
 	"in",
 	"include",
@@ -701,6 +730,8 @@ var KeywordNames = []string{
 	"initially",
 	"inject",
 	"inner",
+	"input",
+	"insensitive",
 	"insert",
 	"int",
 	"integer",
@@ -729,6 +760,7 @@ var KeywordNames = []string{
 	"lc_collate",
 	"lc_ctype",
 	"leading",
+	"leakproof",
 	"lease",
 	"least",
 	"left",
@@ -738,6 +770,7 @@ var KeywordNames = []string{
 	"limit",
 	"linestring",
 	"list",
+	"listen",
 	"local",
 	"locality",
 	"localtime",
@@ -755,6 +788,7 @@ var KeywordNames = []string{
 	"minvalue",
 	"modifyclustersetting",
 	"month",
+	"move",
 	"multilinestring",
 	"multilinestringm",
 	"multilinestringz",
@@ -785,6 +819,7 @@ var KeywordNames = []string{
 	"normal",
 	"not",
 	"nothing",
+	"notify",
 	"notnull",
 	"noviewactivity",
 	"nowait",
@@ -839,6 +874,7 @@ var KeywordNames = []string{
 	"prepare",
 	"preserve",
 	"primary",
+	"prior",
 	"priority",
 	"privileges",
 	"public",
@@ -859,6 +895,7 @@ var KeywordNames = []string{
 	"regional",
 	"regions",
 	"reindex",
+	"relative",
 	"release",
 	"rename",
 	"repeatable",
@@ -870,6 +907,7 @@ var KeywordNames = []string{
 	"resume",
 	"retry",
 	"returning",
+	"returns",
 	"revision_history",
 	"revoke",
 	"right",
@@ -887,6 +925,7 @@ var KeywordNames = []string{
 	"schedules",
 	"schema",
 	"schemas",
+	"scroll",
 	"scrub",
 	"search",
 	"second",
@@ -916,6 +955,7 @@ var KeywordNames = []string{
 	"some",
 	"split",
 	"sql",
+	"stable",
 	"start",
 	"statements",
 	"statistics",
@@ -969,6 +1009,7 @@ var KeywordNames = []string{
 	"union",
 	"unique",
 	"unknown",
+	"unlisten",
 	"unlogged",
 	"unsplit",
 	"until",
@@ -990,6 +1031,7 @@ var KeywordNames = []string{
 	"viewactivity",
 	"virtual",
 	"visible",
+	"volatile",
 	"when",
 	"where",
 	"window",
@@ -1012,6 +1054,8 @@ func GetKeywordID(k string) int32 {
 	switch k {
 	case "abort":
 		return ABORT
+	case "absolute":
+		return ABSOLUTE
 	case "access":
 		return ACCESS
 	case "action":
@@ -1048,6 +1092,8 @@ func GetKeywordID(k string) int32 {
 		return AS
 	case "asc":
 		return ASC
+	case "asensitive":
+		return ASENSITIVE
 	case "asymmetric":
 		return ASYMMETRIC
 	case "at":
@@ -1064,6 +1110,8 @@ func GetKeywordID(k string) int32 {
 		return BACKUP
 	case "backups":
 		return BACKUPS
+	case "backward":
+		return BACKWARD
 	case "before":
 		return BEFORE
 	case "begin":
@@ -1090,6 +1138,8 @@ func GetKeywordID(k string) int32 {
 		return BY
 	case "cache":
 		return CACHE
+	case "called":
+		return CALLED
 	case "cancel":
 		return CANCEL
 	case "cancelquery":
@@ -1194,6 +1244,8 @@ func GetKeywordID(k string) int32 {
 		return CURRENT_TIMESTAMP
 	case "current_user":
 		return CURRENT_USER
+	case "cursor":
+		return CURSOR
 	case "cycle":
 		return CYCLE
 	case "data":
@@ -1314,6 +1366,8 @@ func GetKeywordID(k string) int32 {
 		return FORCE_INDEX
 	case "foreign":
 		return FOREIGN
+	case "forward":
+		return FORWARD
 	case "from":
 		return FROM
 	case "full":
@@ -1364,6 +1418,8 @@ func GetKeywordID(k string) int32 {
 		return HIGH
 	case "histogram":
 		return HISTOGRAM
+	case "hold":
+		return HOLD
 	case "hour":
 		return HOUR
 	case "identity":
@@ -1380,6 +1436,8 @@ func GetKeywordID(k string) int32 {
 		return ILIKE
 	case "immediate":
 		return IMMEDIATE
+	case "immutable":
+		return IMMUTABLE
 	case "import":
 		return IMPORT
 	case "in":
@@ -1404,6 +1462,10 @@ func GetKeywordID(k string) int32 {
 		return INJECT
 	case "inner":
 		return INNER
+	case "input":
+		return INPUT
+	case "insensitive":
+		return INSENSITIVE
 	case "insert":
 		return INSERT
 	case "int":
@@ -1460,6 +1522,8 @@ func GetKeywordID(k string) int32 {
 		return LC_CTYPE
 	case "leading":
 		return LEADING
+	case "leakproof":
+		return LEAKPROOF
 	case "lease":
 		return LEASE
 	case "least":
@@ -1478,6 +1542,8 @@ func GetKeywordID(k string) int32 {
 		return LINESTRING
 	case "list":
 		return LIST
+	case "listen":
+		return LISTEN
 	case "local":
 		return LOCAL
 	case "locality":
@@ -1512,6 +1578,8 @@ func GetKeywordID(k string) int32 {
 		return MODIFYCLUSTERSETTING
 	case "month":
 		return MONTH
+	case "move":
+		return MOVE
 	case "multilinestring":
 		return MULTILINESTRING
 	case "multilinestringm":
@@ -1572,6 +1640,8 @@ func GetKeywordID(k string) int32 {
 		return NOT
 	case "nothing":
 		return NOTHING
+	case "notify":
+		return NOTIFY
 	case "notnull":
 		return NOTNULL
 	case "noviewactivity":
@@ -1680,6 +1750,8 @@ func GetKeywordID(k string) int32 {
 		return PRESERVE
 	case "primary":
 		return PRIMARY
+	case "prior":
+		return PRIOR
 	case "priority":
 		return PRIORITY
 	case "privileges":
@@ -1720,6 +1792,8 @@ func GetKeywordID(k string) int32 {
 		return REGIONS
 	case "reindex":
 		return REINDEX
+	case "relative":
+		return RELATIVE
 	case "release":
 		return RELEASE
 	case "rename":
@@ -1742,6 +1816,8 @@ func GetKeywordID(k string) int32 {
 		return RETRY
 	case "returning":
 		return RETURNING
+	case "returns":
+		return RETURNS
 	case "revision_history":
 		return REVISION_HISTORY
 	case "revoke":
@@ -1776,6 +1852,8 @@ func GetKeywordID(k string) int32 {
 		return SCHEMA
 	case "schemas":
 		return SCHEMAS
+	case "scroll":
+		return SCROLL
 	case "scrub":
 		return SCRUB
 	case "search":
@@ -1834,6 +1912,8 @@ func GetKeywordID(k string) int32 {
 		return SPLIT
 	case "sql":
 		return SQL
+	case "stable":
+		return STABLE
 	case "start":
 		return START
 	case "statements":
@@ -1940,6 +2020,8 @@ func GetKeywordID(k string) int32 {
 		return UNIQUE
 	case "unknown":
 		return UNKNOWN
+	case "unlisten":
+		return UNLISTEN
 	case "unlogged":
 		return UNLOGGED
 	case "unsplit":
@@ -1982,6 +2064,8 @@ func GetKeywordID(k string) int32 {
 		return VIRTUAL
 	case "visible":
 		return VISIBLE
+	case "volatile":
+		return VOLATILE
 	case "when":
 		return WHEN
 	case "where":
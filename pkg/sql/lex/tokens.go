@@ -21,545 +21,567 @@ const REGIMATCH = 57360
 const NOT_REGIMATCH = 57361
 const ERROR = 57362
 const ABORT = 57363
-const ACCESS = 57364
-const ACTION = 57365
-const ADD = 57366
-const ADMIN = 57367
-const AFFINITY = 57368
-const AFTER = 57369
-const AGGREGATE = 57370
-const ALL = 57371
-const ALTER = 57372
-const ALWAYS = 57373
-const ANALYSE = 57374
-const ANALYZE = 57375
-const AND = 57376
-const AND_AND = 57377
-const ANY = 57378
-const ANNOTATE_TYPE = 57379
-const ARRAY = 57380
-const AS = 57381
-const ASC = 57382
-const ASYMMETRIC = 57383
-const AT = 57384
-const ATTRIBUTE = 57385
-const AUTHORIZATION = 57386
-const AUTOMATIC = 57387
-const AVAILABILITY = 57388
-const BACKUP = 57389
-const BACKUPS = 57390
-const BEFORE = 57391
-const BEGIN = 57392
-const BETWEEN = 57393
-const BIGINT = 57394
-const BIGSERIAL = 57395
-const BINARY = 57396
-const BIT = 57397
-const BUCKET_COUNT = 57398
-const BOOLEAN = 57399
-const BOTH = 57400
-const BOX2D = 57401
-const BUNDLE = 57402
-const BY = 57403
-const CACHE = 57404
-const CANCEL = 57405
-const CANCELQUERY = 57406
-const CASCADE = 57407
-const CASE = 57408
-const CAST = 57409
-const CBRT = 57410
-const CHANGEFEED = 57411
-const CHAR = 57412
-const CHARACTER = 57413
-const CHARACTERISTICS = 57414
-const CHECK = 57415
-const CLOSE = 57416
-const CLUSTER = 57417
-const COALESCE = 57418
-const COLLATE = 57419
-const COLLATION = 57420
-const COLUMN = 57421
-const COLUMNS = 57422
-const COMMENT = 57423
-const COMMENTS = 57424
-const COMMIT = 57425
-const COMMITTED = 57426
-const COMPACT = 57427
-const COMPLETE = 57428
-const CONCAT = 57429
-const CONCURRENTLY = 57430
-const CONFIGURATION = 57431
-const CONFIGURATIONS = 57432
-const CONFIGURE = 57433
-const CONFLICT = 57434
-const CONNECTION = 57435
-const CONSTRAINT = 57436
-const CONSTRAINTS = 57437
-const CONTAINS = 57438
-const CONTROLCHANGEFEED = 57439
-const CONTROLJOB = 57440
-const CONVERSION = 57441
-const CONVERT = 57442
-const COPY = 57443
-const COVERING = 57444
-const CREATE = 57445
-const CREATEDB = 57446
-const CREATELOGIN = 57447
-const CREATEROLE = 57448
-const CROSS = 57449
-const CSV = 57450
-const CUBE = 57451
-const CURRENT = 57452
-const CURRENT_CATALOG = 57453
-const CURRENT_DATE = 57454
-const CURRENT_SCHEMA = 57455
-const CURRENT_ROLE = 57456
-const CURRENT_TIME = 57457
-const CURRENT_TIMESTAMP = 57458
-const CURRENT_USER = 57459
-const CYCLE = 57460
-const DATA = 57461
-const DATABASE = 57462
-const DATABASES = 57463
-const DATE = 57464
-const DAY = 57465
-const DEC = 57466
-const DECIMAL = 57467
-const DEFAULT = 57468
-const DEFAULTS = 57469
-const DEALLOCATE = 57470
-const DECLARE = 57471
-const DEFERRABLE = 57472
-const DEFERRED = 57473
-const DELETE = 57474
-const DELIMITER = 57475
-const DESC = 57476
-const DESTINATION = 57477
-const DETACHED = 57478
-const DISCARD = 57479
-const DISTINCT = 57480
-const DO = 57481
-const DOMAIN = 57482
-const DOUBLE = 57483
-const DROP = 57484
-const ELSE = 57485
-const ENCODING = 57486
-const ENCRYPTION_PASSPHRASE = 57487
-const END = 57488
-const ENUM = 57489
-const ENUMS = 57490
-const ESCAPE = 57491
-const EXCEPT = 57492
-const EXCLUDE = 57493
-const EXCLUDING = 57494
-const EXISTS = 57495
-const EXECUTE = 57496
-const EXECUTION = 57497
-const EXPERIMENTAL = 57498
-const EXPERIMENTAL_FINGERPRINTS = 57499
-const EXPERIMENTAL_REPLICA = 57500
-const EXPERIMENTAL_AUDIT = 57501
-const EXPIRATION = 57502
-const EXPLAIN = 57503
-const EXPORT = 57504
-const EXTENSION = 57505
-const EXTRACT = 57506
-const EXTRACT_DURATION = 57507
-const FAILURE = 57508
-const FALSE = 57509
-const FAMILY = 57510
-const FETCH = 57511
-const FETCHVAL = 57512
-const FETCHTEXT = 57513
-const FETCHVAL_PATH = 57514
-const FETCHTEXT_PATH = 57515
-const FILES = 57516
-const FILTER = 57517
-const FIRST = 57518
-const FLOAT = 57519
-const FLOAT4 = 57520
-const FLOAT8 = 57521
-const FLOORDIV = 57522
-const FOLLOWING = 57523
-const FOR = 57524
-const FORCE_INDEX = 57525
-const FOREIGN = 57526
-const FROM = 57527
-const FULL = 57528
-const FUNCTION = 57529
-const GENERATED = 57530
-const GEOGRAPHY = 57531
-const GEOMETRY = 57532
-const GEOMETRYM = 57533
-const GEOMETRYZ = 57534
-const GEOMETRYZM = 57535
-const GEOMETRYCOLLECTION = 57536
-const GEOMETRYCOLLECTIONM = 57537
-const GEOMETRYCOLLECTIONZ = 57538
-const GEOMETRYCOLLECTIONZM = 57539
-const GLOBAL = 57540
-const GOAL = 57541
-const GRANT = 57542
-const GRANTS = 57543
-const GREATEST = 57544
-const GROUP = 57545
-const GROUPING = 57546
-const GROUPS = 57547
-const HAVING = 57548
-const HASH = 57549
-const HIGH = 57550
-const HISTOGRAM = 57551
-const HOUR = 57552
-const IDENTITY = 57553
-const IF = 57554
-const IFERROR = 57555
-const IFNULL = 57556
-const IGNORE_FOREIGN_KEYS = 57557
-const ILIKE = 57558
-const IMMEDIATE = 57559
-const IMPORT = 57560
-const IN = 57561
-const INCLUDE = 57562
-const INCLUDING = 57563
-const INCREMENT = 57564
-const INCREMENTAL = 57565
-const INET = 57566
-const INET_CONTAINED_BY_OR_EQUALS = 57567
-const INET_CONTAINS_OR_EQUALS = 57568
-const INDEX = 57569
-const INDEXES = 57570
-const INHERITS = 57571
-const INJECT = 57572
-const INTERLEAVE = 57573
-const INITIALLY = 57574
-const INNER = 57575
-const INSERT = 57576
-const INT = 57577
-const INTEGER = 57578
-const INTERSECT = 57579
-const INTERVAL = 57580
-const INTO = 57581
-const INTO_DB = 57582
-const INVERTED = 57583
-const IS = 57584
-const ISERROR = 57585
-const ISNULL = 57586
-const ISOLATION = 57587
-const JOB = 57588
-const JOBS = 57589
-const JOIN = 57590
-const JSON = 57591
-const JSONB = 57592
-const JSON_SOME_EXISTS = 57593
-const JSON_ALL_EXISTS = 57594
-const KEY = 57595
-const KEYS = 57596
-const KMS = 57597
-const KV = 57598
-const LANGUAGE = 57599
-const LAST = 57600
-const LATERAL = 57601
-const LATEST = 57602
-const LC_CTYPE = 57603
-const LC_COLLATE = 57604
-const LEADING = 57605
-const LEASE = 57606
-const LEAST = 57607
-const LEFT = 57608
-const LESS = 57609
-const LEVEL = 57610
-const LIKE = 57611
-const LIMIT = 57612
-const LINESTRING = 57613
-const LINESTRINGM = 57614
-const LINESTRINGZ = 57615
-const LINESTRINGZM = 57616
-const LIST = 57617
-const LOCAL = 57618
-const LOCALITY = 57619
-const LOCALTIME = 57620
-const LOCALTIMESTAMP = 57621
-const LOCKED = 57622
-const LOGIN = 57623
-const LOOKUP = 57624
-const LOW = 57625
-const LSHIFT = 57626
-const MATCH = 57627
-const MATERIALIZED = 57628
-const MERGE = 57629
-const MINVALUE = 57630
-const MAXVALUE = 57631
-const METHOD = 57632
-const MINUTE = 57633
-const MODIFYCLUSTERSETTING = 57634
-const MONTH = 57635
-const MULTILINESTRING = 57636
-const MULTILINESTRINGM = 57637
-const MULTILINESTRINGZ = 57638
-const MULTILINESTRINGZM = 57639
-const MULTIPOINT = 57640
-const MULTIPOINTM = 57641
-const MULTIPOINTZ = 57642
-const MULTIPOINTZM = 57643
-const MULTIPOLYGON = 57644
-const MULTIPOLYGONM = 57645
-const MULTIPOLYGONZ = 57646
-const MULTIPOLYGONZM = 57647
-const NAN = 57648
-const NAME = 57649
-const NAMES = 57650
-const NATURAL = 57651
-const NEVER = 57652
-const NEXT = 57653
-const NO = 57654
-const NOCANCELQUERY = 57655
-const NOCONTROLCHANGEFEED = 57656
-const NOCONTROLJOB = 57657
-const NOCREATEDB = 57658
-const NOCREATELOGIN = 57659
-const NOCREATEROLE = 57660
-const NOLOGIN = 57661
-const NOMODIFYCLUSTERSETTING = 57662
-const NO_INDEX_JOIN = 57663
-const NONE = 57664
-const NORMAL = 57665
-const NOT = 57666
-const NOTHING = 57667
-const NOTNULL = 57668
-const NOVIEWACTIVITY = 57669
-const NOWAIT = 57670
-const NULL = 57671
-const NULLIF = 57672
-const NULLS = 57673
-const NUMERIC = 57674
-const OF = 57675
-const OFF = 57676
-const OFFSET = 57677
-const OID = 57678
-const OIDS = 57679
-const OIDVECTOR = 57680
-const ON = 57681
-const ONLY = 57682
-const OPT = 57683
-const OPTION = 57684
-const OPTIONS = 57685
-const OR = 57686
-const ORDER = 57687
-const ORDINALITY = 57688
-const OTHERS = 57689
-const OUT = 57690
-const OUTER = 57691
-const OVER = 57692
-const OVERLAPS = 57693
-const OVERLAY = 57694
-const OWNED = 57695
-const OWNER = 57696
-const OPERATOR = 57697
-const PARENT = 57698
-const PARTIAL = 57699
-const PARTITION = 57700
-const PARTITIONS = 57701
-const PASSWORD = 57702
-const PAUSE = 57703
-const PAUSED = 57704
-const PHYSICAL = 57705
-const PLACING = 57706
-const PLAN = 57707
-const PLANS = 57708
-const POINT = 57709
-const POINTM = 57710
-const POINTZ = 57711
-const POINTZM = 57712
-const POLYGON = 57713
-const POLYGONM = 57714
-const POLYGONZ = 57715
-const POLYGONZM = 57716
-const POSITION = 57717
-const PRECEDING = 57718
-const PRECISION = 57719
-const PREPARE = 57720
-const PRESERVE = 57721
-const PRIMARY = 57722
-const PRIORITY = 57723
-const PRIVILEGES = 57724
-const PROCEDURAL = 57725
-const PUBLIC = 57726
-const PUBLICATION = 57727
-const QUERIES = 57728
-const QUERY = 57729
-const RANGE = 57730
-const RANGES = 57731
-const READ = 57732
-const REAL = 57733
-const REASSIGN = 57734
-const RECURSIVE = 57735
-const RECURRING = 57736
-const REF = 57737
-const REFERENCES = 57738
-const REFRESH = 57739
-const REGCLASS = 57740
-const REGION = 57741
-const REGIONAL = 57742
-const REGIONS = 57743
-const REGPROC = 57744
-const REGPROCEDURE = 57745
-const REGNAMESPACE = 57746
-const REGTYPE = 57747
-const REINDEX = 57748
-const REMOVE_PATH = 57749
-const RENAME = 57750
-const REPEATABLE = 57751
-const REPLACE = 57752
-const REPLICATION = 57753
-const RELEASE = 57754
-const RESET = 57755
-const RESTORE = 57756
-const RESTRICT = 57757
-const RESUME = 57758
-const RETURNING = 57759
-const RETRY = 57760
-const REVISION_HISTORY = 57761
-const REVOKE = 57762
-const RIGHT = 57763
-const ROLE = 57764
-const ROLES = 57765
-const ROLLBACK = 57766
-const ROLLUP = 57767
-const ROW = 57768
-const ROWS = 57769
-const RSHIFT = 57770
-const RULE = 57771
-const RUNNING = 57772
-const SAVEPOINT = 57773
-const SCATTER = 57774
-const SCHEDULE = 57775
-const SCHEDULES = 57776
-const SCHEMA = 57777
-const SCHEMAS = 57778
-const SCRUB = 57779
-const SEARCH = 57780
-const SECOND = 57781
-const SELECT = 57782
-const SEQUENCE = 57783
-const SEQUENCES = 57784
-const SERIALIZABLE = 57785
-const SERVER = 57786
-const SESSION = 57787
-const SESSIONS = 57788
-const SESSION_USER = 57789
-const SET = 57790
-const SETS = 57791
-const SETTING = 57792
-const SETTINGS = 57793
-const SHARE = 57794
-const SHOW = 57795
-const SIMILAR = 57796
-const SIMPLE = 57797
-const SKIP = 57798
-const SKIP_MISSING_FOREIGN_KEYS = 57799
-const SKIP_MISSING_SEQUENCES = 57800
-const SKIP_MISSING_SEQUENCE_OWNERS = 57801
-const SKIP_MISSING_VIEWS = 57802
-const SMALLINT = 57803
-const SMALLSERIAL = 57804
-const SNAPSHOT = 57805
-const SOME = 57806
-const SPLIT = 57807
-const SQL = 57808
-const START = 57809
-const STATISTICS = 57810
-const STATUS = 57811
-const STDIN = 57812
-const STRICT = 57813
-const STRING = 57814
-const STORAGE = 57815
-const STORE = 57816
-const STORED = 57817
-const STORING = 57818
-const STREAM = 57819
-const SUBSTRING = 57820
-const SURVIVE = 57821
-const SURVIVAL = 57822
-const SYMMETRIC = 57823
-const SYNTAX = 57824
-const SYSTEM = 57825
-const SQRT = 57826
-const SUBSCRIPTION = 57827
-const STATEMENTS = 57828
-const TABLE = 57829
-const TABLES = 57830
-const TABLESPACE = 57831
-const TEMP = 57832
-const TEMPLATE = 57833
-const TEMPORARY = 57834
-const TENANT = 57835
-const TESTING_RELOCATE = 57836
-const EXPERIMENTAL_RELOCATE = 57837
-const TEXT = 57838
-const THEN = 57839
-const TIES = 57840
-const TIME = 57841
-const TIMETZ = 57842
-const TIMESTAMP = 57843
-const TIMESTAMPTZ = 57844
-const TO = 57845
-const THROTTLING = 57846
-const TRAILING = 57847
-const TRACE = 57848
-const TRANSACTION = 57849
-const TRANSACTIONS = 57850
-const TREAT = 57851
-const TRIGGER = 57852
-const TRIM = 57853
-const TRUE = 57854
-const TRUNCATE = 57855
-const TRUSTED = 57856
-const TYPE = 57857
-const TYPES = 57858
-const TRACING = 57859
-const UNBOUNDED = 57860
-const UNCOMMITTED = 57861
-const UNION = 57862
-const UNIQUE = 57863
-const UNKNOWN = 57864
-const UNLOGGED = 57865
-const UNSPLIT = 57866
-const UPDATE = 57867
-const UPSERT = 57868
-const UNTIL = 57869
-const USE = 57870
-const USER = 57871
-const USERS = 57872
-const USING = 57873
-const UUID = 57874
-const VALID = 57875
-const VALIDATE = 57876
-const VALUE = 57877
-const VALUES = 57878
-const VARBIT = 57879
-const VARCHAR = 57880
-const VARIADIC = 57881
-const VIEW = 57882
-const VARYING = 57883
-const VIEWACTIVITY = 57884
-const VIRTUAL = 57885
-const VISIBLE = 57886
-const WHEN = 57887
-const WHERE = 57888
-const WINDOW = 57889
-const WITH = 57890
-const WITHIN = 57891
-const WITHOUT = 57892
-const WORK = 57893
-const WRITE = 57894
-const YEAR = 57895
-const ZONE = 57896
-const NOT_LA = 57897
-const NULLS_LA = 57898
-const WITH_LA = 57899
-const AS_LA = 57900
-const GENERATED_ALWAYS = 57901
-const CONTAINED_BY = 57902
-const POSTFIXOP = 57903
-const UMINUS = 57904
-const HELPTOKEN = 57905
+const ABSOLUTE = 57364
+const ACCESS = 57365
+const ACTION = 57366
+const ADD = 57367
+const ADMIN = 57368
+const AFFINITY = 57369
+const AFTER = 57370
+const AGGREGATE = 57371
+const ALL = 57372
+const ALTER = 57373
+const ALWAYS = 57374
+const ANALYSE = 57375
+const ANALYZE = 57376
+const AND = 57377
+const AND_AND = 57378
+const ANY = 57379
+const ANNOTATE_TYPE = 57380
+const ARRAY = 57381
+const AS = 57382
+const ASC = 57383
+const ASENSITIVE = 57384
+const ASYMMETRIC = 57385
+const AT = 57386
+const AT_AT = 57387
+const ATTRIBUTE = 57388
+const AUTHORIZATION = 57389
+const AUTOMATIC = 57390
+const AVAILABILITY = 57391
+const BACKUP = 57392
+const BACKUPS = 57393
+const BACKWARD = 57394
+const BEFORE = 57395
+const BEGIN = 57396
+const BETWEEN = 57397
+const BIGINT = 57398
+const BIGSERIAL = 57399
+const BINARY = 57400
+const BIT = 57401
+const BUCKET_COUNT = 57402
+const BOOLEAN = 57403
+const BOTH = 57404
+const BOX2D = 57405
+const BUNDLE = 57406
+const BY = 57407
+const CACHE = 57408
+const CALLED = 57409
+const CANCEL = 57410
+const CANCELQUERY = 57411
+const CASCADE = 57412
+const CASE = 57413
+const CAST = 57414
+const CBRT = 57415
+const CHANGEFEED = 57416
+const CHAR = 57417
+const CHARACTER = 57418
+const CHARACTERISTICS = 57419
+const CHECK = 57420
+const CLOSE = 57421
+const CLUSTER = 57422
+const COALESCE = 57423
+const COLLATE = 57424
+const COLLATION = 57425
+const COLUMN = 57426
+const COLUMNS = 57427
+const COMMENT = 57428
+const COMMENTS = 57429
+const COMMIT = 57430
+const COMMITTED = 57431
+const COMPACT = 57432
+const COMPLETE = 57433
+const CONCAT = 57434
+const CONCURRENTLY = 57435
+const CONFIGURATION = 57436
+const CONFIGURATIONS = 57437
+const CONFIGURE = 57438
+const CONFLICT = 57439
+const CONNECTION = 57440
+const CONSTRAINT = 57441
+const CONSTRAINTS = 57442
+const CONTAINS = 57443
+const CONTROLCHANGEFEED = 57444
+const CONTROLJOB = 57445
+const CONVERSION = 57446
+const CONVERT = 57447
+const COPY = 57448
+const COVERING = 57449
+const CREATE = 57450
+const CREATEDB = 57451
+const CREATELOGIN = 57452
+const CREATEROLE = 57453
+const CROSS = 57454
+const CSV = 57455
+const CUBE = 57456
+const CURRENT = 57457
+const CURRENT_CATALOG = 57458
+const CURRENT_DATE = 57459
+const CURRENT_SCHEMA = 57460
+const CURRENT_ROLE = 57461
+const CURRENT_TIME = 57462
+const CURRENT_TIMESTAMP = 57463
+const CURRENT_USER = 57464
+const CURSOR = 57465
+const CYCLE = 57466
+const DATA = 57467
+const DATABASE = 57468
+const DATABASES = 57469
+const DATE = 57470
+const DAY = 57471
+const DEC = 57472
+const DECIMAL = 57473
+const DEFAULT = 57474
+const DEFAULTS = 57475
+const DEALLOCATE = 57476
+const DECLARE = 57477
+const DEFERRABLE = 57478
+const DEFERRED = 57479
+const DELETE = 57480
+const DELIMITER = 57481
+const DESC = 57482
+const DESTINATION = 57483
+const DETACHED = 57484
+const DISCARD = 57485
+const DISTINCT = 57486
+const DO = 57487
+const DOMAIN = 57488
+const DOUBLE = 57489
+const DROP = 57490
+const ELSE = 57491
+const ENCODING = 57492
+const ENCRYPTION_PASSPHRASE = 57493
+const END = 57494
+const ENUM = 57495
+const ENUMS = 57496
+const ESCAPE = 57497
+const EXCEPT = 57498
+const EXCLUDE = 57499
+const EXCLUDING = 57500
+const EXISTS = 57501
+const EXECUTE = 57502
+const EXECUTION = 57503
+const EXPERIMENTAL = 57504
+const EXPERIMENTAL_FINGERPRINTS = 57505
+const EXPERIMENTAL_REPLICA = 57506
+const EXPERIMENTAL_AUDIT = 57507
+const EXPIRATION = 57508
+const EXPLAIN = 57509
+const EXPORT = 57510
+const EXTENSION = 57511
+const EXTRACT = 57512
+const EXTRACT_DURATION = 57513
+const FAILURE = 57514
+const FALSE = 57515
+const FAMILY = 57516
+const FETCH = 57517
+const FETCHVAL = 57518
+const FETCHTEXT = 57519
+const FETCHVAL_PATH = 57520
+const FETCHTEXT_PATH = 57521
+const FILES = 57522
+const FILTER = 57523
+const FIRST = 57524
+const FLOAT = 57525
+const FLOAT4 = 57526
+const FLOAT8 = 57527
+const FLOORDIV = 57528
+const FOLLOWING = 57529
+const FOR = 57530
+const FORCE_INDEX = 57531
+const FOREIGN = 57532
+const FORWARD = 57533
+const FROM = 57534
+const FULL = 57535
+const FUNCTION = 57536
+const GENERATED = 57537
+const GEOGRAPHY = 57538
+const GEOMETRY = 57539
+const GEOMETRYM = 57540
+const GEOMETRYZ = 57541
+const GEOMETRYZM = 57542
+const GEOMETRYCOLLECTION = 57543
+const GEOMETRYCOLLECTIONM = 57544
+const GEOMETRYCOLLECTIONZ = 57545
+const GEOMETRYCOLLECTIONZM = 57546
+const GLOBAL = 57547
+const GOAL = 57548
+const GRANT = 57549
+const GRANTS = 57550
+const GREATEST = 57551
+const GROUP = 57552
+const GROUPING = 57553
+const GROUPS = 57554
+const HAVING = 57555
+const HASH = 57556
+const HIGH = 57557
+const HISTOGRAM = 57558
+const HOLD = 57559
+const HOUR = 57560
+const IDENTITY = 57561
+const IF = 57562
+const IFERROR = 57563
+const IFNULL = 57564
+const IGNORE_FOREIGN_KEYS = 57565
+const ILIKE = 57566
+const IMMEDIATE = 57567
+const IMMUTABLE = 57568
+const IMPORT = 57569
+const IN = 57570
+const INCLUDE = 57571
+const INCLUDING = 57572
+const INCREMENT = 57573
+const INCREMENTAL = 57574
+const INET = 57575
+const INET_CONTAINED_BY_OR_EQUALS = 57576
+const INET_CONTAINS_OR_EQUALS = 57577
+const INDEX = 57578
+const INDEXES = 57579
+const INHERITS = 57580
+const INJECT = 57581
+const INTERLEAVE = 57582
+const INITIALLY = 57583
+const INNER = 57584
+const INPUT = 57585
+const INSENSITIVE = 57586
+const INSERT = 57587
+const INT = 57588
+const INTEGER = 57589
+const INTERSECT = 57590
+const INTERVAL = 57591
+const INTO = 57592
+const INTO_DB = 57593
+const INVERTED = 57594
+const IS = 57595
+const ISERROR = 57596
+const ISNULL = 57597
+const ISOLATION = 57598
+const JOB = 57599
+const JOBS = 57600
+const JOIN = 57601
+const JSON = 57602
+const JSONB = 57603
+const JSON_SOME_EXISTS = 57604
+const JSON_ALL_EXISTS = 57605
+const KEY = 57606
+const KEYS = 57607
+const KMS = 57608
+const KV = 57609
+const LANGUAGE = 57610
+const LAST = 57611
+const LATERAL = 57612
+const LATEST = 57613
+const LC_CTYPE = 57614
+const LC_COLLATE = 57615
+const LEADING = 57616
+const LEAKPROOF = 57617
+const LEASE = 57618
+const LEAST = 57619
+const LEFT = 57620
+const LESS = 57621
+const LEVEL = 57622
+const LIKE = 57623
+const LIMIT = 57624
+const LINESTRING = 57625
+const LINESTRINGM = 57626
+const LINESTRINGZ = 57627
+const LINESTRINGZM = 57628
+const LIST = 57629
+const LISTEN = 57630
+const LOCAL = 57631
+const LOCALITY = 57632
+const LOCALTIME = 57633
+const LOCALTIMESTAMP = 57634
+const LOCKED = 57635
+const LOGIN = 57636
+const LOOKUP = 57637
+const LOW = 57638
+const LSHIFT = 57639
+const MATCH = 57640
+const MATERIALIZED = 57641
+const MERGE = 57642
+const MINVALUE = 57643
+const MAXVALUE = 57644
+const METHOD = 57645
+const MINUTE = 57646
+const MODIFYCLUSTERSETTING = 57647
+const MONTH = 57648
+const MOVE = 57649
+const MULTILINESTRING = 57650
+const MULTILINESTRINGM = 57651
+const MULTILINESTRINGZ = 57652
+const MULTILINESTRINGZM = 57653
+const MULTIPOINT = 57654
+const MULTIPOINTM = 57655
+const MULTIPOINTZ = 57656
+const MULTIPOINTZM = 57657
+const MULTIPOLYGON = 57658
+const MULTIPOLYGONM = 57659
+const MULTIPOLYGONZ = 57660
+const MULTIPOLYGONZM = 57661
+const NAN = 57662
+const NAME = 57663
+const NAMES = 57664
+const NATURAL = 57665
+const NEVER = 57666
+const NEXT = 57667
+const NO = 57668
+const NOCANCELQUERY = 57669
+const NOCONTROLCHANGEFEED = 57670
+const NOCONTROLJOB = 57671
+const NOCREATEDB = 57672
+const NOCREATELOGIN = 57673
+const NOCREATEROLE = 57674
+const NOLOGIN = 57675
+const NOMODIFYCLUSTERSETTING = 57676
+const NO_INDEX_JOIN = 57677
+const NONE = 57678
+const NORMAL = 57679
+const NOT = 57680
+const NOTHING = 57681
+const NOTIFY = 57682
+const NOTNULL = 57683
+const NOVIEWACTIVITY = 57684
+const NOWAIT = 57685
+const NULL = 57686
+const NULLIF = 57687
+const NULLS = 57688
+const NUMERIC = 57689
+const OF = 57690
+const OFF = 57691
+const OFFSET = 57692
+const OID = 57693
+const OIDS = 57694
+const OIDVECTOR = 57695
+const ON = 57696
+const ONLY = 57697
+const OPT = 57698
+const OPTION = 57699
+const OPTIONS = 57700
+const OR = 57701
+const ORDER = 57702
+const ORDINALITY = 57703
+const OTHERS = 57704
+const OUT = 57705
+const OUTER = 57706
+const OVER = 57707
+const OVERLAPS = 57708
+const OVERLAY = 57709
+const OWNED = 57710
+const OWNER = 57711
+const OPERATOR = 57712
+const PARENT = 57713
+const PARTIAL = 57714
+const PARTITION = 57715
+const PARTITIONS = 57716
+const PASSWORD = 57717
+const PAUSE = 57718
+const PAUSED = 57719
+const PHYSICAL = 57720
+const PLACING = 57721
+const PLAN = 57722
+const PLANS = 57723
+const POINT = 57724
+const POINTM = 57725
+const POINTZ = 57726
+const POINTZM = 57727
+const POLYGON = 57728
+const POLYGONM = 57729
+const POLYGONZ = 57730
+const POLYGONZM = 57731
+const POSITION = 57732
+const PRECEDING = 57733
+const PRECISION = 57734
+const PREPARE = 57735
+const PRESERVE = 57736
+const PRIMARY = 57737
+const PRIOR = 57738
+const PRIORITY = 57739
+const PRIVILEGES = 57740
+const PROCEDURAL = 57741
+const PUBLIC = 57742
+const PUBLICATION = 57743
+const QUERIES = 57744
+const QUERY = 57745
+const RANGE = 57746
+const RANGES = 57747
+const READ = 57748
+const REAL = 57749
+const REASSIGN = 57750
+const RECURSIVE = 57751
+const RECURRING = 57752
+const REF = 57753
+const REFERENCES = 57754
+const REFRESH = 57755
+const RELATIVE = 57756
+const REGCLASS = 57757
+const REGION = 57758
+const REGIONAL = 57759
+const REGIONS = 57760
+const REGPROC = 57761
+const REGPROCEDURE = 57762
+const REGNAMESPACE = 57763
+const REGTYPE = 57764
+const REINDEX = 57765
+const REMOVE_PATH = 57766
+const RENAME = 57767
+const REPEATABLE = 57768
+const REPLACE = 57769
+const REPLICATION = 57770
+const RELEASE = 57771
+const RESET = 57772
+const RESTORE = 57773
+const RESTRICT = 57774
+const RESUME = 57775
+const RETURNING = 57776
+const RETURNS = 57777
+const RETRY = 57778
+const REVISION_HISTORY = 57779
+const REVOKE = 57780
+const RIGHT = 57781
+const ROLE = 57782
+const ROLES = 57783
+const ROLLBACK = 57784
+const ROLLUP = 57785
+const ROW = 57786
+const ROWS = 57787
+const RSHIFT = 57788
+const RULE = 57789
+const RUNNING = 57790
+const SAVEPOINT = 57791
+const SCATTER = 57792
+const SCHEDULE = 57793
+const SCHEDULES = 57794
+const SCHEMA = 57795
+const SCHEMAS = 57796
+const SCROLL = 57797
+const SCRUB = 57798
+const SEARCH = 57799
+const SECOND = 57800
+const SELECT = 57801
+const SEQUENCE = 57802
+const SEQUENCES = 57803
+const SERIALIZABLE = 57804
+const SERVER = 57805
+const SESSION = 57806
+const SESSIONS = 57807
+const SESSION_USER = 57808
+const SET = 57809
+const SETS = 57810
+const SETTING = 57811
+const SETTINGS = 57812
+const SHARE = 57813
+const SHOW = 57814
+const SIMILAR = 57815
+const SIMPLE = 57816
+const SKIP = 57817
+const SKIP_MISSING_FOREIGN_KEYS = 57818
+const SKIP_MISSING_SEQUENCES = 57819
+const SKIP_MISSING_SEQUENCE_OWNERS = 57820
+const SKIP_MISSING_VIEWS = 57821
+const SMALLINT = 57822
+const SMALLSERIAL = 57823
+const SNAPSHOT = 57824
+const SOME = 57825
+const SPLIT = 57826
+const SQL = 57827
+const STABLE = 57828
+const START = 57829
+const STATISTICS = 57830
+const STATUS = 57831
+const STDIN = 57832
+const STRICT = 57833
+const STRING = 57834
+const STORAGE = 57835
+const STORE = 57836
+const STORED = 57837
+const STORING = 57838
+const STREAM = 57839
+const SUBSTRING = 57840
+const SURVIVE = 57841
+const SURVIVAL = 57842
+const SYMMETRIC = 57843
+const SYNTAX = 57844
+const SYSTEM = 57845
+const SQRT = 57846
+const SUBSCRIPTION = 57847
+const STATEMENTS = 57848
+const TABLE = 57849
+const TABLES = 57850
+const TABLESPACE = 57851
+const TEMP = 57852
+const TEMPLATE = 57853
+const TEMPORARY = 57854
+const TENANT = 57855
+const TESTING_RELOCATE = 57856
+const EXPERIMENTAL_RELOCATE = 57857
+const TEXT = 57858
+const THEN = 57859
+const TIES = 57860
+const TIME = 57861
+const TIMETZ = 57862
+const TIMESTAMP = 57863
+const TIMESTAMPTZ = 57864
+const TO = 57865
+const THROTTLING = 57866
+const TRAILING = 57867
+const TRACE = 57868
+const TRANSACTION = 57869
+const TRANSACTIONS = 57870
+const TREAT = 57871
+const TRIGGER = 57872
+const TRIM = 57873
+const TRUE = 57874
+const TRUNCATE = 57875
+const TRUSTED = 57876
+const TYPE = 57877
+const TYPES = 57878
+const TRACING = 57879
+const UNBOUNDED = 57880
+const UNCOMMITTED = 57881
+const UNION = 57882
+const UNIQUE = 57883
+const UNKNOWN = 57884
+const UNLISTEN = 57885
+const UNLOGGED = 57886
+const UNSPLIT = 57887
+const UPDATE = 57888
+const UPSERT = 57889
+const UNTIL = 57890
+const USE = 57891
+const USER = 57892
+const USERS = 57893
+const USING = 57894
+const UUID = 57895
+const VALID = 57896
+const VALIDATE = 57897
+const VALUE = 57898
+const VALUES = 57899
+const VARBIT = 57900
+const VARCHAR = 57901
+const VARIADIC = 57902
+const VIEW = 57903
+const VARYING = 57904
+const VIEWACTIVITY = 57905
+const VIRTUAL = 57906
+const VISIBLE = 57907
+const VOLATILE = 57908
+const WHEN = 57909
+const WHERE = 57910
+const WINDOW = 57911
+const WITH = 57912
+const WITHIN = 57913
+const WITHOUT = 57914
+const WORK = 57915
+const WRITE = 57916
+const YEAR = 57917
+const ZONE = 57918
+const NOT_LA = 57919
+const NULLS_LA = 57920
+const WITH_LA = 57921
+const AS_LA = 57922
+const GENERATED_ALWAYS = 57923
+const CONTAINED_BY = 57924
+const POSTFIXOP = 57925
+const UMINUS = 57926
+const HELPTOKEN = 57927
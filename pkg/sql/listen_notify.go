@@ -0,0 +1,221 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxPendingNotifications is the maximum number of notifications a session
+// holds while waiting for them to be delivered to the client, for example
+// because it is in a long-running transaction. Further notifications are
+// dropped.
+const maxPendingNotifications = 10000
+
+var droppedNotificationsLogEvery = log.Every(10 * time.Second)
+
+// listenAction is a LISTEN or UNLISTEN statement. It takes effect when the
+// transaction that ran it commits.
+type listenAction struct {
+	channel  string
+	unlisten bool
+	// all is set for UNLISTEN *.
+	all bool
+}
+
+// listenActionsCollection accumulates the LISTEN and UNLISTEN statements run
+// by a transaction.
+type listenActionsCollection []listenAction
+
+// sessionNotifications holds the LISTEN and NOTIFY state of a session.
+type sessionNotifications struct {
+	// listener is created when the session first commits a LISTEN statement.
+	listener *notify.Listener
+
+	mu struct {
+		syncutil.Mutex
+		// pending are the notifications received by the listener that haven't
+		// been delivered to the client yet.
+		pending []notify.Notification
+		// wakeupPushed is set when a DeliverNotifications command has been pushed
+		// to the session's stmtBuf and hasn't been executed yet.
+		wakeupPushed bool
+	}
+}
+
+// listening returns true if the session listens on at least one channel.
+func (sn *sessionNotifications) listening() bool {
+	return sn.listener != nil && sn.listener.Listening()
+}
+
+func (p *planner) checkListenNotifyVersion(ctx context.Context, stmt tree.Statement) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ListenNotify) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for %s", stmt.StatementTag())
+	}
+	return nil
+}
+
+type listenNode struct {
+	n *tree.Listen
+}
+
+// Listen registers the session as a listener on a notification channel.
+// See https://www.postgresql.org/docs/current/sql-listen.html for details.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	if err := p.checkListenNotifyVersion(ctx, n); err != nil {
+		return nil, err
+	}
+	return &listenNode{n: n}, nil
+}
+
+func (n *listenNode) startExec(params runParams) error {
+	*params.extendedEvalCtx.ListenActions = append(*params.extendedEvalCtx.ListenActions,
+		listenAction{channel: string(n.n.ChannelName)})
+	return nil
+}
+
+func (*listenNode) Next(runParams) (bool, error) { return false, nil }
+func (*listenNode) Values() tree.Datums          { return tree.Datums{} }
+func (*listenNode) Close(context.Context)        {}
+
+type unlistenNode struct {
+	n *tree.Unlisten
+}
+
+// Unlisten removes the session from the listeners of a notification channel.
+// See https://www.postgresql.org/docs/current/sql-unlisten.html for details.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	if err := p.checkListenNotifyVersion(ctx, n); err != nil {
+		return nil, err
+	}
+	return &unlistenNode{n: n}, nil
+}
+
+func (n *unlistenNode) startExec(params runParams) error {
+	*params.extendedEvalCtx.ListenActions = append(*params.extendedEvalCtx.ListenActions,
+		listenAction{channel: string(n.n.ChannelName), unlisten: true, all: n.n.All})
+	return nil
+}
+
+func (*unlistenNode) Next(runParams) (bool, error) { return false, nil }
+func (*unlistenNode) Values() tree.Datums          { return tree.Datums{} }
+func (*unlistenNode) Close(context.Context)        {}
+
+type notifyNode struct {
+	n *tree.Notify
+}
+
+// Notify generates a notification, which is delivered to the listening
+// sessions when the current transaction commits.
+// See https://www.postgresql.org/docs/current/sql-notify.html for details.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	if err := p.checkListenNotifyVersion(ctx, n); err != nil {
+		return nil, err
+	}
+	return &notifyNode{n: n}, nil
+}
+
+func (n *notifyNode) startExec(params runParams) error {
+	return params.ExecCfg().NotificationRegistry.Notify(
+		params.ctx, params.p.txn, string(n.n.ChannelName), n.n.Payload,
+	)
+}
+
+func (*notifyNode) Next(runParams) (bool, error) { return false, nil }
+func (*notifyNode) Values() tree.Datums          { return tree.Datums{} }
+func (*notifyNode) Close(context.Context)        {}
+
+func (*notifyNode) ReadingOwnWrites() {}
+
+// applyListenActions applies the LISTEN and UNLISTEN statements run by a
+// transaction that just committed.
+func (ex *connExecutor) applyListenActions(actions listenActionsCollection) {
+	// Internal executors have no client to deliver notifications to.
+	if len(actions) == 0 || ex.executorType == executorTypeInternal {
+		return
+	}
+	sn := &ex.notifications
+	if sn.listener == nil {
+		sn.listener = ex.server.cfg.NotificationRegistry.NewListener(ex.receiveNotification)
+	}
+	for _, a := range actions {
+		switch {
+		case a.all:
+			sn.listener.UnlistenAll()
+		case a.unlisten:
+			sn.listener.Unlisten(a.channel)
+		default:
+			sn.listener.Listen(a.channel)
+		}
+	}
+}
+
+// receiveNotification is called by the session's listener for every
+// notification on one of the channels the session listens on. The notification
+// is queued and, if needed, a DeliverNotifications command is pushed to wake up
+// the session.
+func (ex *connExecutor) receiveNotification(n notify.Notification) {
+	sn := &ex.notifications
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if len(sn.mu.pending) >= maxPendingNotifications {
+		if droppedNotificationsLogEvery.ShouldLog() {
+			log.Warningf(ex.ctxHolder.connCtx,
+				"too many notifications waiting to be delivered; dropping notification on channel %q",
+				n.Channel)
+		}
+		return
+	}
+	sn.mu.pending = append(sn.mu.pending, n)
+	if !sn.mu.wakeupPushed {
+		sn.mu.wakeupPushed = true
+		// Push only fails if the stmtBuf was closed, in which case the session is
+		// going away anyway.
+		_ = ex.stmtBuf.Push(ex.ctxHolder.connCtx, DeliverNotifications{})
+	}
+}
+
+// bufferPendingNotifications buffers the notifications waiting to be delivered
+// on res, unless the session is in a transaction, in which case they are held
+// until the transaction finishes.
+//
+// It must be called by the commands that can consume the wakeup pushed by
+// receiveNotification, i.e. DeliverNotifications and Sync (as the
+// DeliverNotifications command can be skipped when seeking to the next batch
+// after an error).
+func (ex *connExecutor) bufferPendingNotifications(res NotificationBuffer) {
+	sn := &ex.notifications
+	if sn.listener == nil {
+		return
+	}
+	var pending []notify.Notification
+	func() {
+		sn.mu.Lock()
+		defer sn.mu.Unlock()
+		sn.mu.wakeupPushed = false
+		if ex.idleConn() {
+			pending, sn.mu.pending = sn.mu.pending, nil
+		}
+	}()
+	for _, n := range pending {
+		res.BufferNotification(n)
+	}
+}
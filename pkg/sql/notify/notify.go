@@ -0,0 +1,417 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package notify implements the cluster-wide delivery of the notifications
+// generated by NOTIFY statements.
+//
+// NOTIFY writes a row into system.notifications as part of the transaction
+// that issued it, so notifications become visible exactly when (and if) that
+// transaction commits. Every SQL instance on which at least one session runs
+// LISTEN watches the table with a rangefeed and hands the new rows to the
+// sessions listening on the corresponding channel. Old rows are periodically
+// garbage collected.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// MaxPayloadLength is the maximum length, in bytes, of a notification
+// payload. It matches the limit imposed by PostgreSQL.
+const MaxPayloadLength = 8000
+
+var notificationTTL = settings.RegisterDurationSetting(
+	"sql.notifications.ttl",
+	"amount of time for which a notification is retained in system.notifications "+
+		"before being garbage collected",
+	time.Hour,
+	settings.NonNegativeDuration,
+)
+
+// gcInterval is the interval at which system.notifications is garbage
+// collected.
+const gcInterval = 10 * time.Minute
+
+// Notification is a notification generated by a NOTIFY statement.
+type Notification struct {
+	Channel string
+	Payload string
+	// SenderID is the ID of the SQL instance on which the notification was
+	// generated. It is reported to clients in place of the process ID of the
+	// notifying backend.
+	SenderID int32
+}
+
+// Registry keeps track of the sessions listening for notifications on the
+// local SQL instance and delivers the notifications generated anywhere in the
+// cluster to them.
+type Registry struct {
+	codec      keys.SQLCodec
+	db         *kv.DB
+	ie         sqlutil.InternalExecutor
+	st         *cluster.Settings
+	instanceID *base.SQLIDContainer
+
+	// firstListener is closed when the first listener registers, which starts
+	// the rangefeed on system.notifications. Instances that never run LISTEN
+	// don't pay for the rangefeed.
+	firstListener chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		// listeners maps each channel to the listeners subscribed to it.
+		listeners map[string]map[*Listener]struct{}
+		// watchStart is the timestamp from which the rangefeed is started. It is
+		// set when firstListener is closed.
+		watchStart hlc.Timestamp
+	}
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(
+	codec keys.SQLCodec,
+	db *kv.DB,
+	ie sqlutil.InternalExecutor,
+	st *cluster.Settings,
+	instanceID *base.SQLIDContainer,
+) *Registry {
+	r := &Registry{
+		codec:         codec,
+		db:            db,
+		ie:            ie,
+		st:            st,
+		instanceID:    instanceID,
+		firstListener: make(chan struct{}),
+	}
+	r.mu.listeners = make(map[string]map[*Listener]struct{})
+	return r
+}
+
+// Start starts the garbage collection of old notifications. The rangefeed
+// watching for new notifications is started once the first listener
+// registers.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down so let's swallow it.
+	_ = stopper.RunAsyncTask(ctx, "notifications-gc", r.gcLoop)
+	_ = stopper.RunAsyncTask(ctx, "notifications-watcher", func(ctx context.Context) {
+		select {
+		case <-r.firstListener:
+			r.mu.Lock()
+			start := r.mu.watchStart
+			r.mu.Unlock()
+			r.watch(ctx, stopper, start)
+		case <-ctx.Done():
+		}
+	})
+}
+
+// Notify records a notification on the given channel as part of txn. The
+// notification is delivered to the listening sessions once txn commits.
+func (r *Registry) Notify(ctx context.Context, txn *kv.Txn, channel, payload string) error {
+	if len(payload) > MaxPayloadLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "payload string too long")
+	}
+	_, err := r.ie.ExecEx(
+		ctx, "notify", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`INSERT INTO system.notifications (channel, payload, sender_id) VALUES ($1, $2, $3)`,
+		channel, payload, int64(r.instanceID.SQLInstanceID()),
+	)
+	return err
+}
+
+// Listener is the set of channels a session listens on.
+type Listener struct {
+	r *Registry
+	// deliver is called for every notification on one of the listener's
+	// channels. It is called with the registry's lock held, so it must not
+	// block nor call back into the registry.
+	deliver func(Notification)
+	// channels is protected by r.mu.
+	channels map[string]struct{}
+}
+
+// NewListener creates a Listener, initially not listening on any channel,
+// which passes the notifications it receives to deliver. deliver must not
+// block.
+func (r *Registry) NewListener(deliver func(Notification)) *Listener {
+	return &Listener{
+		r:        r,
+		deliver:  deliver,
+		channels: make(map[string]struct{}),
+	}
+}
+
+// Listen subscribes the listener to the given channel. It is a no-op if the
+// listener is already listening on the channel.
+func (l *Listener) Listen(channel string) {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l.channels[channel] = struct{}{}
+	ls, ok := r.mu.listeners[channel]
+	if !ok {
+		ls = make(map[*Listener]struct{})
+		r.mu.listeners[channel] = ls
+	}
+	ls[l] = struct{}{}
+	if r.mu.watchStart.IsEmpty() {
+		// The rangefeed starts asynchronously, possibly after notifications have
+		// been committed by transactions that started after the LISTEN statement
+		// returned. The catch-up scan picks them up as long as the rangefeed starts
+		// below their timestamp, which can be lower than the local clock by up to
+		// the maximum clock offset.
+		r.mu.watchStart = r.db.Clock().Now().Add(-r.db.Clock().MaxOffset().Nanoseconds(), 0)
+		close(r.firstListener)
+	}
+}
+
+// Unlisten unsubscribes the listener from the given channel. It is a no-op if
+// the listener isn't listening on the channel.
+func (l *Listener) Unlisten(channel string) {
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	l.unlistenLocked(channel)
+}
+
+// Listening returns true if the listener is subscribed to at least one
+// channel.
+func (l *Listener) Listening() bool {
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	return len(l.channels) > 0
+}
+
+// UnlistenAll unsubscribes the listener from all of its channels.
+func (l *Listener) UnlistenAll() {
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	for channel := range l.channels {
+		l.unlistenLocked(channel)
+	}
+}
+
+func (l *Listener) unlistenLocked(channel string) {
+	delete(l.channels, channel)
+	ls := l.r.mu.listeners[channel]
+	delete(ls, l)
+	if len(ls) == 0 {
+		delete(l.r.mu.listeners, channel)
+	}
+}
+
+// deliver hands n to all the listeners subscribed to its channel.
+func (r *Registry) deliver(n Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for l := range r.mu.listeners[n.Channel] {
+		l.deliver(n)
+	}
+}
+
+// watch runs a rangefeed on system.notifications starting at the given
+// timestamp and delivers the notifications it observes until ctx is canceled.
+func (r *Registry) watch(ctx context.Context, stopper *stop.Stopper, start hlc.Timestamp) {
+	distSender := r.db.NonTransactionalSender().(*kv.CrossRangeTxnWrapperSender).Wrapped().(*kvcoord.DistSender)
+	tablePrefix := r.codec.TablePrefix(uint32(systemschema.NotificationsTable.GetID()))
+	tableSpan := roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}
+	eventCh := make(chan *roachpb.RangeFeedEvent)
+
+	// The rangefeed is restarted from the resolved timestamp of the table
+	// after a failure. The events above that timestamp may be seen twice, so
+	// the keys of the rows delivered above it are remembered in seen.
+	var resolved struct {
+		syncutil.Mutex
+		frontier *span.Frontier
+	}
+	resolved.frontier = span.MakeFrontier(tableSpan)
+	resolved.frontier.Forward(tableSpan, start)
+	seen := make(map[string]hlc.Timestamp)
+
+	if err := stopper.RunAsyncTask(ctx, "notifications-rangefeed", func(ctx context.Context) {
+		// Run the rangefeed in a loop in the case of failure, likely due to node
+		// failures or general unavailability.
+		restartLogEvery := log.Every(10 * time.Second)
+		for i, retrier := 1, retry.StartWithCtx(ctx, retry.Options{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     2 * time.Second,
+			Closer:         stopper.ShouldQuiesce(),
+		}); retrier.Next(); i++ {
+			resolved.Lock()
+			ts := resolved.frontier.Frontier()
+			resolved.Unlock()
+			log.VEventf(ctx, 1, "starting notifications rangefeed from %v", ts)
+			const withDiff = false
+			err := distSender.RangeFeed(ctx, tableSpan, ts, withDiff, eventCh)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && restartLogEvery.ShouldLog() {
+				log.Warningf(ctx, "notifications rangefeed failed %d times, restarting: %v",
+					log.Safe(i), err)
+			}
+		}
+	}); err != nil {
+		// This will only fail if the stopper has been stopped.
+		return
+	}
+
+	a := &rowenc.DatumAlloc{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-eventCh:
+			switch {
+			case e.Checkpoint != nil:
+				resolved.Lock()
+				advanced := resolved.frontier.Forward(e.Checkpoint.Span, e.Checkpoint.ResolvedTS)
+				frontier := resolved.frontier.Frontier()
+				resolved.Unlock()
+				if advanced {
+					for k, ts := range seen {
+						if ts.LessEq(frontier) {
+							delete(seen, k)
+						}
+					}
+				}
+			case e.Error != nil:
+				log.Warningf(ctx, "got an error from the notifications rangefeed: %v", e.Error.Error)
+			case e.Val != nil:
+				// Deletions, which are issued by the garbage collection of old
+				// notifications, have an empty value.
+				if len(e.Val.Value.RawBytes) == 0 {
+					continue
+				}
+				if _, ok := seen[string(e.Val.Key)]; ok {
+					continue
+				}
+				seen[string(e.Val.Key)] = e.Val.Value.Timestamp
+				n, err := decodeNotification(a, e.Val.Value)
+				if err != nil {
+					log.Warningf(ctx, "unable to decode notification %s: %v", e.Val.Key, err)
+					continue
+				}
+				r.deliver(n)
+			}
+		}
+	}
+}
+
+// decodeNotification decodes the non-primary key columns of a row of
+// system.notifications.
+func decodeNotification(a *rowenc.DatumAlloc, value roachpb.Value) (Notification, error) {
+	tbl := systemschema.NotificationsTable
+	colIdxMap := catalog.ColumnIDToOrdinalMap(tbl.PublicColumns())
+	var n Notification
+	bytes, err := value.GetTuple()
+	if err != nil {
+		return n, err
+	}
+	var colIDDiff uint32
+	var lastColID descpb.ColumnID
+	var res tree.Datum
+	for len(bytes) > 0 {
+		_, _, colIDDiff, _, err = encoding.DecodeValueTag(bytes)
+		if err != nil {
+			return n, err
+		}
+		colID := lastColID + descpb.ColumnID(colIDDiff)
+		lastColID = colID
+		idx, ok := colIdxMap.Get(colID)
+		if !ok {
+			return n, errors.Errorf("unknown column: %v", colID)
+		}
+		res, bytes, err = rowenc.DecodeTableValue(a, tbl.PublicColumns()[idx].GetType(), bytes)
+		if err != nil {
+			return n, err
+		}
+		switch tbl.PublicColumns()[idx].GetName() {
+		case "channel":
+			n.Channel = string(tree.MustBeDString(res))
+		case "payload":
+			n.Payload = string(tree.MustBeDString(res))
+		case "sender_id":
+			n.SenderID = int32(tree.MustBeDInt(res))
+		}
+	}
+	return n, nil
+}
+
+// gcLoop periodically deletes the notifications older than
+// sql.notifications.ttl.
+func (r *Registry) gcLoop(ctx context.Context) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		timer.Reset(gcInterval)
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		if err := r.deleteExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Warningf(ctx, "error deleting expired notifications: %v", err)
+		}
+	}
+}
+
+// deleteExpired deletes the notifications older than sql.notifications.ttl, in
+// batches so as to avoid creating large transactions.
+func (r *Registry) deleteExpired(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.ListenNotify) {
+		return nil
+	}
+	const batchSize = 1000
+	cutoff := timeutil.Now().Add(-notificationTTL.Get(&r.st.SV))
+	for {
+		n, err := r.ie.ExecEx(
+			ctx, "delete-expired-notifications", nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`DELETE FROM system.notifications WHERE created < $1 LIMIT $2`,
+			cutoff, batchSize,
+		)
+		if err != nil || n < batchSize {
+			return err
+		}
+	}
+}
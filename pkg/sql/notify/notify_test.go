@@ -0,0 +1,87 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package notify_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListenNotify checks that notifications generated on one node are
+// delivered to the sessions listening on another node once the notifying
+// transaction commits.
+func TestListenNotify(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := serverutils.StartNewTestCluster(t, 2, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, tc.Server(0).ServingSQLAddr(), "TestListenNotify", url.User(security.RootUser))
+	defer cleanup()
+	conn, err := pgx.Connect(ctx, pgURL.String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close(ctx) }()
+
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(1))
+
+	waitForNotification := func(expChannel, expPayload string) {
+		t.Helper()
+		waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		defer cancel()
+		n, err := conn.WaitForNotification(waitCtx)
+		require.NoError(t, err)
+		require.Equal(t, expChannel, n.Channel)
+		require.Equal(t, expPayload, n.Payload)
+		require.Equal(t, uint32(tc.Server(1).NodeID()), n.PID)
+	}
+
+	_, err = conn.Exec(ctx, "LISTEN foo")
+	require.NoError(t, err)
+
+	sqlDB.Exec(t, "NOTIFY foo, 'hello'")
+	waitForNotification("foo", "hello")
+
+	// Notifications are only delivered once the transaction commits, and
+	// not at all if it rolls back.
+	sqlDB.Exec(t, "BEGIN; NOTIFY foo, 'rolled back'; ROLLBACK")
+	sqlDB.Exec(t, "NOTIFY bar, 'other channel'")
+	sqlDB.Exec(t, "BEGIN; NOTIFY foo, 'committed'; COMMIT")
+	waitForNotification("foo", "committed")
+
+	// A LISTEN statement takes effect when its transaction commits.
+	_, err = conn.Exec(ctx, "BEGIN; LISTEN bar; ROLLBACK")
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "UNLISTEN foo; LISTEN baz")
+	require.NoError(t, err)
+	sqlDB.Exec(t, "NOTIFY foo, 'unlistened'")
+	sqlDB.Exec(t, "NOTIFY bar, 'not listened'")
+	sqlDB.Exec(t, "NOTIFY baz")
+	waitForNotification("baz", "")
+
+	sqlDB.ExpectErr(t, "payload string too long",
+		"NOTIFY foo, '"+strings.Repeat("a", notify.MaxPayloadLength+1)+"'")
+}
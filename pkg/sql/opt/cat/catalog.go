@@ -125,6 +125,14 @@ type Catalog interface {
 		ctx context.Context, name *tree.UnresolvedObjectName,
 	) (*types.T, error)
 
+	// ResolveFunction is used to resolve the name of a user defined function,
+	// using the current search path. It returns nil if the name does not refer
+	// to a user defined function, and an error if the current user is not
+	// allowed to execute the function.
+	ResolveFunction(
+		ctx context.Context, name *tree.UnresolvedName,
+	) (*tree.FunctionDefinition, error)
+
 	// CheckPrivilege verifies that the current user has the given privilege on
 	// the given catalog object. If not, then CheckPrivilege returns an error.
 	CheckPrivilege(ctx context.Context, o Object, priv privilege.Kind) error
@@ -244,6 +244,10 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+	// Deferrability returns whether the checks for this constraint can be
+	// deferred until the end of the transaction, and whether they are deferred
+	// by default.
+	Deferrability() tree.ConstraintDeferrability
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -282,6 +286,11 @@ type UniqueConstraint interface {
 	// cannot make any assumptions about the data. An unvalidated constraint still
 	// needs to be enforced on new mutations.
 	Validated() bool
+
+	// Deferrability returns whether the checks for this constraint can be
+	// deferred until the end of the transaction, and whether they are deferred
+	// by default. Only unique constraints without an index can be deferrable.
+	Deferrability() tree.ConstraintDeferrability
 }
 
 // UniqueOrdinal identifies a unique constraint (in the context of a Table).
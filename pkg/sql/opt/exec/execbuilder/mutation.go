@@ -170,6 +170,11 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			return execPlan{}, false, nil
 		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
+		if fk.Deferrability() != tree.ConstraintNotDeferrable {
+			// The check of a deferrable FK might have to be delayed until the
+			// end of the transaction.
+			return execPlan{}, false, nil
+		}
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
 			// Not a lookup anti-join.
@@ -801,7 +806,7 @@ func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkUniqueCheckErr(md, c, keyVals)
+			return maybeMkDeferrableUniqueCheckErr(md, c, keyVals, mkUniqueCheckErr(md, c, keyVals))
 		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
@@ -827,7 +832,7 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkFKCheckErr(md, c, keyVals)
+			return maybeMkDeferrableFKCheckErr(md, c, keyVals, mkFKCheckErr(md, c, keyVals))
 		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
@@ -838,6 +843,30 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	return nil
 }
 
+// BuildDeferredCheck builds the plan of the check of a deferred FK or unique
+// constraint (see optbuilder.BuildDeferredFKCheck and
+// optbuilder.BuildDeferredUniqueCheck). Like the checks built by
+// buildFKChecks and buildUniqueChecks, the check query is wrapped in an
+// ErrorIfRows operator; the error is made by mkErr, which is passed the values
+// of the given key columns in the first row returned by the check.
+func (b *Builder) BuildDeferredCheck(keyCols opt.ColList, mkErr exec.MkErrFn) (exec.Plan, error) {
+	query, err := b.build(b.e)
+	if err != nil {
+		return nil, err
+	}
+	node, err := b.factory.ConstructErrorIfRows(query.root, func(row tree.Datums) error {
+		keyVals := make(tree.Datums, len(keyCols))
+		for i, col := range keyCols {
+			keyVals[i] = row[query.getNodeColumnOrdinal(col)]
+		}
+		return mkErr(keyVals)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.factory.ConstructPlan(node, b.subqueries, b.cascades, b.checks)
+}
+
 // mkUniqueCheckErr generates a user-friendly error describing a uniqueness
 // violation. The keyVals are the values that correspond to the
 // cat.UniqueConstraint columns.
@@ -973,6 +1002,63 @@ func mkFKCheckErr(md *opt.Metadata, c *memo.FKChecksItem, keyVals tree.Datums) e
 	)
 }
 
+// maybeMkDeferrableUniqueCheckErr wraps the given uniqueness violation error in
+// an exec.DeferrableCheckViolation if the unique constraint is deferrable.
+func maybeMkDeferrableUniqueCheckErr(
+	md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.Datums, err error,
+) error {
+	uc := md.TableMeta(c.Table).Table.Unique(c.CheckOrdinal)
+	if uc.Deferrability() == tree.ConstraintNotDeferrable {
+		return err
+	}
+	return &exec.DeferrableCheckViolation{
+		Err:            err,
+		ConstraintName: uc.Name(),
+		Deferrability:  uc.Deferrability(),
+		Table:          uc.TableID(),
+		KeyVals:        keyVals,
+	}
+}
+
+// maybeMkDeferrableFKCheckErr wraps the given foreign key violation error in
+// an exec.DeferrableCheckViolation if the foreign key constraint is
+// deferrable. As in Postgres, checks for RESTRICT actions are never deferred.
+func maybeMkDeferrableFKCheckErr(
+	md *opt.Metadata, c *memo.FKChecksItem, keyVals tree.Datums, err error,
+) error {
+	var fk cat.ForeignKeyConstraint
+	if c.FKOutbound {
+		fk = md.TableMeta(c.OriginTable).Table.OutboundForeignKey(c.FKOrdinal)
+	} else {
+		fk = md.TableMeta(c.ReferencedTable).Table.InboundForeignKey(c.FKOrdinal)
+		action := fk.UpdateReferenceAction()
+		if c.OpName == "delete" {
+			action = fk.DeleteReferenceAction()
+		}
+		if action == tree.Restrict {
+			return err
+		}
+	}
+	if fk.Deferrability() == tree.ConstraintNotDeferrable {
+		return err
+	}
+	for _, d := range keyVals {
+		if d == tree.DNull {
+			// A MATCH FULL violation can only be fixed by modifying the violating
+			// row, so there is no point in deferring it.
+			return err
+		}
+	}
+	return &exec.DeferrableCheckViolation{
+		Err:            err,
+		ConstraintName: fk.Name(),
+		Deferrability:  fk.Deferrability(),
+		ForeignKey:     true,
+		Table:          fk.OriginTableID(),
+		KeyVals:        keyVals,
+	}
+}
+
 func (b *Builder) buildFKCascades(withID opt.WithID, cascades memo.FKCascades) error {
 	if len(cascades) == 0 {
 		return nil
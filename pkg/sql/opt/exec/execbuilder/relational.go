@@ -201,6 +201,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.GroupByExpr, *memo.ScalarGroupByExpr:
 		ep, err = b.buildGroupBy(e)
 
+	case *memo.GroupingSetsExpr:
+		ep, err = b.buildGroupingSets(t)
+
 	case *memo.DistinctOnExpr, *memo.EnsureDistinctOnExpr, *memo.UpsertDistinctOnExpr,
 		*memo.EnsureUpsertDistinctOnExpr:
 		ep, err = b.buildDistinct(t)
@@ -1100,6 +1103,37 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 	}
 
 	aggregations := *groupBy.Child(1).(*memo.AggregationsExpr)
+	aggInfos, err := b.buildAggregations(aggregations, &input)
+	if err != nil {
+		return execPlan{}, err
+	}
+	for i := range aggregations {
+		ep.outputCols.Set(int(aggregations[i].Col), len(groupingColIdx)+i)
+	}
+
+	if groupBy.Op() == opt.ScalarGroupByOp {
+		ep.root, err = b.factory.ConstructScalarGroupBy(input.root, aggInfos)
+	} else {
+		groupBy := groupBy.(*memo.GroupByExpr)
+		groupingColOrder := input.sqlOrdering(ordering.StreamingGroupingColOrdering(
+			&groupBy.GroupingPrivate, &groupBy.RequiredPhysical().Ordering,
+		))
+		reqOrdering := ep.reqOrdering(groupBy)
+		ep.root, err = b.factory.ConstructGroupBy(
+			input.root, groupingColIdx, groupingColOrder, aggInfos, reqOrdering,
+		)
+	}
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
+// buildAggregations builds the exec.AggInfo for each of the aggregations of a
+// grouping operator, given its input plan.
+func (b *Builder) buildAggregations(
+	aggregations memo.AggregationsExpr, input *execPlan,
+) ([]exec.AggInfo, error) {
 	aggInfos := make([]exec.AggInfo, len(aggregations))
 	for i := range aggregations {
 		item := &aggregations[i]
@@ -1109,7 +1143,7 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 		if aggFilter, ok := agg.(*memo.AggFilterExpr); ok {
 			filter, ok := aggFilter.Filter.(*memo.VariableExpr)
 			if !ok {
-				return execPlan{}, errors.AssertionFailedf("only VariableOp args supported")
+				return nil, errors.AssertionFailedf("only VariableOp args supported")
 			}
 			filterOrd = input.getNodeColumnOrdinal(filter.Col)
 			agg = aggFilter.Input
@@ -1131,12 +1165,12 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 			child := agg.Child(j)
 			if variable, ok := child.(*memo.VariableExpr); ok {
 				if len(constArgs) != 0 {
-					return execPlan{}, errors.Errorf("constant args must come after variable args")
+					return nil, errors.Errorf("constant args must come after variable args")
 				}
 				argCols = append(argCols, input.getNodeColumnOrdinal(variable.Col))
 			} else {
 				if len(argCols) == 0 {
-					return execPlan{}, errors.Errorf("a constant arg requires at least one variable arg")
+					return nil, errors.Errorf("a constant arg requires at least one variable arg")
 				}
 				constArgs = append(constArgs, memo.ExtractConstDatum(child))
 			}
@@ -1150,21 +1184,40 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 			ConstArgs:  constArgs,
 			Filter:     filterOrd,
 		}
-		ep.outputCols.Set(int(item.Col), len(groupingColIdx)+i)
 	}
+	return aggInfos, nil
+}
 
-	if groupBy.Op() == opt.ScalarGroupByOp {
-		ep.root, err = b.factory.ConstructScalarGroupBy(input.root, aggInfos)
-	} else {
-		groupBy := groupBy.(*memo.GroupByExpr)
-		groupingColOrder := input.sqlOrdering(ordering.StreamingGroupingColOrdering(
-			&groupBy.GroupingPrivate, &groupBy.RequiredPhysical().Ordering,
-		))
-		reqOrdering := ep.reqOrdering(groupBy)
-		ep.root, err = b.factory.ConstructGroupBy(
-			input.root, groupingColIdx, groupingColOrder, aggInfos, reqOrdering,
-		)
+func (b *Builder) buildGroupingSets(groupingSets *memo.GroupingSetsExpr) (execPlan, error) {
+	input, err := b.buildGroupByInput(groupingSets)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The grouping columns are projected first; the columns that are not part
+	// of every grouping set are NULL-extended and therefore map to new output
+	// columns.
+	var ep execPlan
+	groupingColIdx := make([]exec.NodeColumnOrdinal, len(groupingSets.GroupingCols))
+	for i, col := range groupingSets.GroupingCols {
+		groupingColIdx[i] = input.getNodeColumnOrdinal(col)
+		ep.outputCols.Set(int(groupingSets.OutCols[i]), i)
+	}
+	sets := make([]exec.NodeColumnOrdinalSet, len(groupingSets.Sets))
+	for i := range groupingSets.Sets {
+		sets[i] = input.getNodeColumnOrdinalSet(groupingSets.Sets[i])
+	}
+
+	aggInfos, err := b.buildAggregations(groupingSets.Aggregations, &input)
+	if err != nil {
+		return execPlan{}, err
 	}
+	for i := range groupingSets.Aggregations {
+		ep.outputCols.Set(int(groupingSets.Aggregations[i].Col), len(groupingColIdx)+i)
+	}
+	ep.outputCols.Set(int(groupingSets.SetIDCol), len(groupingColIdx)+len(aggInfos))
+
+	ep.root, err = b.factory.ConstructGroupingSets(input.root, groupingColIdx, sets, aggInfos)
 	if err != nil {
 		return execPlan{}, err
 	}
@@ -1226,8 +1279,13 @@ func (b *Builder) buildGroupByInput(groupBy memo.RelExpr) (execPlan, error) {
 	// We address just the GroupBy case for now because there is a particularly
 	// important case with COUNT(*) where we can remove all input columns, which
 	// leads to significant speedup.
-	private := groupBy.Private().(*memo.GroupingPrivate)
-	neededCols := private.GroupingCols.Copy()
+	var neededCols opt.ColSet
+	switch private := groupBy.Private().(type) {
+	case *memo.GroupingPrivate:
+		neededCols = private.GroupingCols.Copy()
+	case *memo.GroupingSetsPrivate:
+		neededCols = private.GroupingCols.ToSet()
+	}
 	aggs := *groupBy.Child(1).(*memo.AggregationsExpr)
 	for i := range aggs {
 		neededCols.UnionWith(memo.ExtractAggInputColumns(aggs[i].Agg))
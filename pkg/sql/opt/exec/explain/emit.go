@@ -266,6 +266,7 @@ var nodeNames = [...]string{
 	exportOp:               "export",
 	filterOp:               "filter",
 	groupByOp:              "group",
+	groupingSetsOp:         "group (grouping sets)",
 	hashJoinOp:             "", // This node does not have a fixed name.
 	indexJoinOp:            "index join",
 	insertFastPathOp:       "insert fast path",
@@ -468,6 +469,18 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 			a.Aggregations, nil /* groupCols */, nil /* groupColOrdering */, true, /* isScalar */
 		)
 
+	case groupingSetsOp:
+		a := n.args.(*groupingSetsArgs)
+		inputCols := a.Input.Columns()
+		e.emitGroupByAttributes(
+			inputCols, a.Aggregations, a.GroupCols, nil /* groupColOrdering */, false, /* isScalar */
+		)
+		sets := make([]string, len(a.GroupingSets))
+		for i, set := range a.GroupingSets {
+			sets[i] = fmt.Sprintf("(%s)", printColumnSet(inputCols, set))
+		}
+		ob.Attr("grouping sets", strings.Join(sets, ", "))
+
 	case distinctOp:
 		a := n.args.(*distinctArgs)
 		inputCols := a.Input.Columns()
@@ -404,6 +404,37 @@ func (f *Factory) ConstructScalarGroupBy(
 	return _n, nil
 }
 
+func (f *Factory) ConstructGroupingSets(
+	input exec.Node,
+	groupCols []exec.NodeColumnOrdinal,
+	groupingSets []exec.NodeColumnOrdinalSet,
+	aggregations []exec.AggInfo,
+) (exec.Node, error) {
+	inputNode := input.(*Node)
+	args := &groupingSetsArgs{
+		Input:        inputNode,
+		GroupCols:    groupCols,
+		GroupingSets: groupingSets,
+		Aggregations: aggregations,
+	}
+	_n, err := f.newNode(groupingSetsOp, args, nil /* ordering */, inputNode)
+	if err != nil {
+		return nil, err
+	}
+	// Build the "real" node.
+	wrapped, err := f.wrappedFactory.ConstructGroupingSets(
+		inputNode.WrappedNode(),
+		groupCols,
+		groupingSets,
+		aggregations,
+	)
+	if err != nil {
+		return nil, err
+	}
+	_n.wrappedNode = wrapped
+	return _n, nil
+}
+
 func (f *Factory) ConstructDistinct(
 	input exec.Node,
 	distinctCols exec.NodeColumnOrdinalSet,
@@ -1718,6 +1749,7 @@ const (
 	mergeJoinOp
 	groupByOp
 	scalarGroupByOp
+	groupingSetsOp
 	distinctOp
 	setOpOp
 	sortOp
@@ -1850,6 +1882,13 @@ type scalarGroupByArgs struct {
 	Aggregations []exec.AggInfo
 }
 
+type groupingSetsArgs struct {
+	Input        *Node
+	GroupCols    []exec.NodeColumnOrdinal
+	GroupingSets []exec.NodeColumnOrdinalSet
+	Aggregations []exec.AggInfo
+}
+
 type distinctArgs struct {
 	Input            *Node
 	DistinctCols     exec.NodeColumnOrdinalSet
@@ -97,6 +97,13 @@ func getResultColumns(
 		a := args.(*scalarGroupByArgs)
 		return groupByColumns(inputs[0], nil /* groupCols */, a.Aggregations), nil
 
+	case groupingSetsOp:
+		a := args.(*groupingSetsArgs)
+		return appendColumns(
+			groupByColumns(inputs[0], a.GroupCols, a.Aggregations),
+			colinfo.ResultColumn{Name: "grouping_set", Typ: types.Int},
+		), nil
+
 	case windowOp:
 		return args.(*windowArgs).Window.Cols, nil
 
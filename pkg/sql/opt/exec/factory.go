@@ -259,6 +259,50 @@ type InsertFastPathFKCheck struct {
 // relevant row.
 type MkErrFn func(tree.Datums) error
 
+// DeferrableCheckViolation is the error returned by the MkErrFn of an
+// ErrorIfRows node implementing the check of a deferrable FK or unique
+// constraint. In addition to the error that would be reported for the
+// violation, it identifies the constraint and the violating key values, so
+// that the check can be built again for these key values at the end of the
+// transaction if it is deferred (see optbuilder.BuildDeferredFKCheck).
+type DeferrableCheckViolation struct {
+	// Err is the error reported if the check is not deferred, or if the
+	// violation still exists when the check is run again.
+	Err error
+
+	// ConstraintName is the name of the violated constraint.
+	ConstraintName string
+
+	// Deferrability is the deferrability declared for the constraint.
+	Deferrability tree.ConstraintDeferrability
+
+	// ForeignKey is true if the violated constraint is an FK constraint.
+	ForeignKey bool
+
+	// Table is the origin table of an FK constraint, or the table on which a
+	// unique constraint is defined.
+	Table cat.StableID
+
+	// KeyVals are the values of the constraint columns in the row that
+	// violates the constraint.
+	KeyVals tree.Datums
+}
+
+// Error implements the error interface.
+func (e *DeferrableCheckViolation) Error() string {
+	return e.Err.Error()
+}
+
+// Cause implements the causer interface.
+func (e *DeferrableCheckViolation) Cause() error {
+	return e.Err
+}
+
+// Unwrap implements the Wrapper interface.
+func (e *DeferrableCheckViolation) Unwrap() error {
+	return e.Err
+}
+
 // ExplainFactory is an extension of Factory used when constructing a plan that
 // can be explained. It allows annotation of nodes with extra information.
 type ExplainFactory interface {
@@ -206,6 +206,23 @@ type Factory interface {
 		aggregations []AggInfo,
 	) (Node, error)
 
+	// ConstructGroupingSets creates a node for a GroupingSets operation.
+	//
+	// GroupingSets runs an aggregation separately for each of multiple grouping
+	// sets, in a single pass over the input. Each grouping set is a subset of the
+	// groupCols. For each grouping set, a row is produced for each set of distinct
+	// values on the columns of the grouping set (or a single row if the grouping set
+	// is empty, even when there are no input rows). The row contains the values of
+	// the grouping columns (NULL for the columns that are not part of the row's
+	// grouping set), followed by one value for each aggregation, followed by the
+	// index of the row's grouping set.
+	ConstructGroupingSets(
+		input Node,
+		groupCols []NodeColumnOrdinal,
+		groupingSets []NodeColumnOrdinalSet,
+		aggregations []AggInfo,
+	) (Node, error)
+
 	// ConstructDistinct creates a node for a Distinct operation.
 	//
 	// Distinct filters out rows such that only the first row is kept for each set of
@@ -916,6 +933,15 @@ func (StubFactory) ConstructScalarGroupBy(
 	return struct{}{}, nil
 }
 
+func (StubFactory) ConstructGroupingSets(
+	input Node,
+	groupCols []NodeColumnOrdinal,
+	groupingSets []NodeColumnOrdinalSet,
+	aggregations []AggInfo,
+) (Node, error) {
+	return struct{}{}, nil
+}
+
 func (StubFactory) ConstructDistinct(
 	input Node,
 	distinctCols NodeColumnOrdinalSet,
@@ -110,13 +110,21 @@ func TryFilterInvertedIndex(
 		}
 		typ = types.Geometry
 	} else {
-		filterPlanner = &jsonOrArrayFilterPlanner{
-			tabID:           tabID,
-			index:           index,
-			computedColumns: computedColumns,
-		}
 		col := index.VirtualInvertedColumn().InvertedSourceColumnOrdinal()
 		typ = factory.Metadata().Table(tabID).Column(col).DatumType()
+		if typ.Family() == types.TSVectorFamily {
+			filterPlanner = &tsqueryFilterPlanner{
+				tabID:           tabID,
+				index:           index,
+				computedColumns: computedColumns,
+			}
+		} else {
+			filterPlanner = &jsonOrArrayFilterPlanner{
+				tabID:           tabID,
+				index:           index,
+				computedColumns: computedColumns,
+			}
+		}
 	}
 
 	var invertedExpr inverted.Expression
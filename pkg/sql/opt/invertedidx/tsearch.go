@@ -0,0 +1,86 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package invertedidx
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+type tsqueryFilterPlanner struct {
+	tabID           opt.TableID
+	index           cat.Index
+	computedColumns map[opt.ColumnID]opt.ScalarExpr
+}
+
+var _ invertedFilterPlanner = &tsqueryFilterPlanner{}
+
+// extractInvertedFilterConditionFromLeaf is part of the invertedFilterPlanner
+// interface.
+func (t *tsqueryFilterPlanner) extractInvertedFilterConditionFromLeaf(
+	evalCtx *tree.EvalContext, expr opt.ScalarExpr,
+) (
+	invertedExpr inverted.Expression,
+	remainingFilters opt.ScalarExpr,
+	_ *invertedexpr.PreFiltererStateForInvertedFilterer,
+) {
+	if m, ok := expr.(*memo.TSMatchesExpr); ok {
+		// The @@ operator is commutative, so the index column can be on either
+		// side.
+		if isIndexColumn(t.tabID, t.index, m.Left, t.computedColumns) {
+			invertedExpr = t.extractTSMatchesCondition(m.Right)
+		} else if isIndexColumn(t.tabID, t.index, m.Right, t.computedColumns) {
+			invertedExpr = t.extractTSMatchesCondition(m.Left)
+		}
+	}
+
+	if invertedExpr == nil {
+		// An inverted expression could not be extracted.
+		return inverted.NonInvertedColExpression{}, expr, nil
+	}
+
+	// If the extracted inverted expression is not tight then remaining filters
+	// must be applied after the inverted index scan.
+	if !invertedExpr.IsTight() {
+		remainingFilters = expr
+	}
+
+	// We do not currently support pre-filtering for TSVector indexes, so the
+	// returned pre-filter state is nil.
+	return invertedExpr, remainingFilters, nil
+}
+
+// extractTSMatchesCondition extracts an InvertedExpression representing an
+// inverted filter over the planner's inverted index, based on the given
+// TSQuery argument of a @@ expression whose other argument is the index
+// column. Returns nil if no inverted filter could be extracted.
+func (t *tsqueryFilterPlanner) extractTSMatchesCondition(query opt.ScalarExpr) inverted.Expression {
+	// The query should be a constant.
+	if !memo.CanExtractConstDatum(query) {
+		return nil
+	}
+	q, ok := memo.ExtractConstDatum(query).(*tree.DTSQuery)
+	if !ok {
+		return nil
+	}
+	invertedExpr, err := q.GetInvertedExpr()
+	if err != nil {
+		return nil
+	}
+	if _, ok := invertedExpr.(inverted.NonInvertedColExpression); ok {
+		return nil
+	}
+	return invertedExpr
+}
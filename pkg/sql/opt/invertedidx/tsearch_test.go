@@ -0,0 +1,197 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package invertedidx_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedidx"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func TestTryFilterTSVectorIndex(t *testing.T) {
+	semaCtx := tree.MakeSemaContext()
+	evalCtx := tree.NewTestingEvalContext(nil /* st */)
+
+	tc := testcat.New()
+	if _, err := tc.ExecuteDDL(
+		"CREATE TABLE t (v TSVECTOR, j JSON, INVERTED INDEX (v), INVERTED INDEX (j))",
+	); err != nil {
+		t.Fatal(err)
+	}
+	var f norm.Factory
+	f.Init(evalCtx, tc)
+	md := f.Metadata()
+	tn := tree.NewUnqualifiedTableName("t")
+	tab := md.AddTable(tc.Table(tn), tn)
+	tsvectorOrd, jsonOrd := 1, 2
+
+	testCases := []struct {
+		filters          string
+		indexOrd         int
+		ok               bool
+		tight            bool
+		unique           bool
+		remainingFilters string
+	}{
+		// If we can create an inverted filter with the given filter expression and
+		// index, ok=true. If the spans in the resulting inverted index constraint
+		// do not have duplicate primary keys, unique=true. If the spans are tight,
+		// tight=true and remainingFilters="". Otherwise, tight is false and
+		// remainingFilters contains some or all of the original filters.
+		{
+			filters:  "v @@ 'fat'",
+			indexOrd: tsvectorOrd,
+			ok:       true,
+			tight:    true,
+			unique:   true,
+		},
+		{
+			// The operands of @@ can be in either order.
+			filters:  "'fat'::TSQUERY @@ v",
+			indexOrd: tsvectorOrd,
+			ok:       true,
+			tight:    true,
+			unique:   true,
+		},
+		{
+			// Wrong index ordinal.
+			filters:  "v @@ 'fat'",
+			indexOrd: jsonOrd,
+			ok:       false,
+		},
+		{
+			filters:  "v @@ 'fat & rat'",
+			indexOrd: tsvectorOrd,
+			ok:       true,
+			tight:    true,
+			unique:   true,
+		},
+		{
+			filters:  "v @@ 'fat | rat'",
+			indexOrd: tsvectorOrd,
+			ok:       true,
+			tight:    true,
+			unique:   false,
+		},
+		{
+			// A prefix lexeme can match several lexemes of the same document.
+			filters:  "v @@ 'fa:*'",
+			indexOrd: tsvectorOrd,
+			ok:       true,
+			tight:    true,
+			unique:   false,
+		},
+		{
+			// Weights are not stored in the index.
+			filters:          "v @@ 'fat:A'",
+			indexOrd:         tsvectorOrd,
+			ok:               true,
+			tight:            false,
+			unique:           true,
+			remainingFilters: "v @@ 'fat:A'",
+		},
+		{
+			// Positions are not stored in the index.
+			filters:          "v @@ 'fat <-> rat'",
+			indexOrd:         tsvectorOrd,
+			ok:               true,
+			tight:            false,
+			unique:           true,
+			remainingFilters: "v @@ 'fat <-> rat'",
+		},
+		{
+			filters:          "v @@ 'fat & !rat'",
+			indexOrd:         tsvectorOrd,
+			ok:               true,
+			tight:            false,
+			unique:           true,
+			remainingFilters: "v @@ 'fat & !rat'",
+		},
+		{
+			// A negation can't be evaluated using the index.
+			filters:  "v @@ '!rat'",
+			indexOrd: tsvectorOrd,
+			ok:       false,
+		},
+		{
+			filters:  "v @@ 'fat | !rat'",
+			indexOrd: tsvectorOrd,
+			ok:       false,
+		},
+		{
+			filters:  "v @@ 'fat' AND v @@ 'rat'",
+			indexOrd: tsvectorOrd,
+			ok:       true,
+			tight:    true,
+			unique:   true,
+		},
+		{
+			filters:          "v @@ 'fat' AND j @> '1'",
+			indexOrd:         tsvectorOrd,
+			ok:               true,
+			tight:            false,
+			unique:           true,
+			remainingFilters: "j @> '1'",
+		},
+		{
+			// The query must be a constant.
+			filters:  "v @@ to_tsquery(j->>'a')",
+			indexOrd: tsvectorOrd,
+			ok:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Logf("test case: %v", tc)
+		filters := testutils.BuildFilters(t, &f, &semaCtx, evalCtx, tc.filters)
+
+		spanExpr, _, remainingFilters, _, ok := invertedidx.TryFilterInvertedIndex(
+			evalCtx,
+			&f,
+			filters,
+			nil, /* optionalFilters */
+			tab,
+			md.Table(tab).Index(tc.indexOrd),
+			nil, /* computedColumns */
+		)
+		if tc.ok != ok {
+			t.Fatalf("expected %v, got %v", tc.ok, ok)
+		}
+		if !ok {
+			continue
+		}
+
+		if tc.tight != spanExpr.Tight {
+			t.Fatalf("expected tight=%v, but got %v", tc.tight, spanExpr.Tight)
+		}
+		if tc.unique != spanExpr.Unique {
+			t.Fatalf("expected unique=%v, but got %v", tc.unique, spanExpr.Unique)
+		}
+
+		if remainingFilters == nil {
+			if tc.remainingFilters != "" {
+				t.Fatalf("expected remainingFilters=%s, got <nil>", tc.remainingFilters)
+			}
+			continue
+		}
+		if tc.remainingFilters == "" {
+			t.Fatalf("expected remainingFilters=<nil>, got %v", remainingFilters)
+		}
+		expRemainingFilters := testutils.BuildFilters(t, &f, &semaCtx, evalCtx, tc.remainingFilters)
+		if remainingFilters.String() != expRemainingFilters.String() {
+			t.Errorf("expected remainingFilters=%v, got %v", expRemainingFilters, remainingFilters)
+		}
+	}
+}
@@ -185,6 +185,17 @@ func (m *Memo) CheckExpr(e opt.Expr) {
 			}
 		}
 
+	case *GroupingSetsExpr:
+		if len(t.OutCols) != len(t.GroupingCols) {
+			panic(errors.AssertionFailedf("grouping sets with mismatched grouping and output columns"))
+		}
+		groupingCols := t.GroupingCols.ToSet()
+		for _, set := range t.Sets {
+			if !set.SubsetOf(groupingCols) {
+				panic(errors.AssertionFailedf("grouping set %s is not a subset of the grouping columns", set))
+			}
+		}
+
 	case *IndexJoinExpr:
 		if t.Cols.Empty() {
 			panic(errors.AssertionFailedf("index join with no columns"))
@@ -11,6 +11,7 @@
 package memo
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/bits"
@@ -302,6 +303,21 @@ func (n ZipExpr) OutputCols() opt.ColSet {
 // used by the ColumnAccess scalar expression.
 type TupleOrdinal uint32
 
+// ColSets is a list of column sets. It is used by the GroupingSets operator to
+// store the columns of each grouping set.
+type ColSets []opt.ColSet
+
+func (cs ColSets) String() string {
+	var buf bytes.Buffer
+	for i, set := range cs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(set.String())
+	}
+	return buf.String()
+}
+
 // ScanLimit is used for a limited table or index scan and stores the limit as
 // well as the desired scan direction. A value of 0 means that there is no
 // limit.
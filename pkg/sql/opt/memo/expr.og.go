@@ -5002,6 +5002,174 @@ func (g *ensureUpsertDistinctOnGroup) bestProps() *bestProps {
 	return &g.best
 }
 
+// GroupingSetsExpr computes aggregate functions separately for each of multiple
+// grouping sets, as specified by GROUP BY ROLLUP, CUBE or GROUPING SETS. Each
+// grouping set is a subset of the grouping columns, and the aggregations are
+// computed over the groups of input rows that are equal on the columns of the
+// grouping set. All grouping sets are computed in a single pass over the input.
+//
+// Every output row belongs to exactly one grouping set, the index of which is
+// projected in the SetIDCol column. The grouping columns are projected in
+// OutCols, and are NULL for the rows of the grouping sets that they are not
+// part of. Like ScalarGroupBy, an empty grouping set produces a single row even
+// if the input is empty.
+//
+// GroupingSets is deliberately not tagged as Grouping, since it is not
+// polymorphic with GroupBy: the grouping columns do not form a key of the
+// output on their own, and rules that apply to grouping operators are not
+// valid for it.
+type GroupingSetsExpr struct {
+	Input        RelExpr
+	Aggregations AggregationsExpr
+	GroupingSetsPrivate
+
+	grp  exprGroup
+	next RelExpr
+}
+
+var _ RelExpr = &GroupingSetsExpr{}
+
+func (e *GroupingSetsExpr) Op() opt.Operator {
+	return opt.GroupingSetsOp
+}
+
+func (e *GroupingSetsExpr) ChildCount() int {
+	return 2
+}
+
+func (e *GroupingSetsExpr) Child(nth int) opt.Expr {
+	switch nth {
+	case 0:
+		return e.Input
+	case 1:
+		return &e.Aggregations
+	}
+	panic(errors.AssertionFailedf("child index out of range"))
+}
+
+func (e *GroupingSetsExpr) Private() interface{} {
+	return &e.GroupingSetsPrivate
+}
+
+func (e *GroupingSetsExpr) String() string {
+	f := MakeExprFmtCtx(ExprFmtHideQualifications, e.Memo(), nil)
+	f.FormatExpr(e)
+	return f.Buffer.String()
+}
+
+func (e *GroupingSetsExpr) SetChild(nth int, child opt.Expr) {
+	switch nth {
+	case 0:
+		e.Input = child.(RelExpr)
+		return
+	case 1:
+		e.Aggregations = *child.(*AggregationsExpr)
+		return
+	}
+	panic(errors.AssertionFailedf("child index out of range"))
+}
+
+func (e *GroupingSetsExpr) Memo() *Memo {
+	return e.grp.memo()
+}
+
+func (e *GroupingSetsExpr) Relational() *props.Relational {
+	return e.grp.relational()
+}
+
+func (e *GroupingSetsExpr) FirstExpr() RelExpr {
+	return e.grp.firstExpr()
+}
+
+func (e *GroupingSetsExpr) NextExpr() RelExpr {
+	return e.next
+}
+
+func (e *GroupingSetsExpr) RequiredPhysical() *physical.Required {
+	return e.grp.bestProps().required
+}
+
+func (e *GroupingSetsExpr) ProvidedPhysical() *physical.Provided {
+	return &e.grp.bestProps().provided
+}
+
+func (e *GroupingSetsExpr) Cost() Cost {
+	return e.grp.bestProps().cost
+}
+
+func (e *GroupingSetsExpr) group() exprGroup {
+	return e.grp
+}
+
+func (e *GroupingSetsExpr) bestProps() *bestProps {
+	return e.grp.bestProps()
+}
+
+func (e *GroupingSetsExpr) setNext(member RelExpr) {
+	if e.next != nil {
+		panic(errors.AssertionFailedf("expression already has its next defined: %s", e))
+	}
+	e.next = member
+}
+
+func (e *GroupingSetsExpr) setGroup(member RelExpr) {
+	if e.grp != nil {
+		panic(errors.AssertionFailedf("expression is already in a group: %s", e))
+	}
+	e.grp = member.group()
+	LastGroupMember(member).setNext(e)
+}
+
+type groupingSetsGroup struct {
+	mem   *Memo
+	rel   props.Relational
+	first GroupingSetsExpr
+	best  bestProps
+}
+
+var _ exprGroup = &groupingSetsGroup{}
+
+func (g *groupingSetsGroup) memo() *Memo {
+	return g.mem
+}
+
+func (g *groupingSetsGroup) relational() *props.Relational {
+	return &g.rel
+}
+
+func (g *groupingSetsGroup) firstExpr() RelExpr {
+	return &g.first
+}
+
+func (g *groupingSetsGroup) bestProps() *bestProps {
+	return &g.best
+}
+
+type GroupingSetsPrivate struct {
+	// GroupingCols contains the input columns that are part of at least one
+	// grouping set.
+	GroupingCols opt.ColList
+
+	// OutCols contains the output column corresponding to each of the
+	// GroupingCols. It is the grouping column itself if the column is part of
+	// every grouping set, or a new column otherwise, since the column is NULL
+	// for the grouping sets that it is not part of.
+	OutCols opt.ColList
+
+	// Sets contains the columns of each grouping set; each set is a subset of
+	// GroupingCols.
+	Sets ColSets
+
+	// SetIDCol is the output column containing the index of the grouping set
+	// in Sets that each row belongs to.
+	SetIDCol opt.ColumnID
+
+	// Ordering specifies the order required of the input. Since the grouping
+	// sets are computed with hashing, it only serves as an intra-group ordering
+	// for order-dependent aggregations (like ArrayAgg).
+	Ordering physical.OrderingChoice
+}
+
 // UnionExpr is an operator used to combine the Left and Right input relations into
 // a single set containing rows from both inputs. Duplicate rows are discarded.
 // The SetPrivate field matches columns from the Left and Right inputs of the
@@ -10173,6 +10341,65 @@ func (e *BBoxIntersectsExpr) DataType() *types.T {
 	return types.Bool
 }
 
+// TSMatchesExpr is the @@ operator, which matches a tsvector against a tsquery. The
+// operands can be in either order.
+type TSMatchesExpr struct {
+	Left  opt.ScalarExpr
+	Right opt.ScalarExpr
+
+	id opt.ScalarID
+}
+
+var _ opt.ScalarExpr = &TSMatchesExpr{}
+
+func (e *TSMatchesExpr) ID() opt.ScalarID {
+	return e.id
+}
+
+func (e *TSMatchesExpr) Op() opt.Operator {
+	return opt.TSMatchesOp
+}
+
+func (e *TSMatchesExpr) ChildCount() int {
+	return 2
+}
+
+func (e *TSMatchesExpr) Child(nth int) opt.Expr {
+	switch nth {
+	case 0:
+		return e.Left
+	case 1:
+		return e.Right
+	}
+	panic(errors.AssertionFailedf("child index out of range"))
+}
+
+func (e *TSMatchesExpr) Private() interface{} {
+	return nil
+}
+
+func (e *TSMatchesExpr) String() string {
+	f := MakeExprFmtCtx(ExprFmtHideQualifications, nil, nil)
+	f.FormatExpr(e)
+	return f.Buffer.String()
+}
+
+func (e *TSMatchesExpr) SetChild(nth int, child opt.Expr) {
+	switch nth {
+	case 0:
+		e.Left = child.(opt.ScalarExpr)
+		return
+	case 1:
+		e.Right = child.(opt.ScalarExpr)
+		return
+	}
+	panic(errors.AssertionFailedf("child index out of range"))
+}
+
+func (e *TSMatchesExpr) DataType() *types.T {
+	return types.Bool
+}
+
 // AnyScalarExpr is the form of ANY which refers to an ANY operation on a
 // tuple or array, as opposed to Any which operates on a subquery.
 type AnyScalarExpr struct {
@@ -18745,6 +18972,32 @@ func (m *Memo) MemoizeEnsureUpsertDistinctOn(
 	return interned.FirstExpr()
 }
 
+func (m *Memo) MemoizeGroupingSets(
+	input RelExpr,
+	aggregations AggregationsExpr,
+	groupingSetsPrivate *GroupingSetsPrivate,
+) RelExpr {
+	const size = int64(unsafe.Sizeof(groupingSetsGroup{}))
+	grp := &groupingSetsGroup{mem: m, first: GroupingSetsExpr{
+		Input:               input,
+		Aggregations:        aggregations,
+		GroupingSetsPrivate: *groupingSetsPrivate,
+	}}
+	e := &grp.first
+	e.grp = grp
+	interned := m.interner.InternGroupingSets(e)
+	if interned == e {
+		if m.newGroupFn != nil {
+			m.newGroupFn(e)
+		}
+		m.logPropsBuilder.buildGroupingSetsProps(e, &grp.rel)
+		grp.rel.Populated = true
+		m.memEstimate += size
+		m.CheckExpr(e)
+	}
+	return interned.FirstExpr()
+}
+
 func (m *Memo) MemoizeUnion(
 	left RelExpr,
 	right RelExpr,
@@ -20009,6 +20262,27 @@ func (m *Memo) MemoizeBBoxIntersects(
 	return interned
 }
 
+func (m *Memo) MemoizeTSMatches(
+	left opt.ScalarExpr,
+	right opt.ScalarExpr,
+) *TSMatchesExpr {
+	const size = int64(unsafe.Sizeof(TSMatchesExpr{}))
+	e := &TSMatchesExpr{
+		Left:  left,
+		Right: right,
+		id:    m.NextID(),
+	}
+	interned := m.interner.InternTSMatches(e)
+	if interned == e {
+		if m.newGroupFn != nil {
+			m.newGroupFn(e)
+		}
+		m.memEstimate += size
+		m.CheckExpr(e)
+	}
+	return interned
+}
+
 func (m *Memo) MemoizeAnyScalar(
 	left opt.ScalarExpr,
 	right opt.ScalarExpr,
@@ -22762,6 +23036,20 @@ func (m *Memo) AddEnsureUpsertDistinctOnToGroup(e *EnsureUpsertDistinctOnExpr, g
 	return interned
 }
 
+func (m *Memo) AddGroupingSetsToGroup(e *GroupingSetsExpr, grp RelExpr) *GroupingSetsExpr {
+	const size = int64(unsafe.Sizeof(GroupingSetsExpr{}))
+	interned := m.interner.InternGroupingSets(e)
+	if interned == e {
+		e.setGroup(grp)
+		m.memEstimate += size
+		m.CheckExpr(e)
+	} else if interned.group() != grp.group() {
+		// This is a group collision, do nothing.
+		return nil
+	}
+	return interned
+}
+
 func (m *Memo) AddUnionToGroup(e *UnionExpr, grp RelExpr) *UnionExpr {
 	const size = int64(unsafe.Sizeof(UnionExpr{}))
 	interned := m.interner.InternUnion(e)
@@ -23296,6 +23584,8 @@ func (in *interner) InternExpr(e opt.Expr) opt.Expr {
 		return in.InternUpsertDistinctOn(t)
 	case *EnsureUpsertDistinctOnExpr:
 		return in.InternEnsureUpsertDistinctOn(t)
+	case *GroupingSetsExpr:
+		return in.InternGroupingSets(t)
 	case *UnionExpr:
 		return in.InternUnion(t)
 	case *IntersectExpr:
@@ -23430,6 +23720,8 @@ func (in *interner) InternExpr(e opt.Expr) opt.Expr {
 		return in.InternBBoxCovers(t)
 	case *BBoxIntersectsExpr:
 		return in.InternBBoxIntersects(t)
+	case *TSMatchesExpr:
+		return in.InternTSMatches(t)
 	case *AnyScalarExpr:
 		return in.InternAnyScalar(t)
 	case *BitandExpr:
@@ -24709,6 +25001,36 @@ func (in *interner) InternEnsureUpsertDistinctOn(val *EnsureUpsertDistinctOnExpr
 	return val
 }
 
+func (in *interner) InternGroupingSets(val *GroupingSetsExpr) *GroupingSetsExpr {
+	in.hasher.Init()
+	in.hasher.HashOperator(opt.GroupingSetsOp)
+	in.hasher.HashRelExpr(val.Input)
+	in.hasher.HashAggregationsExpr(val.Aggregations)
+	in.hasher.HashColList(val.GroupingCols)
+	in.hasher.HashColList(val.OutCols)
+	in.hasher.HashColSets(val.Sets)
+	in.hasher.HashColumnID(val.SetIDCol)
+	in.hasher.HashOrderingChoice(val.Ordering)
+
+	in.cache.Start(in.hasher.hash)
+	for in.cache.Next() {
+		if existing, ok := in.cache.Item().(*GroupingSetsExpr); ok {
+			if in.hasher.IsRelExprEqual(val.Input, existing.Input) &&
+				in.hasher.IsAggregationsExprEqual(val.Aggregations, existing.Aggregations) &&
+				in.hasher.IsColListEqual(val.GroupingCols, existing.GroupingCols) &&
+				in.hasher.IsColListEqual(val.OutCols, existing.OutCols) &&
+				in.hasher.IsColSetsEqual(val.Sets, existing.Sets) &&
+				in.hasher.IsColumnIDEqual(val.SetIDCol, existing.SetIDCol) &&
+				in.hasher.IsOrderingChoiceEqual(val.Ordering, existing.Ordering) {
+				return existing
+			}
+		}
+	}
+
+	in.cache.Add(val)
+	return val
+}
+
 func (in *interner) InternUnion(val *UnionExpr) *UnionExpr {
 	in.hasher.Init()
 	in.hasher.HashOperator(opt.UnionOp)
@@ -26111,6 +26433,26 @@ func (in *interner) InternBBoxIntersects(val *BBoxIntersectsExpr) *BBoxIntersect
 	return val
 }
 
+func (in *interner) InternTSMatches(val *TSMatchesExpr) *TSMatchesExpr {
+	in.hasher.Init()
+	in.hasher.HashOperator(opt.TSMatchesOp)
+	in.hasher.HashScalarExpr(val.Left)
+	in.hasher.HashScalarExpr(val.Right)
+
+	in.cache.Start(in.hasher.hash)
+	for in.cache.Next() {
+		if existing, ok := in.cache.Item().(*TSMatchesExpr); ok {
+			if in.hasher.IsScalarExprEqual(val.Left, existing.Left) &&
+				in.hasher.IsScalarExprEqual(val.Right, existing.Right) {
+				return existing
+			}
+		}
+	}
+
+	in.cache.Add(val)
+	return val
+}
+
 func (in *interner) InternAnyScalar(val *AnyScalarExpr) *AnyScalarExpr {
 	in.hasher.Init()
 	in.hasher.HashOperator(opt.AnyScalarOp)
@@ -28495,6 +28837,8 @@ func (b *logicalPropsBuilder) buildProps(e RelExpr, rel *props.Relational) {
 		b.buildUpsertDistinctOnProps(t, rel)
 	case *EnsureUpsertDistinctOnExpr:
 		b.buildEnsureUpsertDistinctOnProps(t, rel)
+	case *GroupingSetsExpr:
+		b.buildGroupingSetsProps(t, rel)
 	case *UnionExpr:
 		b.buildUnionProps(t, rel)
 	case *IntersectExpr:
@@ -303,6 +303,15 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			tp.Childf("error: \"%s\"", private.ErrorOnDup)
 		}
 
+	case *GroupingSetsExpr:
+		if !f.HasFlags(ExprFmtHideColumns) {
+			f.formatColList(e, tp, "grouping columns:", t.GroupingCols)
+			tp.Childf("grouping sets: %s", t.Sets)
+		}
+		if !f.HasFlags(ExprFmtHidePhysProps) && !t.Ordering.Any() {
+			tp.Childf("internal-ordering: %s", t.Ordering)
+		}
+
 	case *LimitExpr:
 		if !f.HasFlags(ExprFmtHidePhysProps) && !t.Ordering.Any() {
 			tp.Childf("internal-ordering: %s", t.Ordering)
@@ -1363,6 +1372,12 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 			fmt.Fprintf(f.Buffer, ",ordering=%s", t.Ordering)
 		}
 
+	case *GroupingSetsPrivate:
+		fmt.Fprintf(f.Buffer, " cols=%s,sets=%s", t.GroupingCols.ToSet().String(), t.Sets)
+		if !t.Ordering.Any() {
+			fmt.Fprintf(f.Buffer, ",ordering=%s", t.Ordering)
+		}
+
 	case *IndexJoinPrivate:
 		tab := f.Memo.metadata.Table(t.Table)
 		fmt.Fprintf(f.Buffer, " %s", tab.Name())
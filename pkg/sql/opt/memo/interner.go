@@ -443,6 +443,13 @@ func (h *hasher) HashColList(val opt.ColList) {
 	h.hash = hash
 }
 
+func (h *hasher) HashColSets(val ColSets) {
+	for _, set := range val {
+		h.HashColSet(set)
+		h.HashInt(set.Len())
+	}
+}
+
 func (h *hasher) HashOptionalColList(val opt.OptionalColList) {
 	hash := h.hash
 	for _, id := range val {
@@ -860,6 +867,18 @@ func (h *hasher) IsColListEqual(l, r opt.ColList) bool {
 	return l.Equals(r)
 }
 
+func (h *hasher) IsColSetsEqual(l, r ColSets) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if !l[i].Equals(r[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *hasher) IsOptionalColListEqual(l, r opt.OptionalColList) bool {
 	return l.Equals(r)
 }
@@ -318,6 +318,14 @@ func TestInterner(t *testing.T) {
 			{val1: opt.MakeColSet(1, 2, 3), val2: opt.MakeColSet(1, 2), equal: false},
 		}},
 
+		{hashFn: in.hasher.HashColSets, eqFn: in.hasher.IsColSetsEqual, variations: []testVariation{
+			{val1: ColSets{}, val2: ColSets{}, equal: true},
+			{val1: ColSets{opt.MakeColSet(1, 2), {}}, val2: ColSets{opt.MakeColSet(1, 2), {}}, equal: true},
+			{val1: ColSets{opt.MakeColSet(1, 2), {}}, val2: ColSets{{}, opt.MakeColSet(1, 2)}, equal: false},
+			{val1: ColSets{opt.MakeColSet(1), opt.MakeColSet(2)}, val2: ColSets{opt.MakeColSet(1, 2)}, equal: false},
+			{val1: ColSets{opt.MakeColSet(1)}, val2: ColSets{opt.MakeColSet(1), opt.MakeColSet(1)}, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashColList, eqFn: in.hasher.IsColListEqual, variations: []testVariation{
 			{val1: opt.ColList{}, val2: opt.ColList{}, equal: true},
 			{val1: opt.ColList{1, 2, 3}, val2: opt.ColList{1, 2, 3}, equal: true},
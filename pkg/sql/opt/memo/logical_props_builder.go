@@ -644,6 +644,102 @@ func (b *logicalPropsBuilder) buildGroupingExprProps(groupExpr RelExpr, rel *pro
 	}
 }
 
+func (b *logicalPropsBuilder) buildGroupingSetsProps(
+	groupingSets *GroupingSetsExpr, rel *props.Relational,
+) {
+	BuildSharedProps(groupingSets, &rel.Shared)
+
+	inputProps := groupingSets.Input.Relational()
+	aggs := groupingSets.Aggregations
+	private := &groupingSets.GroupingSetsPrivate
+
+	hasEmptySet := false
+	for _, set := range private.Sets {
+		if set.Empty() {
+			hasEmptySet = true
+		}
+	}
+
+	// Output Columns
+	// --------------
+	// Output columns are the union of the (possibly NULL-extended) grouping
+	// columns, the grouping set ID column and the columns from the aggregate
+	// projection list.
+	rel.OutputCols = private.OutCols.ToSet()
+	rel.OutputCols.Add(private.SetIDCol)
+	for i := range aggs {
+		rel.OutputCols.Add(aggs[i].Col)
+	}
+
+	// Not Null Columns
+	// ----------------
+	// Propagate not null setting from the grouping columns that are part of
+	// every grouping set, since they keep the IDs of the input columns.
+	rel.NotNullCols = inputProps.NotNullCols.Intersection(rel.OutputCols)
+	rel.NotNullCols.Add(private.SetIDCol)
+
+	for i := range aggs {
+		item := &aggs[i]
+		agg := ExtractAggFunc(item.Agg)
+
+		// Some aggregates never return NULL, regardless of input.
+		if opt.AggregateIsNeverNull(agg.Op()) {
+			rel.NotNullCols.Add(item.Col)
+			continue
+		}
+
+		// If there is a possibility that the aggregate function has zero input
+		// rows, then it may return NULL. This is possible with an empty grouping
+		// set and with AggFilter.
+		if hasEmptySet || item.Agg.Op() == opt.AggFilterOp {
+			continue
+		}
+
+		// Most aggregate functions return a non-NULL result if they have at least
+		// one input row with non-NULL argument value, and if all argument values are non-NULL.
+		if opt.AggregateIsNeverNullOnNonNullInput(agg.Op()) {
+			inputCols := ExtractAggInputColumns(agg)
+			if inputCols.SubsetOf(inputProps.NotNullCols) {
+				rel.NotNullCols.Add(item.Col)
+			}
+		}
+	}
+
+	// Outer Columns
+	// -------------
+	// Outer columns were derived by BuildSharedProps; remove any that are bound
+	// by input columns.
+	rel.OuterCols.DifferenceWith(inputProps.OutputCols)
+
+	// Functional Dependencies
+	// -----------------------
+	// The rows of each grouping set have distinct values of the grouping
+	// columns, and the rows of different grouping sets are distinguished by the
+	// grouping set ID column.
+	key := private.OutCols.ToSet()
+	key.Add(private.SetIDCol)
+	rel.FuncDeps.AddStrictKey(key, rel.OutputCols)
+
+	// Cardinality
+	// -----------
+	// Each empty grouping set returns exactly one row. Every other grouping set
+	// acts like a GroupBy, never returning more rows than the input has.
+	rel.Cardinality = props.ZeroCardinality
+	for _, set := range private.Sets {
+		if set.Empty() {
+			rel.Cardinality = rel.Cardinality.Add(props.OneCardinality)
+		} else {
+			rel.Cardinality = rel.Cardinality.Add(inputProps.Cardinality.AsLowAs(1))
+		}
+	}
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildGroupingSets(groupingSets, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildUnionProps(union *UnionExpr, rel *props.Relational) {
 	b.buildSetProps(union, rel)
 }
@@ -379,6 +379,9 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 		opt.UpsertDistinctOnOp, opt.EnsureUpsertDistinctOnOp:
 		return sb.colStatGroupBy(colSet, e)
 
+	case opt.GroupingSetsOp:
+		return sb.colStatGroupingSets(colSet, e.(*GroupingSetsExpr))
+
 	case opt.LimitOp:
 		return sb.colStatLimit(colSet, e.(*LimitExpr))
 
@@ -1800,6 +1803,92 @@ func (sb *statisticsBuilder) colStatGroupBy(
 	return colStat
 }
 
+// +---------------+
+// | Grouping Sets |
+// +---------------+
+
+func (sb *statisticsBuilder) buildGroupingSets(
+	groupingSets *GroupingSetsExpr, relProps *props.Relational,
+) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+	s.Available = sb.availabilityFromInput(groupingSets)
+
+	// The row count is the sum of the row counts of the grouping sets, each of
+	// which is estimated like the row count of a GroupBy.
+	s.RowCount = 0
+	for _, set := range groupingSets.Sets {
+		s.RowCount += sb.groupingSetRowCount(set, groupingSets)
+	}
+
+	sb.finalizeFromCardinality(relProps)
+}
+
+// groupingSetRowCount estimates the number of rows produced for the given
+// grouping set of a GroupingSets expression.
+func (sb *statisticsBuilder) groupingSetRowCount(
+	set opt.ColSet, groupingSets *GroupingSetsExpr,
+) float64 {
+	if set.Empty() {
+		// An empty grouping set always returns exactly one row.
+		return 1
+	}
+	inputStats := sb.statsFromChild(groupingSets, 0 /* childIdx */)
+	inputColStat := sb.colStatFromChild(set, groupingSets, 0 /* childIdx */)
+	return min(inputColStat.DistinctCount, inputStats.RowCount)
+}
+
+func (sb *statisticsBuilder) colStatGroupingSets(
+	colSet opt.ColSet, groupingSets *GroupingSetsExpr,
+) *props.ColumnStatistic {
+	relProps := groupingSets.Relational()
+	s := &relProps.Stats
+	private := &groupingSets.GroupingSetsPrivate
+
+	colStat, _ := s.ColStats.Add(colSet)
+
+	// Map the requested grouping columns to the corresponding input columns.
+	var inputCols opt.ColSet
+	for i, col := range private.OutCols {
+		if colSet.Contains(col) {
+			inputCols.Add(private.GroupingCols[i])
+		}
+	}
+
+	if inputCols.Len() == colSet.Len() {
+		// Only grouping columns are requested. The distinct values are the ones
+		// of the input, plus NULL for the grouping sets that don't contain all
+		// of the columns. All rows of the grouping sets that contain none of the
+		// columns are NULL on all of them; the other grouping sets have at most
+		// one such row, the group of NULLs on the columns of the set.
+		inputColStat := sb.colStatFromChild(inputCols, groupingSets, 0 /* childIdx */)
+		colStat.DistinctCount = inputColStat.DistinctCount + 1
+		colStat.NullCount = 0
+		for _, set := range private.Sets {
+			if setCols := inputCols.Intersection(set); !setCols.Empty() {
+				setColStat := sb.colStatFromChild(setCols, groupingSets, 0 /* childIdx */)
+				colStat.NullCount += min(1, setColStat.NullCount)
+			} else {
+				colStat.NullCount += sb.groupingSetRowCount(set, groupingSets)
+			}
+		}
+	} else {
+		// Some of the requested columns are aggregates or the grouping set ID
+		// column. Estimate that every row is distinct.
+		colStat.DistinctCount = s.RowCount
+		colStat.NullCount = 0
+	}
+
+	if colSet.Intersects(relProps.NotNullCols) {
+		colStat.NullCount = 0
+	}
+	sb.finalizeFromRowCountAndDistinctCounts(colStat, s)
+	return colStat
+}
+
 // +--------+
 // | Set Op |
 // +--------+
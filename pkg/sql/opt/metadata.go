@@ -98,6 +98,10 @@ type Metadata struct {
 	userDefinedTypes      map[oid.Oid]struct{}
 	userDefinedTypesSlice []*types.T
 
+	// userDefinedFunctions contains all user defined functions called in
+	// expressions in this query, along with the names they were resolved from.
+	userDefinedFunctions []mdUDF
+
 	// deps stores information about all data source objects depended on by the
 	// query, as well as the privileges required to access them. The objects are
 	// deduplicated: any name/object pair shows up at most once.
@@ -131,6 +135,12 @@ type mdDep struct {
 	privileges privilegeBitmap
 }
 
+// mdUDF stores a user defined function referenced by the query.
+type mdUDF struct {
+	name tree.UnresolvedName
+	udf  *tree.UDFDefinition
+}
+
 // MDDepName stores either the unresolved DataSourceName or the StableID from
 // the query that was used to resolve a data source.
 type MDDepName struct {
@@ -177,6 +187,10 @@ func (md *Metadata) Init() {
 		md.views[i] = nil
 	}
 
+	for i := range md.userDefinedFunctions {
+		md.userDefinedFunctions[i] = mdUDF{}
+	}
+
 	// This initialization pattern ensures that fields are not unwittingly
 	// reused. Field reuse must be explicit.
 	*md = Metadata{
@@ -186,6 +200,8 @@ func (md *Metadata) Init() {
 		sequences: md.sequences[:0],
 		deps:      md.deps[:0],
 		views:     md.views[:0],
+
+		userDefinedFunctions: md.userDefinedFunctions[:0],
 	}
 }
 
@@ -197,7 +213,8 @@ func (md *Metadata) Init() {
 func (md *Metadata) CopyFrom(from *Metadata) {
 	if len(md.schemas) != 0 || len(md.cols) != 0 || len(md.tables) != 0 ||
 		len(md.sequences) != 0 || len(md.deps) != 0 || len(md.views) != 0 ||
-		len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 {
+		len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 ||
+		len(md.userDefinedFunctions) != 0 {
 		panic(errors.AssertionFailedf("CopyFrom requires empty destination"))
 	}
 	md.schemas = append(md.schemas, from.schemas...)
@@ -212,6 +229,7 @@ func (md *Metadata) CopyFrom(from *Metadata) {
 		md.userDefinedTypes[typ.Oid()] = struct{}{}
 		md.userDefinedTypesSlice = append(md.userDefinedTypesSlice, typ)
 	}
+	md.userDefinedFunctions = append(md.userDefinedFunctions, from.userDefinedFunctions...)
 
 	// Clear table annotations. These objects can be mutable and can't be safely
 	// shared between different metadata instances.
@@ -323,6 +341,23 @@ func (md *Metadata) CheckDependencies(
 			return false, nil
 		}
 	}
+	// Check that all of the user defined functions still resolve to the same
+	// version of the same function, and that the user is still allowed to
+	// execute them.
+	for i := range md.userDefinedFunctions {
+		f := &md.userDefinedFunctions[i]
+		def, err := catalog.ResolveFunction(ctx, &f.name)
+		if err != nil {
+			return false, err
+		}
+		if def == nil {
+			return false, nil
+		}
+		toCheck := def.UDF()
+		if toCheck == nil || toCheck.ID != f.udf.ID || toCheck.Version != f.udf.Version {
+			return false, nil
+		}
+	}
 	return true, nil
 }
 
@@ -352,6 +387,22 @@ func (md *Metadata) AddUserDefinedType(typ *types.T) {
 	}
 }
 
+// AddUserDefinedFunction adds a user defined function, along with the name it
+// was resolved from, to the metadata for this query.
+func (md *Metadata) AddUserDefinedFunction(name *tree.UnresolvedName, def *tree.FunctionDefinition) {
+	udf := def.UDF()
+	if udf == nil {
+		return
+	}
+	for i := range md.userDefinedFunctions {
+		f := &md.userDefinedFunctions[i]
+		if f.udf.ID == udf.ID && f.name.String() == name.String() {
+			return
+		}
+	}
+	md.userDefinedFunctions = append(md.userDefinedFunctions, mdUDF{name: *name, udf: udf})
+}
+
 // AllUserDefinedTypes returns all user defined types contained in this query.
 func (md *Metadata) AllUserDefinedTypes() []*types.T {
 	return md.userDefinedTypesSlice
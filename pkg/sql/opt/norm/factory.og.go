@@ -9501,6 +9501,32 @@ func (_f *Factory) ConstructEnsureUpsertDistinctOn(
 	return _f.onConstructRelational(e)
 }
 
+// ConstructGroupingSets constructs an expression for the GroupingSets operator.
+// GroupingSets computes aggregate functions separately for each of multiple
+// grouping sets, as specified by GROUP BY ROLLUP, CUBE or GROUPING SETS. Each
+// grouping set is a subset of the grouping columns, and the aggregations are
+// computed over the groups of input rows that are equal on the columns of the
+// grouping set. All grouping sets are computed in a single pass over the input.
+//
+// Every output row belongs to exactly one grouping set, the index of which is
+// projected in the SetIDCol column. The grouping columns are projected in
+// OutCols, and are NULL for the rows of the grouping sets that they are not
+// part of. Like ScalarGroupBy, an empty grouping set produces a single row even
+// if the input is empty.
+//
+// GroupingSets is deliberately not tagged as Grouping, since it is not
+// polymorphic with GroupBy: the grouping columns do not form a key of the
+// output on their own, and rules that apply to grouping operators are not
+// valid for it.
+func (_f *Factory) ConstructGroupingSets(
+	input memo.RelExpr,
+	aggregations memo.AggregationsExpr,
+	groupingSetsPrivate *memo.GroupingSetsPrivate,
+) memo.RelExpr {
+	e := _f.mem.MemoizeGroupingSets(input, aggregations, groupingSetsPrivate)
+	return _f.onConstructRelational(e)
+}
+
 // ConstructUnion constructs an expression for the Union operator.
 // Union is an operator used to combine the Left and Right input relations into
 // a single set containing rows from both inputs. Duplicate rows are discarded.
@@ -11104,7 +11130,7 @@ func (_f *Factory) ConstructNot(
 		if opt.IsComparisonOp(input) {
 			left := input.Child(0).(opt.ScalarExpr)
 			right := input.Child(1).(opt.ScalarExpr)
-			if !(input.Op() == opt.ContainsOp || input.Op() == opt.JsonExistsOp || input.Op() == opt.JsonSomeExistsOp || input.Op() == opt.JsonAllExistsOp || input.Op() == opt.OverlapsOp) {
+			if !(input.Op() == opt.ContainsOp || input.Op() == opt.JsonExistsOp || input.Op() == opt.JsonSomeExistsOp || input.Op() == opt.JsonAllExistsOp || input.Op() == opt.OverlapsOp || input.Op() == opt.TSMatchesOp) {
 				if _f.matchedRule == nil || _f.matchedRule(opt.NegateComparison) {
 					_expr := _f.funcs.NegateComparison(input.Op(), left, right).(opt.ScalarExpr)
 					if _f.appliedRule != nil {
@@ -15119,6 +15145,90 @@ func (_f *Factory) ConstructBBoxIntersects(
 	return _f.onConstructScalar(e)
 }
 
+// ConstructTSMatches constructs an expression for the TSMatches operator.
+// TSMatches is the @@ operator, which matches a tsvector against a tsquery. The
+// operands can be in either order.
+func (_f *Factory) ConstructTSMatches(
+	left opt.ScalarExpr,
+	right opt.ScalarExpr,
+) opt.ScalarExpr {
+	// [FoldNullComparisonLeft]
+	{
+		_null, _ := left.(*memo.NullExpr)
+		if _null != nil {
+			if _f.matchedRule == nil || _f.matchedRule(opt.FoldNullComparisonLeft) {
+				_expr := _f.ConstructNull(
+					_f.funcs.BoolType(),
+				)
+				if _f.appliedRule != nil {
+					_f.appliedRule(opt.FoldNullComparisonLeft, nil, _expr)
+				}
+				return _expr
+			}
+		}
+	}
+
+	// [FoldNullComparisonRight]
+	{
+		_null, _ := right.(*memo.NullExpr)
+		if _null != nil {
+			if _f.matchedRule == nil || _f.matchedRule(opt.FoldNullComparisonRight) {
+				_expr := _f.ConstructNull(
+					_f.funcs.BoolType(),
+				)
+				if _f.appliedRule != nil {
+					_f.appliedRule(opt.FoldNullComparisonRight, nil, _expr)
+				}
+				return _expr
+			}
+		}
+	}
+
+	// [FoldComparison]
+	{
+		if _f.funcs.IsConstValueOrGroupOfConstValues(left) {
+			if _f.funcs.IsConstValueOrGroupOfConstValues(right) {
+				result := _f.funcs.FoldComparison(opt.TSMatchesOp, left, right)
+				if _f.funcs.Succeeded(result) {
+					if _f.matchedRule == nil || _f.matchedRule(opt.FoldComparison) {
+						_expr := result.(opt.ScalarExpr)
+						if _f.appliedRule != nil {
+							_f.appliedRule(opt.FoldComparison, nil, _expr)
+						}
+						return _expr
+					}
+				}
+			}
+		}
+	}
+
+	// [UnifyComparisonTypes]
+	{
+		_variable, _ := left.(*memo.VariableExpr)
+		if _variable != nil {
+			_const, _ := right.(*memo.ConstExpr)
+			if _const != nil {
+				result := _f.funcs.UnifyComparison(_variable, _const)
+				if _f.funcs.Succeeded(result) {
+					if _f.matchedRule == nil || _f.matchedRule(opt.UnifyComparisonTypes) {
+						_expr := _f.ConstructTSMatches(
+							_variable,
+							result,
+						)
+						if _f.appliedRule != nil {
+							_f.appliedRule(opt.UnifyComparisonTypes, nil, _expr)
+						}
+						return _expr
+					}
+				}
+			}
+		}
+	}
+
+	e := _f.mem.MemoizeTSMatches(left, right)
+	return _f.onConstructScalar(e)
+}
+
 // ConstructAnyScalar constructs an expression for the AnyScalar operator.
 // AnyScalar is the form of ANY which refers to an ANY operation on a
 // tuple or array, as opposed to Any which operates on a subquery.
@@ -18208,6 +18318,14 @@ func (f *Factory) Replace(e opt.Expr, replace ReplaceFunc) opt.Expr {
 		}
 		return t
 
+	case *memo.GroupingSetsExpr:
+		input := replace(t.Input).(memo.RelExpr)
+		aggregations, aggregationsChanged := f.replaceAggregationsExpr(t.Aggregations, replace)
+		if input != t.Input || aggregationsChanged {
+			return f.ConstructGroupingSets(input, aggregations, &t.GroupingSetsPrivate)
+		}
+		return t
+
 	case *memo.UnionExpr:
 		left := replace(t.Left).(memo.RelExpr)
 		right := replace(t.Right).(memo.RelExpr)
@@ -18656,6 +18774,14 @@ func (f *Factory) Replace(e opt.Expr, replace ReplaceFunc) opt.Expr {
 		}
 		return t
 
+	case *memo.TSMatchesExpr:
+		left := replace(t.Left).(opt.ScalarExpr)
+		right := replace(t.Right).(opt.ScalarExpr)
+		if left != t.Left || right != t.Right {
+			return f.ConstructTSMatches(left, right)
+		}
+		return t
+
 	case *memo.AnyScalarExpr:
 		left := replace(t.Left).(opt.ScalarExpr)
 		right := replace(t.Right).(opt.ScalarExpr)
@@ -19888,6 +20014,13 @@ func (f *Factory) CopyAndReplaceDefault(src opt.Expr, replace ReplaceFunc) (dst
 			&t.GroupingPrivate,
 		)
 
+	case *memo.GroupingSetsExpr:
+		return f.ConstructGroupingSets(
+			f.invokeReplace(t.Input, replace).(memo.RelExpr),
+			f.copyAndReplaceDefaultAggregationsExpr(t.Aggregations, replace),
+			&t.GroupingSetsPrivate,
+		)
+
 	case *memo.UnionExpr:
 		return f.ConstructUnion(
 			f.invokeReplace(t.Left, replace).(memo.RelExpr),
@@ -20235,6 +20368,12 @@ func (f *Factory) CopyAndReplaceDefault(src opt.Expr, replace ReplaceFunc) (dst
 			f.invokeReplace(t.Right, replace).(opt.ScalarExpr),
 		)
 
+	case *memo.TSMatchesExpr:
+		return f.ConstructTSMatches(
+			f.invokeReplace(t.Left, replace).(opt.ScalarExpr),
+			f.invokeReplace(t.Right, replace).(opt.ScalarExpr),
+		)
+
 	case *memo.AnyScalarExpr:
 		return f.ConstructAnyScalar(
 			f.invokeReplace(t.Left, replace).(opt.ScalarExpr),
@@ -21181,6 +21320,12 @@ func (f *Factory) DynamicConstruct(op opt.Operator, args ...interface{}) opt.Exp
 			*args[1].(*memo.AggregationsExpr),
 			args[2].(*memo.GroupingPrivate),
 		)
+	case opt.GroupingSetsOp:
+		return f.ConstructGroupingSets(
+			args[0].(memo.RelExpr),
+			*args[1].(*memo.AggregationsExpr),
+			args[2].(*memo.GroupingSetsPrivate),
+		)
 	case opt.UnionOp:
 		return f.ConstructUnion(
 			args[0].(memo.RelExpr),
@@ -21474,6 +21619,11 @@ func (f *Factory) DynamicConstruct(op opt.Operator, args ...interface{}) opt.Exp
 			args[0].(opt.ScalarExpr),
 			args[1].(opt.ScalarExpr),
 		)
+	case opt.TSMatchesOp:
+		return f.ConstructTSMatches(
+			args[0].(opt.ScalarExpr),
+			args[1].(opt.ScalarExpr),
+		)
 	case opt.AnyScalarOp:
 		return f.ConstructAnyScalar(
 			args[0].(opt.ScalarExpr),
@@ -561,7 +561,7 @@ func (c *CustomFuncs) FoldFunction(
 	for i := range exprs {
 		exprs[i] = memo.ExtractConstDatum(args[i])
 	}
-	funcRef := tree.WrapFunction(private.Name)
+	funcRef := tree.WrapResolvedFunction(private.Name, private.Properties, private.Overload)
 	fn := tree.NewTypedFuncExpr(
 		funcRef,
 		0, /* aggQualifier */
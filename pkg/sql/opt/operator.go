@@ -142,6 +142,7 @@ var ComparisonOpReverseMap = map[Operator]tree.ComparisonOperator{
 	OverlapsOp:       tree.Overlaps,
 	BBoxCoversOp:     tree.RegMatch,
 	BBoxIntersectsOp: tree.Overlaps,
+	TSMatchesOp:      tree.TSMatches,
 }
 
 // BinaryOpReverseMap maps from an optimizer operator type to a semantic tree
@@ -274,7 +275,7 @@ func ScalarOperatorTransmitsNulls(op Operator) bool {
 	case BitandOp, BitorOp, BitxorOp, PlusOp, MinusOp, MultOp, DivOp, FloorDivOp,
 		ModOp, PowOp, EqOp, NeOp, LtOp, GtOp, LeOp, GeOp, LikeOp, NotLikeOp, ILikeOp,
 		NotILikeOp, SimilarToOp, NotSimilarToOp, RegMatchOp, NotRegMatchOp, RegIMatchOp,
-		NotRegIMatchOp, ConstOp, BBoxCoversOp, BBoxIntersectsOp:
+		NotRegIMatchOp, ConstOp, BBoxCoversOp, BBoxIntersectsOp, TSMatchesOp:
 		return true
 
 	default:
@@ -290,7 +291,7 @@ func BoolOperatorRequiresNotNullArgs(op Operator) bool {
 		EqOp, LtOp, LeOp, GtOp, GeOp, NeOp,
 		LikeOp, NotLikeOp, ILikeOp, NotILikeOp, SimilarToOp, NotSimilarToOp,
 		RegMatchOp, NotRegMatchOp, RegIMatchOp, NotRegIMatchOp, BBoxCoversOp,
-		BBoxIntersectsOp:
+		BBoxIntersectsOp, TSMatchesOp:
 		return true
 	}
 	return false
@@ -476,6 +476,26 @@ const (
 	// polymorphically.
 	GroupingPrivateOp
 
+	// GroupingSets computes aggregate functions separately for each of multiple
+	// grouping sets, as specified by GROUP BY ROLLUP, CUBE or GROUPING SETS. Each
+	// grouping set is a subset of the grouping columns, and the aggregations are
+	// computed over the groups of input rows that are equal on the columns of the
+	// grouping set. All grouping sets are computed in a single pass over the input.
+	//
+	// Every output row belongs to exactly one grouping set, the index of which is
+	// projected in the SetIDCol column. The grouping columns are projected in
+	// OutCols, and are NULL for the rows of the grouping sets that they are not
+	// part of. Like ScalarGroupBy, an empty grouping set produces a single row even
+	// if the input is empty.
+	//
+	// GroupingSets is deliberately not tagged as Grouping, since it is not
+	// polymorphic with GroupBy: the grouping columns do not form a key of the
+	// output on their own, and rules that apply to grouping operators are not
+	// valid for it.
+	GroupingSetsOp
+
+	GroupingSetsPrivateOp
+
 	GtOp
 
 	ILikeOp
@@ -1033,6 +1053,10 @@ const (
 
 	SumIntOp
 
+	// TSMatches is the @@ operator, which matches a tsvector against a tsquery. The
+	// operands can be in either order.
+	TSMatchesOp
+
 	// True is the boolean true value that is equivalent to the tree.DBoolTrue datum
 	// value. It is a separate operator to make matching and replacement simpler and
 	// more efficient, as patterns can contain (True) expressions.
@@ -1253,13 +1277,13 @@ const (
 	NumOperators
 )
 
-const opNames = "unknownagg-distinctagg-filteraggregationsaggregations-itemalter-table-relocatealter-table-relocate-privatealter-table-splitalter-table-split-privatealter-table-unsplitalter-table-unsplit-allandanti-joinanti-join-applyanyany-not-null-aggany-scalararrayarray-aggarray-flattenavgb-box-coversb-box-intersectsbit-and-aggbit-or-aggbitandbitorbitxorbool-andbool-orcancel-privatecancel-queriescancel-sessionscasecastcoalescecollatecolumn-accessconcatconcat-aggconstconst-aggconst-not-null-aggcontainscontrol-jobscontrol-jobs-privatecontrol-schedulescontrol-schedules-privatecorrcountcount-rowscovar-popcovar-sampcreate-statisticscreate-statistics-privatecreate-tablecreate-table-privatecreate-viewcreate-view-privatecume-distdeletedense-rankdistinct-ondivensure-distinct-onensure-upsert-distinct-oneqexceptexcept-allexistsexplainexplain-privateexportexport-privatef-k-checksf-k-checks-itemf-k-checks-item-privatefake-relfake-rel-privatefalsefetch-textfetch-text-pathfetch-valfetch-val-pathfiltersfilters-itemfirst-aggfirst-valuefloor-divfull-joinfunctionfunction-privategegroup-bygrouping-privategti-likeif-errinindex-joinindex-join-privateindirectioninner-joininner-join-applyinsertintersectintersect-allinverted-filterinverted-filter-privateinverted-joininverted-join-privateisis-notis-tuple-not-nullis-tuple-nulljoin-privatejson-aggjson-all-existsjson-existsjson-object-aggjson-some-existsjsonb-aggjsonb-object-aggk-v-optionsk-v-options-iteml-shiftlaglast-valueleleadleft-joinleft-join-applylikelimitlookup-joinlookup-join-privateltmaxmax1-rowmerge-joinmerge-join-privateminminusmodmultmutation-privatenenotnot-i-likenot-innot-likenot-reg-i-matchnot-reg-matchnot-similar-tonth-valuentilenulloffsetopaque-d-d-lopaque-mutationopaque-relopaque-rel-privateorordinalityordinality-privateoverlapspercent-rankpercentile-contpercentile-discplaceholderpluspowprojectproject-setprojectionsprojections-itemr-shiftrangerankrecursive-c-t-erecursive-c-t-e-privatereg-i-matchreg-matchregression-avg-xregression-avg-yregression-countregression-interceptregression-r2regression-s-x-xregression-s-x-yregression-s-y-yregression-sloperight-joinrow-numbers-t-collects-t-extents-t-make-lines-t-unionscalar-group-byscalar-listscanscan-privateselectsemi-joinsemi-join-applysequence-selectsequence-select-privateset-privateshow-trace-for-sessionshow-trace-privatesimilar-tosortsqr-diffstd-devstd-dev-popstring-aggsubquerysubquery-privatesumsum-inttruetupleunary-cbrtunary-complementunary-minusunary-sqrtunionunion-allunique-checksunique-checks-itemunique-checks-item-privateunsupported-exprupdateupsertupsert-distinct-onvaluesvalues-privatevar-popvariablevariancewhenwindowwindow-from-offsetwindow-privatewindow-to-offsetwindowswindows-itemwindows-item-privatewithwith-privatewith-scanwith-scan-privatexor-aggzigzag-joinzigzag-join-privatezipzip-item"
+const opNames = "unknownagg-distinctagg-filteraggregationsaggregations-itemalter-table-relocatealter-table-relocate-privatealter-table-splitalter-table-split-privatealter-table-unsplitalter-table-unsplit-allandanti-joinanti-join-applyanyany-not-null-aggany-scalararrayarray-aggarray-flattenavgb-box-coversb-box-intersectsbit-and-aggbit-or-aggbitandbitorbitxorbool-andbool-orcancel-privatecancel-queriescancel-sessionscasecastcoalescecollatecolumn-accessconcatconcat-aggconstconst-aggconst-not-null-aggcontainscontrol-jobscontrol-jobs-privatecontrol-schedulescontrol-schedules-privatecorrcountcount-rowscovar-popcovar-sampcreate-statisticscreate-statistics-privatecreate-tablecreate-table-privatecreate-viewcreate-view-privatecume-distdeletedense-rankdistinct-ondivensure-distinct-onensure-upsert-distinct-oneqexceptexcept-allexistsexplainexplain-privateexportexport-privatef-k-checksf-k-checks-itemf-k-checks-item-privatefake-relfake-rel-privatefalsefetch-textfetch-text-pathfetch-valfetch-val-pathfiltersfilters-itemfirst-aggfirst-valuefloor-divfull-joinfunctionfunction-privategegroup-bygrouping-privategrouping-setsgrouping-sets-privategti-likeif-errinindex-joinindex-join-privateindirectioninner-joininner-join-applyinsertintersectintersect-allinverted-filterinverted-filter-privateinverted-joininverted-join-privateisis-notis-tuple-not-nullis-tuple-nulljoin-privatejson-aggjson-all-existsjson-existsjson-object-aggjson-some-existsjsonb-aggjsonb-object-aggk-v-optionsk-v-options-iteml-shiftlaglast-valueleleadleft-joinleft-join-applylikelimitlookup-joinlookup-join-privateltmaxmax1-rowmerge-joinmerge-join-privateminminusmodmultmutation-privatenenotnot-i-likenot-innot-likenot-reg-i-matchnot-reg-matchnot-similar-tonth-valuentilenulloffsetopaque-d-d-lopaque-mutationopaque-relopaque-rel-privateorordinalityordinality-privateoverlapspercent-rankpercentile-contpercentile-discplaceholderpluspowprojectproject-setprojectionsprojections-itemr-shiftrangerankrecursive-c-t-erecursive-c-t-e-privatereg-i-matchreg-matchregression-avg-xregression-avg-yregression-countregression-interceptregression-r2regression-s-x-xregression-s-x-yregression-s-y-yregression-sloperight-joinrow-numbers-t-collects-t-extents-t-make-lines-t-unionscalar-group-byscalar-listscanscan-privateselectsemi-joinsemi-join-applysequence-selectsequence-select-privateset-privateshow-trace-for-sessionshow-trace-privatesimilar-tosortsqr-diffstd-devstd-dev-popstring-aggsubquerysubquery-privatesumsum-intt-s-matchestruetupleunary-cbrtunary-complementunary-minusunary-sqrtunionunion-allunique-checksunique-checks-itemunique-checks-item-privateunsupported-exprupdateupsertupsert-distinct-onvaluesvalues-privatevar-popvariablevariancewhenwindowwindow-from-offsetwindow-privatewindow-to-offsetwindowswindows-itemwindows-item-privatewithwith-privatewith-scanwith-scan-privatexor-aggzigzag-joinzigzag-join-privatezipzip-item"
 
-var opNameIndexes = [...]uint32{0, 7, 19, 29, 41, 58, 78, 106, 123, 148, 167, 190, 193, 202, 217, 220, 236, 246, 251, 260, 273, 276, 288, 304, 315, 325, 331, 336, 342, 350, 357, 371, 385, 400, 404, 408, 416, 423, 436, 442, 452, 457, 466, 484, 492, 504, 524, 541, 566, 570, 575, 585, 594, 604, 621, 646, 658, 678, 689, 708, 717, 723, 733, 744, 747, 765, 790, 792, 798, 808, 814, 821, 836, 842, 856, 866, 881, 904, 912, 928, 933, 943, 958, 967, 981, 988, 1000, 1009, 1020, 1029, 1038, 1046, 1062, 1064, 1072, 1088, 1090, 1096, 1102, 1104, 1114, 1132, 1143, 1153, 1169, 1175, 1184, 1197, 1212, 1235, 1248, 1269, 1271, 1277, 1294, 1307, 1319, 1327, 1342, 1353, 1368, 1384, 1393, 1409, 1420, 1436, 1443, 1446, 1456, 1458, 1462, 1471, 1486, 1490, 1495, 1506, 1525, 1527, 1530, 1538, 1548, 1566, 1569, 1574, 1577, 1581, 1597, 1599, 1602, 1612, 1618, 1626, 1641, 1654, 1668, 1677, 1682, 1686, 1692, 1704, 1719, 1729, 1747, 1749, 1759, 1777, 1785, 1797, 1812, 1827, 1838, 1842, 1845, 1852, 1863, 1874, 1890, 1897, 1902, 1906, 1921, 1944, 1955, 1964, 1980, 1996, 2012, 2032, 2045, 2061, 2077, 2093, 2109, 2119, 2129, 2140, 2150, 2163, 2172, 2187, 2198, 2202, 2214, 2220, 2229, 2244, 2259, 2282, 2293, 2315, 2333, 2343, 2347, 2355, 2362, 2373, 2383, 2391, 2407, 2410, 2417, 2421, 2426, 2436, 2452, 2463, 2473, 2478, 2487, 2500, 2518, 2544, 2560, 2566, 2572, 2590, 2596, 2610, 2617, 2625, 2633, 2637, 2643, 2661, 2675, 2691, 2698, 2710, 2730, 2734, 2746, 2755, 2772, 2779, 2790, 2809, 2812, 2820}
+var opNameIndexes = [...]uint32{0, 7, 19, 29, 41, 58, 78, 106, 123, 148, 167, 190, 193, 202, 217, 220, 236, 246, 251, 260, 273, 276, 288, 304, 315, 325, 331, 336, 342, 350, 357, 371, 385, 400, 404, 408, 416, 423, 436, 442, 452, 457, 466, 484, 492, 504, 524, 541, 566, 570, 575, 585, 594, 604, 621, 646, 658, 678, 689, 708, 717, 723, 733, 744, 747, 765, 790, 792, 798, 808, 814, 821, 836, 842, 856, 866, 881, 904, 912, 928, 933, 943, 958, 967, 981, 988, 1000, 1009, 1020, 1029, 1038, 1046, 1062, 1064, 1072, 1088, 1101, 1122, 1124, 1130, 1136, 1138, 1148, 1166, 1177, 1187, 1203, 1209, 1218, 1231, 1246, 1269, 1282, 1303, 1305, 1311, 1328, 1341, 1353, 1361, 1376, 1387, 1402, 1418, 1427, 1443, 1454, 1470, 1477, 1480, 1490, 1492, 1496, 1505, 1520, 1524, 1529, 1540, 1559, 1561, 1564, 1572, 1582, 1600, 1603, 1608, 1611, 1615, 1631, 1633, 1636, 1646, 1652, 1660, 1675, 1688, 1702, 1711, 1716, 1720, 1726, 1738, 1753, 1763, 1781, 1783, 1793, 1811, 1819, 1831, 1846, 1861, 1872, 1876, 1879, 1886, 1897, 1908, 1924, 1931, 1936, 1940, 1955, 1978, 1989, 1998, 2014, 2030, 2046, 2066, 2079, 2095, 2111, 2127, 2143, 2153, 2163, 2174, 2184, 2197, 2206, 2221, 2232, 2236, 2248, 2254, 2263, 2278, 2293, 2316, 2327, 2349, 2367, 2377, 2381, 2389, 2396, 2407, 2417, 2425, 2441, 2444, 2451, 2462, 2466, 2471, 2481, 2497, 2508, 2518, 2523, 2532, 2545, 2563, 2589, 2605, 2611, 2617, 2635, 2641, 2655, 2662, 2670, 2678, 2682, 2688, 2706, 2720, 2736, 2743, 2755, 2775, 2779, 2791, 2800, 2817, 2824, 2835, 2854, 2857, 2865}
 
-const opSyntaxTags = "UNKNOWNAGG DISTINCTAGG FILTERAGGREGATIONSAGGREGATIONS ITEMALTER TABLE RELOCATEALTER TABLE RELOCATE PRIVATEALTER TABLE SPLITALTER TABLE SPLIT PRIVATEALTER TABLE UNSPLITALTER TABLE UNSPLIT ALLANDANTI JOINANTI JOIN APPLYANYANY NOT NULL AGGANY SCALARARRAYARRAY AGGARRAY FLATTENAVGB BOX COVERSB BOX INTERSECTSBIT AND AGGBIT OR AGGBITANDBITORBITXORBOOL ANDBOOL ORCANCEL PRIVATECANCEL QUERIESCANCEL SESSIONSCASECASTCOALESCECOLLATECOLUMN ACCESSCONCATCONCAT AGGCONSTCONST AGGCONST NOT NULL AGGCONTAINSCONTROL JOBSCONTROL JOBS PRIVATECONTROL SCHEDULESCONTROL SCHEDULES PRIVATECORRCOUNTCOUNT ROWSCOVAR POPCOVAR SAMPCREATE STATISTICSCREATE STATISTICS PRIVATECREATE TABLECREATE TABLE PRIVATECREATE VIEWCREATE VIEW PRIVATECUME DISTDELETEDENSE RANKDISTINCT ONDIVENSURE DISTINCT ONENSURE UPSERT DISTINCT ONEQEXCEPTEXCEPT ALLEXISTSEXPLAINEXPLAIN PRIVATEEXPORTEXPORT PRIVATEF K CHECKSF K CHECKS ITEMF K CHECKS ITEM PRIVATEFAKE RELFAKE REL PRIVATEFALSEFETCH TEXTFETCH TEXT PATHFETCH VALFETCH VAL PATHFILTERSFILTERS ITEMFIRST AGGFIRST VALUEFLOOR DIVFULL JOINFUNCTIONFUNCTION PRIVATEGEGROUP BYGROUPING PRIVATEGTI LIKEIF ERRININDEX JOININDEX JOIN PRIVATEINDIRECTIONINNER JOININNER JOIN APPLYINSERTINTERSECTINTERSECT ALLINVERTED FILTERINVERTED FILTER PRIVATEINVERTED JOININVERTED JOIN PRIVATEISIS NOTIS TUPLE NOT NULLIS TUPLE NULLJOIN PRIVATEJSON AGGJSON ALL EXISTSJSON EXISTSJSON OBJECT AGGJSON SOME EXISTSJSONB AGGJSONB OBJECT AGGK V OPTIONSK V OPTIONS ITEML SHIFTLAGLAST VALUELELEADLEFT JOINLEFT JOIN APPLYLIKELIMITLOOKUP JOINLOOKUP JOIN PRIVATELTMAXMAX1 ROWMERGE JOINMERGE JOIN PRIVATEMINMINUSMODMULTMUTATION PRIVATENENOTNOT I LIKENOT INNOT LIKENOT REG I MATCHNOT REG MATCHNOT SIMILAR TONTH VALUENTILENULLOFFSETOPAQUE D D LOPAQUE MUTATIONOPAQUE RELOPAQUE REL PRIVATEORORDINALITYORDINALITY PRIVATEOVERLAPSPERCENT RANKPERCENTILE CONTPERCENTILE DISCPLACEHOLDERPLUSPOWPROJECTPROJECT SETPROJECTIONSPROJECTIONS ITEMR SHIFTRANGERANKRECURSIVE C T ERECURSIVE C T E PRIVATEREG I MATCHREG MATCHREGRESSION AVG XREGRESSION AVG YREGRESSION COUNTREGRESSION INTERCEPTREGRESSION R2REGRESSION S X XREGRESSION S X YREGRESSION S Y YREGRESSION SLOPERIGHT JOINROW NUMBERS T COLLECTS T EXTENTS T MAKE LINES T UNIONSCALAR GROUP BYSCALAR LISTSCANSCAN PRIVATESELECTSEMI JOINSEMI JOIN APPLYSEQUENCE SELECTSEQUENCE SELECT PRIVATESET PRIVATESHOW TRACE FOR SESSIONSHOW TRACE PRIVATESIMILAR TOSORTSQR DIFFSTD DEVSTD DEV POPSTRING AGGSUBQUERYSUBQUERY PRIVATESUMSUM INTTRUETUPLEUNARY CBRTUNARY COMPLEMENTUNARY MINUSUNARY SQRTUNIONUNION ALLUNIQUE CHECKSUNIQUE CHECKS ITEMUNIQUE CHECKS ITEM PRIVATEUNSUPPORTED EXPRUPDATEUPSERTUPSERT DISTINCT ONVALUESVALUES PRIVATEVAR POPVARIABLEVARIANCEWHENWINDOWWINDOW FROM OFFSETWINDOW PRIVATEWINDOW TO OFFSETWINDOWSWINDOWS ITEMWINDOWS ITEM PRIVATEWITHWITH PRIVATEWITH SCANWITH SCAN PRIVATEXOR AGGZIGZAG JOINZIGZAG JOIN PRIVATEZIPZIP ITEM"
+const opSyntaxTags = "UNKNOWNAGG DISTINCTAGG FILTERAGGREGATIONSAGGREGATIONS ITEMALTER TABLE RELOCATEALTER TABLE RELOCATE PRIVATEALTER TABLE SPLITALTER TABLE SPLIT PRIVATEALTER TABLE UNSPLITALTER TABLE UNSPLIT ALLANDANTI JOINANTI JOIN APPLYANYANY NOT NULL AGGANY SCALARARRAYARRAY AGGARRAY FLATTENAVGB BOX COVERSB BOX INTERSECTSBIT AND AGGBIT OR AGGBITANDBITORBITXORBOOL ANDBOOL ORCANCEL PRIVATECANCEL QUERIESCANCEL SESSIONSCASECASTCOALESCECOLLATECOLUMN ACCESSCONCATCONCAT AGGCONSTCONST AGGCONST NOT NULL AGGCONTAINSCONTROL JOBSCONTROL JOBS PRIVATECONTROL SCHEDULESCONTROL SCHEDULES PRIVATECORRCOUNTCOUNT ROWSCOVAR POPCOVAR SAMPCREATE STATISTICSCREATE STATISTICS PRIVATECREATE TABLECREATE TABLE PRIVATECREATE VIEWCREATE VIEW PRIVATECUME DISTDELETEDENSE RANKDISTINCT ONDIVENSURE DISTINCT ONENSURE UPSERT DISTINCT ONEQEXCEPTEXCEPT ALLEXISTSEXPLAINEXPLAIN PRIVATEEXPORTEXPORT PRIVATEF K CHECKSF K CHECKS ITEMF K CHECKS ITEM PRIVATEFAKE RELFAKE REL PRIVATEFALSEFETCH TEXTFETCH TEXT PATHFETCH VALFETCH VAL PATHFILTERSFILTERS ITEMFIRST AGGFIRST VALUEFLOOR DIVFULL JOINFUNCTIONFUNCTION PRIVATEGEGROUP BYGROUPING PRIVATEGROUPING SETSGROUPING SETS PRIVATEGTI LIKEIF ERRININDEX JOININDEX JOIN PRIVATEINDIRECTIONINNER JOININNER JOIN APPLYINSERTINTERSECTINTERSECT ALLINVERTED FILTERINVERTED FILTER PRIVATEINVERTED JOININVERTED JOIN PRIVATEISIS NOTIS TUPLE NOT NULLIS TUPLE NULLJOIN PRIVATEJSON AGGJSON ALL EXISTSJSON EXISTSJSON OBJECT AGGJSON SOME EXISTSJSONB AGGJSONB OBJECT AGGK V OPTIONSK V OPTIONS ITEML SHIFTLAGLAST VALUELELEADLEFT JOINLEFT JOIN APPLYLIKELIMITLOOKUP JOINLOOKUP JOIN PRIVATELTMAXMAX1 ROWMERGE JOINMERGE JOIN PRIVATEMINMINUSMODMULTMUTATION PRIVATENENOTNOT I LIKENOT INNOT LIKENOT REG I MATCHNOT REG MATCHNOT SIMILAR TONTH VALUENTILENULLOFFSETOPAQUE D D LOPAQUE MUTATIONOPAQUE RELOPAQUE REL PRIVATEORORDINALITYORDINALITY PRIVATEOVERLAPSPERCENT RANKPERCENTILE CONTPERCENTILE DISCPLACEHOLDERPLUSPOWPROJECTPROJECT SETPROJECTIONSPROJECTIONS ITEMR SHIFTRANGERANKRECURSIVE C T ERECURSIVE C T E PRIVATEREG I MATCHREG MATCHREGRESSION AVG XREGRESSION AVG YREGRESSION COUNTREGRESSION INTERCEPTREGRESSION R2REGRESSION S X XREGRESSION S X YREGRESSION S Y YREGRESSION SLOPERIGHT JOINROW NUMBERS T COLLECTS T EXTENTS T MAKE LINES T UNIONSCALAR GROUP BYSCALAR LISTSCANSCAN PRIVATESELECTSEMI JOINSEMI JOIN APPLYSEQUENCE SELECTSEQUENCE SELECT PRIVATESET PRIVATESHOW TRACE FOR SESSIONSHOW TRACE PRIVATESIMILAR TOSORTSQR DIFFSTD DEVSTD DEV POPSTRING AGGSUBQUERYSUBQUERY PRIVATESUMSUM INTT S MATCHESTRUETUPLEUNARY CBRTUNARY COMPLEMENTUNARY MINUSUNARY SQRTUNIONUNION ALLUNIQUE CHECKSUNIQUE CHECKS ITEMUNIQUE CHECKS ITEM PRIVATEUNSUPPORTED EXPRUPDATEUPSERTUPSERT DISTINCT ONVALUESVALUES PRIVATEVAR POPVARIABLEVARIANCEWHENWINDOWWINDOW FROM OFFSETWINDOW PRIVATEWINDOW TO OFFSETWINDOWSWINDOWS ITEMWINDOWS ITEM PRIVATEWITHWITH PRIVATEWITH SCANWITH SCAN PRIVATEXOR AGGZIGZAG JOINZIGZAG JOIN PRIVATEZIPZIP ITEM"
 
-var opSyntaxTagIndexes = [...]uint32{0, 7, 19, 29, 41, 58, 78, 106, 123, 148, 167, 190, 193, 202, 217, 220, 236, 246, 251, 260, 273, 276, 288, 304, 315, 325, 331, 336, 342, 350, 357, 371, 385, 400, 404, 408, 416, 423, 436, 442, 452, 457, 466, 484, 492, 504, 524, 541, 566, 570, 575, 585, 594, 604, 621, 646, 658, 678, 689, 708, 717, 723, 733, 744, 747, 765, 790, 792, 798, 808, 814, 821, 836, 842, 856, 866, 881, 904, 912, 928, 933, 943, 958, 967, 981, 988, 1000, 1009, 1020, 1029, 1038, 1046, 1062, 1064, 1072, 1088, 1090, 1096, 1102, 1104, 1114, 1132, 1143, 1153, 1169, 1175, 1184, 1197, 1212, 1235, 1248, 1269, 1271, 1277, 1294, 1307, 1319, 1327, 1342, 1353, 1368, 1384, 1393, 1409, 1420, 1436, 1443, 1446, 1456, 1458, 1462, 1471, 1486, 1490, 1495, 1506, 1525, 1527, 1530, 1538, 1548, 1566, 1569, 1574, 1577, 1581, 1597, 1599, 1602, 1612, 1618, 1626, 1641, 1654, 1668, 1677, 1682, 1686, 1692, 1704, 1719, 1729, 1747, 1749, 1759, 1777, 1785, 1797, 1812, 1827, 1838, 1842, 1845, 1852, 1863, 1874, 1890, 1897, 1902, 1906, 1921, 1944, 1955, 1964, 1980, 1996, 2012, 2032, 2045, 2061, 2077, 2093, 2109, 2119, 2129, 2140, 2150, 2163, 2172, 2187, 2198, 2202, 2214, 2220, 2229, 2244, 2259, 2282, 2293, 2315, 2333, 2343, 2347, 2355, 2362, 2373, 2383, 2391, 2407, 2410, 2417, 2421, 2426, 2436, 2452, 2463, 2473, 2478, 2487, 2500, 2518, 2544, 2560, 2566, 2572, 2590, 2596, 2610, 2617, 2625, 2633, 2637, 2643, 2661, 2675, 2691, 2698, 2710, 2730, 2734, 2746, 2755, 2772, 2779, 2790, 2809, 2812, 2820}
+var opSyntaxTagIndexes = [...]uint32{0, 7, 19, 29, 41, 58, 78, 106, 123, 148, 167, 190, 193, 202, 217, 220, 236, 246, 251, 260, 273, 276, 288, 304, 315, 325, 331, 336, 342, 350, 357, 371, 385, 400, 404, 408, 416, 423, 436, 442, 452, 457, 466, 484, 492, 504, 524, 541, 566, 570, 575, 585, 594, 604, 621, 646, 658, 678, 689, 708, 717, 723, 733, 744, 747, 765, 790, 792, 798, 808, 814, 821, 836, 842, 856, 866, 881, 904, 912, 928, 933, 943, 958, 967, 981, 988, 1000, 1009, 1020, 1029, 1038, 1046, 1062, 1064, 1072, 1088, 1101, 1122, 1124, 1130, 1136, 1138, 1148, 1166, 1177, 1187, 1203, 1209, 1218, 1231, 1246, 1269, 1282, 1303, 1305, 1311, 1328, 1341, 1353, 1361, 1376, 1387, 1402, 1418, 1427, 1443, 1454, 1470, 1477, 1480, 1490, 1492, 1496, 1505, 1520, 1524, 1529, 1540, 1559, 1561, 1564, 1572, 1582, 1600, 1603, 1608, 1611, 1615, 1631, 1633, 1636, 1646, 1652, 1660, 1675, 1688, 1702, 1711, 1716, 1720, 1726, 1738, 1753, 1763, 1781, 1783, 1793, 1811, 1819, 1831, 1846, 1861, 1872, 1876, 1879, 1886, 1897, 1908, 1924, 1931, 1936, 1940, 1955, 1978, 1989, 1998, 2014, 2030, 2046, 2066, 2079, 2095, 2111, 2127, 2143, 2153, 2163, 2174, 2184, 2197, 2206, 2221, 2232, 2236, 2248, 2254, 2263, 2278, 2293, 2316, 2327, 2349, 2367, 2377, 2381, 2389, 2396, 2407, 2417, 2425, 2441, 2444, 2451, 2462, 2466, 2471, 2481, 2497, 2508, 2518, 2523, 2532, 2545, 2563, 2589, 2605, 2611, 2617, 2635, 2641, 2655, 2662, 2670, 2678, 2682, 2688, 2706, 2720, 2736, 2743, 2755, 2775, 2779, 2791, 2800, 2817, 2824, 2835, 2854, 2857, 2865}
 
 var EnforcerOperators = [...]Operator{
 	SortOp,
@@ -1279,6 +1303,7 @@ var TelemetryOperators = [...]Operator{
 	EnsureDistinctOnOp,
 	EnsureUpsertDistinctOnOp,
 	GroupByOp,
+	GroupingSetsOp,
 	InnerJoinApplyOp,
 	LeftJoinApplyOp,
 	ProjectSetOp,
@@ -1292,8 +1317,8 @@ var TelemetryOperators = [...]Operator{
 func IsTelemetryOp(e Expr) bool {
 	switch e.Op() {
 	case AntiJoinApplyOp, DistinctOnOp, EnsureDistinctOnOp, EnsureUpsertDistinctOnOp,
-		GroupByOp, InnerJoinApplyOp, LeftJoinApplyOp, ProjectSetOp, ScalarGroupByOp,
-		SemiJoinApplyOp, SortOp, UpsertDistinctOnOp, ZigzagJoinOp:
+		GroupByOp, GroupingSetsOp, InnerJoinApplyOp, LeftJoinApplyOp, ProjectSetOp,
+		ScalarGroupByOp, SemiJoinApplyOp, SortOp, UpsertDistinctOnOp, ZigzagJoinOp:
 		return true
 	}
 	return false
@@ -1324,6 +1349,7 @@ var RelationalOperators = [...]Operator{
 	FakeRelOp,
 	FullJoinOp,
 	GroupByOp,
+	GroupingSetsOp,
 	IndexJoinOp,
 	InnerJoinOp,
 	InnerJoinApplyOp,
@@ -1373,14 +1399,15 @@ func IsRelationalOp(e Expr) bool {
 		ControlSchedulesOp, CreateStatisticsOp, CreateTableOp, CreateViewOp, DeleteOp,
 		DistinctOnOp, EnsureDistinctOnOp, EnsureUpsertDistinctOnOp, ExceptOp, ExceptAllOp,
 		ExplainOp, ExportOp, FakeRelOp, FullJoinOp, GroupByOp,
-		IndexJoinOp, InnerJoinOp, InnerJoinApplyOp, InsertOp, IntersectOp,
-		IntersectAllOp, InvertedFilterOp, InvertedJoinOp, LeftJoinOp, LeftJoinApplyOp,
-		LimitOp, LookupJoinOp, Max1RowOp, MergeJoinOp, OffsetOp,
-		OpaqueDDLOp, OpaqueMutationOp, OpaqueRelOp, OrdinalityOp, ProjectOp,
-		ProjectSetOp, RecursiveCTEOp, RightJoinOp, ScalarGroupByOp, ScanOp,
-		SelectOp, SemiJoinOp, SemiJoinApplyOp, SequenceSelectOp, ShowTraceForSessionOp,
-		UnionOp, UnionAllOp, UpdateOp, UpsertOp, UpsertDistinctOnOp,
-		ValuesOp, WindowOp, WithOp, WithScanOp, ZigzagJoinOp:
+		GroupingSetsOp, IndexJoinOp, InnerJoinOp, InnerJoinApplyOp, InsertOp,
+		IntersectOp, IntersectAllOp, InvertedFilterOp, InvertedJoinOp, LeftJoinOp,
+		LeftJoinApplyOp, LimitOp, LookupJoinOp, Max1RowOp, MergeJoinOp,
+		OffsetOp, OpaqueDDLOp, OpaqueMutationOp, OpaqueRelOp, OrdinalityOp,
+		ProjectOp, ProjectSetOp, RecursiveCTEOp, RightJoinOp, ScalarGroupByOp,
+		ScanOp, SelectOp, SemiJoinOp, SemiJoinApplyOp, SequenceSelectOp,
+		ShowTraceForSessionOp, UnionOp, UnionAllOp, UpdateOp, UpsertOp,
+		UpsertDistinctOnOp, ValuesOp, WindowOp, WithOp, WithScanOp,
+		ZigzagJoinOp:
 		return true
 	}
 	return false
@@ -1444,6 +1471,7 @@ var PrivateOperators = [...]Operator{
 	FakeRelPrivateOp,
 	FunctionPrivateOp,
 	GroupingPrivateOp,
+	GroupingSetsPrivateOp,
 	IndexJoinPrivateOp,
 	InvertedFilterPrivateOp,
 	InvertedJoinPrivateOp,
@@ -1473,11 +1501,11 @@ func IsPrivateOp(e Expr) bool {
 	case AlterTableRelocatePrivateOp, AlterTableSplitPrivateOp, CancelPrivateOp, ControlJobsPrivateOp,
 		ControlSchedulesPrivateOp, CreateStatisticsPrivateOp, CreateTablePrivateOp, CreateViewPrivateOp, ExplainPrivateOp,
 		ExportPrivateOp, FKChecksItemPrivateOp, FakeRelPrivateOp, FunctionPrivateOp, GroupingPrivateOp,
-		IndexJoinPrivateOp, InvertedFilterPrivateOp, InvertedJoinPrivateOp, JoinPrivateOp, LookupJoinPrivateOp,
-		MergeJoinPrivateOp, MutationPrivateOp, OpaqueRelPrivateOp, OrdinalityPrivateOp, RecursiveCTEPrivateOp,
-		ScanPrivateOp, SequenceSelectPrivateOp, SetPrivateOp, ShowTracePrivateOp, SubqueryPrivateOp,
-		UniqueChecksItemPrivateOp, ValuesPrivateOp, WindowPrivateOp, WindowsItemPrivateOp, WithPrivateOp,
-		WithScanPrivateOp, ZigzagJoinPrivateOp:
+		GroupingSetsPrivateOp, IndexJoinPrivateOp, InvertedFilterPrivateOp, InvertedJoinPrivateOp, JoinPrivateOp,
+		LookupJoinPrivateOp, MergeJoinPrivateOp, MutationPrivateOp, OpaqueRelPrivateOp, OrdinalityPrivateOp,
+		RecursiveCTEPrivateOp, ScanPrivateOp, SequenceSelectPrivateOp, SetPrivateOp, ShowTracePrivateOp,
+		SubqueryPrivateOp, UniqueChecksItemPrivateOp, ValuesPrivateOp, WindowPrivateOp, WindowsItemPrivateOp,
+		WithPrivateOp, WithScanPrivateOp, ZigzagJoinPrivateOp:
 		return true
 	}
 	return false
@@ -1619,6 +1647,7 @@ var ScalarOperators = [...]Operator{
 	SubqueryOp,
 	SumOp,
 	SumIntOp,
+	TSMatchesOp,
 	TrueOp,
 	TupleOp,
 	UnaryCbrtOp,
@@ -1670,11 +1699,11 @@ func IsScalarOp(e Expr) bool {
 		RegressionSYYOp, RegressionSlopeOp, RowNumberOp, STCollectOp, STExtentOp,
 		STMakeLineOp, STUnionOp, ScalarListOp, SimilarToOp, SqrDiffOp,
 		StdDevOp, StdDevPopOp, StringAggOp, SubqueryOp, SumOp,
-		SumIntOp, TrueOp, TupleOp, UnaryCbrtOp, UnaryComplementOp,
-		UnaryMinusOp, UnarySqrtOp, UniqueChecksOp, UniqueChecksItemOp, UnsupportedExprOp,
-		VarPopOp, VariableOp, VarianceOp, WhenOp, WindowFromOffsetOp,
-		WindowToOffsetOp, WindowsOp, WindowsItemOp, XorAggOp, ZipOp,
-		ZipItemOp:
+		SumIntOp, TSMatchesOp, TrueOp, TupleOp, UnaryCbrtOp,
+		UnaryComplementOp, UnaryMinusOp, UnarySqrtOp, UniqueChecksOp, UniqueChecksItemOp,
+		UnsupportedExprOp, VarPopOp, VariableOp, VarianceOp, WhenOp,
+		WindowFromOffsetOp, WindowToOffsetOp, WindowsOp, WindowsItemOp, XorAggOp,
+		ZipOp, ZipItemOp:
 		return true
 	}
 	return false
@@ -1853,6 +1882,7 @@ var BoolOperators = [...]Operator{
 	RegIMatchOp,
 	RegMatchOp,
 	SimilarToOp,
+	TSMatchesOp,
 	TrueOp,
 }
 
@@ -1866,7 +1896,7 @@ func IsBoolOp(e Expr) bool {
 		LtOp, NeOp, NotOp, NotILikeOp, NotInOp,
 		NotLikeOp, NotRegIMatchOp, NotRegMatchOp, NotSimilarToOp, OrOp,
 		OverlapsOp, RangeOp, RegIMatchOp, RegMatchOp, SimilarToOp,
-		TrueOp:
+		TSMatchesOp, TrueOp:
 		return true
 	}
 	return false
@@ -1969,6 +1999,7 @@ var ComparisonOperators = [...]Operator{
 	RegIMatchOp,
 	RegMatchOp,
 	SimilarToOp,
+	TSMatchesOp,
 }
 
 func IsComparisonOp(e Expr) bool {
@@ -1978,7 +2009,7 @@ func IsComparisonOp(e Expr) bool {
 		IsNotOp, JsonAllExistsOp, JsonExistsOp, JsonSomeExistsOp, LeOp,
 		LikeOp, LtOp, NeOp, NotILikeOp, NotInOp,
 		NotLikeOp, NotRegIMatchOp, NotRegMatchOp, NotSimilarToOp, OverlapsOp,
-		RegIMatchOp, RegMatchOp, SimilarToOp:
+		RegIMatchOp, RegMatchOp, SimilarToOp, TSMatchesOp:
 		return true
 	}
 	return false
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
@@ -186,6 +187,15 @@ func (b *Builder) Build() (err error) {
 	}
 	b.semaCtx.TypeResolver = typeTracker
 
+	// Similarly, hijack the FunctionResolver to record all of the user defined
+	// functions that we resolve while building this query.
+	existingFuncResolver := b.semaCtx.FunctionResolver
+	defer func() { b.semaCtx.FunctionResolver = existingFuncResolver }()
+	b.semaCtx.FunctionResolver = &optTrackingFunctionResolver{
+		catalog:  b.catalog,
+		metadata: b.factory.Metadata(),
+	}
+
 	// Special case for CannedOptPlan.
 	if canned, ok := b.stmt.(*tree.CannedOptPlan); ok {
 		b.factory.DisableOptimizations()
@@ -434,3 +444,25 @@ func (o *optTrackingTypeResolver) ResolveTypeByOID(
 	o.metadata.AddUserDefinedType(typ)
 	return typ, nil
 }
+
+// optTrackingFunctionResolver is a FunctionReferenceResolver that resolves
+// user defined functions using the catalog, and remembers all of them in the
+// provided Metadata.
+type optTrackingFunctionResolver struct {
+	catalog  cat.Catalog
+	metadata *opt.Metadata
+}
+
+// ResolveFunction implements the FunctionReferenceResolver interface. The
+// catalog resolves names using the search path of the session, which is the
+// one passed in by the semaCtx.
+func (o *optTrackingFunctionResolver) ResolveFunction(
+	ctx context.Context, name *tree.UnresolvedName, _ sessiondata.SearchPath,
+) (*tree.FunctionDefinition, error) {
+	def, err := o.catalog.ResolveFunction(ctx, name)
+	if err != nil || def == nil {
+		return nil, err
+	}
+	o.metadata.AddUserDefinedFunction(name, def)
+	return def, nil
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
+)
+
+// This file contains the functions that build the checks of deferrable
+// constraints which were deferred until the end of a transaction.
+//
+// When the FK or unique check of a statement finds violations of a deferred
+// constraint, only the key values of the violating rows are remembered. Before
+// the transaction commits, the check is built again for the rows of the table
+// that still have these key values, using the same fkCheckHelper and
+// uniqueCheckHelper that build the checks of an insert. The input of the check
+// is bound to a With expression, in place of the mutation input:
+//
+//   with &1
+//    ├── semi-join (hash)
+//    │    ├── scan child
+//    │    ├── values
+//    │    │    └── (1,)
+//    │    └── filters
+//    │         └── child.p = column1
+//    └── anti-join (hash)
+//         ├── with-scan &1
+//         │    └── mapping:
+//         │         └──  child.p => p
+//         ├── scan parent
+//         └── filters
+//              └── p = parent.p
+//
+
+// BuildDeferredFKCheck builds the check of the FK constraint with the given
+// name on the origin table, for the rows of the origin table whose FK columns
+// have one of the given key values. It is the check that an insert of these
+// rows would run; the rows it returns still violate the constraint.
+//
+// Violations found by mutations of the referenced table are checked in the
+// same way: they exist as long as a row of the origin table has no match in
+// the referenced table.
+//
+// Returns ok=false if there is nothing to check, e.g. because the constraint
+// was dropped.
+func BuildDeferredFKCheck(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factory *norm.Factory,
+	originTableID cat.StableID,
+	fkName string,
+	keyVals []tree.Datums,
+) (check memo.FKChecksItem, ok bool, err error) {
+	err = buildDeferredCheckHelper(ctx, semaCtx, evalCtx, catalog, factory, func(b *Builder) {
+		tab := resolveTable(ctx, catalog, originTableID)
+		if tab == nil {
+			return
+		}
+		fkOrdinal := -1
+		for i, n := 0, tab.OutboundForeignKeyCount(); i < n; i++ {
+			if tab.OutboundForeignKey(i).Name() == fkName {
+				fkOrdinal = i
+				break
+			}
+		}
+		if fkOrdinal == -1 {
+			return
+		}
+		fk := tab.OutboundForeignKey(fkOrdinal)
+		keyOrdinals := make([]int, fk.ColumnCount())
+		for i := range keyOrdinals {
+			keyOrdinals[i] = fk.OriginColumnOrdinal(tab, i)
+		}
+
+		var mb mutationBuilder
+		mb.init(b, "insert", tab, tree.MakeUnqualifiedTableName(tab.Name()))
+		mb.buildDeferredCheckInput(keyOrdinals, keyVals)
+
+		h := &mb.fkCheckHelper
+		if !h.initWithOutboundFK(&mb, fkOrdinal) {
+			return
+		}
+		c := h.buildInsertionCheck()
+		check = b.factory.ConstructFKChecksItem(mb.wrapDeferredCheck(c.Check), &c.FKChecksItemPrivate)
+		ok = true
+	})
+	return check, ok, err
+}
+
+// BuildDeferredUniqueCheck builds the check of the unique constraint with the
+// given name, for the rows of the table whose unique columns have one of the
+// given key values. It is the check that an insert of these rows would run;
+// the rows it returns still violate the constraint.
+//
+// Returns ok=false if there is nothing to check, e.g. because the constraint
+// was dropped.
+func BuildDeferredUniqueCheck(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factory *norm.Factory,
+	tableID cat.StableID,
+	uniqueName string,
+	keyVals []tree.Datums,
+) (check memo.UniqueChecksItem, ok bool, err error) {
+	err = buildDeferredCheckHelper(ctx, semaCtx, evalCtx, catalog, factory, func(b *Builder) {
+		tab := resolveTable(ctx, catalog, tableID)
+		if tab == nil {
+			return
+		}
+		uniqueOrdinal := -1
+		for i, n := 0, tab.UniqueCount(); i < n; i++ {
+			if u := tab.Unique(i); u.WithoutIndex() && u.Name() == uniqueName {
+				uniqueOrdinal = i
+				break
+			}
+		}
+		if uniqueOrdinal == -1 {
+			return
+		}
+		u := tab.Unique(uniqueOrdinal)
+		keyOrdinals := make([]int, u.ColumnCount())
+		for i := range keyOrdinals {
+			keyOrdinals[i] = u.ColumnOrdinal(tab, i)
+		}
+
+		var mb mutationBuilder
+		mb.init(b, "insert", tab, tree.MakeUnqualifiedTableName(tab.Name()))
+		mb.buildDeferredCheckInput(keyOrdinals, keyVals)
+
+		h := &mb.uniqueCheckHelper
+		if !h.init(&mb, uniqueOrdinal) {
+			return
+		}
+		c := h.buildInsertionCheck()
+		check = b.factory.ConstructUniqueChecksItem(
+			mb.wrapDeferredCheck(c.Check), &c.UniqueChecksItemPrivate,
+		)
+		ok = true
+	})
+	return check, ok, err
+}
+
+// buildDeferredCheckInput sets the input of the mutation builder to the rows
+// of its table whose columns with the given ordinals have one of the given key
+// values. The input is a semi-join of the table with a Values expression:
+//
+//   SELECT * FROM tab WHERE (a, b) IN (VALUES (key_a, key_b), ...)
+//
+// The input is bound to a With expression, so that the checks can refer to it
+// in the same way as they refer to the input of a mutation.
+func (mb *mutationBuilder) buildDeferredCheckInput(keyOrdinals []int, keyVals []tree.Datums) {
+	b := mb.b
+	f := b.factory
+	outScope := b.buildScan(
+		b.addTable(mb.tab, &mb.alias),
+		tableOrdinals(mb.tab, columnKinds{
+			includeMutations:       false,
+			includeSystem:          false,
+			includeVirtualInverted: false,
+			includeVirtualComputed: false,
+		}),
+		nil, /* indexFlags */
+		noRowLocking,
+		b.allocScope(),
+	)
+
+	valuesScope := b.allocScope()
+	colTypes := make([]*types.T, len(keyOrdinals))
+	for i, tabOrd := range keyOrdinals {
+		colTypes[i] = mb.tab.Column(tabOrd).DatumType()
+		b.synthesizeColumn(valuesScope, string(mb.tab.Column(tabOrd).ColName()), colTypes[i], nil, nil /* scalar */)
+	}
+	tupleTyp := types.MakeTuple(colTypes)
+	rows := make(memo.ScalarListExpr, len(keyVals))
+	for i, vals := range keyVals {
+		elems := make(memo.ScalarListExpr, len(vals))
+		for j := range vals {
+			elems[j] = f.ConstructConstVal(vals[j], colTypes[j])
+		}
+		rows[i] = f.ConstructTuple(elems, tupleTyp)
+	}
+	valuesScope.expr = f.ConstructValues(rows, &memo.ValuesPrivate{
+		Cols: colsToColList(valuesScope.cols),
+		ID:   f.Metadata().NextUniqueID(),
+	})
+
+	on := make(memo.FiltersExpr, len(keyOrdinals))
+	for i, tabOrd := range keyOrdinals {
+		col := outScope.getColumnForTableOrdinal(tabOrd)
+		on[i] = f.ConstructFiltersItem(f.ConstructEq(
+			f.ConstructVariable(col.id),
+			f.ConstructVariable(valuesScope.cols[i].id),
+		))
+	}
+	outScope.expr = f.ConstructSemiJoin(outScope.expr, valuesScope.expr, on, memo.EmptyJoinPrivate)
+
+	mb.fetchScope = outScope
+	mb.outScope = outScope
+	mb.setFetchColIDs(outScope.cols)
+	mb.ensureWithID()
+}
+
+// wrapDeferredCheck returns a With expression which binds the input built by
+// buildDeferredCheckInput for the given check.
+func (mb *mutationBuilder) wrapDeferredCheck(check memo.RelExpr) memo.RelExpr {
+	return mb.b.factory.ConstructWith(mb.outScope.expr, check, &memo.WithPrivate{
+		ID:   mb.withID,
+		Name: "deferred-check-input",
+	})
+}
+
+// buildDeferredCheckHelper creates a Builder, sets up panic-to-error
+// conversion, and executes the given function, like buildCascadeHelper.
+func buildDeferredCheckHelper(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factory *norm.Factory,
+	fn func(b *Builder),
+) (err error) {
+	b := New(ctx, semaCtx, evalCtx, catalog, factory, nil /* stmt */)
+
+	// Enact panic handling similar to Builder.Build().
+	defer func() {
+		if r := recover(); r != nil {
+			if ok, e := errorutil.ShouldCatch(r); ok {
+				err = e
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	fn(b)
+	return nil
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
 )
 
@@ -100,6 +101,61 @@ type groupby struct {
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
 	buildingGroupingCols bool
+
+	// groupingSets is set if the GROUP BY clause uses ROLLUP, CUBE or GROUPING
+	// SETS to specify more than one grouping set.
+	groupingSets *groupingSets
+}
+
+// maxGroupingSets is the maximum number of grouping sets that a GROUP BY
+// clause can specify. It matches the limit in Postgres.
+const maxGroupingSets = 4096
+
+// maxCubeElements is the maximum number of elements in a CUBE, which expands
+// to 2^n grouping sets. It matches the limit in Postgres.
+const maxCubeElements = 12
+
+// groupingSets stores information about an aggregation with multiple grouping
+// sets.
+//
+// We build such an aggregation as a single GroupingSets operator, which
+// computes all grouping sets in one pass over the pre-projection. Grouping
+// columns that are not part of every grouping set are projected as new
+// columns, which are NULL for the rows of the grouping sets they are not part
+// of, and the index of each row's grouping set is projected as an additional
+// column. For example:
+//
+//   SELECT a, b, sum(c) FROM abc GROUP BY ROLLUP (a, b)
+//
+//   pre-projection: a, b, c
+//   aggregation:    grouping sets (a, b), (a), () over a, b,
+//                   projecting a', b', sum(c), id
+//
+// The aggregate functions see the original values of the grouping columns;
+// only references outside of aggregate functions are remapped to the
+// NULL-extended columns.
+//
+// Aggregations that are ordering sensitive are built as window functions (see
+// buildAggregationAsWindow), which cannot be computed for multiple grouping
+// sets at once. In that case, the pre-projection is instead expanded so that
+// every input row is repeated once per grouping set (see
+// constructGroupingSetsInput).
+type groupingSets struct {
+	// sets contains, for each grouping set, the ordinals of the grouping
+	// columns (see groupby.groupingCols) that are part of the set.
+	sets []util.FastIntSet
+
+	// cols contains the grouping columns.
+	cols opt.ColList
+
+	// outCols contains, for each grouping column, the corresponding column
+	// produced by the aggregation. It is a new column if the grouping column is
+	// not part of every grouping set, or the grouping column itself otherwise.
+	outCols opt.ColList
+
+	// idCol is the grouping column that contains the ID of the grouping set
+	// each row belongs to. The ID is the grouping set's index in sets.
+	idCol opt.ColumnID
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -134,6 +190,17 @@ func (g *groupby) groupingCols() []scopeColumn {
 	return g.aggInScope.cols[len(g.aggInScope.cols)-len(g.groupStrs):]
 }
 
+// groupingColOrdinal returns the ordinal of the grouping column with the given
+// ID in groupingCols.
+func (g *groupby) groupingColOrdinal(id opt.ColumnID) int {
+	for i, col := range g.groupingCols() {
+		if col.id == id {
+			return i
+		}
+	}
+	panic(errors.AssertionFailedf("column %d is not a grouping column", id))
+}
+
 // getAggregateArgCols returns the columns in the aggInScope corresponding to
 // arguments to aggregate functions. If the aggregate has a filter, the column
 // corresponding to the filter's input will immediately follow the arguments.
@@ -292,6 +359,43 @@ func (b *Builder) needsAggregation(sel *tree.SelectClause, scope *scope) bool {
 func (b *Builder) constructGroupBy(
 	input memo.RelExpr, groupingColSet opt.ColSet, aggCols []scopeColumn, ordering opt.Ordering,
 ) memo.RelExpr {
+	aggs := b.constructAggregations(aggCols)
+	private := memo.GroupingPrivate{GroupingCols: groupingColSet}
+
+	// The ordering of the GROUP BY is inherited from the input. This ordering is
+	// only useful for intra-group ordering (for order-sensitive aggregations like
+	// ARRAY_AGG). So we add the grouping columns as optional columns.
+	private.Ordering.FromOrderingWithOptCols(ordering, groupingColSet)
+
+	if groupingColSet.Empty() {
+		return b.factory.ConstructScalarGroupBy(input, aggs, &private)
+	}
+	return b.factory.ConstructGroupBy(input, aggs, &private)
+}
+
+// constructGroupingSets constructs a GroupingSets operator that computes the
+// aggregations in aggCols for each of the grouping sets in gs.
+func (b *Builder) constructGroupingSets(
+	input memo.RelExpr, gs *groupingSets, aggCols []scopeColumn, ordering opt.Ordering,
+) memo.RelExpr {
+	private := memo.GroupingSetsPrivate{
+		GroupingCols: gs.cols,
+		OutCols:      gs.outCols,
+		Sets:         make(memo.ColSets, len(gs.sets)),
+		SetIDCol:     gs.idCol,
+	}
+	for i, set := range gs.sets {
+		set.ForEach(func(ord int) {
+			private.Sets[i].Add(gs.cols[ord])
+		})
+	}
+	private.Ordering.FromOrderingWithOptCols(ordering, gs.cols.ToSet())
+	return b.factory.ConstructGroupingSets(input, b.constructAggregations(aggCols), &private)
+}
+
+// constructAggregations constructs the aggregations of a grouping operator
+// from the given aggregate columns.
+func (b *Builder) constructAggregations(aggCols []scopeColumn) memo.AggregationsExpr {
 	aggs := make(memo.AggregationsExpr, 0, len(aggCols))
 
 	// Deduplicate the columns; we don't need to produce the same aggregation
@@ -308,18 +412,7 @@ func (b *Builder) constructGroupBy(
 			colSet.Add(id)
 		}
 	}
-
-	private := memo.GroupingPrivate{GroupingCols: groupingColSet}
-
-	// The ordering of the GROUP BY is inherited from the input. This ordering is
-	// only useful for intra-group ordering (for order-sensitive aggregations like
-	// ARRAY_AGG). So we add the grouping columns as optional columns.
-	private.Ordering.FromOrderingWithOptCols(ordering, groupingColSet)
-
-	if groupingColSet.Empty() {
-		return b.factory.ConstructScalarGroupBy(input, aggs, &private)
-	}
-	return b.factory.ConstructGroupBy(input, aggs, &private)
+	return aggs
 }
 
 // buildGroupingColumns builds the grouping columns and adds them to the
@@ -336,6 +429,47 @@ func (b *Builder) buildGroupingColumns(sel *tree.SelectClause, projectionsScope,
 
 	// Copy the grouping columns to the aggOutScope.
 	g.aggOutScope.appendColumns(g.groupingCols())
+
+	if g.groupingSets != nil {
+		b.buildGroupingSetCols(g)
+	}
+}
+
+// buildGroupingSetCols synthesizes the output columns of an aggregation with
+// multiple grouping sets: a new column for each grouping column that is not
+// part of every grouping set, and the grouping set ID column. GROUP BY
+// expressions are remapped to the new columns, so that references to them
+// outside of aggregate functions observe NULL for the grouping sets they are
+// not part of.
+func (b *Builder) buildGroupingSetCols(g *groupby) {
+	gs := g.groupingSets
+	numCols := len(g.groupStrs)
+
+	var inAllSets util.FastIntSet
+	if numCols > 0 {
+		inAllSets.AddRange(0, numCols-1)
+	}
+	for _, set := range gs.sets {
+		inAllSets.IntersectionWith(set)
+	}
+
+	outCols := g.aggOutScope.cols[len(g.aggOutScope.cols)-numCols:]
+	gs.cols = make(opt.ColList, numCols)
+	gs.outCols = make(opt.ColList, numCols)
+	for i := range outCols {
+		gs.cols[i] = outCols[i].id
+		if !inAllSets.Contains(i) {
+			b.populateSynthesizedColumn(&outCols[i], nil /* scalar */)
+		}
+		gs.outCols[i] = outCols[i].id
+	}
+	for exprStr, col := range g.groupStrs {
+		g.groupStrs[exprStr] = &outCols[g.groupingColOrdinal(col.id)]
+	}
+
+	gs.idCol = b.synthesizeColumn(
+		g.aggOutScope, "grouping_set", types.Int, nil /* expr */, nil, /* scalar */
+	).id
 }
 
 // buildAggregation builds the aggregation operators and constructs the
@@ -350,6 +484,13 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	for i := range groupingCols {
 		groupingColSet.Add(groupingCols[i].id)
 	}
+	if gs := g.groupingSets; gs != nil {
+		for i, id := range gs.outCols {
+			groupingColSet.Remove(gs.cols[i])
+			groupingColSet.Add(id)
+		}
+		groupingColSet.Add(gs.idCol)
+	}
 
 	// If there are any aggregates that are ordering sensitive, build the
 	// aggregations as window functions over each group.
@@ -363,6 +504,7 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	haveOrderingSensitiveAgg := false
 	aggCols := g.aggregateResultCols()
 	argCols := g.aggregateArgCols()
+	filterCols := make([]opt.ColumnID, len(aggInfos))
 	var fromCols opt.ColSet
 	if b.subquery != nil {
 		// Only calculate the set of fromScope columns if it will be used below.
@@ -393,17 +535,11 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 			aggCols[i].scalar = b.factory.ConstructAggDistinct(aggCols[i].scalar)
 		}
 
-		// Wrap the aggregate function or the AggDistinct in an AggFilter operator
-		// if FILTER (WHERE ...) was specified in the query.
-		// TODO(justin): add a norm rule to push these filters below GroupBy where
-		// possible.
 		if agg.filter != nil {
 			// Column containing filter expression is always after the argument
 			// columns (which have already been processed).
-			colID := argCols[0].id
+			filterCols[i] = argCols[0].id
 			argCols = argCols[1:]
-			variable := b.factory.ConstructVariable(colID)
-			aggCols[i].scalar = b.factory.ConstructAggFilter(aggCols[i].scalar, variable)
 		}
 
 		if agg.isOrderingSensitive() {
@@ -430,12 +566,34 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// aggregate arguments, as well as any additional order by columns.
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
-	g.aggOutScope.expr = b.constructGroupBy(
-		g.aggInScope.expr.(memo.RelExpr),
-		groupingColSet,
-		aggCols,
-		g.aggInScope.ordering,
-	)
+	input := g.aggInScope.expr.(memo.RelExpr)
+
+	// Wrap the aggregate functions or the AggDistincts in an AggFilter operator
+	// if FILTER (WHERE ...) was specified in the query.
+	// TODO(justin): add a norm rule to push these filters below GroupBy where
+	// possible.
+	for i := range aggCols {
+		if filterCols[i] != 0 {
+			variable := b.factory.ConstructVariable(filterCols[i])
+			aggCols[i].scalar = b.factory.ConstructAggFilter(aggCols[i].scalar, variable)
+		}
+	}
+
+	if g.groupingSets != nil {
+		g.aggOutScope.expr = b.constructGroupingSets(
+			input,
+			g.groupingSets,
+			aggCols,
+			g.aggInScope.ordering,
+		)
+	} else {
+		g.aggOutScope.expr = b.constructGroupBy(
+			input,
+			groupingColSet,
+			aggCols,
+			g.aggInScope.ordering,
+		)
+	}
 
 	// Wrap with having filter if it exists.
 	if having != nil {
@@ -507,16 +665,112 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
+	sets := []util.FastIntSet{{}}
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		// The grouping sets of a GROUP BY clause are the cross product of the
+		// grouping sets of each element. For example:
+		//   GROUP BY a, ROLLUP (b, c)
+		// is equivalent to:
+		//   GROUP BY GROUPING SETS ((a, b, c), (a, b), (a))
+		elemSets := b.buildGroupingElement(e, selects, projectionsScope, fromScope)
+		if len(sets)*len(elemSets) > maxGroupingSets {
+			panic(errTooManyGroupingSets)
+		}
+		product := make([]util.FastIntSet, 0, len(sets)*len(elemSets))
+		for _, set := range sets {
+			for _, elemSet := range elemSets {
+				product = append(product, set.Union(elemSet))
+			}
+		}
+		sets = product
 	}
 	g.buildingGroupingCols = false
+
+	// An ordinary GROUP BY has a single grouping set containing all the
+	// grouping columns.
+	if len(sets) > 1 {
+		g.groupingSets = &groupingSets{sets: sets}
+	}
+}
+
+var errTooManyGroupingSets = pgerror.Newf(pgcode.StatementTooComplex,
+	"too many grouping sets present (maximum %d)", maxGroupingSets)
+
+// buildGroupingElement builds the grouping columns for an element of a GROUP
+// BY clause and returns the grouping sets specified by the element, as sets
+// of grouping column ordinals. A ROLLUP, CUBE or GROUPING SETS element
+// specifies one or more grouping sets; any other expression specifies a single
+// grouping set.
+func (b *Builder) buildGroupingElement(
+	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) []util.FastIntSet {
+	g := fromScope.groupby
+	gs, ok := groupBy.(*tree.GroupingSet)
+	if !ok {
+		return []util.FastIntSet{
+			b.buildGrouping(groupBy, selects, projectionsScope, fromScope, g.aggInScope),
+		}
+	}
+
+	switch gs.Type {
+	case tree.RollupGroupingSet:
+		// ROLLUP (a, b, c) is equivalent to:
+		//   GROUPING SETS ((a, b, c), (a, b), (a), ())
+		sets := make([]util.FastIntSet, len(gs.Exprs)+1)
+		for i, e := range gs.Exprs {
+			sets[i+1] = sets[i].Union(
+				b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope),
+			)
+		}
+		// List the grouping sets from the largest to the smallest.
+		for i, j := 0, len(sets)-1; i < j; i, j = i+1, j-1 {
+			sets[i], sets[j] = sets[j], sets[i]
+		}
+		return sets
+
+	case tree.CubeGroupingSet:
+		// CUBE (a, b) is equivalent to:
+		//   GROUPING SETS ((a, b), (a), (b), ())
+		if len(gs.Exprs) > maxCubeElements {
+			panic(pgerror.Newf(pgcode.TooManyColumns,
+				"CUBE is limited to %d elements", maxCubeElements))
+		}
+		elems := make([]util.FastIntSet, len(gs.Exprs))
+		for i, e := range gs.Exprs {
+			elems[i] = b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		}
+		n := len(elems)
+		sets := make([]util.FastIntSet, 0, 1<<n)
+		for mask := 1<<n - 1; mask >= 0; mask-- {
+			var set util.FastIntSet
+			for i := range elems {
+				if mask&(1<<(n-1-i)) != 0 {
+					set.UnionWith(elems[i])
+				}
+			}
+			sets = append(sets, set)
+		}
+		return sets
+
+	case tree.ExplicitGroupingSets:
+		var sets []util.FastIntSet
+		for _, e := range gs.Exprs {
+			sets = append(sets, b.buildGroupingElement(e, selects, projectionsScope, fromScope)...)
+			if len(sets) > maxGroupingSets {
+				panic(errTooManyGroupingSets)
+			}
+		}
+		return sets
+
+	default:
+		panic(errors.AssertionFailedf("unknown grouping set type %v", gs.Type))
+	}
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
-//
+// groupStrs and to the aggInScope. Returns the ordinals of the grouping
+// columns for the expression.
 //
 // groupBy          The given GROUP BY expression.
 // selects          The select expressions are needed in case the GROUP BY
@@ -529,7 +783,7 @@ func (b *Builder) buildGroupingList(
 //                  as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) (ordinals util.FastIntSet) {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -623,21 +877,148 @@ func (b *Builder) buildGrouping(
 	exprs = flattenTuples(exprs)
 
 	// Finally, build each of the GROUP BY columns.
+	g := fromScope.groupby
 	for _, e := range exprs {
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
-		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+		if col, ok := g.groupStrs[exprStr]; ok {
+			ordinals.Add(g.groupingColOrdinal(col.id))
 			continue
 		}
 
 		// Save a representation of the GROUP BY expression for validation of the
 		// SELECT and HAVING expressions. This enables queries such as:
 		//   SELECT x+y FROM t GROUP BY x+y
+		ordinals.Add(len(g.groupStrs))
 		col := aggInScope.addColumn(alias, e)
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
-		fromScope.groupby.groupStrs[exprStr] = col
+		g.groupStrs[exprStr] = col
+	}
+	return ordinals
+}
+
+// constructGroupingSetsInput constructs the input of an aggregation with
+// multiple grouping sets that is built as window functions, given the
+// pre-projection. The pre-projection is cross joined with a Values operator
+// that contains the index of each grouping set (as the grouping set ID
+// column), and grouping columns that are not part of a row's grouping set are
+// replaced by NULL. The window functions are then partitioned by the
+// NULL-extended grouping columns and the grouping set ID column. filterCols
+// contains the FILTER column of each aggregate function, or zero if the
+// aggregate function has no filter.
+//
+// If there are empty grouping sets, the aggregation must produce a row for
+// each of them even if the input is empty. To ensure this, one additional row
+// per empty grouping set is appended to the expanded input, and filterCols is
+// updated so that all aggregate functions ignore these rows.
+func (b *Builder) constructGroupingSetsInput(
+	g *groupby, input memo.RelExpr, filterCols []opt.ColumnID,
+) memo.RelExpr {
+	gs := g.groupingSets
+	md := b.factory.Metadata()
+
+	constInt := func(i int) opt.ScalarExpr {
+		return b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int)
+	}
+	rowType := types.MakeTuple([]*types.T{types.Int})
+	var rows, emptySetRows memo.ScalarListExpr
+	for i := range gs.sets {
+		row := b.factory.ConstructTuple(memo.ScalarListExpr{constInt(i)}, rowType)
+		rows = append(rows, row)
+		if gs.sets[i].Empty() {
+			emptySetRows = append(emptySetRows, row)
+		}
+	}
+
+	setIDCol := gs.idCol
+	if len(emptySetRows) > 0 {
+		setIDCol = md.AddColumn("grouping_set", types.Int)
 	}
+	expanded := b.factory.ConstructInnerJoin(
+		input,
+		b.factory.ConstructValues(rows, &memo.ValuesPrivate{
+			Cols: opt.ColList{setIDCol},
+			ID:   md.NextUniqueID(),
+		}),
+		memo.TrueFilter,
+		memo.EmptyJoinPrivate,
+	)
+
+	var projections memo.ProjectionsExpr
+	passthrough := input.Relational().OutputCols.Copy()
+	passthrough.Add(setIDCol)
+
+	// Replace the grouping columns that are not part of every grouping set by
+	// NULL for the grouping sets they are not part of.
+	setID := b.factory.ConstructVariable(setIDCol)
+	for i, outCol := range gs.outCols {
+		col := gs.cols[i]
+		if outCol == col {
+			continue
+		}
+		var ids memo.ScalarListExpr
+		for j, set := range gs.sets {
+			if set.Contains(i) {
+				ids = append(ids, constInt(j))
+			}
+		}
+		idsTyp := make([]*types.T, len(ids))
+		for j := range idsTyp {
+			idsTyp[j] = types.Int
+		}
+		in := b.factory.ConstructIn(setID, b.factory.ConstructTuple(ids, types.MakeTuple(idsTyp)))
+		projections = append(projections, b.factory.ConstructProjectionsItem(
+			b.factory.ConstructCase(
+				memo.TrueSingleton,
+				memo.ScalarListExpr{
+					b.factory.ConstructWhen(in, b.factory.ConstructVariable(col)),
+				},
+				b.factory.ConstructNull(md.ColumnMeta(col).Type),
+			),
+			outCol,
+		))
+	}
+
+	if len(emptySetRows) > 0 {
+		// Append the rows for the empty grouping sets with a full join that never
+		// matches.
+		emptySetIDCol := md.AddColumn("empty_grouping_set", types.Int)
+		expanded = b.factory.ConstructFullJoin(
+			expanded,
+			b.factory.ConstructValues(emptySetRows, &memo.ValuesPrivate{
+				Cols: opt.ColList{emptySetIDCol},
+				ID:   md.NextUniqueID(),
+			}),
+			memo.FiltersExpr{b.factory.ConstructFiltersItem(memo.FalseSingleton)},
+			memo.EmptyJoinPrivate,
+		)
+		emptySetID := b.factory.ConstructVariable(emptySetIDCol)
+		projections = append(projections, b.factory.ConstructProjectionsItem(
+			b.factory.ConstructCoalesce(memo.ScalarListExpr{setID, emptySetID}),
+			gs.idCol,
+		))
+
+		// The aggregate functions only consider the rows of the original input.
+		fromInput := b.factory.ConstructIs(emptySetID, memo.NullSingleton)
+		fromInputCol := md.AddColumn("from_input", types.Bool)
+		projections = append(projections, b.factory.ConstructProjectionsItem(
+			fromInput, fromInputCol,
+		))
+		for i, filterCol := range filterCols {
+			if filterCol == 0 {
+				filterCols[i] = fromInputCol
+				continue
+			}
+			filterCols[i] = md.AddColumn("filter", types.Bool)
+			projections = append(projections, b.factory.ConstructProjectionsItem(
+				b.factory.ConstructAnd(b.factory.ConstructVariable(filterCol), fromInput),
+				filterCols[i],
+			))
+		}
+	}
+
+	return b.factory.ConstructProject(expanded, projections, passthrough)
 }
 
 // buildAggArg builds a scalar expression which is used as an input in some form
@@ -875,6 +1256,72 @@ func (b *Builder) constructAggregate(name string, args []opt.ScalarExpr) opt.Sca
 	panic(errors.AssertionFailedf("unhandled aggregate: %s", name))
 }
 
+// buildGroupingExpr builds a GROUPING(...) expression. Each argument must be a
+// GROUP BY expression. The result is a bit mask with a bit set for each
+// argument that is not part of the grouping set of the current row, with the
+// last argument corresponding to the least significant bit.
+func (b *Builder) buildGroupingExpr(
+	t *tree.GroupingExpr, inScope *scope, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	switch inScope.context {
+	case exprKindWhere, exprKindOn, exprKindLateralJoin:
+		panic(pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in %s", inScope.context,
+		))
+	}
+	if !inScope.inGroupingContext() || inScope.inAgg || inScope.groupby.buildingGroupingCols {
+		panic(errGroupingArgs)
+	}
+	if len(t.Exprs) > 31 {
+		panic(pgerror.New(pgcode.TooManyArguments, "GROUPING must have fewer than 32 arguments"))
+	}
+	g := inScope.groupby
+	ordinals := make([]int, len(t.Exprs))
+	for i, e := range t.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(e.(tree.TypedExpr))]
+		if !ok {
+			panic(errGroupingArgs)
+		}
+		ordinals[i] = -1
+		if g.groupingSets != nil {
+			for j, id := range g.groupingSets.outCols {
+				if id == col.id {
+					ordinals[i] = j
+				}
+			}
+		}
+	}
+
+	gs := g.groupingSets
+	if gs == nil {
+		// Every grouping column is part of the only grouping set.
+		return b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+	}
+	whens := make(memo.ScalarListExpr, len(gs.sets))
+	for i, set := range gs.sets {
+		var mask int
+		for _, ord := range ordinals {
+			mask <<= 1
+			if ord != -1 && !set.Contains(ord) {
+				mask |= 1
+			}
+		}
+		whens[i] = b.factory.ConstructWhen(
+			b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int),
+			b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(mask)), types.Int),
+		)
+	}
+	if colRefs != nil {
+		colRefs.Add(gs.idCol)
+	}
+	return b.factory.ConstructCase(
+		b.factory.ConstructVariable(gs.idCol), whens, b.factory.ConstructNull(types.Int),
+	)
+}
+
+var errGroupingArgs = pgerror.New(pgcode.Grouping,
+	"arguments to GROUPING must be grouping expressions of the associated query level")
+
 func isAggregate(def *tree.FunctionDefinition) bool {
 	return def.Class == tree.AggregateClass
 }
@@ -903,6 +1350,11 @@ func newGroupingError(name *tree.Name) error {
 // table. In that case, we can allow col as an "implicit" grouping column, even
 // if it is not specified in the query.
 func (b *Builder) allowImplicitGroupingColumn(colID opt.ColumnID, g *groupby) bool {
+	if g.groupingSets != nil {
+		// The column is not functionally determined by the grouping columns for
+		// grouping sets that don't contain the entire PK.
+		return false
+	}
 	md := b.factory.Metadata()
 	colMeta := md.ColumnMeta(colID)
 	if colMeta.Table == 0 {
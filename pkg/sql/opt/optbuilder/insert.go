@@ -1293,7 +1293,10 @@ func (mb *mutationBuilder) arbiterIndexesAndConstraints(
 			}
 		}
 		for uc, ucCount := 0, mb.tab.UniqueCount(); uc < ucCount; uc++ {
-			if mb.tab.Unique(uc).WithoutIndex() {
+			// Deferrable unique constraints cannot be arbiters. As in Postgres,
+			// they are checked as usual instead.
+			if u := mb.tab.Unique(uc); u.WithoutIndex() &&
+				u.Deferrability() == tree.ConstraintNotDeferrable {
 				uniqueConstraints.Add(uc)
 			}
 		}
@@ -1411,6 +1414,10 @@ func (mb *mutationBuilder) arbiterIndexesAndConstraints(
 		}
 
 		if ucOrds.Equals(conflictOrds) {
+			if uniqueConstraint.Deferrability() != tree.ConstraintNotDeferrable {
+				panic(pgerror.New(pgcode.FeatureNotSupported,
+					"ON CONFLICT does not support deferrable unique constraints as arbiters"))
+			}
 			return util.FastIntSet{}, util.MakeFastIntSet(uc)
 		}
 	}
@@ -263,6 +263,9 @@ func (b *Builder) buildScalar(
 		}
 		out = b.factory.ConstructCoalesce(args)
 
+	case *tree.GroupingExpr:
+		out = b.buildGroupingExpr(t, inScope, colRefs)
+
 	case *tree.ColumnAccessExpr:
 		input := b.buildScalar(t.Expr.(tree.TypedExpr), inScope, nil, nil, colRefs)
 		out = b.factory.ConstructColumnAccess(input, memo.TupleOrdinal(t.ColIndex))
@@ -484,7 +487,7 @@ func (b *Builder) buildFunction(
 		}
 	}
 
-	def, err := f.Func.Resolve(b.semaCtx.SearchPath)
+	def, err := f.Func.Resolve(b.ctx, b.semaCtx.SearchPath, b.semaCtx.FunctionResolver)
 	if err != nil {
 		panic(err)
 	}
@@ -497,6 +500,20 @@ func (b *Builder) buildFunction(
 		panic(errors.AssertionFailedf("window function should have been replaced"))
 	}
 
+	if udf := f.ResolvedOverload().UDF; udf != nil {
+		// Views store the text of their query, so a view referencing a
+		// user-defined function would not prevent the function from being
+		// dropped.
+		if b.trackViewDeps {
+			panic(unimplemented.NewWithIssue(17511, "user-defined functions in views are not supported"))
+		}
+		if udf.InlineExpr != nil {
+			if inlined := b.inlineUDF(f, udf); inlined != nil {
+				return b.buildScalar(inlined, inScope, outScope, outCol, colRefs)
+			}
+		}
+	}
+
 	args := make(memo.ScalarListExpr, len(f.Exprs))
 	for i, pexpr := range f.Exprs {
 		args[i] = b.buildScalar(pexpr.(tree.TypedExpr), inScope, nil, nil, colRefs)
@@ -533,6 +550,43 @@ func (b *Builder) buildFunction(
 	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
 }
 
+// inlineUDF returns the body of the given call to a user-defined function,
+// with the arguments of the call substituted for the function's parameters.
+// It returns nil if the call cannot be inlined: an argument which is
+// referenced more than once by the body must be a constant or a column
+// reference, so that inlining does not duplicate the evaluation of arbitrary
+// expressions.
+func (b *Builder) inlineUDF(f *tree.FuncExpr, udf *tree.UDFDefinition) tree.TypedExpr {
+	refs := make([]int, len(f.Exprs))
+	expr, err := tree.SimpleVisit(udf.InlineExpr, func(e tree.Expr) (bool, tree.Expr, error) {
+		if p, ok := e.(*tree.Placeholder); ok {
+			refs[p.Idx]++
+			return false, f.Exprs[p.Idx], nil
+		}
+		return true, e, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	for i, n := range refs {
+		if n > 1 {
+			switch f.Exprs[i].(type) {
+			case tree.Datum, *scopeColumn:
+			default:
+				return nil
+			}
+		}
+	}
+	typ := f.ResolvedType()
+	texpr, err := tree.TypeCheck(b.ctx, &tree.CastExpr{
+		Expr: expr, Type: typ, SyntaxMode: tree.CastShort,
+	}, b.semaCtx, typ)
+	if err != nil {
+		panic(err)
+	}
+	return texpr
+}
+
 // buildRangeCond builds a RANGE clause as a simpler expression. Examples:
 // x BETWEEN a AND b                ->  x >= a AND x <= b
 // x NOT BETWEEN a AND b            ->  NOT (x >= a AND x <= b)
@@ -696,6 +750,8 @@ func (b *Builder) constructComparison(
 		return b.factory.ConstructJsonAllExists(left, right)
 	case tree.JSONSomeExists:
 		return b.factory.ConstructJsonSomeExists(left, right)
+	case tree.TSMatches:
+		return b.factory.ConstructTSMatches(left, right)
 	case tree.Overlaps:
 		leftFam, rightFam := cmp.Fn.LeftType.Family(), cmp.Fn.RightType.Family()
 		if (leftFam == types.GeometryFamily || leftFam == types.Box2DFamily) &&
@@ -935,7 +935,9 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		return false, colI.(*scopeColumn)
 
 	case *tree.FuncExpr:
-		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
+		def, err := t.Func.Resolve(
+			s.builder.ctx, s.builder.semaCtx.SearchPath, s.builder.semaCtx.FunctionResolver,
+		)
 		if err != nil {
 			panic(err)
 		}
@@ -1411,7 +1413,9 @@ func (s *scope) replaceCount(
 				e := &cpy
 				e.Exprs = tree.Exprs{tree.DBoolTrue}
 
-				newDef, err := e.Func.Resolve(s.builder.semaCtx.SearchPath)
+				newDef, err := e.Func.Resolve(
+				s.builder.ctx, s.builder.semaCtx.SearchPath, s.builder.semaCtx.FunctionResolver,
+			)
 				if err != nil {
 					panic(err)
 				}
@@ -1437,7 +1441,9 @@ func (s *scope) replaceCount(
 			if _, err := e.TypeCheck(s.builder.ctx, &semaCtx, types.Any); err != nil {
 				panic(err)
 			}
-			newDef, err := e.Func.Resolve(s.builder.semaCtx.SearchPath)
+			newDef, err := e.Func.Resolve(
+				s.builder.ctx, s.builder.semaCtx.SearchPath, s.builder.semaCtx.FunctionResolver,
+			)
 			if err != nil {
 				panic(err)
 			}
@@ -267,6 +267,9 @@ func (b *Builder) buildAggregationAsWindow(
 
 	// Initialize the aggregate expression.
 	aggregateExpr := g.aggInScope.expr
+	if g.groupingSets != nil {
+		aggregateExpr = b.constructGroupingSetsInput(g, aggregateExpr, filterCols)
+	}
 
 	// frames accumulates the set of distinct window frames we're computing over
 	// so that we can group functions over the same partition and ordering.
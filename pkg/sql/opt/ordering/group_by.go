@@ -67,6 +67,16 @@ func groupByBuildProvided(expr memo.RelExpr, required *physical.OrderingChoice)
 	return remapProvided(provided, inputFDs, groupBy.GroupingCols)
 }
 
+func groupingSetsBuildChildReqOrdering(
+	parent memo.RelExpr, required *physical.OrderingChoice, childIdx int,
+) physical.OrderingChoice {
+	if childIdx != 0 {
+		return physical.OrderingChoice{}
+	}
+	// GroupingSets requires the intra-group ordering in its private.
+	return parent.(*memo.GroupingSetsExpr).Ordering
+}
+
 func distinctOnCanProvideOrdering(expr memo.RelExpr, required *physical.OrderingChoice) bool {
 	// DistinctOn may require a certain ordering of its input, but can also pass
 	// through a stronger ordering on the grouping columns.
@@ -157,6 +157,13 @@ func init() {
 		buildChildReqOrdering: groupByBuildChildReqOrdering,
 		buildProvidedOrdering: groupByBuildProvided,
 	}
+	funcMap[opt.GroupingSetsOp] = funcs{
+		// GroupingSets is always executed with hashing and the rows of the
+		// grouping sets are not interleaved in any particular order.
+		canProvideOrdering:    canNeverProvideOrdering,
+		buildChildReqOrdering: groupingSetsBuildChildReqOrdering,
+		buildProvidedOrdering: noProvidedOrdering,
+	}
 	funcMap[opt.DistinctOnOp] = funcs{
 		canProvideOrdering:    distinctOnCanProvideOrdering,
 		buildChildReqOrdering: distinctOnBuildChildReqOrdering,
@@ -192,7 +192,9 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 		switch def := def.(type) {
 		case *tree.UniqueConstraintTableDef:
 			if def.WithoutIndex {
-				tab.addUniqueConstraint(def.Name, def.Columns, def.Predicate, def.WithoutIndex)
+				tab.addUniqueConstraint(
+					def.Name, def.Columns, def.Predicate, def.WithoutIndex, def.Deferrability,
+				)
 			} else if !def.PrimaryKey {
 				tab.addIndex(&def.IndexTableDef, uniqueIndex)
 			}
@@ -211,6 +213,7 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 						tree.IndexElemList{{Column: def.Name}},
 						nil, /* predicate */
 						def.Unique.WithoutIndex,
+						tree.ConstraintNotDeferrable,
 					)
 				} else {
 					tab.addIndex(
@@ -479,13 +482,18 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		matchMethod:              d.Match,
 		deleteAction:             d.Actions.Delete,
 		updateAction:             d.Actions.Update,
+		deferrability:            d.Deferrability,
 	}
 	tab.outboundFKs = append(tab.outboundFKs, fk)
 	targetTable.inboundFKs = append(targetTable.inboundFKs, fk)
 }
 
 func (tt *Table) addUniqueConstraint(
-	name tree.Name, columns tree.IndexElemList, predicate tree.Expr, withoutIndex bool,
+	name tree.Name,
+	columns tree.IndexElemList,
+	predicate tree.Expr,
+	withoutIndex bool,
+	deferrability tree.ConstraintDeferrability,
 ) {
 	cols := make([]int, len(columns))
 	for i, c := range columns {
@@ -507,6 +515,7 @@ func (tt *Table) addUniqueConstraint(
 		columnOrdinals: cols,
 		withoutIndex:   withoutIndex,
 		validated:      true,
+		deferrability:  deferrability,
 	}
 	// Add partial unique constraint predicate.
 	if predicate != nil {
@@ -585,7 +594,9 @@ func (tt *Table) addIndexWithVersion(
 ) *Index {
 	// Add a unique constraint if this is a primary or unique index.
 	if typ != nonUniqueIndex {
-		tt.addUniqueConstraint(def.Name, def.Columns, def.Predicate, false /* withoutIndex */)
+		tt.addUniqueConstraint(
+			def.Name, def.Columns, def.Predicate, false /* withoutIndex */, tree.ConstraintNotDeferrable,
+		)
 	}
 
 	idx := &Index{
@@ -473,6 +473,9 @@ func (c *coster) ComputeCost(candidate memo.RelExpr, required *physical.Required
 		opt.UpsertDistinctOnOp, opt.EnsureUpsertDistinctOnOp:
 		cost = c.computeGroupingCost(candidate, required)
 
+	case opt.GroupingSetsOp:
+		cost = c.computeGroupingSetsCost(candidate.(*memo.GroupingSetsExpr))
+
 	case opt.LimitOp:
 		cost = c.computeLimitCost(candidate.(*memo.LimitExpr))
 
@@ -1023,6 +1026,29 @@ func (c *coster) computeGroupingCost(grouping memo.RelExpr, required *physical.R
 	return cost
 }
 
+func (c *coster) computeGroupingSetsCost(groupingSets *memo.GroupingSetsExpr) memo.Cost {
+	// Start with the same fixed overhead as the other grouping operators.
+	cost := memo.Cost(cpuCostFactor)
+
+	// Add the CPU cost of emitting the rows.
+	cost += memo.Cost(groupingSets.Relational().Stats.RowCount) * cpuCostFactor
+
+	// Each input row is processed once for every grouping set, which always
+	// uses a hash table (except for an empty grouping set). Cost per row
+	// depends on the number of grouping columns and the number of aggregates.
+	inputRowCount := groupingSets.Input.Relational().Stats.RowCount
+	aggsCount := len(groupingSets.Aggregations)
+	for _, set := range groupingSets.Sets {
+		perRowCost := memo.Cost(aggsCount + set.Len())
+		if !set.Empty() {
+			perRowCost++
+		}
+		cost += memo.Cost(inputRowCount) * perRowCost * cpuCostFactor
+	}
+
+	return cost
+}
+
 func (c *coster) computeLimitCost(limit *memo.LimitExpr) memo.Cost {
 	// Add the CPU cost of emitting the rows.
 	cost := memo.Cost(limit.Relational().Stats.RowCount) * cpuCostFactor
@@ -257,6 +257,13 @@ func (oc *optCatalog) ResolveType(
 	return oc.planner.ResolveType(ctx, name)
 }
 
+// ResolveFunction is part of the cat.Catalog interface.
+func (oc *optCatalog) ResolveFunction(
+	ctx context.Context, name *tree.UnresolvedName,
+) (*tree.FunctionDefinition, error) {
+	return oc.planner.ResolveFunction(ctx, name, oc.planner.CurrentSearchPath())
+}
+
 func getDescFromCatalogObjectForPermissions(o cat.Object) (catalog.Descriptor, error) {
 	switch t := o.(type) {
 	case *optSchema:
@@ -718,12 +725,13 @@ func newOptTable(
 	for i := range ot.desc.GetUniqueWithoutIndexConstraints() {
 		u := &ot.desc.GetUniqueWithoutIndexConstraints()[i]
 		ot.uniqueConstraints = append(ot.uniqueConstraints, optUniqueConstraint{
-			name:         u.Name,
-			table:        ot.ID(),
-			columns:      u.ColumnIDs,
-			predicate:    u.Predicate,
-			withoutIndex: true,
-			validity:     u.Validity,
+			name:          u.Name,
+			table:         ot.ID(),
+			columns:       u.ColumnIDs,
+			predicate:     u.Predicate,
+			withoutIndex:  true,
+			validity:      u.Validity,
+			deferrability: u.Deferrability,
 		})
 	}
 
@@ -802,6 +810,7 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrability:     fk.Deferrability,
 		})
 	}
 	for i := range ot.desc.GetInboundFKs() {
@@ -816,6 +825,7 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrability:     fk.Deferrability,
 		})
 	}
 
@@ -1485,8 +1495,9 @@ type optUniqueConstraint struct {
 	columns   []descpb.ColumnID
 	predicate string
 
-	withoutIndex bool
-	validity     descpb.ConstraintValidity
+	withoutIndex  bool
+	validity      descpb.ConstraintValidity
+	deferrability descpb.ConstraintDeferrability
 }
 
 var _ cat.UniqueConstraint = &optUniqueConstraint{}
@@ -1534,6 +1545,11 @@ func (u *optUniqueConstraint) Validated() bool {
 	return u.validity == descpb.ConstraintValidity_Validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return descpb.ConstraintDeferrabilityType[u.deferrability]
+}
+
 // optForeignKeyConstraint implements cat.ForeignKeyConstraint and represents a
 // foreign key relationship. Both the origin and the referenced table store the
 // same optForeignKeyConstraint (as an outbound and inbound reference,
@@ -1547,10 +1563,11 @@ type optForeignKeyConstraint struct {
 	referencedTable   cat.StableID
 	referencedColumns []descpb.ColumnID
 
-	validity     descpb.ConstraintValidity
-	match        descpb.ForeignKeyReference_Match
-	deleteAction descpb.ForeignKeyReference_Action
-	updateAction descpb.ForeignKeyReference_Action
+	validity      descpb.ConstraintValidity
+	match         descpb.ForeignKeyReference_Match
+	deleteAction  descpb.ForeignKeyReference_Action
+	updateAction  descpb.ForeignKeyReference_Action
+	deferrability descpb.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1622,6 +1639,11 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return descpb.ForeignKeyReferenceActionType[fk.updateAction]
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return descpb.ConstraintDeferrabilityType[fk.deferrability]
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc catalog.TableDescriptor
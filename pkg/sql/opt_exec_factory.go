@@ -458,6 +458,37 @@ func (ef *execFactory) ConstructGroupBy(
 	return n, nil
 }
 
+// ConstructGroupingSets is part of the exec.Factory interface.
+func (ef *execFactory) ConstructGroupingSets(
+	input exec.Node,
+	groupCols []exec.NodeColumnOrdinal,
+	groupingSets []exec.NodeColumnOrdinalSet,
+	aggregations []exec.AggInfo,
+) (exec.Node, error) {
+	inputPlan := input.(planNode)
+	inputCols := planColumns(inputPlan)
+	n := &groupNode{
+		plan:         inputPlan,
+		funcs:        make([]*aggregateFuncHolder, 0, len(groupCols)+len(aggregations)),
+		columns:      getResultColumnsForGroupingSets(inputCols, groupCols, aggregations),
+		groupCols:    convertOrdinalsToInts(groupCols),
+		groupingSets: groupingSets,
+	}
+	for _, col := range n.groupCols {
+		f := newAggregateFuncHolder(
+			builtins.AnyNotNull,
+			[]int{col},
+			nil,   /* arguments */
+			false, /* isDistinct */
+		)
+		n.funcs = append(n.funcs, f)
+	}
+	if err := ef.addAggregations(n, aggregations); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
 func (ef *execFactory) addAggregations(n *groupNode, aggregations []exec.AggInfo) error {
 	for i := range aggregations {
 		agg := &aggregations[i]
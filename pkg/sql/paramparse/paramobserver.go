@@ -15,11 +15,15 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
+	"github.com/gorhill/cronexpr"
 )
 
 // ApplyStorageParameters applies given storage parameters with the
@@ -68,7 +72,9 @@ type StorageParamObserver interface {
 }
 
 // TableStorageParamObserver observes storage parameters for tables.
-type TableStorageParamObserver struct{}
+type TableStorageParamObserver struct {
+	TableDesc *descpb.TableDescriptor
+}
 
 var _ StorageParamObserver = (*TableStorageParamObserver)(nil)
 
@@ -89,8 +95,52 @@ func applyFillFactorStorageParam(evalCtx *tree.EvalContext, key string, datum tr
 	return nil
 }
 
+// rowLevelTTL returns the row-level TTL config of the table, initializing it
+// if necessary.
+func (a *TableStorageParamObserver) rowLevelTTL() *descpb.TableDescriptor_RowLevelTTL {
+	if a.TableDesc.RowLevelTTL == nil {
+		a.TableDesc.RowLevelTTL = &descpb.TableDescriptor_RowLevelTTL{}
+	}
+	return a.TableDesc.RowLevelTTL
+}
+
+func applyTTLExpireAfterStorageParam(
+	evalCtx *tree.EvalContext, key string, datum tree.Datum,
+) (string, error) {
+	d, ok := datum.(*tree.DInterval)
+	if !ok {
+		stringVal, err := DatumAsString(evalCtx, key, datum)
+		if err != nil {
+			return "", err
+		}
+		if d, err = tree.ParseDInterval(stringVal); err != nil {
+			return "", pgerror.Wrapf(err, pgcode.InvalidParameterValue, "value of %q must be an interval", key)
+		}
+	}
+	if d.Duration.Compare(duration.Duration{}) <= 0 {
+		return "", pgerror.Newf(pgcode.InvalidParameterValue, "value of %q must be greater than 0", key)
+	}
+	return d.Duration.String(), nil
+}
+
+func applyTTLPositiveIntStorageParam(
+	evalCtx *tree.EvalContext, key string, datum tree.Datum,
+) (int64, error) {
+	val, err := DatumAsInt(evalCtx, key, datum)
+	if err != nil {
+		return 0, err
+	}
+	if val <= 0 {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue, "%q must be greater than 0", key)
+	}
+	return val, nil
+}
+
 // RunPostChecks implements the StorageParamObserver interface.
 func (a *TableStorageParamObserver) RunPostChecks() error {
+	if ttl := a.TableDesc.RowLevelTTL; ttl != nil && ttl.ExpireAfter == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, `"ttl_expire_after" must be set`)
+	}
 	return nil
 }
 
@@ -122,6 +172,51 @@ func (a *TableStorageParamObserver) Apply(
 			)
 		}
 		return nil
+	case `ttl_expire_after`:
+		expireAfter, err := applyTTLExpireAfterStorageParam(evalCtx, key, datum)
+		if err != nil {
+			return err
+		}
+		a.rowLevelTTL().ExpireAfter = expireAfter
+		return nil
+	case `ttl_select_batch_size`:
+		val, err := applyTTLPositiveIntStorageParam(evalCtx, key, datum)
+		if err != nil {
+			return err
+		}
+		a.rowLevelTTL().SelectBatchSize = val
+		return nil
+	case `ttl_delete_batch_size`:
+		val, err := applyTTLPositiveIntStorageParam(evalCtx, key, datum)
+		if err != nil {
+			return err
+		}
+		a.rowLevelTTL().DeleteBatchSize = val
+		return nil
+	case `ttl_range_concurrency`:
+		val, err := applyTTLPositiveIntStorageParam(evalCtx, key, datum)
+		if err != nil {
+			return err
+		}
+		a.rowLevelTTL().RangeConcurrency = val
+		return nil
+	case `ttl_delete_rate_limit`:
+		val, err := applyTTLPositiveIntStorageParam(evalCtx, key, datum)
+		if err != nil {
+			return err
+		}
+		a.rowLevelTTL().DeleteRateLimit = val
+		return nil
+	case `ttl_job_cron`:
+		stringVal, err := DatumAsString(evalCtx, key, datum)
+		if err != nil {
+			return err
+		}
+		if _, err := cronexpr.Parse(stringVal); err != nil {
+			return pgerror.Wrapf(err, pgcode.InvalidParameterValue, "invalid cron expression for %q", key)
+		}
+		a.rowLevelTTL().DeletionCron = stringVal
+		return nil
 	case `toast_tuple_target`,
 		`parallel_workers`,
 		`toast.autovacuum_enabled`,
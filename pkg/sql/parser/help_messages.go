@@ -4,20 +4,20 @@
 package parser
 
 var helpMessages = map[string]HelpMessageBody{
-	//line sql.y: 1325
+	//line sql.y: 1385
 	`ALTER`: {
-		//line sql.y: 1326
+		//line sql.y: 1386
 		Category: hGroup,
-		//line sql.y: 1327
+		//line sql.y: 1387
 		Text: `ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER, ALTER ROLE
 `,
 	},
-	//line sql.y: 1344
+	//line sql.y: 1404
 	`ALTER TABLE`: {
 		ShortDescription: `change the definition of a table`,
-		//line sql.y: 1345
+		//line sql.y: 1405
 		Category: hDDL,
-		//line sql.y: 1346
+		//line sql.y: 1406
 		Text: `
 ALTER TABLE [IF EXISTS] <tablename> <command> [, ...]
 
@@ -59,16 +59,16 @@ Zone configurations:
   { TO | = } <expr>
 
 `,
-		//line sql.y: 1386
+		//line sql.y: 1446
 		SeeAlso: `WEBDOCS/alter-table.html
 `,
 	},
-	//line sql.y: 1403
+	//line sql.y: 1463
 	`ALTER PARTITION`: {
 		ShortDescription: `apply zone configurations to a partition`,
-		//line sql.y: 1404
+		//line sql.y: 1464
 		Category: hDDL,
-		//line sql.y: 1405
+		//line sql.y: 1465
 		Text: `
 ALTER PARTITION <name> <command>
 
@@ -89,30 +89,30 @@ Zone configurations:
   { TO | = } <expr>
 
 `,
-		//line sql.y: 1424
+		//line sql.y: 1484
 		SeeAlso: `WEBDOCS/configure-zone.html
 `,
 	},
-	//line sql.y: 1429
+	//line sql.y: 1489
 	`ALTER VIEW`: {
 		ShortDescription: `change the definition of a view`,
-		//line sql.y: 1430
+		//line sql.y: 1490
 		Category: hDDL,
-		//line sql.y: 1431
+		//line sql.y: 1491
 		Text: `
 ALTER [MATERIALIZED] VIEW [IF EXISTS] <name> RENAME TO <newname>
 ALTER [MATERIALIZED] VIEW [IF EXISTS] <name> SET SCHEMA <newschemaname>
 `,
-		//line sql.y: 1434
+		//line sql.y: 1494
 		SeeAlso: `WEBDOCS/alter-view.html
 `,
 	},
-	//line sql.y: 1443
+	//line sql.y: 1503
 	`ALTER SEQUENCE`: {
 		ShortDescription: `change the definition of a sequence`,
-		//line sql.y: 1444
+		//line sql.y: 1504
 		Category: hDDL,
-		//line sql.y: 1445
+		//line sql.y: 1505
 		Text: `
 ALTER SEQUENCE [IF EXISTS] <name>
   [INCREMENT <increment>]
@@ -124,12 +124,12 @@ ALTER SEQUENCE [IF EXISTS] <name> RENAME TO <newname>
 ALTER SEQUENCE [IF EXISTS] <name> SET SCHEMA <newschemaname>
 `,
 	},
-	//line sql.y: 1471
+	//line sql.y: 1531
 	`ALTER DATABASE`: {
 		ShortDescription: `change the definition of a database`,
-		//line sql.y: 1472
+		//line sql.y: 1532
 		Category: hDDL,
-		//line sql.y: 1473
+		//line sql.y: 1533
 		Text: `
 ALTER DATABASE <name> RENAME TO <newname>
 ALTER DATABASE <name> OWNER TO <newowner>
@@ -139,16 +139,16 @@ ALTER DATABASE <name> DROP REGIONS <regions>
 ALTER DATABASE <name> SET PRIMARY REGION <region>
 ALTER DATABASE <name> SURVIVE <failure type>
 `,
-		//line sql.y: 1481
+		//line sql.y: 1541
 		SeeAlso: `WEBDOCS/alter-database.html
 `,
 	},
-	//line sql.y: 1537
+	//line sql.y: 1597
 	`ALTER RANGE`: {
 		ShortDescription: `change the parameters of a range`,
-		//line sql.y: 1538
+		//line sql.y: 1598
 		Category: hDDL,
-		//line sql.y: 1539
+		//line sql.y: 1599
 		Text: `
 ALTER RANGE <zonename> <command>
 
@@ -162,16 +162,16 @@ Zone configurations:
   { TO | = } <expr>
 
 `,
-		//line sql.y: 1551
+		//line sql.y: 1611
 		SeeAlso: `ALTER TABLE
 `,
 	},
-	//line sql.y: 1556
+	//line sql.y: 1616
 	`ALTER INDEX`: {
 		ShortDescription: `change the definition of an index`,
-		//line sql.y: 1557
+		//line sql.y: 1617
 		Category: hDDL,
-		//line sql.y: 1558
+		//line sql.y: 1618
 		Text: `
 ALTER INDEX [IF EXISTS] <idxname> <command>
 
@@ -189,16 +189,16 @@ Zone configurations:
   { TO | = } <expr>
 
 `,
-		//line sql.y: 1574
+		//line sql.y: 1634
 		SeeAlso: `WEBDOCS/alter-index.html
 `,
 	},
-	//line sql.y: 2088
+	//line sql.y: 2148
 	`ALTER TYPE`: {
 		ShortDescription: `change the definition of a type.`,
-		//line sql.y: 2089
+		//line sql.y: 2149
 		Category: hDDL,
-		//line sql.y: 2090
+		//line sql.y: 2150
 		Text: `ALTER TYPE <typename> <command>
 
 Commands:
@@ -216,26 +216,26 @@ Attribute action:
   ALTER ATTRIBUTE <name> [ SET DATA ] TYPE <type> [ COLLATE <collation> ] [ CASCADE | RESTRICT ]
 
 `,
-		//line sql.y: 2106
+		//line sql.y: 2166
 		SeeAlso: `WEBDOCS/alter-type.html
 `,
 	},
-	//line sql.y: 2257
+	//line sql.y: 2317
 	`REFRESH`: {
 		ShortDescription: `recalculate a materialized view`,
-		//line sql.y: 2258
+		//line sql.y: 2318
 		Category: hMisc,
-		//line sql.y: 2259
+		//line sql.y: 2319
 		Text: `
 REFRESH MATERIALIZED VIEW [CONCURRENTLY] view_name [WITH [NO] DATA]
 `,
 	},
-	//line sql.y: 2286
+	//line sql.y: 2346
 	`BACKUP`: {
 		ShortDescription: `back up data to external storage`,
-		//line sql.y: 2287
+		//line sql.y: 2347
 		Category: hCCL,
-		//line sql.y: 2288
+		//line sql.y: 2348
 		Text: `
 BACKUP <targets...> TO <location...>
        [ AS OF SYSTEM TIME <expr> ]
@@ -257,16 +257,16 @@ Options:
    detached: execute backup job asynchronously, without waiting for its completion
 
 `,
-		//line sql.y: 2308
+		//line sql.y: 2368
 		SeeAlso: `RESTORE, WEBDOCS/backup.html
 `,
 	},
-	//line sql.y: 2412
+	//line sql.y: 2472
 	`CREATE SCHEDULE FOR BACKUP`: {
 		ShortDescription: `backup data periodically`,
-		//line sql.y: 2413
+		//line sql.y: 2473
 		Category: hCCL,
-		//line sql.y: 2414
+		//line sql.y: 2474
 		Text: `
 CREATE SCHEDULE [<description>]
 FOR BACKUP [<targets>] TO <location...>
@@ -337,17 +337,125 @@ FULL BACKUP <crontab|ALWAYS>:
     objects.
 
 `,
-		//line sql.y: 2483
+		//line sql.y: 2543
 		SeeAlso: `BACKUP
 `,
 	},
 	//line sql.y: 2561
-	`RESTORE`: {
-		ShortDescription: `restore data from external storage`,
+	`CREATE SCHEDULE FOR EXPORT`: {
+		ShortDescription: `export data periodically`,
 		//line sql.y: 2562
 		Category: hCCL,
 		//line sql.y: 2563
 		Text: `
+CREATE SCHEDULE [<description>]
+FOR EXPORT INTO <format> <datafile> [WITH <option> [= value] [,...]] FROM ( <query> )
+RECURRING <crontab>
+[WITH SCHEDULE OPTIONS <schedule_option>=<value> [, ...] ]
+
+Description:
+  Optional description (or name) for this schedule
+
+EXPORT INTO <format> <datafile> [WITH <options>] FROM ( <query> ):
+  The export executed by the schedule: See EXPORT.
+  The query must be enclosed in parentheses.
+  Each execution of the schedule writes its files under <datafile>.
+  An execution which is resumed after a failure, such as the restart of a
+  node, runs the export again from the start. The files written before the
+  failure are left in place, so <datafile> may then hold duplicate files
+  with the same rows.
+
+RECURRING <crontab>:
+  Schedule specified as a string in crontab format.
+  All times in UTC.
+    "5 0 * * *": run schedule 5 minutes past midnight.
+    "@daily": run daily, at midnight
+  See https://en.wikipedia.org/wiki/Cron
+
+ SCHEDULE OPTIONS:
+  The schedule can be modified by specifying the following options:
+  * first_run=TIMESTAMPTZ:
+    execute the schedule at the specified time. If not specified, the default is to execute
+    the scheduled based on it's next RECURRING time.
+  * on_execution_failure='[retry|reschedule|pause]':
+    If an error occurs during the execution, handle the error based as:
+    * retry: retry execution right away
+    * reschedule: retry execution by rescheduling it based on its RECURRING expression.
+      This is the default.
+    * pause: pause this schedule.  Requires manual intervention to unpause.
+  * on_previous_running='[start|skip|wait]':
+    If the previous export started by this schedule still running, handle this as:
+    * start: start this execution anyway, even if the previous one still running.
+    * skip: skip this execution, reschedule it based on RECURRING expression.
+    * wait: wait for the previous execution to complete.  This is the default.
+
+`,
+		//line sql.y: 2605
+		SeeAlso: `EXPORT, SHOW SCHEDULES
+`,
+	},
+	//line sql.y: 2625
+	`CREATE SCHEDULE FOR CHANGEFEED`: {
+		ShortDescription: `emit the changes of tables periodically`,
+		//line sql.y: 2626
+		Category: hCCL,
+		//line sql.y: 2627
+		Text: `
+CREATE SCHEDULE [<description>]
+FOR CHANGEFEED <targets...> INTO <sink> [WITH <option> [= value] [,...]]
+RECURRING <crontab>
+[WITH SCHEDULE OPTIONS <schedule_option>=<value> [, ...] ]
+
+Description:
+  Optional description (or name) for this schedule
+
+Targets:
+   TABLE <pattern> [, ...]
+
+WITH <options>:
+  Options specific to CHANGEFEED: See CREATE CHANGEFEED options.
+  Each execution of the schedule emits the changes committed since the
+  previous successful execution, and stops. The first execution emits a
+  snapshot of the targets.
+  With the initial_scan_only option, each execution emits a snapshot of the
+  targets and stops.
+
+RECURRING <crontab>:
+  Schedule specified as a string in crontab format.
+  All times in UTC.
+    "5 0 * * *": run schedule 5 minutes past midnight.
+    "@daily": run daily, at midnight
+  See https://en.wikipedia.org/wiki/Cron
+
+ SCHEDULE OPTIONS:
+  The schedule can be modified by specifying the following options:
+  * first_run=TIMESTAMPTZ:
+    execute the schedule at the specified time. If not specified, the default is to execute
+    the scheduled based on it's next RECURRING time.
+  * on_execution_failure='[retry|reschedule|pause]':
+    If an error occurs during the execution, handle the error based as:
+    * retry: retry execution right away
+    * reschedule: retry execution by rescheduling it based on its RECURRING expression.
+      This is the default.
+    * pause: pause this schedule.  Requires manual intervention to unpause.
+  * on_previous_running='[start|skip|wait]':
+    If the previous changefeed started by this schedule still running, handle this as:
+    * start: start this execution anyway, even if the previous one still running.
+    * skip: skip this execution, reschedule it based on RECURRING expression.
+    * wait: wait for the previous execution to complete.  This is the default.
+
+`,
+		//line sql.y: 2671
+		SeeAlso: `SHOW SCHEDULES
+`,
+	},
+	//line sql.y: 2750
+	`RESTORE`: {
+		ShortDescription: `restore data from external storage`,
+		//line sql.y: 2751
+		Category: hCCL,
+		//line sql.y: 2752
+		Text: `
 RESTORE <targets...> FROM <location...>
         [ AS OF SYSTEM TIME <expr> ]
         [ WITH <option> [= <value>] [, ...] ]
@@ -369,16 +477,16 @@ Options:
    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt backups using KMS
    detached: execute restore job asynchronously, without waiting for its completion
 `,
-		//line sql.y: 2584
+		//line sql.y: 2773
 		SeeAlso: `BACKUP, WEBDOCS/restore.html
 `,
 	},
-	//line sql.y: 2722
+	//line sql.y: 2911
 	`IMPORT`: {
 		ShortDescription: `load data from file in a distributed manner`,
-		//line sql.y: 2723
+		//line sql.y: 2912
 		Category: hCCL,
-		//line sql.y: 2724
+		//line sql.y: 2913
 		Text: `
 -- Import both schema and table data:
 IMPORT [ TABLE <tablename> FROM ]
@@ -396,6 +504,7 @@ Formats:
    CSV
    DELIMITED
    MYSQLDUMP
+   PARQUET
    PGCOPY
    PGDUMP
 
@@ -406,257 +515,311 @@ Options:
    delimiter = '...'      [CSV, PGCOPY-specific]
    nullif = '...'         [CSV, PGCOPY-specific]
    comment = '...'        [CSV-specific]
+   strict_validation      [AVRO, PARQUET-specific]
 
 `,
-		//line sql.y: 2752
+		//line sql.y: 2943
 		SeeAlso: `CREATE TABLE
 `,
 	},
-	//line sql.y: 2796
+	//line sql.y: 2987
 	`EXPORT`: {
 		ShortDescription: `export data to file in a distributed manner`,
-		//line sql.y: 2797
+		//line sql.y: 2988
 		Category: hCCL,
-		//line sql.y: 2798
+		//line sql.y: 2989
 		Text: `
 EXPORT INTO <format> <datafile> [WITH <option> [= value] [,...]] FROM <query>
 
 Formats:
    CSV
+   PARQUET
 
 Options:
-   delimiter = '...'   [CSV-specific]
+   delimiter = '...'        [CSV-specific]
+   row_group_size = '...'   [PARQUET-specific]
 
 `,
-		//line sql.y: 2807
+		//line sql.y: 3000
 		SeeAlso: `SELECT
 `,
 	},
-	//line sql.y: 2955
+	//line sql.y: 3148
 	`CANCEL`: {
-		//line sql.y: 2956
+		//line sql.y: 3149
 		Category: hGroup,
-		//line sql.y: 2957
+		//line sql.y: 3150
 		Text: `CANCEL JOBS, CANCEL QUERIES, CANCEL SESSIONS
 `,
 	},
-	//line sql.y: 2964
+	//line sql.y: 3157
 	`CANCEL JOBS`: {
 		ShortDescription: `cancel background jobs`,
-		//line sql.y: 2965
+		//line sql.y: 3158
 		Category: hMisc,
-		//line sql.y: 2966
+		//line sql.y: 3159
 		Text: `
 CANCEL JOBS <selectclause>
 CANCEL JOB <jobid>
 `,
-		//line sql.y: 2969
+		//line sql.y: 3162
 		SeeAlso: `SHOW JOBS, PAUSE JOBS, RESUME JOBS
 `,
 	},
-	//line sql.y: 2991
+	//line sql.y: 3184
 	`CANCEL QUERIES`: {
 		ShortDescription: `cancel running queries`,
-		//line sql.y: 2992
+		//line sql.y: 3185
 		Category: hMisc,
-		//line sql.y: 2993
+		//line sql.y: 3186
 		Text: `
 CANCEL QUERIES [IF EXISTS] <selectclause>
 CANCEL QUERY [IF EXISTS] <expr>
 `,
-		//line sql.y: 2996
+		//line sql.y: 3189
 		SeeAlso: `SHOW STATEMENTS
 `,
 	},
-	//line sql.y: 3027
+	//line sql.y: 3220
 	`CANCEL SESSIONS`: {
 		ShortDescription: `cancel open sessions`,
-		//line sql.y: 3028
+		//line sql.y: 3221
 		Category: hMisc,
-		//line sql.y: 3029
+		//line sql.y: 3222
 		Text: `
 CANCEL SESSIONS [IF EXISTS] <selectclause>
 CANCEL SESSION [IF EXISTS] <sessionid>
 `,
-		//line sql.y: 3032
+		//line sql.y: 3225
 		SeeAlso: `SHOW SESSIONS
 `,
 	},
-	//line sql.y: 3102
+	//line sql.y: 3295
 	`CREATE`: {
-		//line sql.y: 3103
+		//line sql.y: 3296
 		Category: hGroup,
-		//line sql.y: 3104
+		//line sql.y: 3297
 		Text: `
 CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-CREATE ROLE, CREATE TYPE, CREATE EXTENSION
+CREATE ROLE, CREATE TYPE, CREATE EXTENSION, CREATE FUNCTION
 `,
 	},
-	//line sql.y: 3117
+	//line sql.y: 3312
 	`CREATE EXTENSION`: {
-		//line sql.y: 3118
+		//line sql.y: 3313
 		Category: hCfg,
-		//line sql.y: 3119
+		//line sql.y: 3314
 		Text: `CREATE EXTENSION [IF NOT EXISTS] name
 `,
 	},
-	//line sql.y: 3197
+	//line sql.y: 3390
 	`CREATE STATISTICS`: {
 		ShortDescription: `create a new table statistic`,
-		//line sql.y: 3198
+		//line sql.y: 3391
 		Category: hMisc,
-		//line sql.y: 3199
+		//line sql.y: 3392
 		Text: `
 CREATE STATISTICS <statisticname>
   [ON <colname> [, ...]]
   FROM <tablename> [AS OF SYSTEM TIME <expr>]
 `,
 	},
-	//line sql.y: 3342
+	//line sql.y: 3551
 	`DELETE`: {
 		ShortDescription: `delete rows from a table`,
-		//line sql.y: 3343
+		//line sql.y: 3552
 		Category: hDML,
-		//line sql.y: 3344
+		//line sql.y: 3553
 		Text: `DELETE FROM <tablename> [WHERE <expr>]
               [ORDER BY <exprs...>]
               [LIMIT <expr>]
               [RETURNING <exprs...>]
 `,
-		//line sql.y: 3348
+		//line sql.y: 3557
 		SeeAlso: `WEBDOCS/delete.html
 `,
 	},
-	//line sql.y: 3368
+	//line sql.y: 3577
 	`DISCARD`: {
 		ShortDescription: `reset the session to its initial state`,
-		//line sql.y: 3369
+		//line sql.y: 3578
 		Category: hCfg,
-		//line sql.y: 3370
+		//line sql.y: 3579
 		Text: `DISCARD ALL
 `,
 	},
-	//line sql.y: 3382
+	//line sql.y: 3591
+	`LISTEN`: {
+		ShortDescription: `register the session as a listener on a notification channel`,
+		//line sql.y: 3592
+		Category: hMisc,
+		//line sql.y: 3593
+		Text: `LISTEN <channel>
+`,
+		//line sql.y: 3594
+		SeeAlso: `NOTIFY, UNLISTEN
+`,
+	},
+	//line sql.y: 3602
+	`NOTIFY`: {
+		ShortDescription: `generate a notification on a channel`,
+		//line sql.y: 3603
+		Category: hMisc,
+		//line sql.y: 3604
+		Text: `NOTIFY <channel> [, '<payload>']
+
+The notification is delivered to all listening sessions when the
+current transaction commits.
+`,
+		//line sql.y: 3608
+		SeeAlso: `LISTEN, UNLISTEN
+`,
+	},
+	//line sql.y: 3620
+	`UNLISTEN`: {
+		ShortDescription: `stop listening on a notification channel`,
+		//line sql.y: 3621
+		Category: hMisc,
+		//line sql.y: 3622
+		Text: `UNLISTEN { <channel> | * }
+`,
+		//line sql.y: 3623
+		SeeAlso: `LISTEN, NOTIFY
+`,
+	},
+	//line sql.y: 3635
 	`DROP`: {
-		//line sql.y: 3383
+		//line sql.y: 3636
 		Category: hGroup,
-		//line sql.y: 3384
+		//line sql.y: 3637
 		Text: `
 DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-DROP USER, DROP ROLE, DROP TYPE
+DROP USER, DROP ROLE, DROP TYPE, DROP FUNCTION
 `,
 	},
-	//line sql.y: 3403
+	//line sql.y: 3657
 	`DROP VIEW`: {
 		ShortDescription: `remove a view`,
-		//line sql.y: 3404
+		//line sql.y: 3658
 		Category: hDDL,
-		//line sql.y: 3405
+		//line sql.y: 3659
 		Text: `DROP [MATERIALIZED] VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
 `,
-		//line sql.y: 3406
+		//line sql.y: 3660
 		SeeAlso: `WEBDOCS/drop-index.html
 `,
 	},
-	//line sql.y: 3436
+	//line sql.y: 3690
 	`DROP SEQUENCE`: {
 		ShortDescription: `remove a sequence`,
-		//line sql.y: 3437
+		//line sql.y: 3691
 		Category: hDDL,
-		//line sql.y: 3438
+		//line sql.y: 3692
 		Text: `DROP SEQUENCE [IF EXISTS] <sequenceName> [, ...] [CASCADE | RESTRICT]
 `,
-		//line sql.y: 3439
+		//line sql.y: 3693
 		SeeAlso: `DROP
 `,
 	},
-	//line sql.y: 3451
+	//line sql.y: 3705
 	`DROP TABLE`: {
 		ShortDescription: `remove a table`,
-		//line sql.y: 3452
+		//line sql.y: 3706
 		Category: hDDL,
-		//line sql.y: 3453
+		//line sql.y: 3707
 		Text: `DROP TABLE [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
 `,
-		//line sql.y: 3454
+		//line sql.y: 3708
 		SeeAlso: `WEBDOCS/drop-table.html
 `,
 	},
-	//line sql.y: 3466
+	//line sql.y: 3720
 	`DROP INDEX`: {
 		ShortDescription: `remove an index`,
-		//line sql.y: 3467
+		//line sql.y: 3721
 		Category: hDDL,
-		//line sql.y: 3468
+		//line sql.y: 3722
 		Text: `DROP INDEX [CONCURRENTLY] [IF EXISTS] <idxname> [, ...] [CASCADE | RESTRICT]
 `,
-		//line sql.y: 3469
+		//line sql.y: 3723
 		SeeAlso: `WEBDOCS/drop-index.html
 `,
 	},
-	//line sql.y: 3491
+	//line sql.y: 3745
 	`DROP DATABASE`: {
 		ShortDescription: `remove a database`,
-		//line sql.y: 3492
+		//line sql.y: 3746
 		Category: hDDL,
-		//line sql.y: 3493
+		//line sql.y: 3747
 		Text: `DROP DATABASE [IF EXISTS] <databasename> [CASCADE | RESTRICT]
 `,
-		//line sql.y: 3494
+		//line sql.y: 3748
 		SeeAlso: `WEBDOCS/drop-database.html
 `,
 	},
-	//line sql.y: 3514
+	//line sql.y: 3768
 	`DROP TYPE`: {
 		ShortDescription: `remove a type`,
-		//line sql.y: 3515
+		//line sql.y: 3769
 		Category: hDDL,
-		//line sql.y: 3516
+		//line sql.y: 3770
 		Text: `DROP TYPE [IF EXISTS] <type_name> [, ...] [CASCASE | RESTRICT]
 `,
 	},
-	//line sql.y: 3552
+	//line sql.y: 3790
+	`DROP FUNCTION`: {
+		ShortDescription: `remove a function`,
+		//line sql.y: 3791
+		Category: hDDL,
+		//line sql.y: 3792
+		Text: `DROP FUNCTION [IF EXISTS] <funcname> [ ( [ <argtype> [, ...] ] ) ] [, ...] [CASCADE | RESTRICT]
+`,
+		//line sql.y: 3793
+		SeeAlso: `CREATE FUNCTION
+`,
+	},
+	//line sql.y: 3849
 	`DROP SCHEMA`: {
 		ShortDescription: `remove a schema`,
-		//line sql.y: 3553
+		//line sql.y: 3850
 		Category: hDDL,
-		//line sql.y: 3554
+		//line sql.y: 3851
 		Text: `DROP SCHEMA [IF EXISTS] <schema_name> [, ...] [CASCADE | RESTRICT]
 `,
 	},
-	//line sql.y: 3574
+	//line sql.y: 3871
 	`DROP ROLE`: {
 		ShortDescription: `remove a user`,
-		//line sql.y: 3575
+		//line sql.y: 3872
 		Category: hPriv,
-		//line sql.y: 3576
+		//line sql.y: 3873
 		Text: `DROP ROLE [IF EXISTS] <user> [, ...]
 `,
-		//line sql.y: 3577
+		//line sql.y: 3874
 		SeeAlso: `CREATE ROLE, SHOW ROLE
 `,
 	},
-	//line sql.y: 3601
+	//line sql.y: 3898
 	`ANALYZE`: {
 		ShortDescription: `collect table statistics`,
-		//line sql.y: 3602
+		//line sql.y: 3899
 		Category: hMisc,
-		//line sql.y: 3603
+		//line sql.y: 3900
 		Text: `
 ANALYZE <tablename>
 
 `,
-		//line sql.y: 3606
+		//line sql.y: 3903
 		SeeAlso: `CREATE STATISTICS
 `,
 	},
-	//line sql.y: 3629
+	//line sql.y: 3926
 	`EXPLAIN`: {
 		ShortDescription: `show the logical plan of a query`,
-		//line sql.y: 3630
+		//line sql.y: 3927
 		Category: hMisc,
-		//line sql.y: 3631
+		//line sql.y: 3928
 		Text: `
 EXPLAIN <statement>
 EXPLAIN ([PLAN ,] <planoptions...> ) <statement>
@@ -672,52 +835,52 @@ Plan options:
     TYPES, VERBOSE, OPT
 
 `,
-		//line sql.y: 3645
+		//line sql.y: 3942
 		SeeAlso: `WEBDOCS/explain.html
 `,
 	},
-	//line sql.y: 3752
+	//line sql.y: 4049
 	`PREPARE`: {
 		ShortDescription: `prepare a statement for later execution`,
-		//line sql.y: 3753
+		//line sql.y: 4050
 		Category: hMisc,
-		//line sql.y: 3754
+		//line sql.y: 4051
 		Text: `PREPARE <name> [ ( <types...> ) ] AS <query>
 `,
-		//line sql.y: 3755
+		//line sql.y: 4052
 		SeeAlso: `EXECUTE, DEALLOCATE, DISCARD
 `,
 	},
-	//line sql.y: 3786
+	//line sql.y: 4083
 	`EXECUTE`: {
 		ShortDescription: `execute a statement prepared previously`,
-		//line sql.y: 3787
+		//line sql.y: 4084
 		Category: hMisc,
-		//line sql.y: 3788
+		//line sql.y: 4085
 		Text: `EXECUTE <name> [ ( <exprs...> ) ]
 `,
-		//line sql.y: 3789
+		//line sql.y: 4086
 		SeeAlso: `PREPARE, DEALLOCATE, DISCARD
 `,
 	},
-	//line sql.y: 3819
+	//line sql.y: 4116
 	`DEALLOCATE`: {
 		ShortDescription: `remove a prepared statement`,
-		//line sql.y: 3820
+		//line sql.y: 4117
 		Category: hMisc,
-		//line sql.y: 3821
+		//line sql.y: 4118
 		Text: `DEALLOCATE [PREPARE] { <name> | ALL }
 `,
-		//line sql.y: 3822
+		//line sql.y: 4119
 		SeeAlso: `PREPARE, EXECUTE, DISCARD
 `,
 	},
-	//line sql.y: 3842
+	//line sql.y: 4139
 	`GRANT`: {
 		ShortDescription: `define access privileges and role memberships`,
-		//line sql.y: 3843
+		//line sql.y: 4140
 		Category: hPriv,
-		//line sql.y: 3844
+		//line sql.y: 4141
 		Text: `
 Grant privileges:
   GRANT {ALL [PRIVILEGES] | <privileges...> } ON <targets...> TO <grantees...>
@@ -725,25 +888,26 @@ Grant role membership:
   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 
 Privileges:
-  CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+  CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 
 Targets:
   DATABASE <databasename> [, ...]
   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
   TYPE <typename> [, <typename>]...
   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname>]...
+  FUNCTION <funcname> [ ( [ <argtype> [, ...] ] ) ] [, ...]
 
 `,
-		//line sql.y: 3859
+		//line sql.y: 4157
 		SeeAlso: `REVOKE, WEBDOCS/grant.html
 `,
 	},
-	//line sql.y: 3889
+	//line sql.y: 4197
 	`REVOKE`: {
 		ShortDescription: `remove access privileges and role memberships`,
-		//line sql.y: 3890
+		//line sql.y: 4198
 		Category: hPriv,
-		//line sql.y: 3891
+		//line sql.y: 4199
 		Text: `
 Revoke privileges:
   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
@@ -751,63 +915,64 @@ Revoke role membership:
   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 
 Privileges:
-  CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+  CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 
 Targets:
   DATABASE <databasename> [, <databasename>]...
   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
   TYPE <typename> [, <typename>]...
   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname]...
+  FUNCTION <funcname> [ ( [ <argtype> [, ...] ] ) ] [, ...]
 
 `,
-		//line sql.y: 3906
+		//line sql.y: 4215
 		SeeAlso: `GRANT, WEBDOCS/revoke.html
 `,
 	},
-	//line sql.y: 3974
+	//line sql.y: 4293
 	`RESET`: {
 		ShortDescription: `reset a session variable to its default value`,
-		//line sql.y: 3975
+		//line sql.y: 4294
 		Category: hCfg,
-		//line sql.y: 3976
+		//line sql.y: 4295
 		Text: `RESET [SESSION] <var>
 `,
-		//line sql.y: 3977
+		//line sql.y: 4296
 		SeeAlso: `RESET CLUSTER SETTING, WEBDOCS/set-vars.html
 `,
 	},
-	//line sql.y: 3989
+	//line sql.y: 4308
 	`RESET CLUSTER SETTING`: {
 		ShortDescription: `reset a cluster setting to its default value`,
-		//line sql.y: 3990
+		//line sql.y: 4309
 		Category: hCfg,
-		//line sql.y: 3991
+		//line sql.y: 4310
 		Text: `RESET CLUSTER SETTING <var>
 `,
-		//line sql.y: 3992
+		//line sql.y: 4311
 		SeeAlso: `SET CLUSTER SETTING, RESET
 `,
 	},
-	//line sql.y: 4001
+	//line sql.y: 4320
 	`USE`: {
 		ShortDescription: `set the current database`,
-		//line sql.y: 4002
+		//line sql.y: 4321
 		Category: hCfg,
-		//line sql.y: 4003
+		//line sql.y: 4322
 		Text: `USE <dbname>
 
 "USE <dbname>" is an alias for "SET [SESSION] database = <dbname>".
 `,
-		//line sql.y: 4006
+		//line sql.y: 4325
 		SeeAlso: `SET SESSION, WEBDOCS/set-vars.html
 `,
 	},
-	//line sql.y: 4027
+	//line sql.y: 4346
 	`SCRUB`: {
 		ShortDescription: `run checks against databases or tables`,
-		//line sql.y: 4028
+		//line sql.y: 4347
 		Category: hExperimental,
-		//line sql.y: 4029
+		//line sql.y: 4348
 		Text: `
 EXPERIMENTAL SCRUB TABLE <table> ...
 EXPERIMENTAL SCRUB DATABASE <database>
@@ -817,16 +982,16 @@ The various checks that ca be run with SCRUB includes:
   - Secondary index integrity
   - Constraint integrity (NOT NULL, CHECK, FOREIGN KEY, UNIQUE)
 `,
-		//line sql.y: 4037
+		//line sql.y: 4356
 		SeeAlso: `SCRUB TABLE, SCRUB DATABASE
 `,
 	},
-	//line sql.y: 4043
+	//line sql.y: 4362
 	`SCRUB DATABASE`: {
 		ShortDescription: `run scrub checks on a database`,
-		//line sql.y: 4044
+		//line sql.y: 4363
 		Category: hExperimental,
-		//line sql.y: 4045
+		//line sql.y: 4364
 		Text: `
 EXPERIMENTAL SCRUB DATABASE <database>
                             [AS OF SYSTEM TIME <expr>]
@@ -836,16 +1001,16 @@ All scrub checks will be run on the database. This includes:
   - Secondary index integrity
   - Constraint integrity (NOT NULL, CHECK, FOREIGN KEY, UNIQUE)
 `,
-		//line sql.y: 4053
+		//line sql.y: 4372
 		SeeAlso: `SCRUB TABLE, SCRUB
 `,
 	},
-	//line sql.y: 4061
+	//line sql.y: 4380
 	`SCRUB TABLE`: {
 		ShortDescription: `run scrub checks on a table`,
-		//line sql.y: 4062
+		//line sql.y: 4381
 		Category: hExperimental,
-		//line sql.y: 4063
+		//line sql.y: 4382
 		Text: `
 SCRUB TABLE <tablename>
             [AS OF SYSTEM TIME <expr>]
@@ -858,67 +1023,84 @@ Options:
   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS CONSTRAINT (<constraint>...)
   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS PHYSICAL
 `,
-		//line sql.y: 4074
+		//line sql.y: 4393
 		SeeAlso: `SCRUB DATABASE, SRUB
 `,
 	},
-	//line sql.y: 4129
+	//line sql.y: 4448
 	`SET CLUSTER SETTING`: {
 		ShortDescription: `change a cluster setting`,
-		//line sql.y: 4130
+		//line sql.y: 4449
 		Category: hCfg,
-		//line sql.y: 4131
+		//line sql.y: 4450
 		Text: `SET CLUSTER SETTING <var> { TO | = } <value>
 `,
-		//line sql.y: 4132
+		//line sql.y: 4451
 		SeeAlso: `SHOW CLUSTER SETTING, RESET CLUSTER SETTING, SET SESSION,
 WEBDOCS/cluster-settings.html
 `,
 	},
-	//line sql.y: 4153
+	//line sql.y: 4472
 	`SET SESSION`: {
 		ShortDescription: `change a session variable`,
-		//line sql.y: 4154
+		//line sql.y: 4473
 		Category: hCfg,
-		//line sql.y: 4155
+		//line sql.y: 4474
 		Text: `
 SET [SESSION] <var> { TO | = } <values...>
 SET [SESSION] TIME ZONE <tz>
-SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { SNAPSHOT | READ COMMITTED | SERIALIZABLE }
 SET [SESSION] TRACING { TO | = } { on | off | cluster | kv | results } [,...]
 
 `,
-		//line sql.y: 4161
+		//line sql.y: 4480
 		SeeAlso: `SHOW SESSION, RESET, DISCARD, SHOW, SET CLUSTER SETTING, SET TRANSACTION,
 WEBDOCS/set-vars.html
 `,
 	},
-	//line sql.y: 4178
+	//line sql.y: 4497
 	`SET TRANSACTION`: {
 		ShortDescription: `configure the transaction settings`,
-		//line sql.y: 4179
+		//line sql.y: 4498
 		Category: hTxn,
-		//line sql.y: 4180
+		//line sql.y: 4499
 		Text: `
 SET [SESSION] TRANSACTION <txnparameters...>
 
 Transaction parameters:
-   ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+   ISOLATION LEVEL { SNAPSHOT | READ COMMITTED | SERIALIZABLE }
    PRIORITY { LOW | NORMAL | HIGH }
    AS OF SYSTEM TIME <expr>
    [NOT] DEFERRABLE
 
 `,
-		//line sql.y: 4189
+		//line sql.y: 4508
 		SeeAlso: `SHOW TRANSACTION, SET SESSION,
 WEBDOCS/set-transaction.html
 `,
 	},
-	//line sql.y: 4381
+	//line sql.y: 4522
+	`SET CONSTRAINTS`: {
+		ShortDescription: `set constraint check timing for the current transaction`,
+		//line sql.y: 4523
+		Category: hTxn,
+		//line sql.y: 4524
+		Text: `
+SET CONSTRAINTS { ALL | <name> [, ...] } { DEFERRED | IMMEDIATE }
+
+Only constraints declared DEFERRABLE are affected. Switching a constraint
+to IMMEDIATE runs any of its checks that were deferred so far.
+
+`,
+		//line sql.y: 4530
+		SeeAlso: `SET TRANSACTION, CREATE TABLE, ALTER TABLE
+`,
+	},
+	//line sql.y: 4730
 	`SHOW`: {
-		//line sql.y: 4382
+		//line sql.y: 4731
 		Category: hGroup,
-		//line sql.y: 4383
+		//line sql.y: 4732
 		Text: `
 SHOW BACKUP, SHOW CLUSTER SETTING, SHOW COLUMNS, SHOW CONSTRAINTS,
 SHOW CREATE, SHOW DATABASES, SHOW ENUMS, SHOW HISTOGRAM, SHOW INDEXES, SHOW
@@ -929,24 +1111,85 @@ SHOW TRANSACTIONS, SHOW TYPES, SHOW USERS, SHOW LAST QUERY STATISTICS, SHOW SCHE
 SHOW LOCALITY
 `,
 	},
-	//line sql.y: 4466
+	//line sql.y: 4779
+	`CLOSE`: {
+		ShortDescription: `close a cursor`,
+		//line sql.y: 4780
+		Category: hMisc,
+		//line sql.y: 4781
+		Text: `CLOSE { <name> | ALL }
+`,
+		//line sql.y: 4782
+		SeeAlso: `DECLARE, FETCH, MOVE
+`,
+	},
+	//line sql.y: 4794
+	`DECLARE`: {
+		ShortDescription: `declare a cursor`,
+		//line sql.y: 4795
+		Category: hMisc,
+		//line sql.y: 4796
+		Text: `DECLARE <name> [INSENSITIVE] [NO SCROLL] CURSOR [WITHOUT HOLD] FOR <selectclause>
+
+Cursors can only be declared inside a transaction block and are
+closed when the transaction ends.
+`,
+		//line sql.y: 4800
+		SeeAlso: `FETCH, MOVE, CLOSE
+`,
+	},
+	//line sql.y: 4867
+	`FETCH`: {
+		ShortDescription: `fetch rows from a cursor`,
+		//line sql.y: 4868
+		Category: hMisc,
+		//line sql.y: 4869
+		Text: `
+FETCH [ <direction> [ FROM | IN ] ] <name>
+
+Direction:
+  NEXT, PRIOR, FIRST, LAST, ABSOLUTE <count>, RELATIVE <count>, <count>,
+  ALL, FORWARD [ <count> | ALL ], BACKWARD [ <count> | ALL ]
+`,
+		//line sql.y: 4875
+		SeeAlso: `DECLARE, MOVE, CLOSE
+`,
+	},
+	//line sql.y: 4883
+	`MOVE`: {
+		ShortDescription: `move a cursor without returning rows`,
+		//line sql.y: 4884
+		Category: hMisc,
+		//line sql.y: 4885
+		Text: `
+MOVE [ <direction> [ FROM | IN ] ] <name>
+
+Direction:
+  NEXT, PRIOR, FIRST, LAST, ABSOLUTE <count>, RELATIVE <count>, <count>,
+  ALL, FORWARD [ <count> | ALL ], BACKWARD [ <count> | ALL ]
+`,
+		//line sql.y: 4891
+		SeeAlso: `DECLARE, FETCH, CLOSE
+`,
+	},
+	//line sql.y: 5010
 	`SHOW SESSION`: {
 		ShortDescription: `display session variables`,
-		//line sql.y: 4467
+		//line sql.y: 5011
 		Category: hCfg,
-		//line sql.y: 4468
+		//line sql.y: 5012
 		Text: `SHOW [SESSION] { <var> | ALL }
 `,
-		//line sql.y: 4469
+		//line sql.y: 5013
 		SeeAlso: `WEBDOCS/show-vars.html
 `,
 	},
-	//line sql.y: 4490
+	//line sql.y: 5034
 	`SHOW STATISTICS`: {
 		ShortDescription: `display table statistics (experimental)`,
-		//line sql.y: 4491
+		//line sql.y: 5035
 		Category: hExperimental,
-		//line sql.y: 4492
+		//line sql.y: 5036
 		Text: `SHOW STATISTICS [USING JSON] FOR TABLE <table_name>
 
 Returns the available statistics for a table.
@@ -955,111 +1198,111 @@ be used with SHOW HISTOGRAM.
 If USING JSON is specified, the statistics and histograms
 are encoded in JSON format.
 `,
-		//line sql.y: 4499
+		//line sql.y: 5043
 		SeeAlso: `SHOW HISTOGRAM
 `,
 	},
-	//line sql.y: 4512
+	//line sql.y: 5056
 	`SHOW HISTOGRAM`: {
 		ShortDescription: `display histogram (experimental)`,
-		//line sql.y: 4513
+		//line sql.y: 5057
 		Category: hExperimental,
-		//line sql.y: 4514
+		//line sql.y: 5058
 		Text: `SHOW HISTOGRAM <histogram_id>
 
 Returns the data in the histogram with the
 given ID (as returned by SHOW STATISTICS).
 `,
-		//line sql.y: 4518
+		//line sql.y: 5062
 		SeeAlso: `SHOW STATISTICS
 `,
 	},
-	//line sql.y: 4531
+	//line sql.y: 5075
 	`SHOW BACKUP`: {
 		ShortDescription: `list backup contents`,
-		//line sql.y: 4532
+		//line sql.y: 5076
 		Category: hCCL,
-		//line sql.y: 4533
+		//line sql.y: 5077
 		Text: `SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
 `,
-		//line sql.y: 4534
+		//line sql.y: 5078
 		SeeAlso: `WEBDOCS/show-backup.html
 `,
 	},
-	//line sql.y: 4588
+	//line sql.y: 5132
 	`SHOW CLUSTER SETTING`: {
 		ShortDescription: `display cluster settings`,
-		//line sql.y: 4589
+		//line sql.y: 5133
 		Category: hCfg,
-		//line sql.y: 4590
+		//line sql.y: 5134
 		Text: `
 SHOW CLUSTER SETTING <var>
 SHOW [ PUBLIC | ALL ] CLUSTER SETTINGS
 `,
-		//line sql.y: 4593
+		//line sql.y: 5137
 		SeeAlso: `WEBDOCS/cluster-settings.html
 `,
 	},
-	//line sql.y: 4619
+	//line sql.y: 5163
 	`SHOW COLUMNS`: {
 		ShortDescription: `list columns in relation`,
-		//line sql.y: 4620
+		//line sql.y: 5164
 		Category: hDDL,
-		//line sql.y: 4621
+		//line sql.y: 5165
 		Text: `SHOW COLUMNS FROM <tablename>
 `,
-		//line sql.y: 4622
+		//line sql.y: 5166
 		SeeAlso: `WEBDOCS/show-columns.html
 `,
 	},
-	//line sql.y: 4630
+	//line sql.y: 5174
 	`SHOW PARTITIONS`: {
 		ShortDescription: `list partition information`,
-		//line sql.y: 4631
+		//line sql.y: 5175
 		Category: hDDL,
-		//line sql.y: 4632
+		//line sql.y: 5176
 		Text: `SHOW PARTITIONS FROM { TABLE <table> | INDEX <index> | DATABASE <database> }
 `,
-		//line sql.y: 4633
+		//line sql.y: 5177
 		SeeAlso: `WEBDOCS/show-partitions.html
 `,
 	},
-	//line sql.y: 4653
+	//line sql.y: 5197
 	`SHOW DATABASES`: {
 		ShortDescription: `list databases`,
-		//line sql.y: 4654
+		//line sql.y: 5198
 		Category: hDDL,
-		//line sql.y: 4655
+		//line sql.y: 5199
 		Text: `SHOW DATABASES
 `,
-		//line sql.y: 4656
+		//line sql.y: 5200
 		SeeAlso: `WEBDOCS/show-databases.html
 `,
 	},
-	//line sql.y: 4664
+	//line sql.y: 5208
 	`SHOW ENUMS`: {
 		ShortDescription: `list enums`,
-		//line sql.y: 4665
+		//line sql.y: 5209
 		Category: hMisc,
-		//line sql.y: 4666
+		//line sql.y: 5210
 		Text: `SHOW ENUMS
 `,
 	},
-	//line sql.y: 4694
+	//line sql.y: 5238
 	`SHOW TYPES`: {
 		ShortDescription: `list user defined types`,
-		//line sql.y: 4695
+		//line sql.y: 5239
 		Category: hMisc,
-		//line sql.y: 4696
+		//line sql.y: 5240
 		Text: `SHOW TYPES
 `,
 	},
-	//line sql.y: 4704
+	//line sql.y: 5248
 	`SHOW GRANTS`: {
 		ShortDescription: `list grants`,
-		//line sql.y: 4705
+		//line sql.y: 5249
 		Category: hPriv,
-		//line sql.y: 4706
+		//line sql.y: 5250
 		Text: `
 Show privilege grants:
   SHOW GRANTS [ON <targets...>] [FOR <users...>]
@@ -1067,244 +1310,246 @@ Show role grants:
   SHOW GRANTS ON ROLE [<roles...>] [FOR <grantees...>]
 
 `,
-		//line sql.y: 4712
+		//line sql.y: 5256
 		SeeAlso: `WEBDOCS/show-grants.html
 `,
 	},
-	//line sql.y: 4725
+	//line sql.y: 5269
 	`SHOW INDEXES`: {
 		ShortDescription: `list indexes`,
-		//line sql.y: 4726
+		//line sql.y: 5270
 		Category: hDDL,
-		//line sql.y: 4727
+		//line sql.y: 5271
 		Text: `SHOW INDEXES FROM { <tablename> | DATABASE <database_name> } [WITH COMMENT]
 `,
-		//line sql.y: 4728
+		//line sql.y: 5272
 		SeeAlso: `WEBDOCS/show-index.html
 `,
 	},
-	//line sql.y: 4758
+	//line sql.y: 5302
 	`SHOW CONSTRAINTS`: {
 		ShortDescription: `list constraints`,
-		//line sql.y: 4759
+		//line sql.y: 5303
 		Category: hDDL,
-		//line sql.y: 4760
+		//line sql.y: 5304
 		Text: `SHOW CONSTRAINTS FROM <tablename>
 `,
-		//line sql.y: 4761
+		//line sql.y: 5305
 		SeeAlso: `WEBDOCS/show-constraints.html
 `,
 	},
-	//line sql.y: 4774
+	//line sql.y: 5318
 	`SHOW STATEMENTS`: {
 		ShortDescription: `list running statements`,
-		//line sql.y: 4775
+		//line sql.y: 5319
 		Category: hMisc,
-		//line sql.y: 4776
+		//line sql.y: 5320
 		Text: `SHOW [ALL] [CLUSTER | LOCAL] STATEMENTS
 `,
-		//line sql.y: 4777
+		//line sql.y: 5321
 		SeeAlso: `CANCEL QUERIES
 `,
 	},
-	//line sql.y: 4804
+	//line sql.y: 5348
 	`SHOW JOBS`: {
 		ShortDescription: `list background jobs`,
-		//line sql.y: 4805
+		//line sql.y: 5349
 		Category: hMisc,
-		//line sql.y: 4806
+		//line sql.y: 5350
 		Text: `
 SHOW [AUTOMATIC] JOBS [select clause]
 SHOW JOBS FOR SCHEDULES [select clause]
 SHOW JOB <jobid>
 `,
-		//line sql.y: 4810
+		//line sql.y: 5354
 		SeeAlso: `CANCEL JOBS, PAUSE JOBS, RESUME JOBS
 `,
 	},
-	//line sql.y: 4854
+	//line sql.y: 5398
 	`SHOW SCHEDULES`: {
 		ShortDescription: `list periodic schedules`,
-		//line sql.y: 4855
+		//line sql.y: 5399
 		Category: hMisc,
-		//line sql.y: 4856
+		//line sql.y: 5400
 		Text: `
-SHOW [RUNNING | PAUSED] SCHEDULES [FOR BACKUP]
+SHOW [RUNNING | PAUSED] SCHEDULES [FOR {BACKUP | EXPORT | CHANGEFEED}]
 SHOW SCHEDULE <schedule_id>
 `,
-		//line sql.y: 4859
+		//line sql.y: 5403
 		SeeAlso: `PAUSE SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
 `,
 	},
-	//line sql.y: 4906
+	//line sql.y: 5458
 	`SHOW TRACE`: {
 		ShortDescription: `display an execution trace`,
-		//line sql.y: 4907
+		//line sql.y: 5459
 		Category: hMisc,
-		//line sql.y: 4908
+		//line sql.y: 5460
 		Text: `
 SHOW [COMPACT] [KV] TRACE FOR SESSION
 `,
-		//line sql.y: 4910
+		//line sql.y: 5462
 		SeeAlso: `EXPLAIN
 `,
 	},
-	//line sql.y: 4933
+	//line sql.y: 5485
 	`SHOW SESSIONS`: {
 		ShortDescription: `list open client sessions`,
-		//line sql.y: 4934
+		//line sql.y: 5486
 		Category: hMisc,
-		//line sql.y: 4935
+		//line sql.y: 5487
 		Text: `SHOW [ALL] [CLUSTER | LOCAL] SESSIONS
 `,
-		//line sql.y: 4936
+		//line sql.y: 5488
 		SeeAlso: `CANCEL SESSIONS
 `,
 	},
-	//line sql.y: 4949
+	//line sql.y: 5501
 	`SHOW TABLES`: {
 		ShortDescription: `list tables`,
-		//line sql.y: 4950
+		//line sql.y: 5502
 		Category: hDDL,
-		//line sql.y: 4951
+		//line sql.y: 5503
 		Text: `SHOW TABLES [FROM <databasename> [ . <schemaname> ] ] [WITH COMMENT]
 `,
-		//line sql.y: 4952
+		//line sql.y: 5504
 		SeeAlso: `WEBDOCS/show-tables.html
 `,
 	},
-	//line sql.y: 4980
+	//line sql.y: 5532
 	`SHOW TRANSACTIONS`: {
 		ShortDescription: `list open client transactions across the cluster`,
-		//line sql.y: 4981
+		//line sql.y: 5533
 		Category: hMisc,
-		//line sql.y: 4982
+		//line sql.y: 5534
 		Text: `SHOW [ALL] [CLUSTER | LOCAL] TRANSACTIONS
 `,
 	},
-	//line sql.y: 4999
+	//line sql.y: 5551
 	`SHOW SCHEMAS`: {
 		ShortDescription: `list schemas`,
-		//line sql.y: 5000
+		//line sql.y: 5552
 		Category: hDDL,
-		//line sql.y: 5001
+		//line sql.y: 5553
 		Text: `SHOW SCHEMAS [FROM <databasename> ]
 `,
 	},
-	//line sql.y: 5013
+	//line sql.y: 5565
 	`SHOW SEQUENCES`: {
 		ShortDescription: `list sequences`,
-		//line sql.y: 5014
+		//line sql.y: 5566
 		Category: hDDL,
-		//line sql.y: 5015
+		//line sql.y: 5567
 		Text: `SHOW SEQUENCES [FROM <databasename> ]
 `,
 	},
-	//line sql.y: 5027
+	//line sql.y: 5579
 	`SHOW SYNTAX`: {
 		ShortDescription: `analyze SQL syntax`,
-		//line sql.y: 5028
+		//line sql.y: 5580
 		Category: hMisc,
-		//line sql.y: 5029
+		//line sql.y: 5581
 		Text: `SHOW SYNTAX <string>
 `,
 	},
-	//line sql.y: 5045
+	//line sql.y: 5597
 	`SHOW SAVEPOINT`: {
 		ShortDescription: `display current savepoint properties`,
-		//line sql.y: 5046
+		//line sql.y: 5598
 		Category: hCfg,
-		//line sql.y: 5047
+		//line sql.y: 5599
 		Text: `SHOW SAVEPOINT STATUS
 `,
 	},
-	//line sql.y: 5055
+	//line sql.y: 5607
 	`SHOW TRANSACTION`: {
 		ShortDescription: `display current transaction properties`,
-		//line sql.y: 5056
+		//line sql.y: 5608
 		Category: hCfg,
-		//line sql.y: 5057
+		//line sql.y: 5609
 		Text: `SHOW TRANSACTION {ISOLATION LEVEL | PRIORITY | STATUS}
 `,
-		//line sql.y: 5058
+		//line sql.y: 5610
 		SeeAlso: `WEBDOCS/show-transaction.html
 `,
 	},
-	//line sql.y: 5077
+	//line sql.y: 5629
 	`SHOW CREATE`: {
-		ShortDescription: `display the CREATE statement for a table, sequence or view`,
-		//line sql.y: 5078
+		ShortDescription: `display the CREATE statement for a table, sequence, view or function`,
+		//line sql.y: 5630
 		Category: hDDL,
-		//line sql.y: 5079
-		Text: `SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+		//line sql.y: 5631
+		Text: `
+SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+SHOW CREATE FUNCTION <funcname>
 `,
-		//line sql.y: 5080
+		//line sql.y: 5634
 		SeeAlso: `WEBDOCS/show-create-table.html
 `,
 	},
-	//line sql.y: 5098
+	//line sql.y: 5656
 	`SHOW USERS`: {
 		ShortDescription: `list defined users`,
-		//line sql.y: 5099
+		//line sql.y: 5657
 		Category: hPriv,
-		//line sql.y: 5100
+		//line sql.y: 5658
 		Text: `SHOW USERS
 `,
-		//line sql.y: 5101
+		//line sql.y: 5659
 		SeeAlso: `CREATE USER, DROP USER, WEBDOCS/show-users.html
 `,
 	},
-	//line sql.y: 5109
+	//line sql.y: 5667
 	`SHOW ROLES`: {
 		ShortDescription: `list defined roles`,
-		//line sql.y: 5110
+		//line sql.y: 5668
 		Category: hPriv,
-		//line sql.y: 5111
+		//line sql.y: 5669
 		Text: `SHOW ROLES
 `,
-		//line sql.y: 5112
+		//line sql.y: 5670
 		SeeAlso: `CREATE ROLE, ALTER ROLE, DROP ROLE
 `,
 	},
-	//line sql.y: 5172
+	//line sql.y: 5730
 	`SHOW RANGE`: {
 		ShortDescription: `show range information for a row`,
-		//line sql.y: 5173
+		//line sql.y: 5731
 		Category: hMisc,
-		//line sql.y: 5174
+		//line sql.y: 5732
 		Text: `
 SHOW RANGE FROM TABLE <tablename> FOR ROW (value1, value2, ...)
 SHOW RANGE FROM INDEX [ <tablename> @ ] <indexname> FOR ROW (value1, value2, ...)
 `,
 	},
-	//line sql.y: 5195
+	//line sql.y: 5753
 	`SHOW RANGES`: {
 		ShortDescription: `list ranges`,
-		//line sql.y: 5196
+		//line sql.y: 5754
 		Category: hMisc,
-		//line sql.y: 5197
+		//line sql.y: 5755
 		Text: `
 SHOW RANGES FROM TABLE <tablename>
 SHOW RANGES FROM INDEX [ <tablename> @ ] <indexname>
 `,
 	},
-	//line sql.y: 5216
+	//line sql.y: 5774
 	`SHOW SURVIVAL GOAL`: {
 		ShortDescription: `shows survival goals`,
-		//line sql.y: 5217
+		//line sql.y: 5775
 		Category: hDDL,
-		//line sql.y: 5218
+		//line sql.y: 5776
 		Text: `
 SHOW SURVIVAL GOAL FROM DATABASE
 SHOW SURVIVAL GOAL FROM DATABASE <database>
 `,
 	},
-	//line sql.y: 5233
+	//line sql.y: 5791
 	`SHOW REGIONS`: {
 		ShortDescription: `shows regions`,
-		//line sql.y: 5234
+		//line sql.y: 5792
 		Category: hDDL,
-		//line sql.y: 5235
+		//line sql.y: 5793
 		Text: `
 SHOW REGIONS
 SHOW REGIONS FROM ALL DATABASES
@@ -1313,11 +1558,11 @@ SHOW REGIONS FROM DATABASE
 SHOW REGIONS FROM DATABASE <database>
 `,
 	},
-	//line sql.y: 5516
+	//line sql.y: 6074
 	`PAUSE`: {
-		//line sql.y: 5517
+		//line sql.y: 6075
 		Category: hMisc,
-		//line sql.y: 5518
+		//line sql.y: 6076
 		Text: `
 
 Pause various background tasks and activities.
@@ -1325,11 +1570,11 @@ Pause various background tasks and activities.
 PAUSE JOBS, PAUSE SCHEDULES
 `,
 	},
-	//line sql.y: 5528
+	//line sql.y: 6086
 	`RESUME`: {
-		//line sql.y: 5529
+		//line sql.y: 6087
 		Category: hMisc,
-		//line sql.y: 5530
+		//line sql.y: 6088
 		Text: `
 
 Resume various background tasks and activities.
@@ -1337,51 +1582,51 @@ Resume various background tasks and activities.
 RESUME JOBS, RESUME SCHEDULES
 `,
 	},
-	//line sql.y: 5540
+	//line sql.y: 6098
 	`PAUSE JOBS`: {
 		ShortDescription: `pause background jobs`,
-		//line sql.y: 5541
+		//line sql.y: 6099
 		Category: hMisc,
-		//line sql.y: 5542
+		//line sql.y: 6100
 		Text: `
 PAUSE JOBS <selectclause>
 PAUSE JOB <jobid>
 `,
-		//line sql.y: 5545
+		//line sql.y: 6103
 		SeeAlso: `SHOW JOBS, CANCEL JOBS, RESUME JOBS
 `,
 	},
-	//line sql.y: 5580
+	//line sql.y: 6138
 	`PAUSE SCHEDULES`: {
 		ShortDescription: `pause scheduled jobs`,
-		//line sql.y: 5581
+		//line sql.y: 6139
 		Category: hMisc,
-		//line sql.y: 5582
+		//line sql.y: 6140
 		Text: `
 PAUSE SCHEDULES <selectclause>
   select clause: select statement returning schedule id to pause.
 PAUSE SCHEDULE <scheduleID>
 `,
-		//line sql.y: 5586
+		//line sql.y: 6144
 		SeeAlso: `RESUME SCHEDULES, SHOW JOBS, CANCEL JOBS
 `,
 	},
-	//line sql.y: 5607
+	//line sql.y: 6165
 	`CREATE SCHEMA`: {
 		ShortDescription: `create a new schema`,
-		//line sql.y: 5608
+		//line sql.y: 6166
 		Category: hDDL,
-		//line sql.y: 5609
+		//line sql.y: 6167
 		Text: `
 CREATE SCHEMA [IF NOT EXISTS] { [<databasename>.]<schemaname> | [[<databasename>.]<schemaname>] AUTHORIZATION <rolename> }
 `,
 	},
-	//line sql.y: 5642
+	//line sql.y: 6200
 	`ALTER SCHEMA`: {
 		ShortDescription: `alter an existing schema`,
-		//line sql.y: 5643
+		//line sql.y: 6201
 		Category: hDDL,
-		//line sql.y: 5644
+		//line sql.y: 6202
 		Text: `
 
 Commands:
@@ -1389,12 +1634,12 @@ Commands:
   ALTER SCHEMA ... OWNER TO {<newowner> | CURRENT_USER | SESSION_USER }
 `,
 	},
-	//line sql.y: 5670
+	//line sql.y: 6228
 	`CREATE TABLE`: {
 		ShortDescription: `create a new table`,
-		//line sql.y: 5671
+		//line sql.y: 6229
 		Category: hDDL,
-		//line sql.y: 5672
+		//line sql.y: 6230
 		Text: `
 CREATE [[GLOBAL | LOCAL] {TEMPORARY | TEMP}] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>] [<on_commit>]
 CREATE [[GLOBAL | LOCAL] {TEMPORARY | TEMP}] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source> [<interleave>] [<on commit>]
@@ -1426,18 +1671,18 @@ On commit clause:
    ON COMMIT {PRESERVE ROWS | DROP | DELETE ROWS}
 
 `,
-		//line sql.y: 5702
+		//line sql.y: 6260
 		SeeAlso: `SHOW TABLES, CREATE VIEW, SHOW CREATE,
 WEBDOCS/create-table.html
 WEBDOCS/create-table-as.html
 `,
 	},
-	//line sql.y: 6622
+	//line sql.y: 7216
 	`CREATE SEQUENCE`: {
 		ShortDescription: `create a new sequence`,
-		//line sql.y: 6623
+		//line sql.y: 7217
 		Category: hDDL,
-		//line sql.y: 6624
+		//line sql.y: 7218
 		Text: `
 CREATE [TEMPORARY | TEMP] SEQUENCE <seqname>
   [INCREMENT <increment>]
@@ -1449,73 +1694,92 @@ CREATE [TEMPORARY | TEMP] SEQUENCE <seqname>
   [VIRTUAL]
 
 `,
-		//line sql.y: 6634
+		//line sql.y: 7228
 		SeeAlso: `CREATE TABLE
 `,
 	},
-	//line sql.y: 6699
+	//line sql.y: 7293
 	`TRUNCATE`: {
 		ShortDescription: `empty one or more tables`,
-		//line sql.y: 6700
+		//line sql.y: 7294
 		Category: hDML,
-		//line sql.y: 6701
+		//line sql.y: 7295
 		Text: `TRUNCATE [TABLE] <tablename> [, ...] [CASCADE | RESTRICT]
 `,
-		//line sql.y: 6702
+		//line sql.y: 7296
 		SeeAlso: `WEBDOCS/truncate.html
 `,
 	},
-	//line sql.y: 6720
+	//line sql.y: 7314
 	`CREATE ROLE`: {
 		ShortDescription: `define a new role`,
-		//line sql.y: 6721
+		//line sql.y: 7315
 		Category: hPriv,
-		//line sql.y: 6722
+		//line sql.y: 7316
 		Text: `CREATE ROLE [IF NOT EXISTS] <name> [ [WITH] <OPTIONS...> ]
 `,
-		//line sql.y: 6723
+		//line sql.y: 7317
 		SeeAlso: `ALTER ROLE, DROP ROLE, SHOW ROLES
 `,
 	},
-	//line sql.y: 6735
+	//line sql.y: 7329
 	`ALTER ROLE`: {
 		ShortDescription: `alter a role`,
-		//line sql.y: 6736
+		//line sql.y: 7330
 		Category: hPriv,
-		//line sql.y: 6737
+		//line sql.y: 7331
 		Text: `ALTER ROLE <name> [WITH] <options...>
 `,
-		//line sql.y: 6738
+		//line sql.y: 7332
 		SeeAlso: `CREATE ROLE, DROP ROLE, SHOW ROLES
 `,
 	},
-	//line sql.y: 6767
+	//line sql.y: 7361
 	`CREATE VIEW`: {
 		ShortDescription: `create a new view`,
-		//line sql.y: 6768
+		//line sql.y: 7362
 		Category: hDDL,
-		//line sql.y: 6769
+		//line sql.y: 7363
 		Text: `CREATE [TEMPORARY | TEMP] [MATERIALIZED] VIEW [IF NOT EXISTS] <viewname> [( <colnames...> )] AS <source>
 `,
-		//line sql.y: 6770
+		//line sql.y: 7364
 		SeeAlso: `CREATE TABLE, SHOW CREATE, WEBDOCS/create-view.html
 `,
 	},
-	//line sql.y: 6945
+	//line sql.y: 7539
+	`CREATE FUNCTION`: {
+		ShortDescription: `create a user-defined function`,
+		//line sql.y: 7540
+		Category: hDDL,
+		//line sql.y: 7541
+		Text: `
+CREATE [OR REPLACE] FUNCTION <funcname> ( [ [<argname>] <argtype> [, ...] ] )
+  RETURNS <rettype>
+  LANGUAGE SQL
+  [ IMMUTABLE | STABLE | VOLATILE ]
+  [ [NOT] LEAKPROOF ]
+  [ CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT ]
+  AS '<body>'
+`,
+		//line sql.y: 7549
+		SeeAlso: `DROP FUNCTION, SHOW CREATE
+`,
+	},
+	//line sql.y: 7659
 	`CREATE TYPE`: {
 		ShortDescription: `- create a type`,
-		//line sql.y: 6946
+		//line sql.y: 7660
 		Category: hDDL,
-		//line sql.y: 6947
+		//line sql.y: 7661
 		Text: `CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
 `,
 	},
-	//line sql.y: 6999
+	//line sql.y: 7713
 	`CREATE INDEX`: {
 		ShortDescription: `create a new index`,
-		//line sql.y: 7000
+		//line sql.y: 7714
 		Category: hDDL,
-		//line sql.y: 7001
+		//line sql.y: 7715
 		Text: `
 CREATE [UNIQUE | INVERTED] INDEX [CONCURRENTLY] [IF NOT EXISTS] [<idxname>]
        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
@@ -1527,43 +1791,43 @@ Interleave clause:
    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
 
 `,
-		//line sql.y: 7011
+		//line sql.y: 7725
 		SeeAlso: `CREATE TABLE, SHOW INDEXES, SHOW CREATE,
 WEBDOCS/create-index.html
 `,
 	},
-	//line sql.y: 7599
+	//line sql.y: 8313
 	`RELEASE`: {
 		ShortDescription: `complete a sub-transaction`,
-		//line sql.y: 7600
+		//line sql.y: 8314
 		Category: hTxn,
-		//line sql.y: 7601
+		//line sql.y: 8315
 		Text: `RELEASE [SAVEPOINT] <savepoint name>
 `,
-		//line sql.y: 7602
+		//line sql.y: 8316
 		SeeAlso: `SAVEPOINT, WEBDOCS/savepoint.html
 `,
 	},
-	//line sql.y: 7610
+	//line sql.y: 8324
 	`RESUME JOBS`: {
 		ShortDescription: `resume background jobs`,
-		//line sql.y: 7611
+		//line sql.y: 8325
 		Category: hMisc,
-		//line sql.y: 7612
+		//line sql.y: 8326
 		Text: `
 RESUME JOBS <selectclause>
 RESUME JOB <jobid>
 `,
-		//line sql.y: 7615
+		//line sql.y: 8329
 		SeeAlso: `SHOW JOBS, CANCEL JOBS, PAUSE JOBS
 `,
 	},
-	//line sql.y: 7637
+	//line sql.y: 8351
 	`RESUME SCHEDULES`: {
 		ShortDescription: `resume executing scheduled jobs`,
-		//line sql.y: 7638
+		//line sql.y: 8352
 		Category: hMisc,
-		//line sql.y: 7639
+		//line sql.y: 8353
 		Text: `
 RESUME SCHEDULES <selectclause>
  selectclause: select statement returning schedule IDs to resume.
@@ -1571,16 +1835,16 @@ RESUME SCHEDULES <selectclause>
 RESUME SCHEDULES <jobid>
 
 `,
-		//line sql.y: 7645
+		//line sql.y: 8359
 		SeeAlso: `PAUSE SCHEDULES, SHOW JOBS, RESUME JOBS
 `,
 	},
-	//line sql.y: 7666
+	//line sql.y: 8380
 	`DROP SCHEDULES`: {
 		ShortDescription: `destroy specified schedules`,
-		//line sql.y: 7667
+		//line sql.y: 8381
 		Category: hMisc,
-		//line sql.y: 7668
+		//line sql.y: 8382
 		Text: `
 DROP SCHEDULES <selectclause>
  selectclause: select statement returning schedule IDs to resume.
@@ -1588,87 +1852,87 @@ DROP SCHEDULES <selectclause>
 DROP SCHEDULE <scheduleid>
 
 `,
-		//line sql.y: 7674
+		//line sql.y: 8388
 		SeeAlso: `PAUSE SCHEDULES, SHOW JOBS, CANCEL JOBS
 `,
 	},
-	//line sql.y: 7695
+	//line sql.y: 8409
 	`SAVEPOINT`: {
 		ShortDescription: `start a sub-transaction`,
-		//line sql.y: 7696
+		//line sql.y: 8410
 		Category: hTxn,
-		//line sql.y: 7697
+		//line sql.y: 8411
 		Text: `SAVEPOINT <savepoint name>
 `,
-		//line sql.y: 7698
+		//line sql.y: 8412
 		SeeAlso: `RELEASE, WEBDOCS/savepoint.html
 `,
 	},
-	//line sql.y: 7713
+	//line sql.y: 8427
 	`BEGIN`: {
 		ShortDescription: `start a transaction`,
-		//line sql.y: 7714
+		//line sql.y: 8428
 		Category: hTxn,
-		//line sql.y: 7715
+		//line sql.y: 8429
 		Text: `
 BEGIN [TRANSACTION] [ <txnparameter> [[,] ...] ]
 START TRANSACTION [ <txnparameter> [[,] ...] ]
 
 Transaction parameters:
-   ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+   ISOLATION LEVEL { SNAPSHOT | READ COMMITTED | SERIALIZABLE }
    PRIORITY { LOW | NORMAL | HIGH }
 
 `,
-		//line sql.y: 7723
+		//line sql.y: 8437
 		SeeAlso: `COMMIT, ROLLBACK, WEBDOCS/begin-transaction.html
 `,
 	},
-	//line sql.y: 7736
+	//line sql.y: 8450
 	`COMMIT`: {
 		ShortDescription: `commit the current transaction`,
-		//line sql.y: 7737
+		//line sql.y: 8451
 		Category: hTxn,
-		//line sql.y: 7738
+		//line sql.y: 8452
 		Text: `
 COMMIT [TRANSACTION]
 END [TRANSACTION]
 `,
-		//line sql.y: 7741
+		//line sql.y: 8455
 		SeeAlso: `BEGIN, ROLLBACK, WEBDOCS/commit-transaction.html
 `,
 	},
-	//line sql.y: 7765
+	//line sql.y: 8479
 	`ROLLBACK`: {
 		ShortDescription: `abort the current (sub-)transaction`,
-		//line sql.y: 7766
+		//line sql.y: 8480
 		Category: hTxn,
-		//line sql.y: 7767
+		//line sql.y: 8481
 		Text: `
 ROLLBACK [TRANSACTION]
 ROLLBACK [TRANSACTION] TO [SAVEPOINT] <savepoint name>
 `,
-		//line sql.y: 7770
+		//line sql.y: 8484
 		SeeAlso: `BEGIN, COMMIT, SAVEPOINT, WEBDOCS/rollback-transaction.html
 `,
 	},
-	//line sql.y: 7884
+	//line sql.y: 8598
 	`CREATE DATABASE`: {
 		ShortDescription: `create a new database`,
-		//line sql.y: 7885
+		//line sql.y: 8599
 		Category: hDDL,
-		//line sql.y: 7886
+		//line sql.y: 8600
 		Text: `CREATE DATABASE [IF NOT EXISTS] <name>
 `,
-		//line sql.y: 7887
+		//line sql.y: 8601
 		SeeAlso: `WEBDOCS/create-database.html
 `,
 	},
-	//line sql.y: 8030
+	//line sql.y: 8744
 	`INSERT`: {
 		ShortDescription: `create new rows in a table`,
-		//line sql.y: 8031
+		//line sql.y: 8745
 		Category: hDML,
-		//line sql.y: 8032
+		//line sql.y: 8746
 		Text: `
 INSERT INTO <tablename> [[AS] <name>] [( <colnames...> )]
        <selectclause>
@@ -1678,31 +1942,31 @@ INSERT INTO <tablename> [[AS] <name>] [( <colnames...> )]
        }
        [RETURNING <exprs...>]
 `,
-		//line sql.y: 8040
+		//line sql.y: 8754
 		SeeAlso: `UPSERT, UPDATE, DELETE, WEBDOCS/insert.html
 `,
 	},
-	//line sql.y: 8059
+	//line sql.y: 8773
 	`UPSERT`: {
 		ShortDescription: `create or replace rows in a table`,
-		//line sql.y: 8060
+		//line sql.y: 8774
 		Category: hDML,
-		//line sql.y: 8061
+		//line sql.y: 8775
 		Text: `
 UPSERT INTO <tablename> [AS <name>] [( <colnames...> )]
        <selectclause>
        [RETURNING <exprs...>]
 `,
-		//line sql.y: 8065
+		//line sql.y: 8779
 		SeeAlso: `INSERT, UPDATE, DELETE, WEBDOCS/upsert.html
 `,
 	},
-	//line sql.y: 8181
+	//line sql.y: 8895
 	`UPDATE`: {
 		ShortDescription: `update rows of a table`,
-		//line sql.y: 8182
+		//line sql.y: 8896
 		Category: hDML,
-		//line sql.y: 8183
+		//line sql.y: 8897
 		Text: `
 UPDATE <tablename> [[AS] <name>]
        SET ...
@@ -1711,42 +1975,42 @@ UPDATE <tablename> [[AS] <name>]
        [LIMIT <expr>]
        [RETURNING <exprs...>]
 `,
-		//line sql.y: 8190
+		//line sql.y: 8904
 		SeeAlso: `INSERT, UPSERT, DELETE, WEBDOCS/update.html
 `,
 	},
-	//line sql.y: 8246
+	//line sql.y: 8960
 	`REASSIGN OWNED BY`: {
 		ShortDescription: `change ownership of all objects`,
-		//line sql.y: 8247
+		//line sql.y: 8961
 		Category: hPriv,
-		//line sql.y: 8248
+		//line sql.y: 8962
 		Text: `REASSIGN OWNED BY {<name> | CURRENT_USER | SESSION_USER}[,...]
 TO {<name> | CURRENT_USER | SESSION_USER}
 `,
-		//line sql.y: 8250
+		//line sql.y: 8964
 		SeeAlso: `DROP OWNED BY
 `,
 	},
-	//line sql.y: 8261
+	//line sql.y: 8975
 	`DROP OWNED BY`: {
 		ShortDescription: `remove database objects owned by role(s).`,
-		//line sql.y: 8262
+		//line sql.y: 8976
 		Category: hPriv,
-		//line sql.y: 8263
+		//line sql.y: 8977
 		Text: `DROP OWNED BY {<name> | CURRENT_USER | SESSION_USER}[,...]
 [RESTRICT | CASCADE]
 `,
-		//line sql.y: 8265
+		//line sql.y: 8979
 		SeeAlso: `REASSIGN OWNED BY
 `,
 	},
-	//line sql.y: 8445
+	//line sql.y: 9159
 	`<SELECTCLAUSE>`: {
 		ShortDescription: `access tabular data`,
-		//line sql.y: 8446
+		//line sql.y: 9160
 		Category: hDML,
-		//line sql.y: 8447
+		//line sql.y: 9161
 		Text: `
 Select clause:
   TABLE <tablename>
@@ -1754,12 +2018,12 @@ Select clause:
   SELECT ... [ { INTERSECT | UNION | EXCEPT } [ ALL | DISTINCT ] <selectclause> ]
 `,
 	},
-	//line sql.y: 8458
+	//line sql.y: 9172
 	`SELECT`: {
 		ShortDescription: `retrieve rows from a data source and compute a result`,
-		//line sql.y: 8459
+		//line sql.y: 9173
 		Category: hDML,
-		//line sql.y: 8460
+		//line sql.y: 9174
 		Text: `
 SELECT [DISTINCT [ ON ( <expr> [ , ... ] ) ] ]
        { <expr> [[AS] <name>] | [ [<dbname>.] <tablename>. ] * } [, ...]
@@ -1773,40 +2037,40 @@ SELECT [DISTINCT [ ON ( <expr> [ , ... ] ) ] ]
        [ LIMIT { <expr> | ALL } ]
        [ OFFSET <expr> [ ROW | ROWS ] ]
 `,
-		//line sql.y: 8472
+		//line sql.y: 9186
 		SeeAlso: `WEBDOCS/select-clause.html
 `,
 	},
-	//line sql.y: 8547
+	//line sql.y: 9261
 	`TABLE`: {
 		ShortDescription: `select an entire table`,
-		//line sql.y: 8548
+		//line sql.y: 9262
 		Category: hDML,
-		//line sql.y: 8549
+		//line sql.y: 9263
 		Text: `TABLE <tablename>
 `,
-		//line sql.y: 8550
+		//line sql.y: 9264
 		SeeAlso: `SELECT, VALUES, WEBDOCS/table-expressions.html
 `,
 	},
-	//line sql.y: 8924
+	//line sql.y: 9647
 	`VALUES`: {
 		ShortDescription: `select a given set of values`,
-		//line sql.y: 8925
+		//line sql.y: 9648
 		Category: hDML,
-		//line sql.y: 8926
+		//line sql.y: 9649
 		Text: `VALUES ( <exprs...> ) [, ...]
 `,
-		//line sql.y: 8927
+		//line sql.y: 9650
 		SeeAlso: `SELECT, TABLE, WEBDOCS/table-expressions.html
 `,
 	},
-	//line sql.y: 9036
+	//line sql.y: 9759
 	`<SOURCE>`: {
 		ShortDescription: `define a data source for SELECT`,
-		//line sql.y: 9037
+		//line sql.y: 9760
 		Category: hDML,
-		//line sql.y: 9038
+		//line sql.y: 9761
 		Text: `
 Data sources:
   <tablename> [ @ { <idxname> | <indexflags> } ]
@@ -1830,7 +2094,7 @@ Join types:
   { INNER | { LEFT | RIGHT | FULL } [OUTER] } [ { HASH | MERGE | LOOKUP | INVERTED } ]
 
 `,
-		//line sql.y: 9060
+		//line sql.y: 9783
 		SeeAlso: `WEBDOCS/table-expressions.html
 `,
 	},
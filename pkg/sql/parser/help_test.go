@@ -145,6 +145,10 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
 		{`DROP TYPE ??`, `DROP TYPE`},
+		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
+		{`CREATE OR REPLACE FUNCTION ??`, `CREATE FUNCTION`},
+		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+		{`DROP FUNCTION IF EXISTS ??`, `DROP FUNCTION`},
 
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
@@ -158,6 +162,18 @@ func TestContextualHelp(t *testing.T) {
 		{`DISCARD ALL ??`, `DISCARD`},
 		{`DISCARD ??`, `DISCARD`},
 
+		{`CLOSE ??`, `CLOSE`},
+		{`DECLARE ??`, `DECLARE`},
+		{`DECLARE foo ??`, `DECLARE`},
+		{`FETCH ??`, `FETCH`},
+		{`FETCH 5 ??`, `FETCH`},
+		{`MOVE ??`, `MOVE`},
+
+		{`LISTEN ??`, `LISTEN`},
+		{`NOTIFY ??`, `NOTIFY`},
+		{`NOTIFY foo, ??`, `NOTIFY`},
+		{`UNLISTEN ??`, `UNLISTEN`},
+
 		{`DROP ??`, `DROP`},
 
 		{`DROP DATABASE IF ??`, `DROP DATABASE`},
@@ -393,6 +409,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SET TRANSACTION ??`, `SET TRANSACTION`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT ??`, `SET TRANSACTION`},
+		{`SET CONSTRAINTS ??`, `SET CONSTRAINTS`},
+		{`SET CONSTRAINTS ALL ??`, `SET CONSTRAINTS`},
 		{`SET TIME ??`, `SET SESSION`},
 		{`SET TIME ZONE 'UTC' ??`, `SET SESSION`},
 		{`SET blah TO ??`, `SET SESSION`},
@@ -443,6 +461,8 @@ func TestContextualHelp(t *testing.T) {
 		{`EXPORT INTO CSV 'a' ??`, `EXPORT`},
 		{`EXPORT INTO CSV 'a' FROM SELECT a ??`, `SELECT`},
 		{`CREATE SCHEDULE FOR BACKUP ??`, `CREATE SCHEDULE FOR BACKUP`},
+		{`CREATE SCHEDULE FOR EXPORT ??`, `CREATE SCHEDULE FOR EXPORT`},
+		{`CREATE SCHEDULE 'foo' FOR CHANGEFEED ??`, `CREATE SCHEDULE FOR CHANGEFEED`},
 	}
 
 	// The following checks that the test definition above exercises all
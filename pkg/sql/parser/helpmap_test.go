@@ -24,12 +24,16 @@ var expectedHelpStrings = []string{
 	"CANCEL QUERIES",
 	"CANCEL SESSIONS",
 	"CANCEL",
+	"CLOSE",
 	"COMMIT",
 	"CREATE DATABASE",
 	"CREATE EXTENSION",
+	"CREATE FUNCTION",
 	"CREATE INDEX",
 	"CREATE ROLE",
 	"CREATE SCHEDULE FOR BACKUP",
+	"CREATE SCHEDULE FOR CHANGEFEED",
+	"CREATE SCHEDULE FOR EXPORT",
 	"CREATE SCHEMA",
 	"CREATE SEQUENCE",
 	"CREATE STATISTICS",
@@ -38,9 +42,11 @@ var expectedHelpStrings = []string{
 	"CREATE VIEW",
 	"CREATE",
 	"DEALLOCATE",
+	"DECLARE",
 	"DELETE",
 	"DISCARD",
 	"DROP DATABASE",
+	"DROP FUNCTION",
 	"DROP INDEX",
 	"DROP OWNED BY",
 	"DROP ROLE",
@@ -54,9 +60,13 @@ var expectedHelpStrings = []string{
 	"EXECUTE",
 	"EXPLAIN",
 	"EXPORT",
+	"FETCH",
 	"GRANT",
 	"IMPORT",
 	"INSERT",
+	"LISTEN",
+	"MOVE",
+	"NOTIFY",
 	"PAUSE JOBS",
 	"PAUSE SCHEDULES",
 	"PAUSE",
@@ -78,6 +88,7 @@ var expectedHelpStrings = []string{
 	"SCRUB",
 	"SELECT",
 	"SET CLUSTER SETTING",
+	"SET CONSTRAINTS",
 	"SET SESSION",
 	"SET TRANSACTION",
 	"SHOW BACKUP",
@@ -114,6 +125,7 @@ var expectedHelpStrings = []string{
 	"SHOW",
 	"TABLE",
 	"TRUNCATE",
+	"UNLISTEN",
 	"UPDATE",
 	"UPSERT",
 	"USE",
@@ -44,6 +44,7 @@ func TestParse(t *testing.T) {
 		{`BEGIN TRANSACTION READ ONLY`},
 		{`BEGIN TRANSACTION READ WRITE`},
 		{`BEGIN TRANSACTION ISOLATION LEVEL SERIALIZABLE`},
+		{`BEGIN TRANSACTION ISOLATION LEVEL READ COMMITTED`},
 		{`BEGIN TRANSACTION PRIORITY LOW`},
 		{`BEGIN TRANSACTION PRIORITY NORMAL`},
 		{`BEGIN TRANSACTION PRIORITY HIGH`},
@@ -235,6 +236,12 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON UPDATE SET NULL)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON DELETE SET DEFAULT)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON DELETE SET DEFAULT ON UPDATE SET NULL)`},
+		{`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) DEFERRABLE INITIALLY IMMEDIATE)`},
+		{`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED)`},
+		{`CREATE TABLE a (b INT8 REFERENCES c (x) DEFERRABLE INITIALLY DEFERRED)`},
+		{`CREATE TABLE a (b INT8, UNIQUE WITHOUT INDEX (b) DEFERRABLE INITIALLY DEFERRED)`},
+		{`CREATE TABLE a (b INT8, UNIQUE WITHOUT INDEX (b) DEFERRABLE INITIALLY IMMEDIATE WHERE b > 0)`},
+		{`ALTER TABLE a ADD CONSTRAINT fk FOREIGN KEY (b) REFERENCES c (x) DEFERRABLE INITIALLY DEFERRED NOT VALID`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX d (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c))`},
@@ -426,6 +433,12 @@ func TestParse(t *testing.T) {
 		{`CREATE TYPE a.b AS ENUM ('a', 'b', 'c')`},
 		{`CREATE TYPE a.b.c AS ENUM ('a', 'b', 'c')`},
 
+		{`CREATE FUNCTION f() RETURNS INT8 LANGUAGE SQL AS 'SELECT 1'`},
+		{`CREATE FUNCTION a.b.f(x INT8, STRING) RETURNS STRING LANGUAGE SQL IMMUTABLE LEAKPROOF STRICT AS 'SELECT $2'`},
+		{`CREATE OR REPLACE FUNCTION f(a INT8) RETURNS INT8 AS 'SELECT a + 1' LANGUAGE SQL STABLE NOT LEAKPROOF`},
+		{`CREATE FUNCTION f(a INT8[]) RETURNS INT8 LANGUAGE SQL VOLATILE CALLED ON NULL INPUT AS 'SELECT a[1]'`},
+		{`CREATE FUNCTION f(a t) RETURNS t LANGUAGE SQL RETURNS NULL ON NULL INPUT AS 'SELECT a'`},
+
 		{`DROP SCHEMA a`},
 		{`DROP SCHEMA a, b`},
 		{`DROP SCHEMA IF EXISTS a, b, c`},
@@ -452,6 +465,14 @@ func TestParse(t *testing.T) {
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a CASCADE`},
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a RESTRICT`},
 
+		{`DROP FUNCTION f`},
+		{`DROP FUNCTION f(), g(INT8, STRING)`},
+		{`DROP FUNCTION IF EXISTS db.sc.f(a INT8) CASCADE`},
+		{`DROP FUNCTION sc.f(INT8), f RESTRICT`},
+
+		{`SHOW CREATE FUNCTION f`},
+		{`SHOW CREATE FUNCTION db.sc.f`},
+
 		{`DELETE FROM a`},
 		{`EXPLAIN DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -468,6 +489,30 @@ func TestParse(t *testing.T) {
 
 		{`DISCARD ALL`},
 
+		{`LISTEN foo`},
+		{`LISTEN "Foo"`},
+		{`UNLISTEN foo`},
+		{`UNLISTEN *`},
+		{`NOTIFY foo`},
+		{`NOTIFY foo, 'bar'`},
+		{`NOTIFY foo, e'\'bar'`},
+
+		{`DECLARE foo CURSOR FOR SELECT 1`},
+		{`DECLARE foo BINARY INSENSITIVE NO SCROLL CURSOR WITH HOLD FOR SELECT a FROM t`},
+		{`DECLARE foo ASENSITIVE SCROLL CURSOR FOR SELECT a FROM t ORDER BY a`},
+		{`FETCH 1 foo`},
+		{`FETCH -3 foo`},
+		{`FETCH ALL foo`},
+		{`FETCH BACKWARD ALL foo`},
+		{`FETCH FIRST foo`},
+		{`FETCH LAST foo`},
+		{`FETCH ABSOLUTE 3 foo`},
+		{`FETCH RELATIVE -2 foo`},
+		{`MOVE 5 foo`},
+		{`MOVE ALL foo`},
+		{`CLOSE foo`},
+		{`CLOSE ALL`},
+
 		{`DROP DATABASE a`},
 		{`EXPLAIN DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -551,6 +596,8 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW SCHEDULES`},
 		{`SHOW SCHEDULES FOR BACKUP`},
 		{`EXPLAIN SHOW SCHEDULES FOR BACKUP`},
+		{`SHOW SCHEDULES FOR EXPORT`},
+		{`SHOW SCHEDULES FOR CHANGEFEED`},
 		{`SHOW PAUSED SCHEDULES`},
 		{`EXPLAIN SHOW PAUSED SCHEDULES`},
 		{`SHOW RUNNING SCHEDULES`},
@@ -559,6 +606,7 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW PAUSED SCHEDULES FOR BACKUP`},
 		{`SHOW RUNNING SCHEDULES FOR BACKUP`},
 		{`EXPLAIN SHOW RUNNING SCHEDULES FOR BACKUP`},
+		{`SHOW PAUSED SCHEDULES FOR CHANGEFEED`},
 
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
@@ -784,6 +832,11 @@ func TestParse(t *testing.T) {
 		{`GRANT USAGE, GRANT ON TYPE foo TO root`},
 		{`GRANT ALL ON TYPE foo TO root`},
 
+		// GRANT ON FUNCTION.
+		{`GRANT EXECUTE ON FUNCTION foo TO root`},
+		{`GRANT EXECUTE, GRANT ON FUNCTION foo(INT8), sc.bar() TO root, bar`},
+		{`GRANT ALL ON FUNCTION db.sc.foo(a STRING) TO root`},
+
 		// GRANT ON SCHEMA.
 		{`GRANT USAGE ON SCHEMA foo TO root`},
 		{`GRANT USAGE ON SCHEMA foo.bar TO root`},
@@ -808,6 +861,11 @@ func TestParse(t *testing.T) {
 		{`REVOKE USAGE, GRANT ON TYPE foo FROM root`},
 		{`REVOKE ALL ON TYPE foo FROM root`},
 
+		// REVOKE ON FUNCTION.
+		{`REVOKE EXECUTE ON FUNCTION foo FROM root`},
+		{`REVOKE EXECUTE, GRANT ON FUNCTION foo(INT8), sc.bar() FROM root, bar`},
+		{`REVOKE ALL ON FUNCTION db.sc.foo(a STRING) FROM root`},
+
 		// REVOKE ON SCHEMA.
 		{`REVOKE USAGE ON SCHEMA foo FROM root`},
 		{`REVOKE USAGE ON SCHEMA foo.bar FROM root`},
@@ -1135,6 +1193,13 @@ func TestParse(t *testing.T) {
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
 		{`SELECT 1 FROM t GROUP BY ()`},
+		{`SELECT 1 FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT 1 FROM t GROUP BY a, ROLLUP (b, (c, d))`},
+		{`SELECT 1 FROM t GROUP BY CUBE (a, b)`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS (a, (a, b), ())`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS (ROLLUP (a), CUBE (b, c), GROUPING SETS (d))`},
+		{`SELECT GROUPING(a), GROUPING(a, b) FROM t GROUP BY CUBE (a, b)`},
+		{`SELECT rollup(a) FROM t`},
 		{`SELECT sum(x ORDER BY y) FROM t`},
 		{`SELECT sum(x ORDER BY y, z) FROM t`},
 
@@ -1294,6 +1359,11 @@ func TestParse(t *testing.T) {
 		{`SET TRANSACTION NOT DEFERRABLE`},
 		{`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, PRIORITY HIGH, AS OF SYSTEM TIME '-1s', NOT DEFERRABLE`},
 
+		{`SET CONSTRAINTS ALL DEFERRED`},
+		{`SET CONSTRAINTS ALL IMMEDIATE`},
+		{`SET CONSTRAINTS a, b DEFERRED`},
+		{`SET CONSTRAINTS a IMMEDIATE`},
+
 		{`SET TRACING = off`},
 		{`EXPLAIN SET TRACING = off`},
 		{`SET TRACING = 'cluster', 'kv'`},
@@ -1668,6 +1738,10 @@ func TestParse(t *testing.T) {
 		{`CREATE SCHEDULE FOR BACKUP TABLE foo, bar, buz INTO 'bar' RECURRING '@daily' FULL BACKUP '@weekly'`},
 		{`CREATE SCHEDULE FOR BACKUP TABLE foo, bar, buz INTO 'bar' WITH revision_history RECURRING '@daily' FULL BACKUP '@weekly'`},
 		{`CREATE SCHEDULE FOR BACKUP INTO 'bar' WITH revision_history RECURRING '@daily' FULL BACKUP '@weekly' WITH SCHEDULE OPTIONS foo = 'bar'`},
+		{`CREATE SCHEDULE FOR EXPORT INTO CSV 'bar' FROM (SELECT * FROM foo) RECURRING '@daily'`},
+		{`CREATE SCHEDULE 'my schedule' FOR EXPORT INTO PARQUET 'bar' WITH compression = 'gzip' FROM (TABLE foo) RECURRING '@hourly' WITH SCHEDULE OPTIONS on_previous_running = 'skip'`},
+		{`CREATE SCHEDULE FOR CHANGEFEED TABLE foo INTO 'sink' RECURRING '@hourly'`},
+		{`CREATE SCHEDULE 'my schedule' FOR CHANGEFEED TABLE foo, bar INTO 'sink' WITH initial_scan_only, format = 'avro' RECURRING '@daily' WITH SCHEDULE OPTIONS first_run = 'now'`},
 		{`EXPLAIN BACKUP TABLE foo TO 'bar'`},
 		{`BACKUP TABLE foo.foo, baz.baz TO 'bar'`},
 
@@ -1761,6 +1835,10 @@ func TestParse(t *testing.T) {
 		// {`CREATE CHANGEFEED FOR TABLE foo PARTITION bar, baz INTO 'sink'`},
 		// {`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
+		{`CREATE CHANGEFEED INTO 'sink' AS SELECT a, b FROM foo WHERE c = 'd'`},
+		{`CREATE CHANGEFEED INTO 'sink' WITH bar = 'baz' AS SELECT * FROM db.foo`},
+		{`EXPERIMENTAL CHANGEFEED AS SELECT a + 1 AS b FROM foo`},
+		{`EXPERIMENTAL CHANGEFEED WITH diff AS SELECT * FROM foo WHERE a > 1`},
 
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
@@ -1796,6 +1874,16 @@ func TestParse2(t *testing.T) {
 		sql      string
 		expected string
 	}{
+		{`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) DEFERRABLE)`,
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) DEFERRABLE INITIALLY IMMEDIATE)`},
+		{`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) INITIALLY DEFERRED)`,
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) DEFERRABLE INITIALLY DEFERRED)`},
+		{`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x) INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES c (x))`},
+		{`CREATE TABLE a (b INT8 REFERENCES c (x) DEFERRABLE)`,
+			`CREATE TABLE a (b INT8 REFERENCES c (x) DEFERRABLE INITIALLY IMMEDIATE)`},
+		{`CREATE TABLE a (b INT8, CHECK (b > 0) INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, CHECK (b > 0))`},
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
@@ -1831,7 +1919,7 @@ func TestParse2(t *testing.T) {
 			`CREATE DATABASE a PRIMARY REGION "us-west-1"`,
 		},
 		{`CREATE TABLE a (b INT) WITH (fillfactor=100)`,
-			`CREATE TABLE a (b INT8)`},
+			`CREATE TABLE a (b INT8) WITH (fillfactor = 100)`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b))`,
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b))`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) WHERE c > 3)`,
@@ -2036,6 +2124,21 @@ func TestParse2(t *testing.T) {
 		// We allow OFFSET before LIMIT, but always output LIMIT first.
 		{`SELECT a FROM t OFFSET a LIMIT b`,
 			`SELECT a FROM t LIMIT b OFFSET a`},
+		{`DECLARE foo NO SCROLL CURSOR WITHOUT HOLD FOR SELECT 1`,
+			`DECLARE foo NO SCROLL CURSOR FOR SELECT 1`},
+		{`FETCH foo`, `FETCH 1 foo`},
+		{`FETCH FROM foo`, `FETCH 1 foo`},
+		{`FETCH NEXT IN foo`, `FETCH 1 foo`},
+		{`FETCH PRIOR foo`, `FETCH -1 foo`},
+		{`FETCH FORWARD foo`, `FETCH 1 foo`},
+		{`FETCH BACKWARD FROM foo`, `FETCH -1 foo`},
+		{`FETCH FORWARD 5 foo`, `FETCH 5 foo`},
+		{`FETCH BACKWARD 5 IN foo`, `FETCH -5 foo`},
+		{`FETCH FORWARD ALL foo`, `FETCH ALL foo`},
+		{`FETCH FIRST FROM foo`, `FETCH FIRST foo`},
+		{`FETCH next`, `FETCH 1 next`},
+		{`MOVE NEXT foo`, `MOVE 1 foo`},
+
 		// FETCH FIRST ... is alternative syntax for LIMIT.
 		{`SELECT a FROM t FETCH FIRST 3 ROWS ONLY`,
 			`SELECT a FROM t LIMIT 3`},
@@ -2305,6 +2408,8 @@ $function$`,
 			`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ WRITE`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT READ ONLY`,
 			`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ ONLY`},
+		{`SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED`,
+			`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`},
 		{"SET CLUSTER SETTING a TO 1", "SET CLUSTER SETTING a = 1"},
 		{"SET TRACING TO off", "SET TRACING = off"},
 		{"RELEASE foo", "RELEASE SAVEPOINT foo"},
@@ -2367,6 +2472,8 @@ $function$`,
 
 		{`CREATE CHANGEFEED FOR TABLE foo INTO sink`,
 			`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED AS SELECT a FROM foo`,
+			`EXPERIMENTAL CHANGEFEED AS SELECT a FROM foo`},
 
 		{`SHOW CLUSTER SETTING ALL`, `SHOW ALL CLUSTER SETTINGS`},
 		{`SHOW CLUSTER SETTINGS`, `SHOW PUBLIC CLUSTER SETTINGS`},
@@ -3204,8 +3311,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE DEFAULT CONVERSION a`, 0, `create def conv`, ``},
 		{`CREATE FOREIGN DATA WRAPPER a`, 0, `create fdw`, ``},
 		{`CREATE FOREIGN TABLE a`, 0, `create foreign table`, ``},
-		{`CREATE FUNCTION a`, 17511, `create`, ``},
-		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`, ``},
 		{`CREATE LANGUAGE a`, 17511, `create language a`, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`, ``},
 		{`CREATE PUBLICATION a`, 0, `create publication`, ``},
@@ -3225,7 +3330,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP EXTENSION a`, 0, `drop extension a`, ``},
 		{`DROP FOREIGN TABLE a`, 0, `drop foreign table`, ``},
 		{`DROP FOREIGN DATA WRAPPER a`, 0, `drop fdw`, ``},
-		{`DROP FUNCTION a`, 17511, `drop `, ``},
 		{`DROP LANGUAGE a`, 17511, `drop language a`, ``},
 		{`DROP OPERATOR a`, 0, `drop operator`, ``},
 		{`DROP PUBLICATION a`, 0, `drop publication`, ``},
@@ -3240,7 +3344,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DISCARD TEMP`, 0, `discard temp`, ``},
 		{`DISCARD TEMPORARY`, 0, `discard temp`, ``},
 
-		{`SET CONSTRAINTS foo`, 0, `set constraints`, ``},
 		{`SET LOCAL foo = bar`, 32562, ``, ``},
 		{`SET foo FROM CURRENT`, 0, `set from current`, ``},
 
@@ -3255,13 +3358,8 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE TABLE a(b INT8 REFERENCES c(x) MATCH PARTIAL`, 20305, `match partial`, ``},
 		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) MATCH PARTIAL)`, 20305, `match partial`, ``},
 
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE)`, 31632, `deferrable`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY DEFERRED)`, 31632, `initially deferred`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY IMMEDIATE)`, 31632, `initially immediate`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY DEFERRED)`, 31632, `initially deferred`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY IMMEDIATE)`, 31632, `initially immediate`, ``},
 		{`CREATE TABLE a(b INT8, UNIQUE (b) DEFERRABLE)`, 31632, `deferrable`, ``},
-		{`CREATE TABLE a(b INT8, CHECK (b > 0) DEFERRABLE)`, 31632, `deferrable`, ``},
+		{`CREATE TABLE a(b INT8, UNIQUE (b) INITIALLY DEFERRED)`, 31632, `deferrable`, ``},
 
 		{`CREATE TABLE a (LIKE b INCLUDING COMMENTS)`, 47071, `like table`, ``},
 		{`CREATE TABLE a (LIKE b INCLUDING IDENTITY)`, 47071, `like table`, ``},
@@ -3322,16 +3420,10 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SELECT a(b) 'c'`, 0, `a(...) SCONST`, ``},
 		{`SELECT (a,b) OVERLAPS (c,d)`, 0, `overlaps`, ``},
 		{`SELECT UNIQUE (SELECT b)`, 0, `UNIQUE predicate`, ``},
-		{`SELECT GROUPING (a,b,c)`, 0, `d_expr grouping`, ``},
 		{`SELECT a(VARIADIC b)`, 0, `variadic`, ``},
 		{`SELECT a(b, c, VARIADIC b)`, 0, `variadic`, ``},
 		{`SELECT TREAT (a AS INT8)`, 0, `treat`, ``},
 
-		{`SELECT 1 FROM t GROUP BY ROLLUP (b)`, 46280, `rollup`, ``},
-		{`SELECT 1 FROM t GROUP BY a, ROLLUP (b)`, 46280, `rollup`, ``},
-		{`SELECT 1 FROM t GROUP BY CUBE (b)`, 46280, `cube`, ``},
-		{`SELECT 1 FROM t GROUP BY GROUPING SETS (b)`, 46280, `grouping sets`, ``},
-
 		{`SELECT a FROM t ORDER BY a NULLS LAST`, 6224, ``, ``},
 		{`SELECT a FROM t ORDER BY a ASC NULLS LAST`, 6224, ``, ``},
 		{`SELECT a FROM t ORDER BY a DESC NULLS FIRST`, 6224, ``, ``},
@@ -3349,8 +3441,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE TABLE a(b PG_LSN)`, 0, `pg_lsn`, ``},
 		{`CREATE TABLE a(b POINT)`, 21286, `point`, ``},
 		{`CREATE TABLE a(b POLYGON)`, 21286, `polygon`, ``},
-		{`CREATE TABLE a(b TSQUERY)`, 7821, `tsquery`, ``},
-		{`CREATE TABLE a(b TSVECTOR)`, 7821, `tsvector`, ``},
 		{`CREATE TABLE a(b TXID_SNAPSHOT)`, 0, `txid_snapshot`, ``},
 		{`CREATE TABLE a(b XML)`, 0, `xml`, ``},
 
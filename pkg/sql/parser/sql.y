@@ -33,6 +33,8 @@ import (
     "github.com/cockroachdb/cockroach/pkg/roachpb"
     "github.com/cockroachdb/cockroach/pkg/security"
     "github.com/cockroachdb/cockroach/pkg/sql/lex"
+    "github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+    "github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
     "github.com/cockroachdb/cockroach/pkg/sql/privilege"
     "github.com/cockroachdb/cockroach/pkg/sql/roleoption"
     "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -515,6 +517,9 @@ func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
 func (u *sqlSymUnion) compositeKeyMatchMethod() tree.CompositeKeyMatchMethod {
   return u.val.(tree.CompositeKeyMatchMethod)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+  return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
     return u.val.(tree.ReferenceAction)
 }
@@ -581,6 +586,33 @@ func (u *sqlSymUnion) objectNamePrefix() tree.ObjectNamePrefix {
 func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
     return u.val.(tree.ObjectNamePrefixList)
 }
+func (u *sqlSymUnion) funcArg() tree.FuncArg {
+    return u.val.(tree.FuncArg)
+}
+func (u *sqlSymUnion) funcArgs() tree.FuncArgs {
+    return u.val.(tree.FuncArgs)
+}
+func (u *sqlSymUnion) functionOption() tree.FunctionOption {
+    return u.val.(tree.FunctionOption)
+}
+func (u *sqlSymUnion) functionOptions() tree.FunctionOptions {
+    return u.val.(tree.FunctionOptions)
+}
+func (u *sqlSymUnion) funcObj() tree.FuncObj {
+    return u.val.(tree.FuncObj)
+}
+func (u *sqlSymUnion) funcObjs() tree.FuncObjs {
+    return u.val.(tree.FuncObjs)
+}
+func (u *sqlSymUnion) cursorSensitivity() tree.CursorSensitivity {
+    return u.val.(tree.CursorSensitivity)
+}
+func (u *sqlSymUnion) cursorScrollOption() tree.CursorScrollOption {
+    return u.val.(tree.CursorScrollOption)
+}
+func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
+    return u.val.(tree.CursorStmt)
+}
 %}
 
 // NB: the %token definitions must come before the %type definitions in this
@@ -600,15 +632,15 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str> ABORT ACCESS ACTION ADD ADMIN AFFINITY AFTER AGGREGATE
+%token <str> ABORT ABSOLUTE ACCESS ACTION ADD ADMIN AFFINITY AFTER AGGREGATE
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
-%token <str> ASYMMETRIC AT ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
+%token <str> ASENSITIVE ASYMMETRIC AT AT_AT ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
 
-%token <str> BACKUP BACKUPS BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
+%token <str> BACKUP BACKUPS BACKWARD BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALLED CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CLOSE
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -616,7 +648,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> CONVERSION CONVERT COPY COVERING CREATE CREATEDB CREATELOGIN CREATEROLE
 %token <str> CROSS CSV CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str> CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
-%token <str> CURRENT_USER CYCLE
+%token <str> CURRENT_USER CURSOR CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT DEFAULTS
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DESC DESTINATION DETACHED
@@ -630,19 +662,19 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %token <str> FAILURE FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
-%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL FUNCTION
+%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FORWARD FROM FULL FUNCTION
 
 %token <str> GENERATED GEOGRAPHY GEOMETRY GEOMETRYM GEOMETRYZ GEOMETRYZM
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
 %token <str> GLOBAL GOAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
 
-%token <str> HAVING HASH HIGH HISTOGRAM HOUR
+%token <str> HAVING HASH HIGH HISTOGRAM HOLD HOUR
 
 %token <str> IDENTITY
-%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
+%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMMUTABLE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INTERLEAVE INITIALLY
-%token <str> INNER INSERT INT INTEGER
+%token <str> INNER INPUT INSENSITIVE INSERT INT INTEGER
 %token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED IS ISERROR ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
@@ -650,41 +682,41 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> KEY KEYS KMS KV
 
 %token <str> LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
-%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT
+%token <str> LEADING LEAKPROOF LEASE LEAST LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
-%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
+%token <str> LIST LISTEN LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
+%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
 
 %token <str> NAN NAME NAMES NATURAL NEVER NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED NOCONTROLJOB
 %token <str> NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING NO_INDEX_JOIN
-%token <str> NONE NORMAL NOT NOTHING NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
+%token <str> NONE NORMAL NOT NOTHING NOTIFY NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPT OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OWNER OPERATOR
 
 %token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
-%token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIORITY PRIVILEGES
+%token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
 %token <str> PROCEDURAL PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
+%token <str> RANGE RANGES READ REAL REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH RELATIVE
 %token <str> REGCLASS REGION REGIONAL REGIONS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE REINDEX
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE REPLICATION
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETRY REVISION_HISTORY REVOKE RIGHT
+%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETURNS RETRY REVISION_HISTORY REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE RUNNING
 
-%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCROLL SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_MISSING_FOREIGN_KEYS
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING STREAM SUBSTRING
+%token <str> STABLE START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING STREAM SUBSTRING
 %token <str> SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
@@ -693,10 +725,10 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> TRUNCATE TRUSTED TYPE TYPES
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL VISIBLE
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL VISIBLE VOLATILE
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -817,6 +849,8 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> create_index_stmt
 %type <tree.Statement> create_role_stmt
 %type <tree.Statement> create_schedule_for_backup_stmt
+%type <tree.Statement> create_schedule_for_changefeed_stmt
+%type <tree.Statement> create_schedule_for_export_stmt
 %type <tree.Statement> create_schema_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
@@ -829,8 +863,12 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <*tree.CreateStatsOptions> create_stats_option
 
 %type <tree.Statement> create_type_stmt
+%type <tree.Statement> create_func_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
+%type <tree.Statement> unlisten_stmt
 
 %type <tree.Statement> drop_stmt
 %type <tree.Statement> drop_ddl_stmt
@@ -840,6 +878,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_type_stmt
+%type <tree.Statement> drop_func_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
 
@@ -876,6 +915,8 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> set_session_stmt
 %type <tree.Statement> set_csetting_stmt
 %type <tree.Statement> set_transaction_stmt
+%type <tree.Statement> set_constraints_stmt
+%type <bool> constraints_set_mode
 %type <tree.Statement> set_exprs_internal
 %type <tree.Statement> generic_set
 %type <tree.Statement> set_rest_more
@@ -932,6 +973,13 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %type <tree.Statement> close_cursor_stmt
 %type <tree.Statement> declare_cursor_stmt
+%type <tree.Statement> fetch_cursor_stmt
+%type <tree.Statement> move_cursor_stmt
+%type <tree.CursorStmt> cursor_movement_specifier
+%type <bool> opt_binary opt_hold
+%type <tree.CursorSensitivity> opt_sensitivity
+%type <tree.CursorScrollOption> opt_scroll
+%type <int64> next_prior forward_backward opt_forward_backward
 %type <tree.Statement> reindex_stmt
 
 %type <[]string> opt_incremental
@@ -1176,6 +1224,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.NamedColumnQualification> col_qualification create_as_col_qualification
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
+%type <tree.ConstraintDeferrability> opt_deferrable
 %type <tree.ReferenceActions> reference_actions
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
@@ -1201,6 +1250,12 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <[]tree.ColumnID> opt_tableref_col_list tableref_col_list
 
 %type <tree.TargetList> targets targets_roles target_types changefeed_targets
+%type <tree.FuncArg> func_arg
+%type <tree.FuncArgs> opt_func_arg_list func_arg_list
+%type <tree.FunctionOption> create_func_opt_item
+%type <tree.FunctionOptions> opt_create_func_opt_list create_func_opt_list
+%type <tree.FuncObj> func_obj
+%type <tree.FuncObjs> func_obj_list
 %type <*tree.TargetList> opt_on_targets_roles opt_backup_targets
 %type <tree.NameList> for_grantee_clause
 %type <privilege.List> privileges
@@ -1266,7 +1321,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %left      '|'
 %left      '#'
 %left      '&'
-%left      LSHIFT RSHIFT INET_CONTAINS_OR_EQUALS INET_CONTAINED_BY_OR_EQUALS AND_AND SQRT CBRT
+%left      LSHIFT RSHIFT INET_CONTAINS_OR_EQUALS INET_CONTAINED_BY_OR_EQUALS AND_AND AT_AT SQRT CBRT
 %left      '+' '-'
 %left      '*' '/' FLOORDIV '%'
 %left      '^'
@@ -1305,8 +1360,11 @@ stmt:
 | deallocate_stmt           // EXTEND WITH HELP: DEALLOCATE
 | discard_stmt              // EXTEND WITH HELP: DISCARD
 | grant_stmt                // EXTEND WITH HELP: GRANT
+| listen_stmt               // EXTEND WITH HELP: LISTEN
+| notify_stmt               // EXTEND WITH HELP: NOTIFY
 | prepare_stmt              // EXTEND WITH HELP: PREPARE
 | revoke_stmt               // EXTEND WITH HELP: REVOKE
+| unlisten_stmt             // EXTEND WITH HELP: UNLISTEN
 | savepoint_stmt            // EXTEND WITH HELP: SAVEPOINT
 | reassign_owned_by_stmt    // EXTEND WITH HELP: REASSIGN OWNED BY
 | drop_owned_by_stmt        // EXTEND WITH HELP: DROP OWNED BY
@@ -1314,8 +1372,10 @@ stmt:
 | refresh_stmt              // EXTEND WITH HELP: REFRESH
 | nonpreparable_set_stmt    // help texts in sub-rule
 | transaction_stmt          // help texts in sub-rule
-| close_cursor_stmt
-| declare_cursor_stmt
+| close_cursor_stmt         // EXTEND WITH HELP: CLOSE
+| declare_cursor_stmt       // EXTEND WITH HELP: DECLARE
+| fetch_cursor_stmt         // EXTEND WITH HELP: FETCH
+| move_cursor_stmt          // EXTEND WITH HELP: MOVE
 | reindex_stmt
 | /* EMPTY */
   {
@@ -2498,6 +2558,135 @@ create_schedule_for_backup_stmt:
   }
 | CREATE SCHEDULE error  // SHOW HELP: CREATE SCHEDULE FOR BACKUP
 
+// %Help: CREATE SCHEDULE FOR EXPORT - export data periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<description>]
+// FOR EXPORT INTO <format> <datafile> [WITH <option> [= value] [,...]] FROM ( <query> )
+// RECURRING <crontab>
+// [WITH SCHEDULE OPTIONS <schedule_option>=<value> [, ...] ]
+//
+// Description:
+//   Optional description (or name) for this schedule
+//
+// EXPORT INTO <format> <datafile> [WITH <options>] FROM ( <query> ):
+//   The export executed by the schedule: See EXPORT.
+//   The query must be enclosed in parentheses.
+//   Each execution of the schedule writes its files under <datafile>.
+//   An execution which is resumed after a failure, such as the restart of a
+//   node, runs the export again from the start. The files written before the
+//   failure are left in place, so <datafile> may then hold duplicate files
+//   with the same rows.
+//
+// RECURRING <crontab>:
+//   Schedule specified as a string in crontab format.
+//   All times in UTC.
+//     "5 0 * * *": run schedule 5 minutes past midnight.
+//     "@daily": run daily, at midnight
+//   See https://en.wikipedia.org/wiki/Cron
+//
+//  SCHEDULE OPTIONS:
+//   The schedule can be modified by specifying the following options:
+//   * first_run=TIMESTAMPTZ:
+//     execute the schedule at the specified time. If not specified, the default is to execute
+//     the scheduled based on it's next RECURRING time.
+//   * on_execution_failure='[retry|reschedule|pause]':
+//     If an error occurs during the execution, handle the error based as:
+//     * retry: retry execution right away
+//     * reschedule: retry execution by rescheduling it based on its RECURRING expression.
+//       This is the default.
+//     * pause: pause this schedule.  Requires manual intervention to unpause.
+//   * on_previous_running='[start|skip|wait]':
+//     If the previous export started by this schedule still running, handle this as:
+//     * start: start this execution anyway, even if the previous one still running.
+//     * skip: skip this execution, reschedule it based on RECURRING expression.
+//     * wait: wait for the previous execution to complete.  This is the default.
+//
+// %SeeAlso: EXPORT, SHOW SCHEDULES
+create_schedule_for_export_stmt:
+  CREATE SCHEDULE /*$3=*/opt_description FOR EXPORT INTO /*$7=*/import_format
+  /*$8=*/string_or_placeholder /*$9=*/opt_with_options FROM /*$11=*/select_with_parens
+  /*$12=*/cron_expr /*$13=*/opt_with_schedule_options
+  {
+    $$.val = &tree.ScheduledExport{
+      ScheduleLabel:   $3.expr(),
+      Recurrence:      $12.expr(),
+      Export:          &tree.Export{
+        Query:      &tree.Select{Select: $11.selectStmt()},
+        FileFormat: $7,
+        File:       $8.expr(),
+        Options:    $9.kvOptions(),
+      },
+      ScheduleOptions: $13.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE opt_description FOR EXPORT error  // SHOW HELP: CREATE SCHEDULE FOR EXPORT
+
+// %Help: CREATE SCHEDULE FOR CHANGEFEED - emit the changes of tables periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<description>]
+// FOR CHANGEFEED <targets...> INTO <sink> [WITH <option> [= value] [,...]]
+// RECURRING <crontab>
+// [WITH SCHEDULE OPTIONS <schedule_option>=<value> [, ...] ]
+//
+// Description:
+//   Optional description (or name) for this schedule
+//
+// Targets:
+//    TABLE <pattern> [, ...]
+//
+// WITH <options>:
+//   Options specific to CHANGEFEED: See CREATE CHANGEFEED options.
+//   Each execution of the schedule emits the changes committed since the
+//   previous successful execution, and stops. The first execution emits a
+//   snapshot of the targets.
+//   With the initial_scan_only option, each execution emits a snapshot of the
+//   targets and stops.
+//
+// RECURRING <crontab>:
+//   Schedule specified as a string in crontab format.
+//   All times in UTC.
+//     "5 0 * * *": run schedule 5 minutes past midnight.
+//     "@daily": run daily, at midnight
+//   See https://en.wikipedia.org/wiki/Cron
+//
+//  SCHEDULE OPTIONS:
+//   The schedule can be modified by specifying the following options:
+//   * first_run=TIMESTAMPTZ:
+//     execute the schedule at the specified time. If not specified, the default is to execute
+//     the scheduled based on it's next RECURRING time.
+//   * on_execution_failure='[retry|reschedule|pause]':
+//     If an error occurs during the execution, handle the error based as:
+//     * retry: retry execution right away
+//     * reschedule: retry execution by rescheduling it based on its RECURRING expression.
+//       This is the default.
+//     * pause: pause this schedule.  Requires manual intervention to unpause.
+//   * on_previous_running='[start|skip|wait]':
+//     If the previous changefeed started by this schedule still running, handle this as:
+//     * start: start this execution anyway, even if the previous one still running.
+//     * skip: skip this execution, reschedule it based on RECURRING expression.
+//     * wait: wait for the previous execution to complete.  This is the default.
+//
+// %SeeAlso: SHOW SCHEDULES
+create_schedule_for_changefeed_stmt:
+  CREATE SCHEDULE /*$3=*/opt_description FOR CHANGEFEED /*$6=*/changefeed_targets
+  INTO /*$8=*/string_or_placeholder /*$9=*/opt_with_options
+  /*$10=*/cron_expr /*$11=*/opt_with_schedule_options
+  {
+    $$.val = &tree.ScheduledChangefeed{
+      ScheduleLabel:    $3.expr(),
+      Recurrence:       $10.expr(),
+      CreateChangefeed: &tree.CreateChangefeed{
+        Targets: $6.targetList(),
+        SinkURI: $8.expr(),
+        Options: $9.kvOptions(),
+      },
+      ScheduleOptions:  $11.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE opt_description FOR CHANGEFEED error  // SHOW HELP: CREATE SCHEDULE FOR CHANGEFEED
+
 opt_description:
   string_or_placeholder
 | /* EMPTY */
@@ -2738,6 +2927,7 @@ import_format:
 //    CSV
 //    DELIMITED
 //    MYSQLDUMP
+//    PARQUET
 //    PGCOPY
 //    PGDUMP
 //
@@ -2748,6 +2938,7 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    strict_validation      [AVRO, PARQUET-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -2800,9 +2991,11 @@ import_stmt:
 //
 // Formats:
 //    CSV
+//    PARQUET
 //
 // Options:
-//    delimiter = '...'   [CSV-specific]
+//    delimiter = '...'        [CSV-specific]
+//    row_group_size = '...'   [PARQUET-specific]
 //
 // %SeeAlso: SELECT
 export_stmt:
@@ -3104,12 +3297,14 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE, CREATE TYPE, CREATE EXTENSION
+// CREATE ROLE, CREATE TYPE, CREATE EXTENSION, CREATE FUNCTION
 create_stmt:
   create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
 | create_schedule_for_backup_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
+| create_schedule_for_export_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR EXPORT
+| create_schedule_for_changefeed_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR CHANGEFEED
 | create_extension_stmt // EXTEND WITH HELP: CREATE EXTENSION
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
@@ -3136,8 +3331,6 @@ create_unsupported:
 | CREATE DEFAULT CONVERSION error { return unimplemented(sqllex, "create def conv") }
 | CREATE FOREIGN TABLE error { return unimplemented(sqllex, "create foreign table") }
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
-| CREATE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
-| CREATE OR REPLACE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE OPERATOR error { return unimplemented(sqllex, "create operator") }
 | CREATE PUBLICATION error { return unimplemented(sqllex, "create publication") }
@@ -3171,7 +3364,6 @@ drop_unsupported:
 | DROP EXTENSION name error { return unimplemented(sqllex, "drop extension " + $3) }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
 | DROP FOREIGN DATA error { return unimplemented(sqllex, "drop fdw") }
-| DROP FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "drop function") }
 | DROP opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "drop language " + $4) }
 | DROP OPERATOR error { return unimplemented(sqllex, "drop operator") }
 | DROP PUBLICATION error { return unimplemented(sqllex, "drop publication") }
@@ -3191,6 +3383,7 @@ create_ddl_stmt:
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_persistence_temp_table TABLE error   // SHOW HELP: CREATE TABLE
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_func_stmt     // EXTEND WITH HELP: CREATE FUNCTION
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 
@@ -3298,6 +3491,14 @@ create_changefeed_stmt:
       Options: $6.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS select_stmt
+  {
+    $$.val = &tree.CreateChangefeed{
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select: $6.slct(),
+    }
+  }
 | EXPERIMENTAL CHANGEFEED FOR changefeed_targets opt_with_options
   {
     /* SKIP DOC */
@@ -3306,6 +3507,14 @@ create_changefeed_stmt:
       Options: $5.kvOptions(),
     }
   }
+| EXPERIMENTAL CHANGEFEED opt_with_options AS select_stmt
+  {
+    /* SKIP DOC */
+    $$.val = &tree.CreateChangefeed{
+      Options: $3.kvOptions(),
+      Select: $5.slct(),
+    }
+  }
 
 changefeed_targets:
   single_table_pattern_list
@@ -3379,11 +3588,55 @@ discard_stmt:
 | DISCARD TEMPORARY { return unimplemented(sqllex, "discard temp") }
 | DISCARD error // SHOW HELP: DISCARD
 
+// %Help: LISTEN - register the session as a listener on a notification channel
+// %Category: Misc
+// %Text: LISTEN <channel>
+// %SeeAlso: NOTIFY, UNLISTEN
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{ChannelName: tree.Name($2)}
+  }
+| LISTEN error // SHOW HELP: LISTEN
+
+// %Help: NOTIFY - generate a notification on a channel
+// %Category: Misc
+// %Text: NOTIFY <channel> [, '<payload>']
+//
+// The notification is delivered to all listening sessions when the
+// current transaction commits.
+// %SeeAlso: LISTEN, UNLISTEN
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2), Payload: $4}
+  }
+| NOTIFY error // SHOW HELP: NOTIFY
+
+// %Help: UNLISTEN - stop listening on a notification channel
+// %Category: Misc
+// %Text: UNLISTEN { <channel> | * }
+// %SeeAlso: LISTEN, NOTIFY
+unlisten_stmt:
+  UNLISTEN name
+  {
+    $$.val = &tree.Unlisten{ChannelName: tree.Name($2)}
+  }
+| UNLISTEN '*'
+  {
+    $$.val = &tree.Unlisten{All: true}
+  }
+| UNLISTEN error // SHOW HELP: UNLISTEN
+
 // %Help: DROP
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE, DROP TYPE
+// DROP USER, DROP ROLE, DROP TYPE, DROP FUNCTION
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
@@ -3399,6 +3652,7 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_func_stmt     // EXTEND WITH HELP: DROP FUNCTION
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -3533,6 +3787,49 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP FUNCTION - remove a function
+// %Category: DDL
+// %Text: DROP FUNCTION [IF EXISTS] <funcname> [ ( [ <argtype> [, ...] ] ) ] [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE FUNCTION
+drop_func_stmt:
+  DROP FUNCTION func_obj_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $3.funcObjs(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP FUNCTION IF EXISTS func_obj_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $5.funcObjs(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+func_obj_list:
+  func_obj
+  {
+    $$.val = tree.FuncObjs{$1.funcObj()}
+  }
+| func_obj_list ',' func_obj
+  {
+    $$.val = append($1.funcObjs(), $3.funcObj())
+  }
+
+func_obj:
+  db_object_name
+  {
+    $$.val = tree.FuncObj{FuncName: $1.unresolvedObjectName()}
+  }
+| db_object_name '(' opt_func_arg_list ')'
+  {
+    $$.val = tree.FuncObj{FuncName: $1.unresolvedObjectName(), Args: $3.funcArgs(), HasArgs: true}
+  }
+
 target_types:
   type_name_list
   {
@@ -3848,13 +4145,14 @@ deallocate_stmt:
 //   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, ...]
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname>]...
+//   FUNCTION <funcname> [ ( [ <argtype> [, ...] ] ) ] [, ...]
 //
 // %SeeAlso: REVOKE, WEBDOCS/grant.html
 grant_stmt:
@@ -3884,6 +4182,16 @@ grant_stmt:
       Grantees: $7.nameList(),
     }
   }
+| GRANT privileges ON FUNCTION func_obj_list TO name_list
+  {
+    $$.val = &tree.Grant{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.funcObjs(),
+      },
+      Grantees: $7.nameList(),
+    }
+  }
 | GRANT error // SHOW HELP: GRANT
 
 // %Help: REVOKE - remove access privileges and role memberships
@@ -3895,13 +4203,14 @@ grant_stmt:
 //   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, <databasename>]...
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname]...
+//   FUNCTION <funcname> [ ( [ <argtype> [, ...] ] ) ] [, ...]
 //
 // %SeeAlso: GRANT, WEBDOCS/revoke.html
 revoke_stmt:
@@ -3931,6 +4240,16 @@ revoke_stmt:
       Grantees: $7.nameList(),
     }
   }
+| REVOKE privileges ON FUNCTION func_obj_list FROM name_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.funcObjs(),
+      },
+      Grantees: $7.nameList(),
+    }
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 // ALL can either be by itself, or with the optional PRIVILEGES keyword (which no-ops)
@@ -4014,8 +4333,8 @@ use_stmt:
 // SET remainder, e.g. SET TRANSACTION
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
+| set_constraints_stmt // EXTEND WITH HELP: SET CONSTRAINTS
 | set_exprs_internal   { /* SKIP DOC */ }
-| SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
 | SET LOCAL error { return unimplementedWithIssue(sqllex, 32562) }
 
 // SET SESSION / SET CLUSTER SETTING
@@ -4155,7 +4474,7 @@ set_exprs_internal:
 // %Text:
 // SET [SESSION] <var> { TO | = } <values...>
 // SET [SESSION] TIME ZONE <tz>
-// SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+// SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { SNAPSHOT | READ COMMITTED | SERIALIZABLE }
 // SET [SESSION] TRACING { TO | = } { on | off | cluster | kv | results } [,...]
 //
 // %SeeAlso: SHOW SESSION, RESET, DISCARD, SHOW, SET CLUSTER SETTING, SET TRANSACTION,
@@ -4181,7 +4500,7 @@ set_session_stmt:
 // SET [SESSION] TRANSACTION <txnparameters...>
 //
 // Transaction parameters:
-//    ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+//    ISOLATION LEVEL { SNAPSHOT | READ COMMITTED | SERIALIZABLE }
 //    PRIORITY { LOW | NORMAL | HIGH }
 //    AS OF SYSTEM TIME <expr>
 //    [NOT] DEFERRABLE
@@ -4200,6 +4519,36 @@ set_transaction_stmt:
   }
 | SET SESSION TRANSACTION error // SHOW HELP: SET TRANSACTION
 
+// %Help: SET CONSTRAINTS - set constraint check timing for the current transaction
+// %Category: Txn
+// %Text:
+// SET CONSTRAINTS { ALL | <name> [, ...] } { DEFERRED | IMMEDIATE }
+//
+// Only constraints declared DEFERRABLE are affected. Switching a constraint
+// to IMMEDIATE runs any of its checks that were deferred so far.
+//
+// %SeeAlso: SET TRANSACTION, CREATE TABLE, ALTER TABLE
+set_constraints_stmt:
+  SET CONSTRAINTS ALL constraints_set_mode
+  {
+    $$.val = &tree.SetConstraints{All: true, Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS name_list constraints_set_mode
+  {
+    $$.val = &tree.SetConstraints{Names: $3.nameList(), Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS error // SHOW HELP: SET CONSTRAINTS
+
+constraints_set_mode:
+  DEFERRED
+  {
+    $$.val = true
+  }
+| IMMEDIATE
+  {
+    $$.val = false
+  }
+
 generic_set:
   var_name to_or_eq var_list
   {
@@ -4317,11 +4666,11 @@ var_list:
 iso_level:
   READ UNCOMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | READ COMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | SNAPSHOT
   {
@@ -4427,14 +4776,209 @@ show_stmt:
 | SHOW error                // SHOW HELP: SHOW
 | show_last_query_stats_stmt
 
-// Cursors are not yet supported by CockroachDB. CLOSE ALL is safe to no-op
-// since there will be no open cursors.
+// %Help: CLOSE - close a cursor
+// %Category: Misc
+// %Text: CLOSE { <name> | ALL }
+// %SeeAlso: DECLARE, FETCH, MOVE
 close_cursor_stmt:
-	CLOSE ALL { }
-| CLOSE cursor_name { return unimplementedWithIssue(sqllex, 41412) }
+  CLOSE ALL
+  {
+    $$.val = &tree.CloseCursor{All: true}
+  }
+| CLOSE cursor_name
+  {
+    $$.val = &tree.CloseCursor{Name: tree.Name($2)}
+  }
+| CLOSE error // SHOW HELP: CLOSE
 
+// %Help: DECLARE - declare a cursor
+// %Category: Misc
+// %Text: DECLARE <name> [INSENSITIVE] [NO SCROLL] CURSOR [WITHOUT HOLD] FOR <selectclause>
+//
+// Cursors can only be declared inside a transaction block and are
+// closed when the transaction ends.
+// %SeeAlso: FETCH, MOVE, CLOSE
 declare_cursor_stmt:
-	DECLARE { return unimplementedWithIssue(sqllex, 41412) }
+  DECLARE cursor_name opt_binary opt_sensitivity opt_scroll CURSOR opt_hold FOR select_stmt
+  {
+    $$.val = &tree.DeclareCursor{
+      Name: tree.Name($2),
+      Select: $9.slct(),
+      Binary: $3.bool(),
+      Sensitivity: $4.cursorSensitivity(),
+      Scroll: $5.cursorScrollOption(),
+      Hold: $7.bool(),
+    }
+  }
+| DECLARE error // SHOW HELP: DECLARE
+
+opt_binary:
+  BINARY
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+opt_sensitivity:
+  INSENSITIVE
+  {
+    $$.val = tree.Insensitive
+  }
+| ASENSITIVE
+  {
+    $$.val = tree.Asensitive
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.UnspecifiedSensitivity
+  }
+
+opt_scroll:
+  SCROLL
+  {
+    $$.val = tree.Scroll
+  }
+| NO SCROLL
+  {
+    $$.val = tree.NoScroll
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.UnspecifiedScroll
+  }
+
+opt_hold:
+  WITH HOLD
+  {
+    $$.val = true
+  }
+| WITHOUT HOLD
+  {
+    $$.val = false
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+// %Help: FETCH - fetch rows from a cursor
+// %Category: Misc
+// %Text:
+// FETCH [ <direction> [ FROM | IN ] ] <name>
+//
+// Direction:
+//   NEXT, PRIOR, FIRST, LAST, ABSOLUTE <count>, RELATIVE <count>, <count>,
+//   ALL, FORWARD [ <count> | ALL ], BACKWARD [ <count> | ALL ]
+// %SeeAlso: DECLARE, MOVE, CLOSE
+fetch_cursor_stmt:
+  FETCH cursor_movement_specifier
+  {
+    $$.val = &tree.FetchCursor{CursorStmt: $2.cursorStmt()}
+  }
+| FETCH error // SHOW HELP: FETCH
+
+// %Help: MOVE - move a cursor without returning rows
+// %Category: Misc
+// %Text:
+// MOVE [ <direction> [ FROM | IN ] ] <name>
+//
+// Direction:
+//   NEXT, PRIOR, FIRST, LAST, ABSOLUTE <count>, RELATIVE <count>, <count>,
+//   ALL, FORWARD [ <count> | ALL ], BACKWARD [ <count> | ALL ]
+// %SeeAlso: DECLARE, FETCH, CLOSE
+move_cursor_stmt:
+  MOVE cursor_movement_specifier
+  {
+    $$.val = &tree.MoveCursor{CursorStmt: $2.cursorStmt()}
+  }
+| MOVE error // SHOW HELP: MOVE
+
+cursor_movement_specifier:
+  cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($1), Count: 1}
+  }
+| from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($2), Count: 1}
+  }
+| next_prior opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: $1.int64()}
+  }
+| forward_backward opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: $1.int64()}
+  }
+| opt_forward_backward signed_iconst64 opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Count: $2.int64() * $1.int64()}
+  }
+| opt_forward_backward ALL opt_from_or_in cursor_name
+  {
+    fetchType := tree.FetchAll
+    if $1.int64() < 0 {
+      fetchType = tree.FetchBackwardAll
+    }
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: fetchType}
+  }
+| ABSOLUTE signed_iconst64 opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchAbsolute, Count: $2.int64()}
+  }
+| RELATIVE signed_iconst64 opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchRelative, Count: $2.int64()}
+  }
+| FIRST opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchFirst}
+  }
+| LAST opt_from_or_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchLast}
+  }
+
+next_prior:
+  NEXT
+  {
+    $$.val = int64(1)
+  }
+| PRIOR
+  {
+    $$.val = int64(-1)
+  }
+
+opt_forward_backward:
+  forward_backward
+  {
+    $$.val = $1.int64()
+  }
+| /* EMPTY */
+  {
+    $$.val = int64(1)
+  }
+
+forward_backward:
+  FORWARD
+  {
+    $$.val = int64(1)
+  }
+| BACKWARD
+  {
+    $$.val = int64(-1)
+  }
+
+opt_from_or_in:
+  from_or_in { }
+| /* EMPTY */ { }
+
+from_or_in:
+  FROM { }
+| IN { }
 
 reindex_stmt:
   REINDEX TABLE error
@@ -4854,7 +5398,7 @@ show_jobs_stmt:
 // %Help: SHOW SCHEDULES - list periodic schedules
 // %Category: Misc
 // %Text:
-// SHOW [RUNNING | PAUSED] SCHEDULES [FOR BACKUP]
+// SHOW [RUNNING | PAUSED] SCHEDULES [FOR {BACKUP | EXPORT | CHANGEFEED}]
 // SHOW SCHEDULE <schedule_id>
 // %SeeAlso: PAUSE SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
 show_schedules_stmt:
@@ -4902,6 +5446,14 @@ opt_schedule_executor_type:
   {
     $$.val = tree.ScheduledBackupExecutor
   }
+| FOR EXPORT
+  {
+    $$.val = tree.ScheduledExportExecutor
+  }
+| FOR CHANGEFEED
+  {
+    $$.val = tree.ScheduledChangefeedExecutor
+  }
 
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
@@ -5074,15 +5626,21 @@ show_transaction_stmt:
   }
 | SHOW TRANSACTION error // SHOW HELP: SHOW TRANSACTION
 
-// %Help: SHOW CREATE - display the CREATE statement for a table, sequence or view
+// %Help: SHOW CREATE - display the CREATE statement for a table, sequence, view or function
 // %Category: DDL
-// %Text: SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+// %Text:
+// SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+// SHOW CREATE FUNCTION <funcname>
 // %SeeAlso: WEBDOCS/show-create-table.html
 show_create_stmt:
   SHOW CREATE table_name
   {
     $$.val = &tree.ShowCreate{Name: $3.unresolvedObjectName()}
   }
+| SHOW CREATE FUNCTION db_object_name
+  {
+    $$.val = &tree.ShowCreateFunction{Name: $4.unresolvedObjectName()}
+  }
 | SHOW CREATE create_kw table_name
   {
     /* SKIP DOC */
@@ -6209,15 +6767,19 @@ col_qualification_elem:
     ShardBuckets: $8.expr(),
   }
 }
-| CHECK '(' a_expr ')'
+| CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      return setErr(sqllex, pgerror.New(pgcode.FeatureNotSupported,
+        "CHECK constraints cannot be marked DEFERRABLE"))
+    }
     $$.val = &tree.ColumnCheckConstraint{Expr: $3.expr()}
   }
 | DEFAULT b_expr
   {
     $$.val = &tree.ColumnDefault{Expr: $2.expr()}
   }
-| REFERENCES table_name opt_name_parens key_match reference_actions
+| REFERENCES table_name opt_name_parens key_match reference_actions opt_deferrable
  {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.ColumnFKConstraint{
@@ -6225,6 +6787,7 @@ col_qualification_elem:
       Col: tree.Name($3),
       Actions: $5.referenceActions(),
       Match: $4.compositeKeyMatchMethod(),
+      Deferrability: $6.constraintDeferrability(),
     }
  }
 | generated_as '(' a_expr ')' STORED
@@ -6324,6 +6887,10 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      return setErr(sqllex, pgerror.New(pgcode.FeatureNotSupported,
+        "CHECK constraints cannot be marked DEFERRABLE"))
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -6331,6 +6898,11 @@ constraint_elem:
 | UNIQUE opt_without_index '(' index_params ')'
     opt_storing opt_interleave opt_partition_by_index opt_deferrable opt_where_clause
   {
+    if !$2.bool() && $9.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      // Uniqueness of index keys is enforced as the index is written, so only
+      // UNIQUE WITHOUT INDEX constraints can have their checks deferred.
+      return unimplementedWithIssueDetail(sqllex, 31632, "deferrable")
+    }
     $$.val = &tree.UniqueConstraintTableDef{
       WithoutIndex: $2.bool(),
       IndexTableDef: tree.IndexTableDef{
@@ -6340,6 +6912,7 @@ constraint_elem:
         PartitionByIndex: $8.partitionByIndex(),
         Predicate: $10.expr(),
       },
+      Deferrability: $9.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_interleave
@@ -6363,6 +6936,7 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrability: $11.constraintDeferrability(),
     }
   }
 | EXCLUDE USING error
@@ -6472,13 +7046,33 @@ create_as_col_qualification_elem:
     $$.val = tree.PrimaryKeyConstraint{}
   }
 
+// NOT DEFERRABLE is not accepted since it would be ambiguous with NOT NULL
+// column qualifications and NOT VALID.
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| DEFERRABLE
+  {
+    $$.val = tree.ConstraintInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY DEFERRED
+  {
+    $$.val = tree.ConstraintInitiallyDeferred
+  }
+| INITIALLY DEFERRED
+  {
+    $$.val = tree.ConstraintInitiallyDeferred
+  }
+| INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
 
 storing:
   COVERING
@@ -6942,6 +7536,126 @@ opt_view_recursive:
 | RECURSIVE { return unimplemented(sqllex, "create recursive view") }
 
 
+// %Help: CREATE FUNCTION - create a user-defined function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <funcname> ( [ [<argname>] <argtype> [, ...] ] )
+//   RETURNS <rettype>
+//   LANGUAGE SQL
+//   [ IMMUTABLE | STABLE | VOLATILE ]
+//   [ [NOT] LEAKPROOF ]
+//   [ CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT ]
+//   AS '<body>'
+// %SeeAlso: DROP FUNCTION, SHOW CREATE
+create_func_stmt:
+  CREATE FUNCTION db_object_name '(' opt_func_arg_list ')' RETURNS typename opt_create_func_opt_list
+  {
+    $$.val = &tree.CreateFunction{
+      FuncName: $3.unresolvedObjectName(),
+      Args: $5.funcArgs(),
+      ReturnType: $8.typeReference(),
+      Options: $9.functionOptions(),
+    }
+  }
+| CREATE OR REPLACE FUNCTION db_object_name '(' opt_func_arg_list ')' RETURNS typename opt_create_func_opt_list
+  {
+    $$.val = &tree.CreateFunction{
+      FuncName: $5.unresolvedObjectName(),
+      Replace: true,
+      Args: $7.funcArgs(),
+      ReturnType: $10.typeReference(),
+      Options: $11.functionOptions(),
+    }
+  }
+| CREATE FUNCTION error // SHOW HELP: CREATE FUNCTION
+| CREATE OR REPLACE FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_func_arg_list:
+  func_arg_list
+| /* EMPTY */
+  {
+    $$.val = tree.FuncArgs(nil)
+  }
+
+func_arg_list:
+  func_arg
+  {
+    $$.val = tree.FuncArgs{$1.funcArg()}
+  }
+| func_arg_list ',' func_arg
+  {
+    $$.val = append($1.funcArgs(), $3.funcArg())
+  }
+
+func_arg:
+  type_function_name typename
+  {
+    $$.val = tree.FuncArg{Name: tree.Name($1), Type: $2.typeReference()}
+  }
+| typename
+  {
+    $$.val = tree.FuncArg{Type: $1.typeReference()}
+  }
+
+opt_create_func_opt_list:
+  create_func_opt_list
+| /* EMPTY */
+  {
+    $$.val = tree.FunctionOptions(nil)
+  }
+
+create_func_opt_list:
+  create_func_opt_item
+  {
+    $$.val = tree.FunctionOptions{$1.functionOption()}
+  }
+| create_func_opt_list create_func_opt_item
+  {
+    $$.val = append($1.functionOptions(), $2.functionOption())
+  }
+
+create_func_opt_item:
+  LANGUAGE non_reserved_word_or_sconst
+  {
+    $$.val = tree.FunctionLanguage(strings.ToUpper($2))
+  }
+| IMMUTABLE
+  {
+    $$.val = tree.FunctionVolatility(tree.VolatilityImmutable)
+  }
+| STABLE
+  {
+    $$.val = tree.FunctionVolatility(tree.VolatilityStable)
+  }
+| VOLATILE
+  {
+    $$.val = tree.FunctionVolatility(tree.VolatilityVolatile)
+  }
+| LEAKPROOF
+  {
+    $$.val = tree.FunctionLeakProof(true)
+  }
+| NOT LEAKPROOF
+  {
+    $$.val = tree.FunctionLeakProof(false)
+  }
+| CALLED ON NULL INPUT
+  {
+    $$.val = tree.FunctionCalledOnNullInput
+  }
+| RETURNS NULL ON NULL INPUT
+  {
+    $$.val = tree.FunctionReturnsNullOnNullInput
+  }
+| STRICT
+  {
+    $$.val = tree.FunctionStrict
+  }
+| AS SCONST
+  {
+    $$.val = tree.FunctionBody($2)
+  }
+
 // %Help: CREATE TYPE -- create a type
 // %Category: DDL
 // %Text: CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
@@ -7717,7 +8431,7 @@ transaction_stmt:
 // START TRANSACTION [ <txnparameter> [[,] ...] ]
 //
 // Transaction parameters:
-//    ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+//    ISOLATION LEVEL { SNAPSHOT | READ COMMITTED | SERIALIZABLE }
 //    PRIORITY { LOW | NORMAL | HIGH }
 //
 // %SeeAlso: COMMIT, ROLLBACK, WEBDOCS/begin-transaction.html
@@ -8898,9 +9612,18 @@ group_by_list:
 // rather than reducing the conflicting unreserved_keyword rule.
 group_by_item:
   a_expr { $$.val = $1.expr() }
-| ROLLUP '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "rollup") }
-| CUBE '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "cube") }
-| GROUPING SETS error { return unimplementedWithIssueDetail(sqllex, 46280, "grouping sets") }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.RollupGroupingSet, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.CubeGroupingSet, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.ExplicitGroupingSets, Exprs: $4.exprs()}
+  }
 
 having_clause:
   HAVING a_expr
@@ -10255,6 +10978,10 @@ a_expr:
   {
     $$.val = &tree.ComparisonExpr{Operator: tree.Overlaps, Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr AT_AT a_expr
+  {
+    $$.val = &tree.ComparisonExpr{Operator: tree.TSMatches, Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr INET_CONTAINS_OR_EQUALS a_expr
   {
     $$.val = &tree.FuncExpr{Func: tree.WrapFunction("inet_contains_or_equals"), Exprs: tree.Exprs{$1.expr(), $3.expr()}}
@@ -10742,7 +11469,10 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingExpr{Exprs: $3.exprs()}
+  }
 
 func_application:
   func_name '(' ')'
@@ -12210,6 +12940,7 @@ unrestricted_name:
 // "Unreserved" keywords --- available for use as any kind of name.
 unreserved_keyword:
   ABORT
+| ABSOLUTE
 | ACTION
 | ACCESS
 | ADD
@@ -12219,12 +12950,14 @@ unreserved_keyword:
 | AGGREGATE
 | ALTER
 | ALWAYS
+| ASENSITIVE
 | AT
 | ATTRIBUTE
 | AUTOMATIC
 | AVAILABILITY
 | BACKUP
 | BACKUPS
+| BACKWARD
 | BEFORE
 | BEGIN
 | BINARY
@@ -12232,6 +12965,7 @@ unreserved_keyword:
 | BUNDLE
 | BY
 | CACHE
+| CALLED
 | CANCEL
 | CANCELQUERY
 | CASCADE
@@ -12263,6 +12997,7 @@ unreserved_keyword:
 | CSV
 | CUBE
 | CURRENT
+| CURSOR
 | CYCLE
 | DATA
 | DATABASE
@@ -12304,6 +13039,7 @@ unreserved_keyword:
 | FIRST
 | FOLLOWING
 | FORCE_INDEX
+| FORWARD
 | FUNCTION
 | GENERATED
 | GEOMETRYM
@@ -12320,9 +13056,11 @@ unreserved_keyword:
 | HASH
 | HIGH
 | HISTOGRAM
+| HOLD
 | HOUR
 | IDENTITY
 | IMMEDIATE
+| IMMUTABLE
 | IMPORT
 | INCLUDE
 | INCLUDING
@@ -12331,6 +13069,8 @@ unreserved_keyword:
 | INDEXES
 | INHERITS
 | INJECT
+| INPUT
+| INSENSITIVE
 | INSERT
 | INTERLEAVE
 | INTO_DB
@@ -12348,11 +13088,13 @@ unreserved_keyword:
 | LATEST
 | LC_COLLATE
 | LC_CTYPE
+| LEAKPROOF
 | LEASE
 | LESS
 | LEVEL
 | LINESTRING
 | LIST
+| LISTEN
 | LOCAL
 | LOCKED
 | LOGIN
@@ -12367,6 +13109,7 @@ unreserved_keyword:
 | MINUTE
 | MINVALUE
 | MODIFYCLUSTERSETTING
+| MOVE
 | MULTILINESTRING
 | MULTILINESTRINGM
 | MULTILINESTRINGZ
@@ -12396,6 +13139,7 @@ unreserved_keyword:
 | NOLOGIN
 | NOMODIFYCLUSTERSETTING
 | NOVIEWACTIVITY
+| NOTIFY
 | NOWAIT
 | NULLS
 | IGNORE_FOREIGN_KEYS
@@ -12430,6 +13174,7 @@ unreserved_keyword:
 | PRECEDING
 | PREPARE
 | PRESERVE
+| PRIOR
 | PRIORITY
 | PRIVILEGES
 | PUBLIC
@@ -12448,6 +13193,7 @@ unreserved_keyword:
 | REGIONAL
 | REGIONS
 | REINDEX
+| RELATIVE
 | RELEASE
 | RENAME
 | REPEATABLE
@@ -12458,6 +13204,7 @@ unreserved_keyword:
 | RESTRICT
 | RESUME
 | RETRY
+| RETURNS
 | REVISION_HISTORY
 | REVOKE
 | ROLE
@@ -12469,6 +13216,7 @@ unreserved_keyword:
 | RUNNING
 | SCHEDULE
 | SCHEDULES
+| SCROLL
 | SETTING
 | SETTINGS
 | STATUS
@@ -12498,6 +13246,7 @@ unreserved_keyword:
 | SNAPSHOT
 | SPLIT
 | SQL
+| STABLE
 | START
 | STATEMENTS
 | STATISTICS
@@ -12534,6 +13283,7 @@ unreserved_keyword:
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLISTEN
 | UNLOGGED
 | UNSPLIT
 | UNTIL
@@ -12547,6 +13297,7 @@ unreserved_keyword:
 | VARYING
 | VIEW
 | VIEWACTIVITY
+| VOLATILE
 | WITHIN
 | WITHOUT
 | WRITE
@@ -217,6 +217,7 @@ var pgCatalog = virtualSchema{
 		catconstants.PgCatalogCollationTableID:           pgCatalogCollationTable,
 		catconstants.PgCatalogConstraintTableID:          pgCatalogConstraintTable,
 		catconstants.PgCatalogConversionTableID:          pgCatalogConversionTable,
+		catconstants.PgCatalogCursorsTableID:             pgCatalogCursorsTable,
 		catconstants.PgCatalogDatabaseTableID:            pgCatalogDatabaseTable,
 		catconstants.PgCatalogDefaultACLTableID:          pgCatalogDefaultACLTable,
 		catconstants.PgCatalogDependTableID:              pgCatalogDependTable,
@@ -765,6 +766,12 @@ func populateTableConstraints(
 		consrc := tree.DNull
 		conbin := tree.DNull
 		condef := tree.DNull
+		condeferrable := tree.MakeDBool(tree.DBool(
+			con.Deferrability() != descpb.ConstraintDeferrability_NotDeferrable,
+		))
+		condeferred := tree.MakeDBool(tree.DBool(
+			con.Deferrability() == descpb.ConstraintDeferrability_InitiallyDeferred,
+		))
 
 		// Determine constraint kind-specific fields.
 		var err error
@@ -856,6 +863,10 @@ func populateTableConstraints(
 				}
 				f.WriteString(strings.Join(colNames, ", "))
 				f.WriteByte(')')
+				if d := con.UniqueWithoutIndexConstraint.Deferrability; d != descpb.ConstraintDeferrability_NotDeferrable {
+					f.WriteString(" ")
+					f.WriteString(descpb.ConstraintDeferrabilityType[d].String())
+				}
 				if con.UniqueWithoutIndexConstraint.Validity != descpb.ConstraintValidity_Validated {
 					f.WriteString(" NOT VALID")
 				}
@@ -899,8 +910,8 @@ func populateTableConstraints(
 			dNameOrNull(conName), // conname
 			namespaceOid,         // connamespace
 			contype,              // contype
-			tree.DBoolFalse,      // condeferrable
-			tree.DBoolFalse,      // condeferred
+			condeferrable,        // condeferrable
+			condeferred,          // condeferred
 			tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -1861,6 +1872,35 @@ https://www.postgresql.org/docs/9.6/view-pg-prepared-xacts.html`,
 	},
 }
 
+// pgCatalogCursorsTable implements the pg_cursors table.
+var pgCatalogCursorsTable = virtualSchemaTable{
+	comment: `open cursors
+https://www.postgresql.org/docs/current/view-pg-cursors.html`,
+	schema: vtable.PGCatalogCursors,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if p.extendedEvalCtx.SQLCursors == nil {
+			return nil
+		}
+		for _, cursor := range p.extendedEvalCtx.SQLCursors.list() {
+			ts, err := tree.MakeDTimestampTZ(cursor.created, time.Microsecond)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				tree.NewDString(string(cursor.name)),
+				tree.NewDString(cursor.statement),
+				tree.DBoolFalse, /* is_holdable */
+				tree.DBoolFalse, /* is_binary */
+				tree.DBoolFalse, /* is_scrollable */
+				ts,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // pgCatalogPreparedStatementsTable implements the pg_prepared_statements table.
 // The statement field differs in that it uses the parsed version
 // of the PREPARE statement.
@@ -2607,6 +2647,8 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.OidFamily:         typCategoryNumeric,
 	types.UuidFamily:        typCategoryUserDefined,
 	types.INetFamily:        typCategoryNetworkAddr,
+	types.TSQueryFamily:     typCategoryUserDefined,
+	types.TSVectorFamily:    typCategoryUserDefined,
 	types.UnknownFamily:     typCategoryUnknown,
 }
 
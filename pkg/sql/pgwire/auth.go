@@ -32,6 +32,15 @@ const (
 	// authCleartextPassword is the pgwire auth response code to request
 	// a plaintext password during the connection handshake.
 	authCleartextPassword int32 = 3
+	// authSASL is the pgwire auth response code to start a SASL
+	// authentication, listing the mechanisms supported by the server.
+	authSASL int32 = 10
+	// authSASLContinue is the pgwire auth response code to send a SASL
+	// challenge to the client.
+	authSASLContinue int32 = 11
+	// authSASLFinal is the pgwire auth response code to send the outcome of
+	// a SASL authentication, with the final data of the mechanism.
+	authSASLFinal int32 = 12
 )
 
 type authOptions struct {
@@ -43,6 +43,13 @@ func loadDefaultMethods() {
 	// method over secure connections, e.g. those encrypted using SSL.
 	RegisterAuthMethod("password", authPassword, hba.ConnAny, nil)
 
+	// The "scram-sha-256" method performs a SCRAM-SHA-256 exchange
+	// (RFC 7677), so that the password is never sent to the server.
+	//
+	// It requires the password of the user to be stored as a SCRAM
+	// verifier; see the server.user_login.password_encryption setting.
+	RegisterAuthMethod("scram-sha-256", authScram, hba.ConnAny, nil)
+
 	// The "cert" method requires a valid client certificate for the
 	// user attempting to connect.
 	//
@@ -88,7 +95,7 @@ func authPassword(
 	_ tls.ConnectionState,
 	pwRetrieveFn PasswordRetrievalFn,
 	pwValidUntilFn PasswordValidUntilFn,
-	_ *sql.ExecutorConfig,
+	execCfg *sql.ExecutorConfig,
 	_ *hba.Entry,
 ) (security.UserAuthHook, error) {
 	if err := c.SendAuthRequest(authCleartextPassword, nil /* data */); err != nil {
@@ -110,20 +117,41 @@ func authPassword(
 		c.LogAuthInfof(ctx, "user has no password defined")
 	}
 
+	if err := checkPasswordExpiry(ctx, c, pwValidUntilFn); err != nil {
+		return nil, err
+	}
+
+	hook := security.UserAuthPasswordHook(
+		false /*insecure*/, password, hashedPassword,
+	)
+	return func(requestedUser security.SQLUsername, clientConnection bool) (func(), error) {
+		connClose, err := hook(requestedUser, clientConnection)
+		if err != nil {
+			return connClose, err
+		}
+		// Now that the password is known to be correct, convert its
+		// stored hash to the configured method if needed.
+		sql.MaybeUpgradeStoredPasswordHash(ctx, execCfg, requestedUser, password, hashedPassword)
+		return connClose, nil
+	}, nil
+}
+
+// checkPasswordExpiry returns an error if the password of the user is
+// expired.
+func checkPasswordExpiry(
+	ctx context.Context, c AuthConn, pwValidUntilFn PasswordValidUntilFn,
+) error {
 	validUntil, err := pwValidUntilFn(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if validUntil != nil {
 		if validUntil.Sub(timeutil.Now()) < 0 {
 			c.LogAuthFailed(ctx, eventpb.AuthFailReason_CREDENTIALS_EXPIRED, nil)
-			return nil, errors.New("password is expired")
+			return errors.New("password is expired")
 		}
 	}
-
-	return security.UserAuthPasswordHook(
-		false /*insecure*/, password, hashedPassword,
-	), nil
+	return nil
 }
 
 func passwordString(pwdData []byte) (string, error) {
@@ -0,0 +1,259 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/errors"
+)
+
+// scramMechanism is the only SASL mechanism supported by the server. The
+// channel binding variant, SCRAM-SHA-256-PLUS, is not supported.
+const scramMechanism = "SCRAM-SHA-256"
+
+// scramNonceLength is the number of random bytes in the nonce generated by
+// the server.
+const scramNonceLength = 18
+
+func authScram(
+	ctx context.Context,
+	c AuthConn,
+	_ tls.ConnectionState,
+	pwRetrieveFn PasswordRetrievalFn,
+	pwValidUntilFn PasswordValidUntilFn,
+	_ *sql.ExecutorConfig,
+	_ *hba.Entry,
+) (security.UserAuthHook, error) {
+	hashedPassword, err := pwRetrieveFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// If the user has no SCRAM verifier, the exchange is performed with a
+	// mock verifier and fails at the end, like in PostgreSQL.
+	var verifier security.ScramVerifier
+	validVerifier := false
+	switch security.GetPasswordHashMethod(hashedPassword) {
+	case security.HashSCRAMSHA256:
+		if verifier, err = security.ParseScramVerifier(hashedPassword); err != nil {
+			return nil, err
+		}
+		validVerifier = true
+	case security.HashMissingPassword:
+		c.LogAuthInfof(ctx, "user has no password defined")
+	default:
+		c.LogAuthInfof(ctx, "user password is not stored as a SCRAM-SHA-256 verifier")
+	}
+	if !validVerifier {
+		if verifier, err = security.MakeMockScramVerifier(); err != nil {
+			return nil, err
+		}
+	}
+
+	validProof, err := scramExchange(c, verifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPasswordExpiry(ctx, c, pwValidUntilFn); err != nil {
+		return nil, err
+	}
+
+	return func(requestedUser security.SQLUsername, clientConnection bool) (func(), error) {
+		if requestedUser.Undefined() {
+			return nil, errors.New("user is missing")
+		}
+		if !clientConnection {
+			return nil, errors.New("password authentication is only available for client connections")
+		}
+		if !validVerifier || !validProof {
+			return nil, errors.Errorf(security.ErrPasswordUserAuthFailed, requestedUser)
+		}
+		return nil, nil
+	}, nil
+}
+
+// scramExchange performs the SASL exchange of the SCRAM-SHA-256 mechanism
+// (RFC 5802) with the client, and returns whether the proof sent by the
+// client is valid for the verifier. The final server message, which proves
+// to the client that the server knows the verifier, is only sent if the
+// proof is valid.
+func scramExchange(c AuthConn, verifier security.ScramVerifier) (validProof bool, _ error) {
+	// Advertise the supported mechanisms. The list is terminated by an
+	// empty string.
+	if err := c.SendAuthRequest(authSASL, []byte(scramMechanism+"\x00\x00")); err != nil {
+		return false, err
+	}
+
+	// The SASLInitialResponse message contains the name of the selected
+	// mechanism and the client-first-message.
+	data, err := c.GetPwdData()
+	if err != nil {
+		return false, err
+	}
+	mechanism, clientFirst, err := parseSASLInitialResponse(data)
+	if err != nil {
+		return false, err
+	}
+	if mechanism != scramMechanism {
+		return false, pgerror.Newf(pgcode.InvalidAuthorizationSpecification,
+			"client selected an invalid SASL authentication mechanism: %q", mechanism)
+	}
+	gs2Header, clientFirstBare, clientNonce, err := parseScramClientFirst(clientFirst)
+	if err != nil {
+		return false, err
+	}
+
+	// Send the server-first-message, with the salt and iteration count of
+	// the verifier.
+	nonceBytes := make([]byte, scramNonceLength)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return false, err
+	}
+	nonce := clientNonce + base64.StdEncoding.EncodeToString(nonceBytes)
+	serverFirst := "r=" + nonce +
+		",s=" + base64.StdEncoding.EncodeToString(verifier.Salt) +
+		",i=" + strconv.Itoa(verifier.Iterations)
+	if err := c.SendAuthRequest(authSASLContinue, []byte(serverFirst)); err != nil {
+		return false, err
+	}
+
+	// The SASLResponse message contains the client-final-message.
+	data, err = c.GetPwdData()
+	if err != nil {
+		return false, err
+	}
+	clientFinalWithoutProof, proof, err := parseScramClientFinal(string(data), gs2Header, nonce)
+	if err != nil {
+		return false, err
+	}
+
+	authMessage := []byte(clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+	if !verifier.VerifyClientProof(authMessage, proof) {
+		return false, nil
+	}
+	serverFinal := "v=" + base64.StdEncoding.EncodeToString(verifier.ServerSignature(authMessage))
+	if err := c.SendAuthRequest(authSASLFinal, []byte(serverFinal)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// parseSASLInitialResponse splits the payload of a SASLInitialResponse
+// message into the name of the mechanism and the initial response.
+func parseSASLInitialResponse(data []byte) (mechanism string, response string, _ error) {
+	idx := bytes.IndexByte(data, 0)
+	if idx < 0 || len(data) < idx+5 {
+		return "", "", newSASLProtocolViolation("malformed SASLInitialResponse message")
+	}
+	mechanism = string(data[:idx])
+	data = data[idx+1:]
+	length := int32(binary.BigEndian.Uint32(data))
+	data = data[4:]
+	if length < 0 {
+		length = 0
+	}
+	if int(length) != len(data) {
+		return "", "", newSASLProtocolViolation("malformed SASLInitialResponse message")
+	}
+	return mechanism, string(data), nil
+}
+
+// parseScramClientFirst parses a client-first-message. It returns the GS2
+// header, which the client repeats in its final message, the rest of the
+// message, which is part of the authentication message, and the nonce of
+// the client.
+func parseScramClientFirst(
+	msg string,
+) (gs2Header, clientFirstBare, clientNonce string, _ error) {
+	// The GS2 header is made of the channel binding flag and the optional
+	// authorization identity, each followed by a comma.
+	parts := strings.SplitN(msg, ",", 3)
+	if len(parts) != 3 {
+		return "", "", "", newSASLProtocolViolation("malformed SCRAM message")
+	}
+	switch {
+	case parts[0] == "n" || parts[0] == "y":
+		// The client does not use channel binding.
+	case strings.HasPrefix(parts[0], "p="):
+		return "", "", "", newSASLProtocolViolation(
+			"channel binding is not supported with the SCRAM-SHA-256 mechanism")
+	default:
+		return "", "", "", newSASLProtocolViolation("malformed SCRAM message")
+	}
+	if parts[1] != "" {
+		// The user name is taken from the startup message.
+		return "", "", "", newSASLProtocolViolation(
+			"client uses an authorization identity, which is not supported")
+	}
+	gs2Header = parts[0] + ",,"
+	clientFirstBare = parts[2]
+
+	// The user name attribute is ignored, like in PostgreSQL: the user name
+	// is taken from the startup message.
+	attrs := strings.Split(clientFirstBare, ",")
+	if len(attrs) < 2 || !strings.HasPrefix(attrs[0], "n=") || !strings.HasPrefix(attrs[1], "r=") {
+		return "", "", "", newSASLProtocolViolation("malformed SCRAM message")
+	}
+	for _, attr := range attrs[2:] {
+		if strings.HasPrefix(attr, "m=") {
+			return "", "", "", newSASLProtocolViolation("unsupported SCRAM extension")
+		}
+	}
+	clientNonce = attrs[1][len("r="):]
+	if clientNonce == "" {
+		return "", "", "", newSASLProtocolViolation("malformed SCRAM message")
+	}
+	return gs2Header, clientFirstBare, clientNonce, nil
+}
+
+// parseScramClientFinal parses a client-final-message. It checks that the
+// channel binding data and the nonce match the rest of the exchange, and
+// returns the message without the proof along with the decoded proof.
+func parseScramClientFinal(
+	msg, gs2Header, nonce string,
+) (clientFinalWithoutProof string, proof []byte, _ error) {
+	idx := strings.LastIndex(msg, ",p=")
+	if idx < 0 {
+		return "", nil, newSASLProtocolViolation("malformed SCRAM message")
+	}
+	clientFinalWithoutProof = msg[:idx]
+	proof, err := base64.StdEncoding.DecodeString(msg[idx+len(",p="):])
+	if err != nil {
+		return "", nil, newSASLProtocolViolation("malformed SCRAM proof")
+	}
+	attrs := strings.Split(clientFinalWithoutProof, ",")
+	if len(attrs) < 2 {
+		return "", nil, newSASLProtocolViolation("malformed SCRAM message")
+	}
+	if attrs[0] != "c="+base64.StdEncoding.EncodeToString([]byte(gs2Header)) {
+		return "", nil, newSASLProtocolViolation("unexpected SCRAM channel binding data")
+	}
+	if attrs[1] != "r="+nonce {
+		return "", nil, newSASLProtocolViolation("SCRAM nonce mismatch")
+	}
+	return clientFinalWithoutProof, proof, nil
+}
+
+func newSASLProtocolViolation(msg string) error {
+	return pgerror.New(pgcode.ProtocolViolation, msg)
+}
@@ -46,6 +46,7 @@ import (
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/stdstrings"
 	"github.com/cockroachdb/redact"
+	"github.com/jackc/pgx"
 	"github.com/lib/pq"
 )
 
@@ -639,3 +640,92 @@ func TestClientAddrOverride(t *testing.T) {
 }
 
 var sessionTerminatedRe = regexp.MustCompile("client_session_end")
+
+// TestSCRAMAuthentication checks the scram-sha-256 authentication method,
+// and the conversion of bcrypt password hashes upon password
+// authentication.
+func TestSCRAMAuthentication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+	pgServer := s.(*server.TestServer).PGServer()
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	setHBAConf := func(hbaConf string) {
+		sqlDB.Exec(t, `SET CLUSTER SETTING server.host_based_authentication.configuration = $1`, hbaConf)
+		expConf, err := pgwire.ParseAndNormalize(hbaConf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutils.SucceedsSoon(t, func() error {
+			if curConf := pgServer.GetAuthenticationConfiguration(); expConf.String() != curConf.String() {
+				return errors.Newf("HBA config not yet loaded\ngot:\n%s\nexpected:\n%s", curConf, expConf)
+			}
+			return nil
+		})
+	}
+	hashMethod := func(user string) string {
+		var hash []byte
+		sqlDB.QueryRow(t, `SELECT "hashedPassword" FROM system.users WHERE username = $1`, user).Scan(&hash)
+		return security.GetPasswordHashMethod(hash).String()
+	}
+	connect := func(user, password string) error {
+		pgURL, cleanupFn := sqlutils.PGUrlWithOptionalClientCerts(
+			t, s.ServingSQLAddr(), t.Name(), url.UserPassword(user, password), false, /* withClientCerts */
+		)
+		defer cleanupFn()
+		cfg, err := pgx.ParseURI(pgURL.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := pgx.Connect(cfg)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING server.user_login.password_encryption = 'crdb-bcrypt'`)
+	sqlDB.Exec(t, `CREATE USER bcrypt_user WITH PASSWORD 'abc'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING server.user_login.password_encryption = 'scram-sha-256'`)
+	sqlDB.Exec(t, `CREATE USER scram_user WITH PASSWORD 'def'`)
+	if m := hashMethod("bcrypt_user"); m != "crdb-bcrypt" {
+		t.Fatalf("expected bcrypt hash, got %s", m)
+	}
+	if m := hashMethod("scram_user"); m != "scram-sha-256" {
+		t.Fatalf("expected SCRAM verifier, got %s", m)
+	}
+
+	setHBAConf("host all all all scram-sha-256\n")
+	if err := connect("scram_user", "def"); err != nil {
+		t.Fatal(err)
+	}
+	if err := connect("scram_user", "xyz"); !testutils.IsError(err, "password authentication failed") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+	// The bcrypt hash cannot be used for a SCRAM exchange.
+	if err := connect("bcrypt_user", "abc"); !testutils.IsError(err, "password authentication failed") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+
+	// A successful password authentication converts the bcrypt hash.
+	setHBAConf("host all all all password\n")
+	if err := connect("bcrypt_user", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if m := hashMethod("bcrypt_user"); m != "scram-sha-256" {
+		t.Fatalf("expected converted SCRAM verifier, got %s", m)
+	}
+	// SCRAM verifiers can also be used for password authentication.
+	if err := connect("scram_user", "def"); err != nil {
+		t.Fatal(err)
+	}
+
+	setHBAConf("host all all all scram-sha-256\n")
+	if err := connect("bcrypt_user", "abc"); err != nil {
+		t.Fatal(err)
+	}
+}
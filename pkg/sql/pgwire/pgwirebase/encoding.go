@@ -482,6 +482,16 @@ func DecodeDatum(
 				return nil, err
 			}
 			return tree.ParseDJSON(string(b))
+		case oid.T_tsquery:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return tree.ParseDTSQuery(string(b))
+		case oid.T_tsvector:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return tree.ParseDTSVector(string(b))
 		}
 		if _, ok := types.ArrayOids[id]; ok {
 			// Arrays come in in their string form, so we parse them as such and later
@@ -18,6 +18,7 @@ func _() {
 	_ = x[ServerMsgEmptyQuery-73]
 	_ = x[ServerMsgErrorResponse-69]
 	_ = x[ServerMsgNoticeResponse-78]
+	_ = x[ServerMsgNotificationResponse-65]
 	_ = x[ServerMsgNoData-110]
 	_ = x[ServerMsgParameterDescription-116]
 	_ = x[ServerMsgParameterStatus-83]
@@ -28,23 +29,24 @@ func _() {
 }
 
 const (
-	_ServerMessageType_name_0 = "ServerMsgParseCompleteServerMsgBindCompleteServerMsgCloseComplete"
-	_ServerMessageType_name_1 = "ServerMsgCommandCompleteServerMsgDataRowServerMsgErrorResponse"
-	_ServerMessageType_name_2 = "ServerMsgCopyInResponse"
-	_ServerMessageType_name_3 = "ServerMsgEmptyQuery"
-	_ServerMessageType_name_4 = "ServerMsgBackendKeyData"
-	_ServerMessageType_name_5 = "ServerMsgNoticeResponse"
-	_ServerMessageType_name_6 = "ServerMsgAuthServerMsgParameterStatusServerMsgRowDescription"
-	_ServerMessageType_name_7 = "ServerMsgReady"
-	_ServerMessageType_name_8 = "ServerMsgNoData"
-	_ServerMessageType_name_9 = "ServerMsgPortalSuspendedServerMsgParameterDescription"
+	_ServerMessageType_name_0  = "ServerMsgParseCompleteServerMsgBindCompleteServerMsgCloseComplete"
+	_ServerMessageType_name_1  = "ServerMsgNotificationResponse"
+	_ServerMessageType_name_2  = "ServerMsgCommandCompleteServerMsgDataRowServerMsgErrorResponse"
+	_ServerMessageType_name_3  = "ServerMsgCopyInResponse"
+	_ServerMessageType_name_4  = "ServerMsgEmptyQuery"
+	_ServerMessageType_name_5  = "ServerMsgBackendKeyData"
+	_ServerMessageType_name_6  = "ServerMsgNoticeResponse"
+	_ServerMessageType_name_7  = "ServerMsgAuthServerMsgParameterStatusServerMsgRowDescription"
+	_ServerMessageType_name_8  = "ServerMsgReady"
+	_ServerMessageType_name_9  = "ServerMsgNoData"
+	_ServerMessageType_name_10 = "ServerMsgPortalSuspendedServerMsgParameterDescription"
 )
 
 var (
-	_ServerMessageType_index_0 = [...]uint8{0, 22, 43, 65}
-	_ServerMessageType_index_1 = [...]uint8{0, 24, 40, 62}
-	_ServerMessageType_index_6 = [...]uint8{0, 13, 37, 60}
-	_ServerMessageType_index_9 = [...]uint8{0, 24, 53}
+	_ServerMessageType_index_0  = [...]uint8{0, 22, 43, 65}
+	_ServerMessageType_index_2  = [...]uint8{0, 24, 40, 62}
+	_ServerMessageType_index_7  = [...]uint8{0, 13, 37, 60}
+	_ServerMessageType_index_10 = [...]uint8{0, 24, 53}
 )
 
 func (i ServerMessageType) String() string {
@@ -52,27 +54,29 @@ func (i ServerMessageType) String() string {
 	case 49 <= i && i <= 51:
 		i -= 49
 		return _ServerMessageType_name_0[_ServerMessageType_index_0[i]:_ServerMessageType_index_0[i+1]]
+	case i == 65:
+		return _ServerMessageType_name_1
 	case 67 <= i && i <= 69:
 		i -= 67
-		return _ServerMessageType_name_1[_ServerMessageType_index_1[i]:_ServerMessageType_index_1[i+1]]
+		return _ServerMessageType_name_2[_ServerMessageType_index_2[i]:_ServerMessageType_index_2[i+1]]
 	case i == 71:
-		return _ServerMessageType_name_2
-	case i == 73:
 		return _ServerMessageType_name_3
-	case i == 75:
+	case i == 73:
 		return _ServerMessageType_name_4
-	case i == 78:
+	case i == 75:
 		return _ServerMessageType_name_5
+	case i == 78:
+		return _ServerMessageType_name_6
 	case 82 <= i && i <= 84:
 		i -= 82
-		return _ServerMessageType_name_6[_ServerMessageType_index_6[i]:_ServerMessageType_index_6[i+1]]
+		return _ServerMessageType_name_7[_ServerMessageType_index_7[i]:_ServerMessageType_index_7[i+1]]
 	case i == 90:
-		return _ServerMessageType_name_7
-	case i == 110:
 		return _ServerMessageType_name_8
+	case i == 110:
+		return _ServerMessageType_name_9
 	case 115 <= i && i <= 116:
 		i -= 115
-		return _ServerMessageType_name_9[_ServerMessageType_index_9[i]:_ServerMessageType_index_9[i+1]]
+		return _ServerMessageType_name_10[_ServerMessageType_index_10[i]:_ServerMessageType_index_10[i+1]]
 	default:
 		return "ServerMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
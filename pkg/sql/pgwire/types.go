@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timetz"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 )
@@ -190,6 +191,12 @@ func (b *writeBuffer) writeTextDatum(
 	case *tree.DJSON:
 		b.writeLengthPrefixedString(v.JSON.String())
 
+	case *tree.DTSQuery:
+		b.writeLengthPrefixedString(v.TSQuery.String())
+
+	case *tree.DTSVector:
+		b.writeLengthPrefixedString(v.TSVector.String())
+
 	case *tree.DTuple:
 		b.textFormatter.FormatNode(v)
 		b.writeFromFmtCtx(b.textFormatter)
@@ -517,6 +524,14 @@ func (b *writeBuffer) writeBinaryDatum(
 		// Postgres version number, as of writing, `1` is the only valid value.
 		b.writeByte(1)
 		b.writeString(s)
+	case *tree.DTSQuery:
+		s := tsearch.EncodeTSQueryPGBinary(nil, v.TSQuery)
+		b.putInt32(int32(len(s)))
+		b.write(s)
+	case *tree.DTSVector:
+		s := tsearch.EncodeTSVectorPGBinary(nil, v.TSVector)
+		b.putInt32(int32(len(s)))
+		b.write(s)
 	case *tree.DOid:
 		b.putInt32(4)
 		b.putInt32(int32(v.DInt))
@@ -127,6 +127,10 @@ func getPlanColumns(plan planNode, mut bool) colinfo.ResultColumns {
 	case *hookFnNode:
 		return n.getColumns(mut, n.header)
 
+	// The columns in the fetchNode are those of the cursor's query.
+	case *fetchNode:
+		return n.getColumns(mut, n.cursor.rows.Types())
+
 	// Nodes that have the same schema as their source or their
 	// valueNode helper.
 	case *bufferNode:
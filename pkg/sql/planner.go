@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/cancelchecker"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -90,6 +91,23 @@ type extendedEvalContext struct {
 	// jobsCollection.
 	Jobs *jobsCollection
 
+	// ListenActions refers to listenActions in extraTxnState. It accumulates
+	// the LISTEN and UNLISTEN statements run by the current transaction.
+	ListenActions *listenActionsCollection
+
+	// Notifications refers to the LISTEN and NOTIFY state of the session.
+	Notifications *sessionNotifications
+
+	// SQLCursors refers to sqlCursors in extraTxnState. It holds the cursors
+	// declared by the current transaction.
+	SQLCursors *sqlCursors
+
+	// DeferredConstraints refers to deferredConstraints in extraTxnState. It
+	// holds the FK and uniqueness checks deferred until the end of the current
+	// transaction. It is nil for internal executors, which always run checks
+	// immediately.
+	DeferredConstraints *deferredConstraints
+
 	// SchemaChangeJobCache refers to schemaChangeJobsCache in extraTxnState.
 	SchemaChangeJobCache map[descpb.ID]*jobs.Job
 
@@ -750,3 +768,28 @@ func (p *planner) CompactEngineSpan(
 	_, err = client.CompactEngineSpan(ctx, req)
 	return err
 }
+
+// RequestStatementBundle is part of the EvalPlanner interface. It inserts a
+// statement diagnostics request, which the nodes pick up to trace the
+// executions of the statements with the given fingerprint. The bundles are
+// collected like the ones of EXPLAIN ANALYZE (DEBUG).
+func (p *planner) RequestStatementBundle(
+	ctx context.Context,
+	fingerprint string,
+	samplingProbability float64,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+	continuous bool,
+) error {
+	if err := p.RequireAdminRole(ctx, "request statement bundles"); err != nil {
+		return err
+	}
+	return p.ExecCfg().StmtDiagnosticsRecorder.InsertConditionalRequest(
+		ctx, fingerprint, stmtdiagnostics.RequestOptions{
+			SamplingProbability: samplingProbability,
+			MinExecutionLatency: minExecutionLatency,
+			ExpiresAfter:        expiresAfter,
+			Continuous:          continuous,
+		},
+	)
+}
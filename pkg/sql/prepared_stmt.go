@@ -16,6 +16,7 @@ import (
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -34,6 +35,9 @@ const (
 	// PreparedStatementOriginSQL signifies the prepared statement was made
 	// over a parsed SQL query.
 	PreparedStatementOriginSQL
+	// PreparedStatementOriginSessionMigration signifies the prepared statement
+	// was restored along with a session migrated from another SQL pod.
+	PreparedStatementOriginSessionMigration
 )
 
 // PreparedStatement is a SQL statement that has been parsed and the types
@@ -113,6 +117,14 @@ type preparedStatementsAccessor interface {
 	Delete(ctx context.Context, name string) bool
 	// DeleteAll removes all prepared statements and portals from the collection.
 	DeleteAll(ctx context.Context)
+	// Add prepares the given statement and adds it to the collection under the
+	// provided name. It is used to restore the prepared statements of a session
+	// migrated from another SQL pod, while the planner executes the statement
+	// restoring the session. It is an error if a statement with that name
+	// already exists.
+	Add(
+		ctx context.Context, name string, stmt parser.Statement, placeholderHints tree.PlaceholderTypes,
+	) error
 }
 
 // PreparedPortal is a PreparedStatement that has been bound with query arguments.
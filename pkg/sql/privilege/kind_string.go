@@ -19,11 +19,12 @@ func _() {
 	_ = x[USAGE-9]
 	_ = x[ZONECONFIG-10]
 	_ = x[CONNECT-11]
+	_ = x[EXECUTE-12]
 }
 
-const _Kind_name = "ALLCREATEDROPGRANTSELECTINSERTDELETEUPDATEUSAGEZONECONFIGCONNECT"
+const _Kind_name = "ALLCREATEDROPGRANTSELECTINSERTDELETEUPDATEUSAGEZONECONFIGCONNECTEXECUTE"
 
-var _Kind_index = [...]uint8{0, 3, 9, 13, 18, 24, 30, 36, 42, 47, 57, 64}
+var _Kind_index = [...]uint8{0, 3, 9, 13, 18, 24, 30, 36, 42, 47, 57, 64, 71}
 
 func (i Kind) String() string {
 	i -= 1
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -125,6 +126,9 @@ func (p *planner) UnsafeUpsertDescriptor(
 	case *typedesc.Mutable:
 		md.TypeDescriptor = *desc.GetType()
 		objectType = privilege.Type
+	case *funcdesc.Mutable:
+		md.FunctionDescriptor = *desc.GetFunction()
+		objectType = privilege.Function
 	case nil:
 		// nolint:descriptormarshal
 		if tableDesc := desc.GetTable(); tableDesc != nil {
@@ -139,6 +143,9 @@ func (p *planner) UnsafeUpsertDescriptor(
 		} else if typeDesc := desc.GetType(); typeDesc != nil {
 			existing = typedesc.NewCreatedMutable(*typeDesc)
 			objectType = privilege.Type
+		} else if funcDesc := desc.GetFunction(); funcDesc != nil {
+			existing = funcdesc.NewCreatedMutable(*funcDesc)
+			objectType = privilege.Function
 		} else {
 			return pgerror.New(pgcode.InvalidTableDefinition, "invalid ")
 		}
@@ -304,6 +311,11 @@ func logPrivilegeEvents(
 			CommonSQLPrivilegeEventDetails: eventDetails,
 			TypeName:                       md.GetName(),
 		})
+	case *funcdesc.Mutable:
+		return p.logEvent(ctx, existing.GetID(), &eventpb.ChangeFunctionPrivilege{
+			CommonSQLPrivilegeEventDetails: eventDetails,
+			FunctionName:                   md.GetName(),
+		})
 	}
 	return nil
 }
@@ -384,7 +396,7 @@ func (p *planner) UnsafeUpsertNamespaceEntry(
 		switch desc.(type) {
 		case nil:
 			return nil
-		case *tabledesc.Mutable, *typedesc.Mutable:
+		case *tabledesc.Mutable, *typedesc.Mutable, *funcdesc.Mutable:
 			if parentID == 0 || parentSchemaID == 0 {
 				return pgerror.Newf(pgcode.InvalidCatalogName,
 					"invalid prefix (%d, %d) for object %d",
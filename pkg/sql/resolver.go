@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
@@ -365,6 +366,28 @@ func getDescriptorsFromTargetListForPrivilegeChange(
 		return descs, nil
 	}
 
+	if targets.Functions != nil {
+		if len(targets.Functions) == 0 {
+			return nil, errNoFunction
+		}
+		descs := make([]catalog.Descriptor, 0, len(targets.Functions))
+		for i := range targets.Functions {
+			_, descriptor, err := p.ResolveMutableFunctionDescriptor(
+				ctx, &targets.Functions[i], true, /* required */
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			descs = append(descs, descriptor)
+		}
+
+		if len(descs) == 0 {
+			return nil, errNoMatch
+		}
+		return descs, nil
+	}
+
 	if targets.Schemas != nil {
 		if len(targets.Schemas) == 0 {
 			return nil, errNoSchema
@@ -834,6 +857,8 @@ func newInternalLookupCtxFromDescriptors(
 			descriptors[i] = typedesc.NewImmutable(*t.Type)
 		case *descpb.Descriptor_Schema:
 			descriptors[i] = schemadesc.NewImmutable(*t.Schema)
+		case *descpb.Descriptor_Function:
+			descriptors[i] = funcdesc.NewImmutable(*t.Function)
 		}
 	}
 	lCtx := newInternalLookupCtx(ctx, descriptors, prefix, nil /* fallback */)
@@ -0,0 +1,108 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+// defaultRowLevelTTLDeletionCron is the schedule of the deletion job of
+// tables with row-level TTL which do not specify ttl_job_cron.
+const defaultRowLevelTTLDeletionCron = "@hourly"
+
+// rowLevelTTLExpirationColDef returns the definition of the hidden column
+// holding the expiration time of the rows of a table with row-level TTL.
+func rowLevelTTLExpirationColDef(expireAfter string) (*tree.ColumnTableDef, error) {
+	defaultExpr, err := parser.ParseExpr(
+		fmt.Sprintf("current_timestamp() + %s::INTERVAL", lex.EscapeSQLString(expireAfter)),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ttl_expire_after %q", expireAfter)
+	}
+	c := &tree.ColumnTableDef{
+		Name:   colinfo.TTLDefaultExpirationColumnName,
+		Type:   types.TimestampTZ,
+		Hidden: true,
+	}
+	c.Nullable.Nullability = tree.NotNull
+	c.DefaultExpr.Expr = defaultExpr
+	return c, nil
+}
+
+// createRowLevelTTLScheduledJob creates the schedule which periodically
+// deletes the expired rows of the given table, and records its ID in the
+// table's row-level TTL config.
+func (p *planner) createRowLevelTTLScheduledJob(
+	ctx context.Context, tblDesc *tabledesc.Mutable,
+) error {
+	ttl := tblDesc.RowLevelTTL
+	sj := jobs.NewScheduledJob(jobSchedulerEnv(p.ExecCfg()))
+	sj.SetScheduleLabel(fmt.Sprintf("row-level-ttl-%d", tblDesc.GetID()))
+	sj.SetOwner(security.RootUserName())
+	cron := ttl.DeletionCron
+	if cron == "" {
+		cron = defaultRowLevelTTLDeletionCron
+	}
+	if err := sj.SetSchedule(cron); err != nil {
+		return err
+	}
+	sj.SetScheduleDetails(jobspb.ScheduleDetails{
+		Wait:    jobspb.ScheduleDetails_SKIP,
+		OnError: jobspb.ScheduleDetails_RETRY_SCHED,
+	})
+	args, err := pbtypes.MarshalAny(&jobspb.ScheduledRowLevelTTLArgs{TableID: tblDesc.GetID()})
+	if err != nil {
+		return err
+	}
+	sj.SetExecutionDetails(
+		tree.ScheduledRowLevelTTLExecutor.InternalName(),
+		jobspb.ExecutionArguments{Args: args},
+	)
+	if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.txn); err != nil {
+		return err
+	}
+	ttl.ScheduleID = sj.ScheduleID()
+	return nil
+}
+
+// deleteRowLevelTTLScheduledJob deletes the schedule of the deletion job of
+// the given table, if any.
+func (p *planner) deleteRowLevelTTLScheduledJob(
+	ctx context.Context, tblDesc *tabledesc.Mutable,
+) error {
+	if !tblDesc.HasRowLevelTTL() || tblDesc.RowLevelTTL.ScheduleID == 0 {
+		return nil
+	}
+	env := jobSchedulerEnv(p.ExecCfg())
+	_, err := p.ExecCfg().InternalExecutor.ExecEx(
+		ctx,
+		"delete-row-level-ttl-schedule",
+		p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		fmt.Sprintf("DELETE FROM %s WHERE schedule_id = $1", env.ScheduledJobsTableName()),
+		tblDesc.RowLevelTTL.ScheduleID,
+	)
+	return err
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/timetz"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
@@ -186,6 +187,21 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 		return encoding.EncodeBytesDescending(b, t.PhysicalRep), nil
 	case *tree.DJSON:
 		return nil, unimplemented.NewWithIssue(35706, "unable to encode JSON as a table key")
+	case *tree.DTSQuery:
+		// TSQuery and TSVector values are never used as keys of forward indexes,
+		// so their key encoding doesn't need to be order-preserving. It is only
+		// used when they are elements of an array in an inverted index.
+		data := tsearch.EncodeTSQuery(nil /* appendTo */, t.TSQuery)
+		if dir == encoding.Ascending {
+			return encoding.EncodeBytesAscending(b, data), nil
+		}
+		return encoding.EncodeBytesDescending(b, data), nil
+	case *tree.DTSVector:
+		data := tsearch.EncodeTSVector(nil /* appendTo */, t.TSVector)
+		if dir == encoding.Ascending {
+			return encoding.EncodeBytesAscending(b, data), nil
+		}
+		return encoding.EncodeBytesDescending(b, data), nil
 	}
 	return nil, errors.Errorf("unable to encode table key: %T", val)
 }
@@ -327,6 +343,36 @@ func DecodeTableKey(
 		}
 		a.DoneInitNewDGeo(so)
 		return g, rkey, err
+	case types.TSQueryFamily:
+		var r []byte
+		if dir == encoding.Ascending {
+			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
+		} else {
+			rkey, r, err = encoding.DecodeBytesDescending(key, nil)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		q, err := tsearch.DecodeTSQuery(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tree.NewDTSQuery(q), rkey, nil
+	case types.TSVectorFamily:
+		var r []byte
+		if dir == encoding.Ascending {
+			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
+		} else {
+			rkey, r, err = encoding.DecodeBytesDescending(key, nil)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		v, err := tsearch.DecodeTSVector(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tree.NewDTSVector(v), rkey, nil
 	case types.DateFamily:
 		var t int64
 		if dir == encoding.Ascending {
@@ -502,6 +548,10 @@ func EncodeTableValue(
 		return encoding.EncodeIntValue(appendTo, uint32(colID), int64(t.DInt)), nil
 	case *tree.DEnum:
 		return encoding.EncodeBytesValue(appendTo, uint32(colID), t.PhysicalRep), nil
+	case *tree.DTSQuery:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), tsearch.EncodeTSQuery(scratch[:0], t.TSQuery)), nil
+	case *tree.DTSVector:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), tsearch.EncodeTSVector(scratch[:0], t.TSVector)), nil
 	default:
 		return nil, errors.Errorf("unable to encode table value: %T", t)
 	}
@@ -676,6 +726,26 @@ func DecodeUntaggedDatum(a *DatumAlloc, t *types.T, buf []byte) (tree.Datum, []b
 			return nil, nil, err
 		}
 		return a.NewDEnum(tree.DEnum{EnumTyp: t, PhysicalRep: phys, LogicalRep: log}), b, nil
+	case types.TSQueryFamily:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		q, err := tsearch.DecodeTSQuery(data)
+		if err != nil {
+			return nil, b, err
+		}
+		return tree.NewDTSQuery(q), b, nil
+	case types.TSVectorFamily:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		v, err := tsearch.DecodeTSVector(data)
+		if err != nil {
+			return nil, b, err
+		}
+		return tree.NewDTSVector(v), b, nil
 	default:
 		return nil, buf, errors.Errorf("couldn't decode type %s", t)
 	}
@@ -832,6 +902,16 @@ func MarshalColumnValue(col *descpb.ColumnDescriptor, val tree.Datum) (roachpb.V
 			r.SetBytes(v.PhysicalRep)
 			return r, nil
 		}
+	case types.TSQueryFamily:
+		if v, ok := val.(*tree.DTSQuery); ok {
+			r.SetBytes(tsearch.EncodeTSQuery(nil, v.TSQuery))
+			return r, nil
+		}
+	case types.TSVectorFamily:
+		if v, ok := val.(*tree.DTSVector); ok {
+			r.SetBytes(tsearch.EncodeTSVector(nil, v.TSVector))
+			return r, nil
+		}
 	default:
 		return r, errors.AssertionFailedf("unsupported column type: %s", col.Type.Family())
 	}
@@ -1011,6 +1091,26 @@ func UnmarshalColumnValue(a *DatumAlloc, typ *types.T, value roachpb.Value) (tre
 			return nil, err
 		}
 		return a.NewDEnum(tree.DEnum{EnumTyp: typ, PhysicalRep: phys, LogicalRep: log}), nil
+	case types.TSQueryFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		q, err := tsearch.DecodeTSQuery(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDTSQuery(q), nil
+	case types.TSVectorFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		vec, err := tsearch.DecodeTSVector(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDTSVector(vec), nil
 	default:
 		return nil, errors.Errorf("unsupported column type: %s", typ.Family())
 	}
@@ -1314,7 +1414,8 @@ func datumTypeToArrayElementEncodingType(t *types.T) (encoding.Type, error) {
 		return encoding.Geo, nil
 	case types.DecimalFamily:
 		return encoding.Decimal, nil
-	case types.BytesFamily, types.StringFamily, types.CollatedStringFamily, types.EnumFamily:
+	case types.BytesFamily, types.StringFamily, types.CollatedStringFamily, types.EnumFamily,
+		types.TSQueryFamily, types.TSVectorFamily:
 		return encoding.Bytes, nil
 	case types.TimestampFamily, types.TimestampTZFamily:
 		return encoding.Time, nil
@@ -1406,6 +1507,10 @@ func encodeArrayElement(b []byte, d tree.Datum) ([]byte, error) {
 		return encodeArrayElement(b, t.Wrapped)
 	case *tree.DEnum:
 		return encoding.EncodeUntaggedBytesValue(b, t.PhysicalRep), nil
+	case *tree.DTSQuery:
+		return encoding.EncodeUntaggedBytesValue(b, tsearch.EncodeTSQuery(nil, t.TSQuery)), nil
+	case *tree.DTSVector:
+		return encoding.EncodeUntaggedBytesValue(b, tsearch.EncodeTSVector(nil, t.TSVector)), nil
 	default:
 		return nil, errors.Errorf("don't know how to encode %s (%T)", d, d)
 	}
@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -173,6 +174,10 @@ func RandDatumWithNullChance(rng *rand.Rand, typ *types.T, nullChance int) tree.
 			return nil
 		}
 		return &tree.DJSON{JSON: j}
+	case types.TSQueryFamily:
+		return tree.NewDTSQuery(tsearch.RandomTSQuery(rng))
+	case types.TSVectorFamily:
+		return tree.NewDTSVector(tsearch.RandomTSVector(rng))
 	case types.TupleFamily:
 		tuple := tree.DTuple{D: make(tree.Datums, len(typ.TupleContents()))}
 		for i := range typ.TupleContents() {
@@ -635,6 +640,36 @@ var (
 			}
 			return res
 		}(),
+		types.TSQueryFamily: func() []tree.Datum {
+			var res []tree.Datum
+			for _, s := range []string{
+				``,
+				`a`,
+				`'fat':*AB & !(rat | cat) <2> mat`,
+			} {
+				d, err := tree.ParseDTSQuery(s)
+				if err != nil {
+					panic(err)
+				}
+				res = append(res, d)
+			}
+			return res
+		}(),
+		types.TSVectorFamily: func() []tree.Datum {
+			var res []tree.Datum
+			for _, s := range []string{
+				``,
+				`a`,
+				`'a b' fat:1A,3 cat:2B rat:16383`,
+			} {
+				d, err := tree.ParseDTSVector(s)
+				if err != nil {
+					panic(err)
+				}
+				res = append(res, d)
+			}
+			return res
+		}(),
 		types.BitFamily: func() []tree.Datum {
 			var res []tree.Datum
 			for _, i := range []int64{
@@ -19,7 +19,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/cancelchecker"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/optional"
@@ -69,6 +71,19 @@ type aggregatorBase struct {
 	orderedGroupCols []uint32
 	aggregations     []execinfrapb.AggregatorSpec_Aggregation
 
+	// groupingSets is set if the aggregations are computed separately for each
+	// of several grouping sets (see AggregatorSpec.GroupingSets). In that case
+	// every output row has an additional column with the index of its grouping
+	// set.
+	groupingSets []execinfrapb.AggregatorSpec_GroupingSet
+	// groupingSetNulls contains, for each grouping set, the ANY_NOT_NULL
+	// aggregations of grouping columns that are not part of the grouping set.
+	// These aggregations produce NULL for the groups of the grouping set.
+	groupingSetNulls []util.FastIntSet
+	// nullAggs is the set of aggregations that produce NULL for the bucket that
+	// is being emitted.
+	nullAggs util.FastIntSet
+
 	lastOrdGroupCols rowenc.EncDatumRow
 	arena            stringarena.Arena
 	row              rowenc.EncDatumRow
@@ -103,8 +118,7 @@ func (ag *aggregatorBase) init(
 	ag.orderedGroupCols = spec.OrderedGroupCols
 	ag.aggregations = spec.Aggregations
 	ag.funcs = make([]*aggregateFuncHolder, len(spec.Aggregations))
-	ag.outputTypes = make([]*types.T, len(spec.Aggregations))
-	ag.row = make(rowenc.EncDatumRow, len(spec.Aggregations))
+	ag.outputTypes = make([]*types.T, len(spec.Aggregations), len(spec.Aggregations)+1)
 	ag.bucketsAcc = memMonitor.MakeBoundAccount()
 	ag.arena = stringarena.Make(&ag.bucketsAcc)
 	ag.aggFuncsAcc = memMonitor.MakeBoundAccount()
@@ -141,6 +155,13 @@ func (ag *aggregatorBase) init(
 		}
 		ag.outputTypes[i] = outputType
 	}
+	if len(spec.GroupingSets) > 0 {
+		if err := ag.initGroupingSets(spec); err != nil {
+			return err
+		}
+		ag.outputTypes = append(ag.outputTypes, types.Int)
+	}
+	ag.row = make(rowenc.EncDatumRow, len(ag.outputTypes))
 
 	return ag.ProcessorBase.Init(
 		self, post, ag.outputTypes, flowCtx, processorID, output, memMonitor,
@@ -151,6 +172,39 @@ func (ag *aggregatorBase) init(
 	)
 }
 
+// initGroupingSets validates the grouping sets of the spec and initializes
+// groupingSets and groupingSetNulls.
+func (ag *aggregatorBase) initGroupingSets(spec *execinfrapb.AggregatorSpec) error {
+	if ag.isScalar || len(ag.orderedGroupCols) > 0 {
+		return errors.AssertionFailedf(
+			"grouping sets require a non-scalar aggregation without ordered grouping columns",
+		)
+	}
+	var groupCols util.FastIntSet
+	for _, c := range ag.groupCols {
+		groupCols.Add(int(c))
+	}
+	ag.groupingSets = spec.GroupingSets
+	ag.groupingSetNulls = make([]util.FastIntSet, len(spec.GroupingSets))
+	for i := range spec.GroupingSets {
+		var set util.FastIntSet
+		for _, c := range spec.GroupingSets[i].Cols {
+			if !groupCols.Contains(int(c)) {
+				return errors.AssertionFailedf("grouping set column %d is not a grouping column", c)
+			}
+			set.Add(int(c))
+		}
+		for j, agg := range spec.Aggregations {
+			if agg.Func == execinfrapb.AggregatorSpec_ANY_NOT_NULL && len(agg.ColIdx) == 1 {
+				if c := int(agg.ColIdx[0]); groupCols.Contains(c) && !set.Contains(c) {
+					ag.groupingSetNulls[i].Add(j)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // execStatsForTrace implements ProcessorBase.ExecStatsForTrace.
 func (ag *aggregatorBase) execStatsForTrace() *execinfrapb.ComponentStats {
 	is, ok := getInputStats(ag.input)
@@ -261,7 +315,7 @@ func newAggregator(
 	if spec.IsRowCount() {
 		return newCountAggregator(flowCtx, processorID, input, post, output)
 	}
-	if len(spec.OrderedGroupCols) == len(spec.GroupCols) {
+	if len(spec.OrderedGroupCols) == len(spec.GroupCols) && len(spec.GroupingSets) == 0 {
 		return newOrderedAggregator(flowCtx, processorID, spec, input, post, output)
 	}
 
@@ -445,9 +499,22 @@ func (ag *hashAggregator) accumulateRows() (
 		}
 	}
 
-	// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing was
-	// aggregated.
-	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
+	if ag.groupingSets != nil {
+		// Each empty grouping set produces a row even if nothing was
+		// aggregated.
+		for i := range ag.groupingSets {
+			if len(ag.groupingSets[i].Cols) == 0 {
+				key := encoding.EncodeUvarintAscending(ag.scratch, uint64(i))
+				ag.scratch = key[:0]
+				if _, err := ag.getBucket(key); err != nil {
+					ag.MoveToDraining(err)
+					return aggStateUnknown, nil, nil
+				}
+			}
+		}
+	} else if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
+		// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing
+		// was aggregated.
 		bucket, err := ag.createAggregateFuncs()
 		if err != nil {
 			ag.MoveToDraining(err)
@@ -537,6 +604,10 @@ func (ag *aggregatorBase) getAggResults(
 	defer bucket.close(ag.Ctx)
 
 	for i, b := range bucket {
+		if ag.nullAggs.Contains(i) {
+			ag.row[i] = rowenc.DatumToEncDatum(ag.outputTypes[i], tree.DNull)
+			continue
+		}
 		result, err := b.Result()
 		if err != nil {
 			ag.MoveToDraining(err)
@@ -615,6 +686,20 @@ func (ag *hashAggregator) emitRow() (
 	bucket := ag.bucketsIter[0]
 	ag.bucketsIter = ag.bucketsIter[1:]
 
+	if ag.groupingSets != nil {
+		// The group key of the bucket starts with the index of its grouping
+		// set.
+		_, setIdx, err := encoding.DecodeUvarintAscending(encoding.UnsafeConvertStringToBytes(bucket))
+		if err != nil {
+			ag.MoveToDraining(err)
+			return aggStateUnknown, nil, nil
+		}
+		ag.nullAggs = ag.groupingSetNulls[setIdx]
+		ag.row[len(ag.funcs)] = rowenc.DatumToEncDatum(
+			types.Int, ag.datumAlloc.NewDInt(tree.DInt(setIdx)),
+		)
+	}
+
 	// Once we get the results from the bucket, we can delete it from the map.
 	// This will allow us to return the memory to the system before the hash
 	// aggregator is fully done (which matters when we have many buckets).
@@ -801,12 +886,12 @@ func (ag *aggregatorBase) accumulateRowIntoBucket(
 	return nil
 }
 
-// encode returns the encoding for the grouping columns, this is then used as
-// our group key to determine which bucket to add to.
+// encode returns the encoding for the given grouping columns, this is then used
+// as our group key to determine which bucket to add to.
 func (ag *hashAggregator) encode(
-	appendTo []byte, row rowenc.EncDatumRow,
+	appendTo []byte, row rowenc.EncDatumRow, groupCols []uint32,
 ) (encoding []byte, err error) {
-	for _, colIdx := range ag.groupCols {
+	for _, colIdx := range groupCols {
 		// We might allocate tree.Datums when hashing the row, so we'll ask the
 		// fingerprint to account for them. Note that if the datums are later
 		// used by the aggregate functions (and accounted for accordingly),
@@ -828,36 +913,65 @@ func (ag *hashAggregator) accumulateRow(row rowenc.EncDatumRow) error {
 		return err
 	}
 
+	if ag.groupingSets == nil {
+		return ag.accumulateRowIntoGroup(row, ag.scratch, ag.groupCols)
+	}
+	// The row is accumulated into one group of every grouping set. The group
+	// keys are prefixed by the index of the grouping set, so that the groups of
+	// different grouping sets are kept apart.
+	for i := range ag.groupingSets {
+		prefix := encoding.EncodeUvarintAscending(ag.scratch, uint64(i))
+		if err := ag.accumulateRowIntoGroup(row, prefix, ag.groupingSets[i].Cols); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accumulateRowIntoGroup accumulates a single row into the bucket of its group
+// on the given grouping columns. The group key is appended to appendTo.
+func (ag *hashAggregator) accumulateRowIntoGroup(
+	row rowenc.EncDatumRow, appendTo []byte, groupCols []uint32,
+) error {
 	// The encoding computed here determines which bucket the non-grouping
 	// datums are accumulated to.
-	encoded, err := ag.encode(ag.scratch, row)
+	encoded, err := ag.encode(appendTo, row, groupCols)
 	if err != nil {
 		return err
 	}
 	ag.scratch = encoded[:0]
 
+	bucket, err := ag.getBucket(encoded)
+	if err != nil {
+		return err
+	}
+	return ag.accumulateRowIntoBucket(row, encoded, bucket)
+}
+
+// getBucket returns the bucket for the given group key, creating it if
+// necessary.
+func (ag *hashAggregator) getBucket(encoded []byte) (aggregateFuncs, error) {
 	bucket, ok := ag.buckets[string(encoded)]
 	if !ok {
 		s, err := ag.arena.AllocBytes(ag.Ctx, encoded)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		bucket, err = ag.createAggregateFuncs()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		ag.buckets[s] = bucket
 		if len(ag.buckets) == ag.bucketsLenGrowThreshold {
 			toAccountFor := ag.bucketsLenGrowThreshold - ag.alreadyAccountedFor
 			if err := ag.bucketsAcc.Grow(ag.Ctx, int64(toAccountFor)*hashAggregatorSizeOfBucketsItem); err != nil {
-				return err
+				return nil, err
 			}
 			ag.alreadyAccountedFor = ag.bucketsLenGrowThreshold
 			ag.bucketsLenGrowThreshold *= 2
 		}
 	}
-
-	return ag.accumulateRowIntoBucket(row, encoded, bucket)
+	return bucket, nil
 }
 
 // accumulateRow accumulates a single row, returning an error if accumulation
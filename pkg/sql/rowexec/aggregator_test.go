@@ -386,6 +386,74 @@ func TestAggregator(t *testing.T) {
 				},
 			},
 		},
+		{
+			// SELECT @1, @2, sum_int(@3), count_rows(), count(DISTINCT @2)
+			// GROUP BY ROLLUP (@1, @2).
+			Name: "GroupingSets",
+			Input: ProcessorTestCaseRows{
+				Rows: [][]interface{}{
+					{1, 1, 1},
+					{1, 2, 2},
+					{2, 1, 3},
+					{1, 1, 4},
+				},
+				Types: rowenc.MakeIntCols(3),
+			},
+			Output: ProcessorTestCaseRows{
+				Rows: [][]interface{}{
+					{1, 1, 5, 2, 1, 0},
+					{1, 2, 2, 1, 1, 0},
+					{2, 1, 3, 1, 1, 0},
+					{1, nil, 7, 3, 2, 1},
+					{2, nil, 3, 1, 1, 1},
+					{nil, nil, 10, 4, 2, 2},
+				},
+				Types: rowenc.MakeIntCols(6),
+			},
+			ProcessorCore: execinfrapb.ProcessorCoreUnion{
+				Aggregator: &execinfrapb.AggregatorSpec{
+					Type:      execinfrapb.AggregatorSpec_NON_SCALAR,
+					GroupCols: []uint32{0, 1},
+					GroupingSets: []execinfrapb.AggregatorSpec_GroupingSet{
+						{Cols: []uint32{0, 1}},
+						{Cols: col0},
+						{},
+					},
+					Aggregations: aggregations([]aggTestSpec{
+						{fname: "ANY_NOT_NULL", colIdx: col0},
+						{fname: "ANY_NOT_NULL", colIdx: col1},
+						{fname: "SUM_INT", colIdx: col2},
+						{fname: "COUNT_ROWS"},
+						{fname: "COUNT", distinct: true, colIdx: col1},
+					}),
+				},
+			},
+		},
+		{
+			// SELECT @1, count_rows() GROUP BY GROUPING SETS ((@1), ()) (no rows).
+			Name: "GroupingSetsNoRows",
+			Input: ProcessorTestCaseRows{
+				Rows:  [][]interface{}{},
+				Types: rowenc.MakeIntCols(1),
+			},
+			Output: ProcessorTestCaseRows{
+				Rows: [][]interface{}{
+					{nil, 0, 1},
+				},
+				Types: rowenc.MakeIntCols(3),
+			},
+			ProcessorCore: execinfrapb.ProcessorCoreUnion{
+				Aggregator: &execinfrapb.AggregatorSpec{
+					Type:         execinfrapb.AggregatorSpec_NON_SCALAR,
+					GroupCols:    col0,
+					GroupingSets: []execinfrapb.AggregatorSpec_GroupingSet{{Cols: col0}, {}},
+					Aggregations: aggregations([]aggTestSpec{
+						{fname: "ANY_NOT_NULL", colIdx: col0},
+						{fname: "COUNT_ROWS"},
+					}),
+				},
+			},
+		},
 	}
 
 	ctx := context.Background()
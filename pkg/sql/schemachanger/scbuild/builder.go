@@ -649,6 +649,8 @@ var minimumTypeUsageVersions = map[types.Family]clusterversion.Key{
 	types.GeographyFamily: clusterversion.GeospatialType,
 	types.GeometryFamily:  clusterversion.GeospatialType,
 	types.Box2DFamily:     clusterversion.Box2DType,
+	types.TSQueryFamily:   clusterversion.TSearchTypes,
+	types.TSVectorFamily:  clusterversion.TSearchTypes,
 }
 
 // isTypeSupportedInVersion returns whether a given type is supported in the given version.
@@ -37,6 +37,7 @@ func init() {
 	initGeoBuiltins()
 	initPGBuiltins()
 	initMathBuiltins()
+	initTSearchBuiltins()
 
 	AllBuiltinNames = make([]string, 0, len(builtins))
 	AllAggregateBuiltinNames = make([]string, 0, len(aggregates))
@@ -114,6 +114,7 @@ const (
 	categorySystemInfo          = "System info"
 	categorySystemRepair        = "System repair"
 	categoryStreamIngestion     = "Stream Ingestion"
+	categoryStreamReplication   = "Stream Replication"
 )
 
 func categorizeType(t *types.T) string {
@@ -2975,8 +2976,6 @@ may increase either contention or retry errors, or both.`,
 	})),
 
 	// Full text search functions.
-	"ts_match_qv":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"ts_match_vq":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"tsvector_cmp":                   makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"tsvector_concat":                makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_debug":                       makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
@@ -2986,18 +2985,13 @@ may increase either contention or retry errors, or both.`,
 	"array_to_tsvector":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"get_current_ts_config":          makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"numnode":                        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"plainto_tsquery":                makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"phraseto_tsquery":               makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"querytree":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"setweight":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"strip":                          makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"to_tsquery":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"to_tsvector":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"json_to_tsvector":               makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"jsonb_to_tsvector":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_delete":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_filter":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"ts_rank":                        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_rank_cd":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_rewrite":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"tsquery_phrase":                 makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
@@ -4193,6 +4187,50 @@ may increase either contention or retry errors, or both.`,
 			Volatility: tree.VolatilityVolatile,
 		},
 	),
+	"crdb_internal.request_statement_bundle": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"stmt_fingerprint", types.String},
+				{"sampling_probability", types.Float},
+				{"min_execution_latency", types.Interval},
+				{"expires_after", types.Interval},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return requestStatementBundle(ctx, args, false /* continuous */)
+			},
+			Info: "Requests a statement diagnostics bundle for the next execution of the statements " +
+				"with the given fingerprint whose latency is at least `min_execution_latency`. " +
+				"If `sampling_probability` is not zero, only this fraction of the executions is " +
+				"traced. The request expires after `expires_after`, unless it is zero. " +
+				"Requires the admin role.",
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"stmt_fingerprint", types.String},
+				{"sampling_probability", types.Float},
+				{"min_execution_latency", types.Interval},
+				{"expires_after", types.Interval},
+				{"continuous", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return requestStatementBundle(ctx, args[:4], bool(tree.MustBeDBool(args[4])))
+			},
+			Info: "Like the overload without `continuous`, but if `continuous` is true, a bundle is " +
+				"collected for every traced execution whose latency is at least " +
+				"`min_execution_latency` until the request expires, rather than for the first one. " +
+				"Only the latest `sql.stmt_diagnostics.max_bundles_per_request` bundles are retained. " +
+				"Continuous requests must expire. Requires the admin role.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	// Returns the number of distinct inverted index entries that would be
 	// generated for a value.
 	"crdb_internal.num_geo_inverted_index_entries": makeBuiltin(
@@ -4294,6 +4332,15 @@ may increase either contention or retry errors, or both.`,
 			Info:       "This function is used only by CockroachDB's developers for testing purposes.",
 			Volatility: tree.VolatilityStable,
 		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"val", types.TSVector}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsvectorNumInvertedIndexEntries(ctx, args[0])
+			},
+			Info:       "This function is used only by CockroachDB's developers for testing purposes.",
+			Volatility: tree.VolatilityStable,
+		},
 		tree.Overload{
 			Types: tree.ArgTypes{
 				{"val", types.Jsonb},
@@ -4322,6 +4369,20 @@ may increase either contention or retry errors, or both.`,
 			},
 			Info:       "This function is used only by CockroachDB's developers for testing purposes.",
 			Volatility: tree.VolatilityStable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"val", types.TSVector},
+				{"version", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				// The version argument is ignored for TSVector inverted indexes, since
+				// they were introduced after all versions of inverted indexes.
+				return tsvectorNumInvertedIndexEntries(ctx, args[0])
+			},
+			Info:       "This function is used only by CockroachDB's developers for testing purposes.",
+			Volatility: tree.VolatilityStable,
 		}),
 
 	// Returns true iff the current user has admin role.
@@ -4751,6 +4812,44 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"crdb_internal.serialize_session": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+			Undocumented:     true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{},
+			ReturnType: tree.FixedReturnType(types.Bytes),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return ctx.Planner.SerializeSessionState()
+			},
+			Info: "This function serializes the variables and prepared statements of the current " +
+				"session, so that they can be restored with crdb_internal.deserialize_session " +
+				"in a session of the same user. It is used to migrate idle connections between SQL pods.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.deserialize_session": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+			Undocumented:     true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"session", types.Bytes}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return ctx.Planner.DeserializeSessionState(args[0].(*tree.DBytes))
+			},
+			Info: "This function restores in the current session the variables and prepared " +
+				"statements serialized by crdb_internal.serialize_session. It is used to migrate " +
+				"idle connections between SQL pods.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	"crdb_internal.increment_feature_counter": makeBuiltin(
 		tree.FunctionProperties{
 			Category:     categorySystemInfo,
@@ -7002,6 +7101,23 @@ func checkPrivilegedUser(ctx *tree.EvalContext) error {
 	return nil
 }
 
+// requestStatementBundle implements crdb_internal.request_statement_bundle.
+func requestStatementBundle(
+	ctx *tree.EvalContext, args tree.Datums, continuous bool,
+) (tree.Datum, error) {
+	fingerprint := string(tree.MustBeDString(args[0]))
+	samplingProbability := float64(tree.MustBeDFloat(args[1]))
+	minExecutionLatency := tree.MustBeDInterval(args[2]).AsFloat64() * float64(time.Second)
+	expiresAfter := tree.MustBeDInterval(args[3]).AsFloat64() * float64(time.Second)
+	if err := ctx.Planner.RequestStatementBundle(
+		ctx.Context, fingerprint, samplingProbability,
+		time.Duration(minExecutionLatency), time.Duration(expiresAfter), continuous,
+	); err != nil {
+		return nil, err
+	}
+	return tree.DBoolTrue, nil
+}
+
 // EvalFollowerReadOffset is a function used often with AS OF SYSTEM TIME queries
 // to determine the appropriate offset from now which is likely to be safe for
 // follower reads. It is injected by followerreadsccl. An error may be returned
@@ -7067,6 +7183,13 @@ func jsonNumInvertedIndexEntries(_ *tree.EvalContext, val tree.Datum) (tree.Datu
 	return tree.NewDInt(tree.DInt(n)), nil
 }
 
+func tsvectorNumInvertedIndexEntries(_ *tree.EvalContext, val tree.Datum) (tree.Datum, error) {
+	if val == tree.DNull {
+		return tree.DZero, nil
+	}
+	return tree.NewDInt(tree.DInt(len(tree.MustBeDTSVector(val).TSVector))), nil
+}
+
 func arrayNumInvertedIndexEntries(
 	ctx *tree.EvalContext, val, version tree.Datum,
 ) (tree.Datum, error) {
@@ -23,7 +23,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/streaming"
 	"github.com/cockroachdb/cockroach/pkg/util/arith"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
@@ -326,6 +328,49 @@ var generators = map[string]builtinDefinition{
 			tree.VolatilityVolatile,
 		),
 	),
+
+	"crdb_internal.stream_topology": makeBuiltin(
+		tree.FunctionProperties{
+			Class:            tree.GeneratorClass,
+			Category:         categoryStreamReplication,
+			DistsqlBlocklist: true,
+		},
+		makeGeneratorOverload(
+			tree.ArgTypes{
+				{Name: "tenant_id", Typ: types.Int},
+			},
+			streaming.StreamTopologyGeneratorType,
+			makeStreamTopologyGenerator,
+			"Returns the partitions that a replication stream of the tenant's keyspace "+
+				"is split into. Each row contains the start and end key of a partition, "+
+				"which can be consumed with crdb_internal.stream_partition.",
+			tree.VolatilityVolatile,
+		),
+	),
+
+	"crdb_internal.stream_partition": makeBuiltin(
+		tree.FunctionProperties{
+			Class:            tree.GeneratorClass,
+			Category:         categoryStreamReplication,
+			DistsqlBlocklist: true,
+		},
+		makeGeneratorOverload(
+			tree.ArgTypes{
+				{Name: "tenant_id", Typ: types.Int},
+				{Name: "start_key", Typ: types.Bytes},
+				{Name: "end_key", Typ: types.Bytes},
+				{Name: "start_time", Typ: types.Decimal},
+			},
+			streaming.StreamPartitionGeneratorType,
+			makeStreamPartitionGenerator,
+			"Streams the changes to a partition of the tenant's keyspace that happen "+
+				"after start_time. A zero start_time first emits the current contents of "+
+				"the partition. Rows with a non-NULL key are KV events, and rows with a NULL "+
+				"key are checkpoints indicating that all changes up to timestamp have been "+
+				"emitted. The stream does not terminate on its own.",
+			tree.VolatilityVolatile,
+		),
+	),
 }
 
 func makeGeneratorOverload(
@@ -1363,3 +1408,49 @@ func (rk *rangeKeyIterator) Values() (tree.Datums, error) {
 
 // Close implements the tree.ValueGenerator interface.
 func (rk *rangeKeyIterator) Close() {}
+
+// tenantIDFromDatum returns the tenant ID held by the provided datum.
+func tenantIDFromDatum(d tree.Datum) (roachpb.TenantID, error) {
+	sTenID := int64(tree.MustBeDInt(d))
+	if sTenID <= 0 {
+		return roachpb.TenantID{}, pgerror.New(pgcode.InvalidParameterValue, "tenant ID must be positive")
+	}
+	return roachpb.MakeTenantID(uint64(sTenID)), nil
+}
+
+func makeStreamTopologyGenerator(
+	evalCtx *tree.EvalContext, args tree.Datums,
+) (tree.ValueGenerator, error) {
+	if streaming.StreamTopologyHook == nil {
+		return nil, sqlerrors.NewCCLRequiredError(
+			errors.New("replication streams require a CCL binary"))
+	}
+	tenantID, err := tenantIDFromDatum(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return streaming.StreamTopologyHook(evalCtx, tenantID)
+}
+
+func makeStreamPartitionGenerator(
+	evalCtx *tree.EvalContext, args tree.Datums,
+) (tree.ValueGenerator, error) {
+	if streaming.StreamPartitionHook == nil {
+		return nil, sqlerrors.NewCCLRequiredError(
+			errors.New("replication streams require a CCL binary"))
+	}
+	tenantID, err := tenantIDFromDatum(args[0])
+	if err != nil {
+		return nil, err
+	}
+	span := roachpb.Span{
+		Key:    roachpb.Key(tree.MustBeDBytes(args[1])),
+		EndKey: roachpb.Key(tree.MustBeDBytes(args[2])),
+	}
+	startTimeDecimal := tree.MustBeDDecimal(args[3])
+	startTime, err := tree.DecimalToHLC(&startTimeDecimal.Decimal)
+	if err != nil {
+		return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+	}
+	return streaming.StreamPartitionHook(evalCtx, tenantID, span, startTime)
+}
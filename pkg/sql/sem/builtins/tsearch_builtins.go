@@ -0,0 +1,224 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package builtins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
+)
+
+func initTSearchBuiltins() {
+	// Add all tsearchBuiltins to the Builtins map after a sanity check.
+	for k, v := range tsearchBuiltins {
+		if _, exists := builtins[k]; exists {
+			panic("duplicate builtin: " + k)
+		}
+		builtins[k] = v
+	}
+}
+
+func tsearchProps() tree.FunctionProperties {
+	return tree.FunctionProperties{Category: categoryFullTextSearch}
+}
+
+// tsearchConfigInfo is appended to the description of the functions that take
+// a text search configuration.
+var tsearchConfigInfo = fmt.Sprintf(
+	" The supported configurations are %s. If no configuration is given, %s is used.",
+	strings.Join(tsearch.ValidConfigNames(), " and "), tsearch.DefaultConfig,
+)
+
+// tsqueryBuiltin makes the overloads of a builtin that turns a string into a
+// TSQuery using the given function, with an optional configuration argument.
+func tsqueryBuiltin(
+	fn func(configName string, input string) (tsearch.TSQuery, error), info string,
+) builtinDefinition {
+	return makeBuiltin(tsearchProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"text", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				q, err := fn(tsearch.DefaultConfig, string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSQuery(q), nil
+			},
+			Info:       info + tsearchConfigInfo,
+			Volatility: tree.VolatilityStable,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {"text", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				q, err := fn(string(tree.MustBeDString(args[0])), string(tree.MustBeDString(args[1])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSQuery(q), nil
+			},
+			Info:       info + tsearchConfigInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+	)
+}
+
+// tsRank evaluates the ts_rank builtin. weights is nil if the default weights
+// should be used.
+func tsRank(weights tree.Datum, vector, query tree.Datum, normalization int) (tree.Datum, error) {
+	var w []float32
+	if weights != nil {
+		arr := tree.MustBeDArray(weights)
+		w = make([]float32, arr.Len())
+		for i, d := range arr.Array {
+			if d == tree.DNull {
+				return nil, pgerror.New(pgcode.NullValueNotAllowed, "array of weight must not contain nulls")
+			}
+			w[i] = float32(tree.MustBeDFloat(d))
+		}
+	}
+	rank, err := tsearch.Rank(
+		w, tree.MustBeDTSVector(vector).TSVector, tree.MustBeDTSQuery(query).TSQuery, normalization,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDFloat(tree.DFloat(rank)), nil
+}
+
+const tsRankInfo = "Ranks how well `vector` matches `query`, based on the frequency " +
+	"of the matching lexemes and on their proximity. `weights` are the weights of " +
+	"the D, C, B and A labels, in that order, and default to {0.1, 0.2, 0.4, 1.0}. " +
+	"`normalization` is a bit mask that controls how the rank is scaled by the length " +
+	"of the document: 1 divides it by 1 + the logarithm of the length, 2 by the length, " +
+	"8 by the number of unique lexemes, 16 by 1 + the logarithm of that number, and " +
+	"32 by itself + 1."
+
+var tsearchBuiltins = map[string]builtinDefinition{
+	"to_tsvector": makeBuiltin(tsearchProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"text", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				v, err := tsearch.ToTSVector(tsearch.DefaultConfig, string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSVector(v), nil
+			},
+			Info:       "Converts `text` to a tsvector, normalizing its words into lexemes." + tsearchConfigInfo,
+			Volatility: tree.VolatilityStable,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {"text", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				v, err := tsearch.ToTSVector(string(tree.MustBeDString(args[0])), string(tree.MustBeDString(args[1])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSVector(v), nil
+			},
+			Info:       "Converts `text` to a tsvector, normalizing its words into lexemes." + tsearchConfigInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"to_tsquery": tsqueryBuiltin(tsearch.ToTSQuery,
+		"Converts `text` to a tsquery, normalizing its words into lexemes. `text` must "+
+			"be a valid tsquery, whose operands may be made of several words."),
+
+	"plainto_tsquery": tsqueryBuiltin(tsearch.PlainToTSQuery,
+		"Converts `text` to a tsquery that matches documents that contain all of its "+
+			"words, normalized into lexemes. Punctuation in `text` is ignored."),
+
+	"phraseto_tsquery": tsqueryBuiltin(tsearch.PhraseToTSQuery,
+		"Converts `text` to a tsquery that matches documents that contain its words "+
+			"in order, normalized into lexemes. Punctuation in `text` is ignored."),
+
+	"ts_match_vq": makeBuiltin(tsearchProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}, {"query", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				q := tree.MustBeDTSQuery(args[1])
+				return tree.MakeDBool(tree.DBool(q.Matches(tree.MustBeDTSVector(args[0]).TSVector))), nil
+			},
+			Info:       "Returns whether `vector` matches `query`. Equivalent to `vector @@ query`.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"ts_match_qv": makeBuiltin(tsearchProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"query", types.TSQuery}, {"vector", types.TSVector}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				q := tree.MustBeDTSQuery(args[0])
+				return tree.MakeDBool(tree.DBool(q.Matches(tree.MustBeDTSVector(args[1]).TSVector))), nil
+			},
+			Info:       "Returns whether `vector` matches `query`. Equivalent to `query @@ vector`.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"ts_rank": makeBuiltin(tsearchProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}, {"query", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsRank(nil /* weights */, args[0], args[1], 0 /* normalization */)
+			},
+			Info:       tsRankInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"vector", types.TSVector}, {"query", types.TSQuery}, {"normalization", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsRank(nil /* weights */, args[0], args[1], int(tree.MustBeDInt(args[2])))
+			},
+			Info:       tsRankInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"weights", types.MakeArray(types.Float4)}, {"vector", types.TSVector}, {"query", types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsRank(args[0], args[1], args[2], 0 /* normalization */)
+			},
+			Info:       tsRankInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"weights", types.MakeArray(types.Float4)}, {"vector", types.TSVector}, {"query", types.TSQuery},
+				{"normalization", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tsRank(args[0], args[1], args[2], int(tree.MustBeDInt(args[3])))
+			},
+			Info:       tsRankInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+}
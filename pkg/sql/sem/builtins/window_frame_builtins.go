@@ -123,6 +123,11 @@ func (w *slidingWindowFunc) Compute(
 			if err != nil {
 				return nil, err
 			}
+			if args[0] == tree.DNull {
+				// Null value can neither be minimum nor maximum over a window
+				// frame with non-null values.
+				continue
+			}
 			if res == nil {
 				res = args[0]
 			} else {
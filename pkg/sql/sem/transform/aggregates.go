@@ -11,6 +11,8 @@
 package transform
 
 import (
+	"context"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 )
@@ -33,7 +35,7 @@ func (v *IsAggregateVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tre
 			// aggregate function, but it can contain aggregate functions.
 			return true, expr
 		}
-		fd, err := t.Func.Resolve(v.searchPath)
+		fd, err := t.Func.Resolve(context.TODO(), v.searchPath, nil /* resolver */)
 		if err != nil {
 			return false, expr
 		}
@@ -172,6 +172,8 @@ func (node *AlterTable) HoistAddColumnConstraints() {
 					Name:     d.References.ConstraintName,
 					Actions:  d.References.Actions,
 					Match:    d.References.Match,
+
+					Deferrability: d.References.Deferrability,
 				}
 				constraint := &AlterTableAddConstraint{
 					ConstraintDef:      fk,
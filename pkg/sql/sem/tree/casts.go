@@ -180,6 +180,8 @@ var validCasts = []castInfo{
 	{from: types.INetFamily, to: types.StringFamily, volatility: VolatilityImmutable},
 	{from: types.JsonFamily, to: types.StringFamily, volatility: VolatilityImmutable},
 	{from: types.EnumFamily, to: types.StringFamily, volatility: VolatilityImmutable},
+	{from: types.TSQueryFamily, to: types.StringFamily, volatility: VolatilityImmutable},
+	{from: types.TSVectorFamily, to: types.StringFamily, volatility: VolatilityImmutable},
 
 	// Casts to CollatedStringFamily.
 	{from: types.UnknownFamily, to: types.CollatedStringFamily, volatility: VolatilityImmutable},
@@ -207,6 +209,8 @@ var validCasts = []castInfo{
 	{from: types.INetFamily, to: types.CollatedStringFamily, volatility: VolatilityImmutable},
 	{from: types.JsonFamily, to: types.CollatedStringFamily, volatility: VolatilityImmutable},
 	{from: types.EnumFamily, to: types.CollatedStringFamily, volatility: VolatilityImmutable},
+	{from: types.TSQueryFamily, to: types.CollatedStringFamily, volatility: VolatilityImmutable},
+	{from: types.TSVectorFamily, to: types.CollatedStringFamily, volatility: VolatilityImmutable},
 
 	// Casts to BytesFamily.
 	{from: types.UnknownFamily, to: types.BytesFamily, volatility: VolatilityImmutable},
@@ -309,6 +313,18 @@ var validCasts = []castInfo{
 	{from: types.EnumFamily, to: types.EnumFamily, volatility: VolatilityImmutable},
 	{from: types.BytesFamily, to: types.EnumFamily, volatility: VolatilityImmutable},
 
+	// Casts to TSQueryFamily.
+	{from: types.UnknownFamily, to: types.TSQueryFamily, volatility: VolatilityImmutable},
+	{from: types.StringFamily, to: types.TSQueryFamily, volatility: VolatilityImmutable},
+	{from: types.CollatedStringFamily, to: types.TSQueryFamily, volatility: VolatilityImmutable},
+	{from: types.TSQueryFamily, to: types.TSQueryFamily, volatility: VolatilityImmutable},
+
+	// Casts to TSVectorFamily.
+	{from: types.UnknownFamily, to: types.TSVectorFamily, volatility: VolatilityImmutable},
+	{from: types.StringFamily, to: types.TSVectorFamily, volatility: VolatilityImmutable},
+	{from: types.CollatedStringFamily, to: types.TSVectorFamily, volatility: VolatilityImmutable},
+	{from: types.TSVectorFamily, to: types.TSVectorFamily, volatility: VolatilityImmutable},
+
 	// Casts to TupleFamily.
 	{from: types.UnknownFamily, to: types.TupleFamily, volatility: VolatilityImmutable},
 }
@@ -887,6 +903,10 @@ func performCastWithoutPrecisionTruncation(ctx *EvalContext, d Datum, t *types.T
 			s = t.JSON.String()
 		case *DEnum:
 			s = t.LogicalRep
+		case *DTSQuery:
+			s = t.TSQuery.String()
+		case *DTSVector:
+			s = t.TSVector.String()
 		}
 		switch t.Family() {
 		case types.StringFamily:
@@ -972,6 +992,26 @@ func performCastWithoutPrecisionTruncation(ctx *EvalContext, d Datum, t *types.T
 			return NewDBox2D(*bbox), nil
 		}
 
+	case types.TSQueryFamily:
+		switch d := d.(type) {
+		case *DString:
+			return ParseDTSQuery(string(*d))
+		case *DCollatedString:
+			return ParseDTSQuery(d.Contents)
+		case *DTSQuery:
+			return d, nil
+		}
+
+	case types.TSVectorFamily:
+		switch d := d.(type) {
+		case *DString:
+			return ParseDTSVector(string(*d))
+		case *DCollatedString:
+			return ParseDTSVector(d.Contents)
+		case *DTSVector:
+			return d, nil
+		}
+
 	case types.GeographyFamily:
 		switch d := d.(type) {
 		case *DString:
@@ -15,12 +15,31 @@ type CreateChangefeed struct {
 	Targets TargetList
 	SinkURI Expr
 	Options KVOptions
+	// Select, if set, filters and projects the rows of the changefeed. It's
+	// used by the CREATE CHANGEFEED ... AS SELECT form, in which case Targets
+	// is empty.
+	Select *Select
 }
 
 var _ Statement = &CreateChangefeed{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateChangefeed) Format(ctx *FmtCtx) {
+	if node.Select != nil {
+		if node.SinkURI != nil {
+			ctx.WriteString("CREATE CHANGEFEED INTO ")
+			ctx.FormatNode(node.SinkURI)
+		} else {
+			ctx.WriteString("EXPERIMENTAL CHANGEFEED")
+		}
+		if node.Options != nil {
+			ctx.WriteString(" WITH ")
+			ctx.FormatNode(&node.Options)
+		}
+		ctx.WriteString(" AS ")
+		ctx.FormatNode(node.Select)
+		return
+	}
 	if node.SinkURI != nil {
 		ctx.WriteString("CREATE ")
 	} else {
@@ -11,6 +11,10 @@
 package tree
 
 import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
@@ -65,8 +69,14 @@ func ComputeColNameInternal(sp sessiondata.SearchPath, target Expr) (int, string
 		return ComputeColNameInternal(sp, e.Expr)
 
 	case *FuncExpr:
-		fd, err := e.Func.Resolve(sp)
+		fd, err := e.Func.Resolve(context.TODO(), sp, nil /* resolver */)
 		if err != nil {
+			// User-defined functions cannot be resolved here; they are named
+			// after the unqualified name of the function.
+			if n, ok := e.Func.FunctionReference.(*UnresolvedName); ok &&
+				pgerror.GetPGCode(err) == pgcode.UndefinedFunction {
+				return 2, n.Parts[0], nil
+			}
 			return 0, "", err
 		}
 		return 2, fd.Name, nil
@@ -147,6 +157,9 @@ func ComputeColNameInternal(sp sessiondata.SearchPath, target Expr) (int, string
 	case *CoalesceExpr:
 		return 2, "coalesce", nil
 
+	case *GroupingExpr:
+		return 2, "grouping", nil
+
 		// CockroachDB-specific nodes follow.
 	case *IfErrExpr:
 		if e.Else == nil {
@@ -337,6 +337,151 @@ func (node *CreateType) String() string {
 	return AsString(node)
 }
 
+// FuncArg represents an argument in the signature of a function.
+type FuncArg struct {
+	// Name is empty if the argument is unnamed.
+	Name Name
+	Type ResolvableTypeReference
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArg) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.FormatTypeReference(node.Type)
+}
+
+// FuncArgs represents a list of function arguments.
+type FuncArgs []FuncArg
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArgs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// FunctionOption is an option of a CREATE FUNCTION statement.
+type FunctionOption interface {
+	NodeFormatter
+	functionOption()
+}
+
+func (FunctionLanguage) functionOption()          {}
+func (FunctionVolatility) functionOption()        {}
+func (FunctionLeakProof) functionOption()         {}
+func (FunctionNullInputBehavior) functionOption() {}
+func (FunctionBody) functionOption()              {}
+
+// FunctionLanguage is the LANGUAGE option of a function.
+type FunctionLanguage string
+
+// Format implements the NodeFormatter interface.
+func (node FunctionLanguage) Format(ctx *FmtCtx) {
+	ctx.WriteString("LANGUAGE ")
+	ctx.WriteString(string(node))
+}
+
+// FunctionVolatility is the IMMUTABLE, STABLE or VOLATILE option of a
+// function. VolatilityLeakProof is not a valid value, see FunctionLeakProof.
+type FunctionVolatility Volatility
+
+// Format implements the NodeFormatter interface.
+func (node FunctionVolatility) Format(ctx *FmtCtx) {
+	ctx.WriteString(strings.ToUpper(Volatility(node).String()))
+}
+
+// FunctionLeakProof is the [NOT] LEAKPROOF option of a function.
+type FunctionLeakProof bool
+
+// Format implements the NodeFormatter interface.
+func (node FunctionLeakProof) Format(ctx *FmtCtx) {
+	if !node {
+		ctx.WriteString("NOT ")
+	}
+	ctx.WriteString("LEAKPROOF")
+}
+
+// FunctionNullInputBehavior is the CALLED ON NULL INPUT, RETURNS NULL ON NULL
+// INPUT or STRICT option of a function.
+type FunctionNullInputBehavior int
+
+// FunctionNullInputBehavior values.
+const (
+	FunctionCalledOnNullInput FunctionNullInputBehavior = iota
+	FunctionReturnsNullOnNullInput
+	FunctionStrict
+)
+
+// Format implements the NodeFormatter interface.
+func (node FunctionNullInputBehavior) Format(ctx *FmtCtx) {
+	switch node {
+	case FunctionCalledOnNullInput:
+		ctx.WriteString("CALLED ON NULL INPUT")
+	case FunctionReturnsNullOnNullInput:
+		ctx.WriteString("RETURNS NULL ON NULL INPUT")
+	case FunctionStrict:
+		ctx.WriteString("STRICT")
+	}
+}
+
+// FunctionBody is the AS option of a function, which contains its source.
+type FunctionBody string
+
+// Format implements the NodeFormatter interface.
+func (node FunctionBody) Format(ctx *FmtCtx) {
+	ctx.WriteString("AS ")
+	lex.EncodeSQLStringWithFlags(&ctx.Buffer, string(node), ctx.flags.EncodeFlags())
+}
+
+// FunctionOptions represents the list of options of a CREATE FUNCTION
+// statement, in the order in which they were specified.
+type FunctionOptions []FunctionOption
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionOptions) Format(ctx *FmtCtx) {
+	for i, opt := range *node {
+		if i > 0 {
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatNode(opt)
+	}
+}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	FuncName   *UnresolvedObjectName
+	Replace    bool
+	Args       FuncArgs
+	ReturnType ResolvableTypeReference
+	Options    FunctionOptions
+}
+
+var _ Statement = &CreateFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(node.FuncName)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteString(") RETURNS ")
+	ctx.FormatTypeReference(node.ReturnType)
+	if len(node.Options) > 0 {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // TableDef represents a column, index or constraint definition within a CREATE
 // TABLE statement.
 type TableDef interface {
@@ -409,6 +554,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrability  ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -540,6 +686,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrability = t.Deferrability
 		case *ColumnComputedDef:
 			d.Computed.Computed = true
 			d.Computed.Expr = t.Expr
@@ -660,6 +807,10 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		if node.References.Deferrability != ConstraintNotDeferrable {
+			ctx.WriteByte(' ')
+			ctx.WriteString(node.References.Deferrability.String())
+		}
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -768,10 +919,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table         TableName
+	Col           Name // empty-string means use PK
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -859,8 +1011,9 @@ func (*CheckConstraintTableDef) constraintTableDef()      {}
 // TABLE statement.
 type UniqueConstraintTableDef struct {
 	IndexTableDef
-	PrimaryKey   bool
-	WithoutIndex bool
+	PrimaryKey    bool
+	WithoutIndex  bool
+	Deferrability ConstraintDeferrability
 }
 
 // SetName implements the TableDef interface.
@@ -900,6 +1053,10 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionByIndex != nil {
 		ctx.FormatNode(node.PartitionByIndex)
 	}
+	if node.Deferrability != ConstraintNotDeferrable {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.Deferrability.String())
+	}
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -973,14 +1130,37 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability indicates whether the checks of a constraint can be
+// postponed until the end of the transaction, and whether they are postponed
+// by default.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	ConstraintInitiallyImmediate
+	ConstraintInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	ConstraintNotDeferrable:      "NOT DEFERRABLE",
+	ConstraintInitiallyImmediate: "DEFERRABLE INITIALLY IMMEDIATE",
+	ConstraintInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (c ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[c]
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
-	Name     Name
-	Table    TableName
-	FromCols NameList
-	ToCols   NameList
-	Actions  ReferenceActions
-	Match    CompositeKeyMatchMethod
+	Name          Name
+	Table         TableName
+	FromCols      NameList
+	ToCols        NameList
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -1008,6 +1188,11 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+
+	if node.Deferrability != ConstraintNotDeferrable {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.Deferrability.String())
+	}
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1344,6 +1529,7 @@ func (node *CreateTable) FormatBody(ctx *FmtCtx) {
 			ctx.FormatNode(&node.Defs)
 			ctx.WriteByte(')')
 		}
+		node.formatStorageParams(ctx)
 		ctx.WriteString(" AS ")
 		ctx.FormatNode(node.AsSource)
 	} else {
@@ -1356,8 +1542,7 @@ func (node *CreateTable) FormatBody(ctx *FmtCtx) {
 		if node.PartitionByTable != nil {
 			ctx.FormatNode(node.PartitionByTable)
 		}
-		// No storage parameters are implemented, so we never list the storage
-		// parameters in the output format.
+		node.formatStorageParams(ctx)
 		if node.Locality != nil {
 			ctx.WriteString(" ")
 			node.Locality.Format(ctx)
@@ -1365,6 +1550,14 @@ func (node *CreateTable) FormatBody(ctx *FmtCtx) {
 	}
 }
 
+func (node *CreateTable) formatStorageParams(ctx *FmtCtx) {
+	if node.StorageParams != nil {
+		ctx.WriteString(" WITH (")
+		ctx.FormatNode(&node.StorageParams)
+		ctx.WriteString(")")
+	}
+}
+
 // HoistConstraints finds column check and foreign key constraints defined
 // inline with their columns and makes them table-level constraints, stored in
 // n.Defs. For example, the foreign key constraint in
@@ -1417,6 +1610,8 @@ func (node *CreateTable) HoistConstraints() {
 					Name:     col.References.ConstraintName,
 					Actions:  col.References.Actions,
 					Match:    col.References.Match,
+
+					Deferrability: col.References.Deferrability,
 				})
 				col.References.Table = nil
 			}
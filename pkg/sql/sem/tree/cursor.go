@@ -0,0 +1,222 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import "strconv"
+
+// DeclareCursor represents a DECLARE statement.
+type DeclareCursor struct {
+	Name        Name
+	Select      *Select
+	Binary      bool
+	Sensitivity CursorSensitivity
+	Scroll      CursorScrollOption
+	Hold        bool
+}
+
+var _ Statement = &DeclareCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *DeclareCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("DECLARE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ")
+	if node.Binary {
+		ctx.WriteString("BINARY ")
+	}
+	if node.Sensitivity != UnspecifiedSensitivity {
+		ctx.WriteString(node.Sensitivity.String())
+		ctx.WriteString(" ")
+	}
+	if node.Scroll != UnspecifiedScroll {
+		ctx.WriteString(node.Scroll.String())
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("CURSOR ")
+	if node.Hold {
+		ctx.WriteString("WITH HOLD ")
+	}
+	ctx.WriteString("FOR ")
+	ctx.FormatNode(node.Select)
+}
+
+// CursorScrollOption represents the scroll option, if one was given, for a
+// DECLARE statement.
+type CursorScrollOption int8
+
+const (
+	// UnspecifiedScroll represents no SCROLL option having been given. In
+	// Postgres, this is like NO SCROLL, but the returned cursor also supports
+	// some backward fetches if the query plan supports it.
+	UnspecifiedScroll CursorScrollOption = iota
+	// Scroll represents SCROLL.
+	Scroll
+	// NoScroll represents NO SCROLL.
+	NoScroll
+)
+
+func (o CursorScrollOption) String() string {
+	switch o {
+	case Scroll:
+		return "SCROLL"
+	case NoScroll:
+		return "NO SCROLL"
+	}
+	return ""
+}
+
+// CursorSensitivity represents the "sensitivity" of a cursor, which
+// determines whether it reflects changes made by its own transaction
+// after it was declared. CockroachDB cursors are always insensitive.
+type CursorSensitivity int8
+
+const (
+	// UnspecifiedSensitivity indicates that no sensitivity was specified.
+	UnspecifiedSensitivity CursorSensitivity = iota
+	// Insensitive indicates that the cursor is INSENSITIVE.
+	Insensitive
+	// Asensitive indicates that the cursor is ASENSITIVE.
+	Asensitive
+)
+
+func (o CursorSensitivity) String() string {
+	switch o {
+	case Insensitive:
+		return "INSENSITIVE"
+	case Asensitive:
+		return "ASENSITIVE"
+	}
+	return ""
+}
+
+// CursorStmt represents the shared structure between a FETCH and MOVE
+// statement.
+type CursorStmt struct {
+	Name      Name
+	FetchType FetchType
+	// Count is the number of rows to move for FetchNormal and the
+	// position for FetchRelative and FetchAbsolute. It is negative for
+	// backward movement.
+	Count int64
+}
+
+// FetchType represents the type of a FETCH (or MOVE) statement.
+type FetchType int
+
+const (
+	// FetchNormal represents a FETCH statement that doesn't have a special
+	// qualifier. It's used for FORWARD, BACKWARD, NEXT, and PRIOR.
+	FetchNormal FetchType = iota
+	// FetchRelative represents a FETCH RELATIVE statement.
+	FetchRelative
+	// FetchAbsolute represents a FETCH ABSOLUTE statement.
+	FetchAbsolute
+	// FetchFirst represents a FETCH FIRST statement.
+	FetchFirst
+	// FetchLast represents a FETCH LAST statement.
+	FetchLast
+	// FetchAll represents a FETCH ALL statement.
+	FetchAll
+	// FetchBackwardAll represents a FETCH BACKWARD ALL statement.
+	FetchBackwardAll
+)
+
+func (o FetchType) String() string {
+	switch o {
+	case FetchNormal:
+		return ""
+	case FetchRelative:
+		return "RELATIVE"
+	case FetchAbsolute:
+		return "ABSOLUTE"
+	case FetchFirst:
+		return "FIRST"
+	case FetchLast:
+		return "LAST"
+	case FetchAll:
+		return "ALL"
+	case FetchBackwardAll:
+		return "BACKWARD ALL"
+	}
+	return ""
+}
+
+// HasCount returns true if the given fetch type should be printed with an
+// associated count.
+func (o FetchType) HasCount() bool {
+	switch o {
+	case FetchNormal, FetchRelative, FetchAbsolute:
+		return true
+	}
+	return false
+}
+
+// Format implements the NodeFormatter interface.
+func (c *CursorStmt) Format(ctx *FmtCtx) {
+	fetchType := c.FetchType.String()
+	if fetchType != "" {
+		ctx.WriteString(fetchType)
+		ctx.WriteString(" ")
+	}
+	if c.FetchType.HasCount() {
+		if ctx.flags.HasFlags(FmtHideConstants) {
+			ctx.WriteByte('0')
+		} else {
+			ctx.WriteString(strconv.Itoa(int(c.Count)))
+		}
+		ctx.WriteString(" ")
+	}
+	ctx.FormatNode(&c.Name)
+}
+
+// FetchCursor represents a FETCH statement.
+type FetchCursor struct {
+	CursorStmt
+}
+
+var _ Statement = &FetchCursor{}
+
+// Format implements the NodeFormatter interface.
+func (f *FetchCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("FETCH ")
+	f.CursorStmt.Format(ctx)
+}
+
+// MoveCursor represents a MOVE statement.
+type MoveCursor struct {
+	CursorStmt
+}
+
+var _ Statement = &MoveCursor{}
+
+// Format implements the NodeFormatter interface.
+func (m *MoveCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("MOVE ")
+	m.CursorStmt.Format(ctx)
+}
+
+// CloseCursor represents a CLOSE statement.
+type CloseCursor struct {
+	Name Name
+	All  bool
+}
+
+var _ Statement = &CloseCursor{}
+
+// Format implements the NodeFormatter interface.
+func (c *CloseCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("CLOSE ")
+	if c.All {
+		ctx.WriteString("ALL")
+	} else {
+		ctx.FormatNode(&c.Name)
+	}
+}
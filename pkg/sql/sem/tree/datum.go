@@ -41,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timetz"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -3183,7 +3184,8 @@ func AsJSON(d Datum, loc *time.Location) (json.JSON, error) {
 	case *DTimestamp:
 		// This is RFC3339Nano, but without the TZ fields.
 		return json.FromString(t.UTC().Format("2006-01-02T15:04:05.999999999")), nil
-	case *DDate, *DUuid, *DOid, *DInterval, *DBytes, *DIPAddr, *DTime, *DTimeTZ, *DBitArray, *DBox2D:
+	case *DDate, *DUuid, *DOid, *DInterval, *DBytes, *DIPAddr, *DTime, *DTimeTZ, *DBitArray, *DBox2D,
+		*DTSQuery, *DTSVector:
 		return json.FromString(AsStringWithFlags(t, FmtBareStrings)), nil
 	case *DGeometry:
 		return json.FromSpatialObject(t.Geometry.SpatialObject(), geo.DefaultGeoJSONDecimalDigits)
@@ -3274,6 +3276,228 @@ func (d *DJSON) Size() uintptr {
 	return unsafe.Sizeof(*d) + d.JSON.Size()
 }
 
+// DTSQuery is the tsquery Datum.
+type DTSQuery struct {
+	tsearch.TSQuery
+}
+
+// NewDTSQuery is a helper routine to create a DTSQuery initialized from its
+// argument.
+func NewDTSQuery(q tsearch.TSQuery) *DTSQuery {
+	return &DTSQuery{TSQuery: q}
+}
+
+// ParseDTSQuery takes a string of a tsquery and returns a DTSQuery value.
+func ParseDTSQuery(s string) (*DTSQuery, error) {
+	q, err := tsearch.ParseTSQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewDTSQuery(q), nil
+}
+
+// AsDTSQuery attempts to retrieve a *DTSQuery from an Expr, returning a
+// *DTSQuery and a flag signifying whether the assertion was successful. The
+// function should be used instead of direct type assertions wherever a
+// *DTSQuery wrapped by a *DOidWrapper is possible.
+func AsDTSQuery(e Expr) (*DTSQuery, bool) {
+	switch t := e.(type) {
+	case *DTSQuery:
+		return t, true
+	case *DOidWrapper:
+		return AsDTSQuery(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDTSQuery attempts to retrieve a *DTSQuery from an Expr, panicking
+// if the assertion fails.
+func MustBeDTSQuery(e Expr) *DTSQuery {
+	q, ok := AsDTSQuery(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DTSQuery, found %T", e))
+	}
+	return q
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTSQuery) ResolvedType() *types.T {
+	return types.TSQuery
+}
+
+// Compare implements the Datum interface. Queries are ordered by their text
+// form.
+func (d *DTSQuery) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DTSQuery)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return strings.Compare(d.TSQuery.String(), v.TSQuery.String())
+}
+
+// Prev implements the Datum interface.
+func (d *DTSQuery) Prev(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DTSQuery) Next(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DTSQuery) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DTSQuery) IsMin(_ *EvalContext) bool {
+	return false
+}
+
+// Max implements the Datum interface.
+func (d *DTSQuery) Max(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DTSQuery) Min(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTSQuery) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTSQuery) Format(ctx *FmtCtx) {
+	formatTSearchString(ctx, d.TSQuery.String())
+}
+
+// Size implements the Datum interface.
+func (d *DTSQuery) Size() uintptr {
+	return unsafe.Sizeof(*d) + d.TSQuery.Size()
+}
+
+// DTSVector is the tsvector Datum.
+type DTSVector struct {
+	tsearch.TSVector
+}
+
+// NewDTSVector is a helper routine to create a DTSVector initialized from its
+// argument.
+func NewDTSVector(v tsearch.TSVector) *DTSVector {
+	return &DTSVector{TSVector: v}
+}
+
+// ParseDTSVector takes a string of a tsvector and returns a DTSVector value.
+func ParseDTSVector(s string) (*DTSVector, error) {
+	v, err := tsearch.ParseTSVector(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewDTSVector(v), nil
+}
+
+// AsDTSVector attempts to retrieve a *DTSVector from an Expr, returning a
+// *DTSVector and a flag signifying whether the assertion was successful. The
+// function should be used instead of direct type assertions wherever a
+// *DTSVector wrapped by a *DOidWrapper is possible.
+func AsDTSVector(e Expr) (*DTSVector, bool) {
+	switch t := e.(type) {
+	case *DTSVector:
+		return t, true
+	case *DOidWrapper:
+		return AsDTSVector(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDTSVector attempts to retrieve a *DTSVector from an Expr, panicking
+// if the assertion fails.
+func MustBeDTSVector(e Expr) *DTSVector {
+	v, ok := AsDTSVector(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DTSVector, found %T", e))
+	}
+	return v
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTSVector) ResolvedType() *types.T {
+	return types.TSVector
+}
+
+// Compare implements the Datum interface. Vectors are ordered by their text
+// form.
+func (d *DTSVector) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DTSVector)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return strings.Compare(d.TSVector.String(), v.TSVector.String())
+}
+
+// Prev implements the Datum interface.
+func (d *DTSVector) Prev(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DTSVector) Next(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DTSVector) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DTSVector) IsMin(_ *EvalContext) bool {
+	return len(d.TSVector) == 0
+}
+
+// Max implements the Datum interface.
+func (d *DTSVector) Max(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DTSVector) Min(_ *EvalContext) (Datum, bool) {
+	return &DTSVector{}, true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTSVector) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTSVector) Format(ctx *FmtCtx) {
+	formatTSearchString(ctx, d.TSVector.String())
+}
+
+// Size implements the Datum interface.
+func (d *DTSVector) Size() uintptr {
+	return unsafe.Sizeof(*d) + d.TSVector.Size()
+}
+
+// formatTSearchString formats the text form of a tsvector or tsquery, which
+// is quoted as a SQL string unless raw strings are requested.
+func formatTSearchString(ctx *FmtCtx, s string) {
+	if ctx.flags.HasFlags(fmtRawStrings) {
+		ctx.WriteString(s)
+	} else {
+		lex.EncodeSQLStringWithFlags(&ctx.Buffer, s, ctx.flags.EncodeFlags())
+	}
+}
+
 // DTuple is the tuple Datum.
 type DTuple struct {
 	D Datums
@@ -4954,6 +5178,8 @@ var baseDatumTypeSizes = map[types.Family]struct {
 	types.INetFamily:           {unsafe.Sizeof(DIPAddr{}), fixedSize},
 	types.OidFamily:            {unsafe.Sizeof(DInt(0)), fixedSize},
 	types.EnumFamily:           {unsafe.Sizeof(DEnum{}), variableSize},
+	types.TSQueryFamily:        {unsafe.Sizeof(DTSQuery{}), variableSize},
+	types.TSVectorFamily:       {unsafe.Sizeof(DTSVector{}), variableSize},
 
 	// TODO(jordan,justin): This seems suspicious.
 	types.ArrayFamily: {unsafe.Sizeof(DString("")), variableSize},
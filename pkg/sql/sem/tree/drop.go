@@ -210,6 +210,61 @@ func (node *DropType) Format(ctx *FmtCtx) {
 	}
 }
 
+// FuncObj identifies a function by name, optionally along with the types of
+// its arguments.
+type FuncObj struct {
+	FuncName *UnresolvedObjectName
+	// Args is only used if HasArgs is set. The names of the arguments are
+	// ignored.
+	Args    FuncArgs
+	HasArgs bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncObj) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.FuncName)
+	if node.HasArgs {
+		ctx.WriteByte('(')
+		ctx.FormatNode(&node.Args)
+		ctx.WriteByte(')')
+	}
+}
+
+// FuncObjs is a list of FuncObj.
+type FuncObjs []FuncObj
+
+// Format implements the NodeFormatter interface.
+func (node *FuncObjs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// DropFunction represents a DROP FUNCTION command.
+type DropFunction struct {
+	Functions    FuncObjs
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+var _ Statement = &DropFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP FUNCTION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Functions)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
+
 // DropSchema represents a DROP SCHEMA command.
 type DropSchema struct {
 	Names        ObjectNamePrefixList
@@ -2117,6 +2117,8 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeEqFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeEqFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeEqFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeEqFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeEqFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeEqFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeEqFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2173,6 +2175,8 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeLtFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeLtFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeLtFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeLtFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeLtFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeLtFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeLtFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2229,6 +2233,8 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeLeFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeLeFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeLeFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeLeFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeLeFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeLeFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeLeFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2295,6 +2301,8 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeIsFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeIsFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeIsFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeIsFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeIsFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeIsFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeIsFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2353,6 +2361,8 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(types.TimeTZ, VolatilityLeakProof),
 		makeEvalTupleIn(types.Timestamp, VolatilityLeakProof),
 		makeEvalTupleIn(types.TimestampTZ, VolatilityLeakProof),
+		makeEvalTupleIn(types.TSQuery, VolatilityLeakProof),
+		makeEvalTupleIn(types.TSVector, VolatilityLeakProof),
 		makeEvalTupleIn(types.Uuid, VolatilityLeakProof),
 		makeEvalTupleIn(types.VarBit, VolatilityLeakProof),
 	},
@@ -2567,6 +2577,29 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 			},
 		)...,
 	),
+
+	TSMatches: {
+		&CmpOp{
+			LeftType:  types.TSVector,
+			RightType: types.TSQuery,
+			Fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				v := MustBeDTSVector(left)
+				q := MustBeDTSQuery(right)
+				return MakeDBool(DBool(q.Matches(v.TSVector))), nil
+			},
+			Volatility: VolatilityImmutable,
+		},
+		&CmpOp{
+			LeftType:  types.TSQuery,
+			RightType: types.TSVector,
+			Fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				q := MustBeDTSQuery(left)
+				v := MustBeDTSVector(right)
+				return MakeDBool(DBool(q.Matches(v.TSVector))), nil
+			},
+			Volatility: VolatilityImmutable,
+		},
+	},
 })
 
 const experimentalBox2DClusterSettingName = "sql.spatial.experimental_box2d_comparison_operators.enabled"
@@ -3083,6 +3116,18 @@ type EvalPlanner interface {
 		ctx context.Context, nodeID int32, storeID int32, startKey []byte, endKey []byte,
 	) error
 
+	// RequestStatementBundle requests statement diagnostics bundles for the
+	// executions of the statements with the given fingerprint which satisfy
+	// the given conditions. See the comment on the planner implementation.
+	RequestStatementBundle(
+		ctx context.Context,
+		fingerprint string,
+		samplingProbability float64,
+		minExecutionLatency time.Duration,
+		expiresAfter time.Duration,
+		continuous bool,
+	) error
+
 	// MemberOfWithAdminOption is used to collect a list of roles (direct and
 	// indirect) that the member is part of. See the comment on the planner
 	// implementation in authorization.go
@@ -3090,6 +3135,15 @@ type EvalPlanner interface {
 		ctx context.Context,
 		member security.SQLUsername,
 	) (map[security.SQLUsername]bool, error)
+
+	// SerializeSessionState serializes the state of the current session, so
+	// that it can be restored in a session on another SQL pod. See the comment
+	// on the planner implementation in session_state.go.
+	SerializeSessionState() (*DBytes, error)
+
+	// DeserializeSessionState restores the session state serialized by
+	// SerializeSessionState in the current session.
+	DeserializeSessionState(state *DBytes) (*DBool, error)
 }
 
 // EvalSessionAccessor is a limited interface to access session variables.
@@ -4192,6 +4246,11 @@ func (expr *ParenExpr) Eval(ctx *EvalContext) (Datum, error) {
 	return expr.Expr.(TypedExpr).Eval(ctx)
 }
 
+// Eval implements the TypedExpr interface.
+func (expr *GroupingExpr) Eval(ctx *EvalContext) (Datum, error) {
+	return nil, errors.AssertionFailedf("unhandled type %T", expr)
+}
+
 // Eval implements the TypedExpr interface.
 func (expr *RangeCond) Eval(ctx *EvalContext) (Datum, error) {
 	return nil, errors.AssertionFailedf("unhandled type %T", expr)
@@ -4401,6 +4460,16 @@ func (t *DJSON) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DTSQuery) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DTSVector) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t dNull) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
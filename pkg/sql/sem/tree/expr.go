@@ -385,6 +385,7 @@ const (
 	JSONSomeExists
 	JSONAllExists
 	Overlaps
+	TSMatches
 
 	// The following operators will always be used with an associated SubOperator.
 	// If Go had algebraic data types they would be defined in a self-contained
@@ -436,6 +437,7 @@ var comparisonOpName = [...]string{
 	JSONSomeExists:    "?|",
 	JSONAllExists:     "?&",
 	Overlaps:          "&&",
+	TSMatches:         "@@",
 	Any:               "ANY",
 	Some:              "SOME",
 	All:               "ALL",
@@ -824,6 +826,22 @@ func (node *CoalesceExpr) Format(ctx *FmtCtx) {
 	ctx.WriteByte(')')
 }
 
+// GroupingExpr represents a GROUPING(...) expression. Its result is a bit
+// mask indicating which of its arguments are not part of the grouping set of
+// the current output row of an aggregation.
+type GroupingExpr struct {
+	Exprs Exprs
+
+	typeAnnotation
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingExpr) Format(ctx *FmtCtx) {
+	ctx.WriteString("GROUPING(")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DefaultVal represents the DEFAULT expression.
 type DefaultVal struct{}
 
@@ -1773,6 +1791,8 @@ func (node *DGeometry) String() string        { return AsString(node) }
 func (node *DInt) String() string             { return AsString(node) }
 func (node *DInterval) String() string        { return AsString(node) }
 func (node *DJSON) String() string            { return AsString(node) }
+func (node *DTSQuery) String() string         { return AsString(node) }
+func (node *DTSVector) String() string        { return AsString(node) }
 func (node *DUuid) String() string            { return AsString(node) }
 func (node *DIPAddr) String() string          { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
@@ -1786,6 +1806,8 @@ func (node *DOidWrapper) String() string      { return AsString(node) }
 func (node *Exprs) String() string            { return AsString(node) }
 func (node *ArrayFlatten) String() string     { return AsString(node) }
 func (node *FuncExpr) String() string         { return AsString(node) }
+func (node *GroupingExpr) String() string     { return AsString(node) }
+func (node *GroupingSet) String() string      { return AsString(node) }
 func (node *IfExpr) String() string           { return AsString(node) }
 func (node *IfErrExpr) String() string        { return AsString(node) }
 func (node *IndexedVar) String() string       { return AsString(node) }
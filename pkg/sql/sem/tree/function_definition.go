@@ -155,6 +155,27 @@ func NewFunctionDefinition(
 	}
 }
 
+// NewUDFFunctionDefinition allocates a function definition for a
+// user-defined function, which has a single overload.
+func NewUDFFunctionDefinition(
+	name string, props *FunctionProperties, def Overload,
+) *FunctionDefinition {
+	return &FunctionDefinition{
+		Name:               name,
+		Definition:         []overloadImpl{&def},
+		FunctionProperties: *props,
+	}
+}
+
+// UDF returns the definition of the user-defined function implemented by fd,
+// or nil if fd is not a user-defined function.
+func (fd *FunctionDefinition) UDF() *UDFDefinition {
+	if len(fd.Definition) != 1 {
+		return nil
+	}
+	return fd.Definition[0].(*Overload).UDF
+}
+
 // FunDefs holds pre-allocated FunctionDefinition instances
 // for every builtin function. Initialized by builtins.init().
 var FunDefs map[string]*FunctionDefinition
@@ -11,8 +11,11 @@
 package tree
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -41,9 +44,11 @@ func (fn *ResolvableFunctionReference) Format(ctx *FmtCtx) {
 func (fn *ResolvableFunctionReference) String() string { return AsString(fn) }
 
 // Resolve checks if the function name is already resolved and
-// resolves it as necessary.
+// resolves it as necessary. Built-in functions take precedence; if the
+// name does not refer to a built-in function and resolver is non-nil, it
+// is used to look up a user-defined function.
 func (fn *ResolvableFunctionReference) Resolve(
-	searchPath sessiondata.SearchPath,
+	ctx context.Context, searchPath sessiondata.SearchPath, resolver FunctionReferenceResolver,
 ) (*FunctionDefinition, error) {
 	switch t := fn.FunctionReference.(type) {
 	case *FunctionDefinition:
@@ -51,7 +56,20 @@ func (fn *ResolvableFunctionReference) Resolve(
 	case *UnresolvedName:
 		fd, err := t.ResolveFunction(searchPath)
 		if err != nil {
-			return nil, err
+			if resolver == nil || pgerror.GetPGCode(err) != pgcode.UndefinedFunction {
+				return nil, err
+			}
+			udf, udfErr := resolver.ResolveFunction(ctx, t, searchPath)
+			if udfErr != nil {
+				return nil, udfErr
+			}
+			if udf == nil {
+				return nil, err
+			}
+			// The definition of a user-defined function is not cached in the
+			// AST, since the function may be altered or dropped after the
+			// statement is prepared.
+			return udf, nil
 		}
 		fn.FunctionReference = fd
 		return fd, nil
@@ -62,6 +80,72 @@ func (fn *ResolvableFunctionReference) Resolve(
 	}
 }
 
+// FunctionReferenceResolver is the interface that provides the ability to
+// look up user-defined functions. It is consulted for names which do not
+// refer to a built-in function.
+type FunctionReferenceResolver interface {
+	// ResolveFunction returns the definition of the user-defined function
+	// with the given name, or nil if there is no such function.
+	ResolveFunction(
+		ctx context.Context, name *UnresolvedName, searchPath sessiondata.SearchPath,
+	) (*FunctionDefinition, error)
+}
+
+// FunctionName corresponds to the name of a user-defined function, which
+// lives in a schema like tables and types do.
+type FunctionName struct {
+	objName
+}
+
+var _ ObjectName = &FunctionName{}
+
+// Format implements the NodeFormatter interface.
+func (f *FunctionName) Format(ctx *FmtCtx) {
+	f.ObjectNamePrefix.Format(ctx)
+	if f.ExplicitSchema || ctx.alwaysFormatTablePrefix() {
+		ctx.WriteByte('.')
+	}
+	ctx.FormatNode(&f.ObjectName)
+}
+
+// String implements the Stringer interface.
+func (f *FunctionName) String() string {
+	return AsString(f)
+}
+
+// FQString renders the function name in full, not omitting the prefix
+// schema and catalog names. Suitable for logging, etc.
+func (f *FunctionName) FQString() string {
+	ctx := NewFmtCtx(FmtSimple)
+	ctx.FormatNode(&f.CatalogName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.SchemaName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.ObjectName)
+	return ctx.CloseAndGetString()
+}
+
+func (f *FunctionName) objectName() {}
+
+// MakeFunctionNameWithPrefix creates a function name qualified by the given
+// prefix.
+func MakeFunctionNameWithPrefix(prefix ObjectNamePrefix, fn string) FunctionName {
+	return FunctionName{objName{
+		ObjectNamePrefix: prefix,
+		ObjectName:       Name(fn),
+	}}
+}
+
+// MakeQualifiedFunctionName creates a fully qualified function name.
+func MakeQualifiedFunctionName(db, schema, fn string) FunctionName {
+	return MakeFunctionNameWithPrefix(ObjectNamePrefix{
+		ExplicitCatalog: true,
+		ExplicitSchema:  true,
+		CatalogName:     Name(db),
+		SchemaName:      Name(schema),
+	}, fn)
+}
+
 // WrapFunction creates a new ResolvableFunctionReference
 // holding a pre-resolved function. Helper for grammar rules.
 func WrapFunction(n string) ResolvableFunctionReference {
@@ -72,6 +156,18 @@ func WrapFunction(n string) ResolvableFunctionReference {
 	return ResolvableFunctionReference{fd}
 }
 
+// WrapResolvedFunction creates a new ResolvableFunctionReference holding the
+// function with the given name and resolved overload. Unlike WrapFunction, it
+// supports user-defined functions, which are not part of FunDefs.
+func WrapResolvedFunction(
+	n string, props *FunctionProperties, overload *Overload,
+) ResolvableFunctionReference {
+	if overload.UDF != nil {
+		return ResolvableFunctionReference{NewUDFFunctionDefinition(n, props, *overload)}
+	}
+	return WrapFunction(n)
+}
+
 // FunctionReference is the common interface to UnresolvedName and QualifiedFunctionName.
 type FunctionReference interface {
 	fmt.Stringer
@@ -11,6 +11,7 @@
 package tree_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -47,7 +48,7 @@ func TestResolveFunction(t *testing.T) {
 			t.Fatalf("%s does not parse to a tree.FuncExpr", tc.in)
 		}
 		q := f.Func
-		_, err = q.Resolve(searchPath)
+		_, err = q.Resolve(context.Background(), searchPath, nil /* resolver */)
 		if tc.err != "" {
 			if !testutils.IsError(err, tc.err) {
 				t.Fatalf("%s: expected %s, but found %v", tc.in, tc.err, err)
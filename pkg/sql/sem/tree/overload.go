@@ -85,6 +85,25 @@ type Overload struct {
 	// volatility against Postgres's volatility at test time.
 	// This should be used with caution.
 	IgnoreVolatilityCheck bool
+
+	// UDF is set if the overload is the implementation of a user-defined
+	// function.
+	UDF *UDFDefinition
+}
+
+// UDFDefinition contains the information about a user-defined function which
+// is needed to plan calls to it.
+type UDFDefinition struct {
+	// ID and Version identify the version of the function descriptor the
+	// overload was built from.
+	ID      uint32
+	Version uint64
+
+	// InlineExpr is set if the function is immutable and its body consists of
+	// a single SELECT of a single expression without a FROM clause. The
+	// arguments of the function are referenced in the expression as
+	// placeholders, i.e. $1 refers to the first argument.
+	InlineExpr Expr
 }
 
 // params implements the overloadImpl interface.
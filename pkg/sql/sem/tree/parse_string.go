@@ -83,6 +83,10 @@ func ParseAndRequireString(
 		d, dependsOnContext, err = ParseDTimestamp(ctx, s, TimeFamilyPrecisionToRoundDuration(t.Precision()))
 	case types.TimestampTZFamily:
 		d, dependsOnContext, err = ParseDTimestampTZ(ctx, s, TimeFamilyPrecisionToRoundDuration(t.Precision()))
+	case types.TSQueryFamily:
+		d, err = ParseDTSQuery(s)
+	case types.TSVectorFamily:
+		d, err = ParseDTSVector(s)
 	case types.UuidFamily:
 		d, err = ParseDUuidFromString(s)
 	case types.EnumFamily:
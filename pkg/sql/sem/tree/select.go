@@ -570,6 +570,47 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetType identifies the kind of a GroupingSet.
+type GroupingSetType int
+
+const (
+	// RollupGroupingSet is a ROLLUP (...) grouping element.
+	RollupGroupingSet GroupingSetType = iota
+	// CubeGroupingSet is a CUBE (...) grouping element.
+	CubeGroupingSet
+	// ExplicitGroupingSets is a GROUPING SETS (...) grouping element.
+	ExplicitGroupingSets
+)
+
+var groupingSetTypeName = [...]string{
+	RollupGroupingSet:    "ROLLUP",
+	CubeGroupingSet:      "CUBE",
+	ExplicitGroupingSets: "GROUPING SETS",
+}
+
+func (t GroupingSetType) String() string {
+	return groupingSetTypeName[t]
+}
+
+// GroupingSet represents a ROLLUP, CUBE or GROUPING SETS element of a GROUP BY
+// clause. For ROLLUP and CUBE, each expression is a grouping expression or a
+// parenthesized list of grouping expressions that is treated as a unit. For
+// GROUPING SETS, each expression is itself a grouping element: an expression,
+// a parenthesized list of expressions (possibly empty), or a nested
+// GroupingSet.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.Type.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
@@ -484,6 +484,17 @@ func (node *ShowCreate) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.Name)
 }
 
+// ShowCreateFunction represents a SHOW CREATE FUNCTION statement.
+type ShowCreateFunction struct {
+	Name *UnresolvedObjectName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowCreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW CREATE FUNCTION ")
+	ctx.FormatNode(node.Name)
+}
+
 // ShowSyntax represents a SHOW SYNTAX statement.
 // This the most lightweight thing that can be done on a statement
 // server-side: just report the statement that was entered without
@@ -658,11 +669,26 @@ const (
 	// ScheduledBackupExecutor is an executor responsible for
 	// the execution of the scheduled backups.
 	ScheduledBackupExecutor
+
+	// ScheduledRowLevelTTLExecutor is an executor responsible for the
+	// deletion of expired rows of tables with row-level TTL.
+	ScheduledRowLevelTTLExecutor
+
+	// ScheduledExportExecutor is an executor responsible for
+	// the execution of the scheduled exports.
+	ScheduledExportExecutor
+
+	// ScheduledChangefeedExecutor is an executor responsible for
+	// the execution of the scheduled changefeeds.
+	ScheduledChangefeedExecutor
 )
 
 var scheduleExecutorInternalNames = map[ScheduledJobExecutorType]string{
-	InvalidExecutor:         "unknown-executor",
-	ScheduledBackupExecutor: "scheduled-backup-executor",
+	InvalidExecutor:              "unknown-executor",
+	ScheduledBackupExecutor:      "scheduled-backup-executor",
+	ScheduledRowLevelTTLExecutor: "scheduled-row-level-ttl-executor",
+	ScheduledExportExecutor:      "scheduled-export-executor",
+	ScheduledChangefeedExecutor:  "scheduled-changefeed-executor",
 }
 
 // InternalName returns an internal executor name.
@@ -676,6 +702,12 @@ func (t ScheduledJobExecutorType) UserName() string {
 	switch t {
 	case ScheduledBackupExecutor:
 		return "BACKUP"
+	case ScheduledRowLevelTTLExecutor:
+		return "ROW LEVEL TTL"
+	case ScheduledExportExecutor:
+		return "EXPORT"
+	case ScheduledChangefeedExecutor:
+		return "CHANGEFEED"
 	}
 	return "unsupported-executor"
 }
@@ -83,6 +83,12 @@ func SampleDatum(t *types.T) Datum {
 	case types.Box2DFamily:
 		b := geo.NewCartesianBoundingBox().AddPoint(1, 2).AddPoint(3, 4)
 		return NewDBox2D(*b)
+	case types.TSQueryFamily:
+		q, _ := ParseDTSQuery(`'a' & 'b'`)
+		return q
+	case types.TSVectorFamily:
+		v, _ := ParseDTSVector(`'a':1 'b':2`)
+		return v
 	case types.GeographyFamily:
 		return NewDGeography(geo.MustParseGeographyFromEWKB([]byte("\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0\x3f\x00\x00\x00\x00\x00\x00\xf0\x3f")))
 	case types.GeometryFamily:
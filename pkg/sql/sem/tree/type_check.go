@@ -49,6 +49,10 @@ type SemaContext struct {
 	// TypeResolver manages resolving type names into *types.T's.
 	TypeResolver TypeReferenceResolver
 
+	// FunctionResolver manages resolving names of user-defined functions into
+	// *FunctionDefinitions. If nil, only built-in functions can be resolved.
+	FunctionResolver FunctionReferenceResolver
+
 	// AsOfTimestamp denotes the explicit AS OF SYSTEM TIME timestamp for the
 	// query, if any. If the query is not an AS OF SYSTEM TIME query,
 	// AsOfTimestamp is nil.
@@ -207,6 +211,14 @@ func (sc *SemaContext) GetTypeResolver() TypeReferenceResolver {
 	return sc.TypeResolver
 }
 
+// GetFunctionResolver returns the FunctionReferenceResolver.
+func (sc *SemaContext) GetFunctionResolver() FunctionReferenceResolver {
+	if sc == nil {
+		return nil
+	}
+	return sc.FunctionResolver
+}
+
 func placeholderTypeAmbiguityError(idx PlaceholderIdx) error {
 	return pgerror.WithCandidateCode(
 		&placeholderTypeAmbiguityErr{idx},
@@ -701,6 +713,39 @@ func (expr *CoalesceExpr) TypeCheck(
 	return expr, nil
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingExpr) TypeCheck(
+	ctx context.Context, semaCtx *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	if semaCtx != nil {
+		if semaCtx.Properties.Derived.inFuncExpr &&
+			semaCtx.Properties.required.rejectFlags&RejectNestedAggregates != 0 {
+			return nil, pgerror.Newf(pgcode.Grouping,
+				"aggregate function calls cannot contain grouping operations")
+		}
+		if semaCtx.Properties.required.rejectFlags&RejectAggregates != 0 {
+			return nil, pgerror.Newf(pgcode.Grouping,
+				"grouping operations are not allowed in %s", semaCtx.Properties.required.context)
+		}
+	}
+	for i, subExpr := range expr.Exprs {
+		typedExpr, err := subExpr.TypeCheck(ctx, semaCtx, types.Any)
+		if err != nil {
+			return nil, err
+		}
+		expr.Exprs[i] = typedExpr
+	}
+	expr.typ = types.Int
+	return expr, nil
+}
+
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSet) TypeCheck(
+	ctx context.Context, semaCtx *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	return nil, pgerror.Newf(pgcode.Syntax, "%s is only allowed in GROUP BY", expr.Type)
+}
+
 // TypeCheck implements the Expr interface.
 func (expr *ComparisonExpr) TypeCheck(
 	ctx context.Context, semaCtx *SemaContext, desired *types.T,
@@ -902,7 +947,7 @@ func (expr *FuncExpr) TypeCheck(
 	if semaCtx != nil {
 		searchPath = semaCtx.SearchPath
 	}
-	def, err := expr.Func.Resolve(searchPath)
+	def, err := expr.Func.Resolve(ctx, searchPath, semaCtx.GetFunctionResolver())
 	if err != nil {
 		return nil, err
 	}
@@ -1659,6 +1704,18 @@ func (d *DJSON) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedE
 	return d, nil
 }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTSQuery) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
+	return d, nil
+}
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTSVector) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
+	return d, nil
+}
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DTuple) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
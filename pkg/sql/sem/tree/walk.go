@@ -185,6 +185,44 @@ func (expr *CoalesceExpr) Walk(v Visitor) Expr {
 	return ret
 }
 
+// copyNode makes a copy of this Expr without recursing in any child Exprs.
+func (expr *GroupingExpr) copyNode() *GroupingExpr {
+	exprCopy := *expr
+	return &exprCopy
+}
+
+// Walk implements the Expr interface.
+func (expr *GroupingExpr) Walk(v Visitor) Expr {
+	ret := expr
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		if ret == expr {
+			ret = expr.copyNode()
+		}
+		ret.Exprs = exprs
+	}
+	return ret
+}
+
+// copyNode makes a copy of this Expr without recursing in any child Exprs.
+func (expr *GroupingSet) copyNode() *GroupingSet {
+	exprCopy := *expr
+	return &exprCopy
+}
+
+// Walk implements the Expr interface.
+func (expr *GroupingSet) Walk(v Visitor) Expr {
+	ret := expr
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		if ret == expr {
+			ret = expr.copyNode()
+		}
+		ret.Exprs = exprs
+	}
+	return ret
+}
+
 // Walk implements the Expr interface.
 func (expr *ComparisonExpr) Walk(v Visitor) Expr {
 	left, changedL := WalkExpr(v, expr.Left)
@@ -671,6 +709,12 @@ func (expr *DGeometry) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DJSON) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DTSQuery) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DTSVector) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *DUuid) Walk(_ Visitor) Expr { return expr }
 
@@ -1489,6 +1533,18 @@ func SimpleVisit(expr Expr, preFn SimpleVisitFn) (Expr, error) {
 	return newExpr, nil
 }
 
+// SimpleStmtVisit is a variant of SimpleVisit which walks all the expressions
+// of a statement that are reachable through walkStmt. See the comment on
+// walkStmt for the parts of a statement which are not visited.
+func SimpleStmtVisit(stmt Statement, preFn SimpleVisitFn) (Statement, error) {
+	v := simpleVisitor{fn: preFn}
+	newStmt, _ := walkStmt(&v, stmt)
+	if v.err != nil {
+		return nil, v.err
+	}
+	return newStmt, nil
+}
+
 type debugVisitor struct {
 	buf   bytes.Buffer
 	level int
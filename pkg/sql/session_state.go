@@ -0,0 +1,179 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// SerializeSessionState is part of the tree.EvalPlanner interface. It
+// serializes the parts of the session state which need to be restored for a
+// connection to be continued on another SQL pod: the session variables which
+// differ from their defaults, including the current database, and the named
+// prepared statements.
+//
+// Only the state of a session which is not inside a transaction can be
+// serialized. Portals don't need to be serialized, since they don't outlive
+// the transactions in which they are created. The LISTEN registrations and the
+// temporary objects of a session can't be restored on another SQL pod, so the
+// sessions which have any can't be serialized.
+func (p *planner) SerializeSessionState() (*tree.DBytes, error) {
+	if !p.EvalContext().TxnImplicit {
+		return nil, pgerror.New(pgcode.InvalidTransactionState,
+			"cannot serialize a session which is inside a transaction")
+	}
+	if sn := p.extendedEvalCtx.Notifications; sn != nil && sn.listening() {
+		return nil, pgerror.New(pgcode.ObjectNotInPrerequisiteState,
+			"cannot serialize a session which is listening on channels")
+	}
+	if p.SessionData().SearchPath.GetTemporarySchemaName() != "" {
+		return nil, pgerror.New(pgcode.ObjectNotInPrerequisiteState,
+			"cannot serialize a session which has temporary objects")
+	}
+
+	state := sessiondatapb.MigratableSession{User: p.User().Normalized()}
+	for _, name := range varNames {
+		v := varGen[name]
+		// Only the variables which can be changed with SET need to be restored.
+		if v.Get == nil || (v.Set == nil && v.SetWithPlanner == nil) {
+			continue
+		}
+		// The other SQL pod is expected to receive the same connection
+		// parameters, so the variables which have their session defaults don't
+		// need to be restored.
+		value := v.Get(&p.extendedEvalCtx)
+		if ok, defVal := getSessionVarDefaultString(name, v, p.sessionDataMutator); ok && value == defVal {
+			continue
+		}
+		state.SessionVars = append(state.SessionVars, sessiondatapb.MigratableSession_SessionVar{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	stmts := p.preparedStatements.List()
+	names := make([]string, 0, len(stmts))
+	for name := range stmts {
+		// The unnamed prepared statement is only used by the extended protocol
+		// until the next statement is prepared, so it isn't worth restoring.
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stmt := stmts[name]
+		oids := make([]uint32, len(stmt.Types))
+		for i, typ := range stmt.Types {
+			if typ != nil {
+				oids[i] = uint32(typ.Oid())
+			}
+		}
+		state.PreparedStatements = append(state.PreparedStatements,
+			sessiondatapb.MigratableSession_PreparedStatement{
+				Name:                name,
+				SQL:                 stmt.SQL,
+				PlaceholderTypeOIDs: oids,
+			})
+	}
+
+	b, err := protoutil.Marshal(&state)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDBytes(tree.DBytes(b)), nil
+}
+
+// DeserializeSessionState is part of the tree.EvalPlanner interface. It
+// restores in the current session the session state serialized by
+// SerializeSessionState on another SQL pod. The session must belong to the
+// same user as the serialized session, and must not be inside a transaction.
+func (p *planner) DeserializeSessionState(state *tree.DBytes) (*tree.DBool, error) {
+	evalCtx := p.EvalContext()
+	if !evalCtx.TxnImplicit {
+		return nil, pgerror.New(pgcode.InvalidTransactionState,
+			"cannot deserialize a session which is inside a transaction")
+	}
+
+	var m sessiondatapb.MigratableSession
+	if err := protoutil.Unmarshal([]byte(*state), &m); err != nil {
+		return nil, pgerror.Wrap(err, pgcode.InvalidParameterValue, "invalid session state")
+	}
+	if user := p.User().Normalized(); m.User != user {
+		return nil, pgerror.Newf(pgcode.InsufficientPrivilege,
+			"session of user %s cannot be restored by user %s", m.User, user)
+	}
+
+	ctx := evalCtx.Context
+	for _, v := range m.SessionVars {
+		if err := p.SetSessionVar(ctx, v.Name, v.Value); err != nil {
+			return nil, errors.Wrapf(err, "restoring session variable %s", v.Name)
+		}
+	}
+
+	// The prepared statements are restored after the session variables, so
+	// that their names are resolved in the restored database and search path.
+	nakedIntType := parser.NakedIntTypeFromDefaultIntSize(p.SessionData().DefaultIntSize)
+	for _, ps := range m.PreparedStatements {
+		var stmt parser.Statement
+		var prs parser.Parser
+		stmts, err := prs.ParseWithInt(ps.SQL, nakedIntType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "restoring prepared statement %s", ps.Name)
+		}
+		switch len(stmts) {
+		case 0:
+			// The empty statement can be prepared, and doesn't need an AST.
+		case 1:
+			stmt = stmts[0]
+		default:
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"prepared statement %s contains %d statements", ps.Name, len(stmts))
+		}
+
+		placeholderHints := make(tree.PlaceholderTypes, len(ps.PlaceholderTypeOIDs))
+		for i, typOID := range ps.PlaceholderTypeOIDs {
+			if placeholderHints[i], err = p.resolveSessionStateType(oid.Oid(typOID)); err != nil {
+				return nil, errors.Wrapf(err, "restoring prepared statement %s", ps.Name)
+			}
+		}
+		if err := p.preparedStatements.Add(ctx, ps.Name, stmt, placeholderHints); err != nil {
+			return nil, errors.Wrapf(err, "restoring prepared statement %s", ps.Name)
+		}
+	}
+	return tree.DBoolTrue, nil
+}
+
+// resolveSessionStateType returns the type with the given OID, or nil if the
+// OID is zero, which stands for a placeholder of unknown type.
+func (p *planner) resolveSessionStateType(typOID oid.Oid) (*types.T, error) {
+	if typOID == 0 {
+		return nil, nil
+	}
+	if types.IsOIDUserDefinedType(typOID) {
+		return p.ResolveTypeByOID(p.EvalContext().Context, typOID)
+	}
+	typ, ok := types.OidToType[typOID]
+	if !ok {
+		return nil, pgerror.Newf(pgcode.UndefinedObject, "type with OID %d does not exist", typOID)
+	}
+	return typ, nil
+}
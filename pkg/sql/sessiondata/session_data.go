@@ -161,6 +161,11 @@ type LocalOnlySessionData struct {
 	// DefaultTxnReadOnly indicates the default read-only status of newly
 	// created transactions.
 	DefaultTxnReadOnly bool
+	// DefaultTxnIsolationLevel indicates the default isolation level of newly
+	// created transactions.
+	// NOTE: we'd prefer to use tree.IsolationLevel here, but doing so would
+	// introduce a package dependency cycle.
+	DefaultTxnIsolationLevel int
 	// DefaultTxnUseFollowerReads indicates whether transactions should be
 	// created by default using an AS OF SYSTEM TIME clause far enough in the
 	// past to facilitate reads against followers. If true, transactions will
@@ -236,6 +241,11 @@ type LocalOnlySessionData struct {
 
 	// NewSchemaChangerMode indicates whether to use the new schema changer.
 	NewSchemaChangerMode NewSchemaChangerMode
+
+	// TraceParent is the W3C trace context of a span of the client, in the
+	// format of the traceparent HTTP header. If set, the spans of the
+	// transactions of the session are children of that span.
+	TraceParent string
 	///////////////////////////////////////////////////////////////////////////
 	// WARNING: consider whether a session parameter you're adding needs to  //
 	// be propagated to the remote nodes. If so, that parameter should live  //
@@ -20,8 +20,9 @@ import (
 func (p *planner) SetSessionCharacteristics(n *tree.SetSessionCharacteristics) (planNode, error) {
 	// Note: We also support SET DEFAULT_TRANSACTION_ISOLATION TO ' .... '.
 	switch n.Modes.Isolation {
-	case tree.SerializableIsolation, tree.UnspecifiedIsolation:
-		// Do nothing. All transactions execute with serializable isolation.
+	case tree.UnspecifiedIsolation:
+	case tree.SerializableIsolation, tree.ReadCommittedIsolation:
+		p.sessionDataMutator.SetDefaultTransactionIsolationLevel(n.Modes.Isolation)
 	default:
 		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
 			"unsupported default isolation level: %s", n.Modes.Isolation)
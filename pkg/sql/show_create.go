@@ -190,6 +190,8 @@ func ShowCreateTable(
 		return "", err
 	}
 
+	showCreateRowLevelTTL(desc, f)
+
 	if err := showCreateLocality(desc, f); err != nil {
 		return "", err
 	}
@@ -216,6 +216,10 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	if fk.Deferrability != descpb.ConstraintDeferrability_NotDeferrable {
+		buf.WriteByte(' ')
+		buf.WriteString(descpb.ConstraintDeferrabilityType[fk.Deferrability].String())
+	}
 	if fk.Validity != descpb.ConstraintValidity_Validated {
 		buf.WriteString(" NOT VALID")
 	}
@@ -277,6 +281,37 @@ func showCreateLocality(desc catalog.TableDescriptor, f *tree.FmtCtx) error {
 	return nil
 }
 
+// showCreateRowLevelTTL writes the storage parameters of the row-level TTL
+// config of the table, if any.
+func showCreateRowLevelTTL(desc catalog.TableDescriptor, f *tree.FmtCtx) {
+	ttl := desc.GetRowLevelTTL()
+	if ttl == nil {
+		return
+	}
+	params := tree.StorageParams{
+		{Key: "ttl_expire_after", Value: tree.NewStrVal(ttl.ExpireAfter)},
+	}
+	if ttl.DeletionCron != "" {
+		params = append(params, tree.StorageParam{Key: "ttl_job_cron", Value: tree.NewStrVal(ttl.DeletionCron)})
+	}
+	for _, p := range []struct {
+		key tree.Name
+		val int64
+	}{
+		{"ttl_select_batch_size", ttl.SelectBatchSize},
+		{"ttl_delete_batch_size", ttl.DeleteBatchSize},
+		{"ttl_range_concurrency", ttl.RangeConcurrency},
+		{"ttl_delete_rate_limit", ttl.DeleteRateLimit},
+	} {
+		if p.val != 0 {
+			params = append(params, tree.StorageParam{Key: p.key, Value: tree.NewDInt(tree.DInt(p.val))})
+		}
+	}
+	f.WriteString(" WITH (")
+	f.FormatNode(&params)
+	f.WriteString(")")
+}
+
 // showCreateInterleave returns an INTERLEAVE IN PARENT clause for the specified
 // index, if applicable.
 //
@@ -479,6 +514,10 @@ func showConstraintClause(
 		}
 		f.WriteString(strings.Join(colNames, ", "))
 		f.WriteString(")")
+		if c.Deferrability != descpb.ConstraintDeferrability_NotDeferrable {
+			f.WriteString(" ")
+			f.WriteString(descpb.ConstraintDeferrabilityType[c.Deferrability].String())
+		}
 		if c.IsPartial() {
 			f.WriteString(" WHERE ")
 			pred, err := schemaexpr.FormatExprForDisplay(ctx, desc, c.Predicate, semaCtx, tree.FmtParsable)
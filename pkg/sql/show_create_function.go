@@ -0,0 +1,91 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+var showCreateFunctionColumns = colinfo.ResultColumns{
+	{Name: "function_name", Typ: types.String},
+	{Name: "create_statement", Typ: types.String},
+}
+
+// ShowCreateFunction returns the CREATE FUNCTION statement which recreates
+// the given user-defined function.
+// Privileges: Any privilege on the function.
+func (p *planner) ShowCreateFunction(
+	ctx context.Context, n *tree.ShowCreateFunction,
+) (planNode, error) {
+	name, desc, err := p.lookupFunction(ctx, n.Name, p.CurrentSearchPath())
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, sqlerrors.NewUndefinedFunctionError(n.Name)
+	}
+	if err := p.CheckAnyPrivilege(ctx, desc); err != nil {
+		return nil, err
+	}
+	stmt := makeCreateFunctionStmt(name, desc.FuncDesc())
+
+	return &delayedNode{
+		name:    "SHOW CREATE FUNCTION " + name.String(),
+		columns: showCreateFunctionColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			v := p.newContainerValuesNode(showCreateFunctionColumns, 0)
+			if _, err := v.rows.AddRow(ctx, tree.Datums{
+				tree.NewDString(name.String()),
+				tree.NewDString(tree.AsString(stmt)),
+			}); err != nil {
+				v.rows.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+// makeCreateFunctionStmt builds the CREATE FUNCTION statement corresponding to
+// the given function descriptor.
+func makeCreateFunctionStmt(
+	name *tree.FunctionName, fd *descpb.FunctionDescriptor,
+) *tree.CreateFunction {
+	args := make(tree.FuncArgs, len(fd.Args))
+	for i := range fd.Args {
+		args[i] = tree.FuncArg{Name: tree.Name(fd.Args[i].Name), Type: fd.Args[i].Type}
+	}
+	nullInput := tree.FunctionCalledOnNullInput
+	if fd.NullInputBehavior == descpb.FunctionDescriptor_RETURNS_NULL_ON_NULL_INPUT {
+		nullInput = tree.FunctionReturnsNullOnNullInput
+	}
+	opts := tree.FunctionOptions{
+		tree.FunctionLanguage(fd.Lang.String()),
+		tree.FunctionVolatility(funcdesc.VolatilityToTree(fd.Volatility, false /* leakProof */)),
+	}
+	if fd.LeakProof {
+		opts = append(opts, tree.FunctionLeakProof(true))
+	}
+	opts = append(opts, nullInput, tree.FunctionBody(fd.Body))
+	return &tree.CreateFunction{
+		FuncName:   name.ToUnresolvedObjectName(),
+		Args:       args,
+		ReturnType: fd.ReturnType,
+		Options:    opts,
+	}
+}
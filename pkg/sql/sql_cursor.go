@@ -0,0 +1,530 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
+)
+
+// sqlCursor is a cursor declared with DECLARE. The cursor's query is executed
+// by an internal executor in lockstep mode, which means that its rows are
+// produced one at a time as they are fetched, and that the query does not
+// use the transaction in between FETCH and MOVE statements.
+type sqlCursor struct {
+	name tree.Name
+	// statement is the text of the DECLARE statement.
+	statement string
+	created   time.Time
+
+	rows *rowsIterator
+	// mon accounts for the memory used by the cursor's query.
+	mon *mon.BytesMonitor
+	// readSeqNum is the read sequence number of the transaction at the time
+	// the cursor was declared. The cursor's query reads at this sequence number
+	// so that it does not observe the writes performed by the transaction
+	// after the cursor was declared.
+	readSeqNum enginepb.TxnSeq
+
+	// pos is the position of the cursor, as defined by Postgres: 0 before the
+	// first row, n when positioned on the n-th row, and the number of rows
+	// plus one after the last row.
+	pos int64
+	// numRows is the number of rows read from the query so far.
+	numRows int64
+	// cur is the row the cursor is positioned on, if any.
+	cur tree.Datums
+	// eof is set once the query has returned all its rows.
+	eof bool
+}
+
+// next advances the cursor by one row. It returns false if there are no more
+// rows, in which case the cursor is positioned after the last row.
+func (c *sqlCursor) next(ctx context.Context) (bool, error) {
+	if !c.eof {
+		ok, err := c.rows.Next(ctx)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			c.numRows++
+			c.pos = c.numRows
+			c.cur = c.rows.Cur()
+			return true, nil
+		}
+		c.eof = true
+	}
+	c.pos = c.numRows + 1
+	c.cur = nil
+	return false, nil
+}
+
+func (c *sqlCursor) close(ctx context.Context) {
+	if err := c.rows.Close(); err != nil {
+		log.VEventf(ctx, 2, "error closing cursor %s: %v", c.name, err)
+	}
+	c.mon.Stop(ctx)
+}
+
+// sqlCursors is the collection of the cursors declared by a transaction. All
+// the cursors are closed when the transaction finishes.
+type sqlCursors struct {
+	// mon is the session monitor, under which the memory used by the cursors'
+	// queries is accounted.
+	mon     *mon.BytesMonitor
+	cursors map[tree.Name]*sqlCursor
+}
+
+func (c *sqlCursors) get(name tree.Name) (*sqlCursor, error) {
+	cursor, ok := c.cursors[name]
+	if !ok {
+		return nil, pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", name)
+	}
+	return cursor, nil
+}
+
+func (c *sqlCursors) add(cursor *sqlCursor) {
+	if c.cursors == nil {
+		c.cursors = make(map[tree.Name]*sqlCursor)
+	}
+	c.cursors[cursor.name] = cursor
+}
+
+// closeCursor closes the named cursor.
+func (c *sqlCursors) closeCursor(ctx context.Context, name tree.Name) error {
+	cursor, err := c.get(name)
+	if err != nil {
+		return err
+	}
+	delete(c.cursors, name)
+	cursor.close(ctx)
+	return nil
+}
+
+// closeAll closes all the cursors. It is called when the transaction commits,
+// rolls back or restarts, before the KV transaction is finalized.
+func (c *sqlCursors) closeAll(ctx context.Context) {
+	for name, cursor := range c.cursors {
+		delete(c.cursors, name)
+		cursor.close(ctx)
+	}
+}
+
+// list returns the open cursors, ordered by name.
+func (c *sqlCursors) list() []*sqlCursor {
+	res := make([]*sqlCursor, 0, len(c.cursors))
+	for _, cursor := range c.cursors {
+		res = append(res, cursor)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].name < res[j].name })
+	return res
+}
+
+type declareCursorNode struct {
+	n *tree.DeclareCursor
+}
+
+// DeclareCursor declares a cursor.
+// See https://www.postgresql.org/docs/current/sql-declare.html for details.
+func (p *planner) DeclareCursor(ctx context.Context, n *tree.DeclareCursor) (planNode, error) {
+	if n.Hold {
+		return nil, unimplemented.NewWithIssue(41412, "DECLARE CURSOR WITH HOLD")
+	}
+	if n.Binary {
+		return nil, unimplemented.NewWithIssue(41412, "DECLARE BINARY CURSOR")
+	}
+	if n.Scroll == tree.Scroll {
+		return nil, unimplemented.NewWithIssue(41412, "DECLARE SCROLL CURSOR")
+	}
+	return &declareCursorNode{n: n}, nil
+}
+
+func (n *declareCursorNode) startExec(params runParams) error {
+	p := params.p
+	if p.extendedEvalCtx.TxnImplicit {
+		return pgerror.New(pgcode.NoActiveSQLTransaction,
+			"DECLARE CURSOR can only be used in transaction blocks")
+	}
+	cursors := p.extendedEvalCtx.SQLCursors
+	if _, ok := cursors.cursors[n.n.Name]; ok {
+		return pgerror.Newf(pgcode.DuplicateCursor, "cursor %q already exists", n.n.Name)
+	}
+	if err := p.checkCursorQuery(params.ctx, n.n.Select); err != nil {
+		return err
+	}
+	query, err := p.formatCursorQuery(n.n.Select)
+	if err != nil {
+		return err
+	}
+
+	// The cursor outlives the DECLARE statement, so its query gets its own
+	// context.
+	ctx := logtags.WithTags(context.Background(), logtags.FromContext(params.ctx))
+	cursorMon := mon.NewMonitorInheritWithLimit(
+		fmt.Sprintf("cursor %s", n.n.Name), 0 /* limit */, cursors.mon,
+	)
+	cursorMon.Start(ctx, cursors.mon, mon.BoundAccount{})
+
+	// The cursor's query runs with a copy of the session data. It is never
+	// distributed so that no remote flows use the transaction while the cursor
+	// is idle.
+	sd := *p.SessionData()
+	sd.DistSQLMode = sessiondata.DistSQLOff
+	ie := InternalExecutor{
+		s:          p.execCfg.InternalExecutor.s,
+		mon:        cursorMon,
+		memMetrics: *p.extendedEvalCtx.MemMetrics,
+		lockstep:   true,
+	}
+	ie.SetSessionData(&sd)
+	readSeqNum := p.txn.GetLeafTxnInputState(params.ctx).ReadSeqNum
+	rows, err := ie.execInternal(
+		ctx, "sql-cursor", p.txn, ie.maybeRootSessionDataOverride("sql-cursor"), query,
+	)
+	if err != nil {
+		cursorMon.Stop(ctx)
+		return err
+	}
+	// Errors from the cursor's query are returned to the client as is.
+	rows.errCallback = nil
+	cursors.add(&sqlCursor{
+		name:       n.n.Name,
+		statement:  p.stmt.SQL,
+		created:    timeutil.Now(),
+		rows:       rows,
+		mon:        cursorMon,
+		readSeqNum: readSeqNum,
+	})
+	return nil
+}
+
+func (*declareCursorNode) Next(runParams) (bool, error) { return false, nil }
+func (*declareCursorNode) Values() tree.Datums          { return tree.Datums{} }
+func (*declareCursorNode) Close(context.Context)        {}
+
+// checkCursorQuery builds the query of a cursor to validate it, and checks
+// that it does not contain data-modifying statements, which are not allowed
+// in cursors.
+func (p *planner) checkCursorQuery(ctx context.Context, query *tree.Select) error {
+	var o xform.Optimizer
+	o.Init(p.EvalContext(), &p.optPlanningCtx.catalog)
+	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &p.optPlanningCtx.catalog, o.Factory(), query)
+	if err := bld.Build(); err != nil {
+		return err
+	}
+	if o.Memo().RootExpr().(memo.RelExpr).Relational().CanMutate {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"DECLARE CURSOR must not contain data-modifying statements in WITH")
+	}
+	return nil
+}
+
+// formatCursorQuery returns the text of the query of a cursor, with the
+// placeholders replaced by their values.
+func (p *planner) formatCursorQuery(query *tree.Select) (string, error) {
+	var err error
+	fmtCtx := tree.NewFmtCtx(tree.FmtParsable)
+	fmtCtx.SetPlaceholderFormat(func(ctx *tree.FmtCtx, placeholder *tree.Placeholder) {
+		d, evalErr := placeholder.Eval(p.EvalContext())
+		if evalErr != nil && err == nil {
+			err = evalErr
+		}
+		ctx.FormatNode(d)
+	})
+	fmtCtx.FormatNode(query)
+	return fmtCtx.CloseAndGetString(), err
+}
+
+// cursorMovement iterates over the rows a FETCH or MOVE statement moves a
+// cursor over. Cursors can only move forward.
+type cursorMovement struct {
+	cursor *sqlCursor
+	// refetch is set if the current row is returned again.
+	refetch bool
+	// skip is the number of rows skipped before the first returned row.
+	skip int64
+	// count is the number of rows to return, or -1 for all the remaining
+	// rows.
+	count int64
+	// last is set if the cursor moves to the last row, which is the only row
+	// returned.
+	last bool
+}
+
+func errCursorBackward() error {
+	return errors.WithHint(
+		pgerror.New(pgcode.ObjectNotInPrerequisiteState, "cursor can only scan forward"),
+		"Declare it with SCROLL option to enable backward scan.",
+	)
+}
+
+func makeCursorMovement(cursor *sqlCursor, s *tree.CursorStmt) (cursorMovement, error) {
+	m := cursorMovement{cursor: cursor}
+	// relative is the number of rows to move for FETCH RELATIVE and friends.
+	var relative int64
+	switch s.FetchType {
+	case tree.FetchNormal, tree.FetchRelative:
+		relative = s.Count
+		if s.FetchType == tree.FetchNormal && s.Count > 0 {
+			m.count = s.Count
+			return m, nil
+		}
+	case tree.FetchAll:
+		m.count = -1
+		return m, nil
+	case tree.FetchLast:
+		m.last = true
+		return m, nil
+	case tree.FetchFirst, tree.FetchAbsolute:
+		target := int64(1)
+		if s.FetchType == tree.FetchAbsolute {
+			target = s.Count
+		}
+		if target < 0 {
+			return m, errCursorBackward()
+		}
+		if target == 0 {
+			if cursor.pos != 0 {
+				return m, errCursorBackward()
+			}
+			return m, nil
+		}
+		relative = target - cursor.pos
+	case tree.FetchBackwardAll:
+		return m, errCursorBackward()
+	default:
+		return m, errors.AssertionFailedf("unknown fetch type %s", s.FetchType)
+	}
+	switch {
+	case relative < 0:
+		return m, errCursorBackward()
+	case relative == 0:
+		m.refetch = true
+	default:
+		m.skip = relative - 1
+		m.count = 1
+	}
+	return m, nil
+}
+
+// next moves the cursor and returns the next row, or nil if there are no
+// more rows.
+func (m *cursorMovement) next(ctx context.Context) (tree.Datums, error) {
+	c := m.cursor
+	if m.refetch {
+		m.refetch = false
+		return c.cur, nil
+	}
+	if m.last {
+		m.last = false
+		// If the cursor is already positioned on the last row, it stays there.
+		last := c.cur
+		for {
+			ok, err := c.next(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			last = c.cur
+		}
+		if last != nil {
+			c.pos, c.cur = c.numRows, last
+		}
+		return last, nil
+	}
+	for ; m.skip > 0; m.skip-- {
+		if ok, err := c.next(ctx); !ok || err != nil {
+			return nil, err
+		}
+	}
+	if m.count == 0 {
+		return nil, nil
+	}
+	if m.count > 0 {
+		m.count--
+	}
+	if ok, err := c.next(ctx); !ok || err != nil {
+		m.count = 0
+		return nil, err
+	}
+	return c.cur, nil
+}
+
+// setCursorReadSeqNum makes the transaction read at the cursor's sequence
+// number. It returns the sequence number to restore once the cursor's query
+// is no longer advanced by the statement.
+func setCursorReadSeqNum(params runParams, cursor *sqlCursor) (enginepb.TxnSeq, error) {
+	txn := params.p.txn
+	prev := txn.GetLeafTxnInputState(params.ctx).ReadSeqNum
+	if err := txn.SetReadSeqNum(cursor.readSeqNum); err != nil {
+		return 0, err
+	}
+	return prev, nil
+}
+
+type fetchNode struct {
+	optColumnsSlot
+
+	n        *tree.FetchCursor
+	cursor   *sqlCursor
+	movement cursorMovement
+	row      tree.Datums
+
+	// txn is set once the transaction reads at the cursor's sequence number,
+	// which is restored to prevReadSeqNum on Close.
+	txn            *kv.Txn
+	prevReadSeqNum enginepb.TxnSeq
+}
+
+// FetchCursor fetches rows from a cursor.
+// See https://www.postgresql.org/docs/current/sql-fetch.html for details.
+func (p *planner) FetchCursor(ctx context.Context, n *tree.FetchCursor) (planNode, error) {
+	cursor, err := p.lookupCursor(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchNode{n: n, cursor: cursor}, nil
+}
+
+func (p *planner) lookupCursor(name tree.Name) (*sqlCursor, error) {
+	if p.extendedEvalCtx.SQLCursors == nil {
+		return nil, pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", name)
+	}
+	return p.extendedEvalCtx.SQLCursors.get(name)
+}
+
+func (n *fetchNode) startExec(params runParams) error {
+	var err error
+	if n.movement, err = makeCursorMovement(n.cursor, &n.n.CursorStmt); err != nil {
+		return err
+	}
+	if n.prevReadSeqNum, err = setCursorReadSeqNum(params, n.cursor); err != nil {
+		return err
+	}
+	n.txn = params.p.txn
+	return nil
+}
+
+func (n *fetchNode) Next(params runParams) (bool, error) {
+	var err error
+	n.row, err = n.movement.next(params.ctx)
+	return n.row != nil, err
+}
+
+func (n *fetchNode) Values() tree.Datums { return n.row }
+
+func (n *fetchNode) Close(ctx context.Context) {
+	if n.txn != nil {
+		if err := n.txn.SetReadSeqNum(n.prevReadSeqNum); err != nil {
+			log.Warningf(ctx, "failed to restore read sequence number: %v", err)
+		}
+		n.txn = nil
+	}
+}
+
+type moveNode struct {
+	n       *tree.MoveCursor
+	cursor  *sqlCursor
+	numRows int
+}
+
+// MoveCursor repositions a cursor without returning rows.
+// See https://www.postgresql.org/docs/current/sql-move.html for details.
+func (p *planner) MoveCursor(ctx context.Context, n *tree.MoveCursor) (planNode, error) {
+	cursor, err := p.lookupCursor(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &moveNode{n: n, cursor: cursor}, nil
+}
+
+// FastPathResults implements the planNodeFastPath interface.
+func (n *moveNode) FastPathResults() (int, bool) {
+	return n.numRows, true
+}
+
+func (n *moveNode) startExec(params runParams) (retErr error) {
+	movement, err := makeCursorMovement(n.cursor, &n.n.CursorStmt)
+	if err != nil {
+		return err
+	}
+	prev, err := setCursorReadSeqNum(params, n.cursor)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := params.p.txn.SetReadSeqNum(prev); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	for {
+		row, err := movement.next(params.ctx)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			return nil
+		}
+		n.numRows++
+	}
+}
+
+func (*moveNode) Next(runParams) (bool, error) { return false, nil }
+func (*moveNode) Values() tree.Datums          { return tree.Datums{} }
+func (*moveNode) Close(context.Context)        {}
+
+type closeCursorNode struct {
+	n *tree.CloseCursor
+}
+
+// CloseCursor closes a cursor.
+// See https://www.postgresql.org/docs/current/sql-close.html for details.
+func (p *planner) CloseCursor(ctx context.Context, n *tree.CloseCursor) (planNode, error) {
+	return &closeCursorNode{n: n}, nil
+}
+
+func (n *closeCursorNode) startExec(params runParams) error {
+	cursors := params.extendedEvalCtx.SQLCursors
+	if n.n.All {
+		if cursors != nil {
+			cursors.closeAll(params.ctx)
+		}
+		return nil
+	}
+	if cursors == nil {
+		return pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", n.n.Name)
+	}
+	return cursors.closeCursor(params.ctx, n.n.Name)
+}
+
+func (*closeCursorNode) Next(runParams) (bool, error) { return false, nil }
+func (*closeCursorNode) Values() tree.Datums          { return tree.Datums{} }
+func (*closeCursorNode) Close(context.Context)        {}
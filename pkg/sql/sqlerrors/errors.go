@@ -116,6 +116,12 @@ func NewUndefinedTypeError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", tree.ErrString(name))
 }
 
+// NewUndefinedFunctionError creates an error that represents a missing
+// function.
+func NewUndefinedFunctionError(name tree.NodeFormatter) error {
+	return pgerror.Newf(pgcode.UndefinedFunction, "unknown function: %s()", tree.ErrString(name))
+}
+
 // NewUndefinedRelationError creates an error that represents a missing database table or view.
 func NewUndefinedRelationError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedTable,
@@ -152,6 +158,8 @@ func MakeObjectAlreadyExistsError(collidingObject *descpb.Descriptor, name strin
 	case *descpb.Descriptor_Schema:
 		// TODO(ajwerner): Add a case for an existing schema object.
 		return errors.AssertionFailedf("schema exists with name %v", name)
+	case *descpb.Descriptor_Function:
+		return NewFunctionAlreadyExistsError(name)
 	default:
 		return errors.AssertionFailedf("unknown type %T exists with name %v", collidingObject.Union, name)
 	}
@@ -167,6 +175,11 @@ func NewTypeAlreadyExistsError(name string) error {
 	return pgerror.Newf(pgcode.DuplicateObject, "type %q already exists", name)
 }
 
+// NewFunctionAlreadyExistsError creates an error for a preexisting function.
+func NewFunctionAlreadyExistsError(name string) error {
+	return pgerror.Newf(pgcode.DuplicateFunction, "function %q already exists", name)
+}
+
 // IsRelationAlreadyExistsError checks whether this is an error for a preexisting relation.
 func IsRelationAlreadyExistsError(err error) bool {
 	return errHasCode(err, pgcode.DuplicateRelation)
@@ -34,6 +34,8 @@ const (
 	OnTable = "on_table"
 	// OnType is used when a GRANT/REVOKE is happening on a type.
 	OnType = "on_type"
+	// OnFunction is used when a GRANT/REVOKE is happening on a function.
+	OnFunction = "on_function"
 
 	iamRoles = "iam.roles"
 )
@@ -15,6 +15,15 @@ import "context"
 // InsertRequestInternal exposes the form of insert which returns the request ID
 // as an int64 to tests in this package.
 func (r *Registry) InsertRequestInternal(ctx context.Context, fprint string) (int64, error) {
-	id, err := r.insertRequestInternal(ctx, fprint)
+	id, err := r.insertRequestInternal(ctx, fprint, RequestOptions{})
+	return int64(id), err
+}
+
+// InsertConditionalRequestInternal is like InsertRequestInternal, for
+// conditional requests.
+func (r *Registry) InsertConditionalRequestInternal(
+	ctx context.Context, fprint string, opts RequestOptions,
+) (int64, error) {
+	id, err := r.insertRequestInternal(ctx, fprint, opts)
 	return int64(id), err
 }
@@ -13,8 +13,10 @@ package stmtdiagnostics
 import (
 	"context"
 	"encoding/binary"
+	"math/rand"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -26,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -50,6 +53,14 @@ var bundleChunkSize = settings.RegisterByteSizeSetting(
 	},
 )
 
+var maxBundlesPerRequest = settings.RegisterIntSetting(
+	"sql.stmt_diagnostics.max_bundles_per_request",
+	"maximum number of statement diagnostics bundles retained for a continuous request; "+
+		"the oldest bundles are deleted when more are collected",
+	10,
+	settings.PositiveInt,
+)
+
 // Registry maintains a view on the statement fingerprints
 // on which data is to be collected (i.e. system.statement_diagnostics_requests)
 // and provides utilities for checking a query against this list and satisfying
@@ -60,9 +71,9 @@ type Registry struct {
 		// internally; it'd deadlock.
 		syncutil.Mutex
 		// requests waiting for the right query to come along.
-		requestFingerprints map[RequestID]string
-		// ids of requests that this node is in the process of servicing.
-		ongoing map[RequestID]struct{}
+		requests map[RequestID]Request
+		// requests that this node is in the process of servicing.
+		ongoing map[RequestID]Request
 
 		// epoch is observed before reading system.statement_diagnostics_requests, and then
 		// checked again before loading the tables contents. If the value changed in
@@ -170,19 +181,81 @@ type RequestID int
 // corresponding to the id column in statement_diagnostics.
 type CollectedInstanceID int
 
+// Request describes a diagnostics request, as stored in
+// statement_diagnostics_requests.
+type Request struct {
+	fingerprint         string
+	samplingProbability float64
+	minExecutionLatency time.Duration
+	expiresAt           time.Time
+	continuous          bool
+}
+
+// MinExecutionLatency returns the minimum execution latency of the statements
+// for which diagnostics are collected. Diagnostics are collected for all the
+// traced executions if it is zero.
+func (req Request) MinExecutionLatency() time.Duration {
+	return req.minExecutionLatency
+}
+
+func (req Request) isExpired(now time.Time) bool {
+	return !req.expiresAt.IsZero() && !req.expiresAt.After(now)
+}
+
+// RequestOptions are the conditions of a diagnostics request. The zero value
+// requests diagnostics for the next execution of the statement.
+type RequestOptions struct {
+	// SamplingProbability, if non-zero, is the probability with which each
+	// execution of the statement is traced. Otherwise, every execution is
+	// traced until diagnostics are collected.
+	SamplingProbability float64
+	// MinExecutionLatency, if non-zero, is the execution latency above which
+	// the diagnostics of a traced execution are collected. Faster executions
+	// are discarded.
+	MinExecutionLatency time.Duration
+	// ExpiresAfter, if non-zero, is the duration after which the request
+	// expires, even if no diagnostics were collected.
+	ExpiresAfter time.Duration
+	// Continuous is set if diagnostics are collected for all the executions
+	// which satisfy the conditions until the request expires, rather than just
+	// the first one. Only the latest sql.stmt_diagnostics.max_bundles_per_request
+	// bundles are retained.
+	Continuous bool
+}
+
+func (opts RequestOptions) validate() error {
+	if opts.SamplingProbability < 0 || opts.SamplingProbability > 1 {
+		return errors.Newf("sampling probability must be between 0 and 1, got %f", opts.SamplingProbability)
+	}
+	if opts.MinExecutionLatency < 0 {
+		return errors.Newf("minimum execution latency must not be negative, got %s", opts.MinExecutionLatency)
+	}
+	if opts.ExpiresAfter < 0 {
+		return errors.Newf("expiration must not be negative, got %s", opts.ExpiresAfter)
+	}
+	if opts.Continuous && opts.ExpiresAfter == 0 {
+		return errors.New("continuous requests must expire")
+	}
+	return nil
+}
+
+// isConditional returns whether the request uses any of the conditions
+// introduced with the ConditionalStatementDiagnostics version.
+func (opts RequestOptions) isConditional() bool {
+	return opts != RequestOptions{}
+}
+
 // addRequestInternalLocked adds a request to r.mu.requests. If the request is
 // already present, the call is a noop.
-func (r *Registry) addRequestInternalLocked(
-	ctx context.Context, id RequestID, queryFingerprint string,
-) {
+func (r *Registry) addRequestInternalLocked(ctx context.Context, id RequestID, req Request) {
 	if r.findRequestLocked(id) {
 		// Request already exists.
 		return
 	}
-	if r.mu.requestFingerprints == nil {
-		r.mu.requestFingerprints = make(map[RequestID]string)
+	if r.mu.requests == nil {
+		r.mu.requests = make(map[RequestID]Request)
 	}
-	r.mu.requestFingerprints[id] = queryFingerprint
+	r.mu.requests[id] = req
 }
 
 func (r *Registry) findRequest(requestID RequestID) bool {
@@ -192,7 +265,7 @@ func (r *Registry) findRequest(requestID RequestID) bool {
 }
 
 func (r *Registry) findRequestLocked(requestID RequestID) bool {
-	_, ok := r.mu.requestFingerprints[requestID]
+	_, ok := r.mu.requests[requestID]
 	if ok {
 		return true
 	}
@@ -202,25 +275,61 @@ func (r *Registry) findRequestLocked(requestID RequestID) bool {
 
 // InsertRequest is part of the StmtDiagnosticsRequester interface.
 func (r *Registry) InsertRequest(ctx context.Context, fprint string) error {
-	_, err := r.insertRequestInternal(ctx, fprint)
+	_, err := r.insertRequestInternal(ctx, fprint, RequestOptions{})
+	return err
+}
+
+// InsertConditionalRequest adds an entry to
+// system.statement_diagnostics_requests for tracing the executions of the
+// statements with the given fingerprint which satisfy the given conditions.
+func (r *Registry) InsertConditionalRequest(
+	ctx context.Context, fprint string, opts RequestOptions,
+) error {
+	_, err := r.insertRequestInternal(ctx, fprint, opts)
 	return err
 }
 
-func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (RequestID, error) {
+func (r *Registry) insertRequestInternal(
+	ctx context.Context, fprint string, opts RequestOptions,
+) (RequestID, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	conditionalActive := r.st.Version.IsActive(ctx, clusterversion.ConditionalStatementDiagnostics)
+	if opts.isConditional() && !conditionalActive {
+		return 0, errors.New("conditional statement diagnostics requests are not supported " +
+			"until the cluster version is finalized")
+	}
+
 	g, err := r.gossip.OptionalErr(48274)
 	if err != nil {
 		return 0, err
 	}
 
+	now := timeutil.Now()
+	req := Request{
+		fingerprint:         fprint,
+		samplingProbability: opts.SamplingProbability,
+		minExecutionLatency: opts.MinExecutionLatency,
+		continuous:          opts.Continuous,
+	}
+	if opts.ExpiresAfter != 0 {
+		req.expiresAt = now.Add(opts.ExpiresAfter)
+	}
+
 	var reqID RequestID
 	err = r.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		// Check if there's already a pending request for this fingerprint.
+		checkPendingQuery := "SELECT count(1) FROM system.statement_diagnostics_requests " +
+			"WHERE completed = false AND statement_fingerprint = $1"
+		if conditionalActive {
+			checkPendingQuery += " AND (expires_at IS NULL OR expires_at > now())"
+		}
 		row, err := r.ie.QueryRowEx(ctx, "stmt-diag-check-pending", txn,
 			sessiondata.InternalExecutorOverride{
 				User: security.RootUserName(),
 			},
-			"SELECT count(1) FROM system.statement_diagnostics_requests "+
-				"WHERE completed = false AND statement_fingerprint = $1",
+			checkPendingQuery,
 			fprint)
 		if err != nil {
 			return err
@@ -233,13 +342,35 @@ func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (Re
 			return errors.New("a pending request for the requested fingerprint already exists")
 		}
 
+		insertQuery := "INSERT INTO system.statement_diagnostics_requests (statement_fingerprint, requested_at) " +
+			"VALUES ($1, $2) RETURNING id"
+		qargs := []interface{}{fprint, now}
+		if opts.isConditional() {
+			insertQuery = "INSERT INTO system.statement_diagnostics_requests " +
+				"(statement_fingerprint, requested_at, min_execution_latency, sampling_probability, " +
+				"expires_at, continuous) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+			minExecutionLatency, samplingProbability, expiresAt := tree.DNull, tree.DNull, tree.DNull
+			if req.minExecutionLatency != 0 {
+				minExecutionLatency = tree.NewDInterval(
+					duration.MakeDuration(req.minExecutionLatency.Nanoseconds(), 0, 0),
+					types.DefaultIntervalTypeMetadata,
+				)
+			}
+			if req.samplingProbability != 0 {
+				samplingProbability = tree.NewDFloat(tree.DFloat(req.samplingProbability))
+			}
+			if !req.expiresAt.IsZero() {
+				if expiresAt, err = tree.MakeDTimestampTZ(req.expiresAt, time.Microsecond); err != nil {
+					return err
+				}
+			}
+			qargs = append(qargs, minExecutionLatency, samplingProbability, expiresAt, tree.MakeDBool(tree.DBool(req.continuous)))
+		}
 		row, err = r.ie.QueryRowEx(ctx, "stmt-diag-insert-request", txn,
 			sessiondata.InternalExecutorOverride{
 				User: security.RootUserName(),
 			},
-			"INSERT INTO system.statement_diagnostics_requests (statement_fingerprint, requested_at) "+
-				"VALUES ($1, $2) RETURNING id",
-			fprint, timeutil.Now())
+			insertQuery, qargs...)
 		if err != nil {
 			return err
 		}
@@ -259,7 +390,7 @@ func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (Re
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.mu.epoch++
-	r.addRequestInternalLocked(ctx, reqID, fprint)
+	r.addRequestInternalLocked(ctx, reqID, req)
 
 	// Notify all the other nodes that they have to poll.
 	buf := make([]byte, 8)
@@ -271,49 +402,79 @@ func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (Re
 	return reqID, nil
 }
 
+// removeOngoing removes the request from r.mu.ongoing. Unless the request was
+// completed in the meantime, it is put back in r.mu.requests so that another
+// execution of the statement can satisfy it.
 func (r *Registry) removeOngoing(requestID RequestID) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	// Remove the request from r.mu.ongoing.
+	req, ok := r.mu.ongoing[requestID]
+	if !ok {
+		return
+	}
 	delete(r.mu.ongoing, requestID)
+	if !req.isExpired(timeutil.Now()) {
+		r.addRequestInternalLocked(context.Background(), requestID, req)
+	}
+}
+
+// markCompleted removes the request from the registry once it has been
+// satisfied, unless it is continuous.
+func (r *Registry) markCompleted(requestID RequestID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req, ok := r.mu.ongoing[requestID]; ok && !req.continuous {
+		delete(r.mu.ongoing, requestID)
+	}
 }
 
 // ShouldCollectDiagnostics checks whether any data should be collected for the
 // given query, which is the case if the registry has a request for this
-// statement's fingerprint; in this case ShouldCollectDiagnostics will not
-// return true again on this note for the same diagnostics request.
+// statement's fingerprint and the execution is sampled; in this case
+// ShouldCollectDiagnostics will not return true again on this node for the same
+// diagnostics request until finishFn is called.
 //
 // If shouldCollect returns true, finishFn must always be called once the data
-// was collected and inserted (even if failures were encountered).
+// was collected and inserted (even if failures were encountered), or once it
+// was discarded because the execution was faster than the minimum execution
+// latency of the request.
 func (r *Registry) ShouldCollectDiagnostics(
 	ctx context.Context, fingerprint string,
-) (shouldCollect bool, reqID RequestID, finishFn func()) {
+) (shouldCollect bool, reqID RequestID, req Request, finishFn func()) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	// Return quickly if we have no requests to trace.
-	if len(r.mu.requestFingerprints) == 0 {
-		return false, 0, nil
+	if len(r.mu.requests) == 0 {
+		return false, 0, Request{}, nil
 	}
 
-	for id, f := range r.mu.requestFingerprints {
-		if f == fingerprint {
-			reqID = id
+	for id, f := range r.mu.requests {
+		if f.fingerprint == fingerprint {
+			reqID, req = id, f
 			break
 		}
 	}
 	if reqID == 0 {
-		return false, 0, nil
+		return false, 0, Request{}, nil
+	}
+
+	if req.isExpired(timeutil.Now()) {
+		delete(r.mu.requests, reqID)
+		return false, 0, Request{}, nil
+	}
+	if req.samplingProbability != 0 && rand.Float64() >= req.samplingProbability {
+		return false, 0, Request{}, nil
 	}
 
 	// Remove the request.
-	delete(r.mu.requestFingerprints, reqID)
+	delete(r.mu.requests, reqID)
 	if r.mu.ongoing == nil {
-		r.mu.ongoing = make(map[RequestID]struct{})
+		r.mu.ongoing = make(map[RequestID]Request)
 	}
 
-	r.mu.ongoing[reqID] = struct{}{}
-	return true, reqID, func() {
+	r.mu.ongoing[reqID] = req
+	return true, reqID, req, func() {
 		r.removeOngoing(reqID)
 	}
 }
@@ -323,11 +484,13 @@ func (r *Registry) ShouldCollectDiagnostics(
 // traceJSON is either DNull (when collectionErr should not be nil) or a *DJSON.
 //
 // If requestID is not zero, it also marks the request as completed in
-// system.statement_diagnostics_requests. If requestID is zero, a new entry is
-// inserted.
+// system.statement_diagnostics_requests, unless the request is continuous. If
+// requestID is zero, a new entry is inserted.
 //
 // collectionErr should be any error generated during the collection or
 // generation of the bundle/trace.
+//
+// execLatency is the execution latency of the statement.
 func (r *Registry) InsertStatementDiagnostics(
 	ctx context.Context,
 	requestID RequestID,
@@ -336,7 +499,19 @@ func (r *Registry) InsertStatementDiagnostics(
 	traceJSON tree.Datum,
 	bundle []byte,
 	collectionErr error,
+	execLatency time.Duration,
 ) (CollectedInstanceID, error) {
+	if requestID != 0 {
+		defer r.markCompleted(requestID)
+	}
+	var continuous bool
+	r.mu.Lock()
+	if req, ok := r.mu.ongoing[requestID]; ok {
+		continuous = req.continuous
+	}
+	r.mu.Unlock()
+	conditionalActive := r.st.Version.IsActive(ctx, clusterversion.ConditionalStatementDiagnostics)
+
 	var diagID CollectedInstanceID
 	err := r.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		if requestID != 0 {
@@ -397,13 +572,25 @@ func (r *Registry) InsertStatementDiagnostics(
 		collectionTime := timeutil.Now()
 
 		// Insert the trace into system.statement_diagnostics.
+		insertQuery := "INSERT INTO system.statement_diagnostics " +
+			"(statement_fingerprint, statement, collected_at, trace, bundle_chunks, error) " +
+			"VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+		qargs := []interface{}{stmtFingerprint, stmt, collectionTime, traceJSON, bundleChunksVal, errorVal}
+		if conditionalActive {
+			insertQuery = "INSERT INTO system.statement_diagnostics " +
+				"(statement_fingerprint, statement, collected_at, trace, bundle_chunks, error, " +
+				"request_id, execution_latency) " +
+				"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+			requestIDVal := tree.DNull
+			if requestID != 0 {
+				requestIDVal = tree.NewDInt(tree.DInt(requestID))
+			}
+			qargs = append(qargs, requestIDVal, execLatency)
+		}
 		row, err := r.ie.QueryRowEx(
 			ctx, "stmt-diag-insert", txn,
 			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
-			"INSERT INTO system.statement_diagnostics "+
-				"(statement_fingerprint, statement, collected_at, trace, bundle_chunks, error) "+
-				"VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-			stmtFingerprint, stmt, collectionTime, traceJSON, bundleChunksVal, errorVal,
+			insertQuery, qargs...,
 		)
 		if err != nil {
 			return err
@@ -413,7 +600,19 @@ func (r *Registry) InsertStatementDiagnostics(
 		}
 		diagID = CollectedInstanceID(*row[0].(*tree.DInt))
 
-		if requestID != 0 {
+		if requestID != 0 && continuous {
+			// Continuous requests stay pending, and point to their latest
+			// diagnostics.
+			_, err := r.ie.ExecEx(ctx, "stmt-diag-update-latest", txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				"UPDATE system.statement_diagnostics_requests "+
+					"SET statement_diagnostics_id = $1 WHERE id = $2",
+				diagID, requestID)
+			if err != nil {
+				return err
+			}
+			return r.deleteOldDiagnostics(ctx, txn, requestID)
+		} else if requestID != 0 {
 			// Mark the request from system.statement_diagnostics_request as completed.
 			_, err := r.ie.ExecEx(ctx, "stmt-diag-mark-completed", txn,
 				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
@@ -445,22 +644,78 @@ func (r *Registry) InsertStatementDiagnostics(
 	return diagID, nil
 }
 
+// deleteOldDiagnostics deletes the oldest diagnostics collected for a
+// continuous request, along with their bundles, so that only the latest
+// sql.stmt_diagnostics.max_bundles_per_request are retained.
+func (r *Registry) deleteOldDiagnostics(
+	ctx context.Context, txn *kv.Txn, requestID RequestID,
+) error {
+	it, err := r.ie.QueryIteratorEx(ctx, "stmt-diag-find-old", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"SELECT id, bundle_chunks FROM system.statement_diagnostics WHERE request_id = $1 "+
+			"ORDER BY collected_at DESC, id DESC OFFSET $2",
+		requestID, maxBundlesPerRequest.Get(&r.st.SV))
+	if err != nil {
+		return err
+	}
+	diagIDs := tree.NewDArray(types.Int)
+	chunkIDs := tree.NewDArray(types.Int)
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		if err := diagIDs.Append(row[0]); err != nil {
+			return err
+		}
+		if chunks, ok := row[1].(*tree.DArray); ok {
+			for _, chunkID := range chunks.Array {
+				if err := chunkIDs.Append(chunkID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if diagIDs.Len() == 0 {
+		return nil
+	}
+	if _, err := r.ie.ExecEx(ctx, "stmt-bundle-chunks-delete", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"DELETE FROM system.statement_bundle_chunks WHERE id = ANY ($1)", chunkIDs,
+	); err != nil {
+		return err
+	}
+	_, err = r.ie.ExecEx(ctx, "stmt-diag-delete", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"DELETE FROM system.statement_diagnostics WHERE id = ANY ($1)", diagIDs,
+	)
+	return err
+}
+
 // pollRequests reads the pending rows from system.statement_diagnostics_requests and
 // updates r.mu.requests accordingly.
 func (r *Registry) pollRequests(ctx context.Context) error {
 	var rows []tree.Datums
+	conditionalActive := r.st.Version.IsActive(ctx, clusterversion.ConditionalStatementDiagnostics)
 	// Loop until we run the query without straddling an epoch increment.
 	for {
 		r.mu.Lock()
 		epoch := r.mu.epoch
 		r.mu.Unlock()
 
+		pollQuery := "SELECT id, statement_fingerprint FROM system.statement_diagnostics_requests " +
+			"WHERE completed = false"
+		if conditionalActive {
+			pollQuery = "SELECT id, statement_fingerprint, min_execution_latency, sampling_probability, " +
+				"expires_at, continuous FROM system.statement_diagnostics_requests " +
+				"WHERE completed = false AND (expires_at IS NULL OR expires_at > now())"
+		}
 		it, err := r.ie.QueryIteratorEx(ctx, "stmt-diag-poll", nil, /* txn */
 			sessiondata.InternalExecutorOverride{
 				User: security.RootUserName(),
 			},
-			"SELECT id, statement_fingerprint FROM system.statement_diagnostics_requests "+
-				"WHERE completed = false")
+			pollQuery)
 		if err != nil {
 			return err
 		}
@@ -488,16 +743,30 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 	var ids util.FastIntSet
 	for _, row := range rows {
 		id := RequestID(*row[0].(*tree.DInt))
-		fprint := string(*row[1].(*tree.DString))
+		req := Request{fingerprint: string(*row[1].(*tree.DString))}
+		if conditionalActive {
+			if minExecutionLatency, ok := row[2].(*tree.DInterval); ok {
+				req.minExecutionLatency = time.Duration(minExecutionLatency.Nanos())
+			}
+			if samplingProbability, ok := row[3].(*tree.DFloat); ok {
+				req.samplingProbability = float64(*samplingProbability)
+			}
+			if expiresAt, ok := row[4].(*tree.DTimestampTZ); ok {
+				req.expiresAt = expiresAt.Time
+			}
+			if continuous, ok := row[5].(*tree.DBool); ok {
+				req.continuous = bool(*continuous)
+			}
+		}
 
 		ids.Add(int(id))
-		r.addRequestInternalLocked(ctx, id, fprint)
+		r.addRequestInternalLocked(ctx, id, req)
 	}
 
 	// Remove all other requests.
-	for id := range r.mu.requestFingerprints {
+	for id := range r.mu.requests {
 		if !ids.Contains(int(id)) {
-			delete(r.mu.requestFingerprints, id)
+			delete(r.mu.requests, id)
 		}
 	}
 	return nil
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -163,6 +164,70 @@ func TestDiagnosticsRequestDifferentNode(t *testing.T) {
 	runUntilTraced("INSERT INTO test VALUES (2)", id1)
 }
 
+// TestDiagnosticsRequestConditional verifies that the conditions of a request
+// are respected: the minimum latency of the traced executions, and the
+// retention of the bundles of a continuous request.
+func TestDiagnosticsRequestConditional(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+	_, err := db.Exec("CREATE TABLE test (x int PRIMARY KEY)")
+	require.NoError(t, err)
+
+	registry := s.ExecutorConfig().(sql.ExecutorConfig).StmtDiagnosticsRecorder
+	isCompleted := func(reqID int64) bool {
+		var completed bool
+		require.NoError(t, db.QueryRow(
+			"SELECT completed FROM system.statement_diagnostics_requests WHERE ID = $1", reqID,
+		).Scan(&completed))
+		return completed
+	}
+	numBundles := func(reqID int64) int {
+		var count int
+		require.NoError(t, db.QueryRow(
+			"SELECT count(*) FROM system.statement_diagnostics WHERE request_id = $1", reqID,
+		).Scan(&count))
+		return count
+	}
+
+	// Only the executions that are slow enough are traced.
+	reqID, err := registry.InsertConditionalRequestInternal(
+		ctx, "SELECT pg_sleep(_)", stmtdiagnostics.RequestOptions{
+			MinExecutionLatency: 100 * time.Millisecond,
+		})
+	require.NoError(t, err)
+	_, err = db.Exec("SELECT pg_sleep(0.001)")
+	require.NoError(t, err)
+	require.False(t, isCompleted(reqID))
+	require.Zero(t, numBundles(reqID))
+	_, err = db.Exec("SELECT pg_sleep(0.2)")
+	require.NoError(t, err)
+	require.True(t, isCompleted(reqID))
+	require.Equal(t, 1, numBundles(reqID))
+
+	// Continuous requests stay pending and retain only the latest bundles.
+	_, err = db.Exec("SET CLUSTER SETTING sql.stmt_diagnostics.max_bundles_per_request = 2")
+	require.NoError(t, err)
+	reqID, err = registry.InsertConditionalRequestInternal(
+		ctx, "INSERT INTO test VALUES (_)", stmtdiagnostics.RequestOptions{
+			ExpiresAfter: time.Hour,
+			Continuous:   true,
+		})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err = db.Exec(fmt.Sprintf("INSERT INTO test VALUES (%d)", i))
+		require.NoError(t, err)
+	}
+	require.False(t, isCompleted(reqID))
+	require.Equal(t, 2, numBundles(reqID))
+
+	// Continuous requests must expire.
+	_, err = registry.InsertConditionalRequestInternal(
+		ctx, "SELECT x FROM test", stmtdiagnostics.RequestOptions{Continuous: true})
+	require.Error(t, err)
+}
+
 // TestChangePollInterval ensures that changing the polling interval takes effect.
 func TestChangePollInterval(t *testing.T) {
 	defer leaktest.AfterTest(t)()
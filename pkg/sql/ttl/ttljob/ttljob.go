@@ -0,0 +1,328 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ttljob contains the jobs.Resumer implementation which deletes the
+// expired rows of a table with row-level TTL.
+package ttljob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+	gogotypes "github.com/gogo/protobuf/types"
+)
+
+var (
+	jobEnabled = settings.RegisterBoolSetting(
+		"sql.ttl.job.enabled",
+		"whether the row-level TTL job is enabled",
+		true,
+	)
+	defaultSelectBatchSize = settings.RegisterIntSetting(
+		"sql.ttl.default_select_batch_size",
+		"default number of expired rows to select in a single query of the row-level TTL job",
+		500,
+		settings.PositiveInt,
+	)
+	defaultDeleteBatchSize = settings.RegisterIntSetting(
+		"sql.ttl.default_delete_batch_size",
+		"default number of expired rows to delete in a single query of the row-level TTL job",
+		100,
+		settings.PositiveInt,
+	)
+	defaultRangeConcurrency = settings.RegisterIntSetting(
+		"sql.ttl.default_range_concurrency",
+		"default number of ranges processed in parallel on each node by the row-level TTL job",
+		4,
+		settings.PositiveInt,
+	)
+	defaultDeleteRateLimit = settings.RegisterIntSetting(
+		"sql.ttl.default_delete_rate_limit",
+		"default maximum number of rows deleted per second on each node by the row-level TTL job; 0 means unlimited",
+		0,
+		settings.NonNegativeInt,
+	)
+)
+
+type rowLevelTTLResumer struct {
+	job *jobs.Job
+	st  *cluster.Settings
+}
+
+var _ jobs.Resumer = (*rowLevelTTLResumer)(nil)
+
+// Resume implements the jobs.Resumer interface.
+func (t rowLevelTTLResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	if !jobEnabled.Get(&execCfg.Settings.SV) {
+		return errors.Newf("row-level TTL jobs are disabled by cluster setting sql.ttl.job.enabled")
+	}
+	details := t.job.Details().(jobspb.RowLevelTTLDetails)
+
+	// Expired rows are selected as of the start of the job, so that the scans
+	// don't contend with foreground traffic.
+	aost := execCfg.Clock.Now()
+
+	var desc catalog.TableDescriptor
+	var spans []roachpb.Span
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) (err error) {
+		txn.SetFixedTimestamp(ctx, aost)
+		desc, err = catalogkv.MustGetTableDescByID(ctx, txn, execCfg.Codec, details.TableID)
+		if err != nil {
+			return err
+		}
+		if !desc.HasRowLevelTTL() {
+			return errors.Newf("table %q does not have row-level TTL", desc.GetName())
+		}
+		spans, err = primaryIndexSpans(ctx, execCfg.DistSender, execCfg.Codec, desc)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	ttl := desc.GetRowLevelTTL()
+	selectBatchSize := ttl.SelectBatchSize
+	if selectBatchSize == 0 {
+		selectBatchSize = defaultSelectBatchSize.Get(&execCfg.Settings.SV)
+	}
+	deleteBatchSize := ttl.DeleteBatchSize
+	if deleteBatchSize == 0 {
+		deleteBatchSize = defaultDeleteBatchSize.Get(&execCfg.Settings.SV)
+	}
+	rangeConcurrency := ttl.RangeConcurrency
+	if rangeConcurrency == 0 {
+		rangeConcurrency = defaultRangeConcurrency.Get(&execCfg.Settings.SV)
+	}
+	deleteRateLimit := ttl.DeleteRateLimit
+	if deleteRateLimit == 0 {
+		deleteRateLimit = defaultDeleteRateLimit.Get(&execCfg.Settings.SV)
+	}
+
+	// Plan one TTL processor on the leaseholder node of each span. A primary
+	// index with descending columns is processed as a single span, which is
+	// planned on the gateway.
+	evalCtx := p.ExtendedEvalContext()
+	dsp := p.DistSQLPlanner()
+	var planCtx *sql.PlanningCtx
+	if hasDescendingPKColumn(desc) {
+		planCtx = dsp.NewPlanningCtx(ctx, evalCtx, nil /* planner */, nil /* txn */, false /* distribute */)
+	} else {
+		var err error
+		// We don't use the returned nodes since PartitionSpans filters out
+		// incompatible nodes.
+		if planCtx, _, err = dsp.SetupAllNodesPlanning(ctx, evalCtx, execCfg); err != nil {
+			return errors.Wrap(err, "failed to determine nodes on which to run")
+		}
+	}
+	spanPartitions, err := dsp.PartitionSpans(planCtx, spans)
+	if err != nil {
+		return err
+	}
+	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
+	for i, partition := range spanPartitions {
+		corePlacement[i].NodeID = partition.Node
+		corePlacement[i].Core.TTL = &execinfrapb.TTLSpec{
+			TableDesc:        *desc.TableDesc(),
+			AOST:             aost,
+			CutoffMicros:     details.CutoffMicros,
+			Spans:            partition.Spans,
+			SelectBatchSize:  selectBatchSize,
+			DeleteBatchSize:  deleteBatchSize,
+			RangeConcurrency: rangeConcurrency,
+			DeleteRateLimit:  deleteRateLimit,
+		}
+	}
+
+	physicalPlan := planCtx.NewPhysicalPlan()
+	// All of the progress information is sent through the metadata stream, so we
+	// have an empty result stream.
+	physicalPlan.AddNoInputStage(
+		corePlacement, execinfrapb.PostProcessSpec{}, []*types.T{}, execinfrapb.Ordering{},
+	)
+	physicalPlan.PlanToStreamColMap = []int{}
+	dsp.FinalizePlan(planCtx, physicalPlan)
+
+	var rangesDone int
+	metaFn := func(ctx context.Context, meta *execinfrapb.ProducerMetadata) error {
+		if meta.BulkProcessorProgress == nil {
+			return nil
+		}
+		var progDetails jobspb.RowLevelTTLProgress
+		if err := gogotypes.UnmarshalAny(&meta.BulkProcessorProgress.ProgressDetails, &progDetails); err != nil {
+			return err
+		}
+		rangesDone += len(meta.BulkProcessorProgress.CompletedSpans)
+		fractionCompleted := float32(rangesDone) / float32(len(spans))
+		return t.job.FractionProgressed(
+			ctx,
+			func(ctx context.Context, details jobspb.ProgressDetails) float32 {
+				prog := details.(*jobspb.Progress_RowLevelTTL).RowLevelTTL
+				prog.RowsDeleted += progDetails.RowsDeleted
+				return fractionCompleted
+			},
+		)
+	}
+	rowResultWriter := sql.NewRowResultWriter(nil)
+	var noTxn *kv.Txn
+	recv := sql.MakeDistSQLReceiver(
+		ctx,
+		sql.NewMetadataCallbackWriter(rowResultWriter, metaFn),
+		tree.Rows,
+		nil,   /* rangeCache */
+		noTxn, /* txn - the flow does not run in a transaction */
+		nil,   /* clockUpdater */
+		evalCtx.Tracing,
+		execCfg.ContentionRegistry,
+	)
+	defer recv.Release()
+
+	// Copy the evalCtx, as dsp.Run() might change it.
+	evalCtxCopy := *evalCtx
+	dsp.Run(planCtx, noTxn, physicalPlan, recv, &evalCtxCopy, nil /* finishedSetupFn */)()
+	if err := rowResultWriter.Err(); err != nil {
+		return err
+	}
+	schedulebase.MaybeNotifyScheduledJobCompletion(ctx, t.job, jobs.StatusSucceeded, execCfg)
+	return nil
+}
+
+// primaryIndexSpans splits the primary index of the table along range
+// boundaries, so that each range can be processed by the TTL processor on its
+// leaseholder node. If the primary index has descending columns, range
+// boundaries don't match the order of the primary key, so the whole index is
+// returned as a single span.
+func primaryIndexSpans(
+	ctx context.Context,
+	distSender *kvcoord.DistSender,
+	codec keys.SQLCodec,
+	desc catalog.TableDescriptor,
+) ([]roachpb.Span, error) {
+	indexSpan := desc.PrimaryIndexSpan(codec)
+	if hasDescendingPKColumn(desc) {
+		return []roachpb.Span{indexSpan}, nil
+	}
+	rs, err := keys.SpanAddr(indexSpan)
+	if err != nil {
+		return nil, err
+	}
+	var spans []roachpb.Span
+	ri := kvcoord.NewRangeIterator(distSender)
+	for ri.Seek(ctx, rs.Key, kvcoord.Ascending); ; ri.Next(ctx) {
+		if !ri.Valid() {
+			return nil, ri.Error()
+		}
+		// Intersect the range with the primary index.
+		startKey, endKey := rs.Key, rs.EndKey
+		if rangeDesc := ri.Desc(); startKey.Less(rangeDesc.StartKey) {
+			startKey = rangeDesc.StartKey
+		}
+		if rangeDesc := ri.Desc(); rangeDesc.EndKey.Less(endKey) {
+			endKey = rangeDesc.EndKey
+		}
+		spans = append(spans, roachpb.Span{Key: startKey.AsRawKey(), EndKey: endKey.AsRawKey()})
+		if !ri.NeedAnother(rs) {
+			return spans, nil
+		}
+	}
+}
+
+// keyToPKPrefix decodes the longest primary key prefix of the given key of the
+// primary index. It returns no datums if the key is outside of the primary
+// index.
+func keyToPKPrefix(
+	codec keys.SQLCodec,
+	desc catalog.TableDescriptor,
+	pkTypes []*types.T,
+	key roachpb.Key,
+	alloc *rowenc.DatumAlloc,
+) (tree.Datums, error) {
+	key, err := codec.StripTenantPrefix(key)
+	if err != nil {
+		return nil, err
+	}
+	key, tableID, indexID, err := rowenc.DecodePartialTableIDIndexID(key)
+	if err != nil || tableID != desc.GetID() || indexID != desc.GetPrimaryIndexID() {
+		// The key is the prefix of the index, or the end of the index.
+		return nil, nil //nolint:returnerrcheck
+	}
+	var datums tree.Datums
+	for _, typ := range pkTypes {
+		if len(key) == 0 {
+			break
+		}
+		var d tree.Datum
+		d, key, err = rowenc.DecodeTableKey(alloc, typ, key, encoding.Ascending)
+		if err != nil {
+			// Range boundaries may fall inside a key; ignore the partially
+			// decoded column.
+			break
+		}
+		datums = append(datums, d)
+	}
+	return datums, nil
+}
+
+// OnFailOrCancel implements the jobs.Resumer interface.
+func (t rowLevelTTLResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	// Rows deleted so far stay deleted; the next run of the schedule picks up
+	// the remaining expired rows.
+	p := execCtx.(sql.JobExecContext)
+	schedulebase.MaybeNotifyScheduledJobCompletion(ctx, t.job, jobs.StatusFailed, p.ExecCfg())
+	return nil
+}
+
+// NewRecord constructs the jobs.Record of a job deleting the rows of the given
+// table which expired before the given time.
+func NewRecord(
+	tableID descpb.ID, tableName string, cutoffMicros int64, createdBy *jobs.CreatedByInfo,
+) jobs.Record {
+	return jobs.Record{
+		Description: fmt.Sprintf("ttl for %s", tableName),
+		Username:    security.RootUserName(),
+		Details: jobspb.RowLevelTTLDetails{
+			TableID:      tableID,
+			CutoffMicros: cutoffMicros,
+		},
+		Progress:      jobspb.RowLevelTTLProgress{},
+		DescriptorIDs: descpb.IDs{tableID},
+		CreatedBy:     createdBy,
+	}
+}
+
+func init() {
+	jobs.RegisterConstructor(jobspb.TypeRowLevelTTL, func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+		return &rowLevelTTLResumer{
+			job: job,
+			st:  settings,
+		}
+	})
+}
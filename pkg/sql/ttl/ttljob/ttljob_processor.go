@@ -0,0 +1,316 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ttljob
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	gogotypes "github.com/gogo/protobuf/types"
+)
+
+const ttlProcessorName = "ttlProcessor"
+
+// ttlProcessor represents the work each node performs during a row-level TTL
+// job. It is assigned the spans of the primary index whose leaseholder is on
+// its node, and deletes their expired rows using RangeConcurrency workers,
+// which each process a span at a time. After processing a span, it streams
+// back its progress through the metadata channel provided by DistSQL.
+type ttlProcessor struct {
+	execinfra.ProcessorBase
+
+	flowCtx *execinfra.FlowCtx
+	spec    execinfrapb.TTLSpec
+
+	cancel context.CancelFunc
+	progCh chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
+	ttlErr error
+}
+
+var _ execinfra.Processor = &ttlProcessor{}
+var _ execinfra.RowSource = &ttlProcessor{}
+
+func newTTLProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.TTLSpec,
+	post *execinfrapb.PostProcessSpec,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	t := &ttlProcessor{
+		flowCtx: flowCtx,
+		spec:    spec,
+		progCh:  make(chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress),
+	}
+	if err := t.Init(t, post, []*types.T{}, flowCtx, processorID, output, nil, /* memMonitor */
+		execinfra.ProcStateOpts{
+			// This processor doesn't have any inputs to drain.
+			InputsToDrain: nil,
+		}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Start is part of the RowSource interface.
+func (t *ttlProcessor) Start(ctx context.Context) context.Context {
+	ctx = t.StartInternal(ctx, ttlProcessorName)
+	workCtx, cancel := context.WithCancel(ctx)
+	t.cancel = cancel
+	go func() {
+		defer close(t.progCh)
+		t.ttlErr = runTTLProcessor(workCtx, t.flowCtx, &t.spec, t.progCh)
+	}()
+	return ctx
+}
+
+// Next is part of the RowSource interface.
+func (t *ttlProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
+	if t.State != execinfra.StateRunning {
+		return nil, t.DrainHelper()
+	}
+
+	for prog := range t.progCh {
+		p := prog
+		return nil, &execinfrapb.ProducerMetadata{BulkProcessorProgress: &p}
+	}
+
+	t.MoveToDraining(t.ttlErr)
+	return nil, t.DrainHelper()
+}
+
+// ConsumerClosed is part of the RowSource interface.
+func (t *ttlProcessor) ConsumerClosed() {
+	// The consumer is done, Next() will not be called again. Stop the workers
+	// and wait for them to exit.
+	if t.cancel != nil {
+		t.cancel()
+		for range t.progCh {
+		}
+	}
+	t.InternalClose()
+}
+
+// runTTLProcessor deletes the expired rows of the spans of the spec, and sends
+// the progress made on progCh after each span.
+func runTTLProcessor(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.TTLSpec,
+	progCh chan<- execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
+) error {
+	desc := tabledesc.NewImmutable(spec.TableDesc)
+	pkColumns, pkTypes, err := primaryKeyColumns(desc)
+	if err != nil {
+		return err
+	}
+	// Spans of a primary index with descending columns can't be bounded by
+	// primary key prefixes; such an index is processed as a single span.
+	boundSpans := !hasDescendingPKColumn(desc)
+
+	var rateLimiter *quotapool.RateLimiter
+	if spec.DeleteRateLimit > 0 {
+		rateLimiter = quotapool.NewRateLimiter(
+			"ttl-delete", quotapool.Limit(spec.DeleteRateLimit), spec.DeleteBatchSize,
+		)
+	}
+	metrics := flowCtx.Cfg.JobRegistry.MetricsStruct().RowLevelTTL.(*RowLevelTTLAggMetrics)
+	cutoff := cutoffDatum(spec.CutoffMicros)
+
+	spanCh := make(chan roachpb.Span)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		defer close(spanCh)
+		for _, sp := range spec.Spans {
+			select {
+			case spanCh <- sp:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for i := int64(0); i < spec.RangeConcurrency; i++ {
+		g.GoCtx(func(ctx context.Context) error {
+			var alloc rowenc.DatumAlloc
+			for sp := range spanCh {
+				var startPK, endPK tree.Datums
+				if boundSpans {
+					var err error
+					if startPK, err = keyToPKPrefix(flowCtx.Codec(), desc, pkTypes, sp.Key, &alloc); err != nil {
+						return err
+					}
+					if endPK, err = keyToPKPrefix(flowCtx.Codec(), desc, pkTypes, sp.EndKey, &alloc); err != nil {
+						return err
+					}
+				}
+				start := timeutil.Now()
+				selectBuilder := makeSelectQueryBuilder(
+					desc.GetID(), pkColumns, spec.SelectBatchSize, spec.AOST, cutoff, startPK, endPK,
+				)
+				deleteBuilder := makeDeleteQueryBuilder(desc.GetID(), pkColumns, cutoff)
+				rowsDeleted, err := runTTLOnSpan(
+					ctx, flowCtx.Cfg.Executor, metrics, &selectBuilder, &deleteBuilder,
+					spec.DeleteBatchSize, rateLimiter,
+				)
+				metrics.RangeTotalDuration.RecordValue(timeutil.Since(start).Nanoseconds())
+				if err != nil {
+					return err
+				}
+
+				details, err := gogotypes.MarshalAny(&jobspb.RowLevelTTLProgress{RowsDeleted: rowsDeleted})
+				if err != nil {
+					return err
+				}
+				var prog execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
+				prog.CompletedSpans = []roachpb.Span{sp}
+				prog.ProgressDetails = *details
+				select {
+				case progCh <- prog:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// runTTLOnSpan deletes the expired rows of a span of the primary index, and
+// returns the number of rows deleted. Each batch of deletions runs in its own
+// transaction, so that it is subject to the same rate limiting as any other
+// foreground traffic.
+func runTTLOnSpan(
+	ctx context.Context,
+	ie sqlutil.InternalExecutor,
+	metrics *RowLevelTTLAggMetrics,
+	selectBuilder *selectQueryBuilder,
+	deleteBuilder *deleteQueryBuilder,
+	deleteBatchSize int64,
+	rateLimiter *quotapool.RateLimiter,
+) (int64, error) {
+	override := sessiondata.InternalExecutorOverride{User: security.RootUserName()}
+	var rowsDeleted int64
+	for {
+		query, args := selectBuilder.nextQuery()
+		start := timeutil.Now()
+		expiredRows, err := queryRows(ctx, ie, "ttl-select", override, query, args...)
+		metrics.SelectDuration.RecordValue(timeutil.Since(start).Nanoseconds())
+		if err != nil {
+			return rowsDeleted, errors.Wrap(err, "error selecting expired rows")
+		}
+		metrics.RowsSelected.Inc(int64(len(expiredRows)))
+		if len(expiredRows) == 0 {
+			return rowsDeleted, nil
+		}
+		selectBuilder.moveCursor(expiredRows[len(expiredRows)-1])
+
+		for startIdx := 0; startIdx < len(expiredRows); startIdx += int(deleteBatchSize) {
+			endIdx := startIdx + int(deleteBatchSize)
+			if endIdx > len(expiredRows) {
+				endIdx = len(expiredRows)
+			}
+			batch := expiredRows[startIdx:endIdx]
+			if rateLimiter != nil {
+				if err := rateLimiter.WaitN(ctx, int64(len(batch))); err != nil {
+					return rowsDeleted, err
+				}
+			}
+			query, args := deleteBuilder.buildQuery(batch)
+			start := timeutil.Now()
+			n, err := ie.ExecEx(ctx, "ttl-delete", nil /* txn */, override, query, args...)
+			metrics.DeleteDuration.RecordValue(timeutil.Since(start).Nanoseconds())
+			if err != nil {
+				return rowsDeleted, errors.Wrap(err, "error deleting expired rows")
+			}
+			metrics.RowsDeleted.Inc(int64(n))
+			rowsDeleted += int64(n)
+		}
+
+		if int64(len(expiredRows)) < selectBuilder.selectBatchSize {
+			return rowsDeleted, nil
+		}
+	}
+}
+
+// queryRows runs the given query outside of any transaction and returns all of
+// its rows.
+func queryRows(
+	ctx context.Context,
+	ie sqlutil.InternalExecutor,
+	opName string,
+	override sessiondata.InternalExecutorOverride,
+	query string,
+	args ...interface{},
+) (_ []tree.Datums, retErr error) {
+	it, err := ie.QueryIteratorEx(ctx, opName, nil /* txn */, override, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+	var rows []tree.Datums
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		rows = append(rows, it.Cur())
+	}
+	return rows, err
+}
+
+// primaryKeyColumns returns the names and types of the primary key columns of
+// the table.
+func primaryKeyColumns(desc catalog.TableDescriptor) ([]string, []*types.T, error) {
+	primaryIndex := desc.GetPrimaryIndex()
+	names := make([]string, primaryIndex.NumColumns())
+	pkTypes := make([]*types.T, primaryIndex.NumColumns())
+	for i := range names {
+		names[i] = primaryIndex.GetColumnName(i)
+		col, err := desc.FindColumnWithID(primaryIndex.GetColumnID(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		pkTypes[i] = col.GetType()
+	}
+	return names, pkTypes, nil
+}
+
+// hasDescendingPKColumn returns whether the primary index of the table has a
+// descending column.
+func hasDescendingPKColumn(desc catalog.TableDescriptor) bool {
+	primaryIndex := desc.GetPrimaryIndex()
+	for i := 0; i < primaryIndex.NumColumns(); i++ {
+		if primaryIndex.GetColumnDirection(i) != descpb.IndexDescriptor_ASC {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rowexec.NewTTLProcessor = newTTLProcessor
+}
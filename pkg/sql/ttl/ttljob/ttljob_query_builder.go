@@ -0,0 +1,176 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ttljob
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// selectQueryBuilder builds the queries which fetch the primary keys of the
+// expired rows of a span of the primary index, one page at a time.
+type selectQueryBuilder struct {
+	tableID         descpb.ID
+	pkColumns       []string
+	selectBatchSize int64
+	aost            hlc.Timestamp
+	cutoff          *tree.DTimestampTZ
+	// startPK and endPK bound the span being processed. They may be prefixes
+	// of the primary key, or empty if the span is unbounded on that side.
+	startPK, endPK tree.Datums
+
+	// lastPK is the primary key of the last row returned by the previous
+	// page, or nil before the first page is fetched.
+	lastPK tree.Datums
+}
+
+func makeSelectQueryBuilder(
+	tableID descpb.ID,
+	pkColumns []string,
+	selectBatchSize int64,
+	aost hlc.Timestamp,
+	cutoff *tree.DTimestampTZ,
+	startPK, endPK tree.Datums,
+) selectQueryBuilder {
+	return selectQueryBuilder{
+		tableID:         tableID,
+		pkColumns:       pkColumns,
+		selectBatchSize: selectBatchSize,
+		aost:            aost,
+		cutoff:          cutoff,
+		startPK:         startPK,
+		endPK:           endPK,
+	}
+}
+
+// nextQuery returns the query and arguments fetching the next page of
+// expired rows.
+func (b *selectQueryBuilder) nextQuery() (string, []interface{}) {
+	args := []interface{}{b.cutoff}
+	var buf bytes.Buffer
+	fmt.Fprintf(
+		&buf,
+		"SELECT %s FROM [%d AS tbl_name] AS OF SYSTEM TIME %s WHERE %s < $1",
+		columnList(b.pkColumns),
+		b.tableID,
+		b.aost.AsOfSystemTime(),
+		tree.NameString(colinfo.TTLDefaultExpirationColumnName),
+	)
+	// Resume after the last row of the previous page, or else start at the
+	// start of the span.
+	if b.lastPK != nil {
+		args = appendTupleComparison(&buf, args, b.pkColumns, ">", b.lastPK)
+	} else if len(b.startPK) > 0 {
+		args = appendTupleComparison(&buf, args, b.pkColumns[:len(b.startPK)], ">=", b.startPK)
+	}
+	if len(b.endPK) > 0 {
+		args = appendTupleComparison(&buf, args, b.pkColumns[:len(b.endPK)], "<", b.endPK)
+	}
+	fmt.Fprintf(&buf, " ORDER BY %s LIMIT %d", columnList(b.pkColumns), b.selectBatchSize)
+	return buf.String(), args
+}
+
+// moveCursor records the last row of a page, after which the next page
+// starts.
+func (b *selectQueryBuilder) moveCursor(lastRow tree.Datums) {
+	b.lastPK = lastRow
+}
+
+// deleteQueryBuilder builds the queries which delete a batch of expired rows
+// by primary key. Rows are checked to still be expired, in case they were
+// updated since they were selected.
+type deleteQueryBuilder struct {
+	tableID   descpb.ID
+	pkColumns []string
+	cutoff    *tree.DTimestampTZ
+}
+
+func makeDeleteQueryBuilder(
+	tableID descpb.ID, pkColumns []string, cutoff *tree.DTimestampTZ,
+) deleteQueryBuilder {
+	return deleteQueryBuilder{
+		tableID:   tableID,
+		pkColumns: pkColumns,
+		cutoff:    cutoff,
+	}
+}
+
+// buildQuery returns the query and arguments deleting the given rows.
+func (b *deleteQueryBuilder) buildQuery(rows []tree.Datums) (string, []interface{}) {
+	args := make([]interface{}, 0, 1+len(rows)*len(b.pkColumns))
+	args = append(args, b.cutoff)
+	var buf bytes.Buffer
+	fmt.Fprintf(
+		&buf,
+		"DELETE FROM [%d AS tbl_name] WHERE %s < $1 AND (%s) IN (",
+		b.tableID,
+		tree.NameString(colinfo.TTLDefaultExpirationColumnName),
+		columnList(b.pkColumns),
+	)
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("(")
+		for j := range b.pkColumns {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			args = append(args, row[j])
+			fmt.Fprintf(&buf, "$%d", len(args))
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(")")
+	return buf.String(), args
+}
+
+// columnList returns the comma-separated list of the given column names.
+func columnList(columns []string) string {
+	var buf bytes.Buffer
+	for i, c := range columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(tree.NameString(c))
+	}
+	return buf.String()
+}
+
+// appendTupleComparison appends a comparison of the tuple of the given
+// columns against the tuple of the given values, as placeholders following
+// the given arguments.
+func appendTupleComparison(
+	buf *bytes.Buffer, args []interface{}, columns []string, op string, values tree.Datums,
+) []interface{} {
+	fmt.Fprintf(buf, " AND (%s) %s (", columnList(columns), op)
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		args = append(args, v)
+		fmt.Fprintf(buf, "$%d", len(args))
+	}
+	buf.WriteString(")")
+	return args
+}
+
+// cutoffDatum returns the expiration cutoff of a job as a TIMESTAMPTZ.
+func cutoffDatum(cutoffMicros int64) *tree.DTimestampTZ {
+	return tree.MustMakeDTimestampTZ(timeutil.FromUnixMicros(cutoffMicros), time.Microsecond)
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ttljob_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/ttl/ttljob"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowLevelTTLJobDeletesExpiredRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		desc        string
+		createTable string
+		splitAt     string
+		// insert inserts the rows for the given i with the given expiration.
+		insert string
+	}{
+		{
+			desc: "single column primary key",
+			createTable: `CREATE TABLE t (id INT PRIMARY KEY, val INT)
+WITH (ttl_expire_after = '10 minutes', ttl_select_batch_size = 7, ttl_delete_batch_size = 3)`,
+			splitAt: `ALTER TABLE t SPLIT AT VALUES (25), (50), (75)`,
+			insert:  `INSERT INTO t (id, val, crdb_internal_expiration) VALUES (%[1]d, %[1]d, %[2]s)`,
+		},
+		{
+			desc: "composite primary key split inside a key",
+			createTable: `CREATE TABLE t (a INT, b STRING, val INT, PRIMARY KEY (a, b))
+WITH (ttl_expire_after = '10 minutes', ttl_range_concurrency = 2, ttl_delete_rate_limit = 1000)`,
+			splitAt: `ALTER TABLE t SPLIT AT VALUES (25, 'x'), (50, 'y')`,
+			insert: `INSERT INTO t (a, b, val, crdb_internal_expiration)
+VALUES (%[1]d, 'x', %[1]d, %[2]s), (%[1]d, 'y', %[1]d, %[2]s)`,
+		},
+		{
+			desc: "descending primary key",
+			createTable: `CREATE TABLE t (id INT, val INT, PRIMARY KEY (id DESC))
+WITH (ttl_expire_after = '10 minutes', ttl_select_batch_size = 11)`,
+			splitAt: `ALTER TABLE t SPLIT AT VALUES (50)`,
+			insert:  `INSERT INTO t (id, val, crdb_internal_expiration) VALUES (%[1]d, %[1]d, %[2]s)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+			defer s.Stopper().Stop(ctx)
+			sqlDB := sqlutils.MakeSQLRunner(db)
+
+			sqlDB.Exec(t, tc.createTable)
+			sqlDB.Exec(t, tc.splitAt)
+			// Rows with an even val have expired.
+			for i := 0; i < 100; i++ {
+				expiration := "now() + '1 hour'"
+				if i%2 == 0 {
+					expiration = "now() - '1 hour'"
+				}
+				sqlDB.Exec(t, fmt.Sprintf(tc.insert, i, expiration))
+			}
+			var total, expectedRemaining int
+			sqlDB.QueryRow(t, `SELECT count(*) FROM t`).Scan(&total)
+			sqlDB.QueryRow(t, `SELECT count(*) FROM t WHERE val % 2 = 1`).Scan(&expectedRemaining)
+
+			job := runTTLJob(ctx, t, s, kvDB, sqlDB)
+
+			var remaining int
+			sqlDB.QueryRow(t, `SELECT count(*) FROM t`).Scan(&remaining)
+			require.Equal(t, expectedRemaining, remaining)
+			sqlDB.CheckQueryResults(t, `SELECT count(*) FROM t WHERE val % 2 = 0`, [][]string{{"0"}})
+
+			progress := job.Progress()
+			require.Equal(t, int64(total-expectedRemaining), progress.GetRowLevelTTL().RowsDeleted)
+			require.Equal(t, float32(1), progress.GetFractionCompleted())
+		})
+	}
+}
+
+// TestRowLevelTTLJobRunsOnLeaseholders checks that each range of the table is
+// processed by the TTL processor of its leaseholder node.
+func TestRowLevelTTLJobRunsOnLeaseholders(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numNodes = 3
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, numNodes, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+
+	sqlDB.Exec(t, `CREATE TABLE t (id INT PRIMARY KEY, val INT) WITH (ttl_expire_after = '10 minutes')`)
+	sqlDB.Exec(t, `ALTER TABLE t SPLIT AT VALUES (100), (200)`)
+	// Rows with an even id have expired.
+	sqlDB.Exec(t, `INSERT INTO t (id, val, crdb_internal_expiration)
+SELECT i, i, IF(i % 2 = 0, now() - '1 hour', now() + '1 hour') FROM generate_series(0, 299) AS g(i)`)
+
+	// Move the i-th range to the i-th node. This may have to retry if the
+	// store descriptors have not yet propagated to the first store's StorePool.
+	for i := 0; i < numNodes; i++ {
+		testutils.SucceedsSoon(t, func() error {
+			_, err := tc.ServerConn(0).Exec(fmt.Sprintf(
+				`ALTER TABLE t EXPERIMENTAL_RELOCATE VALUES (ARRAY[%d], %d)`,
+				tc.Server(i).GetFirstStoreID(), i*100,
+			))
+			return err
+		})
+	}
+	// Refresh the range cache of the gateway, which the job uses to place the
+	// processors.
+	sqlDB.Exec(t, `SELECT count(*) FROM t`)
+
+	job := runTTLJob(ctx, t, tc.Server(0), tc.Server(0).DB(), sqlDB)
+
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM t`, [][]string{{"150"}})
+	progress := job.Progress()
+	require.Equal(t, int64(150), progress.GetRowLevelTTL().RowsDeleted)
+	require.Equal(t, float32(1), progress.GetFractionCompleted())
+	for i := 0; i < numNodes; i++ {
+		registry := tc.Server(i).JobRegistry().(*jobs.Registry)
+		metrics := registry.MetricsStruct().RowLevelTTL.(*ttljob.RowLevelTTLAggMetrics)
+		require.Equal(t, int64(50), metrics.RowsDeleted.Count(), "node %d", i+1)
+	}
+}
+
+// runTTLJob runs a row-level TTL job on table t, deleting the rows which have
+// expired by now, and returns the job once it has succeeded.
+func runTTLJob(
+	ctx context.Context,
+	t *testing.T,
+	s serverutils.TestServerInterface,
+	kvDB *kv.DB,
+	sqlDB *sqlutils.SQLRunner,
+) *jobs.Job {
+	var tableID descpb.ID
+	sqlDB.QueryRow(t, `SELECT 't'::REGCLASS::OID`).Scan(&tableID)
+
+	registry := s.JobRegistry().(*jobs.Registry)
+	var job *jobs.Job
+	require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) (err error) {
+		job, err = registry.CreateJobWithTxn(
+			ctx,
+			ttljob.NewRecord(tableID, "t", timeutil.ToUnixMicros(timeutil.Now()), nil /* createdBy */),
+			txn,
+		)
+		return err
+	}))
+	require.NoError(t, registry.Run(
+		ctx, s.InternalExecutor().(*sql.InternalExecutor), []int64{*job.ID()},
+	))
+	job, err := registry.LoadJob(ctx, *job.ID())
+	require.NoError(t, err)
+	return job
+}
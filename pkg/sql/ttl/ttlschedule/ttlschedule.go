@@ -0,0 +1,145 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package ttlschedule contains the jobs.ScheduledJobExecutor which
+// periodically starts the deletion job of a table with row-level TTL.
+package ttlschedule
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/ttl/ttljob"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+type rowLevelTTLExecutor struct {
+	metrics rowLevelTTLMetrics
+}
+
+var _ jobs.ScheduledJobExecutor = (*rowLevelTTLExecutor)(nil)
+
+type rowLevelTTLMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &rowLevelTTLMetrics{}
+
+// MetricStruct implements metric.Struct interface.
+func (m *rowLevelTTLMetrics) MetricStruct() {}
+
+// ExecuteJob implements the jobs.ScheduledJobExecutor interface.
+func (s *rowLevelTTLExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := s.createJob(ctx, cfg, env, sj, txn); err != nil {
+		s.metrics.NumFailed.Inc(1)
+		return err
+	}
+	s.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (s *rowLevelTTLExecutor) createJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	args := &jobspb.ScheduledRowLevelTTLArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return errors.Wrap(err, "un-marshaling args")
+	}
+
+	p, cleanup := cfg.PlanHookMaker("ttl-create-job", txn, security.RootUserName())
+	defer cleanup()
+	execCfg := p.(sql.PlanHookState).ExecCfg()
+
+	desc, err := catalogkv.MustGetTableDescByID(ctx, txn, execCfg.Codec, args.TableID)
+	if err != nil {
+		return err
+	}
+	if !desc.HasRowLevelTTL() {
+		return errors.Newf("table %q does not have row-level TTL", desc.GetName())
+	}
+
+	// The expiration column already accounts for ttl_expire_after, so the rows
+	// which expired before the start of this run are deleted.
+	record := ttljob.NewRecord(
+		desc.GetID(),
+		desc.GetName(),
+		timeutil.ToUnixMicros(env.Now()),
+		&jobs.CreatedByInfo{Name: jobs.CreatedByScheduledJobs, ID: sj.ScheduleID()},
+	)
+	if _, err := execCfg.JobRegistry.CreateAdoptableJobWithTxn(ctx, record, txn); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NotifyJobTermination implements the jobs.ScheduledJobExecutor interface.
+func (s *rowLevelTTLExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID int64,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		s.metrics.NumSucceeded.Inc(1)
+		return nil
+	}
+
+	s.metrics.NumFailed.Inc(1)
+	err := errors.Errorf(
+		"row-level TTL job %d scheduled by %d failed with status %s",
+		jobID, sj.ScheduleID(), jobStatus)
+	log.Errorf(ctx, "row-level TTL error: %v", err)
+	jobs.DefaultHandleFailedRun(sj, "row-level TTL job %d failed with err=%v", jobID, err)
+	return nil
+}
+
+// Metrics implements the jobs.ScheduledJobExecutor interface.
+func (s *rowLevelTTLExecutor) Metrics() metric.Struct {
+	return &s.metrics
+}
+
+func init() {
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledRowLevelTTLExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledRowLevelTTLExecutor.InternalName())
+			return &rowLevelTTLExecutor{
+				metrics: rowLevelTTLMetrics{
+					ExecutorMetrics: &m,
+				},
+			}, nil
+		})
+}
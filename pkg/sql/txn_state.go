@@ -17,7 +17,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -133,6 +136,7 @@ const (
 // priority: The transaction's priority. Pass roachpb.UnspecifiedUserPriority if the txn arg is
 //   not nil.
 // readOnly: The read-only character of the new txn.
+// isoLevel: The isolation level of the new txn.
 // txn: If not nil, this txn will be used instead of creating a new txn. If so,
 //   all the other arguments need to correspond to the attributes of this txn
 //   (unless otherwise specified).
@@ -144,6 +148,7 @@ func (ts *txnState) resetForNewSQLTxn(
 	historicalTimestamp *hlc.Timestamp,
 	priority roachpb.UserPriority,
 	readOnly tree.ReadWriteMode,
+	isoLevel enginepb.IsolationLevel,
 	txn *kv.Txn,
 	tranCtx transitionCtx,
 ) {
@@ -157,7 +162,13 @@ func (ts *txnState) resetForNewSQLTxn(
 	// TODO(andrei): figure out how to close these spans on server shutdown? Ties
 	// into a larger discussion about how to drain SQL and rollback open txns.
 	opName := sqlTxnName
-	txnCtx, sp := createRootOrChildSpan(connCtx, opName, tranCtx.tracer, tracing.WithBypassRegistry())
+	spanOpts := []tracing.SpanOption{tracing.WithBypassRegistry()}
+	if tracing.SpanFromContext(connCtx) == nil && tranCtx.traceParent != nil {
+		if remoteParent := tranCtx.traceParent(); remoteParent != nil {
+			spanOpts = append(spanOpts, tracing.WithParentAndManualCollection(remoteParent))
+		}
+	}
+	txnCtx, sp := createRootOrChildSpan(connCtx, opName, tranCtx.tracer, spanOpts...)
 	if txnType == implicitTxn {
 		sp.SetTag("implicit", "true")
 	}
@@ -182,6 +193,9 @@ func (ts *txnState) resetForNewSQLTxn(
 		if err := ts.setPriorityLocked(priority); err != nil {
 			panic(err)
 		}
+		if err := ts.mu.txn.SetIsolationLevel(isoLevel); err != nil {
+			panic(err)
+		}
 	} else {
 		if priority != roachpb.UnspecifiedUserPriority {
 			panic(errors.AssertionFailedf("unexpected priority when using an existing txn: %s", priority))
@@ -269,6 +283,15 @@ func (ts *txnState) setPriority(userPriority roachpb.UserPriority) error {
 	return ts.setPriorityLocked(userPriority)
 }
 
+func (ts *txnState) setIsolationLevel(level enginepb.IsolationLevel) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if err := ts.mu.txn.SetIsolationLevel(level); err != nil {
+		return pgerror.Wrap(err, pgcode.ActiveSQLTransaction, "cannot set transaction isolation level")
+	}
+	return nil
+}
+
 func (ts *txnState) setPriorityLocked(userPriority roachpb.UserPriority) error {
 	if err := ts.mu.txn.SetUserPriority(userPriority); err != nil {
 		return err
@@ -382,6 +405,9 @@ type transitionCtx struct {
 	// The Tracer used to create root spans for new txns if the parent ctx doesn't
 	// have a span.
 	tracer *tracing.Tracer
+	// traceParent returns the remote parent of the root spans of new txns, as
+	// set by the client through the traceparent session variable, if any.
+	traceParent func() *tracing.SpanMeta
 	// sessionTracing provides access to the session's tracing interface. The
 	// state machine needs to see if session tracing is enabled.
 	sessionTracing *SessionTracing
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -270,7 +271,7 @@ func TestTransitions(t *testing.T) {
 				return s, ts, nil
 			},
 			ev: eventTxnStart{ImplicitTxn: fsm.True},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(),
+			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, enginepb.SERIALIZABLE, timeutil.Now(),
 				nil /* historicalTimestamp */, tranCtx),
 			expState: stateOpen{ImplicitTxn: fsm.True},
 			expAdv: expAdvance{
@@ -295,7 +296,7 @@ func TestTransitions(t *testing.T) {
 				return s, ts, nil
 			},
 			ev: eventTxnStart{ImplicitTxn: fsm.False},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(),
+			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, enginepb.SERIALIZABLE, timeutil.Now(),
 				nil /* historicalTimestamp */, tranCtx),
 			expState: stateOpen{ImplicitTxn: fsm.False},
 			expAdv: expAdvance{
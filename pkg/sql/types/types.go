@@ -443,6 +443,24 @@ var (
 		},
 	}
 
+	// TSQuery is the type of a full text search query.
+	TSQuery = &T{
+		InternalType: InternalType{
+			Family: TSQueryFamily,
+			Oid:    oid.T_tsquery,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSVector is the type of a full text search document.
+	TSVector = &T{
+		InternalType: InternalType{
+			Family: TSVectorFamily,
+			Oid:    oid.T_tsvector,
+			Locale: &emptyLocale,
+		},
+	}
+
 	// Scalar contains all types that meet this criteria:
 	//
 	//   1. Scalar type (no ArrayFamily or TupleFamily types).
@@ -470,6 +488,8 @@ var (
 		TimeTZ,
 		Jsonb,
 		VarBit,
+		TSQuery,
+		TSVector,
 	}
 
 	// Any is a special type used only during static analysis as a wildcard type
@@ -1298,6 +1318,8 @@ var familyNames = map[Family]string{
 	TimestampFamily:      "timestamp",
 	TimestampTZFamily:    "timestamptz",
 	TimeTZFamily:         "timetz",
+	TSQueryFamily:        "tsquery",
+	TSVectorFamily:       "tsvector",
 	TupleFamily:          "tuple",
 	UnknownFamily:        "unknown",
 	UuidFamily:           "uuid",
@@ -1614,6 +1636,10 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 			return "timestamp with time zone"
 		}
 		return fmt.Sprintf("timestamp(%d) with time zone", typmod)
+	case TSQueryFamily:
+		return "tsquery"
+	case TSVectorFamily:
+		return "tsvector"
 	case TupleFamily:
 		return "record"
 	case UnknownFamily:
@@ -2653,8 +2679,6 @@ var postgresPredefinedTypeIssues = map[string]int{
 	"money":         -1,
 	"path":          21286,
 	"pg_lsn":        -1,
-	"tsquery":       7821,
-	"tsvector":      7821,
 	"txid_snapshot": -1,
 	"xml":           -1,
 }
@@ -348,6 +348,16 @@ func TestTypes(t *testing.T) {
 			Family: JsonFamily, Oid: oid.T_jsonb, Locale: &emptyLocale}}},
 		{Jsonb, MakeScalar(JsonFamily, oid.T_jsonb, 0, 0, emptyLocale)},
 
+		// TSQUERY
+		{TSQuery, &T{InternalType: InternalType{
+			Family: TSQueryFamily, Oid: oid.T_tsquery, Locale: &emptyLocale}}},
+		{TSQuery, MakeScalar(TSQueryFamily, oid.T_tsquery, 0, 0, emptyLocale)},
+
+		// TSVECTOR
+		{TSVector, &T{InternalType: InternalType{
+			Family: TSVectorFamily, Oid: oid.T_tsvector, Locale: &emptyLocale}}},
+		{TSVector, MakeScalar(TSVectorFamily, oid.T_tsvector, 0, 0, emptyLocale)},
+
 		// OID
 		{Oid, &T{InternalType: InternalType{
 			Family: OidFamily, Oid: oid.T_oid, Locale: &emptyLocale}}},
@@ -0,0 +1,373 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// udfMaxCallDepth is the maximum nesting depth of calls to user-defined
+// functions, which protects against unbounded recursion.
+const udfMaxCallDepth = 32
+
+// udfCallDepthKey is the context key under which the current nesting depth of
+// calls to user-defined functions is stored.
+type udfCallDepthKey struct{}
+
+// udfStatement is a statement of the body of a user-defined function, in which
+// references to the function's arguments have been replaced by placeholders.
+type udfStatement struct {
+	sql string
+	// args maps each placeholder of sql to the index of the function argument
+	// it refers to.
+	args []int
+}
+
+// functionLookup adapts the descriptor collection to the
+// tree.ObjectNameExistingResolver interface for the resolution of function
+// names.
+type functionLookup struct {
+	p       *planner
+	mutable bool
+}
+
+var _ tree.ObjectNameExistingResolver = functionLookup{}
+
+// LookupObject implements the tree.ObjectNameExistingResolver interface.
+func (l functionLookup) LookupObject(
+	ctx context.Context, flags tree.ObjectLookupFlags, dbName, scName, obName string,
+) (found bool, objMeta tree.NameResolutionResult, err error) {
+	flags.Required = false
+	flags.AvoidCached = l.p.avoidCachedDescriptors
+	fn := tree.MakeQualifiedFunctionName(dbName, scName, obName)
+	if l.mutable {
+		found, desc, err := l.p.Descriptors().GetMutableFunctionByName(ctx, l.p.txn, &fn, flags)
+		if err != nil || !found {
+			return false, nil, err
+		}
+		return true, desc, nil
+	}
+	found, desc, err := l.p.Descriptors().GetImmutableFunctionByName(ctx, l.p.txn, &fn, flags)
+	if err != nil || !found {
+		return false, nil, err
+	}
+	return true, desc, nil
+}
+
+// lookupFunction resolves the given name to a function descriptor using the
+// given search path. It returns a nil descriptor if the function does not
+// exist.
+func (p *planner) lookupFunction(
+	ctx context.Context, name *tree.UnresolvedObjectName, searchPath sessiondata.SearchPath,
+) (*tree.FunctionName, catalog.FunctionDescriptor, error) {
+	found, prefix, desc, err := tree.ResolveExisting(
+		ctx, name, functionLookup{p: p}, tree.ObjectLookupFlags{}, p.CurrentDatabase(), searchPath,
+	)
+	if err != nil || !found {
+		return nil, nil, err
+	}
+	fn := tree.MakeFunctionNameWithPrefix(prefix, name.Object())
+	return &fn, desc.(catalog.FunctionDescriptor), nil
+}
+
+// ResolveFunction resolves the given name to the definition of a user-defined
+// function. It returns nil if there is no user-defined function with that
+// name, and an error if the current user does not have the EXECUTE privilege
+// on the function.
+func (p *planner) ResolveFunction(
+	ctx context.Context, name *tree.UnresolvedName, searchPath sessiondata.SearchPath,
+) (*tree.FunctionDefinition, error) {
+	if name.Star || !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.UserDefinedFunctions) {
+		return nil, nil
+	}
+	un, err := name.ToUnresolvedObjectName(tree.NoAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	_, desc, err := p.lookupFunction(ctx, un, searchPath)
+	if err != nil || desc == nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, desc, privilege.EXECUTE); err != nil {
+		return nil, err
+	}
+	return makeUDFDefinition(desc)
+}
+
+// makeUDFDefinition builds the definition of the given user-defined function.
+// The function is evaluated by running the statements of its body through
+// the internal executor; single-expression bodies of immutable functions can
+// additionally be inlined by the optimizer.
+func makeUDFDefinition(desc catalog.FunctionDescriptor) (*tree.FunctionDefinition, error) {
+	fd := desc.FuncDesc()
+	stmts, err := parser.Parse(fd.Body)
+	if err != nil {
+		return nil, err
+	}
+	body := make([]udfStatement, len(stmts))
+	for i := range stmts {
+		stmt, args, err := replaceUDFArgs(stmts[i].AST, fd.Args, true /* renumber */)
+		if err != nil {
+			return nil, err
+		}
+		body[i] = udfStatement{sql: tree.AsStringWithFlags(stmt, tree.FmtParsable), args: args}
+	}
+
+	volatility := desc.GetVolatility()
+	calledOnNullInput := fd.NullInputBehavior == descpb.FunctionDescriptor_CALLED_ON_NULL_INPUT
+	var inlineExpr tree.Expr
+	if len(stmts) == 1 && volatility <= tree.VolatilityImmutable && calledOnNullInput {
+		stmt, _, err := replaceUDFArgs(stmts[0].AST, fd.Args, false /* renumber */)
+		if err != nil {
+			return nil, err
+		}
+		inlineExpr = udfInlineExpr(stmt)
+	}
+
+	argTypes := make(tree.ArgTypes, len(fd.Args))
+	for i := range fd.Args {
+		argTypes[i].Name = fd.Args[i].Name
+		argTypes[i].Typ = fd.Args[i].Type
+	}
+	name := fd.Name
+	retType := fd.ReturnType
+	return tree.NewUDFFunctionDefinition(
+		name,
+		&tree.FunctionProperties{
+			Category:         "User-defined",
+			NullableArgs:     calledOnNullInput,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types:      argTypes,
+			ReturnType: tree.FixedReturnType(retType),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return evalUDF(evalCtx, name, body, retType, args)
+			},
+			Volatility: volatility,
+			UDF: &tree.UDFDefinition{
+				ID:         uint32(fd.ID),
+				Version:    uint64(fd.Version),
+				InlineExpr: inlineExpr,
+			},
+		},
+	), nil
+}
+
+// replaceUDFArgs replaces the references to the arguments of a user-defined
+// function in the given statement of its body with placeholders, cast to the
+// type of the argument. Arguments can be referenced positionally ($1, $2, ...)
+// or by name; a named argument shadows any column with the same name.
+//
+// If renumber is set, the placeholders are numbered in order of their first
+// appearance in the statement, and the returned slice maps them back to the
+// index of the argument they refer to. Otherwise, placeholder i refers to
+// argument i.
+func replaceUDFArgs(
+	stmt tree.Statement, args []descpb.FunctionDescriptor_Argument, renumber bool,
+) (tree.Statement, []int, error) {
+	var argIdxs []int
+	placeholders := make(map[int]tree.PlaceholderIdx)
+	ref := func(argIdx int) tree.Expr {
+		idx := tree.PlaceholderIdx(argIdx)
+		if renumber {
+			var ok bool
+			if idx, ok = placeholders[argIdx]; !ok {
+				idx = tree.PlaceholderIdx(len(argIdxs))
+				placeholders[argIdx] = idx
+				argIdxs = append(argIdxs, argIdx)
+			}
+		}
+		return &tree.CastExpr{
+			Expr:       &tree.Placeholder{Idx: idx},
+			Type:       args[argIdx].Type,
+			SyntaxMode: tree.CastShort,
+		}
+	}
+	stmt, err := tree.SimpleStmtVisit(stmt, func(expr tree.Expr) (bool, tree.Expr, error) {
+		switch t := expr.(type) {
+		case *tree.Placeholder:
+			if int(t.Idx) >= len(args) {
+				return false, nil, pgerror.Newf(pgcode.UndefinedParameter,
+					"there is no parameter $%d", t.Idx+1)
+			}
+			return false, ref(int(t.Idx)), nil
+		case *tree.UnresolvedName:
+			if t.NumParts == 1 && !t.Star {
+				for i := range args {
+					if args[i].Name != "" && args[i].Name == t.Parts[0] {
+						return false, ref(i), nil
+					}
+				}
+			}
+		}
+		return true, expr, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return stmt, argIdxs, nil
+}
+
+// errNotInlinable is used to stop the walk of an expression which cannot be
+// inlined.
+var errNotInlinable = errors.New("expression cannot be inlined")
+
+// udfInlineExpr returns the expression of a body of the form SELECT <expr>,
+// where <expr> does not reference any column and contains no subquery,
+// aggregate, window or set-returning function, or call to another
+// user-defined function. It returns nil for any other body.
+func udfInlineExpr(stmt tree.Statement) tree.Expr {
+	sel, ok := stmt.(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return nil
+	}
+	sc, ok := sel.Select.(*tree.SelectClause)
+	if !ok || sc.Distinct || sc.DistinctOn != nil || sc.TableSelect || len(sc.Exprs) != 1 ||
+		len(sc.From.Tables) != 0 || sc.From.AsOf.Expr != nil || sc.Where != nil ||
+		sc.GroupBy != nil || sc.Having != nil || sc.Window != nil {
+		return nil
+	}
+	expr := sc.Exprs[0].Expr
+	if _, err := tree.SimpleVisit(expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		switch t := expr.(type) {
+		case *tree.Subquery, *tree.UnresolvedName, *tree.AllColumnsSelector, *tree.TupleStar,
+			*tree.ColumnItem:
+			return false, nil, errNotInlinable
+		case *tree.FuncExpr:
+			if t.WindowDef != nil {
+				return false, nil, errNotInlinable
+			}
+			// Only built-in functions are resolved here.
+			def, err := t.Func.Resolve(context.TODO(), sessiondata.SearchPath{}, nil /* resolver */)
+			if err != nil || def.Class != tree.NormalClass {
+				return false, nil, errNotInlinable
+			}
+		}
+		return true, expr, nil
+	}); err != nil {
+		return nil
+	}
+	return expr
+}
+
+// evalUDF evaluates a call to a user-defined function by running the
+// statements of its body through the internal executor, within the
+// transaction and as the user of the calling statement. The result of the
+// function is the first column of the first row returned by the last
+// statement, or NULL if it returns no rows.
+func evalUDF(
+	evalCtx *tree.EvalContext, name string, body []udfStatement, retType *types.T, args tree.Datums,
+) (tree.Datum, error) {
+	ctx := evalCtx.Ctx()
+	depth, _ := ctx.Value(udfCallDepthKey{}).(int)
+	if depth >= udfMaxCallDepth {
+		return nil, pgerror.Newf(pgcode.StatementTooComplex,
+			"user-defined function %q exceeded the maximum call depth of %d", name, udfMaxCallDepth)
+	}
+	ctx = context.WithValue(ctx, udfCallDepthKey{}, depth+1)
+
+	ie, ok := evalCtx.InternalExecutor.(*InternalExecutor)
+	if !ok {
+		return nil, errors.AssertionFailedf(
+			"cannot evaluate user-defined function %q without an internal executor", name)
+	}
+	override := sessiondata.InternalExecutorOverride{
+		User:                     evalCtx.SessionData.User(),
+		Database:                 evalCtx.SessionData.Database,
+		SearchPath:               &evalCtx.SessionData.SearchPath,
+		DatabaseIDToTempSchemaID: evalCtx.SessionData.DatabaseIDToTempSchemaID,
+	}
+	opName := "udf-" + name
+	for i := range body {
+		qargs := make([]interface{}, len(body[i].args))
+		for j, argIdx := range body[i].args {
+			qargs[j] = args[argIdx]
+		}
+		if i < len(body)-1 {
+			if _, err := ie.ExecEx(ctx, opName, evalCtx.Txn, override, body[i].sql, qargs...); err != nil {
+				return nil, flattenUDFError(err)
+			}
+			continue
+		}
+		res, err := evalUDFResult(ctx, ie, evalCtx, opName, override, body[i].sql, qargs, retType)
+		if err != nil {
+			return nil, flattenUDFError(err)
+		}
+		return res, nil
+	}
+	return tree.DNull, nil
+}
+
+// flattenUDFError flattens an error returned by a statement of the body of a
+// user-defined function into a new error with the same code, message, hint
+// and detail. The errors returned by the internal executor are wrapped several
+// times, and the cost of formatting them grows exponentially with the number
+// of nested calls to user-defined functions.
+func flattenUDFError(err error) error {
+	pgErr := pgerror.Flatten(err)
+	newErr := pgerror.New(pgcode.MakeCode(pgErr.Code), pgErr.Message)
+	if pgErr.Detail != "" {
+		newErr = errors.WithDetail(newErr, pgErr.Detail)
+	}
+	if pgErr.Hint != "" {
+		newErr = errors.WithHint(newErr, pgErr.Hint)
+	}
+	return newErr
+}
+
+// evalUDFResult runs the last statement of the body of a user-defined
+// function and casts its result to the return type of the function.
+func evalUDFResult(
+	ctx context.Context,
+	ie *InternalExecutor,
+	evalCtx *tree.EvalContext,
+	opName string,
+	override sessiondata.InternalExecutorOverride,
+	stmt string,
+	qargs []interface{},
+	retType *types.T,
+) (_ tree.Datum, retErr error) {
+	it, err := ie.QueryIteratorEx(ctx, opName, evalCtx.Txn, override, stmt, qargs...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+	ok, err := it.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(it.Types()) != 1 {
+		return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"return type mismatch in function declared to return %s: "+
+				"final statement must return exactly one column", retType.SQLString())
+	}
+	if !ok {
+		return tree.DNull, nil
+	}
+	res := it.Cur()[0]
+	if res == tree.DNull || res.ResolvedType().Identical(retType) {
+		return res, nil
+	}
+	return tree.PerformCast(evalCtx, res, retType)
+}
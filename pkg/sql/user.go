@@ -14,6 +14,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
@@ -183,6 +184,47 @@ func retrieveUserAndPassword(
 	return exists, canLogin, hashedPassword, validUntil, err
 }
 
+// MaybeUpgradeStoredPasswordHash replaces the stored hash of the password
+// of a user by a hash using the method configured by
+// server.user_login.password_encryption, if it differs from the method of
+// the current hash. It is called after a successful authentication with the
+// cleartext password. Failures are logged but otherwise ignored, since they
+// must not prevent the user from logging in.
+func MaybeUpgradeStoredPasswordHash(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	username security.SQLUsername,
+	cleartext string,
+	currentHash []byte,
+) {
+	st := execCfg.Settings
+	if !st.Version.IsActive(ctx, clusterversion.SCRAMAuthentication) {
+		return
+	}
+	method := security.GetConfiguredPasswordHashMethod(&st.SV)
+	if current := security.GetPasswordHashMethod(currentHash); current != security.HashBCrypt ||
+		method == current {
+		return
+	}
+	newHash, err := security.HashPassword(method, cleartext)
+	if err != nil {
+		log.Warningf(ctx, "unable to hash the password of user %q: %v", username, err)
+		return
+	}
+	// The condition on the current hash ensures that a password changed
+	// concurrently is not overwritten.
+	if _, err := execCfg.InternalExecutor.ExecEx(
+		ctx, "upgrade-password-hash", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`UPDATE system.public.users SET "hashedPassword" = $3 WHERE username = $1 AND "hashedPassword" = $2`,
+		username, currentHash, newHash,
+	); err != nil {
+		log.Warningf(ctx, "unable to upgrade the password hash of user %q: %v", username, err)
+		return
+	}
+	log.Infof(ctx, "upgraded the password hash of user %q to %s", username, method)
+}
+
 var userLoginTimeout = settings.RegisterDurationSetting(
 	"server.user_login.timeout",
 	"timeout after which client authentication times out if some system range is unavailable (0 = no timeout)",
@@ -33,8 +33,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
 
@@ -345,16 +348,24 @@ var varGen = map[string]sessionVar{
 	`default_transaction_isolation`: {
 		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
 			switch strings.ToUpper(s) {
-			case `READ UNCOMMITTED`, `READ COMMITTED`, `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`, `DEFAULT`:
-				// Do nothing. All transactions execute with serializable isolation.
+			case `READ UNCOMMITTED`, `READ COMMITTED`:
+				m.SetDefaultTransactionIsolationLevel(tree.ReadCommittedIsolation)
+			case `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`:
+				m.SetDefaultTransactionIsolationLevel(tree.SerializableIsolation)
+			case `DEFAULT`:
+				m.SetDefaultTransactionIsolationLevel(tree.UnspecifiedIsolation)
 			default:
-				return newVarValueError(`default_transaction_isolation`, s, "serializable")
+				return newVarValueError(`default_transaction_isolation`, s, "serializable", "read committed")
 			}
 
 			return nil
 		},
 		Get: func(evalCtx *extendedEvalContext) string {
-			return "serializable"
+			level := tree.IsolationLevel(evalCtx.SessionData.DefaultTxnIsolationLevel)
+			if level == tree.UnspecifiedIsolation {
+				level = tree.SerializableIsolation
+			}
+			return strings.ToLower(level.String())
 		},
 		GlobalDefault: func(sv *settings.Values) string { return "default" },
 	},
@@ -1017,14 +1028,18 @@ var varGen = map[string]sessionVar{
 	// See https://github.com/postgres/postgres/blob/REL_10_STABLE/src/backend/utils/misc/guc.c#L3401-L3409
 	`transaction_isolation`: {
 		Get: func(evalCtx *extendedEvalContext) string {
+			if evalCtx.Txn != nil && evalCtx.Txn.IsolationLevel() == enginepb.READ_COMMITTED {
+				return "read committed"
+			}
 			return "serializable"
 		},
 		RuntimeSet: func(_ context.Context, evalCtx *extendedEvalContext, s string) error {
-			_, ok := tree.IsolationLevelMap[s]
+			level, ok := tree.IsolationLevelMap[s]
 			if !ok {
-				return newVarValueError(`transaction_isolation`, s, "serializable")
+				return newVarValueError(`transaction_isolation`, s, "serializable", "read committed")
 			}
-			return nil
+			return evalCtx.TxnModesSetter.setTransactionModes(
+				tree.TransactionModes{Isolation: level}, hlc.Timestamp{} /* asOfTs */)
 		},
 		GlobalDefault: func(_ *settings.Values) string { return "serializable" },
 	},
@@ -1076,6 +1091,27 @@ var varGen = map[string]sessionVar{
 		// Setting is done by the SetTracing statement.
 	},
 
+	// CockroachDB extension. The W3C trace context of a span of the client (see
+	// https://www.w3.org/TR/trace-context/#traceparent-header), which becomes
+	// the parent of the spans of the transactions of the session. This allows
+	// the spans exported to an OpenTelemetry collector to be part of the traces
+	// of the client.
+	`traceparent`: {
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.TraceParent
+		},
+		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
+			if s != "" {
+				if _, err := tracing.ParseTraceParent(s); err != nil {
+					return wrapSetVarError("traceparent", s, "%v", err)
+				}
+			}
+			m.SetTraceParent(s)
+			return nil
+		},
+		GlobalDefault: func(_ *settings.Values) string { return "" },
+	},
+
 	// CockroachDB extension.
 	`allow_prepare_as_opt_plan`: {
 		Hidden: true,
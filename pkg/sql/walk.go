@@ -352,6 +352,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&cancelQueriesNode{}):              "cancel queries",
 	reflect.TypeOf(&cancelSessionsNode{}):             "cancel sessions",
 	reflect.TypeOf(&changePrivilegesNode{}):           "change privileges",
+	reflect.TypeOf(&closeCursorNode{}):                "close cursor",
 	reflect.TypeOf(&commentOnColumnNode{}):            "comment on column",
 	reflect.TypeOf(&commentOnDatabaseNode{}):          "comment on database",
 	reflect.TypeOf(&commentOnIndexNode{}):             "comment on index",
@@ -360,6 +361,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlSchedulesNode{}):           "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):             "create database",
 	reflect.TypeOf(&createExtensionNode{}):            "create extension",
+	reflect.TypeOf(&createFunctionNode{}):             "create function",
 	reflect.TypeOf(&createIndexNode{}):                "create index",
 	reflect.TypeOf(&createSequenceNode{}):             "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):               "create schema",
@@ -368,11 +370,13 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&createTypeNode{}):                 "create type",
 	reflect.TypeOf(&CreateRoleNode{}):                 "create user/role",
 	reflect.TypeOf(&createViewNode{}):                 "create view",
+	reflect.TypeOf(&declareCursorNode{}):              "declare cursor",
 	reflect.TypeOf(&delayedNode{}):                    "virtual table",
 	reflect.TypeOf(&deleteNode{}):                     "delete",
 	reflect.TypeOf(&deleteRangeNode{}):                "delete range",
 	reflect.TypeOf(&distinctNode{}):                   "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):               "drop database",
+	reflect.TypeOf(&dropFunctionNode{}):               "drop function",
 	reflect.TypeOf(&dropIndexNode{}):                  "drop index",
 	reflect.TypeOf(&dropSequenceNode{}):               "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):                 "drop schema",
@@ -385,6 +389,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&explainVecNode{}):                 "explain vectorized",
 	reflect.TypeOf(&explainDDLNode{}):                 "explain ddl",
 	reflect.TypeOf(&exportNode{}):                     "export",
+	reflect.TypeOf(&fetchNode{}):                      "fetch",
 	reflect.TypeOf(&filterNode{}):                     "filter",
 	reflect.TypeOf(&GrantRoleNode{}):                  "grant role",
 	reflect.TypeOf(&groupNode{}):                      "group",
@@ -396,8 +401,11 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&invertedJoinNode{}):               "inverted join",
 	reflect.TypeOf(&joinNode{}):                       "join",
 	reflect.TypeOf(&limitNode{}):                      "limit",
+	reflect.TypeOf(&listenNode{}):                     "listen",
 	reflect.TypeOf(&lookupJoinNode{}):                 "lookup join",
 	reflect.TypeOf(&max1RowNode{}):                    "max1row",
+	reflect.TypeOf(&moveNode{}):                       "move",
+	reflect.TypeOf(&notifyNode{}):                     "notify",
 	reflect.TypeOf(&ordinalityNode{}):                 "ordinality",
 	reflect.TypeOf(&projectSetNode{}):                 "project set",
 	reflect.TypeOf(&reassignOwnedByNode{}):            "reassign owned by",
@@ -422,6 +430,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&sequenceSelectNode{}):             "sequence select",
 	reflect.TypeOf(&serializeNode{}):                  "run",
 	reflect.TypeOf(&setClusterSettingNode{}):          "set cluster setting",
+	reflect.TypeOf(&setConstraintsNode{}):             "set constraints",
 	reflect.TypeOf(&setVarNode{}):                     "set",
 	reflect.TypeOf(&setZoneConfigNode{}):              "configure zone",
 	reflect.TypeOf(&showFingerprintsNode{}):           "show fingerprints",
@@ -435,6 +444,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&truncateNode{}):                   "truncate",
 	reflect.TypeOf(&unaryNode{}):                      "emptyrow",
 	reflect.TypeOf(&unionNode{}):                      "union",
+	reflect.TypeOf(&unlistenNode{}):                   "unlisten",
 	reflect.TypeOf(&updateNode{}):                     "update",
 	reflect.TypeOf(&upsertNode{}):                     "upsert",
 	reflect.TypeOf(&valuesNode{}):                     "values",
@@ -0,0 +1,125 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package cloudimpltests
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyfile(t *testing.T, path string, keys ...[]byte) {
+	var buf bytes.Buffer
+	buf.WriteString("# test keys\n")
+	for _, k := range keys {
+		fmt.Fprintln(&buf, base64.StdEncoding.EncodeToString(k))
+	}
+	require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0600))
+}
+
+func TestEncryptDecryptKeyfile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "backup.keys")
+	env := testKMSEnv{cluster.NoSettings, &base.ExternalIODirConfig{}}
+	uri := "keyfile://" + path
+
+	writeKeyfile(t, path, bytes.Repeat([]byte{1}, 32))
+	testEncryptDecrypt(t, uri, env)
+
+	t.Run("rotation", func(t *testing.T) {
+		ctx := context.Background()
+		writeKeyfile(t, path, bytes.Repeat([]byte{1}, 32))
+		oldKMS, err := cloud.KMSFromURI(uri, &env)
+		require.NoError(t, err)
+		oldCiphertext, err := oldKMS.Encrypt(ctx, []byte("old data key"))
+		require.NoError(t, err)
+		oldID, err := oldKMS.MasterKeyID()
+		require.NoError(t, err)
+
+		// Rotate the master key: the old ciphertext must remain readable, and
+		// the new one must be produced under the new version.
+		writeKeyfile(t, path, bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16))
+		newKMS, err := cloud.KMSFromURI(uri, &env)
+		require.NoError(t, err)
+		newID, err := newKMS.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, oldID, newID)
+
+		plaintext, err := newKMS.Decrypt(ctx, oldCiphertext)
+		require.NoError(t, err)
+		require.Equal(t, "old data key", string(plaintext))
+
+		newCiphertext, err := newKMS.Encrypt(ctx, []byte("new data key"))
+		require.NoError(t, err)
+		_, err = oldKMS.Decrypt(ctx, newCiphertext)
+		require.True(t, testutils.IsError(err, "version 2 of the master key not found"), "%v", err)
+
+		// Tampering with the ciphertext is detected.
+		newCiphertext[len(newCiphertext)-1] ^= 1
+		_, err = newKMS.Decrypt(ctx, newCiphertext)
+		require.True(t, testutils.IsError(err, "decryption failed"), "%v", err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			contents string
+			uri      string
+			env      testKMSEnv
+			err      string
+		}{
+			{name: "no keys", contents: "# nothing\n\n", err: "no key found"},
+			{name: "bad base64", contents: "not base64!\n", err: "invalid base64 key on line 1"},
+			{name: "bad size", contents: base64.StdEncoding.EncodeToString([]byte("short")),
+				err: "expected 16, 24 or 32 bytes, got 5"},
+			{name: "host", uri: "keyfile://host" + path, err: "cannot specify a host"},
+			{name: "missing file", uri: "keyfile://" + path + ".missing", err: "opening keyfile kms key file"},
+			{name: "implicit credentials disabled",
+				env: testKMSEnv{cluster.NoSettings, &base.ExternalIODirConfig{DisableImplicitCredentials: true}},
+				err: "keyfile kms disallowed"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				if tc.contents != "" {
+					require.NoError(t, ioutil.WriteFile(path, []byte(tc.contents), 0600))
+				} else {
+					writeKeyfile(t, path, bytes.Repeat([]byte{1}, 32))
+				}
+				if tc.uri == "" {
+					tc.uri = uri
+				}
+				if tc.env.externalIOConfig == nil {
+					tc.env = env
+				}
+				_, err := cloud.KMSFromURI(tc.uri, &tc.env)
+				require.True(t, testutils.IsError(err, tc.err), "%v", err)
+				// The keys must not leak into the errors.
+				require.False(t, strings.Contains(err.Error(), "AQEB"), "%v", err)
+			})
+		}
+	})
+
+	require.NoError(t, os.Remove(path))
+}
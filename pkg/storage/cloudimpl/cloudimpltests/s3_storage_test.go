@@ -12,7 +12,10 @@ package cloudimpltests
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
@@ -29,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -306,3 +310,65 @@ func TestAntagonisticS3Read(t *testing.T) {
 
 	testAntagonisticRead(t, conf)
 }
+
+func TestS3PathStyleAndCABundleParams(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conf := &roachpb.ExternalStorage_S3{
+		Bucket:       "bucket",
+		Prefix:       "backup-test",
+		AccessKey:    "key",
+		Secret:       "secret",
+		UsePathStyle: true,
+		CABundle:     "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----\n",
+	}
+	parsed, err := cloudimpl.ExternalStorageConfFromURI(
+		cloudimpl.S3URI(conf.Bucket, conf.Prefix, conf), security.RootUserName())
+	require.NoError(t, err)
+	require.Equal(t, conf, parsed.S3Config)
+
+	_, err = cloudimpl.ExternalStorageConfFromURI(
+		"s3://bucket/path?AWS_USE_PATH_STYLE=maybe", security.RootUserName())
+	require.True(t, testutils.IsError(err, "parsing AWS_USE_PATH_STYLE"), "%+v", err)
+}
+
+// TestS3CustomCABundle checks that a custom endpoint is trusted when its
+// certificate is signed by a CA in the CA bundle of the URI, and that its
+// requests address the bucket in the path.
+func TestS3CustomCABundle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var mu syncutil.Mutex
+	var requestPaths []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requestPaths = append(requestPaths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Length", "3")
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	user := security.RootUserName()
+	conf := &roachpb.ExternalStorage_S3{
+		Bucket:    "bucket",
+		Prefix:    "backup-test",
+		AccessKey: "key",
+		Secret:    "secret",
+		Endpoint:  srv.URL,
+		Region:    "us-east-1",
+		CABundle: string(pem.EncodeToMemory(&pem.Block{
+			Type: "CERTIFICATE", Bytes: srv.Certificate().Raw,
+		})),
+	}
+
+	s := storeFromURI(ctx, t, cloudimpl.S3URI(conf.Bucket, conf.Prefix, conf), nil, user, nil, nil)
+	defer s.Close()
+	size, err := s.Size(ctx, "file")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), size)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"/bucket/backup-test/file"}, requestPaths)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpltests
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl/sftp/sftptest"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutSFTP(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+	srv := sftptest.NewServer(t, dir)
+	defer srv.Close()
+
+	ctx := context.Background()
+	user := security.RootUserName()
+	conf := &roachpb.ExternalStorage_SFTP{
+		Host:       srv.Addr,
+		User:       srv.User,
+		PrivateKey: srv.PrivateKey,
+		HostKey:    srv.HostKey,
+	}
+
+	t.Run("export", func(t *testing.T) {
+		testExportStore(t, cloudimpl.SFTPURI("/backup-test", conf), false, user, nil, nil)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		testListFiles(t, cloudimpl.SFTPURI("/listing-test/basepath", conf), user, nil, nil)
+	})
+
+	t.Run("wrong-host-key", func(t *testing.T) {
+		otherSrv := sftptest.NewServer(t, dir)
+		defer otherSrv.Close()
+		wrongConf := *conf
+		wrongConf.HostKey = otherSrv.HostKey
+
+		s := storeFromURI(ctx, t, cloudimpl.SFTPURI("/backup-test", &wrongConf), nil, user, nil, nil)
+		defer s.Close()
+		_, err := s.Size(ctx, "file")
+		require.True(t, testutils.IsError(err, "ssh: handshake failed"), "%+v", err)
+	})
+
+	t.Run("wrong-private-key", func(t *testing.T) {
+		otherSrv := sftptest.NewServer(t, dir)
+		defer otherSrv.Close()
+		wrongConf := *conf
+		wrongConf.PrivateKey = otherSrv.PrivateKey
+
+		s := storeFromURI(ctx, t, cloudimpl.SFTPURI("/backup-test", &wrongConf), nil, user, nil, nil)
+		defer s.Close()
+		_, err := s.Size(ctx, "file")
+		require.True(t, testutils.IsError(err, "unable to authenticate"), "%+v", err)
+	})
+
+	t.Run("unresponsive-server", func(t *testing.T) {
+		// The server accepts connections but never completes the SSH handshake.
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = ln.Close() }()
+		connCh := make(chan net.Conn, 1)
+		go func() {
+			if conn, err := ln.Accept(); err == nil {
+				connCh <- conn
+			}
+		}()
+		unresponsiveConf := *conf
+		unresponsiveConf.Host = ln.Addr().String()
+
+		s := storeFromURI(ctx, t, cloudimpl.SFTPURI("/backup-test", &unresponsiveConf), nil, user, nil, nil)
+		defer s.Close()
+		timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err = s.Size(timeoutCtx, "file")
+		require.True(t, testutils.IsError(err, "i/o timeout"), "%+v", err)
+		_ = (<-connCh).Close()
+	})
+
+	t.Run("missing-params", func(t *testing.T) {
+		for _, tc := range []struct {
+			uri string
+			err string
+		}{
+			{"sftp://host/path", "sftp uri missing user"},
+			{"sftp://roach@host/path", `sftp uri missing "SFTP_HOST_KEY" parameter`},
+			{"sftp://roach@host/path?SFTP_HOST_KEY=key", `sftp uri missing "SFTP_PRIVATE_KEY" parameter`},
+			{"sftp://roach@host/path?SFTP_HOST_KEY=key&SFTP_PRIVATE_KEY=%25", "decoding SFTP_PRIVATE_KEY"},
+		} {
+			_, err := cloudimpl.ExternalStorageConfFromURI(tc.uri, user)
+			require.True(t, testutils.IsError(err, tc.err), "%s: %+v", tc.uri, err)
+		}
+	})
+
+	t.Run("disable-http", func(t *testing.T) {
+		_, err := cloudimpl.MakeExternalStorage(ctx, roachpb.ExternalStorage{
+			Provider:   roachpb.ExternalStorageProvider_SFTP,
+			SFTPConfig: conf,
+		}, base.ExternalIODirConfig{DisableHTTP: true}, testSettings, nil, nil, nil)
+		require.EqualError(t, err, "sftp storage disallowed due to --external-io-disable-http flag")
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		sanitized, err := cloudimpl.SanitizeExternalStorageURI(cloudimpl.SFTPURI("/backup-test", conf), nil)
+		require.NoError(t, err)
+		require.Contains(t, sanitized, cloudimpl.SFTPPrivateKeyParam+"=redacted")
+		require.NotContains(t, sanitized, base64.StdEncoding.EncodeToString([]byte(srv.PrivateKey)))
+	})
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package cloudimpltests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransit is a stand-in for the transit secrets engine of Vault. Its
+// "encryption" tags the plaintext with the key version, which is all the
+// client can observe.
+type fakeTransit struct {
+	token string
+
+	mu struct {
+		syncutil.Mutex
+		// versions is the latest version of each key.
+		versions map[string]int
+	}
+}
+
+func (f *fakeTransit) rotate(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.versions[key]++
+}
+
+func (f *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fail := func(code int, msg string) {
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {msg}})
+	}
+	if r.Header.Get("X-Vault-Token") != f.token {
+		fail(http.StatusForbidden, "permission denied")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/"), "/")
+	if r.Method != "POST" || len(parts) != 3 || parts[0] != "transit" {
+		fail(http.StatusNotFound, "no handler for route")
+		return
+	}
+	op, key := parts[1], parts[2]
+	f.mu.Lock()
+	latest := f.mu.versions[key]
+	f.mu.Unlock()
+	if latest == 0 {
+		fail(http.StatusBadRequest, "encryption key not found")
+		return
+	}
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	var data map[string]string
+	switch op {
+	case "encrypt":
+		data = map[string]string{
+			"ciphertext": fmt.Sprintf("vault:v%d:%s", latest, req["plaintext"]),
+		}
+	case "decrypt":
+		fields := strings.SplitN(req["ciphertext"], ":", 3)
+		if len(fields) != 3 || fields[0] != "vault" {
+			fail(http.StatusBadRequest, "invalid ciphertext")
+			return
+		}
+		v, err := strconv.Atoi(strings.TrimPrefix(fields[1], "v"))
+		if err != nil || v < 1 || v > latest {
+			fail(http.StatusBadRequest, "invalid key version")
+			return
+		}
+		data = map[string]string{"plaintext": fields[2]}
+	default:
+		fail(http.StatusNotFound, "no handler for route")
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func TestEncryptDecryptVault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	transit := &fakeTransit{token: "s.secret"}
+	transit.mu.versions = map[string]int{"backups": 1}
+	srv := httptest.NewServer(transit)
+	defer srv.Close()
+
+	settings := cluster.MakeTestingClusterSettings()
+	env := testKMSEnv{settings, &base.ExternalIODirConfig{}}
+	q := make(url.Values)
+	q.Add(cloudimpl.VaultAddrParam, srv.URL)
+	q.Add(cloudimpl.VaultTokenParam, transit.token)
+	uri := fmt.Sprintf("vault:///backups?%s", q.Encode())
+
+	testEncryptDecrypt(t, uri, env)
+
+	t.Run("rotation", func(t *testing.T) {
+		ctx := context.Background()
+		kms, err := cloud.KMSFromURI(uri, &env)
+		require.NoError(t, err)
+		defer func() { _ = kms.Close() }()
+
+		oldCiphertext, err := kms.Encrypt(ctx, []byte("old data key"))
+		require.NoError(t, err)
+		transit.rotate("backups")
+		newCiphertext, err := kms.Encrypt(ctx, []byte("new data key"))
+		require.NoError(t, err)
+		require.NotEqual(t, oldCiphertext[:len("vault:v1")], newCiphertext[:len("vault:v1")])
+
+		plaintext, err := kms.Decrypt(ctx, oldCiphertext)
+		require.NoError(t, err)
+		require.Equal(t, "old data key", string(plaintext))
+		plaintext, err = kms.Decrypt(ctx, newCiphertext)
+		require.NoError(t, err)
+		require.Equal(t, "new data key", string(plaintext))
+
+		id, err := kms.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, "backups", id)
+	})
+
+	t.Run("vault errors", func(t *testing.T) {
+		ctx := context.Background()
+		badToken := make(url.Values)
+		badToken.Add(cloudimpl.VaultAddrParam, srv.URL)
+		badToken.Add(cloudimpl.VaultTokenParam, "wrong")
+		kms, err := cloud.KMSFromURI("vault:///backups?"+badToken.Encode(), &env)
+		require.NoError(t, err)
+		_, err = kms.Encrypt(ctx, []byte("data"))
+		require.True(t, testutils.IsError(err, "403 Forbidden: permission denied"), "%v", err)
+
+		kms, err = cloud.KMSFromURI(fmt.Sprintf("vault:///missing?%s", q.Encode()), &env)
+		require.NoError(t, err)
+		_, err = kms.Encrypt(ctx, []byte("data"))
+		require.True(t, testutils.IsError(err, "encryption key not found"), "%v", err)
+	})
+
+	t.Run("uri errors", func(t *testing.T) {
+		noToken := make(url.Values)
+		noToken.Add(cloudimpl.VaultAddrParam, srv.URL)
+		implicit := make(url.Values)
+		implicit.Add(cloudimpl.VaultAddrParam, srv.URL)
+		implicit.Add(cloudimpl.AuthParam, cloudimpl.AuthParamImplicit)
+		for _, tc := range []struct {
+			uri   string
+			ioCfg base.ExternalIODirConfig
+			err   string
+		}{
+			{uri: "vault:///backups?" + noToken.Encode(),
+				err: "AUTH is set to 'specified', but VAULT_TOKEN is not set"},
+			{uri: "vault:///backups?VAULT_TOKEN=t",
+				err: "VAULT_ADDR must be set"},
+			{uri: "vault:///backups?VAULT_TOKEN=t&VAULT_ADDR=ftp://vault",
+				err: "expected an http or https URL"},
+			{uri: "vault:///?" + q.Encode(),
+				err: "invalid vault key name"},
+			{uri: "vault:///backups?" + q.Encode(), ioCfg: base.ExternalIODirConfig{DisableHTTP: true},
+				err: "custom endpoints disallowed"},
+			{uri: "vault:///backups?" + implicit.Encode(),
+				ioCfg: base.ExternalIODirConfig{DisableImplicitCredentials: true},
+				err:   "implicit credentials disallowed"},
+		} {
+			t.Run("", func(t *testing.T) {
+				ioCfg := tc.ioCfg
+				_, err := cloud.KMSFromURI(tc.uri, &testKMSEnv{settings, &ioCfg})
+				require.True(t, testutils.IsError(err, tc.err), "%v", err)
+			})
+		}
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		redacted, err := cloudimpl.RedactKMSURI(uri)
+		require.NoError(t, err)
+		require.NotContains(t, redacted, transit.token)
+		require.NotContains(t, redacted, "backups")
+	})
+}
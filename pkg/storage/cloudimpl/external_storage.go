@@ -12,6 +12,7 @@ package cloudimpl
 
 import (
 	"context"
+	"encoding/base64"
 	"io"
 	"net/url"
 	"path"
@@ -58,6 +59,16 @@ const (
 	// S3RegionParam is the query parameter for the 'endpoint' in an S3 URI.
 	S3RegionParam = "AWS_REGION"
 
+	// S3UsePathStyleParam is the query parameter in an S3 URI which, when set to
+	// true, forces path-style addressing of the bucket. Path-style addressing is
+	// always used with a custom endpoint.
+	S3UsePathStyleParam = "AWS_USE_PATH_STYLE"
+
+	// S3CABundleParam is the query parameter in an S3 URI for the base64-encoded
+	// PEM bundle of the CA certificates which are trusted, instead of the
+	// system's CAs, when connecting to the endpoint.
+	S3CABundleParam = "AWS_CA_BUNDLE"
+
 	// KMSRegionParam is the query parameter for the 'region' in every KMS URI.
 	KMSRegionParam = "REGION"
 
@@ -70,6 +81,18 @@ const (
 	// in a gs URI.
 	GoogleBillingProjectParam = "GOOGLE_BILLING_PROJECT"
 
+	// VaultAddrParam is the query parameter for the address of the Vault server
+	// in a vault KMS URI.
+	VaultAddrParam = "VAULT_ADDR"
+	// VaultTokenParam is the query parameter for the token in a vault KMS URI.
+	VaultTokenParam = "VAULT_TOKEN"
+	// VaultNamespaceParam is the query parameter for the Vault Enterprise
+	// namespace in a vault KMS URI.
+	VaultNamespaceParam = "VAULT_NAMESPACE"
+	// VaultTransitMountParam is the query parameter for the path at which the
+	// transit secrets engine is mounted in a vault KMS URI.
+	VaultTransitMountParam = "VAULT_TRANSIT_MOUNT"
+
 	// AuthParam is the query parameter for the cluster settings named
 	// key in a URI.
 	AuthParam = "AUTH"
@@ -87,6 +110,13 @@ const (
 	// the Google Application Credentials JSON file.
 	CredentialsParam = "CREDENTIALS"
 
+	// SFTPPrivateKeyParam is the query parameter for the base64-encoded PEM
+	// private key used to authenticate to the server in an sftp URI.
+	SFTPPrivateKeyParam = "SFTP_PRIVATE_KEY"
+	// SFTPHostKeyParam is the query parameter for the public key of the server,
+	// in the authorized_keys format, in an sftp URI.
+	SFTPHostKeyParam = "SFTP_HOST_KEY"
+
 	cloudstoragePrefix = "cloudstorage"
 	cloudstorageGS     = cloudstoragePrefix + ".gs"
 	cloudstorageHTTP   = cloudstoragePrefix + ".http"
@@ -113,6 +143,8 @@ var redactedQueryParams = map[string]struct{}{
 	AWSTempTokenParam:    {},
 	AzureAccountKeyParam: {},
 	CredentialsParam:     {},
+	SFTPPrivateKeyParam:  {},
+	VaultTokenParam:      {},
 }
 
 // ErrListingUnsupported is a marker for indicating listing is unsupported.
@@ -161,6 +193,18 @@ func ExternalStorageConfFromURI(
 		// contain spaces. We can convert any space characters we see to +
 		// characters to recover the original secret.
 		conf.S3Config.Secret = strings.Replace(conf.S3Config.Secret, " ", "+", -1)
+		if usePathStyle := uri.Query().Get(S3UsePathStyleParam); usePathStyle != "" {
+			if conf.S3Config.UsePathStyle, err = strconv.ParseBool(usePathStyle); err != nil {
+				return conf, errors.Wrapf(err, "parsing %s", S3UsePathStyleParam)
+			}
+		}
+		if caBundle := uri.Query().Get(S3CABundleParam); caBundle != "" {
+			pem, err := decodeBase64Param(S3CABundleParam, caBundle)
+			if err != nil {
+				return conf, err
+			}
+			conf.S3Config.CABundle = string(pem)
+		}
 	case "gs":
 		conf.Provider = roachpb.ExternalStorageProvider_GoogleCloud
 		conf.GoogleCloudConfig = &roachpb.ExternalStorage_GCS{
@@ -191,6 +235,33 @@ func ExternalStorageConfFromURI(
 	case "http", "https":
 		conf.Provider = roachpb.ExternalStorageProvider_Http
 		conf.HttpPath.BaseUri = path
+	case "sftp":
+		conf.Provider = roachpb.ExternalStorageProvider_SFTP
+		conf.SFTPConfig = &roachpb.ExternalStorage_SFTP{
+			Host:    uri.Host,
+			User:    uri.User.Username(),
+			Path:    uri.Path,
+			HostKey: uri.Query().Get(SFTPHostKeyParam),
+			/* NB: additions here should also update the SFTPURI() serializer */
+		}
+		if conf.SFTPConfig.Host == "" {
+			return conf, errors.Errorf("sftp uri missing host")
+		}
+		if conf.SFTPConfig.User == "" {
+			return conf, errors.Errorf("sftp uri missing user")
+		}
+		if conf.SFTPConfig.HostKey == "" {
+			return conf, errors.Errorf("sftp uri missing %q parameter", SFTPHostKeyParam)
+		}
+		privateKey := uri.Query().Get(SFTPPrivateKeyParam)
+		if privateKey == "" {
+			return conf, errors.Errorf("sftp uri missing %q parameter", SFTPPrivateKeyParam)
+		}
+		pem, err := decodeBase64Param(SFTPPrivateKeyParam, privateKey)
+		if err != nil {
+			return conf, err
+		}
+		conf.SFTPConfig.PrivateKey = string(pem)
 	case "nodelocal":
 		if uri.Host == "" {
 			return conf, errors.Errorf(
@@ -242,6 +313,18 @@ func ExternalStorageConfFromURI(
 	return conf, nil
 }
 
+// decodeBase64Param decodes the base64-encoded value of a query parameter.
+// Like AWS secrets, base64 values contain + characters which represent spaces
+// when they are not escaped in a query string, so spaces are converted back to
+// + characters before decoding.
+func decodeBase64Param(param, value string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.Replace(value, " ", "+", -1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", param)
+	}
+	return decoded, nil
+}
+
 // ExternalStorageFromURI returns an ExternalStorage for the given URI.
 func ExternalStorageFromURI(
 	ctx context.Context,
@@ -332,6 +415,12 @@ func MakeExternalStorage(
 	case roachpb.ExternalStorageProvider_FileTable:
 		telemetry.Count("external-io.filetable")
 		return makeFileTableStorage(ctx, dest.FileTableConfig, ie, kvDB, settings, conf)
+	case roachpb.ExternalStorageProvider_SFTP:
+		if conf.DisableHTTP {
+			return nil, errors.New("sftp storage disallowed due to --external-io-disable-http flag")
+		}
+		telemetry.Count("external-io.sftp")
+		return makeSFTPStorage(dest.SFTPConfig, settings, conf)
 	}
 	return nil, errors.Errorf("unsupported external destination type: %s", dest.Provider.String())
 }
@@ -366,6 +455,9 @@ func URINeedsGlobExpansion(uri string) bool {
 // server's network, potentially behind a firewall and only a super user should
 // be able to do this.
 //
+// - SFTP: like HTTP, connections are made by the server, in the server's
+// network.
+//
 // - nodelocal: this is the node's shared filesystem and so only a super user
 // should be able to interact with it.
 func AccessIsWithExplicitAuth(path string) (bool, string, error) {
@@ -389,7 +481,7 @@ func AccessIsWithExplicitAuth(path string) (bool, string, error) {
 		// Azure does not support implicit authentication i.e. all credentials have
 		// to be specified as part of the URI.
 		hasExplicitAuth = true
-	case "http", "https", "nodelocal":
+	case "http", "https", "sftp", "nodelocal":
 		hasExplicitAuth = false
 	case "experimental-workload", "workload", "userfile":
 		hasExplicitAuth = true
@@ -0,0 +1,192 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/errors"
+)
+
+const keyfileScheme = "keyfile"
+
+// keyfileMaxSize bounds the size of the key files.
+const keyfileMaxSize = 1 << 20
+
+// keyfileVersionLen is the length of the key version prefixed to the
+// ciphertexts.
+const keyfileVersionLen = 4
+
+// keyfileKMS is a KMS backed by a file of AES keys local to the nodes, meant
+// for air-gapped installations and tests.
+//
+// Every line of the file which is neither empty nor a comment starting with
+// '#' is a base64-encoded 128, 192 or 256-bit key. The Nth key is version N of
+// the master key, and the last one is used to encrypt. The master key is
+// rotated by appending a new key to the file: the ciphertexts are prefixed
+// with the version of the key used to produce them, so the data keys of
+// backups taken under the older versions can be decrypted as long as their
+// lines are kept.
+type keyfileKMS struct {
+	path string
+	// keys[i] is version i+1 of the master key.
+	keys [][]byte
+}
+
+var _ cloud.KMS = &keyfileKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeKeyfileKMS, keyfileScheme)
+}
+
+// MakeKeyfileKMS is the factory method which returns a configured,
+// ready-to-use keyfile KMS object. The URI is of the form
+// keyfile:///<absolute path>, and the file must be present on every node.
+func MakeKeyfileKMS(uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	// The keys are read from the environment of the node, so they are
+	// subject to the same restrictions as implicit credentials.
+	if env.KMSConfig().DisableImplicitCredentials {
+		return nil, errors.New(
+			"keyfile kms disallowed due to --external-io-disable-implicit-credentials flag")
+	}
+	if kmsURI.Host != "" {
+		return nil, errors.Errorf("keyfile kms URI cannot specify a host, got %q", kmsURI.Host)
+	}
+	path := filepath.Clean(kmsURI.Path)
+	if !filepath.IsAbs(path) {
+		return nil, errors.Errorf("keyfile kms requires an absolute path, got %q", kmsURI.Path)
+	}
+	keys, err := readKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &keyfileKMS{path: path, keys: keys}, nil
+}
+
+// readKeyfile reads the versions of the master key from the file at path.
+// The contents of the file are kept out of the errors.
+func readKeyfile(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening keyfile kms key file")
+	}
+	defer f.Close()
+	contents, err := ioutil.ReadAll(io.LimitReader(f, keyfileMaxSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading keyfile kms key file")
+	}
+	if len(contents) > keyfileMaxSize {
+		return nil, errors.Errorf("keyfile kms key file %s is too large", path)
+	}
+
+	var keys [][]byte
+	for i, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, errors.Errorf("invalid base64 key on line %d of %s", i+1, path)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, errors.Errorf(
+				"invalid key on line %d of %s: expected 16, 24 or 32 bytes, got %d",
+				i+1, path, len(key))
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.Errorf("no key found in keyfile kms key file %s", path)
+	}
+	return keys, nil
+}
+
+// MasterKeyID implements the KMS interface. The ID of the master key is the
+// path of the key file, which does not change when the key is rotated.
+func (k *keyfileKMS) MasterKeyID() (string, error) {
+	return k.path, nil
+}
+
+func (k *keyfileKMS) aead(version uint32) (cipher.AEAD, error) {
+	if version == 0 || int(version) > len(k.keys) {
+		return nil, errors.Errorf("version %d of the master key not found in %s", version, k.path)
+	}
+	block, err := aes.NewCipher(k.keys[version-1])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt implements the KMS interface. The data is encrypted with AES-GCM
+// under the latest version of the master key; the ciphertext is made of the
+// version of the key, the nonce and the sealed data. The version is also
+// authenticated as additional data.
+func (k *keyfileKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	version := uint32(len(k.keys))
+	gcm, err := k.aead(version)
+	if err != nil {
+		return nil, err
+	}
+	headerLen := keyfileVersionLen + gcm.NonceSize()
+	out := make([]byte, headerLen, headerLen+len(data)+gcm.Overhead())
+	binary.BigEndian.PutUint32(out, version)
+	nonce := out[keyfileVersionLen:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generating nonce")
+	}
+	return gcm.Seal(out, nonce, data, out[:keyfileVersionLen]), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *keyfileKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	if len(data) < keyfileVersionLen {
+		return nil, errors.New("keyfile kms ciphertext is too short")
+	}
+	gcm, err := k.aead(binary.BigEndian.Uint32(data))
+	if err != nil {
+		return nil, err
+	}
+	headerLen := keyfileVersionLen + gcm.NonceSize()
+	if len(data) < headerLen {
+		return nil, errors.New("keyfile kms ciphertext is too short")
+	}
+	nonce := data[keyfileVersionLen:headerLen]
+	plaintext, err := gcm.Open(nil, nonce, data[headerLen:], data[:keyfileVersionLen])
+	if err != nil {
+		return nil, errors.Wrap(err, "keyfile kms decryption failed")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *keyfileKMS) Close() error {
+	return nil
+}
@@ -12,6 +12,7 @@ package cloudimpl
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/url"
@@ -66,6 +67,12 @@ func S3URI(bucket, path string, conf *roachpb.ExternalStorage_S3) string {
 	setIf(AuthParam, conf.Auth)
 	setIf(AWSServerSideEncryptionMode, conf.ServerEncMode)
 	setIf(AWSServerSideEncryptionKMSID, conf.ServerKMSID)
+	if conf.UsePathStyle {
+		q.Set(S3UsePathStyleParam, "true")
+	}
+	if conf.CABundle != "" {
+		q.Set(S3CABundleParam, base64.StdEncoding.EncodeToString([]byte(conf.CABundle)))
+	}
 
 	s3URL := url.URL{
 		Scheme:   "s3",
@@ -142,7 +149,9 @@ func MakeS3Storage(
 	maxRetries := 10
 	opts.Config.MaxRetries = &maxRetries
 
-	if conf.Endpoint != "" {
+	// Custom endpoints, such as MinIO, usually do not support virtual-hosted
+	// style requests, which address the bucket in the host name.
+	if conf.Endpoint != "" || conf.UsePathStyle {
 		opts.Config.S3ForcePathStyle = aws.Bool(true)
 	}
 	if log.V(2) {
@@ -177,7 +186,13 @@ func MakeS3Storage(
 }
 
 func (s *s3Storage) newS3Client(ctx context.Context) (*s3.S3, error) {
-	sess, err := session.NewSessionWithOptions(s.opts)
+	opts := s.opts
+	if s.conf.CABundle != "" {
+		// The bundle is read whenever a session is created. It takes precedence
+		// over the AWS_CA_BUNDLE environment variable of the node.
+		opts.CustomCABundle = strings.NewReader(s.conf.CABundle)
+	}
+	sess, err := session.NewSessionWithOptions(opts)
 	if err != nil {
 		return nil, errors.Wrap(err, "new aws session")
 	}
@@ -0,0 +1,448 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package sftp implements a client, and a server used in tests, for version 3
+// of the SSH File Transfer Protocol.
+package sftp
+
+import (
+	"io"
+	"path"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// Client is an SFTP client. Requests are sent one at a time, so a Client can
+// be used concurrently, but concurrent requests are not pipelined.
+type Client struct {
+	r io.Reader
+	w io.WriteCloser
+	// session is the SSH session running the sftp subsystem, if the client was
+	// created with NewClient.
+	session *ssh.Session
+	// extensions are the protocol extensions supported by the server.
+	extensions map[string]string
+
+	mu struct {
+		syncutil.Mutex
+		nextID uint32
+	}
+}
+
+// NewClient starts the sftp subsystem in a new session of the provided SSH
+// connection and returns a client using it. Closing the client closes the
+// session but not the connection.
+func NewClient(conn *ssh.Client) (*Client, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, errors.Wrap(err, "sftp: opening session")
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		_ = session.Close()
+		return nil, errors.Wrap(err, "sftp: requesting subsystem")
+	}
+	c, err := NewClientPipe(r, w)
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	c.session = session
+	return c, nil
+}
+
+// NewClientPipe returns a client which sends requests to w and reads the
+// responses from r.
+func NewClientPipe(r io.Reader, w io.WriteCloser) (*Client, error) {
+	c := &Client{r: r, w: w, extensions: make(map[string]string)}
+	var b buffer
+	b.uint32(protocolVersion)
+	if err := writePacket(w, fxpInit, b); err != nil {
+		return nil, errors.Wrap(err, "sftp: sending init")
+	}
+	typ, payload, err := readPacket(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "sftp: reading version")
+	}
+	if typ != fxpVersion {
+		return nil, errors.Errorf("sftp: expected version packet, got packet type %d", typ)
+	}
+	rd := reader{b: payload}
+	if version := rd.uint32(); rd.err == nil && version != protocolVersion {
+		return nil, errors.Errorf("sftp: unsupported protocol version %d", version)
+	}
+	for len(rd.b) > 0 && rd.err == nil {
+		name, data := rd.string(), rd.string()
+		c.extensions[name] = data
+	}
+	if rd.err != nil {
+		return nil, rd.err
+	}
+	return c, nil
+}
+
+// Close closes the client. Any request in flight fails.
+func (c *Client) Close() error {
+	err := c.w.Close()
+	if c.session != nil {
+		// Closing the session returns io.EOF if the server closed it first.
+		if closeErr := c.session.Close(); closeErr != nil && closeErr != io.EOF && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// request sends a request of the given type, whose fields following the
+// request ID are in payload, and returns the type and the fields following the
+// request ID of the response.
+func (c *Client) request(typ byte, payload buffer) (byte, *reader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.nextID++
+	id := c.mu.nextID
+
+	var b buffer
+	b.uint32(id)
+	b = append(b, payload...)
+	if err := writePacket(c.w, typ, b); err != nil {
+		return 0, nil, errors.Wrap(err, "sftp: sending request")
+	}
+	respTyp, resp, err := readPacket(c.r)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "sftp: reading response")
+	}
+	rd := &reader{b: resp}
+	if respID := rd.uint32(); rd.err == nil && respID != id {
+		return 0, nil, errors.Errorf("sftp: response ID %d does not match request ID %d", respID, id)
+	}
+	return respTyp, rd, rd.err
+}
+
+// readStatus reads the fields of a status response and returns the error it
+// holds, if any.
+func readStatus(rd *reader) error {
+	code, msg := rd.uint32(), rd.string()
+	if rd.err != nil {
+		return rd.err
+	}
+	if code == fxOK {
+		return nil
+	}
+	return &StatusError{Code: code, Msg: msg}
+}
+
+// expectResponse returns an error if the type of a response is not the
+// expected one. Error statuses are returned as a StatusError.
+func expectResponse(typ, expected byte, rd *reader) error {
+	if typ == expected {
+		return nil
+	}
+	if typ == fxpStatus {
+		if err := readStatus(rd); err != nil {
+			return err
+		}
+	}
+	return errors.Errorf("sftp: unexpected response type %d", typ)
+}
+
+// statusRequest sends a request whose response is a status.
+func (c *Client) statusRequest(typ byte, payload buffer) error {
+	respTyp, rd, err := c.request(typ, payload)
+	if err != nil {
+		return err
+	}
+	if respTyp != fxpStatus {
+		return errors.Errorf("sftp: unexpected response type %d", respTyp)
+	}
+	return readStatus(rd)
+}
+
+// Stat returns information about the file or directory at path p, following
+// symbolic links.
+func (c *Client) Stat(p string) (FileInfo, error) {
+	var b buffer
+	b.string(p)
+	typ, rd, err := c.request(fxpStat, b)
+	if err == nil {
+		err = expectResponse(typ, fxpAttrs, rd)
+	}
+	if err != nil {
+		return FileInfo{}, errors.Wrapf(err, "stat %s", p)
+	}
+	fi := rd.attrs()
+	return fi, rd.err
+}
+
+// ReadDir returns the entries of the directory at path p, excluding "." and
+// "..".
+func (c *Client) ReadDir(p string) ([]FileInfo, error) {
+	handle, err := c.openHandle(fxpOpendir, func(b *buffer) { b.string(p) })
+	if err != nil {
+		return nil, errors.Wrapf(err, "opendir %s", p)
+	}
+	var entries []FileInfo
+	for {
+		var b buffer
+		b.string(handle)
+		typ, rd, err := c.request(fxpReaddir, b)
+		if err == nil {
+			err = expectResponse(typ, fxpName, rd)
+		}
+		if err != nil {
+			_ = c.closeHandle(handle)
+			var statusErr *StatusError
+			if errors.As(err, &statusErr) && statusErr.Code == fxEOF {
+				return entries, nil
+			}
+			return nil, errors.Wrapf(err, "readdir %s", p)
+		}
+		for n := rd.uint32(); n > 0 && rd.err == nil; n-- {
+			name := rd.string()
+			rd.string() // The long name, as printed by ls -l.
+			fi := rd.attrs()
+			if name == "." || name == ".." {
+				continue
+			}
+			fi.Name = name
+			entries = append(entries, fi)
+		}
+		if rd.err != nil {
+			_ = c.closeHandle(handle)
+			return nil, rd.err
+		}
+	}
+}
+
+// Mkdir creates the directory at path p.
+func (c *Client) Mkdir(p string) error {
+	var b buffer
+	b.string(p)
+	b.attrs(FileInfo{Mode: 0755})
+	return errors.Wrapf(c.statusRequest(fxpMkdir, b), "mkdir %s", p)
+}
+
+// MkdirAll creates the directory at path p along with any missing parent.
+func (c *Client) MkdirAll(p string) error {
+	fi, err := c.Stat(p)
+	if err == nil {
+		if !fi.IsDir() {
+			return errors.Errorf("sftp: %s is not a directory", p)
+		}
+		return nil
+	}
+	if !IsNotExist(err) {
+		return err
+	}
+	if parent := path.Dir(p); parent != p {
+		if err := c.MkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	if err := c.Mkdir(p); err != nil {
+		// The directory may have been created concurrently.
+		if fi, statErr := c.Stat(p); statErr == nil && fi.IsDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove removes the file at path p.
+func (c *Client) Remove(p string) error {
+	var b buffer
+	b.string(p)
+	return errors.Wrapf(c.statusRequest(fxpRemove, b), "remove %s", p)
+}
+
+// Rename renames the file at oldpath to newpath, replacing newpath if it
+// exists. Servers which do not support the posix-rename extension cannot
+// replace a file atomically, so newpath is removed before the rename.
+func (c *Client) Rename(oldpath, newpath string) error {
+	var b buffer
+	if _, ok := c.extensions[posixRenameExtension]; ok {
+		b.string(posixRenameExtension)
+		b.string(oldpath)
+		b.string(newpath)
+		return errors.Wrapf(c.statusRequest(fxpExtended, b), "rename %s", oldpath)
+	}
+	if err := c.Remove(newpath); err != nil && !IsNotExist(err) {
+		return err
+	}
+	b.string(oldpath)
+	b.string(newpath)
+	return errors.Wrapf(c.statusRequest(fxpRename, b), "rename %s", oldpath)
+}
+
+// Open opens the file at path p for reading.
+func (c *Client) Open(p string) (*File, error) {
+	return c.openFile(p, fxfRead)
+}
+
+// Create creates or truncates the file at path p and opens it for writing.
+func (c *Client) Create(p string) (*File, error) {
+	return c.openFile(p, fxfWrite|fxfCreat|fxfTrunc)
+}
+
+func (c *Client) openFile(p string, flags uint32) (*File, error) {
+	handle, err := c.openHandle(fxpOpen, func(b *buffer) {
+		b.string(p)
+		b.uint32(flags)
+		b.attrs(FileInfo{Mode: 0644})
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", p)
+	}
+	return &File{c: c, path: p, handle: handle}, nil
+}
+
+// openHandle sends an open or opendir request and returns the handle of the
+// opened file or directory.
+func (c *Client) openHandle(typ byte, fields func(b *buffer)) (string, error) {
+	var b buffer
+	fields(&b)
+	respTyp, rd, err := c.request(typ, b)
+	if err != nil {
+		return "", err
+	}
+	if err := expectResponse(respTyp, fxpHandle, rd); err != nil {
+		return "", err
+	}
+	handle := rd.string()
+	return handle, rd.err
+}
+
+func (c *Client) closeHandle(handle string) error {
+	var b buffer
+	b.string(handle)
+	return c.statusRequest(fxpClose, b)
+}
+
+// File is a file opened by a Client. Reads and writes happen at the current
+// offset of the file, which can be changed with Seek.
+type File struct {
+	c      *Client
+	path   string
+	handle string
+	offset int64
+}
+
+var _ io.ReadWriteCloser = &File{}
+var _ io.Seeker = &File{}
+
+// Read implements the io.Reader interface.
+func (f *File) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(p) > maxDataLen {
+		p = p[:maxDataLen]
+	}
+	var b buffer
+	b.string(f.handle)
+	b.uint64(uint64(f.offset))
+	b.uint32(uint32(len(p)))
+	typ, rd, err := f.c.request(fxpRead, b)
+	if err == nil {
+		err = expectResponse(typ, fxpData, rd)
+	}
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.Code == fxEOF {
+			return 0, io.EOF
+		}
+		return 0, errors.Wrapf(err, "read %s", f.path)
+	}
+	data := rd.bytes()
+	if rd.err != nil {
+		return 0, rd.err
+	}
+	n := copy(p, data)
+	f.offset += int64(n)
+	return n, nil
+}
+
+// Write implements the io.Writer interface.
+func (f *File) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > maxDataLen {
+			chunk = chunk[:maxDataLen]
+		}
+		var b buffer
+		b.string(f.handle)
+		b.uint64(uint64(f.offset))
+		b.bytes(chunk)
+		if err := f.c.statusRequest(fxpWrite, b); err != nil {
+			return written, errors.Wrapf(err, "write %s", f.path)
+		}
+		f.offset += int64(len(chunk))
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Seek implements the io.Seeker interface.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		fi, err := f.Stat()
+		if err != nil {
+			return f.offset, err
+		}
+		offset += fi.Size
+	default:
+		return f.offset, errors.Errorf("sftp: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return f.offset, errors.Errorf("sftp: negative offset %d", offset)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Stat returns information about the file.
+func (f *File) Stat() (FileInfo, error) {
+	var b buffer
+	b.string(f.handle)
+	typ, rd, err := f.c.request(fxpFstat, b)
+	if err == nil {
+		err = expectResponse(typ, fxpAttrs, rd)
+	}
+	if err != nil {
+		return FileInfo{}, errors.Wrapf(err, "stat %s", f.path)
+	}
+	fi := rd.attrs()
+	fi.Name = path.Base(f.path)
+	return fi, rd.err
+}
+
+// Close implements the io.Closer interface. Errors writing the file may only be
+// reported when it is closed.
+func (f *File) Close() error {
+	return errors.Wrapf(f.c.closeHandle(f.handle), "close %s", f.path)
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftp_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl/sftp"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl/sftp/sftptest"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkLen is the length of the data sent by the client in each read or write
+// request.
+const chunkLen = 32 << 10
+
+// pipeConn joins the reading end of a pipe and the writing end of another.
+type pipeConn struct {
+	io.Reader
+	io.Writer
+}
+
+// startTestServer returns a client connected to a server serving the files
+// under dir.
+func startTestServer(t *testing.T, dir string) (*sftp.Client, func()) {
+	requestsR, requestsW := io.Pipe()
+	responsesR, responsesW := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		err := sftptest.NewFileServer(pipeConn{requestsR, responsesW}, dir).Serve()
+		_ = responsesW.Close()
+		errCh <- err
+	}()
+	c, err := sftp.NewClientPipe(responsesR, requestsW)
+	require.NoError(t, err)
+	return c, func() {
+		require.NoError(t, c.Close())
+		require.NoError(t, <-errCh)
+	}
+}
+
+func TestClient(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+	c, cleanup := startTestServer(t, dir)
+	defer cleanup()
+
+	// More than a few chunks of data are written and read back.
+	content := bytes.Repeat([]byte("0123456789"), 3*chunkLen/10+7)
+
+	t.Run("write", func(t *testing.T) {
+		require.NoError(t, c.MkdirAll("/a/b"))
+		require.NoError(t, c.MkdirAll("/a/b"))
+		f, err := c.Create("/a/b/file")
+		require.NoError(t, err)
+		_, err = f.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		written, err := ioutil.ReadFile(filepath.Join(dir, "a", "b", "file"))
+		require.NoError(t, err)
+		require.Equal(t, content, written)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		f, err := c.Open("/a/b/file")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, f.Close()) }()
+		read, err := ioutil.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, content, read)
+
+		const offset = chunkLen + 13
+		_, err = f.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		read, err = ioutil.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, content[offset:], read)
+
+		fi, err := f.Stat()
+		require.NoError(t, err)
+		require.Equal(t, int64(len(content)), fi.Size)
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		fi, err := c.Stat("/a/b/file")
+		require.NoError(t, err)
+		require.False(t, fi.IsDir())
+		require.Equal(t, int64(len(content)), fi.Size)
+
+		fi, err = c.Stat("/a")
+		require.NoError(t, err)
+		require.True(t, fi.IsDir())
+
+		_, err = c.Stat("/a/missing")
+		require.True(t, sftp.IsNotExist(err), "%+v", err)
+		_, err = c.Open("/a/missing")
+		require.True(t, sftp.IsNotExist(err), "%+v", err)
+	})
+
+	t.Run("readdir", func(t *testing.T) {
+		entries, err := c.ReadDir("/a")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "b", entries[0].Name)
+		require.True(t, entries[0].IsDir())
+
+		entries, err = c.ReadDir("/a/b")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "file", entries[0].Name)
+		require.Equal(t, int64(len(content)), entries[0].Size)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		f, err := c.Create("/a/b/other")
+		require.NoError(t, err)
+		_, err = f.Write([]byte("other"))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		// The destination of the rename is replaced.
+		require.NoError(t, c.Rename("/a/b/other", "/a/b/file"))
+		written, err := ioutil.ReadFile(filepath.Join(dir, "a", "b", "file"))
+		require.NoError(t, err)
+		require.Equal(t, "other", string(written))
+		_, err = c.Stat("/a/b/other")
+		require.True(t, sftp.IsNotExist(err), "%+v", err)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, c.Remove("/a/b/file"))
+		require.True(t, sftp.IsNotExist(c.Remove("/a/b/file")))
+		entries, err := c.ReadDir("/a/b")
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		// Paths cannot escape the root of the server.
+		entries, err := c.ReadDir("/../..")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "a", entries[0].Name)
+	})
+}
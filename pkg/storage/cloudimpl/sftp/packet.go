@@ -0,0 +1,276 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// protocolVersion is the version of the SFTP protocol spoken by the client and
+// the server, as described in draft-ietf-secsh-filexfer-02. It is the version
+// implemented by OpenSSH and most other SFTP servers.
+const protocolVersion = 3
+
+// Packet types.
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpWrite    = 6
+	fxpLstat    = 7
+	fxpFstat    = 8
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+	fxpExtended = 200
+)
+
+// Status codes of SSH_FXP_STATUS responses.
+const (
+	fxOK               = 0
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+	fxFailure          = 4
+	fxBadMessage       = 5
+	fxOpUnsupported    = 8
+)
+
+// Flags of SSH_FXP_OPEN requests.
+const (
+	fxfRead  = 0x01
+	fxfWrite = 0x02
+	fxfCreat = 0x08
+	fxfTrunc = 0x10
+)
+
+// Flags of the file attributes, which indicate the attributes present.
+const (
+	attrSize        = 0x01
+	attrUIDGID      = 0x02
+	attrPermissions = 0x04
+	attrACModTime   = 0x08
+	attrExtended    = 0x80000000
+)
+
+// posixRenameExtension is the OpenSSH extension which renames a file over an
+// existing file, which the rename request of version 3 of the protocol does not
+// allow.
+const posixRenameExtension = "posix-rename@openssh.com"
+
+// modeType masks the file type bits of the permissions attribute, and modeDir
+// is the file type of a directory.
+const (
+	modeType = 0170000
+	modeDir  = 0040000
+)
+
+// maxDataLen is the maximum length of the data of a read or write request.
+// Servers are only required to support packets of up to 34000 bytes.
+const maxDataLen = 32 << 10
+
+// maxPacketLen is the maximum length of the packets accepted from the peer.
+const maxPacketLen = 256 << 10
+
+// StatusError is an error status returned by the server.
+type StatusError struct {
+	Code uint32
+	Msg  string
+}
+
+func (e *StatusError) Error() string {
+	if e.Msg != "" {
+		return fmt.Sprintf("sftp: %s (code %d)", e.Msg, e.Code)
+	}
+	return fmt.Sprintf("sftp: status code %d", e.Code)
+}
+
+// IsNotExist returns whether the error indicates that a file or directory does
+// not exist.
+func IsNotExist(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code == fxNoSuchFile
+}
+
+// FileInfo describes a file or directory.
+type FileInfo struct {
+	// Name is the base name of the file. It is only populated by ReadDir.
+	Name string
+	Size int64
+	// Mode holds the type and permission bits of the file, if known.
+	Mode uint32
+}
+
+// IsDir returns whether the file is a directory.
+func (fi FileInfo) IsDir() bool {
+	return fi.Mode&modeType == modeDir
+}
+
+// buffer is used to marshal the payload of a packet.
+type buffer []byte
+
+func (b *buffer) byte(v byte) {
+	*b = append(*b, v)
+}
+
+func (b *buffer) uint32(v uint32) {
+	*b = append(*b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32((*b)[len(*b)-4:], v)
+}
+
+func (b *buffer) uint64(v uint64) {
+	*b = append(*b, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64((*b)[len(*b)-8:], v)
+}
+
+func (b *buffer) string(v string) {
+	b.uint32(uint32(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *buffer) bytes(v []byte) {
+	b.uint32(uint32(len(v)))
+	*b = append(*b, v...)
+}
+
+// attrs marshals the size and permissions of the file. The name is not part of
+// the attributes.
+func (b *buffer) attrs(fi FileInfo) {
+	b.uint32(attrSize | attrPermissions)
+	b.uint64(uint64(fi.Size))
+	b.uint32(fi.Mode)
+}
+
+// errShortPacket is returned when a packet is shorter than its contents.
+var errShortPacket = errors.New("sftp: short packet")
+
+// reader is used to unmarshal the payload of a packet. The first error is
+// sticky and kept in err, so that it can be checked once all the fields have
+// been read.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || len(r.b) < 1 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.b) < 4 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v
+}
+
+func (r *reader) uint64() uint64 {
+	if r.err != nil || len(r.b) < 8 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.b)) < n {
+		r.err = errShortPacket
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+// attrs unmarshals file attributes, skipping the ones which are not part of a
+// FileInfo.
+func (r *reader) attrs() FileInfo {
+	var fi FileInfo
+	flags := r.uint32()
+	if flags&attrSize != 0 {
+		fi.Size = int64(r.uint64())
+	}
+	if flags&attrUIDGID != 0 {
+		r.uint32()
+		r.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		fi.Mode = r.uint32()
+	}
+	if flags&attrACModTime != 0 {
+		r.uint32()
+		r.uint32()
+	}
+	if flags&attrExtended != 0 {
+		for n := r.uint32(); n > 0 && r.err == nil; n-- {
+			r.string()
+			r.string()
+		}
+	}
+	return fi
+}
+
+// writePacket writes a packet with the provided type and payload.
+func writePacket(w io.Writer, typ byte, payload []byte) error {
+	var hdr buffer
+	hdr.uint32(uint32(len(payload) + 1))
+	hdr.byte(typ)
+	// The header and the payload are written at once since concurrent writes to
+	// an SSH channel may otherwise be interleaved.
+	_, err := w.Write(append(hdr, payload...))
+	return err
+}
+
+// readPacket reads a packet and returns its type and payload.
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if n < 1 || n > maxPacketLen {
+		return 0, nil, errors.Errorf("sftp: invalid packet length %d", n)
+	}
+	payload := make([]byte, n-1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, errors.Wrap(err, "sftp: reading packet")
+	}
+	return hdr[4], payload, nil
+}
@@ -0,0 +1,325 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftptest
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+// FileServer serves the SFTP protocol for the files under a local directory,
+// which is the root of the paths in the requests. It only supports the requests
+// sent by sftp.Client.
+type FileServer struct {
+	rw   io.ReadWriter
+	root string
+
+	// handles maps the open handles to an *os.File or, for directories, to the
+	// directory's path.
+	handles    map[string]interface{}
+	nextHandle int
+}
+
+// NewFileServer returns a server which reads requests from rw and writes the
+// responses to it.
+func NewFileServer(rw io.ReadWriter, root string) *FileServer {
+	return &FileServer{rw: rw, root: root, handles: make(map[string]interface{})}
+}
+
+// Serve handles requests until the client closes its end of rw.
+func (s *FileServer) Serve() error {
+	defer func() {
+		for _, h := range s.handles {
+			if f, ok := h.(*os.File); ok {
+				_ = f.Close()
+			}
+		}
+	}()
+	for {
+		typ, payload, err := readPacket(s.rw)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if typ == fxpInit {
+			var b buffer
+			b.uint32(protocolVersion)
+			b.string(posixRenameExtension)
+			b.string("1")
+			if err := writePacket(s.rw, fxpVersion, b); err != nil {
+				return err
+			}
+			continue
+		}
+		rd := &reader{b: payload}
+		id := rd.uint32()
+		if rd.err != nil {
+			return rd.err
+		}
+		respTyp, resp, err := s.handle(typ, rd)
+		if err == nil {
+			err = rd.err
+		}
+		if err != nil || respTyp == fxpStatus {
+			respTyp, resp = fxpStatus, statusPayload(err)
+		}
+		b := buffer{}
+		b.uint32(id)
+		b = append(b, resp...)
+		if err := writePacket(s.rw, respTyp, b); err != nil {
+			return err
+		}
+	}
+}
+
+// errUnsupported is returned for requests which are not supported by the
+// server.
+var errUnsupported = errors.New("unsupported request")
+
+// statusPayload returns the fields following the request ID of the status
+// response for an error, which is nil for a successful request.
+func statusPayload(err error) buffer {
+	code := uint32(fxOK)
+	msg := "ok"
+	if err != nil {
+		msg = err.Error()
+		switch {
+		case errors.Is(err, io.EOF):
+			code = fxEOF
+		case errors.Is(err, errShortPacket):
+			code = fxBadMessage
+		case errors.Is(err, errUnsupported):
+			code = fxOpUnsupported
+		case oserror.IsNotExist(err):
+			code = fxNoSuchFile
+		case oserror.IsPermission(err):
+			code = fxPermissionDenied
+		default:
+			code = fxFailure
+		}
+	}
+	var b buffer
+	b.uint32(code)
+	b.string(msg)
+	b.string("" /* language tag */)
+	return b
+}
+
+// localPath returns the local path of a path in a request. Paths cannot escape
+// the root of the server.
+func (s *FileServer) localPath(p string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+func (s *FileServer) newHandle(h interface{}) buffer {
+	s.nextHandle++
+	handle := strconv.Itoa(s.nextHandle)
+	s.handles[handle] = h
+	var b buffer
+	b.string(handle)
+	return b
+}
+
+func (s *FileServer) file(handle string) (*os.File, error) {
+	f, ok := s.handles[handle].(*os.File)
+	if !ok {
+		return nil, errors.Errorf("invalid file handle %q", handle)
+	}
+	return f, nil
+}
+
+func attrsPayload(fi os.FileInfo) buffer {
+	var b buffer
+	b.attrs(fi)
+	return b
+}
+
+// attrs marshals the size and permissions of the file.
+func (b *buffer) attrs(fi os.FileInfo) {
+	mode := uint32(fi.Mode().Perm())
+	if fi.IsDir() {
+		mode |= modeDir
+	} else {
+		mode |= modeRegular
+	}
+	b.uint32(attrSize | attrPermissions)
+	b.uint64(uint64(fi.Size()))
+	b.uint32(mode)
+}
+
+// handle handles a request and returns the type of the response and its fields
+// following the request ID. The fields of status responses are filled in from
+// the returned error.
+func (s *FileServer) handle(typ byte, rd *reader) (byte, buffer, error) {
+	switch typ {
+	case fxpOpen:
+		p, pflags := rd.string(), rd.uint32()
+		rd.skipAttrs()
+		if rd.err != nil {
+			return 0, nil, rd.err
+		}
+		var flags int
+		switch {
+		case pflags&fxfRead != 0 && pflags&fxfWrite != 0:
+			flags = os.O_RDWR
+		case pflags&fxfWrite != 0:
+			flags = os.O_WRONLY
+		default:
+			flags = os.O_RDONLY
+		}
+		if pflags&fxfCreat != 0 {
+			flags |= os.O_CREATE
+		}
+		if pflags&fxfTrunc != 0 {
+			flags |= os.O_TRUNC
+		}
+		f, err := os.OpenFile(s.localPath(p), flags, 0644)
+		if err != nil {
+			return 0, nil, err
+		}
+		return fxpHandle, s.newHandle(f), nil
+
+	case fxpOpendir:
+		p := s.localPath(rd.string())
+		fi, err := os.Stat(p)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !fi.IsDir() {
+			return 0, nil, errors.Errorf("%s is not a directory", p)
+		}
+		return fxpHandle, s.newHandle(p), nil
+
+	case fxpClose:
+		handle := rd.string()
+		h, ok := s.handles[handle]
+		if !ok {
+			return 0, nil, errors.Errorf("invalid handle %q", handle)
+		}
+		delete(s.handles, handle)
+		var err error
+		if f, ok := h.(*os.File); ok {
+			err = f.Close()
+		}
+		return fxpStatus, nil, err
+
+	case fxpRead:
+		handle, offset, length := rd.string(), rd.uint64(), rd.uint32()
+		f, err := s.file(handle)
+		if err != nil {
+			return 0, nil, err
+		}
+		if length > maxDataLen {
+			length = maxDataLen
+		}
+		data := make([]byte, length)
+		n, err := f.ReadAt(data, int64(offset))
+		if n == 0 && err != nil {
+			return 0, nil, err
+		}
+		var b buffer
+		b.bytes(data[:n])
+		return fxpData, b, nil
+
+	case fxpWrite:
+		handle, offset, data := rd.string(), rd.uint64(), rd.bytes()
+		f, err := s.file(handle)
+		if err != nil {
+			return 0, nil, err
+		}
+		_, err = f.WriteAt(data, int64(offset))
+		return fxpStatus, nil, err
+
+	case fxpStat, fxpLstat:
+		fi, err := os.Stat(s.localPath(rd.string()))
+		if err != nil {
+			return 0, nil, err
+		}
+		return fxpAttrs, attrsPayload(fi), nil
+
+	case fxpFstat:
+		f, err := s.file(rd.string())
+		if err != nil {
+			return 0, nil, err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			return 0, nil, err
+		}
+		return fxpAttrs, attrsPayload(fi), nil
+
+	case fxpReaddir:
+		handle := rd.string()
+		p, ok := s.handles[handle].(string)
+		if !ok {
+			return 0, nil, errors.Errorf("invalid directory handle %q", handle)
+		}
+		if p == "" {
+			// All the entries were returned by the previous request.
+			return 0, nil, io.EOF
+		}
+		s.handles[handle] = ""
+		entries, err := ioutil.ReadDir(p)
+		if err != nil {
+			return 0, nil, err
+		}
+		var b buffer
+		b.uint32(uint32(len(entries)))
+		for _, fi := range entries {
+			b.string(fi.Name())
+			b.string(fi.Name())
+			b.attrs(fi)
+		}
+		return fxpName, b, nil
+
+	case fxpRemove:
+		p := s.localPath(rd.string())
+		fi, err := os.Stat(p)
+		if err != nil {
+			return 0, nil, err
+		}
+		if fi.IsDir() {
+			return 0, nil, errors.Errorf("%s is a directory", p)
+		}
+		return fxpStatus, nil, os.Remove(p)
+
+	case fxpMkdir:
+		p := s.localPath(rd.string())
+		rd.skipAttrs()
+		return fxpStatus, nil, os.Mkdir(p, 0755)
+
+	case fxpRename:
+		oldpath, newpath := s.localPath(rd.string()), s.localPath(rd.string())
+		if _, err := os.Stat(newpath); err == nil {
+			return 0, nil, errors.Errorf("%s already exists", newpath)
+		}
+		return fxpStatus, nil, os.Rename(oldpath, newpath)
+
+	case fxpExtended:
+		if rd.string() != posixRenameExtension {
+			return 0, nil, errUnsupported
+		}
+		oldpath, newpath := s.localPath(rd.string()), s.localPath(rd.string())
+		return fxpStatus, nil, os.Rename(oldpath, newpath)
+
+	default:
+		return 0, nil, errUnsupported
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftptest
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// The encoding of the packets is implemented independently of the client in
+// package sftp, so that the tests of the client exercise it against a second
+// implementation of the protocol.
+
+// protocolVersion is the version of the SFTP protocol spoken by the server, as
+// described in draft-ietf-secsh-filexfer-02.
+const protocolVersion = 3
+
+// Packet types.
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpWrite    = 6
+	fxpLstat    = 7
+	fxpFstat    = 8
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+	fxpExtended = 200
+)
+
+// Status codes of SSH_FXP_STATUS responses.
+const (
+	fxOK               = 0
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+	fxFailure          = 4
+	fxBadMessage       = 5
+	fxOpUnsupported    = 8
+)
+
+// Flags of SSH_FXP_OPEN requests.
+const (
+	fxfRead  = 0x01
+	fxfWrite = 0x02
+	fxfCreat = 0x08
+	fxfTrunc = 0x10
+)
+
+// Flags of the file attributes, which indicate the attributes present.
+const (
+	attrSize        = 0x01
+	attrUIDGID      = 0x02
+	attrPermissions = 0x04
+	attrACModTime   = 0x08
+	attrExtended    = 0x80000000
+)
+
+// posixRenameExtension is the OpenSSH extension which renames a file over an
+// existing file.
+const posixRenameExtension = "posix-rename@openssh.com"
+
+// File type bits of the permissions attribute.
+const (
+	modeDir     = 0040000
+	modeRegular = 0100000
+)
+
+// maxDataLen is the maximum length of the data returned by a read request.
+const maxDataLen = 32 << 10
+
+// maxPacketLen is the maximum length of the packets accepted from the client.
+const maxPacketLen = 256 << 10
+
+// buffer is used to marshal the payload of a packet.
+type buffer []byte
+
+func (b *buffer) byte(v byte) {
+	*b = append(*b, v)
+}
+
+func (b *buffer) uint32(v uint32) {
+	*b = append(*b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32((*b)[len(*b)-4:], v)
+}
+
+func (b *buffer) uint64(v uint64) {
+	*b = append(*b, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64((*b)[len(*b)-8:], v)
+}
+
+func (b *buffer) string(v string) {
+	b.uint32(uint32(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *buffer) bytes(v []byte) {
+	b.uint32(uint32(len(v)))
+	*b = append(*b, v...)
+}
+
+// errShortPacket is returned when a packet is shorter than its contents.
+var errShortPacket = errors.New("short packet")
+
+// reader is used to unmarshal the payload of a packet. The first error is
+// sticky and kept in err, so that it can be checked once all the fields have
+// been read.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.b) < 4 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v
+}
+
+func (r *reader) uint64() uint64 {
+	if r.err != nil || len(r.b) < 8 {
+		r.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.b)) < n {
+		r.err = errShortPacket
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+// skipAttrs skips file attributes, which the server ignores.
+func (r *reader) skipAttrs() {
+	flags := r.uint32()
+	if flags&attrSize != 0 {
+		r.uint64()
+	}
+	if flags&attrUIDGID != 0 {
+		r.uint32()
+		r.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		r.uint32()
+	}
+	if flags&attrACModTime != 0 {
+		r.uint32()
+		r.uint32()
+	}
+	if flags&attrExtended != 0 {
+		for n := r.uint32(); n > 0 && r.err == nil; n-- {
+			r.string()
+			r.string()
+		}
+	}
+}
+
+// writePacket writes a packet with the provided type and payload.
+func writePacket(w io.Writer, typ byte, payload []byte) error {
+	var hdr buffer
+	hdr.uint32(uint32(len(payload) + 1))
+	hdr.byte(typ)
+	_, err := w.Write(append(hdr, payload...))
+	return err
+}
+
+// readPacket reads a packet and returns its type and payload.
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if n < 1 || n > maxPacketLen {
+		return 0, nil, errors.Errorf("invalid packet length %d", n)
+	}
+	payload := make([]byte, n-1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, errors.Wrap(err, "reading packet")
+	}
+	return hdr[4], payload, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package sftptest provides servers of the SFTP protocol for tests.
+package sftptest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// Server is an SSH server which serves the files under a local directory over
+// SFTP to a single user authenticated with a private key.
+type Server struct {
+	// Addr is the host:port address of the server.
+	Addr string
+	// User is the name of the user allowed to connect.
+	User string
+	// PrivateKey is the PEM encoded private key of the user.
+	PrivateKey string
+	// HostKey is the public key of the server in the authorized_keys format.
+	HostKey string
+
+	root     string
+	config   *ssh.ServerConfig
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu struct {
+		syncutil.Mutex
+		closed bool
+		conns  map[net.Conn]struct{}
+	}
+}
+
+// NewServer starts an SFTP server for the files under root. Close must be
+// called to stop the server.
+func NewServer(t testing.TB, root string) *Server {
+	s := &Server{User: "roach", root: root}
+	s.mu.conns = make(map[net.Conn]struct{})
+
+	hostKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.HostKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())))
+
+	userKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userKeyDER, err := x509.MarshalECPrivateKey(userKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: userKeyDER}))
+	userPublicKey, err := ssh.NewPublicKey(&userKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(md ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if md.User() == s.User && bytes.Equal(key.Marshal(), userPublicKey.Marshal()) {
+				return &ssh.Permissions{}, nil
+			}
+			return nil, errors.Errorf("unknown public key for %q", md.User())
+		},
+	}
+	s.config.AddHostKey(hostSigner)
+
+	if s.listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	s.Addr = s.listener.Addr().String()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.serve()
+	}()
+	return s
+}
+
+// Close stops the server and closes the open connections.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	s.mu.closed = true
+	for conn := range s.mu.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.mu.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.mu.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+			s.mu.Lock()
+			delete(s.mu.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// serveConn runs the sftp subsystem in the sessions opened on the connection.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			_ = newCh.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveSession(ch, chReqs)
+		}()
+	}
+}
+
+func (s *Server) serveSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer func() { _ = ch.Close() }()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for req := range reqs {
+		var subsystem struct{ Name string }
+		if req.Type != "subsystem" || ssh.Unmarshal(req.Payload, &subsystem) != nil ||
+			subsystem.Name != "sftp" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = NewFileServer(ch, s.root).Serve()
+			exitStatus := struct{ Status uint32 }{0}
+			_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(&exitStatus))
+			_ = ch.Close()
+		}()
+	}
+}
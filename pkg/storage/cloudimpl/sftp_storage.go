@@ -0,0 +1,413 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl/sftp"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpDefaultPort is the port used when the host of an sftp URI has no port.
+const sftpDefaultPort = "22"
+
+type sftpStorage struct {
+	conf      *roachpb.ExternalStorage_SFTP
+	ioConf    base.ExternalIODirConfig
+	settings  *cluster.Settings
+	addr      string
+	sshConfig *ssh.ClientConfig
+
+	// mu holds the connection to the server, which is established by the first
+	// operation and reestablished after it fails.
+	mu struct {
+		syncutil.Mutex
+		conn   *ssh.Client
+		client *sftp.Client
+	}
+}
+
+var _ cloud.ExternalStorage = &sftpStorage{}
+
+// SFTPURI returns the string URI for a given path on an SFTP server.
+func SFTPURI(path string, conf *roachpb.ExternalStorage_SFTP) string {
+	q := make(url.Values)
+	q.Set(SFTPPrivateKeyParam, base64.StdEncoding.EncodeToString([]byte(conf.PrivateKey)))
+	q.Set(SFTPHostKeyParam, conf.HostKey)
+
+	sftpURL := url.URL{
+		Scheme:   "sftp",
+		User:     url.User(conf.User),
+		Host:     conf.Host,
+		Path:     path,
+		RawQuery: q.Encode(),
+	}
+	return sftpURL.String()
+}
+
+func makeSFTPStorage(
+	conf *roachpb.ExternalStorage_SFTP, settings *cluster.Settings, ioConf base.ExternalIODirConfig,
+) (cloud.ExternalStorage, error) {
+	if conf == nil {
+		return nil, errors.Errorf("sftp storage requested but info missing")
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(conf.PrivateKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", SFTPPrivateKeyParam)
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(conf.HostKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", SFTPHostKeyParam)
+	}
+	addr := conf.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, sftpDefaultPort)
+	}
+	return &sftpStorage{
+		conf:     conf,
+		ioConf:   ioConf,
+		settings: settings,
+		addr:     addr,
+		sshConfig: &ssh.ClientConfig{
+			User:            conf.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+		},
+	}, nil
+}
+
+// client returns the client of the connection to the server, connecting to
+// the server if needed. The connection is established without holding mu, so
+// that a slow or unresponsive server doesn't block the operations which
+// observe their context while waiting for it. If several operations connect
+// concurrently, the connections other than the first one installed are closed.
+func (s *sftpStorage) client(ctx context.Context) (*sftp.Client, error) {
+	s.mu.Lock()
+	client := s.mu.client
+	s.mu.Unlock()
+	if client != nil {
+		return client, nil
+	}
+
+	conn, client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.client != nil {
+		_ = client.Close()
+		_ = conn.Close()
+		return s.mu.client, nil
+	}
+	s.mu.conn, s.mu.client = conn, client
+	return client, nil
+}
+
+// connect establishes a connection to the server and starts an sftp session
+// on it. The SSH handshake and the start of the session do not observe the
+// context, so they are bounded by a deadline on the underlying connection,
+// which is the deadline of the context or the cloud storage timeout.
+func (s *sftpStorage) connect(ctx context.Context) (*ssh.Client, *sftp.Client, error) {
+	var d net.Dialer
+	netConn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "dialing sftp server %s", s.addr)
+	}
+	deadline, ok := ctx.Deadline()
+	if timeout := timeoutSetting.Get(&s.settings.SV); !ok && timeout > 0 {
+		deadline = timeutil.Now().Add(timeout)
+	}
+	if err := netConn.SetDeadline(deadline); err != nil {
+		_ = netConn.Close()
+		return nil, nil, errors.Wrapf(err, "connecting to sftp server %s", s.addr)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, s.addr, s.sshConfig)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, nil, errors.Wrapf(err, "connecting to sftp server %s", s.addr)
+	}
+	conn := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, errors.Wrapf(err, "starting sftp session on %s", s.addr)
+	}
+	// The connection is reused by later operations, which observe their own
+	// context.
+	if err := netConn.SetDeadline(time.Time{}); err != nil {
+		_ = client.Close()
+		_ = conn.Close()
+		return nil, nil, errors.Wrapf(err, "connecting to sftp server %s", s.addr)
+	}
+	return conn, client, nil
+}
+
+// resetClient closes the connection to the server if client is still in use,
+// so that the next operation reconnects.
+func (s *sftpStorage) resetClient(client *sftp.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.client != client {
+		return
+	}
+	_ = s.mu.client.Close()
+	_ = s.mu.conn.Close()
+	s.mu.conn, s.mu.client = nil, nil
+}
+
+// withClient runs fn with a client connected to the server. The operations of
+// the client do not observe the context, so the connection is closed if the
+// context is canceled before fn returns. The connection is also closed if fn
+// fails with an error other than an error status returned by the server.
+func (s *sftpStorage) withClient(ctx context.Context, fn func(*sftp.Client) error) error {
+	client, err := s.client(ctx)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.resetClient(client)
+		case <-done:
+		}
+	}()
+
+	if err := fn(client); err != nil {
+		var statusErr *sftp.StatusError
+		if !errors.As(err, &statusErr) {
+			s.resetClient(client)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return errors.CombineErrors(ctxErr, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *sftpStorage) Conf() roachpb.ExternalStorage {
+	return roachpb.ExternalStorage{
+		Provider:   roachpb.ExternalStorageProvider_SFTP,
+		SFTPConfig: s.conf,
+	}
+}
+
+func (s *sftpStorage) ExternalIOConf() base.ExternalIODirConfig {
+	return s.ioConf
+}
+
+func (s *sftpStorage) Settings() *cluster.Settings {
+	return s.settings
+}
+
+func (s *sftpStorage) ReadFile(ctx context.Context, basename string) (io.ReadCloser, error) {
+	reader, _, err := s.ReadFileAt(ctx, basename, 0)
+	return reader, err
+}
+
+func (s *sftpStorage) ReadFileAt(
+	ctx context.Context, basename string, offset int64,
+) (io.ReadCloser, int64, error) {
+	p := path.Join(s.conf.Path, basename)
+	var f *sftp.File
+	var size int64
+	if err := s.withClient(ctx, func(client *sftp.Client) error {
+		var err error
+		if f, err = client.Open(p); err != nil {
+			return err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		size = fi.Size
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return nil
+	}); err != nil {
+		if sftp.IsNotExist(err) {
+			return nil, 0, errors.Wrapf(ErrFileDoesNotExist, "sftp file does not exist: %s", err.Error())
+		}
+		return nil, 0, errors.Wrap(err, "failed to open sftp file")
+	}
+	return f, size, nil
+}
+
+// WriteFile writes the content to a temporary file in the same directory,
+// which is then renamed, so that readers never observe a partially written
+// file.
+func (s *sftpStorage) WriteFile(
+	ctx context.Context, basename string, content io.ReadSeeker,
+) error {
+	p := path.Join(s.conf.Path, basename)
+	tmp := fmt.Sprintf("%s.%s.tmp", p, uuid.MakeV4().Short())
+	err := contextutil.RunWithTimeout(ctx, "write sftp file", timeoutSetting.Get(&s.settings.SV),
+		func(ctx context.Context) error {
+			return s.withClient(ctx, func(client *sftp.Client) error {
+				if err := client.MkdirAll(path.Dir(p)); err != nil {
+					return err
+				}
+				f, err := client.Create(tmp)
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(f, content); err != nil {
+					_ = f.Close()
+					_ = client.Remove(tmp)
+					return err
+				}
+				if err := f.Close(); err != nil {
+					_ = client.Remove(tmp)
+					return err
+				}
+				return client.Rename(tmp, p)
+			})
+		})
+	return errors.Wrap(err, "failed to write sftp file")
+}
+
+func (s *sftpStorage) ListFiles(ctx context.Context, patternSuffix string) ([]string, error) {
+	pattern := s.conf.Path
+	if patternSuffix != "" {
+		if containsGlob(s.conf.Path) {
+			return nil, errors.New("prefix cannot contain globs pattern when passing an explicit pattern")
+		}
+		pattern = path.Join(pattern, patternSuffix)
+	}
+
+	var matches []string
+	if err := s.withClient(ctx, func(client *sftp.Client) error {
+		// Only the directories which can contain files matching the pattern are
+		// walked, starting from the deepest one which contains no wildcard.
+		maxDepth := strings.Count(pattern, "/")
+		var walk func(dir string) error
+		walk = func(dir string) error {
+			entries, err := client.ReadDir(dir)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				p := path.Join(dir, entry.Name)
+				if entry.IsDir() {
+					if strings.Count(p, "/") < maxDepth {
+						if err := walk(p); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+				match, err := path.Match(pattern, p)
+				if err != nil {
+					return err
+				}
+				if match {
+					matches = append(matches, p)
+				}
+			}
+			return nil
+		}
+
+		root := getPrefixBeforeWildcard(pattern)
+		fi, err := client.Stat(root)
+		if err != nil {
+			if sftp.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !fi.IsDir() {
+			if match, err := path.Match(pattern, root); err != nil || !match {
+				return err
+			}
+			matches = append(matches, root)
+			return nil
+		}
+		return walk(root)
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to list sftp directory")
+	}
+	sort.Strings(matches)
+
+	var fileList []string
+	for _, match := range matches {
+		if patternSuffix != "" {
+			if !strings.HasPrefix(match, s.conf.Path) {
+				// TODO(dt): return a nice rel-path instead of erroring out.
+				return nil, errors.New("pattern matched file outside of path")
+			}
+			fileList = append(fileList, strings.TrimPrefix(strings.TrimPrefix(match, s.conf.Path), "/"))
+		} else {
+			fileList = append(fileList, SFTPURI(match, s.conf))
+		}
+	}
+	return fileList, nil
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, basename string) error {
+	return contextutil.RunWithTimeout(ctx, "delete sftp file", timeoutSetting.Get(&s.settings.SV),
+		func(ctx context.Context) error {
+			return s.withClient(ctx, func(client *sftp.Client) error {
+				return client.Remove(path.Join(s.conf.Path, basename))
+			})
+		})
+}
+
+func (s *sftpStorage) Size(ctx context.Context, basename string) (int64, error) {
+	var size int64
+	err := contextutil.RunWithTimeout(ctx, "size sftp file", timeoutSetting.Get(&s.settings.SV),
+		func(ctx context.Context) error {
+			return s.withClient(ctx, func(client *sftp.Client) error {
+				fi, err := client.Stat(path.Join(s.conf.Path, basename))
+				size = fi.Size
+				return err
+			})
+		})
+	return size, err
+}
+
+func (s *sftpStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.client == nil {
+		return nil
+	}
+	err := s.mu.client.Close()
+	if closeErr := s.mu.conn.Close(); err == nil {
+		err = closeErr
+	}
+	s.mu.conn, s.mu.client = nil, nil
+	return err
+}
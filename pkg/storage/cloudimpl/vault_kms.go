@@ -0,0 +1,240 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/errors"
+)
+
+const vaultScheme = "vault"
+
+const (
+	// vaultTokenEnvVar is the environment variable holding the Vault token
+	// used with implicit authentication.
+	vaultTokenEnvVar = "VAULT_TOKEN"
+	// vaultDefaultTransitMount is the default path at which the transit
+	// secrets engine is mounted.
+	vaultDefaultTransitMount = "transit"
+	// vaultMaxResponseSize bounds the size of the responses read from Vault.
+	vaultMaxResponseSize = 1 << 20
+)
+
+// vaultKMS is a KMS backed by the transit secrets engine of a HashiCorp Vault
+// server, or any service implementing the same HTTP API.
+//
+// The ciphertexts produced by the transit engine are prefixed with the
+// version of the key used to encrypt them (e.g. "vault:v2:..."), so rotating
+// the key in Vault does not prevent the decryption of the data keys of
+// backups taken under its older versions, as long as these versions are not
+// trimmed or excluded with min_decryption_version.
+type vaultKMS struct {
+	client    *http.Client
+	addr      *url.URL
+	token     string
+	namespace string
+	mount     string
+	keyName   string
+}
+
+var _ cloud.KMS = &vaultKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeVaultKMS, vaultScheme)
+}
+
+// MakeVaultKMS is the factory method which returns a configured, ready-to-use
+// Vault KMS object. The URI is of the form:
+//
+//   vault:///<key name>?VAULT_ADDR=<address>&VAULT_TOKEN=<token>
+//
+// with the optional VAULT_NAMESPACE and VAULT_TRANSIT_MOUNT parameters.
+func MakeVaultKMS(uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if env.KMSConfig().DisableHTTP {
+		return nil, errors.New(
+			"custom endpoints disallowed for vault kms due to --external-io-disable-http flag")
+	}
+
+	params := kmsURI.Query()
+	keyName := strings.TrimPrefix(kmsURI.Path, "/")
+	if keyName == "" || strings.Contains(keyName, "/") {
+		return nil, errors.Errorf("invalid vault key name %q", keyName)
+	}
+	addrParam := params.Get(VaultAddrParam)
+	if addrParam == "" {
+		return nil, errors.Errorf("%s must be set for vault kms", VaultAddrParam)
+	}
+	addr, err := url.Parse(addrParam)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", VaultAddrParam)
+	}
+	if (addr.Scheme != "http" && addr.Scheme != "https") || addr.Host == "" {
+		return nil, errors.Errorf("invalid %s %q: expected an http or https URL",
+			VaultAddrParam, addrParam)
+	}
+
+	k := &vaultKMS{
+		addr:      addr,
+		namespace: params.Get(VaultNamespaceParam),
+		mount:     strings.Trim(params.Get(VaultTransitMountParam), "/"),
+		keyName:   keyName,
+	}
+	if k.mount == "" {
+		k.mount = vaultDefaultTransitMount
+	}
+
+	// "specified": use the token provided in URI params; error if not present.
+	// "implicit": use the token from the VAULT_TOKEN environment variable.
+	// "": default to `specified`.
+	switch auth := params.Get(AuthParam); auth {
+	case "", AuthParamSpecified:
+		k.token = params.Get(VaultTokenParam)
+		if k.token == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but %s is not set",
+				AuthParam,
+				AuthParamSpecified,
+				VaultTokenParam,
+			)
+		}
+	case AuthParamImplicit:
+		if env.KMSConfig().DisableImplicitCredentials {
+			return nil, errors.New(
+				"implicit credentials disallowed for vault kms due to --external-io-disable-implicit-credentials flag")
+		}
+		k.token = os.Getenv(vaultTokenEnvVar)
+		if k.token == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but the %s environment variable is not set",
+				AuthParam, AuthParamImplicit, vaultTokenEnvVar)
+		}
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", auth, AuthParam)
+	}
+
+	if k.client, err = makeHTTPClient(env.ClusterSettings()); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// MasterKeyID implements the KMS interface. The ID of a key does not change
+// when it is rotated.
+func (k *vaultKMS) MasterKeyID() (string, error) {
+	return k.keyName, nil
+}
+
+// Encrypt implements the KMS interface. The data is encrypted under the
+// latest version of the key.
+func (k *vaultKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := k.call(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(data),
+	}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Ciphertext == "" {
+		return nil, errors.New("vault kms returned an empty ciphertext")
+	}
+	return []byte(resp.Ciphertext), nil
+}
+
+// Decrypt implements the KMS interface. The version of the key used to
+// encrypt the data is recorded in the ciphertext.
+func (k *vaultKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := k.call(ctx, "decrypt", map[string]string{
+		"ciphertext": string(data),
+	}, &resp); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding plaintext returned by vault kms")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *vaultKMS) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
+
+// call sends a request to the given endpoint of the transit engine for the
+// key, and decodes the data of the response into resp.
+func (k *vaultKMS) call(ctx context.Context, op string, body interface{}, resp interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	u := *k.addr
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/" + k.mount + "/" + op + "/" + k.keyName
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", k.token)
+	if k.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "vault kms %s", op)
+	}
+	defer res.Body.Close()
+	respBody, err := ioutil.ReadAll(io.LimitReader(res.Body, vaultMaxResponseSize))
+	if err != nil {
+		return errors.Wrapf(err, "reading vault kms %s response", op)
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []string        `json:"errors"`
+	}
+	if res.StatusCode != http.StatusOK {
+		// Vault reports the cause of errors in the errors field of the body.
+		if json.Unmarshal(respBody, &envelope) == nil && len(envelope.Errors) > 0 {
+			return errors.Errorf("vault kms %s failed: %s: %s",
+				op, res.Status, strings.Join(envelope.Errors, "; "))
+		}
+		return errors.Errorf("vault kms %s failed: %s", op, res.Status)
+	}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return errors.Wrapf(err, "decoding vault kms %s response", op)
+	}
+	if len(envelope.Data) == 0 {
+		return errors.Errorf("vault kms %s response has no data", op)
+	}
+	return errors.Wrapf(json.Unmarshal(envelope.Data, resp), "decoding vault kms %s response", op)
+}
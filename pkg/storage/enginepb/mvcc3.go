@@ -15,6 +15,17 @@ import "github.com/cockroachdb/errors"
 // SafeValue implements the redact.SafeValue interface.
 func (MVCCStatsDelta) SafeValue() {}
 
+// SafeValue implements the redact.SafeValue interface.
+func (IsolationLevel) SafeValue() {}
+
+// ToleratesWriteSkew returns whether transactions running at this isolation
+// level may commit at a timestamp above their read timestamp without first
+// refreshing their reads. A conflicting transaction can therefore push such a
+// transaction's timestamp at no cost to it.
+func (l IsolationLevel) ToleratesWriteSkew() bool {
+	return l == READ_COMMITTED
+}
+
 // ToStats converts the receiver to an MVCCStats.
 func (ms *MVCCStatsDelta) ToStats() MVCCStats {
 	return MVCCStats(*ms)
@@ -12,7 +12,10 @@ package streaming
 
 import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
 // CompleteIngestionHook is the hook run by the
@@ -21,3 +24,34 @@ import (
 // and eventually move to a consistent state as of the latest resolved
 // timestamp.
 var CompleteIngestionHook func(*tree.EvalContext, *kv.Txn, int) error
+
+// StreamTopologyGeneratorType is the type of the rows returned by the
+// crdb_internal.stream_topology builtin. Each row describes the span of a
+// single partition of the stream.
+var StreamTopologyGeneratorType = types.MakeLabeledTuple(
+	[]*types.T{types.Bytes, types.Bytes},
+	[]string{"start_key", "end_key"},
+)
+
+// StreamPartitionGeneratorType is the type of the rows returned by the
+// crdb_internal.stream_partition builtin. A row with a non-NULL key is a KV
+// event, where value holds the raw bytes of the roachpb.Value and timestamp its
+// MVCC timestamp. A row with a NULL key is a checkpoint event, where timestamp
+// is the resolved timestamp of the partition.
+var StreamPartitionGeneratorType = types.MakeLabeledTuple(
+	[]*types.T{types.Bytes, types.Bytes, types.Decimal},
+	[]string{"key", "value", "timestamp"},
+)
+
+// StreamTopologyHook is the hook run by the crdb_internal.stream_topology
+// builtin. It returns a generator over the partitions that a replication stream
+// of the given tenant's keyspace is split into.
+var StreamTopologyHook func(*tree.EvalContext, roachpb.TenantID) (tree.ValueGenerator, error)
+
+// StreamPartitionHook is the hook run by the crdb_internal.stream_partition
+// builtin. It returns a generator that emits the KV and checkpoint events of
+// the given partition span of a tenant's keyspace, starting after startTime.
+// An empty startTime requests an initial scan of the partition.
+var StreamPartitionHook func(
+	evalCtx *tree.EvalContext, tenantID roachpb.TenantID, span roachpb.Span, startTime hlc.Timestamp,
+) (tree.ValueGenerator, error)
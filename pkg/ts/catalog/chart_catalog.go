@@ -2304,6 +2304,32 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{Jobs, "Schedules", "Export"}},
+		Charts: []chartDescription{
+			{
+				Title: "Counts",
+				Metrics: []string{
+					"schedules.EXPORT.started",
+					"schedules.EXPORT.succeeded",
+					"schedules.EXPORT.failed",
+				},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{Jobs, "Schedules", "Changefeed"}},
+		Charts: []chartDescription{
+			{
+				Title: "Counts",
+				Metrics: []string{
+					"schedules.CHANGEFEED.started",
+					"schedules.CHANGEFEED.succeeded",
+					"schedules.CHANGEFEED.failed",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{Jobs, "Execution"}},
 		Charts: []chartDescription{
@@ -2322,6 +2348,8 @@ var charts = []sectionDescription{
 					"jobs.typedesc_schema_change.currently_running",
 					"jobs.stream_ingestion.currently_running",
 					"jobs.migration.currently_running",
+					"jobs.row_level_ttl.currently_running",
+					"jobs.export.currently_running",
 				},
 			},
 			{
@@ -2466,6 +2494,53 @@ var charts = []sectionDescription{
 					"jobs.migration.resume_retry_error",
 				},
 			},
+			{
+				Title: "Row Level TTL",
+				Metrics: []string{
+					"jobs.row_level_ttl.fail_or_cancel_completed",
+					"jobs.row_level_ttl.fail_or_cancel_failed",
+					"jobs.row_level_ttl.fail_or_cancel_retry_error",
+					"jobs.row_level_ttl.resume_completed",
+					"jobs.row_level_ttl.resume_failed",
+					"jobs.row_level_ttl.resume_retry_error",
+				},
+				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
+			{
+				Title: "Export",
+				Metrics: []string{
+					"jobs.export.fail_or_cancel_completed",
+					"jobs.export.fail_or_cancel_failed",
+					"jobs.export.fail_or_cancel_retry_error",
+					"jobs.export.resume_completed",
+					"jobs.export.resume_failed",
+					"jobs.export.resume_retry_error",
+				},
+				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
+		},
+	},
+	{
+		Organization: [][]string{{Jobs, "Row Level TTL"}},
+		Charts: []chartDescription{
+			{
+				Title: "Rows",
+				Metrics: []string{
+					"jobs.row_level_ttl.rows_selected",
+					"jobs.row_level_ttl.rows_deleted",
+				},
+				AxisLabel: "Rows",
+				Rate:      DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
+			{
+				Title: "Latency",
+				Metrics: []string{
+					"jobs.row_level_ttl.select_duration",
+					"jobs.row_level_ttl.delete_duration",
+					"jobs.row_level_ttl.range_total_duration",
+				},
+				AxisLabel: "Latency",
+			},
 		},
 	},
 }
@@ -65,6 +65,7 @@ func init() {
 	*defaultConfig.Sinks.Stderr.Redactable = false
 	// Remove all sinks other than stderr.
 	defaultConfig.Sinks.FluentServers = nil
+	defaultConfig.Sinks.HTTPServers = nil
 	defaultConfig.Sinks.FileGroups = nil
 
 	if _, err := ApplyConfig(defaultConfig); err != nil {
@@ -132,6 +133,9 @@ func ApplyConfig(config logconfig.Config) (cleanupFn func(), err error) {
 		}
 		for _, l := range sinkInfos {
 			allSinkInfos.del(l)
+			if hs, ok := l.sink.(*httpSink); ok {
+				hs.close()
+			}
 		}
 	}
 
@@ -300,6 +304,26 @@ func ApplyConfig(config logconfig.Config) (cleanupFn func(), err error) {
 		}
 	}
 
+	// Create the HTTP sinks.
+	for _, fc := range config.Sinks.HTTPServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		httpSinkInfo, err := newHTTPSinkInfo(*fc)
+		if err != nil {
+			cleanupFn()
+			return nil, err
+		}
+		sinkInfos = append(sinkInfos, httpSinkInfo)
+		allSinkInfos.put(httpSinkInfo)
+
+		// Connect the channels for this sink.
+		for _, ch := range fc.Channels.Channels {
+			l := chans[ch]
+			l.sinkInfos = append(l.sinkInfos, httpSinkInfo)
+		}
+	}
+
 	logging.setChannelLoggers(chans, &stderrSinkInfo)
 	setActive()
 
@@ -338,6 +362,17 @@ func newFluentSinkInfo(c logconfig.FluentSinkConfig) (*sinkInfo, error) {
 	return info, nil
 }
 
+// newHTTPSinkInfo creates a new httpSink and its accompanying sinkInfo
+// from the provided configuration.
+func newHTTPSinkInfo(c logconfig.HTTPSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	info.sink = newHTTPSink(c)
+	return info, nil
+}
+
 // applyConfig applies a common sink configuration to a sinkInfo.
 func (l *sinkInfo) applyConfig(c logconfig.CommonSinkConfig) error {
 	l.threshold = c.Filter
@@ -495,6 +530,39 @@ func DescribeAppliedConfig() string {
 		return nil
 	})
 
+	// Describe the HTTP sinks.
+	config.Sinks.HTTPServers = make(map[string]*logconfig.HTTPSinkConfig)
+	sIdx = 1
+	_ = allSinkInfos.iter(func(l *sinkInfo) error {
+		httpSink, ok := l.sink.(*httpSink)
+		if !ok {
+			return nil
+		}
+
+		fc := &logconfig.HTTPSinkConfig{}
+		fc.CommonSinkConfig = l.describeAppliedConfig()
+		fc.Address = httpSink.address
+		method := logconfig.HTTPSinkMethod(httpSink.method)
+		fc.Method = &method
+		fc.Timeout = &httpSink.client.Timeout
+		fc.UnsafeTLS = &httpSink.unsafeTLS
+		fc.DisableKeepAlives = &httpSink.disableKeepAlives
+		ft := logconfig.ByteSize(httpSink.flushTriggerSize)
+		fc.FlushTriggerSize = &ft
+		fc.MaxStaleness = &httpSink.maxStaleness
+		mb := logconfig.ByteSize(httpSink.maxBufferSize)
+		fc.MaxBufferSize = &mb
+
+		// Describe the connections to this HTTP sink.
+		for ch, logger := range chans {
+			describeConnections(logger, ch, l, &fc.Channels)
+		}
+		skey := fmt.Sprintf("h%d", sIdx)
+		sIdx++
+		config.Sinks.HTTPServers[skey] = fc
+		return nil
+	})
+
 	// Note: we cannot return 'config' directly, because this captures
 	// certain variables from the loggers by reference and thus could be
 	// invalidated by concurrent uses of ApplyConfig().
@@ -0,0 +1,278 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// httpSink represents an HTTP server that accepts batches of log
+// entries.
+type httpSink struct {
+	client      *http.Client
+	address     string
+	method      string
+	contentType string
+
+	// unsafeTLS and disableKeepAlives are only retained to describe
+	// the applied configuration.
+	unsafeTLS         bool
+	disableKeepAlives bool
+
+	// flushTriggerSize is the size of the pending entries above which
+	// they are sent synchronously.
+	flushTriggerSize int
+	// maxStaleness is the maximum time an entry remains pending before
+	// it is sent asynchronously.
+	maxStaleness time.Duration
+	// maxBufferSize is the maximum size of the pending entries kept
+	// while the server is unreachable.
+	maxBufferSize int
+
+	// sendMu is held while a request is in flight.
+	sendMu syncutil.Mutex
+
+	mu struct {
+		syncutil.Mutex
+
+		// pending contains the entries not sent yet, oldest first.
+		pending [][]byte
+		// pendingSize is the total size of the entries in pending.
+		pendingSize int
+		// flushTimer, if set, flushes the pending entries once
+		// maxStaleness has elapsed.
+		flushTimer *time.Timer
+		// good indicates that the last request succeeded.
+		good bool
+		// closed is set once the sink is closed; the flush timer is not
+		// armed any more afterwards.
+		closed bool
+	}
+}
+
+func newHTTPSink(c logconfig.HTTPSinkConfig) *httpSink {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = *c.DisableKeepAlives
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: *c.UnsafeTLS}
+	contentType := "text/plain"
+	if strings.HasPrefix(*c.Format, "json") {
+		contentType = "application/json"
+	}
+	l := &httpSink{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   *c.Timeout,
+		},
+		address:           c.Address,
+		method:            string(*c.Method),
+		contentType:       contentType,
+		unsafeTLS:         *c.UnsafeTLS,
+		disableKeepAlives: *c.DisableKeepAlives,
+		flushTriggerSize:  int(*c.FlushTriggerSize),
+		maxStaleness:      *c.MaxStaleness,
+		maxBufferSize:     int(*c.MaxBufferSize),
+	}
+	l.mu.good = true
+	return l
+}
+
+func (l *httpSink) String() string {
+	return fmt.Sprintf("http:%s", l.address)
+}
+
+// active implements the logSink interface.
+func (l *httpSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (l *httpSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (l *httpSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+//
+// The entry is added to the pending entries, which are sent right
+// away if they exceed the flush trigger size or if a flush was
+// requested. Otherwise, they are sent after maxStaleness at the
+// latest. While the server is unreachable, the pending entries are
+// only sent by the flush timer, so that logging calls do not wait
+// for the request timeout every time.
+func (l *httpSink) output(extraSync bool, b []byte) error {
+	l.mu.Lock()
+	l.addPendingLocked(b)
+	flush := extraSync || (l.mu.good && l.mu.pendingSize >= l.flushTriggerSize)
+	if !flush {
+		l.ensureFlushTimerLocked()
+	}
+	l.mu.Unlock()
+	if flush {
+		return l.flush()
+	}
+	return nil
+}
+
+// emergencyOutput implements the logSink interface.
+func (l *httpSink) emergencyOutput(b []byte) {
+	l.mu.Lock()
+	l.addPendingLocked(b)
+	good := l.mu.good
+	if !good {
+		// The server was not reachable the last time we tried. Don't
+		// block the caller waiting for another timeout; the entry will
+		// be sent with the next batch.
+		l.ensureFlushTimerLocked()
+	}
+	l.mu.Unlock()
+	if good {
+		_ = l.flush()
+	}
+}
+
+// close stops the flush timer, after a last attempt at sending the
+// pending entries if the server was reachable.
+func (l *httpSink) close() {
+	l.mu.Lock()
+	l.mu.closed = true
+	good := l.mu.good
+	l.mu.Unlock()
+	if good {
+		_ = l.flush()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.flushTimer != nil {
+		l.mu.flushTimer.Stop()
+		l.mu.flushTimer = nil
+	}
+}
+
+// addPendingLocked copies the entry into the pending entries.
+func (l *httpSink) addPendingLocked(b []byte) {
+	// The caller reuses the buffer, so we need our own copy.
+	l.mu.pending = append(l.mu.pending, append([]byte(nil), b...))
+	l.mu.pendingSize += len(b)
+	l.dropOldestLocked()
+}
+
+// dropOldestLocked drops the oldest pending entries if the maximum
+// buffer size is exceeded.
+func (l *httpSink) dropOldestLocked() {
+	dropped := 0
+	for l.mu.pendingSize > l.maxBufferSize && len(l.mu.pending) > 1 {
+		l.mu.pendingSize -= len(l.mu.pending[0])
+		l.mu.pending[0] = nil
+		l.mu.pending = l.mu.pending[1:]
+		dropped++
+	}
+	if dropped > 0 {
+		fmt.Fprintf(OrigStderr, "%s: buffer full, dropped %d log entries\n", l, dropped)
+	}
+}
+
+// ensureFlushTimerLocked arms the flush timer if it is not armed yet
+// and the sink is not closed.
+func (l *httpSink) ensureFlushTimerLocked() {
+	if l.mu.flushTimer == nil && !l.mu.closed {
+		l.mu.flushTimer = time.AfterFunc(l.maxStaleness, l.flushAsync)
+	}
+}
+
+// flushAsync is called by the flush timer.
+func (l *httpSink) flushAsync() {
+	l.mu.Lock()
+	l.mu.flushTimer = nil
+	l.mu.Unlock()
+	_ = l.flush()
+}
+
+// flush sends the pending entries to the server in one request. The
+// entries are taken out of the buffer under l.mu, but the request is
+// sent without holding it, so that logging calls only adding entries
+// are not blocked by a slow server. If the request fails, the entries
+// are put back to be sent again with the next batch.
+func (l *httpSink) flush() error {
+	// Only one request is in flight at a time, so that the entries are
+	// received in order.
+	l.sendMu.Lock()
+	defer l.sendMu.Unlock()
+
+	l.mu.Lock()
+	if l.mu.flushTimer != nil {
+		l.mu.flushTimer.Stop()
+		l.mu.flushTimer = nil
+	}
+	batch, batchSize := l.mu.pending, l.mu.pendingSize
+	l.mu.pending, l.mu.pendingSize = nil, 0
+	l.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := l.send(bytes.Join(batch, nil))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		if l.mu.good {
+			fmt.Fprintf(OrigStderr, "%s: error sending log entries: %v\n", l, err)
+		}
+		l.mu.good = false
+		// The entries of the batch are older than those added while the
+		// request was in flight.
+		l.mu.pending = append(batch, l.mu.pending...)
+		l.mu.pendingSize += batchSize
+		l.dropOldestLocked()
+		// Retry later, even if there is no more logging activity.
+		l.ensureFlushTimerLocked()
+		return err
+	}
+	if !l.mu.good {
+		fmt.Fprintf(OrigStderr, "%s: connection to log server resumed\n", l)
+		l.mu.good = true
+	}
+	return nil
+}
+
+// send performs one request with the given body.
+func (l *httpSink) send(body []byte) error {
+	req, err := http.NewRequest(l.method, l.address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", l.contentType)
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Newf("unexpected HTTP response: %s", resp.Status)
+	}
+	return nil
+}
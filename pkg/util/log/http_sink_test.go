@@ -0,0 +1,247 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := Scope(t)
+	defer sc.Close(t)
+
+	type request struct {
+		method      string
+		contentType string
+		body        []byte
+	}
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+		}
+		requests <- request{method: r.Method, contentType: r.Header.Get("Content-Type"), body: body}
+	}))
+	defer server.Close()
+
+	// Set up a logging configuration with the server we've just set up
+	// as target for the OPS channel. The flush trigger size is large
+	// enough for all the events below to be sent in a single batch.
+	cfg := logconfig.DefaultConfig()
+	staleness := 100 * time.Millisecond
+	cfg.Sinks.HTTPServers = map[string]*logconfig.HTTPSinkConfig{
+		"ops": {
+			Address:      server.URL,
+			MaxStaleness: &staleness,
+			Channels:     logconfig.ChannelList{Channels: []Channel{channel.OPS}}},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send two log events on the OPS channel.
+	Ops.Infof(context.Background(), "hello world")
+	Ops.Infof(context.Background(), "hello again")
+
+	// Check that the events were sent in one request once the maximum
+	// staleness elapsed.
+	var req request
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case req = <-requests:
+	}
+	require.Equal(t, "POST", req.method)
+	require.Equal(t, "application/json", req.contentType)
+
+	lines := bytes.Split(bytes.TrimSpace(req.body), []byte("\n"))
+	require.Len(t, lines, 2)
+	for i, expected := range []string{"hello world", "hello again"} {
+		var info map[string]interface{}
+		if err := json.Unmarshal(lines[i], &info); err != nil {
+			t.Fatalf("unable to decode json: %q: %v", lines[i], err)
+		}
+		require.Equal(t, expected, info["message"])
+	}
+}
+
+func TestHTTPSinkUnreachable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var fail syncutil.AtomicBool
+	fail.Set(true)
+	received := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Get() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+		}
+		received <- body
+	}))
+	defer server.Close()
+
+	cfg := logconfig.DefaultConfig()
+	require.NoError(t, cfg.Validate(nil /* defaultLogDir */))
+	fc := logconfig.HTTPSinkConfig{
+		Address:          server.URL,
+		CommonSinkConfig: cfg.HTTPDefaults.CommonSinkConfig,
+	}
+	flushTriggerSize, maxBufferSize := logconfig.ByteSize(0), logconfig.ByteSize(10)
+	staleness := time.Hour
+	fc.Method = cfg.HTTPDefaults.Method
+	fc.Timeout = cfg.HTTPDefaults.Timeout
+	fc.UnsafeTLS = cfg.HTTPDefaults.UnsafeTLS
+	fc.DisableKeepAlives = cfg.HTTPDefaults.DisableKeepAlives
+	fc.FlushTriggerSize = &flushTriggerSize
+	fc.MaxStaleness = &staleness
+	fc.MaxBufferSize = &maxBufferSize
+	s := newHTTPSink(fc)
+
+	// The first failure is reported to the caller.
+	require.Error(t, s.output(false /* extraSync */, []byte("aaaa\n")))
+	// Subsequent entries are buffered, and the oldest ones are dropped
+	// when the buffer is full.
+	require.NoError(t, s.output(false /* extraSync */, []byte("bbbb\n")))
+	require.NoError(t, s.output(false /* extraSync */, []byte("cccc\n")))
+
+	// Once the server is reachable again, the retained entries are
+	// sent with the next flush.
+	fail.Set(false)
+	require.NoError(t, s.output(true /* extraSync */, []byte("dddd\n")))
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case body := <-received:
+		require.Equal(t, "cccc\ndddd\n", string(body))
+	}
+}
+
+// newTestHTTPSink creates an httpSink sending to the given address,
+// which only flushes on demand.
+func newTestHTTPSink(t *testing.T, address string) *httpSink {
+	cfg := logconfig.DefaultConfig()
+	require.NoError(t, cfg.Validate(nil /* defaultLogDir */))
+	fc := logconfig.HTTPSinkConfig{
+		Address:          address,
+		CommonSinkConfig: cfg.HTTPDefaults.CommonSinkConfig,
+	}
+	flushTriggerSize, maxBufferSize := logconfig.ByteSize(1<<20), logconfig.ByteSize(1<<20)
+	staleness := time.Hour
+	fc.Method = cfg.HTTPDefaults.Method
+	fc.Timeout = cfg.HTTPDefaults.Timeout
+	fc.UnsafeTLS = cfg.HTTPDefaults.UnsafeTLS
+	fc.DisableKeepAlives = cfg.HTTPDefaults.DisableKeepAlives
+	fc.FlushTriggerSize = &flushTriggerSize
+	fc.MaxStaleness = &staleness
+	fc.MaxBufferSize = &maxBufferSize
+	return newHTTPSink(fc)
+}
+
+func TestHTTPSinkClose(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	received := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+		}
+		received <- body
+	}))
+	defer server.Close()
+
+	s := newTestHTTPSink(t, server.URL)
+	require.NoError(t, s.output(false /* extraSync */, []byte("aaaa\n")))
+	s.mu.Lock()
+	require.NotNil(t, s.mu.flushTimer)
+	s.mu.Unlock()
+
+	// Closing the sink sends the pending entries and stops the timer.
+	s.close()
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case body := <-received:
+		require.Equal(t, "aaaa\n", string(body))
+	}
+	require.NoError(t, s.output(false /* extraSync */, []byte("bbbb\n")))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Nil(t, s.mu.flushTimer)
+}
+
+func TestHTTPSinkSendsWithoutLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	requestStarted := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	received := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+		}
+		requestStarted <- struct{}{}
+		<-unblock
+		received <- body
+	}))
+	defer server.Close()
+
+	s := newTestHTTPSink(t, server.URL)
+	flushErr := make(chan error, 1)
+	go func() {
+		flushErr <- s.output(true /* extraSync */, []byte("aaaa\n"))
+	}()
+	<-requestStarted
+
+	// While the request is in flight, entries can still be added.
+	outputErr := make(chan error, 1)
+	go func() {
+		outputErr <- s.output(false /* extraSync */, []byte("bbbb\n"))
+	}()
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("output blocked by the in-flight request")
+	case err := <-outputErr:
+		require.NoError(t, err)
+	}
+
+	close(unblock)
+	require.NoError(t, <-flushErr)
+	require.Equal(t, "aaaa\n", string(<-received))
+	s.close()
+	require.Equal(t, "bbbb\n", string(<-received))
+}
@@ -15,6 +15,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/errors"
@@ -34,6 +35,10 @@ const DefaultStderrFormat = `crdb-v2-tty`
 // when not specified in a configuration.
 const DefaultFluentFormat = `json-fluent-compact`
 
+// DefaultHTTPFormat is the entry format for HTTP sinks
+// when not specified in a configuration.
+const DefaultHTTPFormat = `json-compact`
+
 // DefaultConfig returns a suitable default configuration when logging
 // is meant to primarily go to files.
 func DefaultConfig() (c Config) {
@@ -53,6 +58,18 @@ fluent-defaults:
     format: ` + DefaultFluentFormat + `
     redactable: true
     exit-on-error: false
+http-defaults:
+    filter: INFO
+    format: ` + DefaultHTTPFormat + `
+    redactable: true
+    exit-on-error: false
+    method: POST
+    timeout: 2s
+    unsafe-tls: false
+    disable-keep-alives: false
+    flush-trigger-size: 256kib
+    max-staleness: 1s
+    max-buffer-size: 10mib
 sinks:
   stderr:
     filter: NONE
@@ -101,6 +118,11 @@ type Config struct {
 	// configuration value.
 	FluentDefaults FluentDefaults `yaml:"fluent-defaults,omitempty"`
 
+	// HTTPDefaults represents the default configuration for HTTP sinks,
+	// inherited when a specific HTTP sink config does not provide a
+	// configuration value.
+	HTTPDefaults HTTPDefaults `yaml:"http-defaults,omitempty"`
+
 	// Sinks represents the sink configurations.
 	Sinks SinkConfig `yaml:",omitempty"`
 
@@ -163,13 +185,16 @@ type SinkConfig struct {
 	FileGroups map[string]*FileSinkConfig `yaml:"file-groups,omitempty"`
 	// FluentServer represents the list of configured fluent sinks.
 	FluentServers map[string]*FluentSinkConfig `yaml:"fluent-servers,omitempty"`
+	// HTTPServers represents the list of configured http sinks.
+	HTTPServers map[string]*HTTPSinkConfig `yaml:"http-servers,omitempty"`
 	// Stderr represents the configuration for the stderr sink.
 	Stderr StderrSinkConfig `yaml:",omitempty"`
 
-	// sortedFileGroupNames and sortedServerNames are used internally to
-	// make the Export() function deterministic.
-	sortedFileGroupNames []string
-	sortedServerNames    []string
+	// sortedFileGroupNames, sortedServerNames and sortedHTTPServerNames
+	// are used internally to make the Export() function deterministic.
+	sortedFileGroupNames  []string
+	sortedServerNames     []string
+	sortedHTTPServerNames []string
 }
 
 // StderrSinkConfig represents the configuration for the stderr sink.
@@ -314,6 +339,156 @@ type FluentSinkConfig struct {
 	serverName string
 }
 
+// HTTPDefaults represent configuration defaults for HTTP sinks.
+type HTTPDefaults struct {
+	// Method is the HTTP method used to send the logging events.
+	// Can be POST or PUT.
+	Method *HTTPSinkMethod `yaml:",omitempty"`
+
+	// Timeout is the maximum duration of one HTTP request, including
+	// the connection establishment.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// UnsafeTLS disables the verification of the server's TLS
+	// certificate. This should only be used for testing.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	// DisableKeepAlives causes every request to use a new connection.
+	DisableKeepAlives *bool `yaml:"disable-keep-alives,omitempty"`
+
+	// FlushTriggerSize is the size of the buffered logging events
+	// above which they are sent to the server. If zero, every
+	// logging event is sent in its own request.
+	FlushTriggerSize *ByteSize `yaml:"flush-trigger-size,omitempty"`
+
+	// MaxStaleness is the maximum time a logging event can remain
+	// buffered before it is sent to the server.
+	MaxStaleness *time.Duration `yaml:"max-staleness,omitempty"`
+
+	// MaxBufferSize is the maximum size of the logging events kept in
+	// memory while the server is unreachable. When this size is
+	// exceeded, the oldest events are dropped.
+	MaxBufferSize *ByteSize `yaml:"max-buffer-size,omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// HTTPSinkConfig represents the configuration for one HTTP sink.
+//
+// User-facing documentation follows.
+// TITLE: output to HTTP servers
+//
+// This sink type causes logging data to be sent over the network, as
+// requests to an HTTP server.
+//
+// Logging events are buffered in memory and sent in batches: the body
+// of each request contains one or more events in the configured
+// format, separated by newlines. A batch is sent when the buffered
+// events exceed `flush-trigger-size`, or when the oldest buffered
+// event is older than `max-staleness`.
+//
+// If the server is unreachable or responds with an error status, the
+// events are kept in memory and sent again with the next batch, up to
+// `max-buffer-size`; the oldest events are dropped beyond that size.
+// If `exit-on-error` is set, the process terminates instead when a
+// batch cannot be sent synchronously with a logging call.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `http-servers`. Example configuration:
+//
+//     sinks:
+//        http-servers:          # HTTP configurations start here
+//           health:             # defines one sink called "health"
+//              channels: HEALTH
+//              address: http://127.0.0.1:8080/logs
+//
+// A cascading defaults mechanism is available for configurations:
+// every new server sink configured automatically inherits the
+// configurations set in the `http-defaults` section.
+//
+// For example:
+//
+//      http-defaults:
+//          timeout: 5s # default: wait at most 5s for each request
+//      sinks:
+//        http-servers:
+//          health:
+//             channels: HEALTH
+//             # This sink has timeout set to 5s,
+//             # as the setting is inherited from http-defaults
+//             # unless overridden here.
+//
+// The default output format for HTTP sinks is `json-compact`.
+//
+// Users are invited to peruse the `check-log-config` tool to
+// verify the effect of defaults inheritance.
+//
+type HTTPSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelList `yaml:",omitempty,flow"`
+
+	// Address is the URL of the HTTP server, including the scheme,
+	// e.g.: https://logs.example.com:8080/ingest.
+	Address string `yaml:""`
+
+	// Method is the HTTP method used to send the logging events.
+	// Can be POST or PUT.
+	// Inherited from `http-defaults.method` if not specified.
+	Method *HTTPSinkMethod `yaml:",omitempty"`
+
+	// Timeout is the maximum duration of one HTTP request.
+	// Inherited from `http-defaults.timeout` if not specified.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// UnsafeTLS disables the verification of the server's TLS
+	// certificate.
+	// Inherited from `http-defaults.unsafe-tls` if not specified.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	// DisableKeepAlives causes every request to use a new connection.
+	// Inherited from `http-defaults.disable-keep-alives` if not specified.
+	DisableKeepAlives *bool `yaml:"disable-keep-alives,omitempty"`
+
+	// FlushTriggerSize is the size of the buffered logging events
+	// above which they are sent to the server.
+	// Inherited from `http-defaults.flush-trigger-size` if not specified.
+	FlushTriggerSize *ByteSize `yaml:"flush-trigger-size,omitempty"`
+
+	// MaxStaleness is the maximum time a logging event can remain
+	// buffered before it is sent to the server.
+	// Inherited from `http-defaults.max-staleness` if not specified.
+	MaxStaleness *time.Duration `yaml:"max-staleness,omitempty"`
+
+	// MaxBufferSize is the maximum size of the logging events kept in
+	// memory while the server is unreachable.
+	// Inherited from `http-defaults.max-buffer-size` if not specified.
+	MaxBufferSize *ByteSize `yaml:"max-buffer-size,omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+
+	// serverName is populated/used during validation.
+	serverName string
+}
+
+// HTTPSinkMethod is the HTTP method used by an HTTP sink.
+type HTTPSinkMethod string
+
+// The HTTP methods supported by HTTP sinks.
+const (
+	HTTPSinkMethodPost HTTPSinkMethod = "POST"
+	HTTPSinkMethodPut  HTTPSinkMethod = "PUT"
+)
+
 // FileDefaults represent configuration defaults for file sinks.
 type FileDefaults struct {
 	// Dir stores the default output directory for file sinks.
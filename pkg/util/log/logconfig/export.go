@@ -175,6 +175,8 @@ func (c *Config) Export(onlyChans ChannelList) (string, string) {
 	//
 	// servers maps each server to its box declaration.
 	servers := map[string]string{}
+	// httpServers maps each HTTP server to its box declaration.
+	httpServers := map[string]string{}
 	for _, fn := range c.Sinks.sortedServerNames {
 		fc := c.Sinks.FluentServers[fn]
 		if fc.Filter == logpb.Severity_NONE {
@@ -198,6 +200,29 @@ func (c *Config) Export(onlyChans ChannelList) (string, string) {
 		}
 	}
 
+	for _, fn := range c.Sinks.sortedHTTPServerNames {
+		fc := c.Sinks.HTTPServers[fn]
+		if fc.Filter == logpb.Severity_NONE {
+			continue
+		}
+		skey := fmt.Sprintf("h__%s", fc.serverName)
+		target, thisprocs, thislinks := process(skey, fc.CommonSinkConfig)
+		hasLink := false
+		for _, ch := range fc.Channels.Channels {
+			if !chanSel.HasChannel(ch) {
+				continue
+			}
+			hasLink = true
+			links = append(links, fmt.Sprintf("%s --> %s", ch, target))
+		}
+		if hasLink {
+			processing = append(processing, thisprocs...)
+			links = append(links, thislinks...)
+			httpServers[fc.serverName] = fmt.Sprintf("queue %s as \"http: %s\"",
+				skey, fc.Address)
+		}
+	}
+
 	// Export the stderr redirects.
 	if c.Sinks.Stderr.Filter != logpb.Severity_NONE {
 		target, thisprocs, thislinks := process("stderr", c.Sinks.Stderr.CommonSinkConfig)
@@ -238,11 +263,14 @@ func (c *Config) Export(onlyChans ChannelList) (string, string) {
 	}
 
 	// Represent the network servers, if any.
-	if len(c.Sinks.sortedServerNames) > 0 {
+	if len(c.Sinks.sortedServerNames) > 0 || len(c.Sinks.sortedHTTPServerNames) > 0 {
 		buf.WriteString("cloud network {\n")
 		for _, s := range c.Sinks.sortedServerNames {
 			fmt.Fprintf(&buf, " %s\n", servers[s])
 		}
+		for _, s := range c.Sinks.sortedHTTPServerNames {
+			fmt.Fprintf(&buf, " %s\n", httpServers[s])
+		}
 		buf.WriteString("}\n")
 	}
 
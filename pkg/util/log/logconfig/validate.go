@@ -13,9 +13,11 @@ package logconfig
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/errors"
@@ -49,6 +51,9 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Filter == logpb.Severity_UNKNOWN {
 		c.FluentDefaults.Filter = logpb.Severity_INFO
 	}
+	if c.HTTPDefaults.Filter == logpb.Severity_UNKNOWN {
+		c.HTTPDefaults.Filter = logpb.Severity_INFO
+	}
 	// Sinks are not auditable by default.
 	if c.FileDefaults.Auditable == nil {
 		c.FileDefaults.Auditable = &bf
@@ -56,6 +61,9 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Auditable == nil {
 		c.FluentDefaults.Auditable = &bf
 	}
+	if c.HTTPDefaults.Auditable == nil {
+		c.HTTPDefaults.Auditable = &bf
+	}
 	// File sinks are buffered by default.
 	if c.FileDefaults.BufferedWrites == nil {
 		c.FileDefaults.BufferedWrites = &bt
@@ -69,6 +77,10 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		s := DefaultFluentFormat
 		c.FluentDefaults.Format = &s
 	}
+	if c.HTTPDefaults.Format == nil {
+		s := DefaultHTTPFormat
+		c.HTTPDefaults.Format = &s
+	}
 	// No redaction markers -> default keep them.
 	if c.FileDefaults.Redactable == nil {
 		c.FileDefaults.Redactable = &bt
@@ -76,6 +88,9 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Redactable == nil {
 		c.FluentDefaults.Redactable = &bt
 	}
+	if c.HTTPDefaults.Redactable == nil {
+		c.HTTPDefaults.Redactable = &bt
+	}
 	// No redaction specification -> default false.
 	if c.FileDefaults.Redact == nil {
 		c.FileDefaults.Redact = &bf
@@ -83,13 +98,23 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Redact == nil {
 		c.FluentDefaults.Redact = &bf
 	}
-	// No criticality -> default true for files, false for fluent.
+	if c.HTTPDefaults.Redact == nil {
+		c.HTTPDefaults.Redact = &bf
+	}
+	// No criticality -> default true for files, false for network sinks.
 	if c.FileDefaults.Criticality == nil {
 		c.FileDefaults.Criticality = &bt
 	}
 	if c.FluentDefaults.Criticality == nil {
 		c.FluentDefaults.Criticality = &bf
 	}
+	if c.HTTPDefaults.Criticality == nil {
+		c.HTTPDefaults.Criticality = &bf
+	}
+	// HTTP-specific defaults.
+	if err := c.HTTPDefaults.validate(); err != nil {
+		fmt.Fprintf(&errBuf, "http-defaults: %v\n", err)
+	}
 
 	// Validate and fill in defaults for file sinks.
 	for prefix, fc := range c.Sinks.FileGroups {
@@ -115,6 +140,18 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Validate and defaults for HTTP.
+	for serverName, fc := range c.Sinks.HTTPServers {
+		if fc == nil {
+			fc = &HTTPSinkConfig{}
+			c.Sinks.HTTPServers[serverName] = fc
+		}
+		fc.serverName = serverName
+		if err := c.validateHTTPSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "http server %q: %v\n", serverName, err)
+		}
+	}
+
 	// Defaults for stderr.
 	c.inheritCommonDefaults(&c.Sinks.Stderr.CommonSinkConfig, &c.FileDefaults.CommonSinkConfig)
 	if c.Sinks.Stderr.Filter == logpb.Severity_UNKNOWN {
@@ -136,6 +173,8 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	fileSinks := make(map[logpb.Channel]*FileSinkConfig)
 	// fluentSinks maps channels to fluent servers.
 	fluentSinks := make(map[logpb.Channel]*FluentSinkConfig)
+	// httpSinks maps channels to http servers.
+	httpSinks := make(map[logpb.Channel]*HTTPSinkConfig)
 
 	// Check that no channel is listed by more than one file sink,
 	// and every file has at least one channel.
@@ -175,6 +214,23 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Check that no channel is listed by more than one http sink, and
+	// every sink has at least one channel.
+	for _, fc := range c.Sinks.HTTPServers {
+		if len(fc.Channels.Channels) == 0 {
+			fmt.Fprintf(&errBuf, "http server %q: no channel selected\n", fc.serverName)
+		}
+		fc.Channels.Sort()
+		for _, ch := range fc.Channels.Channels {
+			if prev := httpSinks[ch]; prev != nil {
+				fmt.Fprintf(&errBuf, "http server %q: channel %s already captured by server %q\n",
+					fc.serverName, ch, prev.serverName)
+			} else {
+				httpSinks[ch] = fc
+			}
+		}
+	}
+
 	// If capture-stray-errors was enabled, then perform some additional
 	// validation on it.
 	if c.CaptureFd2.Enable {
@@ -257,12 +313,25 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// httpServerNames collects the names of the http servers, for the
+	// same reason.
+	httpServerNames := make([]string, 0, len(c.Sinks.HTTPServers))
+	for serverName, fc := range c.Sinks.HTTPServers {
+		if fc.Filter == logpb.Severity_NONE {
+			delete(c.Sinks.HTTPServers, serverName)
+		} else {
+			httpServerNames = append(httpServerNames, serverName)
+		}
+	}
+
 	// Remember the sorted names, so we get deterministic output in
 	// export.
 	sort.Strings(fileGroupNames)
 	c.Sinks.sortedFileGroupNames = fileGroupNames
 	sort.Strings(serverNames)
 	c.Sinks.sortedServerNames = serverNames
+	sort.Strings(httpServerNames)
+	c.Sinks.sortedHTTPServerNames = httpServerNames
 
 	return nil
 }
@@ -363,6 +432,133 @@ func (c *Config) validateFluentSinkConfig(fc *FluentSinkConfig) error {
 	return nil
 }
 
+func (c *Config) validateHTTPSinkConfig(fc *HTTPSinkConfig) error {
+	c.inheritCommonDefaults(&fc.CommonSinkConfig, &c.HTTPDefaults.CommonSinkConfig)
+
+	// Inherit HTTP-specific defaults.
+	if fc.Method == nil {
+		fc.Method = c.HTTPDefaults.Method
+	}
+	if fc.Timeout == nil {
+		fc.Timeout = c.HTTPDefaults.Timeout
+	}
+	if fc.UnsafeTLS == nil {
+		fc.UnsafeTLS = c.HTTPDefaults.UnsafeTLS
+	}
+	if fc.DisableKeepAlives == nil {
+		fc.DisableKeepAlives = c.HTTPDefaults.DisableKeepAlives
+	}
+	if fc.FlushTriggerSize == nil {
+		fc.FlushTriggerSize = c.HTTPDefaults.FlushTriggerSize
+	}
+	if fc.MaxStaleness == nil {
+		fc.MaxStaleness = c.HTTPDefaults.MaxStaleness
+	}
+	if fc.MaxBufferSize == nil {
+		fc.MaxBufferSize = c.HTTPDefaults.MaxBufferSize
+	}
+	if err := validateHTTPParams(
+		fc.Method, *fc.Timeout, *fc.FlushTriggerSize, *fc.MaxStaleness, *fc.MaxBufferSize,
+	); err != nil {
+		return err
+	}
+
+	fc.Address = strings.TrimSpace(fc.Address)
+	if fc.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	u, err := url.Parse(fc.Address)
+	if err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Newf("unsupported address scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.Newf("address must include a host: %q", fc.Address)
+	}
+
+	// Apply the auditable flag if set.
+	if *fc.Auditable {
+		bt := true
+		fc.Criticality = &bt
+	}
+	fc.Auditable = nil
+
+	return nil
+}
+
+// validate populates the missing HTTP-specific defaults and checks
+// their values.
+func (d *HTTPDefaults) validate() error {
+	bf := false
+	if d.Method == nil {
+		m := HTTPSinkMethodPost
+		d.Method = &m
+	}
+	if d.Timeout == nil {
+		t := defaultHTTPTimeout
+		d.Timeout = &t
+	}
+	if d.UnsafeTLS == nil {
+		d.UnsafeTLS = &bf
+	}
+	if d.DisableKeepAlives == nil {
+		d.DisableKeepAlives = &bf
+	}
+	if d.FlushTriggerSize == nil {
+		s := defaultHTTPFlushTriggerSize
+		d.FlushTriggerSize = &s
+	}
+	if d.MaxStaleness == nil {
+		t := defaultHTTPMaxStaleness
+		d.MaxStaleness = &t
+	}
+	if d.MaxBufferSize == nil {
+		s := defaultHTTPMaxBufferSize
+		d.MaxBufferSize = &s
+	}
+	return validateHTTPParams(
+		d.Method, *d.Timeout, *d.FlushTriggerSize, *d.MaxStaleness, *d.MaxBufferSize,
+	)
+}
+
+// Defaults for HTTP sinks, used when the configuration does not
+// start from DefaultConfig().
+const (
+	defaultHTTPTimeout                   = 2 * time.Second
+	defaultHTTPFlushTriggerSize ByteSize = 256 << 10
+	defaultHTTPMaxStaleness              = time.Second
+	defaultHTTPMaxBufferSize    ByteSize = 10 << 20
+)
+
+func validateHTTPParams(
+	method *HTTPSinkMethod,
+	timeout time.Duration,
+	flushTriggerSize ByteSize,
+	maxStaleness time.Duration,
+	maxBufferSize ByteSize,
+) error {
+	m := HTTPSinkMethod(strings.ToUpper(strings.TrimSpace(string(*method))))
+	switch m {
+	case HTTPSinkMethodPost, HTTPSinkMethodPut:
+		*method = m
+	default:
+		return errors.Newf("unsupported method: %q", *method)
+	}
+	if timeout <= 0 {
+		return errors.Newf("timeout must be positive: %s", timeout)
+	}
+	if maxStaleness <= 0 {
+		return errors.Newf("max-staleness must be positive: %s", maxStaleness)
+	}
+	if maxBufferSize < flushTriggerSize {
+		return errors.Newf("max-buffer-size (%s) cannot be smaller than flush-trigger-size (%s)",
+			maxBufferSize, flushTriggerSize)
+	}
+	return nil
+}
+
 func normalizeDir(dir **string) error {
 	if *dir == nil {
 		return nil
@@ -11,6 +11,8 @@
 package sequence
 
 import (
+	"context"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -22,11 +24,15 @@ import (
 // takes a sequence name as an arg. Returns the name of the sequence or nil
 // if no sequence was found.
 func GetSequenceFromFunc(funcExpr *tree.FuncExpr) (*string, error) {
+	if o := funcExpr.ResolvedOverload(); o != nil && o.UDF != nil {
+		// User-defined functions never take sequence names as arguments.
+		return nil, nil
+	}
 	searchPath := sessiondata.SearchPath{}
 
 	// Resolve doesn't use the searchPath for resolving FunctionDefinitions
 	// so we can pass in an empty SearchPath.
-	def, err := funcExpr.Func.Resolve(searchPath)
+	def, err := funcExpr.Func.Resolve(context.TODO(), searchPath, nil /* resolver */)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +33,9 @@ type crdbSpan struct {
 	spanID       uint64 // probabilistically unique
 	parentSpanID uint64
 	goroutineID  uint64
+	// otlp completes the W3C trace context of the span, and indicates whether
+	// the span is exported to the OpenTelemetry collector when it finishes.
+	otlp otlpContext
 
 	operation string
 	startTime time.Time
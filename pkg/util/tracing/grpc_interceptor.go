@@ -107,7 +107,9 @@ var gRPCComponentTag = opentracing.Tag{Key: string(ext.Component), Value: "gRPC"
 //
 // All gRPC server spans will look for an tracing SpanMeta in the gRPC
 // metadata; if found, the server span will act as the ChildOf that RPC
-// SpanMeta.
+// SpanMeta. The SpanMeta can also be made of just a W3C trace context (the
+// traceparent key), as propagated by the clients instrumented with
+// OpenTelemetry.
 //
 // Root or not, the server Span will be embedded in the context.Context for the
 // application-specific gRPC handler(s) to access.
@@ -155,7 +157,9 @@ func ServerInterceptor(tracer *Tracer) grpc.UnaryServerInterceptor {
 //
 // All gRPC server spans will look for a SpanMeta in the gRPC
 // metadata; if found, the server span will act as the ChildOf that RPC
-// SpanMeta.
+// SpanMeta. The SpanMeta can also be made of just a W3C trace context (the
+// traceparent key), as propagated by the clients instrumented with
+// OpenTelemetry.
 //
 // Root or not, the server Span will be embedded in the context.Context for the
 // application-specific gRPC handler(s) to access.
@@ -0,0 +1,327 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/otlppb"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
+	"github.com/cockroachdb/logtags"
+	"github.com/gogo/protobuf/proto"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+)
+
+const (
+	// otlpQueueSize is the number of finished spans which can wait to be
+	// exported. Spans finished while the queue is full are dropped.
+	otlpQueueSize = 10000
+	// otlpMaxBatchSize is the maximum number of spans exported at once.
+	otlpMaxBatchSize = 512
+	// otlpFlushInterval is the maximum time a finished span waits before
+	// being exported.
+	otlpFlushInterval = time.Second
+	// otlpExportTimeout is the timeout of the export of a batch of spans.
+	otlpExportTimeout = 10 * time.Second
+	// otlpServiceName is the name of the service which produces the spans.
+	otlpServiceName = "cockroach"
+	// otlpLibraryName is the name of the instrumentation library which
+	// produces the spans.
+	otlpLibraryName = "github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+var otlpLogEveryN = util.Every(5 * time.Second)
+
+// otlpExporter sends finished spans to an OpenTelemetry collector, using the
+// OTLP/gRPC protocol. Spans are queued when they finish, and are exported in
+// batches by a background goroutine.
+type otlpExporter struct {
+	addr   string
+	conn   *grpc.ClientConn
+	client otlppb.TraceServiceClient
+	// resource describes this process in the exported spans.
+	resource *otlppb.Resource
+
+	spans   chan *otlppb.Span
+	dropped int64 // accessed atomically
+	stopper chan struct{}
+	done    chan struct{}
+}
+
+// newOTLPExporter creates an exporter which sends spans to the collector at
+// the given address. The connection is established in the background.
+func newOTLPExporter(addr string) (*otlpExporter, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	e := &otlpExporter{
+		addr:   addr,
+		conn:   conn,
+		client: otlppb.NewTraceServiceClient(conn),
+		resource: &otlppb.Resource{
+			Attributes: []otlppb.KeyValue{otlpStringAttribute("service.name", otlpServiceName)},
+		},
+		spans:   make(chan *otlppb.Span, otlpQueueSize),
+		stopper: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		e.resource.Attributes = append(e.resource.Attributes, otlpStringAttribute("host.name", hostname))
+	}
+	go e.run()
+	return e, nil
+}
+
+// export queues a finished span for export.
+func (e *otlpExporter) export(sp *otlppb.Span) {
+	select {
+	case e.spans <- sp:
+	default:
+		atomic.AddInt64(&e.dropped, 1)
+	}
+}
+
+// run exports the queued spans until the exporter is closed, at which point
+// the remaining spans are flushed.
+func (e *otlpExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+	batch := make([]*otlppb.Span, 0, otlpMaxBatchSize)
+	for {
+		select {
+		case sp := <-e.spans:
+			batch = append(batch, sp)
+			if len(batch) < otlpMaxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-e.stopper:
+			for {
+				select {
+				case sp := <-e.spans:
+					batch = append(batch, sp)
+					if len(batch) == otlpMaxBatchSize {
+						e.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					e.flush(batch)
+					return
+				}
+			}
+		}
+		e.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+// flush exports a batch of spans.
+func (e *otlpExporter) flush(batch []*otlppb.Span) {
+	if dropped := atomic.SwapInt64(&e.dropped, 0); dropped > 0 {
+		e.logf("dropped %d spans because the export queue was full", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	req := &otlppb.ExportTraceServiceRequest{
+		ResourceSpans: []*otlppb.ResourceSpans{{
+			Resource: e.resource,
+			InstrumentationLibrarySpans: []*otlppb.InstrumentationLibrarySpans{{
+				InstrumentationLibrary: &otlppb.InstrumentationLibrary{Name: otlpLibraryName},
+				Spans:                  batch,
+			}},
+		}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+	defer cancel()
+	if _, err := e.client.Export(ctx, req); err != nil {
+		e.logf("failed to export %d spans: %v", len(batch), err)
+	}
+}
+
+func (e *otlpExporter) logf(format string, args ...interface{}) {
+	if otlpLogEveryN.ShouldProcess(timeutil.Now()) {
+		// We can't use `log` from this package so print to stderr, like the
+		// Zipkin collector.
+		fmt.Fprintf(os.Stderr, "OpenTelemetry exporter (%s): %s\n", e.addr, fmt.Sprintf(format, args...))
+	}
+}
+
+// Close flushes the queued spans and closes the connection to the collector.
+func (e *otlpExporter) Close() {
+	close(e.stopper)
+	<-e.done
+	_ = e.conn.Close()
+}
+
+// setOTLPExporter starts exporting the sampled spans to the collector at the
+// given address, or stops exporting them if the address is empty.
+func (t *Tracer) setOTLPExporter(addr string) {
+	if old := t.getOTLPExporter(); (old == nil && addr == "") || (old != nil && old.addr == addr) {
+		return
+	}
+	var e *otlpExporter
+	if addr != "" {
+		var err error
+		if e, err = newOTLPExporter(addr); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to export traces to %s: %v\n", addr, err)
+		}
+	}
+	if old := atomic.SwapPointer(&t.otlpExporter, unsafe.Pointer(e)); old != nil {
+		(*otlpExporter)(old).Close()
+	}
+}
+
+func (t *Tracer) getOTLPExporter() *otlpExporter {
+	return (*otlpExporter)(atomic.LoadPointer(&t.otlpExporter))
+}
+
+// otlpSampleRate returns the fraction of new traces which are exported.
+func (t *Tracer) otlpSampleRate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&t._otlpSampleRate))
+}
+
+// maybeSampleOTLPTrace returns the otlpContext of a new trace, which is
+// sampled according to trace.opentelemetry.sample_rate if spans are exported
+// to a collector.
+func (t *Tracer) maybeSampleOTLPTrace() otlpContext {
+	if t.getOTLPExporter() == nil || rand.Float64() >= t.otlpSampleRate() {
+		return otlpContext{}
+	}
+	return otlpContext{
+		traceIDHigh: uint64(rand.Int63()),
+		sampled:     true,
+	}
+}
+
+// otlpSpan converts the finished span into an OTLP span.
+func (s *crdbSpan) otlpSpan() *otlppb.Span {
+	sp := &otlppb.Span{
+		TraceID:           make([]byte, 16),
+		SpanID:            make([]byte, 8),
+		Name:              s.operation,
+		Kind:              otlppb.Span_SPAN_KIND_INTERNAL,
+		StartTimeUnixNano: uint64(s.startTime.UnixNano()),
+	}
+	binary.BigEndian.PutUint64(sp.TraceID[:8], s.otlp.traceIDHigh)
+	binary.BigEndian.PutUint64(sp.TraceID[8:], s.traceID)
+	binary.BigEndian.PutUint64(sp.SpanID, s.spanID)
+	if s.parentSpanID != 0 {
+		sp.ParentSpanID = make([]byte, 8)
+		binary.BigEndian.PutUint64(sp.ParentSpanID, s.parentSpanID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endTime := s.startTime.Add(s.mu.duration)
+	sp.EndTimeUnixNano = uint64(endTime.UnixNano())
+
+	if s.logTags != nil {
+		setLogTags(s.logTags.Get(), func(remappedKey string, tag *logtags.Tag) {
+			sp.Attributes = append(sp.Attributes, otlpStringAttribute(remappedKey, tag.ValueStr()))
+		})
+	}
+	for k, v := range s.mu.tags {
+		switch k {
+		case string(ext.SpanKind):
+			switch fmt.Sprint(v) {
+			case string(ext.SpanKindRPCServerEnum):
+				sp.Kind = otlppb.Span_SPAN_KIND_SERVER
+			case string(ext.SpanKindRPCClientEnum):
+				sp.Kind = otlppb.Span_SPAN_KIND_CLIENT
+			case string(ext.SpanKindProducerEnum):
+				sp.Kind = otlppb.Span_SPAN_KIND_PRODUCER
+			case string(ext.SpanKindConsumerEnum):
+				sp.Kind = otlppb.Span_SPAN_KIND_CONSUMER
+			}
+			continue
+		case string(ext.Error):
+			if v == true {
+				sp.Status = &otlppb.Status{Code: otlppb.Status_STATUS_CODE_ERROR}
+			}
+		}
+		sp.Attributes = append(sp.Attributes, otlpAttribute(k, v))
+	}
+	sort.Slice(sp.Attributes, func(i, j int) bool {
+		return sp.Attributes[i].Key < sp.Attributes[j].Key
+	})
+
+	for _, r := range s.mu.recording.recordedLogs {
+		ev := otlppb.Span_Event{TimeUnixNano: uint64(r.Timestamp.UnixNano())}
+		for _, f := range r.Fields {
+			if f.Key() == tracingpb.LogMessageField {
+				ev.Name = fmt.Sprint(f.Value())
+			} else {
+				ev.Attributes = append(ev.Attributes, otlpAttribute(f.Key(), f.Value()))
+			}
+		}
+		sp.Events = append(sp.Events, ev)
+	}
+	// Structured payloads aren't timestamped, so they are attached to the end
+	// of the span.
+	for _, item := range s.mu.structured {
+		sp.Events = append(sp.Events, otlppb.Span_Event{
+			TimeUnixNano: sp.EndTimeUnixNano,
+			Name:         proto.MessageName(item),
+			Attributes: []otlppb.KeyValue{
+				otlpStringAttribute("payload", strings.TrimSpace(item.String())),
+			},
+		})
+	}
+	return sp
+}
+
+func otlpStringAttribute(key, value string) otlppb.KeyValue {
+	return otlppb.KeyValue{
+		Key:   key,
+		Value: &otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// otlpAttribute converts a tag or a log field into an attribute, preserving
+// the type of the common scalar values.
+func otlpAttribute(key string, value interface{}) otlppb.KeyValue {
+	kv := otlppb.KeyValue{Key: key, Value: &otlppb.AnyValue{}}
+	switch v := value.(type) {
+	case bool:
+		kv.Value.Value = &otlppb.AnyValue_BoolValue{BoolValue: v}
+	case int:
+		kv.Value.Value = &otlppb.AnyValue_IntValue{IntValue: int64(v)}
+	case int32:
+		kv.Value.Value = &otlppb.AnyValue_IntValue{IntValue: int64(v)}
+	case int64:
+		kv.Value.Value = &otlppb.AnyValue_IntValue{IntValue: v}
+	case uint32:
+		kv.Value.Value = &otlppb.AnyValue_IntValue{IntValue: int64(v)}
+	case float32:
+		kv.Value.Value = &otlppb.AnyValue_DoubleValue{DoubleValue: float64(v)}
+	case float64:
+		kv.Value.Value = &otlppb.AnyValue_DoubleValue{DoubleValue: v}
+	default:
+		kv.Value.Value = &otlppb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}
+	}
+	return kv
+}
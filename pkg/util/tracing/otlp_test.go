@@ -0,0 +1,275 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/otlppb"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	for _, tc := range []struct {
+		traceParent string
+		expErr      string
+		exp         SpanMeta
+	}{
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			exp: SpanMeta{
+				traceID: 0x8448eb211c80319c,
+				spanID:  0xb7ad6b7169203331,
+				otlp:    otlpContext{traceIDHigh: 0x0af7651916cd43dd, sampled: true},
+			},
+		},
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+			exp: SpanMeta{
+				traceID: 0x8448eb211c80319c,
+				spanID:  0xb7ad6b7169203331,
+				otlp:    otlpContext{traceIDHigh: 0x0af7651916cd43dd},
+			},
+		},
+		{
+			// Future versions may add fields.
+			traceParent: "01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra",
+			exp: SpanMeta{
+				traceID: 0x8448eb211c80319c,
+				spanID:  0xb7ad6b7169203331,
+				otlp:    otlpContext{traceIDHigh: 0x0af7651916cd43dd, sampled: true},
+			},
+		},
+		{
+			traceParent: "00-0af7651916cd43dd0000000000000000-b7ad6b7169203331-01",
+			exp: SpanMeta{
+				traceID: 1,
+				spanID:  0xb7ad6b7169203331,
+				otlp:    otlpContext{traceIDHigh: 0x0af7651916cd43dd, sampled: true},
+			},
+		},
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331",
+			expErr:      "too short",
+		},
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-",
+			expErr:      "unsupported format",
+		},
+		{
+			traceParent: "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			expErr:      "unsupported format",
+		},
+		{
+			traceParent: "00-0AF7651916CD43DD8448EB211C80319C-b7ad6b7169203331-01",
+			expErr:      "invalid hexadecimal digit",
+		},
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c_b7ad6b7169203331-01",
+			expErr:      "malformed",
+		},
+		{
+			traceParent: "00-00000000000000000000000000000000-b7ad6b7169203331-01",
+			expErr:      "zero trace ID",
+		},
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01",
+			expErr:      "zero parent ID",
+		},
+	} {
+		t.Run(tc.traceParent, func(t *testing.T) {
+			sm, err := ParseTraceParent(tc.traceParent)
+			if tc.expErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, *sm)
+		})
+	}
+}
+
+// fakeCollector is an OpenTelemetry collector which accumulates the spans it
+// receives.
+type fakeCollector struct {
+	mu    sync.Mutex
+	spans []*otlppb.Span
+}
+
+var _ otlppb.TraceServiceServer = (*fakeCollector)(nil)
+
+// Export implements the otlppb.TraceServiceServer interface.
+func (c *fakeCollector) Export(
+	_ context.Context, req *otlppb.ExportTraceServiceRequest,
+) (*otlppb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			c.spans = append(c.spans, ils.Spans...)
+		}
+	}
+	return &otlppb.ExportTraceServiceResponse{}, nil
+}
+
+// getSpans returns the spans received by the collector, by name. It fails if
+// a span was received twice.
+func (c *fakeCollector) getSpans(t *testing.T) map[string]*otlppb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[string]*otlppb.Span)
+	for _, sp := range c.spans {
+		require.Nil(t, spans[sp.Name], "span %s exported twice", sp.Name)
+		spans[sp.Name] = sp
+	}
+	return spans
+}
+
+func startFakeCollector(t *testing.T) (*fakeCollector, string, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &fakeCollector{}
+	s := grpc.NewServer()
+	otlppb.RegisterTraceServiceServer(s, c)
+	go func() { _ = s.Serve(ln) }()
+	return c, ln.Addr().String(), s.Stop
+}
+
+func TestOTLPExport(t *testing.T) {
+	collector, addr, stop := startFakeCollector(t)
+	defer stop()
+
+	tr := NewTracer()
+	sv := settings.Values{}
+	require.NoError(t, settings.NewUpdater(&sv).Set("trace.opentelemetry.collector", addr, "s"))
+	tr.Configure(&sv)
+	defer tr.Close()
+
+	// Continue the trace of a client, as if it came over gRPC.
+	const traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	remote, err := tr.ExtractMetaFrom(metadataCarrier{metadata.Pairs(fieldNameTraceParent, traceParent)})
+	require.NoError(t, err)
+
+	root := tr.StartSpan("root", WithParentAndManualCollection(remote),
+		WithTags(ext.SpanKindRPCServer))
+	require.False(t, root.isNoop())
+	root.SetTag("count", 3)
+	root.SetTag(string(ext.Error), true)
+	child := tr.StartSpan("child", WithParentAndAutoCollection(root))
+	child.SetVerbose(true)
+	child.Record("hello")
+	child.RecordStructured(&otlppb.InstrumentationLibrary{Name: "test"})
+
+	// The trace context of the children propagates the trace ID of the client
+	// and the sampling decision.
+	carrier := MapCarrier{Map: map[string]string{}}
+	require.NoError(t, tr.InjectMetaInto(child.Meta(), carrier))
+	require.Equal(t,
+		"00-0af7651916cd43dd8448eb211c80319c-"+hex.EncodeToString(uint64Bytes(child.crdb.spanID))+"-01",
+		carrier.Map[fieldNameTraceParent])
+
+	child.Finish()
+	root.Finish()
+	// Finishing a span twice doesn't export it twice.
+	root.Finish()
+
+	var spans map[string]*otlppb.Span
+	require.Eventually(t, func() bool {
+		spans = collector.getSpans(t)
+		return len(spans) == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	traceID, err := hex.DecodeString("0af7651916cd43dd8448eb211c80319c")
+	require.NoError(t, err)
+	rootSpan, childSpan := spans["root"], spans["child"]
+	require.Equal(t, traceID, rootSpan.TraceID)
+	require.Equal(t, traceID, childSpan.TraceID)
+	require.Equal(t, uint64Bytes(root.crdb.spanID), rootSpan.SpanID)
+	require.Equal(t, uint64Bytes(0xb7ad6b7169203331), rootSpan.ParentSpanID)
+	require.Equal(t, uint64Bytes(child.crdb.spanID), childSpan.SpanID)
+	require.Equal(t, rootSpan.SpanID, childSpan.ParentSpanID)
+
+	require.Equal(t, otlppb.Span_SPAN_KIND_SERVER, rootSpan.Kind)
+	require.Equal(t, otlppb.Span_SPAN_KIND_INTERNAL, childSpan.Kind)
+	require.Equal(t, otlppb.Status_STATUS_CODE_ERROR, rootSpan.Status.Code)
+	require.Equal(t, []otlppb.KeyValue{
+		{Key: "count", Value: &otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: 3}}},
+		{Key: "error", Value: &otlppb.AnyValue{Value: &otlppb.AnyValue_BoolValue{BoolValue: true}}},
+	}, rootSpan.Attributes)
+	require.True(t, rootSpan.EndTimeUnixNano >= rootSpan.StartTimeUnixNano)
+
+	// The structured payload is also recorded as a message, since the span is
+	// verbose.
+	require.Len(t, childSpan.Events, 3)
+	require.Equal(t, "hello", childSpan.Events[0].Name)
+	require.Equal(t, `name:"test"`, childSpan.Events[1].Name)
+	require.Equal(t, "opentelemetry.proto.collector.trace.v1.InstrumentationLibrary",
+		childSpan.Events[2].Name)
+}
+
+func TestOTLPSampling(t *testing.T) {
+	collector, addr, stop := startFakeCollector(t)
+	defer stop()
+
+	tr := NewTracer()
+	sv := settings.Values{}
+	require.NoError(t, settings.NewUpdater(&sv).Set("trace.opentelemetry.collector", addr, "s"))
+	openTelemetrySampleRate.Override(&sv, 0)
+	tr.Configure(&sv)
+
+	// New traces aren't sampled, and their spans don't need to be real.
+	sp := tr.StartSpan("unsampled")
+	require.True(t, sp.isNoop())
+	require.Nil(t, sp.Meta())
+	sp.Finish()
+
+	// Traces continued from remote spans follow their sampling decision.
+	remote, err := ParseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	require.NoError(t, err)
+	sp = tr.StartSpan("remote unsampled", WithParentAndManualCollection(remote))
+	require.True(t, sp.isNoop())
+	sp.Finish()
+	remote, err = ParseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	require.NoError(t, err)
+	sp = tr.StartSpan("remote sampled", WithParentAndManualCollection(remote))
+	require.False(t, sp.isNoop())
+	sp.Finish()
+
+	openTelemetrySampleRate.Override(&sv, 1)
+	sp = tr.StartSpan("sampled")
+	require.False(t, sp.isNoop())
+	sp.Finish()
+
+	// Closing the tracer flushes the spans.
+	tr.Close()
+	spans := collector.getSpans(t)
+	require.Len(t, spans, 2)
+	require.NotNil(t, spans["remote sampled"])
+	require.NotNil(t, spans["sampled"])
+	require.Nil(t, tr.getOTLPExporter())
+}
+
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -88,6 +88,10 @@ type SpanMeta struct {
 	shadowTracerType string
 	shadowCtx        opentracing.SpanContext
 
+	// The rest of the W3C trace context of the remote Span, including whether
+	// its trace is exported to the OpenTelemetry collector.
+	otlp otlpContext
+
 	// If set, all spans derived from this context are being recorded.
 	//
 	// NB: at the time of writing, this is only ever set to RecordingVerbose
@@ -178,7 +182,8 @@ func (s *Span) IsBlackHole() bool {
 // or corresponds to a "no-op" Span. If this is true, any Span
 // derived from this context will be a "black hole Span".
 func (sm *SpanMeta) isNilOrNoop() bool {
-	return sm == nil || (sm.recordingType == RecordingOff && sm.shadowTracerType == "")
+	return sm == nil ||
+		(sm.recordingType == RecordingOff && sm.shadowTracerType == "" && !sm.otlp.sampled)
 }
 
 // SpanStats are stats that can be added to a Span.
@@ -219,8 +224,14 @@ func (s *Span) Finish() {
 	}
 	finishTime := time.Now()
 	s.crdb.mu.Lock()
+	alreadyFinished := s.crdb.mu.duration != -1
 	s.crdb.mu.duration = finishTime.Sub(s.crdb.startTime)
 	s.crdb.mu.Unlock()
+	if s.crdb.otlp.sampled && !alreadyFinished {
+		if e := s.tracer.getOTLPExporter(); e != nil {
+			e.export(s.crdb.otlpSpan())
+		}
+	}
 	if s.ot.shadowSpan != nil {
 		s.ot.shadowSpan.Finish()
 	}
@@ -241,9 +252,10 @@ func (s *Span) Meta() *SpanMeta {
 	var spanID uint64
 	var recordingType RecordingType
 	var baggage map[string]string
+	var otlp otlpContext
 
 	if s.crdb != nil {
-		traceID, spanID = s.crdb.traceID, s.crdb.spanID
+		traceID, spanID, otlp = s.crdb.traceID, s.crdb.spanID, s.crdb.otlp
 		s.crdb.mu.Lock()
 		defer s.crdb.mu.Unlock()
 		n := len(s.crdb.mu.baggage)
@@ -268,6 +280,7 @@ func (s *Span) Meta() *SpanMeta {
 		spanID == 0 &&
 		shadowTrTyp == "" &&
 		shadowCtx == nil &&
+		otlp == (otlpContext{}) &&
 		recordingType == 0 &&
 		baggage == nil {
 		return nil
@@ -277,6 +290,7 @@ func (s *Span) Meta() *SpanMeta {
 		spanID:           spanID,
 		shadowTracerType: shadowTrTyp,
 		shadowCtx:        shadowCtx,
+		otlp:             otlp,
 		recordingType:    recordingType,
 		Baggage:          baggage,
 	}
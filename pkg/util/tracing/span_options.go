@@ -47,6 +47,21 @@ func (opts *spanOptions) parentSpanID() uint64 {
 	return 0
 }
 
+// parentOTLPContext returns the otlpContext inherited from the parent, if
+// any. False is returned if the span is the root of a new trace.
+func (opts *spanOptions) parentOTLPContext() (otlpContext, bool) {
+	if opts.Parent != nil {
+		if opts.Parent.isNoop() {
+			// The trace of a noop span is not exported.
+			return otlpContext{}, true
+		}
+		return opts.Parent.crdb.otlp, true
+	} else if opts.RemoteParent != nil && opts.RemoteParent.traceID != 0 {
+		return opts.RemoteParent.otlp, true
+	}
+	return otlpContext{}, false
+}
+
 func (opts *spanOptions) recordingType() RecordingType {
 	recordingType := RecordingOff
 	if opts.Parent != nil && !opts.Parent.isNoop() {
@@ -0,0 +1,117 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+)
+
+// fieldNameTraceParent is the key of the W3C trace context in carriers. It
+// is understood by tracers other than ours, which makes it possible to
+// continue traces started outside of CockroachDB.
+const fieldNameTraceParent = "traceparent"
+
+// otlpContext is the part of the W3C trace context of a span which isn't
+// already tracked by its crdbSpan. W3C trace IDs have 128 bits: the low 64
+// bits are the traceID of the crdbSpan, and the high 64 bits are traceIDHigh.
+// The W3C span ID is the spanID of the crdbSpan.
+type otlpContext struct {
+	traceIDHigh uint64
+	// sampled is set if the spans of the trace are exported to the
+	// OpenTelemetry collector.
+	sampled bool
+}
+
+// traceParent formats the W3C trace context of the span, as specified in
+// https://www.w3.org/TR/trace-context/#traceparent-header.
+func (sm *SpanMeta) traceParent() string {
+	var flags byte
+	if sm.otlp.sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%016x%016x-%016x-%02x", sm.otlp.traceIDHigh, sm.traceID, sm.spanID, flags)
+}
+
+// ParseTraceParent parses the W3C trace context of a span, in the format of
+// the traceparent HTTP header (for example
+// "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"). The returned
+// SpanMeta can be passed to WithParentAndManualCollection to continue the
+// trace of the span; the resulting spans are exported to the OpenTelemetry
+// collector, if one is configured, when the span was sampled.
+func ParseTraceParent(traceParent string) (*SpanMeta, error) {
+	// The format is version-traceid-parentid-flags. Future versions may append
+	// fields, which are ignored.
+	const length = 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(traceParent) < length {
+		return nil, errors.Newf("invalid trace context %q: too short", traceParent)
+	}
+	version := traceParent[0:2]
+	if version == "ff" ||
+		(version == "00" && len(traceParent) != length) ||
+		(len(traceParent) > length && traceParent[length] != '-') {
+		return nil, errors.Newf("invalid trace context %q: unsupported format", traceParent)
+	}
+	if traceParent[2] != '-' || traceParent[35] != '-' || traceParent[52] != '-' {
+		return nil, errors.Newf("invalid trace context %q: malformed", traceParent)
+	}
+	var fields [4]uint64
+	for i, field := range []string{
+		traceParent[0:2],   // version
+		traceParent[3:19],  // high bits of the trace ID
+		traceParent[19:35], // low bits of the trace ID
+		traceParent[36:52], // parent ID
+	} {
+		v, err := parseLowerHex(field)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trace context %q", traceParent)
+		}
+		fields[i] = v
+	}
+	flags, err := parseLowerHex(traceParent[53:55])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid trace context %q", traceParent)
+	}
+	traceIDHigh, traceID, spanID := fields[1], fields[2], fields[3]
+	if traceIDHigh == 0 && traceID == 0 {
+		return nil, errors.Newf("invalid trace context %q: zero trace ID", traceParent)
+	}
+	if spanID == 0 {
+		return nil, errors.Newf("invalid trace context %q: zero parent ID", traceParent)
+	}
+	if traceID == 0 {
+		// Our trace IDs are the low bits of W3C trace IDs, and a zero trace ID
+		// stands for the absence of a trace. Only a tiny fraction of the traces
+		// of other tracers are affected by this substitution.
+		traceID = 1
+	}
+	return &SpanMeta{
+		traceID: traceID,
+		spanID:  spanID,
+		otlp: otlpContext{
+			traceIDHigh: traceIDHigh,
+			sampled:     flags&1 != 0,
+		},
+	}, nil
+}
+
+// parseLowerHex parses a hexadecimal number made of lowercase digits, as
+// required by the W3C trace context.
+func parseLowerHex(s string) (uint64, error) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return 0, errors.Newf("invalid hexadecimal digit %q", c)
+		}
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
@@ -13,6 +13,7 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -116,6 +117,25 @@ var zipkinCollector = settings.RegisterStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 ).WithPublic()
 
+var openTelemetryCollector = settings.RegisterStringSetting(
+	"trace.opentelemetry.collector",
+	"if set, sampled traces go to the given OpenTelemetry collector using the OTLP/gRPC protocol (example: '127.0.0.1:4317')",
+	envutil.EnvOrDefaultString("COCKROACH_TEST_OPENTELEMETRY_COLLECTOR", ""),
+).WithPublic()
+
+var openTelemetrySampleRate = settings.RegisterFloatSetting(
+	"trace.opentelemetry.sample_rate",
+	"the probability that a trace started by this node goes to the OpenTelemetry collector; "+
+		"traces continued from a remote span follow the sampling decision of that span",
+	1,
+	func(f float64) error {
+		if f < 0 || f > 1 {
+			return errors.New("value must be between 0 and 1 inclusive")
+		}
+		return nil
+	},
+)
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -127,6 +147,9 @@ var zipkinCollector = settings.RegisterStringSetting(
 //  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
 //    Span inside each of our spans.
 //
+//  - OpenTelemetry traces. Sampled spans are converted to OTLP spans when they
+//    finish, and are exported to a collector.
+//
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
 // lightstep disabled) because of its recording capability (verbose tracing needs
 // to work in all cases).
@@ -147,6 +170,13 @@ type Tracer struct {
 	// Pointer to shadowTracer, if using one.
 	shadowTracer unsafe.Pointer
 
+	// Pointer to otlpExporter, if exporting spans to an OpenTelemetry
+	// collector.
+	otlpExporter unsafe.Pointer
+	// The fraction of new traces which are exported to the OpenTelemetry
+	// collector, as the bits of a float64. Accessed atomically.
+	_otlpSampleRate uint64
+
 	// activeSpans is a map that references all non-Finish'ed local root spans,
 	// i.e. those for which no WithLocalParent(<non-nil>) option was supplied.
 	// It also elides spans created using WithBypassRegistry.
@@ -192,6 +222,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
+		atomic.StoreUint64(&t._otlpSampleRate, math.Float64bits(openTelemetrySampleRate.Get(sv)))
+		t.setOTLPExporter(openTelemetryCollector.Get(sv))
 		var nt int32
 		if enableNetTrace.Get(sv) {
 			nt = 1
@@ -204,6 +236,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	openTelemetryCollector.SetOnChange(sv, reconfigure)
+	openTelemetrySampleRate.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {
@@ -214,6 +248,8 @@ func (t *Tracer) useNetTrace() bool {
 func (t *Tracer) Close() {
 	// Clean up any shadow tracer.
 	t.setShadowTracer(nil, nil)
+	// Flush the spans to be exported to the OpenTelemetry collector.
+	t.setOTLPExporter("")
 }
 
 func (t *Tracer) setShadowTracer(manager shadowTracerManager, tr opentracing.Tracer) {
@@ -292,15 +328,26 @@ func (t *Tracer) startSpanGeneric(
 		opts.LogTags = logtags.FromContext(ctx)
 	}
 
+	// Spans inherit the sampling decision of their parent for the export to
+	// the OpenTelemetry collector. The decision is made when starting new
+	// traces.
+	otlp, ok := opts.parentOTLPContext()
+	if !ok {
+		otlp = t.maybeSampleOTLPTrace()
+	}
+	exportToOTLP := otlp.sampled && t.getOTLPExporter() != nil
+
 	// Avoid creating a real span when possible. If tracing is globally
 	// enabled, we always need to create spans. If the incoming
 	// span is recording (which implies that there is a parent) then
-	// we also have to create a real child. Additionally, if the
+	// we also have to create a real child. Likewise, spans exported to the
+	// OpenTelemetry collector need to be real. Additionally, if the
 	// caller explicitly asked for a real span they need to get one.
 	// In all other cases, a noop span will do.
 	if !t.AlwaysTrace() &&
 		opts.recordingType() == RecordingOff &&
-		!opts.ForceRealSpan {
+		!opts.ForceRealSpan &&
+		!exportToOTLP {
 		return maybeWrapCtx(ctx, nil /* octx */, t.noopSpan)
 	}
 
@@ -390,6 +437,7 @@ func (t *Tracer) startSpanGeneric(
 		operation:    opName,
 		startTime:    startTime,
 		parentSpanID: opts.parentSpanID(),
+		otlp:         otlp,
 		logTags:      opts.LogTags,
 		mu: crdbSpanMu{
 			duration: -1, // unfinished
@@ -549,6 +597,11 @@ func (t *Tracer) InjectMetaInto(sm *SpanMeta, carrier Carrier) error {
 
 	carrier.Set(fieldNameTraceID, strconv.FormatUint(sm.traceID, 16))
 	carrier.Set(fieldNameSpanID, strconv.FormatUint(sm.spanID, 16))
+	if sm.otlp.sampled {
+		// The W3C trace context carries the sampling decision of the trace,
+		// along with the high bits of the trace ID.
+		carrier.Set(fieldNameTraceParent, sm.traceParent())
+	}
 
 	for k, v := range sm.Baggage {
 		carrier.Set(prefixBaggage+k, v)
@@ -596,6 +649,7 @@ func (t *Tracer) ExtractMetaFrom(carrier Carrier) (*SpanMeta, error) {
 	var traceID uint64
 	var spanID uint64
 	var baggage map[string]string
+	var traceParent *SpanMeta
 
 	// TODO(tbg): ForeachKey forces things on the heap. We can do better
 	// by using an explicit carrier.
@@ -615,6 +669,10 @@ func (t *Tracer) ExtractMetaFrom(carrier Carrier) (*SpanMeta, error) {
 			}
 		case fieldNameShadowType:
 			shadowType = v
+		case fieldNameTraceParent:
+			// An invalid trace context is ignored, as mandated by the W3C
+			// specification.
+			traceParent, _ = ParseTraceParent(v)
 		default:
 			if strings.HasPrefix(k, prefixBaggage) {
 				if baggage == nil {
@@ -634,6 +692,17 @@ func (t *Tracer) ExtractMetaFrom(carrier Carrier) (*SpanMeta, error) {
 	if err != nil {
 		return noopSpanMeta, err
 	}
+	var otlp otlpContext
+	if traceParent != nil {
+		if traceID == 0 && spanID == 0 {
+			// The remote span was not created by a CockroachDB node, and is
+			// only identified by its W3C trace context.
+			traceID, spanID = traceParent.traceID, traceParent.spanID
+		}
+		if traceID == traceParent.traceID && spanID == traceParent.spanID {
+			otlp = traceParent.otlp
+		}
+	}
 	if traceID == 0 && spanID == 0 {
 		return noopSpanMeta, nil
 	}
@@ -670,6 +739,7 @@ func (t *Tracer) ExtractMetaFrom(carrier Carrier) (*SpanMeta, error) {
 		spanID:           spanID,
 		shadowTracerType: shadowType,
 		shadowCtx:        shadowCtx,
+		otlp:             otlp,
 		recordingType:    recordingType,
 		Baggage:          baggage,
 	}, nil
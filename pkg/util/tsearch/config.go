@@ -0,0 +1,248 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// maxLexemeLength is the length in bytes of the longest word that is turned
+// into a lexeme. Longer words are skipped.
+const maxLexemeLength = 2047
+
+// config is a text search configuration, which determines how the words of a
+// document or query are turned into lexemes.
+type config struct {
+	// stopwords are words that are too common to be useful in a search, and
+	// are dropped. They are matched after lowercasing.
+	stopwords map[string]struct{}
+	// stem, if set, reduces a lowercased word to its stem.
+	stem func(string) string
+}
+
+var configs = map[string]*config{
+	"simple": {},
+	"english": {
+		stopwords: englishStopwords,
+		stem:      stemEnglish,
+	},
+}
+
+// DefaultConfig is the text search configuration used by the functions that
+// don't take a configuration argument. It corresponds to the Postgres
+// default_text_search_config setting, which defaults to english.
+const DefaultConfig = "english"
+
+// ValidConfigNames returns the names of the supported text search
+// configurations, in sorted order.
+func ValidConfigNames() []string {
+	ret := make([]string, 0, len(configs))
+	for name := range configs {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func getConfig(name string) (*config, error) {
+	c, ok := configs[strings.TrimPrefix(strings.ToLower(name), "pg_catalog.")]
+	if !ok {
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"text search configuration %q does not exist", name)
+	}
+	return c, nil
+}
+
+// lexize turns a word into a lexeme. It returns false if the word is a
+// stopword.
+func (c *config) lexize(word string) (string, bool) {
+	word = strings.ToLower(word)
+	if _, ok := c.stopwords[word]; ok {
+		return "", false
+	}
+	if c.stem != nil {
+		word = c.stem(word)
+	}
+	return word, true
+}
+
+// tokenize splits the input into words, which are maximal runs of letters
+// and digits.
+func tokenize(input string) []string {
+	words := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	ret := words[:0]
+	for _, w := range words {
+		if len(w) <= maxLexemeLength {
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// ToTSVector parses the given document into a TSVector, using the named text
+// search configuration to normalize its words. It implements the to_tsvector
+// builtin. Every word of the document, including stopwords, takes up a
+// position.
+func ToTSVector(configName string, document string) (TSVector, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return nil, err
+	}
+	var ret TSVector
+	for i, w := range tokenize(document) {
+		lexeme, ok := c.lexize(w)
+		if !ok {
+			continue
+		}
+		pos := i + 1
+		if pos > maxTSPosition {
+			pos = maxTSPosition
+		}
+		ret = append(ret, tsTerm{
+			lexeme:    lexeme,
+			positions: []tsPosition{{position: uint16(pos)}},
+		})
+	}
+	return normalizeTSVector(ret), nil
+}
+
+// ToTSQuery parses the given text as a TSQuery and normalizes each of its
+// operands using the named text search configuration. It implements the
+// to_tsquery builtin. An operand that is made of several words, like
+// 'fat-cat', is replaced by a phrase of their lexemes, and stopwords are
+// removed from the query.
+func ToTSQuery(configName string, input string) (TSQuery, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	q, err := ParseTSQuery(input)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	return TSQuery{root: c.normalizeNode(q.root)}, nil
+}
+
+// normalizeNode returns a copy of the tree rooted at n with all lexemes
+// normalized. It returns nil if the tree only contains stopwords.
+func (c *config) normalizeNode(n *tsNode) *tsNode {
+	if n == nil {
+		return nil
+	}
+	switch n.op {
+	case lexemeOp:
+		var ret *tsNode
+		distance := 1
+		for _, w := range tokenize(n.lexeme) {
+			lexeme, ok := c.lexize(w)
+			if !ok {
+				// A stopword still takes up a position in the phrase.
+				if ret != nil {
+					distance++
+				}
+				continue
+			}
+			leaf := &tsNode{op: lexemeOp, lexeme: lexeme, weights: n.weights, prefix: n.prefix}
+			if ret == nil {
+				ret = leaf
+			} else {
+				ret = &tsNode{op: followedByOp, distance: uint16(distance), l: ret, r: leaf}
+			}
+			distance = 1
+		}
+		return ret
+	case notOp:
+		l := c.normalizeNode(n.l)
+		if l == nil {
+			return nil
+		}
+		return &tsNode{op: notOp, l: l}
+	}
+	l, r := c.normalizeNode(n.l), c.normalizeNode(n.r)
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+	return &tsNode{op: n.op, distance: n.distance, l: l, r: r}
+}
+
+// PlainToTSQuery returns a TSQuery that matches documents that contain all of
+// the words of the given text, after normalizing them with the named text
+// search configuration. It implements the plainto_tsquery builtin.
+func PlainToTSQuery(configName string, input string) (TSQuery, error) {
+	return wordsToTSQuery(configName, input, andOp)
+}
+
+// PhraseToTSQuery returns a TSQuery that matches documents that contain the
+// words of the given text in order, after normalizing them with the named
+// text search configuration. It implements the phraseto_tsquery builtin.
+func PhraseToTSQuery(configName string, input string) (TSQuery, error) {
+	return wordsToTSQuery(configName, input, followedByOp)
+}
+
+func wordsToTSQuery(configName string, input string, op tsOperator) (TSQuery, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	var root *tsNode
+	distance := 1
+	for _, w := range tokenize(input) {
+		lexeme, ok := c.lexize(w)
+		if !ok {
+			if root != nil {
+				distance++
+			}
+			continue
+		}
+		leaf := &tsNode{op: lexemeOp, lexeme: lexeme}
+		if root == nil {
+			root = leaf
+		} else {
+			root = &tsNode{op: op, l: root, r: leaf}
+			if op == followedByOp {
+				root.distance = uint16(distance)
+			}
+		}
+		distance = 1
+	}
+	return TSQuery{root: root}, nil
+}
+
+// englishStopwords is the Postgres list of English stopwords.
+var englishStopwords = makeStopwords(`
+i me my myself we our ours ourselves you your yours yourself yourselves he
+him his himself she her hers herself it its itself they them their theirs
+themselves what which who whom this that these those am is are was were be
+been being have has had having do does did doing a an the and but if or
+because as until while of at by for with about against between into through
+during before after above below to from up down in out on off over under
+again further then once here there when where why how all any both each few
+more most other some such no nor not only own same so than too very s t can
+will just don should now
+`)
+
+func makeStopwords(words string) map[string]struct{} {
+	ret := make(map[string]struct{})
+	for _, w := range strings.Fields(words) {
+		ret[w] = struct{}{}
+	}
+	return ret
+}
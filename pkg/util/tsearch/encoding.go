@@ -0,0 +1,294 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// EncodeTSVector appends the value encoding of the given TSVector to
+// appendTo. The encoding is the number of terms followed by, for each term,
+// the length of the lexeme, the lexeme, the number of positions and the
+// positions, with the weight stored in the low two bits.
+func EncodeTSVector(appendTo []byte, v TSVector) []byte {
+	appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(v)))
+	for _, t := range v {
+		appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(t.lexeme)))
+		appendTo = append(appendTo, t.lexeme...)
+		appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(t.positions)))
+		for _, p := range t.positions {
+			appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(p.position)<<2|uint64(p.weight))
+		}
+	}
+	return appendTo
+}
+
+// DecodeTSVector decodes a TSVector that was encoded with EncodeTSVector.
+func DecodeTSVector(b []byte) (TSVector, error) {
+	b, n, err := decodeUvarint(b)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(TSVector, n)
+	for i := range ret {
+		var l, np uint64
+		if b, l, err = decodeUvarint(b); err != nil {
+			return nil, err
+		}
+		if uint64(len(b)) < l {
+			return nil, errors.AssertionFailedf("invalid encoded tsvector: lexeme too short")
+		}
+		ret[i].lexeme, b = string(b[:l]), b[l:]
+		if b, np, err = decodeUvarint(b); err != nil {
+			return nil, err
+		}
+		if np > 0 {
+			ret[i].positions = make([]tsPosition, np)
+		}
+		for j := range ret[i].positions {
+			var p uint64
+			if b, p, err = decodeUvarint(b); err != nil {
+				return nil, err
+			}
+			ret[i].positions[j] = tsPosition{position: uint16(p >> 2), weight: tsWeight(p & 3)}
+		}
+	}
+	if len(b) > 0 {
+		return nil, errors.AssertionFailedf("invalid encoded tsvector: %d trailing bytes", len(b))
+	}
+	return ret, nil
+}
+
+// EncodeTSQuery appends the value encoding of the given TSQuery to appendTo.
+// The encoding is the prefix-order serialization of the nodes of the query
+// tree, each starting with its operator.
+func EncodeTSQuery(appendTo []byte, q TSQuery) []byte {
+	q.walk(func(n *tsNode) {
+		appendTo = append(appendTo, byte(n.op))
+		switch n.op {
+		case lexemeOp:
+			appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(n.lexeme)))
+			appendTo = append(appendTo, n.lexeme...)
+			flags := byte(n.weights)
+			if n.prefix {
+				flags |= 1 << 4
+			}
+			appendTo = append(appendTo, flags)
+		case followedByOp:
+			appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(n.distance))
+		}
+	})
+	return appendTo
+}
+
+// DecodeTSQuery decodes a TSQuery that was encoded with EncodeTSQuery.
+func DecodeTSQuery(b []byte) (TSQuery, error) {
+	if len(b) == 0 {
+		return TSQuery{}, nil
+	}
+	var decode func() (*tsNode, error)
+	decode = func() (*tsNode, error) {
+		if len(b) == 0 {
+			return nil, errors.AssertionFailedf("invalid encoded tsquery: unexpected end")
+		}
+		n := &tsNode{op: tsOperator(b[0])}
+		b = b[1:]
+		var err error
+		switch n.op {
+		case lexemeOp:
+			var l uint64
+			if b, l, err = decodeUvarint(b); err != nil {
+				return nil, err
+			}
+			if uint64(len(b)) < l+1 {
+				return nil, errors.AssertionFailedf("invalid encoded tsquery: lexeme too short")
+			}
+			n.lexeme = string(b[:l])
+			flags := b[l]
+			b = b[l+1:]
+			n.weights = tsWeightMask(flags & 0xf)
+			n.prefix = flags&(1<<4) != 0
+			return n, nil
+		case followedByOp:
+			var d uint64
+			if b, d, err = decodeUvarint(b); err != nil {
+				return nil, err
+			}
+			n.distance = uint16(d)
+		case andOp, orOp, notOp:
+		default:
+			return nil, errors.AssertionFailedf("invalid encoded tsquery: unknown operator %d", n.op)
+		}
+		if n.l, err = decode(); err != nil {
+			return nil, err
+		}
+		if n.op != notOp {
+			if n.r, err = decode(); err != nil {
+				return nil, err
+			}
+		}
+		return n, nil
+	}
+	root, err := decode()
+	if err != nil {
+		return TSQuery{}, err
+	}
+	if len(b) > 0 {
+		return TSQuery{}, errors.AssertionFailedf("invalid encoded tsquery: %d trailing bytes", len(b))
+	}
+	return TSQuery{root: root}, nil
+}
+
+// EncodeTSVectorPGBinary appends the Postgres binary wire format of the given
+// TSVector to appendTo. The format is the number of lexemes followed by, for
+// each lexeme, the NUL-terminated lexeme, the number of positions and the
+// positions, with the weight stored in the high two bits.
+func EncodeTSVectorPGBinary(appendTo []byte, v TSVector) []byte {
+	appendTo = encoding.EncodeUint32Ascending(appendTo, uint32(len(v)))
+	for _, t := range v {
+		appendTo = append(appendTo, t.lexeme...)
+		appendTo = append(appendTo, 0)
+		appendTo = appendUint16(appendTo, uint16(len(t.positions)))
+		for _, p := range t.positions {
+			appendTo = appendUint16(appendTo, uint16(p.weight)<<14|p.position)
+		}
+	}
+	return appendTo
+}
+
+// EncodeTSQueryPGBinary appends the Postgres binary wire format of the given
+// TSQuery to appendTo. The format is the number of nodes followed by the
+// nodes in prefix order, with the right operand of a binary operator before
+// its left operand, as Postgres stores them.
+func EncodeTSQueryPGBinary(appendTo []byte, q TSQuery) []byte {
+	var n uint32
+	q.walk(func(*tsNode) { n++ })
+	appendTo = encoding.EncodeUint32Ascending(appendTo, n)
+	var encode func(n *tsNode)
+	encode = func(n *tsNode) {
+		if n.op == lexemeOp {
+			// QI_VAL.
+			appendTo = append(appendTo, 1, byte(n.weights))
+			if n.prefix {
+				appendTo = append(appendTo, 1)
+			} else {
+				appendTo = append(appendTo, 0)
+			}
+			appendTo = append(appendTo, n.lexeme...)
+			appendTo = append(appendTo, 0)
+			return
+		}
+		// QI_OPR, followed by the Postgres operator number.
+		appendTo = append(appendTo, 2)
+		switch n.op {
+		case notOp:
+			appendTo = append(appendTo, 1)
+		case andOp:
+			appendTo = append(appendTo, 2)
+		case orOp:
+			appendTo = append(appendTo, 3)
+		case followedByOp:
+			appendTo = append(appendTo, 4)
+			appendTo = appendUint16(appendTo, n.distance)
+		}
+		if n.r != nil {
+			encode(n.r)
+		}
+		encode(n.l)
+	}
+	if q.root != nil {
+		encode(q.root)
+	}
+	return appendTo
+}
+
+func appendUint16(appendTo []byte, v uint16) []byte {
+	return append(appendTo, byte(v>>8), byte(v))
+}
+
+func decodeUvarint(b []byte) ([]byte, uint64, error) {
+	b, _, v, err := encoding.DecodeNonsortingUvarint(b)
+	return b, v, err
+}
+
+// EncodeInvertedIndexKeys returns the inverted index keys for the given
+// TSVector, one per lexeme. The input inKey is prefixed to all returned keys.
+func EncodeInvertedIndexKeys(inKey []byte, v TSVector) ([][]byte, error) {
+	outKeys := make([][]byte, 0, len(v))
+	for _, t := range v {
+		outKey := make([]byte, len(inKey), len(inKey)+len(t.lexeme)+3)
+		copy(outKey, inKey)
+		outKeys = append(outKeys, encoding.EncodeStringAscending(outKey, t.lexeme))
+	}
+	return outKeys, nil
+}
+
+// GetInvertedExpr returns the inverted expression that must be evaluated over
+// an inverted index on a TSVector column to find the rows that may match the
+// query. Conjunctions and disjunctions of lexemes map to intersections and
+// unions of the spans of the lexemes. A prefix lexeme maps to the span of all
+// the lexemes with that prefix. Weights and followed by operators require
+// positions, which are not stored in the index, so their expressions are not
+// tight. Negations can't be evaluated using the index at all.
+//
+// An inverted.NonInvertedColExpression is returned if the index can't be used
+// to evaluate the query.
+func (q TSQuery) GetInvertedExpr() (inverted.Expression, error) {
+	if q.root == nil {
+		return inverted.NonInvertedColExpression{}, nil
+	}
+	return q.root.getInvertedExpr()
+}
+
+func (n *tsNode) getInvertedExpr() (inverted.Expression, error) {
+	switch n.op {
+	case lexemeOp:
+		key := encoding.EncodeStringAscending(nil, n.lexeme)
+		if n.prefix {
+			// Strip the terminator of the encoded string so that the span covers
+			// all the lexemes that start with this one.
+			key = key[:len(key)-2]
+		}
+		span := inverted.Span{
+			Start: inverted.EncVal(key),
+			End:   inverted.EncVal(roachpb.Key(key).PrefixEnd()),
+		}
+		expr := inverted.ExprForSpan(span, n.weights == 0 /* tight */)
+		// A row only has one key per lexeme, so a span over a single lexeme
+		// can't produce duplicate primary keys.
+		expr.Unique = !n.prefix
+		return expr, nil
+	case notOp:
+		return inverted.NonInvertedColExpression{}, nil
+	}
+	l, err := n.l.getInvertedExpr()
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.r.getInvertedExpr()
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case andOp:
+		return inverted.And(l, r), nil
+	case orOp:
+		return inverted.Or(l, r), nil
+	case followedByOp:
+		expr := inverted.And(l, r)
+		expr.SetNotTight()
+		return expr, nil
+	}
+	return nil, errors.AssertionFailedf("unknown tsquery operator %d", n.op)
+}
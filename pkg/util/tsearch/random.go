@@ -0,0 +1,65 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "math/rand"
+
+// randomLexeme returns a short random lexeme made of lowercase letters, so
+// that random vectors and queries have a good chance of sharing lexemes.
+func randomLexeme(rng *rand.Rand) string {
+	b := make([]byte, 1+rng.Intn(3))
+	for i := range b {
+		b[i] = byte('a' + rng.Intn(4))
+	}
+	return string(b)
+}
+
+// RandomTSVector returns a random TSVector for testing.
+func RandomTSVector(rng *rand.Rand) TSVector {
+	ret := make(TSVector, rng.Intn(6))
+	for i := range ret {
+		ret[i].lexeme = randomLexeme(rng)
+		if n := rng.Intn(4); n > 0 {
+			ret[i].positions = make([]tsPosition, n)
+			for j := range ret[i].positions {
+				ret[i].positions[j] = tsPosition{
+					position: uint16(1 + rng.Intn(20)),
+					weight:   tsWeight(rng.Intn(4)),
+				}
+			}
+		}
+	}
+	return normalizeTSVector(ret)
+}
+
+// RandomTSQuery returns a random, non-empty TSQuery for testing.
+func RandomTSQuery(rng *rand.Rand) TSQuery {
+	var gen func(depth int) *tsNode
+	gen = func(depth int) *tsNode {
+		if depth == 0 || rng.Intn(3) == 0 {
+			n := &tsNode{op: lexemeOp, lexeme: randomLexeme(rng), prefix: rng.Intn(4) == 0}
+			if rng.Intn(4) == 0 {
+				n.weights = tsWeightMask(1 + rng.Intn(15))
+			}
+			return n
+		}
+		n := &tsNode{op: andOp + tsOperator(rng.Intn(4)), l: gen(depth - 1)}
+		switch n.op {
+		case notOp:
+			return n
+		case followedByOp:
+			n.distance = uint16(rng.Intn(3))
+		}
+		n.r = gen(depth - 1)
+		return n
+	}
+	return TSQuery{root: gen(3)}
+}
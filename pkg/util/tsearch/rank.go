@@ -0,0 +1,233 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// DefaultRankWeights are the weights of the D, C, B and A weight classes, in
+// that order, that are used by Rank when no weights are supplied.
+var DefaultRankWeights = []float32{0.1, 0.2, 0.4, 1.0}
+
+// The normalization flags of Rank, which can be combined. They match the
+// Postgres ones.
+const (
+	// rankNormLogLength divides the rank by 1 + the logarithm of the document
+	// length.
+	rankNormLogLength = 1 << iota
+	// rankNormLength divides the rank by the document length.
+	rankNormLength
+	// rankNormExtDist is only used by ts_rank_cd, and is ignored by Rank.
+	rankNormExtDist
+	// rankNormUniq divides the rank by the number of unique lexemes in the
+	// document.
+	rankNormUniq
+	// rankNormLogUniq divides the rank by 1 + the logarithm of the number of
+	// unique lexemes in the document.
+	rankNormLogUniq
+	// rankNormRDivRPlus1 divides the rank by itself + 1.
+	rankNormRDivRPlus1
+)
+
+// Rank returns how relevant the given document is for the given query, based
+// on the frequency of the lexemes of the query in the document and on their
+// proximity. It implements the ts_rank builtin, and follows the Postgres
+// algorithm so that the results are comparable. weights must contain the
+// weights of the D, C, B and A classes, in that order, or be nil to use
+// DefaultRankWeights. normalization is a bit mask that controls how the rank
+// is scaled by the document length.
+func Rank(weights []float32, v TSVector, q TSQuery, normalization int) (float32, error) {
+	if weights == nil {
+		weights = DefaultRankWeights
+	}
+	if len(weights) < len(DefaultRankWeights) {
+		return 0, pgerror.New(pgcode.ArraySubscript, "array of weight is too short")
+	}
+	w := make([]float64, len(DefaultRankWeights))
+	for i := range w {
+		if weights[i] > 1 {
+			return 0, pgerror.New(pgcode.InvalidParameterValue,
+				"weight out of range")
+		}
+		w[i] = float64(weights[i])
+		if w[i] < 0 {
+			w[i] = float64(DefaultRankWeights[i])
+		}
+	}
+	if len(v) == 0 || q.root == nil {
+		return 0, nil
+	}
+
+	var res float64
+	if q.root.op == andOp || q.root.op == followedByOp {
+		res = rankAnd(w, v, q)
+	} else {
+		res = rankOr(w, v, q)
+	}
+	if res < 0 {
+		res = 1e-20
+	}
+
+	if normalization&rankNormLogLength != 0 {
+		res /= math.Log(float64(v.length()+1)) / math.Log(2)
+	}
+	if normalization&rankNormLength != 0 {
+		if l := v.length(); l > 0 {
+			res /= float64(l)
+		}
+	}
+	if normalization&rankNormUniq != 0 {
+		res /= float64(len(v))
+	}
+	if normalization&rankNormLogUniq != 0 {
+		res /= math.Log(float64(len(v)+1)) / math.Log(2)
+	}
+	if normalization&rankNormRDivRPlus1 != 0 {
+		res /= res + 1
+	}
+	return float32(res), nil
+}
+
+// length returns the number of lexeme occurrences in the vector. A lexeme
+// without positions counts as one occurrence.
+func (v TSVector) length() int {
+	var l int
+	for i := range v {
+		if len(v[i].positions) == 0 {
+			l++
+		} else {
+			l += len(v[i].positions)
+		}
+	}
+	return l
+}
+
+// rankOperands returns the distinct lexeme nodes of the query, sorted by
+// lexeme.
+func rankOperands(q TSQuery) []*tsNode {
+	var ret []*tsNode
+	q.walk(func(n *tsNode) {
+		if n.op == lexemeOp {
+			ret = append(ret, n)
+		}
+	})
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].lexeme != ret[j].lexeme {
+			return ret[i].lexeme < ret[j].lexeme
+		}
+		return !ret[i].prefix && ret[j].prefix
+	})
+	uniq := ret[:0]
+	for i, n := range ret {
+		if i > 0 && n.lexeme == ret[i-1].lexeme && n.prefix == ret[i-1].prefix {
+			continue
+		}
+		uniq = append(uniq, n)
+	}
+	return uniq
+}
+
+// nullPositions are the positions used for a lexeme of the document that
+// doesn't have any.
+var nullPositions = []tsPosition{{position: maxTSPosition, weight: weightD}}
+
+func termPositions(t *tsTerm) []tsPosition {
+	if len(t.positions) == 0 {
+		return nullPositions
+	}
+	return t.positions
+}
+
+// rankOr computes the rank for a query whose root isn't a conjunction. Each
+// occurrence of a lexeme contributes to its score with a weight that decreases
+// quadratically, and the result is the average score of the lexemes.
+func rankOr(w []float64, v TSVector, q TSQuery) float64 {
+	operands := rankOperands(q)
+	var res float64
+	for _, n := range operands {
+		n.forEachMatchingTerm(v, func(t *tsTerm) bool {
+			positions := termPositions(t)
+			var resj, wjm float64
+			wjm = -1
+			jm := 0
+			for j, p := range positions {
+				wp := w[p.weight]
+				resj += wp / float64((j+1)*(j+1))
+				if wp > wjm {
+					wjm = wp
+					jm = j
+				}
+			}
+			// The sum of 1/i^2 converges to pi^2/6.
+			res += (wjm + resj - wjm/float64((jm+1)*(jm+1))) / 1.64493406685
+			return true
+		})
+	}
+	if len(operands) > 0 {
+		res /= float64(len(operands))
+	}
+	return res
+}
+
+// rankAnd computes the rank for a query whose root is a conjunction, based on
+// the distances between the occurrences of the different lexemes.
+func rankAnd(w []float64, v TSVector, q TSQuery) float64 {
+	operands := rankOperands(q)
+	if len(operands) < 2 {
+		return rankOr(w, v, q)
+	}
+	res := -1.0
+	seen := make([][]tsPosition, len(operands))
+	seenNull := make([]bool, len(operands))
+	for i, n := range operands {
+		n.forEachMatchingTerm(v, func(t *tsTerm) bool {
+			seen[i], seenNull[i] = termPositions(t), len(t.positions) == 0
+			for k := 0; k < i; k++ {
+				for _, l := range seen[i] {
+					for _, p := range seen[k] {
+						dist := int(l.position) - int(p.position)
+						if dist < 0 {
+							dist = -dist
+						}
+						if dist == 0 {
+							if !seenNull[i] && !seenNull[k] {
+								continue
+							}
+							dist = maxTSPosition + 1
+						}
+						curw := math.Sqrt(w[l.weight] * w[p.weight] * wordDistance(dist))
+						if res < 0 {
+							res = curw
+						} else {
+							res = 1 - (1-res)*(1-curw)
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return res
+}
+
+// wordDistance returns the contribution of two lexemes that are the given
+// distance apart to the rank of a conjunction.
+func wordDistance(dist int) float64 {
+	if dist > 100 {
+		return 1e-30
+	}
+	return 1 / (1.005 + 0.05*math.Exp(float64(dist)/1.5-2))
+}
@@ -0,0 +1,376 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "strings"
+
+// stemEnglish reduces a lowercase English word to its stem using the Snowball
+// English (Porter2) stemming algorithm, which is also what the Postgres
+// english configuration uses. See
+// https://snowballstem.org/algorithms/english/stemmer.html for a description
+// of the steps and the terminology used below.
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] >= 0x80 {
+			// The algorithm is only defined for ASCII words.
+			return word
+		}
+	}
+	if s, ok := englishStemExceptions[word]; ok {
+		return s
+	}
+
+	w := englishStemmer{b: []byte(strings.TrimPrefix(word, "'"))}
+	w.markYs()
+	w.computeRegions()
+	w.step0()
+	w.step1a()
+	if _, ok := englishStemInvariants[string(w.b)]; ok {
+		return string(w.b)
+	}
+	w.step1b()
+	w.step1c()
+	w.step2()
+	w.step3()
+	w.step4()
+	w.step5()
+	for i := range w.b {
+		if w.b[i] == 'Y' {
+			w.b[i] = 'y'
+		}
+	}
+	return string(w.b)
+}
+
+// englishStemExceptions are words whose stem is not computed by the
+// algorithm.
+var englishStemExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishStemInvariants are words that are left alone after step 1a.
+var englishStemInvariants = map[string]struct{}{
+	"inning":  {},
+	"outing":  {},
+	"canning": {},
+	"herring": {},
+	"earring": {},
+	"proceed": {},
+	"exceed":  {},
+	"succeed": {},
+}
+
+type englishStemmer struct {
+	b []byte
+	// r1 and r2 are the offsets at which the R1 and R2 regions start.
+	r1, r2 int
+}
+
+func isEnglishVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// markYs replaces an initial y, and every y that follows a vowel, with Y so
+// that it is treated as a consonant.
+func (w *englishStemmer) markYs() {
+	for i := range w.b {
+		if w.b[i] == 'y' && (i == 0 || isEnglishVowel(w.b[i-1])) {
+			w.b[i] = 'Y'
+		}
+	}
+}
+
+// computeRegions computes R1, the region after the first non-vowel that
+// follows a vowel, and R2, the same region within R1.
+func (w *englishStemmer) computeRegions() {
+	w.r1 = len(w.b)
+	for _, p := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(w.b), p) {
+			w.r1 = len(p)
+			break
+		}
+	}
+	if w.r1 == len(w.b) {
+		w.r1 = w.regionAfter(0)
+	}
+	w.r2 = w.regionAfter(w.r1)
+}
+
+func (w *englishStemmer) regionAfter(start int) int {
+	for i := start + 1; i < len(w.b); i++ {
+		if !isEnglishVowel(w.b[i]) && isEnglishVowel(w.b[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w.b)
+}
+
+func (w *englishStemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(w.b), suffix)
+}
+
+// longestSuffix returns the longest of the given suffixes that the word ends
+// with, or the empty string.
+func (w *englishStemmer) longestSuffix(suffixes ...string) string {
+	var ret string
+	for _, s := range suffixes {
+		if len(s) > len(ret) && w.hasSuffix(s) {
+			ret = s
+		}
+	}
+	return ret
+}
+
+// inR1 and inR2 return whether a suffix of the given length is contained in
+// the respective region.
+func (w *englishStemmer) inR1(suffixLen int) bool {
+	return len(w.b)-suffixLen >= w.r1
+}
+
+func (w *englishStemmer) inR2(suffixLen int) bool {
+	return len(w.b)-suffixLen >= w.r2
+}
+
+func (w *englishStemmer) replaceSuffix(suffix, replacement string) {
+	w.b = append(w.b[:len(w.b)-len(suffix)], replacement...)
+}
+
+// containsVowel returns whether the first n bytes of the word contain a
+// vowel.
+func (w *englishStemmer) containsVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if isEnglishVowel(w.b[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsInShortSyllable returns whether the word ends in a short syllable,
+// which is either a non-vowel other than w, x or Y, preceded by a vowel,
+// preceded by a non-vowel; or a vowel at the beginning of the word followed
+// by a non-vowel.
+func (w *englishStemmer) endsInShortSyllable() bool {
+	n := len(w.b)
+	if n == 2 {
+		return isEnglishVowel(w.b[0]) && !isEnglishVowel(w.b[1])
+	}
+	if n < 3 {
+		return false
+	}
+	c := w.b[n-1]
+	return !isEnglishVowel(w.b[n-3]) && isEnglishVowel(w.b[n-2]) &&
+		!isEnglishVowel(c) && c != 'w' && c != 'x' && c != 'Y'
+}
+
+// isShort returns whether the word is short, which means that it ends in a
+// short syllable and R1 is empty.
+func (w *englishStemmer) isShort() bool {
+	return w.r1 >= len(w.b) && w.endsInShortSyllable()
+}
+
+func (w *englishStemmer) step0() {
+	if s := w.longestSuffix("'", "'s", "'s'"); s != "" {
+		w.replaceSuffix(s, "")
+	}
+}
+
+func (w *englishStemmer) step1a() {
+	switch s := w.longestSuffix("sses", "ied", "ies", "us", "ss", "s"); s {
+	case "sses":
+		w.replaceSuffix(s, "ss")
+	case "ied", "ies":
+		if len(w.b) > 4 {
+			w.replaceSuffix(s, "i")
+		} else {
+			w.replaceSuffix(s, "ie")
+		}
+	case "s":
+		if w.containsVowel(len(w.b) - 2) {
+			w.replaceSuffix(s, "")
+		}
+	}
+}
+
+func (w *englishStemmer) step1b() {
+	switch s := w.longestSuffix("eed", "eedly", "ed", "edly", "ing", "ingly"); s {
+	case "eed", "eedly":
+		if w.inR1(len(s)) {
+			w.replaceSuffix(s, "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		if !w.containsVowel(len(w.b) - len(s)) {
+			return
+		}
+		w.replaceSuffix(s, "")
+		switch {
+		case w.hasSuffix("at"), w.hasSuffix("bl"), w.hasSuffix("iz"):
+			w.b = append(w.b, 'e')
+		case w.longestSuffix("bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt") != "":
+			w.b = w.b[:len(w.b)-1]
+		case w.isShort():
+			w.b = append(w.b, 'e')
+		}
+	}
+}
+
+func (w *englishStemmer) step1c() {
+	n := len(w.b)
+	if n > 2 && (w.b[n-1] == 'y' || w.b[n-1] == 'Y') && !isEnglishVowel(w.b[n-2]) {
+		w.b[n-1] = 'i'
+	}
+}
+
+// englishStep2Suffixes maps the suffixes removed by step 2 to their
+// replacements.
+var englishStep2Suffixes = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var englishStep2SuffixList = suffixList(englishStep2Suffixes)
+
+func (w *englishStemmer) step2() {
+	s := w.longestSuffix(englishStep2SuffixList...)
+	if s == "" || !w.inR1(len(s)) {
+		return
+	}
+	switch s {
+	case "ogi":
+		if len(w.b) < 4 || w.b[len(w.b)-4] != 'l' {
+			return
+		}
+	case "li":
+		if len(w.b) < 3 || !strings.ContainsRune("cdeghkmnrt", rune(w.b[len(w.b)-3])) {
+			return
+		}
+	}
+	w.replaceSuffix(s, englishStep2Suffixes[s])
+}
+
+// englishStep3Suffixes maps the suffixes removed by step 3 to their
+// replacements.
+var englishStep3Suffixes = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var englishStep3SuffixList = suffixList(englishStep3Suffixes)
+
+func (w *englishStemmer) step3() {
+	s := w.longestSuffix(englishStep3SuffixList...)
+	if s == "" || !w.inR1(len(s)) {
+		return
+	}
+	if s == "ative" && !w.inR2(len(s)) {
+		return
+	}
+	w.replaceSuffix(s, englishStep3Suffixes[s])
+}
+
+var englishStep4SuffixList = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func (w *englishStemmer) step4() {
+	s := w.longestSuffix(englishStep4SuffixList...)
+	if s == "" || !w.inR2(len(s)) {
+		return
+	}
+	if s == "ion" {
+		n := len(w.b) - len(s)
+		if n == 0 || (w.b[n-1] != 's' && w.b[n-1] != 't') {
+			return
+		}
+	}
+	w.replaceSuffix(s, "")
+}
+
+func (w *englishStemmer) step5() {
+	n := len(w.b)
+	switch {
+	case w.hasSuffix("e"):
+		if w.inR2(1) {
+			w.b = w.b[:n-1]
+		} else if w.inR1(1) {
+			w.b = w.b[:n-1]
+			if w.endsInShortSyllable() {
+				w.b = append(w.b, 'e')
+			}
+		}
+	case w.hasSuffix("ll"):
+		if w.inR2(1) {
+			w.b = w.b[:n-1]
+		}
+	}
+}
+
+func suffixList(m map[string]string) []string {
+	ret := make([]string, 0, len(m))
+	for s := range m {
+		ret = append(ret, s)
+	}
+	return ret
+}
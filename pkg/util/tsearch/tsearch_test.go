@@ -0,0 +1,373 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTSVector(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{``, ``},
+		{`a fat cat sat on a mat and ate a fat rat`,
+			`'a' 'and' 'ate' 'cat' 'fat' 'mat' 'on' 'rat' 'sat'`},
+		{`a:1 fat:2 cat:3 sat:4 on:5 a:6 mat:7 and:8 ate:9 a:10 fat:11 rat:12`,
+			`'a':1,6,10 'and':8 'ate':9 'cat':3 'fat':2,11 'mat':7 'on':5 'rat':12 'sat':4`},
+		{`a:1A fat:2B,4C cat:5D`, `'a':1A 'cat':5 'fat':2B,4C`},
+		{`a:3,1,3A,2`, `'a':1,2,3A`},
+		{`a:99999`, `'a':16383`},
+		{`the 'Joe''s' Café`, `'Café' 'Joe''s' 'the'`},
+		{`  'a b'   c\ d  `, `'a b' 'c d'`},
+		{`back\\slash`, `'back\\slash'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := ParseTSVector(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, v.String())
+
+			// The text form must round trip.
+			v2, err := ParseTSVector(v.String())
+			require.NoError(t, err)
+			require.Equal(t, v.String(), v2.String())
+		})
+	}
+
+	for _, input := range []string{`'unterminated`, `a:`, `a:x`, `a:1,`, `a:0`, `a:1X`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseTSVector(input)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseTSQuery(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{``, ``},
+		{`fat & rat`, `'fat' & 'rat'`},
+		{`fat & (rat | cat)`, `'fat' & ( 'rat' | 'cat' )`},
+		{`fat & rat & ! cat`, `'fat' & 'rat' & !'cat'`},
+		{`fat | rat & cat`, `'fat' | 'rat' & 'cat'`},
+		{`(fat | rat) & cat`, `( 'fat' | 'rat' ) & 'cat'`},
+		{`!(fat | rat)`, `!( 'fat' | 'rat' )`},
+		{`!!fat`, `!!'fat'`},
+		{`fat <-> rat`, `'fat' <-> 'rat'`},
+		{`fat <2> rat <0> cat`, `'fat' <2> 'rat' <0> 'cat'`},
+		{`fat <-> (rat <-> cat)`, `'fat' <-> ( 'rat' <-> 'cat' )`},
+		{`fat <-> rat & cat`, `'fat' <-> 'rat' & 'cat'`},
+		{`fat & (rat <-> cat)`, `'fat' & 'rat' <-> 'cat'`},
+		{`supern:*a & star:A*B`, `'supern':*A & 'star':*AB`},
+		{`'a b':DC`, `'a b':CD`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseTSQuery(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q.String())
+
+			// The text form must round trip.
+			q2, err := ParseTSQuery(q.String())
+			require.NoError(t, err)
+			require.Equal(t, q.String(), q2.String())
+		})
+	}
+
+	for _, input := range []string{`&`, `fat &`, `(fat`, `fat)`, `fat <-`, `fat <x> rat`,
+		`fat <99999> rat`, `fat rat`, `'unterminated`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseTSQuery(input)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	testCases := map[string]string{
+		"a":              "a",
+		"cats":           "cat",
+		"rats":           "rat",
+		"running":        "run",
+		"hopping":        "hop",
+		"hoping":         "hope",
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"ties":           "tie",
+		"happiness":      "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"rational":       "ration",
+		"generously":     "generous",
+		"consignment":    "consign",
+		"consisted":      "consist",
+		"knightly":       "knight",
+		"predication":    "predic",
+		"operator":       "oper",
+		"feudalism":      "feudal",
+		"hopefulness":    "hope",
+		"callousness":    "callous",
+		"vietnamization": "vietnam",
+		"beautiful":      "beauti",
+		"supernovae":     "supernova",
+		"skies":          "sky",
+		"dying":          "die",
+		"news":           "news",
+		"proceed":        "proceed",
+		"fatal":          "fatal",
+		"error":          "error",
+		"café":           "café",
+	}
+	for word, expected := range testCases {
+		require.Equal(t, expected, stemEnglish(word), word)
+	}
+}
+
+func TestToTSVectorAndQuery(t *testing.T) {
+	testCases := []struct {
+		fn       func(string, string) (string, error)
+		config   string
+		input    string
+		expected string
+	}{
+		{toTSVector, "english", "The Fat Rats", `'fat':2 'rat':3`},
+		{toTSVector, "english", "a fat  cat sat on a mat - it ate a fat rats",
+			`'ate':9 'cat':3 'fat':2,11 'mat':7 'rat':12 'sat':4`},
+		{toTSVector, "simple", "The Fat Rats", `'fat':2 'rats':3 'the':1`},
+		{toTSVector, "pg_catalog.simple", "", ``},
+		{toTSQuery, "english", "The & Fat & Rats", `'fat' & 'rat'`},
+		{toTSQuery, "english", "Fat | Rats:AB", `'fat' | 'rat':AB`},
+		{toTSQuery, "english", "supernovae:*A & stars:*", `'supernova':*A & 'star':*`},
+		{toTSQuery, "english", "fat-rats", `'fat' <-> 'rat'`},
+		{toTSQuery, "english", "the & !a", ``},
+		{toTSQuery, "simple", "The & Fat & Rats", `'the' & 'fat' & 'rats'`},
+		{plainToTSQuery, "english", "The Fat Rats", `'fat' & 'rat'`},
+		{plainToTSQuery, "english", "the", ``},
+		{phraseToTSQuery, "english", "The Fat Rats", `'fat' <-> 'rat'`},
+		{phraseToTSQuery, "english", "The Cat and Rats", `'cat' <2> 'rat'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := tc.fn(tc.config, tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+
+	_, err := ToTSVector("klingon", "Qapla'")
+	require.EqualError(t, err, `text search configuration "klingon" does not exist`)
+}
+
+func toTSVector(config, input string) (string, error) {
+	v, err := ToTSVector(config, input)
+	return v.String(), err
+}
+
+func toTSQuery(config, input string) (string, error) {
+	q, err := ToTSQuery(config, input)
+	return q.String(), err
+}
+
+func plainToTSQuery(config, input string) (string, error) {
+	q, err := PlainToTSQuery(config, input)
+	return q.String(), err
+}
+
+func phraseToTSQuery(config, input string) (string, error) {
+	q, err := PhraseToTSQuery(config, input)
+	return q.String(), err
+}
+
+func TestMatches(t *testing.T) {
+	testCases := []struct {
+		vector   string
+		query    string
+		expected bool
+	}{
+		{`a fat cat sat on a mat and ate a fat rat`, `cat & rat`, true},
+		{`a fat cat sat on a mat and ate a fat rat`, `cat & dog`, false},
+		{`a fat cat sat on a mat and ate a fat rat`, `cat | dog`, true},
+		{`a fat cat sat on a mat and ate a fat rat`, `!dog`, true},
+		{`a fat cat sat on a mat and ate a fat rat`, `!cat`, false},
+		{`a fat cat sat on a mat and ate a fat rat`, `ca:*`, true},
+		{`a fat cat sat on a mat and ate a fat rat`, `cats:*`, false},
+		{`a fat cat sat on a mat and ate a fat rat`, ``, false},
+		{`a:1 fat:2 cat:3`, `fat <-> cat`, true},
+		{`a:1 fat:2 cat:3`, `cat <-> fat`, false},
+		{`a:1 fat:2 cat:3`, `a <2> cat`, true},
+		{`a:1 fat:2 cat:3`, `a <-> fat <-> cat`, true},
+		{`a:1 fat:2 cat:3`, `a <-> (fat <-> cat)`, true},
+		{`a:1 fat:2 cat:3`, `a <-> (cat <-> fat)`, false},
+		{`a:1 fat:2 cat:3`, `a <-> (fat | rat)`, true},
+		{`a:1 fat:2 cat:3`, `a <-> !cat`, true},
+		{`a:1 fat:2 cat:3`, `a <-> !fat`, false},
+		{`a:1 fat:2 cat:3`, `!a <-> cat`, true},
+		{`a:1 fat:2 cat:3`, `!fat <-> cat`, false},
+		{`a:1 fat:2 cat:3`, `fat <0> fat`, true},
+		{`a fat cat`, `fat <-> cat`, false},
+		{`a:1A fat:2B cat:3`, `fat:B & a:AC`, true},
+		{`a:1A fat:2B cat:3`, `fat:A`, false},
+		{`a:1A fat:2B cat:3`, `cat:D`, true},
+		{`a:1A fat:2B cat:3`, `f:*B <-> c:*`, true},
+		{`a fat cat`, `cat:A`, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.vector+"@@"+tc.query, func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q.Matches(v))
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	testCases := []struct {
+		weights       []float32
+		vector        string
+		query         string
+		normalization int
+		expected      float32
+	}{
+		{nil, `cat:2 sat:3`, `cat`, 0, 0.0607927},
+		{nil, `cat:1,2`, `cat`, 0, 0.0759909},
+		{nil, `cat:1 dog:2`, `cat | dog`, 0, 0.0607927},
+		{nil, `cat:1 dog:2`, `cat | rat`, 0, 0.0303964},
+		{nil, `cat:1A`, `cat`, 0, 0.6079271},
+		{[]float32{0.1, 0.2, 0.4, 0.5}, `cat:1A`, `cat`, 0, 0.3039636},
+		{nil, `fat:1 rat:2`, `fat & rat`, 0, 0.0991032},
+		{nil, `fat:1 rat:2`, `fat & rat`, 2, 0.0495516},
+		{nil, `fat:1 rat:2`, `fat & rat`, 32, 0.0901669},
+		{nil, `fat:1 rat:2`, `dog`, 0, 0},
+		{nil, ``, `dog`, 0, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.vector+"@@"+tc.query, func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			rank, err := Rank(tc.weights, v, q, tc.normalization)
+			require.NoError(t, err)
+			require.InDelta(t, tc.expected, rank, 1e-6)
+		})
+	}
+
+	_, err := Rank([]float32{0.1, 0.2}, nil, TSQuery{}, 0)
+	require.EqualError(t, err, "array of weight is too short")
+	_, err = Rank([]float32{0.1, 0.2, 0.3, 2}, nil, TSQuery{}, 0)
+	require.EqualError(t, err, "weight out of range")
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`a`,
+		`a:1A fat:2B,4C cat:5D`,
+		`'Joe''s' 'a b':16383`,
+	} {
+		v, err := ParseTSVector(s)
+		require.NoError(t, err)
+		decoded, err := DecodeTSVector(EncodeTSVector(nil, v))
+		require.NoError(t, err)
+		require.Equal(t, v.String(), decoded.String())
+	}
+
+	for _, s := range []string{
+		``,
+		`a`,
+		`fat & (rat | !cat)`,
+		`supern:*A & star:A*B <5> 'a b'`,
+	} {
+		q, err := ParseTSQuery(s)
+		require.NoError(t, err)
+		decoded, err := DecodeTSQuery(EncodeTSQuery(nil, q))
+		require.NoError(t, err)
+		require.Equal(t, q.String(), decoded.String())
+	}
+
+	rng, _ := randutil.NewPseudoRand()
+	for i := 0; i < 100; i++ {
+		v := RandomTSVector(rng)
+		parsedV, err := ParseTSVector(v.String())
+		require.NoError(t, err)
+		require.Equal(t, v.String(), parsedV.String())
+		decodedV, err := DecodeTSVector(EncodeTSVector(nil, v))
+		require.NoError(t, err)
+		require.Equal(t, v.String(), decodedV.String())
+
+		q := RandomTSQuery(rng)
+		parsedQ, err := ParseTSQuery(q.String())
+		require.NoError(t, err)
+		require.Equal(t, q.String(), parsedQ.String())
+		decodedQ, err := DecodeTSQuery(EncodeTSQuery(nil, q))
+		require.NoError(t, err)
+		require.Equal(t, q.String(), decodedQ.String())
+	}
+}
+
+func TestGetInvertedExpr(t *testing.T) {
+	v, err := ParseTSVector(`a:1 fat:2 cat:3 catalog:4`)
+	require.NoError(t, err)
+	keys, err := EncodeInvertedIndexKeys(nil, v)
+	require.NoError(t, err)
+	require.Len(t, keys, 4)
+
+	testCases := []struct {
+		query     string
+		indexable bool
+		tight     bool
+		unique    bool
+		matches   bool
+	}{
+		{`fat`, true, true, true, true},
+		{`dog`, true, true, true, false},
+		{`fat & cat`, true, true, true, true},
+		{`fat & dog`, true, true, true, false},
+		{`fat | dog`, true, true, false, true},
+		{`cat:*`, true, true, false, true},
+		{`ca:*`, true, true, false, true},
+		{`do:*`, true, true, false, false},
+		{`fat:A`, true, false, true, true},
+		{`fat <-> cat`, true, false, true, true},
+		{`fat & !dog`, true, false, true, true},
+		{`!dog`, false, false, false, false},
+		{`fat | !dog`, false, false, false, false},
+		{``, false, false, false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			expr, err := q.GetInvertedExpr()
+			require.NoError(t, err)
+			spanExpr, ok := expr.(*inverted.SpanExpression)
+			require.Equal(t, tc.indexable, ok)
+			if !ok {
+				return
+			}
+			require.Equal(t, tc.tight, spanExpr.Tight)
+			require.Equal(t, tc.unique, spanExpr.Unique)
+			matches, err := spanExpr.ContainsKeys(keys)
+			require.NoError(t, err)
+			require.Equal(t, tc.matches, matches)
+		})
+	}
+}
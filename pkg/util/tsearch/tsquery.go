@@ -0,0 +1,549 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// tsOperator is an operator in a TSQuery expression tree.
+type tsOperator byte
+
+const (
+	// lexemeOp marks a leaf of the tree.
+	lexemeOp tsOperator = iota
+	// andOp matches documents that match both of its operands.
+	andOp
+	// orOp matches documents that match either of its operands.
+	orOp
+	// notOp matches documents that don't match its operand.
+	notOp
+	// followedByOp matches documents in which its left operand is followed
+	// by its right operand at the distance given by the node.
+	followedByOp
+)
+
+// priority returns the binding strength of the operator, which is used both
+// when parsing and when deciding where to put parentheses when formatting.
+// The values match the Postgres ones.
+func (o tsOperator) priority() int {
+	switch o {
+	case orOp:
+		return 1
+	case andOp:
+		return 2
+	case followedByOp:
+		return 3
+	case notOp:
+		return 4
+	}
+	return 5
+}
+
+// tsWeightMask is a set of weights. The empty set means that a lexeme in a
+// query matches positions of any weight.
+type tsWeightMask byte
+
+func (m tsWeightMask) contains(w tsWeight) bool {
+	return m&(1<<w) != 0
+}
+
+// tsNode is a node of a TSQuery expression tree.
+type tsNode struct {
+	op tsOperator
+
+	// The following fields are set for lexemeOp nodes.
+	lexeme  string
+	weights tsWeightMask
+	// prefix is set if the lexeme matches any lexeme that starts with it,
+	// which is written as 'lex':* in the text form.
+	prefix bool
+
+	// distance is the distance between the operands of a followedByOp node.
+	distance uint16
+
+	// l is the only operand of notOp nodes.
+	l, r *tsNode
+}
+
+// TSQuery is a full text search query that can be matched against a TSVector.
+// It corresponds to the Postgres tsquery type. The zero value is the empty
+// query, which matches nothing.
+type TSQuery struct {
+	root *tsNode
+}
+
+// ParseTSQuery parses the text form of a TSQuery. Operands are lexemes that
+// may be quoted and may be followed by a colon and a list of weights and the
+// prefix marker *. Operands are combined with the operators ! (not),
+// <-> or <N> (followed by), & (and) and | (or), in decreasing order of
+// precedence, and parentheses. Lexemes are not normalized; use ToTSQuery to
+// build a query from words.
+func ParseTSQuery(input string) (TSQuery, error) {
+	p := tsQueryParser{tsScanner: tsScanner{input: input}}
+	p.skipSpace()
+	if p.done() {
+		// The empty query is valid; it doesn't match anything.
+		return TSQuery{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return TSQuery{}, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return TSQuery{}, p.syntaxError()
+	}
+	return TSQuery{root: root}, nil
+}
+
+type tsQueryParser struct {
+	tsScanner
+}
+
+func (p *tsQueryParser) syntaxError() error {
+	return pgerror.Newf(pgcode.Syntax, "syntax error in tsquery: %q", p.input)
+}
+
+func (p *tsQueryParser) parseOr() (*tsNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.peek() != '|' {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &tsNode{op: orOp, l: l, r: r}
+	}
+}
+
+func (p *tsQueryParser) parseAnd() (*tsNode, error) {
+	l, err := p.parseFollowedBy()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.peek() != '&' {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.parseFollowedBy()
+		if err != nil {
+			return nil, err
+		}
+		l = &tsNode{op: andOp, l: l, r: r}
+	}
+}
+
+func (p *tsQueryParser) parseFollowedBy() (*tsNode, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.peek() != '<' {
+			return l, nil
+		}
+		p.pos++
+		var distance int
+		if p.peek() == '-' {
+			p.pos++
+			distance = 1
+		} else {
+			start := p.pos
+			for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.syntaxError()
+			}
+			distance, err = strconv.Atoi(p.input[start:p.pos])
+			if err != nil || distance > maxTSPosition {
+				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					"distance in phrase operator must be an integer value between zero and %d inclusive",
+					maxTSPosition)
+			}
+		}
+		if p.peek() != '>' {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = &tsNode{op: followedByOp, distance: uint16(distance), l: l, r: r}
+	}
+}
+
+func (p *tsQueryParser) parseNot() (*tsNode, error) {
+	p.skipSpace()
+	switch p.peek() {
+	case '!':
+		p.pos++
+		l, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &tsNode{op: notOp, l: l}, nil
+	case '(':
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		return n, nil
+	}
+	return p.parseOperand()
+}
+
+func (p *tsQueryParser) parseOperand() (*tsNode, error) {
+	lexeme, ok := p.scanLexeme(true /* inQuery */)
+	if !ok {
+		return nil, p.syntaxError()
+	}
+	n := &tsNode{op: lexemeOp, lexeme: lexeme}
+	if p.peek() == ':' {
+		p.pos++
+		for !p.done() {
+			c := p.peek()
+			if c == '*' {
+				n.prefix = true
+			} else if w, ok := parseTSWeight(c); ok {
+				n.weights |= 1 << w
+			} else {
+				break
+			}
+			p.pos++
+		}
+	}
+	return n, nil
+}
+
+// String returns the text form of the TSQuery.
+func (q TSQuery) String() string {
+	if q.root == nil {
+		return ""
+	}
+	var buf strings.Builder
+	q.root.format(&buf, 0 /* parentPriority */, false /* rightOfFollowedBy */)
+	return buf.String()
+}
+
+// format writes the text form of the subtree rooted at n, adding parentheses
+// around it if it binds less tightly than its parent. Like Postgres, the right
+// operand of a followed by operator is parenthesized if it is also a followed
+// by operator, since the operator is not associative.
+func (n *tsNode) format(buf *strings.Builder, parentPriority int, rightOfFollowedBy bool) {
+	switch n.op {
+	case lexemeOp:
+		writeQuotedLexeme(buf, n.lexeme)
+		if n.prefix || n.weights != 0 {
+			buf.WriteByte(':')
+			if n.prefix {
+				buf.WriteByte('*')
+			}
+			for w := weightA; ; w-- {
+				if n.weights.contains(w) {
+					buf.WriteString(w.String())
+				}
+				if w == weightD {
+					break
+				}
+			}
+		}
+		return
+	case notOp:
+		parens := n.op.priority() < parentPriority
+		if parens {
+			buf.WriteString("( ")
+		}
+		buf.WriteByte('!')
+		n.l.format(buf, n.op.priority(), false /* rightOfFollowedBy */)
+		if parens {
+			buf.WriteString(" )")
+		}
+		return
+	}
+	parens := n.op.priority() < parentPriority || (n.op == followedByOp && rightOfFollowedBy)
+	if parens {
+		buf.WriteString("( ")
+	}
+	n.l.format(buf, n.op.priority(), false /* rightOfFollowedBy */)
+	switch n.op {
+	case andOp:
+		buf.WriteString(" & ")
+	case orOp:
+		buf.WriteString(" | ")
+	case followedByOp:
+		if n.distance == 1 {
+			buf.WriteString(" <-> ")
+		} else {
+			buf.WriteString(" <")
+			buf.WriteString(strconv.Itoa(int(n.distance)))
+			buf.WriteString("> ")
+		}
+	}
+	n.r.format(buf, n.op.priority(), n.op == followedByOp)
+	if parens {
+		buf.WriteString(" )")
+	}
+}
+
+// Size returns an estimate of the memory footprint of the TSQuery in bytes.
+func (q TSQuery) Size() uintptr {
+	var sz uintptr
+	q.walk(func(n *tsNode) {
+		sz += uintptr(len(n.lexeme)) + 56
+	})
+	return sz
+}
+
+// walk calls fn on every node of the query, in prefix order.
+func (q TSQuery) walk(fn func(n *tsNode)) {
+	var rec func(n *tsNode)
+	rec = func(n *tsNode) {
+		if n == nil {
+			return
+		}
+		fn(n)
+		rec(n.l)
+		rec(n.r)
+	}
+	rec(q.root)
+}
+
+// Matches returns whether the TSQuery matches the given TSVector. It
+// implements the @@ operator.
+func (q TSQuery) Matches(v TSVector) bool {
+	if q.root == nil {
+		return false
+	}
+	return q.root.matches(v)
+}
+
+func (n *tsNode) matches(v TSVector) bool {
+	switch n.op {
+	case lexemeOp:
+		found := false
+		n.forEachMatchingTerm(v, func(t *tsTerm) bool {
+			found = n.weights == 0 || len(n.matchingPositions(t)) > 0
+			return !found
+		})
+		return found
+	case andOp:
+		return n.l.matches(v) && n.r.matches(v)
+	case orOp:
+		return n.l.matches(v) || n.r.matches(v)
+	case notOp:
+		return !n.l.matches(v)
+	case followedByOp:
+		res := n.phraseMatches(v)
+		return res.negated || len(res.positions) > 0
+	}
+	return false
+}
+
+// forEachMatchingTerm calls fn on every term of v that matches the lexeme of
+// n, until fn returns false.
+func (n *tsNode) forEachMatchingTerm(v TSVector, fn func(t *tsTerm) bool) {
+	for i := v.find(n.lexeme); i < len(v); i++ {
+		t := &v[i]
+		if t.lexeme != n.lexeme && !(n.prefix && strings.HasPrefix(t.lexeme, n.lexeme)) {
+			return
+		}
+		if !fn(t) {
+			return
+		}
+	}
+}
+
+// matchingPositions returns the positions of the given term that have one of
+// the weights of n.
+func (n *tsNode) matchingPositions(t *tsTerm) []uint16 {
+	var ret []uint16
+	for _, p := range t.positions {
+		if n.weights == 0 || n.weights.contains(p.weight) {
+			ret = append(ret, p.position)
+		}
+	}
+	return ret
+}
+
+// phraseResult is the result of evaluating a subtree of a followed by
+// operator. positions contains the positions at which the subtree matches,
+// sorted and without duplicates; for a multi-word phrase, it is the position
+// of the last word. width is the distance between the first and last word of
+// the phrase. If negated is set, the subtree matches at every position except
+// for the ones in positions.
+type phraseResult struct {
+	positions []uint16
+	width     int
+	negated   bool
+}
+
+// phraseMatches evaluates n in the context of a followed by operator, where
+// the positions of the matches, and not just their existence, matter.
+func (n *tsNode) phraseMatches(v TSVector) phraseResult {
+	switch n.op {
+	case lexemeOp:
+		var res phraseResult
+		n.forEachMatchingTerm(v, func(t *tsTerm) bool {
+			res.positions = mergePositions(res.positions, n.matchingPositions(t))
+			return true
+		})
+		return res
+	case notOp:
+		res := n.l.phraseMatches(v)
+		res.negated = !res.negated
+		return res
+	}
+	l, r := n.l.phraseMatches(v), n.r.phraseMatches(v)
+	switch n.op {
+	case andOp:
+		res := phraseResult{width: maxInt(l.width, r.width)}
+		switch {
+		case !l.negated && !r.negated:
+			res.positions = intersectPositions(l.positions, r.positions)
+		case !l.negated:
+			res.positions = subtractPositions(l.positions, r.positions)
+		case !r.negated:
+			res.positions = subtractPositions(r.positions, l.positions)
+		default:
+			res.positions = mergePositions(l.positions, r.positions)
+			res.negated = true
+		}
+		return res
+	case orOp:
+		res := phraseResult{width: maxInt(l.width, r.width)}
+		switch {
+		case !l.negated && !r.negated:
+			res.positions = mergePositions(l.positions, r.positions)
+		case !l.negated:
+			res.positions = subtractPositions(r.positions, l.positions)
+			res.negated = true
+		case !r.negated:
+			res.positions = subtractPositions(l.positions, r.positions)
+			res.negated = true
+		default:
+			res.positions = intersectPositions(l.positions, r.positions)
+			res.negated = true
+		}
+		return res
+	case followedByOp:
+		// A match of the right operand ending at position p is preceded by a
+		// match of the left operand if the left operand matches at position
+		// p - width(r) - distance.
+		offset := int(n.distance) + r.width
+		res := phraseResult{width: l.width + offset}
+		shiftedL := make([]uint16, 0, len(l.positions))
+		for _, p := range l.positions {
+			if int(p)+offset <= maxTSPosition {
+				shiftedL = append(shiftedL, p+uint16(offset))
+			}
+		}
+		switch {
+		case !l.negated && !r.negated:
+			res.positions = intersectPositions(shiftedL, r.positions)
+		case !l.negated:
+			res.positions = subtractPositions(shiftedL, r.positions)
+		case !r.negated:
+			res.positions = subtractPositions(r.positions, shiftedL)
+		default:
+			res.positions = mergePositions(shiftedL, r.positions)
+			res.negated = true
+		}
+		return res
+	}
+	return phraseResult{}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// mergePositions returns the sorted union of two sorted lists of positions.
+func mergePositions(a, b []uint16) []uint16 {
+	ret := make([]uint16, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			ret = append(ret, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			ret = append(ret, b[j])
+			j++
+		default:
+			ret = append(ret, a[i])
+			i++
+			j++
+		}
+	}
+	return ret
+}
+
+// intersectPositions returns the sorted intersection of two sorted lists of
+// positions.
+func intersectPositions(a, b []uint16) []uint16 {
+	var ret []uint16
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case b[j] < a[i]:
+			j++
+		default:
+			ret = append(ret, a[i])
+			i++
+			j++
+		}
+	}
+	return ret
+}
+
+// subtractPositions returns the positions of a that are not in b. Both lists
+// must be sorted.
+func subtractPositions(a, b []uint16) []uint16 {
+	var ret []uint16
+	j := 0
+	for _, p := range a {
+		for j < len(b) && b[j] < p {
+			j++
+		}
+		if j == len(b) || b[j] != p {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,362 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// maxTSPosition is the largest position that can be stored in a TSVector.
+// Larger positions are silently clamped to this value, like in Postgres.
+const maxTSPosition = 1<<14 - 1
+
+// maxTSPositionsPerLexeme is the maximum number of positions that are kept
+// for a single lexeme. Any further positions are dropped.
+const maxTSPositionsPerLexeme = 256
+
+// tsWeight is the weight of a lexeme position. Weights are used to mark
+// lexemes that come from different parts of a document, such as the title
+// or the body. The numeric values match the Postgres on-disk representation,
+// which is why D, the default weight, is 0.
+type tsWeight byte
+
+const (
+	weightD tsWeight = iota
+	weightC
+	weightB
+	weightA
+)
+
+// String returns the single-letter name of the weight.
+func (w tsWeight) String() string {
+	return string("DCBA"[w])
+}
+
+// parseTSWeight returns the weight named by the given character, which may be
+// upper or lower case.
+func parseTSWeight(c byte) (tsWeight, bool) {
+	switch c {
+	case 'A', 'a':
+		return weightA, true
+	case 'B', 'b':
+		return weightB, true
+	case 'C', 'c':
+		return weightC, true
+	case 'D', 'd':
+		return weightD, true
+	}
+	return 0, false
+}
+
+// tsPosition is a single position of a lexeme in a document, along with the
+// weight of the lexeme at that position.
+type tsPosition struct {
+	position uint16
+	weight   tsWeight
+}
+
+// tsTerm is a lexeme in a TSVector along with its positions, which are sorted
+// and contain no duplicates. A term without positions is valid; it is the
+// result of parsing a TSVector that lists the lexeme on its own.
+type tsTerm struct {
+	lexeme    string
+	positions []tsPosition
+}
+
+// TSVector is a sorted list of distinct lexemes with their positions, and is
+// the representation of a document for the purpose of full text search. It
+// corresponds to the Postgres tsvector type.
+type TSVector []tsTerm
+
+// ParseTSVector parses the text form of a TSVector, which is a whitespace
+// separated list of lexemes, each optionally quoted and optionally followed by
+// a colon and a comma separated list of positions, e.g.:
+//
+//   'fat':2 'cat':3A rat:1,5B
+//
+// Lexemes are not normalized; use ToTSVector to turn a document into a
+// TSVector.
+func ParseTSVector(input string) (TSVector, error) {
+	var ret TSVector
+	s := tsScanner{input: input}
+	for {
+		s.skipSpace()
+		if s.done() {
+			break
+		}
+		lexeme, ok := s.scanLexeme(false /* inQuery */)
+		if !ok {
+			return nil, newTSVectorSyntaxError(input)
+		}
+		t := tsTerm{lexeme: lexeme}
+		if s.peek() == ':' {
+			s.pos++
+			for {
+				start := s.pos
+				for !s.done() && s.peek() >= '0' && s.peek() <= '9' {
+					s.pos++
+				}
+				if start == s.pos {
+					return nil, newTSVectorSyntaxError(input)
+				}
+				p, err := strconv.Atoi(input[start:s.pos])
+				if err != nil || p == 0 {
+					return nil, pgerror.Newf(pgcode.Syntax,
+						"wrong position info in tsvector: %q", input)
+				}
+				if p > maxTSPosition {
+					p = maxTSPosition
+				}
+				pos := tsPosition{position: uint16(p)}
+				if !s.done() {
+					if w, ok := parseTSWeight(s.peek()); ok {
+						pos.weight = w
+						s.pos++
+					} else if s.peek() == '*' {
+						// Postgres ignores the prefix marker in a tsvector.
+						s.pos++
+					}
+				}
+				t.positions = append(t.positions, pos)
+				if s.peek() != ',' {
+					break
+				}
+				s.pos++
+			}
+		}
+		if !s.done() && !isTSSpace(s.peek()) {
+			return nil, newTSVectorSyntaxError(input)
+		}
+		ret = append(ret, t)
+	}
+	return normalizeTSVector(ret), nil
+}
+
+func newTSVectorSyntaxError(input string) error {
+	return pgerror.Newf(pgcode.Syntax, "syntax error in tsvector: %q", input)
+}
+
+// normalizeTSVector sorts the terms of the given vector by lexeme, merges the
+// positions of duplicate lexemes and sorts and deduplicates all positions.
+func normalizeTSVector(v TSVector) TSVector {
+	if len(v) == 0 {
+		return v
+	}
+	sort.SliceStable(v, func(i, j int) bool {
+		return v[i].lexeme < v[j].lexeme
+	})
+	ret := v[:1]
+	for _, t := range v[1:] {
+		last := &ret[len(ret)-1]
+		if last.lexeme == t.lexeme {
+			last.positions = append(last.positions, t.positions...)
+			continue
+		}
+		ret = append(ret, t)
+	}
+	for i := range ret {
+		ret[i].positions = normalizeTSPositions(ret[i].positions)
+	}
+	return ret
+}
+
+// normalizeTSPositions sorts the given positions and removes duplicates. When
+// a position appears more than once, the highest weight is kept.
+func normalizeTSPositions(positions []tsPosition) []tsPosition {
+	if len(positions) == 0 {
+		return nil
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].position < positions[j].position
+	})
+	ret := positions[:1]
+	for _, p := range positions[1:] {
+		last := &ret[len(ret)-1]
+		if last.position == p.position {
+			if p.weight > last.weight {
+				last.weight = p.weight
+			}
+			continue
+		}
+		ret = append(ret, p)
+	}
+	if len(ret) > maxTSPositionsPerLexeme {
+		ret = ret[:maxTSPositionsPerLexeme]
+	}
+	return ret
+}
+
+// String returns the text form of the TSVector.
+func (v TSVector) String() string {
+	var buf strings.Builder
+	for i, t := range v {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		writeQuotedLexeme(&buf, t.lexeme)
+		for j, p := range t.positions {
+			if j == 0 {
+				buf.WriteByte(':')
+			} else {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Itoa(int(p.position)))
+			if p.weight != weightD {
+				buf.WriteString(p.weight.String())
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Len returns the number of distinct lexemes in the TSVector.
+func (v TSVector) Len() int {
+	return len(v)
+}
+
+// Lexemes returns the distinct lexemes of the TSVector, in sorted order.
+func (v TSVector) Lexemes() []string {
+	ret := make([]string, len(v))
+	for i := range v {
+		ret[i] = v[i].lexeme
+	}
+	return ret
+}
+
+// Size returns an estimate of the memory footprint of the TSVector in bytes.
+func (v TSVector) Size() uintptr {
+	var sz uintptr
+	for i := range v {
+		sz += uintptr(len(v[i].lexeme)) + uintptr(len(v[i].positions))*3 + 40
+	}
+	return sz
+}
+
+// find returns the index of the first term whose lexeme is greater than or
+// equal to the given lexeme.
+func (v TSVector) find(lexeme string) int {
+	return sort.Search(len(v), func(i int) bool {
+		return v[i].lexeme >= lexeme
+	})
+}
+
+// writeQuotedLexeme writes the lexeme surrounded by single quotes, escaping
+// quotes and backslashes in the same way that Postgres does.
+func writeQuotedLexeme(buf *strings.Builder, lexeme string) {
+	buf.WriteByte('\'')
+	for i := 0; i < len(lexeme); i++ {
+		c := lexeme[i]
+		if c == '\'' || c == '\\' {
+			buf.WriteByte(c)
+		}
+		buf.WriteByte(c)
+	}
+	buf.WriteByte('\'')
+}
+
+// tsScanner is a simple scanner shared by the TSVector and TSQuery parsers.
+type tsScanner struct {
+	input string
+	pos   int
+}
+
+func (s *tsScanner) done() bool {
+	return s.pos >= len(s.input)
+}
+
+// peek returns the next byte of input, or 0 if the input is exhausted.
+func (s *tsScanner) peek() byte {
+	if s.done() {
+		return 0
+	}
+	return s.input[s.pos]
+}
+
+func (s *tsScanner) skipSpace() {
+	for !s.done() && isTSSpace(s.peek()) {
+		s.pos++
+	}
+}
+
+func isTSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// isTSQuerySpecial returns whether c terminates an unquoted lexeme in a
+// TSQuery.
+func isTSQuerySpecial(c byte) bool {
+	switch c {
+	case '&', '|', '!', '(', ')', '<', ':', '\'':
+		return true
+	}
+	return false
+}
+
+// scanLexeme scans a quoted or unquoted lexeme. Inside a quoted lexeme, a
+// quote is escaped by doubling it. In both forms, a backslash escapes the
+// following character. An unquoted lexeme ends at whitespace or a colon, and
+// also at any operator character when inQuery is set.
+func (s *tsScanner) scanLexeme(inQuery bool) (string, bool) {
+	var buf strings.Builder
+	if s.peek() == '\'' {
+		s.pos++
+		for {
+			if s.done() {
+				return "", false
+			}
+			c := s.peek()
+			s.pos++
+			switch c {
+			case '\\':
+				if s.done() {
+					return "", false
+				}
+				buf.WriteByte(s.peek())
+				s.pos++
+			case '\'':
+				if s.peek() != '\'' {
+					if buf.Len() == 0 {
+						return "", false
+					}
+					return buf.String(), true
+				}
+				buf.WriteByte('\'')
+				s.pos++
+			default:
+				buf.WriteByte(c)
+			}
+		}
+	}
+	for !s.done() {
+		c := s.peek()
+		if isTSSpace(c) || c == ':' || (inQuery && isTSQuerySpecial(c)) {
+			break
+		}
+		s.pos++
+		if c == '\\' {
+			if s.done() {
+				return "", false
+			}
+			c = s.peek()
+			s.pos++
+		}
+		buf.WriteByte(c)
+	}
+	if buf.Len() == 0 {
+		return "", false
+	}
+	return buf.String(), true
+}